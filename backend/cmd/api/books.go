@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+
+	mysqladapter "github.com/gerry-sabar/byfood/internal/adapters/mysql"
+	"github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/export"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+// cliActor is who the change log credits books imported from the command
+// line to.
+var cliActor = domain.Actor{ID: "cli"}
+
+// catalog is the book services the one-off commands run through, wired like
+// serve's but without caches, workers or event publishing.
+type catalog struct {
+	db      *sqlx.DB
+	books   ports.BookService
+	aliases ports.AliasService
+}
+
+// openCatalog connects to MySQL and builds the services. Close the pool
+// with closeDB when done.
+func openCatalog(cfg config) (*catalog, error) {
+	db, err := sqlx.Open("mysql", cfg.DSN())
+	if err != nil {
+		return nil, err
+	}
+	if err := ping(db); err != nil {
+		closeDB(cfg.DB.Name, db)
+		return nil, err
+	}
+	phase, err := mysqladapter.ParseMigrationPhase(os.Getenv("MIGRATION_PRICE_CENTS"))
+	if err != nil {
+		logger.Log.Error("invalid MIGRATION_PRICE_CENTS, staying off", "error", err)
+	}
+	priceCents := mysqladapter.NewDualWrite("price_cents", phase)
+	repo := mysqladapter.NewBookRepository(db, mysqladapter.WithPriceCents(priceCents), mysqladapter.WithAuditLog())
+	prices := mysqladapter.NewPriceRepository(db, mysqladapter.WithPriceCents(priceCents), mysqladapter.WithAuditLog())
+	aliasRepo := mysqladapter.NewAliasRepository(db)
+	feed := app.NewChangeFeed(mysqladapter.NewChangeRepository(db))
+	return &catalog{
+		db:      db,
+		books:   app.NewBookService(repo, app.WithChangeFeed(feed), app.WithAliases(aliasRepo), app.WithPriceHistory(prices)),
+		aliases: app.NewAliasService(repo, aliasRepo, feed),
+	}, nil
+}
+
+// runExport writes every book like GET /books/export, to stdout or -o.
+// -table users or changes exports the accounts or the change log instead;
+// -mask anonymizes the rows for copies outside production.
+func runExport(cfg config, args []string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	table := fs.String("table", "books", "books, users or changes")
+	format := fs.String("format", "csv", "csv or xlsx")
+	delimiter := fs.String("delimiter", "", "CSV field delimiter: comma, semicolon, pipe or tab (default comma)")
+	decimal := fs.String("decimal", "", "CSV decimal separator: point or comma (default point)")
+	bom := fs.Bool("bom", false, "prefix the CSV with a UTF-8 byte order mark")
+	mask := fs.Bool("mask", false, "replace emails and actors with pseudonyms keyed by EXPORT_MASK_KEY, for refreshing staging")
+	fuzz := fs.Float64("fuzz-prices", 0, "with -mask, move every price by up to this fraction, e.g. 0.1")
+	out := fs.String("o", "", "file to write (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	opts, err := export.ParseCSVOptions(*delimiter, *decimal, strconv.FormatBool(*bom))
+	switch {
+	case err != nil:
+	case *format != "csv" && *format != "xlsx":
+		err = errors.New("format must be csv or xlsx")
+	case !slices.Contains(export.ExportTables, *table):
+		err = errors.New("table must be books, users or changes")
+	case *fuzz != 0 && !*mask:
+		err = errors.New("-fuzz-prices needs -mask")
+	}
+	var masker *export.Masker
+	if err == nil && *mask {
+		if masker, err = export.NewMasker(cfg.ExportMaskKey, *fuzz); err != nil {
+			err = fmt.Errorf("EXPORT_MASK_KEY: %w", err)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	c, err := openCatalog(cfg)
+	if err != nil {
+		logger.Log.Error("open db", "error", err)
+		return 1
+	}
+	defer closeDB(cfg.DB.Name, c.db)
+	ctx := context.Background()
+
+	// read, mask, then write: masking is a stage between the two, so every
+	// format gets the same rows
+	var write func(io.Writer) error
+	var rows int
+	switch *table {
+	case "books":
+		books, err := c.books.ListBooks(ctx)
+		if err != nil {
+			logger.Log.Error("list books", "error", err)
+			return 1
+		}
+		books, rows = masker.MaskBooks(books), len(books)
+		write = func(w io.Writer) error {
+			if *format == "xlsx" {
+				return export.WriteBooksXLSX(w, books)
+			}
+			return export.WriteBooksCSV(w, books, opts)
+		}
+	case "users":
+		users, err := mysqladapter.NewUserRepository(c.db).List(ctx)
+		if err != nil {
+			logger.Log.Error("list users", "error", err)
+			return 1
+		}
+		users, rows = masker.MaskUsers(users), len(users)
+		write = func(w io.Writer) error {
+			if *format == "xlsx" {
+				return export.WriteUsersXLSX(w, users)
+			}
+			return export.WriteUsersCSV(w, users, opts)
+		}
+	case "changes":
+		changes, err := listChanges(ctx, mysqladapter.NewChangeRepository(c.db))
+		if err == nil {
+			changes, err = masker.MaskChanges(changes)
+		}
+		if err != nil {
+			logger.Log.Error("list changes", "error", err)
+			return 1
+		}
+		rows = len(changes)
+		write = func(w io.Writer) error {
+			if *format == "xlsx" {
+				return export.WriteChangesXLSX(w, changes)
+			}
+			return export.WriteChangesCSV(w, changes, opts)
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			logger.Log.Error("create export file", "path", *out, "error", err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := write(w); err != nil {
+		logger.Log.Error("export failed", "table", *table, "format", *format, "error", err)
+		return 1
+	}
+	logger.Log.Info("exported "+*table, "rows", rows, "format", *format, "masked", *mask)
+	return 0
+}
+
+// listChanges reads the whole change log, a page at a time.
+func listChanges(ctx context.Context, repo ports.ChangeRepository) ([]domain.Change, error) {
+	var all []domain.Change
+	var cursor int64
+	for {
+		page, err := repo.ListSince(ctx, cursor, 1000)
+		if err != nil || len(page) == 0 {
+			return all, err
+		}
+		all = append(all, page...)
+		cursor = page[len(page)-1].ID
+	}
+}
+
+// runImport inserts the books in a file like POST /books/import and prints
+// the outcome of every row as JSON. It exits with 1 if any row failed.
+func runImport(cfg config, args []string) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := fs.String("format", "", "csv or json (default: from the file extension)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: books-api import [-format csv|json] <file, or - for stdin>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	path := fs.Arg(0)
+	if *format == "" {
+		*format = export.BookFormat(path, "")
+	}
+
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			logger.Log.Error("open import file", "path", path, "error", err)
+			return 1
+		}
+		defer f.Close()
+		r = f
+	}
+	var rows []ports.ImportRow
+	var err error
+	switch *format {
+	case "json":
+		rows, err = export.ReadBooksJSON(r)
+	case "csv":
+		rows, err = export.ReadBooksCSV(r)
+	default:
+		err = errors.New("file must be .csv or .json, or set -format")
+	}
+	if err == nil && len(rows) == 0 {
+		err = errors.New("file has no rows")
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	c, err := openCatalog(cfg)
+	if err != nil {
+		logger.Log.Error("open db", "error", err)
+		return 1
+	}
+	defer closeDB(cfg.DB.Name, c.db)
+	res, err := c.books.ImportBooks(domain.WithActor(context.Background(), cliActor), rows)
+	if err != nil {
+		logger.Log.Error("import books", "error", err)
+		return 1
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(res)
+	logger.Log.Info("imported books", "inserted", res.Inserted, "skipped", res.Skipped, "failed", res.Failed)
+	if res.Failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/buildinfo"
+	"github.com/gerry-sabar/byfood/internal/logger"
+)
+
+const usage = `usage: books-api [-config file.yaml] [command] [flags]
+
+  serve         run the API (the default)
+  migrate       apply, revert or list schema migrations
+  seed          insert the demo catalogue, or the books in -file
+  export        write the books, users or change log as CSV or XLSX
+  import        insert the books in a CSV or JSON file
+  healthcheck   exit 0 if the API on PORT is healthy, 1 if not
+  help          print this
+
+Run a command with -h for its flags. Every command reads the same
+configuration: the YAML file named by -config or CONFIG_FILE, if any, with
+environment variables overriding it.`
+
+// commands are the subcommands of the binary, so operators can run one-off
+// tasks from the API's own container. Each returns the process exit code.
+var commands = map[string]func(cfg config, args []string) int{
+	"serve":       runServe,
+	"migrate":     runMigrate,
+	"seed":        runSeed,
+	"export":      runExport,
+	"import":      runImport,
+	"healthcheck": runHealthcheck,
+}
+
+// run loads the configuration and dispatches to the command named by the
+// first argument after the global flags, serve when there is none.
+func run(args []string) int {
+	global := flag.NewFlagSet("books-api", flag.ContinueOnError)
+	configFile := global.String("config", os.Getenv("CONFIG_FILE"), "YAML config file; environment variables override it")
+	global.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+	if err := global.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	args = global.Args()
+	name := "serve"
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		fmt.Println(usage)
+		return 0
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s\n", name, usage)
+		return 2
+	}
+
+	cfg, err := loadConfig(*configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		return 2
+	}
+	out := os.Stdout
+	if name != "serve" {
+		// the one-off commands print their results on stdout, so an export
+		// piped to a file doesn't get log lines mixed in
+		out = os.Stderr
+	}
+	// every line names the build, to tell replicas apart during a rollout
+	logger.Log = cfg.Log.newLogger(out).With("version", buildinfo.Get().String())
+	return cmd(cfg, args)
+}
+
+// runHealthcheck asks the API's /healthz, so a container health check
+// doesn't need curl in the image.
+func runHealthcheck(cfg config, args []string) int {
+	fs := flag.NewFlagSet("healthcheck", flag.ContinueOnError)
+	url := fs.String("url", "http://127.0.0.1:"+cfg.HTTP.Port+"/healthz", "health endpoint to ask")
+	timeout := fs.Duration("timeout", 3*time.Second, "how long to wait for an answer")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	client := &http.Client{Timeout: *timeout}
+	resp, err := client.Get(*url)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "unhealthy:", err)
+		return 1
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		fmt.Fprintln(os.Stderr, "unhealthy:", resp.Status)
+		return 1
+	}
+	return 0
+}
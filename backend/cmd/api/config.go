@@ -0,0 +1,579 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	httpadapter "github.com/gerry-sabar/byfood/internal/adapters/http"
+	"github.com/gerry-sabar/byfood/internal/httpclient"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/gerry-sabar/byfood/internal/presenter"
+	"github.com/gerry-sabar/byfood/internal/urlsafe"
+	"gopkg.in/yaml.v2"
+)
+
+// config is the whole configuration. The DB, HTTP, Log, CORS and Egress
+// sections can come from a YAML file, with the environment overriding it;
+// the rest is read from the environment only.
+type config struct {
+	DB     dbConfig
+	HTTP   httpConfig
+	Log    logConfig
+	CORS   corsConfig
+	Egress egressConfig
+
+	MigrateOnStart bool // apply pending schema migrations before serving
+
+	CacheTTL         time.Duration // 0 disables the read cache
+	CacheStaleWindow time.Duration // how long past CacheTTL lists are served while refreshed; 0 never serves them stale
+	CacheWarmTopN    int           // books to preload before /readyz passes
+	CacheWarmTimeout time.Duration
+
+	QueryGuardMinBooks int64 // filtered lists no index serves are rejected from this catalogue size; 0 disables
+
+	ChangesRetention time.Duration // superseded change log entries older than this are compacted; 0 keeps all
+
+	ListPreferencesTTL time.Duration // how long a tenant's book list defaults are reused before re-reading them
+	SearchRankingTTL   time.Duration // how long a tenant's search ranking is reused before re-reading it
+	SuggestionTermsTTL time.Duration // how long the titles and authors searches are corrected to are reused before re-reading them
+	SynonymsTTL        time.Duration // how long the synonym dictionary is reused before re-reading it
+
+	LoanPeriod time.Duration // how long a book is lent when a checkout sets no due date
+
+	TrustIdentityHeaders bool          // X-User / X-User-Scopes come from an authenticating proxy
+	JWTSecret            string        // signs the tokens of /auth/login; empty disables user accounts
+	JWTTTL               time.Duration // how long a login token is valid
+	EnforceRoles         bool          // only actors with the editor or admin role may change books
+	APIKeyAuth           string        // "writes" or "all": book requests that need an X-API-Key; empty disables keys
+	Envelope             bool          // wrap JSON responses in {data, meta, errors} unless a request opts out
+	GzipLevel            int           // gzip level for book listings and exports; 0 disables compression
+	FieldPolicy          string        // "price=editor|admin": book fields left out for callers without one of the scopes; empty shows all
+
+	InstanceID string // names this replica in leader election; defaults to hostname-pid
+
+	RateLimit float64 // requests per second allowed per client IP; 0 disables rate limiting
+	RateBurst int     // requests a client may send at once before the rate applies
+
+	CleanupProfiles    string        // YAML file of extra URL cleanup profiles; empty offers only the built-ins
+	AllowPrivateURLs   bool          // let URL fetches reach localhost and private addresses; development only
+	URLResolveTimeout  time.Duration // how long the cleanup resolve operation may follow redirects
+	CleanupCacheSize   int           // cleanup and resolve results kept in memory; 0 disables caching
+	URLResolveCacheTTL time.Duration // how long a resolved URL is reused; 0 never caches resolutions
+	ResolverUserAgent  string        // User-Agent of URL fetches, and the agent robots.txt rules are matched against
+	RobotsCacheTTL     time.Duration // how long a host's robots.txt is reused
+	TrackingRulesTTL   time.Duration // how long a tenant's tracking rules are reused before re-reading them
+
+	OutboundRatePerHost     float64       // requests per second each outside host gets from one of our clients; 0 doesn't limit
+	OutboundBurst           int           // requests one of our clients may send a host at once before the rate applies
+	OutboundRetries         int           // times a failed outbound GET is tried again
+	OutboundBreakerFailures int           // consecutive failures that stop outbound requests to a host for a while; 0 never stops them
+	OutboundBreakerCooldown time.Duration // how long a host is left alone once its breaker opens
+
+	MetadataProviders []string      // catalogues asked for book metadata, in order: openlibrary, googlebooks; "none" disables lookups
+	MetadataTimeout   time.Duration // how long each catalogue may take to answer
+	GoogleBooksAPIKey string        // raises the Google Books quota; empty uses the anonymous one
+	MetadataCacheTTL  time.Duration // how long a catalogue's answer is reused; 0 never caches them
+
+	WebhookTimeout time.Duration // how long a webhook may take to answer a delivery
+
+	ExportMaskKey string // keys the pseudonyms of export -mask; keep it the same across refreshes
+
+	NatsURL string // serve books.get / books.list requests from this NATS server; empty disables
+
+	EventPublisher string   // broker the book events are published to: nats (at NatsURL) or kafka; empty disables
+	EventTopic     string   // Kafka topic, or NATS subject prefix, of the events
+	KafkaBrokers   []string // host:port of Kafka brokers to bootstrap from
+
+	SandboxDBName     string        // database behind /sandbox; empty disables the sandbox
+	SandboxResetEvery time.Duration // how often the sandbox is reset to a copy of the catalogue
+
+	Demo         bool      // serve fixtures from memory with a stopped clock; no database
+	DemoFixtures string    // JSON fixtures file; empty uses the built-in catalogue
+	DemoClock    time.Time // the instant every demo timestamp is taken at
+}
+
+type dbConfig struct {
+	User     string `yaml:"user"`     // MYSQL_USER
+	Password string `yaml:"password"` // MYSQL_PASSWORD
+	Host     string `yaml:"host"`     // MYSQL_HOST
+	Port     string `yaml:"port"`     // MYSQL_PORT
+	Name     string `yaml:"name"`     // MYSQL_DATABASE
+	Params   string `yaml:"params"`   // MYSQL_PARAMS
+}
+
+type httpConfig struct {
+	Port string `yaml:"port"` // PORT
+	// AdminPort serves pprof and expvar instead of /debug/vars on Port;
+	// empty disables. ADMIN_PORT
+	AdminPort string `yaml:"admin_port"`
+	// ShutdownTimeout is how long a shutdown waits for in-flight requests
+	// to finish. SHUTDOWN_TIMEOUT
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// DrainTimeout is how long a shutdown waits for workers and imports to
+	// finish. DRAIN_TIMEOUT
+	DrainTimeout time.Duration `yaml:"drain_timeout"`
+	// DefaultPageSize is the page size of book lists that send no limit;
+	// 0 returns every book. DEFAULT_PAGE_SIZE
+	DefaultPageSize int `yaml:"default_page_size"`
+	// MaxPageSize is the largest limit a book list accepts; 0 accepts any.
+	// MAX_PAGE_SIZE
+	MaxPageSize int `yaml:"max_page_size"`
+}
+
+type logConfig struct {
+	Level  string `yaml:"level"`  // debug, info, warn or error. LOG_LEVEL
+	Format string `yaml:"format"` // json or text. LOG_FORMAT
+}
+
+// PageLimits bound the pages of book lists.
+func (h httpConfig) PageLimits() ports.PageLimits {
+	return ports.PageLimits{Default: h.DefaultPageSize, Max: h.MaxPageSize}
+}
+
+// corsConfig lets browsers on other origins call the API. No origins
+// disables CORS.
+type corsConfig struct {
+	AllowedOrigins   []string      `yaml:"allowed_origins"`   // CORS_ALLOWED_ORIGINS, comma-separated; "*" allows any
+	AllowedMethods   []string      `yaml:"allowed_methods"`   // CORS_ALLOWED_METHODS
+	AllowedHeaders   []string      `yaml:"allowed_headers"`   // CORS_ALLOWED_HEADERS
+	AllowCredentials bool          `yaml:"allow_credentials"` // CORS_ALLOW_CREDENTIALS
+	MaxAge           time.Duration `yaml:"max_age"`           // CORS_MAX_AGE, how long a preflight is reused
+}
+
+// egressConfig limits the hosts outbound requests may reach, on top of the
+// URL policy. Each list holds host names, IP addresses and *.domain
+// patterns. A client's own list replaces Allow; with neither, the client
+// may reach any public host.
+type egressConfig struct {
+	Allow    []string `yaml:"allow"`     // EGRESS_ALLOW, comma-separated; every outbound client
+	URLFetch []string `yaml:"url_fetch"` // EGRESS_ALLOW_URL_FETCH; URL resolve and extract, robots.txt
+	Metadata []string `yaml:"metadata"`  // EGRESS_ALLOW_METADATA; catalogue lookups
+	Webhooks []string `yaml:"webhooks"`  // EGRESS_ALLOW_WEBHOOKS; webhook deliveries and the URLs webhooks may have
+}
+
+// fileConfig is the layout of the config file.
+type fileConfig struct {
+	DB     dbConfig     `yaml:"db"`
+	HTTP   httpConfig   `yaml:"http"`
+	Log    logConfig    `yaml:"log"`
+	CORS   corsConfig   `yaml:"cors"`
+	Egress egressConfig `yaml:"egress"`
+}
+
+// defaultFile holds the settings used when neither the file nor the
+// environment sets them.
+var defaultFile = fileConfig{
+	DB: dbConfig{Host: "db", Port: "3306", Name: "booksdb", Params: "parseTime=true&charset=utf8mb4&loc=UTC"},
+	HTTP: httpConfig{Port: "8080", ShutdownTimeout: 20 * time.Second, DrainTimeout: 30 * time.Second,
+		DefaultPageSize: 50, MaxPageSize: 200},
+	Log: logConfig{Level: "info", Format: "json"},
+	CORS: corsConfig{
+		AllowedMethods: []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE"},
+		AllowedHeaders: []string{"Content-Type", "Authorization", "X-API-Key", "X-Request-ID", "If-Match", "If-None-Match"},
+		MaxAge:         10 * time.Minute,
+	},
+}
+
+// readConfigFile reads the YAML file at path over the defaults. Unknown keys
+// are errors, so a typo doesn't silently drop a setting.
+func readConfigFile(path string) (fileConfig, error) {
+	file := defaultFile
+	if path == "" {
+		return file, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return file, err
+	}
+	defer f.Close()
+	dec := yaml.NewDecoder(f)
+	dec.SetStrict(true)
+	if err := dec.Decode(&file); err != nil && err != io.EOF {
+		return file, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// loadConfig reads the config file at path, if any, then the environment,
+// which overrides it, and validates the result.
+func loadConfig(path string) (config, error) {
+	file, err := readConfigFile(path)
+	if err != nil {
+		return config{}, err
+	}
+	c := config{
+		DB: dbConfig{
+			User:     getEnv("MYSQL_USER", file.DB.User),
+			Password: getEnv("MYSQL_PASSWORD", file.DB.Password),
+			Host:     getEnv("MYSQL_HOST", file.DB.Host),
+			Port:     getEnv("MYSQL_PORT", file.DB.Port),
+			Name:     getEnv("MYSQL_DATABASE", file.DB.Name),
+			Params:   getEnv("MYSQL_PARAMS", file.DB.Params),
+		},
+		HTTP: httpConfig{
+			Port:            getEnv("PORT", file.HTTP.Port),
+			AdminPort:       getEnv("ADMIN_PORT", file.HTTP.AdminPort),
+			ShutdownTimeout: getEnvDuration("SHUTDOWN_TIMEOUT", file.HTTP.ShutdownTimeout),
+			DrainTimeout:    getEnvDuration("DRAIN_TIMEOUT", file.HTTP.DrainTimeout),
+			DefaultPageSize: getEnvInt("DEFAULT_PAGE_SIZE", file.HTTP.DefaultPageSize),
+			MaxPageSize:     getEnvInt("MAX_PAGE_SIZE", file.HTTP.MaxPageSize),
+		},
+		Log: logConfig{
+			Level:  strings.ToLower(getEnv("LOG_LEVEL", file.Log.Level)),
+			Format: strings.ToLower(getEnv("LOG_FORMAT", file.Log.Format)),
+		},
+		CORS: corsConfig{
+			AllowedOrigins:   getEnvList("CORS_ALLOWED_ORIGINS", file.CORS.AllowedOrigins),
+			AllowedMethods:   getEnvList("CORS_ALLOWED_METHODS", file.CORS.AllowedMethods),
+			AllowedHeaders:   getEnvList("CORS_ALLOWED_HEADERS", file.CORS.AllowedHeaders),
+			AllowCredentials: getEnv("CORS_ALLOW_CREDENTIALS", strconv.FormatBool(file.CORS.AllowCredentials)) == "true",
+			MaxAge:           getEnvDuration("CORS_MAX_AGE", file.CORS.MaxAge),
+		},
+		Egress: egressConfig{
+			Allow:    getEnvList("EGRESS_ALLOW", file.Egress.Allow),
+			URLFetch: getEnvList("EGRESS_ALLOW_URL_FETCH", file.Egress.URLFetch),
+			Metadata: getEnvList("EGRESS_ALLOW_METADATA", file.Egress.Metadata),
+			Webhooks: getEnvList("EGRESS_ALLOW_WEBHOOKS", file.Egress.Webhooks),
+		},
+
+		MigrateOnStart: os.Getenv("MIGRATE_ON_START") == "true",
+
+		CacheTTL:         getEnvDuration("CACHE_TTL", 0),
+		CacheStaleWindow: getEnvDuration("CACHE_STALE_WINDOW", 0),
+		CacheWarmTopN:    getEnvInt("CACHE_WARM_TOP_N", 100),
+		CacheWarmTimeout: getEnvDuration("CACHE_WARM_TIMEOUT", 30*time.Second),
+
+		QueryGuardMinBooks: int64(getEnvInt("QUERY_GUARD_MIN_BOOKS", 100000)),
+
+		ChangesRetention: getEnvDuration("CHANGES_RETENTION", 30*24*time.Hour),
+
+		ListPreferencesTTL: getEnvDuration("LIST_PREFERENCES_CACHE_TTL", time.Minute),
+		SearchRankingTTL:   getEnvDuration("SEARCH_RANKING_CACHE_TTL", time.Minute),
+		SuggestionTermsTTL: getEnvDuration("SUGGESTION_TERMS_TTL", 5*time.Minute),
+		SynonymsTTL:        getEnvDuration("SYNONYMS_CACHE_TTL", time.Minute),
+
+		LoanPeriod: getEnvDuration("LOAN_PERIOD", 14*24*time.Hour),
+
+		TrustIdentityHeaders: os.Getenv("TRUST_IDENTITY_HEADERS") == "true",
+		JWTSecret:            os.Getenv("JWT_SECRET"),
+		JWTTTL:               getEnvDuration("JWT_TTL", 24*time.Hour),
+		EnforceRoles:         os.Getenv("ENFORCE_ROLES") == "true",
+		APIKeyAuth:           apiKeyMode(os.Getenv("API_KEY_AUTH")),
+		Envelope:             os.Getenv("RESPONSE_ENVELOPE") == "true",
+		GzipLevel:            getEnvInt("GZIP_LEVEL", 5),
+		FieldPolicy:          os.Getenv("FIELD_POLICY"),
+
+		InstanceID: getEnv("INSTANCE_ID", defaultInstanceID()),
+
+		RateLimit: getEnvFloat("RATE_LIMIT_RPS", 0),
+		RateBurst: getEnvInt("RATE_LIMIT_BURST", 20),
+
+		CleanupProfiles:    os.Getenv("CLEANUP_PROFILES"),
+		AllowPrivateURLs:   os.Getenv("ALLOW_PRIVATE_URLS") == "true" && os.Getenv("APP_ENV") != "production",
+		URLResolveTimeout:  getEnvDuration("URL_RESOLVE_TIMEOUT", 10*time.Second),
+		CleanupCacheSize:   getEnvInt("CLEANUP_CACHE_SIZE", 10000),
+		URLResolveCacheTTL: getEnvDuration("URL_RESOLVE_CACHE_TTL", time.Hour),
+		ResolverUserAgent:  getEnv("RESOLVER_USER_AGENT", "byfood-resolver/1.0"),
+		RobotsCacheTTL:     getEnvDuration("ROBOTS_CACHE_TTL", 24*time.Hour),
+		TrackingRulesTTL:   getEnvDuration("TRACKING_RULES_CACHE_TTL", time.Minute),
+
+		OutboundRatePerHost:     getEnvFloat("OUTBOUND_RATE_PER_HOST", 2),
+		OutboundBurst:           getEnvInt("OUTBOUND_BURST", 5),
+		OutboundRetries:         getEnvInt("OUTBOUND_RETRIES", 2),
+		OutboundBreakerFailures: getEnvInt("OUTBOUND_BREAKER_FAILURES", 5),
+		OutboundBreakerCooldown: getEnvDuration("OUTBOUND_BREAKER_COOLDOWN", 30*time.Second),
+
+		MetadataProviders: getEnvList("METADATA_PROVIDERS", []string{"openlibrary", "googlebooks"}),
+		MetadataTimeout:   getEnvDuration("METADATA_TIMEOUT", 5*time.Second),
+		GoogleBooksAPIKey: os.Getenv("GOOGLE_BOOKS_API_KEY"),
+		MetadataCacheTTL:  getEnvDuration("METADATA_CACHE_TTL", time.Hour),
+
+		WebhookTimeout: getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+
+		ExportMaskKey: os.Getenv("EXPORT_MASK_KEY"),
+
+		NatsURL: os.Getenv("NATS_URL"),
+
+		EventPublisher: os.Getenv("EVENT_PUBLISHER"),
+		EventTopic:     getEnv("EVENT_TOPIC", "byfood.events"),
+		KafkaBrokers:   getEnvList("KAFKA_BROKERS", nil),
+
+		SandboxDBName:     os.Getenv("SANDBOX_MYSQL_DATABASE"),
+		SandboxResetEvery: getEnvDuration("SANDBOX_RESET_EVERY", 24*time.Hour),
+
+		Demo:         os.Getenv("DEMO_MODE") == "true",
+		DemoFixtures: os.Getenv("DEMO_FIXTURES"),
+		DemoClock:    getEnvTime("DEMO_CLOCK", time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)),
+	}
+	return c, c.validate()
+}
+
+// eventTopicPattern is what is both a Kafka topic and a NATS subject.
+var eventTopicPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+(\.[A-Za-z0-9_-]+)*$`)
+
+// validate reports every setting of the file-backed sections that can't
+// work, so a bad deploy fails at start instead of on first use.
+func (c config) validate() error {
+	var errs []error
+	bad := func(format string, args ...any) { errs = append(errs, fmt.Errorf(format, args...)) }
+	if c.DB.Host == "" || c.DB.Name == "" {
+		bad("db: host and name are required")
+	}
+	for _, p := range []struct{ name, port string }{{"db.port", c.DB.Port}, {"http.port", c.HTTP.Port}, {"http.admin_port", c.HTTP.AdminPort}} {
+		if n, err := strconv.Atoi(p.port); (p.port != "" || p.name != "http.admin_port") && (err != nil || n < 1 || n > 65535) {
+			bad("%s: %q is not a port", p.name, p.port)
+		}
+	}
+	if c.HTTP.AdminPort != "" && c.HTTP.AdminPort == c.HTTP.Port {
+		bad("http: admin_port must differ from port")
+	}
+	if c.HTTP.ShutdownTimeout <= 0 || c.HTTP.DrainTimeout <= 0 {
+		bad("http: shutdown_timeout and drain_timeout must be positive")
+	}
+	switch {
+	case c.HTTP.DefaultPageSize < 0 || c.HTTP.MaxPageSize < 0:
+		bad("http: default_page_size and max_page_size must not be negative")
+	case c.HTTP.MaxPageSize > 0 && (c.HTTP.DefaultPageSize == 0 || c.HTTP.DefaultPageSize > c.HTTP.MaxPageSize):
+		bad("http: default_page_size must be between 1 and max_page_size")
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Log.Level)); err != nil {
+		bad("log.level: %q is not debug, info, warn or error", c.Log.Level)
+	}
+	if c.Log.Format != "json" && c.Log.Format != "text" {
+		bad("log.format: %q is not json or text", c.Log.Format)
+	}
+	for _, o := range c.CORS.AllowedOrigins {
+		if o == "*" {
+			if c.CORS.AllowCredentials {
+				bad("cors: allow_credentials can't be used with the origin *")
+			}
+			continue
+		}
+		if u, err := url.Parse(o); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" {
+			bad("cors.allowed_origins: %q is not an origin like https://app.example.com", o)
+		}
+	}
+	if c.CORS.MaxAge < 0 {
+		bad("cors.max_age must not be negative")
+	}
+	if _, err := presenter.ParseFieldPolicy(c.FieldPolicy); err != nil {
+		bad("FIELD_POLICY: %v", err)
+	}
+	for _, p := range c.MetadataProviders {
+		if p != "openlibrary" && p != "googlebooks" && p != "none" {
+			bad("METADATA_PROVIDERS: %q is not openlibrary, googlebooks or none", p)
+		}
+	}
+	if _, err := httpclient.ParseAllowlist(c.Egress.Allow); err != nil {
+		bad("egress.allow: %v", err)
+	}
+	if _, err := httpclient.ParseAllowlist(c.Egress.URLFetch); err != nil {
+		bad("egress.url_fetch: %v", err)
+	}
+	if _, err := httpclient.ParseAllowlist(c.Egress.Metadata); err != nil {
+		bad("egress.metadata: %v", err)
+	}
+	if _, err := httpclient.ParseAllowlist(c.Egress.Webhooks); err != nil {
+		bad("egress.webhooks: %v", err)
+	}
+	if c.OutboundRatePerHost < 0 || c.OutboundRetries < 0 || c.OutboundBreakerFailures < 0 {
+		bad("OUTBOUND_RATE_PER_HOST, OUTBOUND_RETRIES and OUTBOUND_BREAKER_FAILURES must not be negative")
+	}
+	if c.MetadataTimeout <= 0 {
+		bad("METADATA_TIMEOUT must be positive")
+	}
+	if c.WebhookTimeout <= 0 {
+		bad("WEBHOOK_TIMEOUT must be positive")
+	}
+	switch c.EventPublisher {
+	case "":
+	case "nats":
+		if c.NatsURL == "" {
+			bad("EVENT_PUBLISHER: nats needs NATS_URL")
+		}
+	case "kafka":
+		if len(c.KafkaBrokers) == 0 {
+			bad("EVENT_PUBLISHER: kafka needs KAFKA_BROKERS")
+		}
+		for _, b := range c.KafkaBrokers {
+			if _, port, err := net.SplitHostPort(b); err != nil || port == "" {
+				bad("KAFKA_BROKERS: %q is not host:port", b)
+			}
+		}
+	default:
+		bad("EVENT_PUBLISHER: %q is not nats or kafka", c.EventPublisher)
+	}
+	if !eventTopicPattern.MatchString(c.EventTopic) || len(c.EventTopic) > 249 {
+		bad("EVENT_TOPIC: %q is not dot-separated letters, digits, _ and -", c.EventTopic)
+	}
+	return errors.Join(errs...)
+}
+
+// newLogger writes the logs in the configured format and level to w.
+func (l logConfig) newLogger(w io.Writer) *slog.Logger {
+	var level slog.Level
+	_ = level.UnmarshalText([]byte(l.Level))
+	opts := &slog.HandlerOptions{Level: level}
+	if l.Format == "text" {
+		return slog.New(slog.NewTextHandler(w, opts))
+	}
+	return slog.New(slog.NewJSONHandler(w, opts))
+}
+
+// CORSOptions is the CORS section as the HTTP adapter takes it.
+func (c corsConfig) CORSOptions() httpadapter.CORSOptions {
+	return httpadapter.CORSOptions{
+		AllowedOrigins:   c.AllowedOrigins,
+		AllowedMethods:   c.AllowedMethods,
+		AllowedHeaders:   c.AllowedHeaders,
+		AllowCredentials: c.AllowCredentials,
+		MaxAge:           c.MaxAge,
+	}
+}
+
+// apiKeyMode checks API_KEY_AUTH. An unknown value protects everything rather
+// than silently leaving the API open.
+func apiKeyMode(v string) string {
+	switch v {
+	case "", "writes", "all":
+		return v
+	}
+	logger.Log.Error("invalid API_KEY_AUTH, requiring keys for all book requests", "value", v)
+	return "all"
+}
+
+// URLPolicy is the SSRF policy for every URL the API fetches on a caller's
+// behalf.
+func (c config) URLPolicy() urlsafe.Policy {
+	return urlsafe.Policy{AllowPrivate: c.AllowPrivateURLs}
+}
+
+// Allowlist is the egress allowlist of the named client, which validate
+// has checked; nil allows every host.
+func (e egressConfig) Allowlist(client string) *httpclient.Allowlist {
+	hosts := e.Allow
+	switch {
+	case client == "url_fetch" && len(e.URLFetch) > 0:
+		hosts = e.URLFetch
+	case client == "metadata" && len(e.Metadata) > 0:
+		hosts = e.Metadata
+	case client == "webhooks" && len(e.Webhooks) > 0:
+		hosts = e.Webhooks
+	}
+	a, _ := httpclient.ParseAllowlist(hosts)
+	return a
+}
+
+// OutboundClient is a client of the URL policy whose requests, each given at
+// most timeout, go through the egress allowlist of name and the OUTBOUND_*
+// limits; name also labels its metrics and logs.
+func (c config) OutboundClient(name string, timeout, cacheTTL time.Duration) *http.Client {
+	return httpclient.New(c.URLPolicy().Client(timeout), httpclient.Options{
+		Name:            name,
+		Egress:          c.Egress.Allowlist(name),
+		RatePerHost:     c.OutboundRatePerHost,
+		Burst:           c.OutboundBurst,
+		Retries:         c.OutboundRetries,
+		CacheTTL:        cacheTTL,
+		BreakerFailures: c.OutboundBreakerFailures,
+		BreakerCooldown: c.OutboundBreakerCooldown,
+	})
+}
+
+// Fields is the policy of FIELD_POLICY, which validate has checked.
+func (c config) Fields() presenter.FieldPolicy {
+	p, _ := presenter.ParseFieldPolicy(c.FieldPolicy)
+	return p
+}
+
+func defaultInstanceID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+func (c config) DSN() string {
+	// user:pass@tcp(host:port)/dbname?params
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?%s", c.DB.User, c.DB.Password, c.DB.Host, c.DB.Port, c.DB.Name, c.DB.Params)
+}
+
+func getEnv(k, def string) string {
+	if v := os.Getenv(k); v != "" {
+		return v
+	}
+	return def
+}
+
+// getEnvList reads a comma-separated list.
+func getEnvList(k string, def []string) []string {
+	if v := os.Getenv(k); v != "" {
+		return splitAndTrim(v, ",")
+	}
+	return def
+}
+
+func getEnvInt(k string, def int) int {
+	v := os.Getenv(k)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		logger.Log.Error("invalid integer env var, using default", "key", k, "value", v, "default", def)
+		return def
+	}
+	return n
+}
+
+func getEnvFloat(k string, def float64) float64 {
+	v := os.Getenv(k)
+	if v == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		logger.Log.Error("invalid number env var, using default", "key", k, "value", v, "default", def)
+		return def
+	}
+	return f
+}
+
+func getEnvDuration(k string, def time.Duration) time.Duration {
+	v := os.Getenv(k)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		logger.Log.Error("invalid duration env var, using default", "key", k, "value", v, "default", def)
+		return def
+	}
+	return d
+}
+
+func getEnvTime(k string, def time.Time) time.Time {
+	v := os.Getenv(k)
+	if v == "" {
+		return def
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		logger.Log.Error("invalid time env var, using default", "key", k, "value", v, "default", def)
+		return def
+	}
+	return t.UTC()
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig_FileAndEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	if err := os.WriteFile(path, []byte(`
+db:
+  host: mysql.internal
+  name: books
+http:
+  port: "9000"
+  shutdown_timeout: 5s
+log:
+  format: text
+cors:
+  allowed_origins: [https://app.example.com]
+egress:
+  allow: [openlibrary.org]
+  metadata: ["*.googleapis.com"]
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MYSQL_HOST", "")
+	t.Setenv("PORT", "9100")
+	t.Setenv("LOG_LEVEL", "DEBUG")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://a.example.com, https://b.example.com")
+	t.Setenv("EGRESS_ALLOW", "openlibrary.org, covers.openlibrary.org")
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	if cfg.DB.Host != "mysql.internal" || cfg.DB.Name != "books" || cfg.DB.Port != "3306" {
+		t.Errorf("db = %+v; want the file's host and name, the default port", cfg.DB)
+	}
+	if cfg.HTTP.Port != "9100" || cfg.HTTP.ShutdownTimeout != 5*time.Second || cfg.HTTP.DrainTimeout != 30*time.Second {
+		t.Errorf("http = %+v; want PORT over the file", cfg.HTTP)
+	}
+	if cfg.Log.Level != "debug" || cfg.Log.Format != "text" {
+		t.Errorf("log = %+v", cfg.Log)
+	}
+	if got := strings.Join(cfg.CORS.AllowedOrigins, " "); got != "https://a.example.com https://b.example.com" ||
+		len(cfg.CORS.AllowedMethods) == 0 {
+		t.Errorf("cors = %+v", cfg.CORS)
+	}
+	if fetch, meta := cfg.Egress.Allowlist("url_fetch"), cfg.Egress.Allowlist("metadata"); !fetch.Allows("covers.openlibrary.org") || fetch.Allows("www.googleapis.com") ||
+		!meta.Allows("www.googleapis.com") || meta.Allows("openlibrary.org") {
+		t.Errorf("egress = %+v; want EGRESS_ALLOW for url_fetch and the file's list for metadata", cfg.Egress)
+	}
+}
+
+func TestLoadConfig_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	typo := filepath.Join(dir, "typo.yaml")
+	_ = os.WriteFile(typo, []byte("http:\n  prot: \"80\"\n"), 0o600)
+	if _, err := loadConfig(typo); err == nil || !strings.Contains(err.Error(), "prot") {
+		t.Fatalf("unknown key: %v", err)
+	}
+	if _, err := loadConfig(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Fatal("missing file loaded")
+	}
+
+	t.Setenv("PORT", "http")
+	t.Setenv("LOG_FORMAT", "xml")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "*,app.example.com")
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "true")
+	t.Setenv("DEFAULT_PAGE_SIZE", "500")
+	t.Setenv("METADATA_PROVIDERS", "openlibrary,amazon")
+	t.Setenv("FIELD_POLICY", "cost=admin")
+	t.Setenv("EGRESS_ALLOW_URL_FETCH", "https://example.com/")
+	t.Setenv("EVENT_PUBLISHER", "kafka")
+	t.Setenv("KAFKA_BROKERS", "kafka:9092,kafka")
+	t.Setenv("EVENT_TOPIC", "byfood..events")
+	_, err := loadConfig("")
+	for _, want := range []string{"http.port", "log.format", "allow_credentials", `"app.example.com" is not an origin`, "default_page_size", `"amazon"`, "egress.url_fetch", `KAFKA_BROKERS: "kafka"`, "EVENT_TOPIC"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("err = %v; want it to mention %s", err, want)
+		}
+	}
+}
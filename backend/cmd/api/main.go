@@ -1,11 +1,19 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"expvar"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"net/http/pprof"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
@@ -15,11 +23,21 @@ import (
 	"github.com/gerry-sabar/byfood/docs"
 
 	httpadapter "github.com/gerry-sabar/byfood/internal/adapters/http"
+	kafkaadapter "github.com/gerry-sabar/byfood/internal/adapters/kafka"
+	"github.com/gerry-sabar/byfood/internal/adapters/memory"
+	"github.com/gerry-sabar/byfood/internal/adapters/metadata"
 	mysqladapter "github.com/gerry-sabar/byfood/internal/adapters/mysql"
+	natsadapter "github.com/gerry-sabar/byfood/internal/adapters/nats"
 	app "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/buildinfo"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/httpclient"
 	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/gerry-sabar/byfood/pkg/urlclean"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
@@ -29,8 +47,16 @@ import (
 // @BasePath        /
 // @schemes         http
 func main() {
-	// --- Config & DB ---
-	cfg := loadConfig()
+	os.Exit(run(os.Args[1:]))
+}
+
+// runServe runs the API until it is stopped, from MySQL or, with DEMO set,
+// from the demo fixtures.
+func runServe(cfg config, args []string) int {
+	if len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "usage: books-api serve")
+		return 2
+	}
 
 	// Configure (optional) Swagger host/schemes at runtime
 	// e.g. set APP_HOST=localhost:8080 and APP_SCHEMES=http (or https)
@@ -46,6 +72,12 @@ func main() {
 	}
 	docs.SwaggerInfo.BasePath = "/"
 
+	if cfg.Demo {
+		runDemo(cfg)
+		return 0
+	}
+
+	// --- DB ---
 	db, err := sqlx.Open("mysql", cfg.DSN())
 	if err != nil {
 		logger.Log.Error("open db", "error", err)
@@ -57,62 +89,610 @@ func main() {
 	if err := ping(db); err != nil {
 		logger.Log.Error("db ping", "error", err)
 	}
+	if cfg.MigrateOnStart {
+		// serving on a schema the code doesn't expect does more harm than
+		// not starting
+		if err := migrateUp(context.Background(), db); err != nil {
+			logger.Log.Error("migrate on start", "error", err)
+			os.Exit(1)
+		}
+	}
+	if n, err := mysqladapter.BackfillSearchKeys(context.Background(), db); err != nil {
+		logger.Log.Error("backfill search keys", "error", err)
+	} else if n > 0 {
+		logger.Log.Info("backfilled search keys", "rows", n)
+	}
+	if n, err := mysqladapter.BackfillISBN13(context.Background(), db, app.CanonicalISBN); err != nil {
+		logger.Log.Error("backfill ISBN-13", "error", err)
+	} else if n > 0 {
+		logger.Log.Info("backfilled ISBN-13", "rows", n)
+	}
+
+	// --- Online migrations ---
+	phase, err := mysqladapter.ParseMigrationPhase(os.Getenv("MIGRATION_PRICE_CENTS"))
+	if err != nil {
+		logger.Log.Error("invalid MIGRATION_PRICE_CENTS, staying off", "error", err)
+	}
+	priceCents := mysqladapter.NewDualWrite("price_cents", phase)
+	priceCents.Publish()
+	go backfillPriceCents(db, priceCents)
 
 	// --- Services & HTTP handler ---
-	repo := mysqladapter.NewBookRepository(db)
-	svc := app.NewBookService(repo)
-	h := httpadapter.NewHandler(svc)
+	repo := mysqladapter.NewBookRepository(db, mysqladapter.WithPriceCents(priceCents), mysqladapter.WithAuditLog())
+	changeRepo := mysqladapter.NewChangeRepository(db)
+	feed := app.NewChangeFeed(changeRepo)
+	workers := app.NewWorkers(mysqladapter.NewWorkerRepository(db))
+	workers.Publish()
+	go workers.Run(context.Background(), 5*time.Second)
+	// jobs that must run on one replica at a time run on the elected leader;
+	// each also keeps its own lock so a deposed leader still finishing a
+	// round can't overlap with the new one. All locks are held on one pooled
+	// connection.
+	locker := mysqladapter.NewLocker(db, 10*time.Second)
+	election := app.NewElection(locker, mysqladapter.NewLeaderRepository(db), "scheduler", cfg.InstanceID, 15*time.Second)
+	election.Publish()
+	electionCtx, stopElection := context.WithCancel(context.Background())
+	electionDone := make(chan struct{})
+	go func() {
+		defer close(electionDone)
+		election.Run(electionCtx)
+	}()
+	singleton := func(name string, job func(ctx context.Context)) func(ctx context.Context) {
+		return election.Lead(app.Singleton(locker, name, 15*time.Second, job))
+	}
+	if cfg.ChangesRetention > 0 {
+		workers.Go(context.Background(), "change_compaction", 3*time.Hour, singleton("change_compaction", func(ctx context.Context) {
+			feed.RunCompaction(ctx, cfg.ChangesRetention, time.Hour)
+		}))
+	}
+	aliasRepo := mysqladapter.NewAliasRepository(db)
+	viewRepo := mysqladapter.NewViewRepository(db)
+	views := app.NewViewCounter(viewRepo)
+	workers.Go(context.Background(), "view_counter", time.Minute, func(ctx context.Context) {
+		views.Run(ctx, 10*time.Second)
+	})
+
+	cleaner := loadCleanupProfiles(cfg.CleanupProfiles)
+	if cfg.CleanupCacheSize > 0 {
+		cleaner.UseCache(cfg.CleanupCacheSize)
+	}
+	cleaner.Publish()
+	trackingRules := app.NewTrackingRules(mysqladapter.NewTrackingRulesRepository(db), cfg.TrackingRulesTTL)
+	cleaner.UseTrackingRules(trackingRules)
+	// Fetches of caller URLs aren't cached here: resolutions and robots.txt
+	// files have caches of their own.
+	urlFetch := cfg.OutboundClient("url_fetch", cfg.URLResolveTimeout, 0)
+	httpclient.Publish()
+	robots := app.NewRobotsChecker(urlFetch, cfg.ResolverUserAgent, cfg.RobotsCacheTTL)
+	var resolver ports.URLResolver = app.NewURLResolver(cfg.URLPolicy(), urlFetch, cfg.ResolverUserAgent, robots)
+	if cfg.CleanupCacheSize > 0 && cfg.URLResolveCacheTTL > 0 {
+		cached := app.NewCachingURLResolver(resolver, cfg.CleanupCacheSize, cfg.URLResolveCacheTTL)
+		cached.Publish()
+		resolver = cached
+	}
+	cleanupStats := app.NewCleanupStats(mysqladapter.NewCleanupStatsRepository(db), cleaner)
+	workers.Go(context.Background(), "cleanup_stats", time.Minute, func(ctx context.Context) {
+		cleanupStats.Run(ctx, 10*time.Second)
+	})
+	searchInsights := app.NewSearchInsights(mysqladapter.NewSearchInsightsRepository(db))
+	workers.Go(context.Background(), "search_insights", time.Minute, func(ctx context.Context) {
+		searchInsights.Run(ctx, 10*time.Second)
+	})
+
+	deadLetters := app.NewDeadLetters(mysqladapter.NewDeadLetterRepository(db))
+	synonyms := app.NewSynonyms(mysqladapter.NewSynonymRepository(db), cfg.SynonymsTTL)
+	prices := mysqladapter.NewPriceRepository(db, mysqladapter.WithPriceCents(priceCents), mysqladapter.WithAuditLog())
+	svcOpts := []app.Option{app.WithChangeFeed(feed), app.WithAliases(aliasRepo), app.WithImportRequeue(deadLetters), app.WithSynonyms(synonyms), app.WithPriceHistory(prices)}
+	if cfg.QueryGuardMinBooks > 0 {
+		svcOpts = append(svcOpts, app.WithQueryGuard(app.NewQueryGuard(mysqladapter.NewBookStats(db), cfg.QueryGuardMinBooks)))
+	}
+	bookMetadata := metadataLookup(cfg)
+	if bookMetadata != nil {
+		svcOpts = append(svcOpts, app.WithMetadata(bookMetadata))
+	}
+	if publisher := eventPublisher(cfg); publisher != nil {
+		svcOpts = append(svcOpts, app.WithEventPublisher(publisher), app.WithEventRequeue(deadLetters))
+	}
+	// Cross-cutting concerns wrap the book service in the order listed: a
+	// call is logged, then counted, then possibly served from the cache.
+	bookMetrics := app.NewBookMetrics()
+	bookMetrics.Publish()
+	decorators := []app.BookDecorator{app.Intercept(app.LogBookCalls), app.Intercept(bookMetrics.Observe)}
+	var cache *app.CachingBookService
+	if cfg.CacheTTL > 0 {
+		decorators = append(decorators, func(next ports.BookService) ports.BookService {
+			cache = app.NewCachingBookService(next, cfg.CacheTTL, feed)
+			cache.ServeStale(cfg.CacheStaleWindow)
+			return cache
+		})
+	}
+	svc := app.DecorateBooks(app.NewBookService(repo, svcOpts...), decorators...)
+	if cfg.NatsURL != "" {
+		responder := natsadapter.NewResponder(svc, nil)
+		responder.UsePageLimits(cfg.HTTP.PageLimits())
+		go natsadapter.Run(context.Background(), cfg.NatsURL, responder)
+	}
+	taxonomy := mysqladapter.NewTaxonomyRepository(db)
+	authors := app.NewAuthorProjection(mysqladapter.NewAuthorRepository(db), repo, feed)
+	authors.UseTaxonomy(taxonomy)
+	workers.Go(context.Background(), "author_projection", 2*time.Minute, singleton("author_projection", authors.Run))
+	apiKeys := app.NewAPIKeys(mysqladapter.NewAPIKeyRepository(db))
+	// user accounts and bearer tokens only exist with a signing secret
+	var verifier ports.AuthService
+	authOpts := []httpadapter.Option{}
+	if cfg.JWTSecret != "" {
+		if len(cfg.JWTSecret) < 32 {
+			logger.Log.Warn("JWT_SECRET is shorter than 32 bytes; tokens are easier to forge")
+		}
+		users := mysqladapter.NewUserRepository(db)
+		auth := app.NewAuth(users, []byte(cfg.JWTSecret), cfg.JWTTTL)
+		verifier = auth
+		authOpts = append(authOpts, httpadapter.WithAuth(auth), httpadapter.WithUsers(app.NewUsers(users)),
+			httpadapter.WithFavorites(app.NewFavorites(mysqladapter.NewFavoriteRepository(db, mysqladapter.WithPriceCents(priceCents)), users)))
+	}
+	if cfg.EnforceRoles {
+		authOpts = append(authOpts, httpadapter.WithRoles())
+	}
+	searches := app.NewSavedSearches(mysqladapter.NewSavedSearchRepository(db), feed, app.LogNotifier{})
+	searches.UseDeadLetters(deadLetters)
+	workers.Go(context.Background(), "saved_searches", 2*time.Minute, singleton("saved_searches", searches.Run))
+	webhooks := app.NewWebhooks(mysqladapter.NewWebhookRepository(db), feed, cfg.URLPolicy(), cfg.Egress.Allowlist("webhooks"),
+		cfg.OutboundClient("webhooks", cfg.WebhookTimeout, 0))
+	webhooks.UseDeadLetters(deadLetters)
+	workers.Go(context.Background(), "webhooks", 2*time.Minute, singleton("webhooks", webhooks.Run))
+	events := app.NewEvents(feed)
+	events.Register("authors", authors.ApplyAll)
+	events.Register("saved_searches", searches.MatchAll)
+	events.Register("webhooks", webhooks.EnqueueAll)
+
+	publishers := mysqladapter.NewPublisherRepository(db, mysqladapter.WithAuditLog())
+	series := mysqladapter.NewSeriesRepository(db, mysqladapter.WithAuditLog())
+	sandbox, sandboxDB := openSandbox(cfg, repo, publishers, series)
+	status := app.NewStatus(2*time.Second, dependencyChecks(cfg, db, sandboxDB, workers)...)
+
+	h := httpadapter.NewHandler(svc, append(authOpts,
+		httpadapter.WithChangeFeed(feed),
+		httpadapter.WithEvents(events),
+		httpadapter.WithWebhooks(webhooks),
+		httpadapter.WithBookHistory(app.NewBookHistory(changeRepo, cfg.ChangesRetention)),
+		httpadapter.WithViewCounter(views),
+		httpadapter.WithAuthors(authors),
+		httpadapter.WithSavedSearches(searches),
+		httpadapter.WithTaxonomy(app.NewTaxonomyService(taxonomy)),
+		httpadapter.WithPublishers(app.NewPublisherService(publishers, repo, feed)),
+		httpadapter.WithSeries(app.NewSeriesService(series, repo, feed)),
+		httpadapter.WithDeadLetters(deadLetters),
+		httpadapter.WithWorkers(workers),
+		httpadapter.WithJobs(workers),
+		httpadapter.WithAPIKeys(apiKeys),
+		httpadapter.WithSync(app.NewSyncService(svc, changeRepo, cfg.HTTP.PageLimits())),
+		httpadapter.WithAliases(app.NewAliasService(repo, aliasRepo, feed)),
+		httpadapter.WithTags(app.NewTagService(repo, mysqladapter.NewTagRepository(db), feed)),
+		httpadapter.WithLoans(app.NewLoanService(mysqladapter.NewLoanRepository(db), cfg.LoanPeriod)),
+		httpadapter.WithHolds(app.NewHoldService(mysqladapter.NewHoldRepository(db), mysqladapter.NewUserRepository(db))),
+		httpadapter.WithReprice(app.NewRepriceService(repo, prices, feed)),
+		httpadapter.WithPriceHistory(app.NewPriceHistory(prices, repo)),
+		httpadapter.WithAuditTrail(app.NewAuditTrail(mysqladapter.NewAuditRepository(db))),
+		httpadapter.WithBulkTag(app.NewBulkTagService(repo, mysqladapter.NewCategoryRepository(db), mysqladapter.NewBulkJobRepository(db), feed, workers)),
+		httpadapter.WithMetadata(bookMetadata),
+		httpadapter.WithURLResolver(resolver),
+		httpadapter.WithURLExtractor(app.NewURLExtractor(cfg.URLPolicy(), urlFetch, cfg.ResolverUserAgent, robots, cleaner)),
+		httpadapter.WithCleanupProfiles(cleaner),
+		httpadapter.WithCleanupStats(cleanupStats),
+		httpadapter.WithTrackingRules(trackingRules),
+		httpadapter.WithListPreferences(app.NewListPreferences(mysqladapter.NewListPreferencesRepository(db), cfg.HTTP.PageLimits(), cfg.ListPreferencesTTL)),
+		httpadapter.WithSearchInsights(searchInsights),
+		httpadapter.WithSuggestions(app.NewSuggester(mysqladapter.NewSearchTermRepository(db), cfg.SuggestionTermsTTL)),
+		httpadapter.WithSearchRanking(app.NewSearchRankings(mysqladapter.NewSearchRankingRepository(db), cfg.SearchRankingTTL)),
+		httpadapter.WithSynonyms(synonyms),
+		httpadapter.WithURLHistory(app.NewURLHistory(mysqladapter.NewURLHistoryRepository(db))),
+		httpadapter.WithStatus(status),
+		httpadapter.WithCapabilities(deploymentCapabilities(cfg, true, sandbox != nil)),
+		httpadapter.WithPageLimits(cfg.HTTP.PageLimits()),
+		httpadapter.WithFieldPolicy(cfg.Fields()),
+	)...)
 
 	// Root router: mount your app and add Swagger UI
 	root := chi.NewRouter()
-	root.Mount("/", h.Router())
+	root.Use(httpadapter.AppVersion(buildinfo.Get().String()))
+	root.Method(http.MethodGet, "/healthz", httpadapter.Liveness{Workers: workers})
+	ready := &httpadapter.Readiness{}
+	root.Method(http.MethodGet, "/readyz", ready)
+	if cfg.HTTP.AdminPort == "" {
+		root.Handle("/debug/vars", expvar.Handler())
+	}
+	wrapAPI := apiLayers(cfg, apiKeys, verifier)
+	pools := map[string]io.Closer{cfg.DB.Name: db}
+	if sandbox != nil {
+		root.Mount("/sandbox", wrapAPI(httpadapter.Sandbox(sandbox.Router())))
+		pools[cfg.SandboxDBName] = sandboxDB
+	}
+	root.Mount("/", wrapAPI(withSpecValidation(h.Router())))
 
 	// Swagger UI at /swagger/index.html
 	// Optionally guard with an ENV check if you want it only in non-prod.
 	root.Get("/swagger/*", httpSwagger.WrapHandler)
 
-	addr := ":" + cfg.Port
+	// /readyz fails until the hottest books are cached, so a fresh replica
+	// doesn't take traffic cold.
+	go func() {
+		defer ready.SetReady(true)
+		if cache == nil || cfg.CacheWarmTopN <= 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.CacheWarmTimeout)
+		defer cancel()
+		start := time.Now()
+		n, err := app.WarmCache(ctx, cache, viewRepo, cfg.CacheWarmTopN)
+		if err != nil {
+			logger.Log.Error("cache warm-up incomplete", "books", n, "error", err)
+			return
+		}
+		logger.Log.Info("cache warmed", "books", n, "took", time.Since(start))
+	}()
+
+	serve(cfg, root, func(ctx context.Context) {
+		if unfinished := workers.Drain(ctx); len(unfinished) > 0 {
+			logger.Log.Error("workers still running at the drain deadline", "workers", unfinished)
+		}
+		// step down only now, so no other replica starts a job while ours
+		// finishes its round
+		stopElection()
+		<-electionDone
+	})
+	for name, db := range pools {
+		closeDB(name, db)
+	}
+	return 0
+}
+
+// serve listens until the server fails or SIGTERM / SIGINT arrives. Then it
+// stops accepting connections and gives in-flight requests ShutdownTimeout to
+// finish, while drain gets DrainTimeout to finish background work. Imports
+// still running are cut off by the drain and answer 503 on their own, so
+// both deadlines run side by side rather than one after the other. With
+// AdminPort set, the admin listener runs alongside and is closed at the end.
+func serve(cfg config, root http.Handler, drain func(ctx context.Context)) {
+	srv := &http.Server{
+		Addr:              ":" + cfg.HTTP.Port,
+		Handler:           root,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	build := buildinfo.Get()
 	logger.Log.Info("Application started",
 		slog.String("env", os.Getenv("APP_ENV")),
-		slog.String("addr", addr),
+		slog.String("commit", build.Commit),
+		slog.String("build_time", build.BuildTime),
+		slog.String("go_version", build.GoVersion),
+		slog.String("addr", srv.Addr),
+		slog.Bool("demo", cfg.Demo),
 	)
-	if err := http.ListenAndServe(addr, root); err != nil {
+	if cfg.HTTP.AdminPort != "" {
+		admin := &http.Server{Addr: ":" + cfg.HTTP.AdminPort, Handler: adminRouter(), ReadHeaderTimeout: 10 * time.Second}
+		logger.Log.Info("admin listener started", "addr", admin.Addr)
+		go func() {
+			if err := admin.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				logger.Log.Error("admin listener exited", "error", err)
+			}
+		}()
+		defer admin.Close()
+	}
+	failed := make(chan error, 1)
+	go func() { failed <- srv.ListenAndServe() }()
+	stop, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	select {
+	case err := <-failed:
 		logger.Log.Error("http server exited", "error", err)
+		return
+	case <-stop.Done():
+	}
+	// a second signal kills the process the default way
+	cancel()
+
+	logger.Log.Info("shutting down", "timeout", cfg.HTTP.ShutdownTimeout, "drain_timeout", cfg.HTTP.DrainTimeout)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			logger.Log.Error("requests still in flight at the shutdown deadline, closing them", "error", err)
+			_ = srv.Close()
+		}
+	}()
+	if drain != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.DrainTimeout)
+		defer cancel()
+		drain(ctx)
+	}
+	<-stopped
+	logger.Log.Info("drained")
+}
+
+// adminRouter serves the runtime's insides: expvar on /debug/vars and the
+// pprof profiles under /debug/pprof/. It is only ever bound to AdminPort,
+// which must not be reachable from outside the cluster.
+func adminRouter() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /debug/vars", expvar.Handler())
+	mux.HandleFunc("GET /debug/pprof/", pprof.Index)
+	mux.HandleFunc("GET /debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("GET /debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("GET /debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("GET /debug/pprof/trace", pprof.Trace)
+	return mux
+}
+
+// closeDB closes a connection pool once nothing uses it any more.
+func closeDB(name string, db io.Closer) {
+	if err := db.Close(); err != nil {
+		logger.Log.Error("close db", "db", name, "error", err)
+	}
+}
+
+// runDemo serves the API from an in-memory store seeded with fixtures and a
+// clock stopped at DemoClock, without touching MySQL. Replaying the same
+// requests against a fresh process gives byte-identical responses, which is
+// what recorded demos and screenshot tests need. Background jobs (view
+// counting, projections, saved search matching) don't run.
+func runDemo(cfg config) {
+	fixtures := memory.DefaultFixtures
+	if cfg.DemoFixtures != "" {
+		b, err := os.ReadFile(cfg.DemoFixtures)
+		if err != nil {
+			logger.Log.Error("read demo fixtures", "path", cfg.DemoFixtures, "error", err)
+			os.Exit(1)
+		}
+		fixtures = b
+	}
+	books, err := memory.LoadFixtures(bytes.NewReader(fixtures))
+	if err != nil {
+		logger.Log.Error("load demo fixtures", "error", err)
+		os.Exit(1)
+	}
+	for i, b := range books {
+		// As BackfillISBN13 does for MySQL, so lookups by either form work.
+		if isbn13, isbn10, err := app.CanonicalISBN(b.ISBN); err == nil {
+			books[i].ISBN, books[i].ISBN10 = isbn13, isbn10
+		}
+	}
+	now := func() time.Time { return cfg.DemoClock }
+	app.SetClock(now)
+
+	store := memory.NewStore()
+	store.Seed(books)
+	repo, aliasRepo, changeRepo := store.Books(), store.Aliases(), store.Changes()
+	feed := app.NewChangeFeed(changeRepo)
+	svc := app.NewBookService(repo, app.WithChangeFeed(feed), app.WithAliases(aliasRepo))
+	h := httpadapter.NewHandler(svc,
+		httpadapter.WithClock(now),
+		httpadapter.WithChangeFeed(feed),
+		httpadapter.WithSync(app.NewSyncService(svc, changeRepo, cfg.HTTP.PageLimits())),
+		httpadapter.WithAliases(app.NewAliasService(repo, aliasRepo, feed)),
+		httpadapter.WithCapabilities(deploymentCapabilities(cfg, false, false)),
+		httpadapter.WithPageLimits(cfg.HTTP.PageLimits()),
+	)
+
+	root := chi.NewRouter()
+	root.Use(httpadapter.AppVersion(buildinfo.Get().String()))
+	root.Get("/healthz", httpadapter.Healthz)
+	ready := &httpadapter.Readiness{}
+	ready.SetReady(true)
+	root.Method(http.MethodGet, "/readyz", ready)
+	root.Mount("/", apiLayers(cfg, nil, nil)(withSpecValidation(h.Router())))
+	root.Get("/swagger/*", httpSwagger.WrapHandler)
+	logger.Log.Info("demo mode", "books", len(books), "clock", cfg.DemoClock)
+	serve(cfg, root, nil)
+}
+
+// loadCleanupProfiles offers the profiles of the YAML file at path next to
+// the built-in ones. A file that can't be used is logged and skipped, which
+// leaves the built-in profiles.
+func loadCleanupProfiles(path string) *app.URLCleaner {
+	builtIn, _ := app.NewURLCleaner(nil)
+	if path == "" {
+		return builtIn
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		logger.Log.Error("cleanup profiles not loaded", "path", path, "error", err)
+		return builtIn
+	}
+	defer f.Close()
+	profiles, err := urlclean.LoadProfiles(f)
+	if err == nil {
+		var c *app.URLCleaner
+		if c, err = app.NewURLCleaner(profiles); err == nil {
+			logger.Log.Info("cleanup profiles loaded", "path", path, "profiles", len(profiles))
+			return c
+		}
+	}
+	logger.Log.Error("cleanup profiles not loaded", "path", path, "error", err)
+	return builtIn
+}
+
+// backfillPriceCents fills price_cents for existing rows once dual writes are
+// on, then logs how far the column is from being safe to read.
+func backfillPriceCents(db *sqlx.DB, d *mysqladapter.DualWrite) {
+	if !d.WritesNew() {
+		return
+	}
+	ctx := context.Background()
+	n, err := mysqladapter.BackfillPriceCents(ctx, db, d, 500)
+	if err != nil {
+		logger.Log.Error("price_cents backfill failed", "rows", n, "error", err)
+		return
+	}
+	pending, mismatched, err := mysqladapter.CheckPriceCents(ctx, db, d)
+	if err != nil {
+		logger.Log.Error("price_cents check failed", "error", err)
+		return
+	}
+	logger.Log.Info("price_cents backfill done", "rows", n, "pending", pending, "mismatched", mismatched, "phase", d.Phase().String())
+}
+
+// deploymentCapabilities describes what cfg sets up around the handler for
+// GET /.well-known/api-capabilities; apiKeys and sandbox tell whether keys
+// are checked and the sandbox is mounted.
+func deploymentCapabilities(cfg config, apiKeys, sandbox bool) domain.Capabilities {
+	c := domain.Capabilities{Version: buildinfo.Get().String(), Search: domain.SearchCapabilities{Backend: "mysql"}}
+	if cfg.Demo {
+		c.Search.Backend = "memory"
+		c.Features = append(c.Features, "demo")
+	}
+	if cfg.TrustIdentityHeaders {
+		c.Auth.Modes = append(c.Auth.Modes, "proxy_headers")
+	}
+	if apiKeys && cfg.APIKeyAuth != "" {
+		c.Auth.Modes = append(c.Auth.Modes, "api_key")
+		c.Auth.APIKeyRequired = cfg.APIKeyAuth
+	}
+	for name, on := range map[string]bool{
+		"compression":      cfg.GzipLevel > 0,
+		"envelope":         cfg.Envelope,
+		"event_publishing": cfg.EventPublisher != "" && !cfg.Demo,
+		"nats":             cfg.NatsURL != "" && !cfg.Demo,
+		"rate_limit":       cfg.RateLimit > 0,
+		"sandbox":          sandbox,
+	} {
+		if on {
+			c.Features = append(c.Features, name)
+		}
 	}
+	return c
 }
 
-type config struct {
-	User   string
-	Pass   string
-	Host   string
-	PortDB string
-	DBName string
-	Params string
-	Port   string
+// eventPublisher returns the broker cfg publishes book events to, or nil
+// when they aren't published.
+func eventPublisher(cfg config) ports.EventPublisher {
+	switch cfg.EventPublisher {
+	case "nats":
+		return natsadapter.NewPublisher(cfg.NatsURL, cfg.EventTopic)
+	case "kafka":
+		return kafkaadapter.NewProducer(cfg.KafkaBrokers, cfg.EventTopic)
+	}
+	return nil
 }
 
-func loadConfig() config {
-	return config{
-		User:   os.Getenv("MYSQL_USER"),
-		Pass:   os.Getenv("MYSQL_PASSWORD"),
-		Host:   getEnv("MYSQL_HOST", "db"),
-		PortDB: getEnv("MYSQL_PORT", "3306"),
-		DBName: getEnv("MYSQL_DATABASE", "booksdb"),
-		Params: getEnv("MYSQL_PARAMS", "parseTime=true&charset=utf8mb4&loc=UTC"),
-		Port:   getEnv("PORT", "8080"),
+// dependencyChecks are what GET /status checks: the databases, the NATS
+// and Kafka brokers when configured, and the background workers.
+func dependencyChecks(cfg config, db, sandboxDB *sqlx.DB, workers *app.Workers) []app.DependencyCheck {
+	checks := []app.DependencyCheck{{Name: "mysql", Check: db.PingContext}}
+	if sandboxDB != nil {
+		checks = append(checks, app.DependencyCheck{Name: "mysql_sandbox", Check: sandboxDB.PingContext})
+	}
+	if cfg.NatsURL != "" {
+		checks = append(checks, app.DependencyCheck{Name: "nats", Check: func(ctx context.Context) error {
+			return natsadapter.Ping(ctx, cfg.NatsURL)
+		}})
 	}
+	if cfg.EventPublisher == "kafka" {
+		checks = append(checks, app.DependencyCheck{Name: "kafka", Check: func(ctx context.Context) error {
+			return kafkaadapter.Ping(ctx, cfg.KafkaBrokers)
+		}})
+	}
+	checks = append(checks, app.DependencyCheck{Name: "workers", Check: func(ctx context.Context) error {
+		if bad := workers.Unhealthy(); len(bad) > 0 {
+			return fmt.Errorf("stalled or stopped: %s", strings.Join(bad, ", "))
+		}
+		return nil
+	}})
+	return checks
 }
 
-func (c config) DSN() string {
-	// user:pass@tcp(host:port)/dbname?params
-	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?%s", c.User, c.Pass, c.Host, c.PortDB, c.DBName, c.Params)
+// openSandbox builds the partner sandbox: the same API over its own database,
+// reset to a copy of the production catalogue on start and every
+// SandboxResetEvery. It returns nil when no sandbox database is configured.
+func openSandbox(cfg config, catalogue ports.BookReader, publishers ports.PublisherRepository, series ports.SeriesRepository) (*httpadapter.Handler, *sqlx.DB) {
+	if cfg.SandboxDBName == "" {
+		return nil, nil
+	}
+	if cfg.SandboxDBName == cfg.DB.Name {
+		logger.Log.Error("sandbox disabled: SANDBOX_MYSQL_DATABASE must differ from MYSQL_DATABASE")
+		return nil, nil
+	}
+	sc := cfg
+	sc.DB.Name = cfg.SandboxDBName
+	db, err := sqlx.Open("mysql", sc.DSN())
+	if err != nil {
+		logger.Log.Error("open sandbox db", "error", err)
+		return nil, nil
+	}
+	db.SetMaxOpenConns(2)
+	db.SetMaxIdleConns(2)
+	db.SetConnMaxLifetime(10 * time.Minute)
+
+	go func() {
+		for {
+			ctx := context.Background()
+			books, err := catalogue.List(ctx, ports.ListFilter{})
+			c := mysqladapter.SandboxCopy{Books: books}
+			if err == nil {
+				c.Publishers, err = publishers.List(ctx)
+			}
+			if err == nil {
+				c.Series, err = series.List(ctx)
+			}
+			if err == nil {
+				err = mysqladapter.ResetSandbox(ctx, db, c)
+			}
+			if err != nil {
+				logger.Log.Error("sandbox reset failed", "error", err)
+			} else {
+				logger.Log.Info("sandbox reset", "books", len(c.Books), "publishers", len(c.Publishers), "series", len(c.Series))
+			}
+			if cfg.SandboxResetEvery <= 0 {
+				return
+			}
+			time.Sleep(cfg.SandboxResetEvery)
+		}
+	}()
+
+	repo := mysqladapter.NewBookRepository(db, mysqladapter.WithAuditLog())
+	changeRepo := mysqladapter.NewChangeRepository(db)
+	feed := app.NewChangeFeed(changeRepo)
+	aliasRepo := mysqladapter.NewAliasRepository(db)
+	prices := mysqladapter.NewPriceRepository(db, mysqladapter.WithAuditLog())
+	svcOpts := []app.Option{app.WithChangeFeed(feed), app.WithAliases(aliasRepo), app.WithPriceHistory(prices)}
+	if cfg.QueryGuardMinBooks > 0 {
+		svcOpts = append(svcOpts, app.WithQueryGuard(app.NewQueryGuard(mysqladapter.NewBookStats(db), cfg.QueryGuardMinBooks)))
+	}
+	svc := app.NewBookService(repo, svcOpts...)
+	opts := []httpadapter.Option{
+		httpadapter.WithChangeFeed(feed),
+		httpadapter.WithSync(app.NewSyncService(svc, changeRepo, cfg.HTTP.PageLimits())),
+		httpadapter.WithAliases(app.NewAliasService(repo, aliasRepo, feed)),
+		httpadapter.WithReprice(app.NewRepriceService(repo, prices, feed)),
+		httpadapter.WithPriceHistory(app.NewPriceHistory(prices, repo)),
+		httpadapter.WithAuditTrail(app.NewAuditTrail(mysqladapter.NewAuditRepository(db))),
+		httpadapter.WithPageLimits(cfg.HTTP.PageLimits()),
+		httpadapter.WithFieldPolicy(cfg.Fields()),
+	}
+	if cfg.EnforceRoles {
+		opts = append(opts, httpadapter.WithRoles())
+	}
+	return httpadapter.NewHandler(svc, opts...), db
 }
 
-func getEnv(k, def string) string {
-	if v := os.Getenv(k); v != "" {
-		return v
+// metadataLookup asks the catalogues of METADATA_PROVIDERS through an
+// outbound client that reuses their answers for METADATA_CACHE_TTL; nil when
+// lookups are disabled.
+func metadataLookup(cfg config) ports.MetadataService {
+	client := cfg.OutboundClient("metadata", cfg.MetadataTimeout, cfg.MetadataCacheTTL)
+	var providers []ports.MetadataProvider
+	for _, name := range cfg.MetadataProviders {
+		switch name {
+		case "openlibrary":
+			providers = append(providers, metadata.NewOpenLibrary(client, metadata.OpenLibraryURL, cfg.ResolverUserAgent))
+		case "googlebooks":
+			providers = append(providers, metadata.NewGoogleBooks(client, metadata.GoogleBooksURL, cfg.GoogleBooksAPIKey, cfg.ResolverUserAgent))
+		}
 	}
-	return def
+	if len(providers) == 0 {
+		return nil
+	}
+	return app.NewMetadataLookup(cfg.MetadataTimeout, providers...)
 }
 
 func ping(db *sqlx.DB) error {
@@ -125,6 +705,43 @@ func ping(db *sqlx.DB) error {
 	return fmt.Errorf("unable to connect to DB after retries")
 }
 
+// apiLayers returns the cross-cutting layers that sit outside the documented
+// API: per-client rate limiting, response compression, caller identity, API
+// keys (when keys is set and API_KEY_AUTH asks for them), impersonation and
+// the optional response envelope. They wrap the spec validator, so it always
+// checks the raw responses. Every API mounted with the same layers shares one
+// rate limit per client.
+func apiLayers(cfg config, keys ports.APIKeyService, auth ports.AuthService) func(http.Handler) http.Handler {
+	limit := httpadapter.RateLimit(cfg.RateLimit, cfg.RateBurst)
+	authKeys := func(next http.Handler) http.Handler { return next }
+	if keys != nil && cfg.APIKeyAuth != "" {
+		authKeys = httpadapter.APIKeyAuth(keys, cfg.APIKeyAuth == "all")
+	}
+	bearer := func(next http.Handler) http.Handler { return next }
+	if auth != nil {
+		bearer = httpadapter.BearerAuth(auth)
+	}
+	return func(next http.Handler) http.Handler {
+		return httpadapter.RequestID(httpadapter.CORS(cfg.CORS.CORSOptions())(middleware.RealIP(limit(httpadapter.Compress(cfg.GzipLevel)(
+			httpadapter.Identify(cfg.TrustIdentityHeaders)(bearer(authKeys(httpadapter.Impersonate(auth)(httpadapter.Envelope(cfg.Envelope)(next))))))))))
+	}
+}
+
+// withSpecValidation wraps the API router with request/response validation
+// against the swagger doc when OPENAPI_VALIDATE=true. Never enabled in production.
+func withSpecValidation(next http.Handler) http.Handler {
+	if os.Getenv("OPENAPI_VALIDATE") != "true" || os.Getenv("APP_ENV") == "production" {
+		return next
+	}
+	v, err := httpadapter.NewSpecValidator([]byte(docs.SwaggerInfo.ReadDoc()))
+	if err != nil {
+		logger.Log.Error("openapi validator disabled", "error", err)
+		return next
+	}
+	logger.Log.Info("openapi validation enabled")
+	return v.Middleware(next)
+}
+
 func splitAndTrim(s, sep string) []string {
 	var out []string
 	for _, p := range strings.Split(s, sep) {
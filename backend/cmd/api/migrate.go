@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	mysqladapter "github.com/gerry-sabar/byfood/internal/adapters/mysql"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/migrations"
+	"github.com/jmoiron/sqlx"
+)
+
+const migrateUsage = `usage: books-api migrate [command]
+
+  up            apply every pending migration (the default)
+  down [n]      revert the last n applied migrations (default 1)
+  status        list the migrations and when they were applied
+  force <n>     record migrations up to n as applied, without running them`
+
+// newMigrator reads the migrations embedded in the binary.
+func newMigrator(db *sqlx.DB) (*mysqladapter.Migrator, error) {
+	migs, err := mysqladapter.LoadMigrations(migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+	return mysqladapter.NewMigrator(db, migs), nil
+}
+
+// migrateUp applies the pending migrations, for MIGRATE_ON_START.
+func migrateUp(ctx context.Context, db *sqlx.DB) error {
+	m, err := newMigrator(db)
+	if err != nil {
+		return err
+	}
+	done, err := m.Up(ctx)
+	if err == nil {
+		logger.Log.Info("schema up to date", "applied", len(done))
+	}
+	return err
+}
+
+// runMigrate runs the migrate subcommand and returns the exit code.
+func runMigrate(cfg config, args []string) int {
+	cmd := "up"
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
+	n := 1
+	switch cmd {
+	case "up", "status":
+	case "down", "force":
+		if cmd == "force" && len(args) == 0 {
+			fmt.Fprintln(os.Stderr, migrateUsage)
+			return 2
+		}
+		if len(args) > 0 {
+			v, err := strconv.Atoi(args[0])
+			if err != nil || v < 0 {
+				fmt.Fprintln(os.Stderr, migrateUsage)
+				return 2
+			}
+			n = v
+		}
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	db, err := sqlx.Open("mysql", cfg.DSN())
+	if err != nil {
+		logger.Log.Error("open db", "error", err)
+		return 1
+	}
+	defer closeDB(cfg.DB.Name, db)
+	if err := ping(db); err != nil {
+		logger.Log.Error("db ping", "error", err)
+		return 1
+	}
+	m, err := newMigrator(db)
+	if err != nil {
+		logger.Log.Error("load migrations", "error", err)
+		return 1
+	}
+	ctx := context.Background()
+	switch cmd {
+	case "up":
+		_, err = m.Up(ctx)
+	case "down":
+		_, err = m.Down(ctx, n)
+	case "force":
+		err = m.Force(ctx, int64(n))
+	case "status":
+		err = printMigrationStatus(ctx, m)
+	}
+	if err != nil {
+		logger.Log.Error("migrate "+cmd, "error", err)
+		return 1
+	}
+	return 0
+}
+
+func printMigrationStatus(ctx context.Context, m *mysqladapter.Migrator) error {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+	for _, s := range status {
+		applied := "pending"
+		if s.AppliedAt != nil {
+			applied = s.AppliedAt.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, applied)
+	}
+	return w.Flush()
+}
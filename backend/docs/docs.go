@@ -15,26 +15,52 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/books/": {
+        "/.well-known/api-capabilities": {
             "get": {
-                "description": "Returns all books",
+                "description": "The optional features enabled, how book search runs, the accepted ways to identify, pagination limits and the book file formats, derived from the configuration. Clients can adapt to them instead of hardcoding environment differences.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "books"
+                    "health"
                 ],
-                "summary": "List books",
+                "summary": "What this deployment offers",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Capabilities"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/api-keys/": {
+            "get": {
+                "description": "Keys themselves are never shown again after they are issued. Requires the admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List API keys",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/domain.Book"
+                                "$ref": "#/definitions/domain.APIKey"
                             }
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -44,6 +70,7 @@ const docTemplate = `{
                 }
             },
             "post": {
+                "description": "The response carries the key in the clear; store it, it can't be retrieved later. Requires the admin scope.",
                 "consumes": [
                     "application/json"
                 ],
@@ -51,17 +78,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "books"
+                    "admin"
                 ],
-                "summary": "Create book",
+                "summary": "Issue an API key",
                 "parameters": [
                     {
-                        "description": "New book",
+                        "description": "Key name and scopes",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/ports.CreateBookInput"
+                            "$ref": "#/definitions/ports.IssueAPIKeyInput"
                         }
                     }
                 ],
@@ -69,7 +96,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/domain.Book"
+                            "$ref": "#/definitions/ports.IssuedAPIKey"
                         }
                     },
                     "400": {
@@ -78,39 +105,108 @@ const docTemplate = `{
                             "$ref": "#/definitions/ports.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
                     "422": {
                         "description": "Unprocessable Entity",
                         "schema": {
                             "$ref": "#/definitions/http.validationPayload"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/books/{id}/": {
+        "/admin/api-keys/{id}": {
+            "delete": {
+                "description": "Requests with the key are refused from now on. Requires the admin scope.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Revoke an API key",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "API key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/dlq/": {
             "get": {
+                "description": "Newest first. Requires the admin scope.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "books"
+                    "admin"
                 ],
-                "summary": "Get a book",
+                "summary": "List failed async work",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only dead letters of this kind, e.g. search_notification or catalog_event",
+                        "name": "kind",
+                        "in": "query"
+                    },
                     {
                         "minimum": 1,
                         "type": "integer",
-                        "description": "Book ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Max dead letters (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Book"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.DeadLetter"
+                            }
                         }
                     },
                     "400": {
@@ -119,8 +215,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/ports.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/ports.ErrorResponse"
                         }
@@ -132,42 +228,33 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/admin/dlq/{id}": {
+            "get": {
+                "description": "Requires the admin scope.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "books"
+                    "admin"
                 ],
-                "summary": "Update a book",
+                "summary": "Get a dead letter",
                 "parameters": [
                     {
                         "minimum": 1,
                         "type": "integer",
-                        "description": "Book ID",
+                        "description": "Dead letter ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Partial update",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/ports.UpdateBookInput"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Book"
+                            "$ref": "#/definitions/domain.DeadLetter"
                         }
                     },
                     "400": {
@@ -176,30 +263,88 @@ const docTemplate = `{
                             "$ref": "#/definitions/ports.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/ports.ErrorResponse"
                         }
                     },
-                    "422": {
-                        "description": "Unprocessable Entity",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/http.validationPayload"
+                            "$ref": "#/definitions/ports.ErrorResponse"
                         }
                     }
                 }
             },
             "delete": {
+                "description": "Requires the admin scope.",
                 "tags": [
-                    "books"
+                    "admin"
                 ],
-                "summary": "Delete a book",
+                "summary": "Discard failed async work",
                 "parameters": [
                     {
                         "minimum": 1,
                         "type": "integer",
-                        "description": "Book ID",
+                        "description": "Dead letter ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/dlq/{id}/retry": {
+            "post": {
+                "description": "On success the dead letter is removed. If the work fails again it stays, with the new error and one more attempt, and the call answers 502. Requires the admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Run failed async work again",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Dead letter ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -215,18 +360,71 @@ const docTemplate = `{
                             "$ref": "#/definitions/ports.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/ports.ErrorResponse"
                         }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/url/cleanup": {
+        "/admin/events/consumers": {
+            "get": {
+                "description": "Requires the admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List the consumers events can be replayed to",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/events/replay": {
             "post": {
-                "description": "operation: \"redirection\" | \"canonical\" | \"all\"",
+                "description": "Hands the events after ` + "`" + `since` + "`" + `, up to the newest one when the call starts, to ` + "`" + `consumer` + "`" + ` again, e.g. ` + "`" + `authors` + "`" + ` to rebuild the author pages or ` + "`" + `saved_searches` + "`" + ` to notify searches of books created before them. Consumers tolerate events they have seen, and keep following new events from where they were. The call returns once the replay is done; one that fails stops after the offset in the error, from where it can be resumed. A consumer replays once at a time per replica (409, code ` + "`" + `replay_running` + "`" + `). Requires the admin scope.",
                 "consumes": [
                     "application/json"
                 ],
@@ -234,17 +432,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tools"
+                    "admin"
                 ],
-                "summary": "Normalize/cleanup a URL",
+                "summary": "Replay past events to a consumer",
                 "parameters": [
                     {
-                        "description": "Cleanup payload",
+                        "description": "Replay",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/http.cleanupRequest"
+                            "$ref": "#/definitions/ports.ReplayInput"
                         }
                     }
                 ],
@@ -252,7 +450,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/http.cleanupResponse"
+                            "$ref": "#/definitions/ports.ReplayResult"
                         }
                     },
                     "400": {
@@ -260,64 +458,7804 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/ports.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "domain.Book": {
-            "type": "object",
-            "properties": {
-                "author": {
-                    "type": "string"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "isbn": {
+        },
+        "/admin/search/insights": {
+            "get": {
+                "description": "Book searches (GET /books/?q=, first pages only) over the last days days (today included): totals, the share that found nothing and the clicks per search reported to POST /search/feedback, the top most searched queries and the top queries that most often found nothing. Queries are folded for case and accents. Counts are flushed every few seconds, so the latest searches may be missing. Requires the admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Search query report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 7,
+                        "description": "Days to report on, 1-90",
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Queries to list, 1-100",
+                        "name": "top",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SearchInsights"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/search/ranking/": {
+            "get": {
+                "description": "The default ranking and every tenant with its own. Searches (GET /books/ with q and no sort) rank books by score: the title weight when q is in the title or an alias, plus the author weight when it is in the author, plus the recency boost divided by one plus the book's age in years. Equal scores go newest first. Requires the admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List search rankings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ports.SearchRankings"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/search/ranking/{tenant}": {
+            "get": {
+                "description": "The tenant is the identity searches run as: an API key (key:NAME) or a user. 404 when it ranks with the defaults. Requires the admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get a tenant's search ranking",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant",
+                        "name": "tenant",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SearchRanking"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Weights range from 0 to 10; omitted ones take their default. Searches use the new ranking right away on this server, and within a minute on the others. Requires the admin scope.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Replace a tenant's search ranking",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant",
+                        "name": "tenant",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Weights",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ports.PutSearchRankingInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SearchRanking"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "The tenant's searches go back to the default ranking. Requires the admin scope.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Delete a tenant's search ranking",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant",
+                        "name": "tenant",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/search/synonyms/": {
+            "get": {
+                "description": "The synonym dictionary, oldest group first. Searches (GET /books/ with q, and GET /books/search) also find the books matching q with each term of a group among its words swapped for the others: with \"programming\" and \"coding\" in a group, \"programming in go\" finds \"Coding in Go\" too. Terms match whole words, ignoring case and accents; exact=true searches don't use synonyms. Requires the admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List synonym groups",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.SynonymGroup"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "A group has 2 to 20 terms of up to 64 characters, which must differ ignoring case and accents. A term belongs to one group only: 409 with code \"synonym_taken\" when another group has one. Searches use the change right away on this server, and within a minute on the others. Requires the admin scope.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Add a synonym group",
+                "parameters": [
+                    {
+                        "description": "Terms",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ports.SynonymInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SynonymGroup"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/search/synonyms/{id}": {
+            "get": {
+                "description": "Requires the admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get a synonym group",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Synonym group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SynonymGroup"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Terms follow the rules of POST /admin/search/synonyms/. Requires the admin scope.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Replace the terms of a synonym group",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Synonym group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Terms",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ports.SynonymInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SynonymGroup"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Its terms go back to matching only themselves. Requires the admin scope.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Delete a synonym group",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Synonym group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/workers/": {
+            "get": {
+                "description": "State and last heartbeat of each background worker of the replica serving the request. Requires the admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List background workers",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.WorkerStatus"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/workers/{name}/pause": {
+            "post": {
+                "description": "The worker stops after its current round, on every replica within a few seconds, until resumed. Requires the admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Pause a background worker",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Worker name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WorkerStatus"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/workers/{name}/resume": {
+            "post": {
+                "description": "Requires the admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Resume a paused background worker",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Worker name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WorkerStatus"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Returns a signed JWT. Send it as ` + "`" + `Authorization: Bearer \u003ctoken\u003e` + "`" + ` to act as the user until it expires.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Log in",
+                "parameters": [
+                    {
+                        "description": "Email and password",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ports.Credentials"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ports.AuthToken"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/register": {
+            "post": {
+                "description": "The password needs at least 8 characters. New users have no scopes. Log in with POST /auth/login to get a token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Create a user account",
+                "parameters": [
+                    {
+                        "description": "Email and password",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ports.Credentials"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/authors/{id}/summary": {
+            "get": {
+                "description": "The author's books, publication year span and price range from a precomputed read model. The id is the author slug, also returned as ` + "`" + `author_id` + "`" + ` on books. Updates appear shortly after the books change.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authors"
+                ],
+                "summary": "Author page summary",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "gabriel-garcia-marquez",
+                        "description": "Author slug",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AuthorSummary"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/": {
+            "get": {
+                "description": "Returns books newest first, one page of limit books at a time. Callers with list preferences (PUT /me/list-preferences/) get their own sort and page size when they send none. Searches (q) without a sort rank the best matches first where search ranking is enabled, using the caller's ranking (see /admin/search/ranking/), also find books matching the synonyms of their terms where synonyms are enabled (see /admin/search/synonyms/), and their first pages are counted for GET /admin/search/insights where search insights are enabled. A search that finds nothing gets the titles and authors it may have meant in X-Search-Suggestions (meta.suggestions when enveloped) where suggestions are enabled. Without limit a page holds the server's default page size (all books when none is configured); a limit above the maximum page size gets 400 with code \"page_size_exceeded\". On large catalogues, filters no index serves (q, year and price on their own, or exact=true) get 400 with code \"query_too_expensive\" unless author or isbn narrows them. GET /.well-known/api-capabilities gives both sizes. Filters combine with AND; year and price bounds are inclusive. X-Total-Count always carries the number of matching books.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "List books",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search title and author, ignoring case and accents",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Whole author name, ignoring case and accents",
+                        "name": "author",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISBN, ignoring hyphens and spaces",
+                        "name": "isbn",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tag, ignoring case and accents",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Match q, author and isbn case-, accent- and hyphen-sensitively",
+                        "name": "exact",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Earliest publication year",
+                        "name": "year_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Latest publication year",
+                        "name": "year_to",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "number",
+                        "description": "Lowest price",
+                        "name": "price_min",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "number",
+                        "description": "Highest price",
+                        "name": "price_max",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "-id",
+                            "title",
+                            "-title",
+                            "author",
+                            "-author",
+                            "price",
+                            "-price",
+                            "publication_year",
+                            "-publication_year"
+                        ],
+                        "type": "string",
+                        "description": "Sort field, prefixed with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Locale used to order title or author, e.g. de, sv, tr",
+                        "name": "collation",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Page size, up to the maximum page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "Books to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Every book as it was at this RFC 3339 instant, rebuilt from the change log; can't be combined with filters, sorting or paging",
+                        "name": "as_of",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/presenter.BookView"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "RFC 8288 next/prev page links"
+                            },
+                            "X-Search-Suggestions": {
+                                "type": "string",
+                                "description": "When q found nothing: titles and authors it may have meant, best first, percent-encoded and comma-separated"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Number of matching books across all pages"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Create book",
+                "parameters": [
+                    {
+                        "description": "New book",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ports.CreateBookInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/presenter.BookView"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Book version, for If-Match"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/bulk-tag": {
+            "post": {
+                "description": "Adds and removes categories on every book matching the filter, which works as in POST /books/reprice. Categories are given by name or slug and stored as slugs, e.g. \"Science Fiction\" as science-fiction. Runs as a preview unless ` + "`" + `dry_run` + "`" + ` is false: the preview counts the books that would change and lists the first 100 with their categories after. Applying starts a background job, changing 100 books per transaction, and answers 202 with the job; poll its Location for progress. Every changed book gets a change-log entry.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Bulk add and remove book categories",
+                "parameters": [
+                    {
+                        "description": "Filter and categories",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ports.BulkTagRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Preview, or nothing to change",
+                        "schema": {
+                            "$ref": "#/definitions/ports.BulkTagResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Job started",
+                        "schema": {
+                            "$ref": "#/definitions/ports.BulkTagResponse"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the job's progress"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/bulk-tag/{job}": {
+            "get": {
+                "description": "Status is running until the job ends as done, failed (with the error) or interrupted by a shutdown. Re-running an interrupted or failed job is safe: books it already changed are left as they are.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Progress of a bulk tag job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "job",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.BulkJob"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/changes": {
+            "get": {
+                "description": "Returns changes after ` + "`" + `since` + "`" + `, ordered by cursor. When there are none yet the request blocks up to ` + "`" + `wait` + "`" + ` (max 60s) and returns an empty list on timeout. Pass the returned cursor as the next ` + "`" + `since` + "`" + `.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Long-poll the books change log",
+                "parameters": [
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "Cursor of the last change seen",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Max time to block, e.g. 30s",
+                        "name": "wait",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 500,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Max changes to return (default 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ChangesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/export": {
+            "get": {
+                "description": "Streams every book as CSV (default) or as an Excel workbook with ` + "`" + `format=xlsx` + "`" + `. For CSV in European locales use ` + "`" + `delimiter=semicolon\u0026decimal=comma\u0026bom=true` + "`" + ` (or ` + "`" + `delimiter=%3B` + "`" + `).",
+                "produces": [
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Export books as CSV or XLSX",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "xlsx"
+                        ],
+                        "type": "string",
+                        "description": "csv or xlsx (default csv)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "CSV field delimiter: comma, semicolon, pipe or tab (default comma)",
+                        "name": "delimiter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "CSV decimal separator: point or comma (default point)",
+                        "name": "decimal",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Prefix the CSV with a UTF-8 byte order mark",
+                        "name": "bom",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Export file",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/import": {
+            "post": {
+                "description": "Upload a file in the multipart field ` + "`" + `file` + "`" + `: a CSV with a header row naming at least title, author, isbn, price and publication_year (the export's format, comma or semicolon separated; other columns are ignored), or a JSON array of books. The format follows the file extension, then the part's content type. Every row is validated like POST /books; rows with an ISBN that already exists or appears earlier in the file are skipped. The response lists the outcome of every row. If the server shuts down mid-import, the rows not yet reached are ` + "`" + `requeued` + "`" + ` as a ` + "`" + `book_import` + "`" + ` dead letter; new imports are refused with 503 while it drains.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Import books from CSV or JSON",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV or JSON file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ImportResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/isbn/{isbn}": {
+            "get": {
+                "description": "Finds the book with an ISBN-10 or ISBN-13, with or without hyphens and spaces. Books store the ISBN-13 and, when it starts with 978, the ISBN-10 as isbn10, so either form finds them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Get a book by ISBN",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ISBN-10 or ISBN-13",
+                        "name": "isbn",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/presenter.BookView"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Book version, for If-Match"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/lookup": {
+            "post": {
+                "description": "Asks the external catalogues (OpenLibrary, then Google Books) for the title, author and publication year of an ISBN-10 or ISBN-13, to pre-fill a new book. Nothing is stored; POST /books with ` + "`" + `autofill: true` + "`" + ` does the same while creating. Each catalogue gets METADATA_TIMEOUT, and one that fails is skipped; 503 means none could be reached.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Look up book metadata by ISBN",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ISBN-10 or ISBN-13",
+                        "name": "isbn",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.BookMetadata"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/reprice": {
+            "post": {
+                "description": "Applies a rule (percent, then amount, then rounding to an ending such as 0.99) to every book matching the filter. Runs as a preview unless ` + "`" + `dry_run` + "`" + ` is false; applied changes are written in one transaction with price-history entries sharing a batch_id.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Bulk re-price books",
+                "parameters": [
+                    {
+                        "description": "Filter and rule",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ports.RepriceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ports.RepriceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/": {
+            "get": {
+                "description": "With as_of, returns the book as it was at that instant, rebuilt from the change log, without an ETag. 404 means it didn't exist then; 410 with code \"beyond_history\" means the change log no longer reaches back that far.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Get a book",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "RFC 3339 instant to read the book at",
+                        "name": "as_of",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/presenter.BookView"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Book version, for If-Match"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Send the version last read, as ` + "`" + `version` + "`" + ` in the body or as the ETag in If-Match, to get 409 instead of overwriting someone else's write.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Update a book",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag (version) the update is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Partial update",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ports.UpdateBookInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/presenter.BookView"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Book version, for If-Match"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/http.conflictPayload"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "A book on loan is kept (409, code \"book_on_loan\") until it is returned.",
+                "tags": [
+                    "books"
+                ],
+                "summary": "Delete a book",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/aliases/": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "aliases"
+                ],
+                "summary": "List alternate titles of a book",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Alias"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Aliases are matched by search and returned in the book's ` + "`" + `aliases` + "`" + `. Duplicates (ignoring case and accents) are rejected.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "aliases"
+                ],
+                "summary": "Add an alternate title to a book",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Alias",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ports.AddAliasInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Alias"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/aliases/{aliasID}": {
+            "delete": {
+                "tags": [
+                    "aliases"
+                ],
+                "summary": "Remove an alternate title",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Alias ID",
+                        "name": "aliasID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/editions": {
+            "get": {
+                "description": "Every book sharing the work of book id, the book itself included, by publication year and then format. A book joins a work with ` + "`" + `work_id` + "`" + ` set to the id of any of its editions; ` + "`" + `POST /books/{id}/split` + "`" + ` creates one.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "List the editions of a book's work",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.editionsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/favorite": {
+            "post": {
+                "description": "Favoriting a book again changes nothing. Favorites belong to the signed-in user; callers without a user account get 403.",
+                "tags": [
+                    "favorites"
+                ],
+                "summary": "Add a book to your favorites",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "404 if the book isn't one of your favorites.",
+                "tags": [
+                    "favorites"
+                ],
+                "summary": "Remove a book from your favorites",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/history": {
+            "get": {
+                "description": "Every create, update and delete of the book, newest first, with who made it, when, and the fields it changed with their old and new values. Entries outlive the book, so a deleted book's trail stays readable. Pass the ` + "`" + `id` + "`" + ` of the last entry as ` + "`" + `before` + "`" + ` for the next page. Requires the admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Audit trail of a book",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Only entries older than this entry id",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Max entries (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.AuditEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/label.zpl": {
+            "get": {
+                "description": "ZPL for a 2x1\" label with the title, an EAN-13 barcode of the ISBN and the price; send it as-is to a Zebra printer.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Shelf label for a book (ZPL)",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ZPL label",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/price-history": {
+            "get": {
+                "description": "Every price change of the book, oldest first, for charting: edits (reason ` + "`" + `update` + "`" + `) and re-prices (reason ` + "`" + `reprice: ...` + "`" + `, with their ` + "`" + `batch_id` + "`" + `), each with who made it. A book's first price is its price at creation, before the first change.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Price history of a book",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.PriceChange"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/split": {
+            "post": {
+                "description": "Creates a new edition from the book with ` + "`" + `fields` + "`" + ` applied on top (a new ISBN is required) and links both under the same work_id. The original keeps its id and history; aliases listed in ` + "`" + `alias_ids` + "`" + ` move to the new edition.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Split a book into two editions",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New edition",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ports.SplitBookInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/http.splitResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/tags/{tag}": {
+            "put": {
+                "description": "Tags are free-form, up to 64 characters without ` + "`" + `/` + "`" + `, and match ignoring case, accents and spacing; a new tag keeps the spelling it is first used with. Tagging a book with a tag it has changes nothing. A book has at most 50 tags. The change is recorded in the change log.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Tag a book",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tag",
+                        "name": "tag",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/presenter.BookView"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "The tag may be given in any spelling. 404 if the book doesn't have it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Remove a tag from a book",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tag",
+                        "name": "tag",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/presenter.BookView"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/events": {
+            "get": {
+                "description": "The change log as domain events (` + "`" + `book.created` + "`" + `, ` + "`" + `book.updated` + "`" + `, ` + "`" + `book.deleted` + "`" + `), oldest first, for integrations to backfill from. Start at ` + "`" + `since=0` + "`" + ` and pass ` + "`" + `next` + "`" + ` as the following ` + "`" + `since` + "`" + ` until ` + "`" + `events` + "`" + ` comes back empty. Superseded events older than the change log retention are compacted away, so a backfill from 0 sees at least the latest event of every book.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Read the domain events after an offset",
+                "parameters": [
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "Offset of the last event seen (default 0)",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Max events (default 100, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ports.EventsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/holds/": {
+            "post": {
+                "description": "Queues the caller for a book that is out on loan; position is their place in the queue. When the book is returned it is lent to the oldest waiting hold. Editors and admins may send user_id to queue another user. 403 for callers without a user account and for user_id of someone else; 409 with code \"book_available\" if the book isn't out (check it out instead) and with code \"hold_exists\" if the user already waits for it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "loans"
+                ],
+                "summary": "Place a hold on a book",
+                "parameters": [
+                    {
+                        "description": "Book, and user for editors",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ports.PlaceHoldInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Hold"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/holds/me": {
+            "get": {
+                "description": "The holds the caller waits on, oldest first, each with its current position in its book's queue. 403 for callers without a user account.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "loans"
+                ],
+                "summary": "List the caller's holds",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Hold"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/holds/{id}": {
+            "get": {
+                "description": "position is the hold's current place in the queue while it waits. Users see their own holds, editors and admins everyone's.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "loans"
+                ],
+                "summary": "Get a hold",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Hold ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Hold"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Takes the hold out of its queue; the holds behind it move up. Users cancel their own holds, editors and admins anyone's. 409 if it was fulfilled or cancelled already.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "loans"
+                ],
+                "summary": "Cancel a hold",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Hold ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Hold"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/loans/": {
+            "post": {
+                "description": "Lends the book to the user until ` + "`" + `due_at` + "`" + `, by default the loan period from now. A book is out on one loan at a time: 409 with code \"book_on_loan\" while it is out. Books on loan can't be deleted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "loans"
+                ],
+                "summary": "Check out a book",
+                "parameters": [
+                    {
+                        "description": "Book, user and due date",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ports.CheckoutInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Loan"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/loans/overdue": {
+            "get": {
+                "description": "Loans still out after their due date, the longest overdue first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "loans"
+                ],
+                "summary": "List overdue loans",
+                "parameters": [
+                    {
+                        "maximum": 1000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Loans to return, default 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Loan"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/loans/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "loans"
+                ],
+                "summary": "Get a loan",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Loan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Loan"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/loans/{id}/return": {
+            "post": {
+                "description": "Closes the loan. If holds wait for the book, it is lent at once to the user of the oldest one for the loan period, and next_loan is that loan; otherwise the book can be lent again. 409 if the loan was returned already.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "loans"
+                ],
+                "summary": "Return a book",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Loan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ReturnedLoan"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me": {
+            "get": {
+                "description": "The account of the signed-in user. 403 for anonymous callers; 404 for callers without an account, such as API keys.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get your profile",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces your name. A new_password replaces your password when current_password is the one it replaces; tokens already issued stay valid until they expire. Scopes are granted by admins through PUT /users/{id}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update your profile",
+                "parameters": [
+                    {
+                        "description": "Profile",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ports.UpdateProfileInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/favorites": {
+            "get": {
+                "description": "The latest favorite first, 50 a page unless limit says otherwise. Deleted books drop out of the list.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "favorites"
+                ],
+                "summary": "List your favorite books",
+                "parameters": [
+                    {
+                        "maximum": 500,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "Favorites to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/presenter.BookView"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "RFC 8288 next/prev page links"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Number of favorites across all pages"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/list-preferences/": {
+            "get": {
+                "description": "The sort and page size GET /books/ uses when your requests don't set them. Preferences belong to the caller: the API key (X-API-Key) or signed-in user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Get your book list defaults",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ListPreferences"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "` + "`" + `sort` + "`" + ` and ` + "`" + `collation` + "`" + ` take the values of the list parameters and apply together when a request sends no ` + "`" + `sort` + "`" + `; ` + "`" + `page_size` + "`" + `, up to the maximum page size, applies when it sends no ` + "`" + `limit` + "`" + `. Empty fields and a page size of 0 keep the server defaults. Lists use the new defaults right away on this server, and within a minute on the others.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Replace your book list defaults",
+                "parameters": [
+                    {
+                        "description": "Preferences",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ports.PutListPreferencesInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ListPreferences"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Lists go back to the server's order and page size.",
+                "tags": [
+                    "books"
+                ],
+                "summary": "Delete your book list defaults",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/url-history/": {
+            "get": {
+                "description": "Newest first. Successful POST /url/cleanup requests by a signed-in user, proxy identity or API key are kept, the newest 100 per caller; repeating a URL with the same operation or profile moves it to the top.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tools"
+                ],
+                "summary": "Your recent URL cleanups",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Max entries (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.URLHistoryEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "tags": [
+                    "tools"
+                ],
+                "summary": "Clear your URL history",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/url-history/{id}": {
+            "delete": {
+                "tags": [
+                    "tools"
+                ],
+                "summary": "Remove an entry from your URL history",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "History entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/publishers/": {
+            "get": {
+                "description": "Ordered by name.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "publishers"
+                ],
+                "summary": "List publishers",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Publisher"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Names are unique, ignoring case and accents. ` + "`" + `country` + "`" + ` is an ISO 3166-1 alpha-2 code and ` + "`" + `website` + "`" + ` an http(s) URL; both are optional.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "publishers"
+                ],
+                "summary": "Add a publisher",
+                "parameters": [
+                    {
+                        "description": "Publisher",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ports.PublisherInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Publisher"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/publishers/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "publishers"
+                ],
+                "summary": "Get a publisher",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Publisher ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Publisher"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Every field is replaced; leaving out ` + "`" + `country` + "`" + ` or ` + "`" + `website` + "`" + ` clears it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "publishers"
+                ],
+                "summary": "Replace a publisher",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Publisher ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Publisher",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ports.PublisherInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Publisher"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "A publisher with books is kept (409, code ` + "`" + `publisher_in_use` + "`" + `) unless ` + "`" + `detach=true` + "`" + `, which removes the publisher from its books in the same transaction.",
+                "tags": [
+                    "publishers"
+                ],
+                "summary": "Delete a publisher",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Publisher ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Remove the publisher from its books",
+                        "name": "detach",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/saved-searches/": {
+            "get": {
+                "description": "Every caller, a user or an API key, sees only the searches it saved; anonymous callers get 403.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "saved-searches"
+                ],
+                "summary": "List your saved searches",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.SavedSearch"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "The search belongs to the caller, who alone sees it. The query uses the search language: bare words match title, author and aliases; ` + "`" + `author:NAME` + "`" + `, ` + "`" + `year:FROM..TO` + "`" + ` and ` + "`" + `price:MIN..MAX` + "`" + ` narrow the result (either end of a range may be left out). Quote values with spaces. With ` + "`" + `notify` + "`" + ` set, every book added afterwards that matches produces a notification.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "saved-searches"
+                ],
+                "summary": "Save a search",
+                "parameters": [
+                    {
+                        "description": "Saved search",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ports.CreateSavedSearchInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SavedSearch"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/saved-searches/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "saved-searches"
+                ],
+                "summary": "Get a saved search",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Saved search ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SavedSearch"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "tags": [
+                    "saved-searches"
+                ],
+                "summary": "Delete a saved search and its notifications",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Saved search ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/saved-searches/{id}/notifications": {
+            "get": {
+                "description": "Newest first. Only searches saved with ` + "`" + `notify` + "`" + ` receive notifications.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "saved-searches"
+                ],
+                "summary": "New books that matched a saved search",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Saved search ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Max notifications (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.SearchNotification"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/search/feedback": {
+            "post": {
+                "description": "Clients call this when a user opens book book_id from the results of a search for q, so GET /admin/search/insights can report click-throughs. Counts are kept per query, folded like searches, and flushed every few seconds.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Report an opened search result",
+                "parameters": [
+                    {
+                        "description": "Search and the book opened",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.searchFeedbackRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    }
+                }
+            }
+        },
+        "/series/": {
+            "get": {
+                "description": "Ordered by name.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "List series",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Series"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Names are unique, ignoring case and accents. Books join a series with ` + "`" + `series_id` + "`" + ` and ` + "`" + `series_position` + "`" + ` on create or update.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "Add a series",
+                "parameters": [
+                    {
+                        "description": "Series",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ports.SeriesInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Series"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/series/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "Get a series",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Series ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Series"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "Rename a series",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Series ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Series",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ports.SeriesInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Series"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "A series with books is kept (409, code ` + "`" + `series_in_use` + "`" + `) unless ` + "`" + `detach=true` + "`" + `, which takes its books out of it, clearing their positions, in the same transaction.",
+                "tags": [
+                    "series"
+                ],
+                "summary": "Delete a series",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Series ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Take the books out of the series",
+                        "name": "detach",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/series/{id}/books": {
+            "get": {
+                "description": "By ` + "`" + `series_position` + "`" + `, books without one last, then by publication year. Editions of the same work share a position.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "List the books of a series in order",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Series ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/presenter.BookView"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/status": {
+            "get": {
+                "description": "Checks each dependency the API uses (MySQL, the sandbox database, the NATS and Kafka brokers, background workers) at once, each within 2 seconds, and reports whether it is up, how long the check took, and the last error this replica saw, kept after the dependency recovers. Answers 503 with the same body when any dependency is down. Requires the admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Health of every dependency",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SystemStatus"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SystemStatus"
+                        }
+                    }
+                }
+            }
+        },
+        "/sync/books": {
+            "get": {
+                "description": "Returns books created/updated and tombstones for books deleted after ` + "`" + `checkpoint` + "`" + `. Checkpoint 0 (or omitted) starts an initial sync, which returns every book in id order, ` + "`" + `limit` + "`" + ` at a time (at most the max page size). While it has more, the response carries a ` + "`" + `snapshot` + "`" + ` token to send back for the next page and checkpoint 0; the last page has the checkpoint. Store the returned checkpoint and keep pulling while has_more is true.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sync"
+                ],
+                "summary": "Pull changes for offline sync",
+                "parameters": [
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "Checkpoint from the previous pull",
+                        "name": "checkpoint",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Snapshot token from the previous page of an initial sync",
+                        "name": "snapshot",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Max change-log entries to scan, or books per page of an initial sync (default 200)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ports.SyncPullResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Applies creates/updates/deletes made offline. Updates are three-way merged against ` + "`" + `base` + "`" + `; strategy \"merge\" resolves conflicting fields per ` + "`" + `field_strategies` + "`" + ` (server-wins by default), \"server-wins\" rejects a change on any conflict, \"client-wins\" overwrites.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sync"
+                ],
+                "summary": "Push offline edits",
+                "parameters": [
+                    {
+                        "description": "Offline changes",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ports.SyncPushRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ports.SyncPushResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags": {
+            "get": {
+                "description": "The tags on at least one book, most used first, for a tag cloud. ` + "`" + `prefix` + "`" + ` keeps the tags starting with it, ignoring case and accents, for autocompletion.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "List tags with usage counts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start of the tag",
+                        "name": "prefix",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Tags to return, default 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Tag"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/taxonomy/export": {
+            "get": {
+                "description": "The whole taxonomy as an indented JSON document, sorted by slug so exports diff cleanly in Git. Import it elsewhere with POST /taxonomy/import.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "taxonomy"
+                ],
+                "summary": "Export the category and author taxonomy",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Taxonomy"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/taxonomy/import": {
+            "post": {
+                "description": "Takes a document from GET /taxonomy/export. ` + "`" + `merge` + "`" + ` adds the terms this deployment lacks and keeps its own version of those that differ, listing each difference under ` + "`" + `conflicts` + "`" + `; it deletes nothing. ` + "`" + `replace` + "`" + ` makes the taxonomy exactly the document. The import applies in one transaction; with ` + "`" + `dry_run=true` + "`" + ` it only reports what it would do. Unknown fields in the document are rejected, so typos don't pass silently.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "taxonomy"
+                ],
+                "summary": "Import a category and author taxonomy",
+                "parameters": [
+                    {
+                        "enum": [
+                            "merge",
+                            "replace"
+                        ],
+                        "type": "string",
+                        "description": "merge or replace",
+                        "name": "mode",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Report without applying",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Taxonomy",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Taxonomy"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ports.TaxonomyReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/url/cleanup": {
+            "post": {
+                "description": "operation: \"redirection\" | \"canonical\" | \"all\" | \"resolve\". url must be an absolute http or https URL of at most 2048 characters; invalid fields are reported per field with 422.\nInstead of an operation, profile may name any profile listed by GET /url/profiles.\nfragment overrides what the operation or profile does with the #fragment: \"drop\" it, \"keep\" it, or keep only single-page app \"routes\" (#/path, #!path).\nduplicates overrides what happens to a query key given more than once: \"keep_all\" values, \"keep_first\" or \"keep_last\". empty_params \"keep\"s or \"drop\"s parameters without a value (?b= and ?b). Kept parameters stay in their order.\n\"resolve\" follows the URL's redirects and returns where it lands. It only fetches public http(s) URLs without credentials; localhost and private addresses get 422, and unreachable URLs 502.\nhost gives the processed URL's host in punycode (ascii) and Unicode, the scripts of its letters, and confusable when a label mixes scripts or spells a Latin lookalike in Cyrillic, Greek or Armenian, as spoofed domains do.\nWith respect_robots, resolve doesn't fetch URLs the host's robots.txt disallows; it stops there with status \"blocked_by_robots\".\nstrip_tracking_params also applies the caller's own rules, managed under /url/cleanup/rules.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tools"
+                ],
+                "summary": "Normalize/cleanup a URL",
+                "parameters": [
+                    {
+                        "description": "Cleanup payload",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.cleanupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.cleanupResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/url/cleanup/rules": {
+            "get": {
+                "description": "The parameters the strip_tracking_params cleanup rule removes from your requests on top of the built-in ones, the ones it keeps, and per-host changes. Rules belong to the caller: the API key (X-API-Key) or signed-in user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tools"
+                ],
+                "summary": "Get your tracking parameter rules",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.TrackingRules"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "strip lists parameters to remove too and keep parameters never to remove, built-in ones included; keep wins. Names ignore case, and a trailing * matches any suffix (` + "`" + `src_*` + "`" + `). A hosts entry applies on top to its host and subdomains. Up to 200 names per list and 50 hosts. Cleanups use the new rules right away on this server, and within a minute on the others.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tools"
+                ],
+                "summary": "Replace your tracking parameter rules",
+                "parameters": [
+                    {
+                        "description": "Rules",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ports.PutTrackingRulesInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.TrackingRules"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Cleanups go back to the built-in tracking parameters.",
+                "tags": [
+                    "tools"
+                ],
+                "summary": "Delete your tracking parameter rules",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/url/cleanup/stats": {
+            "get": {
+                "description": "Requests to POST /url/cleanup over the last days days (today included): totals and error rate, the top busiest domains, and the breakdown per operation or profile and per cleanup rule. Counts are flushed every few seconds, so the latest requests may be missing. Requires the admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tools"
+                ],
+                "summary": "URL cleanup usage report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 7,
+                        "description": "Days to report on, 1-90",
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Domains to list, 1-100",
+                        "name": "top",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.CleanupStats"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/url/extract": {
+            "post": {
+                "description": "Fetches the page at url (following redirects, at most 1 MiB within URL_RESOLVE_TIMEOUT) and returns its \u003clink rel=\"canonical\"\u003e, og:url and sitemap hints, including the Sitemap lines of the host's robots.txt.\nbest_guess is the first of canonical, og_url and final_url that is an http(s) URL, normalized by the canonical cleanup operation; source says which one it is.\nThe same fetch rules as the resolve operation apply: localhost and private addresses get 422, and unreachable URLs or pages answering with an error get 502.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tools"
+                ],
+                "summary": "Find a page's canonical URL",
+                "parameters": [
+                    {
+                        "description": "Page to read",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.extractRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.PageLinks"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/url/profiles": {
+            "get": {
+                "description": "The built-in operations and the profiles configured in CLEANUP_PROFILES, each with the rules it applies in order.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tools"
+                ],
+                "summary": "List URL cleanup profiles",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.CleanupProfile"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/": {
+            "get": {
+                "description": "User accounts by id, 100 a page unless limit says otherwise. Requires the admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List users",
+                "parameters": [
+                    {
+                        "maximum": 1000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "Users to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.User"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "RFC 8288 next/prev page links"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Number of users across all pages"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Like POST /auth/register, with a name of up to 100 characters and scopes among admin, editor and reader. 409 when the email has an account. Requires the admin scope.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Create a user",
+                "parameters": [
+                    {
+                        "description": "User",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ports.CreateUserInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "description": "Requires the admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get a user",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "A password, when sent, replaces the user's. The email can't change. Tokens keep the scopes they were issued with, so new scopes apply from the user's next login. Requires the admin scope.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Replace a user's name and scopes",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "User",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ports.UpdateUserInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Their loans and holds go with them. Tokens already issued to them stay valid until they expire. Requires the admin scope.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete a user",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/version": {
+            "get": {
+                "description": "The version, git commit and build time stamped into the binary at build time, and the Go version it was built with. Fields that weren't stamped read \"unknown\". Every response also carries the version and short commit in X-App-Version.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Build of the running API",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/buildinfo.Info"
+                        },
+                        "headers": {
+                            "X-App-Version": {
+                                "type": "string",
+                                "description": "Version and short commit, as on every response"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/": {
+            "get": {
+                "description": "Oldest first, without their secrets. Requires the admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Webhook"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Book events (` + "`" + `book.created` + "`" + `, ` + "`" + `book.updated` + "`" + `, ` + "`" + `book.deleted` + "`" + `, or those in ` + "`" + `events` + "`" + `) are POSTed to ` + "`" + `url` + "`" + ` as they happen, with the event as the body. Each delivery carries ` + "`" + `X-Webhook-Id` + "`" + `, ` + "`" + `X-Webhook-Event` + "`" + `, ` + "`" + `X-Webhook-Timestamp` + "`" + ` and ` + "`" + `X-Webhook-Signature: sha256=\u003chex\u003e` + "`" + `, the HMAC-SHA256 of the timestamp, a dot and the body keyed with the secret. Without a ` + "`" + `secret` + "`" + ` one is generated; either way it is returned only here. The URL must be public http(s) and allowed by the egress allowlist. Requires the admin scope.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Register a webhook",
+                "parameters": [
+                    {
+                        "description": "Webhook",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ports.WebhookInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/ports.CreatedWebhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "description": "Requires the admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get a webhook",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces the URL and events. A ` + "`" + `secret` + "`" + ` rotates the secret, effective from the next attempt; without one it is kept, as is ` + "`" + `active` + "`" + `. Deliveries of an inactive webhook wait, and go out once it is active again. Requires the admin scope.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Change a webhook",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Webhook",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ports.WebhookInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/http.validationPayload"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Its pending deliveries are dropped along with its delivery log. Requires the admin scope.",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete a webhook",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "description": "Newest first: each event sent or to be sent, its ` + "`" + `status` + "`" + ` (` + "`" + `pending` + "`" + ` until it gets a 2xx or runs out of attempts), the number of attempts, and the HTTP status and error of the latest one. A failed delivery is tried 6 times, 30s after the first failure and twice as long after each next one, before it becomes ` + "`" + `failed` + "`" + ` and goes to the dead letter queue (kind ` + "`" + `webhook_delivery` + "`" + `), from where an admin can retry it. Requires the admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List the deliveries of a webhook",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Max deliveries (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ports.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "buildinfo.Info": {
+            "type": "object",
+            "properties": {
+                "build_time": {
+                    "type": "string",
+                    "example": "2024-01-02T12:00:00Z"
+                },
+                "commit": {
+                    "type": "string",
+                    "example": "3fff8f0c1d2e4b5a6978a1b2c3d4e5f601234567"
+                },
+                "go_version": {
+                    "type": "string",
+                    "example": "go1.23.1"
+                },
+                "version": {
+                    "type": "string",
+                    "example": "1.4.0"
+                }
+            }
+        },
+        "domain.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "warehouse-sync"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "Scopes are granted to requests made with the key, e.g. admin.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.Alias": {
+            "type": "object",
+            "properties": {
+                "alias": {
+                    "type": "string"
+                },
+                "book_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.AuditDiff": {
+            "type": "object",
+            "additionalProperties": {
+                "$ref": "#/definitions/domain.FieldChange"
+            }
+        },
+        "domain.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "description": "Actor made the change; ImpersonatedBy is the admin acting as them.",
+                    "type": "string"
+                },
+                "book_id": {
+                    "type": "integer"
+                },
+                "changes": {
+                    "$ref": "#/definitions/domain.AuditDiff"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "impersonated_by": {
+                    "type": "string"
+                },
+                "op": {
+                    "type": "string",
+                    "enum": [
+                        "created",
+                        "updated",
+                        "deleted"
+                    ]
+                }
+            }
+        },
+        "domain.AuthCapabilities": {
+            "type": "object",
+            "properties": {
+                "api_key_required": {
+                    "description": "APIKeyRequired is \"writes\" or \"all\" when book requests need an API\nkey, empty when they don't.",
+                    "type": "string",
+                    "example": "writes"
+                },
+                "modes": {
+                    "description": "Modes are the accepted identities: \"proxy_headers\" (X-User from an\nauthenticating proxy), \"api_key\" (X-API-Key) and \"bearer\" (tokens\nfrom POST /auth/login).",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "api_key",
+                        "bearer"
+                    ]
+                },
+                "roles": {
+                    "description": "Roles is set when only editors and admins may change books.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.Author": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "example": "ursula-k-le-guin"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Ursula K. Le Guin"
+                }
+            }
+        },
+        "domain.AuthorBook": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "isbn": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "publication_year": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.AuthorSummary": {
+            "type": "object",
+            "properties": {
+                "book_count": {
+                    "type": "integer"
+                },
+                "books": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.AuthorBook"
+                    }
+                },
+                "first_year": {
+                    "description": "FirstYear and LastYear span the known publication years; nil when no\nbook has one.",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "gabriel-garcia-marquez"
+                },
+                "last_year": {
+                    "type": "integer"
+                },
+                "max_price": {
+                    "type": "number"
+                },
+                "min_price": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Gabriel García Márquez"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Book": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "author": {
+                    "type": "string"
+                },
+                "categories": {
+                    "description": "Categories are the slugs the book is filed under, sorted.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "format": {
+                    "description": "Format tells the editions of a work apart: one of BookFormats, or\nempty when unknown.",
+                    "type": "string",
+                    "enum": [
+                        "hardcover",
+                        "paperback",
+                        "ebook"
+                    ]
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "isbn": {
+                    "description": "ISBN-13, also for books created with an ISBN-10",
+                    "type": "string"
+                },
+                "isbn10": {
+                    "description": "empty for ISBN-13s starting with 979, which have none",
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "publication_year": {
+                    "type": "integer"
+                },
+                "publisher_id": {
+                    "description": "PublisherID is the book's publisher; nil when it has none.",
+                    "type": "integer"
+                },
+                "series_id": {
+                    "description": "SeriesID is the series the book belongs to; nil when it has none.",
+                    "type": "integer"
+                },
+                "series_position": {
+                    "description": "SeriesPosition is the book's place in its series, from 1; nil when\nunknown.",
+                    "type": "integer"
+                },
+                "tags": {
+                    "description": "Tags are the book's free-form tags, sorted.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version starts at 1 and goes up by one with every write.",
+                    "type": "integer"
+                },
+                "work_id": {
+                    "description": "WorkID groups editions of the same work; nil for a standalone book.",
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.BookMetadata": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "Robert C. Martin"
+                },
+                "isbn": {
+                    "type": "string",
+                    "example": "9780132350884"
+                },
+                "publication_year": {
+                    "type": "integer",
+                    "example": 2008
+                },
+                "source": {
+                    "description": "Source names the catalogue the title came from: openlibrary or\ngooglebooks.",
+                    "type": "string",
+                    "example": "openlibrary"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Clean Code"
+                }
+            }
+        },
+        "domain.BulkJob": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "done": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "error": {
+                    "description": "Error is why a failed job stopped.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "9f2c4e0a1b7d4c1e8a3f5b6d7e8f9a0b"
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "bulk_tag"
+                },
+                "status": {
+                    "description": "Status is running, done, failed or interrupted.",
+                    "type": "string",
+                    "example": "running"
+                },
+                "total": {
+                    "description": "Total books to change, and how many are done.",
+                    "type": "integer",
+                    "example": 1200
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Capabilities": {
+            "type": "object",
+            "properties": {
+                "auth": {
+                    "$ref": "#/definitions/domain.AuthCapabilities"
+                },
+                "export": {
+                    "$ref": "#/definitions/domain.ExportCapabilities"
+                },
+                "features": {
+                    "description": "Features lists the optional features enabled here, sorted, such as\nchange_feed, saved_searches or url_resolve.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "change_feed",
+                        "saved_searches",
+                        "url_resolve"
+                    ]
+                },
+                "pagination": {
+                    "$ref": "#/definitions/domain.PaginationCapabilities"
+                },
+                "search": {
+                    "$ref": "#/definitions/domain.SearchCapabilities"
+                },
+                "version": {
+                    "type": "string",
+                    "example": "1.4.0+3fff8f0"
+                }
+            }
+        },
+        "domain.Category": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Science Fiction"
+                },
+                "parent": {
+                    "type": "string",
+                    "example": "fiction"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "science-fiction"
+                }
+            }
+        },
+        "domain.Change": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "description": "Actor made the change; ImpersonatedBy is the admin acting as them.",
+                    "type": "string"
+                },
+                "book_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "cursor": {
+                    "type": "integer"
+                },
+                "entity": {
+                    "type": "string"
+                },
+                "impersonated_by": {
+                    "type": "string"
+                },
+                "op": {
+                    "type": "string"
+                },
+                "payload": {
+                    "description": "Payload is the entity right after the change; absent for deletes.",
+                    "type": "object"
+                },
+                "version": {
+                    "description": "Version numbers the changes of one entity, starting at 1.",
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.CleanupCount": {
+            "type": "object",
+            "properties": {
+                "error_rate": {
+                    "type": "number"
+                },
+                "errors": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "example.com"
+                },
+                "requests": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.CleanupProfile": {
+            "type": "object",
+            "properties": {
+                "built_in": {
+                    "type": "boolean"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "One key per page for the CDN cache"
+                },
+                "https_hosts": {
+                    "description": "upgrade_https only upgrades these hosts; empty means all",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "blog.example.com"
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "example": "cache_key"
+                },
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "lowercase_host",
+                        "strip_tracking_params",
+                        "sort_query",
+                        "drop_fragment"
+                    ]
+                }
+            }
+        },
+        "domain.CleanupStats": {
+            "type": "object",
+            "properties": {
+                "error_rate": {
+                    "type": "number"
+                },
+                "errors": {
+                    "type": "integer"
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.CleanupCount"
+                    }
+                },
+                "requests": {
+                    "type": "integer"
+                },
+                "rules": {
+                    "description": "Rules counts the requests that applied each cleanup rule.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.CleanupCount"
+                    }
+                },
+                "since": {
+                    "type": "string"
+                },
+                "top_domains": {
+                    "description": "TopDomains are the most cleaned domains, busiest first.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.CleanupCount"
+                    }
+                }
+            }
+        },
+        "domain.DeadLetter": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Error is the reason the latest attempt failed.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "description": "Kind names the work, and with it the handler that retries it.",
+                    "type": "string",
+                    "example": "search_notification"
+                },
+                "last_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "description": "Payload is whatever the handler needs to run the work again.",
+                    "type": "object"
+                }
+            }
+        },
+        "domain.DependencyStatus": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string",
+                    "example": "dial tcp 10.0.0.5:3306: connect: connection refused"
+                },
+                "last_error_at": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "number",
+                    "example": 1.8
+                },
+                "name": {
+                    "type": "string",
+                    "example": "mysql"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "up"
+                }
+            }
+        },
+        "domain.Event": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "data": {
+                    "description": "Data is the entity right after the event; absent for deletes.",
+                    "type": "object"
+                },
+                "entity": {
+                    "type": "string",
+                    "example": "book"
+                },
+                "entity_id": {
+                    "type": "integer"
+                },
+                "impersonated_by": {
+                    "type": "string"
+                },
+                "occurred_at": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "book.updated"
+                },
+                "version": {
+                    "description": "Version numbers the events of one entity, starting at 1.",
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.ExportCapabilities": {
+            "type": "object",
+            "properties": {
+                "formats": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "csv",
+                        "xlsx"
+                    ]
+                },
+                "import_formats": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "csv",
+                        "json"
+                    ]
+                }
+            }
+        },
+        "domain.FieldChange": {
+            "type": "object",
+            "properties": {
+                "new": {},
+                "old": {}
+            }
+        },
+        "domain.Hold": {
+            "type": "object",
+            "properties": {
+                "book_id": {
+                    "type": "integer"
+                },
+                "closed_at": {
+                    "description": "ClosedAt is when the hold was fulfilled or cancelled.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "loan_id": {
+                    "description": "LoanID is the loan that fulfilled the hold.",
+                    "type": "integer"
+                },
+                "placed_at": {
+                    "type": "string"
+                },
+                "position": {
+                    "description": "Position is the hold's place in its book's queue, 1 being next; 0\nonce the hold is no longer waiting.",
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "waiting",
+                        "fulfilled",
+                        "cancelled"
+                    ]
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.HostTrackingRules": {
+            "type": "object",
+            "properties": {
+                "host": {
+                    "type": "string",
+                    "example": "shop.example.com"
+                },
+                "keep": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ref"
+                    ]
+                },
+                "strip": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "sessionid"
+                    ]
+                }
+            }
+        },
+        "domain.ListPreferences": {
+            "type": "object",
+            "properties": {
+                "collation": {
+                    "type": "string",
+                    "example": ""
+                },
+                "page_size": {
+                    "description": "PageSize is the limit of lists that send none; 0 keeps the server's.",
+                    "type": "integer",
+                    "example": 24
+                },
+                "sort": {
+                    "description": "Sort is a sort field, prefixed with - for descending; empty keeps\nnewest first.",
+                    "type": "string",
+                    "example": "-publication_year"
+                },
+                "tenant": {
+                    "type": "string",
+                    "example": "key:storefront"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Loan": {
+            "type": "object",
+            "properties": {
+                "book_id": {
+                    "type": "integer"
+                },
+                "checked_out_at": {
+                    "type": "string"
+                },
+                "due_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "returned_at": {
+                    "description": "ReturnedAt is nil while the book is out.",
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.PageLinks": {
+            "type": "object",
+            "properties": {
+                "best_guess": {
+                    "description": "BestGuess is the most trusted of canonical, og_url and final_url that\nis an http(s) URL, normalized by the canonical cleanup operation.",
+                    "type": "string",
+                    "example": "https://www.example.com/books/dune"
+                },
+                "canonical": {
+                    "description": "\u003clink rel=\"canonical\"\u003e",
+                    "type": "string",
+                    "example": "https://www.example.com/books/dune"
+                },
+                "final_url": {
+                    "description": "after redirects",
+                    "type": "string",
+                    "example": "https://www.example.com/books/dune?utm_source=x"
+                },
+                "og_url": {
+                    "description": "\u003cmeta property=\"og:url\"\u003e",
+                    "type": "string",
+                    "example": "https://www.example.com/books/dune"
+                },
+                "sitemaps": {
+                    "description": "\u003clink rel=\"sitemap\"\u003e and robots.txt Sitemap lines",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "https://www.example.com/sitemap.xml"
+                    ]
+                },
+                "source": {
+                    "type": "string",
+                    "enum": [
+                        "canonical",
+                        "og_url",
+                        "final_url"
+                    ],
+                    "example": "canonical"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://bit.ly/3xyz"
+                }
+            }
+        },
+        "domain.PaginationCapabilities": {
+            "type": "object",
+            "properties": {
+                "default_page_size": {
+                    "description": "DefaultPageSize is the page size when a paged request sends no\nlimit; 0 means the whole list.",
+                    "type": "integer",
+                    "example": 50
+                },
+                "max_page_size": {
+                    "description": "MaxPageSize is the largest limit a list accepts; 0 means no maximum.",
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "domain.PriceChange": {
+            "type": "object",
+            "properties": {
+                "batch_id": {
+                    "type": "string"
+                },
+                "book_id": {
+                    "type": "integer"
+                },
+                "changed_at": {
+                    "type": "string"
+                },
+                "changed_by": {
+                    "description": "ChangedBy is the actor who changed the price; empty for anonymous\nrequests and background jobs.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "new_price": {
+                    "type": "number"
+                },
+                "old_price": {
+                    "type": "number"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Publisher": {
+            "type": "object",
+            "properties": {
+                "country": {
+                    "description": "Country is an ISO 3166-1 alpha-2 code, empty when unknown.",
+                    "type": "string",
+                    "example": "US"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Ace Books"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "website": {
+                    "description": "Website is an http(s) URL, empty when unknown.",
+                    "type": "string",
+                    "example": "https://www.penguinrandomhouse.com/ace"
+                }
+            }
+        },
+        "domain.RankingWeights": {
+            "type": "object",
+            "properties": {
+                "author_weight": {
+                    "type": "number",
+                    "example": 1
+                },
+                "recency_boost": {
+                    "type": "number",
+                    "example": 0.5
+                },
+                "title_weight": {
+                    "type": "number",
+                    "example": 2
+                }
+            }
+        },
+        "domain.SavedSearch": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "notify": {
+                    "description": "Notify asks for a notification whenever a new book matches.",
+                    "type": "boolean"
+                },
+                "owner": {
+                    "description": "Owner is the actor who saved the search, the only one who sees it.",
+                    "type": "string",
+                    "example": "ann@example.com"
+                },
+                "query": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.SearchCapabilities": {
+            "type": "object",
+            "properties": {
+                "backend": {
+                    "description": "Backend is where searches run: \"mysql\", or \"memory\" in demo mode.",
+                    "type": "string",
+                    "example": "mysql"
+                },
+                "collations": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "de",
+                        "sv"
+                    ]
+                },
+                "sort_fields": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "id",
+                        "title",
+                        "author",
+                        "price",
+                        "publication_year"
+                    ]
+                }
+            }
+        },
+        "domain.SearchInsights": {
+            "type": "object",
+            "properties": {
+                "click_through_rate": {
+                    "type": "number"
+                },
+                "clicks": {
+                    "type": "integer"
+                },
+                "searches": {
+                    "type": "integer"
+                },
+                "since": {
+                    "type": "string"
+                },
+                "top_queries": {
+                    "description": "TopQueries are the most searched queries, busiest first.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.SearchQueryCount"
+                    }
+                },
+                "zero_result_queries": {
+                    "description": "ZeroResultQueries are the queries that most often found nothing,\nthe books the catalogue is missing.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.SearchQueryCount"
+                    }
+                },
+                "zero_result_rate": {
+                    "type": "number"
+                },
+                "zero_results": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.SearchNotification": {
+            "type": "object",
+            "properties": {
+                "book_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "search_id": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.SearchQueryCount": {
+            "type": "object",
+            "properties": {
+                "click_through_rate": {
+                    "type": "number"
+                },
+                "clicks": {
+                    "type": "integer"
+                },
+                "query": {
+                    "type": "string",
+                    "example": "garcia marquez"
+                },
+                "searches": {
+                    "type": "integer"
+                },
+                "zero_results": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.SearchRanking": {
+            "type": "object",
+            "properties": {
+                "author_weight": {
+                    "type": "number",
+                    "example": 1
+                },
+                "recency_boost": {
+                    "type": "number",
+                    "example": 0.5
+                },
+                "tenant": {
+                    "type": "string",
+                    "example": "key:storefront"
+                },
+                "title_weight": {
+                    "type": "number",
+                    "example": 2
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Series": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "The Expanse"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.SynonymGroup": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "terms": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "programming",
+                        "coding"
+                    ]
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.SystemStatus": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "type": "string"
+                },
+                "dependencies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DependencyStatus"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "domain.Tag": {
+            "type": "object",
+            "properties": {
+                "books": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "name": {
+                    "type": "string",
+                    "example": "cozy mystery"
+                }
+            }
+        },
+        "domain.Taxonomy": {
+            "type": "object",
+            "properties": {
+                "authors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Author"
+                    }
+                },
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Category"
+                    }
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "domain.TrackingRules": {
+            "type": "object",
+            "properties": {
+                "hosts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.HostTrackingRules"
+                    }
+                },
+                "keep": {
+                    "description": "Keep lists parameters never removed, even built-in ones.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "utm_id"
+                    ]
+                },
+                "strip": {
+                    "description": "Strip lists parameters removed on top of the built-in ones; a\ntrailing * matches any suffix.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ref",
+                        "src_*"
+                    ]
+                },
+                "tenant": {
+                    "type": "string",
+                    "example": "key:partner-feed"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.URLHistoryEntry": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "operation": {
+                    "description": "or the profile",
+                    "type": "string",
+                    "example": "all"
+                },
+                "processed_url": {
+                    "type": "string",
+                    "example": "https://www.example.com/a"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://Example.com/a/?utm_source=x"
+                }
+            }
+        },
+        "domain.User": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "ann@example.com"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "Name is how the user is shown, empty until set.",
+                    "type": "string",
+                    "example": "Ann Lee"
+                },
+                "scopes": {
+                    "description": "Scopes are granted by an operator, e.g. admin; new users have none.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Webhook": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "Active webhooks get deliveries; inactive ones keep their log.",
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "events": {
+                    "description": "Events are the event types delivered; empty delivers them all.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://hooks.example.com/books"
+                }
+            }
+        },
+        "domain.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "event_offset": {
+                    "type": "integer"
+                },
+                "event_type": {
+                    "type": "string",
+                    "example": "book.updated"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_attempt_at": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "response_status": {
+                    "description": "ResponseStatus is the HTTP status of the latest attempt; absent when\nit got no response.",
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "delivered",
+                        "failed"
+                    ]
+                },
+                "webhook_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.WorkerStatus": {
+            "type": "object",
+            "properties": {
+                "last_heartbeat": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "saved_searches"
+                },
+                "stall_after": {
+                    "description": "StallAfter is how long the worker may go without a heartbeat, in seconds.",
+                    "type": "integer"
+                },
+                "state": {
+                    "type": "string",
+                    "enum": [
+                        "running",
+                        "paused",
+                        "stalled",
+                        "stopped"
+                    ]
+                }
+            }
+        },
+        "http.cleanupRequest": {
+            "type": "object",
+            "properties": {
+                "duplicates": {
+                    "description": "Duplicates overrides what happens to repeated query keys:\n\"keep_all\", \"keep_first\" or \"keep_last\".",
+                    "type": "string",
+                    "example": "keep_first"
+                },
+                "empty_params": {
+                    "description": "EmptyParams overrides what happens to parameters without a value:\n\"keep\" or \"drop\".",
+                    "type": "string",
+                    "example": "drop"
+                },
+                "fragment": {
+                    "description": "Fragment overrides what the operation or profile does with the\n#fragment: \"drop\", \"keep\", or \"routes\" to keep only #/ and #! routes.",
+                    "type": "string",
+                    "example": "routes"
+                },
+                "operation": {
+                    "description": "\"redirection\" | \"canonical\" | \"all\" | \"resolve\", any case",
+                    "type": "string",
+                    "example": "all"
+                },
+                "profile": {
+                    "description": "Profile names a cleanup profile instead of an operation.",
+                    "type": "string",
+                    "example": ""
+                },
+                "respect_robots": {
+                    "description": "RespectRobots makes the resolve operation honour robots.txt.",
+                    "type": "boolean"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://Example.com/Path/?a=1"
+                }
+            }
+        },
+        "http.cleanupResponse": {
+            "type": "object",
+            "properties": {
+                "host": {
+                    "description": "Host is the processed URL's host, punycode and Unicode, with a flag\nfor mixed-script confusables.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/urlclean.Host"
+                        }
+                    ]
+                },
+                "processed_url": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status is set by the resolve operation: \"resolved\", or\n\"blocked_by_robots\" when processed_url is a URL robots.txt disallows.",
+                    "type": "string",
+                    "enum": [
+                        "resolved",
+                        "blocked_by_robots"
+                    ]
+                }
+            }
+        },
+        "http.conflictPayload": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version is the book's current version when the conflict is on version.",
+                    "type": "integer"
+                }
+            }
+        },
+        "http.editionsResponse": {
+            "type": "object",
+            "properties": {
+                "editions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/presenter.BookView"
+                    }
+                },
+                "work_id": {
+                    "description": "WorkID is the work_id the editions share; a book without other\neditions is its own work.",
+                    "type": "integer"
+                }
+            }
+        },
+        "http.extractRequest": {
+            "type": "object",
+            "properties": {
+                "url": {
+                    "type": "string",
+                    "example": "https://bit.ly/3xyz"
+                }
+            }
+        },
+        "http.searchFeedbackRequest": {
+            "type": "object",
+            "properties": {
+                "book_id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "q": {
+                    "type": "string",
+                    "example": "garcia marquez"
+                }
+            }
+        },
+        "http.splitResponse": {
+            "type": "object",
+            "properties": {
+                "edition": {
+                    "$ref": "#/definitions/presenter.BookView"
+                },
+                "source": {
+                    "$ref": "#/definitions/presenter.BookView"
+                }
+            }
+        },
+        "http.validationPayload": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "fields": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "request_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "ports.AddAliasInput": {
+            "type": "object",
+            "properties": {
+                "alias": {
+                    "type": "string",
+                    "example": "Cien años de soledad"
+                }
+            }
+        },
+        "ports.AuthToken": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "token_type": {
+                    "type": "string",
+                    "example": "Bearer"
+                }
+            }
+        },
+        "ports.BulkTagItem": {
+            "type": "object",
+            "properties": {
+                "book_id": {
+                    "type": "integer"
+                },
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "ports.BulkTagRequest": {
+            "type": "object",
+            "properties": {
+                "add": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "science-fiction"
+                    ]
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "filter": {
+                    "description": "Filter selects books like the re-price filter does.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/ports.RepriceFilter"
+                        }
+                    ]
+                },
+                "remove": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "fantasy"
+                    ]
+                }
+            }
+        },
+        "ports.BulkTagResponse": {
+            "type": "object",
+            "properties": {
+                "changed": {
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "job": {
+                    "description": "Job is the started job, when not a dry run and some book changes.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.BulkJob"
+                        }
+                    ]
+                },
+                "matched": {
+                    "description": "Matched books pass the filter; Changed of them would get different\ncategories.",
+                    "type": "integer"
+                },
+                "preview": {
+                    "description": "Preview lists the first changed books, at most 100.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ports.BulkTagItem"
+                    }
+                }
+            }
+        },
+        "ports.ChangesResponse": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Change"
+                    }
+                },
+                "cursor": {
+                    "type": "integer"
+                }
+            }
+        },
+        "ports.CheckoutInput": {
+            "type": "object",
+            "properties": {
+                "book_id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "due_at": {
+                    "description": "DueAt defaults to the loan period from now.",
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 7
+                }
+            }
+        },
+        "ports.CreateBookInput": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string"
+                },
+                "autofill": {
+                    "description": "Autofill fills a blank title, author and publication year from the\nexternal catalogues the ISBN is found in. When they can't be reached\nthe book is created from what was sent.",
+                    "type": "boolean"
+                },
+                "format": {
+                    "description": "Format is hardcover, paperback or ebook.",
+                    "type": "string",
+                    "enum": [
+                        "hardcover",
+                        "paperback",
+                        "ebook"
+                    ]
+                },
+                "isbn": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "publication_year": {
+                    "type": "integer"
+                },
+                "publisher_id": {
+                    "description": "PublisherID files the book under an existing publisher.",
+                    "type": "integer"
+                },
+                "series_id": {
+                    "description": "SeriesID puts the book in an existing series, at SeriesPosition.",
+                    "type": "integer"
+                },
+                "series_position": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "work_id": {
+                    "description": "WorkID makes the book another edition of the work of this book id.",
+                    "type": "integer"
+                }
+            }
+        },
+        "ports.CreateSavedSearchInput": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Early Le Guin"
+                },
+                "notify": {
+                    "type": "boolean"
+                },
+                "query": {
+                    "type": "string",
+                    "example": "author:\"Ursula K. Le Guin\" year:..1975"
+                }
+            }
+        },
+        "ports.CreateUserInput": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "ann@example.com"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Ann Lee"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "correct horse battery"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "editor"
+                    ]
+                }
+            }
+        },
+        "ports.CreatedWebhook": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "Active webhooks get deliveries; inactive ones keep their log.",
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "events": {
+                    "description": "Events are the event types delivered; empty delivers them all.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "whsec_3q2J9xN0yZ..."
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://hooks.example.com/books"
+                }
+            }
+        },
+        "ports.Credentials": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "ann@example.com"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "correct horse battery"
+                }
+            }
+        },
+        "ports.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code identifies errors clients may want to handle specially, such as\n\"page_size_exceeded\".",
+                    "type": "string",
+                    "example": "page_size_exceeded"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "not found"
+                },
+                "request_id": {
+                    "description": "RequestID is the request's X-Request-ID, to quote when reporting it.",
+                    "type": "string",
+                    "example": "5f2b0c9e8a1d4e7f9b3c6a2d1e0f8b7c"
+                }
+            }
+        },
+        "ports.EventsResponse": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Event"
+                    }
+                },
+                "next": {
+                    "description": "Next is the offset to pass as the next since: the offset of the last\nevent, or since itself when there were none.",
+                    "type": "integer"
+                }
+            }
+        },
+        "ports.FieldConflict": {
+            "type": "object",
+            "properties": {
+                "client": {},
+                "field": {
+                    "type": "string"
+                },
+                "resolution": {
+                    "type": "string"
+                },
+                "server": {}
+            }
+        },
+        "ports.ImportResult": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "integer"
+                },
+                "inserted": {
+                    "type": "integer"
+                },
+                "requeued": {
+                    "type": "integer"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ports.ImportRowResult"
+                    }
+                },
+                "skipped": {
+                    "type": "integer"
+                }
+            }
+        },
+        "ports.ImportRowResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "fields": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "isbn": {
+                    "type": "string"
+                },
+                "row": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "inserted",
+                        "skipped",
+                        "failed",
+                        "requeued"
+                    ]
+                }
+            }
+        },
+        "ports.IssueAPIKeyInput": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "warehouse-sync"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "ports.IssuedAPIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "bfk_3q2J9xN0yZ..."
+                },
+                "name": {
+                    "type": "string",
+                    "example": "warehouse-sync"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "Scopes are granted to requests made with the key, e.g. admin.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "ports.PlaceHoldInput": {
+            "type": "object",
+            "properties": {
+                "book_id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "user_id": {
+                    "description": "UserID queues another user; only editors and admins may set it.\nWithout it the hold is the caller's own.",
+                    "type": "integer",
+                    "example": 7
+                }
+            }
+        },
+        "ports.PublisherInput": {
+            "type": "object",
+            "properties": {
+                "country": {
+                    "description": "Country is an ISO 3166-1 alpha-2 code, in either case.",
+                    "type": "string",
+                    "example": "us"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Ace Books"
+                },
+                "website": {
+                    "type": "string",
+                    "example": "https://www.penguinrandomhouse.com/ace"
+                }
+            }
+        },
+        "ports.PutListPreferencesInput": {
+            "type": "object",
+            "properties": {
+                "collation": {
+                    "type": "string",
+                    "example": ""
+                },
+                "page_size": {
+                    "type": "integer",
+                    "example": 24
+                },
+                "sort": {
+                    "type": "string",
+                    "example": "-publication_year"
+                }
+            }
+        },
+        "ports.PutSearchRankingInput": {
+            "type": "object",
+            "properties": {
+                "author_weight": {
+                    "type": "number",
+                    "example": 1
+                },
+                "recency_boost": {
+                    "type": "number",
+                    "example": 0.5
+                },
+                "title_weight": {
+                    "type": "number",
+                    "example": 3
+                }
+            }
+        },
+        "ports.PutTrackingRulesInput": {
+            "type": "object",
+            "properties": {
+                "hosts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.HostTrackingRules"
+                    }
+                },
+                "keep": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "utm_id"
+                    ]
+                },
+                "strip": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ref",
+                        "src_*"
+                    ]
+                }
+            }
+        },
+        "ports.ReplayInput": {
+            "type": "object",
+            "properties": {
+                "consumer": {
+                    "type": "string",
+                    "example": "authors"
+                },
+                "since": {
+                    "description": "Since is the offset to replay after; 0 replays the whole log.",
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "ports.ReplayResult": {
+            "type": "object",
+            "properties": {
+                "consumer": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "integer"
+                },
+                "since": {
+                    "type": "integer"
+                },
+                "until": {
+                    "description": "Until is the offset of the last event replayed.",
+                    "type": "integer"
+                }
+            }
+        },
+        "ports.RepriceFilter": {
+            "type": "object",
+            "properties": {
+                "all": {
+                    "type": "boolean"
+                },
+                "author": {
+                    "type": "string"
+                },
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "max_price": {
+                    "type": "number"
+                },
+                "min_price": {
+                    "type": "number"
+                },
+                "q": {
+                    "description": "title, author or alias, like GET /books?q=",
                     "type": "string"
                 },
-                "price": {
-                    "type": "number"
+                "year_from": {
+                    "type": "integer"
+                },
+                "year_to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "ports.RepriceItem": {
+            "type": "object",
+            "properties": {
+                "book_id": {
+                    "type": "integer"
+                },
+                "new_price": {
+                    "type": "number"
+                },
+                "old_price": {
+                    "type": "number"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "ports.RepriceRequest": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "filter": {
+                    "$ref": "#/definitions/ports.RepriceFilter"
+                },
+                "rule": {
+                    "$ref": "#/definitions/ports.RepriceRule"
+                }
+            }
+        },
+        "ports.RepriceResponse": {
+            "type": "object",
+            "properties": {
+                "batch_id": {
+                    "type": "string"
+                },
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ports.RepriceItem"
+                    }
+                },
+                "dry_run": {
+                    "type": "boolean"
                 },
-                "publication_year": {
+                "matched": {
                     "type": "integer"
+                }
+            }
+        },
+        "ports.RepriceRule": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": -0.5
                 },
-                "title": {
+                "ending": {
+                    "description": "Ending rounds to the nearest price with these cents, e.g. 0.99 or 0.95.",
+                    "type": "number",
+                    "example": 0.99
+                },
+                "percent": {
+                    "type": "number",
+                    "example": 10
+                }
+            }
+        },
+        "ports.ReturnedLoan": {
+            "type": "object",
+            "properties": {
+                "book_id": {
+                    "type": "integer"
+                },
+                "checked_out_at": {
                     "type": "string"
                 },
-                "updated_at": {
+                "due_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "next_loan": {
+                    "description": "NextLoan is missing if no hold was waiting for the book.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Loan"
+                        }
+                    ]
+                },
+                "returned_at": {
+                    "description": "ReturnedAt is nil while the book is out.",
                     "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "http.cleanupRequest": {
+        "ports.SearchRankings": {
             "type": "object",
             "properties": {
-                "operation": {
-                    "description": "\"redirection\" | \"canonical\" | \"all\"",
+                "defaults": {
+                    "description": "Defaults rank the tenants that aren't listed.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.RankingWeights"
+                        }
+                    ]
+                },
+                "tenants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.SearchRanking"
+                    }
+                }
+            }
+        },
+        "ports.SeriesInput": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "The Expanse"
+                }
+            }
+        },
+        "ports.SplitBookInput": {
+            "type": "object",
+            "properties": {
+                "alias_ids": {
+                    "description": "AliasIDs are aliases that belong to the new edition and move with it.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "fields": {
+                    "$ref": "#/definitions/ports.UpdateBookInput"
+                }
+            }
+        },
+        "ports.SyncChange": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "$ref": "#/definitions/ports.UpdateBookInput"
+                },
+                "base_updated_at": {
+                    "description": "BaseUpdatedAt guards deletes: a server edit after it is a conflict.",
                     "type": "string"
                 },
-                "url": {
+                "client_id": {
+                    "description": "echoed back for creates",
                     "type": "string"
+                },
+                "fields": {
+                    "$ref": "#/definitions/ports.UpdateBookInput"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "op": {
+                    "description": "\"create\" | \"update\" | \"delete\"",
+                    "type": "string",
+                    "example": "update"
                 }
             }
         },
-        "http.cleanupResponse": {
+        "ports.SyncPullResponse": {
             "type": "object",
             "properties": {
-                "processed_url": {
-                    "type": "string"
+                "checkpoint": {
+                    "description": "Checkpoint stays 0 until the last page of an initial sync.",
+                    "type": "integer"
+                },
+                "has_more": {
+                    "type": "boolean"
+                },
+                "snapshot": {
+                    "description": "Snapshot continues an initial sync with more books to send; pass it\nback as the snapshot parameter.",
+                    "type": "string",
+                    "example": "812.340"
+                },
+                "tombstones": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ports.Tombstone"
+                    }
+                },
+                "upserts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Book"
+                    }
                 }
             }
         },
-        "http.validationPayload": {
+        "ports.SyncPushRequest": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ports.SyncChange"
+                    }
+                },
+                "field_strategies": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "strategy": {
+                    "type": "string",
+                    "example": "merge"
+                }
+            }
+        },
+        "ports.SyncPushResponse": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ports.SyncResult"
+                    }
+                }
+            }
+        },
+        "ports.SyncResult": {
             "type": "object",
             "properties": {
+                "book": {
+                    "$ref": "#/definitions/domain.Book"
+                },
+                "client_id": {
+                    "type": "string"
+                },
+                "conflicts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ports.FieldConflict"
+                    }
+                },
                 "error": {
                     "type": "string"
                 },
@@ -326,15 +8264,138 @@ const docTemplate = `{
                     "additionalProperties": {
                         "type": "string"
                     }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "created | applied | merged | deleted | conflict | not_found | rejected",
+                    "type": "string"
                 }
             }
         },
-        "ports.CreateBookInput": {
+        "ports.SynonymInput": {
+            "type": "object",
+            "properties": {
+                "terms": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "C#",
+                        "csharp"
+                    ]
+                }
+            }
+        },
+        "ports.TaxonomyConflict": {
+            "type": "object",
+            "properties": {
+                "current": {
+                    "type": "string",
+                    "example": "Sci-Fi"
+                },
+                "field": {
+                    "type": "string",
+                    "example": "name"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "science-fiction"
+                },
+                "imported": {
+                    "type": "string",
+                    "example": "Science Fiction"
+                },
+                "kind": {
+                    "description": "Kind is category or author.",
+                    "type": "string",
+                    "example": "category"
+                }
+            }
+        },
+        "ports.TaxonomyReport": {
+            "type": "object",
+            "properties": {
+                "authors": {
+                    "$ref": "#/definitions/ports.TermReport"
+                },
+                "categories": {
+                    "$ref": "#/definitions/ports.TermReport"
+                },
+                "conflicts": {
+                    "description": "Conflicts are the differences a merge kept the target's version of.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ports.TaxonomyConflict"
+                    }
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "mode": {
+                    "type": "string",
+                    "example": "merge"
+                }
+            }
+        },
+        "ports.TermReport": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "removed": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "unchanged": {
+                    "type": "integer"
+                },
+                "updated": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "ports.Tombstone": {
+            "type": "object",
+            "properties": {
+                "deleted_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "ports.UpdateBookInput": {
             "type": "object",
             "properties": {
                 "author": {
                     "type": "string"
                 },
+                "base_updated_at": {
+                    "description": "BaseUpdatedAt is the updated_at the client last read. When set, fields\nchanged by someone else after it are rejected with 409 instead of\nsilently overwritten; edits to other fields are merged.",
+                    "type": "string"
+                },
+                "format": {
+                    "description": "Format is hardcover, paperback or ebook; \"\" clears it.",
+                    "type": "string",
+                    "enum": [
+                        "hardcover",
+                        "paperback",
+                        "ebook"
+                    ]
+                },
                 "isbn": {
                     "type": "string"
                 },
@@ -344,27 +8405,146 @@ const docTemplate = `{
                 "publication_year": {
                     "type": "integer"
                 },
+                "publisher_id": {
+                    "description": "PublisherID moves the book to another publisher; 0 removes its\npublisher.",
+                    "type": "integer"
+                },
+                "series_id": {
+                    "description": "SeriesID moves the book to another series; 0 takes it out of its\nseries. SeriesPosition 0 clears the position.",
+                    "type": "integer"
+                },
+                "series_position": {
+                    "type": "integer"
+                },
                 "title": {
                     "type": "string"
+                },
+                "version": {
+                    "description": "Version is the version the client last read (also accepted as an\nIf-Match header). When set, the update is rejected with 409 if the\nbook has been written since, whichever fields changed.",
+                    "type": "integer"
+                },
+                "work_id": {
+                    "description": "WorkID makes the book another edition of the work of this book id;\n0 makes it a standalone book.",
+                    "type": "integer"
                 }
             }
         },
-        "ports.ErrorResponse": {
+        "ports.UpdateProfileInput": {
             "type": "object",
             "properties": {
-                "error": {
+                "current_password": {
                     "type": "string",
-                    "example": "not found"
+                    "example": "correct horse battery"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Ann Lee"
+                },
+                "new_password": {
+                    "description": "NewPassword, when set, replaces the password; CurrentPassword must\nthen be the one it replaces.",
+                    "type": "string",
+                    "example": "correct horse battery staple"
                 }
             }
         },
-        "ports.UpdateBookInput": {
+        "ports.UpdateUserInput": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Ann Lee"
+                },
+                "password": {
+                    "description": "Password, when set, replaces the user's password.",
+                    "type": "string",
+                    "example": "correct horse battery"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "editor"
+                    ]
+                }
+            }
+        },
+        "ports.WebhookInput": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "Active defaults to true on registration and is kept on update.",
+                    "type": "boolean"
+                },
+                "events": {
+                    "description": "Events to deliver, of book.created, book.updated and book.deleted;\nempty delivers them all.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "description": "Secret signs the deliveries, 16 to 255 characters. Registering\nwithout one generates it; updating without one keeps it.",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://hooks.example.com/books"
+                }
+            }
+        },
+        "presenter.BookView": {
             "type": "object",
             "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "author": {
                     "type": "string"
                 },
+                "author_id": {
+                    "description": "AuthorID addresses the author page, see GET /authors/{id}/summary.",
+                    "type": "string",
+                    "example": "robert-c-martin"
+                },
+                "categories": {
+                    "description": "Categories are the slugs the book is filed under, sorted.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "format": {
+                    "description": "Format tells the editions of a work apart: one of BookFormats, or\nempty when unknown.",
+                    "type": "string",
+                    "enum": [
+                        "hardcover",
+                        "paperback",
+                        "ebook"
+                    ]
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_public_domain": {
+                    "type": "boolean"
+                },
+                "is_recent": {
+                    "type": "boolean"
+                },
                 "isbn": {
+                    "description": "ISBN-13, also for books created with an ISBN-10",
+                    "type": "string"
+                },
+                "isbn10": {
+                    "description": "empty for ISBN-13s starting with 979, which have none",
                     "type": "string"
                 },
                 "price": {
@@ -373,8 +8553,70 @@ const docTemplate = `{
                 "publication_year": {
                     "type": "integer"
                 },
+                "publisher_id": {
+                    "description": "PublisherID is the book's publisher; nil when it has none.",
+                    "type": "integer"
+                },
+                "series_id": {
+                    "description": "SeriesID is the series the book belongs to; nil when it has none.",
+                    "type": "integer"
+                },
+                "series_position": {
+                    "description": "SeriesPosition is the book's place in its series, from 1; nil when\nunknown.",
+                    "type": "integer"
+                },
+                "tags": {
+                    "description": "Tags are the book's free-form tags, sorted.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "title": {
                     "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version starts at 1 and goes up by one with every write.",
+                    "type": "integer"
+                },
+                "work_id": {
+                    "description": "WorkID groups editions of the same work; nil for a standalone book.",
+                    "type": "integer"
+                },
+                "years_since_publication": {
+                    "type": "integer"
+                }
+            }
+        },
+        "urlclean.Host": {
+            "type": "object",
+            "properties": {
+                "ascii": {
+                    "description": "ASCII is the punycode form, what DNS and HTTP see.",
+                    "type": "string",
+                    "example": "xn--80ak6aa92e.com"
+                },
+                "confusable": {
+                    "description": "Confusable is set when a label mixes scripts (other than the CJK\ncombinations Japanese and Korean use), or is written in Cyrillic,\nGreek or Armenian letters that all look like Latin ones: the usual\nways to spoof a well-known domain.",
+                    "type": "boolean"
+                },
+                "scripts": {
+                    "description": "Scripts are the Unicode scripts of the host's letters, sorted.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Cyrillic"
+                    ]
+                },
+                "unicode": {
+                    "description": "Unicode is the form to show people.",
+                    "type": "string",
+                    "example": "аррӏе.com"
                 }
             }
         }
@@ -0,0 +1,120 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) aliasRoutes(r chi.Router) {
+	r.Get("/", h.ListAliases)
+	r.With(h.requireEditor).Post("/", h.AddAlias)
+	r.With(h.requireEditor).Delete("/{aliasID}", h.DeleteAlias)
+}
+
+// GET /books/{id}/aliases
+// --- ListAliases ---
+// ListAliases godoc
+// @Summary      List alternate titles of a book
+// @Tags         aliases
+// @Produce      json
+// @Param        id   path      int  true  "Book ID"  minimum(1)
+// @Success      200  {array}   domain.Alias
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /books/{id}/aliases/ [get]
+func (h *Handler) ListAliases(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	aliases, err := h.aliases.ListAliases(r.Context(), id)
+	if err != nil {
+		aliasError(w, err)
+		return
+	}
+	jsonOK(w, aliases)
+}
+
+// POST /books/{id}/aliases
+// --- AddAlias ---
+// AddAlias godoc
+// @Summary      Add an alternate title to a book
+// @Description  Aliases are matched by search and returned in the book's `aliases`. Duplicates (ignoring case and accents) are rejected.
+// @Tags         aliases
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int                  true  "Book ID"  minimum(1)
+// @Param        body  body      ports.AddAliasInput  true  "Alias"
+// @Success      201   {object}  domain.Alias
+// @Failure      400   {object}  ports.ErrorResponse
+// @Failure      403   {object}  ports.ErrorResponse
+// @Failure      404   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Failure      500   {object}  ports.ErrorResponse
+// @Router       /books/{id}/aliases/ [post]
+func (h *Handler) AddAlias(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	var in ports.AddAliasInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	a, err := h.aliases.AddAlias(r.Context(), id, in)
+	if err != nil {
+		aliasError(w, err)
+		return
+	}
+	jsonCreated(w, a)
+}
+
+// DELETE /books/{id}/aliases/{aliasID}
+// --- DeleteAlias ---
+// DeleteAlias godoc
+// @Summary      Remove an alternate title
+// @Tags         aliases
+// @Param        id       path  int  true  "Book ID"   minimum(1)
+// @Param        aliasID  path  int  true  "Alias ID"  minimum(1)
+// @Success      204  "No Content"
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /books/{id}/aliases/{aliasID} [delete]
+func (h *Handler) DeleteAlias(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	aliasID, err := strconv.ParseInt(chi.URLParam(r, "aliasID"), 10, 64)
+	if err != nil || aliasID <= 0 {
+		httpError(w, http.StatusBadRequest, "invalid alias id")
+		return
+	}
+	if err := h.aliases.DeleteAlias(r.Context(), id, aliasID); err != nil {
+		aliasError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func aliasError(w http.ResponseWriter, err error) {
+	if ve, ok := err.(*appsvc.ValidationError); ok {
+		httpValidation(w, ve)
+		return
+	}
+	switch err.Error() {
+	case "book not found", "alias not found":
+		httpError(w, http.StatusNotFound, "not found")
+	default:
+		httpError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type mockAliasService struct {
+	ListAliasesFn func(ctx context.Context, bookID int64) ([]domain.Alias, error)
+	AddAliasFn    func(ctx context.Context, bookID int64, in ports.AddAliasInput) (*domain.Alias, error)
+	DeleteAliasFn func(ctx context.Context, bookID, aliasID int64) error
+}
+
+func (m *mockAliasService) ListAliases(ctx context.Context, bookID int64) ([]domain.Alias, error) {
+	return m.ListAliasesFn(ctx, bookID)
+}
+func (m *mockAliasService) AddAlias(ctx context.Context, bookID int64, in ports.AddAliasInput) (*domain.Alias, error) {
+	return m.AddAliasFn(ctx, bookID, in)
+}
+func (m *mockAliasService) DeleteAlias(ctx context.Context, bookID, aliasID int64) error {
+	return m.DeleteAliasFn(ctx, bookID, aliasID)
+}
+
+func newAliasServer(t *testing.T, aliases ports.AliasService) func(method, path string, body any) (*http.Response, string) {
+	t.Helper()
+	ts := httptest.NewServer(NewHandler(&mockBookService{}, WithAliases(aliases)).Router())
+	t.Cleanup(ts.Close)
+	return func(method, path string, body any) (*http.Response, string) {
+		res := do(t, ts, method, path, body)
+		return res, readBody(t, res)
+	}
+}
+
+func TestAliases_ListAndAdd(t *testing.T) {
+	call := newAliasServer(t, &mockAliasService{
+		ListAliasesFn: func(ctx context.Context, bookID int64) ([]domain.Alias, error) {
+			return []domain.Alias{{ID: 1, BookID: bookID, Alias: "Solitude"}}, nil
+		},
+		AddAliasFn: func(ctx context.Context, bookID int64, in ports.AddAliasInput) (*domain.Alias, error) {
+			if in.Alias == "" {
+				return nil, &appsvc.ValidationError{Fields: map[string]string{"alias": "Alias is required"}}
+			}
+			return &domain.Alias{ID: 2, BookID: bookID, Alias: in.Alias}, nil
+		},
+	})
+
+	res, body := call(http.MethodGet, "/books/3/aliases/", nil)
+	if res.StatusCode != http.StatusOK || !contains(body, `"alias":"Solitude"`) {
+		t.Fatalf("list: %d %s", res.StatusCode, body)
+	}
+	res, body = call(http.MethodPost, "/books/3/aliases/", map[string]string{"alias": "Cien años"})
+	if res.StatusCode != http.StatusCreated || !contains(body, `"book_id":3`) {
+		t.Fatalf("add: %d %s", res.StatusCode, body)
+	}
+	res, body = call(http.MethodPost, "/books/3/aliases/", map[string]string{"alias": ""})
+	if res.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("add invalid: %d %s", res.StatusCode, body)
+	}
+}
+
+func TestAliases_NotFound(t *testing.T) {
+	call := newAliasServer(t, &mockAliasService{
+		ListAliasesFn: func(ctx context.Context, bookID int64) ([]domain.Alias, error) {
+			return nil, errors.New("book not found")
+		},
+		DeleteAliasFn: func(ctx context.Context, bookID, aliasID int64) error {
+			if aliasID == 5 {
+				return nil
+			}
+			return errors.New("alias not found")
+		},
+	})
+
+	cases := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/books/9/aliases/", http.StatusNotFound},
+		{http.MethodDelete, "/books/3/aliases/5", http.StatusNoContent},
+		{http.MethodDelete, "/books/3/aliases/6", http.StatusNotFound},
+		{http.MethodDelete, "/books/3/aliases/x", http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		if res, body := call(c.method, c.path, nil); res.StatusCode != c.want {
+			t.Fatalf("%s %s: %d, want %d (%s)", c.method, c.path, res.StatusCode, c.want, body)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/go-chi/chi/v5"
+)
+
+const headerAPIKey = "X-API-Key"
+
+// APIKeyAuth checks the X-API-Key header against keys. A valid key makes the
+// request run as the actor "key:<name>" with the key's scopes, unless the
+// identity proxy already named a caller; an unknown or revoked key is
+// refused with 401 wherever it is sent. Writes to the catalogue and to the
+// other shared resources under keyedPaths (also under /sandbox) require a
+// key or another identity, and with protectReads so do reads.
+func APIKeyAuth(keys ports.APIKeyService, protectReads bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, identified := domain.ActorFrom(r.Context())
+			if key := r.Header.Get(headerAPIKey); key != "" {
+				k, err := keys.Authenticate(r.Context(), key)
+				if err != nil {
+					httpError(w, http.StatusInternalServerError, err.Error())
+					return
+				}
+				if k == nil {
+					httpError(w, http.StatusUnauthorized, "invalid API key")
+					return
+				}
+				if !identified {
+					r = r.WithContext(domain.WithActor(r.Context(), domain.Actor{ID: "key:" + k.Name, Scopes: k.Scopes}))
+					identified = true
+				}
+			}
+			if !identified && needsAPIKey(r, protectReads) {
+				w.Header().Set("WWW-Authenticate", headerAPIKey)
+				httpError(w, http.StatusUnauthorized, "API key required")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// keyedPaths are the path prefixes APIKeyAuth guards.
+var keyedPaths = []string{
+	"/books", "/sync/books", "/publishers", "/series", "/taxonomy",
+	"/loans", "/holds", "/saved-searches", "/me/list-preferences",
+}
+
+func needsAPIKey(r *http.Request, protectReads bool) bool {
+	path := strings.TrimPrefix(r.URL.Path, "/sandbox")
+	if !slices.ContainsFunc(keyedPaths, func(p string) bool { return strings.HasPrefix(path, p) }) {
+		return false
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return protectReads
+	}
+	return true
+}
+
+func (h *Handler) apiKeyRoutes(r chi.Router) {
+	r.Use(requireScope(domain.ScopeAdmin))
+	r.Get("/", h.ListAPIKeys)
+	r.Post("/", h.IssueAPIKey)
+	r.Delete("/{id}", h.RevokeAPIKey)
+}
+
+// GET /admin/api-keys
+// --- ListAPIKeys ---
+// ListAPIKeys godoc
+// @Summary      List API keys
+// @Description  Keys themselves are never shown again after they are issued. Requires the admin scope.
+// @Tags         admin
+// @Produce      json
+// @Success      200  {array}   domain.APIKey
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /admin/api-keys/ [get]
+func (h *Handler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.apiKeys.ListAPIKeys(r.Context())
+	if err != nil {
+		apiKeyError(w, err)
+		return
+	}
+	jsonOK(w, keys)
+}
+
+// POST /admin/api-keys
+// --- IssueAPIKey ---
+// IssueAPIKey godoc
+// @Summary      Issue an API key
+// @Description  The response carries the key in the clear; store it, it can't be retrieved later. Requires the admin scope.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        body  body      ports.IssueAPIKeyInput  true  "Key name and scopes"
+// @Success      201   {object}  ports.IssuedAPIKey
+// @Failure      400   {object}  ports.ErrorResponse
+// @Failure      403   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Failure      500   {object}  ports.ErrorResponse
+// @Router       /admin/api-keys/ [post]
+func (h *Handler) IssueAPIKey(w http.ResponseWriter, r *http.Request) {
+	var in ports.IssueAPIKeyInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	k, err := h.apiKeys.IssueAPIKey(r.Context(), in)
+	if err != nil {
+		apiKeyError(w, err)
+		return
+	}
+	jsonCreated(w, k)
+}
+
+// DELETE /admin/api-keys/{id}
+// --- RevokeAPIKey ---
+// RevokeAPIKey godoc
+// @Summary      Revoke an API key
+// @Description  Requests with the key are refused from now on. Requires the admin scope.
+// @Tags         admin
+// @Param        id   path  int  true  "API key ID"  minimum(1)
+// @Success      204  "No Content"
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /admin/api-keys/{id} [delete]
+func (h *Handler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	if err := h.apiKeys.RevokeAPIKey(r.Context(), id); err != nil {
+		apiKeyError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func apiKeyError(w http.ResponseWriter, err error) {
+	var ve *appsvc.ValidationError
+	switch {
+	case errors.As(err, &ve):
+		httpValidation(w, ve)
+	case err.Error() == "api key not found":
+		httpError(w, http.StatusNotFound, err.Error())
+	default:
+		httpError(w, http.StatusInternalServerError, err.Error())
+	}
+}
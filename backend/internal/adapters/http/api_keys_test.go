@@ -0,0 +1,130 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/docs"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type mockAPIKeyService struct {
+	AuthenticateFn func(ctx context.Context, key string) (*domain.APIKey, error)
+	IssueFn        func(ctx context.Context, in ports.IssueAPIKeyInput) (*ports.IssuedAPIKey, error)
+	ListFn         func(ctx context.Context) ([]domain.APIKey, error)
+	RevokeFn       func(ctx context.Context, id int64) error
+}
+
+func (m *mockAPIKeyService) Authenticate(ctx context.Context, key string) (*domain.APIKey, error) {
+	return m.AuthenticateFn(ctx, key)
+}
+func (m *mockAPIKeyService) IssueAPIKey(ctx context.Context, in ports.IssueAPIKeyInput) (*ports.IssuedAPIKey, error) {
+	return m.IssueFn(ctx, in)
+}
+func (m *mockAPIKeyService) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	return m.ListFn(ctx)
+}
+func (m *mockAPIKeyService) RevokeAPIKey(ctx context.Context, id int64) error {
+	return m.RevokeFn(ctx, id)
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	keys := &mockAPIKeyService{
+		AuthenticateFn: func(ctx context.Context, key string) (*domain.APIKey, error) {
+			if key != "bfk_good" {
+				return nil, nil
+			}
+			return &domain.APIKey{ID: 1, Name: "warehouse", Scopes: domain.ScopeList{"admin"}}, nil
+		},
+	}
+	cases := []struct {
+		name         string
+		protectReads bool
+		method, path string
+		headers      map[string]string
+		status       int
+		actor        string
+	}{
+		{"public read", false, http.MethodGet, "/books/", nil, http.StatusOK, ""},
+		{"protected read", true, http.MethodGet, "/books/1", nil, http.StatusUnauthorized, ""},
+		{"write without key", false, http.MethodPost, "/books/", nil, http.StatusUnauthorized, ""},
+		{"sandbox write without key", false, http.MethodDelete, "/sandbox/books/1", nil, http.StatusUnauthorized, ""},
+		{"sync push without key", false, http.MethodPost, "/sync/books", nil, http.StatusUnauthorized, ""},
+		{"publisher write without key", false, http.MethodPost, "/publishers/", nil, http.StatusUnauthorized, ""},
+		{"series write without key", false, http.MethodPut, "/series/1", nil, http.StatusUnauthorized, ""},
+		{"taxonomy import without key", false, http.MethodPost, "/taxonomy/import", nil, http.StatusUnauthorized, ""},
+		{"loan without key", false, http.MethodPost, "/loans/", nil, http.StatusUnauthorized, ""},
+		{"hold cancel without key", false, http.MethodDelete, "/holds/1", nil, http.StatusUnauthorized, ""},
+		{"saved search without key", false, http.MethodPost, "/saved-searches/", nil, http.StatusUnauthorized, ""},
+		{"list preferences without key", false, http.MethodPut, "/me/list-preferences/", nil, http.StatusUnauthorized, ""},
+		{"public publisher read", false, http.MethodGet, "/publishers/", nil, http.StatusOK, ""},
+		{"write with key", false, http.MethodPost, "/books/", map[string]string{"X-API-Key": "bfk_good"}, http.StatusOK, "key:warehouse"},
+		{"bad key on a public read", false, http.MethodGet, "/books/", map[string]string{"X-API-Key": "bfk_bad"}, http.StatusUnauthorized, ""},
+		{"proxy identity", false, http.MethodPut, "/books/1", map[string]string{"X-User": "ann"}, http.StatusOK, "ann"},
+		{"other routes", false, http.MethodPost, "/url/cleanup", nil, http.StatusOK, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var got domain.Actor
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = domain.ActorFrom(r.Context())
+			})
+			req := httptest.NewRequest(c.method, c.path, nil)
+			for k, v := range c.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			Identify(true)(APIKeyAuth(keys, c.protectReads)(next)).ServeHTTP(rec, req)
+			if rec.Code != c.status || got.ID != c.actor {
+				t.Fatalf("status %d, actor %q; want %d, %q (%s)", rec.Code, got.ID, c.status, c.actor, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestAPIKeys_AdminEndpoints(t *testing.T) {
+	keys := &mockAPIKeyService{
+		AuthenticateFn: func(ctx context.Context, key string) (*domain.APIKey, error) {
+			return &domain.APIKey{ID: 1, Name: "root", Scopes: domain.ScopeList{domain.ScopeAdmin}}, nil
+		},
+		IssueFn: func(ctx context.Context, in ports.IssueAPIKeyInput) (*ports.IssuedAPIKey, error) {
+			return &ports.IssuedAPIKey{APIKey: domain.APIKey{ID: 2, Name: in.Name, Scopes: domain.ScopeList{}}, Key: "bfk_new"}, nil
+		},
+		ListFn: func(ctx context.Context) ([]domain.APIKey, error) {
+			return []domain.APIKey{{ID: 2, Name: "warehouse", Hash: "secret", Scopes: domain.ScopeList{}}}, nil
+		},
+		RevokeFn: func(ctx context.Context, id int64) error { return nil },
+	}
+	v, err := NewSpecValidator([]byte(docs.SwaggerInfo.ReadDoc()))
+	if err != nil {
+		t.Fatalf("NewSpecValidator: %v", err)
+	}
+	ts := httptest.NewServer(APIKeyAuth(keys, false)(v.Middleware(NewHandler(&mockBookService{}, WithAPIKeys(keys)).Router())))
+	defer ts.Close()
+
+	call := func(method, path string, body []byte) (int, string) {
+		req, _ := http.NewRequest(method, ts.URL+path, bytes.NewReader(body))
+		req.Header.Set("X-API-Key", "bfk_root")
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("do request: %v", err)
+		}
+		return res.StatusCode, readBody(t, res)
+	}
+	if code, body := call(http.MethodPost, "/admin/api-keys/", []byte(`{"name":"warehouse"}`)); code != http.StatusCreated || !contains(body, `"key":"bfk_new"`) {
+		t.Fatalf("issue: %d %s", code, body)
+	}
+	if code, body := call(http.MethodGet, "/admin/api-keys/", nil); code != http.StatusOK || contains(body, "secret") {
+		t.Fatalf("list: %d %s", code, body)
+	}
+	if code, _ := call(http.MethodDelete, "/admin/api-keys/2", nil); code != http.StatusNoContent {
+		t.Fatalf("revoke: %d", code)
+	}
+}
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// parseAsOf reads ?as_of=, an RFC 3339 instant. set is false without one;
+// ok is false once an error response has been written.
+func (h *Handler) parseAsOf(w http.ResponseWriter, r *http.Request) (t time.Time, set, ok bool) {
+	v := r.URL.Query().Get("as_of")
+	if v == "" {
+		return t, false, true
+	}
+	if h.bookHistory == nil {
+		httpError(w, http.StatusBadRequest, "as_of needs the change log, which this deployment doesn't keep")
+		return t, true, false
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		httpError(w, http.StatusBadRequest, "as_of must be an RFC 3339 time, e.g. 2024-01-01T00:00:00Z")
+		return t, true, false
+	}
+	return t, true, true
+}
+
+// asOfError answers a failed history read.
+func asOfError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ports.ErrBeyondHistory) {
+		httpErrorCode(w, http.StatusGone, "beyond_history", err.Error())
+		return
+	}
+	httpError(w, http.StatusInternalServerError, err.Error())
+}
+
+// getBookAsOf answers GET /books/{id}?as_of=. The book is rebuilt from the
+// change log, so it has no ETag: it can't be the target of an If-Match.
+func (h *Handler) getBookAsOf(w http.ResponseWriter, r *http.Request, id int64, t time.Time) {
+	book, err := h.bookHistory.BookAsOf(r.Context(), id, t)
+	if err != nil {
+		asOfError(w, err)
+		return
+	}
+	if book == nil {
+		httpError(w, http.StatusNotFound, "not found at "+t.UTC().Format(time.RFC3339))
+		return
+	}
+	jsonOK(w, h.presentBook(r, *book))
+}
+
+// listBooksAsOf answers GET /books?as_of= with every book that existed then.
+func (h *Handler) listBooksAsOf(w http.ResponseWriter, r *http.Request, t time.Time) {
+	books, err := h.bookHistory.BooksAsOf(r.Context(), t)
+	if err != nil {
+		asOfError(w, err)
+		return
+	}
+	w.Header().Set("X-Total-Count", strconv.Itoa(len(books)))
+	jsonOK(w, h.presentBooks(r, books))
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type mockBookHistory struct {
+	books  map[int64]domain.Book
+	before time.Time // instants before this are beyond history
+}
+
+func (m *mockBookHistory) BookAsOf(ctx context.Context, id int64, t time.Time) (*domain.Book, error) {
+	if t.Before(m.before) {
+		return nil, ports.ErrBeyondHistory
+	}
+	if b, ok := m.books[id]; ok {
+		return &b, nil
+	}
+	return nil, nil
+}
+
+func (m *mockBookHistory) BooksAsOf(ctx context.Context, t time.Time) ([]domain.Book, error) {
+	if t.Before(m.before) {
+		return nil, ports.ErrBeyondHistory
+	}
+	return []domain.Book{m.books[1]}, nil
+}
+
+func TestAsOf(t *testing.T) {
+	bh := &mockBookHistory{
+		books:  map[int64]domain.Book{1: {ID: 1, Title: "Dune (first edition)", Price: 10}},
+		before: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	current := &mockBookService{
+		GetBookFn: func(ctx context.Context, id int64) (*domain.Book, error) {
+			return &domain.Book{ID: id, Title: "Dune", Price: 12}, nil
+		},
+	}
+	// the default page size must not count as paging
+	ts := newSpecServer(t, current, WithBookHistory(bh), WithPageLimits(ports.PageLimits{Default: 50, Max: 200}))
+	defer ts.Close()
+
+	cases := []struct {
+		path     string
+		want     int
+		contains string
+	}{
+		{"/books/1/", http.StatusOK, `"title":"Dune"`},
+		{"/books/1/?as_of=2024-01-01T00:00:00Z", http.StatusOK, `"title":"Dune (first edition)"`},
+		{"/books/2/?as_of=2024-01-01T00:00:00Z", http.StatusNotFound, "not found at 2024-01-01T00:00:00Z"},
+		{"/books/1/?as_of=2020-01-01T00:00:00Z", http.StatusGone, `"code":"beyond_history"`},
+		{"/books/1/?as_of=yesterday", http.StatusBadRequest, "RFC 3339"},
+		{"/books/?as_of=2024-01-01T00:00:00Z", http.StatusOK, `"price":10`},
+		{"/books/?as_of=2024-01-01T00:00:00Z&limit=5", http.StatusBadRequest, "can't be combined"},
+		{"/books/?as_of=2020-01-01T00:00:00Z", http.StatusGone, "beyond_history"},
+	}
+	for _, tc := range cases {
+		res := do(t, ts, http.MethodGet, tc.path, nil)
+		body := readBody(t, res)
+		if res.StatusCode != tc.want || !contains(body, tc.contains) {
+			t.Fatalf("%s: status = %d, body = %s", tc.path, res.StatusCode, body)
+		}
+		if tc.want == http.StatusOK && contains(tc.path, "as_of") && res.Header.Get("ETag") != "" {
+			t.Fatalf("%s: historical read has an ETag", tc.path)
+		}
+	}
+}
+
+func TestAsOf_DisabledWithoutHistory(t *testing.T) {
+	ts := newTestServer(t, &mockBookService{})
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodGet, "/books/1/?as_of=2024-01-01T00:00:00Z", nil)
+	if body := readBody(t, res); res.StatusCode != http.StatusBadRequest || !contains(body, "change log") {
+		t.Fatalf("status = %d, body = %s", res.StatusCode, body)
+	}
+}
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// GET /books/{id}/history
+// --- BookAudit ---
+// BookAudit godoc
+// @Summary      Audit trail of a book
+// @Description  Every create, update and delete of the book, newest first, with who made it, when, and the fields it changed with their old and new values. Entries outlive the book, so a deleted book's trail stays readable. Pass the `id` of the last entry as `before` for the next page. Requires the admin scope.
+// @Tags         admin
+// @Produce      json
+// @Param        id      path      int  true   "Book ID"  minimum(1)
+// @Param        before  query     int  false  "Only entries older than this entry id"  minimum(1)
+// @Param        limit   query     int  false  "Max entries (default 50, max 500)"  minimum(1)
+// @Success      200     {array}   domain.AuditEntry
+// @Failure      400     {object}  ports.ErrorResponse
+// @Failure      403     {object}  ports.ErrorResponse
+// @Failure      500     {object}  ports.ErrorResponse
+// @Router       /books/{id}/history [get]
+func (h *Handler) BookAudit(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	q := r.URL.Query()
+	var before int64
+	if v := q.Get("before"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 1 {
+			httpError(w, http.StatusBadRequest, "before must be a positive integer")
+			return
+		}
+		before = n
+	}
+	limit := 0
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			httpError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+	entries, err := h.audit.BookAudit(r.Context(), id, before, limit)
+	if err != nil {
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, entries)
+}
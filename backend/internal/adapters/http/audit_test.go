@@ -0,0 +1,58 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/docs"
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+type mockAuditService func(ctx context.Context, id int64, before int64, limit int) ([]domain.AuditEntry, error)
+
+func (m mockAuditService) BookAudit(ctx context.Context, id int64, before int64, limit int) ([]domain.AuditEntry, error) {
+	return m(ctx, id, before, limit)
+}
+
+func TestBookAudit(t *testing.T) {
+	var gotBefore int64
+	var gotLimit int
+	audit := mockAuditService(func(ctx context.Context, id int64, before int64, limit int) ([]domain.AuditEntry, error) {
+		gotBefore, gotLimit = before, limit
+		at := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+		return []domain.AuditEntry{
+			{ID: 8, BookID: id, Op: domain.AuditDeleted, Actor: "ann", Changes: domain.AuditDiff{"title": {Old: "Dune"}}, CreatedAt: at},
+		}, nil
+	})
+	v, err := NewSpecValidator([]byte(docs.SwaggerInfo.ReadDoc()))
+	if err != nil {
+		t.Fatalf("NewSpecValidator: %v", err)
+	}
+	ts := httptest.NewServer(Identify(true)(v.Middleware(NewHandler(&mockBookService{}, WithAuditTrail(audit)).Router())))
+	defer ts.Close()
+
+	call := func(path, scopes string) (int, string) {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		req.Header.Set("X-User", "ops")
+		req.Header.Set("X-User-Scopes", scopes)
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("do request: %v", err)
+		}
+		return res.StatusCode, readBody(t, res)
+	}
+
+	if code, _ := call("/books/1/history", ""); code != http.StatusForbidden {
+		t.Fatalf("without admin = %d", code)
+	}
+	code, body := call("/books/1/history?before=9&limit=20", domain.ScopeAdmin)
+	if code != http.StatusOK || gotBefore != 9 || gotLimit != 20 || !contains(body, `"op":"deleted"`) || !contains(body, `"changes":{"title":{"old":"Dune","new":null}}`) {
+		t.Fatalf("history = %d %s", code, body)
+	}
+	if code, _ := call("/books/1/history?before=0", domain.ScopeAdmin); code != http.StatusBadRequest {
+		t.Fatalf("bad before = %d", code)
+	}
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// BearerAuth checks "Authorization: Bearer <token>" with auth. A valid token
+// makes the request run as the user it was issued to, unless the identity
+// proxy already named a caller; an invalid or expired token, or one of a
+// deleted user, is refused with 401. Requests without a token pass through
+// unidentified, so APIKeyAuth decides what they may do.
+func BearerAuth(auth ports.AuthService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+			if !found || !strings.EqualFold(scheme, "Bearer") {
+				next.ServeHTTP(w, r)
+				return
+			}
+			actor, err := auth.Verify(r.Context(), strings.TrimSpace(token))
+			if errors.Is(err, ports.ErrInvalidToken) {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				httpError(w, http.StatusUnauthorized, "invalid or expired token")
+				return
+			}
+			if err != nil {
+				httpError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			if _, identified := domain.ActorFrom(r.Context()); !identified {
+				r = r.WithContext(domain.WithActor(r.Context(), *actor))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// POST /auth/register
+// --- Register ---
+// Register godoc
+// @Summary      Create a user account
+// @Description  The password needs at least 8 characters. New users have no scopes. Log in with POST /auth/login to get a token.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body      ports.Credentials  true  "Email and password"
+// @Success      201   {object}  domain.User
+// @Failure      400   {object}  ports.ErrorResponse
+// @Failure      409   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Failure      500   {object}  ports.ErrorResponse
+// @Router       /auth/register [post]
+func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
+	var in ports.Credentials
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	u, err := h.auth.Register(r.Context(), in)
+	if err != nil {
+		authError(w, err)
+		return
+	}
+	jsonCreated(w, u)
+}
+
+// POST /auth/login
+// --- Login ---
+// Login godoc
+// @Summary      Log in
+// @Description  Returns a signed JWT. Send it as `Authorization: Bearer <token>` to act as the user until it expires.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body      ports.Credentials  true  "Email and password"
+// @Success      200   {object}  ports.AuthToken
+// @Failure      400   {object}  ports.ErrorResponse
+// @Failure      401   {object}  ports.ErrorResponse
+// @Failure      500   {object}  ports.ErrorResponse
+// @Router       /auth/login [post]
+func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
+	var in ports.Credentials
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	tok, err := h.auth.Login(r.Context(), in)
+	if err != nil {
+		authError(w, err)
+		return
+	}
+	jsonOK(w, tok)
+}
+
+func authError(w http.ResponseWriter, err error) {
+	var ve *appsvc.ValidationError
+	switch {
+	case errors.As(err, &ve):
+		httpValidation(w, ve)
+	case errors.Is(err, ports.ErrEmailTaken):
+		httpError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, ports.ErrInvalidCredentials):
+		httpError(w, http.StatusUnauthorized, err.Error())
+	default:
+		httpError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,119 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type mockAuthService struct {
+	RegisterFn func(ctx context.Context, in ports.Credentials) (*domain.User, error)
+	LoginFn    func(ctx context.Context, in ports.Credentials) (*ports.AuthToken, error)
+	VerifyFn   func(ctx context.Context, token string) (*domain.Actor, error)
+	ActorFn    func(ctx context.Context, email string) (*domain.Actor, error)
+}
+
+func (m *mockAuthService) Register(ctx context.Context, in ports.Credentials) (*domain.User, error) {
+	return m.RegisterFn(ctx, in)
+}
+func (m *mockAuthService) Login(ctx context.Context, in ports.Credentials) (*ports.AuthToken, error) {
+	return m.LoginFn(ctx, in)
+}
+func (m *mockAuthService) Verify(ctx context.Context, token string) (*domain.Actor, error) {
+	return m.VerifyFn(ctx, token)
+}
+func (m *mockAuthService) Actor(ctx context.Context, email string) (*domain.Actor, error) {
+	return m.ActorFn(ctx, email)
+}
+
+func TestBearerAuth(t *testing.T) {
+	auth := &mockAuthService{VerifyFn: func(ctx context.Context, token string) (*domain.Actor, error) {
+		switch token {
+		case "good":
+			return &domain.Actor{ID: "ann@example.com"}, nil
+		case "unchecked":
+			return nil, errors.New("database down")
+		}
+		return nil, fmt.Errorf("%w: token expired", ports.ErrInvalidToken)
+	}}
+	cases := []struct {
+		name, authorization, user string
+		status                    int
+		actor                     string
+	}{
+		{"no token", "", "", http.StatusOK, ""},
+		{"basic auth is ignored", "Basic YW5uOng=", "", http.StatusOK, ""},
+		{"valid token", "Bearer good", "", http.StatusOK, "ann@example.com"},
+		{"lower-case scheme", "bearer good", "", http.StatusOK, "ann@example.com"},
+		{"bad token", "Bearer bad", "", http.StatusUnauthorized, ""},
+		{"user lookup fails", "Bearer unchecked", "", http.StatusInternalServerError, ""},
+		{"proxy identity wins", "Bearer good", "bob", http.StatusOK, "bob"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var got domain.Actor
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = domain.ActorFrom(r.Context())
+			})
+			req := httptest.NewRequest(http.MethodPost, "/books/", nil)
+			if c.authorization != "" {
+				req.Header.Set("Authorization", c.authorization)
+			}
+			if c.user != "" {
+				req.Header.Set("X-User", c.user)
+			}
+			rec := httptest.NewRecorder()
+			Identify(true)(BearerAuth(auth)(next)).ServeHTTP(rec, req)
+			if rec.Code != c.status || got.ID != c.actor {
+				t.Fatalf("status %d, actor %q; want %d, %q", rec.Code, got.ID, c.status, c.actor)
+			}
+		})
+	}
+}
+
+func TestAuth_RegisterAndLogin(t *testing.T) {
+	auth := &mockAuthService{
+		RegisterFn: func(ctx context.Context, in ports.Credentials) (*domain.User, error) {
+			if in.Email == "taken@example.com" {
+				return nil, ports.ErrEmailTaken
+			}
+			return &domain.User{ID: 1, Email: in.Email, PasswordHash: "secret", Scopes: domain.ScopeList{}}, nil
+		},
+		LoginFn: func(ctx context.Context, in ports.Credentials) (*ports.AuthToken, error) {
+			if in.Password != "correct horse" {
+				return nil, ports.ErrInvalidCredentials
+			}
+			return &ports.AuthToken{Token: "t", TokenType: "Bearer", ExpiresAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}, nil
+		},
+	}
+	ts := newSpecServer(t, &mockBookService{}, WithAuth(auth))
+	defer ts.Close()
+
+	post := func(path, body string) (int, string) {
+		res, err := http.Post(ts.URL+path, "application/json", bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatalf("post: %v", err)
+		}
+		return res.StatusCode, readBody(t, res)
+	}
+	if code, body := post("/auth/register", `{"email":"ann@example.com","password":"correct horse"}`); code != http.StatusCreated || contains(body, "secret") {
+		t.Fatalf("register: %d %s", code, body)
+	}
+	if code, _ := post("/auth/register", `{"email":"taken@example.com","password":"correct horse"}`); code != http.StatusConflict {
+		t.Fatalf("register taken: %d", code)
+	}
+	if code, body := post("/auth/login", `{"email":"ann@example.com","password":"correct horse"}`); code != http.StatusOK || !contains(body, `"token_type":"Bearer"`) {
+		t.Fatalf("login: %d %s", code, body)
+	}
+	if code, _ := post("/auth/login", `{"email":"ann@example.com","password":"nope"}`); code != http.StatusUnauthorized {
+		t.Fatalf("bad login: %d", code)
+	}
+}
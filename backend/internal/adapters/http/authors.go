@@ -0,0 +1,38 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/go-chi/chi/v5"
+)
+
+// GET /authors/{id}/summary
+// --- AuthorSummary ---
+// AuthorSummary godoc
+// @Summary      Author page summary
+// @Description  The author's books, publication year span and price range from a precomputed read model. The id is the author slug, also returned as `author_id` on books. Updates appear shortly after the books change.
+// @Tags         authors
+// @Produce      json
+// @Param        id   path      string  true  "Author slug"  example(gabriel-garcia-marquez)
+// @Success      200  {object}  domain.AuthorSummary
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /authors/{id}/summary [get]
+func (h *Handler) AuthorSummary(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" || domain.AuthorSlug(id) != id {
+		httpError(w, http.StatusNotFound, "author not found")
+		return
+	}
+	s, err := h.authors.AuthorSummary(r.Context(), id)
+	if err != nil {
+		if err.Error() == "author not found" {
+			httpError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, s)
+}
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+type mockAuthorService struct {
+	AuthorSummaryFn func(ctx context.Context, id string) (*domain.AuthorSummary, error)
+}
+
+func (m *mockAuthorService) AuthorSummary(ctx context.Context, id string) (*domain.AuthorSummary, error) {
+	return m.AuthorSummaryFn(ctx, id)
+}
+
+func TestAuthorSummary(t *testing.T) {
+	authors := &mockAuthorService{AuthorSummaryFn: func(ctx context.Context, id string) (*domain.AuthorSummary, error) {
+		switch id {
+		case "robert-c-martin":
+			year := 2008
+			return &domain.AuthorSummary{ID: id, Name: "Robert C. Martin", BookCount: 1, FirstYear: &year, LastYear: &year,
+				Books: []domain.AuthorBook{{ID: 1, Title: "Clean Code"}}}, nil
+		case "broken":
+			return nil, errors.New("db down")
+		}
+		return nil, errors.New("author not found")
+	}}
+	ts := httptest.NewServer(NewHandler(&mockBookService{}, WithAuthors(authors)).Router())
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodGet, "/authors/robert-c-martin/summary", nil)
+	body := readBody(t, res)
+	if res.StatusCode != http.StatusOK || !contains(body, `"first_year":2008`) || !contains(body, `"title":"Clean Code"`) {
+		t.Fatalf("status = %d, body = %s", res.StatusCode, body)
+	}
+
+	for path, want := range map[string]int{
+		"/authors/nobody/summary":          http.StatusNotFound,
+		"/authors/Robert%20Martin/summary": http.StatusNotFound, // not a slug
+		"/authors/broken/summary":          http.StatusInternalServerError,
+	} {
+		res := do(t, ts, http.MethodGet, path, nil)
+		readBody(t, res)
+		if res.StatusCode != want {
+			t.Fatalf("%s: status = %d, want %d", path, res.StatusCode, want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// POST /books/bulk-tag
+// --- BulkTagBooks ---
+// BulkTagBooks godoc
+// @Summary      Bulk add and remove book categories
+// @Description  Adds and removes categories on every book matching the filter, which works as in POST /books/reprice. Categories are given by name or slug and stored as slugs, e.g. "Science Fiction" as science-fiction. Runs as a preview unless `dry_run` is false: the preview counts the books that would change and lists the first 100 with their categories after. Applying starts a background job, changing 100 books per transaction, and answers 202 with the job; poll its Location for progress. Every changed book gets a change-log entry.
+// @Tags         books
+// @Accept       json
+// @Produce      json
+// @Param        body  body      ports.BulkTagRequest  true  "Filter and categories"
+// @Success      200   {object}  ports.BulkTagResponse  "Preview, or nothing to change"
+// @Success      202   {object}  ports.BulkTagResponse  "Job started"
+// @Header       202   {string}  Location  "URL of the job's progress"
+// @Failure      400   {object}  ports.ErrorResponse
+// @Failure      403   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Failure      500   {object}  ports.ErrorResponse
+// @Failure      503   {object}  ports.ErrorResponse
+// @Router       /books/bulk-tag [post]
+func (h *Handler) BulkTagBooks(w http.ResponseWriter, r *http.Request) {
+	var in ports.BulkTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	resp, err := h.bulkTag.BulkTag(r.Context(), in)
+	var ve *appsvc.ValidationError
+	switch {
+	case errors.As(err, &ve):
+		httpValidation(w, ve)
+		return
+	case errors.Is(err, ports.ErrDraining):
+		w.Header().Set("Retry-After", "30")
+		httpError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	case err != nil:
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Job == nil {
+		jsonOK(w, resp)
+		return
+	}
+	w.Header().Set("Location", "/books/bulk-tag/"+resp.Job.ID)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// GET /books/bulk-tag/{job}
+// --- BulkTagJob ---
+// BulkTagJob godoc
+// @Summary      Progress of a bulk tag job
+// @Description  Status is running until the job ends as done, failed (with the error) or interrupted by a shutdown. Re-running an interrupted or failed job is safe: books it already changed are left as they are.
+// @Tags         books
+// @Produce      json
+// @Param        job  path      string  true  "Job ID"
+// @Success      200  {object}  domain.BulkJob
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /books/bulk-tag/{job} [get]
+func (h *Handler) BulkTagJob(w http.ResponseWriter, r *http.Request) {
+	job, err := h.bulkTag.Job(r.Context(), chi.URLParam(r, "job"))
+	if err != nil {
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if job == nil {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+	jsonOK(w, job)
+}
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type mockBulkTag struct {
+	got ports.BulkTagRequest
+}
+
+func (m *mockBulkTag) BulkTag(ctx context.Context, in ports.BulkTagRequest) (*ports.BulkTagResponse, error) {
+	m.got = in
+	switch {
+	case len(in.Add) == 0:
+		return nil, &appsvc.ValidationError{Fields: map[string]string{"add": "Give categories to add or remove"}}
+	case in.Add[0] == "late":
+		return nil, ports.ErrDraining
+	}
+	resp := &ports.BulkTagResponse{DryRun: in.DryRun == nil || *in.DryRun, Matched: 3, Changed: 2,
+		Preview: []ports.BulkTagItem{{BookID: 1, Title: "Dune", Categories: []string{"science-fiction"}}}}
+	if !resp.DryRun {
+		resp.Job = &domain.BulkJob{ID: "job1", Kind: "bulk_tag", Status: domain.JobRunning, Total: 2}
+	}
+	return resp, nil
+}
+
+func (m *mockBulkTag) Job(ctx context.Context, id string) (*domain.BulkJob, error) {
+	if id != "job1" {
+		return nil, nil
+	}
+	return &domain.BulkJob{ID: id, Kind: "bulk_tag", Status: domain.JobDone, Total: 2, Done: 2}, nil
+}
+
+func TestBulkTag(t *testing.T) {
+	bt := &mockBulkTag{}
+	ts := newSpecServer(t, &mockBookService{}, WithBulkTag(bt))
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodPost, "/books/bulk-tag", map[string]any{"filter": map[string]any{"author": "Frank Herbert"}, "add": []string{"Science Fiction"}})
+	if body := readBody(t, res); res.StatusCode != http.StatusOK || !contains(body, `"dry_run":true`) || !contains(body, `"changed":2`) {
+		t.Fatalf("preview: %d %s", res.StatusCode, body)
+	}
+	if bt.got.Filter.Author != "Frank Herbert" {
+		t.Fatalf("filter = %+v", bt.got.Filter)
+	}
+
+	res = do(t, ts, http.MethodPost, "/books/bulk-tag", map[string]any{"filter": map[string]any{"all": true}, "add": []string{"poetry"}, "dry_run": false})
+	if body := readBody(t, res); res.StatusCode != http.StatusAccepted || res.Header.Get("Location") != "/books/bulk-tag/job1" || !contains(body, `"status":"running"`) {
+		t.Fatalf("apply: %d %s", res.StatusCode, body)
+	}
+	res = do(t, ts, http.MethodGet, "/books/bulk-tag/job1", nil)
+	if body := readBody(t, res); res.StatusCode != http.StatusOK || !contains(body, `"done":2`) {
+		t.Fatalf("job: %d %s", res.StatusCode, body)
+	}
+	if res := do(t, ts, http.MethodGet, "/books/bulk-tag/nope", nil); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("unknown job: %d", res.StatusCode)
+	}
+
+	res = do(t, ts, http.MethodPost, "/books/bulk-tag", map[string]any{"filter": map[string]any{"all": true}})
+	if body := readBody(t, res); res.StatusCode != http.StatusUnprocessableEntity || !contains(body, "add") {
+		t.Fatalf("invalid: %d %s", res.StatusCode, body)
+	}
+	res = do(t, ts, http.MethodPost, "/books/bulk-tag", map[string]any{"filter": map[string]any{"all": true}, "add": []string{"late"}, "dry_run": false})
+	if res.StatusCode != http.StatusServiceUnavailable || res.Header.Get("Retry-After") == "" {
+		t.Fatalf("draining: %d", res.StatusCode)
+	}
+}
@@ -0,0 +1,84 @@
+package http
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/export"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// capabilities completes the deployment's capabilities with what the
+// handler itself offers.
+func (h *Handler) capabilities() domain.Capabilities {
+	c := h.deployment
+	c.Features = slices.Clone(c.Features)
+	for name, on := range map[string]bool{
+		"aliases":          h.aliases != nil,
+		"as_of":            h.bookHistory != nil,
+		"audit_trail":      h.audit != nil,
+		"author_summaries": h.authors != nil,
+		"bulk_tag":         h.bulkTag != nil,
+		"change_feed":      h.changes != nil,
+		"events":           h.events != nil,
+		"favorites":        h.favorites != nil,
+		"holds":            h.holds != nil,
+		"list_preferences": h.listPrefs != nil,
+		"loans":            h.loans != nil,
+		"metadata_lookup":  h.metadata != nil,
+		"price_history":    h.priceHistory != nil,
+		"publishers":       h.publishers != nil,
+		"reprice":          h.reprice != nil,
+		"saved_searches":   h.searches != nil,
+		"search_insights":  h.insights != nil,
+		"search_ranking":   h.ranking != nil,
+		"series":           h.series != nil,
+		"status":           h.status != nil,
+		"suggestions":      h.suggester != nil,
+		"sync":             h.sync != nil,
+		"synonyms":         h.synonyms != nil,
+		"tags":             h.tags != nil,
+		"taxonomy":         h.taxonomy != nil,
+		"tracking_rules":   h.trackingRules != nil,
+		"url_extract":      h.extractor != nil,
+		"url_history":      h.history != nil,
+		"url_resolve":      h.resolver != nil,
+		"user_accounts":    h.auth != nil,
+		"user_management":  h.users != nil,
+		"webhooks":         h.webhooks != nil,
+	} {
+		if on {
+			c.Features = append(c.Features, name)
+		}
+	}
+	slices.Sort(c.Features)
+	c.Features = slices.Compact(c.Features)
+
+	c.Auth.Modes = slices.Clone(c.Auth.Modes)
+	if h.auth != nil && !slices.Contains(c.Auth.Modes, "bearer") {
+		c.Auth.Modes = append(c.Auth.Modes, "bearer")
+	}
+	if c.Auth.Modes == nil {
+		c.Auth.Modes = []string{}
+	}
+	c.Auth.Roles = h.roles
+	c.Pagination = domain.PaginationCapabilities{MaxPageSize: h.pageLimits.Max, DefaultPageSize: h.pageLimits.Default}
+	c.Search.SortFields = ports.SortFields
+	c.Search.Collations = ports.SortCollations
+	c.Export = domain.ExportCapabilities{Formats: export.BookExportFormats, ImportFormats: export.BookImportFormats}
+	return c
+}
+
+// GET /.well-known/api-capabilities
+// --- Capabilities ---
+// Capabilities godoc
+// @Summary      What this deployment offers
+// @Description  The optional features enabled, how book search runs, the accepted ways to identify, pagination limits and the book file formats, derived from the configuration. Clients can adapt to them instead of hardcoding environment differences.
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  domain.Capabilities
+// @Router       /.well-known/api-capabilities [get]
+func (h *Handler) Capabilities(w http.ResponseWriter, r *http.Request) {
+	jsonOK(w, h.capabilities())
+}
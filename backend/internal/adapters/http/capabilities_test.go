@@ -0,0 +1,47 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+func TestCapabilities(t *testing.T) {
+	deployment := domain.Capabilities{
+		Version:  "1.4.0",
+		Features: []string{"sandbox", "compression"},
+		Search:   domain.SearchCapabilities{Backend: "mysql"},
+		Auth:     domain.AuthCapabilities{Modes: []string{"api_key"}, APIKeyRequired: "writes"},
+	}
+	ts := newSpecServer(t, &mockBookService{},
+		WithCapabilities(deployment), WithURLHistory(&mockURLHistory{}), WithStatus(&mockStatus{}), WithRoles())
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodGet, "/.well-known/api-capabilities", nil)
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", res.StatusCode)
+	}
+	var got domain.Capabilities
+	if err := json.NewDecoder(res.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if want := []string{"compression", "sandbox", "status", "url_history"}; !slices.Equal(got.Features, want) {
+		t.Errorf("features = %v; want %v", got.Features, want)
+	}
+	if got.Version != "1.4.0" || got.Search.Backend != "mysql" || !slices.Contains(got.Search.SortFields, "title") {
+		t.Errorf("search = %+v", got.Search)
+	}
+	if !slices.Equal(got.Auth.Modes, []string{"api_key"}) || got.Auth.APIKeyRequired != "writes" || !got.Auth.Roles {
+		t.Errorf("auth = %+v", got.Auth)
+	}
+	if !slices.Equal(got.Export.Formats, []string{"csv", "xlsx"}) || !slices.Equal(got.Export.ImportFormats, []string{"csv", "json"}) {
+		t.Errorf("export = %+v", got.Export)
+	}
+	if len(deployment.Features) != 2 {
+		t.Errorf("the handler changed the deployment's features: %v", deployment.Features)
+	}
+}
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/docs"
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+type mockCleanupStats struct {
+	recorded []string
+}
+
+func (m *mockCleanupStats) Record(host, operation, outcome string) {
+	m.recorded = append(m.recorded, host+" "+operation+" "+outcome)
+}
+
+func (m *mockCleanupStats) CleanupStats(ctx context.Context, days, top int) (*domain.CleanupStats, error) {
+	if days > appsvc.MaxCleanupStatsDays {
+		return nil, &appsvc.ValidationError{Fields: map[string]string{"days": "days must be between 1 and 90"}}
+	}
+	return &domain.CleanupStats{
+		Requests: 4, Errors: 1, ErrorRate: 0.25,
+		TopDomains: []domain.CleanupCount{{Name: "example.com", Requests: 4, Errors: 1, ErrorRate: 0.25}},
+		Operations: []domain.CleanupCount{{Name: "all", Requests: 4, Errors: 1, ErrorRate: 0.25}},
+		Rules:      []domain.CleanupCount{},
+	}, nil
+}
+
+func TestCleanupStats(t *testing.T) {
+	stats := &mockCleanupStats{}
+	v, err := NewSpecValidator([]byte(docs.SwaggerInfo.ReadDoc()))
+	if err != nil {
+		t.Fatalf("NewSpecValidator: %v", err)
+	}
+	ts := httptest.NewServer(Identify(true)(v.Middleware(NewHandler(&mockBookService{}, WithCleanupStats(stats)).Router())))
+	defer ts.Close()
+
+	do(t, ts, http.MethodPost, "/url/cleanup", map[string]any{"url": "https://Example.com/a/", "operation": "all"})
+	do(t, ts, http.MethodPost, "/url/cleanup", map[string]any{"url": "ftp://example.com", "operation": "shorten"})
+	if got := strings.Join(stats.recorded, ", "); got != "Example.com all ok,  shorten invalid" {
+		t.Fatalf("recorded = %q", got)
+	}
+
+	call := func(path, scopes string) (int, string) {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		req.Header.Set("X-User", "ops")
+		req.Header.Set("X-User-Scopes", scopes)
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("do request: %v", err)
+		}
+		return res.StatusCode, readBody(t, res)
+	}
+	if code, _ := call("/url/cleanup/stats", ""); code != http.StatusForbidden {
+		t.Fatalf("non-admin: %d", code)
+	}
+	if code, body := call("/url/cleanup/stats?days=7&top=5", "admin"); code != http.StatusOK ||
+		!contains(body, `"top_domains":[{"name":"example.com","requests":4,"errors":1,"error_rate":0.25}]`) {
+		t.Fatalf("stats: %d %s", code, body)
+	}
+	if code, _ := call("/url/cleanup/stats?days=x", "admin"); code != http.StatusBadRequest {
+		t.Fatalf("days=x: %d", code)
+	}
+	if code, body := call("/url/cleanup/stats?days=365", "admin"); code != http.StatusUnprocessableEntity || !contains(body, `"days"`) {
+		t.Fatalf("days=365: %d %s", code, body)
+	}
+}
@@ -0,0 +1,39 @@
+package http
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Compress gzips (or deflates) book listings and exports for clients that
+// send a matching Accept-Encoding, at the given gzip level; 0 turns
+// compression off and levels above 9 are capped. Other endpoints answer with
+// small bodies and are left alone. It must be the outermost layer so the
+// envelope and the spec validator still see plain JSON.
+func Compress(level int) func(http.Handler) http.Handler {
+	if level <= 0 {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	// xlsx exports are zip archives already
+	compress := middleware.Compress(min(level, gzip.BestCompression), "application/json", "text/csv")
+	return func(next http.Handler) http.Handler {
+		compressed := compress(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet && compressible(r.URL.Path) {
+				compressed.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// compressible reports whether path is a book listing or export, also under
+// a mount prefix such as /sandbox.
+func compressible(path string) bool {
+	path = strings.TrimSuffix(path, "/")
+	return strings.HasSuffix(path, "/books") || strings.HasSuffix(path, "/books/export")
+}
@@ -0,0 +1,57 @@
+package http
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+func TestCompress(t *testing.T) {
+	svc := &mockBookService{
+		ListBooksFn: func(ctx context.Context) ([]domain.Book, error) {
+			return []domain.Book{{ID: 1, Title: strings.Repeat("Ficciones ", 200)}}, nil
+		},
+		GetBookFn: func(ctx context.Context, id int64) (*domain.Book, error) {
+			return &domain.Book{ID: id, Title: strings.Repeat("Ficciones ", 200)}, nil
+		},
+	}
+	router := NewHandler(svc).Router()
+	get := func(level int, path, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if accept != "" {
+			req.Header.Set("Accept-Encoding", accept)
+		}
+		rec := httptest.NewRecorder()
+		Compress(level)(router).ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get(5, "/books/", "gzip")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("list: %d, encoding %q", rec.Code, rec.Header().Get("Content-Encoding"))
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("gzip reader: %v", err)
+	}
+	body, _ := io.ReadAll(zr)
+	if !contains(string(body), `"id":1`) {
+		t.Fatalf("decompressed body = %s", body)
+	}
+
+	for name, rec := range map[string]*httptest.ResponseRecorder{
+		"not accepted":   get(5, "/books/", ""),
+		"single book":    get(5, "/books/1", "gzip"),
+		"level 0 is off": get(0, "/books/", "gzip"),
+	} {
+		if enc := rec.Header().Get("Content-Encoding"); enc != "" || !contains(rec.Body.String(), "Ficciones") {
+			t.Fatalf("%s: encoding %q, body %.40s", name, enc, rec.Body.String())
+		}
+	}
+}
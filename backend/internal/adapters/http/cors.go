@@ -0,0 +1,68 @@
+package http
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSOptions are the origins allowed to call the API from a browser and
+// what they may send.
+type CORSOptions struct {
+	AllowedOrigins   []string // exact origins like https://app.example.com, or "*" for any
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool          // let browsers send cookies and Authorization
+	MaxAge           time.Duration // how long browsers reuse a preflight answer
+}
+
+// corsExposed are the response headers scripts on other origins may read.
+var corsExposed = []string{"ETag", "Link", "X-Total-Count", "X-Search-Suggestions", "X-Request-ID", "Retry-After", "Deprecation", "Sunset", "Content-Disposition"}
+
+// CORS adds CORS headers to the responses to allowed origins, and answers
+// their preflight requests with 204 itself, before authentication, since
+// browsers send preflights without credentials. Requests from other origins
+// pass through without CORS headers, so browsers block their responses. No
+// allowed origins turns CORS off.
+func CORS(opts CORSOptions) func(http.Handler) http.Handler {
+	if len(opts.AllowedOrigins) == 0 {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	anyOrigin := slices.Contains(opts.AllowedOrigins, "*")
+	methods := strings.Join(opts.AllowedMethods, ", ")
+	headers := strings.Join(opts.AllowedHeaders, ", ")
+	exposed := strings.Join(corsExposed, ", ")
+	maxAge := strconv.Itoa(int(opts.MaxAge.Seconds()))
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			if origin == "" || !(anyOrigin || slices.Contains(opts.AllowedOrigins, origin)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if anyOrigin && !opts.AllowCredentials {
+				h.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				h.Set("Access-Control-Allow-Origin", origin)
+			}
+			if opts.AllowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				h.Add("Vary", "Access-Control-Request-Method")
+				h.Add("Vary", "Access-Control-Request-Headers")
+				h.Set("Access-Control-Allow-Methods", methods)
+				h.Set("Access-Control-Allow-Headers", headers)
+				h.Set("Access-Control-Max-Age", maxAge)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			h.Set("Access-Control-Expose-Headers", exposed)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORS(t *testing.T) {
+	reached := false
+	h := CORS(CORSOptions{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{"GET", "POST"},
+		AllowedHeaders: []string{"Content-Type", "X-API-Key"},
+		MaxAge:         10 * time.Minute,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { reached = true }))
+	send := func(method, origin string, preflight bool) *httptest.ResponseRecorder {
+		reached = false
+		req := httptest.NewRequest(method, "/books/", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if preflight {
+			req.Header.Set("Access-Control-Request-Method", "POST")
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := send(http.MethodOptions, "https://app.example.com", true)
+	if rec.Code != http.StatusNoContent || reached ||
+		rec.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" ||
+		rec.Header().Get("Access-Control-Allow-Methods") != "GET, POST" ||
+		rec.Header().Get("Access-Control-Allow-Headers") != "Content-Type, X-API-Key" ||
+		rec.Header().Get("Access-Control-Max-Age") != "600" {
+		t.Fatalf("preflight: %d reached=%v %v", rec.Code, reached, rec.Header())
+	}
+
+	rec = send(http.MethodGet, "https://app.example.com", false)
+	if !reached || rec.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" ||
+		!contains(rec.Header().Get("Access-Control-Expose-Headers"), "ETag") || rec.Header().Get("Vary") != "Origin" {
+		t.Fatalf("simple request: reached=%v %v", reached, rec.Header())
+	}
+
+	for _, origin := range []string{"https://evil.example.com", ""} {
+		rec = send(http.MethodOptions, origin, true)
+		if !reached || rec.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Fatalf("origin %q: reached=%v %v", origin, reached, rec.Header())
+		}
+	}
+
+	wildcard := CORS(CORSOptions{AllowedOrigins: []string{"*"}})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req := httptest.NewRequest(http.MethodGet, "/books/", nil)
+	req.Header.Set("Origin", "https://anywhere.example.com")
+	rec = httptest.NewRecorder()
+	wildcard.ServeHTTP(rec, req)
+	if rec.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Fatalf("any origin: %v", rec.Header())
+	}
+
+	off := CORS(CORSOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	rec = httptest.NewRecorder()
+	off.ServeHTTP(rec, req)
+	if rec.Header().Get("Access-Control-Allow-Origin") != "" || rec.Header().Get("Vary") != "" {
+		t.Fatalf("disabled: %v", rec.Header())
+	}
+}
@@ -0,0 +1,159 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) deadLetterRoutes(r chi.Router) {
+	r.Use(requireScope(domain.ScopeAdmin))
+	r.Get("/", h.ListDeadLetters)
+	r.Get("/{id}", h.GetDeadLetter)
+	r.Post("/{id}/retry", h.RetryDeadLetter)
+	r.Delete("/{id}", h.DiscardDeadLetter)
+}
+
+// requireScope answers 403 unless the caller's actor has scope.
+func requireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if actor, ok := domain.ActorFrom(r.Context()); !ok || !actor.HasScope(scope) {
+				httpError(w, http.StatusForbidden, "requires the "+scope+" scope")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// GET /admin/dlq
+// --- ListDeadLetters ---
+// ListDeadLetters godoc
+// @Summary      List failed async work
+// @Description  Newest first. Requires the admin scope.
+// @Tags         admin
+// @Produce      json
+// @Param        kind   query     string  false  "Only dead letters of this kind, e.g. search_notification or catalog_event"
+// @Param        limit  query     int     false  "Max dead letters (default 50, max 500)"  minimum(1)
+// @Success      200    {array}   domain.DeadLetter
+// @Failure      400    {object}  ports.ErrorResponse
+// @Failure      403    {object}  ports.ErrorResponse
+// @Failure      500    {object}  ports.ErrorResponse
+// @Router       /admin/dlq/ [get]
+func (h *Handler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			httpError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+	dls, err := h.deadLetters.ListDeadLetters(r.Context(), r.URL.Query().Get("kind"), limit)
+	if err != nil {
+		deadLetterError(w, err)
+		return
+	}
+	jsonOK(w, dls)
+}
+
+// GET /admin/dlq/{id}
+// --- GetDeadLetter ---
+// GetDeadLetter godoc
+// @Summary      Get a dead letter
+// @Description  Requires the admin scope.
+// @Tags         admin
+// @Produce      json
+// @Param        id   path      int  true  "Dead letter ID"  minimum(1)
+// @Success      200  {object}  domain.DeadLetter
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /admin/dlq/{id} [get]
+func (h *Handler) GetDeadLetter(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	dl, err := h.deadLetters.GetDeadLetter(r.Context(), id)
+	if err != nil {
+		deadLetterError(w, err)
+		return
+	}
+	jsonOK(w, dl)
+}
+
+// POST /admin/dlq/{id}/retry
+// --- RetryDeadLetter ---
+// RetryDeadLetter godoc
+// @Summary      Run failed async work again
+// @Description  On success the dead letter is removed. If the work fails again it stays, with the new error and one more attempt, and the call answers 502. Requires the admin scope.
+// @Tags         admin
+// @Produce      json
+// @Param        id   path  int  true  "Dead letter ID"  minimum(1)
+// @Success      204  "No Content"
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      422  {object}  validationPayload
+// @Failure      500  {object}  ports.ErrorResponse
+// @Failure      502  {object}  ports.ErrorResponse
+// @Router       /admin/dlq/{id}/retry [post]
+func (h *Handler) RetryDeadLetter(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	if err := h.deadLetters.RetryDeadLetter(r.Context(), id); err != nil {
+		deadLetterError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DELETE /admin/dlq/{id}
+// --- DiscardDeadLetter ---
+// DiscardDeadLetter godoc
+// @Summary      Discard failed async work
+// @Description  Requires the admin scope.
+// @Tags         admin
+// @Param        id   path  int  true  "Dead letter ID"  minimum(1)
+// @Success      204  "No Content"
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /admin/dlq/{id} [delete]
+func (h *Handler) DiscardDeadLetter(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	if err := h.deadLetters.DiscardDeadLetter(r.Context(), id); err != nil {
+		deadLetterError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func deadLetterError(w http.ResponseWriter, err error) {
+	var ve *appsvc.ValidationError
+	var rf *appsvc.RetryFailedError
+	switch {
+	case errors.As(err, &ve):
+		httpValidation(w, ve)
+	case errors.As(err, &rf):
+		httpError(w, http.StatusBadGateway, err.Error())
+	case err.Error() == "dead letter not found":
+		httpError(w, http.StatusNotFound, err.Error())
+	default:
+		httpError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/docs"
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+type mockDeadLetterService struct {
+	ListFn    func(ctx context.Context, kind string, limit int) ([]domain.DeadLetter, error)
+	GetFn     func(ctx context.Context, id int64) (*domain.DeadLetter, error)
+	RetryFn   func(ctx context.Context, id int64) error
+	DiscardFn func(ctx context.Context, id int64) error
+}
+
+func (m *mockDeadLetterService) ListDeadLetters(ctx context.Context, kind string, limit int) ([]domain.DeadLetter, error) {
+	return m.ListFn(ctx, kind, limit)
+}
+func (m *mockDeadLetterService) GetDeadLetter(ctx context.Context, id int64) (*domain.DeadLetter, error) {
+	return m.GetFn(ctx, id)
+}
+func (m *mockDeadLetterService) RetryDeadLetter(ctx context.Context, id int64) error {
+	return m.RetryFn(ctx, id)
+}
+func (m *mockDeadLetterService) DiscardDeadLetter(ctx context.Context, id int64) error {
+	return m.DiscardFn(ctx, id)
+}
+
+func TestDeadLetters_AdminConsole(t *testing.T) {
+	letter := domain.DeadLetter{
+		ID: 3, Kind: appsvc.KindSearchNotification, Payload: domain.ChangePayload(`{"search":{"id":1}}`),
+		Error: "smtp down", Attempts: 1, CreatedAt: time.Unix(0, 0).UTC(), LastAttemptAt: time.Unix(0, 0).UTC(),
+	}
+	var gotKind string
+	dlq := &mockDeadLetterService{
+		ListFn: func(ctx context.Context, kind string, limit int) ([]domain.DeadLetter, error) {
+			gotKind = kind
+			return []domain.DeadLetter{letter}, nil
+		},
+		GetFn: func(ctx context.Context, id int64) (*domain.DeadLetter, error) {
+			if id != 3 {
+				return nil, errors.New("dead letter not found")
+			}
+			return &letter, nil
+		},
+		RetryFn: func(ctx context.Context, id int64) error {
+			switch id {
+			case 3:
+				return nil
+			case 4:
+				return &appsvc.RetryFailedError{Err: errors.New("smtp down")}
+			}
+			return &appsvc.ValidationError{Fields: map[string]string{"kind": "can't be retried"}}
+		},
+		DiscardFn: func(ctx context.Context, id int64) error { return nil },
+	}
+	v, err := NewSpecValidator([]byte(docs.SwaggerInfo.ReadDoc()))
+	if err != nil {
+		t.Fatalf("NewSpecValidator: %v", err)
+	}
+	ts := httptest.NewServer(Identify(true)(v.Middleware(NewHandler(&mockBookService{}, WithDeadLetters(dlq)).Router())))
+	defer ts.Close()
+
+	call := func(method, path, scopes string) (int, string) {
+		req, _ := http.NewRequest(method, ts.URL+path, nil)
+		req.Header.Set("X-User", "ops")
+		req.Header.Set("X-User-Scopes", scopes)
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("do request: %v", err)
+		}
+		return res.StatusCode, readBody(t, res)
+	}
+
+	if code, _ := call(http.MethodGet, "/admin/dlq/", "write"); code != http.StatusForbidden {
+		t.Fatalf("non-admin list: %d", code)
+	}
+	if code, body := call(http.MethodGet, "/admin/dlq/?kind=search_notification", "admin"); code != http.StatusOK ||
+		!contains(body, `"error":"smtp down"`) || gotKind != "search_notification" {
+		t.Fatalf("list: %d %s", code, body)
+	}
+	if code, body := call(http.MethodGet, "/admin/dlq/3", "admin"); code != http.StatusOK || !contains(body, `"payload":{"search":{"id":1}}`) {
+		t.Fatalf("get: %d %s", code, body)
+	}
+	for path, want := range map[string]int{
+		"/admin/dlq/9":       http.StatusNotFound,
+		"/admin/dlq/3/retry": http.StatusNoContent,
+		"/admin/dlq/4/retry": http.StatusBadGateway,
+		"/admin/dlq/5/retry": http.StatusUnprocessableEntity,
+	} {
+		method := http.MethodPost
+		if path == "/admin/dlq/9" {
+			method = http.MethodGet
+		}
+		if code, body := call(method, path, "admin"); code != want {
+			t.Fatalf("%s %s: %d %s; want %d", method, path, code, body, want)
+		}
+	}
+	if code, _ := call(http.MethodDelete, "/admin/dlq/3", "admin"); code != http.StatusNoContent {
+		t.Fatalf("discard: %d", code)
+	}
+}
@@ -0,0 +1,68 @@
+package http
+
+import (
+	"expvar"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ---- Deprecation / Sunset ----
+
+// deprecatedHits counts calls to deprecated endpoints, keyed by "METHOD pattern".
+var deprecatedHits = expvar.NewMap("deprecated_requests")
+
+// Deprecation describes a retired endpoint. Successor and Doc may contain
+// route params ({id}) which are filled in from the current request.
+type Deprecation struct {
+	Since     time.Time // Deprecation header (RFC 9745)
+	Sunset    time.Time // optional, Sunset header (RFC 8594)
+	Successor string    // optional, Link rel="successor-version"
+	Doc       string    // optional, Link rel="deprecation"
+}
+
+// Deprecated marks the wrapped routes as deprecated:
+//
+//	r.With(Deprecated(Deprecation{Since: ..., Successor: "/v1/books/{id}"})).Get("/", h.GetBook)
+func Deprecated(d Deprecation) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", fmt.Sprintf("@%d", d.Since.Unix()))
+			if !d.Sunset.IsZero() {
+				w.Header().Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+			}
+			if d.Successor != "" {
+				w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, expandRouteParams(r, d.Successor)))
+			}
+			if d.Doc != "" {
+				w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="deprecation"`, expandRouteParams(r, d.Doc)))
+			}
+
+			next.ServeHTTP(w, r)
+
+			// the full pattern is only known once routing has finished
+			pattern := r.URL.Path
+			if rc := chi.RouteContext(r.Context()); rc != nil && rc.RoutePattern() != "" {
+				pattern = rc.RoutePattern()
+			}
+			deprecatedHits.Add(r.Method+" "+pattern, 1)
+		})
+	}
+}
+
+func expandRouteParams(r *http.Request, tpl string) string {
+	rc := chi.RouteContext(r.Context())
+	if rc == nil {
+		return tpl
+	}
+	for i, k := range rc.URLParams.Keys {
+		if k == "*" {
+			continue
+		}
+		tpl = strings.ReplaceAll(tpl, "{"+k+"}", rc.URLParams.Values[i])
+	}
+	return tpl
+}
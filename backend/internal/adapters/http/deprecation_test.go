@@ -0,0 +1,67 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestDeprecated_Headers(t *testing.T) {
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	r := chi.NewRouter()
+	r.With(Deprecated(Deprecation{
+		Since:     since,
+		Sunset:    sunset,
+		Successor: "/v1/items/{id}",
+		Doc:       "https://docs.example.com/migrate",
+	})).Get("/items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	before := deprecatedCount("GET /items/{id}")
+	res := do(t, ts, http.MethodGet, "/items/42", nil)
+	res.Body.Close()
+
+	if got := res.Header.Get("Deprecation"); got != "@1735689600" {
+		t.Fatalf("Deprecation = %q", got)
+	}
+	if got := res.Header.Get("Sunset"); got != "Mon, 30 Jun 2025 00:00:00 GMT" {
+		t.Fatalf("Sunset = %q", got)
+	}
+	links := res.Header.Values("Link")
+	if len(links) != 2 ||
+		links[0] != `</v1/items/42>; rel="successor-version"` ||
+		links[1] != `<https://docs.example.com/migrate>; rel="deprecation"` {
+		t.Fatalf("Link = %q", links)
+	}
+	if got := deprecatedCount("GET /items/{id}"); got != before+1 {
+		t.Fatalf("deprecated_requests = %d, want %d", got, before+1)
+	}
+}
+
+func TestDeprecated_OptionalHeadersOmitted(t *testing.T) {
+	h := Deprecated(Deprecation{Since: time.Unix(100, 0)})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/x", nil))
+
+	if rec.Header().Get("Deprecation") != "@100" {
+		t.Fatalf("Deprecation = %q", rec.Header().Get("Deprecation"))
+	}
+	if rec.Header().Get("Sunset") != "" || rec.Header().Get("Link") != "" {
+		t.Fatalf("unexpected headers: %v", rec.Header())
+	}
+}
+
+func deprecatedCount(key string) int64 {
+	if v, ok := deprecatedHits.Get(key).(interface{ Value() int64 }); ok {
+		return v.Value()
+	}
+	return 0
+}
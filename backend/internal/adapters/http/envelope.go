@@ -0,0 +1,179 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// headerEnvelope opts a single request in (true) or out (false) of enveloped
+// responses.
+const headerEnvelope = "X-Envelope"
+
+// envelope is the response shape some legacy clients require. Data is null
+// on errors and Errors is empty on success.
+type envelope struct {
+	Data   json.RawMessage `json:"data"`
+	Meta   envelopeMeta    `json:"meta"`
+	Errors []envelopeError `json:"errors"`
+}
+
+type envelopeMeta struct {
+	Status int               `json:"status"`
+	Total  *int              `json:"total,omitempty"`
+	Limit  *int              `json:"limit,omitempty"`
+	Offset *int              `json:"offset,omitempty"`
+	Links  map[string]string `json:"links,omitempty"`
+	// Suggestions are what a search that found nothing may have meant.
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+type envelopeError struct {
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
+}
+
+// Envelope wraps the JSON responses of next as {"data", "meta", "errors"}
+// for requests sending X-Envelope: true, or for all requests when always is
+// set (X-Envelope: false then opts back out). Pagination headers and search
+// suggestions are copied into meta. Non-JSON responses such as exports and
+// labels, and empty ones, pass through untouched, as does everything for
+// clients that don't ask.
+func Envelope(always bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			want := always
+			if v, err := strconv.ParseBool(r.Header.Get(headerEnvelope)); err == nil {
+				want = v
+			}
+			if !want {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ew := &envelopeWriter{ResponseWriter: w}
+			next.ServeHTTP(ew, r)
+			if ew.buffering {
+				ew.flushEnvelope(r)
+			}
+		})
+	}
+}
+
+type envelopeWriter struct {
+	http.ResponseWriter
+	status    int
+	decided   bool
+	buffering bool
+	buf       bytes.Buffer
+}
+
+func (ew *envelopeWriter) WriteHeader(code int) {
+	if ew.decided {
+		return
+	}
+	ew.decided, ew.status = true, code
+	ct := ew.Header().Get("Content-Type")
+	ew.buffering = code != http.StatusNoContent && strings.HasPrefix(ct, "application/json")
+	if !ew.buffering {
+		ew.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (ew *envelopeWriter) Write(p []byte) (int, error) {
+	if !ew.decided {
+		ew.WriteHeader(http.StatusOK)
+	}
+	if ew.buffering {
+		return ew.buf.Write(p)
+	}
+	return ew.ResponseWriter.Write(p)
+}
+
+// Flush keeps streaming responses streaming; buffered ones are sent whole.
+func (ew *envelopeWriter) Flush() {
+	if f, ok := ew.ResponseWriter.(http.Flusher); ok && !ew.buffering {
+		f.Flush()
+	}
+}
+
+func (ew *envelopeWriter) flushEnvelope(r *http.Request) {
+	body := bytes.TrimSpace(ew.buf.Bytes())
+	if !json.Valid(body) {
+		ew.ResponseWriter.WriteHeader(ew.status)
+		_, _ = ew.ResponseWriter.Write(ew.buf.Bytes())
+		return
+	}
+	env := envelope{Meta: envelopeMeta{Status: ew.status}, Errors: []envelopeError{}}
+	if ew.status >= 400 {
+		env.Data = json.RawMessage("null")
+		env.Errors = envelopeErrors(body)
+	} else {
+		env.Data = body
+		env.Meta.addPagination(ew.Header(), r)
+		if v := ew.Header().Get(headerSuggestions); v != "" {
+			env.Meta.Suggestions = parseSuggestions(v)
+		}
+	}
+	ew.Header().Del("Content-Length")
+	ew.ResponseWriter.WriteHeader(ew.status)
+	_ = json.NewEncoder(ew.ResponseWriter).Encode(env)
+}
+
+// envelopeErrors flattens the API's error bodies ({"error", "fields"}) into
+// one entry per offending field, or a single entry without one.
+func envelopeErrors(body []byte) []envelopeError {
+	var e struct {
+		Error  string          `json:"error"`
+		Fields json.RawMessage `json:"fields"`
+	}
+	_ = json.Unmarshal(body, &e)
+	var byField map[string]string
+	if json.Unmarshal(e.Fields, &byField) == nil && len(byField) > 0 {
+		fields := make([]string, 0, len(byField))
+		for f := range byField {
+			fields = append(fields, f)
+		}
+		sort.Strings(fields)
+		out := make([]envelopeError, len(fields))
+		for i, f := range fields {
+			out[i] = envelopeError{Message: byField[f], Field: f}
+		}
+		return out
+	}
+	var fields []string
+	if json.Unmarshal(e.Fields, &fields) == nil && len(fields) > 0 {
+		out := make([]envelopeError, len(fields))
+		for i, f := range fields {
+			out[i] = envelopeError{Message: e.Error, Field: f}
+		}
+		return out
+	}
+	return []envelopeError{{Message: e.Error}}
+}
+
+var linkRel = regexp.MustCompile(`<([^>]*)>;\s*rel="([^"]+)"`)
+
+func (m *envelopeMeta) addPagination(h http.Header, r *http.Request) {
+	total, err := strconv.Atoi(h.Get("X-Total-Count"))
+	if err != nil {
+		return
+	}
+	m.Total = &total
+	q := r.URL.Query()
+	if n, err := strconv.Atoi(q.Get("limit")); err == nil {
+		m.Limit = &n
+	}
+	if n, err := strconv.Atoi(q.Get("offset")); err == nil {
+		m.Offset = &n
+	}
+	for _, l := range linkRel.FindAllStringSubmatch(h.Get("Link"), -1) {
+		if m.Links == nil {
+			m.Links = map[string]string{}
+		}
+		m.Links[l[2]] = l[1]
+	}
+}
@@ -0,0 +1,119 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+func newEnvelopeServer(t *testing.T, always bool) *httptest.Server {
+	t.Helper()
+	mock := &mockBookService{
+		ListPageFn: func(ctx context.Context, f ports.ListFilter, page ports.Page) (*ports.BookPage, error) {
+			if f.YearFrom != nil {
+				return nil, &appsvc.ValidationError{Fields: map[string]string{"year_to": "b", "year_from": "a"}}
+			}
+			return &ports.BookPage{Books: []domain.Book{{ID: 7, Title: "G"}}, Total: 25}, nil
+		},
+		GetBookFn:    func(ctx context.Context, id int64) (*domain.Book, error) { return nil, nil },
+		DeleteBookFn: func(ctx context.Context, id int64) error { return nil },
+	}
+	ts := httptest.NewServer(Envelope(always)(NewHandler(mock).Router()))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func getEnvelope(t *testing.T, ts *httptest.Server, method, path, envelopeHeader string) (*http.Response, envelope) {
+	t.Helper()
+	req, _ := http.NewRequest(method, ts.URL+path, nil)
+	if envelopeHeader != "" {
+		req.Header.Set("X-Envelope", envelopeHeader)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	var env envelope
+	if res.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(res.Body).Decode(&env); err != nil {
+			t.Fatalf("%s: decode envelope: %v", path, err)
+		}
+	}
+	return res, env
+}
+
+func TestEnvelope_PagedList(t *testing.T) {
+	ts := newEnvelopeServer(t, false)
+
+	res, env := getEnvelope(t, ts, http.MethodGet, "/books/?limit=10&offset=10", "true")
+	if res.StatusCode != http.StatusOK || !contains(string(env.Data), `"title":"G"`) || len(env.Errors) != 0 {
+		t.Fatalf("status %d, envelope %+v", res.StatusCode, env)
+	}
+	m := env.Meta
+	if m.Status != 200 || *m.Total != 25 || *m.Limit != 10 || *m.Offset != 10 ||
+		m.Links["next"] != "/books/?limit=10&offset=20" || m.Links["prev"] != "/books/?limit=10&offset=0" {
+		t.Fatalf("meta = %+v", m)
+	}
+	if res.Header.Get("X-Total-Count") != "25" {
+		t.Fatalf("pagination headers should be kept")
+	}
+}
+
+func TestEnvelope_Errors(t *testing.T) {
+	ts := newEnvelopeServer(t, true)
+
+	res, env := getEnvelope(t, ts, http.MethodGet, "/books/?year_from=1", "")
+	if res.StatusCode != http.StatusUnprocessableEntity || string(env.Data) != "null" || env.Meta.Status != 422 {
+		t.Fatalf("status %d, envelope %+v", res.StatusCode, env)
+	}
+	if len(env.Errors) != 2 || env.Errors[0].Field != "year_from" || env.Errors[0].Message != "a" {
+		t.Fatalf("errors = %+v", env.Errors)
+	}
+
+	_, env = getEnvelope(t, ts, http.MethodGet, "/books/5/", "")
+	if len(env.Errors) != 1 || env.Errors[0].Message != "not found" || env.Meta.Status != 404 {
+		t.Fatalf("not found envelope = %+v", env)
+	}
+
+	if res, _ := getEnvelope(t, ts, http.MethodDelete, "/books/5/", ""); res.StatusCode != http.StatusNoContent {
+		t.Fatalf("delete status = %d", res.StatusCode)
+	}
+}
+
+func TestEnvelope_RawUnlessAsked(t *testing.T) {
+	for _, c := range []struct {
+		always bool
+		header string
+	}{{false, ""}, {true, "false"}} {
+		ts := newEnvelopeServer(t, c.always)
+		res := do(t, ts, http.MethodGet, "/books/?limit=1", nil)
+		if c.header != "" {
+			req, _ := http.NewRequest(http.MethodGet, ts.URL+"/books/?limit=1", nil)
+			req.Header.Set("X-Envelope", c.header)
+			res.Body.Close()
+			res, _ = http.DefaultClient.Do(req)
+		}
+		if body := readBody(t, res); len(body) == 0 || body[0] != '[' {
+			t.Fatalf("always=%v header=%q: body = %s", c.always, c.header, body)
+		}
+	}
+}
+
+func TestEnvelope_NonJSONPassesThrough(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("^XA^XZ"))
+	})
+	rec := httptest.NewRecorder()
+	Envelope(true)(next).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/books/1/label.zpl", nil))
+	if rec.Body.String() != "^XA^XZ" {
+		t.Fatalf("body = %q", rec.Body.String())
+	}
+}
@@ -0,0 +1,128 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) eventReplayRoutes(r chi.Router) {
+	r.Use(requireScope(domain.ScopeAdmin))
+	r.Get("/consumers", h.ListEventConsumers)
+	r.Post("/replay", h.ReplayEvents)
+}
+
+// GET /events
+// --- ListEvents ---
+// ListEvents godoc
+// @Summary      Read the domain events after an offset
+// @Description  The change log as domain events (`book.created`, `book.updated`, `book.deleted`), oldest first, for integrations to backfill from. Start at `since=0` and pass `next` as the following `since` until `events` comes back empty. Superseded events older than the change log retention are compacted away, so a backfill from 0 sees at least the latest event of every book.
+// @Tags         events
+// @Produce      json
+// @Param        since  query     int  false  "Offset of the last event seen (default 0)"  minimum(0)
+// @Param        limit  query     int  false  "Max events (default 100, max 500)"  minimum(1)
+// @Success      200    {object}  ports.EventsResponse
+// @Failure      400    {object}  ports.ErrorResponse
+// @Failure      500    {object}  ports.ErrorResponse
+// @Router       /events [get]
+func (h *Handler) ListEvents(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var since int64
+	if v := q.Get("since"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			httpError(w, http.StatusBadRequest, "since must be a non-negative integer")
+			return
+		}
+		since = n
+	}
+	limit := 0
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			httpError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+	res, err := h.events.ListEvents(r.Context(), since, limit)
+	if err != nil {
+		eventError(w, err)
+		return
+	}
+	jsonOK(w, res)
+}
+
+// GET /admin/events/consumers
+// --- ListEventConsumers ---
+// ListEventConsumers godoc
+// @Summary      List the consumers events can be replayed to
+// @Description  Requires the admin scope.
+// @Tags         admin
+// @Produce      json
+// @Success      200  {array}   string
+// @Failure      403  {object}  ports.ErrorResponse
+// @Router       /admin/events/consumers [get]
+func (h *Handler) ListEventConsumers(w http.ResponseWriter, r *http.Request) {
+	jsonOK(w, h.events.EventConsumers())
+}
+
+// POST /admin/events/replay
+// --- ReplayEvents ---
+// ReplayEvents godoc
+// @Summary      Replay past events to a consumer
+// @Description  Hands the events after `since`, up to the newest one when the call starts, to `consumer` again, e.g. `authors` to rebuild the author pages or `saved_searches` to notify searches of books created before them. Consumers tolerate events they have seen, and keep following new events from where they were. The call returns once the replay is done; one that fails stops after the offset in the error, from where it can be resumed. A consumer replays once at a time per replica (409, code `replay_running`). Requires the admin scope.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        body  body      ports.ReplayInput  true  "Replay"
+// @Success      200   {object}  ports.ReplayResult
+// @Failure      400   {object}  ports.ErrorResponse
+// @Failure      403   {object}  ports.ErrorResponse
+// @Failure      409   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Failure      500   {object}  ports.ErrorResponse
+// @Failure      503   {object}  ports.ErrorResponse
+// @Router       /admin/events/replay [post]
+func (h *Handler) ReplayEvents(w http.ResponseWriter, r *http.Request) {
+	var in ports.ReplayInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	ctx := r.Context()
+	if h.jobs != nil {
+		jobCtx, done, ok := h.jobs.Track(ctx)
+		if !ok {
+			w.Header().Set("Retry-After", "30")
+			httpError(w, http.StatusServiceUnavailable, ports.ErrDraining.Error())
+			return
+		}
+		defer done()
+		ctx = jobCtx
+	}
+	res, err := h.events.ReplayEvents(ctx, in)
+	if err != nil {
+		eventError(w, err)
+		return
+	}
+	jsonOK(w, res)
+}
+
+func eventError(w http.ResponseWriter, err error) {
+	var ve *appsvc.ValidationError
+	switch {
+	case errors.As(err, &ve):
+		httpValidation(w, ve)
+	case errors.Is(err, ports.ErrReplayRunning):
+		httpErrorCode(w, http.StatusConflict, "replay_running", err.Error())
+	default:
+		httpError(w, http.StatusInternalServerError, err.Error())
+	}
+}
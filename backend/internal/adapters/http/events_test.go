@@ -0,0 +1,96 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/docs"
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type mockEventService struct {
+	ListFn   func(ctx context.Context, since int64, limit int) (*ports.EventsResponse, error)
+	ReplayFn func(ctx context.Context, in ports.ReplayInput) (*ports.ReplayResult, error)
+}
+
+func (m *mockEventService) ListEvents(ctx context.Context, since int64, limit int) (*ports.EventsResponse, error) {
+	return m.ListFn(ctx, since, limit)
+}
+func (m *mockEventService) EventConsumers() []string { return []string{"authors", "saved_searches"} }
+func (m *mockEventService) ReplayEvents(ctx context.Context, in ports.ReplayInput) (*ports.ReplayResult, error) {
+	return m.ReplayFn(ctx, in)
+}
+
+func TestEvents_ListAndReplay(t *testing.T) {
+	var gotSince int64
+	events := &mockEventService{
+		ListFn: func(ctx context.Context, since int64, limit int) (*ports.EventsResponse, error) {
+			gotSince = since
+			return &ports.EventsResponse{Events: []domain.Event{{
+				Offset: 8, Type: "book.created", Entity: "book", EntityID: 7, Version: 1,
+				Data: domain.ChangePayload(`{"id":7}`), OccurredAt: time.Unix(0, 0).UTC(),
+			}}, Next: 8}, nil
+		},
+		ReplayFn: func(ctx context.Context, in ports.ReplayInput) (*ports.ReplayResult, error) {
+			switch in.Consumer {
+			case "authors":
+				return &ports.ReplayResult{Consumer: in.Consumer, Since: in.Since, Until: 8, Events: 3}, nil
+			case "saved_searches":
+				return nil, ports.ErrReplayRunning
+			}
+			return nil, &appsvc.ValidationError{Fields: map[string]string{"consumer": "Consumer must be one of authors, saved_searches"}}
+		},
+	}
+	v, err := NewSpecValidator([]byte(docs.SwaggerInfo.ReadDoc()))
+	if err != nil {
+		t.Fatalf("NewSpecValidator: %v", err)
+	}
+	ts := httptest.NewServer(Identify(true)(v.Middleware(NewHandler(&mockBookService{}, WithEvents(events)).Router())))
+	defer ts.Close()
+
+	call := func(method, path, scopes, body string) (int, string) {
+		req, _ := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+		req.Header.Set("X-User", "ops")
+		req.Header.Set("X-User-Scopes", scopes)
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("do request: %v", err)
+		}
+		return res.StatusCode, readBody(t, res)
+	}
+
+	if code, body := call(http.MethodGet, "/events?since=5", "", ""); code != http.StatusOK || gotSince != 5 || !contains(body, `"type":"book.created"`) || !contains(body, `"next":8`) {
+		t.Fatalf("list = %d %s", code, body)
+	}
+	if code, _ := call(http.MethodGet, "/events?since=-1", "", ""); code != http.StatusBadRequest {
+		t.Fatalf("bad since = %d", code)
+	}
+
+	if code, _ := call(http.MethodPost, "/admin/events/replay", "", `{"consumer":"authors"}`); code != http.StatusForbidden {
+		t.Fatalf("replay without admin = %d", code)
+	}
+	if code, body := call(http.MethodGet, "/admin/events/consumers", "admin", ""); code != http.StatusOK || !contains(body, `"saved_searches"`) {
+		t.Fatalf("consumers = %d %s", code, body)
+	}
+	if code, body := call(http.MethodPost, "/admin/events/replay", "admin", `{"consumer":"authors","since":2}`); code != http.StatusOK || !contains(body, `"events":3`) {
+		t.Fatalf("replay = %d %s", code, body)
+	}
+	if code, body := call(http.MethodPost, "/admin/events/replay", "admin", `{"consumer":"saved_searches"}`); code != http.StatusConflict || !contains(body, `"code":"replay_running"`) {
+		t.Fatalf("running = %d %s", code, body)
+	}
+	if code, _ := call(http.MethodPost, "/admin/events/replay", "admin", `{"consumer":"nobody"}`); code != http.StatusUnprocessableEntity {
+		t.Fatalf("unknown consumer = %d", code)
+	}
+	if code, body := call(http.MethodGet, "/.well-known/api-capabilities", "", ""); code != http.StatusOK || !contains(body, `"events"`) {
+		t.Fatalf("capabilities = %d %s", code, body)
+	}
+}
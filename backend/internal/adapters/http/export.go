@@ -0,0 +1,65 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gerry-sabar/byfood/internal/export"
+	"github.com/gerry-sabar/byfood/internal/logger"
+)
+
+const xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// GET /books/export
+// --- ExportBooks ---
+// ExportBooks godoc
+// @Summary      Export books as CSV or XLSX
+// @Description  Streams every book as CSV (default) or as an Excel workbook with `format=xlsx`. For CSV in European locales use `delimiter=semicolon&decimal=comma&bom=true` (or `delimiter=%3B`).
+// @Tags         books
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param        format     query     string  false  "csv or xlsx (default csv)"  Enums(csv, xlsx)
+// @Param        delimiter  query     string  false  "CSV field delimiter: comma, semicolon, pipe or tab (default comma)"
+// @Param        decimal    query     string  false  "CSV decimal separator: point or comma (default point)"
+// @Param        bom        query     bool    false  "Prefix the CSV with a UTF-8 byte order mark"
+// @Success      200        {file}    file    "Export file"
+// @Failure      400        {object}  ports.ErrorResponse
+// @Failure      500        {object}  ports.ErrorResponse
+// @Router       /books/export [get]
+func (h *Handler) ExportBooks(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	format := q.Get("format")
+	var opts export.CSVOptions
+	switch format {
+	case "", "csv":
+		format = "csv"
+		var err error
+		if opts, err = export.ParseCSVOptions(q.Get("delimiter"), q.Get("decimal"), q.Get("bom")); err != nil {
+			httpError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	case "xlsx":
+	default:
+		httpError(w, http.StatusBadRequest, "invalid format (csv or xlsx)")
+		return
+	}
+
+	books, err := h.svc.ListBooks(r.Context())
+	if err != nil {
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("books-%s.%s", h.now().UTC().Format("20060102"), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	if format == "xlsx" {
+		w.Header().Set("Content-Type", xlsxContentType)
+		err = export.WriteBooksXLSX(w, books)
+	} else {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		err = export.WriteBooksCSV(w, books, opts)
+	}
+	if err != nil {
+		logger.From(r.Context()).Error("book export failed", "format", format, "error", err)
+	}
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+func TestExportBooks_CSVOptions(t *testing.T) {
+	mock := &mockBookService{
+		ListBooksFn: func(ctx context.Context) ([]domain.Book, error) {
+			return []domain.Book{{ID: 1, Title: "Clean Code", Author: "Robert C. Martin", ISBN: "9780132350884", Price: 29.99, PublicationYear: 2008,
+				CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), UpdatedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}}, nil
+		},
+	}
+	ts := newTestServer(t, mock)
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodGet, "/books/export?delimiter=%3B&decimal=comma&bom=true", nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", res.StatusCode)
+	}
+	if ct := res.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	body := readBody(t, res)
+	want := "\ufeffid;title;author;isbn;price;publication_year;created_at;updated_at\r\n" +
+		"1;Clean Code;Robert C. Martin;9780132350884;29,99;2008;2024-01-01T00:00:00Z;2024-01-02T00:00:00Z\r\n"
+	if body != want {
+		t.Fatalf("body = %q\nwant %q", body, want)
+	}
+}
+
+func TestExportBooks_InvalidOption(t *testing.T) {
+	ts := newTestServer(t, &mockBookService{})
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodGet, "/books/export?decimal=space", nil)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", res.StatusCode)
+	}
+}
+
+func TestExportBooks_XLSX(t *testing.T) {
+	mock := &mockBookService{
+		ListBooksFn: func(ctx context.Context) ([]domain.Book, error) {
+			return []domain.Book{{ID: 1, Title: "Clean Code", ISBN: "0132350882", Price: 29.99, PublicationYear: 2008}}, nil
+		},
+	}
+	ts := newTestServer(t, mock)
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodGet, "/books/export?format=xlsx", nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", res.StatusCode)
+	}
+	if ct := res.Header.Get("Content-Type"); ct != xlsxContentType {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	if cd := res.Header.Get("Content-Disposition"); !strings.HasSuffix(cd, `.xlsx"`) {
+		t.Fatalf("Content-Disposition = %q", cd)
+	}
+	body := []byte(readBody(t, res))
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatalf("not an xlsx: %v", err)
+	}
+	if zr.File[len(zr.File)-1].Name != "xl/worksheets/sheet1.xml" {
+		t.Fatalf("unexpected parts: %v", zr.File)
+	}
+}
+
+func TestExportBooks_InvalidFormat(t *testing.T) {
+	ts := newTestServer(t, &mockBookService{})
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodGet, "/books/export?format=pdf", nil)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", res.StatusCode)
+	}
+}
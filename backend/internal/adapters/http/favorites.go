@@ -0,0 +1,108 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// POST /books/{id}/favorite
+// --- FavoriteBook ---
+// FavoriteBook godoc
+// @Summary      Add a book to your favorites
+// @Description  Favoriting a book again changes nothing. Favorites belong to the signed-in user; callers without a user account get 403.
+// @Tags         favorites
+// @Param        id   path  int  true  "Book ID"  minimum(1)
+// @Success      204  "No Content"
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /books/{id}/favorite [post]
+func (h *Handler) FavoriteBook(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	if err := h.favorites.Favorite(r.Context(), id); err != nil {
+		favoriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DELETE /books/{id}/favorite
+// --- UnfavoriteBook ---
+// UnfavoriteBook godoc
+// @Summary      Remove a book from your favorites
+// @Description  404 if the book isn't one of your favorites.
+// @Tags         favorites
+// @Param        id   path  int  true  "Book ID"  minimum(1)
+// @Success      204  "No Content"
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /books/{id}/favorite [delete]
+func (h *Handler) UnfavoriteBook(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	if err := h.favorites.Unfavorite(r.Context(), id); err != nil {
+		favoriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /me/favorites
+// --- ListFavorites ---
+// ListFavorites godoc
+// @Summary      List your favorite books
+// @Description  The latest favorite first, 50 a page unless limit says otherwise. Deleted books drop out of the list.
+// @Tags         favorites
+// @Produce      json
+// @Param        limit   query     int  false  "Page size"  minimum(1)  maximum(500)
+// @Param        offset  query     int  false  "Favorites to skip"  minimum(0)
+// @Success      200     {array}   presenter.BookView
+// @Header       200     {integer}  X-Total-Count  "Number of favorites across all pages"
+// @Header       200     {string}   Link           "RFC 8288 next/prev page links"
+// @Failure      400     {object}  ports.ErrorResponse
+// @Failure      403     {object}  ports.ErrorResponse
+// @Failure      422     {object}  validationPayload
+// @Failure      500     {object}  ports.ErrorResponse
+// @Router       /me/favorites [get]
+func (h *Handler) ListFavorites(w http.ResponseWriter, r *http.Request) {
+	page, _, err := parsePage(r.URL.Query())
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	res, err := h.favorites.ListFavorites(r.Context(), ports.FavoriteQuery{Limit: page.Limit, Offset: page.Offset})
+	if err != nil {
+		favoriteError(w, err)
+		return
+	}
+	setPageLinks(w, r, ports.Page{Limit: res.Limit, Offset: res.Offset}, res.Total)
+	w.Header().Set("X-Total-Count", strconv.Itoa(res.Total))
+	jsonOK(w, h.presentBooks(r, res.Books))
+}
+
+func favoriteError(w http.ResponseWriter, err error) {
+	if ve, ok := err.(*appsvc.ValidationError); ok {
+		httpValidation(w, ve)
+		return
+	}
+	switch {
+	case errors.Is(err, ports.ErrNoFavoritesAccount):
+		httpError(w, http.StatusForbidden, err.Error())
+	case err.Error() == "book not found", err.Error() == "favorite not found":
+		httpError(w, http.StatusNotFound, err.Error())
+	default:
+		httpError(w, http.StatusInternalServerError, err.Error())
+	}
+}
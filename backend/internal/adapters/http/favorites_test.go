@@ -0,0 +1,95 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/docs"
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// sliceFavoriteRepo keeps one user's favorite book ids in the order added;
+// only book 1 and 2 exist.
+type sliceFavoriteRepo struct {
+	ids []int64
+}
+
+func (m *sliceFavoriteRepo) Add(ctx context.Context, userID, bookID int64, at time.Time) (bool, error) {
+	if bookID > 2 {
+		return false, nil
+	}
+	if !slices.Contains(m.ids, bookID) {
+		m.ids = append(m.ids, bookID)
+	}
+	return true, nil
+}
+func (m *sliceFavoriteRepo) Remove(ctx context.Context, userID, bookID int64) (bool, error) {
+	n := len(m.ids)
+	m.ids = slices.DeleteFunc(m.ids, func(id int64) bool { return id == bookID })
+	return len(m.ids) < n, nil
+}
+func (m *sliceFavoriteRepo) ListBooks(ctx context.Context, userID int64, limit, offset int) ([]domain.Book, int, error) {
+	books := []domain.Book{}
+	for _, id := range slices.Backward(m.ids) {
+		books = append(books, domain.Book{ID: id, Title: "Book", Author: "Author"})
+	}
+	page := books[min(offset, len(books)):]
+	return page[:min(limit, len(page))], len(books), nil
+}
+
+func TestFavorites(t *testing.T) {
+	v, err := NewSpecValidator([]byte(docs.SwaggerInfo.ReadDoc()))
+	if err != nil {
+		t.Fatalf("NewSpecValidator: %v", err)
+	}
+	users := &sliceUserRepo{users: []domain.User{{ID: 1, Email: "ann@example.com"}}}
+	h := NewHandler(&mockBookService{}, WithFavorites(appsvc.NewFavorites(&sliceFavoriteRepo{}, users)))
+	ts := httptest.NewServer(Identify(true)(v.Middleware(h.Router())))
+	defer ts.Close()
+
+	call := func(method, path, user string) (*http.Response, string) {
+		t.Helper()
+		req, _ := http.NewRequest(method, ts.URL+path, nil)
+		if user != "" {
+			req.Header.Set("X-User", user)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("do request: %v", err)
+		}
+		return res, readBody(t, res)
+	}
+
+	if res, _ := call(http.MethodPost, "/books/1/favorite", ""); res.StatusCode != http.StatusForbidden {
+		t.Fatalf("anonymous POST = %d; want 403", res.StatusCode)
+	}
+	if res, _ := call(http.MethodPost, "/books/1/favorite", "ops"); res.StatusCode != http.StatusForbidden {
+		t.Fatalf("POST without an account = %d; want 403", res.StatusCode)
+	}
+	if res, _ := call(http.MethodPost, "/books/9/favorite", "ann@example.com"); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("POST for a missing book = %d; want 404", res.StatusCode)
+	}
+	for _, path := range []string{"/books/1/favorite", "/books/2/favorite", "/books/1/favorite"} {
+		if res, _ := call(http.MethodPost, path, "ann@example.com"); res.StatusCode != http.StatusNoContent {
+			t.Fatalf("POST %s = %d", path, res.StatusCode)
+		}
+	}
+	res, body := call(http.MethodGet, "/me/favorites?limit=1", "ann@example.com")
+	if res.StatusCode != http.StatusOK || res.Header.Get("X-Total-Count") != "2" || !contains(res.Header.Get("Link"), `rel="next"`) || !contains(body, `"id":2`) {
+		t.Fatalf("list = %d %v %s", res.StatusCode, res.Header, body)
+	}
+	if res, _ := call(http.MethodDelete, "/books/2/favorite", "ann@example.com"); res.StatusCode != http.StatusNoContent {
+		t.Fatalf("DELETE = %d", res.StatusCode)
+	}
+	if res, _ := call(http.MethodDelete, "/books/2/favorite", "ann@example.com"); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("second DELETE = %d; want 404", res.StatusCode)
+	}
+	if res, body := call(http.MethodGet, "/.well-known/api-capabilities", ""); res.StatusCode != http.StatusOK || !contains(body, `"favorites"`) {
+		t.Fatalf("capabilities = %d %s", res.StatusCode, body)
+	}
+}
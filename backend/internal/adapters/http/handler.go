@@ -2,42 +2,470 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
 	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/gerry-sabar/byfood/internal/presenter"
+	"github.com/gerry-sabar/byfood/pkg/urlclean"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 )
 
 type Handler struct {
-	svc ports.BookService
+	svc           ports.BookService
+	changes       ports.ChangeFeed
+	events        ports.EventService
+	sync          ports.SyncService
+	aliases       ports.AliasService
+	reprice       ports.RepriceService
+	priceHistory  ports.PriceHistoryService
+	audit         ports.AuditService
+	webhooks      ports.WebhookService
+	bulkTag       ports.BulkTagService
+	metadata      ports.MetadataService
+	views         ports.ViewCounter
+	authors       ports.AuthorService
+	searches      ports.SavedSearchService
+	publishers    ports.PublisherService
+	series        ports.SeriesService
+	taxonomy      ports.TaxonomyService
+	tags          ports.TagService
+	loans         ports.LoanService
+	holds         ports.HoldService
+	deadLetters   ports.DeadLetterService
+	workers       ports.WorkerService
+	apiKeys       ports.APIKeyService
+	jobs          ports.JobTracker
+	auth          ports.AuthService
+	resolver      ports.URLResolver
+	extractor     ports.URLExtractor
+	cleaner       *appsvc.URLCleaner
+	cleanupStats  ports.CleanupStatsService
+	trackingRules ports.TrackingRulesService
+	listPrefs     ports.ListPreferencesService
+	ranking       ports.SearchRankingService
+	insights      ports.SearchInsightsService
+	suggester     ports.SuggestionService
+	synonyms      ports.SynonymService
+	users         ports.UserService
+	favorites     ports.FavoriteService
+	history       ports.URLHistoryService
+	bookHistory   ports.BookHistory
+	status        ports.StatusService
+	deployment    domain.Capabilities // completed by the handler's own options
+	pageLimits    ports.PageLimits
+	fields        presenter.FieldPolicy // book fields hidden from callers without the scopes to see them
+	roles         bool                  // only editors and admins may change books
+	now           func() time.Time      // clock for derived response fields
 }
 
-func NewHandler(svc ports.BookService) *Handler {
-	return &Handler{svc: svc}
+// Option enables optional endpoints on the handler.
+type Option func(*Handler)
+
+// WithChangeFeed exposes GET /books/changes backed by f.
+func WithChangeFeed(f ports.ChangeFeed) Option {
+	return func(h *Handler) { h.changes = f }
+}
+
+// WithEvents exposes GET /events, and the replay of events to consumers
+// under /admin/events to admins.
+func WithEvents(e ports.EventService) Option {
+	return func(h *Handler) { h.events = e }
+}
+
+// WithWebhooks exposes the /webhooks resource to admins.
+func WithWebhooks(w ports.WebhookService) Option {
+	return func(h *Handler) { h.webhooks = w }
+}
+
+// WithBookHistory serves ?as_of= on GET /books and GET /books/{id} from h.
+func WithBookHistory(bh ports.BookHistory) Option {
+	return func(h *Handler) { h.bookHistory = bh }
+}
+
+// WithSync exposes the offline-sync endpoints under /sync.
+func WithSync(s ports.SyncService) Option {
+	return func(h *Handler) { h.sync = s }
+}
+
+// WithAliases exposes the /books/{id}/aliases sub-resource.
+func WithAliases(a ports.AliasService) Option {
+	return func(h *Handler) { h.aliases = a }
+}
+
+// WithReprice exposes POST /books/reprice.
+func WithReprice(rs ports.RepriceService) Option {
+	return func(h *Handler) { h.reprice = rs }
+}
+
+// WithPriceHistory exposes GET /books/{id}/price-history.
+func WithPriceHistory(ph ports.PriceHistoryService) Option {
+	return func(h *Handler) { h.priceHistory = ph }
+}
+
+// WithAuditTrail exposes GET /books/{id}/history to admins.
+func WithAuditTrail(a ports.AuditService) Option {
+	return func(h *Handler) { h.audit = a }
+}
+
+// WithBulkTag exposes POST /books/bulk-tag and its job progress.
+func WithBulkTag(b ports.BulkTagService) Option {
+	return func(h *Handler) { h.bulkTag = b }
+}
+
+// WithMetadata exposes POST /books/lookup.
+func WithMetadata(m ports.MetadataService) Option {
+	return func(h *Handler) { h.metadata = m }
+}
+
+// WithViewCounter counts successful GET /books/{id} responses in v.
+func WithViewCounter(v ports.ViewCounter) Option {
+	return func(h *Handler) { h.views = v }
+}
+
+// WithAuthors exposes GET /authors/{id}/summary.
+func WithAuthors(a ports.AuthorService) Option {
+	return func(h *Handler) { h.authors = a }
+}
+
+// WithSavedSearches exposes the /saved-searches resource.
+func WithSavedSearches(s ports.SavedSearchService) Option {
+	return func(h *Handler) { h.searches = s }
+}
+
+// WithPublishers exposes the /publishers resource.
+func WithPublishers(p ports.PublisherService) Option {
+	return func(h *Handler) { h.publishers = p }
+}
+
+// WithSeries exposes the /series resource and GET /books/{id}/editions.
+func WithSeries(s ports.SeriesService) Option {
+	return func(h *Handler) { h.series = s }
+}
+
+// WithTaxonomy exposes the taxonomy export and import under /taxonomy.
+func WithTaxonomy(t ports.TaxonomyService) Option {
+	return func(h *Handler) { h.taxonomy = t }
+}
+
+// WithTags exposes tagging under /books/{id}/tags and the tag counts of
+// GET /tags.
+func WithTags(t ports.TagService) Option {
+	return func(h *Handler) { h.tags = t }
+}
+
+// WithLoans lets editors lend books to users under /loans.
+func WithLoans(s ports.LoanService) Option {
+	return func(h *Handler) { h.loans = s }
+}
+
+// WithHolds lets editors queue users for books out on loan under /holds.
+func WithHolds(s ports.HoldService) Option {
+	return func(h *Handler) { h.holds = s }
+}
+
+// WithDeadLetters exposes the /admin/dlq console to admins.
+func WithDeadLetters(d ports.DeadLetterService) Option {
+	return func(h *Handler) { h.deadLetters = d }
+}
+
+// WithWorkers exposes the /admin/workers endpoints to admins.
+func WithWorkers(ws ports.WorkerService) Option {
+	return func(h *Handler) { h.workers = ws }
+}
+
+// WithAPIKeys exposes the /admin/api-keys endpoints to admins.
+func WithAPIKeys(k ports.APIKeyService) Option {
+	return func(h *Handler) { h.apiKeys = k }
+}
+
+// WithAuth exposes POST /auth/register and POST /auth/login.
+func WithAuth(a ports.AuthService) Option {
+	return func(h *Handler) { h.auth = a }
+}
+
+// WithRoles lets only actors with the editor or admin role create, change
+// and delete books; everyone else gets 403.
+func WithRoles() Option {
+	return func(h *Handler) { h.roles = true }
+}
+
+// WithJobs registers imports and event replays with t, so a shutdown waits
+// for them and new ones are refused while it drains.
+func WithJobs(t ports.JobTracker) Option {
+	return func(h *Handler) { h.jobs = t }
+}
+
+// WithURLResolver enables the resolve operation of POST /url/cleanup.
+func WithURLResolver(r ports.URLResolver) Option {
+	return func(h *Handler) { h.resolver = r }
+}
+
+// WithURLExtractor enables POST /url/extract.
+func WithURLExtractor(e ports.URLExtractor) Option {
+	return func(h *Handler) { h.extractor = e }
+}
+
+// WithCleanupProfiles offers the profiles of c, built-in and configured, to
+// POST /url/cleanup and lists them under GET /url/profiles.
+func WithCleanupProfiles(c *appsvc.URLCleaner) Option {
+	return func(h *Handler) { h.cleaner = c }
+}
+
+// WithCleanupStats counts cleanup requests in s and exposes GET
+// /url/cleanup/stats to admins.
+func WithCleanupStats(s ports.CleanupStatsService) Option {
+	return func(h *Handler) { h.cleanupStats = s }
+}
+
+// WithTrackingRules lets API keys and users manage their tracking parameter
+// rules under /url/cleanup/rules.
+func WithTrackingRules(s ports.TrackingRulesService) Option {
+	return func(h *Handler) { h.trackingRules = s }
+}
+
+// WithListPreferences lets API keys and users set their own book list
+// defaults under /me/list-preferences.
+func WithListPreferences(p ports.ListPreferencesService) Option {
+	return func(h *Handler) { h.listPrefs = p }
+}
+
+// WithSearchRanking ranks searches with each tenant's ranking from s and
+// lets admins tune them under /admin/search/ranking.
+func WithSearchRanking(s ports.SearchRankingService) Option {
+	return func(h *Handler) { h.ranking = s }
+}
+
+// WithSearchInsights counts searches in s, takes click-throughs on POST
+// /search/feedback and exposes GET /admin/search/insights to admins.
+func WithSearchInsights(s ports.SearchInsightsService) Option {
+	return func(h *Handler) { h.insights = s }
+}
+
+// WithSuggestions suggests what searches that find nothing may have meant
+// with s.
+func WithSuggestions(s ports.SuggestionService) Option {
+	return func(h *Handler) { h.suggester = s }
+}
+
+// WithSynonyms lets admins manage the synonym dictionary searches expand
+// with under /admin/search/synonyms.
+func WithSynonyms(s ports.SynonymService) Option {
+	return func(h *Handler) { h.synonyms = s }
+}
+
+// WithUsers lets admins manage user accounts under /users and signed-in
+// users their own profile at /me.
+func WithUsers(u ports.UserService) Option {
+	return func(h *Handler) { h.users = u }
+}
+
+// WithFavorites lets signed-in users bookmark books with
+// /books/{id}/favorite and list them under /me/favorites.
+func WithFavorites(f ports.FavoriteService) Option {
+	return func(h *Handler) { h.favorites = f }
+}
+
+// WithURLHistory keeps the successful cleanups of identified callers in s
+// and exposes their history under /me/url-history.
+func WithURLHistory(s ports.URLHistoryService) Option {
+	return func(h *Handler) { h.history = s }
+}
+
+// WithStatus exposes GET /status, the dependency health report, to admins.
+func WithStatus(s ports.StatusService) Option {
+	return func(h *Handler) { h.status = s }
+}
+
+// WithCapabilities describes the deployment in GET
+// /.well-known/api-capabilities: its version, search backend, identities
+// and features set up outside the handler. The handler adds the endpoints
+// it was given.
+func WithCapabilities(c domain.Capabilities) Option {
+	return func(h *Handler) { h.deployment = c }
+}
+
+// WithPageLimits sets the default and maximum page size of book lists.
+func WithPageLimits(l ports.PageLimits) Option {
+	return func(h *Handler) { h.pageLimits = l }
+}
+
+// WithFieldPolicy hides book fields in responses from callers without the
+// scopes p asks for.
+func WithFieldPolicy(p presenter.FieldPolicy) Option {
+	return func(h *Handler) { h.fields = p }
+}
+
+// WithClock sets the clock used for derived response fields.
+func WithClock(now func() time.Time) Option {
+	return func(h *Handler) { h.now = now }
+}
+
+func NewHandler(svc ports.BookService, opts ...Option) *Handler {
+	builtIn, _ := appsvc.NewURLCleaner(nil)
+	h := &Handler{svc: svc, cleaner: builtIn, now: time.Now}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 func (h *Handler) Router() http.Handler {
 	r := chi.NewRouter()
-	r.Use(middleware.RequestID, middleware.RealIP, middleware.Logger, middleware.Recoverer)
+	r.Use(RequestID, middleware.RealIP, middleware.Logger, middleware.Recoverer)
 
+	edit := h.requireEditor
 	r.Route("/books", func(r chi.Router) {
 		r.Get("/", h.ListBooks)
-		r.Post("/", h.CreateBook)
+		r.With(edit).Post("/", h.CreateBook)
+		r.Get("/export", h.ExportBooks)
+		r.With(edit).Post("/import", h.ImportBooks)
+		r.Get("/isbn/{isbn}", h.GetBookByISBN)
+		if h.reprice != nil {
+			r.With(edit).Post("/reprice", h.RepriceBooks)
+		}
+		if h.bulkTag != nil {
+			r.With(edit).Post("/bulk-tag", h.BulkTagBooks)
+			r.With(edit).Get("/bulk-tag/{job}", h.BulkTagJob)
+		}
+		if h.metadata != nil {
+			r.With(edit).Post("/lookup", h.LookupBook)
+		}
+		if h.changes != nil {
+			r.Get("/changes", h.BookChanges)
+		}
 		r.Route("/{id}", func(r chi.Router) {
 			r.Get("/", h.GetBook)
-			r.Put("/", h.UpdateBook)
-			r.Delete("/", h.DeleteBook)
+			r.With(edit).Put("/", h.UpdateBook)
+			r.With(edit).Delete("/", h.DeleteBook)
+			r.Get("/label.zpl", h.BookLabel)
+			r.With(edit).Post("/split", h.SplitBook)
+			if h.aliases != nil {
+				r.Route("/aliases", h.aliasRoutes)
+			}
+			if h.tags != nil {
+				r.With(edit).Put("/tags/{tag}", h.TagBook)
+				r.With(edit).Delete("/tags/{tag}", h.UntagBook)
+			}
+			if h.favorites != nil {
+				r.Post("/favorite", h.FavoriteBook)
+				r.Delete("/favorite", h.UnfavoriteBook)
+			}
+			if h.series != nil {
+				r.Get("/editions", h.ListEditions)
+			}
+			if h.priceHistory != nil {
+				r.Get("/price-history", h.BookPriceHistory)
+			}
+			if h.audit != nil {
+				r.With(requireScope(domain.ScopeAdmin)).Get("/history", h.BookAudit)
+			}
 		})
 	})
 
+	if h.sync != nil {
+		r.Get("/sync/books", h.SyncPull)
+		r.With(edit).Post("/sync/books", h.SyncPush)
+	}
+	if h.authors != nil {
+		r.Get("/authors/{id}/summary", h.AuthorSummary)
+	}
+	if h.searches != nil {
+		r.Route("/saved-searches", h.savedSearchRoutes)
+	}
+	if h.publishers != nil {
+		r.Route("/publishers", h.publisherRoutes)
+	}
+	if h.series != nil {
+		r.Route("/series", h.seriesRoutes)
+	}
+	if h.tags != nil {
+		r.Get("/tags", h.ListTags)
+	}
+	if h.taxonomy != nil {
+		r.Get("/taxonomy/export", h.ExportTaxonomy)
+		r.With(edit).Post("/taxonomy/import", h.ImportTaxonomy)
+	}
+	if h.loans != nil {
+		r.Route("/loans", h.loanRoutes)
+	}
+	if h.holds != nil {
+		r.Route("/holds", h.holdRoutes)
+	}
+	if h.deadLetters != nil {
+		r.Route("/admin/dlq", h.deadLetterRoutes)
+	}
+	if h.events != nil {
+		r.Get("/events", h.ListEvents)
+		r.Route("/admin/events", h.eventReplayRoutes)
+	}
+	if h.webhooks != nil {
+		r.Route("/webhooks", h.webhookRoutes)
+	}
+	if h.workers != nil {
+		r.Route("/admin/workers", h.workerRoutes)
+	}
+	if h.apiKeys != nil {
+		r.Route("/admin/api-keys", h.apiKeyRoutes)
+	}
+	if h.ranking != nil {
+		r.Route("/admin/search/ranking", h.searchRankingRoutes)
+	}
+	if h.synonyms != nil {
+		r.Route("/admin/search/synonyms", h.synonymRoutes)
+	}
+	if h.insights != nil {
+		r.Post("/search/feedback", h.SearchFeedback)
+		r.With(requireScope(domain.ScopeAdmin)).Get("/admin/search/insights", h.SearchInsights)
+	}
+	r.Get("/version", h.Version)
+	r.Get("/.well-known/api-capabilities", h.Capabilities)
+	if h.status != nil {
+		r.With(requireScope(domain.ScopeAdmin)).Get("/status", h.Status)
+	}
+	if h.auth != nil {
+		r.Post("/auth/register", h.Register)
+		r.Post("/auth/login", h.Login)
+	}
+	if h.users != nil {
+		r.Route("/users", h.userRoutes)
+		r.Get("/me", h.GetMe)
+		r.Put("/me", h.UpdateMe)
+	}
+
 	// 👇 NEW endpoint
 	r.Post("/url/cleanup", h.CleanupURL)
+	r.Get("/url/profiles", h.ListCleanupProfiles)
+	if h.extractor != nil {
+		r.Post("/url/extract", h.ExtractURL)
+	}
+	if h.cleanupStats != nil {
+		r.With(requireScope(domain.ScopeAdmin)).Get("/url/cleanup/stats", h.CleanupStats)
+	}
+	if h.trackingRules != nil {
+		r.Get("/url/cleanup/rules", h.GetTrackingRules)
+		r.Put("/url/cleanup/rules", h.PutTrackingRules)
+		r.Delete("/url/cleanup/rules", h.DeleteTrackingRules)
+	}
+	if h.history != nil {
+		r.Route("/me/url-history", h.urlHistoryRoutes)
+	}
+	if h.listPrefs != nil {
+		r.Route("/me/list-preferences", h.listPreferenceRoutes)
+	}
+	if h.favorites != nil {
+		r.Get("/me/favorites", h.ListFavorites)
+	}
 
 	return r
 }
@@ -46,19 +474,192 @@ func (h *Handler) Router() http.Handler {
 // --- ListBooks ---
 // ListBooks godoc
 // @Summary      List books
-// @Description  Returns all books
+// @Description  Returns books newest first, one page of limit books at a time. Callers with list preferences (PUT /me/list-preferences/) get their own sort and page size when they send none. Searches (q) without a sort rank the best matches first where search ranking is enabled, using the caller's ranking (see /admin/search/ranking/), also find books matching the synonyms of their terms where synonyms are enabled (see /admin/search/synonyms/), and their first pages are counted for GET /admin/search/insights where search insights are enabled. A search that finds nothing gets the titles and authors it may have meant in X-Search-Suggestions (meta.suggestions when enveloped) where suggestions are enabled. Without limit a page holds the server's default page size (all books when none is configured); a limit above the maximum page size gets 400 with code "page_size_exceeded". On large catalogues, filters no index serves (q, year and price on their own, or exact=true) get 400 with code "query_too_expensive" unless author or isbn narrows them. GET /.well-known/api-capabilities gives both sizes. Filters combine with AND; year and price bounds are inclusive. X-Total-Count always carries the number of matching books.
 // @Tags         books
 // @Produce      json
-// @Success      200  {array}   domain.Book
-// @Failure      500  {object}  ports.ErrorResponse
+// @Param        q          query     string  false  "Search title and author, ignoring case and accents"
+// @Param        author     query     string  false  "Whole author name, ignoring case and accents"
+// @Param        isbn       query     string  false  "ISBN, ignoring hyphens and spaces"
+// @Param        tag        query     string  false  "Tag, ignoring case and accents"
+// @Param        exact      query     bool    false  "Match q, author and isbn case-, accent- and hyphen-sensitively"
+// @Param        year_from  query     int     false  "Earliest publication year"
+// @Param        year_to    query     int     false  "Latest publication year"
+// @Param        price_min  query     number  false  "Lowest price"  minimum(0)
+// @Param        price_max  query     number  false  "Highest price"  minimum(0)
+// @Param        sort       query     string  false  "Sort field, prefixed with - for descending"  Enums(id, -id, title, -title, author, -author, price, -price, publication_year, -publication_year)
+// @Param        collation  query     string  false  "Locale used to order title or author, e.g. de, sv, tr"
+// @Param        limit   query     int     false  "Page size, up to the maximum page size"  minimum(1)
+// @Param        offset  query     int     false  "Books to skip"  minimum(0)
+// @Param        as_of   query     string  false  "Every book as it was at this RFC 3339 instant, rebuilt from the change log; can't be combined with filters, sorting or paging"  format(date-time)
+// @Success      200     {array}   presenter.BookView
+// @Header       200     {integer}  X-Total-Count  "Number of matching books across all pages"
+// @Header       200     {string}   Link           "RFC 8288 next/prev page links"
+// @Header       200     {string}   X-Search-Suggestions  "When q found nothing: titles and authors it may have meant, best first, percent-encoded and comma-separated"
+// @Failure      400     {object}  ports.ErrorResponse
+// @Failure      410     {object}  ports.ErrorResponse
+// @Failure      422     {object}  validationPayload
+// @Failure      500     {object}  ports.ErrorResponse
 // @Router       /books/ [get]
 func (h *Handler) ListBooks(w http.ResponseWriter, r *http.Request) {
-	books, err := h.svc.ListBooks(r.Context())
+	query := r.URL.Query()
+	page, paged, err := parsePage(query)
 	if err != nil {
-		httpError(w, http.StatusInternalServerError, err.Error())
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter, filtered, err := parseListFilter(query)
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if at, set, ok := h.parseAsOf(w, r); !ok {
+		return
+	} else if set {
+		if paged || filtered || filter.Q != "" {
+			httpError(w, http.StatusBadRequest, "as_of can't be combined with filters, sorting or paging")
+			return
+		}
+		h.listBooksAsOf(w, r, at)
+		return
+	}
+	// defaults only apply once the request's own paging is known
+	h.applyListPreferences(r.Context(), &page)
+	h.applySearchRanking(r.Context(), filter, &page)
+	if err := h.pageLimits.Apply(&page); err != nil {
+		httpErrorCode(w, http.StatusBadRequest, "page_size_exceeded", err.Error())
+		return
+	}
+	paged = paged || page.Limit > 0 || page.Sort.Field != "" || page.Ranking != nil
+
+	var books []domain.Book
+	total := 0
+	switch {
+	case paged || filtered:
+		var res *ports.BookPage
+		res, err = h.svc.ListBooksPage(r.Context(), filter, page)
+		if res != nil {
+			books, total = res.Books, res.Total
+			setPageLinks(w, r, page, total)
+		}
+	case filter.Q != "":
+		books, err = h.svc.SearchBooks(r.Context(), filter.Q)
+		total = len(books)
+	default:
+		books, err = h.svc.ListBooks(r.Context())
+		total = len(books)
+	}
+	if err != nil {
+		var ve *appsvc.ValidationError
+		var qe *appsvc.QueryCostError
+		switch {
+		case errors.As(err, &ve):
+			httpValidation(w, ve)
+		case errors.As(err, &qe):
+			httpErrorCode(w, http.StatusBadRequest, "query_too_expensive", err.Error())
+		default:
+			httpError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	h.recordSearch(filter, page, total)
+	if total == 0 {
+		h.suggest(w, r, filter)
+	}
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	jsonOK(w, h.presentBooks(r, books))
+}
+
+// parsePage reads limit/offset/sort/collation; paged reports whether any was
+// given. The sort field and collation are checked by the service.
+func parsePage(query url.Values) (page ports.Page, paged bool, err error) {
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return page, false, errors.New("limit must be a positive integer")
+		}
+		page.Limit, paged = n, true
+	}
+	if v := query.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return page, false, errors.New("offset must be a non-negative integer")
+		}
+		page.Offset, paged = n, true
+	}
+	if v := query.Get("sort"); v != "" {
+		page.Sort.Field, page.Sort.Desc = strings.TrimPrefix(v, "-"), strings.HasPrefix(v, "-")
+		paged = true
+	}
+	if v := query.Get("collation"); v != "" {
+		page.Sort.Collation, paged = v, true
+	}
+	return page, paged, nil
+}
+
+// parseListFilter reads the book list filters; filtered reports whether any
+// besides q was given.
+func parseListFilter(query url.Values) (f ports.ListFilter, filtered bool, err error) {
+	f.Q = query.Get("q")
+	f.Author = strings.TrimSpace(query.Get("author"))
+	f.ISBN = strings.TrimSpace(query.Get("isbn"))
+	f.Tag = strings.TrimSpace(query.Get("tag"))
+	filtered = f.Author != "" || f.ISBN != "" || f.Tag != ""
+	if v := query.Get("exact"); v != "" {
+		if f.Exact, err = strconv.ParseBool(v); err != nil {
+			return f, false, errors.New("exact must be true or false")
+		}
+		filtered = filtered || f.Exact
+	}
+	for _, p := range []struct {
+		name string
+		dst  **int
+	}{{"year_from", &f.YearFrom}, {"year_to", &f.YearTo}} {
+		if v := query.Get(p.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return f, false, fmt.Errorf("%s must be an integer", p.name)
+			}
+			*p.dst, filtered = &n, true
+		}
+	}
+	for _, p := range []struct {
+		name string
+		dst  **float64
+	}{{"price_min", &f.PriceMin}, {"price_max", &f.PriceMax}} {
+		if v := query.Get(p.name); v != "" {
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+				return f, false, fmt.Errorf("%s must be a number", p.name)
+			}
+			*p.dst, filtered = &n, true
+		}
+	}
+	return f, filtered, nil
+}
+
+// setPageLinks adds a Link header pointing at the neighbouring pages.
+func setPageLinks(w http.ResponseWriter, r *http.Request, page ports.Page, total int) {
+	if page.Limit == 0 {
 		return
 	}
-	jsonOK(w, books)
+	link := func(offset int, rel string) string {
+		u := *r.URL
+		q := u.Query()
+		q.Set("limit", strconv.Itoa(page.Limit))
+		q.Set("offset", strconv.Itoa(offset))
+		u.RawQuery = q.Encode()
+		return fmt.Sprintf("<%s>; rel=%q", u.RequestURI(), rel)
+	}
+	var links []string
+	if page.Offset > 0 {
+		links = append(links, link(max(page.Offset-page.Limit, 0), "prev"))
+	}
+	if page.Offset+page.Limit < total {
+		links = append(links, link(page.Offset+page.Limit, "next"))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
 }
 
 // POST /books
@@ -69,8 +670,10 @@ func (h *Handler) ListBooks(w http.ResponseWriter, r *http.Request) {
 // @Accept       json
 // @Produce      json
 // @Param        body  body      ports.CreateBookInput  true  "New book"
-// @Success      201   {object}  domain.Book
+// @Success      201   {object}  presenter.BookView
+// @Header       201   {string}  ETag  "Book version, for If-Match"
 // @Failure      400   {object}  ports.ErrorResponse
+// @Failure      403   {object}  ports.ErrorResponse
 // @Failure      422   {object}  validationPayload
 // @Router       /books/ [post]
 func (h *Handler) CreateBook(w http.ResponseWriter, r *http.Request) {
@@ -88,19 +691,24 @@ func (h *Handler) CreateBook(w http.ResponseWriter, r *http.Request) {
 		httpError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	jsonCreated(w, book)
+	setETag(w, book)
+	jsonCreated(w, h.presentBook(r, *book))
 }
 
 // GET /books/{id}
 // --- GetBook ---
 // GetBook godoc
 // @Summary      Get a book
+// @Description  With as_of, returns the book as it was at that instant, rebuilt from the change log, without an ETag. 404 means it didn't exist then; 410 with code "beyond_history" means the change log no longer reaches back that far.
 // @Tags         books
 // @Produce      json
-// @Param        id   path      int  true  "Book ID"  minimum(1)
-// @Success      200  {object}  domain.Book
+// @Param        id     path      int     true   "Book ID"  minimum(1)
+// @Param        as_of  query     string  false  "RFC 3339 instant to read the book at"  format(date-time)
+// @Success      200  {object}  presenter.BookView
+// @Header       200  {string}  ETag  "Book version, for If-Match"
 // @Failure      400  {object}  ports.ErrorResponse
 // @Failure      404  {object}  ports.ErrorResponse
+// @Failure      410  {object}  ports.ErrorResponse
 // @Failure      500  {object}  ports.ErrorResponse
 // @Router       /books/{id}/ [get]
 func (h *Handler) GetBook(w http.ResponseWriter, r *http.Request) {
@@ -108,6 +716,12 @@ func (h *Handler) GetBook(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
+	if at, set, ok := h.parseAsOf(w, r); !ok {
+		return
+	} else if set {
+		h.getBookAsOf(w, r, id, at)
+		return
+	}
 	book, err := h.svc.GetBook(r.Context(), id)
 	if err != nil {
 		httpError(w, http.StatusInternalServerError, err.Error())
@@ -117,21 +731,66 @@ func (h *Handler) GetBook(w http.ResponseWriter, r *http.Request) {
 		httpError(w, http.StatusNotFound, "not found")
 		return
 	}
-	jsonOK(w, book)
+	if h.views != nil {
+		h.views.Add(id)
+	}
+	setETag(w, book)
+	jsonOK(w, h.presentBook(r, *book))
+}
+
+// GET /books/isbn/{isbn}
+// --- GetBookByISBN ---
+// GetBookByISBN godoc
+// @Summary      Get a book by ISBN
+// @Description  Finds the book with an ISBN-10 or ISBN-13, with or without hyphens and spaces. Books store the ISBN-13 and, when it starts with 978, the ISBN-10 as isbn10, so either form finds them.
+// @Tags         books
+// @Produce      json
+// @Param        isbn  path      string  true  "ISBN-10 or ISBN-13"
+// @Success      200   {object}  presenter.BookView
+// @Header       200   {string}  ETag  "Book version, for If-Match"
+// @Failure      404   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Failure      500   {object}  ports.ErrorResponse
+// @Router       /books/isbn/{isbn} [get]
+func (h *Handler) GetBookByISBN(w http.ResponseWriter, r *http.Request) {
+	book, err := h.svc.GetBookByISBN(r.Context(), chi.URLParam(r, "isbn"))
+	if err != nil {
+		var ve *appsvc.ValidationError
+		if errors.As(err, &ve) {
+			httpValidation(w, ve)
+			return
+		}
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if book == nil {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if h.views != nil {
+		h.views.Add(book.ID)
+	}
+	setETag(w, book)
+	jsonOK(w, h.presentBook(r, *book))
 }
 
 // PUT /books/{id}
 // --- UpdateBook ---
 // UpdateBook godoc
 // @Summary      Update a book
+// @Description  Send the version last read, as `version` in the body or as the ETag in If-Match, to get 409 instead of overwriting someone else's write.
 // @Tags         books
 // @Accept       json
 // @Produce      json
-// @Param        id    path      int              true  "Book ID"  minimum(1)
+// @Param        id        path      int              true  "Book ID"  minimum(1)
+// @Param        If-Match  header    string           false  "ETag (version) the update is based on"
 // @Param        body  body      ports.UpdateBookInput  true  "Partial update"
-// @Success      200   {object}  domain.Book
+// @Success      200   {object}  presenter.BookView
+// @Header       200   {string}  ETag  "Book version, for If-Match"
 // @Failure      400   {object}  ports.ErrorResponse
+// @Failure      403   {object}  ports.ErrorResponse
 // @Failure      404   {object}  ports.ErrorResponse
+// @Failure      409   {object}  conflictPayload
 // @Failure      422   {object}  validationPayload
 // @Router       /books/{id}/ [put]
 func (h *Handler) UpdateBook(w http.ResponseWriter, r *http.Request) {
@@ -145,12 +804,24 @@ func (h *Handler) UpdateBook(w http.ResponseWriter, r *http.Request) {
 		httpError(w, http.StatusBadRequest, "invalid JSON body")
 		return
 	}
+	if v := r.Header.Get("If-Match"); v != "" && v != "*" {
+		version, err := parseETag(v)
+		if err != nil {
+			httpError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		in.Version = &version
+	}
 	book, err := h.svc.UpdateBook(r.Context(), id, in)
 	if err != nil {
 		if ve, ok := err.(*appsvc.ValidationError); ok {
 			httpValidation(w, ve)
 			return
 		}
+		if ce, ok := err.(*appsvc.ConflictError); ok {
+			httpConflict(w, ce)
+			return
+		}
 		// distinguish not found
 		if err.Error() == "book not found" {
 			httpError(w, http.StatusNotFound, "not found")
@@ -159,17 +830,134 @@ func (h *Handler) UpdateBook(w http.ResponseWriter, r *http.Request) {
 		httpError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	jsonOK(w, book)
+	setETag(w, book)
+	jsonOK(w, h.presentBook(r, *book))
+}
+
+// setETag tags a single-book response with the book's version.
+func setETag(w http.ResponseWriter, b *domain.Book) {
+	w.Header().Set("ETag", strconv.Quote(strconv.FormatInt(b.Version, 10)))
+}
+
+// parseETag reads the version back from an If-Match value: the ETag as
+// sent ("3"), its weak form (W/"3") or a bare number.
+func parseETag(v string) (int64, error) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "W/")
+	if uq, err := strconv.Unquote(v); err == nil {
+		v = uq
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 1 {
+		return 0, errors.New("If-Match must be a single book ETag")
+	}
+	return n, nil
+}
+
+// POST /books/{id}/split
+// --- SplitBook ---
+// SplitBook godoc
+// @Summary      Split a book into two editions
+// @Description  Creates a new edition from the book with `fields` applied on top (a new ISBN is required) and links both under the same work_id. The original keeps its id and history; aliases listed in `alias_ids` move to the new edition.
+// @Tags         books
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int                   true  "Book ID"  minimum(1)
+// @Param        body  body      ports.SplitBookInput  true  "New edition"
+// @Success      201   {object}  splitResponse
+// @Failure      400   {object}  ports.ErrorResponse
+// @Failure      403   {object}  ports.ErrorResponse
+// @Failure      404   {object}  ports.ErrorResponse
+// @Failure      409   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Router       /books/{id}/split [post]
+func (h *Handler) SplitBook(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	var in ports.SplitBookInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	res, err := h.svc.SplitBook(r.Context(), id, in)
+	if err != nil {
+		if ve, ok := err.(*appsvc.ValidationError); ok {
+			httpValidation(w, ve)
+			return
+		}
+		switch {
+		case err.Error() == "book not found":
+			httpError(w, http.StatusNotFound, "not found")
+		case errors.Is(err, ports.ErrConcurrentUpdate):
+			httpError(w, http.StatusConflict, err.Error())
+		default:
+			httpError(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+	jsonCreated(w, splitResponse{
+		Source:  h.presentBook(r, res.Source),
+		Edition: h.presentBook(r, res.Edition),
+	})
+}
+
+type splitResponse struct {
+	Source  presenter.BookView `json:"source"`
+	Edition presenter.BookView `json:"edition"`
+}
+
+// GET /books/{id}/label.zpl
+// --- BookLabel ---
+// BookLabel godoc
+// @Summary      Shelf label for a book (ZPL)
+// @Description  ZPL for a 2x1" label with the title, an EAN-13 barcode of the ISBN and the price; send it as-is to a Zebra printer.
+// @Tags         books
+// @Produce      plain
+// @Param        id   path      int     true  "Book ID"  minimum(1)
+// @Success      200  {string}  string  "ZPL label"
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /books/{id}/label.zpl [get]
+func (h *Handler) BookLabel(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	actor, _ := domain.ActorFrom(r.Context())
+	for _, f := range h.fields.Hidden(actor) {
+		if slices.Contains(presenter.LabelFields, f) {
+			httpError(w, http.StatusForbidden, "the label shows the "+f+", which this caller may not see")
+			return
+		}
+	}
+	book, err := h.svc.GetBook(r.Context(), id)
+	if err != nil {
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if book == nil {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="book-%d.zpl"`, id))
+	_, _ = w.Write(presenter.ZPLLabel(*book))
 }
 
 // DELETE /books/{id}
 // --- DeleteBook ---
 // DeleteBook godoc
 // @Summary      Delete a book
+// @Description  A book on loan is kept (409, code "book_on_loan") until it is returned.
 // @Tags         books
 // @Param        id  path  int  true  "Book ID"  minimum(1)
 // @Success      204  "No Content"
 // @Failure      400  {object}  ports.ErrorResponse
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      409  {object}  ports.ErrorResponse
 // @Failure      500  {object}  ports.ErrorResponse
 // @Router       /books/{id}/ [delete]
 func (h *Handler) DeleteBook(w http.ResponseWriter, r *http.Request) {
@@ -178,12 +966,166 @@ func (h *Handler) DeleteBook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err := h.svc.DeleteBook(r.Context(), id); err != nil {
+		if errors.Is(err, ports.ErrBookOnLoan) {
+			httpErrorCode(w, http.StatusConflict, "book_on_loan", err.Error())
+			return
+		}
 		httpError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// GET /books/changes
+// --- BookChanges ---
+// BookChanges godoc
+// @Summary      Long-poll the books change log
+// @Description  Returns changes after `since`, ordered by cursor. When there are none yet the request blocks up to `wait` (max 60s) and returns an empty list on timeout. Pass the returned cursor as the next `since`.
+// @Tags         books
+// @Produce      json
+// @Param        since  query     int     false  "Cursor of the last change seen"  minimum(0)
+// @Param        wait   query     string  false  "Max time to block, e.g. 30s"
+// @Param        limit  query     int     false  "Max changes to return (default 100)"  minimum(1)  maximum(500)
+// @Success      200    {object}  ports.ChangesResponse
+// @Failure      400    {object}  ports.ErrorResponse
+// @Failure      500    {object}  ports.ErrorResponse
+// @Router       /books/changes [get]
+func (h *Handler) BookChanges(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var since int64
+	if v := q.Get("since"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			httpError(w, http.StatusBadRequest, "invalid since")
+			return
+		}
+		since = n
+	}
+
+	var wait time.Duration
+	if v := q.Get("wait"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			httpError(w, http.StatusBadRequest, "invalid wait (use e.g. 30s)")
+			return
+		}
+		wait = min(d, maxChangesWait)
+	}
+
+	limit := 100
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 500 {
+			httpError(w, http.StatusBadRequest, "invalid limit (1-500)")
+			return
+		}
+		limit = n
+	}
+
+	changes, err := h.changes.Since(r.Context(), since, limit, wait)
+	if err != nil {
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	resp := ports.ChangesResponse{Changes: changes, Cursor: since}
+	if resp.Changes == nil {
+		resp.Changes = []domain.Change{}
+	}
+	if n := len(changes); n > 0 {
+		resp.Cursor = changes[n-1].ID
+	}
+	jsonOK(w, resp)
+}
+
+const maxChangesWait = 60 * time.Second
+
+// GET /sync/books
+// --- SyncPull ---
+// SyncPull godoc
+// @Summary      Pull changes for offline sync
+// @Description  Returns books created/updated and tombstones for books deleted after `checkpoint`. Checkpoint 0 (or omitted) starts an initial sync, which returns every book in id order, `limit` at a time (at most the max page size). While it has more, the response carries a `snapshot` token to send back for the next page and checkpoint 0; the last page has the checkpoint. Store the returned checkpoint and keep pulling while has_more is true.
+// @Tags         sync
+// @Produce      json
+// @Param        checkpoint  query     int     false  "Checkpoint from the previous pull"  minimum(0)
+// @Param        snapshot    query     string  false  "Snapshot token from the previous page of an initial sync"
+// @Param        limit       query     int     false  "Max change-log entries to scan, or books per page of an initial sync (default 200)"  minimum(1)  maximum(1000)
+// @Success      200         {object}  ports.SyncPullResponse
+// @Failure      400         {object}  ports.ErrorResponse
+// @Failure      500         {object}  ports.ErrorResponse
+// @Router       /sync/books [get]
+func (h *Handler) SyncPull(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var checkpoint int64
+	if v := q.Get("checkpoint"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			httpError(w, http.StatusBadRequest, "invalid checkpoint")
+			return
+		}
+		checkpoint = n
+	}
+	var snapshot *ports.SyncSnapshot
+	if v := q.Get("snapshot"); v != "" {
+		snap, err := ports.ParseSyncSnapshot(v)
+		if err != nil || checkpoint != 0 {
+			httpError(w, http.StatusBadRequest, "invalid snapshot")
+			return
+		}
+		snapshot = &snap
+	}
+	limit := 200
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 1000 {
+			httpError(w, http.StatusBadRequest, "invalid limit (1-1000)")
+			return
+		}
+		limit = n
+	}
+
+	resp, err := h.sync.Pull(r.Context(), checkpoint, snapshot, limit)
+	if err != nil {
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, resp)
+}
+
+// POST /sync/books
+// --- SyncPush ---
+// SyncPush godoc
+// @Summary      Push offline edits
+// @Description  Applies creates/updates/deletes made offline. Updates are three-way merged against `base`; strategy "merge" resolves conflicting fields per `field_strategies` (server-wins by default), "server-wins" rejects a change on any conflict, "client-wins" overwrites.
+// @Tags         sync
+// @Accept       json
+// @Produce      json
+// @Param        body  body      ports.SyncPushRequest  true  "Offline changes"
+// @Success      200   {object}  ports.SyncPushResponse
+// @Failure      400   {object}  ports.ErrorResponse
+// @Failure      403   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Failure      500   {object}  ports.ErrorResponse
+// @Router       /sync/books [post]
+func (h *Handler) SyncPush(w http.ResponseWriter, r *http.Request) {
+	var in ports.SyncPushRequest
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	resp, err := h.sync.Push(r.Context(), in)
+	if err != nil {
+		if ve, ok := err.(*appsvc.ValidationError); ok {
+			httpValidation(w, ve)
+			return
+		}
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, resp)
+}
+
 // helpers
 
 func parseIDParam(w http.ResponseWriter, r *http.Request) (int64, bool) {
@@ -196,6 +1138,19 @@ func parseIDParam(w http.ResponseWriter, r *http.Request) (int64, bool) {
 	return id, true
 }
 
+// presentBook shapes b for the caller of r: the derived fields, without the
+// fields the field policy keeps from it.
+func (h *Handler) presentBook(r *http.Request, b domain.Book) presenter.BookView {
+	actor, _ := domain.ActorFrom(r.Context())
+	return h.fields.Apply(presenter.Book(b, h.now()), actor)
+}
+
+// presentBooks is presentBook for a list.
+func (h *Handler) presentBooks(r *http.Request, bs []domain.Book) []presenter.BookView {
+	actor, _ := domain.ActorFrom(r.Context())
+	return h.fields.ApplyAll(presenter.Books(bs, h.now()), actor)
+}
+
 func jsonOK(w http.ResponseWriter, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -209,33 +1164,67 @@ func jsonCreated(w http.ResponseWriter, v any) {
 }
 
 func httpError(w http.ResponseWriter, code int, msg string) {
+	httpErrorCode(w, code, "", msg)
+}
+
+// httpErrorCode is httpError with a machine-readable errorCode for clients.
+func httpErrorCode(w http.ResponseWriter, code int, errorCode, msg string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
-	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+	_ = json.NewEncoder(w).Encode(ports.ErrorResponse{Error: msg, Code: errorCode, RequestID: requestID(w)})
 }
 
 // ---- URL Cleanup ----
 
 type cleanupRequest struct {
-	URL       string `json:"url"`
-	Operation string `json:"operation"` // "redirection" | "canonical" | "all"
+	URL       string `json:"url" example:"https://Example.com/Path/?a=1"`
+	Operation string `json:"operation,omitempty" example:"all"` // "redirection" | "canonical" | "all" | "resolve", any case
+	// Profile names a cleanup profile instead of an operation.
+	Profile string `json:"profile,omitempty" example:""`
+	// Fragment overrides what the operation or profile does with the
+	// #fragment: "drop", "keep", or "routes" to keep only #/ and #! routes.
+	Fragment string `json:"fragment,omitempty" example:"routes"`
+	// Duplicates overrides what happens to repeated query keys:
+	// "keep_all", "keep_first" or "keep_last".
+	Duplicates string `json:"duplicates,omitempty" example:"keep_first"`
+	// EmptyParams overrides what happens to parameters without a value:
+	// "keep" or "drop".
+	EmptyParams string `json:"empty_params,omitempty" example:"drop"`
+	// RespectRobots makes the resolve operation honour robots.txt.
+	RespectRobots bool `json:"respect_robots,omitempty"`
 }
 
 type cleanupResponse struct {
 	ProcessedURL string `json:"processed_url"`
+	// Status is set by the resolve operation: "resolved", or
+	// "blocked_by_robots" when processed_url is a URL robots.txt disallows.
+	Status string `json:"status,omitempty" enums:"resolved,blocked_by_robots"`
+	// Host is the processed URL's host, punycode and Unicode, with a flag
+	// for mixed-script confusables.
+	Host *urlclean.Host `json:"host,omitempty"`
 }
 
 // cleanupRequest and cleanupResponse are already declared in your file.
 
 // CleanupURL godoc
 // @Summary      Normalize/cleanup a URL
-// @Description  operation: "redirection" | "canonical" | "all"
+// @Description  operation: "redirection" | "canonical" | "all" | "resolve". url must be an absolute http or https URL of at most 2048 characters; invalid fields are reported per field with 422.
+// @Description  Instead of an operation, profile may name any profile listed by GET /url/profiles.
+// @Description  fragment overrides what the operation or profile does with the #fragment: "drop" it, "keep" it, or keep only single-page app "routes" (#/path, #!path).
+// @Description  duplicates overrides what happens to a query key given more than once: "keep_all" values, "keep_first" or "keep_last". empty_params "keep"s or "drop"s parameters without a value (?b= and ?b). Kept parameters stay in their order.
+// @Description  "resolve" follows the URL's redirects and returns where it lands. It only fetches public http(s) URLs without credentials; localhost and private addresses get 422, and unreachable URLs 502.
+// @Description  host gives the processed URL's host in punycode (ascii) and Unicode, the scripts of its letters, and confusable when a label mixes scripts or spells a Latin lookalike in Cyrillic, Greek or Armenian, as spoofed domains do.
+// @Description  With respect_robots, resolve doesn't fetch URLs the host's robots.txt disallows; it stops there with status "blocked_by_robots".
+// @Description  strip_tracking_params also applies the caller's own rules, managed under /url/cleanup/rules.
 // @Tags         tools
 // @Accept       json
 // @Produce      json
 // @Param        body  body      cleanupRequest   true  "Cleanup payload"
 // @Success      200   {object}  cleanupResponse
 // @Failure      400   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Failure      500   {object}  ports.ErrorResponse
+// @Failure      502   {object}  ports.ErrorResponse
 // @Router       /url/cleanup [post]
 func (h *Handler) CleanupURL(w http.ResponseWriter, r *http.Request) {
 	var req cleanupRequest
@@ -243,104 +1232,217 @@ func (h *Handler) CleanupURL(w http.ResponseWriter, r *http.Request) {
 		httpError(w, http.StatusBadRequest, "invalid JSON body")
 		return
 	}
-	op := strings.ToLower(strings.TrimSpace(req.Operation))
-	out, err := processURL(op, req.URL)
+	overrides := appsvc.CleanupOverrides{
+		Fragment:    urlclean.Fragment(strings.ToLower(strings.TrimSpace(req.Fragment))),
+		Duplicates:  urlclean.Duplicates(strings.ToLower(strings.TrimSpace(req.Duplicates))),
+		EmptyParams: urlclean.EmptyParams(strings.ToLower(strings.TrimSpace(req.EmptyParams))),
+	}
+	raw, op, err := appsvc.ValidateURLCleanup(req.URL, req.Operation, req.Profile, overrides, h.cleaner)
+	outcome := domain.CleanupOK
+	defer func() { h.recordCleanup(raw, op, outcome) }()
+	if err != nil {
+		outcome = domain.CleanupInvalid
+		var ve *appsvc.ValidationError
+		if errors.As(err, &ve) {
+			httpValidation(w, ve)
+			return
+		}
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if op == "resolve" {
+		outcome = h.resolveURL(w, r, raw, ports.ResolveOptions{RespectRobots: req.RespectRobots})
+		return
+	}
+	out, err := h.cleaner.Clean(r.Context(), raw, op, overrides)
+	if errors.Is(err, urlclean.ErrPort) {
+		outcome = domain.CleanupInvalid
+		httpValidation(w, &appsvc.ValidationError{Fields: map[string]string{"url": err.Error()}})
+		return
+	}
+	if errors.Is(err, appsvc.ErrTrackingRules) {
+		outcome = domain.CleanupFailed
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
 	if err != nil {
+		outcome = domain.CleanupInvalid
 		httpError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	jsonOK(w, cleanupResponse{ProcessedURL: out})
+	h.recordHistory(r, raw, out, op)
+	jsonOK(w, cleanupResponse{ProcessedURL: out, Host: hostForms(out)})
 }
 
-func processURL(op, raw string) (string, error) {
-	u, err := url.Parse(raw)
-	if err != nil || u.Scheme == "" || u.Host == "" {
-		return "", fmt.Errorf("invalid url")
+// hostForms describes the host of a processed URL, or returns nil when it
+// isn't a valid IDNA host name.
+func hostForms(processed string) *urlclean.Host {
+	u, err := urlclean.Parse(processed)
+	if err != nil {
+		return nil
 	}
-
-	switch op {
-	case "canonical":
-		// Keep host/path as-is; drop query & fragment.
-		u.RawQuery = ""
-		u.Fragment = ""
-		u.Path = strings.TrimSuffix(u.Path, "/")
-		return u.String(), nil
-
-	case "redirection":
-		return applyRedirection(u), nil
-
-	case "all":
-		// redirection + canonical
-		redir := applyRedirection(cloneURL(u))
-		u2, err := url.Parse(redir)
-		if err != nil {
-			return "", fmt.Errorf("unexpected parse error")
-		}
-		u2.RawQuery = ""
-		u2.Fragment = ""
-		return u2.String(), nil
-
-	default:
-		return "", fmt.Errorf("invalid operation (use: redirection|canonical|all)")
+	h, err := urlclean.ParseHost(u.Hostname())
+	if err != nil {
+		return nil
 	}
+	return &h
 }
 
-func applyRedirection(u *url.URL) string {
-	// 1) lowercase host and add www. for bare domains (example.com -> www.example.com)
-	host := strings.ToLower(u.Host)
-	if idx := strings.IndexByte(host, ':'); idx != -1 { // strip port for decision
-		hostOnly := host[:idx]
-		if needsWWW(hostOnly) {
-			hostOnly = "www." + hostOnly
-		}
-		host = hostOnly + host[idx:]
-	} else if needsWWW(host) {
-		host = "www." + host
+// recordCleanup counts a cleanup request in the cleanup stats, if enabled.
+func (h *Handler) recordCleanup(raw, op, outcome string) {
+	if h.cleanupStats == nil {
+		return
+	}
+	var host string
+	if u, err := urlclean.Parse(raw); err == nil {
+		host = u.Hostname()
 	}
+	h.cleanupStats.Record(host, op, outcome)
+}
 
-	// 2) lowercase path & drop trailing slash
-	path := strings.TrimSuffix(strings.ToLower(u.Path), "/")
+// ListCleanupProfiles godoc
+// @Summary      List URL cleanup profiles
+// @Description  The built-in operations and the profiles configured in CLEANUP_PROFILES, each with the rules it applies in order.
+// @Tags         tools
+// @Produce      json
+// @Success      200  {array}  domain.CleanupProfile
+// @Router       /url/profiles [get]
+func (h *Handler) ListCleanupProfiles(w http.ResponseWriter, r *http.Request) {
+	jsonOK(w, h.cleaner.Profiles())
+}
 
-	// 3) keep query params but trim trailing slashes from values
-	q := u.Query()
-	for k, vals := range q {
-		for i, v := range vals {
-			vals[i] = strings.TrimSuffix(v, "/")
-		}
-		q[k] = vals
+// resolveURL answers a resolve operation and returns its outcome for the
+// cleanup stats.
+func (h *Handler) resolveURL(w http.ResponseWriter, r *http.Request, raw string, opts ports.ResolveOptions) string {
+	if h.resolver == nil {
+		httpValidation(w, &appsvc.ValidationError{Fields: map[string]string{"operation": "Operation resolve is not enabled"}})
+		return domain.CleanupInvalid
+	}
+	out, err := h.resolver.Resolve(r.Context(), raw, opts)
+	var ve *appsvc.ValidationError
+	switch {
+	case errors.As(err, &ve):
+		httpValidation(w, ve)
+		return domain.CleanupInvalid
+	case err != nil:
+		httpError(w, http.StatusBadGateway, ports.ErrURLUnreachable.Error())
+		return domain.CleanupUnreachable
 	}
+	status := "resolved"
+	if out.BlockedByRobots {
+		status = "blocked_by_robots"
+	}
+	h.recordHistory(r, raw, out.URL, "resolve")
+	jsonOK(w, cleanupResponse{ProcessedURL: out.URL, Status: status, Host: hostForms(out.URL)})
+	return domain.CleanupOK
+}
 
-	u.Host = host
-	u.Path = path
-	u.RawQuery = q.Encode()
-	u.Fragment = "" // normalize: drop fragment for redirects
-	return u.String()
+type extractRequest struct {
+	URL string `json:"url" example:"https://bit.ly/3xyz"`
 }
 
-func needsWWW(host string) bool {
-	// Add www. only for simple root domains (one dot), e.g., example.com
-	// Avoid breaking subdomains like api.example.com
-	if strings.HasPrefix(host, "www.") {
-		return false
+// ExtractURL godoc
+// @Summary      Find a page's canonical URL
+// @Description  Fetches the page at url (following redirects, at most 1 MiB within URL_RESOLVE_TIMEOUT) and returns its <link rel="canonical">, og:url and sitemap hints, including the Sitemap lines of the host's robots.txt.
+// @Description  best_guess is the first of canonical, og_url and final_url that is an http(s) URL, normalized by the canonical cleanup operation; source says which one it is.
+// @Description  The same fetch rules as the resolve operation apply: localhost and private addresses get 422, and unreachable URLs or pages answering with an error get 502.
+// @Tags         tools
+// @Accept       json
+// @Produce      json
+// @Param        request  body      extractRequest  true  "Page to read"
+// @Success      200      {object}  domain.PageLinks
+// @Failure      400      {object}  ports.ErrorResponse
+// @Failure      422      {object}  validationPayload
+// @Failure      502      {object}  ports.ErrorResponse
+// @Router       /url/extract [post]
+func (h *Handler) ExtractURL(w http.ResponseWriter, r *http.Request) {
+	var req extractRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	links, err := h.extractor.Extract(r.Context(), req.URL)
+	var ve *appsvc.ValidationError
+	switch {
+	case errors.As(err, &ve):
+		httpValidation(w, ve)
+		return
+	case err != nil:
+		httpError(w, http.StatusBadGateway, ports.ErrURLUnreachable.Error())
+		return
 	}
-	return strings.Count(host, ".") == 1
+	jsonOK(w, links)
 }
 
-func cloneURL(u *url.URL) *url.URL {
-	c := *u
-	return &c
+// CleanupStats godoc
+// @Summary      URL cleanup usage report
+// @Description  Requests to POST /url/cleanup over the last days days (today included): totals and error rate, the top busiest domains, and the breakdown per operation or profile and per cleanup rule. Counts are flushed every few seconds, so the latest requests may be missing. Requires the admin scope.
+// @Tags         tools
+// @Produce      json
+// @Param        days  query     int  false  "Days to report on, 1-90"  default(7)
+// @Param        top   query     int  false  "Domains to list, 1-100"   default(10)
+// @Success      200   {object}  domain.CleanupStats
+// @Failure      400   {object}  ports.ErrorResponse
+// @Failure      403   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Failure      500   {object}  ports.ErrorResponse
+// @Router       /url/cleanup/stats [get]
+func (h *Handler) CleanupStats(w http.ResponseWriter, r *http.Request) {
+	days, top := 7, 10
+	for name, dst := range map[string]*int{"days": &days, "top": &top} {
+		if v := r.URL.Query().Get(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				httpError(w, http.StatusBadRequest, name+" must be an integer")
+				return
+			}
+			*dst = n
+		}
+	}
+	stats, err := h.cleanupStats.CleanupStats(r.Context(), days, top)
+	if err != nil {
+		var ve *appsvc.ValidationError
+		if errors.As(err, &ve) {
+			httpValidation(w, ve)
+			return
+		}
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, stats)
 }
 
 type validationPayload struct {
-	Error  string            `json:"error"`
-	Fields map[string]string `json:"fields"`
+	Error     string            `json:"error"`
+	Fields    map[string]string `json:"fields"`
+	RequestID string            `json:"request_id,omitempty"`
 }
 
 func httpValidation(w http.ResponseWriter, ve *appsvc.ValidationError) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusUnprocessableEntity) // 422
 	_ = json.NewEncoder(w).Encode(validationPayload{
-		Error:  "validation error",
-		Fields: ve.Fields,
+		Error:     "validation error",
+		Fields:    ve.Fields,
+		RequestID: requestID(w),
+	})
+}
+
+type conflictPayload struct {
+	Error  string   `json:"error"`
+	Fields []string `json:"fields"`
+	// Version is the book's current version when the conflict is on version.
+	Version   int64  `json:"version,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+func httpConflict(w http.ResponseWriter, ce *appsvc.ConflictError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	_ = json.NewEncoder(w).Encode(conflictPayload{
+		Error:     "conflict",
+		Fields:    ce.Fields,
+		Version:   ce.Version,
+		RequestID: requestID(w),
 	})
 }
@@ -4,27 +4,37 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	appsvc "github.com/gerry-sabar/byfood/internal/app"
 	"github.com/gerry-sabar/byfood/internal/domain"
 	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/gerry-sabar/byfood/pkg/urlclean"
 )
 
 type cleanupResp struct {
-	ProcessedURL string `json:"processed_url"`
+	ProcessedURL string         `json:"processed_url"`
+	Status       string         `json:"status"`
+	Host         *urlclean.Host `json:"host"`
 }
 
 type mockBookService struct {
-	ListBooksFn  func(ctx context.Context) ([]domain.Book, error)
-	CreateBookFn func(ctx context.Context, in ports.CreateBookInput) (*domain.Book, error)
-	GetBookFn    func(ctx context.Context, id int64) (*domain.Book, error)
-	UpdateBookFn func(ctx context.Context, id int64, in ports.UpdateBookInput) (*domain.Book, error)
-	DeleteBookFn func(ctx context.Context, id int64) error
+	ListBooksFn   func(ctx context.Context) ([]domain.Book, error)
+	SearchBooksFn func(ctx context.Context, q string) ([]domain.Book, error)
+	ListPageFn    func(ctx context.Context, f ports.ListFilter, page ports.Page) (*ports.BookPage, error)
+	CreateBookFn  func(ctx context.Context, in ports.CreateBookInput) (*domain.Book, error)
+	GetBookFn     func(ctx context.Context, id int64) (*domain.Book, error)
+	GetByISBNFn   func(ctx context.Context, isbn string) (*domain.Book, error)
+	UpdateBookFn  func(ctx context.Context, id int64, in ports.UpdateBookInput) (*domain.Book, error)
+	DeleteBookFn  func(ctx context.Context, id int64) error
+	SplitBookFn   func(ctx context.Context, id int64, in ports.SplitBookInput) (*ports.SplitBookResult, error)
+	ImportFn      func(ctx context.Context, rows []ports.ImportRow) (*ports.ImportResult, error)
 }
 
 func decodeCleanup(t *testing.T, res *http.Response) cleanupResp {
@@ -40,15 +50,30 @@ func decodeCleanup(t *testing.T, res *http.Response) cleanupResp {
 func (m *mockBookService) ListBooks(ctx context.Context) ([]domain.Book, error) {
 	return m.ListBooksFn(ctx)
 }
+func (m *mockBookService) ListBooksPage(ctx context.Context, f ports.ListFilter, page ports.Page) (*ports.BookPage, error) {
+	return m.ListPageFn(ctx, f, page)
+}
+func (m *mockBookService) SearchBooks(ctx context.Context, q string) ([]domain.Book, error) {
+	return m.SearchBooksFn(ctx, q)
+}
 func (m *mockBookService) CreateBook(ctx context.Context, in ports.CreateBookInput) (*domain.Book, error) {
 	return m.CreateBookFn(ctx, in)
 }
 func (m *mockBookService) GetBook(ctx context.Context, id int64) (*domain.Book, error) {
 	return m.GetBookFn(ctx, id)
 }
+func (m *mockBookService) GetBookByISBN(ctx context.Context, isbn string) (*domain.Book, error) {
+	return m.GetByISBNFn(ctx, isbn)
+}
 func (m *mockBookService) UpdateBook(ctx context.Context, id int64, in ports.UpdateBookInput) (*domain.Book, error) {
 	return m.UpdateBookFn(ctx, id, in)
 }
+func (m *mockBookService) SplitBook(ctx context.Context, id int64, in ports.SplitBookInput) (*ports.SplitBookResult, error) {
+	return m.SplitBookFn(ctx, id, in)
+}
+func (m *mockBookService) ImportBooks(ctx context.Context, rows []ports.ImportRow) (*ports.ImportResult, error) {
+	return m.ImportFn(ctx, rows)
+}
 func (m *mockBookService) DeleteBook(ctx context.Context, id int64) error {
 	return m.DeleteBookFn(ctx, id)
 }
@@ -112,6 +137,204 @@ func TestListBooks_OK(t *testing.T) {
 	}
 }
 
+func TestListBooks_Search(t *testing.T) {
+	var gotQ string
+	mock := &mockBookService{
+		SearchBooksFn: func(ctx context.Context, q string) ([]domain.Book, error) {
+			gotQ = q
+			return []domain.Book{{ID: 1, Author: "Gabriel García Márquez"}}, nil
+		},
+	}
+	ts := newTestServer(t, mock)
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodGet, "/books/?q=garcia+marquez", nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", res.StatusCode)
+	}
+	readBody(t, res)
+	if gotQ != "garcia marquez" {
+		t.Fatalf("q = %q", gotQ)
+	}
+}
+
+func TestListBooks_Paged(t *testing.T) {
+	var got ports.Page
+	mock := &mockBookService{
+		ListPageFn: func(ctx context.Context, f ports.ListFilter, page ports.Page) (*ports.BookPage, error) {
+			got = page
+			return &ports.BookPage{Books: []domain.Book{{ID: 7, Title: "G"}}, Total: 25}, nil
+		},
+	}
+	ts := newTestServer(t, mock)
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodGet, "/books/?q=x&limit=10&offset=10", nil)
+	body := readBody(t, res)
+	if res.StatusCode != http.StatusOK || !contains(body, `"title":"G"`) {
+		t.Fatalf("status = %d, body = %s", res.StatusCode, body)
+	}
+	if got != (ports.Page{Limit: 10, Offset: 10}) {
+		t.Fatalf("page = %+v", got)
+	}
+	if n := res.Header.Get("X-Total-Count"); n != "25" {
+		t.Fatalf("X-Total-Count = %q", n)
+	}
+	link := res.Header.Get("Link")
+	if !contains(link, `</books/?limit=10&offset=0&q=x>; rel="prev"`) || !contains(link, `</books/?limit=10&offset=20&q=x>; rel="next"`) {
+		t.Fatalf("Link = %q", link)
+	}
+}
+
+func TestListBooks_UnpagedTotal(t *testing.T) {
+	mock := &mockBookService{
+		ListBooksFn: func(ctx context.Context) ([]domain.Book, error) {
+			return []domain.Book{{ID: 1}, {ID: 2}}, nil
+		},
+	}
+	ts := newTestServer(t, mock)
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodGet, "/books/", nil)
+	readBody(t, res)
+	if n := res.Header.Get("X-Total-Count"); n != "2" || res.Header.Get("Link") != "" {
+		t.Fatalf("X-Total-Count = %q, Link = %q", n, res.Header.Get("Link"))
+	}
+}
+
+func TestListBooks_BadPage(t *testing.T) {
+	ts := newTestServer(t, &mockBookService{})
+	defer ts.Close()
+
+	for _, q := range []string{"limit=0", "limit=x", "offset=-1"} {
+		res := do(t, ts, http.MethodGet, "/books/?"+q, nil)
+		readBody(t, res)
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want 400", q, res.StatusCode)
+		}
+	}
+}
+
+func TestListBooks_PageLimits(t *testing.T) {
+	var got ports.Page
+	mock := &mockBookService{
+		ListPageFn: func(ctx context.Context, f ports.ListFilter, page ports.Page) (*ports.BookPage, error) {
+			got = page
+			return &ports.BookPage{Books: []domain.Book{{ID: 1}}, Total: 120}, nil
+		},
+	}
+	ts := newSpecServer(t, mock, WithPageLimits(ports.PageLimits{Default: 50, Max: 200}))
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodGet, "/books/", nil)
+	readBody(t, res)
+	if res.StatusCode != http.StatusOK || got.Limit != 50 {
+		t.Fatalf("status = %d, page = %+v", res.StatusCode, got)
+	}
+	if !contains(res.Header.Get("Link"), `</books/?limit=50&offset=50>; rel="next"`) {
+		t.Fatalf("Link = %q", res.Header.Get("Link"))
+	}
+
+	res = do(t, ts, http.MethodGet, "/books/?limit=200", nil)
+	readBody(t, res)
+	if res.StatusCode != http.StatusOK || got.Limit != 200 {
+		t.Fatalf("status = %d, page = %+v", res.StatusCode, got)
+	}
+
+	res = do(t, ts, http.MethodGet, "/books/?limit=201", nil)
+	body := readBody(t, res)
+	if res.StatusCode != http.StatusBadRequest || !contains(body, `"code":"page_size_exceeded"`) || !contains(body, "at most 200") {
+		t.Fatalf("status = %d, body = %s", res.StatusCode, body)
+	}
+}
+
+func TestListBooks_QueryTooExpensive(t *testing.T) {
+	mock := &mockBookService{
+		ListPageFn: func(ctx context.Context, f ports.ListFilter, page ports.Page) (*ports.BookPage, error) {
+			return nil, &appsvc.QueryCostError{Books: 500000, Reason: "year and price ranges aren't indexed"}
+		},
+	}
+	ts := newSpecServer(t, mock)
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodGet, "/books/?year_from=1940", nil)
+	body := readBody(t, res)
+	if res.StatusCode != http.StatusBadRequest || !contains(body, `"code":"query_too_expensive"`) || !contains(body, "add author or isbn") {
+		t.Fatalf("status = %d, body = %s", res.StatusCode, body)
+	}
+}
+
+func TestListBooks_Filters(t *testing.T) {
+	var got ports.ListFilter
+	mock := &mockBookService{
+		ListPageFn: func(ctx context.Context, f ports.ListFilter, page ports.Page) (*ports.BookPage, error) {
+			got = f
+			return &ports.BookPage{Books: []domain.Book{{ID: 3}}, Total: 1}, nil
+		},
+	}
+	ts := newTestServer(t, mock)
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodGet, "/books/?author=Borges&year_from=1940&year_to=1950&price_min=9.5&price_max=20", nil)
+	readBody(t, res)
+	if res.StatusCode != http.StatusOK || res.Header.Get("X-Total-Count") != "1" {
+		t.Fatalf("status = %d, X-Total-Count = %q", res.StatusCode, res.Header.Get("X-Total-Count"))
+	}
+	if got.Author != "Borges" || *got.YearFrom != 1940 || *got.YearTo != 1950 || *got.PriceMin != 9.5 || *got.PriceMax != 20 {
+		t.Fatalf("filter = %+v", got)
+	}
+
+	res = do(t, ts, http.MethodGet, "/books/?isbn=978-0-8021-3030-3&exact=true", nil)
+	readBody(t, res)
+	if res.StatusCode != http.StatusOK || got.ISBN != "978-0-8021-3030-3" || !got.Exact {
+		t.Fatalf("status = %d, filter = %+v", res.StatusCode, got)
+	}
+
+	for _, q := range []string{"year_from=x", "year_to=1.5", "price_min=abc", "price_max=NaN", "exact=maybe"} {
+		res := do(t, ts, http.MethodGet, "/books/?"+q, nil)
+		readBody(t, res)
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want 400", q, res.StatusCode)
+		}
+	}
+}
+
+func TestListBooks_Sort(t *testing.T) {
+	var got ports.Page
+	mock := &mockBookService{
+		ListPageFn: func(ctx context.Context, f ports.ListFilter, page ports.Page) (*ports.BookPage, error) {
+			got = page
+			return &ports.BookPage{}, nil
+		},
+	}
+	ts := newTestServer(t, mock)
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodGet, "/books/?sort=-title&collation=tr", nil)
+	readBody(t, res)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", res.StatusCode)
+	}
+	if want := (ports.Sort{Field: "title", Desc: true, Collation: "tr"}); got.Sort != want {
+		t.Fatalf("sort = %+v; want %+v", got.Sort, want)
+	}
+}
+
+func TestListBooks_FilterValidation(t *testing.T) {
+	mock := &mockBookService{
+		ListPageFn: func(ctx context.Context, f ports.ListFilter, page ports.Page) (*ports.BookPage, error) {
+			return nil, &appsvc.ValidationError{Fields: map[string]string{"year_from": "year_from must not be after year_to"}}
+		},
+	}
+	ts := newTestServer(t, mock)
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodGet, "/books/?year_from=2000&year_to=1990", nil)
+	if body := readBody(t, res); res.StatusCode != http.StatusUnprocessableEntity || !contains(body, "year_from") {
+		t.Fatalf("status = %d, body = %s", res.StatusCode, body)
+	}
+}
+
 func TestListBooks_ServiceError(t *testing.T) {
 	mock := &mockBookService{
 		ListBooksFn: func(ctx context.Context) ([]domain.Book, error) {
@@ -229,6 +452,89 @@ func TestGetBook_Error(t *testing.T) {
 	}
 }
 
+func TestBookLabel(t *testing.T) {
+	mock := &mockBookService{
+		GetBookFn: func(ctx context.Context, id int64) (*domain.Book, error) {
+			if id != 7 {
+				return nil, nil
+			}
+			return &domain.Book{ID: 7, Title: "Clean Code", ISBN: "9780132350884", Price: 30}, nil
+		},
+	}
+	ts := newTestServer(t, mock)
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodGet, "/books/7/label.zpl", nil)
+	body := readBody(t, res)
+	if res.StatusCode != http.StatusOK || !strings.HasPrefix(body, "^XA") || !contains(body, "^FDClean Code^FS") {
+		t.Fatalf("status = %d body = %s", res.StatusCode, body)
+	}
+
+	res = do(t, ts, http.MethodGet, "/books/8/label.zpl", nil)
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", res.StatusCode)
+	}
+}
+
+func TestSplitBook(t *testing.T) {
+	work := int64(1)
+	mock := &mockBookService{
+		SplitBookFn: func(ctx context.Context, id int64, in ports.SplitBookInput) (*ports.SplitBookResult, error) {
+			switch {
+			case id == 9:
+				return nil, errors.New("book not found")
+			case in.Fields.ISBN == nil:
+				return nil, &appsvc.ValidationError{Fields: map[string]string{"fields.isbn": "ISBN of the new edition is required"}}
+			}
+			return &ports.SplitBookResult{
+				Source:  domain.Book{ID: id, WorkID: &work},
+				Edition: domain.Book{ID: 2, ISBN: *in.Fields.ISBN, WorkID: &work},
+			}, nil
+		},
+	}
+	ts := newTestServer(t, mock)
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodPost, "/books/1/split", map[string]any{"fields": map[string]any{"isbn": "9780321125217"}})
+	body := readBody(t, res)
+	if res.StatusCode != http.StatusCreated || !contains(body, `"edition":{"id":2`) || !contains(body, `"work_id":1`) {
+		t.Fatalf("status = %d body = %s", res.StatusCode, body)
+	}
+
+	res = do(t, ts, http.MethodPost, "/books/1/split", map[string]any{})
+	if res.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422", res.StatusCode)
+	}
+	res.Body.Close()
+
+	res = do(t, ts, http.MethodPost, "/books/9/split", map[string]any{})
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", res.StatusCode)
+	}
+	res.Body.Close()
+}
+
+func TestGetBook_DerivedFields(t *testing.T) {
+	mock := &mockBookService{
+		GetBookFn: func(ctx context.Context, id int64) (*domain.Book, error) {
+			return &domain.Book{ID: id, Title: "Ulysses", PublicationYear: 1922}, nil
+		},
+	}
+	h := NewHandler(mock)
+	h.now = func() time.Time { return time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC) }
+	ts := httptest.NewServer(h.Router())
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodGet, "/books/1/", nil)
+	body := readBody(t, res)
+	for _, want := range []string{`"years_since_publication":104`, `"is_recent":false`, `"is_public_domain":true`} {
+		if !contains(body, want) {
+			t.Fatalf("body %s missing %s", body, want)
+		}
+	}
+}
+
 func TestGetBook_OK(t *testing.T) {
 	mock := &mockBookService{
 		GetBookFn: func(ctx context.Context, id int64) (*domain.Book, error) {
@@ -248,6 +554,37 @@ func TestGetBook_OK(t *testing.T) {
 	}
 }
 
+func TestGetBookByISBN(t *testing.T) {
+	mock := &mockBookService{
+		GetByISBNFn: func(ctx context.Context, isbn string) (*domain.Book, error) {
+			switch isbn {
+			case "0-321-12521-5":
+				return &domain.Book{ID: 4, ISBN: "9780321125217", ISBN10: "0321125215"}, nil
+			case "bad":
+				return nil, &appsvc.ValidationError{Fields: map[string]string{"isbn": "Invalid ISBN"}}
+			}
+			return nil, nil
+		},
+	}
+	ts := newSpecServer(t, mock)
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodGet, "/books/isbn/0-321-12521-5", nil)
+	body := readBody(t, res)
+	if res.StatusCode != http.StatusOK || !contains(body, `"isbn":"9780321125217"`) || !contains(body, `"isbn10":"0321125215"`) {
+		t.Fatalf("status = %d, body = %s", res.StatusCode, body)
+	}
+	if res.Header.Get("ETag") == "" {
+		t.Fatal("missing ETag")
+	}
+	if res := do(t, ts, http.MethodGet, "/books/isbn/9780132350884", nil); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("unknown isbn status = %d, want 404", res.StatusCode)
+	}
+	if res := do(t, ts, http.MethodGet, "/books/isbn/bad", nil); res.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("invalid isbn status = %d, want 422", res.StatusCode)
+	}
+}
+
 // --- UpdateBook ---
 
 func TestUpdateBook_InvalidID(t *testing.T) {
@@ -311,18 +648,74 @@ func TestUpdateBook_NotFound(t *testing.T) {
 	}
 }
 
+func TestUpdateBook_Conflict(t *testing.T) {
+	mock := &mockBookService{
+		UpdateBookFn: func(ctx context.Context, id int64, in ports.UpdateBookInput) (*domain.Book, error) {
+			if in.BaseUpdatedAt == nil {
+				t.Fatalf("base_updated_at not decoded")
+			}
+			return nil, &appsvc.ConflictError{Fields: []string{"price"}}
+		},
+	}
+	ts := newTestServer(t, mock)
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodPut, "/books/3/", map[string]any{"price": 9.5, "base_updated_at": "2024-01-01T00:00:00Z"})
+	if res.StatusCode != http.StatusConflict {
+		t.Fatalf("status = %d, want 409", res.StatusCode)
+	}
+	if body := readBody(t, res); !contains(body, `"fields":["price"]`) {
+		t.Fatalf("body = %s", body)
+	}
+}
+
+func TestUpdateBook_IfMatch(t *testing.T) {
+	mock := &mockBookService{
+		UpdateBookFn: func(ctx context.Context, id int64, in ports.UpdateBookInput) (*domain.Book, error) {
+			if in.Version == nil || *in.Version != 3 {
+				t.Fatalf("version = %v; want 3 from If-Match", in.Version)
+			}
+			return nil, &appsvc.ConflictError{Fields: []string{"version"}, Version: 4}
+		},
+	}
+	ts := newTestServer(t, mock)
+	defer ts.Close()
+
+	for _, etag := range []string{`"3"`, `W/"3"`} {
+		req, _ := http.NewRequest(http.MethodPut, ts.URL+"/books/3/", strings.NewReader(`{"price": 9.5}`))
+		req.Header.Set("If-Match", etag)
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("do: %v", err)
+		}
+		if body := readBody(t, res); res.StatusCode != http.StatusConflict || !contains(body, `"fields":["version"],"version":4`) {
+			t.Fatalf("%s: status = %d, body = %s", etag, res.StatusCode, body)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodPut, ts.URL+"/books/3/", strings.NewReader(`{"price": 9.5}`))
+	req.Header.Set("If-Match", `"abc"`)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	if readBody(t, res); res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("bad If-Match: status = %d, want 400", res.StatusCode)
+	}
+}
+
 func TestUpdateBook_OK(t *testing.T) {
 	mock := &mockBookService{
 		UpdateBookFn: func(ctx context.Context, id int64, in ports.UpdateBookInput) (*domain.Book, error) {
-			return &domain.Book{ID: id, Title: *in.Title}, nil
+			return &domain.Book{ID: id, Title: *in.Title, Version: 5}, nil
 		},
 	}
 	ts := newTestServer(t, mock)
 	defer ts.Close()
 
 	res := do(t, ts, http.MethodPut, "/books/3/", map[string]any{"title": "Edited"})
-	if res.StatusCode != http.StatusOK {
-		t.Fatalf("status = %d, want 200", res.StatusCode)
+	if res.StatusCode != http.StatusOK || res.Header.Get("ETag") != `"5"` {
+		t.Fatalf("status = %d, ETag = %q; want 200, \"5\"", res.StatusCode, res.Header.Get("ETag"))
 	}
 	body := readBody(t, res)
 	if !contains(body, `"title":"Edited"`) {
@@ -368,6 +761,213 @@ func TestDeleteBook_NoContent(t *testing.T) {
 	}
 }
 
+func TestDeleteBook_OnLoan(t *testing.T) {
+	mock := &mockBookService{
+		DeleteBookFn: func(ctx context.Context, id int64) error { return ports.ErrBookOnLoan },
+	}
+	ts := newTestServer(t, mock)
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodDelete, "/books/10/", nil)
+	if body := readBody(t, res); res.StatusCode != http.StatusConflict || !contains(body, `"code":"book_on_loan"`) {
+		t.Fatalf("status = %d %s, want 409 book_on_loan", res.StatusCode, body)
+	}
+}
+
+// --- BookChanges ---
+
+type mockChangeFeed struct {
+	SinceFn func(ctx context.Context, cursor int64, limit int, wait time.Duration) ([]domain.Change, error)
+}
+
+func (m *mockChangeFeed) Since(ctx context.Context, cursor int64, limit int, wait time.Duration) ([]domain.Change, error) {
+	return m.SinceFn(ctx, cursor, limit, wait)
+}
+
+func TestBookChanges_NotMountedWithoutFeed(t *testing.T) {
+	mock := &mockBookService{
+		GetBookFn: func(ctx context.Context, id int64) (*domain.Book, error) { return nil, nil },
+	}
+	ts := newTestServer(t, mock)
+	defer ts.Close()
+
+	// falls through to /books/{id} which rejects the non-numeric id
+	res := do(t, ts, http.MethodGet, "/books/changes", nil)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", res.StatusCode)
+	}
+}
+
+func TestBookChanges_OK(t *testing.T) {
+	feed := &mockChangeFeed{
+		SinceFn: func(ctx context.Context, cursor int64, limit int, wait time.Duration) ([]domain.Change, error) {
+			if cursor != 10 || limit != 100 || wait != 30*time.Second {
+				t.Fatalf("cursor=%d limit=%d wait=%v", cursor, limit, wait)
+			}
+			return []domain.Change{{ID: 11, BookID: 1, Op: domain.ChangeCreated}, {ID: 12, BookID: 2, Op: domain.ChangeDeleted}}, nil
+		},
+	}
+	ts := httptest.NewServer(NewHandler(&mockBookService{}, WithChangeFeed(feed)).Router())
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodGet, "/books/changes?since=10&wait=30s", nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", res.StatusCode)
+	}
+	body := readBody(t, res)
+	if !contains(body, `"cursor":12`) || !contains(body, `"op":"deleted"`) {
+		t.Fatalf("body = %s", body)
+	}
+}
+
+func TestBookChanges_TimeoutKeepsCursor(t *testing.T) {
+	feed := &mockChangeFeed{
+		SinceFn: func(ctx context.Context, cursor int64, limit int, wait time.Duration) ([]domain.Change, error) {
+			if wait != maxChangesWait {
+				t.Fatalf("wait = %v; want capped at %v", wait, maxChangesWait)
+			}
+			return nil, nil
+		},
+	}
+	ts := httptest.NewServer(NewHandler(&mockBookService{}, WithChangeFeed(feed)).Router())
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodGet, "/books/changes?since=5&wait=10m", nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", res.StatusCode)
+	}
+	body := readBody(t, res)
+	if !contains(body, `"changes":[]`) || !contains(body, `"cursor":5`) {
+		t.Fatalf("body = %s", body)
+	}
+}
+
+func TestBookChanges_BadParams(t *testing.T) {
+	ts := httptest.NewServer(NewHandler(&mockBookService{}, WithChangeFeed(&mockChangeFeed{})).Router())
+	defer ts.Close()
+
+	for _, q := range []string{"since=-1", "since=x", "wait=soon", "limit=0", "limit=501"} {
+		res := do(t, ts, http.MethodGet, "/books/changes?"+q, nil)
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want 400", q, res.StatusCode)
+		}
+		res.Body.Close()
+	}
+}
+
+// --- Sync ---
+
+type mockSyncService struct {
+	PullFn func(ctx context.Context, checkpoint int64, snapshot *ports.SyncSnapshot, limit int) (*ports.SyncPullResponse, error)
+	PushFn func(ctx context.Context, in ports.SyncPushRequest) (*ports.SyncPushResponse, error)
+}
+
+func (m *mockSyncService) Pull(ctx context.Context, checkpoint int64, snapshot *ports.SyncSnapshot, limit int) (*ports.SyncPullResponse, error) {
+	return m.PullFn(ctx, checkpoint, snapshot, limit)
+}
+func (m *mockSyncService) Push(ctx context.Context, in ports.SyncPushRequest) (*ports.SyncPushResponse, error) {
+	return m.PushFn(ctx, in)
+}
+
+func TestSyncPull_OK(t *testing.T) {
+	sync := &mockSyncService{
+		PullFn: func(ctx context.Context, checkpoint int64, snapshot *ports.SyncSnapshot, limit int) (*ports.SyncPullResponse, error) {
+			if checkpoint != 7 || snapshot != nil || limit != 200 {
+				t.Fatalf("checkpoint=%d snapshot=%v limit=%d", checkpoint, snapshot, limit)
+			}
+			return &ports.SyncPullResponse{
+				Upserts:    []domain.Book{{ID: 1, Title: "A"}},
+				Tombstones: []ports.Tombstone{{ID: 2}},
+				Checkpoint: 9,
+			}, nil
+		},
+	}
+	ts := httptest.NewServer(NewHandler(&mockBookService{}, WithSync(sync)).Router())
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodGet, "/sync/books?checkpoint=7", nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", res.StatusCode)
+	}
+	body := readBody(t, res)
+	if !contains(body, `"checkpoint":9`) || !contains(body, `"tombstones":[{"id":2`) {
+		t.Fatalf("body = %s", body)
+	}
+}
+
+func TestSyncPull_Snapshot(t *testing.T) {
+	sync := &mockSyncService{
+		PullFn: func(ctx context.Context, checkpoint int64, snapshot *ports.SyncSnapshot, limit int) (*ports.SyncPullResponse, error) {
+			if checkpoint != 0 || snapshot == nil || *snapshot != (ports.SyncSnapshot{Checkpoint: 812, AfterID: 340}) || limit != 50 {
+				t.Fatalf("checkpoint=%d snapshot=%v limit=%d", checkpoint, snapshot, limit)
+			}
+			return &ports.SyncPullResponse{
+				Upserts:    []domain.Book{{ID: 341, Title: "A"}},
+				Tombstones: []ports.Tombstone{},
+				HasMore:    true,
+				Snapshot:   "812.341",
+			}, nil
+		},
+	}
+	ts := httptest.NewServer(NewHandler(&mockBookService{}, WithSync(sync)).Router())
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodGet, "/sync/books?snapshot=812.340&limit=50", nil)
+	if body := readBody(t, res); res.StatusCode != http.StatusOK || !contains(body, `"snapshot":"812.341"`) || !contains(body, `"checkpoint":0`) {
+		t.Fatalf("status = %d, body = %s", res.StatusCode, body)
+	}
+}
+
+func TestSyncPull_BadCheckpoint(t *testing.T) {
+	ts := httptest.NewServer(NewHandler(&mockBookService{}, WithSync(&mockSyncService{})).Router())
+	defer ts.Close()
+
+	for _, q := range []string{"checkpoint=-3", "snapshot=nope", "snapshot=812.0", "checkpoint=5&snapshot=812.340"} {
+		res := do(t, ts, http.MethodGet, "/sync/books?"+q, nil)
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want 400", q, res.StatusCode)
+		}
+	}
+}
+
+func TestSyncPush_ValidationError(t *testing.T) {
+	sync := &mockSyncService{
+		PushFn: func(ctx context.Context, in ports.SyncPushRequest) (*ports.SyncPushResponse, error) {
+			return nil, &appsvc.ValidationError{Fields: map[string]string{"strategy": "bad"}}
+		},
+	}
+	ts := httptest.NewServer(NewHandler(&mockBookService{}, WithSync(sync)).Router())
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodPost, "/sync/books", map[string]any{"strategy": "nope"})
+	if res.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422", res.StatusCode)
+	}
+}
+
+func TestSyncPush_OK(t *testing.T) {
+	sync := &mockSyncService{
+		PushFn: func(ctx context.Context, in ports.SyncPushRequest) (*ports.SyncPushResponse, error) {
+			if len(in.Changes) != 1 || in.Changes[0].Op != "update" || *in.Changes[0].Fields.Price != 12.5 {
+				t.Fatalf("in = %+v", in)
+			}
+			return &ports.SyncPushResponse{Results: []ports.SyncResult{{ID: 1, Status: "applied"}}}, nil
+		},
+	}
+	ts := httptest.NewServer(NewHandler(&mockBookService{}, WithSync(sync)).Router())
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodPost, "/sync/books", map[string]any{
+		"changes": []map[string]any{{"op": "update", "id": 1, "fields": map[string]any{"price": 12.5}}},
+	})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", res.StatusCode)
+	}
+	if body := readBody(t, res); !contains(body, `"status":"applied"`) {
+		t.Fatalf("body = %s", body)
+	}
+}
+
 // --- URL Cleanup endpoint ---
 
 func TestCleanupURL_Canonical(t *testing.T) {
@@ -425,8 +1025,189 @@ func TestCleanupURL_All(t *testing.T) {
 	}
 }
 
+func TestCleanupURL_Fragment(t *testing.T) {
+	ts := newSpecServer(t, &mockBookService{})
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodPost, "/url/cleanup", map[string]any{"url": "https://example.com/app/#/books/1", "operation": "redirection", "fragment": "routes"})
+	if cr := decodeCleanup(t, res); res.StatusCode != http.StatusOK || cr.ProcessedURL != "https://www.example.com/app#/books/1" {
+		t.Fatalf("routes: %d %+v", res.StatusCode, cr)
+	}
+	res = do(t, ts, http.MethodPost, "/url/cleanup", map[string]any{"url": "https://example.com/", "operation": "all", "fragment": "hashbang"})
+	if got := readBody(t, res); res.StatusCode != http.StatusUnprocessableEntity || !contains(got, `"fragment":"Fragment must be one of`) {
+		t.Fatalf("unknown fragment: %d %s", res.StatusCode, got)
+	}
+}
+
+func TestCleanupURL_QueryParams(t *testing.T) {
+	ts := newSpecServer(t, &mockBookService{})
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodPost, "/url/cleanup", map[string]any{"url": "https://example.com/p?a=1&b=&a=2",
+		"operation": "redirection", "duplicates": "Keep_Last", "empty_params": "drop"})
+	if cr := decodeCleanup(t, res); res.StatusCode != http.StatusOK || cr.ProcessedURL != "https://www.example.com/p?a=2" {
+		t.Fatalf("keep_last, drop: %d %+v", res.StatusCode, cr)
+	}
+	res = do(t, ts, http.MethodPost, "/url/cleanup", map[string]any{"url": "https://example.com/", "operation": "all",
+		"duplicates": "merge", "empty_params": "blank"})
+	if got := readBody(t, res); res.StatusCode != http.StatusUnprocessableEntity ||
+		!contains(got, `"duplicates":"Duplicates must be one of`) || !contains(got, `"empty_params":"Empty params must be one of`) {
+		t.Fatalf("unknown values: %d %s", res.StatusCode, got)
+	}
+}
+
+func TestCleanupURL_HostForms(t *testing.T) {
+	ts := newSpecServer(t, &mockBookService{})
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodPost, "/url/cleanup", map[string]any{"url": "https://xn--80ak6aa92e.com/Login", "operation": "canonical"})
+	cr := decodeCleanup(t, res)
+	if res.StatusCode != http.StatusOK || cr.Host == nil {
+		t.Fatalf("status = %d, host = %v", res.StatusCode, cr.Host)
+	}
+	if cr.Host.ASCII != "xn--80ak6aa92e.com" || cr.Host.Unicode != "аррӏе.com" || !cr.Host.Confusable {
+		t.Fatalf("host = %+v; want both forms and the confusable flag", cr.Host)
+	}
+	res = do(t, ts, http.MethodPost, "/url/cleanup", map[string]any{"url": "https://Example.com/", "operation": "canonical"})
+	if cr := decodeCleanup(t, res); cr.Host == nil || cr.Host.Unicode != "example.com" || cr.Host.Confusable {
+		t.Fatalf("plain host = %+v", cr.Host)
+	}
+}
+
+func TestCleanupURL_Profiles(t *testing.T) {
+	cleaner, err := appsvc.NewURLCleaner([]urlclean.Profile{
+		{Name: "cache_key", Rules: []urlclean.Rule{urlclean.LowercaseHost, urlclean.StripTrackingParams, urlclean.SortQuery}},
+		{Name: "no_ports", Rules: []urlclean.Rule{urlclean.RejectPort, urlclean.LowercaseHost}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := newSpecServer(t, &mockBookService{}, WithCleanupProfiles(cleaner))
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodGet, "/url/profiles", nil)
+	if body := readBody(t, res); res.StatusCode != http.StatusOK ||
+		!contains(body, `"name":"canonical"`) || !contains(body, `{"name":"cache_key","rules":["lowercase_host","strip_tracking_params","sort_query"],"built_in":false}`) {
+		t.Fatalf("profiles: %d %s", res.StatusCode, body)
+	}
+
+	res = do(t, ts, http.MethodPost, "/url/cleanup", map[string]any{
+		"url": "https://Example.com/Books?utm_medium=mail&b=2&a=1", "profile": "cache_key",
+	})
+	if cr := decodeCleanup(t, res); res.StatusCode != http.StatusOK || cr.ProcessedURL != "https://example.com/Books?a=1&b=2" {
+		t.Fatalf("cache_key: %d %+v", res.StatusCode, cr)
+	}
+	res = do(t, ts, http.MethodPost, "/url/cleanup", map[string]any{"url": "https://example.com:8443/", "profile": "no_ports"})
+	if body := readBody(t, res); res.StatusCode != http.StatusUnprocessableEntity || !contains(body, `"url":"URL must not have a port"`) {
+		t.Fatalf("rejected port: %d %s", res.StatusCode, body)
+	}
+	res = do(t, ts, http.MethodPost, "/url/cleanup", map[string]any{"url": "https://example.com", "profile": "seo"})
+	if body := readBody(t, res); res.StatusCode != http.StatusUnprocessableEntity || !contains(body, `"profile":"Unknown profile`) {
+		t.Fatalf("unknown profile: %d %s", res.StatusCode, body)
+	}
+}
+
+type mockResolver func(ctx context.Context, rawURL string, opts ports.ResolveOptions) (*ports.Resolution, error)
+
+func (f mockResolver) Resolve(ctx context.Context, rawURL string, opts ports.ResolveOptions) (*ports.Resolution, error) {
+	return f(ctx, rawURL, opts)
+}
+
+func TestCleanupURL_Resolve(t *testing.T) {
+	body := map[string]any{"url": "https://bit.ly/x", "operation": "resolve"}
+
+	ts := newSpecServer(t, &mockBookService{})
+	res := do(t, ts, http.MethodPost, "/url/cleanup", body)
+	if got := readBody(t, res); res.StatusCode != http.StatusUnprocessableEntity || !contains(got, `"operation":"Operation resolve is not enabled"`) {
+		t.Fatalf("without resolver: %d %s", res.StatusCode, got)
+	}
+	ts.Close()
+
+	ts = newSpecServer(t, &mockBookService{}, WithURLResolver(mockResolver(func(_ context.Context, u string, opts ports.ResolveOptions) (*ports.Resolution, error) {
+		switch u {
+		case "https://bit.ly/x":
+			return &ports.Resolution{URL: "https://example.com/books/1", BlockedByRobots: opts.RespectRobots}, nil
+		case "http://10.0.0.1/":
+			return nil, &appsvc.ValidationError{Fields: map[string]string{"url": "URL must not point to a private or reserved address"}}
+		}
+		return nil, ports.ErrURLUnreachable
+	})))
+	defer ts.Close()
+	res = do(t, ts, http.MethodPost, "/url/cleanup", body)
+	if cr := decodeCleanup(t, res); res.StatusCode != http.StatusOK || cr.ProcessedURL != "https://example.com/books/1" || cr.Status != "resolved" {
+		t.Fatalf("resolve: %d %+v", res.StatusCode, cr)
+	}
+	res = do(t, ts, http.MethodPost, "/url/cleanup", map[string]any{"url": "https://bit.ly/x", "operation": "resolve", "respect_robots": true})
+	if cr := decodeCleanup(t, res); res.StatusCode != http.StatusOK || cr.Status != "blocked_by_robots" {
+		t.Fatalf("blocked by robots: %d %+v", res.StatusCode, cr)
+	}
+	res = do(t, ts, http.MethodPost, "/url/cleanup", map[string]any{"url": "http://10.0.0.1/", "operation": "resolve"})
+	if got := readBody(t, res); res.StatusCode != http.StatusUnprocessableEntity || !contains(got, "private") {
+		t.Fatalf("private: %d %s", res.StatusCode, got)
+	}
+	res = do(t, ts, http.MethodPost, "/url/cleanup", map[string]any{"url": "https://down.example/", "operation": "resolve"})
+	if got := readBody(t, res); res.StatusCode != http.StatusBadGateway || !contains(got, "could not be reached") {
+		t.Fatalf("unreachable: %d %s", res.StatusCode, got)
+	}
+}
+
+type mockExtractor func(ctx context.Context, rawURL string) (*domain.PageLinks, error)
+
+func (f mockExtractor) Extract(ctx context.Context, rawURL string) (*domain.PageLinks, error) {
+	return f(ctx, rawURL)
+}
+
+func TestExtractURL(t *testing.T) {
+	ts := newSpecServer(t, &mockBookService{}, WithURLExtractor(mockExtractor(func(_ context.Context, u string) (*domain.PageLinks, error) {
+		switch u {
+		case "https://bit.ly/x":
+			return &domain.PageLinks{URL: u, FinalURL: "https://example.com/dune?utm_source=x", Canonical: "https://example.com/dune",
+				Sitemaps: []string{}, BestGuess: "https://example.com/dune", Source: domain.LinkSourceCanonical}, nil
+		case "ftp://example.com/":
+			return nil, &appsvc.ValidationError{Fields: map[string]string{"url": "URL scheme must be http or https"}}
+		}
+		return nil, ports.ErrURLUnreachable
+	})))
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodPost, "/url/extract", map[string]any{"url": "https://bit.ly/x"})
+	if got := readBody(t, res); res.StatusCode != http.StatusOK || !contains(got, `"best_guess":"https://example.com/dune","source":"canonical"`) {
+		t.Fatalf("extract: %d %s", res.StatusCode, got)
+	}
+	res = do(t, ts, http.MethodPost, "/url/extract", map[string]any{"url": "ftp://example.com/"})
+	if got := readBody(t, res); res.StatusCode != http.StatusUnprocessableEntity || !contains(got, `"url":"URL scheme`) {
+		t.Fatalf("invalid url: %d %s", res.StatusCode, got)
+	}
+	res = do(t, ts, http.MethodPost, "/url/extract", map[string]any{"url": "https://down.example/"})
+	if got := readBody(t, res); res.StatusCode != http.StatusBadGateway {
+		t.Fatalf("unreachable: %d %s", res.StatusCode, got)
+	}
+}
+
 // --- util for "book not found" error string matching ---
 
 type fmtError string
 
 func (e fmtError) Error() string { return string(e) }
+
+func TestCleanupURL_ValidationErrors(t *testing.T) {
+	ts := newSpecServer(t, &mockBookService{})
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodPost, "/url/cleanup", map[string]any{
+		"url":       "ftp://example.com/file",
+		"operation": "shorten",
+	})
+	body := readBody(t, res)
+	if res.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422; body = %s", res.StatusCode, body)
+	}
+	if !contains(body, `"url":"URL scheme must be http or https"`) || !contains(body, `"operation":"Operation must be one of`) {
+		t.Fatalf("body = %s", body)
+	}
+
+	res = do(t, ts, http.MethodPost, "/url/cleanup", map[string]any{"operation": "all"})
+	if body := readBody(t, res); res.StatusCode != http.StatusUnprocessableEntity || !contains(body, `"url":"URL is required"`) {
+		t.Fatalf("missing url: status = %d, body = %s", res.StatusCode, body)
+	}
+}
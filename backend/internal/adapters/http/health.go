@@ -0,0 +1,61 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+)
+
+type probeResponse struct {
+	Status string `json:"status"`
+	// Unhealthy lists the stalled or stopped background workers.
+	Unhealthy []string `json:"unhealthy,omitempty"`
+}
+
+// Healthz reports that the process is up. It never depends on the database.
+func Healthz(w http.ResponseWriter, r *http.Request) {
+	jsonOK(w, probeResponse{Status: "ok"})
+}
+
+// WorkerMonitor reports background workers that stopped making progress.
+type WorkerMonitor interface {
+	Unhealthy() []string
+}
+
+// Liveness is Healthz for a process with background workers: it answers 503
+// "degraded" with the workers' names while any of them is stalled or
+// stopped, so the replica gets restarted.
+type Liveness struct {
+	Workers WorkerMonitor
+}
+
+func (l Liveness) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if bad := l.Workers.Unhealthy(); len(bad) > 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(probeResponse{Status: "degraded", Unhealthy: bad})
+		return
+	}
+	Healthz(w, r)
+}
+
+// Readiness answers GET /readyz with 503 until SetReady(true) is called, so a
+// load balancer only routes traffic once startup work such as cache warm-up
+// has finished.
+type Readiness struct {
+	ready atomic.Bool
+}
+
+func (rd *Readiness) SetReady(v bool) { rd.ready.Store(v) }
+
+func (rd *Readiness) Ready() bool { return rd.ready.Load() }
+
+func (rd *Readiness) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !rd.Ready() {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(probeResponse{Status: "starting"})
+		return
+	}
+	jsonOK(w, probeResponse{Status: "ready"})
+}
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+func TestHealthz(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Healthz(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK || !contains(rec.Body.String(), `"ok"`) {
+		t.Fatalf("got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+type stubMonitor []string
+
+func (s stubMonitor) Unhealthy() []string { return s }
+
+func TestLiveness(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Liveness{Workers: stubMonitor(nil)}.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK || !contains(rec.Body.String(), `"ok"`) {
+		t.Fatalf("healthy: got %d %s", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	Liveness{Workers: stubMonitor{"saved_searches"}}.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusServiceUnavailable || !contains(rec.Body.String(), `"unhealthy":["saved_searches"]`) {
+		t.Fatalf("stalled worker: got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	var rd Readiness
+	rec := httptest.NewRecorder()
+	rd.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("before ready: got %d", rec.Code)
+	}
+
+	rd.SetReady(true)
+	rec = httptest.NewRecorder()
+	rd.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusOK || !contains(rec.Body.String(), `"ready"`) {
+		t.Fatalf("after ready: got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+type countingViews map[int64]int
+
+func (c countingViews) Add(id int64) { c[id]++ }
+
+func TestGetBook_CountsViews(t *testing.T) {
+	views := countingViews{}
+	svc := &mockBookService{GetBookFn: func(ctx context.Context, id int64) (*domain.Book, error) {
+		if id != 1 {
+			return nil, nil
+		}
+		return &domain.Book{ID: 1, Title: "Clean Code"}, nil
+	}}
+	ts := httptest.NewServer(NewHandler(svc, WithViewCounter(views)).Router())
+	defer ts.Close()
+
+	readBody(t, do(t, ts, http.MethodGet, "/books/1/", nil))
+	readBody(t, do(t, ts, http.MethodGet, "/books/2/", nil))
+	if views[1] != 1 || views[2] != 0 {
+		t.Fatalf("views = %v", views)
+	}
+}
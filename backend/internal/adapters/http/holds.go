@@ -0,0 +1,143 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) holdRoutes(r chi.Router) {
+	r.Post("/", h.PlaceHold)
+	r.Get("/me", h.ListMyHolds)
+	r.Get("/{id}", h.GetHold)
+	r.Delete("/{id}", h.CancelHold)
+}
+
+// POST /holds
+// --- PlaceHold ---
+// PlaceHold godoc
+// @Summary      Place a hold on a book
+// @Description  Queues the caller for a book that is out on loan; position is their place in the queue. When the book is returned it is lent to the oldest waiting hold. Editors and admins may send user_id to queue another user. 403 for callers without a user account and for user_id of someone else; 409 with code "book_available" if the book isn't out (check it out instead) and with code "hold_exists" if the user already waits for it.
+// @Tags         loans
+// @Accept       json
+// @Produce      json
+// @Param        body  body      ports.PlaceHoldInput  true  "Book, and user for editors"
+// @Success      201   {object}  domain.Hold
+// @Failure      400   {object}  ports.ErrorResponse
+// @Failure      403   {object}  ports.ErrorResponse
+// @Failure      409   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Failure      500   {object}  ports.ErrorResponse
+// @Router       /holds/ [post]
+func (h *Handler) PlaceHold(w http.ResponseWriter, r *http.Request) {
+	var in ports.PlaceHoldInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	hold, err := h.holds.PlaceHold(r.Context(), in)
+	if err != nil {
+		holdError(w, err)
+		return
+	}
+	jsonCreated(w, hold)
+}
+
+// GET /holds/{id}
+// --- GetHold ---
+// GetHold godoc
+// @Summary      Get a hold
+// @Description  position is the hold's current place in the queue while it waits. Users see their own holds, editors and admins everyone's.
+// @Tags         loans
+// @Produce      json
+// @Param        id   path      int  true  "Hold ID"  minimum(1)
+// @Success      200  {object}  domain.Hold
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /holds/{id} [get]
+func (h *Handler) GetHold(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	hold, err := h.holds.GetHold(r.Context(), id)
+	if err != nil {
+		holdError(w, err)
+		return
+	}
+	jsonOK(w, hold)
+}
+
+// DELETE /holds/{id}
+// --- CancelHold ---
+// CancelHold godoc
+// @Summary      Cancel a hold
+// @Description  Takes the hold out of its queue; the holds behind it move up. Users cancel their own holds, editors and admins anyone's. 409 if it was fulfilled or cancelled already.
+// @Tags         loans
+// @Produce      json
+// @Param        id   path      int  true  "Hold ID"  minimum(1)
+// @Success      200  {object}  domain.Hold
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      409  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /holds/{id} [delete]
+func (h *Handler) CancelHold(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	hold, err := h.holds.CancelHold(r.Context(), id)
+	if err != nil {
+		holdError(w, err)
+		return
+	}
+	jsonOK(w, hold)
+}
+
+// GET /holds/me
+// --- ListMyHolds ---
+// ListMyHolds godoc
+// @Summary      List the caller's holds
+// @Description  The holds the caller waits on, oldest first, each with its current position in its book's queue. 403 for callers without a user account.
+// @Tags         loans
+// @Produce      json
+// @Success      200  {array}   domain.Hold
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /holds/me [get]
+func (h *Handler) ListMyHolds(w http.ResponseWriter, r *http.Request) {
+	holds, err := h.holds.ListMyHolds(r.Context())
+	if err != nil {
+		holdError(w, err)
+		return
+	}
+	jsonOK(w, holds)
+}
+
+func holdError(w http.ResponseWriter, err error) {
+	var ve *appsvc.ValidationError
+	switch {
+	case errors.As(err, &ve):
+		httpValidation(w, ve)
+	case errors.Is(err, ports.ErrBookAvailable):
+		httpErrorCode(w, http.StatusConflict, "book_available", err.Error())
+	case errors.Is(err, ports.ErrHoldExists):
+		httpErrorCode(w, http.StatusConflict, "hold_exists", err.Error())
+	case errors.Is(err, ports.ErrHoldClosed):
+		httpError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, ports.ErrNoHoldAccount), errors.Is(err, ports.ErrNotHoldOwner):
+		httpError(w, http.StatusForbidden, err.Error())
+	case err.Error() == "hold not found":
+		httpError(w, http.StatusNotFound, err.Error())
+	default:
+		httpError(w, http.StatusInternalServerError, err.Error())
+	}
+}
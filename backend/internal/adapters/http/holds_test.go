@@ -0,0 +1,119 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/docs"
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type mockHoldService struct {
+	PlaceFn  func(ctx context.Context, in ports.PlaceHoldInput) (*domain.Hold, error)
+	GetFn    func(ctx context.Context, id int64) (*domain.Hold, error)
+	CancelFn func(ctx context.Context, id int64) (*domain.Hold, error)
+	ListMyFn func(ctx context.Context) ([]domain.Hold, error)
+}
+
+func (m *mockHoldService) PlaceHold(ctx context.Context, in ports.PlaceHoldInput) (*domain.Hold, error) {
+	return m.PlaceFn(ctx, in)
+}
+func (m *mockHoldService) GetHold(ctx context.Context, id int64) (*domain.Hold, error) {
+	return m.GetFn(ctx, id)
+}
+func (m *mockHoldService) CancelHold(ctx context.Context, id int64) (*domain.Hold, error) {
+	return m.CancelFn(ctx, id)
+}
+func (m *mockHoldService) ListMyHolds(ctx context.Context) ([]domain.Hold, error) {
+	return m.ListMyFn(ctx)
+}
+
+func TestHolds(t *testing.T) {
+	placed := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	hold := domain.Hold{ID: 21, BookID: 3, UserID: 8, Status: domain.HoldWaiting, PlacedAt: placed, Position: 2}
+	svc := &mockHoldService{
+		PlaceFn: func(ctx context.Context, in ports.PlaceHoldInput) (*domain.Hold, error) {
+			switch in.BookID {
+			case 3:
+				return &hold, nil
+			case 4:
+				return nil, ports.ErrBookAvailable
+			case 5:
+				return nil, ports.ErrHoldExists
+			case 7:
+				return nil, ports.ErrNotHoldOwner
+			}
+			return nil, &appsvc.ValidationError{Fields: map[string]string{"book_id": "No book has this id"}}
+		},
+		GetFn: func(ctx context.Context, id int64) (*domain.Hold, error) {
+			switch id {
+			case 21:
+				return &hold, nil
+			case 23:
+				return nil, ports.ErrNotHoldOwner
+			}
+			return nil, errors.New("hold not found")
+		},
+		ListMyFn: func(ctx context.Context) ([]domain.Hold, error) {
+			if _, ok := domain.ActorFrom(ctx); !ok {
+				return nil, ports.ErrNoHoldAccount
+			}
+			return []domain.Hold{hold}, nil
+		},
+		CancelFn: func(ctx context.Context, id int64) (*domain.Hold, error) {
+			if id == 21 {
+				cancelled := hold
+				cancelled.Status, cancelled.Position, cancelled.ClosedAt = domain.HoldCancelled, 0, &placed
+				return &cancelled, nil
+			}
+			return nil, ports.ErrHoldClosed
+		},
+	}
+	v, err := NewSpecValidator([]byte(docs.SwaggerInfo.ReadDoc()))
+	if err != nil {
+		t.Fatalf("NewSpecValidator: %v", err)
+	}
+	// no roles needed: users manage their own holds
+	ts := httptest.NewServer(Identify(true)(v.Middleware(NewHandler(&mockBookService{}, WithHolds(svc), WithRoles()).Router())))
+	defer ts.Close()
+
+	for _, tc := range []struct {
+		method, path string
+		body         any
+		code         int
+		want         string
+	}{
+		{http.MethodPost, "/holds/", map[string]any{"book_id": 3}, http.StatusCreated, `"position":2`},
+		{http.MethodPost, "/holds/", map[string]any{"book_id": 7, "user_id": 9}, http.StatusForbidden, "hold belongs to another user"},
+		{http.MethodPost, "/holds/", map[string]any{"book_id": 4, "user_id": 8}, http.StatusConflict, `"code":"book_available"`},
+		{http.MethodPost, "/holds/", map[string]any{"book_id": 5, "user_id": 8}, http.StatusConflict, `"code":"hold_exists"`},
+		{http.MethodPost, "/holds/", map[string]any{"book_id": 6, "user_id": 8}, http.StatusUnprocessableEntity, "book_id"},
+		{http.MethodGet, "/holds/21", nil, http.StatusOK, `"status":"waiting"`},
+		{http.MethodGet, "/holds/22", nil, http.StatusNotFound, "hold not found"},
+		{http.MethodGet, "/holds/23", nil, http.StatusForbidden, "hold belongs to another user"},
+		{http.MethodGet, "/holds/me", nil, http.StatusForbidden, "sign in"},
+		{http.MethodDelete, "/holds/21", nil, http.StatusOK, `"status":"cancelled"`},
+		{http.MethodDelete, "/holds/22", nil, http.StatusConflict, "hold is no longer waiting"},
+	} {
+		res := do(t, ts, tc.method, tc.path, tc.body)
+		if body := readBody(t, res); res.StatusCode != tc.code || !contains(body, tc.want) {
+			t.Errorf("%s %s = %d %s; want %d with %s", tc.method, tc.path, res.StatusCode, body, tc.code, tc.want)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/holds/me", nil)
+	req.Header.Set("X-User", "ann@example.com")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("do request: %v", err)
+	}
+	if body := readBody(t, res); res.StatusCode != http.StatusOK || !contains(body, `"id":21`) || !contains(body, `"position":2`) {
+		t.Fatalf("GET /holds/me = %d %s", res.StatusCode, body)
+	}
+}
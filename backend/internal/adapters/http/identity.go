@@ -0,0 +1,87 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// Identity headers. X-User and X-User-Scopes (comma separated) are set by
+// the authenticating proxy in front of the API; X-Impersonate-User is sent
+// by an admin client to act as another user.
+const (
+	headerUser        = "X-User"
+	headerUserScopes  = "X-User-Scopes"
+	headerImpersonate = "X-Impersonate-User"
+)
+
+// Identify puts the caller's domain.Actor on the request context. The proxy
+// headers are only believed when trustHeaders is set, which must only be done
+// behind a proxy that authenticates callers and strips these headers from
+// client input.
+func Identify(trustHeaders bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var actor domain.Actor
+			if trustHeaders {
+				actor.ID = strings.TrimSpace(r.Header.Get(headerUser))
+				for _, s := range strings.Split(r.Header.Get(headerUserScopes), ",") {
+					if s = strings.TrimSpace(s); s != "" {
+						actor.Scopes = append(actor.Scopes, s)
+					}
+				}
+			}
+			if actor.ID != "" {
+				r = r.WithContext(domain.WithActor(r.Context(), actor))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Impersonate lets an admin send X-Impersonate-User to run the request as
+// that user, looked up with users; the actor then carries both identities
+// and every change it makes is stamped with both. Anyone else sending it gets
+// 403, and naming a user without an account (or any user, when users is nil
+// because accounts are off) gets 404. It goes after every layer that
+// identifies the caller (Identify, BearerAuth, APIKeyAuth), so an admin is
+// recognised however they signed in.
+func Impersonate(users ports.AuthService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			target := strings.TrimSpace(r.Header.Get(headerImpersonate))
+			if target == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			actor, ok := domain.ActorFrom(r.Context())
+			if !ok || actor.ID == "" || !actor.HasScope(domain.ScopeAdmin) {
+				httpError(w, http.StatusForbidden, "impersonation requires the admin scope")
+				return
+			}
+			var user *domain.Actor
+			if users != nil {
+				u, err := users.Actor(r.Context(), target)
+				if err != nil {
+					logger.From(r.Context()).Error("failed to look up impersonated user", "user", target, "error", err)
+					httpError(w, http.StatusInternalServerError, err.Error())
+					return
+				}
+				user = u
+			}
+			if user == nil {
+				httpError(w, http.StatusNotFound, "impersonated user not found")
+				return
+			}
+			logger.From(r.Context()).Info("admin acting as user",
+				"admin", actor.ID, "user", user.ID, "method", r.Method, "path", r.URL.Path)
+			// the admin's scopes stay behind: the request sees what the user sees
+			user.ImpersonatedBy = actor.ID
+			r = r.WithContext(domain.WithActor(r.Context(), *user))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// accounts knows ann, an editor.
+var accounts = &mockAuthService{ActorFn: func(ctx context.Context, email string) (*domain.Actor, error) {
+	if email == "ann@example.com" {
+		return &domain.Actor{ID: email, Scopes: []string{domain.ScopeEditor}}, nil
+	}
+	return nil, nil
+}}
+
+func TestIdentify(t *testing.T) {
+	cases := []struct {
+		name    string
+		trust   bool
+		headers map[string]string
+		status  int
+		want    domain.Actor
+	}{
+		{"anonymous", true, nil, http.StatusOK, domain.Actor{}},
+		{"headers ignored unless trusted", false, map[string]string{"X-User": "ann"}, http.StatusOK, domain.Actor{}},
+		{"proxy identity", true, map[string]string{"X-User": "ann", "X-User-Scopes": "read, write"}, http.StatusOK,
+			domain.Actor{ID: "ann", Scopes: []string{"read", "write"}}},
+		{"admin impersonates", true, map[string]string{"X-User": "root", "X-User-Scopes": "admin", "X-Impersonate-User": "ann@example.com"},
+			http.StatusOK, domain.Actor{ID: "ann@example.com", Scopes: []string{domain.ScopeEditor}, ImpersonatedBy: "root"}},
+		{"unknown user refused", true, map[string]string{"X-User": "root", "X-User-Scopes": "admin", "X-Impersonate-User": "nobody@example.com"},
+			http.StatusNotFound, domain.Actor{}},
+		{"non-admin refused", true, map[string]string{"X-User": "bob", "X-User-Scopes": "write", "X-Impersonate-User": "ann@example.com"},
+			http.StatusForbidden, domain.Actor{}},
+		{"untrusted admin refused", false, map[string]string{"X-User": "root", "X-User-Scopes": "admin", "X-Impersonate-User": "ann@example.com"},
+			http.StatusForbidden, domain.Actor{}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var got domain.Actor
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = domain.ActorFrom(r.Context())
+			})
+			req := httptest.NewRequest(http.MethodGet, "/books/", nil)
+			for k, v := range c.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			Identify(c.trust)(Impersonate(accounts)(next)).ServeHTTP(rec, req)
+			if rec.Code != c.status {
+				t.Fatalf("status = %d, want %d", rec.Code, c.status)
+			}
+			if got.ID != c.want.ID || got.ImpersonatedBy != c.want.ImpersonatedBy || len(got.Scopes) != len(c.want.Scopes) {
+				t.Fatalf("actor = %+v, want %+v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestImpersonate_BearerAdmin(t *testing.T) {
+	auth := &mockAuthService{ActorFn: accounts.ActorFn, VerifyFn: func(ctx context.Context, token string) (*domain.Actor, error) {
+		switch token {
+		case "admin":
+			return &domain.Actor{ID: "root@example.com", Scopes: []string{domain.ScopeAdmin}}, nil
+		case "user":
+			return &domain.Actor{ID: "bob@example.com"}, nil
+		}
+		return nil, ports.ErrInvalidToken
+	}}
+	cases := []struct {
+		token  string
+		status int
+		want   domain.Actor
+	}{
+		{"admin", http.StatusOK, domain.Actor{ID: "ann@example.com", Scopes: []string{domain.ScopeEditor}, ImpersonatedBy: "root@example.com"}},
+		{"user", http.StatusForbidden, domain.Actor{}},
+	}
+	for _, c := range cases {
+		t.Run(c.token, func(t *testing.T) {
+			var got domain.Actor
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = domain.ActorFrom(r.Context())
+			})
+			req := httptest.NewRequest(http.MethodPost, "/books/", nil)
+			req.Header.Set("Authorization", "Bearer "+c.token)
+			req.Header.Set("X-Impersonate-User", "ann@example.com")
+			rec := httptest.NewRecorder()
+			Identify(false)(BearerAuth(auth)(Impersonate(auth)(next))).ServeHTTP(rec, req)
+			if rec.Code != c.status {
+				t.Fatalf("status = %d, want %d", rec.Code, c.status)
+			}
+			if got.ID != c.want.ID || got.ImpersonatedBy != c.want.ImpersonatedBy || len(got.Scopes) != len(c.want.Scopes) {
+				t.Fatalf("actor = %+v, want %+v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestImpersonate_WithoutAccounts(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/books/", nil)
+	req.Header.Set("X-User", "root")
+	req.Header.Set("X-User-Scopes", "admin")
+	req.Header.Set("X-Impersonate-User", "ann@example.com")
+	rec := httptest.NewRecorder()
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { t.Fatalf("request ran without accounts") })
+	Identify(true)(Impersonate(nil)(next)).ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound || !contains(rec.Body.String(), "impersonated user not found") {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+}
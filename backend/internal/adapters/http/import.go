@@ -0,0 +1,85 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/export"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// maxImportBytes caps the uploaded file; MaxImportRows caps what's in it.
+const maxImportBytes = 10 << 20
+
+// POST /books/import
+// --- ImportBooks ---
+// ImportBooks godoc
+// @Summary      Import books from CSV or JSON
+// @Description  Upload a file in the multipart field `file`: a CSV with a header row naming at least title, author, isbn, price and publication_year (the export's format, comma or semicolon separated; other columns are ignored), or a JSON array of books. The format follows the file extension, then the part's content type. Every row is validated like POST /books; rows with an ISBN that already exists or appears earlier in the file are skipped. The response lists the outcome of every row. If the server shuts down mid-import, the rows not yet reached are `requeued` as a `book_import` dead letter; new imports are refused with 503 while it drains.
+// @Tags         books
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file  formData  file  true  "CSV or JSON file"
+// @Success      200   {object}  ports.ImportResult
+// @Failure      400   {object}  ports.ErrorResponse
+// @Failure      403   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Failure      500   {object}  ports.ErrorResponse
+// @Failure      503   {object}  ports.ErrorResponse
+// @Router       /books/import [post]
+func (h *Handler) ImportBooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if h.jobs != nil {
+		jobCtx, done, ok := h.jobs.Track(ctx)
+		if !ok {
+			w.Header().Set("Retry-After", "30")
+			httpError(w, http.StatusServiceUnavailable, ports.ErrDraining.Error())
+			return
+		}
+		defer done()
+		ctx = jobCtx
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var tooBig *http.MaxBytesError
+		if errors.As(err, &tooBig) {
+			httpError(w, http.StatusBadRequest, fmt.Sprintf("file must be at most %d MB", maxImportBytes>>20))
+			return
+		}
+		httpError(w, http.StatusBadRequest, `multipart field "file" is required`)
+		return
+	}
+	defer file.Close()
+
+	var rows []ports.ImportRow
+	switch export.BookFormat(header.Filename, header.Header.Get("Content-Type")) {
+	case "json":
+		rows, err = export.ReadBooksJSON(file)
+	case "csv":
+		rows, err = export.ReadBooksCSV(file)
+	default:
+		err = errors.New("file must be .csv or .json")
+	}
+	if err == nil && len(rows) == 0 {
+		err = errors.New("file has no rows")
+	}
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := h.svc.ImportBooks(ctx, rows)
+	if err != nil {
+		var ve *appsvc.ValidationError
+		if errors.As(err, &ve) {
+			httpValidation(w, ve)
+			return
+		}
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, res)
+}
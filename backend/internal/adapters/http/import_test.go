@@ -0,0 +1,168 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+func postImport(t *testing.T, ts *httptest.Server, filename, content string) *http.Response {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	fw, err := mw.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatalf("create form file: %v", err)
+	}
+	_, _ = fw.Write([]byte(content))
+	_ = mw.Close()
+	res, err := http.Post(ts.URL+"/books/import", mw.FormDataContentType(), &buf)
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	return res
+}
+
+func TestImportBooks_CSV(t *testing.T) {
+	var got []ports.ImportRow
+	mock := &mockBookService{
+		ImportFn: func(ctx context.Context, rows []ports.ImportRow) (*ports.ImportResult, error) {
+			got = rows
+			return &ports.ImportResult{Inserted: 1, Failed: 1, Rows: []ports.ImportRowResult{
+				{Row: 1, Status: ports.ImportInserted, ID: 9}, {Row: 2, Status: ports.ImportFailed, Error: "validation error"},
+			}}, nil
+		},
+	}
+	ts := newTestServer(t, mock)
+	defer ts.Close()
+
+	// the export's European layout: BOM, semicolons, decimal commas
+	csv := "\ufeffid;title;author;isbn;price;publication_year\r\n" +
+		"1;Cien años de soledad;Gabriel García Márquez;978-0-06-088328-7;12,5;1967\r\n" +
+		"2;Bad;Someone;9780132350884;cheap;19x7\r\n"
+	res := postImport(t, ts, "books.csv", csv)
+	if body := readBody(t, res); res.StatusCode != http.StatusOK || !contains(body, `"inserted":1`) {
+		t.Fatalf("status = %d, body = %s", res.StatusCode, body)
+	}
+	if len(got) != 2 {
+		t.Fatalf("rows = %+v", got)
+	}
+	first := ports.CreateBookInput{Title: "Cien años de soledad", Author: "Gabriel García Márquez", ISBN: "978-0-06-088328-7", Price: 12.5, PublicationYear: 1967}
+	if got[0].Row != 1 || got[0].Book != first || got[0].Errors != nil {
+		t.Fatalf("row 1 = %+v", got[0])
+	}
+	if got[1].Row != 2 || got[1].Errors["price"] == "" || got[1].Errors["publication_year"] == "" {
+		t.Fatalf("row 2 = %+v", got[1])
+	}
+}
+
+func TestImportBooks_JSON(t *testing.T) {
+	var got []ports.ImportRow
+	mock := &mockBookService{
+		ImportFn: func(ctx context.Context, rows []ports.ImportRow) (*ports.ImportResult, error) {
+			got = rows
+			return &ports.ImportResult{Rows: []ports.ImportRowResult{}}, nil
+		},
+	}
+	ts := newTestServer(t, mock)
+	defer ts.Close()
+
+	res := postImport(t, ts, "books.json", `[{"title":"A","isbn":"9780132350884","price":1.5},{"title":"B","price":"x"}]`)
+	readBody(t, res)
+	if res.StatusCode != http.StatusOK || len(got) != 2 {
+		t.Fatalf("status = %d, rows = %+v", res.StatusCode, got)
+	}
+	if got[0].Book.Price != 1.5 || got[0].Errors != nil || got[1].Row != 2 || got[1].Errors["price"] == "" {
+		t.Fatalf("rows = %+v", got)
+	}
+}
+
+func TestImportBooks_BadFiles(t *testing.T) {
+	ts := newTestServer(t, &mockBookService{})
+	defer ts.Close()
+
+	cases := []struct{ name, filename, content, want string }{
+		{"unknown format", "books.txt", "x", ".csv or .json"},
+		{"missing column", "books.csv", "title,author\nA,B\n", "CSV header must include"},
+		{"header only", "books.csv", "title,author,isbn,price,publication_year\n", "no rows"},
+		{"json object", "books.json", `{"title":"A"}`, "array of books"},
+	}
+	for _, c := range cases {
+		res := postImport(t, ts, c.filename, c.content)
+		if body := readBody(t, res); res.StatusCode != http.StatusBadRequest || !contains(body, c.want) {
+			t.Fatalf("%s: status = %d, body = %s", c.name, res.StatusCode, body)
+		}
+	}
+
+	res := do(t, ts, http.MethodPost, "/books/import", map[string]any{"title": "A"})
+	if body := readBody(t, res); res.StatusCode != http.StatusBadRequest || !contains(body, `field \"file\"`) {
+		t.Fatalf("no file: status = %d, body = %s", res.StatusCode, body)
+	}
+}
+
+func TestImportBooks_MatchesSpec(t *testing.T) {
+	mock := &mockBookService{
+		ImportFn: func(ctx context.Context, rows []ports.ImportRow) (*ports.ImportResult, error) {
+			return &ports.ImportResult{Inserted: 1, Failed: 1, Rows: []ports.ImportRowResult{
+				{Row: 1, Status: ports.ImportInserted, ID: 9, ISBN: "9780132350884"},
+				{Row: 2, Status: ports.ImportFailed, Error: "validation error", Fields: map[string]string{"isbn": "ISBN is required"}},
+			}}, nil
+		},
+	}
+	ts := newSpecServer(t, mock)
+	defer ts.Close()
+
+	res := postImport(t, ts, "books.csv", "title,author,isbn,price,publication_year\nA,B,9780132350884,1,2008\nC,D,,1,2008\n")
+	if body := readBody(t, res); res.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", res.StatusCode, body)
+	}
+}
+
+type fakeJobs struct {
+	draining bool
+	started  int
+	ended    int
+}
+
+type jobKey struct{}
+
+func (f *fakeJobs) Track(ctx context.Context) (context.Context, func(), bool) {
+	if f.draining {
+		return ctx, func() {}, false
+	}
+	f.started++
+	return context.WithValue(ctx, jobKey{}, true), func() { f.ended++ }, true
+}
+
+func TestImportBooks_TrackedForDraining(t *testing.T) {
+	jobs := &fakeJobs{}
+	tracked := false
+	mock := &mockBookService{
+		ImportFn: func(ctx context.Context, rows []ports.ImportRow) (*ports.ImportResult, error) {
+			tracked = ctx.Value(jobKey{}) == true
+			return &ports.ImportResult{Requeued: 1, Rows: []ports.ImportRowResult{{Row: 1, Status: ports.ImportRequeued}}}, nil
+		},
+	}
+	ts := httptest.NewServer(NewHandler(mock, WithJobs(jobs)).Router())
+	defer ts.Close()
+
+	csv := "title,author,isbn,price,publication_year\nA,B,9780132350884,1,2008\n"
+	res := postImport(t, ts, "books.csv", csv)
+	if body := readBody(t, res); res.StatusCode != http.StatusOK || !contains(body, `"requeued":1`) {
+		t.Fatalf("status = %d, body = %s", res.StatusCode, body)
+	}
+	if !tracked || jobs.started != 1 || jobs.ended != 1 {
+		t.Fatalf("tracked = %v, jobs = %+v", tracked, jobs)
+	}
+
+	jobs.draining = true
+	res = postImport(t, ts, "books.csv", csv)
+	if body := readBody(t, res); res.StatusCode != http.StatusServiceUnavailable || res.Header.Get("Retry-After") == "" {
+		t.Fatalf("while draining: status = %d, body = %s", res.StatusCode, body)
+	}
+}
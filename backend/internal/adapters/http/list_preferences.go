@@ -0,0 +1,132 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) listPreferenceRoutes(r chi.Router) {
+	r.Get("/", h.GetListPreferences)
+	r.Put("/", h.PutListPreferences)
+	r.Delete("/", h.DeleteListPreferences)
+}
+
+// GET /me/list-preferences
+// --- GetListPreferences ---
+// GetListPreferences godoc
+// @Summary      Get your book list defaults
+// @Description  The sort and page size GET /books/ uses when your requests don't set them. Preferences belong to the caller: the API key (X-API-Key) or signed-in user.
+// @Tags         books
+// @Produce      json
+// @Success      200  {object}  domain.ListPreferences
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /me/list-preferences/ [get]
+func (h *Handler) GetListPreferences(w http.ResponseWriter, r *http.Request) {
+	p, err := h.listPrefs.GetListPreferences(r.Context())
+	if err != nil {
+		listPreferencesError(w, err)
+		return
+	}
+	jsonOK(w, p)
+}
+
+// PUT /me/list-preferences
+// --- PutListPreferences ---
+// PutListPreferences godoc
+// @Summary      Replace your book list defaults
+// @Description  `sort` and `collation` take the values of the list parameters and apply together when a request sends no `sort`; `page_size`, up to the maximum page size, applies when it sends no `limit`. Empty fields and a page size of 0 keep the server defaults. Lists use the new defaults right away on this server, and within a minute on the others.
+// @Tags         books
+// @Accept       json
+// @Produce      json
+// @Param        body  body      ports.PutListPreferencesInput  true  "Preferences"
+// @Success      200   {object}  domain.ListPreferences
+// @Failure      400   {object}  ports.ErrorResponse
+// @Failure      403   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Failure      500   {object}  ports.ErrorResponse
+// @Router       /me/list-preferences/ [put]
+func (h *Handler) PutListPreferences(w http.ResponseWriter, r *http.Request) {
+	var in ports.PutListPreferencesInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	p, err := h.listPrefs.PutListPreferences(r.Context(), in)
+	if err != nil {
+		listPreferencesError(w, err)
+		return
+	}
+	jsonOK(w, p)
+}
+
+// DELETE /me/list-preferences
+// --- DeleteListPreferences ---
+// DeleteListPreferences godoc
+// @Summary      Delete your book list defaults
+// @Description  Lists go back to the server's order and page size.
+// @Tags         books
+// @Success      204  "No Content"
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /me/list-preferences/ [delete]
+func (h *Handler) DeleteListPreferences(w http.ResponseWriter, r *http.Request) {
+	if err := h.listPrefs.DeleteListPreferences(r.Context()); err != nil {
+		listPreferencesError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func listPreferencesError(w http.ResponseWriter, err error) {
+	if ve, ok := err.(*appsvc.ValidationError); ok {
+		httpValidation(w, ve)
+		return
+	}
+	switch {
+	case errors.Is(err, ports.ErrNoPreferencesTenant):
+		httpError(w, http.StatusForbidden, err.Error())
+	case err.Error() == "list preferences not found":
+		httpError(w, http.StatusNotFound, err.Error())
+	default:
+		httpError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// applyListPreferences fills in the sort and limit page leaves out from the
+// caller's preferences. A preferred page size above the maximum is cut to
+// it, so lowering MAX_PAGE_SIZE doesn't break callers that saved a larger
+// one. Lists fall back to the server defaults when the preferences can't be
+// read.
+func (h *Handler) applyListPreferences(ctx context.Context, page *ports.Page) {
+	if h.listPrefs == nil {
+		return
+	}
+	p, err := h.listPrefs.For(ctx)
+	if err != nil {
+		logger.From(ctx).Warn("failed to read list preferences", "error", err)
+		return
+	}
+	if p == nil {
+		return
+	}
+	if page.Sort.Field == "" && page.Sort.Collation == "" && p.Sort != "" {
+		page.Sort = ports.Sort{Field: strings.TrimPrefix(p.Sort, "-"), Desc: strings.HasPrefix(p.Sort, "-"), Collation: p.Collation}
+	}
+	if page.Limit == 0 && p.PageSize > 0 {
+		page.Limit = p.PageSize
+		if h.pageLimits.Max > 0 {
+			page.Limit = min(page.Limit, h.pageLimits.Max)
+		}
+	}
+}
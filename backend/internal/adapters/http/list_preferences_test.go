@@ -0,0 +1,96 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/docs"
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type mapListPreferencesRepo map[string]domain.ListPreferences
+
+func (m mapListPreferencesRepo) Get(ctx context.Context, tenant string) (*domain.ListPreferences, error) {
+	if p, ok := m[tenant]; ok {
+		return &p, nil
+	}
+	return nil, nil
+}
+func (m mapListPreferencesRepo) Put(ctx context.Context, p *domain.ListPreferences) error {
+	m[p.Tenant] = *p
+	return nil
+}
+func (m mapListPreferencesRepo) Delete(ctx context.Context, tenant string) (bool, error) {
+	_, ok := m[tenant]
+	delete(m, tenant)
+	return ok, nil
+}
+
+func TestListPreferences(t *testing.T) {
+	limits := ports.PageLimits{Default: 50, Max: 200}
+	var got ports.Page
+	mock := &mockBookService{
+		ListPageFn: func(ctx context.Context, f ports.ListFilter, page ports.Page) (*ports.BookPage, error) {
+			got = page
+			return &ports.BookPage{Books: []domain.Book{}}, nil
+		},
+	}
+	v, err := NewSpecValidator([]byte(docs.SwaggerInfo.ReadDoc()))
+	if err != nil {
+		t.Fatalf("NewSpecValidator: %v", err)
+	}
+	prefs := appsvc.NewListPreferences(mapListPreferencesRepo{}, limits, time.Minute)
+	h := NewHandler(mock, WithListPreferences(prefs), WithPageLimits(limits))
+	ts := httptest.NewServer(Identify(true)(v.Middleware(h.Router())))
+	defer ts.Close()
+
+	call := func(method, path, user string, body any) (int, string) {
+		t.Helper()
+		res := doAs(t, ts, method, path, user, body)
+		return res.StatusCode, readBody(t, res)
+	}
+
+	if code, _ := call(http.MethodPut, "/me/list-preferences/", "", map[string]any{"page_size": 24}); code != http.StatusForbidden {
+		t.Fatalf("anonymous PUT = %d; want 403", code)
+	}
+	if code, body := call(http.MethodPut, "/me/list-preferences/", "storefront", map[string]any{"page_size": 500}); code != http.StatusUnprocessableEntity || !contains(body, "page_size") {
+		t.Fatalf("oversized PUT = %d %s", code, body)
+	}
+	if code, body := call(http.MethodPut, "/me/list-preferences/", "storefront", map[string]any{"sort": "-publication_year", "page_size": 24}); code != http.StatusOK || !contains(body, `"tenant":"storefront"`) {
+		t.Fatalf("PUT = %d %s", code, body)
+	}
+	if code, body := call(http.MethodGet, "/me/list-preferences/", "storefront", nil); code != http.StatusOK || !contains(body, `"page_size":24`) {
+		t.Fatalf("GET = %d %s", code, body)
+	}
+
+	for _, tc := range []struct {
+		path, user string
+		want       ports.Page
+	}{
+		{"/books/", "storefront", ports.Page{Limit: 24, Sort: ports.Sort{Field: "publication_year", Desc: true}}},
+		{"/books/?sort=title&limit=5", "storefront", ports.Page{Limit: 5, Sort: ports.Sort{Field: "title"}}},
+		{"/books/?author=Herbert", "storefront", ports.Page{Limit: 24, Sort: ports.Sort{Field: "publication_year", Desc: true}}},
+		{"/books/", "admin-ui", ports.Page{Limit: 50}},
+		{"/books/", "", ports.Page{Limit: 50}},
+	} {
+		got = ports.Page{}
+		if code, body := call(http.MethodGet, tc.path, tc.user, nil); code != http.StatusOK {
+			t.Fatalf("%s as %q = %d %s", tc.path, tc.user, code, body)
+		}
+		if got != tc.want {
+			t.Errorf("%s as %q: page = %+v; want %+v", tc.path, tc.user, got, tc.want)
+		}
+	}
+
+	if code, _ := call(http.MethodDelete, "/me/list-preferences/", "storefront", nil); code != http.StatusNoContent {
+		t.Fatalf("DELETE = %d", code)
+	}
+	if code, _ := call(http.MethodGet, "/me/list-preferences/", "storefront", nil); code != http.StatusNotFound {
+		t.Fatalf("GET after DELETE = %d; want 404", code)
+	}
+}
@@ -0,0 +1,152 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) loanRoutes(r chi.Router) {
+	r.Use(h.requireEditor)
+	r.Post("/", h.CheckoutBook)
+	r.Get("/overdue", h.ListOverdueLoans)
+	r.Get("/{id}", h.GetLoan)
+	r.Post("/{id}/return", h.ReturnLoan)
+}
+
+// POST /loans
+// --- CheckoutBook ---
+// CheckoutBook godoc
+// @Summary      Check out a book
+// @Description  Lends the book to the user until `due_at`, by default the loan period from now. A book is out on one loan at a time: 409 with code "book_on_loan" while it is out. Books on loan can't be deleted.
+// @Tags         loans
+// @Accept       json
+// @Produce      json
+// @Param        body  body      ports.CheckoutInput  true  "Book, user and due date"
+// @Success      201   {object}  domain.Loan
+// @Failure      400   {object}  ports.ErrorResponse
+// @Failure      403   {object}  ports.ErrorResponse
+// @Failure      409   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Failure      500   {object}  ports.ErrorResponse
+// @Router       /loans/ [post]
+func (h *Handler) CheckoutBook(w http.ResponseWriter, r *http.Request) {
+	var in ports.CheckoutInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	l, err := h.loans.CheckoutBook(r.Context(), in)
+	if err != nil {
+		loanError(w, err)
+		return
+	}
+	jsonCreated(w, l)
+}
+
+// GET /loans/overdue
+// --- ListOverdueLoans ---
+// ListOverdueLoans godoc
+// @Summary      List overdue loans
+// @Description  Loans still out after their due date, the longest overdue first.
+// @Tags         loans
+// @Produce      json
+// @Param        limit  query     int  false  "Loans to return, default 100"  minimum(1)  maximum(1000)
+// @Success      200    {array}   domain.Loan
+// @Failure      400    {object}  ports.ErrorResponse
+// @Failure      403    {object}  ports.ErrorResponse
+// @Failure      422    {object}  validationPayload
+// @Failure      500    {object}  ports.ErrorResponse
+// @Router       /loans/overdue [get]
+func (h *Handler) ListOverdueLoans(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			httpError(w, http.StatusBadRequest, "limit must be an integer")
+			return
+		}
+		limit = n
+	}
+	loans, err := h.loans.ListOverdueLoans(r.Context(), limit)
+	if err != nil {
+		loanError(w, err)
+		return
+	}
+	jsonOK(w, loans)
+}
+
+// GET /loans/{id}
+// --- GetLoan ---
+// GetLoan godoc
+// @Summary      Get a loan
+// @Tags         loans
+// @Produce      json
+// @Param        id   path      int  true  "Loan ID"  minimum(1)
+// @Success      200  {object}  domain.Loan
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /loans/{id} [get]
+func (h *Handler) GetLoan(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	l, err := h.loans.GetLoan(r.Context(), id)
+	if err != nil {
+		loanError(w, err)
+		return
+	}
+	jsonOK(w, l)
+}
+
+// POST /loans/{id}/return
+// --- ReturnLoan ---
+// ReturnLoan godoc
+// @Summary      Return a book
+// @Description  Closes the loan. If holds wait for the book, it is lent at once to the user of the oldest one for the loan period, and next_loan is that loan; otherwise the book can be lent again. 409 if the loan was returned already.
+// @Tags         loans
+// @Produce      json
+// @Param        id   path      int  true  "Loan ID"  minimum(1)
+// @Success      200  {object}  ports.ReturnedLoan
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      409  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /loans/{id}/return [post]
+func (h *Handler) ReturnLoan(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	l, err := h.loans.ReturnLoan(r.Context(), id)
+	if err != nil {
+		loanError(w, err)
+		return
+	}
+	jsonOK(w, l)
+}
+
+func loanError(w http.ResponseWriter, err error) {
+	var ve *appsvc.ValidationError
+	switch {
+	case errors.As(err, &ve):
+		httpValidation(w, ve)
+	case errors.Is(err, ports.ErrBookOnLoan):
+		httpErrorCode(w, http.StatusConflict, "book_on_loan", err.Error())
+	case errors.Is(err, ports.ErrLoanReturned):
+		httpError(w, http.StatusConflict, err.Error())
+	case err.Error() == "loan not found":
+		httpError(w, http.StatusNotFound, err.Error())
+	default:
+		httpError(w, http.StatusInternalServerError, err.Error())
+	}
+}
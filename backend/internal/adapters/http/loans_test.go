@@ -0,0 +1,95 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type mockLoanService struct {
+	CheckoutFn func(ctx context.Context, in ports.CheckoutInput) (*domain.Loan, error)
+	GetFn      func(ctx context.Context, id int64) (*domain.Loan, error)
+	ReturnFn   func(ctx context.Context, id int64) (*ports.ReturnedLoan, error)
+	OverdueFn  func(ctx context.Context, limit int) ([]domain.Loan, error)
+}
+
+func (m *mockLoanService) CheckoutBook(ctx context.Context, in ports.CheckoutInput) (*domain.Loan, error) {
+	return m.CheckoutFn(ctx, in)
+}
+func (m *mockLoanService) GetLoan(ctx context.Context, id int64) (*domain.Loan, error) {
+	return m.GetFn(ctx, id)
+}
+func (m *mockLoanService) ReturnLoan(ctx context.Context, id int64) (*ports.ReturnedLoan, error) {
+	return m.ReturnFn(ctx, id)
+}
+func (m *mockLoanService) ListOverdueLoans(ctx context.Context, limit int) ([]domain.Loan, error) {
+	return m.OverdueFn(ctx, limit)
+}
+
+func TestLoans(t *testing.T) {
+	due := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	loan := domain.Loan{ID: 11, BookID: 3, UserID: 7, CheckedOutAt: due.AddDate(0, 0, -14), DueAt: due}
+	var gotLimit int
+	svc := &mockLoanService{
+		CheckoutFn: func(ctx context.Context, in ports.CheckoutInput) (*domain.Loan, error) {
+			switch in.BookID {
+			case 3:
+				return &loan, nil
+			case 4:
+				return nil, ports.ErrBookOnLoan
+			}
+			return nil, &appsvc.ValidationError{Fields: map[string]string{"book_id": "No book has this id"}}
+		},
+		GetFn: func(ctx context.Context, id int64) (*domain.Loan, error) {
+			if id != 11 {
+				return nil, errors.New("loan not found")
+			}
+			return &loan, nil
+		},
+		ReturnFn: func(ctx context.Context, id int64) (*ports.ReturnedLoan, error) {
+			if id == 12 {
+				returned := due.AddDate(0, 0, -1)
+				next := domain.Loan{ID: 13, BookID: 3, UserID: 8, CheckedOutAt: returned, DueAt: returned.AddDate(0, 0, 14)}
+				return &ports.ReturnedLoan{Loan: domain.Loan{ID: 12, BookID: 3, UserID: 7, ReturnedAt: &returned}, NextLoan: &next}, nil
+			}
+			return nil, ports.ErrLoanReturned
+		},
+		OverdueFn: func(ctx context.Context, limit int) ([]domain.Loan, error) {
+			gotLimit = limit
+			return []domain.Loan{loan}, nil
+		},
+	}
+	ts := newSpecServer(t, &mockBookService{}, WithLoans(svc))
+	defer ts.Close()
+
+	for _, tc := range []struct {
+		method, path string
+		body         any
+		code         int
+		want         string
+	}{
+		{http.MethodPost, "/loans/", map[string]any{"book_id": 3, "user_id": 7}, http.StatusCreated, `"due_at":"2026-03-15T12:00:00Z"`},
+		{http.MethodPost, "/loans/", map[string]any{"book_id": 4, "user_id": 7}, http.StatusConflict, `"code":"book_on_loan"`},
+		{http.MethodPost, "/loans/", map[string]any{"book_id": 5, "user_id": 7}, http.StatusUnprocessableEntity, "book_id"},
+		{http.MethodGet, "/loans/11", nil, http.StatusOK, `"user_id":7`},
+		{http.MethodGet, "/loans/12", nil, http.StatusNotFound, "loan not found"},
+		{http.MethodPost, "/loans/11/return", nil, http.StatusConflict, "loan already returned"},
+		{http.MethodPost, "/loans/12/return", nil, http.StatusOK, `"next_loan":{"id":13,"book_id":3,"user_id":8`},
+		{http.MethodGet, "/loans/overdue?limit=5", nil, http.StatusOK, `"id":11`},
+		{http.MethodGet, "/loans/overdue?limit=x", nil, http.StatusBadRequest, "limit"},
+	} {
+		res := do(t, ts, tc.method, tc.path, tc.body)
+		if body := readBody(t, res); res.StatusCode != tc.code || !contains(body, tc.want) {
+			t.Errorf("%s %s = %d %s; want %d with %s", tc.method, tc.path, res.StatusCode, body, tc.code, tc.want)
+		}
+	}
+	if gotLimit != 5 {
+		t.Fatalf("overdue limit = %d; want 5", gotLimit)
+	}
+}
@@ -0,0 +1,43 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// POST /books/lookup
+// --- LookupBook ---
+// LookupBook godoc
+// @Summary      Look up book metadata by ISBN
+// @Description  Asks the external catalogues (OpenLibrary, then Google Books) for the title, author and publication year of an ISBN-10 or ISBN-13, to pre-fill a new book. Nothing is stored; POST /books with `autofill: true` does the same while creating. Each catalogue gets METADATA_TIMEOUT, and one that fails is skipped; 503 means none could be reached.
+// @Tags         books
+// @Produce      json
+// @Param        isbn  query     string  true  "ISBN-10 or ISBN-13"
+// @Success      200   {object}  domain.BookMetadata
+// @Failure      403   {object}  ports.ErrorResponse
+// @Failure      404   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Failure      503   {object}  ports.ErrorResponse
+// @Router       /books/lookup [post]
+func (h *Handler) LookupBook(w http.ResponseWriter, r *http.Request) {
+	md, err := h.metadata.LookupBook(r.Context(), r.URL.Query().Get("isbn"))
+	var ve *appsvc.ValidationError
+	switch {
+	case errors.As(err, &ve):
+		httpValidation(w, ve)
+		return
+	case errors.Is(err, ports.ErrMetadataUnavailable):
+		httpError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	case err != nil:
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	case md == nil:
+		httpError(w, http.StatusNotFound, "no catalogue knows this ISBN")
+		return
+	}
+	jsonOK(w, md)
+}
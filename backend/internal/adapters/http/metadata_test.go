@@ -0,0 +1,64 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type mockMetadataService struct {
+	LookupFn func(ctx context.Context, isbn string) (*domain.BookMetadata, error)
+}
+
+func (m *mockMetadataService) LookupBook(ctx context.Context, isbn string) (*domain.BookMetadata, error) {
+	return m.LookupFn(ctx, isbn)
+}
+
+func TestLookupBook(t *testing.T) {
+	ms := &mockMetadataService{
+		LookupFn: func(ctx context.Context, isbn string) (*domain.BookMetadata, error) {
+			switch isbn {
+			case "9780132350884":
+				return &domain.BookMetadata{ISBN: isbn, Title: "Clean Code", Author: "Robert C. Martin", PublicationYear: 2008, Source: "openlibrary"}, nil
+			case "bad":
+				return nil, &appsvc.ValidationError{Fields: map[string]string{"isbn": "Invalid ISBN"}}
+			case "9780306406157":
+				return nil, ports.ErrMetadataUnavailable
+			}
+			return nil, nil
+		},
+	}
+	ts := newSpecServer(t, &mockBookService{}, WithMetadata(ms))
+	defer ts.Close()
+
+	cases := []struct {
+		isbn     string
+		want     int
+		contains string
+	}{
+		{"9780132350884", http.StatusOK, `"source":"openlibrary"`},
+		{"9791032305690", http.StatusNotFound, "no catalogue"},
+		{"bad", http.StatusUnprocessableEntity, "isbn"},
+		{"9780306406157", http.StatusServiceUnavailable, "unavailable"},
+	}
+	for _, tc := range cases {
+		res := do(t, ts, http.MethodPost, "/books/lookup?isbn="+tc.isbn, nil)
+		body := readBody(t, res)
+		if res.StatusCode != tc.want || !contains(body, tc.contains) {
+			t.Fatalf("%s: status = %d, body = %s", tc.isbn, res.StatusCode, body)
+		}
+	}
+}
+
+func TestLookupBook_DisabledWithoutProviders(t *testing.T) {
+	ts := newTestServer(t, &mockBookService{})
+	defer ts.Close()
+
+	if res := do(t, ts, http.MethodPost, "/books/lookup?isbn=9780132350884", nil); res.StatusCode == http.StatusOK {
+		t.Fatal("lookup served without metadata providers")
+	}
+}
@@ -0,0 +1,456 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gerry-sabar/byfood/internal/logger"
+)
+
+// ---- OpenAPI (Swagger 2.0) request/response validation ----
+//
+// SpecValidator checks every request and response passing through it against
+// the generated swagger document. It is meant for non-prod environments: any
+// drift between the handlers and the spec is logged and turned into a 500 so
+// it gets noticed instead of silently shipping.
+
+type specSchema struct {
+	Ref                  string                 `json:"$ref"`
+	Type                 string                 `json:"type"`
+	Properties           map[string]*specSchema `json:"properties"`
+	AdditionalProperties *specSchema            `json:"additionalProperties"`
+	Items                *specSchema            `json:"items"`
+	Required             []string               `json:"required"`
+	Enum                 []any                  `json:"enum"`
+}
+
+type specParam struct {
+	Name     string      `json:"name"`
+	In       string      `json:"in"`
+	Required bool        `json:"required"`
+	Type     string      `json:"type"`
+	Minimum  *float64    `json:"minimum"`
+	Maximum  *float64    `json:"maximum"`
+	Enum     []any       `json:"enum"`
+	Schema   *specSchema `json:"schema"`
+}
+
+type specResponse struct {
+	Schema *specSchema `json:"schema"`
+}
+
+type specOperation struct {
+	Parameters []specParam             `json:"parameters"`
+	Responses  map[string]specResponse `json:"responses"`
+}
+
+type specDoc struct {
+	BasePath    string                              `json:"basePath"`
+	Paths       map[string]map[string]specOperation `json:"paths"`
+	Definitions map[string]*specSchema              `json:"definitions"`
+}
+
+type specRoute struct {
+	template string
+	segments []string
+	literals int
+	ops      map[string]specOperation // keyed by upper-case method
+}
+
+// SpecValidator matches requests to documented operations and validates both
+// directions against their schemas.
+type SpecValidator struct {
+	defs   map[string]*specSchema
+	routes []specRoute
+}
+
+// NewSpecValidator parses a swagger 2.0 JSON document (as produced by swag).
+func NewSpecValidator(doc []byte) (*SpecValidator, error) {
+	var d specDoc
+	if err := json.Unmarshal(doc, &d); err != nil {
+		return nil, fmt.Errorf("parse openapi doc: %w", err)
+	}
+	base := strings.TrimSuffix(d.BasePath, "/")
+
+	v := &SpecValidator{defs: d.Definitions}
+	for tpl, methods := range d.Paths {
+		rt := specRoute{template: tpl, segments: splitPath(base + tpl), ops: map[string]specOperation{}}
+		for _, s := range rt.segments {
+			if !isPathParam(s) {
+				rt.literals++
+			}
+		}
+		for m, op := range methods {
+			rt.ops[strings.ToUpper(m)] = op
+		}
+		v.routes = append(v.routes, rt)
+	}
+	// most specific first: literal segments win over {params}
+	sort.Slice(v.routes, func(i, j int) bool {
+		if v.routes[i].literals != v.routes[j].literals {
+			return v.routes[i].literals > v.routes[j].literals
+		}
+		return v.routes[i].template < v.routes[j].template
+	})
+	return v, nil
+}
+
+// Middleware validates the request before calling next and the buffered
+// response afterwards.
+func (v *SpecValidator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		op, params, tpl, err := v.match(r)
+		if err != nil {
+			v.drift(w, r, err)
+			return
+		}
+		if err := v.validateRequest(r, op, params); err != nil {
+			logger.From(r.Context()).Warn("openapi request mismatch",
+				"method", r.Method, "path", r.URL.Path, "route", tpl, "error", err)
+			httpError(w, http.StatusBadRequest, "request does not match API spec: "+err.Error())
+			return
+		}
+
+		rec := &responseRecorder{header: http.Header{}, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		if err := v.validateResponse(op, rec); err != nil {
+			v.drift(w, r, fmt.Errorf("%s %s: %w", r.Method, tpl, err))
+			return
+		}
+		rec.flushTo(w)
+	})
+}
+
+func (v *SpecValidator) drift(w http.ResponseWriter, r *http.Request, err error) {
+	logger.From(r.Context()).Error("openapi drift", "method", r.Method, "path", r.URL.Path, "error", err)
+	httpError(w, http.StatusInternalServerError, "openapi drift: "+err.Error())
+}
+
+func (v *SpecValidator) match(r *http.Request) (specOperation, map[string]string, string, error) {
+	// split before unescaping, so an escaped / stays inside its segment
+	segs := splitPath(r.URL.EscapedPath())
+	for i, s := range segs {
+		if u, err := url.PathUnescape(s); err == nil {
+			segs[i] = u
+		}
+	}
+	for _, rt := range v.routes {
+		params, ok := matchSegments(rt.segments, segs)
+		if !ok {
+			continue
+		}
+		op, ok := rt.ops[r.Method]
+		if !ok {
+			return specOperation{}, nil, "", fmt.Errorf("method %s not documented for %s", r.Method, rt.template)
+		}
+		return op, params, rt.template, nil
+	}
+	return specOperation{}, nil, "", fmt.Errorf("route %s %s not documented", r.Method, r.URL.Path)
+}
+
+func (v *SpecValidator) validateRequest(r *http.Request, op specOperation, pathParams map[string]string) error {
+	q := r.URL.Query()
+	for _, p := range op.Parameters {
+		switch p.In {
+		case "path":
+			if err := checkParam(p, pathParams[p.Name], true); err != nil {
+				return err
+			}
+		case "query":
+			if _, ok := q[p.Name]; !ok {
+				if p.Required {
+					return fmt.Errorf("query parameter %q is required", p.Name)
+				}
+				continue
+			}
+			if err := checkParam(p, q.Get(p.Name), true); err != nil {
+				return err
+			}
+		case "header":
+			val := r.Header.Get(p.Name)
+			if err := checkParam(p, val, val != "" || p.Required); err != nil {
+				return err
+			}
+		case "body":
+			if p.Schema == nil {
+				continue
+			}
+			raw, err := io.ReadAll(r.Body)
+			if err != nil {
+				return fmt.Errorf("read body: %w", err)
+			}
+			r.Body = io.NopCloser(bytes.NewReader(raw))
+			if len(bytes.TrimSpace(raw)) == 0 {
+				if p.Required {
+					return fmt.Errorf("request body is required")
+				}
+				continue
+			}
+			doc, err := decodeJSON(raw)
+			if err != nil {
+				// leave malformed JSON to the handler's own 400
+				continue
+			}
+			if err := v.validate(p.Schema, doc, "body", false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v *SpecValidator) validateResponse(op specOperation, rec *responseRecorder) error {
+	resp, ok := op.Responses[strconv.Itoa(rec.status)]
+	if !ok {
+		if resp, ok = op.Responses["default"]; !ok {
+			return fmt.Errorf("status %d not documented", rec.status)
+		}
+	}
+	if resp.Schema == nil {
+		return nil
+	}
+	if !strings.HasPrefix(rec.header.Get("Content-Type"), "application/json") {
+		// non-JSON payloads (CSV, ZPL, ...) are only checked for their status
+		return nil
+	}
+	doc, err := decodeJSON(rec.body.Bytes())
+	if err != nil {
+		return fmt.Errorf("status %d: invalid JSON body: %w", rec.status, err)
+	}
+	if err := v.validate(resp.Schema, doc, "response", true); err != nil {
+		return fmt.Errorf("status %d: %w", rec.status, err)
+	}
+	return nil
+}
+
+// validate checks doc against s. strict rejects properties the schema does not
+// declare, which is what we want for responses (undocumented fields are drift).
+func (v *SpecValidator) validate(s *specSchema, doc any, at string, strict bool) error {
+	if s.Ref != "" {
+		def, ok := v.defs[strings.TrimPrefix(s.Ref, "#/definitions/")]
+		if !ok {
+			return fmt.Errorf("%s: unknown schema %s", at, s.Ref)
+		}
+		s = def
+	}
+	if len(s.Enum) > 0 && !enumContains(s.Enum, doc) {
+		return fmt.Errorf("%s: value %v not in enum", at, doc)
+	}
+
+	switch s.Type {
+	case "", "file":
+		return nil
+	case "object":
+		obj, ok := doc.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: want object, got %s", at, jsonKind(doc))
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				return fmt.Errorf("%s.%s: required", at, name)
+			}
+		}
+		for name, val := range obj {
+			prop, ok := s.Properties[name]
+			if !ok {
+				prop = s.AdditionalProperties
+			}
+			if prop == nil {
+				if strict && s.Properties != nil {
+					return fmt.Errorf("%s.%s: property not documented", at, name)
+				}
+				continue
+			}
+			if val == nil {
+				continue // nullable/omitted optional values
+			}
+			if err := v.validate(prop, val, at+"."+name, strict); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "array":
+		arr, ok := doc.([]any)
+		if !ok {
+			return fmt.Errorf("%s: want array, got %s", at, jsonKind(doc))
+		}
+		if s.Items == nil {
+			return nil
+		}
+		for i, el := range arr {
+			if err := v.validate(s.Items, el, fmt.Sprintf("%s[%d]", at, i), strict); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "string":
+		if _, ok := doc.(string); !ok {
+			return fmt.Errorf("%s: want string, got %s", at, jsonKind(doc))
+		}
+	case "integer":
+		n, ok := doc.(json.Number)
+		if !ok {
+			return fmt.Errorf("%s: want integer, got %s", at, jsonKind(doc))
+		}
+		if _, err := n.Int64(); err != nil {
+			return fmt.Errorf("%s: want integer, got %s", at, n)
+		}
+	case "number":
+		if _, ok := doc.(json.Number); !ok {
+			return fmt.Errorf("%s: want number, got %s", at, jsonKind(doc))
+		}
+	case "boolean":
+		if _, ok := doc.(bool); !ok {
+			return fmt.Errorf("%s: want boolean, got %s", at, jsonKind(doc))
+		}
+	}
+	return nil
+}
+
+// helpers
+
+func checkParam(p specParam, raw string, present bool) error {
+	if !present {
+		if p.Required {
+			return fmt.Errorf("%s parameter %q is required", p.In, p.Name)
+		}
+		return nil
+	}
+	var num float64
+	switch p.Type {
+	case "integer":
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s parameter %q must be an integer", p.In, p.Name)
+		}
+		num = float64(n)
+	case "number":
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("%s parameter %q must be a number", p.In, p.Name)
+		}
+		num = n
+	case "boolean":
+		if _, err := strconv.ParseBool(raw); err != nil {
+			return fmt.Errorf("%s parameter %q must be a boolean", p.In, p.Name)
+		}
+	}
+	if p.Minimum != nil && num < *p.Minimum {
+		return fmt.Errorf("%s parameter %q must be ≥ %v", p.In, p.Name, *p.Minimum)
+	}
+	if p.Maximum != nil && num > *p.Maximum {
+		return fmt.Errorf("%s parameter %q must be ≤ %v", p.In, p.Name, *p.Maximum)
+	}
+	if len(p.Enum) > 0 && !enumContains(p.Enum, raw) {
+		return fmt.Errorf("%s parameter %q has unsupported value %q", p.In, p.Name, raw)
+	}
+	return nil
+}
+
+func enumContains(enum []any, v any) bool {
+	want := fmt.Sprint(v)
+	for _, e := range enum {
+		if fmt.Sprint(e) == want {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeJSON(b []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func jsonKind(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func splitPath(p string) []string {
+	var out []string
+	for _, s := range strings.Split(p, "/") {
+		if s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func isPathParam(seg string) bool {
+	return strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}")
+}
+
+func matchSegments(tpl, segs []string) (map[string]string, bool) {
+	if len(tpl) != len(segs) {
+		return nil, false
+	}
+	params := map[string]string{}
+	for i, t := range tpl {
+		if isPathParam(t) {
+			params[t[1:len(t)-1]] = segs[i]
+			continue
+		}
+		if t != segs[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// responseRecorder buffers the handler output so it can be checked before it
+// reaches the client.
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+	wrote  bool
+}
+
+func (rr *responseRecorder) Header() http.Header { return rr.header }
+
+func (rr *responseRecorder) WriteHeader(code int) {
+	if !rr.wrote {
+		rr.status = code
+		rr.wrote = true
+	}
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	rr.wrote = true
+	return rr.body.Write(b)
+}
+
+func (rr *responseRecorder) flushTo(w http.ResponseWriter) {
+	for k, vals := range rr.header {
+		w.Header()[k] = vals
+	}
+	w.WriteHeader(rr.status)
+	_, _ = w.Write(rr.body.Bytes())
+}
@@ -0,0 +1,207 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/docs"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+func newSpecServer(t *testing.T, svc ports.BookService, opts ...Option) *httptest.Server {
+	t.Helper()
+	v, err := NewSpecValidator([]byte(docs.SwaggerInfo.ReadDoc()))
+	if err != nil {
+		t.Fatalf("NewSpecValidator: %v", err)
+	}
+	return httptest.NewServer(v.Middleware(NewHandler(svc, opts...).Router()))
+}
+
+func TestSpecValidator_HandlersMatchSpec(t *testing.T) {
+	mock := &mockBookService{
+		ListBooksFn: func(ctx context.Context) ([]domain.Book, error) {
+			return nil, nil // empty list must still encode as an array
+		},
+		ListPageFn: func(ctx context.Context, f ports.ListFilter, page ports.Page) (*ports.BookPage, error) {
+			return &ports.BookPage{Books: []domain.Book{{ID: 1}}, Total: 3}, nil
+		},
+		GetBookFn: func(ctx context.Context, id int64) (*domain.Book, error) {
+			return &domain.Book{ID: id, Title: "X"}, nil
+		},
+		DeleteBookFn: func(ctx context.Context, id int64) error { return nil },
+		SplitBookFn: func(ctx context.Context, id int64, in ports.SplitBookInput) (*ports.SplitBookResult, error) {
+			work := id
+			return &ports.SplitBookResult{
+				Source:  domain.Book{ID: id, WorkID: &work, Aliases: []string{"A"}},
+				Edition: domain.Book{ID: id + 1, WorkID: &work},
+			}, nil
+		},
+	}
+	feed := &mockChangeFeed{
+		SinceFn: func(ctx context.Context, cursor int64, limit int, wait time.Duration) ([]domain.Change, error) {
+			return []domain.Change{{ID: 3, BookID: 1, Op: domain.ChangeCreated}}, nil
+		},
+	}
+	sync := &mockSyncService{
+		PullFn: func(ctx context.Context, checkpoint int64, snapshot *ports.SyncSnapshot, limit int) (*ports.SyncPullResponse, error) {
+			return &ports.SyncPullResponse{Upserts: []domain.Book{{ID: 1}}, Tombstones: []ports.Tombstone{{ID: 2}}}, nil
+		},
+		PushFn: func(ctx context.Context, in ports.SyncPushRequest) (*ports.SyncPushResponse, error) {
+			return &ports.SyncPushResponse{Results: []ports.SyncResult{{ID: 1, Status: "merged",
+				Conflicts: []ports.FieldConflict{{Field: "price", Server: 1.5, Client: 2.0, Resolution: "server-wins"}}}}}, nil
+		},
+	}
+	aliases := &mockAliasService{
+		ListAliasesFn: func(ctx context.Context, bookID int64) ([]domain.Alias, error) {
+			return []domain.Alias{{ID: 1, BookID: bookID, Alias: "Y"}}, nil
+		},
+		AddAliasFn: func(ctx context.Context, bookID int64, in ports.AddAliasInput) (*domain.Alias, error) {
+			return &domain.Alias{ID: 2, BookID: bookID, Alias: in.Alias}, nil
+		},
+		DeleteAliasFn: func(ctx context.Context, bookID, aliasID int64) error { return nil },
+	}
+	authors := &mockAuthorService{AuthorSummaryFn: func(ctx context.Context, id string) (*domain.AuthorSummary, error) {
+		return &domain.AuthorSummary{ID: id, Books: []domain.AuthorBook{{ID: 1}}}, nil
+	}}
+	searches := &mockSavedSearchService{
+		CreateFn: func(ctx context.Context, in ports.CreateSavedSearchInput) (*domain.SavedSearch, error) {
+			return &domain.SavedSearch{ID: 1, Name: in.Name, Query: in.Query, Notify: in.Notify}, nil
+		},
+		ListFn: func(ctx context.Context) ([]domain.SavedSearch, error) {
+			return []domain.SavedSearch{{ID: 1}}, nil
+		},
+		GetFn: func(ctx context.Context, id int64) (*domain.SavedSearch, error) {
+			return &domain.SavedSearch{ID: id}, nil
+		},
+		DeleteFn: func(ctx context.Context, id int64) error { return nil },
+		NotificationsFn: func(ctx context.Context, id int64, limit int) ([]domain.SearchNotification, error) {
+			return []domain.SearchNotification{{ID: 1, SearchID: id, BookID: 2}}, nil
+		},
+	}
+	ts := newSpecServer(t, mock, WithChangeFeed(feed), WithSync(sync), WithAliases(aliases), WithAuthors(authors),
+		WithSavedSearches(searches))
+	defer ts.Close()
+
+	cases := []struct {
+		method, path string
+		body         any
+		want         int
+	}{
+		{http.MethodGet, "/books/", nil, http.StatusOK},
+		{http.MethodGet, "/books/?limit=1&offset=1", nil, http.StatusOK},
+		{http.MethodGet, "/books/?author=X&year_from=1990&year_to=2000&price_min=1.5&price_max=20", nil, http.StatusOK},
+		{http.MethodGet, "/books/?sort=-title&collation=tr", nil, http.StatusOK},
+		{http.MethodGet, "/books/?isbn=9780802130303&exact=true", nil, http.StatusOK},
+		{http.MethodGet, "/books/1/", nil, http.StatusOK},
+		{http.MethodGet, "/books/1", nil, http.StatusOK},
+		{http.MethodDelete, "/books/1/", nil, http.StatusNoContent},
+		{http.MethodPost, "/url/cleanup", map[string]any{"url": "https://example.com", "operation": "all"}, http.StatusOK},
+		{http.MethodGet, "/books/changes?since=2", nil, http.StatusOK},
+		{http.MethodGet, "/authors/robert-c-martin/summary", nil, http.StatusOK},
+		{http.MethodPost, "/saved-searches/", map[string]any{"name": "Borges", "query": "author:borges", "notify": true}, http.StatusCreated},
+		{http.MethodGet, "/saved-searches/", nil, http.StatusOK},
+		{http.MethodGet, "/saved-searches/1", nil, http.StatusOK},
+		{http.MethodGet, "/saved-searches/1/notifications?limit=10", nil, http.StatusOK},
+		{http.MethodDelete, "/saved-searches/1", nil, http.StatusNoContent},
+		{http.MethodGet, "/sync/books?checkpoint=1", nil, http.StatusOK},
+		{http.MethodPost, "/sync/books", map[string]any{"changes": []map[string]any{{"op": "update", "id": 1}}}, http.StatusOK},
+		{http.MethodGet, "/books/export?format=xlsx", nil, http.StatusOK},
+		{http.MethodGet, "/books/1/label.zpl", nil, http.StatusOK},
+		{http.MethodPost, "/books/1/split", map[string]any{"fields": map[string]any{"isbn": "9780321125217"}, "alias_ids": []int{1}}, http.StatusCreated},
+		{http.MethodGet, "/books/1/aliases/", nil, http.StatusOK},
+		{http.MethodPost, "/books/1/aliases/", map[string]any{"alias": "Z"}, http.StatusCreated},
+		{http.MethodDelete, "/books/1/aliases/2", nil, http.StatusNoContent},
+	}
+	for _, c := range cases {
+		res := do(t, ts, c.method, c.path, c.body)
+		if res.StatusCode != c.want {
+			t.Fatalf("%s %s: status = %d, want %d (%s)", c.method, c.path, res.StatusCode, c.want, readBody(t, res))
+		}
+		res.Body.Close()
+	}
+}
+
+func TestSpecValidator_RequestMismatch(t *testing.T) {
+	ts := newSpecServer(t, &mockBookService{})
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodPost, "/books/", map[string]any{"title": 123})
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", res.StatusCode)
+	}
+	body := readBody(t, res)
+	if !contains(body, "body.title: want string") {
+		t.Fatalf("body = %s", body)
+	}
+}
+
+func TestSpecValidator_UndocumentedRoute(t *testing.T) {
+	ts := newSpecServer(t, &mockBookService{})
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodPatch, "/books/1/", map[string]any{"title": "A"})
+	if res.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", res.StatusCode)
+	}
+	if body := readBody(t, res); !contains(body, "openapi drift") {
+		t.Fatalf("body = %s", body)
+	}
+}
+
+func TestSpecValidator_ResponseDrift(t *testing.T) {
+	spec := `{
+		"basePath": "/",
+		"paths": {
+			"/things/{id}": {
+				"get": {
+					"parameters": [{"name": "id", "in": "path", "required": true, "type": "integer", "minimum": 1}],
+					"responses": {"200": {"schema": {"$ref": "#/definitions/Thing"}}}
+				}
+			}
+		},
+		"definitions": {
+			"Thing": {"type": "object", "properties": {"id": {"type": "integer"}, "name": {"type": "string"}}}
+		}
+	}`
+	v, err := NewSpecValidator([]byte(spec))
+	if err != nil {
+		t.Fatalf("NewSpecValidator: %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		path   string
+		status int
+		body   string
+		want   int
+		errSub string
+	}{
+		{"ok", "/things/1", http.StatusOK, `{"id":1,"name":"a"}`, http.StatusOK, ""},
+		{"wrong type", "/things/1", http.StatusOK, `{"id":"1"}`, http.StatusInternalServerError, "response.id: want integer"},
+		{"extra field", "/things/1", http.StatusOK, `{"id":1,"secret":true}`, http.StatusInternalServerError, "response.secret: property not documented"},
+		{"status not documented", "/things/1", http.StatusTeapot, `{}`, http.StatusInternalServerError, "status 418 not documented"},
+		{"bad path param", "/things/0", http.StatusOK, `{}`, http.StatusBadRequest, "must be ≥ 1"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(c.status)
+				_, _ = w.Write([]byte(c.body))
+			})
+			rec := httptest.NewRecorder()
+			v.Middleware(next).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, c.path, nil))
+			if rec.Code != c.want {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, c.want, rec.Body.String())
+			}
+			if c.errSub != "" && !strings.Contains(rec.Body.String(), c.errSub) {
+				t.Fatalf("body = %s, want substring %q", rec.Body.String(), c.errSub)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) publisherRoutes(r chi.Router) {
+	edit := h.requireEditor
+	r.Get("/", h.ListPublishers)
+	r.With(edit).Post("/", h.CreatePublisher)
+	r.Get("/{id}", h.GetPublisher)
+	r.With(edit).Put("/{id}", h.UpdatePublisher)
+	r.With(edit).Delete("/{id}", h.DeletePublisher)
+}
+
+// GET /publishers
+// --- ListPublishers ---
+// ListPublishers godoc
+// @Summary      List publishers
+// @Description  Ordered by name.
+// @Tags         publishers
+// @Produce      json
+// @Success      200  {array}   domain.Publisher
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /publishers/ [get]
+func (h *Handler) ListPublishers(w http.ResponseWriter, r *http.Request) {
+	publishers, err := h.publishers.ListPublishers(r.Context())
+	if err != nil {
+		publisherError(w, err)
+		return
+	}
+	jsonOK(w, publishers)
+}
+
+// POST /publishers
+// --- CreatePublisher ---
+// CreatePublisher godoc
+// @Summary      Add a publisher
+// @Description  Names are unique, ignoring case and accents. `country` is an ISO 3166-1 alpha-2 code and `website` an http(s) URL; both are optional.
+// @Tags         publishers
+// @Accept       json
+// @Produce      json
+// @Param        body  body      ports.PublisherInput  true  "Publisher"
+// @Success      201   {object}  domain.Publisher
+// @Failure      400   {object}  ports.ErrorResponse
+// @Failure      403   {object}  ports.ErrorResponse
+// @Failure      409   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Failure      500   {object}  ports.ErrorResponse
+// @Router       /publishers/ [post]
+func (h *Handler) CreatePublisher(w http.ResponseWriter, r *http.Request) {
+	var in ports.PublisherInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	p, err := h.publishers.CreatePublisher(r.Context(), in)
+	if err != nil {
+		publisherError(w, err)
+		return
+	}
+	jsonCreated(w, p)
+}
+
+// GET /publishers/{id}
+// --- GetPublisher ---
+// GetPublisher godoc
+// @Summary      Get a publisher
+// @Tags         publishers
+// @Produce      json
+// @Param        id   path      int  true  "Publisher ID"  minimum(1)
+// @Success      200  {object}  domain.Publisher
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /publishers/{id} [get]
+func (h *Handler) GetPublisher(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	p, err := h.publishers.GetPublisher(r.Context(), id)
+	if err != nil {
+		publisherError(w, err)
+		return
+	}
+	jsonOK(w, p)
+}
+
+// PUT /publishers/{id}
+// --- UpdatePublisher ---
+// UpdatePublisher godoc
+// @Summary      Replace a publisher
+// @Description  Every field is replaced; leaving out `country` or `website` clears it.
+// @Tags         publishers
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int                   true  "Publisher ID"  minimum(1)
+// @Param        body  body      ports.PublisherInput  true  "Publisher"
+// @Success      200   {object}  domain.Publisher
+// @Failure      400   {object}  ports.ErrorResponse
+// @Failure      403   {object}  ports.ErrorResponse
+// @Failure      404   {object}  ports.ErrorResponse
+// @Failure      409   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Failure      500   {object}  ports.ErrorResponse
+// @Router       /publishers/{id} [put]
+func (h *Handler) UpdatePublisher(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	var in ports.PublisherInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	p, err := h.publishers.UpdatePublisher(r.Context(), id, in)
+	if err != nil {
+		publisherError(w, err)
+		return
+	}
+	jsonOK(w, p)
+}
+
+// DELETE /publishers/{id}
+// --- DeletePublisher ---
+// DeletePublisher godoc
+// @Summary      Delete a publisher
+// @Description  A publisher with books is kept (409, code `publisher_in_use`) unless `detach=true`, which removes the publisher from its books in the same transaction.
+// @Tags         publishers
+// @Param        id      path   int   true   "Publisher ID"  minimum(1)
+// @Param        detach  query  bool  false  "Remove the publisher from its books"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      409  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /publishers/{id} [delete]
+func (h *Handler) DeletePublisher(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	detach := false
+	if v := r.URL.Query().Get("detach"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			httpError(w, http.StatusBadRequest, "detach must be true or false")
+			return
+		}
+		detach = b
+	}
+	if err := h.publishers.DeletePublisher(r.Context(), id, detach); err != nil {
+		publisherError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func publisherError(w http.ResponseWriter, err error) {
+	if ve, ok := err.(*appsvc.ValidationError); ok {
+		httpValidation(w, ve)
+		return
+	}
+	switch {
+	case err.Error() == "publisher not found":
+		httpError(w, http.StatusNotFound, "not found")
+	case errors.Is(err, ports.ErrPublisherNameTaken):
+		httpError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, ports.ErrPublisherInUse):
+		httpErrorCode(w, http.StatusConflict, "publisher_in_use", err.Error()+"; delete with detach=true to remove it from them")
+	default:
+		httpError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type mockPublisherService struct {
+	ports.PublisherService
+	CreateFn func(ctx context.Context, in ports.PublisherInput) (*domain.Publisher, error)
+	DeleteFn func(ctx context.Context, id int64, detach bool) error
+}
+
+func (m *mockPublisherService) CreatePublisher(ctx context.Context, in ports.PublisherInput) (*domain.Publisher, error) {
+	return m.CreateFn(ctx, in)
+}
+func (m *mockPublisherService) DeletePublisher(ctx context.Context, id int64, detach bool) error {
+	return m.DeleteFn(ctx, id, detach)
+}
+
+func TestPublishers_CreateAndDelete(t *testing.T) {
+	var detached bool
+	ts := httptest.NewServer(NewHandler(&mockBookService{}, WithPublishers(&mockPublisherService{
+		CreateFn: func(ctx context.Context, in ports.PublisherInput) (*domain.Publisher, error) {
+			switch in.Name {
+			case "":
+				return nil, &appsvc.ValidationError{Fields: map[string]string{"name": "Name is required"}}
+			case "Ace Books":
+				return nil, ports.ErrPublisherNameTaken
+			}
+			return &domain.Publisher{ID: 3, Name: in.Name, Country: in.Country}, nil
+		},
+		DeleteFn: func(ctx context.Context, id int64, detach bool) error {
+			detached = detach
+			if !detach {
+				return ports.ErrPublisherInUse
+			}
+			return nil
+		},
+	})).Router())
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodPost, "/publishers/", map[string]any{"name": "Tor Books", "country": "US"})
+	if body := readBody(t, res); res.StatusCode != http.StatusCreated || !contains(body, `"country":"US"`) {
+		t.Fatalf("create: %d %s", res.StatusCode, body)
+	}
+	res = do(t, ts, http.MethodPost, "/publishers/", map[string]any{"name": ""})
+	if body := readBody(t, res); res.StatusCode != http.StatusUnprocessableEntity || !contains(body, "name") {
+		t.Fatalf("invalid: %d %s", res.StatusCode, body)
+	}
+	res = do(t, ts, http.MethodPost, "/publishers/", map[string]any{"name": "Ace Books"})
+	if readBody(t, res); res.StatusCode != http.StatusConflict {
+		t.Fatalf("taken: %d", res.StatusCode)
+	}
+
+	res = do(t, ts, http.MethodDelete, "/publishers/3", nil)
+	if body := readBody(t, res); res.StatusCode != http.StatusConflict || !contains(body, `"code":"publisher_in_use"`) {
+		t.Fatalf("in use: %d %s", res.StatusCode, body)
+	}
+	res = do(t, ts, http.MethodDelete, "/publishers/3?detach=true", nil)
+	if readBody(t, res); res.StatusCode != http.StatusNoContent || !detached {
+		t.Fatalf("detach: %d (detach %v)", res.StatusCode, detached)
+	}
+	res = do(t, ts, http.MethodDelete, "/publishers/3?detach=maybe", nil)
+	if readBody(t, res); res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("bad detach: %d", res.StatusCode)
+	}
+}
@@ -0,0 +1,46 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	l := NewRateLimiter(0.5, 2) // one request every 2s, bursts of 2
+	l.now = func() time.Time { return now }
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	get := func(remote string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/books/", nil)
+		req.RemoteAddr = remote
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := get("10.0.0.1:1234"); rec.Code != http.StatusOK {
+			t.Fatalf("request %d within burst: %d", i, rec.Code)
+		}
+	}
+	rec := get("10.0.0.1:5678") // same client, other port
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "2" ||
+		!contains(rec.Body.String(), `"error":"rate limit exceeded"`) {
+		t.Fatalf("over limit: %d %q %s", rec.Code, rec.Header().Get("Retry-After"), rec.Body.String())
+	}
+	if rec := get("10.0.0.2"); rec.Code != http.StatusOK {
+		t.Fatalf("other client: %d", rec.Code)
+	}
+
+	now = now.Add(2 * time.Second)
+	if rec := get("10.0.0.1:1234"); rec.Code != http.StatusOK {
+		t.Fatalf("after refill: %d", rec.Code)
+	}
+	now = now.Add(time.Hour)
+	get("10.0.0.3")
+	if len(l.buckets) != 1 {
+		t.Fatalf("idle buckets kept: %d", len(l.buckets))
+	}
+}
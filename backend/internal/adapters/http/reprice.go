@@ -0,0 +1,78 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// POST /books/reprice
+// --- RepriceBooks ---
+// RepriceBooks godoc
+// @Summary      Bulk re-price books
+// @Description  Applies a rule (percent, then amount, then rounding to an ending such as 0.99) to every book matching the filter. Runs as a preview unless `dry_run` is false; applied changes are written in one transaction with price-history entries sharing a batch_id.
+// @Tags         books
+// @Accept       json
+// @Produce      json
+// @Param        body  body      ports.RepriceRequest  true  "Filter and rule"
+// @Success      200   {object}  ports.RepriceResponse
+// @Failure      400   {object}  ports.ErrorResponse
+// @Failure      403   {object}  ports.ErrorResponse
+// @Failure      409   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Failure      500   {object}  ports.ErrorResponse
+// @Router       /books/reprice [post]
+func (h *Handler) RepriceBooks(w http.ResponseWriter, r *http.Request) {
+	var in ports.RepriceRequest
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	resp, err := h.reprice.Reprice(r.Context(), in)
+	if err != nil {
+		if ve, ok := err.(*appsvc.ValidationError); ok {
+			httpValidation(w, ve)
+			return
+		}
+		if errors.Is(err, ports.ErrConcurrentUpdate) {
+			httpError(w, http.StatusConflict, "prices changed while re-pricing; nothing was applied, preview again")
+			return
+		}
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, resp)
+}
+
+// GET /books/{id}/price-history
+// --- BookPriceHistory ---
+// BookPriceHistory godoc
+// @Summary      Price history of a book
+// @Description  Every price change of the book, oldest first, for charting: edits (reason `update`) and re-prices (reason `reprice: ...`, with their `batch_id`), each with who made it. A book's first price is its price at creation, before the first change.
+// @Tags         books
+// @Produce      json
+// @Param        id   path      int  true  "Book ID"  minimum(1)
+// @Success      200  {array}   domain.PriceChange
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /books/{id}/price-history [get]
+func (h *Handler) BookPriceHistory(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	history, err := h.priceHistory.PriceHistory(r.Context(), id)
+	if err != nil {
+		if err.Error() == "book not found" {
+			httpError(w, http.StatusNotFound, "not found")
+			return
+		}
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, history)
+}
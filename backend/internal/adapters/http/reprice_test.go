@@ -0,0 +1,91 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/docs"
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type mockRepriceService struct {
+	RepriceFn func(ctx context.Context, in ports.RepriceRequest) (*ports.RepriceResponse, error)
+}
+
+func (m *mockRepriceService) Reprice(ctx context.Context, in ports.RepriceRequest) (*ports.RepriceResponse, error) {
+	return m.RepriceFn(ctx, in)
+}
+
+func TestRepriceBooks(t *testing.T) {
+	rs := &mockRepriceService{
+		RepriceFn: func(ctx context.Context, in ports.RepriceRequest) (*ports.RepriceResponse, error) {
+			switch {
+			case in.Filter.Author == "":
+				return nil, &appsvc.ValidationError{Fields: map[string]string{"filter": "Filter is required"}}
+			case in.DryRun != nil && !*in.DryRun:
+				return nil, ports.ErrConcurrentUpdate
+			}
+			return &ports.RepriceResponse{DryRun: true, Matched: 1, Changes: []ports.RepriceItem{{BookID: 1, OldPrice: 30, NewPrice: 32.99}}}, nil
+		},
+	}
+	ts := httptest.NewServer(NewHandler(&mockBookService{}, WithReprice(rs)).Router())
+	defer ts.Close()
+
+	cases := []struct {
+		body     map[string]any
+		want     int
+		contains string
+	}{
+		{map[string]any{"filter": map[string]any{"author": "X"}, "rule": map[string]any{"percent": 10}}, http.StatusOK, `"new_price":32.99`},
+		{map[string]any{"rule": map[string]any{"percent": 10}}, http.StatusUnprocessableEntity, `"filter"`},
+		{map[string]any{"filter": map[string]any{"author": "X"}, "dry_run": false}, http.StatusConflict, "nothing was applied"},
+	}
+	for _, c := range cases {
+		res := do(t, ts, http.MethodPost, "/books/reprice", c.body)
+		body := readBody(t, res)
+		if res.StatusCode != c.want || !contains(body, c.contains) {
+			t.Fatalf("%v: status = %d body = %s", c.body, res.StatusCode, body)
+		}
+	}
+}
+
+type mockPriceHistoryService func(ctx context.Context, id int64) ([]domain.PriceChange, error)
+
+func (m mockPriceHistoryService) PriceHistory(ctx context.Context, id int64) ([]domain.PriceChange, error) {
+	return m(ctx, id)
+}
+
+func TestBookPriceHistory(t *testing.T) {
+	batch := "b1"
+	history := mockPriceHistoryService(func(ctx context.Context, id int64) ([]domain.PriceChange, error) {
+		if id != 1 {
+			return nil, errors.New("book not found")
+		}
+		at := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+		return []domain.PriceChange{
+			{ID: 1, BookID: 1, OldPrice: 30, NewPrice: 32.99, Reason: "reprice: +10%", BatchID: &batch, ChangedAt: at},
+			{ID: 2, BookID: 1, OldPrice: 32.99, NewPrice: 29.5, Reason: "update", ChangedBy: "ann", ChangedAt: at.Add(time.Hour)},
+		}, nil
+	})
+	v, err := NewSpecValidator([]byte(docs.SwaggerInfo.ReadDoc()))
+	if err != nil {
+		t.Fatalf("NewSpecValidator: %v", err)
+	}
+	ts := httptest.NewServer(v.Middleware(NewHandler(&mockBookService{}, WithPriceHistory(history)).Router()))
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodGet, "/books/1/price-history", nil)
+	if body := readBody(t, res); res.StatusCode != http.StatusOK || !contains(body, `"changed_by":"ann"`) || !contains(body, `"batch_id":"b1"`) {
+		t.Fatalf("history: %d %s", res.StatusCode, body)
+	}
+	res = do(t, ts, http.MethodGet, "/books/9/price-history", nil)
+	if readBody(t, res); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("missing book: %d", res.StatusCode)
+	}
+}
@@ -0,0 +1,67 @@
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/gerry-sabar/byfood/internal/logger"
+)
+
+// RequestIDHeader carries the request ID both ways: a caller may send one to
+// correlate its own logs, and every response echoes the ID that was used.
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLength = 128
+
+// RequestID gives each request an ID: the one already in the context when an
+// outer layer assigned it, else a well-formed X-Request-ID from the caller,
+// else a fresh random one. The ID is stored under chi's middleware.RequestIDKey
+// so middleware.Logger prints it, echoed in the X-Request-ID response header,
+// and attached to a request-scoped logger (see logger.From), so every line
+// logged while serving the request carries request_id.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id := middleware.GetReqID(ctx)
+		if id == "" {
+			id = r.Header.Get(RequestIDHeader)
+			if !validRequestID(id) {
+				id = newRequestID()
+			}
+			ctx = context.WithValue(ctx, middleware.RequestIDKey, id)
+			ctx = logger.WithLogger(ctx, logger.Log.With("request_id", id))
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// validRequestID accepts IDs of printable ASCII without spaces, so a caller's
+// ID can't smuggle anything odd into log lines or headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// requestID is the ID RequestID assigned to the response being written, for
+// error bodies; empty when the middleware isn't in the chain.
+func requestID(w http.ResponseWriter) string {
+	return w.Header().Get(RequestIDHeader)
+}
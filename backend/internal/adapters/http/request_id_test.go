@@ -0,0 +1,51 @@
+package http
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/internal/logger"
+)
+
+func TestRequestID(t *testing.T) {
+	var logs bytes.Buffer
+	prev := logger.Log
+	logger.Log = slog.New(slog.NewJSONHandler(&logs, nil))
+	t.Cleanup(func() { logger.Log = prev })
+
+	h := RequestID(RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.From(r.Context()).Info("lookup failed")
+		httpError(w, http.StatusNotFound, "not found")
+	})))
+	get := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/books/9", nil)
+		if id != "" {
+			req.Header.Set(RequestIDHeader, id)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("trace-42")
+	if got := rec.Header().Get(RequestIDHeader); got != "trace-42" {
+		t.Fatalf("X-Request-ID = %q; want the caller's", got)
+	}
+	if !contains(rec.Body.String(), `"request_id":"trace-42"`) {
+		t.Fatalf("error body = %s", rec.Body.String())
+	}
+	if !contains(logs.String(), `"request_id":"trace-42"`) {
+		t.Fatalf("log lines = %s", logs.String())
+	}
+
+	for _, bad := range []string{"", "has space", "\x7fdel", strings.Repeat("a", 129)} {
+		id := get(bad).Header().Get(RequestIDHeader)
+		if id == bad || len(id) != 32 {
+			t.Errorf("X-Request-ID for %q = %q; want a generated one", bad, id)
+		}
+	}
+}
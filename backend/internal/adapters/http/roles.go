@@ -0,0 +1,22 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// requireEditor refuses book mutations from actors without the editor or
+// admin role with 403, when roles are enforced (WithRoles).
+func (h *Handler) requireEditor(next http.Handler) http.Handler {
+	if !h.roles {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if actor, _ := domain.ActorFrom(r.Context()); !actor.CanEditBooks() {
+			httpError(w, http.StatusForbidden, "requires the editor or admin role")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,116 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/docs"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/gerry-sabar/byfood/internal/presenter"
+)
+
+func TestRoles_OnlyEditorsChangeBooks(t *testing.T) {
+	mock := &mockBookService{
+		ListBooksFn:  func(ctx context.Context) ([]domain.Book, error) { return nil, nil },
+		DeleteBookFn: func(ctx context.Context, id int64) error { return nil },
+		CreateBookFn: func(ctx context.Context, in ports.CreateBookInput) (*domain.Book, error) {
+			return &domain.Book{ID: 1, Title: in.Title}, nil
+		},
+	}
+	v, err := NewSpecValidator([]byte(docs.SwaggerInfo.ReadDoc()))
+	if err != nil {
+		t.Fatalf("NewSpecValidator: %v", err)
+	}
+	searches := &mockSavedSearchService{
+		CreateFn: func(ctx context.Context, in ports.CreateSavedSearchInput) (*domain.SavedSearch, error) {
+			return &domain.SavedSearch{ID: 1, Name: in.Name, Query: in.Query}, nil
+		},
+		DeleteFn: func(ctx context.Context, id int64) error { return nil },
+	}
+	ts := httptest.NewServer(Identify(true)(v.Middleware(NewHandler(mock, WithRoles(), WithSavedSearches(searches)).Router())))
+	defer ts.Close()
+
+	cases := []struct {
+		name, method, path, scopes string
+		want                       int
+	}{
+		{"anyone reads", http.MethodGet, "/books/", "", http.StatusOK},
+		{"anonymous create", http.MethodPost, "/books/", "", http.StatusForbidden},
+		{"reader create", http.MethodPost, "/books/", "reader", http.StatusForbidden},
+		{"reader delete", http.MethodDelete, "/books/1/", "reader", http.StatusForbidden},
+		{"editor create", http.MethodPost, "/books/", "editor", http.StatusCreated},
+		{"admin delete", http.MethodDelete, "/books/1/", "admin", http.StatusNoContent},
+		{"reader saves a search", http.MethodPost, "/saved-searches/", "reader", http.StatusCreated},
+		{"reader deletes their search", http.MethodDelete, "/saved-searches/1", "reader", http.StatusNoContent},
+	}
+	for _, c := range cases {
+		var body io.Reader
+		if c.method == http.MethodPost {
+			body = strings.NewReader(`{"title":"Dune","author":"Frank Herbert","isbn":"9780441013593","price":10,"publication_year":1965}`)
+		}
+		req, _ := http.NewRequest(c.method, ts.URL+c.path, body)
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if c.scopes != "" {
+			req.Header.Set("X-User", "ann")
+			req.Header.Set("X-User-Scopes", c.scopes)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if body := readBody(t, res); res.StatusCode != c.want {
+			t.Errorf("%s: status %d, body %s; want %d", c.name, res.StatusCode, body, c.want)
+		} else if c.want == http.StatusForbidden && !contains(body, "requires the editor or admin role") {
+			t.Errorf("%s: body %s", c.name, body)
+		}
+	}
+}
+
+func TestFieldPolicy_HidesPriceFromReaders(t *testing.T) {
+	mock := &mockBookService{
+		GetBookFn: func(ctx context.Context, id int64) (*domain.Book, error) {
+			return &domain.Book{ID: id, Title: "Dune", ISBN: "9780441013593", Price: 10}, nil
+		},
+	}
+	policy, err := presenter.ParseFieldPolicy("price=editor|admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(Identify(true)(NewHandler(mock, WithFieldPolicy(policy)).Router()))
+	defer ts.Close()
+
+	get := func(path, scopes string) (int, string) {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		if scopes != "" {
+			req.Header.Set("X-User", "ann")
+			req.Header.Set("X-User-Scopes", scopes)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.StatusCode, readBody(t, res)
+	}
+	if status, body := get("/books/1/", "reader"); status != http.StatusOK || contains(body, `"price"`) || !contains(body, `"title":"Dune"`) {
+		t.Fatalf("reader: %d %s", status, body)
+	}
+	if status, body := get("/books/1/", ""); status != http.StatusOK || contains(body, `"price"`) {
+		t.Fatalf("anonymous: %d %s", status, body)
+	}
+	if status, body := get("/books/1/", "editor"); status != http.StatusOK || !contains(body, `"price":10`) {
+		t.Fatalf("editor: %d %s", status, body)
+	}
+	if status, body := get("/books/1/label.zpl", "reader"); status != http.StatusForbidden || !contains(body, "price") {
+		t.Fatalf("reader label: %d %s", status, body)
+	}
+	if status, _ := get("/books/1/label.zpl", "admin"); status != http.StatusOK {
+		t.Fatalf("admin label: %d", status)
+	}
+}
@@ -0,0 +1,12 @@
+package http
+
+import "net/http"
+
+// Sandbox marks every response of next with X-Sandbox: true, so partners
+// can tell sandbox data from production at a glance.
+func Sandbox(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Sandbox", "true")
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,34 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/go-chi/chi/v5"
+)
+
+func TestSandbox_MountedUnderPrefix(t *testing.T) {
+	prod := NewHandler(&mockBookService{ListBooksFn: func(ctx context.Context) ([]domain.Book, error) {
+		return []domain.Book{{ID: 1, Title: "Prod"}}, nil
+	}})
+	sandbox := NewHandler(&mockBookService{ListBooksFn: func(ctx context.Context) ([]domain.Book, error) {
+		return []domain.Book{{ID: 1, Title: "Sandbox"}}, nil
+	}})
+	root := chi.NewRouter()
+	root.Mount("/sandbox", Sandbox(sandbox.Router()))
+	root.Mount("/", prod.Router())
+	ts := httptest.NewServer(root)
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodGet, "/sandbox/books/", nil)
+	if body := readBody(t, res); !contains(body, `"title":"Sandbox"`) || res.Header.Get("X-Sandbox") != "true" {
+		t.Fatalf("sandbox: %s (X-Sandbox %q)", body, res.Header.Get("X-Sandbox"))
+	}
+	res = do(t, ts, http.MethodGet, "/books/", nil)
+	if body := readBody(t, res); !contains(body, `"title":"Prod"`) || res.Header.Get("X-Sandbox") != "" {
+		t.Fatalf("prod: %s (X-Sandbox %q)", body, res.Header.Get("X-Sandbox"))
+	}
+}
@@ -0,0 +1,171 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) savedSearchRoutes(r chi.Router) {
+	r.Get("/", h.ListSavedSearches)
+	r.Post("/", h.CreateSavedSearch)
+	r.Get("/{id}", h.GetSavedSearch)
+	r.Delete("/{id}", h.DeleteSavedSearch)
+	r.Get("/{id}/notifications", h.SearchNotifications)
+}
+
+// GET /saved-searches
+// --- ListSavedSearches ---
+// ListSavedSearches godoc
+// @Summary      List your saved searches
+// @Description  Every caller, a user or an API key, sees only the searches it saved; anonymous callers get 403.
+// @Tags         saved-searches
+// @Produce      json
+// @Success      200  {array}   domain.SavedSearch
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /saved-searches/ [get]
+func (h *Handler) ListSavedSearches(w http.ResponseWriter, r *http.Request) {
+	searches, err := h.searches.ListSavedSearches(r.Context())
+	if err != nil {
+		savedSearchError(w, err)
+		return
+	}
+	jsonOK(w, searches)
+}
+
+// POST /saved-searches
+// --- CreateSavedSearch ---
+// CreateSavedSearch godoc
+// @Summary      Save a search
+// @Description  The search belongs to the caller, who alone sees it. The query uses the search language: bare words match title, author and aliases; `author:NAME`, `year:FROM..TO` and `price:MIN..MAX` narrow the result (either end of a range may be left out). Quote values with spaces. With `notify` set, every book added afterwards that matches produces a notification.
+// @Tags         saved-searches
+// @Accept       json
+// @Produce      json
+// @Param        body  body      ports.CreateSavedSearchInput  true  "Saved search"
+// @Success      201   {object}  domain.SavedSearch
+// @Failure      400   {object}  ports.ErrorResponse
+// @Failure      403   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Failure      500   {object}  ports.ErrorResponse
+// @Router       /saved-searches/ [post]
+func (h *Handler) CreateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	var in ports.CreateSavedSearchInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	s, err := h.searches.CreateSavedSearch(r.Context(), in)
+	if err != nil {
+		savedSearchError(w, err)
+		return
+	}
+	jsonCreated(w, s)
+}
+
+// GET /saved-searches/{id}
+// --- GetSavedSearch ---
+// GetSavedSearch godoc
+// @Summary      Get a saved search
+// @Tags         saved-searches
+// @Produce      json
+// @Param        id   path      int  true  "Saved search ID"  minimum(1)
+// @Success      200  {object}  domain.SavedSearch
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /saved-searches/{id} [get]
+func (h *Handler) GetSavedSearch(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	s, err := h.searches.GetSavedSearch(r.Context(), id)
+	if err != nil {
+		savedSearchError(w, err)
+		return
+	}
+	jsonOK(w, s)
+}
+
+// DELETE /saved-searches/{id}
+// --- DeleteSavedSearch ---
+// DeleteSavedSearch godoc
+// @Summary      Delete a saved search and its notifications
+// @Tags         saved-searches
+// @Param        id   path  int  true  "Saved search ID"  minimum(1)
+// @Success      204  "No Content"
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /saved-searches/{id} [delete]
+func (h *Handler) DeleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	if err := h.searches.DeleteSavedSearch(r.Context(), id); err != nil {
+		savedSearchError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /saved-searches/{id}/notifications
+// --- SearchNotifications ---
+// SearchNotifications godoc
+// @Summary      New books that matched a saved search
+// @Description  Newest first. Only searches saved with `notify` receive notifications.
+// @Tags         saved-searches
+// @Produce      json
+// @Param        id     path      int  true   "Saved search ID"  minimum(1)
+// @Param        limit  query     int  false  "Max notifications (default 50, max 200)"  minimum(1)
+// @Success      200    {array}   domain.SearchNotification
+// @Failure      400    {object}  ports.ErrorResponse
+// @Failure      403    {object}  ports.ErrorResponse
+// @Failure      404    {object}  ports.ErrorResponse
+// @Failure      500    {object}  ports.ErrorResponse
+// @Router       /saved-searches/{id}/notifications [get]
+func (h *Handler) SearchNotifications(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			httpError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+	ns, err := h.searches.SearchNotifications(r.Context(), id, limit)
+	if err != nil {
+		savedSearchError(w, err)
+		return
+	}
+	jsonOK(w, ns)
+}
+
+func savedSearchError(w http.ResponseWriter, err error) {
+	if ve, ok := err.(*appsvc.ValidationError); ok {
+		httpValidation(w, ve)
+		return
+	}
+	switch {
+	case errors.Is(err, ports.ErrNoSearchOwner):
+		httpError(w, http.StatusForbidden, err.Error())
+	case err.Error() == "saved search not found":
+		httpError(w, http.StatusNotFound, err.Error())
+	default:
+		httpError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type mockSavedSearchService struct {
+	CreateFn        func(ctx context.Context, in ports.CreateSavedSearchInput) (*domain.SavedSearch, error)
+	ListFn          func(ctx context.Context) ([]domain.SavedSearch, error)
+	GetFn           func(ctx context.Context, id int64) (*domain.SavedSearch, error)
+	DeleteFn        func(ctx context.Context, id int64) error
+	NotificationsFn func(ctx context.Context, id int64, limit int) ([]domain.SearchNotification, error)
+}
+
+func (m *mockSavedSearchService) CreateSavedSearch(ctx context.Context, in ports.CreateSavedSearchInput) (*domain.SavedSearch, error) {
+	return m.CreateFn(ctx, in)
+}
+func (m *mockSavedSearchService) ListSavedSearches(ctx context.Context) ([]domain.SavedSearch, error) {
+	return m.ListFn(ctx)
+}
+func (m *mockSavedSearchService) GetSavedSearch(ctx context.Context, id int64) (*domain.SavedSearch, error) {
+	return m.GetFn(ctx, id)
+}
+func (m *mockSavedSearchService) DeleteSavedSearch(ctx context.Context, id int64) error {
+	return m.DeleteFn(ctx, id)
+}
+func (m *mockSavedSearchService) SearchNotifications(ctx context.Context, id int64, limit int) ([]domain.SearchNotification, error) {
+	return m.NotificationsFn(ctx, id, limit)
+}
+
+func TestSavedSearches_CreateAndNotifications(t *testing.T) {
+	var gotLimit int
+	ts := httptest.NewServer(NewHandler(&mockBookService{}, WithSavedSearches(&mockSavedSearchService{
+		CreateFn: func(ctx context.Context, in ports.CreateSavedSearchInput) (*domain.SavedSearch, error) {
+			if in.Query == "year:x" {
+				return nil, &appsvc.ValidationError{Fields: map[string]string{"query": "Invalid query"}}
+			}
+			return &domain.SavedSearch{ID: 1, Name: in.Name, Query: in.Query, Notify: in.Notify}, nil
+		},
+		NotificationsFn: func(ctx context.Context, id int64, limit int) ([]domain.SearchNotification, error) {
+			gotLimit = limit
+			if id != 1 {
+				return nil, errors.New("saved search not found")
+			}
+			return []domain.SearchNotification{{ID: 4, SearchID: 1, BookID: 7, Title: "Ficciones"}}, nil
+		},
+	})).Router())
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodPost, "/saved-searches/", map[string]any{"name": "Borges", "query": "author:borges", "notify": true})
+	if body := readBody(t, res); res.StatusCode != http.StatusCreated || !contains(body, `"notify":true`) {
+		t.Fatalf("create: %d %s", res.StatusCode, body)
+	}
+	res = do(t, ts, http.MethodPost, "/saved-searches/", map[string]any{"name": "Bad", "query": "year:x"})
+	if body := readBody(t, res); res.StatusCode != http.StatusUnprocessableEntity || !contains(body, "query") {
+		t.Fatalf("invalid: %d %s", res.StatusCode, body)
+	}
+
+	res = do(t, ts, http.MethodGet, "/saved-searches/1/notifications?limit=5", nil)
+	if body := readBody(t, res); res.StatusCode != http.StatusOK || !contains(body, `"book_id":7`) || gotLimit != 5 {
+		t.Fatalf("notifications: %d %s (limit %d)", res.StatusCode, body, gotLimit)
+	}
+	res = do(t, ts, http.MethodGet, "/saved-searches/2/notifications", nil)
+	if readBody(t, res); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("missing search: %d", res.StatusCode)
+	}
+	res = do(t, ts, http.MethodGet, "/saved-searches/1/notifications?limit=0", nil)
+	if readBody(t, res); res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("bad limit: %d", res.StatusCode)
+	}
+}
+
+func TestSavedSearches_NotMountedWithoutOption(t *testing.T) {
+	ts := newTestServer(t, &mockBookService{})
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodGet, "/saved-searches/", nil)
+	if readBody(t, res); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", res.StatusCode)
+	}
+}
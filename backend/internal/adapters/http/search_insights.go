@@ -0,0 +1,96 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type searchFeedbackRequest struct {
+	Q      string `json:"q" example:"garcia marquez"`
+	BookID int64  `json:"book_id" example:"42"`
+}
+
+// POST /search/feedback
+// --- SearchFeedback ---
+// SearchFeedback godoc
+// @Summary      Report an opened search result
+// @Description  Clients call this when a user opens book book_id from the results of a search for q, so GET /admin/search/insights can report click-throughs. Counts are kept per query, folded like searches, and flushed every few seconds.
+// @Tags         books
+// @Accept       json
+// @Param        request  body      searchFeedbackRequest  true  "Search and the book opened"
+// @Success      204      "No Content"
+// @Failure      400      {object}  ports.ErrorResponse
+// @Failure      422      {object}  validationPayload
+// @Router       /search/feedback [post]
+func (h *Handler) SearchFeedback(w http.ResponseWriter, r *http.Request) {
+	var req searchFeedbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if err := h.insights.RecordClick(req.Q, req.BookID); err != nil {
+		var ve *appsvc.ValidationError
+		if errors.As(err, &ve) {
+			httpValidation(w, ve)
+			return
+		}
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /admin/search/insights
+// --- SearchInsights ---
+// SearchInsights godoc
+// @Summary      Search query report
+// @Description  Book searches (GET /books/?q=, first pages only) over the last days days (today included): totals, the share that found nothing and the clicks per search reported to POST /search/feedback, the top most searched queries and the top queries that most often found nothing. Queries are folded for case and accents. Counts are flushed every few seconds, so the latest searches may be missing. Requires the admin scope.
+// @Tags         books
+// @Produce      json
+// @Param        days  query     int  false  "Days to report on, 1-90"  default(7)
+// @Param        top   query     int  false  "Queries to list, 1-100"   default(10)
+// @Success      200   {object}  domain.SearchInsights
+// @Failure      400   {object}  ports.ErrorResponse
+// @Failure      403   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Failure      500   {object}  ports.ErrorResponse
+// @Router       /admin/search/insights [get]
+func (h *Handler) SearchInsights(w http.ResponseWriter, r *http.Request) {
+	days, top := 7, 10
+	for name, dst := range map[string]*int{"days": &days, "top": &top} {
+		if v := r.URL.Query().Get(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				httpError(w, http.StatusBadRequest, name+" must be an integer")
+				return
+			}
+			*dst = n
+		}
+	}
+	insights, err := h.insights.SearchInsights(r.Context(), days, top)
+	if err != nil {
+		var ve *appsvc.ValidationError
+		if errors.As(err, &ve) {
+			httpValidation(w, ve)
+			return
+		}
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, insights)
+}
+
+// recordSearch counts a search in the search insights, if enabled. Only
+// first pages count, so paging through results isn't counted as searching
+// again.
+func (h *Handler) recordSearch(f ports.ListFilter, page ports.Page, total int) {
+	if h.insights == nil || f.Q == "" || page.Offset > 0 {
+		return
+	}
+	h.insights.Record(f.Q, total)
+}
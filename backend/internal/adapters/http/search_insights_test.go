@@ -0,0 +1,100 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/docs"
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type mockSearchInsights struct {
+	recorded []string
+}
+
+func (m *mockSearchInsights) Record(q string, results int) {
+	m.recorded = append(m.recorded, q+" "+strings.Repeat("+", results))
+}
+
+func (m *mockSearchInsights) RecordClick(q string, bookID int64) error {
+	if bookID < 1 {
+		return &appsvc.ValidationError{Fields: map[string]string{"book_id": "Book ID must be a positive integer"}}
+	}
+	m.recorded = append(m.recorded, "click "+q)
+	return nil
+}
+
+func (m *mockSearchInsights) SearchInsights(ctx context.Context, days, top int) (*domain.SearchInsights, error) {
+	if days > appsvc.MaxSearchInsightsDays {
+		return nil, &appsvc.ValidationError{Fields: map[string]string{"days": "days must be between 1 and 90"}}
+	}
+	return &domain.SearchInsights{
+		SearchCounts: domain.SearchCounts{Searches: 4, ZeroResults: 1, Clicks: 2}, ZeroResultRate: 0.25, ClickThroughRate: 0.5,
+		TopQueries:        []domain.SearchQueryCount{{Query: "dune", SearchCounts: domain.SearchCounts{Searches: 3, Clicks: 2}, ClickThroughRate: 2.0 / 3}},
+		ZeroResultQueries: []domain.SearchQueryCount{{Query: "unicorn", SearchCounts: domain.SearchCounts{Searches: 1, ZeroResults: 1}}},
+	}, nil
+}
+
+func TestSearchInsights(t *testing.T) {
+	insights := &mockSearchInsights{}
+	svc := &mockBookService{
+		SearchBooksFn: func(ctx context.Context, q string) ([]domain.Book, error) {
+			if q == "dune" {
+				return []domain.Book{{ID: 1, Title: "Dune", Author: "Frank Herbert", PublicationYear: 1965}}, nil
+			}
+			return []domain.Book{}, nil
+		},
+		ListPageFn: func(ctx context.Context, f ports.ListFilter, page ports.Page) (*ports.BookPage, error) {
+			return &ports.BookPage{Books: []domain.Book{}, Total: 12}, nil
+		},
+	}
+	v, err := NewSpecValidator([]byte(docs.SwaggerInfo.ReadDoc()))
+	if err != nil {
+		t.Fatalf("NewSpecValidator: %v", err)
+	}
+	ts := httptest.NewServer(Identify(true)(v.Middleware(NewHandler(svc, WithSearchInsights(insights)).Router())))
+	defer ts.Close()
+
+	do(t, ts, http.MethodGet, "/books/?q=dune", nil)
+	do(t, ts, http.MethodGet, "/books/?q=unicorn", nil)
+	do(t, ts, http.MethodGet, "/books/?q=dune&limit=5&offset=5", nil) // a later page isn't a new search
+	do(t, ts, http.MethodGet, "/books/", nil)
+	if res := do(t, ts, http.MethodPost, "/search/feedback", map[string]any{"q": "dune", "book_id": 1}); res.StatusCode != http.StatusNoContent {
+		t.Fatalf("feedback: %d %s", res.StatusCode, readBody(t, res))
+	}
+	if res := do(t, ts, http.MethodPost, "/search/feedback", map[string]any{"q": "dune", "book_id": 0}); res.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("feedback without a book: %d", res.StatusCode)
+	}
+	if got := strings.Join(insights.recorded, ", "); got != "dune +, unicorn , click dune" {
+		t.Fatalf("recorded = %q", got)
+	}
+
+	call := func(path, scopes string) (int, string) {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		req.Header.Set("X-User", "catalog")
+		req.Header.Set("X-User-Scopes", scopes)
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("do request: %v", err)
+		}
+		return res.StatusCode, readBody(t, res)
+	}
+	if code, _ := call("/admin/search/insights", ""); code != http.StatusForbidden {
+		t.Fatalf("non-admin: %d", code)
+	}
+	if code, body := call("/admin/search/insights?days=7&top=5", "admin"); code != http.StatusOK ||
+		!contains(body, `"zero_result_queries":[{"query":"unicorn","searches":1,"zero_results":1,"clicks":0,"click_through_rate":0}]`) {
+		t.Fatalf("insights: %d %s", code, body)
+	}
+	if code, _ := call("/admin/search/insights?top=x", "admin"); code != http.StatusBadRequest {
+		t.Fatalf("top=x: %d", code)
+	}
+	if code, body := call("/admin/search/insights?days=365", "admin"); code != http.StatusUnprocessableEntity || !contains(body, `"days"`) {
+		t.Fatalf("days=365: %d %s", code, body)
+	}
+}
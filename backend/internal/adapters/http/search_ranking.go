@@ -0,0 +1,142 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) searchRankingRoutes(r chi.Router) {
+	r.Use(requireScope(domain.ScopeAdmin))
+	r.Get("/", h.ListSearchRankings)
+	r.Get("/{tenant}", h.GetSearchRanking)
+	r.Put("/{tenant}", h.PutSearchRanking)
+	r.Delete("/{tenant}", h.DeleteSearchRanking)
+}
+
+// GET /admin/search/ranking
+// --- ListSearchRankings ---
+// ListSearchRankings godoc
+// @Summary      List search rankings
+// @Description  The default ranking and every tenant with its own. Searches (GET /books/ with q and no sort) rank books by score: the title weight when q is in the title or an alias, plus the author weight when it is in the author, plus the recency boost divided by one plus the book's age in years. Equal scores go newest first. Requires the admin scope.
+// @Tags         admin
+// @Produce      json
+// @Success      200  {object}  ports.SearchRankings
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /admin/search/ranking/ [get]
+func (h *Handler) ListSearchRankings(w http.ResponseWriter, r *http.Request) {
+	rankings, err := h.ranking.ListSearchRankings(r.Context())
+	if err != nil {
+		searchRankingError(w, err)
+		return
+	}
+	jsonOK(w, rankings)
+}
+
+// GET /admin/search/ranking/{tenant}
+// --- GetSearchRanking ---
+// GetSearchRanking godoc
+// @Summary      Get a tenant's search ranking
+// @Description  The tenant is the identity searches run as: an API key (key:NAME) or a user. 404 when it ranks with the defaults. Requires the admin scope.
+// @Tags         admin
+// @Produce      json
+// @Param        tenant  path      string  true  "Tenant"
+// @Success      200     {object}  domain.SearchRanking
+// @Failure      403     {object}  ports.ErrorResponse
+// @Failure      404     {object}  ports.ErrorResponse
+// @Failure      500     {object}  ports.ErrorResponse
+// @Router       /admin/search/ranking/{tenant} [get]
+func (h *Handler) GetSearchRanking(w http.ResponseWriter, r *http.Request) {
+	s, err := h.ranking.GetSearchRanking(r.Context(), chi.URLParam(r, "tenant"))
+	if err != nil {
+		searchRankingError(w, err)
+		return
+	}
+	jsonOK(w, s)
+}
+
+// PUT /admin/search/ranking/{tenant}
+// --- PutSearchRanking ---
+// PutSearchRanking godoc
+// @Summary      Replace a tenant's search ranking
+// @Description  Weights range from 0 to 10; omitted ones take their default. Searches use the new ranking right away on this server, and within a minute on the others. Requires the admin scope.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        tenant  path      string                       true  "Tenant"
+// @Param        body    body      ports.PutSearchRankingInput  true  "Weights"
+// @Success      200     {object}  domain.SearchRanking
+// @Failure      400     {object}  ports.ErrorResponse
+// @Failure      403     {object}  ports.ErrorResponse
+// @Failure      422     {object}  validationPayload
+// @Failure      500     {object}  ports.ErrorResponse
+// @Router       /admin/search/ranking/{tenant} [put]
+func (h *Handler) PutSearchRanking(w http.ResponseWriter, r *http.Request) {
+	var in ports.PutSearchRankingInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	s, err := h.ranking.PutSearchRanking(r.Context(), chi.URLParam(r, "tenant"), in)
+	if err != nil {
+		searchRankingError(w, err)
+		return
+	}
+	jsonOK(w, s)
+}
+
+// DELETE /admin/search/ranking/{tenant}
+// --- DeleteSearchRanking ---
+// DeleteSearchRanking godoc
+// @Summary      Delete a tenant's search ranking
+// @Description  The tenant's searches go back to the default ranking. Requires the admin scope.
+// @Tags         admin
+// @Param        tenant  path  string  true  "Tenant"
+// @Success      204  "No Content"
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /admin/search/ranking/{tenant} [delete]
+func (h *Handler) DeleteSearchRanking(w http.ResponseWriter, r *http.Request) {
+	if err := h.ranking.DeleteSearchRanking(r.Context(), chi.URLParam(r, "tenant")); err != nil {
+		searchRankingError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func searchRankingError(w http.ResponseWriter, err error) {
+	if ve, ok := err.(*appsvc.ValidationError); ok {
+		httpValidation(w, ve)
+		return
+	}
+	switch err.Error() {
+	case "search ranking not found":
+		httpError(w, http.StatusNotFound, err.Error())
+	default:
+		httpError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// applySearchRanking ranks a search that leaves its order to the server,
+// after list preferences had their say, with the caller's ranking. Searches
+// fall back to the default ranking when the caller's can't be read.
+func (h *Handler) applySearchRanking(ctx context.Context, f ports.ListFilter, page *ports.Page) {
+	if h.ranking == nil || strings.TrimSpace(f.Q) == "" || page.Sort.Field != "" {
+		return
+	}
+	weights, err := h.ranking.For(ctx)
+	if err != nil {
+		logger.From(ctx).Warn("failed to read search ranking", "error", err)
+		weights = domain.DefaultRankingWeights()
+	}
+	page.Ranking = &ports.Ranking{RankingWeights: weights, Year: h.now().UTC().Year()}
+}
@@ -0,0 +1,126 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/docs"
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type mapSearchRankingRepo map[string]domain.SearchRanking
+
+func (m mapSearchRankingRepo) Get(ctx context.Context, tenant string) (*domain.SearchRanking, error) {
+	if s, ok := m[tenant]; ok {
+		return &s, nil
+	}
+	return nil, nil
+}
+func (m mapSearchRankingRepo) List(ctx context.Context) ([]domain.SearchRanking, error) {
+	out := []domain.SearchRanking{}
+	for _, s := range m {
+		out = append(out, s)
+	}
+	slices.SortFunc(out, func(a, b domain.SearchRanking) int { return strings.Compare(a.Tenant, b.Tenant) })
+	return out, nil
+}
+func (m mapSearchRankingRepo) Put(ctx context.Context, s *domain.SearchRanking) error {
+	m[s.Tenant] = *s
+	return nil
+}
+func (m mapSearchRankingRepo) Delete(ctx context.Context, tenant string) (bool, error) {
+	_, ok := m[tenant]
+	delete(m, tenant)
+	return ok, nil
+}
+
+func TestSearchRanking(t *testing.T) {
+	var got ports.Page
+	mock := &mockBookService{
+		ListPageFn: func(ctx context.Context, f ports.ListFilter, page ports.Page) (*ports.BookPage, error) {
+			got = page
+			return &ports.BookPage{Books: []domain.Book{}}, nil
+		},
+	}
+	v, err := NewSpecValidator([]byte(docs.SwaggerInfo.ReadDoc()))
+	if err != nil {
+		t.Fatalf("NewSpecValidator: %v", err)
+	}
+	rankings := appsvc.NewSearchRankings(mapSearchRankingRepo{}, time.Minute)
+	now := func() time.Time { return time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC) }
+	h := NewHandler(mock, WithSearchRanking(rankings), WithClock(now))
+	ts := httptest.NewServer(Identify(true)(v.Middleware(h.Router())))
+	defer ts.Close()
+
+	call := func(method, path, user, scopes string, body any) (int, string) {
+		t.Helper()
+		var r io.Reader
+		if body != nil {
+			b, _ := json.Marshal(body)
+			r = bytes.NewReader(b)
+		}
+		req, _ := http.NewRequest(method, ts.URL+path, r)
+		req.Header.Set("Content-Type", "application/json")
+		if user != "" {
+			req.Header.Set("X-User", user)
+			req.Header.Set("X-User-Scopes", scopes)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("do request: %v", err)
+		}
+		return res.StatusCode, readBody(t, res)
+	}
+
+	if code, _ := call(http.MethodPut, "/admin/search/ranking/key:storefront", "ops", "", map[string]any{"title_weight": 1}); code != http.StatusForbidden {
+		t.Fatalf("non-admin PUT = %d; want 403", code)
+	}
+	if code, body := call(http.MethodPut, "/admin/search/ranking/key:storefront", "ops", "admin", map[string]any{"title_weight": 11}); code != http.StatusUnprocessableEntity || !contains(body, "title_weight") {
+		t.Fatalf("out of range PUT = %d %s", code, body)
+	}
+	if code, body := call(http.MethodPut, "/admin/search/ranking/key:storefront", "ops", "admin", map[string]any{"title_weight": 1, "author_weight": 3}); code != http.StatusOK || !contains(body, `"recency_boost":0`) {
+		t.Fatalf("PUT = %d %s", code, body)
+	}
+	if code, body := call(http.MethodGet, "/admin/search/ranking/", "ops", "admin", nil); code != http.StatusOK || !contains(body, `"defaults":{"title_weight":2,"author_weight":1`) || !contains(body, `"tenant":"key:storefront"`) {
+		t.Fatalf("list = %d %s", code, body)
+	}
+
+	storefront := &ports.Ranking{RankingWeights: domain.RankingWeights{TitleWeight: 1, AuthorWeight: 3}, Year: 2026}
+	defaults := &ports.Ranking{RankingWeights: domain.DefaultRankingWeights(), Year: 2026}
+	for _, tc := range []struct {
+		path, user string
+		want       *ports.Ranking
+	}{
+		{"/books/?q=tolkien", "key:storefront", storefront},
+		{"/books/?q=tolkien&limit=5", "key:storefront", storefront},
+		{"/books/?q=tolkien", "admin-ui", defaults},
+		{"/books/?q=tolkien", "", defaults},
+		{"/books/?q=tolkien&sort=title", "key:storefront", nil},
+		{"/books/?author=Tolkien", "key:storefront", nil},
+	} {
+		got = ports.Page{}
+		if code, body := call(http.MethodGet, tc.path, tc.user, "", nil); code != http.StatusOK {
+			t.Fatalf("%s as %q = %d %s", tc.path, tc.user, code, body)
+		}
+		if (got.Ranking == nil) != (tc.want == nil) || got.Ranking != nil && *got.Ranking != *tc.want {
+			t.Errorf("%s as %q: ranking = %+v; want %+v", tc.path, tc.user, got.Ranking, tc.want)
+		}
+	}
+
+	if code, _ := call(http.MethodDelete, "/admin/search/ranking/key:storefront", "ops", "admin", nil); code != http.StatusNoContent {
+		t.Fatalf("DELETE = %d", code)
+	}
+	if code, _ := call(http.MethodGet, "/admin/search/ranking/key:storefront", "ops", "admin", nil); code != http.StatusNotFound {
+		t.Fatalf("GET after DELETE = %d; want 404", code)
+	}
+}
@@ -0,0 +1,244 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/gerry-sabar/byfood/internal/presenter"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) seriesRoutes(r chi.Router) {
+	edit := h.requireEditor
+	r.Get("/", h.ListSeries)
+	r.With(edit).Post("/", h.CreateSeries)
+	r.Get("/{id}", h.GetSeries)
+	r.With(edit).Put("/{id}", h.UpdateSeries)
+	r.With(edit).Delete("/{id}", h.DeleteSeries)
+	r.Get("/{id}/books", h.ListSeriesBooks)
+}
+
+// GET /series
+// --- ListSeries ---
+// ListSeries godoc
+// @Summary      List series
+// @Description  Ordered by name.
+// @Tags         series
+// @Produce      json
+// @Success      200  {array}   domain.Series
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /series/ [get]
+func (h *Handler) ListSeries(w http.ResponseWriter, r *http.Request) {
+	series, err := h.series.ListSeries(r.Context())
+	if err != nil {
+		seriesError(w, err)
+		return
+	}
+	jsonOK(w, series)
+}
+
+// POST /series
+// --- CreateSeries ---
+// CreateSeries godoc
+// @Summary      Add a series
+// @Description  Names are unique, ignoring case and accents. Books join a series with `series_id` and `series_position` on create or update.
+// @Tags         series
+// @Accept       json
+// @Produce      json
+// @Param        body  body      ports.SeriesInput  true  "Series"
+// @Success      201   {object}  domain.Series
+// @Failure      400   {object}  ports.ErrorResponse
+// @Failure      403   {object}  ports.ErrorResponse
+// @Failure      409   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Failure      500   {object}  ports.ErrorResponse
+// @Router       /series/ [post]
+func (h *Handler) CreateSeries(w http.ResponseWriter, r *http.Request) {
+	var in ports.SeriesInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	s, err := h.series.CreateSeries(r.Context(), in)
+	if err != nil {
+		seriesError(w, err)
+		return
+	}
+	jsonCreated(w, s)
+}
+
+// GET /series/{id}
+// --- GetSeries ---
+// GetSeries godoc
+// @Summary      Get a series
+// @Tags         series
+// @Produce      json
+// @Param        id   path      int  true  "Series ID"  minimum(1)
+// @Success      200  {object}  domain.Series
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /series/{id} [get]
+func (h *Handler) GetSeries(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	s, err := h.series.GetSeries(r.Context(), id)
+	if err != nil {
+		seriesError(w, err)
+		return
+	}
+	jsonOK(w, s)
+}
+
+// PUT /series/{id}
+// --- UpdateSeries ---
+// UpdateSeries godoc
+// @Summary      Rename a series
+// @Tags         series
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int                true  "Series ID"  minimum(1)
+// @Param        body  body      ports.SeriesInput  true  "Series"
+// @Success      200   {object}  domain.Series
+// @Failure      400   {object}  ports.ErrorResponse
+// @Failure      403   {object}  ports.ErrorResponse
+// @Failure      404   {object}  ports.ErrorResponse
+// @Failure      409   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Failure      500   {object}  ports.ErrorResponse
+// @Router       /series/{id} [put]
+func (h *Handler) UpdateSeries(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	var in ports.SeriesInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	s, err := h.series.UpdateSeries(r.Context(), id, in)
+	if err != nil {
+		seriesError(w, err)
+		return
+	}
+	jsonOK(w, s)
+}
+
+// DELETE /series/{id}
+// --- DeleteSeries ---
+// DeleteSeries godoc
+// @Summary      Delete a series
+// @Description  A series with books is kept (409, code `series_in_use`) unless `detach=true`, which takes its books out of it, clearing their positions, in the same transaction.
+// @Tags         series
+// @Param        id      path   int   true   "Series ID"  minimum(1)
+// @Param        detach  query  bool  false  "Take the books out of the series"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      409  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /series/{id} [delete]
+func (h *Handler) DeleteSeries(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	detach := false
+	if v := r.URL.Query().Get("detach"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			httpError(w, http.StatusBadRequest, "detach must be true or false")
+			return
+		}
+		detach = b
+	}
+	if err := h.series.DeleteSeries(r.Context(), id, detach); err != nil {
+		seriesError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /series/{id}/books
+// --- ListSeriesBooks ---
+// ListSeriesBooks godoc
+// @Summary      List the books of a series in order
+// @Description  By `series_position`, books without one last, then by publication year. Editions of the same work share a position.
+// @Tags         series
+// @Produce      json
+// @Param        id   path      int  true  "Series ID"  minimum(1)
+// @Success      200  {array}   presenter.BookView
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /series/{id}/books [get]
+func (h *Handler) ListSeriesBooks(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	books, err := h.series.ListSeriesBooks(r.Context(), id)
+	if err != nil {
+		seriesError(w, err)
+		return
+	}
+	jsonOK(w, h.presentBooks(r, books))
+}
+
+// GET /books/{id}/editions
+// --- ListEditions ---
+// ListEditions godoc
+// @Summary      List the editions of a book's work
+// @Description  Every book sharing the work of book id, the book itself included, by publication year and then format. A book joins a work with `work_id` set to the id of any of its editions; `POST /books/{id}/split` creates one.
+// @Tags         series
+// @Produce      json
+// @Param        id   path      int  true  "Book ID"  minimum(1)
+// @Success      200  {object}  editionsResponse
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /books/{id}/editions [get]
+func (h *Handler) ListEditions(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.series.ListEditions(r.Context(), id)
+	if err != nil {
+		seriesError(w, err)
+		return
+	}
+	jsonOK(w, editionsResponse{WorkID: res.WorkID, Editions: h.presentBooks(r, res.Books)})
+}
+
+type editionsResponse struct {
+	// WorkID is the work_id the editions share; a book without other
+	// editions is its own work.
+	WorkID   int64                `json:"work_id"`
+	Editions []presenter.BookView `json:"editions"`
+}
+
+func seriesError(w http.ResponseWriter, err error) {
+	if ve, ok := err.(*appsvc.ValidationError); ok {
+		httpValidation(w, ve)
+		return
+	}
+	switch {
+	case err.Error() == "series not found", err.Error() == "book not found":
+		httpError(w, http.StatusNotFound, "not found")
+	case errors.Is(err, ports.ErrSeriesNameTaken):
+		httpError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, ports.ErrSeriesInUse):
+		httpErrorCode(w, http.StatusConflict, "series_in_use", err.Error()+"; delete with detach=true to take them out of it")
+	default:
+		httpError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,69 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type mockSeriesService struct {
+	ports.SeriesService
+	DeleteFn   func(ctx context.Context, id int64, detach bool) error
+	EditionsFn func(ctx context.Context, id int64) (*ports.Editions, error)
+}
+
+func (m *mockSeriesService) DeleteSeries(ctx context.Context, id int64, detach bool) error {
+	return m.DeleteFn(ctx, id, detach)
+}
+func (m *mockSeriesService) ListEditions(ctx context.Context, id int64) (*ports.Editions, error) {
+	return m.EditionsFn(ctx, id)
+}
+
+func TestSeries_DeleteAndEditions(t *testing.T) {
+	ts := httptest.NewServer(NewHandler(&mockBookService{}, WithSeries(&mockSeriesService{
+		DeleteFn: func(ctx context.Context, id int64, detach bool) error {
+			if !detach {
+				return ports.ErrSeriesInUse
+			}
+			return nil
+		},
+		EditionsFn: func(ctx context.Context, id int64) (*ports.Editions, error) {
+			if id != 2 {
+				return nil, errors.New("book not found")
+			}
+			root := int64(1)
+			return &ports.Editions{WorkID: 1, Books: []domain.Book{
+				{ID: 1, Title: "Dune", Format: "hardcover"},
+				{ID: 2, Title: "Dune", Format: "ebook", WorkID: &root},
+			}}, nil
+		},
+	})).Router())
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodDelete, "/series/3", nil)
+	if body := readBody(t, res); res.StatusCode != http.StatusConflict || !contains(body, `"code":"series_in_use"`) {
+		t.Fatalf("in use: %d %s", res.StatusCode, body)
+	}
+	res = do(t, ts, http.MethodDelete, "/series/3?detach=true", nil)
+	if readBody(t, res); res.StatusCode != http.StatusNoContent {
+		t.Fatalf("detach: %d", res.StatusCode)
+	}
+
+	res = do(t, ts, http.MethodGet, "/books/2/editions", nil)
+	if body := readBody(t, res); res.StatusCode != http.StatusOK || !contains(body, `"work_id":1`) || !contains(body, `"format":"ebook"`) {
+		t.Fatalf("editions: %d %s", res.StatusCode, body)
+	}
+	res = do(t, ts, http.MethodGet, "/books/9/editions", nil)
+	if readBody(t, res); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("missing book: %d", res.StatusCode)
+	}
+	res = do(t, ts, http.MethodGet, "/.well-known/api-capabilities", nil)
+	if body := readBody(t, res); !contains(body, `"series"`) {
+		t.Fatalf("capabilities: %s", body)
+	}
+}
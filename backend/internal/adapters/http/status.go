@@ -0,0 +1,28 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// GET /status
+// --- Status ---
+// Status godoc
+// @Summary      Health of every dependency
+// @Description  Checks each dependency the API uses (MySQL, the sandbox database, the NATS and Kafka brokers, background workers) at once, each within 2 seconds, and reports whether it is up, how long the check took, and the last error this replica saw, kept after the dependency recovers. Answers 503 with the same body when any dependency is down. Requires the admin scope.
+// @Tags         admin
+// @Produce      json
+// @Success      200  {object}  domain.SystemStatus
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      503  {object}  domain.SystemStatus
+// @Router       /status [get]
+func (h *Handler) Status(w http.ResponseWriter, r *http.Request) {
+	s := h.status.Status(r.Context())
+	if s.Status != "ok" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(s)
+		return
+	}
+	jsonOK(w, s)
+}
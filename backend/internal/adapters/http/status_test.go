@@ -0,0 +1,57 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/docs"
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+type mockStatus struct{ down bool }
+
+func (m *mockStatus) Status(ctx context.Context) domain.SystemStatus {
+	s := domain.SystemStatus{Status: "ok", CheckedAt: time.Now(),
+		Dependencies: []domain.DependencyStatus{{Name: "mysql", Status: domain.DependencyUp, LatencyMS: 1.5}}}
+	if m.down {
+		at := time.Now()
+		s.Status = "degraded"
+		s.Dependencies[0] = domain.DependencyStatus{Name: "mysql", Status: domain.DependencyDown, LatencyMS: 2000,
+			Error: "context deadline exceeded", LastError: "context deadline exceeded", LastErrorAt: &at}
+	}
+	return s
+}
+
+func TestStatus(t *testing.T) {
+	status := &mockStatus{}
+	v, err := NewSpecValidator([]byte(docs.SwaggerInfo.ReadDoc()))
+	if err != nil {
+		t.Fatalf("NewSpecValidator: %v", err)
+	}
+	ts := httptest.NewServer(Identify(true)(v.Middleware(NewHandler(&mockBookService{}, WithStatus(status)).Router())))
+	defer ts.Close()
+
+	call := func(scopes string) (int, string) {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+"/status", nil)
+		req.Header.Set("X-User", "ops")
+		req.Header.Set("X-User-Scopes", scopes)
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("do request: %v", err)
+		}
+		return res.StatusCode, readBody(t, res)
+	}
+	if code, _ := call(""); code != http.StatusForbidden {
+		t.Fatalf("without admin = %d; want 403", code)
+	}
+	if code, body := call("admin"); code != http.StatusOK || !contains(body, `"latency_ms":1.5`) {
+		t.Fatalf("healthy = %d %s", code, body)
+	}
+	status.down = true
+	if code, body := call("admin"); code != http.StatusServiceUnavailable || !contains(body, `"last_error":"context deadline exceeded"`) {
+		t.Fatalf("degraded = %d %s", code, body)
+	}
+}
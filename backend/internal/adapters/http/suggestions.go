@@ -0,0 +1,47 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// headerSuggestions carries what a search that found nothing may have
+// meant: the suggestions best first, each percent-encoded, separated by
+// commas. Enveloped responses also get them as meta.suggestions.
+const headerSuggestions = "X-Search-Suggestions"
+
+// suggest sets the suggestions header for a search that found nothing. The
+// list is sent without it when the suggestions can't be read.
+func (h *Handler) suggest(w http.ResponseWriter, r *http.Request, f ports.ListFilter) {
+	if h.suggester == nil || strings.TrimSpace(f.Q) == "" {
+		return
+	}
+	suggestions, err := h.suggester.Suggest(r.Context(), f.Q)
+	if err != nil {
+		logger.From(r.Context()).Warn("failed to suggest searches", "q", f.Q, "error", err)
+		return
+	}
+	if len(suggestions) == 0 {
+		return
+	}
+	escaped := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		escaped[i] = url.PathEscape(s)
+	}
+	w.Header().Set(headerSuggestions, strings.Join(escaped, ", "))
+}
+
+// parseSuggestions reads the suggestions header back.
+func parseSuggestions(v string) []string {
+	var out []string
+	for _, s := range strings.Split(v, ",") {
+		if s, err := url.PathUnescape(strings.TrimSpace(s)); err == nil && s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type stubSuggester struct {
+	err error
+}
+
+func (s stubSuggester) Suggest(ctx context.Context, q string) ([]string, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []string{"Harry Potter, Part 1", "J. K. Rowling"}, nil
+}
+
+func TestListBooks_Suggestions(t *testing.T) {
+	svc := &mockBookService{
+		SearchBooksFn: func(ctx context.Context, q string) ([]domain.Book, error) {
+			if q == "dune" {
+				return []domain.Book{{ID: 1, Title: "Dune", Author: "Frank Herbert", PublicationYear: 1965}}, nil
+			}
+			return []domain.Book{}, nil
+		},
+		ListPageFn: func(ctx context.Context, f ports.ListFilter, page ports.Page) (*ports.BookPage, error) {
+			return &ports.BookPage{Books: []domain.Book{}}, nil
+		},
+	}
+	ts := newSpecServer(t, svc, WithSuggestions(stubSuggester{}))
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodGet, "/books/?q=harry+pottr", nil)
+	if body := readBody(t, res); res.StatusCode != http.StatusOK || body != "[]\n" ||
+		res.Header.Get(headerSuggestions) != "Harry%20Potter%2C%20Part%201, J.%20K.%20Rowling" {
+		t.Fatalf("zero results = %d %q %q", res.StatusCode, body, res.Header.Get(headerSuggestions))
+	}
+	if res := do(t, ts, http.MethodGet, "/books/?q=dune", nil); res.Header.Get(headerSuggestions) != "" {
+		t.Fatalf("a search with results got suggestions")
+	}
+	if res := do(t, ts, http.MethodGet, "/books/?q=harry+pottr&limit=5", nil); res.Header.Get(headerSuggestions) == "" {
+		t.Fatalf("a paged search without results got no suggestions")
+	}
+
+	env := httptest.NewServer(Envelope(false)(NewHandler(svc, WithSuggestions(stubSuggester{})).Router()))
+	defer env.Close()
+	_, e := getEnvelope(t, env, http.MethodGet, "/books/?q=harry+pottr", "true")
+	if !slices.Equal(e.Meta.Suggestions, []string{"Harry Potter, Part 1", "J. K. Rowling"}) || string(e.Data) != "[]" {
+		t.Fatalf("enveloped = %+v", e)
+	}
+
+	down := newSpecServer(t, svc, WithSuggestions(stubSuggester{err: errors.New("db down")}))
+	defer down.Close()
+	if res := do(t, down, http.MethodGet, "/books/?q=harry+pottr", nil); res.StatusCode != http.StatusOK || res.Header.Get(headerSuggestions) != "" {
+		t.Fatalf("failed suggestions = %d %q", res.StatusCode, res.Header.Get(headerSuggestions))
+	}
+}
@@ -0,0 +1,174 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) synonymRoutes(r chi.Router) {
+	r.Use(requireScope(domain.ScopeAdmin))
+	r.Get("/", h.ListSynonyms)
+	r.Post("/", h.CreateSynonyms)
+	r.Get("/{id}", h.GetSynonyms)
+	r.Put("/{id}", h.UpdateSynonyms)
+	r.Delete("/{id}", h.DeleteSynonyms)
+}
+
+// GET /admin/search/synonyms
+// --- ListSynonyms ---
+// ListSynonyms godoc
+// @Summary      List synonym groups
+// @Description  The synonym dictionary, oldest group first. Searches (GET /books/ with q, and GET /books/search) also find the books matching q with each term of a group among its words swapped for the others: with "programming" and "coding" in a group, "programming in go" finds "Coding in Go" too. Terms match whole words, ignoring case and accents; exact=true searches don't use synonyms. Requires the admin scope.
+// @Tags         admin
+// @Produce      json
+// @Success      200  {array}   domain.SynonymGroup
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /admin/search/synonyms/ [get]
+func (h *Handler) ListSynonyms(w http.ResponseWriter, r *http.Request) {
+	groups, err := h.synonyms.ListSynonyms(r.Context())
+	if err != nil {
+		synonymError(w, err)
+		return
+	}
+	jsonOK(w, groups)
+}
+
+// POST /admin/search/synonyms
+// --- CreateSynonyms ---
+// CreateSynonyms godoc
+// @Summary      Add a synonym group
+// @Description  A group has 2 to 20 terms of up to 64 characters, which must differ ignoring case and accents. A term belongs to one group only: 409 with code "synonym_taken" when another group has one. Searches use the change right away on this server, and within a minute on the others. Requires the admin scope.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        body  body      ports.SynonymInput  true  "Terms"
+// @Success      201   {object}  domain.SynonymGroup
+// @Failure      400   {object}  ports.ErrorResponse
+// @Failure      403   {object}  ports.ErrorResponse
+// @Failure      409   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Failure      500   {object}  ports.ErrorResponse
+// @Router       /admin/search/synonyms/ [post]
+func (h *Handler) CreateSynonyms(w http.ResponseWriter, r *http.Request) {
+	var in ports.SynonymInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	g, err := h.synonyms.CreateSynonyms(r.Context(), in)
+	if err != nil {
+		synonymError(w, err)
+		return
+	}
+	jsonCreated(w, g)
+}
+
+// GET /admin/search/synonyms/{id}
+// --- GetSynonyms ---
+// GetSynonyms godoc
+// @Summary      Get a synonym group
+// @Description  Requires the admin scope.
+// @Tags         admin
+// @Produce      json
+// @Param        id   path      int  true  "Synonym group ID"  minimum(1)
+// @Success      200  {object}  domain.SynonymGroup
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /admin/search/synonyms/{id} [get]
+func (h *Handler) GetSynonyms(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	g, err := h.synonyms.GetSynonyms(r.Context(), id)
+	if err != nil {
+		synonymError(w, err)
+		return
+	}
+	jsonOK(w, g)
+}
+
+// PUT /admin/search/synonyms/{id}
+// --- UpdateSynonyms ---
+// UpdateSynonyms godoc
+// @Summary      Replace the terms of a synonym group
+// @Description  Terms follow the rules of POST /admin/search/synonyms/. Requires the admin scope.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int                 true  "Synonym group ID"  minimum(1)
+// @Param        body  body      ports.SynonymInput  true  "Terms"
+// @Success      200   {object}  domain.SynonymGroup
+// @Failure      400   {object}  ports.ErrorResponse
+// @Failure      403   {object}  ports.ErrorResponse
+// @Failure      404   {object}  ports.ErrorResponse
+// @Failure      409   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Failure      500   {object}  ports.ErrorResponse
+// @Router       /admin/search/synonyms/{id} [put]
+func (h *Handler) UpdateSynonyms(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	var in ports.SynonymInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	g, err := h.synonyms.UpdateSynonyms(r.Context(), id, in)
+	if err != nil {
+		synonymError(w, err)
+		return
+	}
+	jsonOK(w, g)
+}
+
+// DELETE /admin/search/synonyms/{id}
+// --- DeleteSynonyms ---
+// DeleteSynonyms godoc
+// @Summary      Delete a synonym group
+// @Description  Its terms go back to matching only themselves. Requires the admin scope.
+// @Tags         admin
+// @Param        id   path  int  true  "Synonym group ID"  minimum(1)
+// @Success      204  "No Content"
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /admin/search/synonyms/{id} [delete]
+func (h *Handler) DeleteSynonyms(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	if err := h.synonyms.DeleteSynonyms(r.Context(), id); err != nil {
+		synonymError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func synonymError(w http.ResponseWriter, err error) {
+	if ve, ok := err.(*appsvc.ValidationError); ok {
+		httpValidation(w, ve)
+		return
+	}
+	switch {
+	case err.Error() == "synonym group not found":
+		httpError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, ports.ErrSynonymTaken):
+		httpErrorCode(w, http.StatusConflict, "synonym_taken", err.Error())
+	default:
+		httpError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,116 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/docs"
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// sliceSynonymRepo keeps groups in id order. The term "taken" stands in for
+// a term another group has.
+type sliceSynonymRepo struct {
+	groups []domain.SynonymGroup
+}
+
+func (m *sliceSynonymRepo) List(ctx context.Context) ([]domain.SynonymGroup, error) {
+	return slices.Clone(m.groups), nil
+}
+func (m *sliceSynonymRepo) GetByID(ctx context.Context, id int64) (*domain.SynonymGroup, error) {
+	for _, g := range m.groups {
+		if g.ID == id {
+			return &g, nil
+		}
+	}
+	return nil, nil
+}
+func (m *sliceSynonymRepo) Create(ctx context.Context, g *domain.SynonymGroup) (int64, error) {
+	if slices.Contains(g.Terms, "taken") {
+		return 0, ports.ErrSynonymTaken
+	}
+	c := *g
+	c.ID = int64(len(m.groups) + 1)
+	m.groups = append(m.groups, c)
+	return c.ID, nil
+}
+func (m *sliceSynonymRepo) Update(ctx context.Context, g *domain.SynonymGroup) (bool, error) {
+	for i := range m.groups {
+		if m.groups[i].ID == g.ID {
+			m.groups[i] = *g
+			return true, nil
+		}
+	}
+	return false, nil
+}
+func (m *sliceSynonymRepo) Delete(ctx context.Context, id int64) (bool, error) {
+	n := len(m.groups)
+	m.groups = slices.DeleteFunc(m.groups, func(g domain.SynonymGroup) bool { return g.ID == id })
+	return len(m.groups) < n, nil
+}
+
+func TestSynonyms(t *testing.T) {
+	v, err := NewSpecValidator([]byte(docs.SwaggerInfo.ReadDoc()))
+	if err != nil {
+		t.Fatalf("NewSpecValidator: %v", err)
+	}
+	h := NewHandler(&mockBookService{}, WithSynonyms(appsvc.NewSynonyms(&sliceSynonymRepo{}, time.Minute)))
+	ts := httptest.NewServer(Identify(true)(v.Middleware(h.Router())))
+	defer ts.Close()
+
+	call := func(method, path, scopes string, body any) (int, string) {
+		t.Helper()
+		var r io.Reader
+		if body != nil {
+			b, _ := json.Marshal(body)
+			r = bytes.NewReader(b)
+		}
+		req, _ := http.NewRequest(method, ts.URL+path, r)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User", "ops")
+		req.Header.Set("X-User-Scopes", scopes)
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("do request: %v", err)
+		}
+		return res.StatusCode, readBody(t, res)
+	}
+
+	programming := map[string]any{"terms": []string{"programming", "coding"}}
+	if code, _ := call(http.MethodPost, "/admin/search/synonyms/", "", programming); code != http.StatusForbidden {
+		t.Fatalf("non-admin POST = %d; want 403", code)
+	}
+	if code, body := call(http.MethodPost, "/admin/search/synonyms/", "admin", map[string]any{"terms": []string{"coding"}}); code != http.StatusUnprocessableEntity || !contains(body, "terms") {
+		t.Fatalf("POST with one term = %d %s", code, body)
+	}
+	if code, body := call(http.MethodPost, "/admin/search/synonyms/", "admin", map[string]any{"terms": []string{"taken", "other"}}); code != http.StatusConflict || !contains(body, `"code":"synonym_taken"`) {
+		t.Fatalf("POST with a taken term = %d %s", code, body)
+	}
+	if code, body := call(http.MethodPost, "/admin/search/synonyms/", "admin", programming); code != http.StatusCreated || !contains(body, `"terms":["programming","coding"]`) {
+		t.Fatalf("POST = %d %s", code, body)
+	}
+	if code, body := call(http.MethodPut, "/admin/search/synonyms/1", "admin", map[string]any{"terms": []string{"programming", "coding", "software development"}}); code != http.StatusOK || !contains(body, "software development") {
+		t.Fatalf("PUT = %d %s", code, body)
+	}
+	if code, body := call(http.MethodGet, "/admin/search/synonyms/", "admin", nil); code != http.StatusOK || !contains(body, `"id":1`) {
+		t.Fatalf("list = %d %s", code, body)
+	}
+	if code, body := call(http.MethodGet, "/.well-known/api-capabilities", "", nil); code != http.StatusOK || !contains(body, `"synonyms"`) {
+		t.Fatalf("capabilities = %d %s", code, body)
+	}
+	if code, _ := call(http.MethodDelete, "/admin/search/synonyms/1", "admin", nil); code != http.StatusNoContent {
+		t.Fatalf("DELETE = %d", code)
+	}
+	if code, _ := call(http.MethodGet, "/admin/search/synonyms/1", "admin", nil); code != http.StatusNotFound {
+		t.Fatalf("GET after DELETE = %d; want 404", code)
+	}
+}
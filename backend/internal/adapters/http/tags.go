@@ -0,0 +1,136 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/go-chi/chi/v5"
+)
+
+// GET /tags
+// --- ListTags ---
+// ListTags godoc
+// @Summary      List tags with usage counts
+// @Description  The tags on at least one book, most used first, for a tag cloud. `prefix` keeps the tags starting with it, ignoring case and accents, for autocompletion.
+// @Tags         tags
+// @Produce      json
+// @Param        prefix  query     string  false  "Start of the tag"
+// @Param        limit   query     int     false  "Tags to return, default 100"  minimum(1)  maximum(1000)
+// @Success      200     {array}   domain.Tag
+// @Failure      400     {object}  ports.ErrorResponse
+// @Failure      422     {object}  validationPayload
+// @Failure      500     {object}  ports.ErrorResponse
+// @Router       /tags [get]
+func (h *Handler) ListTags(w http.ResponseWriter, r *http.Request) {
+	q := ports.TagQuery{Prefix: r.URL.Query().Get("prefix")}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			httpError(w, http.StatusBadRequest, "limit must be an integer")
+			return
+		}
+		q.Limit = n
+	}
+	tags, err := h.tags.ListTags(r.Context(), q)
+	if err != nil {
+		tagError(w, err)
+		return
+	}
+	jsonOK(w, tags)
+}
+
+// PUT /books/{id}/tags/{tag}
+// --- TagBook ---
+// TagBook godoc
+// @Summary      Tag a book
+// @Description  Tags are free-form, up to 64 characters without `/`, and match ignoring case, accents and spacing; a new tag keeps the spelling it is first used with. Tagging a book with a tag it has changes nothing. A book has at most 50 tags. The change is recorded in the change log.
+// @Tags         tags
+// @Produce      json
+// @Param        id   path      int     true  "Book ID"  minimum(1)
+// @Param        tag  path      string  true  "Tag"
+// @Success      200  {object}  presenter.BookView
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      422  {object}  validationPayload
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /books/{id}/tags/{tag} [put]
+func (h *Handler) TagBook(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	tag, ok := tagParam(w, r)
+	if !ok {
+		return
+	}
+	b, err := h.tags.TagBook(r.Context(), id, tag)
+	if err != nil {
+		tagError(w, err)
+		return
+	}
+	jsonOK(w, h.presentBook(r, *b))
+}
+
+// DELETE /books/{id}/tags/{tag}
+// --- UntagBook ---
+// UntagBook godoc
+// @Summary      Remove a tag from a book
+// @Description  The tag may be given in any spelling. 404 if the book doesn't have it.
+// @Tags         tags
+// @Produce      json
+// @Param        id   path      int     true  "Book ID"  minimum(1)
+// @Param        tag  path      string  true  "Tag"
+// @Success      200  {object}  presenter.BookView
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /books/{id}/tags/{tag} [delete]
+func (h *Handler) UntagBook(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	tag, ok := tagParam(w, r)
+	if !ok {
+		return
+	}
+	b, err := h.tags.UntagBook(r.Context(), id, tag)
+	if err != nil {
+		tagError(w, err)
+		return
+	}
+	jsonOK(w, h.presentBook(r, *b))
+}
+
+// tagParam reads the tag from the path. chi matches escaped paths as sent,
+// so a tag with escapes that aren't the default ones arrives escaped.
+func tagParam(w http.ResponseWriter, r *http.Request) (string, bool) {
+	tag := chi.URLParam(r, "tag")
+	if r.URL.RawPath == "" {
+		return tag, true
+	}
+	tag, err := url.PathUnescape(tag)
+	if err != nil {
+		httpError(w, http.StatusBadRequest, "invalid tag")
+		return "", false
+	}
+	return tag, true
+}
+
+func tagError(w http.ResponseWriter, err error) {
+	if ve, ok := err.(*appsvc.ValidationError); ok {
+		httpValidation(w, ve)
+		return
+	}
+	switch err.Error() {
+	case "book not found", "tag not found":
+		httpError(w, http.StatusNotFound, err.Error())
+	default:
+		httpError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type mockTagService struct {
+	tagged []string // tags as the handler passed them
+	query  ports.TagQuery
+}
+
+func (m *mockTagService) TagBook(ctx context.Context, bookID int64, tag string) (*domain.Book, error) {
+	if bookID != 1 {
+		return nil, errors.New("book not found")
+	}
+	if tag == "" {
+		return nil, &appsvc.ValidationError{Fields: map[string]string{"tag": "Tag is required"}}
+	}
+	m.tagged = append(m.tagged, tag)
+	return &domain.Book{ID: 1, Title: "Dune", Tags: m.tagged}, nil
+}
+func (m *mockTagService) UntagBook(ctx context.Context, bookID int64, tag string) (*domain.Book, error) {
+	return nil, errors.New("tag not found")
+}
+func (m *mockTagService) ListTags(ctx context.Context, q ports.TagQuery) ([]domain.Tag, error) {
+	m.query = q
+	return []domain.Tag{{Name: "space opera", Books: 3}}, nil
+}
+
+func TestTags(t *testing.T) {
+	tags := &mockTagService{}
+	var filter ports.ListFilter
+	books := &mockBookService{
+		ListPageFn: func(ctx context.Context, f ports.ListFilter, page ports.Page) (*ports.BookPage, error) {
+			filter = f
+			return &ports.BookPage{Books: []domain.Book{}}, nil
+		},
+	}
+	ts := newSpecServer(t, books, WithTags(tags))
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodPut, "/books/1/tags/Space%20Opera", nil)
+	if body := readBody(t, res); res.StatusCode != http.StatusOK || !contains(body, `"tags":["Space Opera"]`) {
+		t.Fatalf("PUT = %d %s", res.StatusCode, body)
+	}
+	// escapes chi leaves alone are undone
+	res = do(t, ts, http.MethodPut, "/books/1/tags/AC%2FDC", nil)
+	if body := readBody(t, res); res.StatusCode != http.StatusOK || tags.tagged[1] != "AC/DC" {
+		t.Fatalf("PUT escaped = %d %s %q", res.StatusCode, body, tags.tagged)
+	}
+	res = do(t, ts, http.MethodPut, "/books/2/tags/classic", nil)
+	if body := readBody(t, res); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("PUT missing book = %d %s", res.StatusCode, body)
+	}
+	res = do(t, ts, http.MethodDelete, "/books/1/tags/classic", nil)
+	if body := readBody(t, res); res.StatusCode != http.StatusNotFound || !contains(body, "tag not found") {
+		t.Fatalf("DELETE = %d %s", res.StatusCode, body)
+	}
+
+	res = do(t, ts, http.MethodGet, "/tags?prefix=spa&limit=5", nil)
+	if body := readBody(t, res); res.StatusCode != http.StatusOK || !contains(body, `"books":3`) || tags.query != (ports.TagQuery{Prefix: "spa", Limit: 5}) {
+		t.Fatalf("GET /tags = %d %s %+v", res.StatusCode, body, tags.query)
+	}
+
+	res = do(t, ts, http.MethodGet, "/books/?tag=space+opera", nil)
+	if body := readBody(t, res); res.StatusCode != http.StatusOK || filter.Tag != "space opera" {
+		t.Fatalf("GET /books?tag = %d %s %+v", res.StatusCode, body, filter)
+	}
+}
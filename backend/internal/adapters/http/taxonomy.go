@@ -0,0 +1,99 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+)
+
+// maxTaxonomyBytes caps an imported taxonomy document.
+const maxTaxonomyBytes = 5 << 20
+
+// GET /taxonomy/export
+// --- ExportTaxonomy ---
+// ExportTaxonomy godoc
+// @Summary      Export the category and author taxonomy
+// @Description  The whole taxonomy as an indented JSON document, sorted by slug so exports diff cleanly in Git. Import it elsewhere with POST /taxonomy/import.
+// @Tags         taxonomy
+// @Produce      json
+// @Success      200  {object}  domain.Taxonomy
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /taxonomy/export [get]
+func (h *Handler) ExportTaxonomy(w http.ResponseWriter, r *http.Request) {
+	t, err := h.taxonomy.ExportTaxonomy(r.Context())
+	if err != nil {
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		logger.From(r.Context()).Error("failed to encode taxonomy", "error", err)
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	filename := fmt.Sprintf("taxonomy-%s.json", h.now().UTC().Format("20060102"))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(append(data, '\n'))
+}
+
+// POST /taxonomy/import
+// --- ImportTaxonomy ---
+// ImportTaxonomy godoc
+// @Summary      Import a category and author taxonomy
+// @Description  Takes a document from GET /taxonomy/export. `merge` adds the terms this deployment lacks and keeps its own version of those that differ, listing each difference under `conflicts`; it deletes nothing. `replace` makes the taxonomy exactly the document. The import applies in one transaction; with `dry_run=true` it only reports what it would do. Unknown fields in the document are rejected, so typos don't pass silently.
+// @Tags         taxonomy
+// @Accept       json
+// @Produce      json
+// @Param        mode     query     string           true   "merge or replace"  Enums(merge, replace)
+// @Param        dry_run  query     bool             false  "Report without applying"
+// @Param        body     body      domain.Taxonomy  true   "Taxonomy"
+// @Success      200      {object}  ports.TaxonomyReport
+// @Failure      400      {object}  ports.ErrorResponse
+// @Failure      403      {object}  ports.ErrorResponse
+// @Failure      422      {object}  validationPayload
+// @Failure      500      {object}  ports.ErrorResponse
+// @Router       /taxonomy/import [post]
+func (h *Handler) ImportTaxonomy(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	dryRun := false
+	if v := q.Get("dry_run"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			httpError(w, http.StatusBadRequest, "dry_run must be true or false")
+			return
+		}
+		dryRun = b
+	}
+
+	var t domain.Taxonomy
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTaxonomyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&t); err != nil {
+		var tooBig *http.MaxBytesError
+		if errors.As(err, &tooBig) {
+			httpError(w, http.StatusBadRequest, fmt.Sprintf("taxonomy must be at most %d MB", maxTaxonomyBytes>>20))
+			return
+		}
+		httpError(w, http.StatusBadRequest, "invalid taxonomy: "+err.Error())
+		return
+	}
+
+	report, err := h.taxonomy.ImportTaxonomy(r.Context(), t, q.Get("mode"), dryRun)
+	if err != nil {
+		if ve, ok := err.(*appsvc.ValidationError); ok {
+			httpValidation(w, ve)
+			return
+		}
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, report)
+}
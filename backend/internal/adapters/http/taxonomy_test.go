@@ -0,0 +1,62 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type mockTaxonomyService struct {
+	taxonomy domain.Taxonomy
+	mode     string
+	dryRun   bool
+}
+
+func (m *mockTaxonomyService) ExportTaxonomy(ctx context.Context) (*domain.Taxonomy, error) {
+	return &m.taxonomy, nil
+}
+func (m *mockTaxonomyService) ImportTaxonomy(ctx context.Context, t domain.Taxonomy, mode string, dryRun bool) (*ports.TaxonomyReport, error) {
+	m.mode, m.dryRun = mode, dryRun
+	if mode != ports.TaxonomyMerge && mode != ports.TaxonomyReplace {
+		return nil, &appsvc.ValidationError{Fields: map[string]string{"mode": "Mode must be merge or replace"}}
+	}
+	return &ports.TaxonomyReport{Mode: mode, DryRun: dryRun, Conflicts: []ports.TaxonomyConflict{}}, nil
+}
+
+func TestTaxonomy_ExportAndImport(t *testing.T) {
+	svc := &mockTaxonomyService{taxonomy: domain.Taxonomy{
+		Version:    1,
+		Categories: []domain.Category{{Slug: "fiction", Name: "Fiction"}},
+		Authors:    []domain.Author{},
+	}}
+	ts := httptest.NewServer(NewHandler(&mockBookService{}, WithTaxonomy(svc)).Router())
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodGet, "/taxonomy/export", nil)
+	body := readBody(t, res)
+	if res.StatusCode != http.StatusOK || !strings.Contains(body, "\n    {\n      \"slug\": \"fiction\"") {
+		t.Fatalf("export: %d %s", res.StatusCode, body)
+	}
+	if cd := res.Header.Get("Content-Disposition"); !strings.HasPrefix(cd, `attachment; filename="taxonomy-`) {
+		t.Fatalf("Content-Disposition = %q", cd)
+	}
+
+	res = do(t, ts, http.MethodPost, "/taxonomy/import?mode=replace&dry_run=true", map[string]any{"version": 1, "categories": []any{}})
+	if body := readBody(t, res); res.StatusCode != http.StatusOK || svc.mode != "replace" || !svc.dryRun {
+		t.Fatalf("import: %d %s", res.StatusCode, body)
+	}
+	res = do(t, ts, http.MethodPost, "/taxonomy/import?mode=merge", map[string]any{"version": 1, "categorys": []any{}})
+	if body := readBody(t, res); res.StatusCode != http.StatusBadRequest || !contains(body, "categorys") {
+		t.Fatalf("unknown field: %d %s", res.StatusCode, body)
+	}
+	res = do(t, ts, http.MethodPost, "/taxonomy/import", map[string]any{"version": 1})
+	if body := readBody(t, res); res.StatusCode != http.StatusUnprocessableEntity || !contains(body, "mode") {
+		t.Fatalf("no mode: %d %s", res.StatusCode, body)
+	}
+}
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// GET /url/cleanup/rules
+// --- GetTrackingRules ---
+// GetTrackingRules godoc
+// @Summary      Get your tracking parameter rules
+// @Description  The parameters the strip_tracking_params cleanup rule removes from your requests on top of the built-in ones, the ones it keeps, and per-host changes. Rules belong to the caller: the API key (X-API-Key) or signed-in user.
+// @Tags         tools
+// @Produce      json
+// @Success      200  {object}  domain.TrackingRules
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /url/cleanup/rules [get]
+func (h *Handler) GetTrackingRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.trackingRules.GetTrackingRules(r.Context())
+	if err != nil {
+		trackingRulesError(w, err)
+		return
+	}
+	jsonOK(w, rules)
+}
+
+// PUT /url/cleanup/rules
+// --- PutTrackingRules ---
+// PutTrackingRules godoc
+// @Summary      Replace your tracking parameter rules
+// @Description  strip lists parameters to remove too and keep parameters never to remove, built-in ones included; keep wins. Names ignore case, and a trailing * matches any suffix (`src_*`). A hosts entry applies on top to its host and subdomains. Up to 200 names per list and 50 hosts. Cleanups use the new rules right away on this server, and within a minute on the others.
+// @Tags         tools
+// @Accept       json
+// @Produce      json
+// @Param        body  body      ports.PutTrackingRulesInput  true  "Rules"
+// @Success      200   {object}  domain.TrackingRules
+// @Failure      400   {object}  ports.ErrorResponse
+// @Failure      403   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Failure      500   {object}  ports.ErrorResponse
+// @Router       /url/cleanup/rules [put]
+func (h *Handler) PutTrackingRules(w http.ResponseWriter, r *http.Request) {
+	var in ports.PutTrackingRulesInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	rules, err := h.trackingRules.PutTrackingRules(r.Context(), in)
+	if err != nil {
+		trackingRulesError(w, err)
+		return
+	}
+	jsonOK(w, rules)
+}
+
+// DELETE /url/cleanup/rules
+// --- DeleteTrackingRules ---
+// DeleteTrackingRules godoc
+// @Summary      Delete your tracking parameter rules
+// @Description  Cleanups go back to the built-in tracking parameters.
+// @Tags         tools
+// @Success      204  "No Content"
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /url/cleanup/rules [delete]
+func (h *Handler) DeleteTrackingRules(w http.ResponseWriter, r *http.Request) {
+	if err := h.trackingRules.DeleteTrackingRules(r.Context()); err != nil {
+		trackingRulesError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func trackingRulesError(w http.ResponseWriter, err error) {
+	if ve, ok := err.(*appsvc.ValidationError); ok {
+		httpValidation(w, ve)
+		return
+	}
+	switch {
+	case errors.Is(err, ports.ErrNoTenant):
+		httpError(w, http.StatusForbidden, err.Error())
+	case err.Error() == "cleanup rules not found":
+		httpError(w, http.StatusNotFound, err.Error())
+	default:
+		httpError(w, http.StatusInternalServerError, err.Error())
+	}
+}
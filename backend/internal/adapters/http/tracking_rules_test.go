@@ -0,0 +1,119 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/docs"
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/pkg/urlclean"
+)
+
+type mapTrackingRulesRepo map[string]domain.TrackingRules
+
+func (m mapTrackingRulesRepo) Get(ctx context.Context, tenant string) (*domain.TrackingRules, error) {
+	if r, ok := m[tenant]; ok {
+		return &r, nil
+	}
+	return nil, nil
+}
+func (m mapTrackingRulesRepo) Put(ctx context.Context, r *domain.TrackingRules) error {
+	m[r.Tenant] = *r
+	return nil
+}
+func (m mapTrackingRulesRepo) Delete(ctx context.Context, tenant string) (bool, error) {
+	_, ok := m[tenant]
+	delete(m, tenant)
+	return ok, nil
+}
+
+// doAs sends a JSON request as the proxy-identified user, if any.
+func doAs(t *testing.T, ts *httptest.Server, method, path, user string, body any) *http.Response {
+	t.Helper()
+	var r io.Reader
+	if body != nil {
+		b, _ := json.Marshal(body)
+		r = bytes.NewReader(b)
+	}
+	req, _ := http.NewRequest(method, ts.URL+path, r)
+	req.Header.Set("Content-Type", "application/json")
+	if user != "" {
+		req.Header.Set("X-User", user)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("do request: %v", err)
+	}
+	return res
+}
+
+func TestTrackingRules(t *testing.T) {
+	rules := appsvc.NewTrackingRules(mapTrackingRulesRepo{}, time.Minute)
+	cleaner, err := appsvc.NewURLCleaner([]urlclean.Profile{{Name: "feed", Rules: []urlclean.Rule{urlclean.StripTrackingParams}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleaner.UseTrackingRules(rules)
+	v, err := NewSpecValidator([]byte(docs.SwaggerInfo.ReadDoc()))
+	if err != nil {
+		t.Fatalf("NewSpecValidator: %v", err)
+	}
+	h := NewHandler(&mockBookService{}, WithCleanupProfiles(cleaner), WithTrackingRules(rules))
+	ts := httptest.NewServer(Identify(true)(v.Middleware(h.Router())))
+	defer ts.Close()
+
+	call := func(method, path, user string, body any) (int, string) {
+		t.Helper()
+		res := doAs(t, ts, method, path, user, body)
+		return res.StatusCode, readBody(t, res)
+	}
+
+	if code, _ := call(http.MethodGet, "/url/cleanup/rules", "", nil); code != http.StatusForbidden {
+		t.Fatalf("anonymous GET = %d; want 403", code)
+	}
+	if code, _ := call(http.MethodGet, "/url/cleanup/rules", "alice", nil); code != http.StatusNotFound {
+		t.Fatalf("GET before PUT = %d; want 404", code)
+	}
+	if code, body := call(http.MethodPut, "/url/cleanup/rules", "alice", map[string]any{"strip": []string{"a&b"}}); code != http.StatusUnprocessableEntity || !contains(body, "strip[0]") {
+		t.Fatalf("invalid PUT = %d %s", code, body)
+	}
+	rulesBody := map[string]any{"strip": []string{"ref"}, "hosts": []map[string]any{{"host": "news.example.com", "keep": []string{"ref"}}}}
+	if code, body := call(http.MethodPut, "/url/cleanup/rules", "alice", rulesBody); code != http.StatusOK || !contains(body, `"tenant":"alice"`) {
+		t.Fatalf("PUT = %d %s", code, body)
+	}
+	if code, body := call(http.MethodGet, "/url/cleanup/rules", "alice", nil); code != http.StatusOK || !contains(body, `"news.example.com"`) {
+		t.Fatalf("GET = %d %s", code, body)
+	}
+
+	cleanup := func(user, url string) string {
+		t.Helper()
+		res := doAs(t, ts, http.MethodPost, "/url/cleanup", user, map[string]any{"url": url, "profile": "feed"})
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("cleanup %s = %d %s", url, res.StatusCode, readBody(t, res))
+		}
+		return decodeCleanup(t, res).ProcessedURL
+	}
+	if got := cleanup("alice", "https://example.com/?ref=x&utm_source=y"); got != "https://example.com/" {
+		t.Fatalf("alice cleanup = %s", got)
+	}
+	if got := cleanup("alice", "https://news.example.com/?ref=x"); got != "https://news.example.com/?ref=x" {
+		t.Fatalf("alice host cleanup = %s", got)
+	}
+	if got := cleanup("bob", "https://example.com/?ref=x"); got != "https://example.com/?ref=x" {
+		t.Fatalf("bob cleanup = %s", got)
+	}
+
+	if code, _ := call(http.MethodDelete, "/url/cleanup/rules", "alice", nil); code != http.StatusNoContent {
+		t.Fatalf("DELETE = %d", code)
+	}
+	if code, _ := call(http.MethodDelete, "/url/cleanup/rules", "alice", nil); code != http.StatusNotFound {
+		t.Fatalf("second DELETE = %d; want 404", code)
+	}
+}
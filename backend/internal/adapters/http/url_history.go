@@ -0,0 +1,111 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) urlHistoryRoutes(r chi.Router) {
+	r.Get("/", h.ListURLHistory)
+	r.Delete("/", h.ClearURLHistory)
+	r.Delete("/{id}", h.DeleteURLHistoryEntry)
+}
+
+// recordHistory adds a successful cleanup to the caller's URL history, if
+// enabled. A failure is only logged: the cleanup itself succeeded.
+func (h *Handler) recordHistory(r *http.Request, url, processedURL, op string) {
+	if h.history == nil {
+		return
+	}
+	if err := h.history.Record(r.Context(), url, processedURL, op); err != nil {
+		logger.From(r.Context()).Error("failed to record url history", "error", err)
+	}
+}
+
+// GET /me/url-history
+// --- ListURLHistory ---
+// ListURLHistory godoc
+// @Summary      Your recent URL cleanups
+// @Description  Newest first. Successful POST /url/cleanup requests by a signed-in user, proxy identity or API key are kept, the newest 100 per caller; repeating a URL with the same operation or profile moves it to the top.
+// @Tags         tools
+// @Produce      json
+// @Param        limit  query     int  false  "Max entries (default 20, max 100)"  minimum(1)
+// @Success      200    {array}   domain.URLHistoryEntry
+// @Failure      400    {object}  ports.ErrorResponse
+// @Failure      403    {object}  ports.ErrorResponse
+// @Failure      500    {object}  ports.ErrorResponse
+// @Router       /me/url-history/ [get]
+func (h *Handler) ListURLHistory(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			httpError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+	entries, err := h.history.URLHistory(r.Context(), limit)
+	if err != nil {
+		urlHistoryError(w, err)
+		return
+	}
+	jsonOK(w, entries)
+}
+
+// DELETE /me/url-history/{id}
+// --- DeleteURLHistoryEntry ---
+// DeleteURLHistoryEntry godoc
+// @Summary      Remove an entry from your URL history
+// @Tags         tools
+// @Param        id   path  int  true  "History entry ID"  minimum(1)
+// @Success      204  "No Content"
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /me/url-history/{id} [delete]
+func (h *Handler) DeleteURLHistoryEntry(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	if err := h.history.DeleteURLHistoryEntry(r.Context(), id); err != nil {
+		urlHistoryError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DELETE /me/url-history
+// --- ClearURLHistory ---
+// ClearURLHistory godoc
+// @Summary      Clear your URL history
+// @Tags         tools
+// @Success      204  "No Content"
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /me/url-history/ [delete]
+func (h *Handler) ClearURLHistory(w http.ResponseWriter, r *http.Request) {
+	if err := h.history.ClearURLHistory(r.Context()); err != nil {
+		urlHistoryError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func urlHistoryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ports.ErrNoUser):
+		httpError(w, http.StatusForbidden, err.Error())
+	case err.Error() == "history entry not found":
+		httpError(w, http.StatusNotFound, err.Error())
+	default:
+		httpError(w, http.StatusInternalServerError, err.Error())
+	}
+}
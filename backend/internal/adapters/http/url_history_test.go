@@ -0,0 +1,75 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/docs"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type mockURLHistory struct {
+	recorded []string
+}
+
+func (m *mockURLHistory) Record(ctx context.Context, url, processedURL, operation string) error {
+	if a, ok := domain.ActorFrom(ctx); ok {
+		m.recorded = append(m.recorded, a.ID+" "+url+" "+processedURL+" "+operation)
+	}
+	return nil
+}
+
+func (m *mockURLHistory) URLHistory(ctx context.Context, limit int) ([]domain.URLHistoryEntry, error) {
+	if _, ok := domain.ActorFrom(ctx); !ok {
+		return nil, ports.ErrNoUser
+	}
+	return []domain.URLHistoryEntry{{ID: 1, URL: "https://Example.com/a/", ProcessedURL: "https://www.example.com/a", Operation: "all"}}, nil
+}
+
+func (m *mockURLHistory) DeleteURLHistoryEntry(ctx context.Context, id int64) error {
+	if id != 1 {
+		return errors.New("history entry not found")
+	}
+	return nil
+}
+
+func (m *mockURLHistory) ClearURLHistory(ctx context.Context) error { return nil }
+
+func TestURLHistory(t *testing.T) {
+	history := &mockURLHistory{}
+	v, err := NewSpecValidator([]byte(docs.SwaggerInfo.ReadDoc()))
+	if err != nil {
+		t.Fatalf("NewSpecValidator: %v", err)
+	}
+	ts := httptest.NewServer(Identify(true)(v.Middleware(NewHandler(&mockBookService{}, WithURLHistory(history)).Router())))
+	defer ts.Close()
+
+	doAs(t, ts, http.MethodPost, "/url/cleanup", "alice", map[string]any{"url": "https://Example.com/a/", "operation": "all"}).Body.Close()
+	doAs(t, ts, http.MethodPost, "/url/cleanup", "", map[string]any{"url": "https://Example.com/b/", "operation": "all"}).Body.Close()
+	doAs(t, ts, http.MethodPost, "/url/cleanup", "alice", map[string]any{"url": "ftp://example.com", "operation": "all"}).Body.Close()
+	if len(history.recorded) != 1 || history.recorded[0] != "alice https://Example.com/a/ https://www.example.com/a all" {
+		t.Fatalf("recorded = %q; want only alice's successful cleanup", history.recorded)
+	}
+
+	cases := []struct {
+		method, path, user string
+		want               int
+	}{
+		{http.MethodGet, "/me/url-history", "", http.StatusForbidden},
+		{http.MethodGet, "/me/url-history?limit=0", "alice", http.StatusBadRequest},
+		{http.MethodGet, "/me/url-history?limit=5", "alice", http.StatusOK},
+		{http.MethodDelete, "/me/url-history/1", "alice", http.StatusNoContent},
+		{http.MethodDelete, "/me/url-history/2", "alice", http.StatusNotFound},
+		{http.MethodDelete, "/me/url-history", "alice", http.StatusNoContent},
+	}
+	for _, c := range cases {
+		res := doAs(t, ts, c.method, c.path, c.user, nil)
+		if body := readBody(t, res); res.StatusCode != c.want {
+			t.Errorf("%s %s = %d %s; want %d", c.method, c.path, res.StatusCode, body, c.want)
+		}
+	}
+}
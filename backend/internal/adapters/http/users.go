@@ -0,0 +1,240 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) userRoutes(r chi.Router) {
+	r.Use(requireScope(domain.ScopeAdmin))
+	r.Get("/", h.ListUsers)
+	r.Post("/", h.CreateUser)
+	r.Get("/{id}", h.GetUser)
+	r.Put("/{id}", h.UpdateUser)
+	r.Delete("/{id}", h.DeleteUser)
+}
+
+// GET /users
+// --- ListUsers ---
+// ListUsers godoc
+// @Summary      List users
+// @Description  User accounts by id, 100 a page unless limit says otherwise. Requires the admin scope.
+// @Tags         users
+// @Produce      json
+// @Param        limit   query     int  false  "Page size"  minimum(1)  maximum(1000)
+// @Param        offset  query     int  false  "Users to skip"  minimum(0)
+// @Success      200     {array}   domain.User
+// @Header       200     {integer}  X-Total-Count  "Number of users across all pages"
+// @Header       200     {string}   Link           "RFC 8288 next/prev page links"
+// @Failure      400     {object}  ports.ErrorResponse
+// @Failure      403     {object}  ports.ErrorResponse
+// @Failure      422     {object}  validationPayload
+// @Failure      500     {object}  ports.ErrorResponse
+// @Router       /users/ [get]
+func (h *Handler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	page, _, err := parsePage(r.URL.Query())
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	res, err := h.users.ListUsers(r.Context(), ports.UserQuery{Limit: page.Limit, Offset: page.Offset})
+	if err != nil {
+		userError(w, err)
+		return
+	}
+	setPageLinks(w, r, ports.Page{Limit: res.Limit, Offset: res.Offset}, res.Total)
+	w.Header().Set("X-Total-Count", strconv.Itoa(res.Total))
+	jsonOK(w, res.Users)
+}
+
+// POST /users
+// --- CreateUser ---
+// CreateUser godoc
+// @Summary      Create a user
+// @Description  Like POST /auth/register, with a name of up to 100 characters and scopes among admin, editor and reader. 409 when the email has an account. Requires the admin scope.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        body  body      ports.CreateUserInput  true  "User"
+// @Success      201   {object}  domain.User
+// @Failure      400   {object}  ports.ErrorResponse
+// @Failure      403   {object}  ports.ErrorResponse
+// @Failure      409   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Failure      500   {object}  ports.ErrorResponse
+// @Router       /users/ [post]
+func (h *Handler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var in ports.CreateUserInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	u, err := h.users.CreateUser(r.Context(), in)
+	if err != nil {
+		userError(w, err)
+		return
+	}
+	jsonCreated(w, u)
+}
+
+// GET /users/{id}
+// --- GetUser ---
+// GetUser godoc
+// @Summary      Get a user
+// @Description  Requires the admin scope.
+// @Tags         users
+// @Produce      json
+// @Param        id   path      int  true  "User ID"  minimum(1)
+// @Success      200  {object}  domain.User
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /users/{id} [get]
+func (h *Handler) GetUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	u, err := h.users.GetUser(r.Context(), id)
+	if err != nil {
+		userError(w, err)
+		return
+	}
+	jsonOK(w, u)
+}
+
+// PUT /users/{id}
+// --- UpdateUser ---
+// UpdateUser godoc
+// @Summary      Replace a user's name and scopes
+// @Description  A password, when sent, replaces the user's. The email can't change. Tokens keep the scopes they were issued with, so new scopes apply from the user's next login. Requires the admin scope.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int                    true  "User ID"  minimum(1)
+// @Param        body  body      ports.UpdateUserInput  true  "User"
+// @Success      200   {object}  domain.User
+// @Failure      400   {object}  ports.ErrorResponse
+// @Failure      403   {object}  ports.ErrorResponse
+// @Failure      404   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Failure      500   {object}  ports.ErrorResponse
+// @Router       /users/{id} [put]
+func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	var in ports.UpdateUserInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	u, err := h.users.UpdateUser(r.Context(), id, in)
+	if err != nil {
+		userError(w, err)
+		return
+	}
+	jsonOK(w, u)
+}
+
+// DELETE /users/{id}
+// --- DeleteUser ---
+// DeleteUser godoc
+// @Summary      Delete a user
+// @Description  Their loans and holds go with them. Tokens already issued to them stay valid until they expire. Requires the admin scope.
+// @Tags         users
+// @Param        id   path  int  true  "User ID"  minimum(1)
+// @Success      204  "No Content"
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /users/{id} [delete]
+func (h *Handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	if err := h.users.DeleteUser(r.Context(), id); err != nil {
+		userError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /me
+// --- GetMe ---
+// GetMe godoc
+// @Summary      Get your profile
+// @Description  The account of the signed-in user. 403 for anonymous callers; 404 for callers without an account, such as API keys.
+// @Tags         users
+// @Produce      json
+// @Success      200  {object}  domain.User
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /me [get]
+func (h *Handler) GetMe(w http.ResponseWriter, r *http.Request) {
+	u, err := h.users.Me(r.Context())
+	if err != nil {
+		userError(w, err)
+		return
+	}
+	jsonOK(w, u)
+}
+
+// PUT /me
+// --- UpdateMe ---
+// UpdateMe godoc
+// @Summary      Update your profile
+// @Description  Replaces your name. A new_password replaces your password when current_password is the one it replaces; tokens already issued stay valid until they expire. Scopes are granted by admins through PUT /users/{id}.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        body  body      ports.UpdateProfileInput  true  "Profile"
+// @Success      200   {object}  domain.User
+// @Failure      400   {object}  ports.ErrorResponse
+// @Failure      403   {object}  ports.ErrorResponse
+// @Failure      404   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Failure      500   {object}  ports.ErrorResponse
+// @Router       /me [put]
+func (h *Handler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	var in ports.UpdateProfileInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	u, err := h.users.UpdateMe(r.Context(), in)
+	if err != nil {
+		userError(w, err)
+		return
+	}
+	jsonOK(w, u)
+}
+
+func userError(w http.ResponseWriter, err error) {
+	if ve, ok := err.(*appsvc.ValidationError); ok {
+		httpValidation(w, ve)
+		return
+	}
+	switch {
+	case errors.Is(err, ports.ErrNotSignedIn):
+		httpError(w, http.StatusForbidden, err.Error())
+	case err.Error() == "user not found":
+		httpError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, ports.ErrEmailTaken):
+		httpError(w, http.StatusConflict, err.Error())
+	default:
+		httpError(w, http.StatusInternalServerError, err.Error())
+	}
+}
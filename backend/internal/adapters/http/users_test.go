@@ -0,0 +1,149 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/docs"
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// sliceUserRepo keeps users in id order.
+type sliceUserRepo struct {
+	users []domain.User
+}
+
+func (m *sliceUserRepo) Create(ctx context.Context, u *domain.User) (int64, error) {
+	if slices.ContainsFunc(m.users, func(x domain.User) bool { return x.Email == u.Email }) {
+		return 0, ports.ErrEmailTaken
+	}
+	c := *u
+	c.ID = int64(len(m.users) + 1)
+	m.users = append(m.users, c)
+	return c.ID, nil
+}
+func (m *sliceUserRepo) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	for _, u := range m.users {
+		if u.Email == email {
+			return &u, nil
+		}
+	}
+	return nil, nil
+}
+func (m *sliceUserRepo) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	for _, u := range m.users {
+		if u.ID == id {
+			return &u, nil
+		}
+	}
+	return nil, nil
+}
+func (m *sliceUserRepo) List(ctx context.Context) ([]domain.User, error) {
+	return slices.Clone(m.users), nil
+}
+func (m *sliceUserRepo) ListPage(ctx context.Context, limit, offset int) ([]domain.User, int, error) {
+	page := m.users[min(offset, len(m.users)):]
+	return slices.Clone(page[:min(limit, len(page))]), len(m.users), nil
+}
+func (m *sliceUserRepo) Update(ctx context.Context, u *domain.User) (bool, error) {
+	for i := range m.users {
+		if m.users[i].ID == u.ID {
+			m.users[i] = *u
+			return true, nil
+		}
+	}
+	return false, nil
+}
+func (m *sliceUserRepo) Delete(ctx context.Context, id int64) (bool, error) {
+	n := len(m.users)
+	m.users = slices.DeleteFunc(m.users, func(u domain.User) bool { return u.ID == id })
+	return len(m.users) < n, nil
+}
+
+func TestUsers(t *testing.T) {
+	v, err := NewSpecValidator([]byte(docs.SwaggerInfo.ReadDoc()))
+	if err != nil {
+		t.Fatalf("NewSpecValidator: %v", err)
+	}
+	h := NewHandler(&mockBookService{}, WithUsers(appsvc.NewUsers(&sliceUserRepo{})))
+	ts := httptest.NewServer(Identify(true)(v.Middleware(h.Router())))
+	defer ts.Close()
+
+	call := func(method, path, user, scopes string, body any) (*http.Response, string) {
+		t.Helper()
+		var r io.Reader
+		if body != nil {
+			b, _ := json.Marshal(body)
+			r = bytes.NewReader(b)
+		}
+		req, _ := http.NewRequest(method, ts.URL+path, r)
+		req.Header.Set("Content-Type", "application/json")
+		if user != "" {
+			req.Header.Set("X-User", user)
+			req.Header.Set("X-User-Scopes", scopes)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("do request: %v", err)
+		}
+		return res, readBody(t, res)
+	}
+
+	ann := map[string]any{"email": "ann@example.com", "password": "correct horse", "name": "Ann", "scopes": []string{"editor"}}
+	if res, _ := call(http.MethodPost, "/users/", "ops", "", ann); res.StatusCode != http.StatusForbidden {
+		t.Fatalf("non-admin POST = %d; want 403", res.StatusCode)
+	}
+	if res, body := call(http.MethodPost, "/users/", "ops", "admin", map[string]any{"email": "ann@example.com", "password": "correct horse", "scopes": []string{"root"}}); res.StatusCode != http.StatusUnprocessableEntity || !contains(body, "scopes") {
+		t.Fatalf("POST with an unknown scope = %d %s", res.StatusCode, body)
+	}
+	if res, body := call(http.MethodPost, "/users/", "ops", "admin", ann); res.StatusCode != http.StatusCreated || !contains(body, `"scopes":["editor"]`) || contains(body, "password") {
+		t.Fatalf("POST = %d %s", res.StatusCode, body)
+	}
+	if res, _ := call(http.MethodPost, "/users/", "ops", "admin", ann); res.StatusCode != http.StatusConflict {
+		t.Fatalf("POST twice = %d; want 409", res.StatusCode)
+	}
+	if res, _ := call(http.MethodPost, "/users/", "ops", "admin", map[string]any{"email": "bob@example.com", "password": "correct horse"}); res.StatusCode != http.StatusCreated {
+		t.Fatalf("POST bob = %d", res.StatusCode)
+	}
+	res, body := call(http.MethodGet, "/users/?limit=1", "ops", "admin", nil)
+	if res.StatusCode != http.StatusOK || res.Header.Get("X-Total-Count") != "2" || !contains(res.Header.Get("Link"), `rel="next"`) || !contains(body, "ann@example.com") {
+		t.Fatalf("list = %d %v %s", res.StatusCode, res.Header, body)
+	}
+	if res, body := call(http.MethodPut, "/users/2", "ops", "admin", map[string]any{"name": "Bob", "scopes": []string{"reader"}}); res.StatusCode != http.StatusOK || !contains(body, `"name":"Bob"`) {
+		t.Fatalf("PUT = %d %s", res.StatusCode, body)
+	}
+
+	if res, _ := call(http.MethodGet, "/me", "", "", nil); res.StatusCode != http.StatusForbidden {
+		t.Fatalf("anonymous GET /me = %d; want 403", res.StatusCode)
+	}
+	if res, _ := call(http.MethodGet, "/me", "ops", "admin", nil); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("GET /me without an account = %d; want 404", res.StatusCode)
+	}
+	if res, body := call(http.MethodPut, "/me", "ann@example.com", "", map[string]any{"name": "Ann Lee"}); res.StatusCode != http.StatusOK || !contains(body, `"name":"Ann Lee"`) || !contains(body, `"scopes":["editor"]`) {
+		t.Fatalf("PUT /me = %d %s", res.StatusCode, body)
+	}
+	if res, body := call(http.MethodPut, "/me", "ann@example.com", "", map[string]any{"name": "Ann", "new_password": "battery staple", "current_password": "wrong"}); res.StatusCode != http.StatusUnprocessableEntity || !contains(body, "current_password") {
+		t.Fatalf("PUT /me with a wrong password = %d %s", res.StatusCode, body)
+	}
+	if res, body := call(http.MethodGet, "/me", "ann@example.com", "", nil); res.StatusCode != http.StatusOK || !contains(body, `"name":"Ann Lee"`) {
+		t.Fatalf("GET /me = %d %s", res.StatusCode, body)
+	}
+
+	if res, body := call(http.MethodGet, "/.well-known/api-capabilities", "", "", nil); res.StatusCode != http.StatusOK || !contains(body, `"user_management"`) {
+		t.Fatalf("capabilities = %d %s", res.StatusCode, body)
+	}
+	if res, _ := call(http.MethodDelete, "/users/1", "ops", "admin", nil); res.StatusCode != http.StatusNoContent {
+		t.Fatalf("DELETE = %d", res.StatusCode)
+	}
+	if res, _ := call(http.MethodGet, "/users/1", "ops", "admin", nil); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("GET after DELETE = %d; want 404", res.StatusCode)
+	}
+}
@@ -0,0 +1,35 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gerry-sabar/byfood/internal/buildinfo"
+)
+
+// headerAppVersion names the build that answered, on every response.
+const headerAppVersion = "X-App-Version"
+
+// AppVersion sets X-App-Version to version on every response, so what is
+// deployed shows in any request's headers.
+func AppVersion(version string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(headerAppVersion, version)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// GET /version
+// --- Version ---
+// Version godoc
+// @Summary      Build of the running API
+// @Description  The version, git commit and build time stamped into the binary at build time, and the Go version it was built with. Fields that weren't stamped read "unknown". Every response also carries the version and short commit in X-App-Version.
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  buildinfo.Info
+// @Header       200  {string}  X-App-Version  "Version and short commit, as on every response"
+// @Router       /version [get]
+func (h *Handler) Version(w http.ResponseWriter, r *http.Request) {
+	jsonOK(w, buildinfo.Get())
+}
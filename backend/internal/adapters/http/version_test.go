@@ -0,0 +1,36 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/docs"
+	"github.com/gerry-sabar/byfood/internal/buildinfo"
+)
+
+func TestVersion(t *testing.T) {
+	v, err := NewSpecValidator([]byte(docs.SwaggerInfo.ReadDoc()))
+	if err != nil {
+		t.Fatalf("NewSpecValidator: %v", err)
+	}
+	ts := httptest.NewServer(AppVersion("1.4.0+3fff8f0")(v.Middleware(NewHandler(&mockBookService{}).Router())))
+	defer ts.Close()
+
+	res := do(t, ts, http.MethodGet, "/version", nil)
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK || res.Header.Get("X-App-Version") != "1.4.0+3fff8f0" {
+		t.Fatalf("GET /version = %d, X-App-Version %q", res.StatusCode, res.Header.Get("X-App-Version"))
+	}
+	var got buildinfo.Info
+	if err := json.NewDecoder(res.Body).Decode(&got); err != nil || got != buildinfo.Get() {
+		t.Fatalf("body = %+v, %v; want %+v", got, err, buildinfo.Get())
+	}
+
+	other := do(t, ts, http.MethodGet, "/url/profiles", nil)
+	other.Body.Close()
+	if other.Header.Get("X-App-Version") != "1.4.0+3fff8f0" {
+		t.Fatalf("X-App-Version missing from other responses")
+	}
+}
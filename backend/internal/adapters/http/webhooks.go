@@ -0,0 +1,208 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) webhookRoutes(r chi.Router) {
+	r.Use(requireScope(domain.ScopeAdmin))
+	r.Get("/", h.ListWebhooks)
+	r.Post("/", h.CreateWebhook)
+	r.Get("/{id}", h.GetWebhook)
+	r.Put("/{id}", h.UpdateWebhook)
+	r.Delete("/{id}", h.DeleteWebhook)
+	r.Get("/{id}/deliveries", h.ListWebhookDeliveries)
+}
+
+// GET /webhooks
+// --- ListWebhooks ---
+// ListWebhooks godoc
+// @Summary      List webhooks
+// @Description  Oldest first, without their secrets. Requires the admin scope.
+// @Tags         webhooks
+// @Produce      json
+// @Success      200  {array}   domain.Webhook
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /webhooks/ [get]
+func (h *Handler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	hooks, err := h.webhooks.ListWebhooks(r.Context())
+	if err != nil {
+		webhookError(w, err)
+		return
+	}
+	jsonOK(w, hooks)
+}
+
+// POST /webhooks
+// --- CreateWebhook ---
+// CreateWebhook godoc
+// @Summary      Register a webhook
+// @Description  Book events (`book.created`, `book.updated`, `book.deleted`, or those in `events`) are POSTed to `url` as they happen, with the event as the body. Each delivery carries `X-Webhook-Id`, `X-Webhook-Event`, `X-Webhook-Timestamp` and `X-Webhook-Signature: sha256=<hex>`, the HMAC-SHA256 of the timestamp, a dot and the body keyed with the secret. Without a `secret` one is generated; either way it is returned only here. The URL must be public http(s) and allowed by the egress allowlist. Requires the admin scope.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        body  body      ports.WebhookInput  true  "Webhook"
+// @Success      201   {object}  ports.CreatedWebhook
+// @Failure      400   {object}  ports.ErrorResponse
+// @Failure      403   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Failure      500   {object}  ports.ErrorResponse
+// @Router       /webhooks/ [post]
+func (h *Handler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var in ports.WebhookInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	hook, err := h.webhooks.CreateWebhook(r.Context(), in)
+	if err != nil {
+		webhookError(w, err)
+		return
+	}
+	jsonCreated(w, hook)
+}
+
+// GET /webhooks/{id}
+// --- GetWebhook ---
+// GetWebhook godoc
+// @Summary      Get a webhook
+// @Description  Requires the admin scope.
+// @Tags         webhooks
+// @Produce      json
+// @Param        id   path      int  true  "Webhook ID"  minimum(1)
+// @Success      200  {object}  domain.Webhook
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /webhooks/{id} [get]
+func (h *Handler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	hook, err := h.webhooks.GetWebhook(r.Context(), id)
+	if err != nil {
+		webhookError(w, err)
+		return
+	}
+	jsonOK(w, hook)
+}
+
+// PUT /webhooks/{id}
+// --- UpdateWebhook ---
+// UpdateWebhook godoc
+// @Summary      Change a webhook
+// @Description  Replaces the URL and events. A `secret` rotates the secret, effective from the next attempt; without one it is kept, as is `active`. Deliveries of an inactive webhook wait, and go out once it is active again. Requires the admin scope.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int                 true  "Webhook ID"  minimum(1)
+// @Param        body  body      ports.WebhookInput  true  "Webhook"
+// @Success      200   {object}  domain.Webhook
+// @Failure      400   {object}  ports.ErrorResponse
+// @Failure      403   {object}  ports.ErrorResponse
+// @Failure      404   {object}  ports.ErrorResponse
+// @Failure      422   {object}  validationPayload
+// @Failure      500   {object}  ports.ErrorResponse
+// @Router       /webhooks/{id} [put]
+func (h *Handler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	var in ports.WebhookInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	hook, err := h.webhooks.UpdateWebhook(r.Context(), id, in)
+	if err != nil {
+		webhookError(w, err)
+		return
+	}
+	jsonOK(w, hook)
+}
+
+// DELETE /webhooks/{id}
+// --- DeleteWebhook ---
+// DeleteWebhook godoc
+// @Summary      Delete a webhook
+// @Description  Its pending deliveries are dropped along with its delivery log. Requires the admin scope.
+// @Tags         webhooks
+// @Param        id   path  int  true  "Webhook ID"  minimum(1)
+// @Success      204  "No Content"
+// @Failure      400  {object}  ports.ErrorResponse
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      404  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /webhooks/{id} [delete]
+func (h *Handler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	if err := h.webhooks.DeleteWebhook(r.Context(), id); err != nil {
+		webhookError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /webhooks/{id}/deliveries
+// --- ListWebhookDeliveries ---
+// ListWebhookDeliveries godoc
+// @Summary      List the deliveries of a webhook
+// @Description  Newest first: each event sent or to be sent, its `status` (`pending` until it gets a 2xx or runs out of attempts), the number of attempts, and the HTTP status and error of the latest one. A failed delivery is tried 6 times, 30s after the first failure and twice as long after each next one, before it becomes `failed` and goes to the dead letter queue (kind `webhook_delivery`), from where an admin can retry it. Requires the admin scope.
+// @Tags         webhooks
+// @Produce      json
+// @Param        id     path      int  true   "Webhook ID"  minimum(1)
+// @Param        limit  query     int  false  "Max deliveries (default 50, max 500)"  minimum(1)
+// @Success      200    {array}   domain.WebhookDelivery
+// @Failure      400    {object}  ports.ErrorResponse
+// @Failure      403    {object}  ports.ErrorResponse
+// @Failure      404    {object}  ports.ErrorResponse
+// @Failure      500    {object}  ports.ErrorResponse
+// @Router       /webhooks/{id}/deliveries [get]
+func (h *Handler) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseIDParam(w, r)
+	if !ok {
+		return
+	}
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			httpError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+	deliveries, err := h.webhooks.WebhookDeliveries(r.Context(), id, limit)
+	if err != nil {
+		webhookError(w, err)
+		return
+	}
+	jsonOK(w, deliveries)
+}
+
+func webhookError(w http.ResponseWriter, err error) {
+	if ve, ok := err.(*appsvc.ValidationError); ok {
+		httpValidation(w, ve)
+		return
+	}
+	switch {
+	case err.Error() == "webhook not found":
+		httpError(w, http.StatusNotFound, err.Error())
+	default:
+		httpError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/docs"
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type mockWebhookService struct {
+	CreateFn     func(ctx context.Context, in ports.WebhookInput) (*ports.CreatedWebhook, error)
+	GetFn        func(ctx context.Context, id int64) (*domain.Webhook, error)
+	DeliveriesFn func(ctx context.Context, id int64, limit int) ([]domain.WebhookDelivery, error)
+}
+
+func (m *mockWebhookService) CreateWebhook(ctx context.Context, in ports.WebhookInput) (*ports.CreatedWebhook, error) {
+	return m.CreateFn(ctx, in)
+}
+func (m *mockWebhookService) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	return []domain.Webhook{}, nil
+}
+func (m *mockWebhookService) GetWebhook(ctx context.Context, id int64) (*domain.Webhook, error) {
+	return m.GetFn(ctx, id)
+}
+func (m *mockWebhookService) UpdateWebhook(ctx context.Context, id int64, in ports.WebhookInput) (*domain.Webhook, error) {
+	return nil, errors.New("webhook not found")
+}
+func (m *mockWebhookService) DeleteWebhook(ctx context.Context, id int64) error {
+	return nil
+}
+func (m *mockWebhookService) WebhookDeliveries(ctx context.Context, id int64, limit int) ([]domain.WebhookDelivery, error) {
+	return m.DeliveriesFn(ctx, id, limit)
+}
+
+func TestWebhooks_AdminEndpoints(t *testing.T) {
+	at := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	hook := domain.Webhook{ID: 1, URL: "https://hooks.example.com/x", Secret: "whsec_abc", Events: domain.EventTypes{"book.created"}, Active: true, CreatedAt: at, UpdatedAt: at}
+	var gotLimit int
+	svc := &mockWebhookService{
+		CreateFn: func(ctx context.Context, in ports.WebhookInput) (*ports.CreatedWebhook, error) {
+			if in.URL == "" {
+				return nil, &appsvc.ValidationError{Fields: map[string]string{"url": "URL is required"}}
+			}
+			return &ports.CreatedWebhook{Webhook: hook, Secret: hook.Secret}, nil
+		},
+		GetFn: func(ctx context.Context, id int64) (*domain.Webhook, error) {
+			if id != 1 {
+				return nil, errors.New("webhook not found")
+			}
+			return &hook, nil
+		},
+		DeliveriesFn: func(ctx context.Context, id int64, limit int) ([]domain.WebhookDelivery, error) {
+			gotLimit = limit
+			return []domain.WebhookDelivery{{
+				ID: 3, WebhookID: id, EventOffset: 40, EventType: "book.created", Status: domain.DeliveryPending,
+				Attempts: 1, ResponseStatus: 503, Error: "webhook answered 503", NextAttemptAt: &at, LastAttemptAt: &at, CreatedAt: at,
+			}}, nil
+		},
+	}
+	v, err := NewSpecValidator([]byte(docs.SwaggerInfo.ReadDoc()))
+	if err != nil {
+		t.Fatalf("NewSpecValidator: %v", err)
+	}
+	ts := httptest.NewServer(Identify(true)(v.Middleware(NewHandler(&mockBookService{}, WithWebhooks(svc)).Router())))
+	defer ts.Close()
+
+	call := func(method, path, scopes, body string) (int, string) {
+		req, _ := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User", "ops")
+		req.Header.Set("X-User-Scopes", scopes)
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("do request: %v", err)
+		}
+		return res.StatusCode, readBody(t, res)
+	}
+
+	if code, _ := call(http.MethodGet, "/webhooks/", "", ""); code != http.StatusForbidden {
+		t.Fatalf("without admin = %d", code)
+	}
+	// the secret is shown once, on create
+	code, body := call(http.MethodPost, "/webhooks/", domain.ScopeAdmin, `{"url":"https://hooks.example.com/x","events":["book.created"]}`)
+	if code != http.StatusCreated || !contains(body, `"secret":"whsec_abc"`) {
+		t.Fatalf("create = %d %s", code, body)
+	}
+	if code, body := call(http.MethodGet, "/webhooks/1", domain.ScopeAdmin, ""); code != http.StatusOK || contains(body, "whsec_abc") {
+		t.Fatalf("get = %d %s", code, body)
+	}
+	if code, body := call(http.MethodPost, "/webhooks/", domain.ScopeAdmin, `{"url":""}`); code != http.StatusUnprocessableEntity || !contains(body, `"url"`) {
+		t.Fatalf("invalid = %d %s", code, body)
+	}
+	if code, _ := call(http.MethodGet, "/webhooks/9", domain.ScopeAdmin, ""); code != http.StatusNotFound {
+		t.Fatalf("missing = %d", code)
+	}
+	code, body = call(http.MethodGet, "/webhooks/1/deliveries?limit=20", domain.ScopeAdmin, "")
+	if code != http.StatusOK || gotLimit != 20 || !contains(body, `"status":"pending"`) || !contains(body, `"response_status":503`) {
+		t.Fatalf("deliveries = %d %s", code, body)
+	}
+}
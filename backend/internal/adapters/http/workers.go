@@ -0,0 +1,87 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) workerRoutes(r chi.Router) {
+	r.Use(requireScope(domain.ScopeAdmin))
+	r.Get("/", h.ListWorkers)
+	r.Post("/{name}/pause", h.PauseWorker)
+	r.Post("/{name}/resume", h.ResumeWorker)
+}
+
+// GET /admin/workers
+// --- ListWorkers ---
+// ListWorkers godoc
+// @Summary      List background workers
+// @Description  State and last heartbeat of each background worker of the replica serving the request. Requires the admin scope.
+// @Tags         admin
+// @Produce      json
+// @Success      200  {array}   domain.WorkerStatus
+// @Failure      403  {object}  ports.ErrorResponse
+// @Failure      500  {object}  ports.ErrorResponse
+// @Router       /admin/workers/ [get]
+func (h *Handler) ListWorkers(w http.ResponseWriter, r *http.Request) {
+	ws, err := h.workers.ListWorkers(r.Context())
+	if err != nil {
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, ws)
+}
+
+// POST /admin/workers/{name}/pause
+// --- PauseWorker ---
+// PauseWorker godoc
+// @Summary      Pause a background worker
+// @Description  The worker stops after its current round, on every replica within a few seconds, until resumed. Requires the admin scope.
+// @Tags         admin
+// @Produce      json
+// @Param        name  path      string  true  "Worker name"
+// @Success      200   {object}  domain.WorkerStatus
+// @Failure      403   {object}  ports.ErrorResponse
+// @Failure      404   {object}  ports.ErrorResponse
+// @Failure      500   {object}  ports.ErrorResponse
+// @Router       /admin/workers/{name}/pause [post]
+func (h *Handler) PauseWorker(w http.ResponseWriter, r *http.Request) {
+	s, err := h.workers.PauseWorker(r.Context(), chi.URLParam(r, "name"))
+	if err != nil {
+		workerError(w, err)
+		return
+	}
+	jsonOK(w, s)
+}
+
+// POST /admin/workers/{name}/resume
+// --- ResumeWorker ---
+// ResumeWorker godoc
+// @Summary      Resume a paused background worker
+// @Description  Requires the admin scope.
+// @Tags         admin
+// @Produce      json
+// @Param        name  path      string  true  "Worker name"
+// @Success      200   {object}  domain.WorkerStatus
+// @Failure      403   {object}  ports.ErrorResponse
+// @Failure      404   {object}  ports.ErrorResponse
+// @Failure      500   {object}  ports.ErrorResponse
+// @Router       /admin/workers/{name}/resume [post]
+func (h *Handler) ResumeWorker(w http.ResponseWriter, r *http.Request) {
+	s, err := h.workers.ResumeWorker(r.Context(), chi.URLParam(r, "name"))
+	if err != nil {
+		workerError(w, err)
+		return
+	}
+	jsonOK(w, s)
+}
+
+func workerError(w http.ResponseWriter, err error) {
+	if err.Error() == "worker not found" {
+		httpError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	httpError(w, http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/docs"
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+type mockWorkerService struct {
+	ListFn   func(ctx context.Context) ([]domain.WorkerStatus, error)
+	PauseFn  func(ctx context.Context, name string) (*domain.WorkerStatus, error)
+	ResumeFn func(ctx context.Context, name string) (*domain.WorkerStatus, error)
+}
+
+func (m *mockWorkerService) ListWorkers(ctx context.Context) ([]domain.WorkerStatus, error) {
+	return m.ListFn(ctx)
+}
+func (m *mockWorkerService) PauseWorker(ctx context.Context, name string) (*domain.WorkerStatus, error) {
+	return m.PauseFn(ctx, name)
+}
+func (m *mockWorkerService) ResumeWorker(ctx context.Context, name string) (*domain.WorkerStatus, error) {
+	return m.ResumeFn(ctx, name)
+}
+
+func TestWorkers_AdminEndpoints(t *testing.T) {
+	set := func(state string) func(ctx context.Context, name string) (*domain.WorkerStatus, error) {
+		return func(ctx context.Context, name string) (*domain.WorkerStatus, error) {
+			if name != "saved_searches" {
+				return nil, errors.New("worker not found")
+			}
+			return &domain.WorkerStatus{Name: name, State: state, StallAfter: 120}, nil
+		}
+	}
+	svc := &mockWorkerService{
+		ListFn: func(ctx context.Context) ([]domain.WorkerStatus, error) {
+			return []domain.WorkerStatus{{Name: "saved_searches", State: domain.WorkerStalled, StallAfter: 120}}, nil
+		},
+		PauseFn:  set(domain.WorkerPaused),
+		ResumeFn: set(domain.WorkerRunning),
+	}
+	v, err := NewSpecValidator([]byte(docs.SwaggerInfo.ReadDoc()))
+	if err != nil {
+		t.Fatalf("NewSpecValidator: %v", err)
+	}
+	ts := httptest.NewServer(Identify(true)(v.Middleware(NewHandler(&mockBookService{}, WithWorkers(svc)).Router())))
+	defer ts.Close()
+
+	call := func(method, path, scopes string) (int, string) {
+		req, _ := http.NewRequest(method, ts.URL+path, nil)
+		req.Header.Set("X-User", "ops")
+		req.Header.Set("X-User-Scopes", scopes)
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("do request: %v", err)
+		}
+		return res.StatusCode, readBody(t, res)
+	}
+	if code, _ := call(http.MethodGet, "/admin/workers/", ""); code != http.StatusForbidden {
+		t.Fatalf("non-admin: %d", code)
+	}
+	if code, body := call(http.MethodGet, "/admin/workers/", "admin"); code != http.StatusOK || !contains(body, `"state":"stalled"`) {
+		t.Fatalf("list: %d %s", code, body)
+	}
+	if code, body := call(http.MethodPost, "/admin/workers/saved_searches/pause", "admin"); code != http.StatusOK || !contains(body, `"state":"paused"`) {
+		t.Fatalf("pause: %d %s", code, body)
+	}
+	if code, body := call(http.MethodPost, "/admin/workers/saved_searches/resume", "admin"); code != http.StatusOK || !contains(body, `"state":"running"`) {
+		t.Fatalf("resume: %d %s", code, body)
+	}
+	if code, _ := call(http.MethodPost, "/admin/workers/nope/pause", "admin"); code != http.StatusNotFound {
+		t.Fatalf("unknown worker: %d", code)
+	}
+}
@@ -0,0 +1,107 @@
+// Package kafka consumes the upstream ERP's catalogue topic and hands each
+// record to the catalogue inbox. It depends only on the small Reader
+// interface below, which a consumer-group reader of any Kafka client library
+// satisfies with a thin wrapper. Publishing the API's own events needs no
+// library: Producer speaks the little of the protocol it takes.
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/logger"
+)
+
+// Message is the part of a Kafka record the consumer needs.
+type Message struct {
+	Topic     string
+	Partition int
+	Offset    int64
+	Value     []byte
+}
+
+// Reader fetches records of a consumer group and commits their offsets.
+// Fetch blocks until a record arrives or ctx is done; records of one
+// partition come in offset order.
+type Reader interface {
+	Fetch(ctx context.Context) (Message, error)
+	Commit(ctx context.Context, m Message) error
+}
+
+// Handler applies one record; a non-nil error means it must be redelivered.
+type Handler interface {
+	Handle(ctx context.Context, source, messageID string, payload []byte) error
+}
+
+// Consumer feeds records from a Reader to a Handler, committing each offset
+// only after the handler succeeded. A failing record is retried with backoff
+// (up to maxBackoff) before anything after it, so per-partition order holds.
+type Consumer struct {
+	reader     Reader
+	handler    Handler
+	maxBackoff time.Duration
+}
+
+func NewConsumer(r Reader, h Handler) *Consumer {
+	return &Consumer{reader: r, handler: h, maxBackoff: 30 * time.Second}
+}
+
+// MessageID identifies a record when its payload carries no event id.
+func MessageID(m Message) string {
+	return fmt.Sprintf("%s/%d/%d", m.Topic, m.Partition, m.Offset)
+}
+
+// Run consumes until ctx is done.
+func (c *Consumer) Run(ctx context.Context) {
+	for {
+		m, err := c.reader.Fetch(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logger.From(ctx).Error("kafka fetch failed", "error", err)
+			if !sleep(ctx, time.Second) {
+				return
+			}
+			continue
+		}
+		if !c.handle(ctx, m) {
+			return
+		}
+	}
+}
+
+// handle retries m until it is handled and committed; false means ctx ended
+// first.
+func (c *Consumer) handle(ctx context.Context, m Message) bool {
+	backoff := 100 * time.Millisecond
+	for {
+		err := c.handler.Handle(ctx, m.Topic, MessageID(m), m.Value)
+		if err == nil {
+			if err := c.reader.Commit(ctx, m); err != nil && ctx.Err() == nil {
+				// only means a redelivery later, which the inbox absorbs
+				logger.From(ctx).Error("kafka commit failed", "topic", m.Topic, "partition", m.Partition, "offset", m.Offset, "error", err)
+			}
+			return true
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+		logger.From(ctx).Error("catalog record failed, retrying", "topic", m.Topic, "partition", m.Partition,
+			"offset", m.Offset, "in", backoff, "error", err)
+		if !sleep(ctx, backoff) {
+			return false
+		}
+		backoff = min(backoff*2, c.maxBackoff)
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
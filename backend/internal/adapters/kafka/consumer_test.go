@@ -0,0 +1,76 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeReader struct {
+	mu        sync.Mutex
+	msgs      []Message
+	committed []int64
+	done      context.CancelFunc
+}
+
+func (r *fakeReader) Fetch(ctx context.Context) (Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.msgs) == 0 {
+		r.done()
+		return Message{}, ctx.Err()
+	}
+	m := r.msgs[0]
+	r.msgs = r.msgs[1:]
+	return m, nil
+}
+
+func (r *fakeReader) Commit(ctx context.Context, m Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.committed = append(r.committed, m.Offset)
+	return nil
+}
+
+type flakyHandler struct {
+	calls []string
+	fail  int // fail this many calls before succeeding
+}
+
+func (h *flakyHandler) Handle(ctx context.Context, source, id string, payload []byte) error {
+	h.calls = append(h.calls, id)
+	if h.fail > 0 {
+		h.fail--
+		return errors.New("db down")
+	}
+	return nil
+}
+
+func TestConsumer_RetriesBeforeCommitting(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	r := &fakeReader{done: cancel, msgs: []Message{
+		{Topic: "erp.catalog", Partition: 0, Offset: 7, Value: []byte(`{}`)},
+		{Topic: "erp.catalog", Partition: 0, Offset: 8, Value: []byte(`{}`)},
+	}}
+	h := &flakyHandler{fail: 2}
+	c := NewConsumer(r, h)
+	c.maxBackoff = time.Millisecond
+
+	c.Run(ctx)
+
+	want := []string{"erp.catalog/0/7", "erp.catalog/0/7", "erp.catalog/0/7", "erp.catalog/0/8"}
+	if len(h.calls) != len(want) {
+		t.Fatalf("calls = %v; want %v", h.calls, want)
+	}
+	for i := range want {
+		if h.calls[i] != want[i] {
+			t.Fatalf("calls = %v; want %v", h.calls, want)
+		}
+	}
+	if len(r.committed) != 2 || r.committed[0] != 7 || r.committed[1] != 8 {
+		t.Fatalf("committed = %v; want [7 8]", r.committed)
+	}
+}
@@ -0,0 +1,332 @@
+package kafka
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"hash/fnv"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+const (
+	clientID = "byfood"
+	// requestTimeout bounds a request whose ctx has no deadline, and is the
+	// time a broker may wait for replicas before answering a produce.
+	requestTimeout = 10 * time.Second
+	// maxResponse guards against reading garbage as a huge length.
+	maxResponse = 16 << 20
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// Producer publishes domain events to one Kafka topic as JSON, keyed by the
+// entity id so the events of a book stay in order on one partition. It
+// speaks the protocol directly, and only what producing needs: Metadata to
+// find the partition leaders and Produce v3 with uncompressed record batches,
+// over plaintext connections to Kafka 0.11 or later. It sends one request at
+// a time and waits for every in-sync replica to acknowledge it.
+type Producer struct {
+	brokers []string
+	topic   string
+
+	mu          sync.Mutex
+	leaders     []string // address of each partition's leader, by partition
+	conns       map[string]*brokerConn
+	correlation int32
+}
+
+type brokerConn struct {
+	nc net.Conn
+	r  *bufio.Reader
+}
+
+// NewProducer publishes to topic on the cluster the host:port brokers
+// belong to. Nothing is dialled before the first Publish.
+func NewProducer(brokers []string, topic string) *Producer {
+	return &Producer{brokers: brokers, topic: topic, conns: map[string]*brokerConn{}}
+}
+
+// Publish appends e to its partition. A failure drops the connections and
+// the partition leaders the producer knew, then tries once more, so a
+// broker restart or a leader moving costs one retry.
+func (p *Producer) Publish(ctx context.Context, e domain.Event) error {
+	value, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	key := []byte(strconv.FormatInt(e.EntityID, 10))
+	headers := [][2]string{{"type", e.Type}}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for attempt := 0; ; attempt++ {
+		err = p.produce(ctx, key, value, headers, e.OccurredAt)
+		if err == nil {
+			return nil
+		}
+		p.reset()
+		if attempt == 1 || ctx.Err() != nil {
+			return err
+		}
+	}
+}
+
+// Close hangs up on every broker.
+func (p *Producer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.reset()
+	return nil
+}
+
+// Ping asks the first broker that answers for the cluster's metadata,
+// reporting whether the cluster is reachable.
+func Ping(ctx context.Context, brokers []string) error {
+	p := NewProducer(brokers, "")
+	defer p.Close()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := p.metadata(ctx, nil)
+	return err
+}
+
+func (p *Producer) reset() {
+	for addr, c := range p.conns {
+		c.nc.Close()
+		delete(p.conns, addr)
+	}
+	p.leaders = nil
+}
+
+func (p *Producer) produce(ctx context.Context, key, value []byte, headers [][2]string, at time.Time) error {
+	if p.leaders == nil {
+		leaders, err := p.metadata(ctx, []string{p.topic})
+		if err != nil {
+			return err
+		}
+		p.leaders = leaders
+	}
+	if len(p.leaders) == 0 {
+		return Error(3)
+	}
+	h := fnv.New32a()
+	h.Write(key)
+	partition := int32(h.Sum32() % uint32(len(p.leaders)))
+	c, err := p.conn(ctx, p.leaders[partition])
+	if err != nil {
+		return err
+	}
+
+	var req encoder
+	req.int16(-1) // no transactional id
+	req.int16(-1) // acks from every in-sync replica
+	req.int32(int32(requestTimeout / time.Millisecond))
+	req.int32(1)
+	req.string(p.topic)
+	req.int32(1)
+	req.int32(partition)
+	req.bytes(recordBatch(key, value, headers, at))
+	resp, err := p.roundTrip(ctx, c, apiProduce, 3, req.b)
+	if err != nil {
+		return err
+	}
+
+	d := decoder{b: resp}
+	var code int16
+	for range d.arrayLen() {
+		d.string()
+		for range d.arrayLen() {
+			d.int32()        // partition
+			code = d.int16() // error code
+			d.int64()        // base offset
+			d.int64()        // log append time
+		}
+	}
+	if d.err != nil {
+		return d.err
+	}
+	if code != 0 {
+		return Error(code)
+	}
+	return nil
+}
+
+// recordBatch encodes one record as a record batch of magic 2.
+func recordBatch(key, value []byte, headers [][2]string, at time.Time) []byte {
+	var rec encoder
+	rec.int8(0)   // attributes
+	rec.varint(0) // timestamp delta
+	rec.varint(0) // offset delta
+	rec.varbytes(key)
+	rec.varbytes(value)
+	rec.varint(int64(len(headers)))
+	for _, h := range headers {
+		rec.varbytes([]byte(h[0]))
+		rec.varbytes([]byte(h[1]))
+	}
+
+	ts := at.UnixMilli()
+	var b encoder
+	b.int64(0)  // base offset, assigned by the broker
+	b.int32(0)  // batch length, set below
+	b.int32(-1) // partition leader epoch
+	b.int8(2)   // magic
+	b.int32(0)  // crc, set below
+	b.int16(0)  // attributes: no compression, create time
+	b.int32(0)  // last offset delta
+	b.int64(ts) // first timestamp
+	b.int64(ts) // max timestamp
+	b.int64(-1) // producer id
+	b.int16(-1) // producer epoch
+	b.int32(-1) // base sequence
+	b.int32(1)  // records
+	b.varint(int64(len(rec.b)))
+	b.b = append(b.b, rec.b...)
+
+	binary.BigEndian.PutUint32(b.b[8:], uint32(len(b.b)-12))
+	binary.BigEndian.PutUint32(b.b[17:], crc32.Checksum(b.b[21:], castagnoli))
+	return b.b
+}
+
+// metadata asks the brokers in turn, until one answers, for the leaders of
+// the partitions of topics. With no topics it only checks that one answers.
+func (p *Producer) metadata(ctx context.Context, topics []string) ([]string, error) {
+	if len(p.brokers) == 0 {
+		return nil, errors.New("kafka: no brokers configured")
+	}
+	var req encoder
+	req.int32(int32(len(topics)))
+	for _, t := range topics {
+		req.string(t)
+	}
+	var errs []error
+	for _, addr := range p.brokers {
+		c, err := p.conn(ctx, addr)
+		if err == nil {
+			var resp []byte
+			if resp, err = p.roundTrip(ctx, c, apiMetadata, 1, req.b); err == nil {
+				return parseMetadata(resp)
+			}
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", addr, err))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// parseMetadata reads a Metadata v1 response for at most one topic into the
+// address of each partition's leader.
+func parseMetadata(resp []byte) ([]string, error) {
+	d := decoder{b: resp}
+	nodes := map[int32]string{}
+	for range d.arrayLen() {
+		id := d.int32()
+		host := d.string()
+		port := d.int32()
+		d.string() // rack
+		nodes[id] = net.JoinHostPort(host, strconv.Itoa(int(port)))
+	}
+	d.int32() // controller
+	var leaders []string
+	for range d.arrayLen() {
+		topicErr := d.int16()
+		d.string()
+		d.int8() // internal
+		n := d.arrayLen()
+		if topicErr != 0 && d.err == nil {
+			return nil, Error(topicErr)
+		}
+		leaders = make([]string, n)
+		for range n {
+			partErr := d.int16()
+			partition := d.int32()
+			leader := d.int32()
+			for range d.arrayLen() {
+				d.int32() // replica
+			}
+			for range d.arrayLen() {
+				d.int32() // in-sync replica
+			}
+			if d.err != nil {
+				return nil, d.err
+			}
+			addr, ok := nodes[leader]
+			switch {
+			case partErr != 0 && partErr != 9: // 9: a replica is down, the leader isn't
+				return nil, Error(partErr)
+			case !ok || partition < 0 || int(partition) >= n:
+				return nil, Error(5)
+			}
+			leaders[partition] = addr
+		}
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return leaders, nil
+}
+
+func (p *Producer) conn(ctx context.Context, addr string) (*brokerConn, error) {
+	if c, ok := p.conns[addr]; ok {
+		return c, nil
+	}
+	var dialer net.Dialer
+	nc, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	c := &brokerConn{nc: nc, r: bufio.NewReader(nc)}
+	p.conns[addr] = c
+	return c, nil
+}
+
+// roundTrip sends a request with body and returns the body of its response.
+func (p *Producer) roundTrip(ctx context.Context, c *brokerConn, apiKey, version int16, body []byte) ([]byte, error) {
+	p.correlation++
+	id := p.correlation
+	var req encoder
+	req.int32(0) // size, set below
+	req.int16(apiKey)
+	req.int16(version)
+	req.int32(id)
+	req.string(clientID)
+	req.b = append(req.b, body...)
+	binary.BigEndian.PutUint32(req.b, uint32(len(req.b)-4))
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(requestTimeout)
+	}
+	_ = c.nc.SetDeadline(deadline)
+	if _, err := c.nc.Write(req.b); err != nil {
+		return nil, err
+	}
+	var size [4]byte
+	if _, err := io.ReadFull(c.r, size[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n < 4 || n > maxResponse {
+		return nil, fmt.Errorf("kafka: bad response size %d", n)
+	}
+	resp := make([]byte, n)
+	if _, err := io.ReadFull(c.r, resp); err != nil {
+		return nil, err
+	}
+	if got := int32(binary.BigEndian.Uint32(resp)); got != id {
+		return nil, fmt.Errorf("kafka: response to request %d, want %d", got, id)
+	}
+	return resp[4:], nil
+}
@@ -0,0 +1,258 @@
+package kafka
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// produced is a record a fakeBroker took.
+type produced struct {
+	partition  int32
+	key, value string
+	headers    map[string]string
+	timestamp  int64
+}
+
+// fakeBroker is a one-node cluster leading both partitions of every topic.
+// It answers the first produceErrs produce requests with those error codes.
+type fakeBroker struct {
+	t  *testing.T
+	ln net.Listener
+
+	mu          sync.Mutex
+	produceErrs []int16
+	metadata    int
+	records     []produced
+}
+
+func newFakeBroker(t *testing.T, produceErrs ...int16) *fakeBroker {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	b := &fakeBroker{t: t, ln: ln, produceErrs: produceErrs}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { ln.Close() })
+	return b
+}
+
+func (b *fakeBroker) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		var size [4]byte
+		if _, err := io.ReadFull(conn, size[:]); err != nil {
+			return
+		}
+		req := make([]byte, binary.BigEndian.Uint32(size[:]))
+		if _, err := io.ReadFull(conn, req); err != nil {
+			return
+		}
+		d := decoder{b: req}
+		apiKey, version, id := d.int16(), d.int16(), d.int32()
+		if d.string() != clientID {
+			b.t.Errorf("client id missing")
+		}
+		var resp encoder
+		resp.int32(0)
+		resp.int32(id)
+		switch {
+		case apiKey == apiMetadata && version == 1:
+			b.answerMetadata(&d, &resp)
+		case apiKey == apiProduce && version == 3:
+			b.answerProduce(&d, &resp)
+		default:
+			b.t.Errorf("unexpected request %d v%d", apiKey, version)
+			return
+		}
+		binary.BigEndian.PutUint32(resp.b, uint32(len(resp.b)-4))
+		if _, err := conn.Write(resp.b); err != nil {
+			return
+		}
+	}
+}
+
+func (b *fakeBroker) answerMetadata(d *decoder, resp *encoder) {
+	b.mu.Lock()
+	b.metadata++
+	b.mu.Unlock()
+	host, port, _ := net.SplitHostPort(b.ln.Addr().String())
+	n, _ := strconv.Atoi(port)
+	resp.int32(1)
+	resp.int32(0)
+	resp.string(host)
+	resp.int32(int32(n))
+	resp.int16(-1) // rack
+	resp.int32(0)  // controller
+	topics := d.arrayLen()
+	resp.int32(int32(topics))
+	for range topics {
+		resp.int16(0)
+		resp.string(d.string())
+		resp.int8(0)
+		resp.int32(2)
+		for p := range int32(2) {
+			resp.int16(0)
+			resp.int32(p)
+			resp.int32(0) // leader
+			resp.int32(1)
+			resp.int32(0)
+			resp.int32(1)
+			resp.int32(0)
+		}
+	}
+}
+
+func (b *fakeBroker) answerProduce(d *decoder, resp *encoder) {
+	d.string() // transactional id
+	if acks := d.int16(); acks != -1 {
+		b.t.Errorf("acks = %d", acks)
+	}
+	d.int32() // timeout
+	d.arrayLen()
+	topic := d.string()
+	d.arrayLen()
+	partition := d.int32()
+	batch := d.take(int(d.int32()))
+	if d.err != nil {
+		b.t.Errorf("produce request: %v", d.err)
+		return
+	}
+
+	var code int16
+	b.mu.Lock()
+	if len(b.produceErrs) > 0 {
+		code, b.produceErrs = b.produceErrs[0], b.produceErrs[1:]
+	} else {
+		rec, err := decodeBatch(batch)
+		if err != nil {
+			b.t.Errorf("record batch: %v", err)
+		}
+		rec.partition = partition
+		b.records = append(b.records, rec)
+	}
+	b.mu.Unlock()
+
+	resp.int32(1)
+	resp.string(topic)
+	resp.int32(1)
+	resp.int32(partition)
+	resp.int16(code)
+	resp.int64(0)
+	resp.int64(-1)
+	resp.int32(0) // throttle time
+}
+
+// decodeBatch checks a magic 2 record batch of one record and returns it.
+func decodeBatch(batch []byte) (produced, error) {
+	d := decoder{b: batch}
+	d.int64() // base offset
+	if n := d.int32(); int(n) != len(batch)-12 {
+		return produced{}, errors.New("bad batch length")
+	}
+	d.int32() // leader epoch
+	if d.int8() != 2 {
+		return produced{}, errors.New("not magic 2")
+	}
+	if crc := uint32(d.int32()); crc != crc32.Checksum(batch[21:], crc32.MakeTable(crc32.Castagnoli)) {
+		return produced{}, errors.New("bad crc")
+	}
+	d.int16() // attributes
+	d.int32() // last offset delta
+	rec := produced{timestamp: d.int64(), headers: map[string]string{}}
+	d.int64() // max timestamp
+	d.int64() // producer id
+	d.int16() // producer epoch
+	d.int32() // base sequence
+	if d.int32() != 1 {
+		return produced{}, errors.New("want one record")
+	}
+	varint := func() int64 {
+		v, n := binary.Varint(d.b)
+		d.b = d.b[n:]
+		return v
+	}
+	varstring := func() string { return string(d.take(int(varint()))) }
+	if n := varint(); int(n) != len(d.b) {
+		return produced{}, errors.New("bad record length")
+	}
+	d.int8()
+	varint()
+	varint()
+	rec.key, rec.value = varstring(), varstring()
+	for range varint() {
+		k := varstring()
+		rec.headers[k] = varstring()
+	}
+	return rec, d.err
+}
+
+func TestProducer_PublishesKeyedByEntity(t *testing.T) {
+	// the first produce finds a stale leader, as after a leader election
+	b := newFakeBroker(t, 6)
+	p := NewProducer([]string{"127.0.0.1:1", b.ln.Addr().String()}, "byfood.events")
+	defer p.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	at := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	for _, e := range []domain.Event{
+		{Offset: 40, Type: "book.created", Entity: domain.EntityBook, EntityID: 7, Version: 1, Data: domain.ChangePayload(`{"id":7}`), OccurredAt: at},
+		{Offset: 41, Type: "book.deleted", Entity: domain.EntityBook, EntityID: 7, Version: 2, OccurredAt: at},
+	} {
+		if err := p.Publish(ctx, e); err != nil {
+			t.Fatalf("Publish %d: %v", e.Offset, err)
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.metadata != 2 {
+		t.Fatalf("metadata requests = %d; want 2, one more after the stale leader", b.metadata)
+	}
+	if len(b.records) != 2 {
+		t.Fatalf("records = %+v", b.records)
+	}
+	first := b.records[0]
+	if first.key != "7" || first.headers["type"] != "book.created" || first.timestamp != at.UnixMilli() ||
+		!strings.Contains(first.value, `"offset":40`) || !strings.Contains(first.value, `"data":{"id":7}`) {
+		t.Fatalf("record = %+v", first)
+	}
+	if b.records[1].partition != first.partition {
+		t.Fatalf("events of one book on partitions %d and %d", first.partition, b.records[1].partition)
+	}
+}
+
+func TestProducer_ReportsBrokerErrors(t *testing.T) {
+	b := newFakeBroker(t, 19, 19)
+	p := NewProducer([]string{b.ln.Addr().String()}, "byfood.events")
+	defer p.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := p.Publish(ctx, domain.Event{Offset: 1, Type: "book.created", EntityID: 1})
+	if !errors.Is(err, Error(19)) || err.Error() != "kafka: not enough replicas" {
+		t.Fatalf("Publish = %v", err)
+	}
+	if err := Ping(ctx, []string{b.ln.Addr().String()}); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+type aliasRepository struct{ s *Store }
+
+func (r aliasRepository) ListByBook(ctx context.Context, bookID int64) ([]domain.Alias, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	aliases := []domain.Alias{}
+	for _, a := range r.s.aliases {
+		if a.BookID == bookID {
+			aliases = append(aliases, a)
+		}
+	}
+	return aliases, nil
+}
+
+func (r aliasRepository) Add(ctx context.Context, a *domain.Alias) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.aliasID++
+	stored := *a
+	stored.ID = r.s.aliasID
+	r.s.aliases = append(r.s.aliases, stored)
+	return stored.ID, nil
+}
+
+func (r aliasRepository) Delete(ctx context.Context, bookID, id int64) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for i, a := range r.s.aliases {
+		if a.ID == id && a.BookID == bookID {
+			r.s.aliases = append(r.s.aliases[:i], r.s.aliases[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,150 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+type bookRepository struct{ s *Store }
+
+func (r bookRepository) List(ctx context.Context, f ports.ListFilter) ([]domain.Book, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	books := []domain.Book{}
+	for _, b := range r.s.books {
+		if b = r.s.withAliases(b); f.Matches(b) {
+			books = append(books, b)
+		}
+	}
+	// newest first, like the MySQL repository
+	slices.SortFunc(books, func(a, b domain.Book) int { return cmp.Compare(b.ID, a.ID) })
+	return books, nil
+}
+
+func (r bookRepository) Search(ctx context.Context, q string) ([]domain.Book, error) {
+	return r.List(ctx, ports.ListFilter{Q: q})
+}
+
+func (r bookRepository) ListPage(ctx context.Context, f ports.ListFilter, page ports.Page) ([]domain.Book, int, error) {
+	books, _ := r.List(ctx, f)
+	if page.Ranking != nil && strings.TrimSpace(f.Q) != "" {
+		// List is newest first, which the stable sort keeps for equal scores
+		slices.SortStableFunc(books, func(a, b domain.Book) int {
+			return cmp.Compare(f.Score(b, *page.Ranking), f.Score(a, *page.Ranking))
+		})
+	} else {
+		sortBooks(books, page.Sort)
+	}
+	end := len(books)
+	if page.Limit > 0 {
+		end = min(end, page.Offset+page.Limit)
+	}
+	return books[min(page.Offset, end):end], len(books), nil
+}
+
+// sortBooks orders books newest first by default, like the MySQL repository,
+// comparing strings with the ICU collation of s.Collation at primary
+// strength (ignoring case and accents) to match the _ai_ci MySQL collations.
+func sortBooks(books []domain.Book, s ports.Sort) {
+	tag := language.Und
+	if s.Collation != "" {
+		tag = language.Make(s.Collation)
+	}
+	col := collate.New(tag, collate.Loose)
+	key := map[string]func(a, b domain.Book) int{
+		"title":            func(a, b domain.Book) int { return col.CompareString(a.Title, b.Title) },
+		"author":           func(a, b domain.Book) int { return col.CompareString(a.Author, b.Author) },
+		"price":            func(a, b domain.Book) int { return cmp.Compare(a.Price, b.Price) },
+		"publication_year": func(a, b domain.Book) int { return cmp.Compare(a.PublicationYear, b.PublicationYear) },
+	}[s.Field]
+	slices.SortStableFunc(books, func(a, b domain.Book) int {
+		if key != nil {
+			c := key(a, b)
+			if s.Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		} else if s.Field == "id" && !s.Desc {
+			return cmp.Compare(a.ID, b.ID)
+		}
+		return cmp.Compare(b.ID, a.ID)
+	})
+}
+
+func (r bookRepository) GetByID(ctx context.Context, id int64) (*domain.Book, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	b, ok := r.s.books[id]
+	if !ok {
+		return nil, nil
+	}
+	b = r.s.withAliases(b)
+	return &b, nil
+}
+
+func (r bookRepository) Create(ctx context.Context, b *domain.Book) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return r.s.insert(*b), nil
+}
+
+// insert stores b under the next id. Callers hold s.mu.
+func (s *Store) insert(b domain.Book) int64 {
+	s.nextID++
+	b.ID, b.Aliases, b.Version = s.nextID, nil, 1
+	s.books[b.ID] = cloneBook(b)
+	return b.ID
+}
+
+func (r bookRepository) Update(ctx context.Context, b *domain.Book, prevUpdatedAt time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	cur, ok := r.s.books[b.ID]
+	if !ok || !cur.UpdatedAt.Equal(prevUpdatedAt) {
+		return ports.ErrConcurrentUpdate
+	}
+	next := cloneBook(*b)
+	next.Aliases, next.WorkID, next.CreatedAt = nil, cur.WorkID, cur.CreatedAt
+	next.Version = cur.Version + 1
+	b.Version = next.Version
+	r.s.books[b.ID] = next
+	return nil
+}
+
+func (r bookRepository) Delete(ctx context.Context, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	delete(r.s.books, id)
+	r.s.aliases = slices.DeleteFunc(r.s.aliases, func(a domain.Alias) bool { return a.BookID == id })
+	return nil
+}
+
+func (r bookRepository) Split(ctx context.Context, source *domain.Book, prevUpdatedAt time.Time, edition *domain.Book, aliasIDs []int64) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	cur, ok := r.s.books[source.ID]
+	if !ok || !cur.UpdatedAt.Equal(prevUpdatedAt) {
+		return 0, ports.ErrConcurrentUpdate
+	}
+	cur.WorkID, cur.UpdatedAt = source.WorkID, source.UpdatedAt
+	cur.Version++
+	source.Version = cur.Version
+	r.s.books[source.ID] = cloneBook(cur)
+	id := r.s.insert(*edition)
+	for i, a := range r.s.aliases {
+		if a.BookID == source.ID && slices.Contains(aliasIDs, a.ID) {
+			r.s.aliases[i].BookID = id
+		}
+	}
+	return id, nil
+}
@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+type changeRepository struct{ s *Store }
+
+func (r changeRepository) Append(ctx context.Context, c *domain.Change) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if c.Entity == "" {
+		c.Entity = domain.EntityBook
+	}
+	c.Version = 1
+	for _, prev := range r.s.changes {
+		if prev.Entity == c.Entity && prev.BookID == c.BookID {
+			c.Version = max(c.Version, prev.Version+1)
+		}
+	}
+	c.ID = 1
+	if n := len(r.s.changes); n > 0 {
+		c.ID = r.s.changes[n-1].ID + 1
+	}
+	r.s.changes = append(r.s.changes, *c)
+	return c.ID, nil
+}
+
+func (r changeRepository) ListSince(ctx context.Context, cursor int64, limit int) ([]domain.Change, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	changes := []domain.Change{}
+	for _, c := range r.s.changes {
+		if c.ID > cursor && len(changes) < limit {
+			changes = append(changes, c)
+		}
+	}
+	return changes, nil
+}
+
+func (r changeRepository) LatestID(ctx context.Context) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if n := len(r.s.changes); n > 0 {
+		return r.s.changes[n-1].ID, nil
+	}
+	return 0, nil
+}
+
+func (r changeRepository) BookAt(ctx context.Context, bookID int64, t time.Time) (*domain.Change, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for i := len(r.s.changes) - 1; i >= 0; i-- {
+		c := r.s.changes[i]
+		if c.Entity == domain.EntityBook && c.BookID == bookID && !c.CreatedAt.After(t) {
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r changeRepository) BooksAt(ctx context.Context, t time.Time) ([]domain.Change, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	latest := map[int64]domain.Change{}
+	for _, c := range r.s.changes {
+		if c.Entity == domain.EntityBook && !c.CreatedAt.After(t) {
+			latest[c.BookID] = c
+		}
+	}
+	changes := make([]domain.Change, 0, len(latest))
+	for _, c := range latest {
+		changes = append(changes, c)
+	}
+	slices.SortFunc(changes, func(a, b domain.Change) int { return cmp.Compare(b.BookID, a.BookID) })
+	return changes, nil
+}
+
+func (r changeRepository) Compact(ctx context.Context, cutoff time.Time) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	type key struct {
+		entity string
+		id     int64
+	}
+	latest := map[key]int64{}
+	for _, c := range r.s.changes {
+		k := key{c.Entity, c.BookID}
+		latest[k] = max(latest[k], c.Version)
+	}
+	kept := r.s.changes[:0]
+	for _, c := range r.s.changes {
+		if c.CreatedAt.Before(cutoff) && c.Version < latest[key{c.Entity, c.BookID}] {
+			continue
+		}
+		kept = append(kept, c)
+	}
+	n := int64(len(r.s.changes) - len(kept))
+	r.s.changes = kept
+	return n, nil
+}
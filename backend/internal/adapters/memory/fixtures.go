@@ -0,0 +1,32 @@
+package memory
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// DefaultFixtures is the catalogue demo mode starts with.
+//
+//go:embed fixtures/books.json
+var DefaultFixtures []byte
+
+// LoadFixtures decodes a JSON array of books in the API's book shape. Every
+// book needs an id, so references to it are stable across runs.
+func LoadFixtures(r io.Reader) ([]domain.Book, error) {
+	var books []domain.Book
+	if err := json.NewDecoder(r).Decode(&books); err != nil {
+		return nil, fmt.Errorf("decode fixtures: %w", err)
+	}
+	seen := map[int64]bool{}
+	for i, b := range books {
+		if b.ID <= 0 || seen[b.ID] {
+			return nil, fmt.Errorf("fixture %d: id must be positive and unique", i)
+		}
+		seen[b.ID] = true
+	}
+	return books, nil
+}
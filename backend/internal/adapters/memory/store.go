@@ -0,0 +1,69 @@
+// Package memory holds in-process implementations of the repositories. They
+// back demo mode, where the API must run without MySQL and give the same
+// answers on every run.
+package memory
+
+import (
+	"maps"
+	"sync"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// Store is one in-memory dataset shared by its repositories, the way one
+// database is shared by the MySQL ones. Ids are handed out sequentially, so
+// the same fixtures and requests always produce the same ids.
+type Store struct {
+	mu      sync.Mutex
+	books   map[int64]domain.Book
+	aliases []domain.Alias
+	changes []domain.Change
+	nextID  int64
+	aliasID int64
+}
+
+func NewStore() *Store {
+	return &Store{books: map[int64]domain.Book{}}
+}
+
+// Seed adds books, keeping their ids and aliases.
+func (s *Store) Seed(books []domain.Book) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, b := range books {
+		for _, a := range b.Aliases {
+			s.aliasID++
+			s.aliases = append(s.aliases, domain.Alias{ID: s.aliasID, BookID: b.ID, Alias: a, CreatedAt: b.CreatedAt})
+		}
+		b.Aliases, b.Version = nil, max(b.Version, 1)
+		s.books[b.ID] = cloneBook(b)
+		s.nextID = max(s.nextID, b.ID)
+	}
+}
+
+func (s *Store) Books() ports.BookRepository     { return bookRepository{s} }
+func (s *Store) Aliases() ports.AliasRepository  { return aliasRepository{s} }
+func (s *Store) Changes() ports.ChangeRepository { return changeRepository{s} }
+
+// withAliases returns a copy of b with its alias titles filled in, like the
+// MySQL repository returns it. Callers hold s.mu.
+func (s *Store) withAliases(b domain.Book) domain.Book {
+	b = cloneBook(b)
+	b.Aliases = []string{}
+	for _, a := range s.aliases {
+		if a.BookID == b.ID {
+			b.Aliases = append(b.Aliases, a.Alias)
+		}
+	}
+	return b
+}
+
+func cloneBook(b domain.Book) domain.Book {
+	b.FieldUpdatedAt = maps.Clone(b.FieldUpdatedAt)
+	if b.WorkID != nil {
+		w := *b.WorkID
+		b.WorkID = &w
+	}
+	return b
+}
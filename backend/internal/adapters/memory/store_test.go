@@ -0,0 +1,169 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+func seededStore(t *testing.T) *Store {
+	t.Helper()
+	books, err := LoadFixtures(bytes.NewReader(DefaultFixtures))
+	if err != nil {
+		t.Fatalf("LoadFixtures: %v", err)
+	}
+	s := NewStore()
+	s.Seed(books)
+	return s
+}
+
+func TestLoadFixtures_RejectsMissingIDs(t *testing.T) {
+	if _, err := LoadFixtures(strings.NewReader(`[{"id": 1}, {"id": 1}]`)); err == nil {
+		t.Fatalf("want duplicate id error")
+	}
+	if _, err := LoadFixtures(strings.NewReader(`[{"title": "x"}]`)); err == nil {
+		t.Fatalf("want missing id error")
+	}
+}
+
+func TestBookRepository_ListFiltersAndPages(t *testing.T) {
+	repo := seededStore(t).Books()
+	ctx := context.Background()
+
+	books, total, err := repo.ListPage(ctx, ports.ListFilter{Author: "gabriel garcia marquez"}, ports.Page{Limit: 1})
+	if err != nil || total != 2 || len(books) != 1 || books[0].ID != 4 {
+		t.Fatalf("ListPage = %+v, %d, %v", books, total, err)
+	}
+	found, _ := repo.Search(ctx, "solitude")
+	if len(found) != 1 || found[0].ID != 3 || found[0].Aliases[0] != "One Hundred Years of Solitude" {
+		t.Fatalf("Search by alias = %+v", found)
+	}
+}
+
+func TestBookRepository_CreateUpdateDelete(t *testing.T) {
+	s := seededStore(t)
+	repo := s.Books()
+	ctx := context.Background()
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	id, err := repo.Create(ctx, &domain.Book{Title: "Dune", CreatedAt: now, UpdatedAt: now})
+	if err != nil || id != 9 {
+		t.Fatalf("Create = %d, %v; want the next id after the fixtures", id, err)
+	}
+	b, _ := repo.GetByID(ctx, id)
+	b.Title = "Dune Messiah"
+	b.UpdatedAt = now.Add(time.Second)
+	if err := repo.Update(ctx, b, now); err != nil {
+		t.Fatalf("Update err: %v", err)
+	}
+	if stored, _ := repo.GetByID(ctx, id); b.Version != 2 || stored.Version != 2 {
+		t.Fatalf("version = %d, stored %d; want 2", b.Version, stored.Version)
+	}
+	if err := repo.Update(ctx, b, now); !errors.Is(err, ports.ErrConcurrentUpdate) {
+		t.Fatalf("stale Update = %v; want ErrConcurrentUpdate", err)
+	}
+	if err := repo.Delete(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := repo.GetByID(ctx, 3); got != nil {
+		t.Fatalf("deleted book still there: %+v", got)
+	}
+	if aliases, _ := s.Aliases().ListByBook(ctx, 3); len(aliases) != 0 {
+		t.Fatalf("aliases of deleted book survived: %+v", aliases)
+	}
+}
+
+func TestChangeRepository_Versions(t *testing.T) {
+	changes := NewStore().Changes()
+	ctx := context.Background()
+	for _, id := range []int64{1, 1, 2} {
+		if _, err := changes.Append(ctx, &domain.Change{BookID: id, Op: domain.ChangeUpdated}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, _ := changes.ListSince(ctx, 0, 10)
+	if len(got) != 3 || got[1].Version != 2 || got[2].Version != 1 || got[2].ID != 3 {
+		t.Fatalf("changes = %+v", got)
+	}
+	if n, _ := changes.LatestID(ctx); n != 3 {
+		t.Fatalf("LatestID = %d", n)
+	}
+}
+
+func TestBookRepository_SortsByLocale(t *testing.T) {
+	s := NewStore()
+	s.Seed([]domain.Book{{ID: 1, Title: "İnce Memed"}, {ID: 2, Title: "Irmak"}, {ID: 3, Title: "ılık"}})
+	titles := func(collation string) []string {
+		books, _, _ := s.Books().ListPage(context.Background(), ports.ListFilter{},
+			ports.Page{Sort: ports.Sort{Field: "title", Collation: collation}})
+		var out []string
+		for _, b := range books {
+			out = append(out, b.Title)
+		}
+		return out
+	}
+	// Turkish: I is the capital of dotless ı, which sorts before i
+	if got, want := titles("tr"), []string{"ılık", "Irmak", "İnce Memed"}; !slices.Equal(got, want) {
+		t.Fatalf("tr order = %v; want %v", got, want)
+	}
+	if got, want := titles(""), []string{"İnce Memed", "Irmak", "ılık"}; !slices.Equal(got, want) {
+		t.Fatalf("root order = %v; want %v", got, want)
+	}
+}
+
+func TestBookRepository_ExactFilters(t *testing.T) {
+	s := NewStore()
+	s.Seed([]domain.Book{{ID: 1, Title: "Ficciones", Author: "Jorge Luis Borges", ISBN: "9780802130303"}})
+	repo := s.Books()
+	cases := []struct {
+		f    ports.ListFilter
+		want int
+	}{
+		{ports.ListFilter{Author: "jorge luis borges"}, 1},
+		{ports.ListFilter{Author: "jorge luis borges", Exact: true}, 0},
+		{ports.ListFilter{Author: "Jorge Luis Borges", Exact: true}, 1},
+		{ports.ListFilter{ISBN: "978-0-8021-3030-3"}, 1},
+		{ports.ListFilter{ISBN: "978-0-8021-3030-3", Exact: true}, 0},
+		{ports.ListFilter{Q: "ficc"}, 1},
+		{ports.ListFilter{Q: "ficc", Exact: true}, 0},
+	}
+	for _, c := range cases {
+		if books, _ := repo.List(context.Background(), c.f); len(books) != c.want {
+			t.Errorf("%+v matched %d books; want %d", c.f, len(books), c.want)
+		}
+	}
+}
+
+func TestBookRepository_ListPageRanked(t *testing.T) {
+	s := NewStore()
+	s.Seed([]domain.Book{
+		{ID: 1, Title: "Tolkien: A Biography", Author: "Humphrey Carpenter", PublicationYear: 1977},
+		{ID: 2, Title: "The Hobbit", Author: "J.R.R. Tolkien", PublicationYear: 1937},
+		{ID: 3, Title: "The Silmarillion", Author: "J.R.R. Tolkien", PublicationYear: 1977},
+	})
+	f := ports.ListFilter{Q: "tolkien"}
+	for _, tc := range []struct {
+		weights domain.RankingWeights
+		want    []int64
+	}{
+		{domain.DefaultRankingWeights(), []int64{1, 3, 2}},
+		{domain.RankingWeights{TitleWeight: 1, AuthorWeight: 3}, []int64{3, 2, 1}},
+		{domain.RankingWeights{RecencyBoost: 10}, []int64{3, 1, 2}},
+	} {
+		books, _, err := s.Books().ListPage(context.Background(), f, ports.Page{Ranking: &ports.Ranking{RankingWeights: tc.weights, Year: 2026}})
+		ids := make([]int64, len(books))
+		for i, b := range books {
+			ids[i] = b.ID
+		}
+		if err != nil || !slices.Equal(ids, tc.want) {
+			t.Errorf("%+v: ids = %v, %v; want %v", tc.weights, ids, err, tc.want)
+		}
+	}
+}
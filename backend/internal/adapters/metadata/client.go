@@ -0,0 +1,60 @@
+// Package metadata looks books up in public catalogues (OpenLibrary, Google
+// Books) over HTTP. Callers pass an http.Client from urlsafe.Policy.Client,
+// so the timeout and the SSRF checks of every other outbound fetch apply.
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+)
+
+// maxResponseSize caps what a lookup reads; a single volume is a few
+// kilobytes.
+const maxResponseSize = 1 << 20
+
+// getJSON GETs target and decodes its JSON body into v.
+func getJSON(ctx context.Context, client *http.Client, userAgent, target string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		// drop the URL, which may carry an API key, before the error is logged
+		var ue *url.Error
+		if errors.As(err, &ue) {
+			return fmt.Errorf("%s: %w", ue.Op, ue.Err)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(v); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+var yearPattern = regexp.MustCompile(`\b(\d{4})\b`)
+
+// parseYear finds the year in a publication date such as "2008-08-01",
+// "August 2008" or "2008"; 0 when there is none.
+func parseYear(date string) int {
+	m := yearPattern.FindStringSubmatch(date)
+	if m == nil {
+		return 0
+	}
+	y, _ := strconv.Atoi(m[1])
+	return y
+}
@@ -0,0 +1,64 @@
+package metadata
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// GoogleBooksURL is the public Google Books API.
+const GoogleBooksURL = "https://www.googleapis.com"
+
+// GoogleBooks looks books up with the Google Books volumes search.
+type GoogleBooks struct {
+	client    *http.Client
+	baseURL   string
+	apiKey    string
+	userAgent string
+}
+
+var _ ports.MetadataProvider = (*GoogleBooks)(nil)
+
+// NewGoogleBooks sends apiKey with every request when set; without one
+// Google applies a lower anonymous quota.
+func NewGoogleBooks(client *http.Client, baseURL, apiKey, userAgent string) *GoogleBooks {
+	return &GoogleBooks{client: client, baseURL: strings.TrimSuffix(baseURL, "/"), apiKey: apiKey, userAgent: userAgent}
+}
+
+func (*GoogleBooks) Name() string { return "googlebooks" }
+
+type googleBooksVolumes struct {
+	Items []struct {
+		VolumeInfo struct {
+			Title         string   `json:"title"`
+			Authors       []string `json:"authors"`
+			PublishedDate string   `json:"publishedDate"`
+		} `json:"volumeInfo"`
+	} `json:"items"`
+}
+
+func (g *GoogleBooks) LookupISBN(ctx context.Context, isbn13 string) (*domain.BookMetadata, error) {
+	q := url.Values{"q": {"isbn:" + isbn13}, "maxResults": {"1"}}
+	if g.apiKey != "" {
+		q.Set("key", g.apiKey)
+	}
+	var res googleBooksVolumes
+	if err := getJSON(ctx, g.client, g.userAgent, g.baseURL+"/books/v1/volumes?"+q.Encode(), &res); err != nil {
+		return nil, err
+	}
+	if len(res.Items) == 0 || strings.TrimSpace(res.Items[0].VolumeInfo.Title) == "" {
+		return nil, nil
+	}
+	v := res.Items[0].VolumeInfo
+	return &domain.BookMetadata{
+		ISBN:            isbn13,
+		Title:           strings.TrimSpace(v.Title),
+		Author:          strings.Join(v.Authors, ", "),
+		PublicationYear: parseYear(v.PublishedDate),
+		Source:          g.Name(),
+	}, nil
+}
@@ -0,0 +1,101 @@
+package metadata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/urlsafe"
+)
+
+func testClient() *http.Client {
+	return urlsafe.Policy{AllowPrivate: true}.Client(time.Second)
+}
+
+func TestOpenLibrary(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/books" || r.URL.Query().Get("jscmd") != "data" || r.Header.Get("User-Agent") != "test-agent" {
+			t.Errorf("request = %s %v", r.URL, r.Header)
+		}
+		if r.URL.Query().Get("bibkeys") != "ISBN:9780132350884" {
+			w.Write([]byte(`{}`))
+			return
+		}
+		w.Write([]byte(`{"ISBN:9780132350884": {"title": "Clean Code", "authors": [{"name": "Robert C. Martin"}], "publish_date": "August 2008"}}`))
+	}))
+	defer srv.Close()
+	ol := NewOpenLibrary(testClient(), srv.URL+"/", "test-agent")
+
+	md, err := ol.LookupISBN(context.Background(), "9780132350884")
+	if err != nil || md == nil || md.Title != "Clean Code" || md.Author != "Robert C. Martin" || md.PublicationYear != 2008 || md.Source != "openlibrary" {
+		t.Fatalf("LookupISBN = %+v, %v", md, err)
+	}
+	if md, err := ol.LookupISBN(context.Background(), "9780306406157"); md != nil || err != nil {
+		t.Fatalf("unknown ISBN = %+v, %v", md, err)
+	}
+}
+
+func TestGoogleBooks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("q") {
+		case "isbn:9780132350884":
+			if r.URL.Query().Get("key") != "secret" {
+				t.Errorf("key = %q", r.URL.Query().Get("key"))
+			}
+			w.Write([]byte(`{"totalItems": 1, "items": [{"volumeInfo": {"title": "Clean Code", "authors": ["Robert C. Martin", "Dean Wampler"], "publishedDate": "2008-08-01"}}]}`))
+		case "isbn:9780306406157":
+			w.Write([]byte(`{"totalItems": 0}`))
+		default:
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer srv.Close()
+	gb := NewGoogleBooks(testClient(), srv.URL, "secret", "test-agent")
+
+	md, err := gb.LookupISBN(context.Background(), "9780132350884")
+	if err != nil || md == nil || md.Author != "Robert C. Martin, Dean Wampler" || md.PublicationYear != 2008 || md.Source != "googlebooks" {
+		t.Fatalf("LookupISBN = %+v, %v", md, err)
+	}
+	if md, err := gb.LookupISBN(context.Background(), "9780306406157"); md != nil || err != nil {
+		t.Fatalf("unknown ISBN = %+v, %v", md, err)
+	}
+	if _, err := gb.LookupISBN(context.Background(), "9791032305690"); err == nil || !strings.Contains(err.Error(), "429") {
+		t.Fatalf("quota err = %v", err)
+	}
+}
+
+func TestLookup_ErrorsHideTheURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer srv.Close()
+	gb := NewGoogleBooks(urlsafe.Policy{AllowPrivate: true}.Client(50*time.Millisecond), srv.URL, "secret", "test-agent")
+
+	_, err := gb.LookupISBN(context.Background(), "9780132350884")
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestLookup_RefusesPrivateAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached a private address")
+	}))
+	defer srv.Close()
+	ol := NewOpenLibrary(urlsafe.Policy{}.Client(time.Second), srv.URL, "test-agent")
+
+	if _, err := ol.LookupISBN(context.Background(), "9780132350884"); err == nil {
+		t.Fatal("lookup against localhost succeeded")
+	}
+}
+
+func TestParseYear(t *testing.T) {
+	for in, want := range map[string]int{"2008-08-01": 2008, "August 2008": 2008, "1967": 1967, "c. 1890s": 0, "": 0} {
+		if got := parseYear(in); got != want {
+			t.Fatalf("parseYear(%q) = %d; want %d", in, got, want)
+		}
+	}
+}
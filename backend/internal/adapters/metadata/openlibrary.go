@@ -0,0 +1,63 @@
+package metadata
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// OpenLibraryURL is the public OpenLibrary API.
+const OpenLibraryURL = "https://openlibrary.org"
+
+// OpenLibrary looks books up with OpenLibrary's Books API.
+type OpenLibrary struct {
+	client    *http.Client
+	baseURL   string
+	userAgent string
+}
+
+var _ ports.MetadataProvider = (*OpenLibrary)(nil)
+
+func NewOpenLibrary(client *http.Client, baseURL, userAgent string) *OpenLibrary {
+	return &OpenLibrary{client: client, baseURL: strings.TrimSuffix(baseURL, "/"), userAgent: userAgent}
+}
+
+func (*OpenLibrary) Name() string { return "openlibrary" }
+
+type openLibraryBook struct {
+	Title   string `json:"title"`
+	Authors []struct {
+		Name string `json:"name"`
+	} `json:"authors"`
+	PublishDate string `json:"publish_date"`
+}
+
+// LookupISBN asks /api/books, which answers an empty object for ISBNs it
+// doesn't know.
+func (o *OpenLibrary) LookupISBN(ctx context.Context, isbn13 string) (*domain.BookMetadata, error) {
+	key := "ISBN:" + isbn13
+	q := url.Values{"bibkeys": {key}, "format": {"json"}, "jscmd": {"data"}}
+	var res map[string]openLibraryBook
+	if err := getJSON(ctx, o.client, o.userAgent, o.baseURL+"/api/books?"+q.Encode(), &res); err != nil {
+		return nil, err
+	}
+	b, ok := res[key]
+	if !ok || strings.TrimSpace(b.Title) == "" {
+		return nil, nil
+	}
+	authors := make([]string, 0, len(b.Authors))
+	for _, a := range b.Authors {
+		authors = append(authors, a.Name)
+	}
+	return &domain.BookMetadata{
+		ISBN:            isbn13,
+		Title:           strings.TrimSpace(b.Title),
+		Author:          strings.Join(authors, ", "),
+		PublicationYear: parseYear(b.PublishDate),
+		Source:          o.Name(),
+	}, nil
+}
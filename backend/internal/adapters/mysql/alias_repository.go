@@ -0,0 +1,98 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type aliasRepository struct {
+	db *sqlx.DB
+}
+
+func NewAliasRepository(db *sqlx.DB) ports.AliasRepository {
+	return &aliasRepository{db: db}
+}
+
+func (r *aliasRepository) ListByBook(ctx context.Context, bookID int64) ([]domain.Alias, error) {
+	aliases := []domain.Alias{}
+	err := r.db.SelectContext(ctx, &aliases, `
+		SELECT id, book_id, alias, created_at
+		FROM book_aliases
+		WHERE book_id = ?
+		ORDER BY id ASC`, bookID)
+	if err != nil {
+		logger.From(ctx).Error("failed to list book aliases", "book_id", bookID, "error", err)
+	}
+	return aliases, err
+}
+
+func (r *aliasRepository) Add(ctx context.Context, a *domain.Alias) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO book_aliases (book_id, alias, alias_key, created_at)
+		VALUES (?, ?, ?, ?)`,
+		a.BookID, a.Alias, domain.SearchKey(a.Alias), a.CreatedAt,
+	)
+	if err != nil {
+		logger.From(ctx).Error("failed to add book alias", "book_id", a.BookID, "error", err)
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *aliasRepository) Delete(ctx context.Context, bookID, id int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM book_aliases WHERE id = ? AND book_id = ?`, id, bookID)
+	if err != nil {
+		logger.From(ctx).Error("failed to delete book alias", "book_id", bookID, "id", id, "error", err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// attachRelations fills in what books keep in other tables: their aliases,
+// categories and tags.
+func attachRelations(ctx context.Context, db *sqlx.DB, books []domain.Book) error {
+	if err := attachAliases(ctx, db, books); err != nil {
+		return err
+	}
+	if err := attachCategories(ctx, db, books); err != nil {
+		return err
+	}
+	return attachTags(ctx, db, books)
+}
+
+// attachAliases fills in Aliases for each book with a single query.
+func attachAliases(ctx context.Context, db *sqlx.DB, books []domain.Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(books))
+	byID := make(map[int64]*domain.Book, len(books))
+	for i := range books {
+		books[i].Aliases = []string{}
+		ids[i] = books[i].ID
+		byID[books[i].ID] = &books[i]
+	}
+	query, args, err := sqlx.In(`SELECT book_id, alias FROM book_aliases WHERE book_id IN (?) ORDER BY id ASC`, ids)
+	if err != nil {
+		return err
+	}
+	var rows []struct {
+		BookID int64  `db:"book_id"`
+		Alias  string `db:"alias"`
+	}
+	if err := db.SelectContext(ctx, &rows, db.Rebind(query), args...); err != nil {
+		logger.From(ctx).Error("failed to load book aliases", "error", err)
+		return err
+	}
+	for _, row := range rows {
+		if b := byID[row.BookID]; b != nil {
+			b.Aliases = append(b.Aliases, row.Alias)
+		}
+	}
+	return nil
+}
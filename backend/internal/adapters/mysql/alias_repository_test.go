@@ -0,0 +1,73 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+func TestAliasAdd_StoresKey(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	now := time.Now().UTC()
+	mock.ExpectExec("INSERT INTO book_aliases").
+		WithArgs(int64(3), "Cien años de soledad", "cien anos de soledad", now).
+		WillReturnResult(sqlmock.NewResult(8, 1))
+
+	r := NewAliasRepository(db)
+	id, err := r.Add(context.Background(), &domain.Alias{BookID: 3, Alias: "Cien años de soledad", CreatedAt: now})
+	if err != nil || id != 8 {
+		t.Fatalf("Add = %d, %v", id, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestAliasListByBook(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT id, book_id, alias, created_at FROM book_aliases WHERE book_id = \\?").
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "book_id", "alias", "created_at"}).
+			AddRow(int64(8), int64(3), "One Hundred Years of Solitude", now))
+
+	r := NewAliasRepository(db)
+	got, err := r.ListByBook(context.Background(), 3)
+	if err != nil || len(got) != 1 || got[0].Alias != "One Hundred Years of Solitude" {
+		t.Fatalf("ListByBook = %+v, %v", got, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestAliasDelete(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectExec("DELETE FROM book_aliases WHERE id = \\? AND book_id = \\?").
+		WithArgs(int64(8), int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM book_aliases").
+		WithArgs(int64(9), int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	r := NewAliasRepository(db)
+	if found, err := r.Delete(context.Background(), 3, 8); err != nil || !found {
+		t.Fatalf("Delete existing = %v, %v", found, err)
+	}
+	if found, err := r.Delete(context.Background(), 3, 9); err != nil || found {
+		t.Fatalf("Delete missing = %v, %v", found, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
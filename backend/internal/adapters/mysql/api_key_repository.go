@@ -0,0 +1,72 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+const apiKeyColumns = `id, name, key_hash, scopes, created_at, revoked_at`
+
+type apiKeyRepository struct {
+	db *sqlx.DB
+}
+
+func NewAPIKeyRepository(db *sqlx.DB) ports.APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, k *domain.APIKey) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO api_keys (name, key_hash, scopes, created_at) VALUES (?, ?, ?, ?)`,
+		k.Name, k.Hash, k.Scopes, k.CreatedAt)
+	if err != nil {
+		logger.From(ctx).Error("failed to create api key", "name", k.Name, "error", err)
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *apiKeyRepository) List(ctx context.Context) ([]domain.APIKey, error) {
+	out := []domain.APIKey{}
+	err := r.db.SelectContext(ctx, &out, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY id`)
+	if err != nil {
+		logger.From(ctx).Error("failed to list api keys", "error", err)
+	}
+	return out, err
+}
+
+func (r *apiKeyRepository) GetByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	var k domain.APIKey
+	err := r.db.GetContext(ctx, &k, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = ?`, hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to look up api key", "error", err)
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (r *apiKeyRepository) Revoke(ctx context.Context, id int64, at time.Time) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE api_keys SET revoked_at = COALESCE(revoked_at, ?) WHERE id = ?`, at, id)
+	if err != nil {
+		logger.From(ctx).Error("failed to revoke api key", "id", id, "error", err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil || n > 0 {
+		return n > 0, err
+	}
+	// MySQL counts changed rows only, so an already revoked key looks missing
+	err = r.db.GetContext(ctx, &n, `SELECT COUNT(*) FROM api_keys WHERE id = ?`, id)
+	return n > 0, err
+}
@@ -0,0 +1,49 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+func TestAPIKeyRepository(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	mock.ExpectExec("INSERT INTO api_keys").
+		WithArgs("warehouse", "abc", "admin,write", now).
+		WillReturnResult(sqlmock.NewResult(4, 1))
+	mock.ExpectQuery("SELECT id, name, key_hash, scopes, created_at, revoked_at FROM api_keys WHERE key_hash = \\?").
+		WithArgs("abc").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "key_hash", "scopes", "created_at", "revoked_at"}).
+			AddRow(4, "warehouse", "abc", "admin,write", now, nil))
+	mock.ExpectExec("UPDATE api_keys SET revoked_at = COALESCE\\(revoked_at, \\?\\) WHERE id = \\?").
+		WithArgs(now, int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM api_keys WHERE id = \\?").
+		WithArgs(int64(4)).
+		WillReturnRows(sqlmock.NewRows([]string{"n"}).AddRow(1))
+
+	repo := NewAPIKeyRepository(db)
+	ctx := context.Background()
+	id, err := repo.Create(ctx, &domain.APIKey{Name: "warehouse", Hash: "abc", Scopes: domain.ScopeList{"admin", "write"}, CreatedAt: now})
+	if err != nil || id != 4 {
+		t.Fatalf("Create = %d, %v", id, err)
+	}
+	k, err := repo.GetByHash(ctx, "abc")
+	if err != nil || k == nil || len(k.Scopes) != 2 || k.Scopes[1] != "write" || k.RevokedAt != nil {
+		t.Fatalf("GetByHash = %+v, %v", k, err)
+	}
+	// revoked before: nothing changes, but the key exists
+	if found, err := repo.Revoke(ctx, 4, now); err != nil || !found {
+		t.Fatalf("Revoke = %v, %v", found, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+// WithAuditLog makes book writes, re-prices included, append their audit
+// entries to audit_log in the transaction that writes the books, so a
+// change is never without its entry or the other way round.
+func WithAuditLog() RepoOption {
+	return func(o *repoOptions) { o.audit = true }
+}
+
+type auditRepository struct {
+	db *sqlx.DB
+}
+
+func NewAuditRepository(db *sqlx.DB) ports.AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) BookAudit(ctx context.Context, id int64, before int64, limit int) ([]domain.AuditEntry, error) {
+	q := sqlf(`
+		SELECT id, book_id, op, actor, impersonated_by, changes, created_at
+		FROM audit_log WHERE book_id = ?`, id)
+	if before > 0 {
+		q = q.append(sqlf(` AND id < ?`, before))
+	}
+	entries := []domain.AuditEntry{}
+	if err := selectSQL(ctx, r.db, &entries, q.append(sqlf(` ORDER BY id DESC LIMIT ?`, limit))); err != nil {
+		logger.From(ctx).Error("failed to read audit log", "id", id, "error", err)
+		return nil, err
+	}
+	return entries, nil
+}
+
+// lockBook reads book id for the audit entry of a write in tx, locking it
+// until the write commits; nil if there is no such book.
+func (r *bookRepository) lockBook(ctx context.Context, tx *sqlx.Tx, id int64) (*domain.Book, error) {
+	var b domain.Book
+	err := getSQL(ctx, tx, &b, sqlf(`SELECT `+r.bookColumns()+` FROM books WHERE id = ? FOR UPDATE`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to lock book", "id", id, "error", err)
+		return nil, err
+	}
+	return &b, nil
+}
+
+// appendAudit records in tx that op took book id from before to after, nil
+// for creates and deletes respectively.
+func appendAudit(ctx context.Context, tx sqlx.ExecerContext, op string, id int64, before, after *domain.Book, at time.Time) error {
+	return appendAuditDiff(ctx, tx, op, id, domain.DiffBooks(before, after), at)
+}
+
+// appendAuditDiff records diff as op on book id in tx, unless it is empty.
+// The actor in ctx, if any, is stamped on the entry.
+func appendAuditDiff(ctx context.Context, tx sqlx.ExecerContext, op string, id int64, diff domain.AuditDiff, at time.Time) error {
+	if len(diff) == 0 {
+		return nil
+	}
+	var actor domain.Actor
+	if a, ok := domain.ActorFrom(ctx); ok {
+		actor = a
+	}
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO audit_log (book_id, op, actor, impersonated_by, changes, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		id, op, actor.ID, actor.ImpersonatedBy, diff, at.Round(time.Microsecond))
+	if err != nil {
+		logger.From(ctx).Error("failed to append audit entry", "id", id, "op", op, "error", err)
+	}
+	return err
+}
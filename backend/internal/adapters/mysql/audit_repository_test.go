@@ -0,0 +1,82 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+func TestBookUpdate_AppendsAuditEntryInTransaction(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	prev := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	at := prev.Add(time.Hour)
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .* FROM books WHERE id = \\? FOR UPDATE").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "author", "isbn", "price", "publication_year", "updated_at", "version"}).
+			AddRow(int64(1), "Dune", "Frank Herbert", "9780441013593", 30.0, 1965, prev, 2))
+	mock.ExpectExec("UPDATE books").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO audit_log \\(book_id, op, actor, impersonated_by, changes, created_at\\)").
+		WithArgs(int64(1), "updated", "ann", "root", `{"price":{"old":30,"new":32.5}}`, at).
+		WillReturnResult(sqlmock.NewResult(9, 1))
+	mock.ExpectCommit()
+
+	r := NewBookRepository(db, WithAuditLog())
+	ctx := domain.WithActor(context.Background(), domain.Actor{ID: "ann", ImpersonatedBy: "root"})
+	b := &domain.Book{ID: 1, Title: "Dune", Author: "Frank Herbert", ISBN: "9780441013593", Price: 32.5, PublicationYear: 1965, UpdatedAt: at, Version: 2}
+	if err := r.Update(ctx, b, prev); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestBookDelete_AuditFailureRollsBack(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM books WHERE id = \\? FOR UPDATE").
+		WithArgs(int64(1)).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs(int64(1)).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery("SELECT .* FROM books WHERE id = \\? FOR UPDATE").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(int64(1), "Dune"))
+	mock.ExpectExec("DELETE FROM books WHERE id = \\?").WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO audit_log").WillReturnError(context.DeadlineExceeded)
+	mock.ExpectRollback()
+
+	if err := NewBookRepository(db, WithAuditLog()).Delete(context.Background(), 1); err == nil {
+		t.Fatal("Delete succeeded without its audit entry")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestBookAudit_NewestFirstBeforeCursor(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	at := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT id, book_id, op, actor, impersonated_by, changes, created_at FROM audit_log WHERE book_id = \\? AND id < \\? ORDER BY id DESC LIMIT \\?").
+		WithArgs(int64(1), int64(9), 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "book_id", "op", "actor", "impersonated_by", "changes", "created_at"}).
+			AddRow(int64(8), int64(1), "updated", "ann", "", []byte(`{"price":{"old":30,"new":32.5}}`), at).
+			AddRow(int64(3), int64(1), "created", "", "", []byte(`{"title":{"old":null,"new":"Dune"}}`), at.Add(-time.Hour)))
+
+	entries, err := NewAuditRepository(db).BookAudit(context.Background(), 1, 9, 2)
+	if err != nil || len(entries) != 2 || entries[0].Changes["price"].New != 32.5 || entries[1].Changes["title"].Old != nil {
+		t.Fatalf("BookAudit = %+v, %v", entries, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
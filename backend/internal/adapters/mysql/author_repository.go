@@ -0,0 +1,145 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+// authorProjection names the author read model in projection_cursors.
+const authorProjection = "authors"
+
+type authorRepository struct {
+	db *sqlx.DB
+}
+
+func NewAuthorRepository(db *sqlx.DB) ports.AuthorRepository {
+	return &authorRepository{db: db}
+}
+
+type authorSummaryRow struct {
+	ID        string    `db:"id"`
+	Name      string    `db:"name"`
+	BookCount int       `db:"book_count"`
+	FirstYear *int      `db:"first_year"`
+	LastYear  *int      `db:"last_year"`
+	MinPrice  float64   `db:"min_price"`
+	MaxPrice  float64   `db:"max_price"`
+	Books     []byte    `db:"books"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (r *authorRepository) Summary(ctx context.Context, id string) (*domain.AuthorSummary, error) {
+	var row authorSummaryRow
+	err := r.db.GetContext(ctx, &row, `
+		SELECT id, name, book_count, first_year, last_year, min_price, max_price, books, updated_at
+		FROM author_summaries WHERE id = ?`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to get author summary", "id", id, "error", err)
+		return nil, err
+	}
+	s := &domain.AuthorSummary{
+		ID: row.ID, Name: row.Name, BookCount: row.BookCount,
+		FirstYear: row.FirstYear, LastYear: row.LastYear,
+		MinPrice: row.MinPrice, MaxPrice: row.MaxPrice, UpdatedAt: row.UpdatedAt,
+	}
+	if err := json.Unmarshal(row.Books, &s.Books); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (r *authorRepository) SaveSummary(ctx context.Context, s *domain.AuthorSummary) error {
+	books, err := json.Marshal(s.Books)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO author_summaries (id, name, book_count, first_year, last_year, min_price, max_price, books, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE name = VALUES(name), book_count = VALUES(book_count),
+		    first_year = VALUES(first_year), last_year = VALUES(last_year),
+		    min_price = VALUES(min_price), max_price = VALUES(max_price),
+		    books = VALUES(books), updated_at = VALUES(updated_at)`,
+		s.ID, s.Name, s.BookCount, s.FirstYear, s.LastYear, s.MinPrice, s.MaxPrice, string(books), s.UpdatedAt,
+	)
+	if err != nil {
+		logger.From(ctx).Error("failed to save author summary", "id", s.ID, "error", err)
+	}
+	return err
+}
+
+func (r *authorRepository) DeleteSummary(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM author_summaries WHERE id = ?`, id)
+	if err != nil {
+		logger.From(ctx).Error("failed to delete author summary", "id", id, "error", err)
+	}
+	return err
+}
+
+func (r *authorRepository) IndexBook(ctx context.Context, bookID int64, authorID string) (string, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = tx.Rollback() }() // no-op after Commit
+
+	var prev string
+	err = tx.GetContext(ctx, &prev, `SELECT author_id FROM author_books WHERE book_id = ? FOR UPDATE`, bookID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", err
+	}
+	if authorID == "" {
+		_, err = tx.ExecContext(ctx, `DELETE FROM author_books WHERE book_id = ?`, bookID)
+	} else {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO author_books (book_id, author_id) VALUES (?, ?)
+			ON DUPLICATE KEY UPDATE author_id = VALUES(author_id)`, bookID, authorID)
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to index book author", "book_id", bookID, "error", err)
+		return "", err
+	}
+	return prev, tx.Commit()
+}
+
+func (r *authorRepository) Books(ctx context.Context, authorID string) ([]domain.Book, error) {
+	books := []domain.Book{}
+	err := r.db.SelectContext(ctx, &books, `
+		SELECT b.id, b.title, b.author, b.isbn, b.price, b.publication_year, b.created_at, b.updated_at, b.work_id
+		FROM books b
+		JOIN author_books a ON a.book_id = b.id
+		WHERE a.author_id = ?`, authorID)
+	if err != nil {
+		logger.From(ctx).Error("failed to list author books", "author_id", authorID, "error", err)
+	}
+	return books, err
+}
+
+func (r *authorRepository) Reset(ctx context.Context) error {
+	for _, q := range []string{`DELETE FROM author_books`, `DELETE FROM author_summaries`} {
+		if _, err := r.db.ExecContext(ctx, q); err != nil {
+			logger.From(ctx).Error("failed to reset author read model", "error", err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *authorRepository) Cursor(ctx context.Context) (int64, error) {
+	return loadCursor(ctx, r.db, authorProjection)
+}
+
+func (r *authorRepository) SaveCursor(ctx context.Context, cursor int64) error {
+	return saveCursor(ctx, r.db, authorProjection, cursor)
+}
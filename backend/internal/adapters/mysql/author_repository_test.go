@@ -0,0 +1,126 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+func TestAuthorSummary_Found(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	cols := []string{"id", "name", "book_count", "first_year", "last_year", "min_price", "max_price", "books", "updated_at"}
+	mock.ExpectQuery("SELECT (.+) FROM author_summaries WHERE id = \\?").
+		WithArgs("robert-c-martin").
+		WillReturnRows(sqlmock.NewRows(cols).AddRow("robert-c-martin", "Robert C. Martin", 1, 2008, 2008, 30.0, 30.0,
+			[]byte(`[{"id":1,"title":"Clean Code"}]`), time.Now()))
+
+	s, err := NewAuthorRepository(db).Summary(context.Background(), "robert-c-martin")
+	if err != nil {
+		t.Fatalf("Summary error: %v", err)
+	}
+	if s.Name != "Robert C. Martin" || *s.FirstYear != 2008 || len(s.Books) != 1 || s.Books[0].Title != "Clean Code" {
+		t.Fatalf("summary = %+v", s)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestAuthorSummary_NotFound(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectQuery("FROM author_summaries").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	s, err := NewAuthorRepository(db).Summary(context.Background(), "nobody")
+	if err != nil || s != nil {
+		t.Fatalf("Summary = %+v, %v; want nil, nil", s, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestAuthorSaveSummary(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	now := time.Now()
+	mock.ExpectExec("INSERT INTO author_summaries .* ON DUPLICATE KEY UPDATE").
+		WithArgs("a", "A", 1, nil, nil, 5.0, 5.0, `[{"id":1,"title":"T","isbn":"","publication_year":0,"price":5}]`, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	s := &domain.AuthorSummary{ID: "a", Name: "A", BookCount: 1, MinPrice: 5, MaxPrice: 5,
+		Books: []domain.AuthorBook{{ID: 1, Title: "T", Price: 5}}, UpdatedAt: now}
+	if err := NewAuthorRepository(db).SaveSummary(context.Background(), s); err != nil {
+		t.Fatalf("SaveSummary error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestAuthorIndexBook(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT author_id FROM author_books WHERE book_id = \\? FOR UPDATE").
+		WithArgs(int64(4)).
+		WillReturnRows(sqlmock.NewRows([]string{"author_id"}).AddRow("old-author"))
+	mock.ExpectExec("INSERT INTO author_books .* ON DUPLICATE KEY UPDATE author_id = VALUES\\(author_id\\)").
+		WithArgs(int64(4), "new-author").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	// removing a book that was never indexed
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT author_id FROM author_books").
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"author_id"}))
+	mock.ExpectExec("DELETE FROM author_books WHERE book_id = \\?").
+		WithArgs(int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	r := NewAuthorRepository(db)
+	prev, err := r.IndexBook(context.Background(), 4, "new-author")
+	if err != nil || prev != "old-author" {
+		t.Fatalf("IndexBook = %q, %v", prev, err)
+	}
+	prev, err = r.IndexBook(context.Background(), 5, "")
+	if err != nil || prev != "" {
+		t.Fatalf("IndexBook = %q, %v", prev, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestAuthorCursor(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT position FROM projection_cursors WHERE name = \\?").
+		WithArgs("authors").
+		WillReturnRows(sqlmock.NewRows([]string{"position"}))
+	mock.ExpectExec("INSERT INTO projection_cursors").
+		WithArgs("authors", int64(12)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	r := NewAuthorRepository(db)
+	if c, err := r.Cursor(context.Background()); err != nil || c != 0 {
+		t.Fatalf("Cursor = %d, %v; want 0 before the first build", c, err)
+	}
+	if err := r.SaveCursor(context.Background(), 12); err != nil {
+		t.Fatalf("SaveCursor error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -4,78 +4,513 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/gerry-sabar/byfood/internal/domain"
 	"github.com/gerry-sabar/byfood/internal/logger"
 	"github.com/gerry-sabar/byfood/internal/ports"
+	mysqldriver "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 )
 
 type bookRepository struct {
 	db *sqlx.DB
+	repoOptions
 }
 
-func NewBookRepository(db *sqlx.DB) ports.BookRepository {
-	return &bookRepository{db: db}
+func NewBookRepository(db *sqlx.DB, opts ...RepoOption) ports.BookRepository {
+	return &bookRepository{db: db, repoOptions: newRepoOptions(opts)}
 }
 
-func (r *bookRepository) List(ctx context.Context) ([]domain.Book, error) {
+// NewBookReader reads books from db without offering writes, e.g. from a
+// read replica.
+func NewBookReader(db *sqlx.DB, opts ...RepoOption) ports.BookReader {
+	return &bookRepository{db: db, repoOptions: newRepoOptions(opts)}
+}
+
+// bookColumns are the columns of book lists; the price column depends on
+// the price_cents migration.
+func (r *bookRepository) bookColumns() sqlText {
+	return "id, title, author, isbn, isbn10, " + r.priceSelect() + ", publication_year, created_at, updated_at, version, work_id, publisher_id, series_id, series_position, format"
+}
+
+func (r *bookRepository) List(ctx context.Context, f ports.ListFilter) ([]domain.Book, error) {
 	var books []domain.Book
-	err := r.db.SelectContext(ctx, &books, `
-		SELECT id, title, author, isbn, price, publication_year, created_at, updated_at
+	err := selectSQL(ctx, r.db, &books, r.listQuery(f))
+
+	if err != nil {
+		logger.From(ctx).Error("failed to list books", "filter", f, "error", err)
+		return books, err
+	}
+	return books, attachRelations(ctx, r.db, books)
+}
+
+// Search matches q against the folded title, author and aliases, so
+// "garcia marquez" finds "García Márquez".
+func (r *bookRepository) Search(ctx context.Context, q string) ([]domain.Book, error) {
+	var books []domain.Book
+	err := selectSQL(ctx, r.db, &books, r.searchQuery(q))
+	if err != nil {
+		logger.From(ctx).Error("failed to search books", "q", q, "error", err)
+		return books, err
+	}
+	return books, attachRelations(ctx, r.db, books)
+}
+
+func (r *bookRepository) listQuery(f ports.ListFilter) sqlQuery {
+	return sqlf(`
+		SELECT `+r.bookColumns()+`
+		FROM books`).append(listWhere(f), sqlf(`
+		ORDER BY id DESC`))
+}
+
+func (r *bookRepository) searchQuery(q string) sqlQuery {
+	return sqlf(`
+		SELECT `+r.bookColumns()+`
 		FROM books
-		ORDER BY id DESC`)
+		WHERE `).append(searchCond(q), sqlf(`
+		ORDER BY id DESC`))
+}
+
+// searchCond matches books with any of qs in their folded title, author or
+// aliases.
+func searchCond(qs ...string) sqlQuery {
+	conds := make([]sqlQuery, len(qs))
+	for i, q := range qs {
+		pattern := "%" + escapeLike(domain.SearchKey(q)) + "%"
+		conds[i] = sqlf(`title_key LIKE ? OR author_key LIKE ?
+		   OR EXISTS (SELECT 1 FROM book_aliases a WHERE a.book_id = books.id AND a.alias_key LIKE ?)`,
+			pattern, pattern, pattern)
+	}
+	return joinSQL(`
+		   OR `, conds)
+}
+
+// exactSearchCond is searchCond comparing the original columns byte for
+// byte, so case and accents must match.
+func exactSearchCond(q string) sqlQuery {
+	pattern := "%" + escapeLike(q) + "%"
+	return sqlf(`title LIKE ? COLLATE utf8mb4_bin OR author LIKE ? COLLATE utf8mb4_bin
+		   OR EXISTS (SELECT 1 FROM book_aliases a WHERE a.book_id = books.id AND a.alias LIKE ? COLLATE utf8mb4_bin)`,
+		pattern, pattern, pattern)
+}
+
+// listWhere turns f into a WHERE clause, or the empty query when f filters
+// nothing. Q matches as itself or any of f.Synonyms, Author matches the
+// whole name, ignoring case and accents, and ISBN ignores hyphens and
+// spaces; with f.Exact every string must match byte for byte.
+func listWhere(f ports.ListFilter) sqlQuery {
+	var conds []sqlQuery
+	if q := strings.TrimSpace(f.Q); q != "" {
+		cond := searchCond(append([]string{q}, f.Synonyms...)...)
+		if f.Exact {
+			cond = exactSearchCond(q)
+		}
+		conds = append(conds, sqlf("(").append(cond, sqlf(")")))
+	}
+	if a := strings.TrimSpace(f.Author); a != "" {
+		if f.Exact {
+			conds = append(conds, sqlf("author = ? COLLATE utf8mb4_bin", a))
+		} else {
+			conds = append(conds, sqlf("author_key = ?", domain.SearchKey(a)))
+		}
+	}
+	if isbn := strings.TrimSpace(f.ISBN); isbn != "" {
+		if f.Exact {
+			conds = append(conds, sqlf("isbn = ? COLLATE utf8mb4_bin", isbn))
+		} else {
+			// ISBNs are stored normalized, the ISBN-10 of an ISBN-13 in isbn10
+			key := domain.ISBNKey(isbn)
+			conds = append(conds, sqlf("(isbn = ? OR isbn10 = ?)", key, key))
+		}
+	}
+	if t := domain.TagKey(f.Tag); t != "" {
+		conds = append(conds, sqlf(`id IN (SELECT bt.book_id FROM book_tags bt JOIN tags t ON t.id = bt.tag_id WHERE t.tag_key = ?)`, t))
+	}
+	if f.YearFrom != nil {
+		conds = append(conds, sqlf("publication_year >= ?", *f.YearFrom))
+	}
+	if f.YearTo != nil {
+		conds = append(conds, sqlf("publication_year <= ?", *f.YearTo))
+	}
+	if f.PriceMin != nil {
+		conds = append(conds, sqlf("price >= ?", *f.PriceMin))
+	}
+	if f.PriceMax != nil {
+		conds = append(conds, sqlf("price <= ?", *f.PriceMax))
+	}
+	if f.SeriesID != 0 {
+		conds = append(conds, sqlf("series_id = ?", f.SeriesID))
+	}
+	if f.WorkID != 0 {
+		conds = append(conds, sqlf("(work_id = ? OR id = ?)", f.WorkID, f.WorkID))
+	}
+	if f.AfterID != 0 {
+		conds = append(conds, sqlf("id > ?", f.AfterID))
+	}
+	if len(conds) == 0 {
+		return sqlQuery{}
+	}
+	return sqlf(`
+		WHERE `).append(joinSQL(`
+		  AND `, conds))
+}
+
+// maxLimit is MySQL's documented way to say "no limit" when only an offset
+// is wanted.
+const maxLimit = "18446744073709551615"
+
+func (r *bookRepository) ListPage(ctx context.Context, f ports.ListFilter, page ports.Page) ([]domain.Book, int, error) {
+	count, list := r.pageQueries(f, page)
 
+	var total int
+	if err := getSQL(ctx, r.db, &total, count); err != nil {
+		logger.From(ctx).Error("failed to count books", "filter", f, "error", err)
+		return nil, 0, err
+	}
+
+	books := []domain.Book{}
+	err := selectSQL(ctx, r.db, &books, list)
 	if err != nil {
-		logger.Log.Error("failed to list books", "error", err)
+		logger.From(ctx).Error("failed to list books page", "filter", f, "limit", page.Limit, "offset", page.Offset, "error", err)
+		return books, 0, err
+	}
+	return books, total, attachRelations(ctx, r.db, books)
+}
+
+// pageQueries returns the queries of ListPage: the count of the books
+// matching f and the page of them.
+func (r *bookRepository) pageQueries(f ports.ListFilter, page ports.Page) (count, list sqlQuery) {
+	where := listWhere(f)
+	limit := sqlf(`
+		LIMIT `+maxLimit+` OFFSET ?`, page.Offset)
+	if page.Limit > 0 {
+		limit = sqlf(`
+		LIMIT ? OFFSET ?`, page.Limit, page.Offset)
+	}
+	order := sqlf(`
+		ORDER BY ` + orderBy(page.Sort))
+	if page.Ranking != nil && strings.TrimSpace(f.Q) != "" {
+		order = sqlf(`
+		ORDER BY `).append(rankScore(f, *page.Ranking), sqlf(` DESC, id DESC`))
+	}
+	count = sqlf(`SELECT COUNT(*) FROM books`).append(where)
+	list = sqlf(`
+		SELECT `+r.bookColumns()+`
+		FROM books`).append(where, order, limit)
+	return count, list
+}
+
+// rankScore is ports.ListFilter.Score of a book matching f.Q.
+func rankScore(f ports.ListFilter, r ports.Ranking) sqlQuery {
+	q := strings.TrimSpace(f.Q)
+	var titles, authors []sqlQuery
+	for _, q := range append([]string{q}, f.Synonyms...) {
+		pattern := "%" + escapeLike(domain.SearchKey(q)) + "%"
+		titles = append(titles, sqlf(`title_key LIKE ? OR EXISTS (SELECT 1 FROM book_aliases a WHERE a.book_id = books.id AND a.alias_key LIKE ?)`, pattern, pattern))
+		authors = append(authors, sqlf(`author_key LIKE ?`, pattern))
 	}
-	return books, err
+	title, author := joinSQL(` OR `, titles), joinSQL(` OR `, authors)
+	if f.Exact {
+		pattern := "%" + escapeLike(q) + "%"
+		title = sqlf(`title LIKE ? COLLATE utf8mb4_bin OR EXISTS (SELECT 1 FROM book_aliases a WHERE a.book_id = books.id AND a.alias LIKE ? COLLATE utf8mb4_bin)`, pattern, pattern)
+		author = sqlf(`author LIKE ? COLLATE utf8mb4_bin`, pattern)
+	}
+	return sqlf(`(CASE WHEN `).append(title, sqlf(` THEN ? ELSE 0 END
+		+ CASE WHEN `, r.TitleWeight), author, sqlf(` THEN ? ELSE 0 END
+		+ ? / (1 + GREATEST(? - publication_year, 0)))`, r.AuthorWeight, r.RecencyBoost, r.Year))
+}
+
+// mysqlCollations maps ports.SortCollations to MySQL 8 collations. German
+// (DIN 1) order is the default Unicode order.
+var mysqlCollations = map[string]sqlText{
+	"cs": "utf8mb4_cs_0900_ai_ci", "da": "utf8mb4_da_0900_ai_ci", "de": "utf8mb4_0900_ai_ci",
+	"es": "utf8mb4_es_0900_ai_ci", "hr": "utf8mb4_hr_0900_ai_ci", "hu": "utf8mb4_hu_0900_ai_ci",
+	"pl": "utf8mb4_pl_0900_ai_ci", "ro": "utf8mb4_ro_0900_ai_ci", "ru": "utf8mb4_ru_0900_ai_ci",
+	"sk": "utf8mb4_sk_0900_ai_ci", "sv": "utf8mb4_sv_0900_ai_ci", "tr": "utf8mb4_tr_0900_ai_ci",
+	"vi": "utf8mb4_vi_0900_ai_ci",
+}
+
+// orderBy renders s as an ORDER BY list. Field and collation come from fixed
+// sets checked by the service; anything else falls back to newest first.
+func orderBy(s ports.Sort) sqlText {
+	col := map[string]sqlText{
+		"title": "title", "author": "author", "price": "price", "publication_year": "publication_year",
+	}[s.Field]
+	if col == "" {
+		if s.Field == "id" && !s.Desc {
+			return "id ASC"
+		}
+		return "id DESC"
+	}
+	if c := mysqlCollations[s.Collation]; c != "" {
+		col += " COLLATE " + c
+	}
+	dir := sqlText(" ASC")
+	if s.Desc {
+		dir = " DESC"
+	}
+	return col + dir + ", id DESC"
 }
 
 func (r *bookRepository) GetByID(ctx context.Context, id int64) (*domain.Book, error) {
 	var b domain.Book
-	err := r.db.GetContext(ctx, &b, `
-		SELECT id, title, author, isbn, price, publication_year, created_at, updated_at
-		FROM books WHERE id = ?`, id)
+	err := getSQL(ctx, r.db, &b, sqlf(`
+		SELECT `+r.bookColumns()+`, field_updated_at
+		FROM books WHERE id = ?`, id))
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
 	}
 	if err != nil {
-		logger.Log.Error("failed to get book by id", "id", id, "error", err)
+		logger.From(ctx).Error("failed to get book by id", "id", id, "error", err)
+		return &b, err
 	}
-	return &b, err
+	books := []domain.Book{b}
+	if err := attachRelations(ctx, r.db, books); err != nil {
+		return nil, err
+	}
+	return &books[0], nil
 }
 
 func (r *bookRepository) Create(ctx context.Context, b *domain.Book) (int64, error) {
-	res, err := r.db.ExecContext(ctx, `
-		INSERT INTO books (title, author, isbn, price, publication_year, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)`,
-		b.Title, b.Author, b.ISBN, b.Price, b.PublicationYear, b.CreatedAt, b.UpdatedAt,
-	)
+	if !r.audit {
+		return r.insertBook(ctx, r.db, b)
+	}
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		logger.Log.Error("failed to create book", "book", b, "error", err)
 		return 0, err
 	}
+	defer func() { _ = tx.Rollback() }() // no-op after Commit
+
+	id, err := r.insertBook(ctx, tx, b)
+	if err != nil {
+		return 0, err
+	}
+	if err := appendAudit(ctx, tx, domain.AuditCreated, id, nil, b, b.UpdatedAt); err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+func (r *bookRepository) insertBook(ctx context.Context, db sqlx.ExecerContext, b *domain.Book) (int64, error) {
+	var cols sqlText
+	vals := sqlf("")
+	if d := r.priceCents; d != nil && d.WritesNew() {
+		d.dualWrites.Add(1)
+		cols, vals = ", price_cents", sqlf(", ?", toCents(b.Price))
+	}
+	res, err := execSQL(ctx, db, sqlf(`
+		INSERT INTO books (title, author, isbn, isbn10, price, publication_year, created_at, updated_at, field_updated_at, title_key, author_key,
+		                   work_id, publisher_id, series_id, series_position, format`+cols+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?`,
+		b.Title, b.Author, b.ISBN, b.ISBN10, b.Price, b.PublicationYear, b.CreatedAt, b.UpdatedAt, b.FieldUpdatedAt,
+		domain.SearchKey(b.Title), domain.SearchKey(b.Author), b.WorkID, b.PublisherID, b.SeriesID, b.SeriesPosition, b.Format,
+	).append(vals, sqlf(")")))
+	if err != nil {
+		logger.From(ctx).Error("failed to create book", "book", b, "error", err)
+		return 0, bookWriteError(err)
+	}
 	return res.LastInsertId()
 }
 
-func (r *bookRepository) Update(ctx context.Context, b *domain.Book) error {
-	_, err := r.db.ExecContext(ctx, `
+func (r *bookRepository) Update(ctx context.Context, b *domain.Book, prevUpdatedAt time.Time) error {
+	if !r.audit {
+		return r.updateBook(ctx, r.db, b, prevUpdatedAt)
+	}
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }() // no-op after Commit
+
+	prev, err := r.lockBook(ctx, tx, b.ID)
+	if err != nil {
+		return err
+	}
+	if err := r.updateBook(ctx, tx, b, prevUpdatedAt); err != nil {
+		return err
+	}
+	if err := appendAudit(ctx, tx, domain.AuditUpdated, b.ID, prev, b, b.UpdatedAt); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *bookRepository) updateBook(ctx context.Context, db sqlx.ExecerContext, b *domain.Book, prevUpdatedAt time.Time) error {
+	res, err := execSQL(ctx, db, sqlf(`
 		UPDATE books
-		SET title = ?, author = ?, isbn = ?, price = ?, publication_year = ?, updated_at = ?
-		WHERE id = ?`,
-		b.Title, b.Author, b.ISBN, b.Price, b.PublicationYear, b.UpdatedAt, b.ID,
+		SET title = ?, author = ?, isbn = ?, isbn10 = ?, price = ?, publication_year = ?, publisher_id = ?,
+		    series_id = ?, series_position = ?, format = ?, work_id = ?, updated_at = ?, field_updated_at = ?,
+		    title_key = ?, author_key = ?, version = version + 1`,
+		b.Title, b.Author, b.ISBN, b.ISBN10, b.Price, b.PublicationYear, b.PublisherID,
+		b.SeriesID, b.SeriesPosition, b.Format, b.WorkID, b.UpdatedAt, b.FieldUpdatedAt,
+		domain.SearchKey(b.Title), domain.SearchKey(b.Author),
+	).append(r.priceCentsAssign(b.Price), sqlf(`
+		WHERE id = ? AND updated_at = ?`, b.ID, prevUpdatedAt)))
+	if err != nil {
+		logger.From(ctx).Error("failed to update book", "id", b.ID, "error", err)
+		return bookWriteError(err)
+	}
+	// updated_at always moves forward, so zero rows means someone else won the race
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ports.ErrConcurrentUpdate
+	}
+	b.Version++ // the row matched, so it still had the version b was read with
+	return nil
+}
+
+func (r *bookRepository) Split(ctx context.Context, source *domain.Book, prevUpdatedAt time.Time, edition *domain.Book, aliasIDs []int64) (int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }() // no-op after Commit
+
+	var prev *domain.Book
+	if r.audit {
+		if prev, err = r.lockBook(ctx, tx, source.ID); err != nil {
+			return 0, err
+		}
+	}
+	res, err := tx.ExecContext(ctx, `
+		UPDATE books SET work_id = ?, updated_at = ?, version = version + 1
+		WHERE id = ? AND updated_at = ?`,
+		source.WorkID, source.UpdatedAt, source.ID, prevUpdatedAt,
 	)
 	if err != nil {
-		logger.Log.Error("failed to update book", "id", b.ID, "error", err)
+		logger.From(ctx).Error("failed to split book", "id", source.ID, "error", err)
+		return 0, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return 0, err
+	} else if n == 0 {
+		return 0, ports.ErrConcurrentUpdate
+	}
+	source.Version++
+
+	id, err := r.insertBook(ctx, tx, edition)
+	if err != nil {
+		return 0, err
 	}
-	return err
+	if r.audit {
+		if err := appendAudit(ctx, tx, domain.AuditUpdated, source.ID, prev, source, source.UpdatedAt); err != nil {
+			return 0, err
+		}
+		if err := appendAudit(ctx, tx, domain.AuditCreated, id, nil, edition, edition.UpdatedAt); err != nil {
+			return 0, err
+		}
+	}
+	if len(aliasIDs) > 0 {
+		query, args, err := sqlx.In(`UPDATE book_aliases SET book_id = ? WHERE book_id = ? AND id IN (?)`, id, source.ID, aliasIDs)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, tx.Rebind(query), args...); err != nil {
+			logger.From(ctx).Error("failed to move aliases to new edition", "id", source.ID, "error", err)
+			return 0, err
+		}
+	}
+	return id, tx.Commit()
 }
 
 func (r *bookRepository) Delete(ctx context.Context, id int64) error {
-	_, err := r.db.ExecContext(ctx, `DELETE FROM books WHERE id = ?`, id)
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		logger.Log.Error("failed to delete book", "id", id, "error", err)
+		return err
+	}
+	defer func() { _ = tx.Rollback() }() // no-op after Commit
+
+	found, onLoan, err := lockBookLoans(ctx, tx, id)
+	switch {
+	case err != nil:
+		return err
+	case !found:
+		return nil
+	case onLoan:
+		return ports.ErrBookOnLoan
+	}
+	var prev *domain.Book
+	if r.audit {
+		if prev, err = r.lockBook(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM books WHERE id = ?`, id); err != nil {
+		logger.From(ctx).Error("failed to delete book", "id", id, "error", err)
+		return err
+	}
+	if r.audit {
+		if err := appendAudit(ctx, tx, domain.AuditDeleted, id, prev, nil, time.Now().UTC()); err != nil {
+			return err
+		}
 	}
-	return err
+	return tx.Commit()
 }
+
+// BackfillSearchKeys fills title_key/author_key for rows written before the
+// columns existed. Rows already keyed are skipped, so running it on every
+// start is cheap.
+func BackfillSearchKeys(ctx context.Context, db *sqlx.DB) (int, error) {
+	var rows []struct {
+		ID     int64  `db:"id"`
+		Title  string `db:"title"`
+		Author string `db:"author"`
+	}
+	if err := db.SelectContext(ctx, &rows, `
+		SELECT id, title, author FROM books
+		WHERE title_key = '' OR author_key = ''`); err != nil {
+		return 0, err
+	}
+	for _, row := range rows {
+		if _, err := db.ExecContext(ctx, `UPDATE books SET title_key = ?, author_key = ? WHERE id = ?`,
+			domain.SearchKey(row.Title), domain.SearchKey(row.Author), row.ID); err != nil {
+			return 0, err
+		}
+	}
+	return len(rows), nil
+}
+
+// BackfillISBN13 stores the ISBN-13 of books written before ISBNs were
+// stored in that form, and the ISBN-10 of every book that has one.
+// canonical returns both forms of an ISBN, failing for invalid ones, which
+// are left alone. A book whose ISBN-13 another book already has is logged
+// and skipped. Converted rows are skipped, so running it on every start is
+// cheap.
+func BackfillISBN13(ctx context.Context, db *sqlx.DB, canonical func(isbn string) (isbn13, isbn10 string, err error)) (int, error) {
+	var rows []struct {
+		ID     int64  `db:"id"`
+		ISBN   string `db:"isbn"`
+		ISBN10 string `db:"isbn10"`
+	}
+	if err := db.SelectContext(ctx, &rows, `
+		SELECT id, isbn, isbn10 FROM books
+		WHERE CHAR_LENGTH(isbn) = 10 OR (isbn10 = '' AND isbn LIKE '978%')`); err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, row := range rows {
+		isbn13, isbn10, err := canonical(row.ISBN)
+		if err != nil || (isbn13 == row.ISBN && isbn10 == row.ISBN10) {
+			continue
+		}
+		if _, err := db.ExecContext(ctx, `UPDATE books SET isbn = ?, isbn10 = ? WHERE id = ?`, isbn13, isbn10, row.ID); err != nil {
+			var myErr *mysqldriver.MySQLError
+			if errors.As(err, &myErr) && myErr.Number == errDuplicateKey {
+				logger.From(ctx).Warn("ISBN-13 already taken, keeping the ISBN-10", "id", row.ID, "isbn", row.ISBN, "isbn13", isbn13)
+				continue
+			}
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(s string) string { return likeEscaper.Replace(s) }
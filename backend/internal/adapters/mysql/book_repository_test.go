@@ -3,14 +3,19 @@ package mysql
 import (
 	"context"
 	"database/sql/driver"
+	"errors"
+	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	mysqldriver "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 
 	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
 )
 
 // helper to create a sqlx DB backed by sqlmock
@@ -40,19 +45,40 @@ func TestList_Success(t *testing.T) {
 
 	// Keep the query matcher readable but specific
 	mock.ExpectQuery(regexp.QuoteMeta(`
-		SELECT id, title, author, isbn, price, publication_year, created_at, updated_at
+		SELECT id, title, author, isbn, isbn10, price, publication_year, created_at, updated_at, version, work_id, publisher_id, series_id, series_position, format
 		FROM books
 		ORDER BY id DESC`,
 	)).WillReturnRows(rows)
+	// aliases for the whole page are loaded with one query
+	mock.ExpectQuery("SELECT book_id, alias FROM book_aliases WHERE book_id IN \\(\\?, \\?\\)").
+		WithArgs(int64(2), int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "alias"}).AddRow(int64(1), "A: The Sequel"))
+	// and so are their categories
+	mock.ExpectQuery("SELECT book_id, category FROM book_categories WHERE book_id IN \\(\\?, \\?\\) ORDER BY category").
+		WithArgs(int64(2), int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "category"}).AddRow(int64(2), "fantasy").AddRow(int64(2), "poetry"))
+	// and their tags
+	mock.ExpectQuery("SELECT bt.book_id, t.name FROM book_tags bt JOIN tags t ON t.id = bt.tag_id WHERE bt.book_id IN \\(\\?, \\?\\) ORDER BY t.tag_key").
+		WithArgs(int64(2), int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "name"}).AddRow(int64(1), "Cozy Mystery"))
 
 	r := NewBookRepository(db)
-	books, err := r.List(context.Background())
+	books, err := r.List(context.Background(), ports.ListFilter{})
 	if err != nil {
 		t.Fatalf("List returned error: %v", err)
 	}
 	if len(books) != 2 {
 		t.Fatalf("got %d books; want 2", len(books))
 	}
+	if books[0].Aliases == nil || len(books[0].Aliases) != 0 || len(books[1].Aliases) != 1 {
+		t.Fatalf("aliases = %#v, %#v", books[0].Aliases, books[1].Aliases)
+	}
+	if len(books[0].Categories) != 2 || books[1].Categories == nil || len(books[1].Categories) != 0 {
+		t.Fatalf("categories = %#v, %#v", books[0].Categories, books[1].Categories)
+	}
+	if books[0].Tags == nil || len(books[0].Tags) != 0 || len(books[1].Tags) != 1 || books[1].Tags[0] != "Cozy Mystery" {
+		t.Fatalf("tags = %#v, %#v", books[0].Tags, books[1].Tags)
+	}
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Fatalf("unmet expectations: %v", err)
 	}
@@ -66,7 +92,7 @@ func TestList_Error(t *testing.T) {
 		WillReturnError(assertErr("boom"))
 
 	r := NewBookRepository(db)
-	_, err := r.List(context.Background())
+	_, err := r.List(context.Background(), ports.ListFilter{})
 	if err == nil {
 		t.Fatalf("expected error; got nil")
 	}
@@ -79,14 +105,21 @@ func TestGetByID_Found(t *testing.T) {
 	db, mock, cleanup := newMockSQLX(t)
 	defer cleanup()
 
-	cols := []string{"id", "title", "author", "isbn", "publication_year", "price", "created_at", "updated_at"}
+	cols := []string{"id", "title", "author", "isbn", "publication_year", "price", "created_at", "updated_at", "field_updated_at"}
 	now := time.Now()
 	rows := sqlmock.NewRows(cols).
-		AddRow(int64(1), "A", "AuthA", "ISBNA", 2001, 9.99, now, now)
+		AddRow(int64(1), "A", "AuthA", "ISBNA", 2001, 9.99, now, now, []byte(`{"title":"2024-01-02T03:04:05.123456Z"}`))
 
 	mock.ExpectQuery("SELECT .* FROM books WHERE id = \\?").
 		WithArgs(int64(1)).
 		WillReturnRows(rows)
+	mock.ExpectQuery("SELECT book_id, alias FROM book_aliases").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "alias"}))
+	mock.ExpectQuery("SELECT book_id, category FROM book_categories").
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "category"}))
+	mock.ExpectQuery("SELECT bt.book_id, t.name FROM book_tags bt").
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "name"}))
 
 	r := NewBookRepository(db)
 	got, err := r.GetByID(context.Background(), 1)
@@ -96,6 +129,9 @@ func TestGetByID_Found(t *testing.T) {
 	if got == nil || got.ID != 1 {
 		t.Fatalf("unexpected result: %#v", got)
 	}
+	if ts := got.FieldUpdatedAt["title"]; ts.Nanosecond() != 123456000 {
+		t.Fatalf("field_updated_at not decoded: %#v", got.FieldUpdatedAt)
+	}
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Fatalf("unmet expectations: %v", err)
 	}
@@ -147,14 +183,15 @@ func TestCreate_Success(t *testing.T) {
 	db, mock, cleanup := newMockSQLX(t)
 	defer cleanup()
 
-	// Expect INSERT with 7 args: title, author, isbn, publication_year, price, created_at, updated_at
+	// Expect INSERT with 16 args: title, author, isbn, isbn10, price, publication_year, created_at, updated_at, field_updated_at,
+	// the folded title and author search keys, work_id, publisher_id, series_id, series_position and format
 	mock.ExpectExec("INSERT INTO books").
-		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "cien anos de soledad", "gabriel garcia marquez", nil, nil, nil, nil, "").
 		WillReturnResult(sqlmock.NewResult(123, 1))
 
 	r := NewBookRepository(db)
 	id, err := r.Create(context.Background(), &domain.Book{
-		// fields can be zero; Exec args matched by AnyArg()
+		Title: "Cien años de soledad", Author: "Gabriel García Márquez",
 	})
 	if err != nil {
 		t.Fatalf("Create error: %v", err)
@@ -171,9 +208,9 @@ func TestCreate_Error(t *testing.T) {
 	db, mock, cleanup := newMockSQLX(t)
 	defer cleanup()
 
-	// 7 args with publication_year included
+	// 16 args with isbn10, publication_year, field_updated_at, the search keys, work_id, publisher_id and the series and format included
 	mock.ExpectExec("INSERT INTO books").
-		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnError(assertErr("insert failed"))
 
 	r := NewBookRepository(db)
@@ -190,16 +227,21 @@ func TestUpdate_Success(t *testing.T) {
 	db, mock, cleanup := newMockSQLX(t)
 	defer cleanup()
 
-	// Expect UPDATE with 7 args: title, author, isbn, publication_year, price, updated_at, id
-	mock.ExpectExec("UPDATE books").
-		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+	// Expect UPDATE with 17 args: title, author, isbn, isbn10, price, publication_year, publisher_id, series_id,
+	// series_position, format, work_id, updated_at, field_updated_at, title_key, author_key, id, previous updated_at
+	prev := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectExec("UPDATE books .* version = version \\+ 1 WHERE id = \\? AND updated_at = \\?").
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "l'etranger", "albert camus", int64(7), prev).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	r := NewBookRepository(db)
-	err := r.Update(context.Background(), &domain.Book{ID: 7})
-	if err != nil {
+	b := &domain.Book{ID: 7, Title: "L'Étranger", Author: "Albert Camus", Version: 4}
+	if err := r.Update(context.Background(), b, prev); err != nil {
 		t.Fatalf("Update error: %v", err)
 	}
+	if b.Version != 5 {
+		t.Fatalf("version = %d; want 5", b.Version)
+	}
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Fatalf("unmet expectations: %v", err)
 	}
@@ -209,13 +251,13 @@ func TestUpdate_Error(t *testing.T) {
 	db, mock, cleanup := newMockSQLX(t)
 	defer cleanup()
 
-	// 7 args including publication_year and id
+	// 17 args including isbn10, publication_year, publisher_id, the series, format, work_id, the search keys, id and the previous updated_at
 	mock.ExpectExec("UPDATE books").
-		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnError(assertErr("update failed"))
 
 	r := NewBookRepository(db)
-	err := r.Update(context.Background(), &domain.Book{ID: 7})
+	err := r.Update(context.Background(), &domain.Book{ID: 7}, time.Time{})
 	if err == nil {
 		t.Fatalf("expected error; got nil")
 	}
@@ -224,13 +266,393 @@ func TestUpdate_Error(t *testing.T) {
 	}
 }
 
+func TestUpdate_Concurrent(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	// updated_at no longer matches -> no row affected
+	mock.ExpectExec("UPDATE books").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	r := NewBookRepository(db)
+	err := r.Update(context.Background(), &domain.Book{ID: 7}, time.Time{})
+	if !errors.Is(err, ports.ErrConcurrentUpdate) {
+		t.Fatalf("err = %v; want ErrConcurrentUpdate", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSearch_FoldsAndEscapesQuery(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	cols := []string{"id", "title", "author", "isbn", "price", "publication_year", "created_at", "updated_at"}
+	now := time.Now()
+	mock.ExpectQuery("SELECT (.+) FROM books WHERE title_key LIKE \\? OR author_key LIKE \\?").
+		WithArgs(`%garcia\_marquez%`, `%garcia\_marquez%`, `%garcia\_marquez%`).
+		WillReturnRows(sqlmock.NewRows(cols).AddRow(1, "Cien años de soledad", "Gabriel García Márquez", "9780307474728", 15.0, 1967, now, now))
+	mock.ExpectQuery("SELECT book_id, alias FROM book_aliases").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "alias"}).AddRow(int64(1), "One Hundred Years of Solitude"))
+	mock.ExpectQuery("SELECT book_id, category FROM book_categories").
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "category"}))
+	mock.ExpectQuery("SELECT bt.book_id, t.name FROM book_tags bt").
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "name"}))
+
+	r := NewBookRepository(db)
+	books, err := r.Search(context.Background(), "  GARCÍA_Márquez ")
+	if err != nil {
+		t.Fatalf("Search error: %v", err)
+	}
+	if len(books) != 1 || books[0].Author != "Gabriel García Márquez" || len(books[0].Aliases) != 1 {
+		t.Fatalf("books = %+v", books)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestListPage_CountsAndLimits(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM books WHERE \\(title_key LIKE \\?").
+		WithArgs("%tolkien%", "%tolkien%", "%tolkien%").
+		WillReturnRows(sqlmock.NewRows([]string{"n"}).AddRow(42))
+	mock.ExpectQuery("SELECT (.+) FROM books WHERE (.+) ORDER BY id DESC LIMIT \\? OFFSET \\?").
+		WithArgs("%tolkien%", "%tolkien%", "%tolkien%", 10, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(int64(5), "The Hobbit"))
+	mock.ExpectQuery("SELECT book_id, alias FROM book_aliases").
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "alias"}))
+	mock.ExpectQuery("SELECT book_id, category FROM book_categories").
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "category"}))
+	mock.ExpectQuery("SELECT bt.book_id, t.name FROM book_tags bt").
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "name"}))
+
+	books, total, err := NewBookRepository(db).ListPage(context.Background(), ports.ListFilter{Q: "Tolkien"}, ports.Page{Limit: 10, Offset: 20})
+	if err != nil {
+		t.Fatalf("ListPage error: %v", err)
+	}
+	if total != 42 || len(books) != 1 || books[0].Title != "The Hobbit" {
+		t.Fatalf("got %d books, total %d", len(books), total)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestListPage_Ranked(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM books WHERE").
+		WillReturnRows(sqlmock.NewRows([]string{"n"}).AddRow(1))
+	mock.ExpectQuery("ORDER BY \\(CASE WHEN title_key LIKE \\? OR EXISTS (.+) THEN \\? ELSE 0 END (.+) CASE WHEN author_key LIKE \\? THEN \\? ELSE 0 END (.+) \\? / \\(1 \\+ GREATEST\\(\\? - publication_year, 0\\)\\)\\) DESC, id DESC LIMIT \\? OFFSET \\?").
+		WithArgs("%tolkien%", "%tolkien%", "%tolkien%", "%tolkien%", "%tolkien%", 3.0, "%tolkien%", 1.0, 0.5, 2026, 10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(int64(5), "The Hobbit"))
+	mock.ExpectQuery("SELECT book_id, alias FROM book_aliases").
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "alias"}))
+	mock.ExpectQuery("SELECT book_id, category FROM book_categories").
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "category"}))
+	mock.ExpectQuery("SELECT bt.book_id, t.name FROM book_tags bt").
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "name"}))
+
+	ranking := &ports.Ranking{RankingWeights: domain.RankingWeights{TitleWeight: 3, AuthorWeight: 1, RecencyBoost: 0.5}, Year: 2026}
+	books, _, err := NewBookRepository(db).ListPage(context.Background(), ports.ListFilter{Q: "Tolkien"}, ports.Page{Limit: 10, Ranking: ranking})
+	if err != nil || len(books) != 1 {
+		t.Fatalf("ListPage = %+v, %v", books, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestListPage_OffsetOnly(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM books$").
+		WillReturnRows(sqlmock.NewRows([]string{"n"}).AddRow(3))
+	mock.ExpectQuery("ORDER BY id DESC LIMIT 18446744073709551615 OFFSET \\?").
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+	mock.ExpectQuery("SELECT book_id, alias FROM book_aliases").
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "alias"}))
+	mock.ExpectQuery("SELECT book_id, category FROM book_categories").
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "category"}))
+	mock.ExpectQuery("SELECT bt.book_id, t.name FROM book_tags bt").
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "name"}))
+
+	if _, total, err := NewBookRepository(db).ListPage(context.Background(), ports.ListFilter{}, ports.Page{Offset: 2}); err != nil || total != 3 {
+		t.Fatalf("ListPage = %d, %v", total, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestListPage_AfterID(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM books WHERE id > \\?$").
+		WithArgs(int64(340)).
+		WillReturnRows(sqlmock.NewRows([]string{"n"}).AddRow(1))
+	mock.ExpectQuery("WHERE id > \\?\\s+ORDER BY id ASC\\s+LIMIT \\?").
+		WithArgs(int64(340), 50, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(341)))
+	mock.ExpectQuery("SELECT book_id, alias FROM book_aliases").
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "alias"}))
+	mock.ExpectQuery("SELECT book_id, category FROM book_categories").
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "category"}))
+	mock.ExpectQuery("SELECT bt.book_id, t.name FROM book_tags bt").
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "name"}))
+
+	books, total, err := NewBookRepository(db).ListPage(context.Background(), ports.ListFilter{AfterID: 340},
+		ports.Page{Limit: 50, Sort: ports.Sort{Field: "id"}})
+	if err != nil || total != 1 || len(books) != 1 || books[0].ID != 341 {
+		t.Fatalf("ListPage = %+v, %d, %v", books, total, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestListPage_SortsWithCollation(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM books$").
+		WillReturnRows(sqlmock.NewRows([]string{"n"}).AddRow(1))
+	mock.ExpectQuery("ORDER BY title COLLATE utf8mb4_tr_0900_ai_ci DESC, id DESC LIMIT").
+		WithArgs(0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+	mock.ExpectQuery("SELECT book_id, alias FROM book_aliases").
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "alias"}))
+	mock.ExpectQuery("SELECT book_id, category FROM book_categories").
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "category"}))
+	mock.ExpectQuery("SELECT bt.book_id, t.name FROM book_tags bt").
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "name"}))
+
+	page := ports.Page{Sort: ports.Sort{Field: "title", Desc: true, Collation: "tr"}}
+	if _, _, err := NewBookRepository(db).ListPage(context.Background(), ports.ListFilter{}, page); err != nil {
+		t.Fatalf("ListPage error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestOrderBy(t *testing.T) {
+	cases := []struct {
+		sort ports.Sort
+		want sqlText
+	}{
+		{ports.Sort{}, "id DESC"},
+		{ports.Sort{Field: "id"}, "id ASC"},
+		{ports.Sort{Field: "price", Desc: true}, "price DESC, id DESC"},
+		{ports.Sort{Field: "author", Collation: "de"}, "author COLLATE utf8mb4_0900_ai_ci ASC, id DESC"},
+		{ports.Sort{Field: "title", Collation: "sv"}, "title COLLATE utf8mb4_sv_0900_ai_ci ASC, id DESC"},
+	}
+	for _, c := range cases {
+		if got := orderBy(c.sort); got != c.want {
+			t.Errorf("orderBy(%+v) = %q; want %q", c.sort, got, c.want)
+		}
+	}
+}
+
+func TestList_Filters(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM books
+		WHERE author_key = ?
+		  AND publication_year >= ?
+		  AND publication_year <= ?
+		  AND price >= ?
+		  AND price <= ?
+		ORDER BY id DESC`)).
+		WithArgs("garcia marquez", 1960, 1970, 5.0, 20.0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(int64(1), "Cien años de soledad"))
+	mock.ExpectQuery("SELECT book_id, alias FROM book_aliases").
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "alias"}))
+	mock.ExpectQuery("SELECT book_id, category FROM book_categories").
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "category"}))
+	mock.ExpectQuery("SELECT bt.book_id, t.name FROM book_tags bt").
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "name"}))
+
+	y1, y2, p1, p2 := 1960, 1970, 5.0, 20.0
+	books, err := NewBookRepository(db).List(context.Background(), ports.ListFilter{
+		Author: " García Márquez ", YearFrom: &y1, YearTo: &y2, PriceMin: &p1, PriceMax: &p2,
+	})
+	if err != nil || len(books) != 1 {
+		t.Fatalf("List = %+v, %v", books, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestListWhere_ISBNAndExact(t *testing.T) {
+	q := listWhere(ports.ListFilter{Author: "Borges", ISBN: "978-0-14-303943-3"})
+	where, args := q.String(), q.Args()
+	if !strings.Contains(where, "author_key = ?") || !strings.Contains(where, "AND (isbn = ? OR isbn10 = ?)") ||
+		!reflect.DeepEqual(args, []any{"borges", "9780143039433", "9780143039433"}) {
+		t.Fatalf("folded: %q %v", where, args)
+	}
+
+	q = listWhere(ports.ListFilter{Q: "Ficc", Author: "Borges", ISBN: "978-0-14-303943-3", Exact: true})
+	where, args = q.String(), q.Args()
+	for _, want := range []string{"title LIKE ? COLLATE utf8mb4_bin", "a.alias LIKE ? COLLATE utf8mb4_bin",
+		"author = ? COLLATE utf8mb4_bin", "isbn = ? COLLATE utf8mb4_bin"} {
+		if !strings.Contains(where, want) {
+			t.Fatalf("exact where %q lacks %q", where, want)
+		}
+	}
+	if want := []any{"%Ficc%", "%Ficc%", "%Ficc%", "Borges", "978-0-14-303943-3"}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("exact args = %v; want %v", args, want)
+	}
+}
+
+func TestListWhere_Synonyms(t *testing.T) {
+	f := ports.ListFilter{Q: "Programming", Synonyms: []string{"coding"}}
+	q := listWhere(f)
+	if n := strings.Count(q.String(), "title_key LIKE ?"); n != 2 {
+		t.Fatalf("where %q matches %d searches; want 2", q, n)
+	}
+	if want := []any{"%programming%", "%programming%", "%programming%", "%coding%", "%coding%", "%coding%"}; !reflect.DeepEqual(q.Args(), want) {
+		t.Fatalf("args = %v; want %v", q.Args(), want)
+	}
+	rank := rankScore(f, ports.Ranking{RankingWeights: domain.DefaultRankingWeights(), Year: 2026})
+	if !strings.Contains(rank.String(), "CASE WHEN title_key LIKE ? OR EXISTS") || !strings.Contains(rank.String(), "author_key LIKE ? OR author_key LIKE ?") {
+		t.Fatalf("rank %q doesn't score the synonyms", rank)
+	}
+
+	f.Exact = true
+	if q := listWhere(f); !reflect.DeepEqual(q.Args(), []any{"%Programming%", "%Programming%", "%Programming%"}) {
+		t.Fatalf("exact where %q %v uses synonyms", q, q.Args())
+	}
+}
+
+func TestBackfillSearchKeys(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT id, title, author FROM books WHERE title_key = '' OR author_key = ''").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "author"}).AddRow(3, "Ficciones", "Jorge Luis Borges"))
+	mock.ExpectExec("UPDATE books SET title_key = \\?, author_key = \\? WHERE id = \\?").
+		WithArgs("ficciones", "jorge luis borges", int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	n, err := BackfillSearchKeys(context.Background(), db)
+	if err != nil || n != 1 {
+		t.Fatalf("BackfillSearchKeys = %d, %v", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestBackfillISBN13(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT id, isbn, isbn10 FROM books WHERE CHAR_LENGTH\\(isbn\\) = 10").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "isbn", "isbn10"}).
+			AddRow(1, "0321125215", "").
+			AddRow(2, "0306406152", "").
+			AddRow(3, "bad-isbn-x", ""))
+	update := "UPDATE books SET isbn = \\?, isbn10 = \\? WHERE id = \\?"
+	mock.ExpectExec(update).WithArgs("9780321125217", "0321125215", int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(update).WithArgs("9780306406157", "0306406152", int64(2)).
+		WillReturnError(&mysqldriver.MySQLError{Number: 1062, Message: "Duplicate entry"})
+
+	canonical := func(isbn string) (string, string, error) {
+		switch isbn {
+		case "0321125215":
+			return "9780321125217", isbn, nil
+		case "0306406152":
+			return "9780306406157", isbn, nil
+		}
+		return "", "", assertErr("invalid ISBN")
+	}
+	n, err := BackfillISBN13(context.Background(), db, canonical)
+	if err != nil || n != 1 {
+		t.Fatalf("BackfillISBN13 = %d, %v", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSplit_Success(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	prev := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	work := int64(7)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE books SET work_id = \\?, updated_at = \\?, version = version \\+ 1 WHERE id = \\? AND updated_at = \\?").
+		WithArgs(&work, now, int64(7), prev).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO books").
+		WillReturnResult(sqlmock.NewResult(12, 1))
+	mock.ExpectExec("UPDATE book_aliases SET book_id = \\? WHERE book_id = \\? AND id IN \\(\\?, \\?\\)").
+		WithArgs(int64(12), int64(7), int64(3), int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	r := NewBookRepository(db)
+	id, err := r.Split(context.Background(),
+		&domain.Book{ID: 7, WorkID: &work, UpdatedAt: now}, prev,
+		&domain.Book{WorkID: &work}, []int64{3, 4})
+	if err != nil || id != 12 {
+		t.Fatalf("Split = %d, %v", id, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSplit_ConcurrentRollsBack(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE books SET work_id").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	r := NewBookRepository(db)
+	_, err := r.Split(context.Background(), &domain.Book{ID: 7}, time.Time{}, &domain.Book{}, nil)
+	if !errors.Is(err, ports.ErrConcurrentUpdate) {
+		t.Fatalf("err = %v; want ErrConcurrentUpdate", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
 func TestDelete_Success(t *testing.T) {
 	db, mock, cleanup := newMockSQLX(t)
 	defer cleanup()
 
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM books WHERE id = \\? FOR UPDATE").
+		WithArgs(int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(9)))
+	mock.ExpectQuery("SELECT EXISTS \\(SELECT 1 FROM loans WHERE book_id = \\? AND returned_at IS NULL\\)").
+		WithArgs(int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"on_loan"}).AddRow(false))
 	mock.ExpectExec("DELETE FROM books WHERE id = \\?").
 		WithArgs(int64(9)).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
 
 	r := NewBookRepository(db)
 	err := r.Delete(context.Background(), 9)
@@ -246,9 +668,17 @@ func TestDelete_Error(t *testing.T) {
 	db, mock, cleanup := newMockSQLX(t)
 	defer cleanup()
 
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM books WHERE id = \\? FOR UPDATE").
+		WithArgs(int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(9)))
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs(int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"on_loan"}).AddRow(false))
 	mock.ExpectExec("DELETE FROM books WHERE id = \\?").
 		WithArgs(int64(9)).
 		WillReturnError(assertErr("delete failed"))
+	mock.ExpectRollback()
 
 	r := NewBookRepository(db)
 	err := r.Delete(context.Background(), 9)
@@ -260,6 +690,27 @@ func TestDelete_Error(t *testing.T) {
 	}
 }
 
+func TestDelete_OnLoan(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM books WHERE id = \\? FOR UPDATE").
+		WithArgs(int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(9)))
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs(int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"on_loan"}).AddRow(true))
+	mock.ExpectRollback()
+
+	if err := NewBookRepository(db).Delete(context.Background(), 9); !errors.Is(err, ports.ErrBookOnLoan) {
+		t.Fatalf("Delete = %v; want ErrBookOnLoan", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
 // --- small helper error type (avoids importing fmt just for errors) ---
 
 type assertErr string
@@ -0,0 +1,31 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type bookStats struct {
+	db *sqlx.DB
+}
+
+// NewBookStats estimates the size of the books table from InnoDB's
+// statistics, which is cheap but may be off by tens of percent.
+func NewBookStats(db *sqlx.DB) ports.BookCountEstimator {
+	return &bookStats{db: db}
+}
+
+func (s *bookStats) EstimateBooks(ctx context.Context) (int64, error) {
+	var n int64
+	err := s.db.GetContext(ctx, &n, `
+		SELECT COALESCE(TABLE_ROWS, 0) FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'books'`)
+	if err != nil {
+		logger.From(ctx).Error("failed to estimate books", "error", err)
+		return 0, err
+	}
+	return n, nil
+}
@@ -0,0 +1,24 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestEstimateBooks(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT COALESCE\\(TABLE_ROWS, 0\\) FROM information_schema.TABLES .* TABLE_NAME = 'books'").
+		WillReturnRows(sqlmock.NewRows([]string{"rows"}).AddRow(int64(250000)))
+
+	n, err := NewBookStats(db).EstimateBooks(context.Background())
+	if err != nil || n != 250000 {
+		t.Fatalf("EstimateBooks = %d, %v", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
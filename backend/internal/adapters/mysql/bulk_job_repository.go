@@ -0,0 +1,56 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type bulkJobRepository struct {
+	db *sqlx.DB
+}
+
+func NewBulkJobRepository(db *sqlx.DB) ports.BulkJobRepository {
+	return &bulkJobRepository{db: db}
+}
+
+func (r *bulkJobRepository) Create(ctx context.Context, j *domain.BulkJob) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO bulk_jobs (id, kind, status, total, done, error, actor, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		j.ID, j.Kind, j.Status, j.Total, j.Done, j.Error, j.Actor, j.CreatedAt, j.UpdatedAt)
+	if err != nil {
+		logger.From(ctx).Error("failed to create bulk job", "kind", j.Kind, "error", err)
+	}
+	return err
+}
+
+func (r *bulkJobRepository) Update(ctx context.Context, j *domain.BulkJob) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE bulk_jobs SET status = ?, done = ?, error = ?, updated_at = ? WHERE id = ?`,
+		j.Status, j.Done, j.Error, j.UpdatedAt, j.ID)
+	if err != nil {
+		logger.From(ctx).Error("failed to update bulk job", "job", j.ID, "error", err)
+	}
+	return err
+}
+
+func (r *bulkJobRepository) Get(ctx context.Context, id string) (*domain.BulkJob, error) {
+	var j domain.BulkJob
+	err := r.db.GetContext(ctx, &j, `
+		SELECT id, kind, status, total, done, error, actor, created_at, updated_at
+		FROM bulk_jobs WHERE id = ?`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to get bulk job", "job", id, "error", err)
+		return nil, err
+	}
+	return &j, nil
+}
@@ -0,0 +1,94 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type categoryRepository struct {
+	db *sqlx.DB
+}
+
+func NewCategoryRepository(db *sqlx.DB) ports.CategoryRepository {
+	return &categoryRepository{db: db}
+}
+
+func (r *categoryRepository) Assign(ctx context.Context, bookIDs []int64, add, remove []string) error {
+	if len(bookIDs) == 0 {
+		return nil
+	}
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }() // no-op after Commit
+
+	if len(add) > 0 {
+		rows := make([][]any, 0, len(bookIDs)*len(add))
+		for _, id := range bookIDs {
+			for _, c := range add {
+				rows = append(rows, []any{id, c})
+			}
+		}
+		// IGNORE skips categories a book has, and books deleted meanwhile
+		if _, err := execSQL(ctx, tx, sqlf(`
+			INSERT IGNORE INTO book_categories (book_id, category)
+			VALUES `).append(repeatSQL("(?, ?)", ", ", rows))); err != nil {
+			logger.From(ctx).Error("failed to add book categories", "books", len(bookIDs), "error", err)
+			return err
+		}
+	}
+	if len(remove) > 0 {
+		if _, err := execSQL(ctx, tx, sqlf(`
+			DELETE FROM book_categories
+			WHERE book_id IN (`).append(inList(bookIDs), sqlf(`) AND category IN (`), inList(remove), sqlf(`)`))); err != nil {
+			logger.From(ctx).Error("failed to remove book categories", "books", len(bookIDs), "error", err)
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// inList binds values to a comma separated list of placeholders, for IN.
+func inList[T any](values []T) sqlQuery {
+	rows := make([][]any, len(values))
+	for i, v := range values {
+		rows[i] = []any{v}
+	}
+	return repeatSQL("?", ", ", rows)
+}
+
+// attachCategories fills in the categories of books, sorted.
+func attachCategories(ctx context.Context, db *sqlx.DB, books []domain.Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(books))
+	byID := make(map[int64]*domain.Book, len(books))
+	for i := range books {
+		books[i].Categories = []string{}
+		ids[i] = books[i].ID
+		byID[books[i].ID] = &books[i]
+	}
+	var rows []struct {
+		BookID   int64  `db:"book_id"`
+		Category string `db:"category"`
+	}
+	if err := selectSQL(ctx, db, &rows, sqlf(`
+		SELECT book_id, category FROM book_categories
+		WHERE book_id IN (`).append(inList(ids), sqlf(`)
+		ORDER BY category`))); err != nil {
+		logger.From(ctx).Error("failed to load book categories", "error", err)
+		return err
+	}
+	for _, row := range rows {
+		if b := byID[row.BookID]; b != nil {
+			b.Categories = append(b.Categories, row.Category)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+func TestCategoryAssign(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT IGNORE INTO book_categories \\(book_id, category\\) VALUES \\(\\?, \\?\\), \\(\\?, \\?\\), \\(\\?, \\?\\), \\(\\?, \\?\\)").
+		WithArgs(int64(1), "poetry", int64(1), "sci-fi", int64(2), "poetry", int64(2), "sci-fi").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("DELETE FROM book_categories WHERE book_id IN \\(\\?, \\?\\) AND category IN \\(\\?\\)").
+		WithArgs(int64(1), int64(2), "fantasy").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	// removing only: no insert
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM book_categories").
+		WithArgs(int64(3), "fantasy").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT IGNORE INTO book_categories").
+		WillReturnError(assertErr("db down"))
+	mock.ExpectRollback()
+
+	r := NewCategoryRepository(db)
+	ctx := context.Background()
+	if err := r.Assign(ctx, []int64{1, 2}, []string{"poetry", "sci-fi"}, []string{"fantasy"}); err != nil {
+		t.Fatalf("Assign: %v", err)
+	}
+	if err := r.Assign(ctx, []int64{3}, nil, []string{"fantasy"}); err != nil {
+		t.Fatalf("Assign remove: %v", err)
+	}
+	if err := r.Assign(ctx, []int64{4}, []string{"poetry"}, nil); err == nil {
+		t.Fatal("Assign error swallowed")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestBulkJobRepository(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	j := &domain.BulkJob{ID: "abc", Kind: "bulk_tag", Status: domain.JobRunning, Total: 250, Actor: "ann", CreatedAt: now, UpdatedAt: now}
+	mock.ExpectExec("INSERT INTO bulk_jobs").
+		WithArgs("abc", "bulk_tag", domain.JobRunning, 250, 0, "", "ann", now, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE bulk_jobs SET status = \\?, done = \\?, error = \\?, updated_at = \\? WHERE id = \\?").
+		WithArgs(domain.JobDone, 250, "", now, "abc").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT .* FROM bulk_jobs WHERE id = \\?").
+		WithArgs("abc").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "kind", "status", "total", "done"}).
+			AddRow("abc", "bulk_tag", domain.JobDone, 250, 250))
+	mock.ExpectQuery("SELECT .* FROM bulk_jobs WHERE id = \\?").
+		WithArgs("nope").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	r := NewBulkJobRepository(db)
+	ctx := context.Background()
+	if err := r.Create(ctx, j); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	j.Status, j.Done = domain.JobDone, 250
+	if err := r.Update(ctx, j); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if got, err := r.Get(ctx, "abc"); err != nil || got.Done != 250 || got.Status != domain.JobDone {
+		t.Fatalf("Get = %+v, %v", got, err)
+	}
+	if got, err := r.Get(ctx, "nope"); err != nil || got != nil {
+		t.Fatalf("Get unknown = %+v, %v", got, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
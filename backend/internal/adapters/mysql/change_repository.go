@@ -0,0 +1,137 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type changeRepository struct {
+	db *sqlx.DB
+}
+
+func NewChangeRepository(db *sqlx.DB) ports.ChangeRepository {
+	return &changeRepository{db: db}
+}
+
+func (r *changeRepository) Append(ctx context.Context, c *domain.Change) (int64, error) {
+	if c.Entity == "" {
+		c.Entity = domain.EntityBook
+	}
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }() // no-op after Commit
+
+	// the sequence row serializes appends until commit, so ids become
+	// visible in order and readers following the log by id never skip one
+	var id int64
+	if err := tx.GetContext(ctx, &id, `
+		SELECT last_id FROM change_sequence WHERE name = 'book_changes' FOR UPDATE`); err != nil {
+		logger.From(ctx).Error("failed to lock the change sequence", "error", err)
+		return 0, err
+	}
+	id++
+	var version int64
+	if err := tx.GetContext(ctx, &version, `
+		SELECT COALESCE(MAX(version), 0) FROM book_changes
+		WHERE entity = ? AND book_id = ?`, c.Entity, c.BookID); err != nil {
+		logger.From(ctx).Error("failed to read book change version", "book_id", c.BookID, "error", err)
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO book_changes (id, entity, book_id, op, version, payload, actor, impersonated_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		id, c.Entity, c.BookID, c.Op, version+1, c.Payload, c.Actor, c.ImpersonatedBy, c.CreatedAt,
+	); err != nil {
+		logger.From(ctx).Error("failed to append book change", "book_id", c.BookID, "op", c.Op, "error", err)
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE change_sequence SET last_id = ? WHERE name = 'book_changes'`, id); err != nil {
+		logger.From(ctx).Error("failed to advance the change sequence", "error", err)
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	c.Version = version + 1
+	return id, nil
+}
+
+func (r *changeRepository) ListSince(ctx context.Context, cursor int64, limit int) ([]domain.Change, error) {
+	changes := []domain.Change{}
+	err := r.db.SelectContext(ctx, &changes, `
+		SELECT id, entity, book_id, op, version, payload, actor, impersonated_by, created_at
+		FROM book_changes
+		WHERE id > ?
+		ORDER BY id ASC
+		LIMIT ?`, cursor, limit)
+	if err != nil {
+		logger.From(ctx).Error("failed to list book changes", "cursor", cursor, "error", err)
+	}
+	return changes, err
+}
+
+func (r *changeRepository) LatestID(ctx context.Context) (int64, error) {
+	var id int64
+	err := r.db.GetContext(ctx, &id, `SELECT COALESCE(MAX(id), 0) FROM book_changes`)
+	if err != nil {
+		logger.From(ctx).Error("failed to read latest book change", "error", err)
+	}
+	return id, err
+}
+
+func (r *changeRepository) BookAt(ctx context.Context, bookID int64, t time.Time) (*domain.Change, error) {
+	var c domain.Change
+	err := r.db.GetContext(ctx, &c, `
+		SELECT id, entity, book_id, op, version, payload, actor, impersonated_by, created_at
+		FROM book_changes
+		WHERE entity = ? AND book_id = ? AND created_at <= ?
+		ORDER BY id DESC
+		LIMIT 1`, domain.EntityBook, bookID, t)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to read book change", "book_id", bookID, "at", t, "error", err)
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *changeRepository) BooksAt(ctx context.Context, t time.Time) ([]domain.Change, error) {
+	changes := []domain.Change{}
+	err := r.db.SelectContext(ctx, &changes, `
+		SELECT c.id, c.entity, c.book_id, c.op, c.version, c.payload, c.actor, c.impersonated_by, c.created_at
+		FROM book_changes c
+		JOIN (
+			SELECT MAX(id) AS id FROM book_changes
+			WHERE entity = ? AND created_at <= ?
+			GROUP BY book_id
+		) latest ON latest.id = c.id
+		ORDER BY c.book_id DESC`, domain.EntityBook, t)
+	if err != nil {
+		logger.From(ctx).Error("failed to read book changes", "at", t, "error", err)
+	}
+	return changes, err
+}
+
+func (r *changeRepository) Compact(ctx context.Context, cutoff time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE c FROM book_changes c
+		JOIN book_changes later
+		  ON later.entity = c.entity AND later.book_id = c.book_id AND later.version > c.version
+		WHERE c.created_at < ?`, cutoff)
+	if err != nil {
+		logger.From(ctx).Error("failed to compact book changes", "cutoff", cutoff, "error", err)
+		return 0, err
+	}
+	return res.RowsAffected()
+}
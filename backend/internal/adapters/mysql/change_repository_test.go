@@ -0,0 +1,191 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+func TestChangeAppend_Success(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	now := time.Now().UTC()
+	payload := domain.ChangePayload(`{"id":4}`)
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT last_id FROM change_sequence WHERE name = 'book_changes' FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows([]string{"last_id"}).AddRow(int64(76)))
+	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(version\\), 0\\) FROM book_changes WHERE entity = \\? AND book_id = \\?").
+		WithArgs(domain.EntityBook, int64(4)).
+		WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow(int64(2)))
+	mock.ExpectExec("INSERT INTO book_changes \\(id, entity").
+		WithArgs(int64(77), domain.EntityBook, int64(4), domain.ChangeUpdated, int64(3), `{"id":4}`, "ann", "root", now).
+		WillReturnResult(sqlmock.NewResult(77, 1))
+	mock.ExpectExec("UPDATE change_sequence SET last_id = \\? WHERE name = 'book_changes'").
+		WithArgs(int64(77)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	r := NewChangeRepository(db)
+	c := &domain.Change{BookID: 4, Op: domain.ChangeUpdated, Payload: payload, Actor: "ann", ImpersonatedBy: "root", CreatedAt: now}
+	id, err := r.Append(context.Background(), c)
+	if err != nil {
+		t.Fatalf("Append error: %v", err)
+	}
+	if id != 77 || c.Version != 3 {
+		t.Fatalf("id = %d, version = %d; want 77, 3", id, c.Version)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestChangeAppend_Error(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT last_id FROM change_sequence").
+		WillReturnRows(sqlmock.NewRows([]string{"last_id"}).AddRow(int64(0)))
+	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(version\\), 0\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow(int64(0)))
+	mock.ExpectExec("INSERT INTO book_changes").
+		WillReturnError(assertErr("insert failed"))
+	mock.ExpectRollback()
+
+	r := NewChangeRepository(db)
+	if _, err := r.Append(context.Background(), &domain.Change{}); err == nil {
+		t.Fatalf("expected error; got nil")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestChangeListSince_Success(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "entity", "book_id", "op", "version", "payload", "created_at"}).
+		AddRow(int64(11), domain.EntityBook, int64(1), domain.ChangeCreated, int64(1), []byte(`{"id":1,"title":"A"}`), now).
+		AddRow(int64(12), domain.EntityBook, int64(1), domain.ChangeDeleted, int64(2), nil, now)
+
+	mock.ExpectQuery("SELECT .* FROM book_changes WHERE id > \\? ORDER BY id ASC LIMIT \\?").
+		WithArgs(int64(10), 50).
+		WillReturnRows(rows)
+
+	r := NewChangeRepository(db)
+	got, err := r.ListSince(context.Background(), 10, 50)
+	if err != nil {
+		t.Fatalf("ListSince error: %v", err)
+	}
+	if len(got) != 2 || got[0].ID != 11 || got[1].Op != domain.ChangeDeleted || got[1].Version != 2 {
+		t.Fatalf("unexpected result: %#v", got)
+	}
+	if b, err := got[0].Book(); err != nil || b.Title != "A" {
+		t.Fatalf("payload = %+v, %v", b, err)
+	}
+	if b, _ := got[1].Book(); b != nil {
+		t.Fatalf("delete should carry no payload: %+v", b)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestChangeListSince_Error(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT .* FROM book_changes").
+		WillReturnError(assertErr("db down"))
+
+	r := NewChangeRepository(db)
+	if _, err := r.ListSince(context.Background(), 0, 10); err == nil {
+		t.Fatalf("expected error; got nil")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestChangeLatestID(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(id\\), 0\\) FROM book_changes").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(42)))
+
+	r := NewChangeRepository(db)
+	id, err := r.LatestID(context.Background())
+	if err != nil {
+		t.Fatalf("LatestID error: %v", err)
+	}
+	if id != 42 {
+		t.Fatalf("id = %d; want 42", id)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestChangeBookAt(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT .* FROM book_changes WHERE entity = \\? AND book_id = \\? AND created_at <= \\? ORDER BY id DESC LIMIT 1").
+		WithArgs(domain.EntityBook, int64(1), at).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "book_id", "op", "payload"}).
+			AddRow(int64(7), int64(1), domain.ChangeUpdated, []byte(`{"id":1,"price":12}`)))
+	mock.ExpectQuery("SELECT .* FROM book_changes WHERE entity = \\? AND book_id = \\?").
+		WithArgs(domain.EntityBook, int64(2), at).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery("SELECT MAX\\(id\\) AS id FROM book_changes WHERE entity = \\? AND created_at <= \\? GROUP BY book_id \\) latest ON latest.id = c.id ORDER BY c.book_id DESC").
+		WithArgs(domain.EntityBook, at).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "book_id", "op"}).
+			AddRow(int64(9), int64(2), domain.ChangeDeleted).
+			AddRow(int64(7), int64(1), domain.ChangeUpdated))
+
+	r := NewChangeRepository(db)
+	ctx := context.Background()
+	c, err := r.BookAt(ctx, 1, at)
+	if err != nil || c == nil || c.ID != 7 {
+		t.Fatalf("BookAt = %+v, %v", c, err)
+	}
+	if b, err := c.Book(); err != nil || b.Price != 12 {
+		t.Fatalf("payload = %+v, %v", b, err)
+	}
+	if c, err := r.BookAt(ctx, 2, at); err != nil || c != nil {
+		t.Fatalf("BookAt with no changes = %+v, %v", c, err)
+	}
+	if cs, err := r.BooksAt(ctx, at); err != nil || len(cs) != 2 || cs[0].BookID != 2 {
+		t.Fatalf("BooksAt = %+v, %v", cs, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestChangeCompact(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	cutoff := time.Now().Add(-30 * 24 * time.Hour)
+	mock.ExpectExec("DELETE c FROM book_changes c JOIN book_changes later .* later.version > c.version WHERE c.created_at < \\?").
+		WithArgs(cutoff).
+		WillReturnResult(sqlmock.NewResult(0, 9))
+
+	n, err := NewChangeRepository(db).Compact(context.Background(), cutoff)
+	if err != nil || n != 9 {
+		t.Fatalf("Compact = %d, %v; want 9", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
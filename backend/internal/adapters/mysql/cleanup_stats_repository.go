@@ -0,0 +1,70 @@
+package mysql
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type cleanupStatsRepository struct {
+	db *sqlx.DB
+}
+
+func NewCleanupStatsRepository(db *sqlx.DB) ports.CleanupStatsRepository {
+	return &cleanupStatsRepository{db: db}
+}
+
+// AddCleanupUsage upserts all counters in a single statement.
+func (r *cleanupStatsRepository) AddCleanupUsage(ctx context.Context, counts map[domain.CleanupUsageKey]int64) error {
+	if len(counts) == 0 {
+		return nil
+	}
+	keys := make([]domain.CleanupUsageKey, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { // stable lock order
+		a, b := keys[i], keys[j]
+		if !a.Day.Equal(b.Day) {
+			return a.Day.Before(b.Day)
+		}
+		if a.Domain != b.Domain {
+			return a.Domain < b.Domain
+		}
+		if a.Operation != b.Operation {
+			return a.Operation < b.Operation
+		}
+		return a.Outcome < b.Outcome
+	})
+
+	rows := make([][]any, 0, len(keys))
+	for _, k := range keys {
+		rows = append(rows, []any{k.Day.Format(time.DateOnly), k.Domain, k.Operation, k.Outcome, counts[k]})
+	}
+	_, err := execSQL(ctx, r.db, sqlf(`
+		INSERT INTO url_cleanup_stats (day, domain, operation, outcome, requests)
+		VALUES `).append(repeatSQL("(?, ?, ?, ?, ?)", ", ", rows), sqlf(`
+		ON DUPLICATE KEY UPDATE requests = requests + VALUES(requests)`)))
+	if err != nil {
+		logger.From(ctx).Error("failed to add cleanup usage", "rows", len(keys), "error", err)
+	}
+	return err
+}
+
+func (r *cleanupStatsRepository) CleanupUsageSince(ctx context.Context, since time.Time) ([]domain.CleanupUsage, error) {
+	usage := []domain.CleanupUsage{}
+	err := r.db.SelectContext(ctx, &usage, `
+		SELECT domain, operation, outcome, SUM(requests) AS requests
+		FROM url_cleanup_stats
+		WHERE day >= ?
+		GROUP BY domain, operation, outcome`, since.Format(time.DateOnly))
+	if err != nil {
+		logger.From(ctx).Error("failed to read cleanup usage", "since", since, "error", err)
+	}
+	return usage, err
+}
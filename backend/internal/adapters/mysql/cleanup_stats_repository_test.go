@@ -0,0 +1,41 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+func TestCleanupStatsRepository(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	mock.ExpectExec("INSERT INTO url_cleanup_stats .* ON DUPLICATE KEY UPDATE requests = requests \\+ VALUES\\(requests\\)").
+		WithArgs("2024-01-02", "a.com", "all", "ok", int64(2), "2024-01-02", "b.com", "all", "ok", int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery("SELECT domain, operation, outcome, SUM\\(requests\\) AS requests FROM url_cleanup_stats WHERE day >= \\? GROUP BY").
+		WithArgs("2024-01-02").
+		WillReturnRows(sqlmock.NewRows([]string{"domain", "operation", "outcome", "requests"}).AddRow("a.com", "all", "ok", 2))
+
+	repo := NewCleanupStatsRepository(db)
+	ctx := context.Background()
+	err := repo.AddCleanupUsage(ctx, map[domain.CleanupUsageKey]int64{
+		{Day: day, Domain: "b.com", Operation: "all", Outcome: "ok"}: 1,
+		{Day: day, Domain: "a.com", Operation: "all", Outcome: "ok"}: 2,
+	})
+	if err != nil {
+		t.Fatalf("AddCleanupUsage: %v", err)
+	}
+	usage, err := repo.CleanupUsageSince(ctx, day)
+	if err != nil || len(usage) != 1 || usage[0].Requests != 2 {
+		t.Fatalf("CleanupUsageSince = %+v, %v", usage, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+const deadLetterColumns = `id, kind, payload, error, attempts, created_at, last_attempt_at`
+
+type deadLetterRepository struct {
+	db *sqlx.DB
+}
+
+func NewDeadLetterRepository(db *sqlx.DB) ports.DeadLetterRepository {
+	return &deadLetterRepository{db: db}
+}
+
+func (r *deadLetterRepository) Add(ctx context.Context, d *domain.DeadLetter) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO dead_letters (kind, payload, error, attempts, created_at, last_attempt_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		d.Kind, d.Payload, d.Error, d.Attempts, d.CreatedAt, d.LastAttemptAt)
+	if err != nil {
+		logger.From(ctx).Error("failed to add dead letter", "kind", d.Kind, "error", err)
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *deadLetterRepository) List(ctx context.Context, kind string, limit int) ([]domain.DeadLetter, error) {
+	out := []domain.DeadLetter{}
+	q := sqlf(`SELECT ` + deadLetterColumns + ` FROM dead_letters`)
+	if kind != "" {
+		q = q.append(sqlf(` WHERE kind = ?`, kind))
+	}
+	err := selectSQL(ctx, r.db, &out, q.append(sqlf(` ORDER BY id DESC LIMIT ?`, limit)))
+	if err != nil {
+		logger.From(ctx).Error("failed to list dead letters", "kind", kind, "error", err)
+	}
+	return out, err
+}
+
+func (r *deadLetterRepository) GetByID(ctx context.Context, id int64) (*domain.DeadLetter, error) {
+	var d domain.DeadLetter
+	err := r.db.GetContext(ctx, &d, `SELECT `+deadLetterColumns+` FROM dead_letters WHERE id = ?`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to get dead letter", "id", id, "error", err)
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *deadLetterRepository) Failed(ctx context.Context, id int64, reason string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE dead_letters SET error = ?, attempts = attempts + 1, last_attempt_at = ? WHERE id = ?`,
+		reason, at, id)
+	if err != nil {
+		logger.From(ctx).Error("failed to record dead letter attempt", "id", id, "error", err)
+	}
+	return err
+}
+
+func (r *deadLetterRepository) Delete(ctx context.Context, id int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM dead_letters WHERE id = ?`, id)
+	if err != nil {
+		logger.From(ctx).Error("failed to delete dead letter", "id", id, "error", err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
@@ -0,0 +1,54 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+func TestDeadLetterRepository(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	payload := domain.ChangePayload(`{"search":{"id":1}}`)
+	mock.ExpectExec("INSERT INTO dead_letters").
+		WithArgs("search_notification", payload, "smtp down", 1, now, now).
+		WillReturnResult(sqlmock.NewResult(5, 1))
+	mock.ExpectQuery("SELECT id, kind, payload, error, attempts, created_at, last_attempt_at FROM dead_letters WHERE kind = \\? ORDER BY id DESC LIMIT \\?").
+		WithArgs("search_notification", 50).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "kind", "payload", "error", "attempts", "created_at", "last_attempt_at"}).
+			AddRow(5, "search_notification", []byte(payload), "smtp down", 1, now, now))
+	mock.ExpectExec("UPDATE dead_letters SET error = \\?, attempts = attempts \\+ 1, last_attempt_at = \\? WHERE id = \\?").
+		WithArgs("still down", now, int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM dead_letters WHERE id = \\?").
+		WithArgs(int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	repo := NewDeadLetterRepository(db)
+	ctx := context.Background()
+	id, err := repo.Add(ctx, &domain.DeadLetter{
+		Kind: "search_notification", Payload: payload, Error: "smtp down", Attempts: 1, CreatedAt: now, LastAttemptAt: now,
+	})
+	if err != nil || id != 5 {
+		t.Fatalf("Add = %d, %v", id, err)
+	}
+	got, err := repo.List(ctx, "search_notification", 50)
+	if err != nil || len(got) != 1 || string(got[0].Payload) != string(payload) {
+		t.Fatalf("List = %+v, %v", got, err)
+	}
+	if err := repo.Failed(ctx, 5, "still down", now); err != nil {
+		t.Fatalf("Failed: %v", err)
+	}
+	if found, err := repo.Delete(ctx, 5); err != nil || !found {
+		t.Fatalf("Delete = %v, %v", found, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+func TestParseMigrationPhase(t *testing.T) {
+	for in, want := range map[string]MigrationPhase{"": PhaseOff, "off": PhaseOff, "dual_write": PhaseDualWrite, "read_new": PhaseReadNew} {
+		got, err := ParseMigrationPhase(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseMigrationPhase(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseMigrationPhase("cents"); err == nil {
+		t.Fatalf("expected error for unknown phase")
+	}
+}
+
+func TestDualWrite_Phases(t *testing.T) {
+	d := NewDualWrite("test", PhaseOff)
+	if d.WritesNew() || d.ReadsNew() {
+		t.Fatalf("off: writes=%v reads=%v", d.WritesNew(), d.ReadsNew())
+	}
+	d.SetPhase(PhaseDualWrite)
+	if !d.WritesNew() || d.ReadsNew() {
+		t.Fatalf("dual_write: writes=%v reads=%v", d.WritesNew(), d.ReadsNew())
+	}
+	d.SetPhase(PhaseReadNew)
+	if !d.WritesNew() || !d.ReadsNew() {
+		t.Fatalf("read_new: writes=%v reads=%v", d.WritesNew(), d.ReadsNew())
+	}
+	if got := d.Stats()["phase"]; got != "read_new" {
+		t.Fatalf("stats phase = %v", got)
+	}
+}
+
+func TestCreate_DualWritesPriceCents(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	d := NewDualWrite("price_cents", PhaseDualWrite)
+	b := &domain.Book{Title: "T", Author: "A", ISBN: "I", PublicationYear: 2020, Price: 19.99}
+	args := make([]driver.Value, 16)
+	for i := range args {
+		args[i] = sqlmock.AnyArg()
+	}
+	mock.ExpectExec("INSERT INTO books \\(.*, work_id, publisher_id, series_id, series_position, format, price_cents\\)").
+		WithArgs(append(args, int64(1999))...).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+
+	if _, err := NewBookRepository(db, WithPriceCents(d)).Create(context.Background(), b); err != nil {
+		t.Fatalf("Create error: %v", err)
+	}
+	if got := d.Stats()["dual_writes"]; got != int64(1) {
+		t.Fatalf("dual_writes = %v", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestUpdate_PriceCentsResetWhenOff(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectExec("UPDATE books SET .*, price_cents = NULL WHERE id = \\? AND updated_at = \\?").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	r := NewBookRepository(db, WithPriceCents(NewDualWrite("price_cents", PhaseOff)))
+	if err := r.Update(context.Background(), &domain.Book{ID: 1, Price: 5}, time.Now()); err != nil {
+		t.Fatalf("Update error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestGetByID_ReadsPriceCents(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT id, title, author, isbn, isbn10, COALESCE\\(price_cents / 100, price\\) AS price, .* FROM books WHERE id = \\?").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "price"}).AddRow(int64(1), 12.5))
+	mock.ExpectQuery("SELECT book_id, alias FROM book_aliases").
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "alias"}))
+	mock.ExpectQuery("SELECT book_id, category FROM book_categories").
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "category"}))
+	mock.ExpectQuery("SELECT bt.book_id, t.name FROM book_tags bt").
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "name"}))
+
+	r := NewBookRepository(db, WithPriceCents(NewDualWrite("price_cents", PhaseReadNew)))
+	b, err := r.GetByID(context.Background(), 1)
+	if err != nil || b.Price != 12.5 {
+		t.Fatalf("GetByID = %+v, %v", b, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestApplyPrices_DualWritesPriceCents(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE books SET price = \\?, .*, price_cents = \\? WHERE id = \\? AND price = \\?").
+		WithArgs(10.5, sqlmock.AnyArg(), sqlmock.AnyArg(), int64(1050), int64(1), "12.00").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO book_price_history").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	r := NewPriceRepository(db, WithPriceCents(NewDualWrite("price_cents", PhaseDualWrite)))
+	err := r.ApplyPrices(context.Background(), []domain.PriceChange{{BookID: 1, OldPrice: 12, NewPrice: 10.5, ChangedAt: time.Now()}})
+	if err != nil {
+		t.Fatalf("ApplyPrices error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestBackfillPriceCents_Batches(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	fill := "UPDATE books SET price_cents = ROUND\\(price \\* 100\\) WHERE price_cents IS NULL LIMIT \\?"
+	mock.ExpectExec(fill).WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(fill).WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	d := NewDualWrite("price_cents", PhaseDualWrite)
+	n, err := BackfillPriceCents(context.Background(), db, d, 2)
+	if err != nil || n != 3 {
+		t.Fatalf("BackfillPriceCents = %d, %v", n, err)
+	}
+	if got := d.Stats()["backfilled"]; got != int64(3) {
+		t.Fatalf("backfilled = %v", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestBackfillPriceCents_SkippedWhenOff(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	n, err := BackfillPriceCents(context.Background(), db, NewDualWrite("price_cents", PhaseOff), 100)
+	if err != nil || n != 0 {
+		t.Fatalf("BackfillPriceCents = %d, %v", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCheckPriceCents(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT .* AS pending, .* AS mismatched FROM books").
+		WillReturnRows(sqlmock.NewRows([]string{"pending", "mismatched"}).AddRow(int64(4), int64(1)))
+
+	d := NewDualWrite("price_cents", PhaseDualWrite)
+	pending, mismatched, err := CheckPriceCents(context.Background(), db, d)
+	if err != nil || pending != 4 || mismatched != 1 {
+		t.Fatalf("CheckPriceCents = %d, %d, %v", pending, mismatched, err)
+	}
+	if s := d.Stats(); s["pending"] != int64(4) || s["mismatched"] != int64(1) {
+		t.Fatalf("stats = %v", s)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
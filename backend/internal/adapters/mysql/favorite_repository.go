@@ -0,0 +1,89 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+)
+
+type favoriteRepository struct {
+	db    *sqlx.DB
+	books *bookRepository
+}
+
+// NewFavoriteRepository reads favorite books the way NewBookRepository with
+// opts does.
+func NewFavoriteRepository(db *sqlx.DB, opts ...RepoOption) ports.FavoriteRepository {
+	return &favoriteRepository{db: db, books: &bookRepository{db: db, repoOptions: newRepoOptions(opts)}}
+}
+
+func (r *favoriteRepository) Add(ctx context.Context, userID, bookID int64, at time.Time) (bool, error) {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO favorites (user_id, book_id, created_at) VALUES (?, ?, ?)`, userID, bookID, at)
+	var myErr *mysqldriver.MySQLError
+	switch {
+	case errors.As(err, &myErr) && myErr.Number == errNoReferencedRow:
+		return false, nil
+	case errors.As(err, &myErr) && myErr.Number == errDuplicateKey:
+		return true, nil
+	case err != nil:
+		logger.From(ctx).Error("failed to add favorite", "user_id", userID, "book_id", bookID, "error", err)
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *favoriteRepository) Remove(ctx context.Context, userID, bookID int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM favorites WHERE user_id = ? AND book_id = ?`, userID, bookID)
+	if err != nil {
+		logger.From(ctx).Error("failed to remove favorite", "user_id", userID, "book_id", bookID, "error", err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *favoriteRepository) ListBooks(ctx context.Context, userID int64, limit, offset int) ([]domain.Book, int, error) {
+	var total int
+	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM favorites WHERE user_id = ?`, userID); err != nil {
+		logger.From(ctx).Error("failed to count favorites", "user_id", userID, "error", err)
+		return nil, 0, err
+	}
+	var ids []int64
+	if err := selectSQL(ctx, r.db, &ids, sqlf(`
+		SELECT book_id FROM favorites WHERE user_id = ?
+		ORDER BY created_at DESC, book_id DESC
+		LIMIT ? OFFSET ?`, userID, limit, offset)); err != nil {
+		logger.From(ctx).Error("failed to list favorites", "user_id", userID, "error", err)
+		return nil, 0, err
+	}
+	books := []domain.Book{}
+	if len(ids) == 0 {
+		return books, total, nil
+	}
+	var rows []domain.Book
+	if err := selectSQL(ctx, r.db, &rows, sqlf(`
+		SELECT `+r.books.bookColumns()+`
+		FROM books WHERE id IN (`).append(inList(ids), sqlf(`)`))); err != nil {
+		logger.From(ctx).Error("failed to load favorite books", "user_id", userID, "error", err)
+		return nil, 0, err
+	}
+	// back in favorite order; a book deleted in between drops out
+	byID := make(map[int64]domain.Book, len(rows))
+	for _, b := range rows {
+		byID[b.ID] = b
+	}
+	for _, id := range ids {
+		if b, ok := byID[id]; ok {
+			books = append(books, b)
+		}
+	}
+	return books, total, attachRelations(ctx, r.db, books)
+}
@@ -0,0 +1,69 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	mysqldriver "github.com/go-sql-driver/mysql"
+)
+
+func TestFavoriteAdd(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+	repo := NewFavoriteRepository(db)
+	ctx := context.Background()
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec("INSERT INTO favorites \\(user_id, book_id, created_at\\) VALUES \\(\\?, \\?, \\?\\)").
+		WithArgs(int64(7), int64(42), at).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO favorites").
+		WillReturnError(&mysqldriver.MySQLError{Number: 1062, Message: "Duplicate entry"})
+	mock.ExpectExec("INSERT INTO favorites").
+		WillReturnError(&mysqldriver.MySQLError{Number: 1452, Message: "Cannot add or update a child row"})
+
+	for _, want := range []bool{true, true, false} {
+		if found, err := repo.Add(ctx, 7, 42, at); found != want || err != nil {
+			t.Fatalf("Add = %v, %v; want %v", found, err, want)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestFavoriteListBooks(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM favorites WHERE user_id = \\?").
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"n"}).AddRow(5))
+	mock.ExpectQuery("SELECT book_id FROM favorites WHERE user_id = \\? ORDER BY created_at DESC, book_id DESC LIMIT \\? OFFSET \\?").
+		WithArgs(int64(7), 3, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"book_id"}).AddRow(int64(2)).AddRow(int64(9)).AddRow(int64(1)))
+	mock.ExpectQuery("SELECT id, title, author, .* FROM books WHERE id IN \\(\\?, \\?, \\?\\)").
+		WithArgs(int64(2), int64(9), int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "author", "price", "created_at", "updated_at"}).
+			AddRow(int64(1), "A", "AuthA", 9.99, now, now).
+			AddRow(int64(2), "B", "AuthB", 5.0, now, now))
+	mock.ExpectQuery("SELECT book_id, alias FROM book_aliases").
+		WithArgs(int64(2), int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "alias"}))
+	mock.ExpectQuery("SELECT book_id, category FROM book_categories").
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "category"}))
+	mock.ExpectQuery("SELECT bt.book_id, t.name FROM book_tags bt").
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "name"}))
+
+	// book 9 was deleted after the ids were read
+	books, total, err := NewFavoriteRepository(db).ListBooks(context.Background(), 7, 3, 0)
+	if err != nil || total != 5 || len(books) != 2 || books[0].ID != 2 || books[1].ID != 1 {
+		t.Fatalf("ListBooks = %+v, %d, %v; want books 2 and 1", books, total, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
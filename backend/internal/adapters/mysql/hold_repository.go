@@ -0,0 +1,120 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+)
+
+type holdRepository struct {
+	db *sqlx.DB
+}
+
+func NewHoldRepository(db *sqlx.DB) ports.HoldRepository {
+	return &holdRepository{db: db}
+}
+
+func (r *holdRepository) Place(ctx context.Context, h *domain.Hold) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback() }() // no-op after Commit
+
+	found, onLoan, err := lockBookLoans(ctx, tx, h.BookID)
+	if err != nil || !found {
+		return false, err
+	}
+	if !onLoan {
+		return true, ports.ErrBookAvailable
+	}
+	var state struct {
+		Borrower int64 `db:"borrower"`
+		Holding  bool  `db:"holding"`
+	}
+	if err := tx.GetContext(ctx, &state, `
+		SELECT COALESCE((SELECT user_id FROM loans WHERE book_id = ? AND returned_at IS NULL LIMIT 1), 0) AS borrower,
+			EXISTS (SELECT 1 FROM holds WHERE book_id = ? AND user_id = ? AND status = ?) AS holding`,
+		h.BookID, h.BookID, h.UserID, domain.HoldWaiting); err != nil {
+		logger.From(ctx).Error("failed to check holds of book", "id", h.BookID, "error", err)
+		return true, err
+	}
+	switch {
+	case state.Borrower == h.UserID:
+		return true, ports.ErrBorrowerHold
+	case state.Holding:
+		return true, ports.ErrHoldExists
+	}
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO holds (book_id, user_id, status, placed_at) VALUES (?, ?, ?, ?)`,
+		h.BookID, h.UserID, domain.HoldWaiting, h.PlacedAt)
+	var myErr *mysqldriver.MySQLError
+	switch {
+	case errors.As(err, &myErr) && myErr.Number == errNoReferencedRow:
+		return true, ports.ErrUnknownBorrower // the book is locked, so it's the user
+	case err != nil:
+		logger.From(ctx).Error("failed to place hold", "id", h.BookID, "user", h.UserID, "error", err)
+		return false, err
+	}
+	if h.ID, err = res.LastInsertId(); err != nil {
+		return false, err
+	}
+	if err := tx.GetContext(ctx, &h.Position, `
+		SELECT COUNT(*) FROM holds WHERE book_id = ? AND status = ? AND id <= ?`,
+		h.BookID, domain.HoldWaiting, h.ID); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// holdColumns selects a hold h with its position in its book's queue.
+const holdColumns = `h.id, h.book_id, h.user_id, h.status, h.placed_at, h.loan_id, h.closed_at,
+			CASE WHEN h.status = ? THEN (
+				SELECT COUNT(*) FROM holds q WHERE q.book_id = h.book_id AND q.status = ? AND q.id <= h.id
+			) ELSE 0 END AS position`
+
+func (r *holdRepository) GetByID(ctx context.Context, id int64) (*domain.Hold, error) {
+	var h domain.Hold
+	err := r.db.GetContext(ctx, &h, `
+		SELECT `+holdColumns+`
+		FROM holds h WHERE h.id = ?`, domain.HoldWaiting, domain.HoldWaiting, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to get hold", "id", id, "error", err)
+		return nil, err
+	}
+	return &h, nil
+}
+
+func (r *holdRepository) Cancel(ctx context.Context, id int64, at time.Time) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE holds SET status = ?, closed_at = ? WHERE id = ? AND status = ?`,
+		domain.HoldCancelled, at, id, domain.HoldWaiting)
+	if err != nil {
+		logger.From(ctx).Error("failed to cancel hold", "id", id, "error", err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *holdRepository) ListWaiting(ctx context.Context, userID int64) ([]domain.Hold, error) {
+	holds := []domain.Hold{}
+	if err := r.db.SelectContext(ctx, &holds, `
+		SELECT `+holdColumns+`
+		FROM holds h WHERE h.user_id = ? AND h.status = ? ORDER BY h.id`,
+		domain.HoldWaiting, domain.HoldWaiting, userID, domain.HoldWaiting); err != nil {
+		logger.From(ctx).Error("failed to list holds of user", "user", userID, "error", err)
+		return nil, err
+	}
+	return holds, nil
+}
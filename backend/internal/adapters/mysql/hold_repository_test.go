@@ -0,0 +1,157 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	mysqldriver "github.com/go-sql-driver/mysql"
+)
+
+func TestPlaceHold(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name      string
+		onLoan    bool
+		borrower  int64
+		holding   bool
+		insertErr error
+		err       error
+	}{
+		{"queued", true, 8, false, nil, nil},
+		{"available", false, 0, false, nil, ports.ErrBookAvailable},
+		{"borrower", true, 7, false, nil, ports.ErrBorrowerHold},
+		{"holding", true, 8, true, nil, ports.ErrHoldExists},
+		{"no user", true, 8, false, &mysqldriver.MySQLError{Number: errNoReferencedRow}, ports.ErrUnknownBorrower},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, cleanup := newMockSQLX(t)
+			defer cleanup()
+
+			mock.ExpectBegin()
+			mock.ExpectQuery("SELECT id FROM books WHERE id = \\? FOR UPDATE").
+				WithArgs(int64(3)).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(3)))
+			mock.ExpectQuery("SELECT EXISTS").
+				WithArgs(int64(3)).
+				WillReturnRows(sqlmock.NewRows([]string{"on_loan"}).AddRow(tc.onLoan))
+			if tc.onLoan {
+				mock.ExpectQuery("SELECT COALESCE\\(\\(SELECT user_id FROM loans .*\\), 0\\) AS borrower, EXISTS \\(SELECT 1 FROM holds .*\\) AS holding").
+					WithArgs(int64(3), int64(3), int64(7), domain.HoldWaiting).
+					WillReturnRows(sqlmock.NewRows([]string{"borrower", "holding"}).AddRow(tc.borrower, tc.holding))
+			}
+			if tc.onLoan && tc.borrower != 7 && !tc.holding {
+				insert := mock.ExpectExec("INSERT INTO holds \\(book_id, user_id, status, placed_at\\) VALUES \\(\\?, \\?, \\?, \\?\\)").
+					WithArgs(int64(3), int64(7), domain.HoldWaiting, at)
+				if tc.insertErr != nil {
+					insert.WillReturnError(tc.insertErr)
+				} else {
+					insert.WillReturnResult(sqlmock.NewResult(21, 1))
+					mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM holds WHERE book_id = \\? AND status = \\? AND id <= \\?").
+						WithArgs(int64(3), domain.HoldWaiting, int64(21)).
+						WillReturnRows(sqlmock.NewRows([]string{"n"}).AddRow(2))
+					mock.ExpectCommit()
+				}
+			}
+			if tc.err != nil {
+				mock.ExpectRollback()
+			}
+
+			h := &domain.Hold{BookID: 3, UserID: 7, Status: domain.HoldWaiting, PlacedAt: at}
+			found, err := NewHoldRepository(db).Place(context.Background(), h)
+			if !found || !errors.Is(err, tc.err) {
+				t.Fatalf("Place = %v, %v; want true, %v", found, err, tc.err)
+			}
+			if tc.err == nil && (h.ID != 21 || h.Position != 2) {
+				t.Fatalf("hold = %+v; want id 21 at position 2", h)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestHolds_GetAndCancel(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+	repo := NewHoldRepository(db)
+	ctx := context.Background()
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT h.id, .* AS position FROM holds h WHERE h.id = \\?").
+		WithArgs(domain.HoldWaiting, domain.HoldWaiting, int64(21)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "book_id", "user_id", "status", "placed_at", "loan_id", "closed_at", "position"}).
+			AddRow(int64(21), int64(3), int64(7), domain.HoldWaiting, now, nil, nil, 1))
+	h, err := repo.GetByID(ctx, 21)
+	if err != nil || h == nil || h.Position != 1 || h.LoanID != nil {
+		t.Fatalf("GetByID = %+v, %v", h, err)
+	}
+	mock.ExpectQuery("SELECT h.id").WithArgs(domain.HoldWaiting, domain.HoldWaiting, int64(22)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	if h, err := repo.GetByID(ctx, 22); h != nil || err != nil {
+		t.Fatalf("GetByID(missing) = %+v, %v", h, err)
+	}
+
+	mock.ExpectQuery("SELECT h.id, .* AS position FROM holds h WHERE h.user_id = \\? AND h.status = \\? ORDER BY h.id").
+		WithArgs(domain.HoldWaiting, domain.HoldWaiting, int64(7), domain.HoldWaiting).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "book_id", "user_id", "status", "placed_at", "loan_id", "closed_at", "position"}).
+			AddRow(int64(21), int64(3), int64(7), domain.HoldWaiting, now, nil, nil, 1).
+			AddRow(int64(25), int64(4), int64(7), domain.HoldWaiting, now, nil, nil, 3))
+	if holds, err := repo.ListWaiting(ctx, 7); err != nil || len(holds) != 2 || holds[1].BookID != 4 || holds[1].Position != 3 {
+		t.Fatalf("ListWaiting = %+v, %v", holds, err)
+	}
+
+	mock.ExpectExec("UPDATE holds SET status = \\?, closed_at = \\? WHERE id = \\? AND status = \\?").
+		WithArgs(domain.HoldCancelled, now, int64(21), domain.HoldWaiting).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if waiting, err := repo.Cancel(ctx, 21, now); !waiting || err != nil {
+		t.Fatalf("Cancel = %v, %v", waiting, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestReturn_FulfilsHold(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	due := now.AddDate(0, 0, 14)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT book_id FROM loans WHERE id = \\?").
+		WithArgs(int64(11)).
+		WillReturnRows(sqlmock.NewRows([]string{"book_id"}).AddRow(int64(3)))
+	mock.ExpectQuery("SELECT id FROM books WHERE id = \\? FOR UPDATE").
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(3)))
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"on_loan"}).AddRow(true))
+	mock.ExpectExec("UPDATE loans SET returned_at = \\? WHERE id = \\? AND returned_at IS NULL").
+		WithArgs(now, int64(11)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT id, user_id FROM holds WHERE book_id = \\? AND status = \\? ORDER BY id LIMIT 1 FOR UPDATE").
+		WithArgs(int64(3), domain.HoldWaiting).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(int64(21), int64(8)))
+	mock.ExpectExec("INSERT INTO loans \\(book_id, user_id, checked_out_at, due_at\\)").
+		WithArgs(int64(3), int64(8), now, due).
+		WillReturnResult(sqlmock.NewResult(12, 1))
+	mock.ExpectExec("UPDATE holds SET status = \\?, loan_id = \\?, closed_at = \\? WHERE id = \\?").
+		WithArgs(domain.HoldFulfilled, int64(12), now, int64(21)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	open, next, err := NewLoanRepository(db).Return(context.Background(), 11, now, due)
+	if !open || err != nil || next == nil || next.ID != 12 || next.UserID != 8 || !next.DueAt.Equal(due) {
+		t.Fatalf("Return = %v, %+v, %v; want the book lent to user 8", open, next, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type inboxRepository struct {
+	db *sqlx.DB
+}
+
+func NewInboxRepository(db *sqlx.DB) ports.InboxRepository {
+	return &inboxRepository{db: db}
+}
+
+func (r *inboxRepository) Seen(ctx context.Context, source, messageID string) (bool, error) {
+	var n int
+	err := r.db.GetContext(ctx, &n, `
+		SELECT COUNT(*) FROM catalog_inbox WHERE source = ? AND message_id = ?`, source, messageID)
+	if err != nil {
+		logger.From(ctx).Error("failed to check inbox", "source", source, "message_id", messageID, "error", err)
+	}
+	return n > 0, err
+}
+
+func (r *inboxRepository) Record(ctx context.Context, m *domain.InboxMessage) error {
+	var errText *string
+	if m.Error != "" {
+		errText = &m.Error
+	}
+	_, err := r.db.ExecContext(ctx, `
+		INSERT IGNORE INTO catalog_inbox (source, message_id, op, book_id, error, processed_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		m.Source, m.MessageID, m.Op, m.BookID, errText, m.ProcessedAt)
+	if err != nil {
+		logger.From(ctx).Error("failed to record inbox message", "source", m.Source, "message_id", m.MessageID, "error", err)
+	}
+	return err
+}
@@ -0,0 +1,39 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+func TestInboxRepository(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM catalog_inbox WHERE source = \\? AND message_id = \\?").
+		WithArgs("erp.catalog", "e1").
+		WillReturnRows(sqlmock.NewRows([]string{"n"}).AddRow(1))
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	id := int64(3)
+	mock.ExpectExec("INSERT IGNORE INTO catalog_inbox").
+		WithArgs("erp.catalog", "e2", domain.ChangeUpdated, &id, nil, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	repo := NewInboxRepository(db)
+	if seen, err := repo.Seen(context.Background(), "erp.catalog", "e1"); err != nil || !seen {
+		t.Fatalf("Seen = %v, %v", seen, err)
+	}
+	err := repo.Record(context.Background(), &domain.InboxMessage{
+		Source: "erp.catalog", MessageID: "e2", Op: domain.ChangeUpdated, BookID: &id, ProcessedAt: now,
+	})
+	if err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
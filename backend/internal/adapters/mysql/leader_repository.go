@@ -0,0 +1,45 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type leaderRepository struct {
+	db *sqlx.DB
+}
+
+func NewLeaderRepository(db *sqlx.DB) ports.LeaderRepository {
+	return &leaderRepository{db: db}
+}
+
+func (r *leaderRepository) SetLeader(ctx context.Context, election, identity string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO leaders (election, identity, elected_at) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE identity = VALUES(identity), elected_at = VALUES(elected_at)`,
+		election, identity, at)
+	if err != nil {
+		logger.From(ctx).Error("failed to record leader", "election", election, "error", err)
+	}
+	return err
+}
+
+func (r *leaderRepository) Leader(ctx context.Context, election string) (*domain.Leader, error) {
+	var l domain.Leader
+	err := r.db.GetContext(ctx, &l, `SELECT identity, elected_at FROM leaders WHERE election = ?`, election)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to get leader", "election", election, "error", err)
+		return nil, err
+	}
+	return &l, nil
+}
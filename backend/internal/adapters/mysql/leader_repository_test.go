@@ -0,0 +1,40 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestLeaderRepository(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT identity, elected_at FROM leaders WHERE election = \\?").
+		WithArgs("scheduler").
+		WillReturnRows(sqlmock.NewRows([]string{"identity", "elected_at"}))
+	mock.ExpectExec("INSERT INTO leaders .* ON DUPLICATE KEY UPDATE identity = VALUES\\(identity\\)").
+		WithArgs("scheduler", "api-1", now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT identity, elected_at FROM leaders WHERE election = \\?").
+		WithArgs("scheduler").
+		WillReturnRows(sqlmock.NewRows([]string{"identity", "elected_at"}).AddRow("api-1", now))
+
+	repo := NewLeaderRepository(db)
+	ctx := context.Background()
+	if l, err := repo.Leader(ctx, "scheduler"); err != nil || l != nil {
+		t.Fatalf("Leader before any election = %+v, %v", l, err)
+	}
+	if err := repo.SetLeader(ctx, "scheduler", "api-1", now); err != nil {
+		t.Fatalf("SetLeader: %v", err)
+	}
+	if l, err := repo.Leader(ctx, "scheduler"); err != nil || l == nil || l.Identity != "api-1" || !l.ElectedAt.Equal(now) {
+		t.Fatalf("Leader = %+v, %v", l, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
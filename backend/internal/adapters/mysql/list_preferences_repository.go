@@ -0,0 +1,56 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type listPreferencesRepository struct {
+	db *sqlx.DB
+}
+
+func NewListPreferencesRepository(db *sqlx.DB) ports.ListPreferencesRepository {
+	return &listPreferencesRepository{db: db}
+}
+
+func (r *listPreferencesRepository) Get(ctx context.Context, tenant string) (*domain.ListPreferences, error) {
+	var p domain.ListPreferences
+	err := r.db.GetContext(ctx, &p, `
+		SELECT tenant, sort, collation, page_size, updated_at FROM list_preferences WHERE tenant = ?`, tenant)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to get list preferences", "tenant", tenant, "error", err)
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *listPreferencesRepository) Put(ctx context.Context, p *domain.ListPreferences) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO list_preferences (tenant, sort, collation, page_size, updated_at) VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE sort = VALUES(sort), collation = VALUES(collation),
+			page_size = VALUES(page_size), updated_at = VALUES(updated_at)`,
+		p.Tenant, p.Sort, p.Collation, p.PageSize, p.UpdatedAt)
+	if err != nil {
+		logger.From(ctx).Error("failed to save list preferences", "tenant", p.Tenant, "error", err)
+	}
+	return err
+}
+
+func (r *listPreferencesRepository) Delete(ctx context.Context, tenant string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM list_preferences WHERE tenant = ?`, tenant)
+	if err != nil {
+		logger.From(ctx).Error("failed to delete list preferences", "tenant", tenant, "error", err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
@@ -0,0 +1,50 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+func TestListPreferences_PutGetDelete(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+	repo := NewListPreferencesRepository(db)
+	ctx := context.Background()
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec("INSERT INTO list_preferences \\(tenant, sort, collation, page_size, updated_at\\) VALUES \\(\\?, \\?, \\?, \\?, \\?\\) ON DUPLICATE KEY UPDATE").
+		WithArgs("key:storefront", "-author", "sv", 24, at).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := repo.Put(ctx, &domain.ListPreferences{Tenant: "key:storefront", Sort: "-author", Collation: "sv", PageSize: 24, UpdatedAt: at}); err != nil {
+		t.Fatalf("Put error: %v", err)
+	}
+
+	mock.ExpectQuery("SELECT tenant, sort, collation, page_size, updated_at FROM list_preferences WHERE tenant = \\?").
+		WithArgs("key:storefront").
+		WillReturnRows(sqlmock.NewRows([]string{"tenant", "sort", "collation", "page_size", "updated_at"}).AddRow("key:storefront", "-author", "sv", 24, at))
+	p, err := repo.Get(ctx, "key:storefront")
+	if err != nil || p == nil || p.Sort != "-author" || p.PageSize != 24 {
+		t.Fatalf("Get = %+v, %v", p, err)
+	}
+
+	mock.ExpectQuery("SELECT tenant, sort, collation, page_size, updated_at FROM list_preferences WHERE tenant = \\?").
+		WithArgs("nobody").
+		WillReturnRows(sqlmock.NewRows([]string{"tenant", "sort", "collation", "page_size", "updated_at"}))
+	if p, err := repo.Get(ctx, "nobody"); p != nil || err != nil {
+		t.Fatalf("Get(nobody) = %+v, %v; want nil", p, err)
+	}
+
+	mock.ExpectExec("DELETE FROM list_preferences WHERE tenant = \\?").
+		WithArgs("key:storefront").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if found, err := repo.Delete(ctx, "key:storefront"); !found || err != nil {
+		t.Fatalf("Delete = %v, %v", found, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+)
+
+const loanColumns = `id, book_id, user_id, checked_out_at, due_at, returned_at`
+
+type loanRepository struct {
+	db *sqlx.DB
+}
+
+func NewLoanRepository(db *sqlx.DB) ports.LoanRepository {
+	return &loanRepository{db: db}
+}
+
+func (r *loanRepository) Checkout(ctx context.Context, l *domain.Loan) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback() }() // no-op after Commit
+
+	found, onLoan, err := lockBookLoans(ctx, tx, l.BookID)
+	if err != nil || !found {
+		return false, err
+	}
+	if onLoan {
+		return true, ports.ErrBookOnLoan
+	}
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO loans (book_id, user_id, checked_out_at, due_at) VALUES (?, ?, ?, ?)`,
+		l.BookID, l.UserID, l.CheckedOutAt, l.DueAt)
+	var myErr *mysqldriver.MySQLError
+	switch {
+	case errors.As(err, &myErr) && myErr.Number == errNoReferencedRow:
+		return true, ports.ErrUnknownBorrower // the book is locked, so it's the user
+	case err != nil:
+		logger.From(ctx).Error("failed to check out book", "id", l.BookID, "user", l.UserID, "error", err)
+		return false, err
+	}
+	if l.ID, err = res.LastInsertId(); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// lockBookLoans locks book id and reports whether it exists and is out on
+// an open loan. Checkouts, returns, holds and deletes take the lock before
+// looking at the loans, so they see each other's committed work and never
+// interleave.
+func lockBookLoans(ctx context.Context, tx *sqlx.Tx, id int64) (found, onLoan bool, err error) {
+	var locked int64
+	err = tx.GetContext(ctx, &locked, `SELECT id FROM books WHERE id = ? FOR UPDATE`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, false, nil
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to lock book", "id", id, "error", err)
+		return false, false, err
+	}
+	if err := tx.GetContext(ctx, &onLoan, `
+		SELECT EXISTS (SELECT 1 FROM loans WHERE book_id = ? AND returned_at IS NULL)`, id); err != nil {
+		logger.From(ctx).Error("failed to check loans of book", "id", id, "error", err)
+		return true, false, err
+	}
+	return true, onLoan, nil
+}
+
+func (r *loanRepository) GetByID(ctx context.Context, id int64) (*domain.Loan, error) {
+	var l domain.Loan
+	err := r.db.GetContext(ctx, &l, `SELECT `+loanColumns+` FROM loans WHERE id = ?`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to get loan", "id", id, "error", err)
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *loanRepository) Return(ctx context.Context, id int64, at, nextDue time.Time) (bool, *domain.Loan, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, nil, err
+	}
+	defer func() { _ = tx.Rollback() }() // no-op after Commit
+
+	var bookID int64
+	err = tx.GetContext(ctx, &bookID, `SELECT book_id FROM loans WHERE id = ?`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil, nil
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to get loan", "id", id, "error", err)
+		return false, nil, err
+	}
+	// lock the book like checkouts and holds do, so no hold joins the
+	// queue while the book changes hands
+	if _, _, err := lockBookLoans(ctx, tx, bookID); err != nil {
+		return false, nil, err
+	}
+	res, err := tx.ExecContext(ctx, `UPDATE loans SET returned_at = ? WHERE id = ? AND returned_at IS NULL`, at, id)
+	if err != nil {
+		logger.From(ctx).Error("failed to return loan", "id", id, "error", err)
+		return false, nil, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return false, nil, err
+	}
+	next, err := fulfilHold(ctx, tx, bookID, at, nextDue)
+	if err != nil {
+		return false, nil, err
+	}
+	return true, next, tx.Commit()
+}
+
+// fulfilHold lends book bookID to the user of its oldest waiting hold until
+// due and closes the hold. It returns nil if no hold is waiting.
+func fulfilHold(ctx context.Context, tx *sqlx.Tx, bookID int64, at, due time.Time) (*domain.Loan, error) {
+	var h struct {
+		ID     int64 `db:"id"`
+		UserID int64 `db:"user_id"`
+	}
+	err := tx.GetContext(ctx, &h, `
+		SELECT id, user_id FROM holds
+		WHERE book_id = ? AND status = ?
+		ORDER BY id
+		LIMIT 1
+		FOR UPDATE`, bookID, domain.HoldWaiting)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to read holds of book", "id", bookID, "error", err)
+		return nil, err
+	}
+	l := &domain.Loan{BookID: bookID, UserID: h.UserID, CheckedOutAt: at, DueAt: due}
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO loans (book_id, user_id, checked_out_at, due_at) VALUES (?, ?, ?, ?)`,
+		l.BookID, l.UserID, l.CheckedOutAt, l.DueAt)
+	if err != nil {
+		logger.From(ctx).Error("failed to lend book to hold", "id", bookID, "hold", h.ID, "error", err)
+		return nil, err
+	}
+	if l.ID, err = res.LastInsertId(); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE holds SET status = ?, loan_id = ?, closed_at = ? WHERE id = ?`,
+		domain.HoldFulfilled, l.ID, at, h.ID); err != nil {
+		logger.From(ctx).Error("failed to fulfil hold", "id", h.ID, "error", err)
+		return nil, err
+	}
+	return l, nil
+}
+
+func (r *loanRepository) Overdue(ctx context.Context, now time.Time, limit int) ([]domain.Loan, error) {
+	loans := []domain.Loan{}
+	if err := r.db.SelectContext(ctx, &loans, `
+		SELECT `+loanColumns+` FROM loans
+		WHERE returned_at IS NULL AND due_at < ?
+		ORDER BY due_at, id
+		LIMIT ?`, now, limit); err != nil {
+		logger.From(ctx).Error("failed to list overdue loans", "error", err)
+		return nil, err
+	}
+	return loans, nil
+}
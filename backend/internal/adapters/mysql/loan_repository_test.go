@@ -0,0 +1,114 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	mysqldriver "github.com/go-sql-driver/mysql"
+)
+
+func TestCheckout(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	due := at.AddDate(0, 0, 14)
+	for _, tc := range []struct {
+		name      string
+		book      bool
+		onLoan    bool
+		insertErr error
+		found     bool
+		err       error
+	}{
+		{"lent", true, false, nil, true, nil},
+		{"no book", false, false, nil, false, nil},
+		{"on loan", true, true, nil, true, ports.ErrBookOnLoan},
+		{"no user", true, false, &mysqldriver.MySQLError{Number: errNoReferencedRow}, true, ports.ErrUnknownBorrower},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, cleanup := newMockSQLX(t)
+			defer cleanup()
+
+			mock.ExpectBegin()
+			lock := mock.ExpectQuery("SELECT id FROM books WHERE id = \\? FOR UPDATE").WithArgs(int64(3))
+			switch {
+			case !tc.book:
+				lock.WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectRollback()
+			case tc.onLoan:
+				lock.WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(3)))
+				mock.ExpectQuery("SELECT EXISTS \\(SELECT 1 FROM loans WHERE book_id = \\? AND returned_at IS NULL\\)").
+					WithArgs(int64(3)).
+					WillReturnRows(sqlmock.NewRows([]string{"on_loan"}).AddRow(true))
+				mock.ExpectRollback()
+			default:
+				lock.WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(3)))
+				mock.ExpectQuery("SELECT EXISTS").
+					WithArgs(int64(3)).
+					WillReturnRows(sqlmock.NewRows([]string{"on_loan"}).AddRow(false))
+				insert := mock.ExpectExec("INSERT INTO loans \\(book_id, user_id, checked_out_at, due_at\\) VALUES \\(\\?, \\?, \\?, \\?\\)").
+					WithArgs(int64(3), int64(7), at, due)
+				if tc.insertErr != nil {
+					insert.WillReturnError(tc.insertErr)
+					mock.ExpectRollback()
+				} else {
+					insert.WillReturnResult(sqlmock.NewResult(11, 1))
+					mock.ExpectCommit()
+				}
+			}
+
+			l := &domain.Loan{BookID: 3, UserID: 7, CheckedOutAt: at, DueAt: due}
+			found, err := NewLoanRepository(db).Checkout(context.Background(), l)
+			if found != tc.found || !errors.Is(err, tc.err) {
+				t.Fatalf("Checkout = %v, %v; want %v, %v", found, err, tc.found, tc.err)
+			}
+			if tc.found && tc.err == nil && l.ID != 11 {
+				t.Fatalf("loan id = %d; want 11", l.ID)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoans_ReturnAndOverdue(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+	repo := NewLoanRepository(db)
+	ctx := context.Background()
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT book_id FROM loans WHERE id = \\?").
+		WithArgs(int64(11)).
+		WillReturnRows(sqlmock.NewRows([]string{"book_id"}).AddRow(int64(3)))
+	mock.ExpectQuery("SELECT id FROM books WHERE id = \\? FOR UPDATE").
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(3)))
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"on_loan"}).AddRow(false))
+	mock.ExpectExec("UPDATE loans SET returned_at = \\? WHERE id = \\? AND returned_at IS NULL").
+		WithArgs(now, int64(11)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	if open, next, err := repo.Return(ctx, 11, now, now.AddDate(0, 0, 14)); open || next != nil || err != nil {
+		t.Fatalf("Return = %v, %+v, %v; want false for a returned loan", open, next, err)
+	}
+
+	mock.ExpectQuery("SELECT id, book_id, user_id, checked_out_at, due_at, returned_at FROM loans WHERE returned_at IS NULL AND due_at < \\? ORDER BY due_at, id LIMIT \\?").
+		WithArgs(now, 100).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "book_id", "user_id", "checked_out_at", "due_at", "returned_at"}).
+			AddRow(int64(11), int64(3), int64(7), now.AddDate(0, 0, -20), now.AddDate(0, 0, -6), nil))
+	loans, err := repo.Overdue(ctx, now, 100)
+	if err != nil || len(loans) != 1 || loans[0].ReturnedAt != nil || !loans[0].Overdue(now) {
+		t.Fatalf("Overdue = %+v, %v", loans, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
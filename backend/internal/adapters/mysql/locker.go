@@ -0,0 +1,144 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+// lockPrefix namespaces the locks of this service on a shared server.
+const lockPrefix = "byfood."
+
+// Locker implements ports.Locker with MySQL's GET_LOCK. Named locks belong to
+// the session that took them, so all locks of the process are held on one
+// dedicated connection, which is pinged every renewEvery to keep it (and
+// with it every lease) alive. When the process crashes or the connection
+// drops, MySQL frees the locks and another replica can take them; the leases
+// held here are then reported lost.
+type Locker struct {
+	db         *sqlx.DB
+	renewEvery time.Duration
+
+	mu     sync.Mutex
+	conn   *sql.Conn // nil while no lock is held
+	leases map[string]*lease
+}
+
+func NewLocker(db *sqlx.DB, renewEvery time.Duration) *Locker {
+	return &Locker{db: db, renewEvery: renewEvery, leases: map[string]*lease{}}
+}
+
+type lease struct {
+	l    *Locker
+	name string
+	lost chan struct{}
+}
+
+func (ls *lease) Lost() <-chan struct{} { return ls.lost }
+
+func (ls *lease) Release(ctx context.Context) error {
+	return ls.l.release(ctx, ls)
+}
+
+func (l *Locker) TryLock(ctx context.Context, name string) (ports.Lease, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, held := l.leases[name]; held {
+		return nil, false, nil
+	}
+	if l.conn == nil {
+		conn, err := l.db.Conn(ctx)
+		if err != nil {
+			logger.From(ctx).Error("failed to open lock connection", "error", err)
+			return nil, false, err
+		}
+		l.conn = conn
+		go l.renew(conn)
+	}
+	var got sql.NullInt64
+	if err := l.conn.QueryRowContext(ctx, `SELECT GET_LOCK(?, 0)`, lockPrefix+name).Scan(&got); err != nil {
+		logger.From(ctx).Error("failed to take lock", "lock", name, "error", err)
+		l.dropLocked()
+		return nil, false, err
+	}
+	if got.Int64 != 1 {
+		return nil, false, nil
+	}
+	ls := &lease{l: l, name: name, lost: make(chan struct{})}
+	l.leases[name] = ls
+	return ls, true, nil
+}
+
+func (l *Locker) release(ctx context.Context, ls *lease) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.leases[ls.name] != ls {
+		return nil // already released or lost
+	}
+	delete(l.leases, ls.name)
+	_, err := l.conn.ExecContext(ctx, `DO RELEASE_LOCK(?)`, lockPrefix+ls.name)
+	if err != nil {
+		logger.From(ctx).Error("failed to release lock", "lock", ls.name, "error", err)
+		l.dropLocked()
+		return err
+	}
+	if len(l.leases) == 0 {
+		// no named lock is left on the session, so it can go back to the
+		// pool rather than being pinned while idle
+		l.closeLocked()
+	}
+	return nil
+}
+
+// renew pings conn until it is dropped, declaring every lease lost if the
+// session is gone.
+func (l *Locker) renew(conn *sql.Conn) {
+	t := time.NewTicker(l.renewEvery)
+	defer t.Stop()
+	for range t.C {
+		l.mu.Lock()
+		if l.conn != conn {
+			l.mu.Unlock()
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), l.renewEvery)
+		err := conn.PingContext(ctx)
+		cancel()
+		if err != nil {
+			logger.Log.Error("lock connection lost", "locks", len(l.leases), "error", err)
+			l.dropLocked()
+		}
+		l.mu.Unlock()
+	}
+}
+
+// dropLocked marks every lease lost and discards the lock connection. Closing
+// a *sql.Conn only returns its session to the pool, where it would keep any
+// named lock still on it, so the driver connection is reported bad and
+// closed, which ends the session and frees its locks on the server. The
+// caller holds l.mu.
+func (l *Locker) dropLocked() {
+	for name, ls := range l.leases {
+		close(ls.lost)
+		delete(l.leases, name)
+	}
+	if l.conn != nil {
+		_ = l.conn.Raw(func(any) error { return driver.ErrBadConn })
+		l.closeLocked()
+	}
+}
+
+// closeLocked closes the lock connection. The caller holds l.mu.
+func (l *Locker) closeLocked() {
+	if err := l.conn.Close(); err != nil && !errors.Is(err, sql.ErrConnDone) {
+		logger.Log.Error("failed to close lock connection", "error", err)
+	}
+	l.conn = nil
+}
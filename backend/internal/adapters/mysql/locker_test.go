@@ -0,0 +1,99 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestLocker_TakesAndReleases(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT GET_LOCK\\(\\?, 0\\)").WithArgs("byfood.purge").
+		WillReturnRows(sqlmock.NewRows([]string{"got"}).AddRow(1))
+	mock.ExpectQuery("SELECT GET_LOCK\\(\\?, 0\\)").WithArgs("byfood.report").
+		WillReturnRows(sqlmock.NewRows([]string{"got"}).AddRow(0))
+	mock.ExpectExec("DO RELEASE_LOCK\\(\\?\\)").WithArgs("byfood.purge").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	l := NewLocker(db, time.Hour)
+	ctx := context.Background()
+	lease, ok, err := l.TryLock(ctx, "purge")
+	if err != nil || !ok {
+		t.Fatalf("TryLock purge = %v, %v", ok, err)
+	}
+	if _, ok, _ := l.TryLock(ctx, "purge"); ok {
+		t.Fatalf("lock taken twice by the same process")
+	}
+	if _, ok, err := l.TryLock(ctx, "report"); err != nil || ok {
+		t.Fatalf("TryLock of a lock held elsewhere = %v, %v", ok, err)
+	}
+	if err := lease.Release(ctx); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if l.conn != nil {
+		t.Fatalf("idle lock connection kept open")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestLocker_LeaseLostWhenSessionDrops(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	db := sqlx.NewDb(sqlDB, "mysql")
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT GET_LOCK").WithArgs("byfood.purge").
+		WillReturnRows(sqlmock.NewRows([]string{"got"}).AddRow(1))
+	mock.ExpectPing()
+	mock.ExpectPing().WillReturnError(errors.New("connection reset"))
+
+	lease, ok, err := NewLocker(db, 5*time.Millisecond).TryLock(context.Background(), "purge")
+	if err != nil || !ok {
+		t.Fatalf("TryLock = %v, %v", ok, err)
+	}
+	select {
+	case <-lease.Lost():
+	case <-time.After(time.Second):
+		t.Fatalf("lease not lost after the connection failed")
+	}
+	if err := lease.Release(context.Background()); err != nil {
+		t.Fatalf("Release of a lost lease = %v", err)
+	}
+}
+
+func TestLocker_DiscardsSessionWhenReleaseFails(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT GET_LOCK\\(\\?, 0\\)").WithArgs("byfood.purge").
+		WillReturnRows(sqlmock.NewRows([]string{"got"}).AddRow(1))
+	mock.ExpectExec("DO RELEASE_LOCK\\(\\?\\)").WithArgs("byfood.purge").
+		WillReturnError(errors.New("lock wait timeout"))
+	// the session may still hold the lock, so it is closed, not pooled
+	mock.ExpectClose()
+
+	l := NewLocker(db, time.Hour)
+	lease, ok, err := l.TryLock(context.Background(), "purge")
+	if err != nil || !ok {
+		t.Fatalf("TryLock = %v, %v", ok, err)
+	}
+	if err := lease.Release(context.Background()); err == nil {
+		t.Fatalf("Release succeeded, want the RELEASE_LOCK error")
+	}
+	if l.conn != nil {
+		t.Fatalf("lock connection kept after a failed release")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("session returned to the pool: %v", err)
+	}
+}
@@ -0,0 +1,287 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+// Migration is one schema change: NNNN_name.sql, and NNNN_name.down.sql to
+// revert it.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string // empty when the migration can't be reverted
+}
+
+// MigrationStatus is a migration and when it was applied, if it was.
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+var migrationFile = regexp.MustCompile(`^(\d+)_(\w+?)(\.down)?\.sql$`)
+
+// LoadMigrations reads the migrations in the top directory of fsys, sorted
+// by version. Versions must be unique and every down file needs its up file.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int64]*Migration{}
+	for _, e := range entries {
+		m := migrationFile.FindStringSubmatch(e.Name())
+		if e.IsDir() || m == nil {
+			continue
+		}
+		version, _ := strconv.ParseInt(m[1], 10, 64)
+		body, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+		mig := byVersion[version]
+		if mig == nil {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		}
+		if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration %d has two names, %s and %s", version, mig.Name, m[2])
+		}
+		if m[3] != "" {
+			mig.Down = string(body)
+		} else {
+			mig.Up = string(body)
+		}
+	}
+	out := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %04d_%s has no up file", m.Version, m.Name)
+		}
+		out = append(out, *m)
+	}
+	slices.SortFunc(out, func(a, b Migration) int { return int(a.Version - b.Version) })
+	return out, nil
+}
+
+// migrateLock keeps replicas starting together from migrating at once.
+const migrateLock = lockPrefix + "migrate"
+
+// Migrator applies and reverts migrations, recording the applied versions
+// in schema_version. MySQL commits DDL as it goes, so a migration that fails
+// halfway leaves its first statements applied and isn't recorded: fix the
+// schema by hand, then record it with Force.
+type Migrator struct {
+	db          *sqlx.DB
+	migrations  []Migration
+	lockTimeout time.Duration
+}
+
+func NewMigrator(db *sqlx.DB, migrations []Migration) *Migrator {
+	return &Migrator{db: db, migrations: migrations, lockTimeout: 5 * time.Minute}
+}
+
+// Up applies every migration not applied yet, in order, and returns them.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func(conn *sql.Conn, applied map[int64]bool) error {
+		for _, mig := range m.migrations {
+			if applied[mig.Version] {
+				continue
+			}
+			if err := m.run(ctx, conn, mig, "up", mig.Up); err != nil {
+				return err
+			}
+			if _, err := conn.ExecContext(ctx,
+				`INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, UTC_TIMESTAMP(6))`,
+				mig.Version, mig.Name); err != nil {
+				return fmt.Errorf("record migration %d: %w", mig.Version, err)
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down reverts the last steps applied migrations, newest first, and returns
+// them.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func(conn *sql.Conn, applied map[int64]bool) error {
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			mig := m.migrations[i]
+			if !applied[mig.Version] {
+				continue
+			}
+			if mig.Down == "" {
+				return fmt.Errorf("migration %04d_%s can't be reverted", mig.Version, mig.Name)
+			}
+			if err := m.run(ctx, conn, mig, "down", mig.Down); err != nil {
+				return err
+			}
+			if _, err := conn.ExecContext(ctx, `DELETE FROM schema_version WHERE version = ?`, mig.Version); err != nil {
+				return fmt.Errorf("unrecord migration %d: %w", mig.Version, err)
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Force records the migrations up to version as applied and the later ones
+// as not, without running any. It adopts databases created before
+// schema_version existed, and recovers from a failed migration.
+func (m *Migrator) Force(ctx context.Context, version int64) error {
+	return m.locked(ctx, func(conn *sql.Conn, applied map[int64]bool) error {
+		if _, err := conn.ExecContext(ctx, `DELETE FROM schema_version WHERE version > ?`, version); err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			if mig.Version > version || applied[mig.Version] {
+				continue
+			}
+			if _, err := conn.ExecContext(ctx,
+				`INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, UTC_TIMESTAMP(6))`,
+				mig.Version, mig.Name); err != nil {
+				return err
+			}
+		}
+		logger.From(ctx).Info("forced schema version", "version", version)
+		return nil
+	})
+}
+
+// Status lists every migration with when it was applied.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	if err := m.ensureTable(ctx, m.db); err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		Version   int64     `db:"version"`
+		AppliedAt time.Time `db:"applied_at"`
+	}
+	if err := m.db.SelectContext(ctx, &rows, `SELECT version, applied_at FROM schema_version`); err != nil {
+		return nil, err
+	}
+	at := map[int64]time.Time{}
+	for _, r := range rows {
+		at[r.Version] = r.AppliedAt
+	}
+	out := make([]MigrationStatus, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		s := MigrationStatus{Migration: mig}
+		if t, ok := at[mig.Version]; ok {
+			s.AppliedAt = &t
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func (m *Migrator) ensureTable(ctx context.Context, db execer) error {
+	_, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_version (
+		  version BIGINT NOT NULL,
+		  name VARCHAR(255) NOT NULL,
+		  applied_at DATETIME(6) NOT NULL,
+		  PRIMARY KEY (version)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`)
+	return err
+}
+
+// locked runs fn on one connection holding the migrate lock, with the
+// versions applied so far.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn, applied map[int64]bool) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	var got sql.NullInt64
+	if err := conn.QueryRowContext(ctx, `SELECT GET_LOCK(?, ?)`, migrateLock, int(m.lockTimeout.Seconds())).Scan(&got); err != nil {
+		return err
+	}
+	if got.Int64 != 1 {
+		return errors.New("another process is migrating the database")
+	}
+	defer func() {
+		_, _ = conn.ExecContext(context.Background(), `DO RELEASE_LOCK(?)`, migrateLock)
+	}()
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return err
+	}
+	rows, err := conn.QueryContext(ctx, `SELECT version FROM schema_version`)
+	if err != nil {
+		return err
+	}
+	applied := map[int64]bool{}
+	for rows.Next() {
+		var v int64
+		if err := rows.Scan(&v); err != nil {
+			rows.Close()
+			return err
+		}
+		applied[v] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return fn(conn, applied)
+}
+
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, mig Migration, direction, script string) error {
+	start := time.Now()
+	for i, stmt := range splitStatements(script) {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			logger.From(ctx).Error("migration failed", "version", mig.Version, "name", mig.Name,
+				"direction", direction, "statement", i+1, "error", err)
+			return fmt.Errorf("migration %04d_%s %s, statement %d: %w", mig.Version, mig.Name, direction, i+1, err)
+		}
+	}
+	logger.From(ctx).Info("migration applied", "version", mig.Version, "name", mig.Name,
+		"direction", direction, "took", time.Since(start))
+	return nil
+}
+
+// splitStatements splits a script at lines ending in a semicolon, dropping
+// comment lines. Migrations don't define routines, so no statement has a
+// semicolon at the end of an inner line.
+func splitStatements(script string) []string {
+	var out []string
+	var cur strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		cur.WriteString(line)
+		cur.WriteByte('\n')
+		if strings.HasSuffix(trimmed, ";") {
+			out = append(out, strings.TrimSuffix(strings.TrimSpace(cur.String()), ";"))
+			cur.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(cur.String()); rest != "" {
+		out = append(out, rest)
+	}
+	return out
+}
@@ -0,0 +1,112 @@
+package mysql
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gerry-sabar/byfood/migrations"
+)
+
+func TestLoadMigrations_Embedded(t *testing.T) {
+	migs, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, m := range migs {
+		if m.Version != int64(i+1) {
+			t.Fatalf("migration %d has version %d; versions must have no gaps", i, m.Version)
+		}
+		if m.Down == "" {
+			t.Errorf("%04d_%s has no down file", m.Version, m.Name)
+		}
+	}
+	if cdc := splitStatements(migs[9].Up); len(cdc) != 3 || !strings.HasPrefix(cdc[1], "UPDATE book_changes") {
+		t.Fatalf("0010 statements = %q", cdc)
+	}
+}
+
+func TestLoadMigrations_Rejects(t *testing.T) {
+	for name, fsys := range map[string]fstest.MapFS{
+		"down without up": {"0001_a.down.sql": {Data: []byte("DROP TABLE a;")}},
+		"two names":       {"0001_a.sql": {Data: []byte("x;")}, "0001_b.sql": {Data: []byte("y;")}},
+	} {
+		if _, err := LoadMigrations(fsys); err == nil {
+			t.Errorf("%s accepted", name)
+		}
+	}
+}
+
+func expectMigrateLock(mock sqlmock.Sqlmock, applied ...int64) {
+	mock.ExpectQuery("SELECT GET_LOCK").WithArgs("byfood.migrate", 300).
+		WillReturnRows(sqlmock.NewRows([]string{"got"}).AddRow(1))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_version").WillReturnResult(sqlmock.NewResult(0, 0))
+	rows := sqlmock.NewRows([]string{"version"})
+	for _, v := range applied {
+		rows.AddRow(v)
+	}
+	mock.ExpectQuery("SELECT version FROM schema_version").WillReturnRows(rows)
+}
+
+func TestMigrator_UpAndDown(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+	migs, err := LoadMigrations(fstest.MapFS{
+		"0001_create_a.sql":      {Data: []byte("-- a\nCREATE TABLE a (id INT);\n")},
+		"0001_create_a.down.sql": {Data: []byte("DROP TABLE a;")},
+		"0002_seed_a.sql":        {Data: []byte("INSERT INTO a VALUES (1);\nINSERT INTO a VALUES (2);\n")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewMigrator(db, migs)
+	ctx := context.Background()
+
+	expectMigrateLock(mock, 1)
+	mock.ExpectExec("INSERT INTO a VALUES \\(1\\)").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO a VALUES \\(2\\)").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO schema_version").WithArgs(int64(2), "seed_a").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DO RELEASE_LOCK").WillReturnResult(sqlmock.NewResult(0, 0))
+	done, err := m.Up(ctx)
+	if err != nil || len(done) != 1 || done[0].Version != 2 {
+		t.Fatalf("Up = %+v, %v; want only 0002 applied", done, err)
+	}
+
+	// 0002 has no down file, so stepping back over it fails before touching 0001
+	expectMigrateLock(mock, 1, 2)
+	mock.ExpectExec("DO RELEASE_LOCK").WillReturnResult(sqlmock.NewResult(0, 0))
+	if _, err := m.Down(ctx, 2); err == nil || !strings.Contains(err.Error(), "can't be reverted") {
+		t.Fatalf("Down over an irreversible migration = %v", err)
+	}
+
+	expectMigrateLock(mock, 1)
+	mock.ExpectExec("DROP TABLE a").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM schema_version WHERE version = \\?").WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DO RELEASE_LOCK").WillReturnResult(sqlmock.NewResult(0, 0))
+	if done, err := m.Down(ctx, 1); err != nil || len(done) != 1 || done[0].Version != 1 {
+		t.Fatalf("Down = %+v, %v", done, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrator_Force(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+	migs, _ := LoadMigrations(fstest.MapFS{
+		"0001_a.sql": {Data: []byte("x;")}, "0002_b.sql": {Data: []byte("y;")}, "0003_c.sql": {Data: []byte("z;")},
+	})
+	expectMigrateLock(mock, 1, 3)
+	mock.ExpectExec("DELETE FROM schema_version WHERE version > \\?").WithArgs(int64(2)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO schema_version").WithArgs(int64(2), "b").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DO RELEASE_LOCK").WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := NewMigrator(db, migs).Force(context.Background(), 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,104 @@
+package mysql
+
+import (
+	"context"
+	"math"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// RepoOption configures optional behaviour shared by the repositories.
+type RepoOption func(*repoOptions)
+
+type repoOptions struct {
+	priceCents *DualWrite
+	audit      bool // see WithAuditLog
+}
+
+// WithPriceCents routes price reads and writes through the price-to-cents
+// migration d. Without it the repositories never touch price_cents.
+func WithPriceCents(d *DualWrite) RepoOption {
+	return func(o *repoOptions) { o.priceCents = d }
+}
+
+func newRepoOptions(opts []RepoOption) repoOptions {
+	var o repoOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// priceSelect is the price column of book SELECTs.
+func (o repoOptions) priceSelect() sqlText {
+	if o.priceCents != nil && o.priceCents.ReadsNew() {
+		// fall back for rows the backfill hasn't reached
+		return "COALESCE(price_cents / 100, price) AS price"
+	}
+	return "price"
+}
+
+// priceCentsAssign returns the SET fragment that keeps price_cents in step
+// with price. Outside dual-write the column is reset to NULL so a later
+// backfill can't miss a row that changed in between.
+func (o repoOptions) priceCentsAssign(price float64) sqlQuery {
+	d := o.priceCents
+	if d == nil {
+		return sqlQuery{}
+	}
+	if !d.WritesNew() {
+		return sqlf(", price_cents = NULL")
+	}
+	d.dualWrites.Add(1)
+	return sqlf(", price_cents = ?", toCents(price))
+}
+
+func toCents(price float64) int64 { return int64(math.Round(price * 100)) }
+
+// BackfillPriceCents fills price_cents for rows that don't have it yet, in
+// batches of batchSize so no single statement holds many row locks. It only
+// runs while d writes the new column; otherwise it returns 0.
+func BackfillPriceCents(ctx context.Context, db *sqlx.DB, d *DualWrite, batchSize int) (int, error) {
+	if !d.WritesNew() {
+		return 0, nil
+	}
+	total := 0
+	for {
+		res, err := db.ExecContext(ctx, `
+			UPDATE books SET price_cents = ROUND(price * 100)
+			WHERE price_cents IS NULL
+			LIMIT ?`, batchSize)
+		if err != nil {
+			return total, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += int(n)
+		d.backfilled.Add(n)
+		if n < int64(batchSize) {
+			return total, nil
+		}
+	}
+}
+
+// CheckPriceCents counts rows still missing price_cents and rows where it
+// disagrees with price, and records both on d. Reads should only be flipped
+// once both are zero.
+func CheckPriceCents(ctx context.Context, db *sqlx.DB, d *DualWrite) (pending, mismatched int64, err error) {
+	var row struct {
+		Pending    int64 `db:"pending"`
+		Mismatched int64 `db:"mismatched"`
+	}
+	err = db.GetContext(ctx, &row, `
+		SELECT COALESCE(SUM(price_cents IS NULL), 0) AS pending,
+		       COALESCE(SUM(price_cents <> ROUND(price * 100)), 0) AS mismatched
+		FROM books`)
+	if err != nil {
+		return 0, 0, err
+	}
+	d.pending.Set(row.Pending)
+	d.mismatched.Set(row.Mismatched)
+	return row.Pending, row.Mismatched, nil
+}
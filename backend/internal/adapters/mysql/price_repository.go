@@ -0,0 +1,88 @@
+package mysql
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type priceRepository struct {
+	db *sqlx.DB
+	repoOptions
+}
+
+func NewPriceRepository(db *sqlx.DB, opts ...RepoOption) ports.PriceRepository {
+	return &priceRepository{db: db, repoOptions: newRepoOptions(opts)}
+}
+
+func (r *priceRepository) ApplyPrices(ctx context.Context, changes []domain.PriceChange) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }() // no-op after Commit
+
+	for _, c := range changes {
+		at := c.ChangedAt.Round(time.Microsecond)
+		res, err := execSQL(ctx, tx, sqlf(`
+			UPDATE books
+			SET price = ?, updated_at = ?, version = version + 1,
+			    field_updated_at = JSON_SET(COALESCE(field_updated_at, JSON_OBJECT()), '$.price', ?)`,
+			c.NewPrice, at, at.Format(time.RFC3339Nano),
+		).append(r.priceCentsAssign(c.NewPrice), sqlf(`
+			WHERE id = ? AND price = ?`,
+			// compare as a decimal string: a float param would be compared as DOUBLE
+			c.BookID, strconv.FormatFloat(c.OldPrice, 'f', 2, 64))))
+		if err != nil {
+			logger.From(ctx).Error("failed to reprice book", "id", c.BookID, "error", err)
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return ports.ErrConcurrentUpdate
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO book_price_history (book_id, old_price, new_price, reason, batch_id, changed_by, changed_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			c.BookID, c.OldPrice, c.NewPrice, c.Reason, c.BatchID, c.ChangedBy, at,
+		); err != nil {
+			logger.From(ctx).Error("failed to record price history", "id", c.BookID, "error", err)
+			return err
+		}
+		if r.audit {
+			diff := domain.AuditDiff{"price": {Old: c.OldPrice, New: c.NewPrice}}
+			if err := appendAuditDiff(ctx, tx, domain.AuditUpdated, c.BookID, diff, at); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *priceRepository) RecordPrice(ctx context.Context, c domain.PriceChange) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO book_price_history (book_id, old_price, new_price, reason, batch_id, changed_by, changed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		c.BookID, c.OldPrice, c.NewPrice, c.Reason, c.BatchID, c.ChangedBy, c.ChangedAt.Round(time.Microsecond))
+	if err != nil {
+		logger.From(ctx).Error("failed to record price history", "id", c.BookID, "error", err)
+	}
+	return err
+}
+
+func (r *priceRepository) PriceHistory(ctx context.Context, id int64) ([]domain.PriceChange, error) {
+	history := []domain.PriceChange{}
+	err := r.db.SelectContext(ctx, &history, `
+		SELECT id, book_id, old_price, new_price, reason, batch_id, changed_by, changed_at
+		FROM book_price_history WHERE book_id = ? ORDER BY changed_at, id`, id)
+	if err != nil {
+		logger.From(ctx).Error("failed to read price history", "id", id, "error", err)
+	}
+	return history, err
+}
@@ -0,0 +1,86 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+func TestApplyPrices_Success(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	at := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	batch := "b1"
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE books SET price = \\?, updated_at = \\?, .* WHERE id = \\? AND price = \\?").
+		WithArgs(32.99, at, "2024-03-01T10:00:00Z", int64(1), "30.00").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO book_price_history").
+		WithArgs(int64(1), 30.0, 32.99, "reprice: +10%", &batch, "ops@example.com", at).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	r := NewPriceRepository(db)
+	err := r.ApplyPrices(context.Background(), []domain.PriceChange{
+		{BookID: 1, OldPrice: 30, NewPrice: 32.99, Reason: "reprice: +10%", BatchID: &batch, ChangedBy: "ops@example.com", ChangedAt: at},
+	})
+	if err != nil {
+		t.Fatalf("ApplyPrices error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestApplyPrices_PriceMovedRollsBack(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE books").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO book_price_history").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE books").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	r := NewPriceRepository(db)
+	err := r.ApplyPrices(context.Background(), []domain.PriceChange{{BookID: 1}, {BookID: 2}})
+	if !errors.Is(err, ports.ErrConcurrentUpdate) {
+		t.Fatalf("err = %v; want ErrConcurrentUpdate", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestPriceHistory_OldestFirst(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	at := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	mock.ExpectExec("INSERT INTO book_price_history \\(book_id, old_price, new_price, reason, batch_id, changed_by, changed_at\\)").
+		WithArgs(int64(1), 30.0, 32.5, "update", (*string)(nil), "ann", at).
+		WillReturnResult(sqlmock.NewResult(4, 1))
+	mock.ExpectQuery("SELECT id, book_id, old_price, new_price, reason, batch_id, changed_by, changed_at FROM book_price_history WHERE book_id = \\? ORDER BY changed_at, id").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "book_id", "old_price", "new_price", "reason", "batch_id", "changed_by", "changed_at"}).
+			AddRow(int64(4), int64(1), 30.0, 32.5, "update", nil, "ann", at))
+
+	r := NewPriceRepository(db)
+	if err := r.RecordPrice(context.Background(), domain.PriceChange{BookID: 1, OldPrice: 30, NewPrice: 32.5, Reason: "update", ChangedBy: "ann", ChangedAt: at}); err != nil {
+		t.Fatalf("RecordPrice: %v", err)
+	}
+	history, err := r.PriceHistory(context.Background(), 1)
+	if err != nil || len(history) != 1 || history[0].NewPrice != 32.5 || history[0].ChangedBy != "ann" || history[0].BatchID != nil {
+		t.Fatalf("PriceHistory = %+v, %v", history, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
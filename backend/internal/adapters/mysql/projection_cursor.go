@@ -0,0 +1,31 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+// loadCursor returns how far the change log consumer name has read, 0 if it
+// has never saved a position.
+func loadCursor(ctx context.Context, db *sqlx.DB, name string) (int64, error) {
+	var pos int64
+	err := db.GetContext(ctx, &pos, `SELECT position FROM projection_cursors WHERE name = ?`, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return pos, err
+}
+
+func saveCursor(ctx context.Context, db *sqlx.DB, name string, cursor int64) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO projection_cursors (name, position) VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE position = VALUES(position)`, name, cursor)
+	if err != nil {
+		logger.From(ctx).Error("failed to save projection cursor", "name", name, "error", err)
+	}
+	return err
+}
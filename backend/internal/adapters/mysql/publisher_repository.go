@@ -0,0 +1,157 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+)
+
+// errNoReferencedRow is MySQL's ER_NO_REFERENCED_ROW_2: a foreign key names
+// a row that doesn't exist.
+const errNoReferencedRow = 1452
+
+// bookWriteError turns a book write naming a series or publisher that
+// doesn't exist into ErrUnknownSeries or ErrUnknownPublisher; the message
+// names the foreign key.
+func bookWriteError(err error) error {
+	var myErr *mysqldriver.MySQLError
+	if errors.As(err, &myErr) && myErr.Number == errNoReferencedRow {
+		if strings.Contains(myErr.Message, "fk_books_series") {
+			return ports.ErrUnknownSeries
+		}
+		return ports.ErrUnknownPublisher
+	}
+	return err
+}
+
+type publisherRepository struct {
+	db *sqlx.DB
+	repoOptions
+}
+
+// NewPublisherRepository takes WithAuditLog, which audits the books a
+// delete detaches.
+func NewPublisherRepository(db *sqlx.DB, opts ...RepoOption) ports.PublisherRepository {
+	return &publisherRepository{db: db, repoOptions: newRepoOptions(opts)}
+}
+
+func (r *publisherRepository) Create(ctx context.Context, p *domain.Publisher) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO publishers (name, country, website, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+		p.Name, p.Country, p.Website, p.CreatedAt, p.UpdatedAt)
+	var myErr *mysqldriver.MySQLError
+	if errors.As(err, &myErr) && myErr.Number == errDuplicateKey {
+		return 0, ports.ErrPublisherNameTaken
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to create publisher", "name", p.Name, "error", err)
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *publisherRepository) List(ctx context.Context) ([]domain.Publisher, error) {
+	publishers := []domain.Publisher{}
+	err := r.db.SelectContext(ctx, &publishers, `
+		SELECT id, name, country, website, created_at, updated_at FROM publishers ORDER BY name, id`)
+	if err != nil {
+		logger.From(ctx).Error("failed to list publishers", "error", err)
+	}
+	return publishers, err
+}
+
+func (r *publisherRepository) GetByID(ctx context.Context, id int64) (*domain.Publisher, error) {
+	var p domain.Publisher
+	err := r.db.GetContext(ctx, &p, `
+		SELECT id, name, country, website, created_at, updated_at FROM publishers WHERE id = ?`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to get publisher", "id", id, "error", err)
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *publisherRepository) Update(ctx context.Context, p *domain.Publisher) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE publishers SET name = ?, country = ?, website = ?, updated_at = ? WHERE id = ?`,
+		p.Name, p.Country, p.Website, p.UpdatedAt, p.ID)
+	var myErr *mysqldriver.MySQLError
+	if errors.As(err, &myErr) && myErr.Number == errDuplicateKey {
+		return false, ports.ErrPublisherNameTaken
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to update publisher", "id", p.ID, "error", err)
+		return false, err
+	}
+	// MySQL counts changed rows, so a publisher saved unchanged reports
+	// none either; look for it
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return n > 0, err
+	}
+	var exists bool
+	err = r.db.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM publishers WHERE id = ?)`, p.ID)
+	return exists, err
+}
+
+func (r *publisherRepository) Delete(ctx context.Context, id int64, detach bool, at time.Time) (bool, []int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, nil, err
+	}
+	defer func() { _ = tx.Rollback() }() // no-op after Commit
+
+	// lock the publisher first, so no book is filed under it meanwhile
+	var locked int64
+	err = tx.GetContext(ctx, &locked, `SELECT id FROM publishers WHERE id = ? FOR UPDATE`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil, nil
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to lock publisher", "id", id, "error", err)
+		return false, nil, err
+	}
+	var books []int64
+	if err := tx.SelectContext(ctx, &books, `SELECT id FROM books WHERE publisher_id = ? ORDER BY id FOR UPDATE`, id); err != nil {
+		logger.From(ctx).Error("failed to list books of publisher", "id", id, "error", err)
+		return false, nil, err
+	}
+	if len(books) > 0 && !detach {
+		return true, nil, ports.ErrPublisherInUse
+	}
+	if len(books) > 0 {
+		at = at.Round(time.Microsecond)
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE books
+			SET publisher_id = NULL, updated_at = ?, version = version + 1,
+			    field_updated_at = JSON_SET(COALESCE(field_updated_at, JSON_OBJECT()), '$.publisher_id', ?)
+			WHERE publisher_id = ?`,
+			at, at.Format(time.RFC3339Nano), id); err != nil {
+			logger.From(ctx).Error("failed to detach books from publisher", "id", id, "error", err)
+			return false, nil, err
+		}
+		if r.audit {
+			diff := domain.AuditDiff{"publisher_id": {Old: id, New: nil}}
+			for _, book := range books {
+				if err := appendAuditDiff(ctx, tx, domain.AuditUpdated, book, diff, at); err != nil {
+					return false, nil, err
+				}
+			}
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM publishers WHERE id = ?`, id); err != nil {
+		logger.From(ctx).Error("failed to delete publisher", "id", id, "error", err)
+		return false, nil, err
+	}
+	return true, books, tx.Commit()
+}
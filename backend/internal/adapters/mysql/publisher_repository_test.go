@@ -0,0 +1,97 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	mysqldriver "github.com/go-sql-driver/mysql"
+)
+
+func TestPublisherCreate_NameTaken(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectExec("INSERT INTO publishers").
+		WillReturnError(&mysqldriver.MySQLError{Number: 1062, Message: "Duplicate entry"})
+
+	_, err := NewPublisherRepository(db).Create(context.Background(), &domain.Publisher{Name: "Ace Books"})
+	if !errors.Is(err, ports.ErrPublisherNameTaken) {
+		t.Fatalf("err = %v; want ErrPublisherNameTaken", err)
+	}
+}
+
+func TestPublisherDelete_InUse(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM publishers WHERE id = \\? FOR UPDATE").
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(3)))
+	mock.ExpectQuery("SELECT id FROM books WHERE publisher_id = \\? ORDER BY id FOR UPDATE").
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(7)))
+	mock.ExpectRollback()
+
+	found, _, err := NewPublisherRepository(db).Delete(context.Background(), 3, false, time.Now())
+	if !found || !errors.Is(err, ports.ErrPublisherInUse) {
+		t.Fatalf("found = %v, err = %v; want ErrPublisherInUse", found, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestPublisherDelete_Detach(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM publishers").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(3)))
+	mock.ExpectQuery("SELECT id FROM books WHERE publisher_id").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(7)).AddRow(int64(9)))
+	mock.ExpectExec("UPDATE books SET publisher_id = NULL, updated_at = \\?, version = version \\+ 1, "+
+		"field_updated_at = JSON_SET\\(COALESCE\\(field_updated_at, JSON_OBJECT\\(\\)\\), '\\$.publisher_id', \\?\\) WHERE publisher_id = \\?").
+		WithArgs(at, at.Format(time.RFC3339Nano), int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	for _, book := range []int64{7, 9} {
+		mock.ExpectExec("INSERT INTO audit_log").
+			WithArgs(book, "updated", "ann", "", `{"publisher_id":{"old":3,"new":null}}`, at).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectExec("DELETE FROM publishers WHERE id = \\?").
+		WithArgs(int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	ctx := domain.WithActor(context.Background(), domain.Actor{ID: "ann"})
+	found, detached, err := NewPublisherRepository(db, WithAuditLog()).Delete(ctx, 3, true, at)
+	if err != nil || !found || !slices.Equal(detached, []int64{7, 9}) {
+		t.Fatalf("Delete = %v, %v, %v", found, detached, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCreateBook_UnknownPublisher(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectExec("INSERT INTO books").
+		WillReturnError(&mysqldriver.MySQLError{Number: 1452, Message: "Cannot add or update a child row"})
+
+	publisher := int64(42)
+	_, err := NewBookRepository(db).Create(context.Background(), &domain.Book{Title: "Dune", PublisherID: &publisher})
+	if !errors.Is(err, ports.ErrUnknownPublisher) {
+		t.Fatalf("err = %v; want ErrUnknownPublisher", err)
+	}
+}
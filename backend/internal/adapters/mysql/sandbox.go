@@ -0,0 +1,112 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+// sandboxTables are emptied by ResetSandbox, children before parents.
+var sandboxTables = []sqlText{
+	"saved_search_notifications", "saved_searches",
+	"author_books", "author_summaries", "projection_cursors",
+	"audit_log", "book_aliases", "book_categories", "book_tags", "book_price_history", "book_views", "book_changes",
+	"books", "tags", "publishers", "series",
+}
+
+// SandboxCopy is the part of the catalogue ResetSandbox copies: the books,
+// with their aliases, categories and tags, and the publishers and series
+// they refer to.
+type SandboxCopy struct {
+	Books      []domain.Book
+	Publishers []domain.Publisher
+	Series     []domain.Series
+}
+
+// ResetSandbox empties the sandbox database db and refills it with c,
+// keeping ids, in one transaction. db must never be the production
+// database.
+func ResetSandbox(ctx context.Context, db *sqlx.DB, c SandboxCopy) error {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }() // no-op after Commit
+
+	for _, t := range sandboxTables {
+		if _, err := execSQL(ctx, tx, sqlf(`DELETE FROM `+t)); err != nil {
+			logger.From(ctx).Error("failed to empty sandbox table", "table", t, "error", err)
+			return err
+		}
+	}
+	for _, p := range c.Publishers {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO publishers (id, name, country, website, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?)`, p.ID, p.Name, p.Country, p.Website, p.CreatedAt, p.UpdatedAt,
+		); err != nil {
+			logger.From(ctx).Error("failed to copy publisher into sandbox", "id", p.ID, "error", err)
+			return err
+		}
+	}
+	for _, s := range c.Series {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO series (id, name, created_at, updated_at)
+			VALUES (?, ?, ?, ?)`, s.ID, s.Name, s.CreatedAt, s.UpdatedAt,
+		); err != nil {
+			logger.From(ctx).Error("failed to copy series into sandbox", "id", s.ID, "error", err)
+			return err
+		}
+	}
+	for _, b := range c.Books {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO books (id, title, author, isbn, isbn10, price, price_cents, publication_year, created_at, updated_at, version, field_updated_at,
+			                   title_key, author_key, work_id, publisher_id, series_id, series_position, format)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			b.ID, b.Title, b.Author, b.ISBN, b.ISBN10, b.Price, toCents(b.Price), b.PublicationYear, b.CreatedAt, b.UpdatedAt, max(b.Version, 1), b.FieldUpdatedAt,
+			domain.SearchKey(b.Title), domain.SearchKey(b.Author), b.WorkID, b.PublisherID, b.SeriesID, b.SeriesPosition, b.Format,
+		); err != nil {
+			logger.From(ctx).Error("failed to copy book into sandbox", "id", b.ID, "error", err)
+			return err
+		}
+		for _, a := range b.Aliases {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO book_aliases (book_id, alias, alias_key, created_at)
+				VALUES (?, ?, ?, ?)`, b.ID, a, domain.SearchKey(a), b.CreatedAt,
+			); err != nil {
+				logger.From(ctx).Error("failed to copy alias into sandbox", "book_id", b.ID, "error", err)
+				return err
+			}
+		}
+		for _, cat := range b.Categories {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO book_categories (book_id, category) VALUES (?, ?)`, b.ID, cat,
+			); err != nil {
+				logger.From(ctx).Error("failed to copy category into sandbox", "book_id", b.ID, "error", err)
+				return err
+			}
+		}
+		for _, tag := range b.Tags {
+			// LAST_INSERT_ID(id) makes a tag another book brought report its id
+			res, err := tx.ExecContext(ctx, `
+				INSERT INTO tags (name, tag_key, created_at) VALUES (?, ?, ?)
+				ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id)`, tag, domain.TagKey(tag), b.CreatedAt)
+			if err != nil {
+				logger.From(ctx).Error("failed to copy tag into sandbox", "book_id", b.ID, "error", err)
+				return err
+			}
+			tagID, err := res.LastInsertId()
+			if err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO book_tags (book_id, tag_id, created_at) VALUES (?, ?, ?)`, b.ID, tagID, b.CreatedAt,
+			); err != nil {
+				logger.From(ctx).Error("failed to tag book in sandbox", "book_id", b.ID, "error", err)
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
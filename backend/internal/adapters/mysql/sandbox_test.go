@@ -0,0 +1,79 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+func TestResetSandbox(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	now := time.Now()
+	mock.ExpectBegin()
+	for _, table := range sandboxTables {
+		mock.ExpectExec("DELETE FROM " + string(table)).WillReturnResult(sqlmock.NewResult(0, 3))
+	}
+	mock.ExpectExec("INSERT INTO publishers \\(id, name, country, website, created_at, updated_at\\)").
+		WithArgs(int64(4), "Grove Press", "US", "https://groveatlantic.com", now, now).
+		WillReturnResult(sqlmock.NewResult(4, 1))
+	mock.ExpectExec("INSERT INTO series \\(id, name, created_at, updated_at\\)").
+		WithArgs(int64(2), "Collected Fictions", now, now).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectExec("INSERT INTO books \\(id, title, .* publisher_id, series_id, series_position, format\\)").
+		WithArgs(int64(9), "Ficciones", "Jorge Luis Borges", "9780802130303", "", 12.5, int64(1250), 1944, now, now, int64(3), sqlmock.AnyArg(),
+			"ficciones", "jorge luis borges", int64(5), int64(4), int64(2), 1, "paperback").
+		WillReturnResult(sqlmock.NewResult(9, 1))
+	mock.ExpectExec("INSERT INTO book_aliases").
+		WithArgs(int64(9), "Fictions", "fictions", now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO book_categories \\(book_id, category\\)").
+		WithArgs(int64(9), "short-stories").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO tags \\(name, tag_key, created_at\\) .* ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID\\(id\\)").
+		WithArgs("Magic Realism", "magic realism", now).
+		WillReturnResult(sqlmock.NewResult(6, 1))
+	mock.ExpectExec("INSERT INTO book_tags \\(book_id, tag_id, created_at\\)").
+		WithArgs(int64(9), int64(6), now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	work, publisher, series, position := int64(5), int64(4), int64(2), 1
+	err := ResetSandbox(context.Background(), db, SandboxCopy{
+		Books: []domain.Book{{
+			ID: 9, Title: "Ficciones", Author: "Jorge Luis Borges", ISBN: "9780802130303", Price: 12.5,
+			PublicationYear: 1944, CreatedAt: now, UpdatedAt: now, Version: 3, Aliases: []string{"Fictions"},
+			Categories: []string{"short-stories"}, Tags: []string{"Magic Realism"},
+			WorkID: &work, PublisherID: &publisher, SeriesID: &series, SeriesPosition: &position, Format: "paperback",
+		}},
+		Publishers: []domain.Publisher{{ID: 4, Name: "Grove Press", Country: "US", Website: "https://groveatlantic.com", CreatedAt: now, UpdatedAt: now}},
+		Series:     []domain.Series{{ID: 2, Name: "Collected Fictions", CreatedAt: now, UpdatedAt: now}},
+	})
+	if err != nil {
+		t.Fatalf("ResetSandbox error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestResetSandbox_RollsBackOnError(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM " + string(sandboxTables[0])).WillReturnError(assertErr("locked"))
+	mock.ExpectRollback()
+
+	if err := ResetSandbox(context.Background(), db, SandboxCopy{}); err == nil {
+		t.Fatalf("expected error; got nil")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
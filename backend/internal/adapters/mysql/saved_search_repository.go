@@ -0,0 +1,116 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+// savedSearchMatcher names the saved search matcher in projection_cursors.
+const savedSearchMatcher = "saved_searches"
+
+type savedSearchRepository struct {
+	db *sqlx.DB
+}
+
+func NewSavedSearchRepository(db *sqlx.DB) ports.SavedSearchRepository {
+	return &savedSearchRepository{db: db}
+}
+
+func (r *savedSearchRepository) Create(ctx context.Context, s *domain.SavedSearch) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO saved_searches (owner, name, query, notify, created_at) VALUES (?, ?, ?, ?, ?)`,
+		s.Owner, s.Name, s.Query, s.Notify, s.CreatedAt)
+	if err != nil {
+		logger.From(ctx).Error("failed to create saved search", "name", s.Name, "error", err)
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *savedSearchRepository) List(ctx context.Context) ([]domain.SavedSearch, error) {
+	out := []domain.SavedSearch{}
+	err := r.db.SelectContext(ctx, &out, `
+		SELECT id, owner, name, query, notify, created_at FROM saved_searches ORDER BY id`)
+	if err != nil {
+		logger.From(ctx).Error("failed to list saved searches", "error", err)
+	}
+	return out, err
+}
+
+func (r *savedSearchRepository) ListByOwner(ctx context.Context, owner string) ([]domain.SavedSearch, error) {
+	out := []domain.SavedSearch{}
+	err := r.db.SelectContext(ctx, &out, `
+		SELECT id, owner, name, query, notify, created_at FROM saved_searches WHERE owner = ? ORDER BY id`, owner)
+	if err != nil {
+		logger.From(ctx).Error("failed to list saved searches", "owner", owner, "error", err)
+	}
+	return out, err
+}
+
+func (r *savedSearchRepository) GetByID(ctx context.Context, owner string, id int64) (*domain.SavedSearch, error) {
+	var s domain.SavedSearch
+	err := r.db.GetContext(ctx, &s, `
+		SELECT id, owner, name, query, notify, created_at FROM saved_searches WHERE id = ? AND owner = ?`, id, owner)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to get saved search", "id", id, "error", err)
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *savedSearchRepository) Delete(ctx context.Context, owner string, id int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM saved_searches WHERE id = ? AND owner = ?`, id, owner)
+	if err != nil {
+		logger.From(ctx).Error("failed to delete saved search", "id", id, "error", err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *savedSearchRepository) AddNotification(ctx context.Context, n *domain.SearchNotification) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		INSERT IGNORE INTO saved_search_notifications (search_id, book_id, title, created_at)
+		VALUES (?, ?, ?, ?)`, n.SearchID, n.BookID, n.Title, n.CreatedAt)
+	if err != nil {
+		logger.From(ctx).Error("failed to add search notification", "search_id", n.SearchID, "book_id", n.BookID, "error", err)
+		return false, err
+	}
+	added, err := res.RowsAffected()
+	if err != nil || added == 0 {
+		return false, err
+	}
+	n.ID, err = res.LastInsertId()
+	return true, err
+}
+
+func (r *savedSearchRepository) Notifications(ctx context.Context, searchID int64, limit int) ([]domain.SearchNotification, error) {
+	out := []domain.SearchNotification{}
+	err := r.db.SelectContext(ctx, &out, `
+		SELECT id, search_id, book_id, title, created_at
+		FROM saved_search_notifications
+		WHERE search_id = ?
+		ORDER BY id DESC
+		LIMIT ?`, searchID, limit)
+	if err != nil {
+		logger.From(ctx).Error("failed to list search notifications", "search_id", searchID, "error", err)
+	}
+	return out, err
+}
+
+func (r *savedSearchRepository) Cursor(ctx context.Context) (int64, error) {
+	return loadCursor(ctx, r.db, savedSearchMatcher)
+}
+
+func (r *savedSearchRepository) SaveCursor(ctx context.Context, cursor int64) error {
+	return saveCursor(ctx, r.db, savedSearchMatcher, cursor)
+}
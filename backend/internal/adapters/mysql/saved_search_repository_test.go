@@ -0,0 +1,66 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+func TestSavedSearchAddNotification(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	now := time.Now()
+	mock.ExpectExec("INSERT IGNORE INTO saved_search_notifications").
+		WithArgs(int64(1), int64(7), "Ficciones", now).
+		WillReturnResult(sqlmock.NewResult(12, 1))
+	mock.ExpectExec("INSERT IGNORE INTO saved_search_notifications").
+		WithArgs(int64(1), int64(7), "Ficciones", now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	r := NewSavedSearchRepository(db)
+	n := &domain.SearchNotification{SearchID: 1, BookID: 7, Title: "Ficciones", CreatedAt: now}
+	if added, err := r.AddNotification(context.Background(), n); err != nil || !added || n.ID != 12 {
+		t.Fatalf("first AddNotification = %v, %v (id %d)", added, err, n.ID)
+	}
+	dup := &domain.SearchNotification{SearchID: 1, BookID: 7, Title: "Ficciones", CreatedAt: now}
+	if added, err := r.AddNotification(context.Background(), dup); err != nil || added {
+		t.Fatalf("duplicate AddNotification = %v, %v", added, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSavedSearchDeleteAndCursor(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectExec("DELETE FROM saved_searches WHERE id = \\? AND owner = \\?").
+		WithArgs(int64(3), "ann@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT position FROM projection_cursors WHERE name = \\?").
+		WithArgs("saved_searches").
+		WillReturnRows(sqlmock.NewRows([]string{"position"}))
+	mock.ExpectExec("INSERT INTO projection_cursors .* ON DUPLICATE KEY UPDATE").
+		WithArgs("saved_searches", int64(40)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	r := NewSavedSearchRepository(db)
+	if found, err := r.Delete(context.Background(), "ann@example.com", 3); err != nil || found {
+		t.Fatalf("Delete = %v, %v; want false, nil", found, err)
+	}
+	if pos, err := r.Cursor(context.Background()); err != nil || pos != 0 {
+		t.Fatalf("Cursor = %d, %v", pos, err)
+	}
+	if err := r.SaveCursor(context.Background(), 40); err != nil {
+		t.Fatalf("SaveCursor error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package mysql
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type searchInsightsRepository struct {
+	db *sqlx.DB
+}
+
+func NewSearchInsightsRepository(db *sqlx.DB) ports.SearchInsightsRepository {
+	return &searchInsightsRepository{db: db}
+}
+
+// AddSearchUsage upserts all counters in a single statement.
+func (r *searchInsightsRepository) AddSearchUsage(ctx context.Context, counts map[domain.SearchUsageKey]domain.SearchCounts) error {
+	if len(counts) == 0 {
+		return nil
+	}
+	keys := make([]domain.SearchUsageKey, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { // stable lock order
+		a, b := keys[i], keys[j]
+		if !a.Day.Equal(b.Day) {
+			return a.Day.Before(b.Day)
+		}
+		return a.Query < b.Query
+	})
+
+	rows := make([][]any, 0, len(keys))
+	for _, k := range keys {
+		c := counts[k]
+		rows = append(rows, []any{k.Day.Format(time.DateOnly), k.Query, c.Searches, c.ZeroResults, c.Clicks})
+	}
+	_, err := execSQL(ctx, r.db, sqlf(`
+		INSERT INTO search_query_stats (day, query_key, searches, zero_results, clicks)
+		VALUES `).append(repeatSQL("(?, ?, ?, ?, ?)", ", ", rows), sqlf(`
+		ON DUPLICATE KEY UPDATE searches = searches + VALUES(searches),
+			zero_results = zero_results + VALUES(zero_results), clicks = clicks + VALUES(clicks)`)))
+	if err != nil {
+		logger.From(ctx).Error("failed to add search usage", "rows", len(keys), "error", err)
+	}
+	return err
+}
+
+func (r *searchInsightsRepository) SearchTotalsSince(ctx context.Context, since time.Time) (domain.SearchCounts, error) {
+	var c domain.SearchCounts
+	err := r.db.GetContext(ctx, &c, `
+		SELECT COALESCE(SUM(searches), 0) AS searches, COALESCE(SUM(zero_results), 0) AS zero_results,
+			COALESCE(SUM(clicks), 0) AS clicks
+		FROM search_query_stats
+		WHERE day >= ?`, since.Format(time.DateOnly))
+	if err != nil {
+		logger.From(ctx).Error("failed to read search totals", "since", since, "error", err)
+	}
+	return c, err
+}
+
+func (r *searchInsightsRepository) TopSearchQueriesSince(ctx context.Context, since time.Time, zeroResults bool, limit int) ([]domain.SearchQueryCount, error) {
+	order := sqlf(`
+		ORDER BY searches DESC, query_key
+		LIMIT ?`, limit)
+	if zeroResults {
+		order = sqlf(`
+		HAVING zero_results > 0
+		ORDER BY zero_results DESC, query_key
+		LIMIT ?`, limit)
+	}
+	queries := []domain.SearchQueryCount{}
+	err := selectSQL(ctx, r.db, &queries, sqlf(`
+		SELECT query_key, SUM(searches) AS searches, SUM(zero_results) AS zero_results, SUM(clicks) AS clicks
+		FROM search_query_stats
+		WHERE day >= ?
+		GROUP BY query_key`, since.Format(time.DateOnly)).append(order))
+	if err != nil {
+		logger.From(ctx).Error("failed to read top search queries", "since", since, "zero_results", zeroResults, "error", err)
+	}
+	return queries, err
+}
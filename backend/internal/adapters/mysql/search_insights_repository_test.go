@@ -0,0 +1,55 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+func TestSearchInsightsRepository(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	mock.ExpectExec("INSERT INTO search_query_stats .* ON DUPLICATE KEY UPDATE searches = searches \\+ VALUES\\(searches\\)").
+		WithArgs("2024-01-02", "dune", int64(2), int64(0), int64(1), "2024-01-02", "zzz", int64(1), int64(1), int64(0)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery("SELECT COALESCE\\(SUM\\(searches\\), 0\\) AS searches, .* FROM search_query_stats WHERE day >= \\?").
+		WithArgs("2024-01-02").
+		WillReturnRows(sqlmock.NewRows([]string{"searches", "zero_results", "clicks"}).AddRow(3, 1, 1))
+	mock.ExpectQuery("SELECT query_key, .* GROUP BY query_key ORDER BY searches DESC, query_key LIMIT \\?").
+		WithArgs("2024-01-02", 5).
+		WillReturnRows(sqlmock.NewRows([]string{"query_key", "searches", "zero_results", "clicks"}).AddRow("dune", 2, 0, 1))
+	mock.ExpectQuery("GROUP BY query_key HAVING zero_results > 0 ORDER BY zero_results DESC, query_key LIMIT \\?").
+		WithArgs("2024-01-02", 5).
+		WillReturnRows(sqlmock.NewRows([]string{"query_key", "searches", "zero_results", "clicks"}).AddRow("zzz", 1, 1, 0))
+
+	repo := NewSearchInsightsRepository(db)
+	ctx := context.Background()
+	err := repo.AddSearchUsage(ctx, map[domain.SearchUsageKey]domain.SearchCounts{
+		{Day: day, Query: "zzz"}:  {Searches: 1, ZeroResults: 1},
+		{Day: day, Query: "dune"}: {Searches: 2, Clicks: 1},
+	})
+	if err != nil {
+		t.Fatalf("AddSearchUsage: %v", err)
+	}
+	totals, err := repo.SearchTotalsSince(ctx, day)
+	if err != nil || totals != (domain.SearchCounts{Searches: 3, ZeroResults: 1, Clicks: 1}) {
+		t.Fatalf("SearchTotalsSince = %+v, %v", totals, err)
+	}
+	top, err := repo.TopSearchQueriesSince(ctx, day, false, 5)
+	if err != nil || len(top) != 1 || top[0].Query != "dune" || top[0].Clicks != 1 {
+		t.Fatalf("TopSearchQueriesSince = %+v, %v", top, err)
+	}
+	zero, err := repo.TopSearchQueriesSince(ctx, day, true, 5)
+	if err != nil || len(zero) != 1 || zero[0].Query != "zzz" || zero[0].ZeroResults != 1 {
+		t.Fatalf("TopSearchQueriesSince(zero) = %+v, %v", zero, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type searchRankingRepository struct {
+	db *sqlx.DB
+}
+
+func NewSearchRankingRepository(db *sqlx.DB) ports.SearchRankingRepository {
+	return &searchRankingRepository{db: db}
+}
+
+func (r *searchRankingRepository) Get(ctx context.Context, tenant string) (*domain.SearchRanking, error) {
+	var s domain.SearchRanking
+	err := r.db.GetContext(ctx, &s, `
+		SELECT tenant, title_weight, author_weight, recency_boost, updated_at FROM search_rankings WHERE tenant = ?`, tenant)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to get search ranking", "tenant", tenant, "error", err)
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *searchRankingRepository) List(ctx context.Context) ([]domain.SearchRanking, error) {
+	out := []domain.SearchRanking{}
+	if err := r.db.SelectContext(ctx, &out, `
+		SELECT tenant, title_weight, author_weight, recency_boost, updated_at FROM search_rankings ORDER BY tenant`); err != nil {
+		logger.From(ctx).Error("failed to list search rankings", "error", err)
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *searchRankingRepository) Put(ctx context.Context, s *domain.SearchRanking) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO search_rankings (tenant, title_weight, author_weight, recency_boost, updated_at) VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE title_weight = VALUES(title_weight), author_weight = VALUES(author_weight),
+			recency_boost = VALUES(recency_boost), updated_at = VALUES(updated_at)`,
+		s.Tenant, s.TitleWeight, s.AuthorWeight, s.RecencyBoost, s.UpdatedAt)
+	if err != nil {
+		logger.From(ctx).Error("failed to save search ranking", "tenant", s.Tenant, "error", err)
+	}
+	return err
+}
+
+func (r *searchRankingRepository) Delete(ctx context.Context, tenant string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM search_rankings WHERE tenant = ?`, tenant)
+	if err != nil {
+		logger.From(ctx).Error("failed to delete search ranking", "tenant", tenant, "error", err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
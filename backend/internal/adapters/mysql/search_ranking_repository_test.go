@@ -0,0 +1,51 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+func TestSearchRankings_PutListDelete(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+	repo := NewSearchRankingRepository(db)
+	ctx := context.Background()
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cols := []string{"tenant", "title_weight", "author_weight", "recency_boost", "updated_at"}
+
+	mock.ExpectExec("INSERT INTO search_rankings \\(tenant, title_weight, author_weight, recency_boost, updated_at\\) VALUES \\(\\?, \\?, \\?, \\?, \\?\\) ON DUPLICATE KEY UPDATE").
+		WithArgs("key:storefront", 3.0, 1.0, 0.5, at).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s := &domain.SearchRanking{Tenant: "key:storefront", RankingWeights: domain.RankingWeights{TitleWeight: 3, AuthorWeight: 1, RecencyBoost: 0.5}, UpdatedAt: at}
+	if err := repo.Put(ctx, s); err != nil {
+		t.Fatalf("Put error: %v", err)
+	}
+
+	mock.ExpectQuery("SELECT tenant, title_weight, author_weight, recency_boost, updated_at FROM search_rankings ORDER BY tenant").
+		WillReturnRows(sqlmock.NewRows(cols).AddRow("key:storefront", 3.0, 1.0, 0.5, at))
+	list, err := repo.List(ctx)
+	if err != nil || len(list) != 1 || list[0].TitleWeight != 3 || list[0].RecencyBoost != 0.5 {
+		t.Fatalf("List = %+v, %v", list, err)
+	}
+
+	mock.ExpectQuery("SELECT tenant, title_weight, author_weight, recency_boost, updated_at FROM search_rankings WHERE tenant = \\?").
+		WithArgs("nobody").
+		WillReturnRows(sqlmock.NewRows(cols))
+	if s, err := repo.Get(ctx, "nobody"); s != nil || err != nil {
+		t.Fatalf("Get(nobody) = %+v, %v; want nil", s, err)
+	}
+
+	mock.ExpectExec("DELETE FROM search_rankings WHERE tenant = \\?").
+		WithArgs("key:storefront").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if found, err := repo.Delete(ctx, "key:storefront"); !found || err != nil {
+		t.Fatalf("Delete = %v, %v", found, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
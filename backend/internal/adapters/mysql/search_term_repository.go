@@ -0,0 +1,30 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type searchTermRepository struct {
+	db *sqlx.DB
+}
+
+func NewSearchTermRepository(db *sqlx.DB) ports.SearchTermSource {
+	return &searchTermRepository{db: db}
+}
+
+func (r *searchTermRepository) SearchTerms(ctx context.Context, limit int) ([]string, error) {
+	terms := []string{}
+	if err := r.db.SelectContext(ctx, &terms, `
+		SELECT title FROM books
+		UNION
+		SELECT author FROM books
+		LIMIT ?`, limit); err != nil {
+		logger.From(ctx).Error("failed to list search terms", "error", err)
+		return nil, err
+	}
+	return terms, nil
+}
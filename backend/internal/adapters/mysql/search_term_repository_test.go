@@ -0,0 +1,24 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestSearchTerms(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT title FROM books UNION SELECT author FROM books LIMIT \\?").
+		WithArgs(50000).
+		WillReturnRows(sqlmock.NewRows([]string{"title"}).AddRow("The Hobbit").AddRow("J. R. R. Tolkien"))
+	terms, err := NewSearchTermRepository(db).SearchTerms(context.Background(), 50000)
+	if err != nil || len(terms) != 2 || terms[1] != "J. R. R. Tolkien" {
+		t.Fatalf("SearchTerms = %q, %v", terms, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+)
+
+type seriesRepository struct {
+	db *sqlx.DB
+	repoOptions
+}
+
+// NewSeriesRepository takes WithAuditLog, which audits the books a delete
+// detaches.
+func NewSeriesRepository(db *sqlx.DB, opts ...RepoOption) ports.SeriesRepository {
+	return &seriesRepository{db: db, repoOptions: newRepoOptions(opts)}
+}
+
+func (r *seriesRepository) Create(ctx context.Context, s *domain.Series) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO series (name, created_at, updated_at) VALUES (?, ?, ?)`,
+		s.Name, s.CreatedAt, s.UpdatedAt)
+	var myErr *mysqldriver.MySQLError
+	if errors.As(err, &myErr) && myErr.Number == errDuplicateKey {
+		return 0, ports.ErrSeriesNameTaken
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to create series", "name", s.Name, "error", err)
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *seriesRepository) List(ctx context.Context) ([]domain.Series, error) {
+	series := []domain.Series{}
+	err := r.db.SelectContext(ctx, &series, `
+		SELECT id, name, created_at, updated_at FROM series ORDER BY name, id`)
+	if err != nil {
+		logger.From(ctx).Error("failed to list series", "error", err)
+	}
+	return series, err
+}
+
+func (r *seriesRepository) GetByID(ctx context.Context, id int64) (*domain.Series, error) {
+	var s domain.Series
+	err := r.db.GetContext(ctx, &s, `
+		SELECT id, name, created_at, updated_at FROM series WHERE id = ?`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to get series", "id", id, "error", err)
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *seriesRepository) Update(ctx context.Context, s *domain.Series) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE series SET name = ?, updated_at = ? WHERE id = ?`,
+		s.Name, s.UpdatedAt, s.ID)
+	var myErr *mysqldriver.MySQLError
+	if errors.As(err, &myErr) && myErr.Number == errDuplicateKey {
+		return false, ports.ErrSeriesNameTaken
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to update series", "id", s.ID, "error", err)
+		return false, err
+	}
+	// MySQL counts changed rows, so a series saved unchanged reports none
+	// either; look for it
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return n > 0, err
+	}
+	var exists bool
+	err = r.db.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM series WHERE id = ?)`, s.ID)
+	return exists, err
+}
+
+func (r *seriesRepository) Delete(ctx context.Context, id int64, detach bool, at time.Time) (bool, []int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, nil, err
+	}
+	defer func() { _ = tx.Rollback() }() // no-op after Commit
+
+	// lock the series first, so no book joins it meanwhile
+	var locked int64
+	err = tx.GetContext(ctx, &locked, `SELECT id FROM series WHERE id = ? FOR UPDATE`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil, nil
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to lock series", "id", id, "error", err)
+		return false, nil, err
+	}
+	var members []struct {
+		ID       int64 `db:"id"`
+		Position *int  `db:"series_position"`
+	}
+	if err := tx.SelectContext(ctx, &members, `SELECT id, series_position FROM books WHERE series_id = ? ORDER BY id FOR UPDATE`, id); err != nil {
+		logger.From(ctx).Error("failed to list books of series", "id", id, "error", err)
+		return false, nil, err
+	}
+	books := make([]int64, len(members))
+	for i, m := range members {
+		books[i] = m.ID
+	}
+	if len(books) > 0 && !detach {
+		return true, nil, ports.ErrSeriesInUse
+	}
+	if len(books) > 0 {
+		at = at.Round(time.Microsecond)
+		stamp := at.Format(time.RFC3339Nano)
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE books
+			SET series_id = NULL, series_position = NULL, updated_at = ?, version = version + 1,
+			    field_updated_at = JSON_SET(COALESCE(field_updated_at, JSON_OBJECT()), '$.series_id', ?, '$.series_position', ?)
+			WHERE series_id = ?`,
+			at, stamp, stamp, id); err != nil {
+			logger.From(ctx).Error("failed to detach books from series", "id", id, "error", err)
+			return false, nil, err
+		}
+		if r.audit {
+			for _, m := range members {
+				diff := domain.AuditDiff{"series_id": {Old: id, New: nil}}
+				if m.Position != nil {
+					diff["series_position"] = domain.FieldChange{Old: *m.Position, New: nil}
+				}
+				if err := appendAuditDiff(ctx, tx, domain.AuditUpdated, m.ID, diff, at); err != nil {
+					return false, nil, err
+				}
+			}
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM series WHERE id = ?`, id); err != nil {
+		logger.From(ctx).Error("failed to delete series", "id", id, "error", err)
+		return false, nil, err
+	}
+	return true, books, tx.Commit()
+}
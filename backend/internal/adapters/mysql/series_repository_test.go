@@ -0,0 +1,78 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	mysqldriver "github.com/go-sql-driver/mysql"
+)
+
+func TestSeriesCreate_NameTaken(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectExec("INSERT INTO series").
+		WillReturnError(&mysqldriver.MySQLError{Number: 1062, Message: "Duplicate entry"})
+
+	_, err := NewSeriesRepository(db).Create(context.Background(), &domain.Series{Name: "Dune"})
+	if !errors.Is(err, ports.ErrSeriesNameTaken) {
+		t.Fatalf("err = %v; want ErrSeriesNameTaken", err)
+	}
+}
+
+func TestSeriesDelete_Detach(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	stamp := at.Format(time.RFC3339Nano)
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM series WHERE id = \\? FOR UPDATE").
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(3)))
+	mock.ExpectQuery("SELECT id, series_position FROM books WHERE series_id = \\? ORDER BY id FOR UPDATE").
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "series_position"}).AddRow(int64(7), 2).AddRow(int64(9), nil))
+	mock.ExpectExec("UPDATE books SET series_id = NULL, series_position = NULL, updated_at = \\?, version = version \\+ 1, "+
+		"field_updated_at = JSON_SET\\(COALESCE\\(field_updated_at, JSON_OBJECT\\(\\)\\), '\\$.series_id', \\?, '\\$.series_position', \\?\\) WHERE series_id = \\?").
+		WithArgs(at, stamp, stamp, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO audit_log").
+		WithArgs(int64(7), "updated", "", "", `{"series_id":{"old":3,"new":null},"series_position":{"old":2,"new":null}}`, at).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO audit_log").
+		WithArgs(int64(9), "updated", "", "", `{"series_id":{"old":3,"new":null}}`, at).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM series WHERE id = \\?").
+		WithArgs(int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	found, detached, err := NewSeriesRepository(db, WithAuditLog()).Delete(context.Background(), 3, true, at)
+	if err != nil || !found || !slices.Equal(detached, []int64{7, 9}) {
+		t.Fatalf("Delete = %v, %v, %v", found, detached, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCreateBook_UnknownSeries(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectExec("INSERT INTO books").
+		WillReturnError(&mysqldriver.MySQLError{Number: 1452, Message: "Cannot add or update a child row: a foreign key constraint fails (`byfood`.`books`, CONSTRAINT `fk_books_series` FOREIGN KEY (`series_id`) REFERENCES `series` (`id`))"})
+
+	series := int64(42)
+	_, err := NewBookRepository(db).Create(context.Background(), &domain.Book{Title: "Dune", SeriesID: &series})
+	if !errors.Is(err, ports.ErrUnknownSeries) {
+		t.Fatalf("err = %v; want ErrUnknownSeries", err)
+	}
+}
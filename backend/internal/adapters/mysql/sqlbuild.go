@@ -0,0 +1,88 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// sqlText is SQL written in this package. Only untyped string constants
+// convert to it implicitly, so a value from a request can't become SQL text
+// by accident: it would need an explicit sqlText(...) conversion, which
+// TestSQLTextOnlyFromConstants rejects unless its operand is a literal.
+// Values reach queries only as arguments bound to ? placeholders.
+type sqlText string
+
+// sqlQuery is SQL text with the arguments bound to its placeholders. The
+// zero value is the empty query.
+type sqlQuery struct {
+	sql  string
+	args []any
+}
+
+// sqlf returns text with args bound to its ? placeholders. It panics when
+// their numbers differ, which is a bug in the calling code.
+func sqlf(text sqlText, args ...any) sqlQuery {
+	if n := strings.Count(string(text), "?"); n != len(args) {
+		panic(fmt.Sprintf("mysql: %d placeholders but %d arguments in %q", n, len(args), text))
+	}
+	return sqlQuery{sql: string(text), args: args}
+}
+
+// append returns q followed by parts.
+func (q sqlQuery) append(parts ...sqlQuery) sqlQuery {
+	var b strings.Builder
+	b.WriteString(q.sql)
+	args := append([]any{}, q.args...)
+	for _, p := range parts {
+		b.WriteString(p.sql)
+		args = append(args, p.args...)
+	}
+	return sqlQuery{sql: b.String(), args: args}
+}
+
+// joinSQL joins parts with sep between them.
+func joinSQL(sep sqlText, parts []sqlQuery) sqlQuery {
+	var q sqlQuery
+	for i, p := range parts {
+		if i > 0 {
+			q = q.append(sqlf(sep))
+		}
+		q = q.append(p)
+	}
+	return q
+}
+
+// repeatSQL binds each row of args to its own copy of row, joined by sep,
+// e.g. the tuples of a multi-row INSERT.
+func repeatSQL(row, sep sqlText, args [][]any) sqlQuery {
+	parts := make([]sqlQuery, len(args))
+	for i, a := range args {
+		parts[i] = sqlf(row, a...)
+	}
+	return joinSQL(sep, parts)
+}
+
+func (q sqlQuery) empty() bool { return q.sql == "" }
+
+func (q sqlQuery) String() string { return q.sql }
+
+func (q sqlQuery) Args() []any { return q.args }
+
+// The statements below run a sqlQuery. Repositories with dynamic SQL use
+// them instead of passing strings to sqlx.
+
+func selectSQL(ctx context.Context, db sqlx.QueryerContext, dest any, q sqlQuery) error {
+	return sqlx.SelectContext(ctx, db, dest, q.sql, q.args...)
+}
+
+func getSQL(ctx context.Context, db sqlx.QueryerContext, dest any, q sqlQuery) error {
+	return sqlx.GetContext(ctx, db, dest, q.sql, q.args...)
+}
+
+func execSQL(ctx context.Context, db sqlx.ExecerContext, q sqlQuery) (sql.Result, error) {
+	return db.ExecContext(ctx, q.sql, q.args...)
+}
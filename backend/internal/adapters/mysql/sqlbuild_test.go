@@ -0,0 +1,217 @@
+package mysql
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+func TestSQLf(t *testing.T) {
+	base := sqlf("SELECT id FROM books WHERE id > ?", 1)
+	a := base.append(sqlf(" AND price < ?", 9.5))
+	b := base.append(sqlf(" AND isbn = ?", "x"))
+	if a.String() != "SELECT id FROM books WHERE id > ? AND price < ?" || !reflect.DeepEqual(a.Args(), []any{1, 9.5}) {
+		t.Fatalf("a = %q %v", a, a.Args())
+	}
+	if !reflect.DeepEqual(b.Args(), []any{1, "x"}) {
+		t.Fatalf("appends share arguments: b = %v", b.Args())
+	}
+
+	rows := repeatSQL("(?, ?)", ", ", [][]any{{1, "a"}, {2, "b"}})
+	if rows.String() != "(?, ?), (?, ?)" || !reflect.DeepEqual(rows.Args(), []any{1, "a", 2, "b"}) {
+		t.Fatalf("rows = %q %v", rows, rows.Args())
+	}
+	if !joinSQL(" AND ", nil).empty() {
+		t.Fatal("joining nothing isn't empty")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("sqlf accepted an argument without a placeholder")
+		}
+	}()
+	sqlf("SELECT id FROM books", 1)
+}
+
+// TestSQLTextOnlyFromConstants keeps request values out of SQL text: no
+// sqlText conversion of anything but a literal, and no query string put
+// together at run time outside the builder.
+func TestSQLTextOnlyFromConstants(t *testing.T) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", func(fi fs.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	consts := map[string]bool{}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				if gd, ok := decl.(*ast.GenDecl); ok && gd.Tok == token.CONST {
+					for _, spec := range gd.Specs {
+						for _, name := range spec.(*ast.ValueSpec).Names {
+							consts[name.Name] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	// constant reports whether e is made only of string literals and
+	// constants.
+	var constant func(e ast.Expr) bool
+	constant = func(e ast.Expr) bool {
+		switch e := e.(type) {
+		case *ast.BasicLit:
+			return true
+		case *ast.Ident:
+			return consts[e.Name]
+		case *ast.ParenExpr:
+			return constant(e.X)
+		case *ast.BinaryExpr:
+			return constant(e.X) && constant(e.Y)
+		}
+		return false
+	}
+	// queryArg is the position of the query in the sqlx and database/sql
+	// calls that take one.
+	queryArg := map[string]int{
+		"ExecContext": 1, "QueryContext": 1, "QueryRowContext": 1, "QueryxContext": 1, "QueryRowxContext": 1,
+		"SelectContext": 2, "GetContext": 2,
+	}
+	for _, pkg := range pkgs {
+		for name, file := range pkg.Files {
+			ast.Inspect(file, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				pos := fset.Position(call.Pos())
+				if id, ok := call.Fun.(*ast.Ident); ok && id.Name == "sqlText" {
+					if len(call.Args) != 1 || !constant(call.Args[0]) {
+						t.Errorf("%s: sqlText conversion of a value that isn't constant", pos)
+					}
+				}
+				sel, ok := call.Fun.(*ast.SelectorExpr)
+				if !ok || name == "sqlbuild.go" {
+					return true
+				}
+				if i, ok := queryArg[sel.Sel.Name]; ok && i < len(call.Args) {
+					if bin, ok := call.Args[i].(*ast.BinaryExpr); ok && !constant(bin) {
+						t.Errorf("%s: %s query built by concatenation; use sqlf", pos, sel.Sel.Name)
+					}
+				}
+				return true
+			})
+		}
+	}
+}
+
+// hostileInputs seed FuzzBookQueries with classic injection attempts and
+// characters special to SQL and LIKE.
+var hostileInputs = []string{
+	"", " ", "x", "'", `"`, "`", `\`, "%", "_", "?", "??", ";", "--", "/*", "*/", "#",
+	"' OR '1'='1", "' OR 1=1 -- ", `" OR ""="`, "'; DROP TABLE books; --",
+	"1; DELETE FROM books", "title; DROP TABLE books", "id DESC, (SELECT SLEEP(5))",
+	"title COLLATE utf8mb4_bin", "de' --", `\' OR 1=1 #`, "%' AND '%'='", "_%_",
+	"\x00", "\n\t", "é", "0x27", "UNION SELECT * FROM users", "?) OR (1=1",
+	"title", "-title", "id", "author", "de", "sv", "utf8mb4_0900_ai_ci",
+}
+
+// FuzzBookQueries feeds hostile filters and sorts through every query the
+// book repository builds and checks that the input only ever reaches the
+// arguments: the SQL text must equal the text built for harmless input of
+// the same shape, and every placeholder must have its argument.
+func FuzzBookQueries(f *testing.F) {
+	for i, s := range hostileInputs {
+		t := hostileInputs[(i+1)%len(hostileInputs)]
+		f.Add(s, t, s, t, s, i%2 == 0, i%3 == 0)
+	}
+	f.Fuzz(func(t *testing.T, q, author, isbn, sortField, collation string, exact, desc bool) {
+		year, price := 1967, 9.99
+		hostile := ports.ListFilter{Q: q, Synonyms: []string{isbn}, Author: author, ISBN: isbn, Exact: exact, YearFrom: &year, PriceMax: &price}
+		harmless := hostile
+		harmless.Q, harmless.Author, harmless.ISBN = sameShape(q), sameShape(author), sameShape(isbn)
+		harmless.Synonyms = []string{sameShape(isbn)}
+		hostileSort := ports.Sort{Field: sortField, Desc: desc, Collation: collation}
+		harmlessSort := hostileSort
+		if !slices.Contains(ports.SortFields, sortField) {
+			harmlessSort.Field = ""
+		}
+		if _, ok := mysqlCollations[collation]; !ok {
+			harmlessSort.Collation = ""
+		}
+
+		for _, r := range []*bookRepository{{}, {repoOptions: repoOptions{priceCents: NewDualWrite("price_cents", PhaseReadNew)}}} {
+			hostileCount, hostileList := r.pageQueries(hostile, ports.Page{Limit: 10, Offset: 5, Sort: hostileSort})
+			harmlessCount, harmlessList := r.pageQueries(harmless, ports.Page{Limit: 10, Offset: 5, Sort: harmlessSort})
+			ranking := &ports.Ranking{RankingWeights: domain.DefaultRankingWeights(), Year: 2024}
+			_, hostileRanked := r.pageQueries(hostile, ports.Page{Limit: 10, Sort: hostileSort, Ranking: ranking})
+			_, harmlessRanked := r.pageQueries(harmless, ports.Page{Limit: 10, Sort: harmlessSort, Ranking: ranking})
+			pairs := [][2]sqlQuery{
+				{r.listQuery(hostile), r.listQuery(harmless)},
+				{hostileCount, harmlessCount},
+				{hostileList, harmlessList},
+				{hostileRanked, harmlessRanked},
+			}
+			if strings.TrimSpace(q) != "" {
+				pairs = append(pairs, [2]sqlQuery{r.searchQuery(q), r.searchQuery(sameShape(q))})
+			}
+			for _, p := range pairs {
+				got, want := p[0], p[1]
+				if got.String() != want.String() {
+					t.Fatalf("input changed the SQL:\n%s\nwant\n%s", got, want)
+				}
+				if n := strings.Count(got.String(), "?"); n != len(got.Args()) {
+					t.Fatalf("%d placeholders, %d arguments: %s", n, len(got.Args()), got)
+				}
+			}
+		}
+		for _, cond := range []sqlQuery{searchCond(q, isbn), exactSearchCond(q)} {
+			for _, arg := range cond.Args() {
+				if !likeLiteral(arg.(string)) {
+					t.Fatalf("LIKE pattern %q lets %q match wildcards", arg, q)
+				}
+			}
+		}
+	})
+}
+
+// sameShape replaces a filter value with a harmless one that filters the
+// same way: blank stays blank.
+func sameShape(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return s
+	}
+	return "x"
+}
+
+// likeLiteral reports whether pattern is %text% with every wildcard and
+// backslash inside text escaped.
+func likeLiteral(pattern string) bool {
+	if len(pattern) < 2 || pattern[0] != '%' || pattern[len(pattern)-1] != '%' {
+		return false
+	}
+	inner := pattern[1 : len(pattern)-1]
+	for i := 0; i < len(inner); i++ {
+		switch inner[i] {
+		case '\\':
+			if i+1 == len(inner) || !strings.ContainsRune(`\%_`, rune(inner[i+1])) {
+				return false
+			}
+			i++
+		case '%', '_':
+			return false
+		}
+	}
+	return true
+}
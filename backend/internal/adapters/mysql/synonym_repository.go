@@ -0,0 +1,165 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+)
+
+type synonymRepository struct {
+	db *sqlx.DB
+}
+
+func NewSynonymRepository(db *sqlx.DB) ports.SynonymRepository {
+	return &synonymRepository{db: db}
+}
+
+func (r *synonymRepository) List(ctx context.Context) ([]domain.SynonymGroup, error) {
+	groups := []domain.SynonymGroup{}
+	if err := r.db.SelectContext(ctx, &groups, `
+		SELECT id, created_at, updated_at FROM synonym_groups ORDER BY id`); err != nil {
+		logger.From(ctx).Error("failed to list synonym groups", "error", err)
+		return nil, err
+	}
+	return groups, attachSynonymTerms(ctx, r.db, groups)
+}
+
+func (r *synonymRepository) GetByID(ctx context.Context, id int64) (*domain.SynonymGroup, error) {
+	var g domain.SynonymGroup
+	err := r.db.GetContext(ctx, &g, `
+		SELECT id, created_at, updated_at FROM synonym_groups WHERE id = ?`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to get synonym group", "id", id, "error", err)
+		return nil, err
+	}
+	groups := []domain.SynonymGroup{g}
+	if err := attachSynonymTerms(ctx, r.db, groups); err != nil {
+		return nil, err
+	}
+	return &groups[0], nil
+}
+
+func (r *synonymRepository) Create(ctx context.Context, g *domain.SynonymGroup) (int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }() // no-op after Commit; drops the group when a term is taken
+
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO synonym_groups (created_at, updated_at) VALUES (?, ?)`, g.CreatedAt, g.UpdatedAt)
+	if err != nil {
+		logger.From(ctx).Error("failed to create synonym group", "error", err)
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	if err := insertSynonymTerms(ctx, tx, id, g.Terms); err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+func (r *synonymRepository) Update(ctx context.Context, g *domain.SynonymGroup) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback() }() // no-op after Commit
+
+	var id int64
+	err = tx.GetContext(ctx, &id, `SELECT id FROM synonym_groups WHERE id = ? FOR UPDATE`, g.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to lock synonym group", "id", g.ID, "error", err)
+		return false, err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE synonym_groups SET updated_at = ? WHERE id = ?`, g.UpdatedAt, g.ID); err != nil {
+		logger.From(ctx).Error("failed to update synonym group", "id", g.ID, "error", err)
+		return false, err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM synonym_terms WHERE group_id = ?`, g.ID); err != nil {
+		logger.From(ctx).Error("failed to clear synonym terms", "id", g.ID, "error", err)
+		return false, err
+	}
+	if err := insertSynonymTerms(ctx, tx, g.ID, g.Terms); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+func (r *synonymRepository) Delete(ctx context.Context, id int64) (bool, error) {
+	// the terms go with the group, ON DELETE CASCADE
+	res, err := r.db.ExecContext(ctx, `DELETE FROM synonym_groups WHERE id = ?`, id)
+	if err != nil {
+		logger.From(ctx).Error("failed to delete synonym group", "id", id, "error", err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// insertSynonymTerms stores terms as the terms of group id, in order. A term
+// another group has fails with ports.ErrSynonymTaken.
+func insertSynonymTerms(ctx context.Context, tx *sqlx.Tx, id int64, terms []string) error {
+	rows := make([][]any, len(terms))
+	for i, t := range terms {
+		rows[i] = []any{id, i, t, domain.SearchKey(t)}
+	}
+	_, err := execSQL(ctx, tx, sqlf(`
+		INSERT INTO synonym_terms (group_id, position, term, term_key)
+		VALUES `).append(repeatSQL("(?, ?, ?, ?)", ", ", rows)))
+	var myErr *mysqldriver.MySQLError
+	if errors.As(err, &myErr) && myErr.Number == errDuplicateKey {
+		return ports.ErrSynonymTaken
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to save synonym terms", "id", id, "error", err)
+	}
+	return err
+}
+
+// attachSynonymTerms fills in the terms of groups, in order.
+func attachSynonymTerms(ctx context.Context, db *sqlx.DB, groups []domain.SynonymGroup) error {
+	if len(groups) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(groups))
+	byID := make(map[int64]*domain.SynonymGroup, len(groups))
+	for i := range groups {
+		groups[i].Terms = []string{}
+		ids[i] = groups[i].ID
+		byID[groups[i].ID] = &groups[i]
+	}
+	var rows []struct {
+		GroupID int64  `db:"group_id"`
+		Term    string `db:"term"`
+	}
+	if err := selectSQL(ctx, db, &rows, sqlf(`
+		SELECT group_id, term FROM synonym_terms
+		WHERE group_id IN (`).append(inList(ids), sqlf(`)
+		ORDER BY group_id, position`))); err != nil {
+		logger.From(ctx).Error("failed to load synonym terms", "error", err)
+		return err
+	}
+	for _, row := range rows {
+		if g := byID[row.GroupID]; g != nil {
+			g.Terms = append(g.Terms, row.Term)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	mysqldriver "github.com/go-sql-driver/mysql"
+)
+
+func TestSynonymCreate(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO synonym_groups \\(created_at, updated_at\\) VALUES \\(\\?, \\?\\)").
+		WithArgs(at, at).
+		WillReturnResult(sqlmock.NewResult(4, 1))
+	mock.ExpectExec("INSERT INTO synonym_terms \\(group_id, position, term, term_key\\) VALUES \\(\\?, \\?, \\?, \\?\\), \\(\\?, \\?, \\?, \\?\\)").
+		WithArgs(int64(4), 0, "C#", "c#", int64(4), 1, "CSharp", "csharp").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	g := &domain.SynonymGroup{Terms: []string{"C#", "CSharp"}, CreatedAt: at, UpdatedAt: at}
+	if id, err := NewSynonymRepository(db).Create(context.Background(), g); err != nil || id != 4 {
+		t.Fatalf("Create = %d, %v", id, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSynonymCreate_TermTaken(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO synonym_groups").
+		WillReturnResult(sqlmock.NewResult(4, 1))
+	mock.ExpectExec("INSERT INTO synonym_terms").
+		WillReturnError(&mysqldriver.MySQLError{Number: 1062, Message: "Duplicate entry"})
+	mock.ExpectRollback()
+
+	_, err := NewSynonymRepository(db).Create(context.Background(), &domain.SynonymGroup{Terms: []string{"coding", "programming"}})
+	if !errors.Is(err, ports.ErrSynonymTaken) {
+		t.Fatalf("err = %v; want ErrSynonymTaken", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSynonymUpdate(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+	repo := NewSynonymRepository(db)
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM synonym_groups WHERE id = \\? FOR UPDATE").
+		WithArgs(int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+	if found, err := repo.Update(context.Background(), &domain.SynonymGroup{ID: 9, Terms: []string{"a", "b"}}); found || err != nil {
+		t.Fatalf("Update(missing) = %v, %v", found, err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM synonym_groups WHERE id = \\? FOR UPDATE").
+		WithArgs(int64(4)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(4)))
+	mock.ExpectExec("UPDATE synonym_groups SET updated_at = \\? WHERE id = \\?").
+		WithArgs(at, int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM synonym_terms WHERE group_id = \\?").
+		WithArgs(int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO synonym_terms").
+		WithArgs(int64(4), 0, "ML", "ml", int64(4), 1, "Machine Learning", "machine learning").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	g := &domain.SynonymGroup{ID: 4, Terms: []string{"ML", "Machine Learning"}, UpdatedAt: at}
+	if found, err := repo.Update(context.Background(), g); !found || err != nil {
+		t.Fatalf("Update = %v, %v", found, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSynonymList(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT id, created_at, updated_at FROM synonym_groups ORDER BY id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
+			AddRow(int64(1), at, at).AddRow(int64(2), at, at))
+	mock.ExpectQuery("SELECT group_id, term FROM synonym_terms WHERE group_id IN \\(\\?, \\?\\) ORDER BY group_id, position").
+		WithArgs(int64(1), int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"group_id", "term"}).
+			AddRow(int64(1), "programming").AddRow(int64(1), "coding").
+			AddRow(int64(2), "C#").AddRow(int64(2), "csharp"))
+
+	groups, err := NewSynonymRepository(db).List(context.Background())
+	if err != nil || len(groups) != 2 ||
+		!slices.Equal(groups[0].Terms, []string{"programming", "coding"}) || !slices.Equal(groups[1].Terms, []string{"C#", "csharp"}) {
+		t.Fatalf("List = %+v, %v", groups, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
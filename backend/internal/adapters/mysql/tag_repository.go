@@ -0,0 +1,117 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+)
+
+type tagRepository struct {
+	db *sqlx.DB
+}
+
+func NewTagRepository(db *sqlx.DB) ports.TagRepository {
+	return &tagRepository{db: db}
+}
+
+func (r *tagRepository) Tag(ctx context.Context, bookID int64, name, key string, at time.Time) (found, added bool, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, false, err
+	}
+	defer func() { _ = tx.Rollback() }() // no-op after Commit; drops a tag created for a missing book
+
+	// LAST_INSERT_ID(id) makes an existing tag report its id as inserted
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO tags (name, tag_key, created_at) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id)`, name, key, at)
+	if err != nil {
+		logger.From(ctx).Error("failed to save tag", "tag", key, "error", err)
+		return false, false, err
+	}
+	tagID, err := res.LastInsertId()
+	if err != nil {
+		return false, false, err
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO book_tags (book_id, tag_id, created_at) VALUES (?, ?, ?)`, bookID, tagID, at)
+	var myErr *mysqldriver.MySQLError
+	switch {
+	case errors.As(err, &myErr) && myErr.Number == errNoReferencedRow:
+		return false, false, nil
+	case errors.As(err, &myErr) && myErr.Number == errDuplicateKey:
+		return true, false, nil
+	case err != nil:
+		logger.From(ctx).Error("failed to tag book", "id", bookID, "tag", key, "error", err)
+		return false, false, err
+	}
+	return true, true, tx.Commit()
+}
+
+func (r *tagRepository) Untag(ctx context.Context, bookID int64, key string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE bt FROM book_tags bt JOIN tags t ON t.id = bt.tag_id
+		WHERE bt.book_id = ? AND t.tag_key = ?`, bookID, key)
+	if err != nil {
+		logger.From(ctx).Error("failed to untag book", "id", bookID, "tag", key, "error", err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *tagRepository) List(ctx context.Context, prefix string, limit int) ([]domain.Tag, error) {
+	where := sqlQuery{}
+	if prefix != "" {
+		where = sqlf(`
+		WHERE t.tag_key LIKE ?`, escapeLike(prefix)+"%")
+	}
+	tags := []domain.Tag{}
+	if err := selectSQL(ctx, r.db, &tags, sqlf(`
+		SELECT t.name, COUNT(*) AS books
+		FROM tags t JOIN book_tags bt ON bt.tag_id = t.id`).append(where, sqlf(`
+		GROUP BY t.id, t.name, t.tag_key
+		ORDER BY books DESC, t.tag_key
+		LIMIT ?`, limit))); err != nil {
+		logger.From(ctx).Error("failed to list tags", "error", err)
+		return nil, err
+	}
+	return tags, nil
+}
+
+// attachTags fills in the tags of books, sorted.
+func attachTags(ctx context.Context, db *sqlx.DB, books []domain.Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(books))
+	byID := make(map[int64]*domain.Book, len(books))
+	for i := range books {
+		books[i].Tags = []string{}
+		ids[i] = books[i].ID
+		byID[books[i].ID] = &books[i]
+	}
+	var rows []struct {
+		BookID int64  `db:"book_id"`
+		Name   string `db:"name"`
+	}
+	if err := selectSQL(ctx, db, &rows, sqlf(`
+		SELECT bt.book_id, t.name FROM book_tags bt JOIN tags t ON t.id = bt.tag_id
+		WHERE bt.book_id IN (`).append(inList(ids), sqlf(`)
+		ORDER BY t.tag_key`))); err != nil {
+		logger.From(ctx).Error("failed to load book tags", "error", err)
+		return err
+	}
+	for _, row := range rows {
+		if b := byID[row.BookID]; b != nil {
+			b.Tags = append(b.Tags, row.Name)
+		}
+	}
+	return nil
+}
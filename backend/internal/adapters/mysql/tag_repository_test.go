@@ -0,0 +1,86 @@
+package mysql
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	mysqldriver "github.com/go-sql-driver/mysql"
+)
+
+func TestTag(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name         string
+		insertErr    error
+		found, added bool
+	}{
+		{"new", nil, true, true},
+		{"already tagged", &mysqldriver.MySQLError{Number: errDuplicateKey}, true, false},
+		{"no book", &mysqldriver.MySQLError{Number: errNoReferencedRow}, false, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, cleanup := newMockSQLX(t)
+			defer cleanup()
+
+			mock.ExpectBegin()
+			mock.ExpectExec("INSERT INTO tags \\(name, tag_key, created_at\\) VALUES \\(\\?, \\?, \\?\\) ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID\\(id\\)").
+				WithArgs("Cozy Mystery", "cozy mystery", at).
+				WillReturnResult(sqlmock.NewResult(7, 1))
+			insert := mock.ExpectExec("INSERT INTO book_tags \\(book_id, tag_id, created_at\\) VALUES \\(\\?, \\?, \\?\\)").
+				WithArgs(int64(3), int64(7), at)
+			if tc.insertErr != nil {
+				insert.WillReturnError(tc.insertErr)
+				mock.ExpectRollback()
+			} else {
+				insert.WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			}
+
+			found, added, err := NewTagRepository(db).Tag(context.Background(), 3, "Cozy Mystery", "cozy mystery", at)
+			if err != nil || found != tc.found || added != tc.added {
+				t.Fatalf("Tag = %v, %v, %v; want %v, %v", found, added, err, tc.found, tc.added)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestUntagAndList(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+	repo := NewTagRepository(db)
+
+	mock.ExpectExec("DELETE bt FROM book_tags bt JOIN tags t ON t.id = bt.tag_id WHERE bt.book_id = \\? AND t.tag_key = \\?").
+		WithArgs(int64(3), "cozy mystery").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	if removed, err := repo.Untag(context.Background(), 3, "cozy mystery"); removed || err != nil {
+		t.Fatalf("Untag = %v, %v; want false", removed, err)
+	}
+
+	mock.ExpectQuery("SELECT t.name, COUNT\\(\\*\\) AS books FROM tags t JOIN book_tags bt ON bt.tag_id = t.id WHERE t.tag_key LIKE \\? "+
+		"GROUP BY t.id, t.name, t.tag_key ORDER BY books DESC, t.tag_key LIMIT \\?").
+		WithArgs("100\\%%", 10).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "books"}).AddRow("100% Recycled", 2))
+	tags, err := repo.List(context.Background(), "100%", 10)
+	if err != nil || len(tags) != 1 || tags[0].Books != 2 {
+		t.Fatalf("List = %+v, %v", tags, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestListWhere_Tag(t *testing.T) {
+	q := listWhere(ports.ListFilter{Tag: " Cozy  Mystery "})
+	if where := q.String(); !strings.Contains(where, "id IN (SELECT bt.book_id FROM book_tags bt JOIN tags t ON t.id = bt.tag_id WHERE t.tag_key = ?)") ||
+		!reflect.DeepEqual(q.Args(), []any{"cozy mystery"}) {
+		t.Fatalf("listWhere = %q %v", where, q.Args())
+	}
+}
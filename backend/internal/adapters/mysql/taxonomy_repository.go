@@ -0,0 +1,94 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type taxonomyRepository struct {
+	db *sqlx.DB
+}
+
+func NewTaxonomyRepository(db *sqlx.DB) ports.TaxonomyRepository {
+	return &taxonomyRepository{db: db}
+}
+
+func (r *taxonomyRepository) Taxonomy(ctx context.Context) (*domain.Taxonomy, error) {
+	t := &domain.Taxonomy{Categories: []domain.Category{}, Authors: []domain.Author{}}
+	if err := r.db.SelectContext(ctx, &t.Categories, `
+		SELECT slug, name, parent FROM taxonomy_categories ORDER BY slug`); err != nil {
+		logger.From(ctx).Error("failed to list taxonomy categories", "error", err)
+		return nil, err
+	}
+	if err := r.db.SelectContext(ctx, &t.Authors, `
+		SELECT id, name FROM taxonomy_authors ORDER BY id`); err != nil {
+		logger.From(ctx).Error("failed to list taxonomy authors", "error", err)
+		return nil, err
+	}
+	return t, nil
+}
+
+func (r *taxonomyRepository) Author(ctx context.Context, id string) (*domain.Author, error) {
+	var a domain.Author
+	err := r.db.GetContext(ctx, &a, `SELECT id, name FROM taxonomy_authors WHERE id = ?`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to get taxonomy author", "author", id, "error", err)
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *taxonomyRepository) Apply(ctx context.Context, c ports.TaxonomyChanges, at time.Time) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }() // no-op after Commit
+
+	var writes []sqlQuery
+	if len(c.DeleteCategories) > 0 {
+		writes = append(writes, sqlf(`
+			DELETE FROM taxonomy_categories WHERE slug IN (`).append(inList(c.DeleteCategories), sqlf(`)`)))
+	}
+	if len(c.DeleteAuthors) > 0 {
+		writes = append(writes, sqlf(`
+			DELETE FROM taxonomy_authors WHERE id IN (`).append(inList(c.DeleteAuthors), sqlf(`)`)))
+	}
+	if len(c.PutCategories) > 0 {
+		rows := make([][]any, len(c.PutCategories))
+		for i, cat := range c.PutCategories {
+			rows[i] = []any{cat.Slug, cat.Name, cat.Parent, at}
+		}
+		writes = append(writes, sqlf(`
+			INSERT INTO taxonomy_categories (slug, name, parent, updated_at)
+			VALUES `).append(repeatSQL("(?, ?, ?, ?)", ", ", rows), sqlf(`
+			ON DUPLICATE KEY UPDATE name = VALUES(name), parent = VALUES(parent), updated_at = VALUES(updated_at)`)))
+	}
+	if len(c.PutAuthors) > 0 {
+		rows := make([][]any, len(c.PutAuthors))
+		for i, a := range c.PutAuthors {
+			rows[i] = []any{a.ID, a.Name, at}
+		}
+		writes = append(writes, sqlf(`
+			INSERT INTO taxonomy_authors (id, name, updated_at)
+			VALUES `).append(repeatSQL("(?, ?, ?)", ", ", rows), sqlf(`
+			ON DUPLICATE KEY UPDATE name = VALUES(name), updated_at = VALUES(updated_at)`)))
+	}
+	for _, w := range writes {
+		if _, err := execSQL(ctx, tx, w); err != nil {
+			logger.From(ctx).Error("failed to import taxonomy", "error", err)
+			return err
+		}
+	}
+	return tx.Commit()
+}
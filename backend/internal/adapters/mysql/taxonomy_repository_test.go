@@ -0,0 +1,60 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+func TestTaxonomyApply(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM taxonomy_categories WHERE slug IN \\(\\?, \\?\\)").
+		WithArgs("poetry", "drama").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO taxonomy_categories \\(slug, name, parent, updated_at\\) VALUES \\(\\?, \\?, \\?, \\?\\), \\(\\?, \\?, \\?, \\?\\) "+
+		"ON DUPLICATE KEY UPDATE name = VALUES\\(name\\), parent = VALUES\\(parent\\), updated_at = VALUES\\(updated_at\\)").
+		WithArgs("fiction", "Fiction", "", at, "fantasy", "Fantasy", "fiction", at).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO taxonomy_authors \\(id, name, updated_at\\) VALUES \\(\\?, \\?, \\?\\) ON DUPLICATE KEY UPDATE").
+		WithArgs("ursula-k-le-guin", "Ursula K. Le Guin", at).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := NewTaxonomyRepository(db).Apply(context.Background(), ports.TaxonomyChanges{
+		PutCategories:    []domain.Category{{Slug: "fiction", Name: "Fiction"}, {Slug: "fantasy", Name: "Fantasy", Parent: "fiction"}},
+		DeleteCategories: []string{"poetry", "drama"},
+		PutAuthors:       []domain.Author{{ID: "ursula-k-le-guin", Name: "Ursula K. Le Guin"}},
+	}, at)
+	if err != nil {
+		t.Fatalf("Apply error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestTaxonomy_Sorted(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT slug, name, parent FROM taxonomy_categories ORDER BY slug").
+		WillReturnRows(sqlmock.NewRows([]string{"slug", "name", "parent"}).AddRow("fantasy", "Fantasy", "fiction").AddRow("fiction", "Fiction", ""))
+	mock.ExpectQuery("SELECT id, name FROM taxonomy_authors ORDER BY id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+
+	tx, err := NewTaxonomyRepository(db).Taxonomy(context.Background())
+	if err != nil {
+		t.Fatalf("Taxonomy error: %v", err)
+	}
+	if len(tx.Categories) != 2 || tx.Categories[0].Parent != "fiction" || tx.Authors == nil {
+		t.Fatalf("taxonomy = %+v", tx)
+	}
+}
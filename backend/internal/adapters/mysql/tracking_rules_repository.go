@@ -0,0 +1,74 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type trackingRulesRepository struct {
+	db *sqlx.DB
+}
+
+func NewTrackingRulesRepository(db *sqlx.DB) ports.TrackingRulesRepository {
+	return &trackingRulesRepository{db: db}
+}
+
+// trackingRuleLists is the JSON kept in tracking_rules.rules.
+type trackingRuleLists struct {
+	Strip []string                   `json:"strip"`
+	Keep  []string                   `json:"keep"`
+	Hosts []domain.HostTrackingRules `json:"hosts"`
+}
+
+func (r *trackingRulesRepository) Get(ctx context.Context, tenant string) (*domain.TrackingRules, error) {
+	var row struct {
+		Rules     []byte    `db:"rules"`
+		UpdatedAt time.Time `db:"updated_at"`
+	}
+	err := r.db.GetContext(ctx, &row, `SELECT rules, updated_at FROM tracking_rules WHERE tenant = ?`, tenant)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to get tracking rules", "tenant", tenant, "error", err)
+		return nil, err
+	}
+	var lists trackingRuleLists
+	if err := json.Unmarshal(row.Rules, &lists); err != nil {
+		return nil, err
+	}
+	return &domain.TrackingRules{Tenant: tenant, Strip: lists.Strip, Keep: lists.Keep, Hosts: lists.Hosts, UpdatedAt: row.UpdatedAt}, nil
+}
+
+func (r *trackingRulesRepository) Put(ctx context.Context, t *domain.TrackingRules) error {
+	rules, err := json.Marshal(trackingRuleLists{Strip: t.Strip, Keep: t.Keep, Hosts: t.Hosts})
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO tracking_rules (tenant, rules, updated_at) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE rules = VALUES(rules), updated_at = VALUES(updated_at)`,
+		t.Tenant, rules, t.UpdatedAt)
+	if err != nil {
+		logger.From(ctx).Error("failed to save tracking rules", "tenant", t.Tenant, "error", err)
+	}
+	return err
+}
+
+func (r *trackingRulesRepository) Delete(ctx context.Context, tenant string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM tracking_rules WHERE tenant = ?`, tenant)
+	if err != nil {
+		logger.From(ctx).Error("failed to delete tracking rules", "tenant", tenant, "error", err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
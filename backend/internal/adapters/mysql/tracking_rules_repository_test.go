@@ -0,0 +1,57 @@
+package mysql
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+func TestTrackingRulesRoundTrip(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	now := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	stored := `{"strip":["ref","src_*"],"keep":["utm_id"],"hosts":[{"host":"shop.example.com","strip":["sid"],"keep":null}]}`
+	mock.ExpectExec("INSERT INTO tracking_rules .* ON DUPLICATE KEY UPDATE").
+		WithArgs("key:feed", []byte(stored), now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT rules, updated_at FROM tracking_rules WHERE tenant = \\?").
+		WithArgs("key:feed").
+		WillReturnRows(sqlmock.NewRows([]string{"rules", "updated_at"}).AddRow(stored, now))
+	mock.ExpectQuery("SELECT rules, updated_at FROM tracking_rules WHERE tenant = \\?").
+		WithArgs("alice").
+		WillReturnRows(sqlmock.NewRows([]string{"rules", "updated_at"}))
+	mock.ExpectExec("DELETE FROM tracking_rules WHERE tenant = \\?").
+		WithArgs("key:feed").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	r := NewTrackingRulesRepository(db)
+	ctx := context.Background()
+	in := &domain.TrackingRules{Tenant: "key:feed", Strip: []string{"ref", "src_*"}, Keep: []string{"utm_id"},
+		Hosts: []domain.HostTrackingRules{{Host: "shop.example.com", Strip: []string{"sid"}}}, UpdatedAt: now}
+	if err := r.Put(ctx, in); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	got, err := r.Get(ctx, "key:feed")
+	if err != nil || got == nil {
+		t.Fatalf("Get = %v, %v", got, err)
+	}
+	if got.Tenant != "key:feed" || !slices.Equal(got.Strip, in.Strip) || !slices.Equal(got.Keep, in.Keep) ||
+		len(got.Hosts) != 1 || got.Hosts[0].Host != "shop.example.com" || !got.UpdatedAt.Equal(now) {
+		t.Fatalf("Get = %+v", got)
+	}
+	if got, err := r.Get(ctx, "alice"); err != nil || got != nil {
+		t.Fatalf("Get(alice) = %v, %v; want nil, nil", got, err)
+	}
+	if found, err := r.Delete(ctx, "key:feed"); err != nil || !found {
+		t.Fatalf("Delete = %v, %v", found, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type urlHistoryRepository struct {
+	db *sqlx.DB
+}
+
+func NewURLHistoryRepository(db *sqlx.DB) ports.URLHistoryRepository {
+	return &urlHistoryRepository{db: db}
+}
+
+func (r *urlHistoryRepository) Add(ctx context.Context, e *domain.URLHistoryEntry, keep int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM url_history WHERE owner = ? AND url = ? AND operation = ?`,
+		e.Owner, e.URL, e.Operation); err != nil {
+		logger.From(ctx).Error("failed to replace url history entry", "owner", e.Owner, "error", err)
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO url_history (owner, url, processed_url, operation, created_at) VALUES (?, ?, ?, ?, ?)`,
+		e.Owner, e.URL, e.ProcessedURL, e.Operation, e.CreatedAt)
+	if err != nil {
+		logger.From(ctx).Error("failed to add url history entry", "owner", e.Owner, "error", err)
+		return err
+	}
+	if e.ID, err = res.LastInsertId(); err != nil {
+		return err
+	}
+	// the derived table lets MySQL read the table it deletes from
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM url_history WHERE owner = ? AND id <= (
+		  SELECT id FROM (
+		    SELECT id FROM url_history WHERE owner = ? ORDER BY id DESC LIMIT 1 OFFSET ?
+		  ) oldest
+		)`, e.Owner, e.Owner, keep); err != nil {
+		logger.From(ctx).Error("failed to trim url history", "owner", e.Owner, "error", err)
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *urlHistoryRepository) List(ctx context.Context, owner string, limit int) ([]domain.URLHistoryEntry, error) {
+	out := []domain.URLHistoryEntry{}
+	err := r.db.SelectContext(ctx, &out, `
+		SELECT id, owner, url, processed_url, operation, created_at FROM url_history
+		WHERE owner = ? ORDER BY id DESC LIMIT ?`, owner, limit)
+	if err != nil {
+		logger.From(ctx).Error("failed to list url history", "owner", owner, "error", err)
+	}
+	return out, err
+}
+
+func (r *urlHistoryRepository) Delete(ctx context.Context, owner string, id int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM url_history WHERE owner = ? AND id = ?`, owner, id)
+	if err != nil {
+		logger.From(ctx).Error("failed to delete url history entry", "owner", owner, "id", id, "error", err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *urlHistoryRepository) Clear(ctx context.Context, owner string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM url_history WHERE owner = ?`, owner)
+	if err != nil {
+		logger.From(ctx).Error("failed to clear url history", "owner", owner, "error", err)
+	}
+	return err
+}
@@ -0,0 +1,71 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+func TestURLHistoryAdd(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	now := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM url_history WHERE owner = \\? AND url = \\? AND operation = \\?").
+		WithArgs("alice", "https://example.com/?a=1", "all").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO url_history").
+		WithArgs("alice", "https://example.com/?a=1", "https://www.example.com?a=1", "all", now).
+		WillReturnResult(sqlmock.NewResult(9, 1))
+	mock.ExpectExec("DELETE FROM url_history WHERE owner = \\? AND id <= .* LIMIT 1 OFFSET \\?").
+		WithArgs("alice", "alice", 100).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	r := NewURLHistoryRepository(db)
+	e := &domain.URLHistoryEntry{Owner: "alice", URL: "https://example.com/?a=1", ProcessedURL: "https://www.example.com?a=1", Operation: "all", CreatedAt: now}
+	if err := r.Add(context.Background(), e, 100); err != nil || e.ID != 9 {
+		t.Fatalf("Add = %v (id %d)", err, e.ID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestURLHistoryListAndDelete(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT id, owner, url, processed_url, operation, created_at FROM url_history").
+		WithArgs("alice", 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "owner", "url", "processed_url", "operation", "created_at"}).
+			AddRow(9, "alice", "https://example.com/", "https://www.example.com", "all", now))
+	mock.ExpectExec("DELETE FROM url_history WHERE owner = \\? AND id = \\?").
+		WithArgs("bob", int64(9)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM url_history WHERE owner = \\?").
+		WithArgs("alice").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	r := NewURLHistoryRepository(db)
+	ctx := context.Background()
+	entries, err := r.List(ctx, "alice", 20)
+	if err != nil || len(entries) != 1 || entries[0].ProcessedURL != "https://www.example.com" {
+		t.Fatalf("List = %+v, %v", entries, err)
+	}
+	if found, err := r.Delete(ctx, "bob", 9); err != nil || found {
+		t.Fatalf("Delete of another owner's entry = %v, %v", found, err)
+	}
+	if err := r.Clear(ctx, "alice"); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
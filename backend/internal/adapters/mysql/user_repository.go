@@ -0,0 +1,125 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+// errDuplicateKey is MySQL's ER_DUP_ENTRY.
+const errDuplicateKey = 1062
+
+type userRepository struct {
+	db *sqlx.DB
+}
+
+func NewUserRepository(db *sqlx.DB) ports.UserRepository {
+	return &userRepository{db: db}
+}
+
+// userColumns are the columns of domain.User.
+const userColumns sqlText = "id, email, password_hash, name, scopes, created_at, updated_at"
+
+func (r *userRepository) Create(ctx context.Context, u *domain.User) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO users (email, password_hash, name, scopes, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		u.Email, u.PasswordHash, u.Name, u.Scopes, u.CreatedAt, u.UpdatedAt)
+	var myErr *mysqldriver.MySQLError
+	if errors.As(err, &myErr) && myErr.Number == errDuplicateKey {
+		return 0, ports.ErrEmailTaken
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to create user", "error", err)
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	var u domain.User
+	err := getSQL(ctx, r.db, &u, sqlf(`
+		SELECT `+userColumns+` FROM users WHERE email = ?`, email))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to look up user", "error", err)
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *userRepository) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	var u domain.User
+	err := getSQL(ctx, r.db, &u, sqlf(`
+		SELECT `+userColumns+` FROM users WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to get user", "id", id, "error", err)
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *userRepository) List(ctx context.Context) ([]domain.User, error) {
+	users := []domain.User{}
+	err := selectSQL(ctx, r.db, &users, sqlf(`
+		SELECT `+userColumns+` FROM users ORDER BY id`))
+	if err != nil {
+		logger.From(ctx).Error("failed to list users", "error", err)
+	}
+	return users, err
+}
+
+func (r *userRepository) ListPage(ctx context.Context, limit, offset int) ([]domain.User, int, error) {
+	var total int
+	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM users`); err != nil {
+		logger.From(ctx).Error("failed to count users", "error", err)
+		return nil, 0, err
+	}
+	users := []domain.User{}
+	if err := selectSQL(ctx, r.db, &users, sqlf(`
+		SELECT `+userColumns+` FROM users ORDER BY id LIMIT ? OFFSET ?`, limit, offset)); err != nil {
+		logger.From(ctx).Error("failed to list users page", "limit", limit, "offset", offset, "error", err)
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+func (r *userRepository) Update(ctx context.Context, u *domain.User) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE users SET name = ?, scopes = ?, password_hash = ?, updated_at = ? WHERE id = ?`,
+		u.Name, u.Scopes, u.PasswordHash, u.UpdatedAt, u.ID)
+	if err != nil {
+		logger.From(ctx).Error("failed to update user", "id", u.ID, "error", err)
+		return false, err
+	}
+	// MySQL counts changed rows, so a user saved unchanged reports none
+	// either; look for it
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return n > 0, err
+	}
+	var exists bool
+	err = r.db.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM users WHERE id = ?)`, u.ID)
+	return exists, err
+}
+
+func (r *userRepository) Delete(ctx context.Context, id int64) (bool, error) {
+	// loans and holds go with the user, ON DELETE CASCADE
+	res, err := r.db.ExecContext(ctx, `DELETE FROM users WHERE id = ?`, id)
+	if err != nil {
+		logger.From(ctx).Error("failed to delete user", "id", id, "error", err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
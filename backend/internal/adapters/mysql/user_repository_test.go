@@ -0,0 +1,113 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	mysqldriver "github.com/go-sql-driver/mysql"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+func TestUserRepository(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	u := &domain.User{Email: "ann@example.com", PasswordHash: "h", Scopes: domain.ScopeList{}, CreatedAt: now, UpdatedAt: now}
+	cols := []string{"id", "email", "password_hash", "name", "scopes", "created_at", "updated_at"}
+	mock.ExpectExec("INSERT INTO users").
+		WithArgs("ann@example.com", "h", "", "", now, now).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+	mock.ExpectExec("INSERT INTO users").
+		WillReturnError(&mysqldriver.MySQLError{Number: 1062, Message: "Duplicate entry"})
+	mock.ExpectQuery("SELECT id, email, password_hash, name, scopes, created_at, updated_at FROM users WHERE email = \\?").
+		WithArgs("ann@example.com").
+		WillReturnRows(sqlmock.NewRows(cols).
+			AddRow(3, "ann@example.com", "h", "Ann", "admin", now, now))
+	mock.ExpectQuery("SELECT .* FROM users WHERE email = \\?").
+		WithArgs("bob@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery("SELECT id, email, password_hash, name, scopes, created_at, updated_at FROM users ORDER BY id$").
+		WillReturnRows(sqlmock.NewRows(cols).
+			AddRow(3, "ann@example.com", "h", "Ann", "admin", now, now))
+
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+	if id, err := repo.Create(ctx, u); err != nil || id != 3 {
+		t.Fatalf("Create = %d, %v", id, err)
+	}
+	if _, err := repo.Create(ctx, u); !errors.Is(err, ports.ErrEmailTaken) {
+		t.Fatalf("duplicate Create = %v; want ErrEmailTaken", err)
+	}
+	if got, err := repo.GetByEmail(ctx, "ann@example.com"); err != nil || got.ID != 3 || got.Name != "Ann" || got.Scopes[0] != "admin" {
+		t.Fatalf("GetByEmail = %+v, %v", got, err)
+	}
+	if got, err := repo.GetByEmail(ctx, "bob@example.com"); err != nil || got != nil {
+		t.Fatalf("GetByEmail unknown = %+v, %v", got, err)
+	}
+	if got, err := repo.List(ctx); err != nil || len(got) != 1 || got[0].Email != "ann@example.com" {
+		t.Fatalf("List = %+v, %v", got, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestUserRepository_Manage(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	cols := []string{"id", "email", "password_hash", "name", "scopes", "created_at", "updated_at"}
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"n"}).AddRow(7))
+	mock.ExpectQuery("SELECT id, email, password_hash, name, scopes, created_at, updated_at FROM users ORDER BY id LIMIT \\? OFFSET \\?").
+		WithArgs(2, 4).
+		WillReturnRows(sqlmock.NewRows(cols).
+			AddRow(5, "ann@example.com", "h", "", "", now, now).
+			AddRow(6, "bob@example.com", "h", "", "editor", now, now))
+	if users, total, err := repo.ListPage(ctx, 2, 4); err != nil || total != 7 || len(users) != 2 || users[1].Scopes[0] != "editor" {
+		t.Fatalf("ListPage = %+v, %d, %v", users, total, err)
+	}
+
+	mock.ExpectQuery("SELECT .* FROM users WHERE id = \\?").
+		WithArgs(int64(9)).
+		WillReturnRows(sqlmock.NewRows(cols))
+	if got, err := repo.GetByID(ctx, 9); err != nil || got != nil {
+		t.Fatalf("GetByID unknown = %+v, %v", got, err)
+	}
+
+	u := &domain.User{ID: 5, Name: "Ann", PasswordHash: "h2", Scopes: domain.ScopeList{"editor"}, UpdatedAt: now}
+	mock.ExpectExec("UPDATE users SET name = \\?, scopes = \\?, password_hash = \\?, updated_at = \\? WHERE id = \\?").
+		WithArgs("Ann", "editor", "h2", now, int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if found, err := repo.Update(ctx, u); !found || err != nil {
+		t.Fatalf("Update = %v, %v", found, err)
+	}
+	// saved unchanged: no rows changed, but the user exists
+	mock.ExpectExec("UPDATE users").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"e"}).AddRow(true))
+	if found, err := repo.Update(ctx, u); !found || err != nil {
+		t.Fatalf("unchanged Update = %v, %v", found, err)
+	}
+
+	mock.ExpectExec("DELETE FROM users WHERE id = \\?").
+		WithArgs(int64(9)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	if found, err := repo.Delete(ctx, 9); found || err != nil {
+		t.Fatalf("Delete unknown = %v, %v", found, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package mysql
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type viewRepository struct {
+	db *sqlx.DB
+}
+
+func NewViewRepository(db *sqlx.DB) ports.ViewRepository {
+	return &viewRepository{db: db}
+}
+
+// AddViews upserts all counters in a single statement.
+func (r *viewRepository) AddViews(ctx context.Context, counts map[int64]int64) error {
+	if len(counts) == 0 {
+		return nil
+	}
+	ids := make([]int64, 0, len(counts))
+	for id := range counts {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] }) // stable lock order
+
+	now := time.Now().UTC()
+	rows := make([][]any, 0, len(ids))
+	for _, id := range ids {
+		rows = append(rows, []any{id, counts[id], now})
+	}
+	_, err := execSQL(ctx, r.db, sqlf(`
+		INSERT INTO book_views (book_id, views, updated_at)
+		VALUES `).append(repeatSQL("(?, ?, ?)", ", ", rows), sqlf(`
+		ON DUPLICATE KEY UPDATE views = views + VALUES(views), updated_at = VALUES(updated_at)`)))
+	if err != nil {
+		logger.From(ctx).Error("failed to add book views", "books", len(ids), "error", err)
+	}
+	return err
+}
+
+func (r *viewRepository) TopViewed(ctx context.Context, n int) ([]int64, error) {
+	ids := []int64{}
+	err := r.db.SelectContext(ctx, &ids, `
+		SELECT v.book_id
+		FROM book_views v
+		JOIN books b ON b.id = v.book_id
+		ORDER BY v.views DESC, v.book_id ASC
+		LIMIT ?`, n)
+	if err != nil {
+		logger.From(ctx).Error("failed to list top viewed books", "n", n, "error", err)
+	}
+	return ids, err
+}
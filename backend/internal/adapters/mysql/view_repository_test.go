@@ -0,0 +1,58 @@
+package mysql
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestAddViews_UpsertsInIDOrder(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectExec("INSERT INTO book_views .* ON DUPLICATE KEY UPDATE views = views \\+ VALUES\\(views\\)").
+		WithArgs(int64(2), int64(1), sqlmock.AnyArg(), int64(9), int64(5), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	r := NewViewRepository(db)
+	if err := r.AddViews(context.Background(), map[int64]int64{9: 5, 2: 1}); err != nil {
+		t.Fatalf("AddViews error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestAddViews_EmptyIsNoop(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	if err := NewViewRepository(db).AddViews(context.Background(), nil); err != nil {
+		t.Fatalf("AddViews error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestTopViewed(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT v.book_id FROM book_views v JOIN books b .* ORDER BY v.views DESC, v.book_id ASC LIMIT \\?").
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"book_id"}).AddRow(int64(7)).AddRow(int64(3)))
+
+	ids, err := NewViewRepository(db).TopViewed(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("TopViewed error: %v", err)
+	}
+	if !slices.Equal(ids, []int64{7, 3}) {
+		t.Fatalf("ids = %v", ids)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
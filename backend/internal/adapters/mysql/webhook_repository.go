@@ -0,0 +1,171 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+// webhookDispatcher names the webhook dispatcher in projection_cursors.
+const webhookDispatcher = "webhooks"
+
+const (
+	webhookColumns  = `id, url, secret, events, active, created_at, updated_at`
+	deliveryColumns = `id, webhook_id, event_offset, event_type, payload, status, attempts, response_status, error, next_attempt_at, last_attempt_at, created_at`
+)
+
+type webhookRepository struct {
+	db *sqlx.DB
+}
+
+func NewWebhookRepository(db *sqlx.DB) ports.WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) Create(ctx context.Context, w *domain.Webhook) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO webhooks (url, secret, events, active, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		w.URL, w.Secret, w.Events, w.Active, w.CreatedAt, w.UpdatedAt)
+	if err != nil {
+		logger.From(ctx).Error("failed to create webhook", "error", err)
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *webhookRepository) List(ctx context.Context) ([]domain.Webhook, error) {
+	out := []domain.Webhook{}
+	err := r.db.SelectContext(ctx, &out, `SELECT `+webhookColumns+` FROM webhooks ORDER BY id`)
+	if err != nil {
+		logger.From(ctx).Error("failed to list webhooks", "error", err)
+	}
+	return out, err
+}
+
+func (r *webhookRepository) GetByID(ctx context.Context, id int64) (*domain.Webhook, error) {
+	var w domain.Webhook
+	err := r.db.GetContext(ctx, &w, `SELECT `+webhookColumns+` FROM webhooks WHERE id = ?`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to get webhook", "id", id, "error", err)
+		return nil, err
+	}
+	return &w, nil
+}
+
+func (r *webhookRepository) Update(ctx context.Context, w *domain.Webhook) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE webhooks SET url = ?, secret = ?, events = ?, active = ?, updated_at = ? WHERE id = ?`,
+		w.URL, w.Secret, w.Events, w.Active, w.UpdatedAt, w.ID)
+	if err != nil {
+		logger.From(ctx).Error("failed to update webhook", "id", w.ID, "error", err)
+		return false, err
+	}
+	// MySQL counts changed rows, so a webhook saved unchanged reports none
+	// either; look for it
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return n > 0, err
+	}
+	existing, err := r.GetByID(ctx, w.ID)
+	return existing != nil, err
+}
+
+func (r *webhookRepository) Delete(ctx context.Context, id int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM webhooks WHERE id = ?`, id)
+	if err != nil {
+		logger.From(ctx).Error("failed to delete webhook", "id", id, "error", err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *webhookRepository) Enqueue(ctx context.Context, deliveries []domain.WebhookDelivery) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }() // no-op after Commit
+
+	for _, d := range deliveries {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT IGNORE INTO webhook_deliveries (webhook_id, event_offset, event_type, payload, status, next_attempt_at, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			d.WebhookID, d.EventOffset, d.EventType, d.Payload, d.Status, d.NextAttemptAt, d.CreatedAt); err != nil {
+			logger.From(ctx).Error("failed to queue webhook delivery", "webhook_id", d.WebhookID, "offset", d.EventOffset, "error", err)
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *webhookRepository) DueDeliveries(ctx context.Context, now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	out := []domain.WebhookDelivery{}
+	// deliveries of inactive webhooks wait for them to be active again
+	err := r.db.SelectContext(ctx, &out, `
+		SELECT d.id, d.webhook_id, d.event_offset, d.event_type, d.payload, d.status, d.attempts,
+		       d.response_status, d.error, d.next_attempt_at, d.last_attempt_at, d.created_at
+		FROM webhook_deliveries d JOIN webhooks w ON w.id = d.webhook_id AND w.active
+		WHERE d.status = ? AND d.next_attempt_at <= ?
+		ORDER BY d.next_attempt_at, d.id
+		LIMIT ?`, domain.DeliveryPending, now, limit)
+	if err != nil {
+		logger.From(ctx).Error("failed to list due webhook deliveries", "error", err)
+	}
+	return out, err
+}
+
+func (r *webhookRepository) GetDelivery(ctx context.Context, id int64) (*domain.WebhookDelivery, error) {
+	var d domain.WebhookDelivery
+	err := r.db.GetContext(ctx, &d, `SELECT `+deliveryColumns+` FROM webhook_deliveries WHERE id = ?`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.From(ctx).Error("failed to get webhook delivery", "id", id, "error", err)
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *webhookRepository) SaveAttempt(ctx context.Context, d *domain.WebhookDelivery) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, response_status = ?, error = ?, next_attempt_at = ?, last_attempt_at = ?
+		WHERE id = ?`,
+		d.Status, d.Attempts, d.ResponseStatus, d.Error, d.NextAttemptAt, d.LastAttemptAt, d.ID)
+	if err != nil {
+		logger.From(ctx).Error("failed to save webhook delivery attempt", "id", d.ID, "error", err)
+	}
+	return err
+}
+
+func (r *webhookRepository) Deliveries(ctx context.Context, webhookID int64, limit int) ([]domain.WebhookDelivery, error) {
+	out := []domain.WebhookDelivery{}
+	err := r.db.SelectContext(ctx, &out, `
+		SELECT `+deliveryColumns+`
+		FROM webhook_deliveries
+		WHERE webhook_id = ?
+		ORDER BY id DESC
+		LIMIT ?`, webhookID, limit)
+	if err != nil {
+		logger.From(ctx).Error("failed to list webhook deliveries", "webhook_id", webhookID, "error", err)
+	}
+	return out, err
+}
+
+func (r *webhookRepository) Cursor(ctx context.Context) (int64, error) {
+	return loadCursor(ctx, r.db, webhookDispatcher)
+}
+
+func (r *webhookRepository) SaveCursor(ctx context.Context, cursor int64) error {
+	return saveCursor(ctx, r.db, webhookDispatcher, cursor)
+}
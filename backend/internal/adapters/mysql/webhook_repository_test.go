@@ -0,0 +1,58 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+func TestWebhookEnqueue_IgnoresQueuedEvents(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	at := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT IGNORE INTO webhook_deliveries").
+		WithArgs(int64(1), int64(40), "book.created", `{"id":7}`, domain.DeliveryPending, at, at).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+	mock.ExpectExec("INSERT IGNORE INTO webhook_deliveries").
+		WithArgs(int64(2), int64(40), "book.created", `{"id":7}`, domain.DeliveryPending, at, at).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	var deliveries []domain.WebhookDelivery
+	for _, hook := range []int64{1, 2} {
+		deliveries = append(deliveries, domain.WebhookDelivery{
+			WebhookID: hook, EventOffset: 40, EventType: "book.created", Payload: domain.ChangePayload(`{"id":7}`),
+			Status: domain.DeliveryPending, NextAttemptAt: &at, CreatedAt: at,
+		})
+	}
+	if err := NewWebhookRepository(db).Enqueue(context.Background(), deliveries); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestWebhookDueDeliveries_OfActiveWebhooks(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	at := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("FROM webhook_deliveries d JOIN webhooks w ON w.id = d.webhook_id AND w.active WHERE d.status = \\? AND d.next_attempt_at <= \\? ORDER BY d.next_attempt_at, d.id LIMIT \\?").
+		WithArgs(domain.DeliveryPending, at, 50).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "webhook_id", "event_offset", "event_type", "payload", "status", "attempts", "response_status", "error", "next_attempt_at", "last_attempt_at", "created_at"}).
+			AddRow(int64(3), int64(1), int64(40), "book.created", []byte(`{"id":7}`), "pending", 2, 503, "webhook answered 503", at, at.Add(-time.Minute), at.Add(-time.Hour)))
+
+	due, err := NewWebhookRepository(db).DueDeliveries(context.Background(), at, 50)
+	if err != nil || len(due) != 1 || due[0].Attempts != 2 || string(due[0].Payload) != `{"id":7}` || !due[0].NextAttemptAt.Equal(at) {
+		t.Fatalf("DueDeliveries = %+v, %v", due, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
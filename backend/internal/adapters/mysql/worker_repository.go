@@ -0,0 +1,38 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+type workerRepository struct {
+	db *sqlx.DB
+}
+
+func NewWorkerRepository(db *sqlx.DB) ports.WorkerRepository {
+	return &workerRepository{db: db}
+}
+
+func (r *workerRepository) Paused(ctx context.Context) ([]string, error) {
+	out := []string{}
+	err := r.db.SelectContext(ctx, &out, `SELECT name FROM workers WHERE paused = 1`)
+	if err != nil {
+		logger.From(ctx).Error("failed to list paused workers", "error", err)
+	}
+	return out, err
+}
+
+func (r *workerRepository) SetPaused(ctx context.Context, name string, paused bool, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO workers (name, paused, updated_at) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE paused = VALUES(paused), updated_at = VALUES(updated_at)`,
+		name, paused, at)
+	if err != nil {
+		logger.From(ctx).Error("failed to set worker paused", "worker", name, "paused", paused, "error", err)
+	}
+	return err
+}
@@ -0,0 +1,33 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestWorkerRepository(t *testing.T) {
+	db, mock, cleanup := newMockSQLX(t)
+	defer cleanup()
+
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	mock.ExpectExec("INSERT INTO workers .* ON DUPLICATE KEY UPDATE paused = VALUES\\(paused\\)").
+		WithArgs("saved_searches", true, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT name FROM workers WHERE paused = 1").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("saved_searches"))
+
+	repo := NewWorkerRepository(db)
+	if err := repo.SetPaused(context.Background(), "saved_searches", true, now); err != nil {
+		t.Fatalf("SetPaused: %v", err)
+	}
+	names, err := repo.Paused(context.Background())
+	if err != nil || len(names) != 1 || names[0] != "saved_searches" {
+		t.Fatalf("Paused = %v, %v", names, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
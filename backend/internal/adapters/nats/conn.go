@@ -0,0 +1,207 @@
+// Package nats serves the book service over NATS request-reply for internal
+// callers that prefer messaging to HTTP, and publishes the API's domain
+// events. It speaks the core NATS text protocol directly; only what
+// request-reply and publishing need is implemented.
+package nats
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Msg is one message delivered to a subscription. Reply is the inbox the
+// requester waits on, empty for plain publishes.
+type Msg struct {
+	Subject string
+	Reply   string
+	Data    []byte
+}
+
+// Conn is a single connection to a NATS server. It doesn't reconnect: Serve
+// returns when the connection drops and the caller dials again.
+type Conn struct {
+	nc net.Conn
+	r  *bufio.Reader
+
+	wmu sync.Mutex // serializes writes
+
+	mu   sync.Mutex
+	subs map[string]func(Msg) // by sid
+	sid  int
+}
+
+// Dial connects to a nats://[user:pass@]host:port URL and completes the
+// handshake.
+func Dial(ctx context.Context, rawURL string) (*Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid NATS URL %q", rawURL)
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "4222")
+	}
+	var d net.Dialer
+	nc, err := d.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, err
+	}
+	// ctx bounds the handshake too; Serve runs without a deadline
+	if dl, ok := ctx.Deadline(); ok {
+		_ = nc.SetDeadline(dl)
+	}
+	c := &Conn{nc: nc, r: bufio.NewReader(nc), subs: map[string]func(Msg){}}
+	if err := c.handshake(u.User); err != nil {
+		nc.Close()
+		return nil, err
+	}
+	_ = nc.SetDeadline(time.Time{})
+	return c, nil
+}
+
+// Ping connects to the server at rawURL, completes the handshake and hangs
+// up, reporting whether the server is usable.
+func Ping(ctx context.Context, rawURL string) error {
+	c, err := Dial(ctx, rawURL)
+	if err != nil {
+		return err
+	}
+	return c.Close()
+}
+
+// handshake reads the server's INFO, sends CONNECT and waits for the PONG
+// answering our PING, which confirms the server accepted CONNECT.
+func (c *Conn) handshake(user *url.Userinfo) error {
+	line, err := c.readLine()
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		return fmt.Errorf("nats: expected INFO, got %q", line)
+	}
+	opts := map[string]any{"verbose": false, "pedantic": false, "name": "byfood", "lang": "go", "protocol": 1}
+	if user != nil {
+		opts["user"] = user.Username()
+		if p, ok := user.Password(); ok {
+			opts["pass"] = p
+		}
+	}
+	b, _ := json.Marshal(opts)
+	if err := c.write("CONNECT " + string(b) + "\r\nPING\r\n"); err != nil {
+		return err
+	}
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			return err
+		}
+		switch {
+		case line == "PONG":
+			return nil
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("nats: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+// Subscribe delivers messages on subject to h. Subscribers sharing a
+// non-empty queue group get each message once between them. h runs on its
+// own goroutine so a slow handler doesn't hold up the connection.
+func (c *Conn) Subscribe(subject, queue string, h func(Msg)) error {
+	c.mu.Lock()
+	c.sid++
+	sid := strconv.Itoa(c.sid)
+	c.subs[sid] = h
+	c.mu.Unlock()
+	if queue != "" {
+		return c.write("SUB " + subject + " " + queue + " " + sid + "\r\n")
+	}
+	return c.write("SUB " + subject + " " + sid + "\r\n")
+}
+
+// Publish sends data to subject.
+func (c *Conn) Publish(subject string, data []byte) error {
+	return c.write("PUB " + subject + " " + strconv.Itoa(len(data)) + "\r\n" + string(data) + "\r\n")
+}
+
+// Serve reads from the server until the connection fails or is closed,
+// dispatching messages to subscriptions and answering keep-alive PINGs.
+func (c *Conn) Serve() error {
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			return err
+		}
+		switch {
+		case strings.HasPrefix(line, "MSG "):
+			if err := c.dispatch(strings.Fields(line)[1:]); err != nil {
+				return err
+			}
+		case line == "PING":
+			if err := c.write("PONG\r\n"); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("nats: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+// dispatch reads the payload announced by "MSG <subject> <sid> [reply] <size>".
+func (c *Conn) dispatch(args []string) error {
+	if len(args) != 3 && len(args) != 4 {
+		return fmt.Errorf("nats: malformed MSG %q", args)
+	}
+	size, err := strconv.Atoi(args[len(args)-1])
+	if err != nil || size < 0 {
+		return fmt.Errorf("nats: malformed MSG size %q", args[len(args)-1])
+	}
+	data := make([]byte, size+2) // payload + CRLF
+	if _, err := io.ReadFull(c.r, data); err != nil {
+		return err
+	}
+	msg := Msg{Subject: args[0], Data: data[:size]}
+	if len(args) == 4 {
+		msg.Reply = args[2]
+	}
+	c.mu.Lock()
+	h := c.subs[args[1]]
+	c.mu.Unlock()
+	if h != nil {
+		go h(msg)
+	}
+	return nil
+}
+
+// Close closes the connection, which makes Serve return.
+func (c *Conn) Close() error {
+	return c.nc.Close()
+}
+
+func (c *Conn) readLine() (string, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		if errors.Is(err, io.EOF) && line != "" {
+			err = io.ErrUnexpectedEOF
+		}
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (c *Conn) write(s string) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	_, err := io.WriteString(c.nc, s)
+	return err
+}
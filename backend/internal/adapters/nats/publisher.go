@@ -0,0 +1,98 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+)
+
+// Publisher publishes domain events as JSON on <prefix>.<type>, e.g.
+// byfood.events.book.updated, so subscribers pick the events they want with
+// wildcards. It keeps one connection, dialled on the first publish and again
+// on the next one after it drops. Core NATS doesn't acknowledge publishes:
+// an event is only as safe as the connection it was written to.
+type Publisher struct {
+	url    string
+	prefix string
+
+	mu sync.Mutex
+	c  *Conn
+}
+
+func NewPublisher(url, prefix string) *Publisher {
+	return &Publisher{url: url, prefix: prefix}
+}
+
+// Publish sends e, redialling once if the connection turns out to be
+// broken.
+func (p *Publisher) Publish(ctx context.Context, e domain.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	subject := p.prefix + "." + e.Type
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for attempt := 0; ; attempt++ {
+		var c *Conn
+		if c, err = p.conn(ctx); err == nil {
+			if dl, ok := ctx.Deadline(); ok {
+				_ = c.nc.SetWriteDeadline(dl)
+			}
+			if err = c.Publish(subject, data); err == nil {
+				_ = c.nc.SetWriteDeadline(time.Time{})
+				return nil
+			}
+			c.Close()
+			p.c = nil
+		}
+		if attempt == 1 || ctx.Err() != nil {
+			return err
+		}
+	}
+}
+
+// Close hangs up.
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.c == nil {
+		return nil
+	}
+	err := p.c.Close()
+	p.c = nil
+	return err
+}
+
+// conn returns the open connection or dials one. The connection is served
+// in the background, which answers the server's PINGs and notices when it
+// drops.
+func (p *Publisher) conn(ctx context.Context) (*Conn, error) {
+	if p.c != nil {
+		return p.c, nil
+	}
+	c, err := Dial(ctx, p.url)
+	if err != nil {
+		return nil, err
+	}
+	p.c = c
+	go func() {
+		err := c.Serve()
+		p.mu.Lock()
+		lost := p.c == c
+		if lost {
+			p.c = nil
+		}
+		p.mu.Unlock()
+		c.Close()
+		if lost {
+			logger.Log.Warn("nats event publisher connection lost", "error", err)
+		}
+	}()
+	return c, nil
+}
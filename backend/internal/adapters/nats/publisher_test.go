@@ -0,0 +1,84 @@
+package nats
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// TestPublisher_Redials publishes through a scripted NATS server that hangs
+// up after the first event.
+func TestPublisher_Redials(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+
+	got := make(chan string, 2)
+	go func() {
+		for range 2 {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			br := bufio.NewReader(conn)
+			read := func() string {
+				line, _ := br.ReadString('\n')
+				return strings.TrimRight(line, "\r\n")
+			}
+			conn.Write([]byte("INFO {\"server_id\":\"test\"}\r\n"))
+			read() // CONNECT
+			read() // PING
+			conn.Write([]byte("PONG\r\n"))
+			got <- read() + " " + read()
+			conn.Close()
+		}
+	}()
+
+	p := NewPublisher("nats://"+ln.Addr().String(), "byfood.events")
+	defer p.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	at := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	publish := func(e domain.Event) string {
+		if err := p.Publish(ctx, e); err != nil {
+			t.Fatalf("Publish %d: %v", e.Offset, err)
+		}
+		select {
+		case line := <-got:
+			return line
+		case <-ctx.Done():
+			t.Fatal("nothing published")
+			return ""
+		}
+	}
+	line := publish(domain.Event{Offset: 40, Type: "book.updated", Entity: domain.EntityBook, EntityID: 7, Data: domain.ChangePayload(`{"id":7}`), OccurredAt: at})
+	if !strings.HasPrefix(line, "PUB byfood.events.book.updated ") || !strings.Contains(line, `"offset":40`) || !strings.Contains(line, `"data":{"id":7}`) {
+		t.Fatalf("published %s", line)
+	}
+
+	// wait for the publisher to notice the server hung up
+	for {
+		p.mu.Lock()
+		c := p.c
+		p.mu.Unlock()
+		if c == nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatal("connection loss not noticed")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	if line := publish(domain.Event{Offset: 41, Type: "book.deleted", Entity: domain.EntityBook, EntityID: 7, OccurredAt: at}); !strings.HasPrefix(line, "PUB byfood.events.book.deleted ") {
+		t.Fatalf("published %s after redial", line)
+	}
+}
@@ -0,0 +1,200 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/gerry-sabar/byfood/internal/presenter"
+)
+
+// Subjects served, and the queue group replicas share so each request is
+// answered once.
+const (
+	SubjectGet  = "books.get"
+	SubjectList = "books.list"
+	QueueGroup  = "byfood"
+)
+
+// requestTimeout bounds one request; NATS requesters give up on their own
+// timeout anyway, so there is no point working much longer.
+const requestTimeout = 10 * time.Second
+
+// Responder answers book requests arriving over NATS with the same
+// ports.BookService the HTTP adapter uses, and the same JSON book views.
+type Responder struct {
+	svc    ports.BookService
+	now    func() time.Time
+	limits ports.PageLimits
+}
+
+func NewResponder(svc ports.BookService, now func() time.Time) *Responder {
+	if now == nil {
+		now = time.Now
+	}
+	return &Responder{svc: svc, now: now}
+}
+
+// UsePageLimits bounds books.list pages like those of GET /books/.
+func (r *Responder) UsePageLimits(l ports.PageLimits) {
+	r.limits = l
+}
+
+// getRequest is the books.get payload.
+type getRequest struct {
+	ID int64 `json:"id"`
+}
+
+// listRequest is the books.list payload, the query parameters of
+// GET /books/ as JSON. Sort takes the same "-field" form.
+type listRequest struct {
+	Q         string   `json:"q"`
+	Author    string   `json:"author"`
+	ISBN      string   `json:"isbn"`
+	Tag       string   `json:"tag"`
+	Exact     bool     `json:"exact"`
+	YearFrom  *int     `json:"year_from"`
+	YearTo    *int     `json:"year_to"`
+	PriceMin  *float64 `json:"price_min"`
+	PriceMax  *float64 `json:"price_max"`
+	Sort      string   `json:"sort"`
+	Collation string   `json:"collation"`
+	Limit     int      `json:"limit"`
+	Offset    int      `json:"offset"`
+}
+
+type getReply struct {
+	Book presenter.BookView `json:"book"`
+}
+
+type listReply struct {
+	Books []presenter.BookView `json:"books"`
+	Total int                  `json:"total"`
+}
+
+// errorReply carries the HTTP status the same failure gets over REST, so
+// callers can share their error handling.
+type errorReply struct {
+	Error  string            `json:"error"`
+	Code   string            `json:"code,omitempty"`
+	Status int               `json:"status"`
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// Handle answers one request and returns the JSON reply.
+func (r *Responder) Handle(ctx context.Context, subject string, data []byte) []byte {
+	var reply any
+	switch subject {
+	case SubjectGet:
+		reply = r.get(ctx, data)
+	case SubjectList:
+		reply = r.list(ctx, data)
+	default:
+		reply = errorReply{Error: "unknown subject", Status: http.StatusNotFound}
+	}
+	b, _ := json.Marshal(reply)
+	return b
+}
+
+func (r *Responder) get(ctx context.Context, data []byte) any {
+	var req getRequest
+	if err := json.Unmarshal(data, &req); err != nil || req.ID < 1 {
+		return errorReply{Error: "id must be a positive integer", Status: http.StatusBadRequest}
+	}
+	book, err := r.svc.GetBook(ctx, req.ID)
+	if err != nil {
+		return errorReply{Error: err.Error(), Status: http.StatusInternalServerError}
+	}
+	if book == nil {
+		return errorReply{Error: "not found", Status: http.StatusNotFound}
+	}
+	return getReply{Book: presenter.Book(*book, r.now())}
+}
+
+func (r *Responder) list(ctx context.Context, data []byte) any {
+	var req listRequest
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &req); err != nil {
+			return errorReply{Error: "invalid JSON", Status: http.StatusBadRequest}
+		}
+	}
+	f := ports.ListFilter{
+		Q: req.Q, Author: req.Author, ISBN: req.ISBN, Tag: req.Tag, Exact: req.Exact,
+		YearFrom: req.YearFrom, YearTo: req.YearTo, PriceMin: req.PriceMin, PriceMax: req.PriceMax,
+	}
+	page := ports.Page{Limit: req.Limit, Offset: req.Offset, Sort: ports.Sort{Collation: req.Collation}}
+	if req.Sort != "" {
+		page.Sort.Field, page.Sort.Desc = req.Sort, false
+		if req.Sort[0] == '-' {
+			page.Sort.Field, page.Sort.Desc = req.Sort[1:], true
+		}
+	}
+	if err := r.limits.Apply(&page); err != nil {
+		return errorReply{Error: err.Error(), Code: "page_size_exceeded", Status: http.StatusBadRequest}
+	}
+	res, err := r.svc.ListBooksPage(ctx, f, page)
+	if err != nil {
+		var ve *appsvc.ValidationError
+		var qe *appsvc.QueryCostError
+		switch {
+		case errors.As(err, &ve):
+			return errorReply{Error: "validation error", Status: http.StatusUnprocessableEntity, Fields: ve.Fields}
+		case errors.As(err, &qe):
+			return errorReply{Error: err.Error(), Code: "query_too_expensive", Status: http.StatusBadRequest}
+		}
+		return errorReply{Error: err.Error(), Status: http.StatusInternalServerError}
+	}
+	return listReply{Books: presenter.Books(res.Books, r.now()), Total: res.Total}
+}
+
+// Serve subscribes to every subject on c and answers requests until the
+// connection drops.
+func (r *Responder) Serve(ctx context.Context, c *Conn) error {
+	for _, subject := range []string{SubjectGet, SubjectList} {
+		err := c.Subscribe(subject, QueueGroup, func(m Msg) {
+			if m.Reply == "" {
+				return // a publish, not a request; nobody is waiting
+			}
+			ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+			defer cancel()
+			if err := c.Publish(m.Reply, r.Handle(ctx, m.Subject, m.Data)); err != nil {
+				logger.Log.Error("nats reply failed", "subject", m.Subject, "error", err)
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return c.Serve()
+}
+
+// Run keeps r connected to the NATS server at url, redialing with backoff
+// (up to 30s) whenever the connection fails, until ctx is done.
+func Run(ctx context.Context, url string, r *Responder) {
+	backoff := time.Second
+	for ctx.Err() == nil {
+		c, err := Dial(ctx, url)
+		if err == nil {
+			logger.From(ctx).Info("serving book requests over nats")
+			backoff = time.Second
+			stop := context.AfterFunc(ctx, func() { c.Close() })
+			err = r.Serve(ctx, c)
+			stop()
+			c.Close()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		logger.From(ctx).Error("nats connection lost, retrying", "in", backoff, "error", err)
+		select {
+		case <-ctx.Done():
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, 30*time.Second)
+	}
+}
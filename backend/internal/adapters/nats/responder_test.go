@@ -0,0 +1,167 @@
+package nats
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	appsvc "github.com/gerry-sabar/byfood/internal/app"
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// stubBooks implements the two service methods the responder calls.
+type stubBooks struct {
+	ports.BookService
+	GetBookFn  func(ctx context.Context, id int64) (*domain.Book, error)
+	ListPageFn func(ctx context.Context, f ports.ListFilter, page ports.Page) (*ports.BookPage, error)
+}
+
+func (s *stubBooks) GetBook(ctx context.Context, id int64) (*domain.Book, error) {
+	return s.GetBookFn(ctx, id)
+}
+
+func (s *stubBooks) ListBooksPage(ctx context.Context, f ports.ListFilter, page ports.Page) (*ports.BookPage, error) {
+	return s.ListPageFn(ctx, f, page)
+}
+
+var fixedNow = func() time.Time { return time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC) }
+
+func TestResponder_Handle(t *testing.T) {
+	var gotFilter ports.ListFilter
+	var gotPage ports.Page
+	svc := &stubBooks{
+		GetBookFn: func(ctx context.Context, id int64) (*domain.Book, error) {
+			if id == 404 {
+				return nil, nil
+			}
+			return &domain.Book{ID: id, Title: "Ficciones"}, nil
+		},
+		ListPageFn: func(ctx context.Context, f ports.ListFilter, page ports.Page) (*ports.BookPage, error) {
+			if f.YearFrom != nil && *f.YearFrom > 3000 {
+				return nil, &appsvc.ValidationError{Fields: map[string]string{"year_from": "bad"}}
+			}
+			gotFilter, gotPage = f, page
+			return &ports.BookPage{Books: []domain.Book{{ID: 1}}, Total: 7}, nil
+		},
+	}
+	r := NewResponder(svc, fixedNow)
+	ctx := context.Background()
+
+	cases := []struct {
+		subject, data string
+		want          []string
+	}{
+		{SubjectGet, `{"id":3}`, []string{`"book":{`, `"id":3`, `"title":"Ficciones"`}},
+		{SubjectGet, `{"id":404}`, []string{`"status":404`}},
+		{SubjectGet, `{"id":"x"}`, []string{`"status":400`}},
+		{SubjectList, `{"author":"Borges","sort":"-title","collation":"es","limit":5}`, []string{`"books":[{`, `"total":7`}},
+		{SubjectList, `{"year_from":4000}`, []string{`"status":422`, `"fields":{"year_from":"bad"}`}},
+		{SubjectList, `nope`, []string{`"status":400`}},
+		{"books.delete", `{}`, []string{`"status":404`, "unknown subject"}},
+	}
+	for _, c := range cases {
+		got := string(r.Handle(ctx, c.subject, []byte(c.data)))
+		for _, w := range c.want {
+			if !strings.Contains(got, w) {
+				t.Fatalf("%s %s = %s; want %s", c.subject, c.data, got, w)
+			}
+		}
+	}
+	if gotFilter.Author != "Borges" || gotPage != (ports.Page{Limit: 5, Sort: ports.Sort{Field: "title", Desc: true, Collation: "es"}}) {
+		t.Fatalf("filter = %+v, page = %+v", gotFilter, gotPage)
+	}
+}
+
+func TestResponder_PageLimits(t *testing.T) {
+	var got ports.Page
+	svc := &stubBooks{ListPageFn: func(ctx context.Context, f ports.ListFilter, page ports.Page) (*ports.BookPage, error) {
+		got = page
+		return &ports.BookPage{}, nil
+	}}
+	r := NewResponder(svc, fixedNow)
+	r.UsePageLimits(ports.PageLimits{Default: 50, Max: 200})
+	ctx := context.Background()
+
+	r.Handle(ctx, SubjectList, []byte(`{}`))
+	if got.Limit != 50 {
+		t.Fatalf("default page = %+v", got)
+	}
+	reply := string(r.Handle(ctx, SubjectList, []byte(`{"limit":500}`)))
+	if !strings.Contains(reply, `"code":"page_size_exceeded"`) || !strings.Contains(reply, `"status":400`) {
+		t.Fatalf("reply = %s", reply)
+	}
+}
+
+// TestResponder_Serve drives the responder through a scripted NATS server.
+func TestResponder_Serve(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+
+	reply := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		br := bufio.NewReader(conn)
+		read := func() string {
+			line, _ := br.ReadString('\n')
+			return strings.TrimRight(line, "\r\n")
+		}
+		conn.Write([]byte("INFO {\"server_id\":\"test\"}\r\n"))
+		if l := read(); !strings.HasPrefix(l, "CONNECT ") || !strings.Contains(l, `"user":"svc"`) {
+			reply <- "bad connect: " + l
+			return
+		}
+		read() // PING
+		conn.Write([]byte("PONG\r\n"))
+		for _, want := range []string{"SUB books.get byfood 1", "SUB books.list byfood 2"} {
+			if l := read(); l != want {
+				reply <- "bad sub: " + l
+				return
+			}
+		}
+		conn.Write([]byte("PING\r\nMSG books.get 1 _INBOX.abc 8\r\n{\"id\":5}\r\n"))
+		for {
+			l := read()
+			if l == "PONG" {
+				continue
+			}
+			if !strings.HasPrefix(l, "PUB _INBOX.abc ") {
+				reply <- "bad pub: " + l
+				return
+			}
+			reply <- read()
+			return
+		}
+	}()
+
+	svc := &stubBooks{GetBookFn: func(ctx context.Context, id int64) (*domain.Book, error) {
+		return &domain.Book{ID: id, Title: "Ficciones"}, nil
+	}}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c, err := Dial(ctx, "nats://svc:secret@"+ln.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer c.Close()
+	go NewResponder(svc, fixedNow).Serve(ctx, c)
+
+	select {
+	case got := <-reply:
+		if !strings.Contains(got, `"id":5`) || !strings.Contains(got, `"title":"Ficciones"`) {
+			t.Fatalf("reply = %s", got)
+		}
+	case <-ctx.Done():
+		t.Fatal("no reply")
+	}
+}
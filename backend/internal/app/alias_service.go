@@ -0,0 +1,107 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+const maxAliasesPerBook = 20
+
+type aliasService struct {
+	books   ports.BookReader
+	aliases ports.AliasRepository
+	changes *ChangeFeed // optional; alias edits count as book updates
+}
+
+func NewAliasService(books ports.BookReader, aliases ports.AliasRepository, changes *ChangeFeed) ports.AliasService {
+	return &aliasService{books: books, aliases: aliases, changes: changes}
+}
+
+func (s *aliasService) ListAliases(ctx context.Context, bookID int64) ([]domain.Alias, error) {
+	if err := s.requireBook(ctx, bookID); err != nil {
+		return nil, err
+	}
+	return s.aliases.ListByBook(ctx, bookID)
+}
+
+func (s *aliasService) AddAlias(ctx context.Context, bookID int64, in ports.AddAliasInput) (*domain.Alias, error) {
+	if err := s.requireBook(ctx, bookID); err != nil {
+		return nil, err
+	}
+	existing, err := s.aliases.ListByBook(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := &ValidationError{}
+	alias := strings.TrimSpace(in.Alias)
+	switch {
+	case alias == "":
+		errs.add("alias", "Alias is required")
+	case len(alias) > 120:
+		errs.add("alias", "Alias must be ≤ 120 characters")
+	case len(existing) >= maxAliasesPerBook:
+		errs.add("alias", fmt.Sprintf("A book can have at most %d aliases", maxAliasesPerBook))
+	}
+	key := domain.SearchKey(alias)
+	for _, a := range existing {
+		if domain.SearchKey(a.Alias) == key {
+			errs.add("alias", "Alias already exists")
+		}
+	}
+	if !errs.ok() {
+		return nil, errs
+	}
+
+	a := &domain.Alias{BookID: bookID, Alias: alias, CreatedAt: clock().UTC()}
+	id, err := s.aliases.Add(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+	a.ID = id
+	s.recordChange(ctx, bookID)
+	return a, nil
+}
+
+func (s *aliasService) DeleteAlias(ctx context.Context, bookID, aliasID int64) error {
+	found, err := s.aliases.Delete(ctx, bookID, aliasID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.New("alias not found")
+	}
+	s.recordChange(ctx, bookID)
+	return nil
+}
+
+func (s *aliasService) requireBook(ctx context.Context, id int64) error {
+	b, err := s.books.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if b == nil {
+		return errors.New("book not found")
+	}
+	return nil
+}
+
+func (s *aliasService) recordChange(ctx context.Context, bookID int64) {
+	if s.changes == nil {
+		return
+	}
+	// snapshot after the change; a failed read still records the change
+	b, err := s.books.GetByID(ctx, bookID)
+	if err != nil {
+		logger.From(ctx).Error("failed to read book for change payload", "id", bookID, "error", err)
+	}
+	if err := s.changes.Record(ctx, bookID, domain.ChangeUpdated, b); err != nil {
+		logger.From(ctx).Error("failed to record book change", "id", bookID, "op", domain.ChangeUpdated, "error", err)
+	}
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type memAliasRepo struct {
+	aliases []domain.Alias
+}
+
+func (m *memAliasRepo) ListByBook(ctx context.Context, bookID int64) ([]domain.Alias, error) {
+	out := []domain.Alias{}
+	for _, a := range m.aliases {
+		if a.BookID == bookID {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+func (m *memAliasRepo) Add(ctx context.Context, a *domain.Alias) (int64, error) {
+	a.ID = int64(len(m.aliases) + 1)
+	m.aliases = append(m.aliases, *a)
+	return a.ID, nil
+}
+func (m *memAliasRepo) Delete(ctx context.Context, bookID, id int64) (bool, error) {
+	for i, a := range m.aliases {
+		if a.ID == id && a.BookID == bookID {
+			m.aliases = append(m.aliases[:i], m.aliases[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestAliasService_AddValidatesAndRecords(t *testing.T) {
+	changes := &memChangeRepo{}
+	aliases := &memAliasRepo{}
+	svc := NewAliasService(newMemBookRepo(domain.Book{ID: 1}), aliases, NewChangeFeed(changes))
+	ctx := context.Background()
+
+	a, err := svc.AddAlias(ctx, 1, ports.AddAliasInput{Alias: "  Cien años de soledad "})
+	if err != nil {
+		t.Fatalf("AddAlias err: %v", err)
+	}
+	if a.ID == 0 || a.Alias != "Cien años de soledad" {
+		t.Fatalf("alias = %+v", a)
+	}
+	if len(changes.changes) != 1 || changes.changes[0].Op != domain.ChangeUpdated {
+		t.Fatalf("changes = %+v", changes.changes)
+	}
+
+	for _, bad := range []string{"", "CIEN ANOS DE SOLEDAD"} {
+		_, err := svc.AddAlias(ctx, 1, ports.AddAliasInput{Alias: bad})
+		if ve, ok := err.(*ValidationError); !ok || ve.Fields["alias"] == "" {
+			t.Fatalf("AddAlias(%q) err = %v; want alias validation error", bad, err)
+		}
+	}
+
+	if _, err := svc.AddAlias(ctx, 2, ports.AddAliasInput{Alias: "x"}); err == nil || err.Error() != "book not found" {
+		t.Fatalf("err = %v; want book not found", err)
+	}
+}
+
+func TestAliasService_Delete(t *testing.T) {
+	aliases := &memAliasRepo{aliases: []domain.Alias{{ID: 1, BookID: 1, Alias: "A"}}}
+	svc := NewAliasService(newMemBookRepo(domain.Book{ID: 1}), aliases, nil)
+
+	if err := svc.DeleteAlias(context.Background(), 2, 1); err == nil || err.Error() != "alias not found" {
+		t.Fatalf("err = %v; want alias not found", err)
+	}
+	if err := svc.DeleteAlias(context.Background(), 1, 1); err != nil {
+		t.Fatalf("DeleteAlias err: %v", err)
+	}
+	if len(aliases.aliases) != 0 {
+		t.Fatalf("aliases = %+v", aliases.aliases)
+	}
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type memAPIKeyRepo struct {
+	keys []domain.APIKey
+}
+
+func (m *memAPIKeyRepo) Create(ctx context.Context, k *domain.APIKey) (int64, error) {
+	k.ID = int64(len(m.keys) + 1)
+	m.keys = append(m.keys, *k)
+	return k.ID, nil
+}
+func (m *memAPIKeyRepo) List(ctx context.Context) ([]domain.APIKey, error) { return m.keys, nil }
+func (m *memAPIKeyRepo) GetByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	for _, k := range m.keys {
+		if k.Hash == hash {
+			return &k, nil
+		}
+	}
+	return nil, nil
+}
+func (m *memAPIKeyRepo) Revoke(ctx context.Context, id int64, at time.Time) (bool, error) {
+	for i := range m.keys {
+		if m.keys[i].ID == id {
+			m.keys[i].RevokedAt = &at
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestAPIKeys_IssueAuthenticateRevoke(t *testing.T) {
+	repo := &memAPIKeyRepo{}
+	svc := NewAPIKeys(repo)
+	ctx := context.Background()
+
+	if _, err := svc.IssueAPIKey(ctx, ports.IssueAPIKeyInput{Name: " ", Scopes: []string{"a,b"}}); err == nil {
+		t.Fatalf("want validation error")
+	} else if ve := err.(*ValidationError); ve.Fields["name"] == "" || ve.Fields["scopes"] == "" {
+		t.Fatalf("fields = %v", ve.Fields)
+	}
+
+	issued, err := svc.IssueAPIKey(ctx, ports.IssueAPIKeyInput{Name: "warehouse", Scopes: []string{"admin"}})
+	if err != nil || !strings.HasPrefix(issued.Key, "bfk_") || issued.ID != 1 {
+		t.Fatalf("Issue = %+v, %v", issued, err)
+	}
+	if repo.keys[0].Hash == issued.Key || repo.keys[0].Hash != HashAPIKey(issued.Key) {
+		t.Fatalf("stored %q; want the hash of the key", repo.keys[0].Hash)
+	}
+
+	k, err := svc.Authenticate(ctx, issued.Key)
+	if err != nil || k == nil || k.Name != "warehouse" || k.Scopes[0] != "admin" {
+		t.Fatalf("Authenticate = %+v, %v", k, err)
+	}
+	for _, bad := range []string{"", "bfk_nope", issued.Key[4:]} {
+		if k, _ := svc.Authenticate(ctx, bad); k != nil {
+			t.Fatalf("Authenticate(%q) = %+v", bad, k)
+		}
+	}
+
+	if err := svc.RevokeAPIKey(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if k, _ := svc.Authenticate(ctx, issued.Key); k != nil {
+		t.Fatalf("revoked key still accepted")
+	}
+	if err := svc.RevokeAPIKey(ctx, 9); err == nil || err.Error() != "api key not found" {
+		t.Fatalf("Revoke missing = %v", err)
+	}
+}
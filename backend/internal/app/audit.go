@@ -0,0 +1,32 @@
+package app
+
+import (
+	"context"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+// AuditTrail serves the audit entries the book repository writes with every
+// create, update and delete. Entries of deleted books stay readable.
+type AuditTrail struct {
+	repo ports.AuditRepository
+}
+
+func NewAuditTrail(repo ports.AuditRepository) *AuditTrail {
+	return &AuditTrail{repo: repo}
+}
+
+// BookAudit returns the newest entries of book id first. A limit of 0 means
+// the default; larger limits are capped.
+func (a *AuditTrail) BookAudit(ctx context.Context, id int64, before int64, limit int) ([]domain.AuditEntry, error) {
+	if limit <= 0 {
+		limit = defaultAuditLimit
+	}
+	return a.repo.BookAudit(ctx, id, before, min(limit, maxAuditLimit))
+}
@@ -0,0 +1,29 @@
+package app
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+type memAuditRepo struct{ limits []int }
+
+func (m *memAuditRepo) BookAudit(ctx context.Context, id int64, before int64, limit int) ([]domain.AuditEntry, error) {
+	m.limits = append(m.limits, limit)
+	return []domain.AuditEntry{}, nil
+}
+
+func TestAuditTrail_Limits(t *testing.T) {
+	repo := &memAuditRepo{}
+	trail := NewAuditTrail(repo)
+	for _, limit := range []int{0, 20, 10000} {
+		if _, err := trail.BookAudit(context.Background(), 1, 0, limit); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := []int{defaultAuditLimit, 20, maxAuditLimit}; !slices.Equal(repo.limits, want) {
+		t.Fatalf("limits = %v; want %v", repo.limits, want)
+	}
+}
@@ -0,0 +1,184 @@
+package app
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/jwt"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// passwordIterations is the PBKDF2-SHA256 work factor OWASP recommends.
+const passwordIterations = 600_000
+
+const (
+	minPasswordLen = 8
+	maxPasswordLen = 256
+)
+
+// Auth registers users and logs them in with HS256 JWTs that act as the
+// user's email. A token carries the scopes the user had at login, but every
+// request runs with the ones the user has now, so demoting or deleting a
+// user takes effect at once.
+type Auth struct {
+	repo       ports.UserRepository
+	secret     []byte
+	ttl        time.Duration
+	iterations int
+	now        func() time.Time
+}
+
+func NewAuth(repo ports.UserRepository, secret []byte, ttl time.Duration) *Auth {
+	return &Auth{repo: repo, secret: secret, ttl: ttl, iterations: passwordIterations, now: clock}
+}
+
+func (a *Auth) Register(ctx context.Context, in ports.Credentials) (*domain.User, error) {
+	errs := &ValidationError{}
+	email := validateEmail(errs, in.Email)
+	validatePassword(errs, "password", in.Password)
+	if !errs.ok() {
+		return nil, errs
+	}
+	hashed, err := hashPassword(in.Password, a.iterations)
+	if err != nil {
+		return nil, err
+	}
+	now := a.now().UTC()
+	u := &domain.User{Email: email, PasswordHash: hashed, Scopes: domain.ScopeList{}, CreatedAt: now, UpdatedAt: now}
+	if u.ID, err = a.repo.Create(ctx, u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (a *Auth) Login(ctx context.Context, in ports.Credentials) (*ports.AuthToken, error) {
+	u, err := a.repo.GetByEmail(ctx, strings.ToLower(strings.TrimSpace(in.Email)))
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		// hash anyway, so unknown emails take as long as wrong passwords
+		_, _ = hashPassword(in.Password, a.iterations)
+		return nil, ports.ErrInvalidCredentials
+	}
+	if !checkPassword(u.PasswordHash, in.Password) {
+		return nil, ports.ErrInvalidCredentials
+	}
+	now := a.now().UTC().Truncate(time.Second)
+	exp := now.Add(a.ttl)
+	token, err := jwt.Sign(jwt.Claims{
+		Subject: strconv.FormatInt(u.ID, 10), Email: u.Email, Scopes: u.Scopes,
+		IssuedAt: now.Unix(), ExpiresAt: exp.Unix(),
+	}, a.secret)
+	if err != nil {
+		return nil, err
+	}
+	return &ports.AuthToken{Token: token, TokenType: "Bearer", ExpiresAt: exp}, nil
+}
+
+func (a *Auth) Verify(ctx context.Context, token string) (*domain.Actor, error) {
+	c, err := jwt.Verify(token, a.secret, a.now())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ports.ErrInvalidToken, err)
+	}
+	id, err := strconv.ParseInt(c.Subject, 10, 64)
+	if err != nil || c.Email == "" {
+		return nil, fmt.Errorf("%w: %w", ports.ErrInvalidToken, jwt.ErrMalformed)
+	}
+	u, err := a.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil || u.Email != c.Email {
+		return nil, fmt.Errorf("%w: user no longer exists", ports.ErrInvalidToken)
+	}
+	return &domain.Actor{ID: u.Email, Scopes: u.Scopes}, nil
+}
+
+func (a *Auth) Actor(ctx context.Context, email string) (*domain.Actor, error) {
+	u, err := a.repo.GetByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil || u == nil {
+		return nil, err
+	}
+	return &domain.Actor{ID: u.Email, Scopes: u.Scopes}, nil
+}
+
+// validateEmail checks an account's email and returns it lowercased.
+func validateEmail(errs *ValidationError, email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email || len(email) > 254 {
+		errs.add("email", "Email must be a valid address")
+	}
+	return email
+}
+
+func validatePassword(errs *ValidationError, field, password string) {
+	switch n := len([]rune(password)); {
+	case n < minPasswordLen:
+		errs.add(field, fmt.Sprintf("Password must be at least %d characters", minPasswordLen))
+	case n > maxPasswordLen:
+		errs.add(field, fmt.Sprintf("Password must be ≤ %d characters", maxPasswordLen))
+	}
+}
+
+// hashPassword returns "pbkdf2-sha256$<iterations>$<salt>$<key>", salt and
+// key base64 encoded.
+func hashPassword(password string, iterations int) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := pbkdf2(sha256.New, []byte(password), salt, iterations, sha256.Size)
+	return fmt.Sprintf("pbkdf2-sha256$%d$%s$%s", iterations,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func checkPassword(stored, password string) bool {
+	parts := strings.Split(stored, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2-sha256" {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	salt, err1 := base64.RawStdEncoding.DecodeString(parts[2])
+	key, err2 := base64.RawStdEncoding.DecodeString(parts[3])
+	if err := errors.Join(err, err1, err2); err != nil || iterations < 1 {
+		return false
+	}
+	got := pbkdf2(sha256.New, []byte(password), salt, iterations, len(key))
+	return hmac.Equal(got, key)
+}
+
+// pbkdf2 derives a keyLen byte key as in RFC 8018, section 5.2.
+func pbkdf2(h func() hash.Hash, password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(h, password)
+	size := prf.Size()
+	var out []byte
+	for block := uint32(1); len(out) < keyLen; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write([]byte{byte(block >> 24), byte(block >> 16), byte(block >> 8), byte(block)})
+		u := prf.Sum(nil)
+		t := append([]byte(nil), u...)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		out = append(out, t[:size]...)
+	}
+	return out[:keyLen]
+}
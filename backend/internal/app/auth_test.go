@@ -0,0 +1,176 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type memUserRepo struct {
+	users  []domain.User
+	nextID int64
+}
+
+func (m *memUserRepo) Create(ctx context.Context, u *domain.User) (int64, error) {
+	for _, x := range m.users {
+		if x.Email == u.Email {
+			return 0, ports.ErrEmailTaken
+		}
+	}
+	m.nextID++
+	u.ID = m.nextID
+	m.users = append(m.users, *u)
+	return u.ID, nil
+}
+
+func (m *memUserRepo) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	for _, u := range m.users {
+		if u.Email == email {
+			return &u, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memUserRepo) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	for _, u := range m.users {
+		if u.ID == id {
+			return &u, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memUserRepo) List(ctx context.Context) ([]domain.User, error) {
+	return m.users, nil
+}
+
+func (m *memUserRepo) ListPage(ctx context.Context, limit, offset int) ([]domain.User, int, error) {
+	page := m.users[min(offset, len(m.users)):]
+	return slices.Clone(page[:min(limit, len(page))]), len(m.users), nil
+}
+
+func (m *memUserRepo) Update(ctx context.Context, u *domain.User) (bool, error) {
+	for i := range m.users {
+		if m.users[i].ID == u.ID {
+			m.users[i].Name, m.users[i].Scopes = u.Name, u.Scopes
+			m.users[i].PasswordHash, m.users[i].UpdatedAt = u.PasswordHash, u.UpdatedAt
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *memUserRepo) Delete(ctx context.Context, id int64) (bool, error) {
+	n := len(m.users)
+	m.users = slices.DeleteFunc(m.users, func(u domain.User) bool { return u.ID == id })
+	return len(m.users) < n, nil
+}
+
+func TestPBKDF2_RFCVectors(t *testing.T) {
+	for iterations, want := range map[int]string{
+		1:    "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b",
+		4096: "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a",
+	} {
+		if got := hex.EncodeToString(pbkdf2(sha256.New, []byte("password"), []byte("salt"), iterations, 32)); got != want {
+			t.Errorf("%d iterations: %s; want %s", iterations, got, want)
+		}
+	}
+}
+
+func TestAuth_RegisterLoginVerify(t *testing.T) {
+	repo := &memUserRepo{}
+	auth := NewAuth(repo, []byte("secret"), time.Hour)
+	auth.iterations = 10
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	auth.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, err := auth.Register(ctx, ports.Credentials{Email: "Ann <ann@example.com>", Password: "short"})
+	if ve, ok := err.(*ValidationError); !ok || ve.Fields["email"] == "" || ve.Fields["password"] == "" {
+		t.Fatalf("Register invalid = %v", err)
+	}
+	u, err := auth.Register(ctx, ports.Credentials{Email: " Ann@Example.com ", Password: "correct horse"})
+	if err != nil || u.ID != 1 || u.Email != "ann@example.com" || !strings.HasPrefix(u.PasswordHash, "pbkdf2-sha256$10$") {
+		t.Fatalf("Register = %+v, %v", u, err)
+	}
+	if _, err := auth.Register(ctx, ports.Credentials{Email: "ann@example.com", Password: "another one"}); !errors.Is(err, ports.ErrEmailTaken) {
+		t.Fatalf("Register twice = %v", err)
+	}
+
+	for _, c := range []ports.Credentials{{Email: "ann@example.com", Password: "wrong horse"}, {Email: "bob@example.com", Password: "correct horse"}} {
+		if _, err := auth.Login(ctx, c); !errors.Is(err, ports.ErrInvalidCredentials) {
+			t.Fatalf("Login(%+v) = %v", c, err)
+		}
+	}
+	repo.users[0].Scopes = domain.ScopeList{domain.ScopeAdmin}
+	tok, err := auth.Login(ctx, ports.Credentials{Email: "ANN@example.com", Password: "correct horse"})
+	if err != nil || tok.TokenType != "Bearer" || !tok.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Fatalf("Login = %+v, %v", tok, err)
+	}
+
+	actor, err := auth.Verify(ctx, tok.Token)
+	if err != nil || actor.ID != "ann@example.com" || !actor.HasScope(domain.ScopeAdmin) {
+		t.Fatalf("Verify = %+v, %v", actor, err)
+	}
+	now = now.Add(time.Hour)
+	if _, err := auth.Verify(ctx, tok.Token); !errors.Is(err, ports.ErrInvalidToken) {
+		t.Fatalf("expired token: %v", err)
+	}
+}
+
+func TestAuth_VerifyUsesCurrentAccount(t *testing.T) {
+	repo := &memUserRepo{}
+	auth := NewAuth(repo, []byte("secret"), time.Hour)
+	auth.iterations = 10
+	users := NewUsers(repo)
+	ctx := context.Background()
+	u, _ := auth.Register(ctx, ports.Credentials{Email: "ann@example.com", Password: "correct horse"})
+	repo.users[0].Scopes = domain.ScopeList{domain.ScopeAdmin}
+	tok, err := auth.Login(ctx, ports.Credentials{Email: "ann@example.com", Password: "correct horse"})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+
+	if _, err := users.UpdateUser(ctx, u.ID, ports.UpdateUserInput{Scopes: []string{domain.ScopeReader}}); err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	actor, err := auth.Verify(ctx, tok.Token)
+	if err != nil || actor.HasScope(domain.ScopeAdmin) || !actor.HasScope(domain.ScopeReader) {
+		t.Fatalf("Verify after demotion = %+v, %v", actor, err)
+	}
+
+	if err := users.DeleteUser(ctx, u.ID); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+	if _, err := auth.Verify(ctx, tok.Token); !errors.Is(err, ports.ErrInvalidToken) {
+		t.Fatalf("Verify after delete = %v", err)
+	}
+}
+
+func TestAuth_Actor(t *testing.T) {
+	repo := &memUserRepo{}
+	auth := NewAuth(repo, []byte("secret"), time.Hour)
+	auth.iterations = 10
+	ctx := context.Background()
+	if _, err := auth.Register(ctx, ports.Credentials{Email: "ann@example.com", Password: "correct horse"}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	repo.users[0].Scopes = domain.ScopeList{domain.ScopeEditor}
+
+	actor, err := auth.Actor(ctx, " ANN@example.com")
+	if err != nil || actor == nil || actor.ID != "ann@example.com" || !actor.HasScope(domain.ScopeEditor) {
+		t.Fatalf("Actor = %+v, %v", actor, err)
+	}
+	if actor, err := auth.Actor(ctx, "bob@example.com"); err != nil || actor != nil {
+		t.Fatalf("Actor of an unknown email = %+v, %v", actor, err)
+	}
+}
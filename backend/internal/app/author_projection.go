@@ -0,0 +1,192 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// AuthorProjection keeps the author read model in step with the change log
+// and serves it. Every replica may run it: applying a change recomputes the
+// affected summaries from the books table, so repeated or concurrent
+// application converges on the same result.
+type AuthorProjection struct {
+	authors ports.AuthorRepository
+	books   ports.BookReader
+	feed    *ChangeFeed
+	names   ports.TaxonomyRepository // optional curated author names
+	now     func() time.Time
+}
+
+func NewAuthorProjection(authors ports.AuthorRepository, books ports.BookReader, feed *ChangeFeed) *AuthorProjection {
+	return &AuthorProjection{authors: authors, books: books, feed: feed, now: clock}
+}
+
+// UseTaxonomy names authors on their pages as the taxonomy in t does, where
+// it has them.
+func (p *AuthorProjection) UseTaxonomy(t ports.TaxonomyRepository) {
+	p.names = t
+}
+
+func (p *AuthorProjection) AuthorSummary(ctx context.Context, id string) (*domain.AuthorSummary, error) {
+	s, err := p.authors.Summary(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, errors.New("author not found")
+	}
+	if p.names != nil {
+		// the page still works with the spelling of the newest book
+		a, err := p.names.Author(ctx, id)
+		if err != nil {
+			logger.From(ctx).Error("failed to read curated author name", "author", id, "error", err)
+		} else if a != nil {
+			s.Name = a.Name
+		}
+	}
+	return s, nil
+}
+
+// Apply folds one change into the read model.
+func (p *AuthorProjection) Apply(ctx context.Context, c domain.Change) error {
+	if c.Entity != "" && c.Entity != domain.EntityBook {
+		return nil
+	}
+	var book *domain.Book
+	if c.Op != domain.ChangeDeleted {
+		var err error
+		if book, err = c.Book(); err != nil || book == nil {
+			// entries from before payloads existed: read the row instead
+			if book, err = p.books.GetByID(ctx, c.BookID); err != nil {
+				return err
+			}
+		}
+	}
+	author := ""
+	if book != nil {
+		author = domain.AuthorSlug(book.Author)
+	}
+	prev, err := p.authors.IndexBook(ctx, c.BookID, author)
+	if err != nil {
+		return err
+	}
+	if err := p.refresh(ctx, prev); err != nil {
+		return err
+	}
+	if author != prev {
+		return p.refresh(ctx, author)
+	}
+	return nil
+}
+
+// ApplyAll folds changes into the read model in log order, e.g. when they
+// are replayed.
+func (p *AuthorProjection) ApplyAll(ctx context.Context, changes []domain.Change) error {
+	for _, c := range changes {
+		if err := p.Apply(ctx, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refresh recomputes the summary of author from its current books.
+func (p *AuthorProjection) refresh(ctx context.Context, author string) error {
+	if author == "" {
+		return nil
+	}
+	books, err := p.authors.Books(ctx, author)
+	if err != nil {
+		return err
+	}
+	if len(books) == 0 {
+		return p.authors.DeleteSummary(ctx, author)
+	}
+	s := domain.SummarizeAuthor(author, books, p.now().UTC())
+	return p.authors.SaveSummary(ctx, &s)
+}
+
+// Rebuild recomputes the whole read model from the books table and moves the
+// cursor to the change log position read beforehand, so nothing written
+// meanwhile is skipped.
+func (p *AuthorProjection) Rebuild(ctx context.Context) error {
+	cursor, err := p.feed.LatestID(ctx)
+	if err != nil {
+		return err
+	}
+	books, err := p.books.List(ctx, ports.ListFilter{})
+	if err != nil {
+		return err
+	}
+	if err := p.authors.Reset(ctx); err != nil {
+		return err
+	}
+	authors := map[string]bool{}
+	for _, b := range books {
+		author := domain.AuthorSlug(b.Author)
+		if _, err := p.authors.IndexBook(ctx, b.ID, author); err != nil {
+			return err
+		}
+		authors[author] = true
+	}
+	for author := range authors {
+		if err := p.refresh(ctx, author); err != nil {
+			return err
+		}
+	}
+	return p.authors.SaveCursor(ctx, cursor)
+}
+
+// authorPollWait is how long Run long-polls the change log per round.
+const authorPollWait = 30 * time.Second
+
+// Run rebuilds the model if it has never been built, then follows the change
+// log until ctx is done.
+func (p *AuthorProjection) Run(ctx context.Context) {
+	cursor, err := p.authors.Cursor(ctx)
+	if err == nil && cursor == 0 {
+		// never built, or the change log is empty and rebuilding is cheap
+		if err = p.Rebuild(ctx); err == nil {
+			cursor, err = p.authors.Cursor(ctx)
+		}
+	}
+	if err != nil {
+		logger.From(ctx).Error("author projection failed to start", "error", err)
+		return
+	}
+	for ctx.Err() == nil {
+		Heartbeat(ctx)
+		next, err := p.catchUp(ctx, cursor)
+		if err != nil && ctx.Err() == nil {
+			logger.From(ctx).Error("author projection failed", "cursor", next, "error", err)
+			select {
+			case <-time.After(5 * time.Second):
+			case <-ctx.Done():
+			}
+		}
+		cursor = next
+	}
+}
+
+// catchUp applies one batch of changes after cursor and returns the new cursor.
+func (p *AuthorProjection) catchUp(ctx context.Context, cursor int64) (int64, error) {
+	changes, err := p.feed.Since(ctx, cursor, 100, authorPollWait)
+	if err != nil {
+		return cursor, err
+	}
+	for _, c := range changes {
+		if err := p.Apply(ctx, c); err != nil {
+			return cursor, err
+		}
+		cursor = c.ID
+	}
+	if len(changes) > 0 {
+		return cursor, p.authors.SaveCursor(ctx, cursor)
+	}
+	return cursor, nil
+}
@@ -0,0 +1,145 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// ---- In-memory ports.AuthorRepository over a memBookRepo ----
+
+type memAuthorRepo struct {
+	books     *memBookRepo
+	index     map[int64]string
+	summaries map[string]domain.AuthorSummary
+	cursor    int64
+}
+
+func newMemAuthorRepo(books *memBookRepo) *memAuthorRepo {
+	return &memAuthorRepo{books: books, index: map[int64]string{}, summaries: map[string]domain.AuthorSummary{}}
+}
+
+func (m *memAuthorRepo) Summary(ctx context.Context, id string) (*domain.AuthorSummary, error) {
+	s, ok := m.summaries[id]
+	if !ok {
+		return nil, nil
+	}
+	return &s, nil
+}
+func (m *memAuthorRepo) SaveSummary(ctx context.Context, s *domain.AuthorSummary) error {
+	m.summaries[s.ID] = *s
+	return nil
+}
+func (m *memAuthorRepo) DeleteSummary(ctx context.Context, id string) error {
+	delete(m.summaries, id)
+	return nil
+}
+func (m *memAuthorRepo) IndexBook(ctx context.Context, bookID int64, authorID string) (string, error) {
+	prev := m.index[bookID]
+	if authorID == "" {
+		delete(m.index, bookID)
+	} else {
+		m.index[bookID] = authorID
+	}
+	return prev, nil
+}
+func (m *memAuthorRepo) Books(ctx context.Context, authorID string) ([]domain.Book, error) {
+	out := []domain.Book{}
+	for id, a := range m.index {
+		if b, ok := m.books.books[id]; ok && a == authorID {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+func (m *memAuthorRepo) Reset(ctx context.Context) error {
+	m.index, m.summaries = map[int64]string{}, map[string]domain.AuthorSummary{}
+	return nil
+}
+func (m *memAuthorRepo) Cursor(ctx context.Context) (int64, error) { return m.cursor, nil }
+func (m *memAuthorRepo) SaveCursor(ctx context.Context, cursor int64) error {
+	m.cursor = cursor
+	return nil
+}
+
+func newAuthorFixture(books ...domain.Book) (*AuthorProjection, ports.BookService, *memAuthorRepo, *memBookRepo) {
+	repo := newMemBookRepo(books...)
+	changes := &memChangeRepo{}
+	feed := NewChangeFeed(changes)
+	authors := newMemAuthorRepo(repo)
+	p := NewAuthorProjection(authors, repo, feed)
+	return p, NewBookService(repo, WithChangeFeed(feed)), authors, repo
+}
+
+func TestAuthorProjection_Rebuild(t *testing.T) {
+	p, _, authors, _ := newAuthorFixture(
+		domain.Book{ID: 1, Title: "Cien años de soledad", Author: "Gabriel García Márquez", PublicationYear: 1967, Price: 18},
+		domain.Book{ID: 2, Title: "Love in the Time of Cholera", Author: "Gabriel Garcia Marquez", PublicationYear: 1985, Price: 14},
+		domain.Book{ID: 3, Title: "Clean Code", Author: "Robert C. Martin", PublicationYear: 2008, Price: 30},
+	)
+	p.now = func() time.Time { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	if err := p.Rebuild(context.Background()); err != nil {
+		t.Fatalf("Rebuild err: %v", err)
+	}
+	s, err := p.AuthorSummary(context.Background(), "gabriel-garcia-marquez")
+	if err != nil {
+		t.Fatalf("AuthorSummary err: %v", err)
+	}
+	if s.BookCount != 2 || *s.FirstYear != 1967 || *s.LastYear != 1985 || s.MinPrice != 14 || s.MaxPrice != 18 {
+		t.Fatalf("summary = %+v", s)
+	}
+	if len(authors.summaries) != 2 {
+		t.Fatalf("summaries = %v", authors.summaries)
+	}
+	if _, err := p.AuthorSummary(context.Background(), "nobody"); err == nil || err.Error() != "author not found" {
+		t.Fatalf("want author not found, got %v", err)
+	}
+}
+
+func TestAuthorProjection_FollowsChanges(t *testing.T) {
+	p, svc, authors, _ := newAuthorFixture(syncBook())
+	ctx := context.Background()
+	if err := p.Rebuild(ctx); err != nil {
+		t.Fatalf("Rebuild err: %v", err)
+	}
+
+	created, err := svc.CreateBook(ctx, ports.CreateBookInput{
+		Title: "The Clean Coder", Author: "Robert C. Martin", ISBN: "9780137081073", PublicationYear: 2011, Price: 25,
+	})
+	if err != nil {
+		t.Fatalf("CreateBook err: %v", err)
+	}
+	// the original book moves to another author
+	if _, err := svc.UpdateBook(ctx, 1, ports.UpdateBookInput{Author: strptr("Uncle Bob")}); err != nil {
+		t.Fatalf("UpdateBook err: %v", err)
+	}
+
+	cursor, err := p.catchUp(ctx, authors.cursor)
+	if err != nil {
+		t.Fatalf("catchUp err: %v", err)
+	}
+	if cursor != 2 || authors.cursor != 2 {
+		t.Fatalf("cursor = %d, saved %d; want 2", cursor, authors.cursor)
+	}
+	s := authors.summaries["robert-c-martin"]
+	if s.BookCount != 1 || s.Books[0].ID != created.ID {
+		t.Fatalf("robert-c-martin = %+v", s)
+	}
+	if authors.summaries["uncle-bob"].BookCount != 1 {
+		t.Fatalf("uncle-bob = %+v", authors.summaries["uncle-bob"])
+	}
+
+	if err := svc.DeleteBook(ctx, 1); err != nil {
+		t.Fatalf("DeleteBook err: %v", err)
+	}
+	if _, err := p.catchUp(ctx, cursor); err != nil {
+		t.Fatalf("catchUp err: %v", err)
+	}
+	if _, ok := authors.summaries["uncle-bob"]; ok {
+		t.Fatalf("summary of an author without books should be removed")
+	}
+}
@@ -0,0 +1,196 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// BookDecorator wraps a book service in a cross-cutting concern (logging,
+// metrics, caching, authorization, audit) without the service knowing.
+type BookDecorator func(ports.BookService) ports.BookService
+
+// DecorateBooks wraps svc in decorators. The first one is outermost and sees
+// every call first, like middleware added with Use, so main lists the
+// chain in the order a call passes through it.
+func DecorateBooks(svc ports.BookService, decorators ...BookDecorator) ports.BookService {
+	for i := len(decorators) - 1; i >= 0; i-- {
+		svc = decorators[i](svc)
+	}
+	return svc
+}
+
+// BookInterceptor runs around one book service call. op is the method
+// name, such as "CreateBook", and call runs the rest of the chain. The
+// caller gets the error the interceptor returns, so it can also refuse a
+// call without running it.
+type BookInterceptor func(ctx context.Context, op string, call func(context.Context) error) error
+
+// Intercept makes a decorator of i, for concerns that treat every method
+// alike and would otherwise need a wrapper per method.
+func Intercept(i BookInterceptor) BookDecorator {
+	return func(next ports.BookService) ports.BookService {
+		return &interceptedBooks{next: next, around: i}
+	}
+}
+
+// bookWrites are the BookService methods that change books.
+var bookWrites = map[string]bool{
+	"CreateBook": true, "UpdateBook": true, "DeleteBook": true, "SplitBook": true, "ImportBooks": true,
+}
+
+// IsBookWrite reports whether the intercepted op changes books.
+func IsBookWrite(op string) bool { return bookWrites[op] }
+
+// LogBookCalls logs every call at debug level with its duration, and
+// failed writes at warn level. Validation errors aren't failures.
+func LogBookCalls(ctx context.Context, op string, call func(context.Context) error) error {
+	start := time.Now()
+	err := call(ctx)
+	log := logger.From(ctx)
+	var ve *ValidationError
+	if err != nil && IsBookWrite(op) && !errors.As(err, &ve) {
+		log.Warn("book service call failed", "op", op, "duration", time.Since(start), "error", err)
+		return err
+	}
+	log.Debug("book service call", "op", op, "duration", time.Since(start), "error", err)
+	return err
+}
+
+// BookMetrics counts book service calls, errors and time spent per method.
+type BookMetrics struct {
+	mu  sync.Mutex
+	ops map[string]BookOpStats
+}
+
+// BookOpStats are the counters of one BookService method.
+type BookOpStats struct {
+	Calls      int64   `json:"calls"`
+	Errors     int64   `json:"errors"`
+	TotalMilli float64 `json:"total_ms"`
+}
+
+func NewBookMetrics() *BookMetrics {
+	return &BookMetrics{ops: map[string]BookOpStats{}}
+}
+
+// Observe is the BookInterceptor that records the calls.
+func (m *BookMetrics) Observe(ctx context.Context, op string, call func(context.Context) error) error {
+	start := time.Now()
+	err := call(ctx)
+	elapsed := time.Since(start)
+	m.mu.Lock()
+	s := m.ops[op]
+	s.Calls++
+	if err != nil {
+		s.Errors++
+	}
+	s.TotalMilli += float64(elapsed.Microseconds()) / 1000
+	m.ops[op] = s
+	m.mu.Unlock()
+	return err
+}
+
+// Stats returns the counters by method name.
+func (m *BookMetrics) Stats() map[string]BookOpStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return maps.Clone(m.ops)
+}
+
+// Publish exposes the counters as the expvar "book_service" (served on
+// /debug/vars). It panics if called twice, like expvar.Publish.
+func (m *BookMetrics) Publish() {
+	expvar.Publish("book_service", expvar.Func(func() any { return m.Stats() }))
+}
+
+// interceptedBooks runs every call of next through around.
+type interceptedBooks struct {
+	next   ports.BookService
+	around BookInterceptor
+}
+
+func (s *interceptedBooks) ListBooks(ctx context.Context) (books []domain.Book, err error) {
+	err = s.around(ctx, "ListBooks", func(ctx context.Context) error {
+		books, err = s.next.ListBooks(ctx)
+		return err
+	})
+	return books, err
+}
+
+func (s *interceptedBooks) SearchBooks(ctx context.Context, q string) (books []domain.Book, err error) {
+	err = s.around(ctx, "SearchBooks", func(ctx context.Context) error {
+		books, err = s.next.SearchBooks(ctx, q)
+		return err
+	})
+	return books, err
+}
+
+func (s *interceptedBooks) ListBooksPage(ctx context.Context, f ports.ListFilter, page ports.Page) (res *ports.BookPage, err error) {
+	err = s.around(ctx, "ListBooksPage", func(ctx context.Context) error {
+		res, err = s.next.ListBooksPage(ctx, f, page)
+		return err
+	})
+	return res, err
+}
+
+func (s *interceptedBooks) GetBook(ctx context.Context, id int64) (b *domain.Book, err error) {
+	err = s.around(ctx, "GetBook", func(ctx context.Context) error {
+		b, err = s.next.GetBook(ctx, id)
+		return err
+	})
+	return b, err
+}
+
+func (s *interceptedBooks) GetBookByISBN(ctx context.Context, isbn string) (b *domain.Book, err error) {
+	err = s.around(ctx, "GetBookByISBN", func(ctx context.Context) error {
+		b, err = s.next.GetBookByISBN(ctx, isbn)
+		return err
+	})
+	return b, err
+}
+
+func (s *interceptedBooks) CreateBook(ctx context.Context, in ports.CreateBookInput) (b *domain.Book, err error) {
+	err = s.around(ctx, "CreateBook", func(ctx context.Context) error {
+		b, err = s.next.CreateBook(ctx, in)
+		return err
+	})
+	return b, err
+}
+
+func (s *interceptedBooks) UpdateBook(ctx context.Context, id int64, in ports.UpdateBookInput) (b *domain.Book, err error) {
+	err = s.around(ctx, "UpdateBook", func(ctx context.Context) error {
+		b, err = s.next.UpdateBook(ctx, id, in)
+		return err
+	})
+	return b, err
+}
+
+func (s *interceptedBooks) DeleteBook(ctx context.Context, id int64) error {
+	return s.around(ctx, "DeleteBook", func(ctx context.Context) error {
+		return s.next.DeleteBook(ctx, id)
+	})
+}
+
+func (s *interceptedBooks) SplitBook(ctx context.Context, id int64, in ports.SplitBookInput) (res *ports.SplitBookResult, err error) {
+	err = s.around(ctx, "SplitBook", func(ctx context.Context) error {
+		res, err = s.next.SplitBook(ctx, id, in)
+		return err
+	})
+	return res, err
+}
+
+func (s *interceptedBooks) ImportBooks(ctx context.Context, rows []ports.ImportRow) (res *ports.ImportResult, err error) {
+	err = s.around(ctx, "ImportBooks", func(ctx context.Context) error {
+		res, err = s.next.ImportBooks(ctx, rows)
+		return err
+	})
+	return res, err
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+func TestDecorateBooks_Order(t *testing.T) {
+	var calls []string
+	trace := func(name string) BookDecorator {
+		return Intercept(func(ctx context.Context, op string, call func(context.Context) error) error {
+			calls = append(calls, name+">"+op)
+			err := call(ctx)
+			calls = append(calls, name+"<"+op)
+			return err
+		})
+	}
+	svc := DecorateBooks(NewBookService(newMemBookRepo(domain.Book{ID: 1, Title: "Ficciones"})), trace("outer"), trace("inner"))
+
+	b, err := svc.GetBook(context.Background(), 1)
+	if err != nil || b == nil || b.Title != "Ficciones" {
+		t.Fatalf("GetBook = %+v, %v", b, err)
+	}
+	if want := []string{"outer>GetBook", "inner>GetBook", "inner<GetBook", "outer<GetBook"}; !slices.Equal(calls, want) {
+		t.Fatalf("calls = %v; want %v", calls, want)
+	}
+}
+
+func TestIntercept_Refuses(t *testing.T) {
+	errFrozen := errors.New("catalogue frozen")
+	freeze := Intercept(func(ctx context.Context, op string, call func(context.Context) error) error {
+		if IsBookWrite(op) {
+			return errFrozen
+		}
+		return call(ctx)
+	})
+	repo := newMemBookRepo(domain.Book{ID: 1, Title: "Ficciones"})
+	svc := DecorateBooks(NewBookService(repo), freeze)
+
+	if _, err := svc.CreateBook(context.Background(), validCreateInput()); !errors.Is(err, errFrozen) {
+		t.Fatalf("CreateBook err = %v", err)
+	}
+	if err := svc.DeleteBook(context.Background(), 1); !errors.Is(err, errFrozen) || len(repo.books) != 1 {
+		t.Fatalf("DeleteBook err = %v, %d books", err, len(repo.books))
+	}
+	if page, err := svc.ListBooksPage(context.Background(), ports.ListFilter{}, ports.Page{}); err != nil || page.Total != 1 {
+		t.Fatalf("ListBooksPage = %+v, %v", page, err)
+	}
+}
+
+func TestBookMetrics(t *testing.T) {
+	m := NewBookMetrics()
+	svc := DecorateBooks(NewBookService(newMemBookRepo()), Intercept(LogBookCalls), Intercept(m.Observe))
+
+	_, _ = svc.ListBooks(context.Background())
+	_, _ = svc.ListBooks(context.Background())
+	_, _ = svc.CreateBook(context.Background(), ports.CreateBookInput{})
+
+	stats := m.Stats()
+	if stats["ListBooks"].Calls != 2 || stats["ListBooks"].Errors != 0 {
+		t.Fatalf("ListBooks stats = %+v", stats["ListBooks"])
+	}
+	if stats["CreateBook"].Calls != 1 || stats["CreateBook"].Errors != 1 {
+		t.Fatalf("CreateBook stats = %+v", stats["CreateBook"])
+	}
+}
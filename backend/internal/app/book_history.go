@@ -0,0 +1,77 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// BookHistory rebuilds books from the payloads of the change log: a book as
+// of t is the payload of its last change at or before t, and didn't exist
+// if that change is a delete or there is none.
+type BookHistory struct {
+	repo      ports.ChangeRepository
+	retention time.Duration
+}
+
+// NewBookHistory reads repo, whose superseded entries are compacted after
+// retention (0 if they never are). Instants older than that are refused
+// with ErrBeyondHistory, since compaction may have dropped the entry that
+// answered for them.
+func NewBookHistory(repo ports.ChangeRepository, retention time.Duration) *BookHistory {
+	return &BookHistory{repo: repo, retention: retention}
+}
+
+func (h *BookHistory) check(t time.Time) error {
+	if h.retention > 0 && t.Before(clock().Add(-h.retention)) {
+		return ports.ErrBeyondHistory
+	}
+	return nil
+}
+
+func (h *BookHistory) BookAsOf(ctx context.Context, id int64, t time.Time) (*domain.Book, error) {
+	if err := h.check(t); err != nil {
+		return nil, err
+	}
+	c, err := h.repo.BookAt(ctx, id, t)
+	if err != nil || c == nil {
+		return nil, err
+	}
+	return bookAsOf(*c)
+}
+
+func (h *BookHistory) BooksAsOf(ctx context.Context, t time.Time) ([]domain.Book, error) {
+	if err := h.check(t); err != nil {
+		return nil, err
+	}
+	changes, err := h.repo.BooksAt(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	books := []domain.Book{}
+	for _, c := range changes {
+		b, err := bookAsOf(c)
+		if err != nil {
+			return nil, err
+		}
+		if b != nil {
+			books = append(books, *b)
+		}
+	}
+	return books, nil
+}
+
+// bookAsOf is the book a change left behind, nil after a delete.
+func bookAsOf(c domain.Change) (*domain.Book, error) {
+	if c.Op == domain.ChangeDeleted {
+		return nil, nil
+	}
+	b, err := c.Book()
+	if err == nil && b == nil {
+		// written before payloads were recorded
+		return nil, ports.ErrBeyondHistory
+	}
+	return b, err
+}
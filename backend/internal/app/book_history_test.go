@@ -0,0 +1,55 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+func TestBookHistory_AsOf(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 12, 0, 0, 0, time.UTC) }
+	SetClock(func() time.Time { return day(20) })
+	t.Cleanup(func() { SetClock(time.Now) })
+
+	repo := &memChangeRepo{}
+	record := func(d int, id int64, op string, b *domain.Book) {
+		payload, _ := domain.NewChangePayload(b)
+		_, _ = repo.Append(context.Background(), &domain.Change{BookID: id, Op: op, Payload: payload, CreatedAt: day(d)})
+	}
+	record(1, 1, domain.ChangeCreated, &domain.Book{ID: 1, Title: "Dune", Price: 10})
+	record(3, 1, domain.ChangeUpdated, &domain.Book{ID: 1, Title: "Dune", Price: 12})
+	record(4, 2, domain.ChangeCreated, &domain.Book{ID: 2, Title: "Emma", Price: 8})
+	record(6, 2, domain.ChangeDeleted, nil)
+	record(7, 3, domain.ChangeCreated, nil) // from before payloads were recorded
+
+	h := NewBookHistory(repo, 30*24*time.Hour)
+	ctx := context.Background()
+	for _, c := range []struct {
+		day   int
+		id    int64
+		price float64 // 0: didn't exist
+	}{{1, 1, 10}, {2, 1, 10}, {3, 1, 12}, {19, 1, 12}, {5, 2, 8}, {6, 2, 0}, {1, 2, 0}} {
+		b, err := h.BookAsOf(ctx, c.id, day(c.day))
+		if err != nil {
+			t.Fatalf("BookAsOf(%d, day %d): %v", c.id, c.day, err)
+		}
+		if (b == nil) != (c.price == 0) || (b != nil && b.Price != c.price) {
+			t.Errorf("BookAsOf(%d, day %d) = %+v; want price %v", c.id, c.day, b, c.price)
+		}
+	}
+
+	books, err := h.BooksAsOf(ctx, day(5))
+	if err != nil || len(books) != 2 || books[0].ID != 2 || books[1].Price != 12 {
+		t.Fatalf("BooksAsOf(day 5) = %+v, %v", books, err)
+	}
+	if _, err := h.BooksAsOf(ctx, day(8)); !errors.Is(err, ports.ErrBeyondHistory) {
+		t.Fatalf("BooksAsOf over a payload-less entry = %v; want ErrBeyondHistory", err)
+	}
+	if _, err := NewBookHistory(repo, 7*24*time.Hour).BookAsOf(ctx, 1, day(3)); !errors.Is(err, ports.ErrBeyondHistory) {
+		t.Fatalf("BookAsOf before retention = %v; want ErrBeyondHistory", err)
+	}
+}
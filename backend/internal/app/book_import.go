@@ -0,0 +1,131 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// MaxImportRows bounds a single import; larger files must be split.
+const MaxImportRows = 5000
+
+// KindBookImport dead letters hold the rows of an import cut off by a
+// shutdown.
+const KindBookImport = "book_import"
+
+// ImportBooks validates every row like CreateBook and inserts the valid ones
+// in order. Rows whose ISBN is already in the catalogue, or was inserted by
+// an earlier row of the same import, are skipped rather than failed so a
+// partially applied import can simply be sent again. When ctx is cancelled
+// with ports.ErrDraining the import stops at the next row, and the rows left
+// are requeued.
+func (s *bookService) ImportBooks(ctx context.Context, rows []ports.ImportRow) (*ports.ImportResult, error) {
+	if len(rows) > MaxImportRows {
+		errs := &ValidationError{}
+		errs.add("file", fmt.Sprintf("Import must have at most %d rows", MaxImportRows))
+		return nil, errs
+	}
+
+	res := &ports.ImportResult{Rows: make([]ports.ImportRowResult, 0, len(rows))}
+	seen := map[string]bool{}
+	for i, row := range rows {
+		if errors.Is(context.Cause(ctx), ports.ErrDraining) {
+			s.requeueImport(ctx, rows[i:], res)
+			break
+		}
+		r := s.importRow(ctx, row, seen)
+		switch r.Status {
+		case ports.ImportInserted:
+			res.Inserted++
+		case ports.ImportSkipped:
+			res.Skipped++
+		default:
+			res.Failed++
+		}
+		res.Rows = append(res.Rows, r)
+	}
+	return res, nil
+}
+
+func (s *bookService) importRow(ctx context.Context, row ports.ImportRow, seen map[string]bool) ports.ImportRowResult {
+	r := ports.ImportRowResult{Row: row.Row, Status: ports.ImportFailed, ISBN: row.Book.ISBN}
+	in, err := validateAndNormalizeCreate(row.Book)
+	if err != nil || len(row.Errors) > 0 {
+		// parse errors win over the validation of the zero value they left
+		r.Error, r.Fields = "validation error", map[string]string{}
+		if ve, ok := err.(*ValidationError); ok {
+			for k, v := range ve.Fields {
+				r.Fields[k] = v
+			}
+		}
+		for k, v := range row.Errors {
+			r.Fields[k] = v
+		}
+		return r
+	}
+	r.ISBN = in.ISBN
+
+	if seen[in.ISBN] {
+		r.Status, r.Error = ports.ImportSkipped, "duplicate ISBN earlier in the import"
+		return r
+	}
+	existing, err := s.repo.List(ctx, ports.ListFilter{ISBN: isbnLookupKey(in.ISBN)})
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+	if len(existing) > 0 {
+		r.Status, r.ID, r.Error = ports.ImportSkipped, existing[0].ID, "ISBN already exists"
+		return r
+	}
+
+	book, err := s.CreateBook(ctx, in)
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+	seen[in.ISBN] = true
+	r.Status, r.ID = ports.ImportInserted, book.ID
+	return r
+}
+
+// requeueImport parks rows as a dead letter so a retry imports them, or fails
+// them when there is nowhere to park them.
+func (s *bookService) requeueImport(ctx context.Context, rows []ports.ImportRow, res *ports.ImportResult) {
+	status, msg := ports.ImportRequeued, ""
+	if s.dlq == nil {
+		status, msg = ports.ImportFailed, ports.ErrDraining.Error()
+	} else if err := s.dlq.Park(context.WithoutCancel(ctx), KindBookImport, rows, ports.ErrDraining); err != nil {
+		status, msg = ports.ImportFailed, err.Error()
+	}
+	for _, row := range rows {
+		res.Rows = append(res.Rows, ports.ImportRowResult{Row: row.Row, Status: status, ISBN: row.Book.ISBN, Error: msg})
+	}
+	if status == ports.ImportRequeued {
+		res.Requeued += len(rows)
+	} else {
+		res.Failed += len(rows)
+	}
+}
+
+// retryImport imports requeued rows. Rows failing validation won't pass on
+// another retry either, so only other failures fail the retry.
+func (s *bookService) retryImport(ctx context.Context, payload []byte) error {
+	var rows []ports.ImportRow
+	if err := json.Unmarshal(payload, &rows); err != nil {
+		return err
+	}
+	res, err := s.ImportBooks(ctx, rows)
+	if err != nil {
+		return err
+	}
+	for _, r := range res.Rows {
+		if r.Status == ports.ImportFailed && r.Fields == nil {
+			return fmt.Errorf("row %d: %s", r.Row, r.Error)
+		}
+	}
+	return nil
+}
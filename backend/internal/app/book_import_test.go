@@ -0,0 +1,84 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+func TestImportBooks(t *testing.T) {
+	repo := newMemBookRepo(domain.Book{ID: 1, Title: "Ficciones", Author: "Jorge Luis Borges", ISBN: "9780802130303", ISBN10: "0802130305"})
+	svc := NewBookService(repo)
+
+	valid := ports.CreateBookInput{Title: "Refactoring", Author: "Martin Fowler", ISBN: "978-0-201-48567-7", Price: 40, PublicationYear: 1999}
+	res, err := svc.ImportBooks(context.Background(), []ports.ImportRow{
+		{Row: 1, Book: valid},
+		{Row: 2, Book: valid},
+		{Row: 3, Book: ports.CreateBookInput{Title: "Ficciones", Author: "Borges", ISBN: "978-0-8021-3030-3", PublicationYear: 1944}},
+		{Row: 4, Book: ports.CreateBookInput{Title: "No ISBN", Author: "X", PublicationYear: 2000}},
+		{Row: 5, Book: ports.CreateBookInput{Title: "T", Author: "A", ISBN: "9780132350884", PublicationYear: 2008},
+			Errors: map[string]string{"price": "Price must be a number"}},
+	})
+	if err != nil {
+		t.Fatalf("ImportBooks err: %v", err)
+	}
+	if res.Inserted != 1 || res.Skipped != 2 || res.Failed != 2 || len(res.Rows) != 5 {
+		t.Fatalf("summary = %+v", res)
+	}
+	want := []string{ports.ImportInserted, ports.ImportSkipped, ports.ImportSkipped, ports.ImportFailed, ports.ImportFailed}
+	for i, r := range res.Rows {
+		if r.Status != want[i] || r.Row != i+1 {
+			t.Fatalf("row %d = %+v; want %s", i+1, r, want[i])
+		}
+	}
+	if res.Rows[0].ID == 0 || res.Rows[0].ISBN != "9780201485677" || res.Rows[2].ID != 1 {
+		t.Fatalf("ids = %+v", res.Rows)
+	}
+	if res.Rows[3].Fields["isbn"] == "" || res.Rows[4].Fields["price"] == "" {
+		t.Fatalf("fields = %+v / %+v", res.Rows[3].Fields, res.Rows[4].Fields)
+	}
+	if len(repo.books) != 2 {
+		t.Fatalf("repo has %d books; want 2", len(repo.books))
+	}
+
+	_, err = svc.ImportBooks(context.Background(), make([]ports.ImportRow, MaxImportRows+1))
+	if ve, ok := err.(*ValidationError); !ok || ve.Fields["file"] == "" {
+		t.Fatalf("want file validation error, got %v", err)
+	}
+}
+
+func TestImportBooks_RequeuesRowsCutOffByShutdown(t *testing.T) {
+	repo := newMemBookRepo()
+	dlq := NewDeadLetters(&memDeadLetterRepo{})
+	svc := NewBookService(repo, WithImportRequeue(dlq))
+	rows := []ports.ImportRow{
+		{Row: 1, Book: ports.CreateBookInput{Title: "Refactoring", Author: "Martin Fowler", ISBN: "9780201485677", Price: 40, PublicationYear: 1999}},
+		{Row: 2, Book: ports.CreateBookInput{Title: "Clean Code", Author: "Robert C. Martin", ISBN: "9780132350884", Price: 30, PublicationYear: 2008}},
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(ports.ErrDraining)
+	res, err := svc.ImportBooks(ctx, rows)
+	if err != nil || res.Requeued != 2 || res.Inserted != 0 || res.Rows[1].Status != ports.ImportRequeued {
+		t.Fatalf("ImportBooks = %+v, %v", res, err)
+	}
+	letters, _ := dlq.ListDeadLetters(context.Background(), KindBookImport, 0)
+	if len(letters) != 1 || len(repo.books) != 0 {
+		t.Fatalf("dead letters = %+v, books = %d", letters, len(repo.books))
+	}
+
+	if err := dlq.RetryDeadLetter(context.Background(), letters[0].ID); err != nil {
+		t.Fatalf("Retry = %v", err)
+	}
+	if len(repo.books) != 2 {
+		t.Fatalf("books after retry = %d; want 2", len(repo.books))
+	}
+
+	// without a dead letter queue the rows fail instead
+	res, _ = NewBookService(newMemBookRepo()).ImportBooks(ctx, rows)
+	if res.Failed != 2 || res.Rows[0].Error != ports.ErrDraining.Error() {
+		t.Fatalf("ImportBooks without requeue = %+v", res)
+	}
+}
@@ -2,53 +2,317 @@ package app
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
 	"github.com/gerry-sabar/byfood/internal/ports"
 )
 
 type bookService struct {
-	repo ports.BookRepository
+	repo     ports.BookReader
+	writer   ports.BookWriter
+	changes  *ChangeFeed
+	aliases  ports.AliasRepository
+	dlq      *DeadLetters
+	guard    *QueryGuard
+	meta     ports.MetadataService
+	synonyms ports.SynonymExpander
+	prices   ports.PriceRepository
+	events   ports.EventPublisher
+	eventDLQ *DeadLetters
 }
 
-func NewBookService(repo ports.BookRepository) ports.BookService {
-	return &bookService{repo: repo}
+// Option configures optional collaborators of the book service.
+type Option func(*bookService)
+
+// WithChangeFeed records every successful mutation in the change log.
+func WithChangeFeed(f *ChangeFeed) Option {
+	return func(s *bookService) { s.changes = f }
+}
+
+// WithPriceHistory records the price changes of book updates in the price
+// history that re-pricing also writes to.
+func WithPriceHistory(r ports.PriceRepository) Option {
+	return func(s *bookService) { s.prices = r }
+}
+
+// WithAliases lets SplitBook move aliases to the new edition.
+func WithAliases(r ports.AliasRepository) Option {
+	return func(s *bookService) { s.aliases = r }
+}
+
+// WithImportRequeue parks the rows an import did not reach before a shutdown
+// in d, from where they are imported on retry, instead of failing them.
+func WithImportRequeue(d *DeadLetters) Option {
+	return func(s *bookService) {
+		s.dlq = d
+		d.Register(KindBookImport, s.retryImport)
+	}
+}
+
+// WithQueryGuard rejects filtered lists and searches that would scan a large
+// catalogue.
+func WithQueryGuard(g *QueryGuard) Option {
+	return func(s *bookService) { s.guard = g }
+}
+
+// WithMetadata lets CreateBook fill in books created with autofill.
+func WithMetadata(m ports.MetadataService) Option {
+	return func(s *bookService) { s.meta = m }
+}
+
+// WithSynonyms lets searches also find books matching the synonyms of their
+// terms.
+func WithSynonyms(e ports.SynonymExpander) Option {
+	return func(s *bookService) { s.synonyms = e }
+}
+
+// WithEventPublisher publishes the event of every change the book service
+// records in the change log to p, e.g. a Kafka topic.
+func WithEventPublisher(p ports.EventPublisher) Option {
+	return func(s *bookService) { s.events = p }
+}
+
+// WithEventRequeue parks the events the publisher failed to take in d, from
+// where a retry publishes them again.
+func WithEventRequeue(d *DeadLetters) Option {
+	return func(s *bookService) {
+		s.eventDLQ = d
+		d.Register(KindEventPublish, s.retryEvent)
+	}
+}
+
+func NewBookService(repo ports.BookRepository, opts ...Option) ports.BookService {
+	return newBookService(repo, repo, opts)
+}
+
+// NewReadOnlyBookService serves books from books alone. Every write fails
+// with ports.ErrReadOnly, so read-only deployments such as a NATS responder
+// on a replica need no write wiring.
+func NewReadOnlyBookService(books ports.BookReader, opts ...Option) ports.BookService {
+	return newBookService(books, readOnlyBooks{}, opts)
+}
+
+func newBookService(r ports.BookReader, w ports.BookWriter, opts []Option) *bookService {
+	s := &bookService{repo: r, writer: w}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// readOnlyBooks is the BookWriter of a read-only book service.
+type readOnlyBooks struct{}
+
+func (readOnlyBooks) Create(context.Context, *domain.Book) (int64, error) {
+	return 0, ports.ErrReadOnly
+}
+
+func (readOnlyBooks) Update(context.Context, *domain.Book, time.Time) error {
+	return ports.ErrReadOnly
+}
+
+func (readOnlyBooks) Delete(context.Context, int64) error { return ports.ErrReadOnly }
+
+func (readOnlyBooks) Split(context.Context, *domain.Book, time.Time, *domain.Book, []int64) (int64, error) {
+	return 0, ports.ErrReadOnly
 }
 
 func (s *bookService) ListBooks(ctx context.Context) ([]domain.Book, error) {
-	return s.repo.List(ctx)
+	return s.repo.List(ctx, ports.ListFilter{})
+}
+
+// SearchBooks matches q against title and author, ignoring case and accents,
+// along with the synonyms of its terms where synonyms are enabled. A blank q
+// lists every book.
+func (s *bookService) SearchBooks(ctx context.Context, q string) ([]domain.Book, error) {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return s.repo.List(ctx, ports.ListFilter{})
+	}
+	if err := s.checkCost(ctx, ports.ListFilter{Q: q}); err != nil {
+		return nil, err
+	}
+	if syn := s.expand(ctx, q); len(syn) > 0 {
+		return s.repo.List(ctx, ports.ListFilter{Q: q, Synonyms: syn})
+	}
+	return s.repo.Search(ctx, q)
+}
+
+func (s *bookService) ListBooksPage(ctx context.Context, f ports.ListFilter, page ports.Page) (*ports.BookPage, error) {
+	errs := &ValidationError{}
+	validateListFilter(errs, f)
+	if page.Limit < 0 {
+		errs.add("limit", "Limit must not be negative")
+	}
+	if page.Offset < 0 {
+		errs.add("offset", "Offset must not be negative")
+	}
+	validateSort(errs, &page.Sort)
+	if !errs.ok() {
+		return nil, errs
+	}
+	f.Q, f.Author, f.ISBN, f.Tag = strings.TrimSpace(f.Q), strings.TrimSpace(f.Author), strings.TrimSpace(f.ISBN), strings.TrimSpace(f.Tag)
+	if f.ISBN != "" && !f.Exact {
+		f.ISBN = isbnLookupKey(f.ISBN)
+	}
+	if err := s.checkCost(ctx, f); err != nil {
+		return nil, err
+	}
+	if f.Q != "" && !f.Exact {
+		f.Synonyms = s.expand(ctx, f.Q)
+	}
+	books, total, err := s.repo.ListPage(ctx, f, page)
+	if err != nil {
+		return nil, err
+	}
+	return &ports.BookPage{Books: books, Total: total}, nil
+}
+
+// expand returns the synonyms of search q. Searches go ahead without
+// synonyms when the dictionary can't be read.
+func (s *bookService) expand(ctx context.Context, q string) []string {
+	if s.synonyms == nil {
+		return nil
+	}
+	syn, err := s.synonyms.Expand(ctx, q)
+	if err != nil {
+		logger.From(ctx).Warn("failed to expand synonyms", "q", q, "error", err)
+		return nil
+	}
+	return syn
+}
+
+func (s *bookService) checkCost(ctx context.Context, f ports.ListFilter) error {
+	if s.guard == nil {
+		return nil
+	}
+	return s.guard.Check(ctx, f)
+}
+
+func validateSort(errs *ValidationError, srt *ports.Sort) {
+	srt.Collation = strings.ToLower(strings.TrimSpace(srt.Collation))
+	if srt.Field != "" && !slices.Contains(ports.SortFields, srt.Field) {
+		errs.add("sort", "Sort must be one of "+strings.Join(ports.SortFields, ", "))
+	}
+	switch {
+	case srt.Collation == "":
+	case !slices.Contains(ports.SortCollations, srt.Collation):
+		errs.add("collation", "Collation must be one of "+strings.Join(ports.SortCollations, ", "))
+	case srt.Field != "title" && srt.Field != "author":
+		errs.add("collation", "Collation only applies to sorting by title or author")
+	}
+}
+
+func validateListFilter(errs *ValidationError, f ports.ListFilter) {
+	if f.YearFrom != nil && f.YearTo != nil && *f.YearFrom > *f.YearTo {
+		errs.add("year_from", "year_from must not be after year_to")
+	}
+	if f.PriceMin != nil && *f.PriceMin < 0 {
+		errs.add("price_min", "price_min must not be negative")
+	}
+	if f.PriceMax != nil && *f.PriceMax < 0 {
+		errs.add("price_max", "price_max must not be negative")
+	}
+	if f.PriceMin != nil && f.PriceMax != nil && *f.PriceMin > *f.PriceMax {
+		errs.add("price_min", "price_min must not exceed price_max")
+	}
 }
 
 func (s *bookService) GetBook(ctx context.Context, id int64) (*domain.Book, error) {
 	return s.repo.GetByID(ctx, id)
 }
 
+// autofill fills the blank title, author and year of in from the metadata
+// of its ISBN. Lookup failures leave in as it is, for validation to report
+// what is still missing.
+func (s *bookService) autofill(ctx context.Context, in ports.CreateBookInput) ports.CreateBookInput {
+	if s.meta == nil || !isValidISBN(in.ISBN) {
+		return in
+	}
+	md, err := s.meta.LookupBook(ctx, in.ISBN)
+	if err != nil || md == nil {
+		logger.From(ctx).Info("autofill found nothing", "isbn", in.ISBN, "error", err)
+		return in
+	}
+	if strings.TrimSpace(in.Title) == "" {
+		in.Title = md.Title
+	}
+	if strings.TrimSpace(in.Author) == "" {
+		in.Author = md.Author
+	}
+	if in.PublicationYear == 0 {
+		in.PublicationYear = md.PublicationYear
+	}
+	return in
+}
+
+// GetBookByISBN finds the book with isbn in either form.
+func (s *bookService) GetBookByISBN(ctx context.Context, isbn string) (*domain.Book, error) {
+	if _, _, err := CanonicalISBN(isbn); err != nil {
+		return nil, &ValidationError{Fields: map[string]string{"isbn": "Invalid ISBN (must be ISBN-10 or ISBN-13)"}}
+	}
+	books, err := s.repo.List(ctx, ports.ListFilter{ISBN: isbnLookupKey(isbn)})
+	if err != nil || len(books) == 0 {
+		return nil, err
+	}
+	return &books[0], nil
+}
+
 func (s *bookService) CreateBook(ctx context.Context, in ports.CreateBookInput) (*domain.Book, error) {
+	if in.Autofill {
+		in = s.autofill(ctx, in)
+	}
 	inNorm, err := validateAndNormalizeCreate(in)
 	if err != nil {
 		return nil, err
 	}
 
-	now := time.Now().UTC()
+	var workID *int64
+	if inNorm.WorkID != nil {
+		w, err := s.workOf(ctx, *inNorm.WorkID)
+		if err != nil {
+			return nil, err
+		}
+		workID = &w
+	}
+
+	now := clock().UTC()
 	book := &domain.Book{
 		Title:           inNorm.Title,
 		Author:          inNorm.Author,
-		ISBN:            inNorm.ISBN, // normalized
+		ISBN:            inNorm.ISBN, // ISBN-13
 		PublicationYear: inNorm.PublicationYear,
 		Price:           inNorm.Price,
+		PublisherID:     inNorm.PublisherID,
+		SeriesID:        inNorm.SeriesID,
+		SeriesPosition:  inNorm.SeriesPosition,
+		Format:          inNorm.Format,
 		CreatedAt:       now,
 		UpdatedAt:       now,
+		Version:         1,
+		WorkID:          workID,
 	}
-	id, err := s.repo.Create(ctx, book)
+	_, book.ISBN10, _ = CanonicalISBN(book.ISBN)
+	book.FieldUpdatedAt.Touch(now, domain.BookFields...)
+	id, err := s.writer.Create(ctx, book)
 	if err != nil {
-		return nil, err
+		return nil, unknownReference(err, "")
 	}
 	book.ID = id
+	s.recordChange(ctx, id, domain.ChangeCreated, book)
 	return book, nil
 }
 
+// maxUpdateAttempts bounds the read-merge-write retries of UpdateBook when
+// other writers keep winning the race.
+const maxUpdateAttempts = 3
+
 func (s *bookService) UpdateBook(ctx context.Context, id int64, in ports.UpdateBookInput) (*domain.Book, error) {
 	existing, err := s.repo.GetByID(ctx, id)
 	if err != nil {
@@ -62,30 +326,255 @@ func (s *bookService) UpdateBook(ctx context.Context, id int64, in ports.UpdateB
 	if err != nil {
 		return nil, err
 	}
+	if inNorm.WorkID != nil && *inNorm.WorkID != 0 {
+		w, err := s.workOf(ctx, *inNorm.WorkID)
+		if err != nil {
+			return nil, err
+		}
+		inNorm.WorkID = &w
+	}
 
-	if inNorm.Title != nil {
-		existing.Title = *inNorm.Title
+	var oldPrice float64
+	var now time.Time
+	for attempt := 1; ; attempt++ {
+		if in.Version != nil && *in.Version != existing.Version {
+			return nil, &ConflictError{Fields: []string{"version"}, Version: existing.Version}
+		}
+		changed := changedFields(existing, inNorm)
+		if err := checkFieldConflicts(existing, changed, inNorm.BaseUpdatedAt); err != nil {
+			return nil, err
+		}
+		if slices.Contains(changed, "work_id") {
+			if err := s.checkLeavesWork(ctx, existing); err != nil {
+				return nil, err
+			}
+		}
+
+		prev := existing.UpdatedAt
+		oldPrice, now = existing.Price, clock().UTC()
+		applyUpdate(existing, inNorm)
+		if existing.SeriesPosition != nil && existing.SeriesID == nil {
+			errs := &ValidationError{}
+			errs.add("series_position", "Series position needs a series_id")
+			return nil, errs
+		}
+		existing.FieldUpdatedAt.Touch(now, changed...)
+		existing.UpdatedAt = now
+
+		err := s.writer.Update(ctx, existing, prev)
+		if errors.Is(err, ports.ErrConcurrentUpdate) && attempt < maxUpdateAttempts {
+			// someone else wrote in between: merge on top of their version
+			if existing, err = s.repo.GetByID(ctx, id); err != nil {
+				return nil, err
+			}
+			if existing == nil {
+				return nil, errors.New("book not found")
+			}
+			continue
+		}
+		if err != nil {
+			return nil, unknownReference(err, "")
+		}
+		break
+	}
+	s.recordChange(ctx, id, domain.ChangeUpdated, existing)
+	if existing.Price != oldPrice {
+		s.recordPrice(ctx, id, oldPrice, existing.Price, now)
+	}
+	return existing, nil
+}
+
+func applyUpdate(b *domain.Book, in ports.UpdateBookInput) {
+	if in.Title != nil {
+		b.Title = *in.Title
+	}
+	if in.Author != nil {
+		b.Author = *in.Author
+	}
+	if in.ISBN != nil {
+		b.ISBN = *in.ISBN // ISBN-13
+		_, b.ISBN10, _ = CanonicalISBN(b.ISBN)
 	}
-	if inNorm.Author != nil {
-		existing.Author = *inNorm.Author
+	if in.PublicationYear != nil {
+		b.PublicationYear = *in.PublicationYear
 	}
-	if inNorm.ISBN != nil {
-		existing.ISBN = *inNorm.ISBN // normalized
+	if in.Price != nil {
+		b.Price = *in.Price
 	}
-	if inNorm.PublicationYear != nil {
-		existing.PublicationYear = *inNorm.PublicationYear
+	if in.PublisherID != nil {
+		b.PublisherID = nil
+		if id := *in.PublisherID; id != 0 {
+			b.PublisherID = &id
+		}
 	}
-	if inNorm.Price != nil {
-		existing.Price = *inNorm.Price
+	if in.SeriesID != nil {
+		b.SeriesID = nil
+		if id := *in.SeriesID; id != 0 {
+			b.SeriesID = &id
+		} else {
+			b.SeriesPosition = nil
+		}
 	}
-	existing.UpdatedAt = time.Now().UTC()
+	if in.SeriesPosition != nil {
+		b.SeriesPosition = nil
+		if n := *in.SeriesPosition; n != 0 {
+			b.SeriesPosition = &n
+		}
+	}
+	if in.Format != nil {
+		b.Format = *in.Format
+	}
+	if in.WorkID != nil {
+		b.WorkID = nil
+		if id := *in.WorkID; id != 0 {
+			b.WorkID = &id
+		}
+	}
+}
 
-	if err := s.repo.Update(ctx, existing); err != nil {
-		return nil, err
+// workOf returns the work_id the editions of book id share, for a work_id
+// given as the id of any edition.
+func (s *bookService) workOf(ctx context.Context, id int64) (int64, error) {
+	b, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return 0, err
 	}
-	return existing, nil
+	if b == nil {
+		errs := &ValidationError{}
+		errs.add("work_id", "No book has this id")
+		return 0, errs
+	}
+	if b.WorkID != nil {
+		return *b.WorkID, nil
+	}
+	return b.ID, nil
+}
+
+// checkLeavesWork refuses to move b to another work while other editions
+// share b's id as their work_id: they would stay behind in a work whose
+// original edition left.
+func (s *bookService) checkLeavesWork(ctx context.Context, b *domain.Book) error {
+	editions, err := s.repo.List(ctx, ports.ListFilter{WorkID: b.ID})
+	if err != nil {
+		return err
+	}
+	for _, e := range editions {
+		if e.ID != b.ID {
+			errs := &ValidationError{}
+			errs.add("work_id", "Other editions belong to this book's work; move them first")
+			return errs
+		}
+	}
+	return nil
+}
+
+// idOrZero is the value of an optional id or position, 0 when unset.
+func idOrZero[T int | int64](p *T) T {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
+
+// unknownReference reports a write that named a publisher or series which
+// doesn't exist as an invalid field, under prefix, passing other errors
+// through.
+func unknownReference(err error, prefix string) error {
+	errs := &ValidationError{}
+	switch {
+	case errors.Is(err, ports.ErrUnknownPublisher):
+		errs.add(prefix+"publisher_id", "No publisher has this id")
+	case errors.Is(err, ports.ErrUnknownSeries):
+		errs.add(prefix+"series_id", "No series has this id")
+	default:
+		return err
+	}
+	return errs
 }
 
 func (s *bookService) DeleteBook(ctx context.Context, id int64) error {
-	return s.repo.Delete(ctx, id)
+	if err := s.writer.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.recordChange(ctx, id, domain.ChangeDeleted, nil)
+	return nil
+}
+
+// recordChange appends to the change log when one is configured, and
+// publishes the entry's event. The mutation already succeeded at this point,
+// so failures are logged rather than returned.
+func (s *bookService) recordChange(ctx context.Context, id int64, op string, book *domain.Book) {
+	if s.changes == nil {
+		return
+	}
+	c, err := s.changes.record(ctx, id, op, book)
+	if err != nil {
+		logger.From(ctx).Error("failed to record book change", "id", id, "op", op, "error", err)
+		return
+	}
+	s.publishEvent(ctx, domain.EventOf(c))
+}
+
+// eventPublishTimeout bounds the publish following a write, so a broker that
+// doesn't answer delays writes by at most this much.
+const eventPublishTimeout = 2 * time.Second
+
+// KindEventPublish dead letters hold an event the broker didn't take.
+const KindEventPublish = "event_publish"
+
+// publishEvent hands e to the event publisher when one is configured. Like
+// the change log entry it follows the write, so a failure doesn't fail the
+// write: the event is parked for a retry when there is somewhere to park it,
+// and logged otherwise.
+func (s *bookService) publishEvent(ctx context.Context, e domain.Event) {
+	if s.events == nil {
+		return
+	}
+	// a caller hanging up after the write doesn't take the event with it
+	ctx = context.WithoutCancel(ctx)
+	pctx, cancel := context.WithTimeout(ctx, eventPublishTimeout)
+	defer cancel()
+	err := s.events.Publish(pctx, e)
+	if err == nil {
+		return
+	}
+	if s.eventDLQ != nil {
+		perr := s.eventDLQ.Park(ctx, KindEventPublish, e, err)
+		if perr == nil {
+			return
+		}
+		err = errors.Join(err, perr)
+	}
+	logger.From(ctx).Error("failed to publish book event", "offset", e.Offset, "type", e.Type, "id", e.EntityID, "error", err)
+}
+
+// retryEvent publishes a parked event again. Consumers may by then have
+// later events of the same book; its version tells them it is older.
+func (s *bookService) retryEvent(ctx context.Context, payload []byte) error {
+	if s.events == nil {
+		return errors.New("event publishing is not configured")
+	}
+	var e domain.Event
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, eventPublishTimeout)
+	defer cancel()
+	return s.events.Publish(ctx, e)
+}
+
+// recordPrice appends a price change of book id to its price history. Like
+// the change log entry, it follows the write: a failure is logged rather
+// than failing an update that already happened.
+func (s *bookService) recordPrice(ctx context.Context, id int64, oldPrice, newPrice float64, at time.Time) {
+	if s.prices == nil {
+		return
+	}
+	c := domain.PriceChange{BookID: id, OldPrice: oldPrice, NewPrice: newPrice, Reason: "update", ChangedAt: at}
+	if a, ok := domain.ActorFrom(ctx); ok {
+		c.ChangedBy = a.ID
+	}
+	if err := s.prices.RecordPrice(ctx, c); err != nil {
+		logger.From(ctx).Error("failed to record price change", "id", id, "error", err)
+	}
 }
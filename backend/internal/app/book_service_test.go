@@ -14,28 +14,59 @@ import (
 
 type mockRepo struct {
 	ListFn    func(ctx context.Context) ([]domain.Book, error)
+	SearchFn  func(ctx context.Context, q string) ([]domain.Book, error)
+	PageFn    func(ctx context.Context, f ports.ListFilter, page ports.Page) ([]domain.Book, int, error)
 	GetByIDFn func(ctx context.Context, id int64) (*domain.Book, error)
 	CreateFn  func(ctx context.Context, b *domain.Book) (int64, error)
 	UpdateFn  func(ctx context.Context, b *domain.Book) error
 	DeleteFn  func(ctx context.Context, id int64) error
+	SplitFn   func(ctx context.Context, src *domain.Book, prev time.Time, ed *domain.Book, aliasIDs []int64) (int64, error)
 }
 
-func (m *mockRepo) List(ctx context.Context) ([]domain.Book, error) { return m.ListFn(ctx) }
+func (m *mockRepo) List(ctx context.Context, f ports.ListFilter) ([]domain.Book, error) {
+	return m.ListFn(ctx)
+}
+func (m *mockRepo) Search(ctx context.Context, q string) ([]domain.Book, error) {
+	return m.SearchFn(ctx, q)
+}
+func (m *mockRepo) ListPage(ctx context.Context, f ports.ListFilter, page ports.Page) ([]domain.Book, int, error) {
+	return m.PageFn(ctx, f, page)
+}
 func (m *mockRepo) GetByID(ctx context.Context, id int64) (*domain.Book, error) {
 	return m.GetByIDFn(ctx, id)
 }
 func (m *mockRepo) Create(ctx context.Context, b *domain.Book) (int64, error) {
 	return m.CreateFn(ctx, b)
 }
-func (m *mockRepo) Update(ctx context.Context, b *domain.Book) error { return m.UpdateFn(ctx, b) }
-func (m *mockRepo) Delete(ctx context.Context, id int64) error       { return m.DeleteFn(ctx, id) }
+func (m *mockRepo) Update(ctx context.Context, b *domain.Book, prev time.Time) error {
+	return m.UpdateFn(ctx, b)
+}
+func (m *mockRepo) Delete(ctx context.Context, id int64) error { return m.DeleteFn(ctx, id) }
+func (m *mockRepo) Split(ctx context.Context, src *domain.Book, prev time.Time, ed *domain.Book, aliasIDs []int64) (int64, error) {
+	return m.SplitFn(ctx, src, prev, ed, aliasIDs)
+}
 
 // ---- Small helpers ----
 
 func f64ptr(v float64) *float64 { return &v }
 func strptr(s string) *string   { return &s }
+func i64ptr(i int64) *int64     { return &i }
 func iptr(i int) *int           { return &i }
 
+func validCreateInput() ports.CreateBookInput {
+	return ports.CreateBookInput{
+		Title:           "Clean Code",
+		Author:          "Robert C. Martin",
+		ISBN:            "9780132350884",
+		PublicationYear: 2008,
+		Price:           33.50,
+	}
+}
+
+func updateTitle(title string) ports.UpdateBookInput {
+	return ports.UpdateBookInput{Title: strptr(title)}
+}
+
 // ---- Tests ----
 
 func TestListBooks_OK(t *testing.T) {
@@ -55,6 +86,110 @@ func TestListBooks_OK(t *testing.T) {
 	}
 }
 
+func TestSearchBooks_AccentInsensitive(t *testing.T) {
+	repo := newMemBookRepo(
+		domain.Book{ID: 1, Title: "Cien años de soledad", Author: "Gabriel García Márquez"},
+		domain.Book{ID: 2, Title: "Clean Code", Author: "Robert C. Martin"},
+	)
+	svc := NewBookService(repo)
+
+	got, err := svc.SearchBooks(context.Background(), "Garcia Marquez")
+	if err != nil {
+		t.Fatalf("SearchBooks err: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != 1 {
+		t.Fatalf("unexpected: %+v", got)
+	}
+
+	all, _ := svc.SearchBooks(context.Background(), "   ")
+	if len(all) != 2 {
+		t.Fatalf("blank query should list everything, got %+v", all)
+	}
+}
+
+func TestListBooksPage(t *testing.T) {
+	books := []domain.Book{}
+	for i := int64(1); i <= 5; i++ {
+		books = append(books, domain.Book{ID: i, Title: "Book", Author: "A"})
+	}
+	svc := NewBookService(newMemBookRepo(books...))
+
+	got, err := svc.ListBooksPage(context.Background(), ports.ListFilter{Q: "  "}, ports.Page{Limit: 2, Offset: 1})
+	if err != nil {
+		t.Fatalf("ListBooksPage err: %v", err)
+	}
+	if got.Total != 5 || len(got.Books) != 2 || got.Books[0].ID != 4 || got.Books[1].ID != 3 {
+		t.Fatalf("page = %+v", got)
+	}
+
+	_, err = svc.ListBooksPage(context.Background(), ports.ListFilter{}, ports.Page{Limit: -1, Offset: -1})
+	ve, ok := err.(*ValidationError)
+	if !ok || ve.Fields["limit"] == "" || ve.Fields["offset"] == "" {
+		t.Fatalf("want limit/offset validation errors, got %v", err)
+	}
+}
+
+func TestListBooksPage_Filters(t *testing.T) {
+	svc := NewBookService(newMemBookRepo(
+		domain.Book{ID: 1, Author: "Gabriel García Márquez", PublicationYear: 1967, Price: 15},
+		domain.Book{ID: 2, Author: "Gabriel García Márquez", PublicationYear: 1985, Price: 18},
+		domain.Book{ID: 3, Author: "Jorge Luis Borges", PublicationYear: 1944, Price: 12},
+	))
+
+	got, err := svc.ListBooksPage(context.Background(), ports.ListFilter{
+		Author: " gabriel garcia marquez", YearTo: iptr(1980), PriceMin: f64ptr(10),
+	}, ports.Page{})
+	if err != nil {
+		t.Fatalf("ListBooksPage err: %v", err)
+	}
+	if got.Total != 1 || got.Books[0].ID != 1 {
+		t.Fatalf("page = %+v", got)
+	}
+
+	_, err = svc.ListBooksPage(context.Background(), ports.ListFilter{
+		YearFrom: iptr(2000), YearTo: iptr(1990), PriceMin: f64ptr(-1), PriceMax: f64ptr(5),
+	}, ports.Page{})
+	ve, ok := err.(*ValidationError)
+	if !ok || ve.Fields["year_from"] == "" || ve.Fields["price_min"] == "" {
+		t.Fatalf("want year/price validation errors, got %v", err)
+	}
+}
+
+func TestListBooksPage_SortValidation(t *testing.T) {
+	var got ports.Page
+	svc := NewBookService(&mockRepo{
+		PageFn: func(ctx context.Context, f ports.ListFilter, page ports.Page) ([]domain.Book, int, error) {
+			got = page
+			return nil, 0, nil
+		},
+	})
+
+	if _, err := svc.ListBooksPage(context.Background(), ports.ListFilter{},
+		ports.Page{Sort: ports.Sort{Field: "title", Collation: " TR "}}); err != nil {
+		t.Fatalf("ListBooksPage err: %v", err)
+	}
+	if got.Sort.Collation != "tr" {
+		t.Fatalf("collation = %q; want tr", got.Sort.Collation)
+	}
+
+	cases := []struct {
+		sort  ports.Sort
+		field string
+	}{
+		{ports.Sort{Field: "isbn"}, "sort"},
+		{ports.Sort{Field: "title", Collation: "xx"}, "collation"},
+		{ports.Sort{Field: "price", Collation: "de"}, "collation"},
+		{ports.Sort{Collation: "de"}, "collation"},
+	}
+	for _, c := range cases {
+		_, err := svc.ListBooksPage(context.Background(), ports.ListFilter{}, ports.Page{Sort: c.sort})
+		ve, ok := err.(*ValidationError)
+		if !ok || ve.Fields[c.field] == "" {
+			t.Fatalf("%+v: want %s validation error, got %v", c.sort, c.field, err)
+		}
+	}
+}
+
 func TestGetBook_PassThrough(t *testing.T) {
 	m := &mockRepo{
 		GetByIDFn: func(ctx context.Context, id int64) (*domain.Book, error) {
@@ -75,6 +210,51 @@ func TestGetBook_PassThrough(t *testing.T) {
 	}
 }
 
+func TestGetBookByISBN(t *testing.T) {
+	svc := NewBookService(newMemBookRepo(
+		domain.Book{ID: 1, ISBN: "9780321125217", ISBN10: "0321125215"},
+		domain.Book{ID: 2, ISBN: "0306406152"}, // stored before ISBN-13 conversion
+		domain.Book{ID: 3, ISBN: "9791032305690"},
+	))
+
+	for isbn, want := range map[string]int64{
+		"0-321-12521-5": 1, "978-0-321-12521-7": 1,
+		"0306406152": 2, "9780306406157": 2,
+		"979-10-323-0569-0": 3,
+	} {
+		got, err := svc.GetBookByISBN(context.Background(), isbn)
+		if err != nil || got == nil || got.ID != want {
+			t.Fatalf("GetBookByISBN(%q) = %+v, %v; want book %d", isbn, got, err, want)
+		}
+	}
+
+	if got, err := svc.GetBookByISBN(context.Background(), "9780132350884"); got != nil || err != nil {
+		t.Fatalf("unknown ISBN = %+v, %v", got, err)
+	}
+	if _, err := svc.GetBookByISBN(context.Background(), "12345"); err == nil {
+		t.Fatal("invalid ISBN accepted")
+	} else if ve, ok := err.(*ValidationError); !ok || ve.Fields["isbn"] == "" {
+		t.Fatalf("err = %v; want isbn validation error", err)
+	}
+}
+
+func TestReadOnlyBookService(t *testing.T) {
+	svc := NewReadOnlyBookService(newMemBookRepo(domain.Book{ID: 1, Title: "Ficciones", PublicationYear: 1944}))
+
+	if b, err := svc.GetBook(context.Background(), 1); err != nil || b == nil || b.Title != "Ficciones" {
+		t.Fatalf("GetBook = %+v, %v", b, err)
+	}
+	if _, err := svc.CreateBook(context.Background(), validCreateInput()); !errors.Is(err, ports.ErrReadOnly) {
+		t.Fatalf("CreateBook err = %v; want ErrReadOnly", err)
+	}
+	if _, err := svc.UpdateBook(context.Background(), 1, updateTitle("Artificios")); !errors.Is(err, ports.ErrReadOnly) {
+		t.Fatalf("UpdateBook err = %v; want ErrReadOnly", err)
+	}
+	if err := svc.DeleteBook(context.Background(), 1); !errors.Is(err, ports.ErrReadOnly) {
+		t.Fatalf("DeleteBook err = %v; want ErrReadOnly", err)
+	}
+}
+
 func TestCreateBook_OK(t *testing.T) {
 	var captured *domain.Book
 	m := &mockRepo{
@@ -332,3 +512,143 @@ func TestDeleteBook_Error(t *testing.T) {
 		t.Fatalf("want boom; got %v", err)
 	}
 }
+
+// ---- Conflict-aware merge ----
+
+func TestUpdateBook_RejectsSameFieldChangedAfterBase(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := &mockRepo{
+		GetByIDFn: func(ctx context.Context, id int64) (*domain.Book, error) {
+			return &domain.Book{
+				ID: id, Title: "Theirs", Price: 10, UpdatedAt: base.Add(time.Minute),
+				FieldUpdatedAt: domain.FieldTimes{"title": base.Add(time.Minute), "price": base.Add(-time.Hour)},
+			}, nil
+		},
+		UpdateFn: func(ctx context.Context, b *domain.Book) error {
+			t.Fatalf("repo.Update must not be called on conflict")
+			return nil
+		},
+	}
+	svc := NewBookService(m)
+
+	_, err := svc.UpdateBook(context.Background(), 1, ports.UpdateBookInput{
+		Title: strptr("Mine"), Price: f64ptr(12), BaseUpdatedAt: &base,
+	})
+	ce, ok := err.(*ConflictError)
+	if !ok {
+		t.Fatalf("want *ConflictError; got %T (%v)", err, err)
+	}
+	if len(ce.Fields) != 1 || ce.Fields[0] != "title" {
+		t.Fatalf("conflict fields = %v", ce.Fields)
+	}
+}
+
+func TestUpdateBook_MergesDisjointFieldChangedAfterBase(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var written *domain.Book
+	m := &mockRepo{
+		GetByIDFn: func(ctx context.Context, id int64) (*domain.Book, error) {
+			return &domain.Book{
+				ID: id, Title: "Theirs", Price: 10, UpdatedAt: base.Add(time.Minute),
+				FieldUpdatedAt: domain.FieldTimes{"title": base.Add(time.Minute), "price": base},
+			}, nil
+		},
+		UpdateFn: func(ctx context.Context, b *domain.Book) error { written = b; return nil },
+	}
+	svc := NewBookService(m)
+
+	// title is resent unchanged, so only price counts as an edit
+	_, err := svc.UpdateBook(context.Background(), 1, ports.UpdateBookInput{
+		Title: strptr("Theirs"), Price: f64ptr(12), BaseUpdatedAt: &base,
+	})
+	if err != nil {
+		t.Fatalf("UpdateBook err: %v", err)
+	}
+	if written.Title != "Theirs" || written.Price != 12 {
+		t.Fatalf("written = %+v", written)
+	}
+	if !written.FieldUpdatedAt["price"].After(base) || !written.FieldUpdatedAt["title"].Equal(base.Add(time.Minute)) {
+		t.Fatalf("field times = %v", written.FieldUpdatedAt)
+	}
+}
+
+func TestUpdateBook_RejectsStaleVersion(t *testing.T) {
+	repo := newMemBookRepo(domain.Book{ID: 1, Title: "Old", Author: "A", Version: 3})
+	svc := NewBookService(repo)
+
+	_, err := svc.UpdateBook(context.Background(), 1, ports.UpdateBookInput{Price: f64ptr(5), Version: i64ptr(2)})
+	ce, ok := err.(*ConflictError)
+	if !ok || len(ce.Fields) != 1 || ce.Fields[0] != "version" || ce.Version != 3 {
+		t.Fatalf("want version conflict at 3; got %#v", err)
+	}
+
+	got, err := svc.UpdateBook(context.Background(), 1, ports.UpdateBookInput{Price: f64ptr(5), Version: i64ptr(3)})
+	if err != nil {
+		t.Fatalf("UpdateBook err: %v", err)
+	}
+	if got.Version != 4 || repo.books[1].Version != 4 {
+		t.Fatalf("version = %d, stored %d; want 4", got.Version, repo.books[1].Version)
+	}
+}
+
+func TestUpdateBook_StaleVersionAfterConcurrentWrite(t *testing.T) {
+	repo := newMemBookRepo(domain.Book{ID: 1, Title: "Old", Version: 1})
+	svc := NewBookService(&racingRepo{memBookRepo: repo, race: func() {
+		b := repo.books[1]
+		b.UpdatedAt, b.Version = b.UpdatedAt.Add(time.Second), 2
+		repo.books[1] = b
+	}})
+
+	// the retry sees version 2, so the client's version 1 is stale
+	_, err := svc.UpdateBook(context.Background(), 1, ports.UpdateBookInput{Title: strptr("New"), Version: i64ptr(1)})
+	if ce, ok := err.(*ConflictError); !ok || ce.Version != 2 {
+		t.Fatalf("want version conflict at 2; got %v", err)
+	}
+}
+
+func TestUpdateBook_RetriesOnConcurrentWrite(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := newMemBookRepo(domain.Book{ID: 1, Title: "Old", Author: "A", Price: 10, UpdatedAt: t0})
+	svc := NewBookService(&racingRepo{memBookRepo: repo, race: func() {
+		// another writer changes the author between our read and write
+		b := repo.books[1]
+		b.Author = "B"
+		b.UpdatedAt = t0.Add(time.Second)
+		repo.books[1] = b
+	}})
+
+	got, err := svc.UpdateBook(context.Background(), 1, ports.UpdateBookInput{Title: strptr("New")})
+	if err != nil {
+		t.Fatalf("UpdateBook err: %v", err)
+	}
+	if got.Title != "New" || got.Author != "B" || repo.books[1].Author != "B" {
+		t.Fatalf("concurrent edit lost: returned %+v stored %+v", got, repo.books[1])
+	}
+}
+
+func TestUpdateBook_GivesUpAfterMaxAttempts(t *testing.T) {
+	m := &mockRepo{
+		GetByIDFn: func(ctx context.Context, id int64) (*domain.Book, error) { return &domain.Book{ID: id}, nil },
+		UpdateFn:  func(ctx context.Context, b *domain.Book) error { return ports.ErrConcurrentUpdate },
+	}
+	svc := NewBookService(m)
+
+	_, err := svc.UpdateBook(context.Background(), 1, updateTitle("X"))
+	if !errors.Is(err, ports.ErrConcurrentUpdate) {
+		t.Fatalf("want ErrConcurrentUpdate; got %v", err)
+	}
+}
+
+// racingRepo runs race once right before the first write.
+type racingRepo struct {
+	*memBookRepo
+	race func()
+}
+
+func (r *racingRepo) Update(ctx context.Context, b *domain.Book, prev time.Time) error {
+	if r.race != nil {
+		r.race()
+		r.race = nil
+	}
+	return r.memBookRepo.Update(ctx, b, prev)
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// selectBooks returns the books a bulk operation's filter matches.
+func selectBooks(ctx context.Context, books ports.BookReader, f ports.RepriceFilter) ([]domain.Book, error) {
+	var all []domain.Book
+	var err error
+	if q := strings.TrimSpace(f.Query); q != "" {
+		all, err = books.Search(ctx, q)
+	} else {
+		all, err = books.List(ctx, ports.ListFilter{})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(all, func(b domain.Book) bool { return !matchesFilter(f, b) }), nil
+}
+
+func matchesFilter(f ports.RepriceFilter, b domain.Book) bool {
+	switch {
+	case len(f.IDs) > 0 && !slices.Contains(f.IDs, b.ID):
+		return false
+	case f.Author != "" && domain.SearchKey(f.Author) != domain.SearchKey(b.Author):
+		return false
+	case f.MinPrice != nil && b.Price < *f.MinPrice:
+		return false
+	case f.MaxPrice != nil && b.Price > *f.MaxPrice:
+		return false
+	case f.YearFrom != nil && b.PublicationYear < *f.YearFrom:
+		return false
+	case f.YearTo != nil && b.PublicationYear > *f.YearTo:
+		return false
+	}
+	return true
+}
+
+// emptyFilter reports whether f selects nothing in particular, which bulk
+// operations refuse unless f.All says every book is meant.
+func emptyFilter(f ports.RepriceFilter) bool {
+	return !f.All && len(f.IDs) == 0 && strings.TrimSpace(f.Query) == "" && f.Author == "" &&
+		f.MinPrice == nil && f.MaxPrice == nil && f.YearFrom == nil && f.YearTo == nil
+}
@@ -0,0 +1,198 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+const (
+	maxBulkTagBooks      = 50000
+	maxBulkTagCategories = 20
+	bulkTagChunk         = 100 // books per transaction
+	bulkTagPreview       = 100
+)
+
+type bulkTagService struct {
+	books   ports.BookReader
+	cats    ports.CategoryRepository
+	jobs    ports.BulkJobRepository
+	changes *ChangeFeed      // optional
+	tracker ports.JobTracker // optional
+}
+
+// NewBulkTagService runs bulk tag jobs in the background, one chunk of books
+// per transaction, saving their progress in jobs. With a tracker, a shutdown
+// waits for running jobs and interrupts those that outlive the drain.
+func NewBulkTagService(books ports.BookReader, cats ports.CategoryRepository, jobs ports.BulkJobRepository, changes *ChangeFeed, tracker ports.JobTracker) ports.BulkTagService {
+	return &bulkTagService{books: books, cats: cats, jobs: jobs, changes: changes, tracker: tracker}
+}
+
+// BulkTag previews (the default) or starts applying the category changes
+// to every book matching the filter. Books whose categories wouldn't change
+// are left out.
+func (s *bulkTagService) BulkTag(ctx context.Context, in ports.BulkTagRequest) (*ports.BulkTagResponse, error) {
+	add, remove, err := validateBulkTag(in)
+	if err != nil {
+		return nil, err
+	}
+	books, err := selectBooks(ctx, s.books, in.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ports.BulkTagResponse{DryRun: in.DryRun == nil || *in.DryRun, Matched: len(books), Preview: []ports.BulkTagItem{}}
+	var ids []int64
+	for _, b := range books {
+		after := recategorize(b.Categories, add, remove)
+		if slices.Equal(after, recategorize(b.Categories, nil, nil)) {
+			continue
+		}
+		ids = append(ids, b.ID)
+		if len(resp.Preview) < bulkTagPreview {
+			resp.Preview = append(resp.Preview, ports.BulkTagItem{BookID: b.ID, Title: b.Title, Categories: after})
+		}
+	}
+	resp.Changed = len(ids)
+	if len(ids) > maxBulkTagBooks {
+		errs := &ValidationError{}
+		errs.add("filter", fmt.Sprintf("Filter matches %d books to change; at most %d per job", len(ids), maxBulkTagBooks))
+		return nil, errs
+	}
+	if resp.DryRun || len(ids) == 0 {
+		return resp, nil
+	}
+
+	// the job outlives the request, but keeps its actor and logger
+	jobCtx, done := context.WithoutCancel(ctx), func() {}
+	if s.tracker != nil {
+		var ok bool
+		if jobCtx, done, ok = s.tracker.Track(jobCtx); !ok {
+			return nil, ports.ErrDraining
+		}
+	}
+	now := clock().UTC()
+	job := &domain.BulkJob{ID: newBatchID(), Kind: "bulk_tag", Status: domain.JobRunning, Total: len(ids), CreatedAt: now, UpdatedAt: now}
+	if a, ok := domain.ActorFrom(ctx); ok {
+		job.Actor = a.ID
+	}
+	if err := s.jobs.Create(ctx, job); err != nil {
+		done()
+		return nil, err
+	}
+	snapshot := *job
+	resp.Job = &snapshot
+	go func() {
+		defer done()
+		s.run(jobCtx, job, ids, add, remove)
+	}()
+	return resp, nil
+}
+
+// run applies the change chunk by chunk, saving progress after each.
+func (s *bulkTagService) run(ctx context.Context, job *domain.BulkJob, ids []int64, add, remove []string) {
+	log := logger.From(ctx).With("job", job.ID)
+	save := func() {
+		job.UpdatedAt = clock().UTC()
+		// saved even once ctx is cancelled, so the interruption is recorded
+		if err := s.jobs.Update(context.WithoutCancel(ctx), job); err != nil {
+			log.Error("failed to save bulk tag progress", "done", job.Done, "error", err)
+		}
+	}
+	for chunk := range slices.Chunk(ids, bulkTagChunk) {
+		err := context.Cause(ctx)
+		if err == nil {
+			err = s.cats.Assign(ctx, chunk, add, remove)
+		}
+		if err != nil {
+			job.Status, job.Error = domain.JobFailed, err.Error()
+			if errors.Is(err, ports.ErrDraining) || ctx.Err() != nil {
+				job.Status = domain.JobInterrupted
+			}
+			log.Warn("bulk tag stopped", "status", job.Status, "done", job.Done, "total", job.Total, "error", err)
+			save()
+			return
+		}
+		for _, id := range chunk {
+			s.recordChange(ctx, id)
+		}
+		job.Done += len(chunk)
+		if job.Done < job.Total {
+			save()
+		}
+	}
+	job.Status = domain.JobDone
+	save()
+	log.Info("bulk tag done", "books", job.Total, "add", add, "remove", remove)
+}
+
+func (s *bulkTagService) recordChange(ctx context.Context, bookID int64) {
+	if s.changes == nil {
+		return
+	}
+	b, err := s.books.GetByID(ctx, bookID)
+	if err != nil {
+		logger.From(ctx).Error("failed to read book for change payload", "id", bookID, "error", err)
+	}
+	if err := s.changes.Record(ctx, bookID, domain.ChangeUpdated, b); err != nil {
+		logger.From(ctx).Error("failed to record book change", "id", bookID, "op", domain.ChangeUpdated, "error", err)
+	}
+}
+
+func (s *bulkTagService) Job(ctx context.Context, id string) (*domain.BulkJob, error) {
+	return s.jobs.Get(ctx, id)
+}
+
+// recategorize returns cats with add added and remove removed, sorted and
+// without duplicates.
+func recategorize(cats, add, remove []string) []string {
+	out := append(slices.Clone(cats), add...)
+	out = slices.DeleteFunc(out, func(c string) bool { return slices.Contains(remove, c) })
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+// validateBulkTag checks the request and returns its categories as slugs.
+func validateBulkTag(in ports.BulkTagRequest) (add, remove []string, err error) {
+	errs := &ValidationError{}
+	if emptyFilter(in.Filter) {
+		errs.add("filter", "Filter is required (use all: true to tag every book)")
+	}
+	slugs := func(field string, names []string) []string {
+		if len(names) > maxBulkTagCategories {
+			errs.add(field, fmt.Sprintf("At most %d categories", maxBulkTagCategories))
+		}
+		var out []string
+		for _, name := range names {
+			slug := domain.CategorySlug(name)
+			switch {
+			case slug == "":
+				errs.add(field, "Categories need a letter or digit")
+			case len(slug) > domain.MaxCategoryLen:
+				errs.add(field, fmt.Sprintf("Categories are at most %d characters", domain.MaxCategoryLen))
+			default:
+				out = append(out, slug)
+			}
+		}
+		slices.Sort(out)
+		return slices.Compact(out)
+	}
+	add, remove = slugs("add", in.Add), slugs("remove", in.Remove)
+	if len(add) == 0 && len(remove) == 0 && errs.ok() {
+		errs.add("add", "Give categories to add or remove")
+	}
+	for _, c := range add {
+		if slices.Contains(remove, c) {
+			errs.add("remove", fmt.Sprintf("%q is both added and removed", c))
+		}
+	}
+	if !errs.ok() {
+		return nil, nil, errs
+	}
+	return add, remove, nil
+}
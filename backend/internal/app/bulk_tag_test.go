@@ -0,0 +1,168 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// memCategoryRepo files books of a memBookRepo under categories.
+type memCategoryRepo struct {
+	books  *memBookRepo
+	chunks int
+	err    error
+}
+
+func (m *memCategoryRepo) Assign(ctx context.Context, bookIDs []int64, add, remove []string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.chunks++
+	for _, id := range bookIDs {
+		b := m.books.books[id]
+		b.Categories = recategorize(b.Categories, add, remove)
+		m.books.books[id] = b
+	}
+	return nil
+}
+
+type memBulkJobRepo struct {
+	mu   sync.Mutex
+	jobs map[string]domain.BulkJob
+}
+
+func (m *memBulkJobRepo) Create(ctx context.Context, j *domain.BulkJob) error {
+	return m.Update(ctx, j)
+}
+
+func (m *memBulkJobRepo) Update(ctx context.Context, j *domain.BulkJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.jobs == nil {
+		m.jobs = map[string]domain.BulkJob{}
+	}
+	m.jobs[j.ID] = *j
+	return nil
+}
+
+func (m *memBulkJobRepo) Get(ctx context.Context, id string) (*domain.BulkJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if j, ok := m.jobs[id]; ok {
+		return &j, nil
+	}
+	return nil, nil
+}
+
+// waitForJob polls until job id has stopped running.
+func waitForJob(t *testing.T, svc ports.BulkTagService, id string) *domain.BulkJob {
+	t.Helper()
+	for range 200 {
+		j, err := svc.Job(context.Background(), id)
+		if err != nil || j == nil {
+			t.Fatalf("Job = %+v, %v", j, err)
+		}
+		if j.Status != domain.JobRunning {
+			return j
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("job %s still running", id)
+	return nil
+}
+
+func TestBulkTag_PreviewThenApply(t *testing.T) {
+	var books []domain.Book
+	for id := int64(1); id <= 250; id++ {
+		books = append(books, domain.Book{ID: id, Title: "Book", Author: "Ursula K. Le Guin", Categories: []string{"fantasy"}})
+	}
+	books = append(books, domain.Book{ID: 251, Author: "Ursula K. Le Guin", Categories: []string{"science-fiction"}})
+	books = append(books, domain.Book{ID: 252, Author: "Someone Else"})
+	repo := newMemBookRepo(books...)
+	cats := &memCategoryRepo{books: repo}
+	changes := &memChangeRepo{}
+	svc := NewBulkTagService(repo, cats, &memBulkJobRepo{}, NewChangeFeed(changes), nil)
+	in := ports.BulkTagRequest{
+		Filter: ports.RepriceFilter{Author: "URSULA K. LE GUIN"},
+		Add:    []string{"Science Fiction"},
+		Remove: []string{"fantasy"},
+	}
+
+	preview, err := svc.BulkTag(context.Background(), in)
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	if !preview.DryRun || preview.Matched != 251 || preview.Changed != 250 || len(preview.Preview) != bulkTagPreview || preview.Job != nil {
+		t.Fatalf("preview = %+v", preview)
+	}
+	if got := preview.Preview[0].Categories; !slices.Equal(got, []string{"science-fiction"}) {
+		t.Fatalf("preview categories = %v", got)
+	}
+	if cats.chunks != 0 {
+		t.Fatal("preview changed books")
+	}
+
+	in.DryRun = new(bool)
+	ctx := domain.WithActor(context.Background(), domain.Actor{ID: "ann"})
+	started, err := svc.BulkTag(ctx, in)
+	if err != nil || started.Job == nil || started.Job.Total != 250 || started.Job.Actor != "ann" {
+		t.Fatalf("apply = %+v, %v", started, err)
+	}
+	job := waitForJob(t, svc, started.Job.ID)
+	if job.Status != domain.JobDone || job.Done != 250 {
+		t.Fatalf("job = %+v", job)
+	}
+	if cats.chunks != 3 {
+		t.Fatalf("chunks = %d; want 3 of at most %d books", cats.chunks, bulkTagChunk)
+	}
+	if got := repo.books[7].Categories; !slices.Equal(got, []string{"science-fiction"}) {
+		t.Fatalf("book 7 categories = %v", got)
+	}
+	if len(repo.books[252].Categories) != 0 {
+		t.Fatal("book outside the filter was tagged")
+	}
+	if len(changes.changes) != 250 || changes.changes[0].Actor != "ann" {
+		t.Fatalf("recorded %d changes", len(changes.changes))
+	}
+}
+
+func TestBulkTag_Validation(t *testing.T) {
+	svc := NewBulkTagService(newMemBookRepo(), &memCategoryRepo{}, &memBulkJobRepo{}, nil, nil)
+	for name, in := range map[string]ports.BulkTagRequest{
+		"no filter":     {Add: []string{"poetry"}},
+		"no categories": {Filter: ports.RepriceFilter{All: true}},
+		"blank":         {Filter: ports.RepriceFilter{All: true}, Add: []string{"--"}},
+		"both":          {Filter: ports.RepriceFilter{All: true}, Add: []string{"Poetry"}, Remove: []string{"poetry"}},
+	} {
+		var ve *ValidationError
+		if _, err := svc.BulkTag(context.Background(), in); !errors.As(err, &ve) {
+			t.Errorf("%s: err = %v; want a ValidationError", name, err)
+		}
+	}
+}
+
+type cancelledJobs struct{}
+
+func (cancelledJobs) Track(ctx context.Context) (context.Context, func(), bool) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	cancel(ports.ErrDraining)
+	return ctx, func() {}, true
+}
+
+func TestBulkTag_InterruptedByDrain(t *testing.T) {
+	repo := newMemBookRepo(domain.Book{ID: 1}, domain.Book{ID: 2})
+	svc := NewBulkTagService(repo, &memCategoryRepo{books: repo}, &memBulkJobRepo{}, nil, cancelledJobs{})
+	res, err := svc.BulkTag(context.Background(), ports.BulkTagRequest{Filter: ports.RepriceFilter{All: true}, Add: []string{"poetry"}, DryRun: new(bool)})
+	if err != nil {
+		t.Fatalf("BulkTag: %v", err)
+	}
+	if job := waitForJob(t, svc, res.Job.ID); job.Status != domain.JobInterrupted || job.Done != 0 {
+		t.Fatalf("job = %+v", job)
+	}
+}
@@ -0,0 +1,344 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+const (
+	// maxCachedPages bounds the pages of ListBooksPage kept, as every
+	// filter and search is a page of its own.
+	maxCachedPages = 1000
+	// revalidateTimeout bounds a background refresh of a stale list.
+	revalidateTimeout = 30 * time.Second
+)
+
+// CachingBookService serves GetBook, ListBooks and ListBooksPage from an
+// in-process cache with a fixed TTL and passes everything else through.
+// Mutations recorded on the change feed of this instance invalidate the
+// affected entries at once; writes made by other replicas become visible when
+// the TTL expires.
+type CachingBookService struct {
+	ports.BookService
+	ttl   time.Duration
+	stale time.Duration // see ServeStale
+	now   func() time.Time
+
+	mu    sync.Mutex
+	books map[int64]cachedBook
+	list  *cachedList
+	pages map[string]cachedPage
+	// gen counts invalidations, so that a load that raced one doesn't
+	// cache what it read before the write.
+	gen        uint64
+	refreshing map[string]bool
+	refreshes  sync.WaitGroup
+}
+
+type cachedBook struct {
+	book    domain.Book
+	expires time.Time
+}
+
+type cachedList struct {
+	books   []domain.Book
+	expires time.Time
+}
+
+type cachedPage struct {
+	page    ports.BookPage
+	expires time.Time
+}
+
+// NewCachingBookService wraps inner. When feed is non-nil the cache subscribes
+// to it, which also covers mutations made outside inner (aliases, re-pricing).
+func NewCachingBookService(inner ports.BookService, ttl time.Duration, feed *ChangeFeed) *CachingBookService {
+	c := &CachingBookService{
+		BookService: inner,
+		ttl:         ttl,
+		now:         time.Now,
+		books:       map[int64]cachedBook{},
+		pages:       map[string]cachedPage{},
+		refreshing:  map[string]bool{},
+	}
+	if feed != nil {
+		feed.OnChange(func(ch domain.Change) { c.Invalidate(ch.BookID) })
+	}
+	return c
+}
+
+// ServeStale keeps serving an expired list or page for up to window past
+// its TTL while a single background call refreshes it, so callers of the
+// hot lists never wait on the database. A refresh that fails leaves the
+// stale copy in place until the window ends; writes on this instance still
+// invalidate at once. A window of 0, the default, never serves stale lists.
+func (c *CachingBookService) ServeStale(window time.Duration) {
+	c.stale = max(window, 0)
+}
+
+func (c *CachingBookService) GetBook(ctx context.Context, id int64) (*domain.Book, error) {
+	now := c.now()
+	c.mu.Lock()
+	e, ok := c.books[id]
+	c.mu.Unlock()
+	if ok && now.Before(e.expires) {
+		b := cloneBook(e.book)
+		return &b, nil
+	}
+
+	b, err := c.BookService.GetBook(ctx, id)
+	if err != nil || b == nil {
+		return b, err
+	}
+	c.storeBook(*b, now)
+	return b, nil
+}
+
+func (c *CachingBookService) ListBooks(ctx context.Context) ([]domain.Book, error) {
+	now := c.now()
+	c.mu.Lock()
+	l := c.list
+	c.mu.Unlock()
+	if l != nil && now.Before(l.expires.Add(c.stale)) {
+		if !now.Before(l.expires) {
+			c.revalidate(ctx, "list", func(ctx context.Context) error {
+				_, err := c.loadList(ctx)
+				return err
+			})
+		}
+		return cloneBooks(l.books), nil
+	}
+	return c.loadList(ctx)
+}
+
+func (c *CachingBookService) loadList(ctx context.Context) ([]domain.Book, error) {
+	gen := c.generation()
+	books, err := c.BookService.ListBooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	if gen == c.gen {
+		c.list = &cachedList{books: cloneBooks(books), expires: c.now().Add(c.ttl)}
+	}
+	c.mu.Unlock()
+	return books, nil
+}
+
+// ListBooksPage caches pages by their filter and paging, searches included.
+func (c *CachingBookService) ListBooksPage(ctx context.Context, f ports.ListFilter, page ports.Page) (*ports.BookPage, error) {
+	raw, err := json.Marshal(struct {
+		F ports.ListFilter
+		P ports.Page
+	}{f, page})
+	if err != nil {
+		return c.BookService.ListBooksPage(ctx, f, page)
+	}
+	key := string(raw)
+	now := c.now()
+	c.mu.Lock()
+	p, ok := c.pages[key]
+	c.mu.Unlock()
+	if ok && now.Before(p.expires.Add(c.stale)) {
+		if !now.Before(p.expires) {
+			c.revalidate(ctx, "page "+key, func(ctx context.Context) error {
+				_, err := c.loadPage(ctx, key, f, page)
+				return err
+			})
+		}
+		return clonePage(p.page), nil
+	}
+	return c.loadPage(ctx, key, f, page)
+}
+
+func (c *CachingBookService) loadPage(ctx context.Context, key string, f ports.ListFilter, page ports.Page) (*ports.BookPage, error) {
+	gen := c.generation()
+	res, err := c.BookService.ListBooksPage(ctx, f, page)
+	if err != nil || res == nil {
+		return res, err
+	}
+	now := c.now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if gen != c.gen {
+		return res, nil
+	}
+	if _, ok := c.pages[key]; !ok && len(c.pages) >= maxCachedPages {
+		for k, p := range c.pages {
+			if !now.Before(p.expires.Add(c.stale)) {
+				delete(c.pages, k)
+			}
+		}
+		if len(c.pages) >= maxCachedPages {
+			return res, nil
+		}
+	}
+	c.pages[key] = cachedPage{page: *clonePage(*res), expires: now.Add(c.ttl)}
+	return res, nil
+}
+
+// revalidate refreshes the entry key with load in the background, unless a
+// refresh of it is already running. The refresh outlives the request that
+// found the entry stale.
+func (c *CachingBookService) revalidate(ctx context.Context, key string, load func(ctx context.Context) error) {
+	c.mu.Lock()
+	if c.refreshing[key] {
+		c.mu.Unlock()
+		return
+	}
+	c.refreshing[key] = true
+	c.mu.Unlock()
+
+	c.refreshes.Add(1)
+	go func() {
+		defer c.refreshes.Done()
+		defer func() {
+			c.mu.Lock()
+			delete(c.refreshing, key)
+			c.mu.Unlock()
+		}()
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), revalidateTimeout)
+		defer cancel()
+		if err := load(ctx); err != nil {
+			logger.From(ctx).Warn("failed to refresh stale book list, serving it until the stale window ends", "error", err)
+		}
+	}()
+}
+
+func (c *CachingBookService) generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gen
+}
+
+// SearchBooks serves a blank query from the cached list.
+func (c *CachingBookService) SearchBooks(ctx context.Context, q string) ([]domain.Book, error) {
+	if strings.TrimSpace(q) == "" {
+		return c.ListBooks(ctx)
+	}
+	return c.BookService.SearchBooks(ctx, q)
+}
+
+func (c *CachingBookService) CreateBook(ctx context.Context, in ports.CreateBookInput) (*domain.Book, error) {
+	b, err := c.BookService.CreateBook(ctx, in)
+	if err == nil {
+		c.Invalidate(b.ID)
+	}
+	return b, err
+}
+
+func (c *CachingBookService) UpdateBook(ctx context.Context, id int64, in ports.UpdateBookInput) (*domain.Book, error) {
+	b, err := c.BookService.UpdateBook(ctx, id, in)
+	c.Invalidate(id) // a conflict may mean the cached copy is stale
+	return b, err
+}
+
+func (c *CachingBookService) DeleteBook(ctx context.Context, id int64) error {
+	err := c.BookService.DeleteBook(ctx, id)
+	c.Invalidate(id)
+	return err
+}
+
+func (c *CachingBookService) SplitBook(ctx context.Context, id int64, in ports.SplitBookInput) (*ports.SplitBookResult, error) {
+	res, err := c.BookService.SplitBook(ctx, id, in)
+	c.Invalidate(id)
+	return res, err
+}
+
+// Invalidate drops book id and the cached lists, stale ones included.
+func (c *CachingBookService) Invalidate(id int64) {
+	c.mu.Lock()
+	delete(c.books, id)
+	c.list = nil
+	clear(c.pages)
+	c.gen++
+	c.mu.Unlock()
+}
+
+// Warm loads ids and the full list into the cache, skipping books that no
+// longer exist. It returns how many books were cached.
+func (c *CachingBookService) Warm(ctx context.Context, ids []int64) (int, error) {
+	n := 0
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+		b, err := c.BookService.GetBook(ctx, id)
+		if err != nil {
+			return n, err
+		}
+		if b != nil {
+			c.storeBook(*b, c.now())
+			n++
+		}
+	}
+	c.mu.Lock()
+	c.list = nil // force a fresh list
+	c.mu.Unlock()
+	if _, err := c.ListBooks(ctx); err != nil {
+		return n, err
+	}
+	return n, nil
+}
+
+func (c *CachingBookService) storeBook(b domain.Book, now time.Time) {
+	c.mu.Lock()
+	c.books[b.ID] = cachedBook{book: cloneBook(b), expires: now.Add(c.ttl)}
+	c.mu.Unlock()
+}
+
+// WarmCache preloads the topN most viewed books and the book list into c.
+func WarmCache(ctx context.Context, c *CachingBookService, views ports.ViewRepository, topN int) (int, error) {
+	ids, err := views.TopViewed(ctx, topN)
+	if err != nil {
+		return 0, err
+	}
+	return c.Warm(ctx, ids)
+}
+
+// cloneBook copies the reference fields so cached entries can't be mutated
+// through a returned book.
+func cloneBook(b domain.Book) domain.Book {
+	b.Aliases = slices.Clone(b.Aliases)
+	b.Categories = slices.Clone(b.Categories)
+	b.Tags = slices.Clone(b.Tags)
+	b.FieldUpdatedAt = maps.Clone(b.FieldUpdatedAt)
+	if b.WorkID != nil {
+		w := *b.WorkID
+		b.WorkID = &w
+	}
+	if b.PublisherID != nil {
+		p := *b.PublisherID
+		b.PublisherID = &p
+	}
+	if b.SeriesID != nil {
+		id := *b.SeriesID
+		b.SeriesID = &id
+	}
+	if b.SeriesPosition != nil {
+		n := *b.SeriesPosition
+		b.SeriesPosition = &n
+	}
+	return b
+}
+
+func clonePage(p ports.BookPage) *ports.BookPage {
+	return &ports.BookPage{Books: cloneBooks(p.Books), Total: p.Total}
+}
+
+func cloneBooks(bs []domain.Book) []domain.Book {
+	out := make([]domain.Book, len(bs))
+	for i, b := range bs {
+		out[i] = cloneBook(b)
+	}
+	return out
+}
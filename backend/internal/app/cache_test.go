@@ -0,0 +1,240 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// ---- In-memory ports.ViewRepository ----
+
+type memViewRepo struct {
+	views map[int64]int64
+	top   []int64
+	err   error
+}
+
+func (m *memViewRepo) AddViews(ctx context.Context, counts map[int64]int64) error {
+	if m.err != nil {
+		return m.err
+	}
+	if m.views == nil {
+		m.views = map[int64]int64{}
+	}
+	for id, n := range counts {
+		m.views[id] += n
+	}
+	return nil
+}
+
+func (m *memViewRepo) TopViewed(ctx context.Context, n int) ([]int64, error) {
+	return m.top[:min(n, len(m.top))], m.err
+}
+
+func newCacheFixture(books ...domain.Book) (*CachingBookService, *memBookRepo, *ChangeFeed, *time.Time) {
+	repo := newMemBookRepo(books...)
+	feed := NewChangeFeed(&memChangeRepo{})
+	c := NewCachingBookService(NewBookService(repo, WithChangeFeed(feed)), time.Minute, feed)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	return c, repo, feed, &now
+}
+
+func TestCache_GetBookServedUntilExpiry(t *testing.T) {
+	c, repo, _, now := newCacheFixture(syncBook())
+	ctx := context.Background()
+
+	if _, err := c.GetBook(ctx, 1); err != nil {
+		t.Fatalf("GetBook err: %v", err)
+	}
+	b := repo.books[1]
+	b.Title = "changed behind the cache"
+	repo.books[1] = b
+
+	got, _ := c.GetBook(ctx, 1)
+	if got.Title != "Clean Code" {
+		t.Fatalf("want cached title, got %q", got.Title)
+	}
+	*now = now.Add(2 * time.Minute)
+	got, _ = c.GetBook(ctx, 1)
+	if got.Title != "changed behind the cache" {
+		t.Fatalf("want reloaded title after TTL, got %q", got.Title)
+	}
+}
+
+func TestCache_ChangeFeedInvalidates(t *testing.T) {
+	c, repo, feed, _ := newCacheFixture(syncBook())
+	ctx := context.Background()
+
+	_, _ = c.GetBook(ctx, 1)
+	_, _ = c.ListBooks(ctx)
+	b := repo.books[1]
+	b.Price = 99
+	repo.books[1] = b
+	// e.g. a re-price, which bypasses the book service
+	_ = feed.Record(ctx, 1, domain.ChangeUpdated, nil)
+
+	got, _ := c.GetBook(ctx, 1)
+	list, _ := c.ListBooks(ctx)
+	if got.Price != 99 || list[0].Price != 99 {
+		t.Fatalf("stale after change: book=%v list=%v", got.Price, list[0].Price)
+	}
+}
+
+func TestCache_WritesInvalidate(t *testing.T) {
+	// without a feed the decorator still invalidates its own writes
+	c := NewCachingBookService(NewBookService(newMemBookRepo(syncBook())), time.Minute, nil)
+	ctx := context.Background()
+
+	_, _ = c.GetBook(ctx, 1)
+	if _, err := c.UpdateBook(ctx, 1, updateTitle("Refactoring")); err != nil {
+		t.Fatalf("UpdateBook err: %v", err)
+	}
+	if got, _ := c.GetBook(ctx, 1); got.Title != "Refactoring" {
+		t.Fatalf("title = %q", got.Title)
+	}
+	if err := c.DeleteBook(ctx, 1); err != nil {
+		t.Fatalf("DeleteBook err: %v", err)
+	}
+	if got, _ := c.GetBook(ctx, 1); got != nil {
+		t.Fatalf("deleted book still cached: %+v", got)
+	}
+}
+
+func TestCache_ServesStaleListsWhileRevalidating(t *testing.T) {
+	c, repo, _, now := newCacheFixture(syncBook())
+	c.ServeStale(time.Minute)
+	ctx := context.Background()
+	page := ports.Page{Limit: 10}
+
+	_, _ = c.ListBooks(ctx)
+	_, _ = c.ListBooksPage(ctx, ports.ListFilter{}, page)
+	b := repo.books[1]
+	b.Title = "changed behind the cache"
+	repo.books[1] = b
+
+	// past the TTL but within the window: the stale copy, refreshed behind it
+	*now = now.Add(90 * time.Second)
+	list, _ := c.ListBooks(ctx)
+	res, _ := c.ListBooksPage(ctx, ports.ListFilter{}, page)
+	if list[0].Title != "Clean Code" || res.Books[0].Title != "Clean Code" {
+		t.Fatalf("want stale titles, got %q and %q", list[0].Title, res.Books[0].Title)
+	}
+	c.refreshes.Wait()
+	list, _ = c.ListBooks(ctx)
+	res, _ = c.ListBooksPage(ctx, ports.ListFilter{}, page)
+	if list[0].Title != "changed behind the cache" || res.Books[0].Title != "changed behind the cache" {
+		t.Fatalf("want refreshed titles, got %q and %q", list[0].Title, res.Books[0].Title)
+	}
+
+	// past the window the list is loaded before answering
+	b.Title = "Refactoring"
+	repo.books[1] = b
+	*now = now.Add(3 * time.Minute)
+	if list, _ = c.ListBooks(ctx); list[0].Title != "Refactoring" {
+		t.Fatalf("want reloaded title past the window, got %q", list[0].Title)
+	}
+}
+
+func TestCache_StalePagesDroppedOnWrite(t *testing.T) {
+	c, repo, feed, now := newCacheFixture(syncBook())
+	c.ServeStale(time.Hour)
+	ctx := context.Background()
+	f := ports.ListFilter{Q: "clean"}
+
+	if res, _ := c.ListBooksPage(ctx, f, ports.Page{}); res.Total != 1 {
+		t.Fatalf("total = %d", res.Total)
+	}
+	b := repo.books[1]
+	b.Price = 99
+	repo.books[1] = b
+	_ = feed.Record(ctx, 1, domain.ChangeUpdated, nil)
+
+	*now = now.Add(2 * time.Minute)
+	if res, _ := c.ListBooksPage(ctx, f, ports.Page{}); res.Books[0].Price != 99 {
+		t.Fatalf("stale page served after a write: %v", res.Books[0].Price)
+	}
+}
+
+func TestCache_ReturnsCopies(t *testing.T) {
+	b := syncBook()
+	b.Aliases = []string{"CC"}
+	c, _, _, _ := newCacheFixture(b)
+	ctx := context.Background()
+
+	got, _ := c.GetBook(ctx, 1)
+	got.Aliases[0] = "mutated"
+	got, _ = c.GetBook(ctx, 1)
+	if got.Aliases[0] != "CC" {
+		t.Fatalf("cache entry was mutated: %v", got.Aliases)
+	}
+}
+
+func TestWarmCache(t *testing.T) {
+	b2 := syncBook()
+	b2.ID = 2
+	c, repo, _, _ := newCacheFixture(syncBook(), b2)
+	views := &memViewRepo{top: []int64{2, 42, 1}} // 42 was deleted since
+
+	n, err := WarmCache(context.Background(), c, views, 10)
+	if err != nil {
+		t.Fatalf("WarmCache err: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("warmed %d; want 2", n)
+	}
+	// served without touching the repository
+	repo.books = map[int64]domain.Book{}
+	if got, _ := c.GetBook(context.Background(), 2); got == nil {
+		t.Fatalf("book 2 not warmed")
+	}
+	if list, _ := c.ListBooks(context.Background()); len(list) != 2 {
+		t.Fatalf("list not warmed: %v", list)
+	}
+}
+
+func TestWarmCache_Error(t *testing.T) {
+	c, _, _, _ := newCacheFixture()
+	if _, err := WarmCache(context.Background(), c, &memViewRepo{err: errors.New("db down")}, 5); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+// ---- ViewCounter ----
+
+func TestViewCounter_Flush(t *testing.T) {
+	repo := &memViewRepo{}
+	vc := NewViewCounter(repo)
+	vc.Add(1)
+	vc.Add(1)
+	vc.Add(3)
+
+	if err := vc.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush err: %v", err)
+	}
+	if repo.views[1] != 2 || repo.views[3] != 1 {
+		t.Fatalf("views = %v", repo.views)
+	}
+}
+
+func TestViewCounter_KeepsCountsOnError(t *testing.T) {
+	repo := &memViewRepo{err: errors.New("db down")}
+	vc := NewViewCounter(repo)
+	vc.Add(7)
+	if err := vc.Flush(context.Background()); err == nil {
+		t.Fatalf("expected error")
+	}
+	vc.Add(7)
+
+	repo.err = nil
+	if err := vc.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush err: %v", err)
+	}
+	if repo.views[7] != 2 {
+		t.Fatalf("views = %v", repo.views)
+	}
+}
@@ -0,0 +1,189 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// Upstream catalogue event types.
+const (
+	CatalogUpserted = "book.upserted"
+	CatalogDeleted  = "book.deleted"
+)
+
+// KindCatalogEvent dead letters hold an upstream event that could not be
+// applied, e.g. because it failed validation; it can be retried once the
+// cause is fixed.
+const KindCatalogEvent = "catalog_event"
+
+// catalogEventLetter is the payload of a KindCatalogEvent dead letter.
+type catalogEventLetter struct {
+	Source    string       `json:"source"`
+	MessageID string       `json:"message_id"`
+	Event     CatalogEvent `json:"event"`
+}
+
+// catalogActor stamps the change log entries written for upstream events.
+var catalogActor = domain.Actor{ID: "catalog-sync"}
+
+// CatalogEvent is one message of the upstream ERP's catalogue feed. Books are
+// matched by ISBN; absent fields of an upsert leave the stored value alone.
+type CatalogEvent struct {
+	// EventID identifies the event across redeliveries; when empty the
+	// transport's own message id (e.g. topic/partition/offset) is used.
+	EventID         string   `json:"event_id"`
+	Type            string   `json:"type"`
+	ISBN            string   `json:"isbn"`
+	Title           *string  `json:"title"`
+	Author          *string  `json:"author"`
+	Price           *float64 `json:"price"`
+	PublicationYear *int     `json:"publication_year"`
+}
+
+// CatalogInbox applies upstream catalogue events through the book service,
+// recording each in the inbox so it takes effect once however often it is
+// delivered. The inbox is written after the book, so a crash in between
+// replays the event; upserts and deletes by ISBN converge to the same state
+// when replayed, which keeps that safe.
+type CatalogInbox struct {
+	books ports.BookService
+	inbox ports.InboxRepository
+	dlq   *DeadLetters // nil: unprocessable events are only recorded in the inbox
+}
+
+func NewCatalogInbox(books ports.BookService, inbox ports.InboxRepository) *CatalogInbox {
+	return &CatalogInbox{books: books, inbox: inbox}
+}
+
+// UseDeadLetters also parks events that could not be applied in d, where an
+// operator can retry or discard them. Malformed messages aren't parked: there
+// is no event to apply again.
+func (c *CatalogInbox) UseDeadLetters(d *DeadLetters) {
+	c.dlq = d
+	d.Register(KindCatalogEvent, func(ctx context.Context, payload []byte) error {
+		var l catalogEventLetter
+		if err := json.Unmarshal(payload, &l); err != nil {
+			return err
+		}
+		// the inbox already holds the original outcome; the retry only has
+		// to apply the event
+		return c.apply(domain.WithActor(ctx, catalogActor), l.Event, &domain.InboxMessage{})
+	})
+}
+
+// Handle applies one message from source. Messages that can never apply
+// (malformed, invalid, unknown type) are recorded with their error and
+// acknowledged; an error is returned only for failures worth retrying, in
+// which case the caller must redeliver the message rather than move on.
+func (c *CatalogInbox) Handle(ctx context.Context, source, messageID string, payload []byte) error {
+	var ev CatalogEvent
+	decodeErr := json.Unmarshal(payload, &ev)
+	if decodeErr == nil && ev.EventID != "" {
+		messageID = ev.EventID
+	}
+	seen, err := c.inbox.Seen(ctx, source, messageID)
+	if err != nil {
+		return err
+	}
+	if seen {
+		return nil
+	}
+
+	msg := &domain.InboxMessage{Source: source, MessageID: messageID}
+	if decodeErr != nil {
+		msg.Error = "malformed message: " + decodeErr.Error()
+	} else if err := c.apply(domain.WithActor(ctx, catalogActor), ev, msg); err != nil {
+		reason, ok := permanent(err)
+		if !ok {
+			return err
+		}
+		msg.Error = reason
+		if c.dlq != nil {
+			letter := catalogEventLetter{Source: source, MessageID: messageID, Event: ev}
+			if err := c.dlq.Park(ctx, KindCatalogEvent, letter, err); err != nil {
+				return err
+			}
+		}
+	}
+	if msg.Error != "" {
+		logger.From(ctx).Error("skipping catalog event", "source", source, "message_id", messageID, "error", msg.Error)
+	}
+	msg.ProcessedAt = clock().UTC()
+	return c.inbox.Record(ctx, msg)
+}
+
+func (c *CatalogInbox) apply(ctx context.Context, ev CatalogEvent, msg *domain.InboxMessage) error {
+	if ev.ISBN == "" {
+		return &ValidationError{Fields: map[string]string{"isbn": "ISBN is required"}}
+	}
+	page, err := c.books.ListBooksPage(ctx, ports.ListFilter{ISBN: ev.ISBN}, ports.Page{Limit: 1})
+	if err != nil {
+		return err
+	}
+	var existing *domain.Book
+	if len(page.Books) > 0 {
+		existing = &page.Books[0]
+	}
+
+	switch ev.Type {
+	case CatalogUpserted:
+		if existing == nil {
+			in := ports.CreateBookInput{ISBN: ev.ISBN}
+			in.Title, in.Author = deref(ev.Title), deref(ev.Author)
+			in.Price, in.PublicationYear = deref(ev.Price), deref(ev.PublicationYear)
+			b, err := c.books.CreateBook(ctx, in)
+			if err != nil {
+				return err
+			}
+			msg.Op, msg.BookID = domain.ChangeCreated, &b.ID
+			return nil
+		}
+		in := ports.UpdateBookInput{Title: ev.Title, Author: ev.Author, Price: ev.Price, PublicationYear: ev.PublicationYear}
+		if _, err := c.books.UpdateBook(ctx, existing.ID, in); err != nil {
+			return err
+		}
+		msg.Op, msg.BookID = domain.ChangeUpdated, &existing.ID
+	case CatalogDeleted:
+		if existing == nil {
+			return nil // already gone
+		}
+		if err := c.books.DeleteBook(ctx, existing.ID); err != nil {
+			return err
+		}
+		msg.Op, msg.BookID = domain.ChangeDeleted, &existing.ID
+	default:
+		return &ValidationError{Fields: map[string]string{"type": fmt.Sprintf("unknown event type %q", ev.Type)}}
+	}
+	return nil
+}
+
+// permanent reports whether retrying err could never succeed, and if so
+// describes it for the inbox.
+func permanent(err error) (string, bool) {
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		return "", false
+	}
+	var parts []string
+	for _, f := range slices.Sorted(maps.Keys(ve.Fields)) {
+		parts = append(parts, f+": "+ve.Fields[f])
+	}
+	return "validation error: " + strings.Join(parts, "; "), true
+}
+
+func deref[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
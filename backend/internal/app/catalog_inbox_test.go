@@ -0,0 +1,102 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+type memInbox struct {
+	msgs    map[string]domain.InboxMessage
+	seenErr error
+}
+
+func (m *memInbox) Seen(ctx context.Context, source, id string) (bool, error) {
+	_, ok := m.msgs[source+"|"+id]
+	return ok, m.seenErr
+}
+
+func (m *memInbox) Record(ctx context.Context, msg *domain.InboxMessage) error {
+	if _, ok := m.msgs[msg.Source+"|"+msg.MessageID]; !ok {
+		m.msgs[msg.Source+"|"+msg.MessageID] = *msg
+	}
+	return nil
+}
+
+func TestCatalogInbox_AppliesEachEventOnce(t *testing.T) {
+	repo := newMemBookRepo()
+	changes := &memChangeRepo{}
+	inbox := &memInbox{msgs: map[string]domain.InboxMessage{}}
+	c := NewCatalogInbox(NewBookService(repo, WithChangeFeed(NewChangeFeed(changes))), inbox)
+	ctx := context.Background()
+
+	create := `{"event_id":"e1","type":"book.upserted","isbn":"978-0-8021-3030-3","title":"Ficciones","author":"Jorge Luis Borges","price":12.5,"publication_year":1944}`
+	for range 2 { // redelivered
+		if err := c.Handle(ctx, "erp.catalog", "erp.catalog/0/1", []byte(create)); err != nil {
+			t.Fatalf("Handle err: %v", err)
+		}
+	}
+	if len(repo.books) != 1 || len(changes.changes) != 1 {
+		t.Fatalf("books = %d, changes = %d; want the create applied once", len(repo.books), len(changes.changes))
+	}
+	if changes.changes[0].Actor != "catalog-sync" {
+		t.Fatalf("actor = %q", changes.changes[0].Actor)
+	}
+	msg := inbox.msgs["erp.catalog|e1"]
+	if msg.Op != domain.ChangeCreated || msg.BookID == nil || msg.Error != "" {
+		t.Fatalf("inbox = %+v", msg)
+	}
+
+	// no event id: the transport's message id is the key
+	if err := c.Handle(ctx, "erp.catalog", "erp.catalog/0/2", []byte(`{"type":"book.upserted","isbn":"9780802130303","price":14}`)); err != nil {
+		t.Fatalf("Handle err: %v", err)
+	}
+	if b := repo.books[*msg.BookID]; b.Price != 14 || b.Title != "Ficciones" {
+		t.Fatalf("updated book = %+v", b)
+	}
+	if inbox.msgs["erp.catalog|erp.catalog/0/2"].Op != domain.ChangeUpdated {
+		t.Fatalf("inbox = %+v", inbox.msgs)
+	}
+
+	if err := c.Handle(ctx, "erp.catalog", "erp.catalog/0/3", []byte(`{"event_id":"e3","type":"book.deleted","isbn":"9780802130303"}`)); err != nil {
+		t.Fatalf("Handle err: %v", err)
+	}
+	if len(repo.books) != 0 {
+		t.Fatalf("book not deleted: %+v", repo.books)
+	}
+}
+
+func TestCatalogInbox_RecordsUnprocessableMessages(t *testing.T) {
+	inbox := &memInbox{msgs: map[string]domain.InboxMessage{}}
+	c := NewCatalogInbox(NewBookService(newMemBookRepo()), inbox)
+	ctx := context.Background()
+
+	cases := map[string]string{
+		"m1": `not json`,
+		"m2": `{"type":"book.upserted","isbn":"9780802130303"}`,
+		"m3": `{"type":"book.renamed","isbn":"9780802130303"}`,
+	}
+	for id, payload := range cases {
+		if err := c.Handle(ctx, "erp", id, []byte(payload)); err != nil {
+			t.Fatalf("%s: Handle err: %v; want it acknowledged", id, err)
+		}
+	}
+	for id, want := range map[string]string{"m1": "malformed", "m2": "title: Title is required", "m3": "unknown event type"} {
+		if got := inbox.msgs["erp|"+id]; !strings.Contains(got.Error, want) || got.Op != "" {
+			t.Fatalf("%s: inbox = %+v; want error containing %q", id, got, want)
+		}
+	}
+}
+
+func TestCatalogInbox_TransientErrorIsReturned(t *testing.T) {
+	inbox := &memInbox{msgs: map[string]domain.InboxMessage{}, seenErr: errors.New("db down")}
+	c := NewCatalogInbox(NewBookService(newMemBookRepo()), inbox)
+
+	err := c.Handle(context.Background(), "erp", "m1", []byte(`{"type":"book.deleted","isbn":"9780802130303"}`))
+	if err == nil || len(inbox.msgs) != 0 {
+		t.Fatalf("err = %v, inbox = %+v; want the error and nothing recorded", err, inbox.msgs)
+	}
+}
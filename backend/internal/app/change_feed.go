@@ -0,0 +1,160 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// ChangeFeed appends book mutations to the change log and lets readers
+// long-poll for entries after a cursor. Writers on this instance wake waiting
+// readers immediately; writes from other replicas are picked up by polling.
+type ChangeFeed struct {
+	repo      ports.ChangeRepository
+	pollEvery time.Duration
+
+	mu        sync.Mutex
+	notify    chan struct{} // closed and replaced on every Record
+	listeners []func(domain.Change)
+}
+
+func NewChangeFeed(repo ports.ChangeRepository) *ChangeFeed {
+	return &ChangeFeed{
+		repo:      repo,
+		pollEvery: time.Second,
+		notify:    make(chan struct{}),
+	}
+}
+
+// OnChange registers fn to be called synchronously after every Record on
+// this instance, e.g. to invalidate caches.
+func (f *ChangeFeed) OnChange(fn func(domain.Change)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.listeners = append(f.listeners, fn)
+}
+
+// Record appends a change for bookID and wakes up pending readers. book is
+// the state right after the change (nil for deletes) and becomes the
+// entry's payload; the actor in ctx, if any, is stamped on the entry.
+func (f *ChangeFeed) Record(ctx context.Context, bookID int64, op string, book *domain.Book) error {
+	_, err := f.record(ctx, bookID, op, book)
+	return err
+}
+
+// record is Record returning the entry appended, with its id and version.
+func (f *ChangeFeed) record(ctx context.Context, bookID int64, op string, book *domain.Book) (domain.Change, error) {
+	payload, err := domain.NewChangePayload(book)
+	if err != nil {
+		return domain.Change{}, err
+	}
+	c := domain.Change{
+		Entity:    domain.EntityBook,
+		BookID:    bookID,
+		Op:        op,
+		Payload:   payload,
+		CreatedAt: clock().UTC(),
+	}
+	if a, ok := domain.ActorFrom(ctx); ok {
+		c.Actor, c.ImpersonatedBy = a.ID, a.ImpersonatedBy
+	}
+	id, err := f.repo.Append(ctx, &c)
+	if err != nil {
+		return domain.Change{}, err
+	}
+	c.ID = id
+	f.mu.Lock()
+	close(f.notify)
+	f.notify = make(chan struct{})
+	listeners := f.listeners
+	f.mu.Unlock()
+	for _, fn := range listeners {
+		fn(c)
+	}
+	return c, nil
+}
+
+// replayBatch is the page size Replay reads the change log with.
+const replayBatch = 500
+
+// Replay calls fn for every change after cursor in log order, e.g. to rebuild
+// a read model from scratch. It returns the cursor of the last change seen.
+func (f *ChangeFeed) Replay(ctx context.Context, cursor int64, fn func(domain.Change) error) (int64, error) {
+	for {
+		changes, err := f.repo.ListSince(ctx, cursor, replayBatch)
+		if err != nil {
+			return cursor, err
+		}
+		for _, c := range changes {
+			if err := fn(c); err != nil {
+				return cursor, err
+			}
+			cursor = c.ID
+		}
+		if len(changes) < replayBatch {
+			return cursor, nil
+		}
+	}
+}
+
+// Compact drops superseded entries older than retention.
+func (f *ChangeFeed) Compact(ctx context.Context, retention time.Duration) (int64, error) {
+	return f.repo.Compact(ctx, time.Now().UTC().Add(-retention))
+}
+
+// RunCompaction compacts every interval until ctx is done.
+func (f *ChangeFeed) RunCompaction(ctx context.Context, retention, every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		Heartbeat(ctx)
+		n, err := f.Compact(ctx, retention)
+		if err != nil {
+			logger.From(ctx).Error("failed to compact change log", "error", err)
+		} else if n > 0 {
+			logger.From(ctx).Info("compacted change log", "removed", n)
+		}
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (f *ChangeFeed) Since(ctx context.Context, cursor int64, limit int, wait time.Duration) ([]domain.Change, error) {
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+	poll := time.NewTicker(f.pollEvery)
+	defer poll.Stop()
+
+	for {
+		// grab the channel before reading so a Record in between isn't missed
+		f.mu.Lock()
+		woken := f.notify
+		f.mu.Unlock()
+
+		changes, err := f.repo.ListSince(ctx, cursor, limit)
+		if err != nil || len(changes) > 0 || wait <= 0 {
+			return changes, err
+		}
+
+		select {
+		case <-woken:
+		case <-poll.C:
+		case <-deadline.C:
+			return changes, nil
+		case <-ctx.Done():
+			return changes, ctx.Err()
+		}
+	}
+}
+
+// LatestID returns the cursor of the newest change.
+func (f *ChangeFeed) LatestID(ctx context.Context) (int64, error) {
+	return f.repo.LatestID(ctx)
+}
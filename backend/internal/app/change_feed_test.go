@@ -0,0 +1,362 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// ---- In-memory ports.ChangeRepository ----
+
+type memChangeRepo struct {
+	mu      sync.Mutex
+	changes []domain.Change
+	err     error
+}
+
+func (m *memChangeRepo) Append(ctx context.Context, c *domain.Change) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return 0, m.err
+	}
+	c.ID = int64(len(m.changes) + 1)
+	for _, prev := range m.changes {
+		if prev.Entity == c.Entity && prev.BookID == c.BookID {
+			c.Version = max(c.Version, prev.Version)
+		}
+	}
+	c.Version++
+	m.changes = append(m.changes, *c)
+	return c.ID, nil
+}
+
+func (m *memChangeRepo) ListSince(ctx context.Context, cursor int64, limit int) ([]domain.Change, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := []domain.Change{}
+	for _, c := range m.changes {
+		if c.ID > cursor && len(out) < limit {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (m *memChangeRepo) Compact(ctx context.Context, cutoff time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	latest := map[int64]int64{}
+	for _, c := range m.changes {
+		latest[c.BookID] = max(latest[c.BookID], c.Version)
+	}
+	kept := m.changes[:0]
+	for _, c := range m.changes {
+		if c.CreatedAt.Before(cutoff) && c.Version < latest[c.BookID] {
+			continue
+		}
+		kept = append(kept, c)
+	}
+	n := int64(len(m.changes) - len(kept))
+	m.changes = kept
+	return n, nil
+}
+
+func (m *memChangeRepo) BookAt(ctx context.Context, bookID int64, t time.Time) (*domain.Change, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.changes) - 1; i >= 0; i-- {
+		if c := m.changes[i]; c.BookID == bookID && !c.CreatedAt.After(t) {
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memChangeRepo) BooksAt(ctx context.Context, t time.Time) ([]domain.Change, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []domain.Change
+	for i := len(m.changes) - 1; i >= 0; i-- {
+		c := m.changes[i]
+		if !c.CreatedAt.After(t) && !slices.ContainsFunc(out, func(o domain.Change) bool { return o.BookID == c.BookID }) {
+			out = append(out, c)
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.Change) int { return int(b.BookID - a.BookID) })
+	return out, nil
+}
+
+func (m *memChangeRepo) LatestID(ctx context.Context) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return int64(len(m.changes)), nil
+}
+
+func TestChangeFeed_SinceReturnsImmediately(t *testing.T) {
+	repo := &memChangeRepo{}
+	feed := NewChangeFeed(repo)
+	_ = feed.Record(context.Background(), 1, domain.ChangeCreated, nil)
+	_ = feed.Record(context.Background(), 1, domain.ChangeUpdated, nil)
+
+	got, err := feed.Since(context.Background(), 1, 10, time.Minute)
+	if err != nil {
+		t.Fatalf("Since err: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != 2 || got[0].Op != domain.ChangeUpdated {
+		t.Fatalf("unexpected: %+v", got)
+	}
+}
+
+func TestChangeFeed_WakesOnRecord(t *testing.T) {
+	feed := NewChangeFeed(&memChangeRepo{})
+	feed.pollEvery = time.Hour // only the notification can wake us
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_ = feed.Record(context.Background(), 7, domain.ChangeDeleted, nil)
+	}()
+
+	start := time.Now()
+	got, err := feed.Since(context.Background(), 0, 10, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Since err: %v", err)
+	}
+	if len(got) != 1 || got[0].BookID != 7 {
+		t.Fatalf("unexpected: %+v", got)
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Fatalf("Since was not woken by Record")
+	}
+}
+
+func TestChangeFeed_TimeoutReturnsEmpty(t *testing.T) {
+	feed := NewChangeFeed(&memChangeRepo{})
+	feed.pollEvery = 5 * time.Millisecond
+
+	got, err := feed.Since(context.Background(), 0, 10, 30*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Since err: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("want no changes; got %+v", got)
+	}
+}
+
+func TestChangeFeed_ContextCanceled(t *testing.T) {
+	feed := NewChangeFeed(&memChangeRepo{})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := feed.Since(ctx, 0, 10, time.Minute); !errors.Is(err, context.Canceled) {
+		t.Fatalf("want context.Canceled; got %v", err)
+	}
+}
+
+func TestBookService_RecordsChanges(t *testing.T) {
+	changes := &memChangeRepo{}
+	m := &mockRepo{
+		CreateFn: func(ctx context.Context, b *domain.Book) (int64, error) { return 5, nil },
+		GetByIDFn: func(ctx context.Context, id int64) (*domain.Book, error) {
+			return &domain.Book{ID: id, Title: "Old"}, nil
+		},
+		UpdateFn: func(ctx context.Context, b *domain.Book) error { return nil },
+		DeleteFn: func(ctx context.Context, id int64) error { return nil },
+	}
+	svc := NewBookService(m, WithChangeFeed(NewChangeFeed(changes)))
+	ctx := context.Background()
+
+	if _, err := svc.CreateBook(ctx, validCreateInput()); err != nil {
+		t.Fatalf("CreateBook err: %v", err)
+	}
+	if _, err := svc.UpdateBook(ctx, 5, updateTitle("New")); err != nil {
+		t.Fatalf("UpdateBook err: %v", err)
+	}
+	if err := svc.DeleteBook(ctx, 5); err != nil {
+		t.Fatalf("DeleteBook err: %v", err)
+	}
+
+	want := []string{domain.ChangeCreated, domain.ChangeUpdated, domain.ChangeDeleted}
+	if len(changes.changes) != len(want) {
+		t.Fatalf("recorded %d changes; want %d", len(changes.changes), len(want))
+	}
+	for i, c := range changes.changes {
+		if c.BookID != 5 || c.Op != want[i] || c.Version != int64(i+1) || c.Entity != domain.EntityBook {
+			t.Fatalf("change %d = %+v; want book 5 op %s version %d", i, c, want[i], i+1)
+		}
+	}
+	if b, _ := changes.changes[1].Book(); b == nil || b.Title != "New" {
+		t.Fatalf("update payload = %+v", b)
+	}
+	if len(changes.changes[2].Payload) != 0 {
+		t.Fatalf("delete payload = %s", changes.changes[2].Payload)
+	}
+}
+
+// publisherFunc adapts a function to ports.EventPublisher.
+type publisherFunc func(ctx context.Context, e domain.Event) error
+
+func (f publisherFunc) Publish(ctx context.Context, e domain.Event) error { return f(ctx, e) }
+
+func TestBookService_PublishesRecordedChanges(t *testing.T) {
+	changes := &memChangeRepo{}
+	var published []domain.Event
+	events := publisherFunc(func(ctx context.Context, e domain.Event) error {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("publish without a deadline")
+		}
+		published = append(published, e)
+		return errors.New("broker down")
+	})
+	m := &mockRepo{
+		CreateFn: func(ctx context.Context, b *domain.Book) (int64, error) { return 5, nil },
+		DeleteFn: func(ctx context.Context, id int64) error { return nil },
+	}
+	svc := NewBookService(m, WithChangeFeed(NewChangeFeed(changes)), WithEventPublisher(events))
+	// a caller gone after the write still gets its event published
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// a publish failing doesn't fail the write
+	if _, err := svc.CreateBook(ctx, validCreateInput()); err != nil {
+		t.Fatalf("CreateBook err: %v", err)
+	}
+	if err := svc.DeleteBook(ctx, 5); err != nil {
+		t.Fatalf("DeleteBook err: %v", err)
+	}
+	if len(published) != 2 {
+		t.Fatalf("published %+v", published)
+	}
+	if e := published[0]; e.Offset != 1 || e.Type != "book.created" || e.EntityID != 5 || e.Version != 1 || len(e.Data) == 0 {
+		t.Fatalf("created event = %+v", e)
+	}
+	if e := published[1]; e.Offset != 2 || e.Type != "book.deleted" || e.Version != 2 || len(e.Data) != 0 {
+		t.Fatalf("deleted event = %+v", e)
+	}
+}
+
+func TestBookService_ParksUnpublishedEvents(t *testing.T) {
+	down := true
+	var published []domain.Event
+	events := publisherFunc(func(ctx context.Context, e domain.Event) error {
+		if down {
+			return errors.New("broker down")
+		}
+		published = append(published, e)
+		return nil
+	})
+	letters := &memDeadLetterRepo{}
+	dlq := NewDeadLetters(letters)
+	m := &mockRepo{CreateFn: func(ctx context.Context, b *domain.Book) (int64, error) { return 5, nil }}
+	svc := NewBookService(m, WithChangeFeed(NewChangeFeed(&memChangeRepo{})), WithEventPublisher(events), WithEventRequeue(dlq))
+
+	if _, err := svc.CreateBook(context.Background(), validCreateInput()); err != nil {
+		t.Fatalf("CreateBook err: %v", err)
+	}
+	if len(letters.letters) != 1 {
+		t.Fatalf("dead letters = %+v", letters.letters)
+	}
+	dl := letters.letters[0]
+	if dl.Kind != KindEventPublish || dl.Error != "broker down" {
+		t.Fatalf("dead letter = %+v", dl)
+	}
+
+	// still down: the letter stays
+	if err := dlq.RetryDeadLetter(context.Background(), dl.ID); err == nil || len(letters.letters) != 1 {
+		t.Fatalf("RetryDeadLetter = %v, letters = %d", err, len(letters.letters))
+	}
+	down = false
+	if err := dlq.RetryDeadLetter(context.Background(), dl.ID); err != nil {
+		t.Fatalf("RetryDeadLetter err: %v", err)
+	}
+	if len(letters.letters) != 0 || len(published) != 1 {
+		t.Fatalf("letters = %+v, published = %+v", letters.letters, published)
+	}
+	if e := published[0]; e.Offset != 1 || e.Type != "book.created" || e.EntityID != 5 || e.Version != 1 || len(e.Data) == 0 {
+		t.Fatalf("replayed event = %+v", e)
+	}
+}
+
+func TestChangeFeed_StampsActor(t *testing.T) {
+	repo := &memChangeRepo{}
+	feed := NewChangeFeed(repo)
+	ctx := domain.WithActor(context.Background(), domain.Actor{ID: "ann", ImpersonatedBy: "root"})
+
+	if err := feed.Record(ctx, 1, domain.ChangeDeleted, nil); err != nil {
+		t.Fatalf("Record err: %v", err)
+	}
+	_ = feed.Record(context.Background(), 2, domain.ChangeDeleted, nil)
+	if c := repo.changes[0]; c.Actor != "ann" || c.ImpersonatedBy != "root" {
+		t.Fatalf("change = %+v", c)
+	}
+	if c := repo.changes[1]; c.Actor != "" || c.ImpersonatedBy != "" {
+		t.Fatalf("background change = %+v", c)
+	}
+}
+
+func TestBookService_ChangeLogFailureDoesNotFailMutation(t *testing.T) {
+	m := &mockRepo{
+		DeleteFn: func(ctx context.Context, id int64) error { return nil },
+	}
+	svc := NewBookService(m, WithChangeFeed(NewChangeFeed(&memChangeRepo{err: errors.New("log down")})))
+
+	if err := svc.DeleteBook(context.Background(), 1); err != nil {
+		t.Fatalf("DeleteBook err: %v", err)
+	}
+}
+
+func TestChangeFeed_Replay(t *testing.T) {
+	repo := &memChangeRepo{}
+	feed := NewChangeFeed(repo)
+	ctx := context.Background()
+	for i := 0; i < replayBatch+3; i++ {
+		_ = feed.Record(ctx, int64(i%4), domain.ChangeUpdated, &domain.Book{ID: int64(i % 4), Title: "v"})
+	}
+
+	seen := 0
+	cursor, err := feed.Replay(ctx, 0, func(c domain.Change) error {
+		seen++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Replay err: %v", err)
+	}
+	if seen != replayBatch+3 || cursor != int64(replayBatch+3) {
+		t.Fatalf("seen %d, cursor %d", seen, cursor)
+	}
+
+	stop := errors.New("stop")
+	if _, err := feed.Replay(ctx, 0, func(domain.Change) error { return stop }); !errors.Is(err, stop) {
+		t.Fatalf("want callback error; got %v", err)
+	}
+}
+
+func TestChangeFeed_CompactKeepsLatestPerBook(t *testing.T) {
+	old := time.Now().Add(-48 * time.Hour)
+	repo := &memChangeRepo{changes: []domain.Change{
+		{ID: 1, BookID: 1, Op: domain.ChangeCreated, Version: 1, CreatedAt: old},
+		{ID: 2, BookID: 1, Op: domain.ChangeUpdated, Version: 2, CreatedAt: old},
+		{ID: 3, BookID: 2, Op: domain.ChangeCreated, Version: 1, CreatedAt: old},
+		{ID: 4, BookID: 2, Op: domain.ChangeDeleted, Version: 2, CreatedAt: old},
+		{ID: 5, BookID: 3, Op: domain.ChangeCreated, Version: 1, CreatedAt: time.Now()},
+		{ID: 6, BookID: 3, Op: domain.ChangeUpdated, Version: 2, CreatedAt: time.Now()},
+	}}
+	feed := NewChangeFeed(repo)
+
+	n, err := feed.Compact(context.Background(), 24*time.Hour)
+	if err != nil || n != 2 {
+		t.Fatalf("Compact = %d, %v; want 2", n, err)
+	}
+	var ids []int64
+	for _, c := range repo.changes {
+		ids = append(ids, c.ID)
+	}
+	if want := []int64{2, 4, 5, 6}; !slices.Equal(ids, want) {
+		t.Fatalf("kept %v; want %v", ids, want)
+	}
+}
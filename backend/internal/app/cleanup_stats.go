@@ -0,0 +1,176 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// MaxCleanupStatsDays is the longest period GET /url/cleanup/stats reports on.
+const MaxCleanupStatsDays = 90
+
+// maxHostLength is the longest DNS name.
+const maxHostLength = 253
+
+// CleanupStats buffers URL cleanup counts in memory and periodically adds
+// them to the repository, like ViewCounter does for book views.
+type CleanupStats struct {
+	repo    ports.CleanupStatsRepository
+	cleaner *URLCleaner // resolves operations to the rules they apply
+
+	mu      sync.Mutex
+	pending map[domain.CleanupUsageKey]int64
+}
+
+var _ ports.CleanupStatsService = (*CleanupStats)(nil)
+
+func NewCleanupStats(repo ports.CleanupStatsRepository, cleaner *URLCleaner) *CleanupStats {
+	return &CleanupStats{repo: repo, cleaner: cleaner, pending: map[domain.CleanupUsageKey]int64{}}
+}
+
+// Record counts one cleanup request of host. Operations that aren't known
+// are counted as "", so invalid input can't flood the table with new rows.
+func (s *CleanupStats) Record(host, operation, outcome string) {
+	if operation != "resolve" && !s.cleaner.Has(operation) {
+		operation = ""
+	}
+	host = strings.ToLower(host)
+	if len(host) > maxHostLength {
+		host = host[:maxHostLength]
+	}
+	k := domain.CleanupUsageKey{
+		Day:       clock().UTC().Truncate(24 * time.Hour),
+		Domain:    host,
+		Operation: operation,
+		Outcome:   outcome,
+	}
+	s.mu.Lock()
+	s.pending[k]++
+	s.mu.Unlock()
+}
+
+// Flush writes the buffered counts. On failure they are kept for the next
+// attempt.
+func (s *CleanupStats) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = map[domain.CleanupUsageKey]int64{}
+	s.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+	if err := s.repo.AddCleanupUsage(ctx, batch); err != nil {
+		s.mu.Lock()
+		for k, n := range batch {
+			s.pending[k] += n
+		}
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Run flushes every interval until ctx is done, then flushes once more.
+func (s *CleanupStats) Run(ctx context.Context, every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			Heartbeat(ctx)
+			if err := s.Flush(ctx); err != nil {
+				logger.From(ctx).Error("failed to flush cleanup stats", "error", err)
+			}
+		case <-ctx.Done():
+			if err := s.Flush(context.Background()); err != nil {
+				logger.From(ctx).Error("failed to flush cleanup stats", "error", err)
+			}
+			return
+		}
+	}
+}
+
+// CleanupStats reports on the requests of today and the days-1 days before,
+// as far as they have been flushed.
+func (s *CleanupStats) CleanupStats(ctx context.Context, days, top int) (*domain.CleanupStats, error) {
+	errs := &ValidationError{}
+	if days < 1 || days > MaxCleanupStatsDays {
+		errs.add("days", fmt.Sprintf("days must be between 1 and %d", MaxCleanupStatsDays))
+	}
+	if top < 1 || top > 100 {
+		errs.add("top", "top must be between 1 and 100")
+	}
+	if !errs.ok() {
+		return nil, errs
+	}
+
+	since := clock().UTC().Truncate(24*time.Hour).AddDate(0, 0, -(days - 1))
+	usage, err := s.repo.CleanupUsageSince(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	out := &domain.CleanupStats{Since: since}
+	domains, ops, rules := map[string]*domain.CleanupCount{}, map[string]*domain.CleanupCount{}, map[string]*domain.CleanupCount{}
+	for _, u := range usage {
+		failed := u.Outcome != domain.CleanupOK
+		out.Requests += u.Requests
+		if failed {
+			out.Errors += u.Requests
+		}
+		if u.Domain != "" {
+			tally(domains, u.Domain, u.Requests, failed)
+		}
+		tally(ops, u.Operation, u.Requests, failed)
+		for _, r := range s.cleaner.Rules(u.Operation) {
+			tally(rules, r, u.Requests, failed)
+		}
+	}
+	out.ErrorRate = rate(out.Errors, out.Requests)
+	out.TopDomains = ranked(domains, top)
+	out.Operations = ranked(ops, len(ops))
+	out.Rules = ranked(rules, len(rules))
+	return out, nil
+}
+
+func tally(counts map[string]*domain.CleanupCount, name string, n int64, failed bool) {
+	c := counts[name]
+	if c == nil {
+		c = &domain.CleanupCount{Name: name}
+		counts[name] = c
+	}
+	c.Requests += n
+	if failed {
+		c.Errors += n
+	}
+}
+
+// ranked returns the n busiest counts with their error rates, busiest first.
+func ranked(counts map[string]*domain.CleanupCount, n int) []domain.CleanupCount {
+	out := make([]domain.CleanupCount, 0, len(counts))
+	for _, c := range counts {
+		c.ErrorRate = rate(c.Errors, c.Requests)
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Requests != out[j].Requests {
+			return out[i].Requests > out[j].Requests
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out[:min(n, len(out))]
+}
+
+func rate(errors, requests int64) float64 {
+	if requests == 0 {
+		return 0
+	}
+	return float64(errors) / float64(requests)
+}
@@ -0,0 +1,103 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+type memCleanupStatsRepo struct {
+	counts map[domain.CleanupUsageKey]int64
+	err    error
+	since  time.Time
+}
+
+func (m *memCleanupStatsRepo) AddCleanupUsage(_ context.Context, counts map[domain.CleanupUsageKey]int64) error {
+	if m.err != nil {
+		return m.err
+	}
+	for k, n := range counts {
+		m.counts[k] += n
+	}
+	return nil
+}
+
+func (m *memCleanupStatsRepo) CleanupUsageSince(_ context.Context, since time.Time) ([]domain.CleanupUsage, error) {
+	m.since = since
+	sums := map[domain.CleanupUsage]int64{}
+	for k, n := range m.counts {
+		if !k.Day.Before(since) {
+			sums[domain.CleanupUsage{Domain: k.Domain, Operation: k.Operation, Outcome: k.Outcome}] += n
+		}
+	}
+	var out []domain.CleanupUsage
+	for u, n := range sums {
+		u.Requests = n
+		out = append(out, u)
+	}
+	return out, nil
+}
+
+func TestCleanupStats(t *testing.T) {
+	now := time.Date(2024, 1, 10, 15, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return now })
+	t.Cleanup(func() { SetClock(time.Now) })
+
+	repo := &memCleanupStatsRepo{counts: map[domain.CleanupUsageKey]int64{
+		// outside a 7-day window
+		{Day: now.AddDate(0, 0, -7).Truncate(24 * time.Hour), Domain: "old.com", Operation: "all", Outcome: domain.CleanupOK}: 50,
+	}}
+	cleaner, _ := NewURLCleaner(nil)
+	s := NewCleanupStats(repo, cleaner)
+	for i := 0; i < 3; i++ {
+		s.Record("Example.com", "canonical", domain.CleanupOK)
+	}
+	s.Record("example.com", "resolve", domain.CleanupUnreachable)
+	s.Record("books.org", "redirection", domain.CleanupOK)
+	s.Record("", "shorten", domain.CleanupInvalid)
+
+	repo.err = errors.New("db down")
+	if err := s.Flush(context.Background()); err == nil {
+		t.Fatalf("Flush hid the repository error")
+	}
+	repo.err = nil
+	if err := s.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if n := repo.counts[domain.CleanupUsageKey{Day: now.Truncate(24 * time.Hour), Domain: "example.com", Operation: "canonical", Outcome: domain.CleanupOK}]; n != 3 {
+		t.Fatalf("example.com canonical = %d after a failed and a good flush; want 3", n)
+	}
+
+	stats, err := s.CleanupStats(context.Background(), 7, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stats.Since.Equal(time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)) || stats.Requests != 6 || stats.Errors != 2 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	if len(stats.TopDomains) != 1 || stats.TopDomains[0] != (domain.CleanupCount{Name: "example.com", Requests: 4, Errors: 1, ErrorRate: 0.25}) {
+		t.Fatalf("top domains = %+v", stats.TopDomains)
+	}
+	ops := map[string]int64{}
+	for _, o := range stats.Operations {
+		ops[o.Name] = o.Requests
+	}
+	if ops["canonical"] != 3 || ops["resolve"] != 1 || ops[""] != 1 || ops["shorten"] != 0 {
+		t.Fatalf("operations = %+v", stats.Operations)
+	}
+	rules := map[string]int64{}
+	for _, r := range stats.Rules {
+		rules[r.Name] = r.Requests
+	}
+	// canonical and redirection both trim the trailing slash
+	if rules["trim_trailing_slash"] != 4 || rules["drop_query"] != 3 || rules["add_www"] != 1 {
+		t.Fatalf("rules = %+v", stats.Rules)
+	}
+
+	if _, err := s.CleanupStats(context.Background(), 0, 500); err == nil {
+		t.Fatalf("out of range days and top accepted")
+	}
+}
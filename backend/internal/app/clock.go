@@ -0,0 +1,11 @@
+package app
+
+import "time"
+
+// clock stamps created/updated times and change log entries.
+var clock = time.Now
+
+// SetClock replaces the time source of every service, e.g. with a fixed
+// instant so demo runs produce identical output. Call it before building the
+// services and serving requests.
+func SetClock(now func() time.Time) { clock = now }
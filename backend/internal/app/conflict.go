@@ -0,0 +1,86 @@
+package app
+
+import (
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// ConflictError is returned when an update touches fields that somebody else
+// modified after the client's base_updated_at, or (Fields is ["version"])
+// when the book is past the version the client sent.
+type ConflictError struct {
+	Fields []string `json:"fields"`
+	// Version is the book's current version, set for version conflicts.
+	Version int64 `json:"version,omitempty"`
+}
+
+func (c *ConflictError) Error() string { return "conflicting update" }
+
+// changedFields lists the fields of in whose value differs from b, in
+// domain.BookFields order.
+func changedFields(b *domain.Book, in ports.UpdateBookInput) []string {
+	var out []string
+	if in.Title != nil && *in.Title != b.Title {
+		out = append(out, "title")
+	}
+	if in.Author != nil && *in.Author != b.Author {
+		out = append(out, "author")
+	}
+	if in.ISBN != nil && *in.ISBN != b.ISBN {
+		out = append(out, "isbn")
+	}
+	if in.Price != nil && *in.Price != b.Price {
+		out = append(out, "price")
+	}
+	if in.PublicationYear != nil && *in.PublicationYear != b.PublicationYear {
+		out = append(out, "publication_year")
+	}
+	if in.PublisherID != nil && *in.PublisherID != idOrZero(b.PublisherID) {
+		out = append(out, "publisher_id")
+	}
+	if in.SeriesID != nil && *in.SeriesID != idOrZero(b.SeriesID) {
+		out = append(out, "series_id")
+	}
+	// leaving the series clears the position too
+	position := in.SeriesPosition
+	if in.SeriesID != nil && *in.SeriesID == 0 && position == nil {
+		position = new(int)
+	}
+	if position != nil && *position != idOrZero(b.SeriesPosition) {
+		out = append(out, "series_position")
+	}
+	if in.Format != nil && *in.Format != b.Format {
+		out = append(out, "format")
+	}
+	if in.WorkID != nil && *in.WorkID != idOrZero(b.WorkID) {
+		out = append(out, "work_id")
+	}
+	return out
+}
+
+// checkFieldConflicts rejects the update when any field it changes was
+// modified after base. Without a base the update is last-write-wins per field.
+func checkFieldConflicts(b *domain.Book, changed []string, base *time.Time) error {
+	if base == nil {
+		return nil
+	}
+	// stored times are rounded to microseconds (DATETIME(6)); do the same
+	// for the client's copy so its own write never looks newer
+	since := base.Round(time.Microsecond)
+	conflict := &ConflictError{}
+	for _, f := range changed {
+		at, ok := b.FieldUpdatedAt[f]
+		if !ok {
+			at = b.UpdatedAt // rows written before per-field tracking
+		}
+		if at.After(since) {
+			conflict.Fields = append(conflict.Fields, f)
+		}
+	}
+	if len(conflict.Fields) > 0 {
+		return conflict
+	}
+	return nil
+}
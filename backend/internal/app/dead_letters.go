@@ -0,0 +1,119 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+const (
+	defaultDeadLetterLimit = 50
+	maxDeadLetterLimit     = 500
+)
+
+// RetryFunc runs the work of a dead letter again from its payload.
+type RetryFunc func(ctx context.Context, payload []byte) error
+
+// RetryFailedError is returned by RetryDeadLetter when the work failed again.
+type RetryFailedError struct {
+	Err error
+}
+
+func (e *RetryFailedError) Error() string { return "retry failed: " + e.Err.Error() }
+func (e *RetryFailedError) Unwrap() error { return e.Err }
+
+// DeadLetters parks failed async work and lets an operator list, retry or
+// discard it. Each kind of work registers the RetryFunc that knows how to run
+// it again; dead letters of a kind nobody registered can only be discarded.
+type DeadLetters struct {
+	repo ports.DeadLetterRepository
+	now  func() time.Time
+
+	mu       sync.RWMutex
+	handlers map[string]RetryFunc
+}
+
+func NewDeadLetters(repo ports.DeadLetterRepository) *DeadLetters {
+	return &DeadLetters{repo: repo, now: clock, handlers: map[string]RetryFunc{}}
+}
+
+// Register makes dead letters of kind retryable with retry.
+func (d *DeadLetters) Register(kind string, retry RetryFunc) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers[kind] = retry
+}
+
+// Park stores work that failed with cause so it isn't lost. payload must be
+// what the kind's RetryFunc expects, encoded as JSON.
+func (d *DeadLetters) Park(ctx context.Context, kind string, payload any, cause error) error {
+	p, err := domain.NewChangePayload(payload)
+	if err != nil {
+		return fmt.Errorf("encode %s dead letter: %w", kind, err)
+	}
+	now := d.now().UTC()
+	dl := &domain.DeadLetter{Kind: kind, Payload: p, Error: cause.Error(), Attempts: 1, CreatedAt: now, LastAttemptAt: now}
+	if dl.ID, err = d.repo.Add(ctx, dl); err != nil {
+		return err
+	}
+	logger.From(ctx).Error("async work dead-lettered", "kind", kind, "id", dl.ID, "error", cause)
+	return nil
+}
+
+// ListDeadLetters returns the newest dead letters first, only those of kind
+// when it is set. A limit of 0 means the default; larger limits are capped.
+func (d *DeadLetters) ListDeadLetters(ctx context.Context, kind string, limit int) ([]domain.DeadLetter, error) {
+	if limit <= 0 {
+		limit = defaultDeadLetterLimit
+	}
+	return d.repo.List(ctx, kind, min(limit, maxDeadLetterLimit))
+}
+
+func (d *DeadLetters) GetDeadLetter(ctx context.Context, id int64) (*domain.DeadLetter, error) {
+	dl, err := d.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if dl == nil {
+		return nil, errors.New("dead letter not found")
+	}
+	return dl, nil
+}
+
+func (d *DeadLetters) RetryDeadLetter(ctx context.Context, id int64) error {
+	dl, err := d.GetDeadLetter(ctx, id)
+	if err != nil {
+		return err
+	}
+	d.mu.RLock()
+	retry := d.handlers[dl.Kind]
+	d.mu.RUnlock()
+	if retry == nil {
+		return &ValidationError{Fields: map[string]string{"kind": fmt.Sprintf("%q dead letters can't be retried, only discarded", dl.Kind)}}
+	}
+	if cause := retry(ctx, dl.Payload); cause != nil {
+		if err := d.repo.Failed(ctx, id, cause.Error(), d.now().UTC()); err != nil {
+			return err
+		}
+		return &RetryFailedError{Err: cause}
+	}
+	_, err = d.repo.Delete(ctx, id)
+	return err
+}
+
+func (d *DeadLetters) DiscardDeadLetter(ctx context.Context, id int64) error {
+	found, err := d.repo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.New("dead letter not found")
+	}
+	return nil
+}
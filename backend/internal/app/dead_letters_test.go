@@ -0,0 +1,158 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// ---- In-memory ports.DeadLetterRepository ----
+
+type memDeadLetterRepo struct {
+	letters []domain.DeadLetter
+	nextID  int64
+}
+
+func (m *memDeadLetterRepo) Add(ctx context.Context, d *domain.DeadLetter) (int64, error) {
+	m.nextID++
+	d.ID = m.nextID
+	m.letters = append(m.letters, *d)
+	return d.ID, nil
+}
+func (m *memDeadLetterRepo) List(ctx context.Context, kind string, limit int) ([]domain.DeadLetter, error) {
+	out := []domain.DeadLetter{}
+	for i := len(m.letters) - 1; i >= 0 && len(out) < limit; i-- {
+		if kind == "" || m.letters[i].Kind == kind {
+			out = append(out, m.letters[i])
+		}
+	}
+	return out, nil
+}
+func (m *memDeadLetterRepo) GetByID(ctx context.Context, id int64) (*domain.DeadLetter, error) {
+	for _, d := range m.letters {
+		if d.ID == id {
+			return &d, nil
+		}
+	}
+	return nil, nil
+}
+func (m *memDeadLetterRepo) Failed(ctx context.Context, id int64, reason string, at time.Time) error {
+	for i := range m.letters {
+		if m.letters[i].ID == id {
+			m.letters[i].Error, m.letters[i].LastAttemptAt = reason, at
+			m.letters[i].Attempts++
+		}
+	}
+	return nil
+}
+func (m *memDeadLetterRepo) Delete(ctx context.Context, id int64) (bool, error) {
+	for i, d := range m.letters {
+		if d.ID == id {
+			m.letters = append(m.letters[:i], m.letters[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestDeadLetters_ParksFailedNotificationsForRetry(t *testing.T) {
+	feed := NewChangeFeed(&memChangeRepo{})
+	notifier := &recordingNotifier{err: errors.New("smtp down")}
+	svc := NewSavedSearches(&memSavedSearchRepo{}, feed, notifier)
+	repo := &memDeadLetterRepo{}
+	dlq := NewDeadLetters(repo)
+	svc.UseDeadLetters(dlq)
+	ctx := domain.WithActor(context.Background(), domain.Actor{ID: "ann@example.com"})
+
+	if _, err := svc.CreateSavedSearch(ctx, ports.CreateSavedSearchInput{Name: "Borges", Query: "author:borges", Notify: true}); err != nil {
+		t.Fatal(err)
+	}
+	for id, title := range map[int64]string{7: "Ficciones", 8: "El Aleph"} {
+		_ = feed.Record(ctx, id, domain.ChangeCreated, &domain.Book{ID: id, Title: title, Author: "Borges"})
+	}
+	if _, err := svc.catchUp(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	letters, err := dlq.ListDeadLetters(ctx, KindSearchNotification, 0)
+	if err != nil || len(letters) != 2 || letters[0].Error != "smtp down" || letters[0].Attempts != 1 {
+		t.Fatalf("dead letters = %+v, %v", letters, err)
+	}
+
+	// still failing: the letter stays with another attempt counted
+	var rf *RetryFailedError
+	if err := dlq.RetryDeadLetter(ctx, 1); !errors.As(err, &rf) {
+		t.Fatalf("Retry = %v; want RetryFailedError", err)
+	}
+	if d, _ := dlq.GetDeadLetter(ctx, 1); d.Attempts != 2 {
+		t.Fatalf("after failed retry = %+v", d)
+	}
+
+	notifier.err, notifier.sent = nil, nil
+	if err := dlq.RetryDeadLetter(ctx, 1); err != nil {
+		t.Fatalf("Retry = %v", err)
+	}
+	if len(notifier.sent) != 1 || notifier.sent[0].SearchID != 1 {
+		t.Fatalf("redelivered %+v", notifier.sent)
+	}
+	if _, err := dlq.GetDeadLetter(ctx, 1); err == nil || err.Error() != "dead letter not found" {
+		t.Fatalf("retried letter still there: %v", err)
+	}
+	if err := dlq.DiscardDeadLetter(ctx, 2); err != nil || len(repo.letters) != 0 {
+		t.Fatalf("Discard = %v, left %+v", err, repo.letters)
+	}
+	if err := dlq.DiscardDeadLetter(ctx, 2); err == nil || err.Error() != "dead letter not found" {
+		t.Fatalf("Discard missing = %v", err)
+	}
+}
+
+func TestDeadLetters_UnknownKindCanOnlyBeDiscarded(t *testing.T) {
+	dlq := NewDeadLetters(&memDeadLetterRepo{})
+	ctx := context.Background()
+	if err := dlq.Park(ctx, "webhook", map[string]string{"url": "https://example.com"}, errors.New("timeout")); err != nil {
+		t.Fatal(err)
+	}
+	var ve *ValidationError
+	if err := dlq.RetryDeadLetter(ctx, 1); !errors.As(err, &ve) || ve.Fields["kind"] == "" {
+		t.Fatalf("Retry = %v; want kind validation error", err)
+	}
+	if err := dlq.DiscardDeadLetter(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDeadLetters_RetriesCatalogEvent(t *testing.T) {
+	repo := newMemBookRepo()
+	books := NewBookService(repo)
+	c := NewCatalogInbox(books, &memInbox{msgs: map[string]domain.InboxMessage{}})
+	dlq := NewDeadLetters(&memDeadLetterRepo{})
+	c.UseDeadLetters(dlq)
+	ctx := context.Background()
+
+	// a price change for a book the ERP never announced can't be created
+	if err := c.Handle(ctx, "erp", "m1", []byte(`{"type":"book.upserted","isbn":"9780802130303","price":14}`)); err != nil {
+		t.Fatalf("Handle err: %v; want it acknowledged", err)
+	}
+	if letters, _ := dlq.ListDeadLetters(ctx, KindCatalogEvent, 0); len(letters) != 1 {
+		t.Fatalf("dead letters = %+v", letters)
+	}
+	// malformed messages have nothing to retry
+	_ = c.Handle(ctx, "erp", "m2", []byte(`not json`))
+	if letters, _ := dlq.ListDeadLetters(ctx, "", 0); len(letters) != 1 {
+		t.Fatalf("dead letters = %+v", letters)
+	}
+
+	b, err := books.CreateBook(ctx, ports.CreateBookInput{Title: "Ficciones", Author: "Borges", ISBN: "9780802130303", Price: 10, PublicationYear: 1944})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dlq.RetryDeadLetter(ctx, 1); err != nil {
+		t.Fatalf("Retry = %v", err)
+	}
+	if got := repo.books[b.ID]; got.Price != 14 {
+		t.Fatalf("book after retry = %+v", got)
+	}
+}
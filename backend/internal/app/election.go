@@ -0,0 +1,172 @@
+package app
+
+import (
+	"context"
+	"expvar"
+	"sync"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// Election picks one replica as leader through a lock that holds across
+// replicas: whoever holds lock name leads, the others retry every retry and
+// take over once the leader's lease is gone. Jobs wrapped with Lead run only
+// while this replica leads. The winner is recorded in the repository so
+// every replica can report who the leader is.
+type Election struct {
+	locker   ports.Locker
+	repo     ports.LeaderRepository
+	name     string
+	identity string
+	retry    time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	term     chan struct{} // closed when the current term ends; nil while following
+	changed  chan struct{} // closed and replaced whenever leadership changes
+	observed *domain.Leader
+}
+
+func NewElection(locker ports.Locker, repo ports.LeaderRepository, name, identity string, retry time.Duration) *Election {
+	return &Election{
+		locker: locker, repo: repo, name: name, identity: identity, retry: retry,
+		now: clock, changed: make(chan struct{}),
+	}
+}
+
+// Run takes part in the election until ctx is done, then steps down and
+// releases the lock so another replica takes over without waiting.
+func (e *Election) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		lease, ok, err := e.locker.TryLock(ctx, e.name)
+		if err != nil && ctx.Err() == nil {
+			logger.From(ctx).Error("failed to take leader lock", "election", e.name, "error", err)
+		}
+		if ok {
+			e.lead(ctx, lease)
+		}
+		e.observe(ctx)
+		select {
+		case <-time.After(e.retry):
+		case <-ctx.Done():
+		}
+	}
+}
+
+// lead holds a term until the lease is lost or ctx is done.
+func (e *Election) lead(ctx context.Context, lease ports.Lease) {
+	at := e.now()
+	logger.From(ctx).Info("elected leader", "election", e.name, "identity", e.identity)
+	_ = e.repo.SetLeader(ctx, e.name, e.identity, at)
+	e.mu.Lock()
+	e.term = make(chan struct{})
+	e.observed = &domain.Leader{Identity: e.identity, ElectedAt: at}
+	e.notifyLocked()
+	e.mu.Unlock()
+
+	select {
+	case <-lease.Lost():
+		logger.From(ctx).Error("leader lock lost, stepping down", "election", e.name)
+	case <-ctx.Done():
+	}
+
+	e.mu.Lock()
+	close(e.term)
+	e.term = nil
+	e.observed = nil
+	e.notifyLocked()
+	e.mu.Unlock()
+	select {
+	case <-lease.Lost():
+	default:
+		if err := lease.Release(context.WithoutCancel(ctx)); err != nil {
+			logger.From(ctx).Error("failed to release leader lock", "election", e.name, "error", err)
+		}
+	}
+}
+
+func (e *Election) notifyLocked() {
+	close(e.changed)
+	e.changed = make(chan struct{})
+}
+
+// observe refreshes who leads while following.
+func (e *Election) observe(ctx context.Context) {
+	if ctx.Err() != nil {
+		return
+	}
+	l, err := e.repo.Leader(ctx, e.name)
+	if err != nil {
+		return
+	}
+	e.mu.Lock()
+	if e.term == nil {
+		e.observed = l
+	}
+	e.mu.Unlock()
+}
+
+// Leading reports whether this replica is the leader.
+func (e *Election) Leading() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.term != nil
+}
+
+// Lead wraps job so that it only runs while this replica leads. Followers
+// wait, sending heartbeats every retry. job's context is cancelled when the
+// term ends, and job is started again in the next term; if job returns by
+// itself, so does the wrapper.
+func (e *Election) Lead(job func(ctx context.Context)) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		for ctx.Err() == nil {
+			Heartbeat(ctx)
+			e.mu.Lock()
+			term, changed := e.term, e.changed
+			e.mu.Unlock()
+			if term == nil {
+				select {
+				case <-changed:
+				case <-time.After(e.retry):
+				case <-ctx.Done():
+				}
+				continue
+			}
+			jobCtx, cancel := context.WithCancel(ctx)
+			go func() {
+				select {
+				case <-term:
+					cancel()
+				case <-jobCtx.Done():
+				}
+			}()
+			job(jobCtx)
+			cancel()
+			select {
+			case <-term:
+			default:
+				return
+			}
+		}
+	}
+}
+
+type leaderStatus struct {
+	Identity string         `json:"identity"`
+	Leading  bool           `json:"leading"`
+	Leader   *domain.Leader `json:"leader"`
+}
+
+// Publish exposes this replica's identity and the current leader as the
+// expvar "leader" (served on /debug/vars). It panics if called twice, like
+// expvar.Publish.
+func (e *Election) Publish() {
+	expvar.Publish("leader", expvar.Func(func() any {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		return leaderStatus{Identity: e.identity, Leading: e.term != nil, Leader: e.observed}
+	}))
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+type memLeaderRepo struct {
+	mu     sync.Mutex
+	leader *domain.Leader
+}
+
+func (m *memLeaderRepo) SetLeader(ctx context.Context, election, identity string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.leader = &domain.Leader{Identity: identity, ElectedAt: at}
+	return nil
+}
+
+func (m *memLeaderRepo) Leader(ctx context.Context, election string) (*domain.Leader, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.leader, nil
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestElection_LeaderRunsJobsAndFailsOver(t *testing.T) {
+	locker, repo := &memLocker{}, &memLeaderRepo{}
+	a := NewElection(locker, repo, "scheduler", "a", time.Millisecond)
+	b := NewElection(locker, repo, "scheduler", "b", time.Millisecond)
+
+	running := make(chan string, 4)
+	stopped := make(chan string, 4)
+	start := func(ctx context.Context, e *Election) {
+		go e.Run(ctx)
+		go e.Lead(func(ctx context.Context) {
+			running <- e.identity
+			<-ctx.Done()
+			stopped <- e.identity
+		})(ctx)
+	}
+	ctxA, stopA := context.WithCancel(context.Background())
+	defer stopA()
+	start(ctxA, a)
+	if got := <-running; got != "a" {
+		t.Fatalf("running %s; want a", got)
+	}
+	ctxB, stopB := context.WithCancel(context.Background())
+	defer stopB()
+	start(ctxB, b)
+	waitFor(t, "b to see a as leader", func() bool {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		return b.observed != nil && b.observed.Identity == "a"
+	})
+	if b.Leading() {
+		t.Fatalf("b leads while a holds the lock")
+	}
+
+	// a crashing hands the term to b
+	locker.crash()
+	if got := <-stopped; got != "a" {
+		t.Fatalf("stopped %s; want a", got)
+	}
+	waitFor(t, "a new leader", func() bool { return a.Leading() || b.Leading() })
+	if a.Leading() && b.Leading() {
+		t.Fatalf("two leaders")
+	}
+	leader, follower, stopLeader := a, b, stopA
+	if b.Leading() {
+		leader, follower, stopLeader = b, a, stopB
+	}
+	if got := <-running; got != leader.identity {
+		t.Fatalf("running %s; want the leader %s", got, leader.identity)
+	}
+
+	// shutting down steps down and releases the lock right away
+	stopLeader()
+	if got := <-stopped; got != leader.identity {
+		t.Fatalf("stopped %s; want %s", got, leader.identity)
+	}
+	if got := <-running; got != follower.identity {
+		t.Fatalf("running %s; want %s", got, follower.identity)
+	}
+	if l, _ := repo.Leader(context.Background(), "scheduler"); l.Identity != follower.identity {
+		t.Fatalf("recorded leader %+v; want %s", l, follower.identity)
+	}
+}
@@ -0,0 +1,130 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+const (
+	defaultEventLimit = 100
+	maxEventLimit     = 500
+)
+
+// EventConsumer folds a batch of change log entries, in log order, into
+// something that follows the log. Replays hand it entries it has seen
+// before, so it must tolerate them.
+type EventConsumer func(ctx context.Context, changes []domain.Change) error
+
+// Events serves the change log as domain events and replays it on demand to
+// the consumers registered with it, so a consumer added later, or one whose
+// state was lost, catches up with history. A replay doesn't move the
+// consumer's own cursor: it follows the log on from where it was.
+type Events struct {
+	feed *ChangeFeed
+
+	mu        sync.Mutex
+	consumers map[string]EventConsumer
+	replaying map[string]bool
+}
+
+func NewEvents(feed *ChangeFeed) *Events {
+	return &Events{feed: feed, consumers: map[string]EventConsumer{}, replaying: map[string]bool{}}
+}
+
+// Register makes name a consumer events can be replayed to.
+func (e *Events) Register(name string, consume EventConsumer) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.consumers[name] = consume
+}
+
+// ListEvents returns the events after since. A limit of 0 means the default;
+// larger limits are capped.
+func (e *Events) ListEvents(ctx context.Context, since int64, limit int) (*ports.EventsResponse, error) {
+	if limit <= 0 {
+		limit = defaultEventLimit
+	}
+	changes, err := e.feed.Since(ctx, since, min(limit, maxEventLimit), 0)
+	if err != nil {
+		return nil, err
+	}
+	res := &ports.EventsResponse{Events: make([]domain.Event, 0, len(changes)), Next: since}
+	for _, c := range changes {
+		res.Events = append(res.Events, domain.EventOf(c))
+		res.Next = c.ID
+	}
+	return res, nil
+}
+
+func (e *Events) EventConsumers() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	names := make([]string, 0, len(e.consumers))
+	for name := range e.consumers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func (e *Events) ReplayEvents(ctx context.Context, in ports.ReplayInput) (*ports.ReplayResult, error) {
+	errs := &ValidationError{}
+	if in.Since < 0 {
+		errs.add("since", "Since must be 0 or more")
+	}
+	e.mu.Lock()
+	consume, ok := e.consumers[in.Consumer]
+	e.mu.Unlock()
+	if !ok {
+		errs.add("consumer", "Consumer must be one of "+strings.Join(e.EventConsumers(), ", "))
+	}
+	if !errs.ok() {
+		return nil, errs
+	}
+
+	e.mu.Lock()
+	if e.replaying[in.Consumer] {
+		e.mu.Unlock()
+		return nil, ports.ErrReplayRunning
+	}
+	e.replaying[in.Consumer] = true
+	e.mu.Unlock()
+	defer func() {
+		e.mu.Lock()
+		delete(e.replaying, in.Consumer)
+		e.mu.Unlock()
+	}()
+
+	// stop at the newest event now: the consumer follows later ones itself
+	until, err := e.feed.LatestID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := &ports.ReplayResult{Consumer: in.Consumer, Since: in.Since, Until: in.Since}
+	for res.Until < until {
+		changes, err := e.feed.repo.ListSince(ctx, res.Until, replayBatch)
+		if err != nil {
+			return nil, fmt.Errorf("replay to %s stopped after offset %d: %w", in.Consumer, res.Until, err)
+		}
+		if n := slices.IndexFunc(changes, func(c domain.Change) bool { return c.ID > until }); n >= 0 {
+			changes = changes[:n]
+		}
+		if len(changes) == 0 {
+			break
+		}
+		if err := consume(ctx, changes); err != nil {
+			return nil, fmt.Errorf("replay to %s stopped after offset %d: %w", in.Consumer, res.Until, err)
+		}
+		res.Until = changes[len(changes)-1].ID
+		res.Events += len(changes)
+	}
+	logger.From(ctx).Info("replayed events", "consumer", in.Consumer, "since", in.Since, "until", res.Until, "events", res.Events)
+	return res, nil
+}
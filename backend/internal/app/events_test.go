@@ -0,0 +1,100 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+func TestEvents_ListsChangesAsEvents(t *testing.T) {
+	feed := NewChangeFeed(&memChangeRepo{})
+	ctx := context.Background()
+	_ = feed.Record(ctx, 7, domain.ChangeCreated, &domain.Book{ID: 7, Title: "Dune"})
+	_ = feed.Record(ctx, 7, domain.ChangeDeleted, nil)
+	events := NewEvents(feed)
+
+	res, err := events.ListEvents(ctx, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Events) != 2 || res.Next != 2 {
+		t.Fatalf("events = %+v", res)
+	}
+	if e := res.Events[0]; e.Type != "book.created" || e.EntityID != 7 || e.Version != 1 || len(e.Data) == 0 {
+		t.Fatalf("created = %+v", e)
+	}
+	if e := res.Events[1]; e.Type != "book.deleted" || e.Version != 2 || e.Data != nil {
+		t.Fatalf("deleted = %+v", e)
+	}
+
+	res, err = events.ListEvents(ctx, 2, 10)
+	if err != nil || len(res.Events) != 0 || res.Next != 2 {
+		t.Fatalf("caught up = %+v, %v", res, err)
+	}
+}
+
+func TestEvents_ReplaysToAConsumer(t *testing.T) {
+	feed := NewChangeFeed(&memChangeRepo{})
+	ctx := context.Background()
+	for id := int64(1); id <= 5; id++ {
+		_ = feed.Record(ctx, id, domain.ChangeCreated, &domain.Book{ID: id})
+	}
+	events := NewEvents(feed)
+	var seen []int64
+	events.Register("audit", func(ctx context.Context, changes []domain.Change) error {
+		for _, c := range changes {
+			seen = append(seen, c.ID)
+		}
+		return nil
+	})
+	events.Register("broken", func(ctx context.Context, changes []domain.Change) error {
+		return errors.New("boom")
+	})
+
+	res, err := events.ReplayEvents(ctx, ports.ReplayInput{Consumer: "audit", Since: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Since != 2 || res.Until != 5 || res.Events != 3 || len(seen) != 3 || seen[0] != 3 {
+		t.Fatalf("replay = %+v, seen %v", res, seen)
+	}
+
+	_, err = events.ReplayEvents(ctx, ports.ReplayInput{Consumer: "nobody", Since: -1})
+	var ve *ValidationError
+	if !errors.As(err, &ve) || ve.Fields["consumer"] != "Consumer must be one of audit, broken" || ve.Fields["since"] == "" {
+		t.Fatalf("invalid = %v", err)
+	}
+	if _, err := events.ReplayEvents(ctx, ports.ReplayInput{Consumer: "broken"}); err == nil || err.Error() != "replay to broken stopped after offset 0: boom" {
+		t.Fatalf("failing consumer: %v", err)
+	}
+}
+
+func TestEvents_RefusesConcurrentReplays(t *testing.T) {
+	feed := NewChangeFeed(&memChangeRepo{})
+	ctx := context.Background()
+	_ = feed.Record(ctx, 1, domain.ChangeCreated, &domain.Book{ID: 1})
+	events := NewEvents(feed)
+	started, release := make(chan struct{}), make(chan struct{})
+	events.Register("slow", func(ctx context.Context, changes []domain.Change) error {
+		close(started)
+		<-release
+		return nil
+	})
+
+	done := make(chan error)
+	go func() {
+		_, err := events.ReplayEvents(ctx, ports.ReplayInput{Consumer: "slow"})
+		done <- err
+	}()
+	<-started
+	if _, err := events.ReplayEvents(ctx, ports.ReplayInput{Consumer: "slow"}); !errors.Is(err, ports.ErrReplayRunning) {
+		t.Fatalf("second replay: %v", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("first replay: %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// Page sizes of GET /me/favorites.
+const (
+	defaultFavoriteLimit = 50
+	maxFavoriteLimit     = 500
+)
+
+// Favorites keeps the books each user bookmarked. Favorites belong to user
+// accounts, found by the email the caller runs as.
+type Favorites struct {
+	repo  ports.FavoriteRepository
+	users ports.UserRepository
+}
+
+var _ ports.FavoriteService = (*Favorites)(nil)
+
+func NewFavorites(repo ports.FavoriteRepository, users ports.UserRepository) *Favorites {
+	return &Favorites{repo: repo, users: users}
+}
+
+// Favorite bookmarks book bookID; bookmarking it again changes nothing.
+func (s *Favorites) Favorite(ctx context.Context, bookID int64) error {
+	userID, err := s.user(ctx)
+	if err != nil {
+		return err
+	}
+	found, err := s.repo.Add(ctx, userID, bookID, clock().UTC())
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.New("book not found")
+	}
+	return nil
+}
+
+func (s *Favorites) Unfavorite(ctx context.Context, bookID int64) error {
+	userID, err := s.user(ctx)
+	if err != nil {
+		return err
+	}
+	found, err := s.repo.Remove(ctx, userID, bookID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.New("favorite not found")
+	}
+	return nil
+}
+
+func (s *Favorites) ListFavorites(ctx context.Context, q ports.FavoriteQuery) (*ports.FavoritePage, error) {
+	errs := &ValidationError{}
+	switch {
+	case q.Limit == 0:
+		q.Limit = defaultFavoriteLimit
+	case q.Limit < 0 || q.Limit > maxFavoriteLimit:
+		errs.add("limit", fmt.Sprintf("Limit must be between 1 and %d", maxFavoriteLimit))
+	}
+	if q.Offset < 0 {
+		errs.add("offset", "Offset must not be negative")
+	}
+	if !errs.ok() {
+		return nil, errs
+	}
+	userID, err := s.user(ctx)
+	if err != nil {
+		return nil, err
+	}
+	books, total, err := s.repo.ListBooks(ctx, userID, q.Limit, q.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return &ports.FavoritePage{Books: books, Total: total, Limit: q.Limit, Offset: q.Offset}, nil
+}
+
+// user returns the id of the account ctx runs as.
+func (s *Favorites) user(ctx context.Context) (int64, error) {
+	a, ok := domain.ActorFrom(ctx)
+	if !ok || a.ID == "" {
+		return 0, ports.ErrNoFavoritesAccount
+	}
+	u, err := s.users.GetByEmail(ctx, strings.ToLower(a.ID))
+	if err != nil {
+		return 0, err
+	}
+	if u == nil {
+		return 0, ports.ErrNoFavoritesAccount
+	}
+	return u.ID, nil
+}
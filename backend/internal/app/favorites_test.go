@@ -0,0 +1,100 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// ---- In-memory ports.FavoriteRepository over books 1 to 3 ----
+
+type memFavorite struct {
+	userID, bookID int64
+	at             time.Time
+}
+
+type memFavoriteRepo struct {
+	favs []memFavorite
+}
+
+func (m *memFavoriteRepo) Add(ctx context.Context, userID, bookID int64, at time.Time) (bool, error) {
+	if bookID < 1 || bookID > 3 {
+		return false, nil
+	}
+	for _, f := range m.favs {
+		if f.userID == userID && f.bookID == bookID {
+			return true, nil
+		}
+	}
+	m.favs = append(m.favs, memFavorite{userID, bookID, at})
+	return true, nil
+}
+
+func (m *memFavoriteRepo) Remove(ctx context.Context, userID, bookID int64) (bool, error) {
+	for i, f := range m.favs {
+		if f.userID == userID && f.bookID == bookID {
+			m.favs = slices.Delete(m.favs, i, i+1)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *memFavoriteRepo) ListBooks(ctx context.Context, userID int64, limit, offset int) ([]domain.Book, int, error) {
+	var books []domain.Book
+	for i := len(m.favs) - 1; i >= 0; i-- {
+		if m.favs[i].userID == userID {
+			books = append(books, domain.Book{ID: m.favs[i].bookID})
+		}
+	}
+	page := books[min(offset, len(books)):]
+	return page[:min(limit, len(page))], len(books), nil
+}
+
+func TestFavorites(t *testing.T) {
+	users := &memUserRepo{users: []domain.User{{ID: 1, Email: "ann@example.com"}, {ID: 2, Email: "bob@example.com"}}}
+	s := NewFavorites(&memFavoriteRepo{}, users)
+	ctx := context.Background()
+	ann := domain.WithActor(ctx, domain.Actor{ID: "Ann@example.com"})
+	bob := domain.WithActor(ctx, domain.Actor{ID: "bob@example.com"})
+
+	for _, c := range []context.Context{ctx, domain.WithActor(ctx, domain.Actor{ID: "key:ci"})} {
+		if err := s.Favorite(c, 1); !errors.Is(err, ports.ErrNoFavoritesAccount) {
+			t.Fatalf("Favorite without an account = %v; want ErrNoFavoritesAccount", err)
+		}
+	}
+	if err := s.Favorite(ann, 9); err == nil || err.Error() != "book not found" {
+		t.Fatalf("Favorite of a missing book = %v", err)
+	}
+	for _, id := range []int64{1, 2, 1} {
+		if err := s.Favorite(ann, id); err != nil {
+			t.Fatalf("Favorite(%d): %v", id, err)
+		}
+	}
+	if err := s.Favorite(bob, 3); err != nil {
+		t.Fatalf("Favorite as bob: %v", err)
+	}
+
+	page, err := s.ListFavorites(ann, ports.FavoriteQuery{})
+	if err != nil || page.Total != 2 || page.Limit != defaultFavoriteLimit || page.Books[0].ID != 2 || page.Books[1].ID != 1 {
+		t.Fatalf("ListFavorites = %+v, %v; want books 2 and 1", page, err)
+	}
+	if _, err := s.ListFavorites(ann, ports.FavoriteQuery{Limit: maxFavoriteLimit + 1}); !errors.As(err, new(*ValidationError)) {
+		t.Fatalf("ListFavorites over the limit = %v; want a validation error", err)
+	}
+
+	if err := s.Unfavorite(ann, 2); err != nil {
+		t.Fatalf("Unfavorite: %v", err)
+	}
+	if err := s.Unfavorite(ann, 3); err == nil || err.Error() != "favorite not found" {
+		t.Fatalf("Unfavorite of bob's favorite = %v", err)
+	}
+	if page, _ := s.ListFavorites(ann, ports.FavoriteQuery{}); page.Total != 1 || page.Books[0].ID != 1 {
+		t.Fatalf("ListFavorites after Unfavorite = %+v", page)
+	}
+}
@@ -0,0 +1,128 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// holdService finds the caller's account, like Favorites, by the email the
+// caller runs as.
+type holdService struct {
+	repo  ports.HoldRepository
+	users ports.UserRepository
+}
+
+func NewHoldService(repo ports.HoldRepository, users ports.UserRepository) ports.HoldService {
+	return &holdService{repo: repo, users: users}
+}
+
+func (s *holdService) PlaceHold(ctx context.Context, in ports.PlaceHoldInput) (*domain.Hold, error) {
+	errs := &ValidationError{}
+	if in.BookID < 1 {
+		errs.add("book_id", "Book ID is required")
+	}
+	if in.UserID < 0 {
+		errs.add("user_id", "User ID must be positive")
+	}
+	if !errs.ok() {
+		return nil, errs
+	}
+	self, staff, err := s.caller(ctx)
+	switch {
+	case err != nil:
+		return nil, err
+	case in.UserID == 0 && self == 0 && staff:
+		errs.add("user_id", "User ID is required without an account of your own")
+		return nil, errs
+	case in.UserID == 0 && self == 0:
+		return nil, ports.ErrNoHoldAccount
+	case in.UserID == 0:
+		in.UserID = self
+	case in.UserID != self && !staff:
+		return nil, ports.ErrNotHoldOwner
+	}
+	h := &domain.Hold{BookID: in.BookID, UserID: in.UserID, Status: domain.HoldWaiting, PlacedAt: clock().UTC()}
+	found, err := s.repo.Place(ctx, h)
+	switch {
+	case errors.Is(err, ports.ErrUnknownBorrower):
+		errs.add("user_id", "No user has this id")
+		return nil, errs
+	case errors.Is(err, ports.ErrBorrowerHold):
+		errs.add("user_id", "This user has the book on loan")
+		return nil, errs
+	case err != nil:
+		return nil, err
+	case !found:
+		errs.add("book_id", "No book has this id")
+		return nil, errs
+	}
+	return h, nil
+}
+
+func (s *holdService) GetHold(ctx context.Context, id int64) (*domain.Hold, error) {
+	h, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if h == nil {
+		return nil, errors.New("hold not found")
+	}
+	self, staff, err := s.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !staff && (self == 0 || h.UserID != self) {
+		return nil, ports.ErrNotHoldOwner
+	}
+	return h, nil
+}
+
+func (s *holdService) CancelHold(ctx context.Context, id int64) (*domain.Hold, error) {
+	h, err := s.GetHold(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if h.Status != domain.HoldWaiting {
+		return nil, ports.ErrHoldClosed
+	}
+	now := clock().UTC()
+	waiting, err := s.repo.Cancel(ctx, id, now)
+	if err != nil {
+		return nil, err
+	}
+	if !waiting {
+		return nil, ports.ErrHoldClosed // fulfilled or cancelled concurrently
+	}
+	h.Status, h.Position, h.ClosedAt = domain.HoldCancelled, 0, &now
+	return h, nil
+}
+
+func (s *holdService) ListMyHolds(ctx context.Context) ([]domain.Hold, error) {
+	self, _, err := s.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if self == 0 {
+		return nil, ports.ErrNoHoldAccount
+	}
+	return s.repo.ListWaiting(ctx, self)
+}
+
+// caller returns the id of the account ctx runs as, 0 if it has none, and
+// whether it may manage the holds of other users.
+func (s *holdService) caller(ctx context.Context) (int64, bool, error) {
+	a, _ := domain.ActorFrom(ctx)
+	staff := a.CanEditBooks()
+	if a.ID == "" {
+		return 0, staff, nil
+	}
+	u, err := s.users.GetByEmail(ctx, strings.ToLower(a.ID))
+	if err != nil || u == nil {
+		return 0, staff, err
+	}
+	return u.ID, staff, nil
+}
@@ -0,0 +1,192 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// ---- In-memory ports.HoldRepository, sharing memLoanRepo's books ----
+
+type memHoldRepo struct {
+	*memLoanRepo
+}
+
+func (m memHoldRepo) Place(ctx context.Context, h *domain.Hold) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.books[h.BookID] {
+		return false, nil
+	}
+	var borrower int64
+	for _, l := range m.loans {
+		if l.BookID == h.BookID && l.ReturnedAt == nil {
+			borrower = l.UserID
+		}
+	}
+	switch {
+	case borrower == 0:
+		return true, ports.ErrBookAvailable
+	case borrower == h.UserID:
+		return true, ports.ErrBorrowerHold
+	case !m.users[h.UserID]:
+		return true, ports.ErrUnknownBorrower
+	}
+	for _, o := range m.holds {
+		if o.BookID == h.BookID && o.UserID == h.UserID && o.Status == domain.HoldWaiting {
+			return true, ports.ErrHoldExists
+		}
+	}
+	h.ID = int64(len(m.holds) + 1)
+	m.holds = append(m.holds, *h)
+	h.Position = m.position(*h)
+	return true, nil
+}
+
+func (m memHoldRepo) position(h domain.Hold) int {
+	if h.Status != domain.HoldWaiting {
+		return 0
+	}
+	n := 0
+	for _, o := range m.holds {
+		if o.BookID == h.BookID && o.Status == domain.HoldWaiting && o.ID <= h.ID {
+			n++
+		}
+	}
+	return n
+}
+
+func (m memHoldRepo) GetByID(ctx context.Context, id int64) (*domain.Hold, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if id < 1 || int(id) > len(m.holds) {
+		return nil, nil
+	}
+	h := m.holds[id-1]
+	h.Position = m.position(h)
+	return &h, nil
+}
+
+func (m memHoldRepo) Cancel(ctx context.Context, id int64, at time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if id < 1 || int(id) > len(m.holds) || m.holds[id-1].Status != domain.HoldWaiting {
+		return false, nil
+	}
+	m.holds[id-1].Status, m.holds[id-1].ClosedAt = domain.HoldCancelled, &at
+	return true, nil
+}
+
+func (m memHoldRepo) ListWaiting(ctx context.Context, userID int64) ([]domain.Hold, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := []domain.Hold{}
+	for _, h := range m.holds {
+		if h.UserID == userID && h.Status == domain.HoldWaiting {
+			h.Position = m.position(h)
+			out = append(out, h)
+		}
+	}
+	return out, nil
+}
+
+func TestHolds_QueueAndFulfil(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return now })
+	defer SetClock(time.Now)
+	loanRepo := &memLoanRepo{books: map[int64]bool{3: true}, users: map[int64]bool{7: true, 8: true, 9: true}}
+	loans := NewLoanService(loanRepo, 14*24*time.Hour)
+	users := &memUserRepo{users: []domain.User{{ID: 8, Email: "ann@example.com"}, {ID: 9, Email: "bob@example.com"}}}
+	holds := NewHoldService(memHoldRepo{loanRepo}, users)
+	ctx := domain.WithActor(context.Background(), domain.Actor{ID: "key:desk", Scopes: []string{domain.ScopeEditor}})
+	ann := domain.WithActor(context.Background(), domain.Actor{ID: "Ann@example.com"})
+	bob := domain.WithActor(context.Background(), domain.Actor{ID: "bob@example.com"})
+
+	if _, err := holds.PlaceHold(ctx, ports.PlaceHoldInput{BookID: 3, UserID: 8}); !errors.Is(err, ports.ErrBookAvailable) {
+		t.Fatalf("hold on an available book = %v; want ErrBookAvailable", err)
+	}
+	l, err := loans.CheckoutBook(ctx, ports.CheckoutInput{BookID: 3, UserID: 7})
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	for _, tc := range []struct {
+		in    ports.PlaceHoldInput
+		field string
+	}{
+		{ports.PlaceHoldInput{UserID: 8}, "book_id"},
+		{ports.PlaceHoldInput{BookID: 4, UserID: 8}, "book_id"},
+		{ports.PlaceHoldInput{BookID: 3, UserID: 7}, "user_id"}, // the borrower
+		{ports.PlaceHoldInput{BookID: 3, UserID: 10}, "user_id"},
+		{ports.PlaceHoldInput{BookID: 3}, "user_id"}, // the desk has no account of its own
+	} {
+		_, err := holds.PlaceHold(ctx, tc.in)
+		var ve *ValidationError
+		if !errors.As(err, &ve) || ve.Fields[tc.field] == "" {
+			t.Errorf("PlaceHold(%+v) = %v; want an error for %s", tc.in, err, tc.field)
+		}
+	}
+
+	if _, err := holds.PlaceHold(context.Background(), ports.PlaceHoldInput{BookID: 3}); !errors.Is(err, ports.ErrNoHoldAccount) {
+		t.Fatalf("anonymous hold = %v; want ErrNoHoldAccount", err)
+	}
+	if _, err := holds.PlaceHold(ann, ports.PlaceHoldInput{BookID: 3, UserID: 9}); !errors.Is(err, ports.ErrNotHoldOwner) {
+		t.Fatalf("ann holding for bob = %v; want ErrNotHoldOwner", err)
+	}
+
+	// users hold for themselves, the desk for anyone
+	first, err := holds.PlaceHold(ann, ports.PlaceHoldInput{BookID: 3})
+	if err != nil || first.UserID != 8 || first.Position != 1 || first.Status != domain.HoldWaiting {
+		t.Fatalf("first hold = %+v, %v", first, err)
+	}
+	if _, err := holds.PlaceHold(ann, ports.PlaceHoldInput{BookID: 3, UserID: 8}); !errors.Is(err, ports.ErrHoldExists) {
+		t.Fatalf("second hold of user 8 = %v; want ErrHoldExists", err)
+	}
+	second, err := holds.PlaceHold(ctx, ports.PlaceHoldInput{BookID: 3, UserID: 9})
+	if err != nil || second.Position != 2 {
+		t.Fatalf("second hold = %+v, %v", second, err)
+	}
+	if mine, err := holds.ListMyHolds(bob); err != nil || len(mine) != 1 || mine[0].ID != second.ID || mine[0].Position != 2 {
+		t.Fatalf("ListMyHolds(bob) = %+v, %v", mine, err)
+	}
+	if _, err := holds.ListMyHolds(ctx); !errors.Is(err, ports.ErrNoHoldAccount) {
+		t.Fatalf("ListMyHolds(desk) = %v; want ErrNoHoldAccount", err)
+	}
+	if _, err := holds.GetHold(ann, second.ID); !errors.Is(err, ports.ErrNotHoldOwner) {
+		t.Fatalf("ann reading bob's hold = %v; want ErrNotHoldOwner", err)
+	}
+	if _, err := holds.CancelHold(ann, second.ID); !errors.Is(err, ports.ErrNotHoldOwner) {
+		t.Fatalf("ann cancelling bob's hold = %v; want ErrNotHoldOwner", err)
+	}
+
+	// the oldest hold gets the book back first
+	returned, err := loans.ReturnLoan(ctx, l.ID)
+	if err != nil || returned.NextLoan == nil || returned.NextLoan.UserID != 8 || !returned.NextLoan.DueAt.Equal(now.AddDate(0, 0, 14)) {
+		t.Fatalf("Return = %+v, %v; want the book lent to user 8", returned, err)
+	}
+	if h, _ := holds.GetHold(ctx, first.ID); h.Status != domain.HoldFulfilled || h.Position != 0 || *h.LoanID != returned.NextLoan.ID {
+		t.Fatalf("first hold after return = %+v", h)
+	}
+	if h, _ := holds.GetHold(bob, second.ID); h.Position != 1 {
+		t.Fatalf("second hold after return = %+v; want it next", h)
+	}
+	if _, err := holds.CancelHold(ctx, first.ID); !errors.Is(err, ports.ErrHoldClosed) {
+		t.Fatalf("cancel a fulfilled hold = %v; want ErrHoldClosed", err)
+	}
+	cancelled, err := holds.CancelHold(bob, second.ID)
+	if err != nil || cancelled.Status != domain.HoldCancelled || cancelled.ClosedAt == nil || cancelled.Position != 0 {
+		t.Fatalf("CancelHold = %+v, %v", cancelled, err)
+	}
+
+	// with the queue empty the next return frees the book
+	returned, err = loans.ReturnLoan(ctx, returned.NextLoan.ID)
+	if err != nil || returned.NextLoan != nil {
+		t.Fatalf("second Return = %+v, %v; want no next loan", returned, err)
+	}
+	if _, err := holds.GetHold(ctx, 99); err == nil || err.Error() != "hold not found" {
+		t.Fatalf("GetHold(99) = %v", err)
+	}
+}
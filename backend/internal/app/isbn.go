@@ -0,0 +1,103 @@
+package app
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrInvalidISBN means a string is neither a valid ISBN-10 nor a valid
+	// ISBN-13.
+	ErrInvalidISBN = errors.New("invalid ISBN (must be ISBN-10 or ISBN-13)")
+	// ErrNoISBN10 means an ISBN-13 outside the 978 prefix, which has no
+	// ISBN-10 form.
+	ErrNoISBN10 = errors.New("only ISBN-13s starting with 978 have an ISBN-10")
+)
+
+// ISBN10To13 converts an ISBN-10, with or without hyphens and spaces, to
+// its ISBN-13: 978, the first nine digits and a new check digit.
+func ISBN10To13(isbn string) (string, error) {
+	n := normalizeISBN(isbn)
+	if !isValidISBN10(n) {
+		return "", ErrInvalidISBN
+	}
+	body := "978" + n[:9]
+	return body + isbn13Check(body), nil
+}
+
+// ISBN13To10 converts an ISBN-13 starting with 978, with or without hyphens
+// and spaces, to its ISBN-10. Other ISBN-13s fail with ErrNoISBN10.
+func ISBN13To10(isbn string) (string, error) {
+	n := normalizeISBN(isbn)
+	if !isValidISBN13(n) {
+		return "", ErrInvalidISBN
+	}
+	if !strings.HasPrefix(n, "978") {
+		return "", ErrNoISBN10
+	}
+	body := n[3:12]
+	sum := 0
+	for i := range 9 {
+		sum += (10 - i) * int(body[i]-'0')
+	}
+	switch check := (11 - sum%11) % 11; check {
+	case 10:
+		return body + "X", nil
+	default:
+		return body + string(rune('0'+check)), nil
+	}
+}
+
+// CanonicalISBN returns the ISBN-13 of a valid ISBN in either form, which is
+// how books store it, and its ISBN-10, or "" when it has none.
+func CanonicalISBN(isbn string) (isbn13, isbn10 string, err error) {
+	n := normalizeISBN(isbn)
+	switch len(n) {
+	case 10:
+		isbn13, err = ISBN10To13(n)
+		return isbn13, n, err
+	case 13:
+		if !isValidISBN13(n) {
+			return "", "", ErrInvalidISBN
+		}
+		isbn10, _ = ISBN13To10(n)
+		return n, isbn10, nil
+	}
+	return "", "", ErrInvalidISBN
+}
+
+// isbnKey compares ISBNs: the ISBN-13 of a valid ISBN, so both forms of one
+// book are equal, or the normalized string of an invalid one.
+func isbnKey(isbn string) string {
+	if isbn13, _, err := CanonicalISBN(isbn); err == nil {
+		return isbn13
+	}
+	return normalizeISBN(isbn)
+}
+
+// isbnLookupKey is the ISBN repositories look a book up by: the ISBN-10 when
+// isbn has one, which matches both the isbn10 of converted books and the isbn
+// of books stored before conversion, or else the ISBN-13.
+func isbnLookupKey(isbn string) string {
+	isbn13, isbn10, err := CanonicalISBN(isbn)
+	switch {
+	case err != nil:
+		return normalizeISBN(isbn)
+	case isbn10 != "":
+		return isbn10
+	}
+	return isbn13
+}
+
+// isbn13Check is the check digit of the first twelve digits of an ISBN-13.
+func isbn13Check(body string) string {
+	sum := 0
+	for i := range 12 {
+		d := int(body[i] - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return string(rune('0' + (10-sum%10)%10))
+}
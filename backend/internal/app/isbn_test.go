@@ -0,0 +1,65 @@
+package app
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestISBNConversion(t *testing.T) {
+	cases := []struct{ isbn10, isbn13 string }{
+		{"0306406152", "9780306406157"},
+		{"0321125215", "9780321125217"},
+		{"080442957X", "9780804429573"}, // X check digit
+		{"0132350882", "9780132350884"},
+	}
+	for _, tc := range cases {
+		if got, err := ISBN10To13(tc.isbn10); err != nil || got != tc.isbn13 {
+			t.Fatalf("ISBN10To13(%q) = %q, %v; want %q", tc.isbn10, got, err, tc.isbn13)
+		}
+		if got, err := ISBN13To10(tc.isbn13); err != nil || got != tc.isbn10 {
+			t.Fatalf("ISBN13To10(%q) = %q, %v; want %q", tc.isbn13, got, err, tc.isbn10)
+		}
+	}
+
+	if got, err := ISBN10To13("0-8044-2957-x"); err != nil || got != "9780804429573" {
+		t.Fatalf("hyphenated ISBN-10 = %q, %v", got, err)
+	}
+	if _, err := ISBN13To10("9791032305690"); !errors.Is(err, ErrNoISBN10) {
+		t.Fatalf("979 prefix err = %v; want ErrNoISBN10", err)
+	}
+	for _, bad := range []string{"", "0306406153", "9780306406158", "12345"} {
+		if _, err := ISBN10To13(bad); !errors.Is(err, ErrInvalidISBN) {
+			t.Fatalf("ISBN10To13(%q) err = %v", bad, err)
+		}
+		if _, err := ISBN13To10(bad); !errors.Is(err, ErrInvalidISBN) {
+			t.Fatalf("ISBN13To10(%q) err = %v", bad, err)
+		}
+	}
+}
+
+func TestCanonicalISBN(t *testing.T) {
+	cases := []struct{ in, isbn13, isbn10 string }{
+		{"0-321-12521-5", "9780321125217", "0321125215"},
+		{"978-0-321-12521-7", "9780321125217", "0321125215"},
+		{"9791032305690", "9791032305690", ""},
+	}
+	for _, tc := range cases {
+		isbn13, isbn10, err := CanonicalISBN(tc.in)
+		if err != nil || isbn13 != tc.isbn13 || isbn10 != tc.isbn10 {
+			t.Fatalf("CanonicalISBN(%q) = %q, %q, %v", tc.in, isbn13, isbn10, err)
+		}
+	}
+	if _, _, err := CanonicalISBN("not an isbn"); !errors.Is(err, ErrInvalidISBN) {
+		t.Fatalf("invalid err = %v", err)
+	}
+
+	if isbnKey("0321125215") != isbnKey("978-0-321-12521-7") {
+		t.Fatal("both forms of one ISBN have different keys")
+	}
+	if got := isbnLookupKey("9780321125217"); got != "0321125215" {
+		t.Fatalf("lookup key = %q; want the ISBN-10", got)
+	}
+	if got := isbnLookupKey("9791032305690"); got != "9791032305690" {
+		t.Fatalf("lookup key = %q; want the ISBN-13", got)
+	}
+}
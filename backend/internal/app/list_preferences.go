@@ -0,0 +1,118 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// listPreferenceTenants is how many tenants' preferences are kept in memory.
+const listPreferenceTenants = 10000
+
+// ListPreferences stores each tenant's book list defaults and keeps them in
+// memory for the lists that use them. Like TrackingRules, a tenant's entry
+// is dropped when it changes its preferences here; other replicas see the
+// change once their entry expires after ttl.
+type ListPreferences struct {
+	repo   ports.ListPreferencesRepository
+	limits ports.PageLimits
+	cache  *lru[string, *domain.ListPreferences] // nil value: the tenant has none
+	now    func() time.Time
+}
+
+// NewListPreferences checks preferred page sizes against limits.
+func NewListPreferences(repo ports.ListPreferencesRepository, limits ports.PageLimits, ttl time.Duration) *ListPreferences {
+	return &ListPreferences{repo: repo, limits: limits, cache: newLRU[string, *domain.ListPreferences](listPreferenceTenants, ttl), now: clock}
+}
+
+func (s *ListPreferences) GetListPreferences(ctx context.Context) (*domain.ListPreferences, error) {
+	tenant, err := preferencesTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.repo.Get(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errors.New("list preferences not found")
+	}
+	return p, nil
+}
+
+// PutListPreferences replaces the caller's preferences. The sort and
+// collation are checked as a list request's are.
+func (s *ListPreferences) PutListPreferences(ctx context.Context, in ports.PutListPreferencesInput) (*domain.ListPreferences, error) {
+	tenant, err := preferencesTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	errs := &ValidationError{}
+	in.Sort = strings.TrimSpace(in.Sort)
+	srt := ports.Sort{Field: strings.TrimPrefix(in.Sort, "-"), Desc: strings.HasPrefix(in.Sort, "-"), Collation: in.Collation}
+	if in.Sort == "-" {
+		errs.add("sort", "Sort must be one of "+strings.Join(ports.SortFields, ", "))
+	} else {
+		validateSort(errs, &srt)
+	}
+	switch {
+	case in.PageSize < 0:
+		errs.add("page_size", "Page size must not be negative")
+	case s.limits.Max > 0 && in.PageSize > s.limits.Max:
+		errs.add("page_size", fmt.Sprintf("Page size must be ≤ %d", s.limits.Max))
+	}
+	if !errs.ok() {
+		return nil, errs
+	}
+	p := &domain.ListPreferences{Tenant: tenant, Sort: in.Sort, Collation: srt.Collation, PageSize: in.PageSize, UpdatedAt: s.now().UTC()}
+	if err := s.repo.Put(ctx, p); err != nil {
+		return nil, err
+	}
+	s.cache.Remove(tenant)
+	return p, nil
+}
+
+func (s *ListPreferences) DeleteListPreferences(ctx context.Context) error {
+	tenant, err := preferencesTenant(ctx)
+	if err != nil {
+		return err
+	}
+	found, err := s.repo.Delete(ctx, tenant)
+	if err != nil {
+		return err
+	}
+	s.cache.Remove(tenant)
+	if !found {
+		return errors.New("list preferences not found")
+	}
+	return nil
+}
+
+func (s *ListPreferences) For(ctx context.Context) (*domain.ListPreferences, error) {
+	a, ok := domain.ActorFrom(ctx)
+	if !ok || a.ID == "" {
+		return nil, nil
+	}
+	if p, ok := s.cache.Get(a.ID); ok {
+		return p, nil
+	}
+	p, err := s.repo.Get(ctx, a.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Add(a.ID, p)
+	return p, nil
+}
+
+func preferencesTenant(ctx context.Context) (string, error) {
+	a, ok := domain.ActorFrom(ctx)
+	if !ok || a.ID == "" {
+		return "", ports.ErrNoPreferencesTenant
+	}
+	return a.ID, nil
+}
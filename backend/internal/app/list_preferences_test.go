@@ -0,0 +1,113 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// ---- In-memory ports.ListPreferencesRepository ----
+
+type memListPreferencesRepo struct {
+	mu    sync.Mutex
+	prefs map[string]domain.ListPreferences
+	gets  int
+}
+
+func (m *memListPreferencesRepo) Get(ctx context.Context, tenant string) (*domain.ListPreferences, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gets++
+	if p, ok := m.prefs[tenant]; ok {
+		return &p, nil
+	}
+	return nil, nil
+}
+func (m *memListPreferencesRepo) Put(ctx context.Context, p *domain.ListPreferences) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.prefs == nil {
+		m.prefs = map[string]domain.ListPreferences{}
+	}
+	m.prefs[p.Tenant] = *p
+	return nil
+}
+func (m *memListPreferencesRepo) Delete(ctx context.Context, tenant string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.prefs[tenant]
+	delete(m.prefs, tenant)
+	return ok, nil
+}
+
+func TestListPreferences_Validation(t *testing.T) {
+	s := NewListPreferences(&memListPreferencesRepo{}, ports.PageLimits{Default: 50, Max: 200}, time.Minute)
+	if _, err := s.PutListPreferences(context.Background(), ports.PutListPreferencesInput{}); !errors.Is(err, ports.ErrNoPreferencesTenant) {
+		t.Fatalf("anonymous Put = %v; want ErrNoPreferencesTenant", err)
+	}
+	ctx := domain.WithActor(context.Background(), domain.Actor{ID: "key:storefront"})
+	for _, tc := range []struct {
+		in    ports.PutListPreferencesInput
+		field string
+	}{
+		{ports.PutListPreferencesInput{Sort: "rating"}, "sort"},
+		{ports.PutListPreferencesInput{Sort: "-"}, "sort"},
+		{ports.PutListPreferencesInput{Sort: "price", Collation: "sv"}, "collation"},
+		{ports.PutListPreferencesInput{Sort: "title", Collation: "xx"}, "collation"},
+		{ports.PutListPreferencesInput{PageSize: 201}, "page_size"},
+		{ports.PutListPreferencesInput{PageSize: -1}, "page_size"},
+	} {
+		_, err := s.PutListPreferences(ctx, tc.in)
+		var ve *ValidationError
+		if !errors.As(err, &ve) || ve.Fields[tc.field] == "" {
+			t.Errorf("Put(%+v) = %v; want an error for %s", tc.in, err, tc.field)
+		}
+	}
+
+	p, err := s.PutListPreferences(ctx, ports.PutListPreferencesInput{Sort: " -author ", Collation: "SV", PageSize: 24})
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if p.Tenant != "key:storefront" || p.Sort != "-author" || p.Collation != "sv" || p.PageSize != 24 {
+		t.Fatalf("preferences = %+v", p)
+	}
+}
+
+func TestListPreferences_ForCachesPerTenant(t *testing.T) {
+	repo := &memListPreferencesRepo{}
+	s := NewListPreferences(repo, ports.PageLimits{}, time.Minute)
+	if p, err := s.For(context.Background()); p != nil || err != nil {
+		t.Fatalf("anonymous For = %v, %v; want nil", p, err)
+	}
+
+	admin := domain.WithActor(context.Background(), domain.Actor{ID: "alice"})
+	if p, _ := s.For(admin); p != nil {
+		t.Fatalf("For before Put = %+v", p)
+	}
+	if _, err := s.PutListPreferences(admin, ports.PutListPreferencesInput{Sort: "title", PageSize: 100}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	for range 3 {
+		if p, _ := s.For(admin); p == nil || p.PageSize != 100 {
+			t.Fatalf("For after Put = %+v; the cached miss survived the Put", p)
+		}
+	}
+	if repo.gets != 2 {
+		t.Fatalf("repo reads = %d; want 2 (the miss, then one after the Put)", repo.gets)
+	}
+
+	if err := s.DeleteListPreferences(admin); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if p, _ := s.For(admin); p != nil {
+		t.Fatalf("For after Delete = %+v", p)
+	}
+	if err := s.DeleteListPreferences(admin); err == nil || err.Error() != "list preferences not found" {
+		t.Fatalf("second Delete = %v", err)
+	}
+}
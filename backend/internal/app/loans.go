@@ -0,0 +1,112 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// Page sizes of GET /loans/overdue.
+const (
+	defaultOverdueLimit = 100
+	maxOverdueLimit     = 1000
+)
+
+type loanService struct {
+	repo   ports.LoanRepository
+	period time.Duration
+}
+
+// NewLoanService lends books for period unless a checkout sets its own due
+// date.
+func NewLoanService(repo ports.LoanRepository, period time.Duration) ports.LoanService {
+	return &loanService{repo: repo, period: period}
+}
+
+func (s *loanService) CheckoutBook(ctx context.Context, in ports.CheckoutInput) (*domain.Loan, error) {
+	now := clock().UTC()
+	due := now.Add(s.period)
+	errs := &ValidationError{}
+	if in.BookID < 1 {
+		errs.add("book_id", "Book ID is required")
+	}
+	if in.UserID < 1 {
+		errs.add("user_id", "User ID is required")
+	}
+	if in.DueAt != nil {
+		if due = in.DueAt.UTC(); !due.After(now) {
+			errs.add("due_at", "Due date must be in the future")
+		}
+	}
+	if !errs.ok() {
+		return nil, errs
+	}
+	l := &domain.Loan{BookID: in.BookID, UserID: in.UserID, CheckedOutAt: now, DueAt: due}
+	found, err := s.repo.Checkout(ctx, l)
+	if errors.Is(err, ports.ErrUnknownBorrower) {
+		errs.add("user_id", "No user has this id")
+		return nil, errs
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		errs.add("book_id", "No book has this id")
+		return nil, errs
+	}
+	return l, nil
+}
+
+func (s *loanService) GetLoan(ctx context.Context, id int64) (*domain.Loan, error) {
+	l, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if l == nil {
+		return nil, errors.New("loan not found")
+	}
+	return l, nil
+}
+
+func (s *loanService) ReturnLoan(ctx context.Context, id int64) (*ports.ReturnedLoan, error) {
+	l, err := s.GetLoan(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if l.ReturnedAt != nil {
+		return nil, ports.ErrLoanReturned
+	}
+	now := clock().UTC()
+	open, next, err := s.repo.Return(ctx, id, now, now.Add(s.period))
+	if err != nil {
+		return nil, err
+	}
+	if !open {
+		return nil, ports.ErrLoanReturned // returned concurrently
+	}
+	l.ReturnedAt = &now
+	return &ports.ReturnedLoan{Loan: *l, NextLoan: next}, nil
+}
+
+func (s *loanService) ListOverdueLoans(ctx context.Context, limit int) ([]domain.Loan, error) {
+	switch {
+	case limit == 0:
+		limit = defaultOverdueLimit
+	case limit < 0 || limit > maxOverdueLimit:
+		errs := &ValidationError{}
+		errs.add("limit", fmt.Sprintf("Limit must be between 1 and %d", maxOverdueLimit))
+		return nil, errs
+	}
+	loans, err := s.repo.Overdue(ctx, clock().UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	if loans == nil {
+		loans = []domain.Loan{}
+	}
+	return loans, nil
+}
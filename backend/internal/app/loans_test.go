@@ -0,0 +1,152 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// ---- In-memory ports.LoanRepository ----
+
+type memLoanRepo struct {
+	mu    sync.Mutex
+	books map[int64]bool
+	users map[int64]bool
+	loans []domain.Loan
+	holds []domain.Hold // see memHoldRepo
+}
+
+func (m *memLoanRepo) Checkout(ctx context.Context, l *domain.Loan) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.books[l.BookID] {
+		return false, nil
+	}
+	for _, o := range m.loans {
+		if o.BookID == l.BookID && o.ReturnedAt == nil {
+			return true, ports.ErrBookOnLoan
+		}
+	}
+	if !m.users[l.UserID] {
+		return true, ports.ErrUnknownBorrower
+	}
+	l.ID = int64(len(m.loans) + 1)
+	m.loans = append(m.loans, *l)
+	return true, nil
+}
+func (m *memLoanRepo) GetByID(ctx context.Context, id int64) (*domain.Loan, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if id < 1 || int(id) > len(m.loans) {
+		return nil, nil
+	}
+	l := m.loans[id-1]
+	return &l, nil
+}
+func (m *memLoanRepo) Return(ctx context.Context, id int64, at, nextDue time.Time) (bool, *domain.Loan, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if id < 1 || int(id) > len(m.loans) || m.loans[id-1].ReturnedAt != nil {
+		return false, nil, nil
+	}
+	m.loans[id-1].ReturnedAt = &at
+	for i, h := range m.holds {
+		if h.BookID == m.loans[id-1].BookID && h.Status == domain.HoldWaiting {
+			next := domain.Loan{ID: int64(len(m.loans) + 1), BookID: h.BookID, UserID: h.UserID, CheckedOutAt: at, DueAt: nextDue}
+			m.loans = append(m.loans, next)
+			m.holds[i].Status, m.holds[i].LoanID, m.holds[i].ClosedAt = domain.HoldFulfilled, &next.ID, &at
+			return true, &next, nil
+		}
+	}
+	return true, nil, nil
+}
+func (m *memLoanRepo) Overdue(ctx context.Context, now time.Time, limit int) ([]domain.Loan, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []domain.Loan
+	for _, l := range m.loans {
+		if l.Overdue(now) && len(out) < limit {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+func TestLoans_CheckoutAndReturn(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return now })
+	defer SetClock(time.Now)
+	repo := &memLoanRepo{books: map[int64]bool{3: true}, users: map[int64]bool{7: true}}
+	s := NewLoanService(repo, 14*24*time.Hour)
+	ctx := context.Background()
+
+	past := now.Add(-time.Hour)
+	for _, tc := range []struct {
+		in    ports.CheckoutInput
+		field string
+	}{
+		{ports.CheckoutInput{UserID: 7}, "book_id"},
+		{ports.CheckoutInput{BookID: 3}, "user_id"},
+		{ports.CheckoutInput{BookID: 3, UserID: 7, DueAt: &past}, "due_at"},
+		{ports.CheckoutInput{BookID: 4, UserID: 7}, "book_id"},
+		{ports.CheckoutInput{BookID: 3, UserID: 8}, "user_id"},
+	} {
+		_, err := s.CheckoutBook(ctx, tc.in)
+		var ve *ValidationError
+		if !errors.As(err, &ve) || ve.Fields[tc.field] == "" {
+			t.Errorf("Checkout(%+v) = %v; want an error for %s", tc.in, err, tc.field)
+		}
+	}
+
+	l, err := s.CheckoutBook(ctx, ports.CheckoutInput{BookID: 3, UserID: 7})
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	if !l.CheckedOutAt.Equal(now) || !l.DueAt.Equal(now.AddDate(0, 0, 14)) {
+		t.Fatalf("loan = %+v; want it due after the loan period", l)
+	}
+	if _, err := s.CheckoutBook(ctx, ports.CheckoutInput{BookID: 3, UserID: 7}); !errors.Is(err, ports.ErrBookOnLoan) {
+		t.Fatalf("second Checkout = %v; want ErrBookOnLoan", err)
+	}
+
+	returned, err := s.ReturnLoan(ctx, l.ID)
+	if err != nil || returned.ReturnedAt == nil || !returned.ReturnedAt.Equal(now) {
+		t.Fatalf("Return = %+v, %v", returned, err)
+	}
+	if _, err := s.ReturnLoan(ctx, l.ID); !errors.Is(err, ports.ErrLoanReturned) {
+		t.Fatalf("second Return = %v; want ErrLoanReturned", err)
+	}
+	if _, err := s.ReturnLoan(ctx, 99); err == nil || err.Error() != "loan not found" {
+		t.Fatalf("Return(99) = %v", err)
+	}
+	if _, err := s.CheckoutBook(ctx, ports.CheckoutInput{BookID: 3, UserID: 7}); err != nil {
+		t.Fatalf("Checkout after Return: %v", err)
+	}
+}
+
+func TestLoans_Overdue(t *testing.T) {
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return now })
+	defer SetClock(time.Now)
+	returned := now.AddDate(0, 0, -1)
+	repo := &memLoanRepo{loans: []domain.Loan{
+		{ID: 1, BookID: 1, DueAt: now.AddDate(0, 0, -5)},
+		{ID: 2, BookID: 2, DueAt: now.AddDate(0, 0, 5)},
+		{ID: 3, BookID: 3, DueAt: now.AddDate(0, 0, -5), ReturnedAt: &returned},
+	}}
+	s := NewLoanService(repo, time.Hour)
+
+	loans, err := s.ListOverdueLoans(context.Background(), 0)
+	if err != nil || len(loans) != 1 || loans[0].ID != 1 {
+		t.Fatalf("ListOverdueLoans = %+v, %v", loans, err)
+	}
+	var ve *ValidationError
+	if _, err := s.ListOverdueLoans(context.Background(), 1001); !errors.As(err, &ve) {
+		t.Fatalf("ListOverdueLoans(1001) = %v; want a validation error", err)
+	}
+}
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CacheStats are the counters of an in-process cache.
+type CacheStats struct {
+	Entries int     `json:"entries"`
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+}
+
+// lru keeps up to size entries, dropping the least recently used one to make
+// room. With a ttl, entries also expire that long after they were added.
+// Safe for concurrent use.
+type lru[K comparable, V any] struct {
+	size int
+	ttl  time.Duration // 0 keeps entries until evicted
+	now  func() time.Time
+
+	mu    sync.Mutex
+	order *list.List // front is the most recently used
+	items map[K]*list.Element
+
+	hits, misses atomic.Int64
+}
+
+type lruEntry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time
+}
+
+func newLRU[K comparable, V any](size int, ttl time.Duration) *lru[K, V] {
+	return &lru[K, V]{size: size, ttl: ttl, now: time.Now, order: list.New(), items: map[K]*list.Element{}}
+}
+
+func (c *lru[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*lruEntry[K, V])
+		if c.ttl <= 0 || c.now().Before(e.expires) {
+			c.order.MoveToFront(el)
+			c.hits.Add(1)
+			return e.value, true
+		}
+		c.order.Remove(el)
+		delete(c.items, key)
+	}
+	c.misses.Add(1)
+	var zero V
+	return zero, false
+}
+
+func (c *lru[K, V]) Add(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := &lruEntry[K, V]{key: key, value: value, expires: c.now().Add(c.ttl)}
+	if el, ok := c.items[key]; ok {
+		el.Value = e
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(e)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry[K, V]).key)
+	}
+}
+
+func (c *lru[K, V]) Stats() CacheStats {
+	c.mu.Lock()
+	n := c.order.Len()
+	c.mu.Unlock()
+	s := CacheStats{Entries: n, Hits: c.hits.Load(), Misses: c.misses.Load()}
+	if total := s.Hits + s.Misses; total > 0 {
+		s.HitRate = float64(s.Hits) / float64(total)
+	}
+	return s
+}
+
+// Remove drops the entry of key, if there is one.
+func (c *lru[K, V]) Remove(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.order.Remove(el)
+		delete(c.items, key)
+	}
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+func TestLRU(t *testing.T) {
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	c := newLRU[string, int](2, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Add("a", 1)
+	c.Add("b", 2)
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("Get(a) = %d, %v", v, ok)
+	}
+	c.Add("c", 3) // evicts b, the least recently used
+	if _, ok := c.Get("b"); ok {
+		t.Fatalf("b survived eviction")
+	}
+	now = now.Add(time.Minute)
+	if _, ok := c.Get("a"); ok {
+		t.Fatalf("a survived its ttl")
+	}
+	if s := c.Stats(); s.Entries != 1 || s.Hits != 1 || s.Misses != 2 || s.HitRate != 1.0/3 {
+		t.Fatalf("Stats = %+v", s)
+	}
+}
+
+func TestURLCleaner_Cache(t *testing.T) {
+	c, _ := NewURLCleaner(nil)
+	c.UseCache(10)
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if got, err := c.Clean(ctx, "https://Example.com/A/", "all", CleanupOverrides{}); err != nil || got != "https://www.example.com/a" {
+			t.Fatalf("Clean = %q, %v", got, err)
+		}
+	}
+	if _, err := c.Clean(ctx, "https://example.com", "seo", CleanupOverrides{}); err == nil {
+		t.Fatalf("unknown profile cleaned")
+	}
+	if s := c.CacheStats(); s.Entries != 1 || s.Hits != 2 || s.Misses != 2 {
+		t.Fatalf("CacheStats = %+v", s)
+	}
+}
+
+type countingResolver struct{ calls int }
+
+func (r *countingResolver) Resolve(ctx context.Context, rawURL string, opts ports.ResolveOptions) (*ports.Resolution, error) {
+	r.calls++
+	if rawURL == "https://down.example/" {
+		return nil, errors.New("unreachable")
+	}
+	return &ports.Resolution{URL: rawURL + "landing", BlockedByRobots: opts.RespectRobots}, nil
+}
+
+func TestCachingURLResolver(t *testing.T) {
+	inner := &countingResolver{}
+	r := NewCachingURLResolver(inner, 10, time.Hour)
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if got, err := r.Resolve(ctx, "https://bit.ly/", ports.ResolveOptions{}); err != nil || got.URL != "https://bit.ly/landing" {
+			t.Fatalf("Resolve = %+v, %v", got, err)
+		}
+		if _, err := r.Resolve(ctx, "https://down.example/", ports.ResolveOptions{}); err == nil {
+			t.Fatalf("failure not passed through")
+		}
+	}
+	// the options are part of the key
+	if got, _ := r.Resolve(ctx, "https://bit.ly/", ports.ResolveOptions{RespectRobots: true}); !got.BlockedByRobots {
+		t.Fatalf("Resolve with robots = %+v; want a separate entry", got)
+	}
+	// failures aren't cached: 1 call for the hit URL, 2 for the failing one,
+	// 1 with other options
+	if inner.calls != 4 || r.Stats().Hits != 1 {
+		t.Fatalf("calls = %d, stats = %+v", inner.calls, r.Stats())
+	}
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// MetadataLookup asks metadata providers about an ISBN in order. Each gets
+// its own timeout, and a provider that fails or times out is logged and
+// skipped, so one slow catalogue degrades a lookup instead of failing it.
+type MetadataLookup struct {
+	providers []ports.MetadataProvider
+	timeout   time.Duration
+}
+
+var _ ports.MetadataService = (*MetadataLookup)(nil)
+
+func NewMetadataLookup(timeout time.Duration, providers ...ports.MetadataProvider) *MetadataLookup {
+	return &MetadataLookup{providers: providers, timeout: timeout}
+}
+
+// LookupBook takes the title from the first provider that knows isbn and
+// fills the author and year it lacks from the providers after it. It fails
+// with ports.ErrMetadataUnavailable only when no provider answered.
+func (m *MetadataLookup) LookupBook(ctx context.Context, isbn string) (*domain.BookMetadata, error) {
+	isbn13, _, err := CanonicalISBN(isbn)
+	if err != nil {
+		errs := &ValidationError{}
+		errs.add("isbn", "Invalid ISBN (must be ISBN-10 or ISBN-13)")
+		return nil, errs
+	}
+	var found *domain.BookMetadata
+	answered := 0
+	for _, p := range m.providers {
+		pctx, cancel := context.WithTimeout(ctx, m.timeout)
+		md, err := p.LookupISBN(pctx, isbn13)
+		cancel()
+		if err != nil {
+			logger.From(ctx).Warn("metadata lookup failed", "provider", p.Name(), "isbn", isbn13, "error", err)
+			continue
+		}
+		answered++
+		switch {
+		case md == nil:
+		case found == nil:
+			found = md
+			found.ISBN, found.Source = isbn13, p.Name()
+		default:
+			if found.Author == "" {
+				found.Author = md.Author
+			}
+			if found.PublicationYear == 0 {
+				found.PublicationYear = md.PublicationYear
+			}
+		}
+		if found != nil && found.Author != "" && found.PublicationYear != 0 {
+			break
+		}
+	}
+	if found == nil && answered == 0 && len(m.providers) > 0 {
+		return nil, ports.ErrMetadataUnavailable
+	}
+	return found, nil
+}
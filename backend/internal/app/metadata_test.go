@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type fakeProvider struct {
+	name  string
+	md    *domain.BookMetadata
+	err   error
+	delay time.Duration
+	isbns []string
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) LookupISBN(ctx context.Context, isbn13 string) (*domain.BookMetadata, error) {
+	p.isbns = append(p.isbns, isbn13)
+	select {
+	case <-time.After(p.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if p.md == nil {
+		return nil, p.err
+	}
+	md := *p.md
+	return &md, p.err
+}
+
+func TestMetadataLookup(t *testing.T) {
+	slow := &fakeProvider{name: "slow", delay: time.Second, md: &domain.BookMetadata{Title: "Too late"}}
+	partial := &fakeProvider{name: "openlibrary", md: &domain.BookMetadata{Title: "Clean Code"}}
+	rest := &fakeProvider{name: "googlebooks", md: &domain.BookMetadata{Title: "Clean code", Author: "Robert C. Martin", PublicationYear: 2008}}
+	m := NewMetadataLookup(20*time.Millisecond, slow, partial, rest)
+
+	md, err := m.LookupBook(context.Background(), "0-13-235088-2")
+	if err != nil || md == nil {
+		t.Fatalf("LookupBook = %+v, %v", md, err)
+	}
+	want := domain.BookMetadata{ISBN: "9780132350884", Title: "Clean Code", Author: "Robert C. Martin", PublicationYear: 2008, Source: "openlibrary"}
+	if *md != want {
+		t.Fatalf("metadata = %+v; want %+v", *md, want)
+	}
+	if len(partial.isbns) != 1 || partial.isbns[0] != "9780132350884" {
+		t.Fatalf("providers asked for %v; want the ISBN-13", partial.isbns)
+	}
+
+	if _, err := m.LookupBook(context.Background(), "12345"); err == nil {
+		t.Fatal("invalid ISBN accepted")
+	} else if ve, ok := err.(*ValidationError); !ok || ve.Fields["isbn"] == "" {
+		t.Fatalf("err = %v; want isbn validation error", err)
+	}
+}
+
+func TestMetadataLookup_Degrades(t *testing.T) {
+	down := &fakeProvider{name: "openlibrary", err: errors.New("connection refused")}
+	unknown := &fakeProvider{name: "googlebooks"}
+
+	md, err := NewMetadataLookup(time.Second, down, unknown).LookupBook(context.Background(), "9780132350884")
+	if md != nil || err != nil {
+		t.Fatalf("one provider up = %+v, %v; want not found", md, err)
+	}
+	_, err = NewMetadataLookup(time.Second, down, &fakeProvider{name: "googlebooks", err: errors.New("quota")}).
+		LookupBook(context.Background(), "9780132350884")
+	if !errors.Is(err, ports.ErrMetadataUnavailable) {
+		t.Fatalf("all providers down err = %v", err)
+	}
+}
+
+func TestCreateBook_Autofill(t *testing.T) {
+	meta := NewMetadataLookup(time.Second, &fakeProvider{name: "openlibrary",
+		md: &domain.BookMetadata{Title: "Clean Code", Author: "Robert C. Martin", PublicationYear: 2008}})
+	svc := NewBookService(newMemBookRepo(), WithMetadata(meta))
+
+	b, err := svc.CreateBook(context.Background(), ports.CreateBookInput{
+		ISBN: "9780132350884", Author: "Uncle Bob", Price: 30, Autofill: true,
+	})
+	if err != nil {
+		t.Fatalf("CreateBook err: %v", err)
+	}
+	if b.Title != "Clean Code" || b.Author != "Uncle Bob" || b.PublicationYear != 2008 {
+		t.Fatalf("book = %+v; want sent fields kept and blanks filled", b)
+	}
+
+	down := NewBookService(newMemBookRepo(), WithMetadata(NewMetadataLookup(time.Second,
+		&fakeProvider{name: "openlibrary", err: errors.New("timeout")})))
+	_, err = down.CreateBook(context.Background(), ports.CreateBookInput{ISBN: "9780132350884", Price: 30, Autofill: true})
+	if ve, ok := err.(*ValidationError); !ok || ve.Fields["title"] == "" {
+		t.Fatalf("err = %v; want the missing title reported", err)
+	}
+}
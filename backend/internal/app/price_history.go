@@ -0,0 +1,31 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type priceHistory struct {
+	prices ports.PriceRepository
+	books  ports.BookReader
+}
+
+// NewPriceHistory serves the price history that re-pricing and book updates
+// (see WithPriceHistory) record.
+func NewPriceHistory(prices ports.PriceRepository, books ports.BookReader) ports.PriceHistoryService {
+	return &priceHistory{prices: prices, books: books}
+}
+
+func (h *priceHistory) PriceHistory(ctx context.Context, id int64) ([]domain.PriceChange, error) {
+	b, err := h.books.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, errors.New("book not found")
+	}
+	return h.prices.PriceHistory(ctx, id)
+}
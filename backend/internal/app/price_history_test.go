@@ -0,0 +1,44 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+func TestUpdateBook_RecordsPriceChanges(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return at })
+	t.Cleanup(func() { SetClock(time.Now) })
+	books := newMemBookRepo(domain.Book{ID: 7, Title: "Dune", Price: 10})
+	prices := &memPriceRepo{}
+	svc := NewBookService(books, WithPriceHistory(prices))
+	ctx := domain.WithActor(context.Background(), domain.Actor{ID: "ann"})
+
+	title := "Dune (Deluxe)"
+	if _, err := svc.UpdateBook(ctx, 7, ports.UpdateBookInput{Title: &title}); err != nil {
+		t.Fatal(err)
+	}
+	if len(prices.applied) != 0 {
+		t.Fatalf("title change recorded a price: %+v", prices.applied)
+	}
+	price := 12.5
+	if _, err := svc.UpdateBook(ctx, 7, ports.UpdateBookInput{Price: &price}); err != nil {
+		t.Fatal(err)
+	}
+	want := domain.PriceChange{BookID: 7, OldPrice: 10, NewPrice: 12.5, Reason: "update", ChangedBy: "ann", ChangedAt: at}
+	if len(prices.applied) != 1 || prices.applied[0] != want {
+		t.Fatalf("history = %+v; want %+v", prices.applied, want)
+	}
+
+	history, err := NewPriceHistory(prices, books).PriceHistory(ctx, 7)
+	if err != nil || len(history) != 1 {
+		t.Fatalf("PriceHistory = %+v, %v", history, err)
+	}
+	if _, err := NewPriceHistory(prices, books).PriceHistory(ctx, 9); err == nil || err.Error() != "book not found" {
+		t.Fatalf("missing book: %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"golang.org/x/text/language"
+)
+
+type publisherService struct {
+	repo    ports.PublisherRepository
+	books   ports.BookReader
+	changes *ChangeFeed // optional; detaching books counts as updating them
+}
+
+func NewPublisherService(repo ports.PublisherRepository, books ports.BookReader, changes *ChangeFeed) ports.PublisherService {
+	return &publisherService{repo: repo, books: books, changes: changes}
+}
+
+func (s *publisherService) CreatePublisher(ctx context.Context, in ports.PublisherInput) (*domain.Publisher, error) {
+	in, err := validatePublisher(in)
+	if err != nil {
+		return nil, err
+	}
+	now := clock().UTC()
+	p := &domain.Publisher{Name: in.Name, Country: in.Country, Website: in.Website, CreatedAt: now, UpdatedAt: now}
+	id, err := s.repo.Create(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	p.ID = id
+	return p, nil
+}
+
+func (s *publisherService) ListPublishers(ctx context.Context) ([]domain.Publisher, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *publisherService) GetPublisher(ctx context.Context, id int64) (*domain.Publisher, error) {
+	p, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errors.New("publisher not found")
+	}
+	return p, nil
+}
+
+func (s *publisherService) UpdatePublisher(ctx context.Context, id int64, in ports.PublisherInput) (*domain.Publisher, error) {
+	in, err := validatePublisher(in)
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.GetPublisher(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	p.Name, p.Country, p.Website, p.UpdatedAt = in.Name, in.Country, in.Website, clock().UTC()
+	found, err := s.repo.Update(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New("publisher not found")
+	}
+	return p, nil
+}
+
+func (s *publisherService) DeletePublisher(ctx context.Context, id int64, detach bool) error {
+	found, detached, err := s.repo.Delete(ctx, id, detach, clock().UTC())
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.New("publisher not found")
+	}
+	for _, bookID := range detached {
+		s.recordChange(ctx, bookID)
+	}
+	return nil
+}
+
+func (s *publisherService) recordChange(ctx context.Context, bookID int64) {
+	if s.changes == nil {
+		return
+	}
+	b, err := s.books.GetByID(ctx, bookID)
+	if err != nil {
+		logger.From(ctx).Error("failed to read book for change payload", "id", bookID, "error", err)
+	}
+	if err := s.changes.Record(ctx, bookID, domain.ChangeUpdated, b); err != nil {
+		logger.From(ctx).Error("failed to record book change", "id", bookID, "op", domain.ChangeUpdated, "error", err)
+	}
+}
+
+// validatePublisher checks in and normalizes it: trimmed, with the country
+// in upper case.
+func validatePublisher(in ports.PublisherInput) (ports.PublisherInput, error) {
+	errs := &ValidationError{}
+
+	in.Name = strings.TrimSpace(in.Name)
+	switch {
+	case in.Name == "":
+		errs.add("name", "Name is required")
+	case len(in.Name) > 120:
+		errs.add("name", "Name must be ≤ 120 characters")
+	}
+
+	in.Country = strings.ToUpper(strings.TrimSpace(in.Country))
+	if in.Country != "" && !isCountryCode(in.Country) {
+		errs.add("country", "Country must be an ISO 3166-1 alpha-2 code, such as US")
+	}
+
+	in.Website = strings.TrimSpace(in.Website)
+	if in.Website != "" {
+		u, err := url.Parse(in.Website)
+		switch {
+		case len(in.Website) > 255:
+			errs.add("website", "Website must be ≤ 255 characters")
+		case err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "":
+			errs.add("website", "Website must be an http or https URL")
+		}
+	}
+
+	if !errs.ok() {
+		return in, errs
+	}
+	return in, nil
+}
+
+// isCountryCode reports whether code is an assigned ISO 3166-1 alpha-2
+// code, in upper case.
+func isCountryCode(code string) bool {
+	if len(code) != 2 || code[0] < 'A' || code[0] > 'Z' || code[1] < 'A' || code[1] > 'Z' {
+		return false
+	}
+	r, err := language.ParseRegion(code)
+	return err == nil && r.IsCountry()
+}
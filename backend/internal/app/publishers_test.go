@@ -0,0 +1,171 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// memPublisherRepo keeps publishers next to the books of a memBookRepo,
+// enforcing the relation like the foreign key does.
+type memPublisherRepo struct {
+	publishers map[int64]domain.Publisher
+	books      *memBookRepo
+}
+
+func (m *memPublisherRepo) Create(ctx context.Context, p *domain.Publisher) (int64, error) {
+	for _, other := range m.publishers {
+		if other.Name == p.Name {
+			return 0, ports.ErrPublisherNameTaken
+		}
+	}
+	p.ID = int64(len(m.publishers) + 1)
+	m.publishers[p.ID] = *p
+	return p.ID, nil
+}
+func (m *memPublisherRepo) List(ctx context.Context) ([]domain.Publisher, error) {
+	return nil, nil
+}
+func (m *memPublisherRepo) GetByID(ctx context.Context, id int64) (*domain.Publisher, error) {
+	p, ok := m.publishers[id]
+	if !ok {
+		return nil, nil
+	}
+	return &p, nil
+}
+func (m *memPublisherRepo) Update(ctx context.Context, p *domain.Publisher) (bool, error) {
+	_, ok := m.publishers[p.ID]
+	if ok {
+		m.publishers[p.ID] = *p
+	}
+	return ok, nil
+}
+func (m *memPublisherRepo) Delete(ctx context.Context, id int64, detach bool, at time.Time) (bool, []int64, error) {
+	if _, ok := m.publishers[id]; !ok {
+		return false, nil, nil
+	}
+	var books []int64
+	for _, b := range m.books.books {
+		if b.PublisherID != nil && *b.PublisherID == id {
+			books = append(books, b.ID)
+		}
+	}
+	if len(books) > 0 && !detach {
+		return true, nil, ports.ErrPublisherInUse
+	}
+	for _, bid := range books {
+		b := m.books.books[bid]
+		b.PublisherID, b.UpdatedAt = nil, at
+		m.books.books[bid] = b
+	}
+	delete(m.publishers, id)
+	return true, books, nil
+}
+
+// publishedBooks is a memBookRepo whose writes fail for publishers the
+// repository doesn't have.
+type publishedBooks struct {
+	*memBookRepo
+	publishers *memPublisherRepo
+}
+
+func (r publishedBooks) Create(ctx context.Context, b *domain.Book) (int64, error) {
+	if b.PublisherID != nil {
+		if _, ok := r.publishers.publishers[*b.PublisherID]; !ok {
+			return 0, ports.ErrUnknownPublisher
+		}
+	}
+	return r.memBookRepo.Create(ctx, b)
+}
+
+func TestPublishers_ValidatesAndNormalizes(t *testing.T) {
+	svc := NewPublisherService(&memPublisherRepo{publishers: map[int64]domain.Publisher{}, books: newMemBookRepo()}, newMemBookRepo(), nil)
+	ctx := context.Background()
+
+	p, err := svc.CreatePublisher(ctx, ports.PublisherInput{Name: "  Ace Books ", Country: "us", Website: "https://example.com/ace"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if p.ID == 0 || p.Name != "Ace Books" || p.Country != "US" {
+		t.Fatalf("publisher = %+v", p)
+	}
+	if _, err := svc.CreatePublisher(ctx, ports.PublisherInput{Name: "Ace Books"}); !errors.Is(err, ports.ErrPublisherNameTaken) {
+		t.Fatalf("duplicate: %v", err)
+	}
+
+	_, err = svc.CreatePublisher(ctx, ports.PublisherInput{Name: " ", Country: "XX", Website: "ftp://example.com"})
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("err = %v; want a validation error", err)
+	}
+	for _, f := range []string{"name", "country", "website"} {
+		if ve.Fields[f] == "" {
+			t.Errorf("no error for %s: %v", f, ve.Fields)
+		}
+	}
+
+	if _, err := svc.UpdatePublisher(ctx, 9, ports.PublisherInput{Name: "Tor"}); err == nil || err.Error() != "publisher not found" {
+		t.Fatalf("update missing: %v", err)
+	}
+}
+
+func TestPublishers_DeleteDetachesBooks(t *testing.T) {
+	ace := int64(1)
+	books := newMemBookRepo(domain.Book{ID: 7, Title: "Dune", PublisherID: &ace}, domain.Book{ID: 8, Title: "Emma"})
+	repo := &memPublisherRepo{publishers: map[int64]domain.Publisher{1: {ID: 1, Name: "Ace Books"}}, books: books}
+	changes := &memChangeRepo{}
+	svc := NewPublisherService(repo, books, NewChangeFeed(changes))
+	ctx := context.Background()
+
+	if err := svc.DeletePublisher(ctx, 1, false); !errors.Is(err, ports.ErrPublisherInUse) {
+		t.Fatalf("delete in use: %v", err)
+	}
+	if err := svc.DeletePublisher(ctx, 1, true); err != nil {
+		t.Fatalf("delete detaching: %v", err)
+	}
+	if books.books[7].PublisherID != nil {
+		t.Fatalf("book 7 still has publisher %d", *books.books[7].PublisherID)
+	}
+	if len(changes.changes) != 1 || changes.changes[0].BookID != 7 || changes.changes[0].Op != domain.ChangeUpdated {
+		t.Fatalf("changes = %+v", changes.changes)
+	}
+	if err := svc.DeletePublisher(ctx, 1, true); err == nil || err.Error() != "publisher not found" {
+		t.Fatalf("delete again: %v", err)
+	}
+}
+
+func TestBookPublisher_SetAndRemove(t *testing.T) {
+	books := newMemBookRepo()
+	pubs := &memPublisherRepo{publishers: map[int64]domain.Publisher{1: {ID: 1, Name: "Ace Books"}}, books: books}
+	svc := NewBookService(publishedBooks{books, pubs})
+	ctx := context.Background()
+	in := ports.CreateBookInput{Title: "Dune", Author: "Frank Herbert", ISBN: "9780441013593", Price: 10, PublicationYear: 1965}
+
+	missing := int64(9)
+	in.PublisherID = &missing
+	_, err := svc.CreateBook(ctx, in)
+	var ve *ValidationError
+	if !errors.As(err, &ve) || ve.Fields["publisher_id"] == "" {
+		t.Fatalf("unknown publisher: %v", err)
+	}
+
+	ace := int64(1)
+	in.PublisherID = &ace
+	b, err := svc.CreateBook(ctx, in)
+	if err != nil || b.PublisherID == nil || *b.PublisherID != 1 {
+		t.Fatalf("create = %+v, %v", b, err)
+	}
+
+	none := int64(0)
+	b, err = svc.UpdateBook(ctx, b.ID, ports.UpdateBookInput{PublisherID: &none})
+	if err != nil || b.PublisherID != nil {
+		t.Fatalf("remove publisher = %+v, %v", b, err)
+	}
+	if _, ok := b.FieldUpdatedAt["publisher_id"]; !ok {
+		t.Fatalf("publisher_id change not tracked: %v", b.FieldUpdatedAt)
+	}
+}
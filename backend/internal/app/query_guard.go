@@ -0,0 +1,94 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// queryGuardRefresh is how long a catalogue size estimate is reused.
+const queryGuardRefresh = time.Minute
+
+// QueryCostError rejects a book list that would scan the whole catalogue.
+type QueryCostError struct {
+	Books  int64  // estimated catalogue size
+	Reason string // why no index serves the filter
+}
+
+func (e *QueryCostError) Error() string {
+	return fmt.Sprintf("query too expensive: %s, so it would scan about %d books; add author or isbn to narrow it", e.Reason, e.Books)
+}
+
+// QueryGuard protects the database from book lists that would scan a large
+// catalogue: filters with no predicate an index can serve, such as q (a
+// LIKE with a leading wildcard) or year and price ranges on their own.
+// Author and isbn are served by indexes unless exact is set, tag always is,
+// and unfiltered lists walk the primary key a page at a time, so these
+// pass. The
+// catalogue counts as large from minBooks books, going by the store's
+// estimate.
+type QueryGuard struct {
+	books    ports.BookCountEstimator
+	minBooks int64
+	now      func() time.Time
+
+	mu      sync.Mutex
+	size    int64
+	checked time.Time
+}
+
+func NewQueryGuard(books ports.BookCountEstimator, minBooks int64) *QueryGuard {
+	return &QueryGuard{books: books, minBooks: minBooks, now: time.Now}
+}
+
+// Check returns a *QueryCostError when f would scan a large catalogue.
+func (g *QueryGuard) Check(ctx context.Context, f ports.ListFilter) error {
+	reason := scanReason(f)
+	if reason == "" {
+		return nil
+	}
+	if n := g.catalogueSize(ctx); n >= g.minBooks {
+		return &QueryCostError{Books: n, Reason: reason}
+	}
+	return nil
+}
+
+// scanReason explains why no index serves f, or returns "" when one does
+// or f filters nothing.
+func scanReason(f ports.ListFilter) string {
+	author, isbn := strings.TrimSpace(f.Author) != "", strings.TrimSpace(f.ISBN) != ""
+	switch {
+	case !f.Exact && (author || isbn), strings.TrimSpace(f.Tag) != "":
+		return ""
+	case strings.TrimSpace(f.Q) != "":
+		return "q matches text anywhere in titles, authors and aliases, which no index can serve"
+	case author || isbn:
+		return "exact author and isbn comparisons can't use the indexes"
+	case f.YearFrom != nil || f.YearTo != nil || f.PriceMin != nil || f.PriceMax != nil:
+		return "year and price ranges aren't indexed"
+	}
+	return ""
+}
+
+// catalogueSize returns the estimated number of books, refreshed at most
+// once a minute. A failed estimate keeps the previous one, so an outage of
+// the statistics doesn't reject every search.
+func (g *QueryGuard) catalogueSize(ctx context.Context) int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if now := g.now(); now.Sub(g.checked) >= queryGuardRefresh {
+		g.checked = now
+		n, err := g.books.EstimateBooks(ctx)
+		if err != nil {
+			logger.From(ctx).Warn("keeping the previous catalogue size estimate", "books", g.size, "error", err)
+			return g.size
+		}
+		g.size = n
+	}
+	return g.size
+}
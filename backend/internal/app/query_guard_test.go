@@ -0,0 +1,96 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// fakeEstimator returns n, or err when set, and counts the calls.
+type fakeEstimator struct {
+	n     int64
+	err   error
+	calls int
+}
+
+func (f *fakeEstimator) EstimateBooks(ctx context.Context) (int64, error) {
+	f.calls++
+	return f.n, f.err
+}
+
+func TestQueryGuard_Check(t *testing.T) {
+	g := NewQueryGuard(&fakeEstimator{n: 500000}, 100000)
+	ctx := context.Background()
+
+	cases := []struct {
+		f      ports.ListFilter
+		reject string
+	}{
+		{ports.ListFilter{}, ""},
+		{ports.ListFilter{Author: "Borges"}, ""},
+		{ports.ListFilter{Q: "ficciones", ISBN: "978-0-8021-3030-0"}, ""},
+		{ports.ListFilter{Q: "ficciones"}, "q matches text anywhere"},
+		{ports.ListFilter{Q: "ficciones", Author: "Borges", Exact: true}, "q matches text anywhere"},
+		{ports.ListFilter{Author: "Borges", Exact: true}, "exact author and isbn"},
+		{ports.ListFilter{YearFrom: iptr(1940), PriceMax: f64ptr(10)}, "ranges aren't indexed"},
+	}
+	for _, c := range cases {
+		err := g.Check(ctx, c.f)
+		var qe *QueryCostError
+		switch {
+		case c.reject == "" && err != nil:
+			t.Errorf("%+v: err = %v", c.f, err)
+		case c.reject != "" && (!errors.As(err, &qe) || !strings.Contains(qe.Reason, c.reject) || qe.Books != 500000):
+			t.Errorf("%+v: err = %v; want %q", c.f, err, c.reject)
+		}
+	}
+}
+
+func TestQueryGuard_SmallCatalogue(t *testing.T) {
+	est := &fakeEstimator{n: 99999}
+	g := NewQueryGuard(est, 100000)
+	now := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	g.now = func() time.Time { return now }
+	ctx := context.Background()
+	q := ports.ListFilter{Q: "ficciones"}
+
+	if err := g.Check(ctx, q); err != nil {
+		t.Fatalf("small catalogue: %v", err)
+	}
+	est.n = 100000
+	if err := g.Check(ctx, q); err != nil || est.calls != 1 {
+		t.Fatalf("estimate not reused: err = %v, calls = %d", err, est.calls)
+	}
+	now = now.Add(time.Minute)
+	if err := g.Check(ctx, q); err == nil {
+		t.Fatal("grown catalogue passed")
+	}
+	est.err = errors.New("down")
+	now = now.Add(time.Minute)
+	if err := g.Check(ctx, q); err == nil || est.calls != 3 {
+		t.Fatalf("failed estimate dropped the previous one: err = %v, calls = %d", err, est.calls)
+	}
+}
+
+func TestBookService_QueryGuard(t *testing.T) {
+	svc := NewBookService(newMemBookRepo(domain.Book{ID: 1, Title: "Ficciones", Author: "Borges"}),
+		WithQueryGuard(NewQueryGuard(&fakeEstimator{n: 1 << 20}, 100000)))
+	ctx := context.Background()
+
+	var qe *QueryCostError
+	if _, err := svc.ListBooksPage(ctx, ports.ListFilter{Q: "ficc"}, ports.Page{Limit: 10}); !errors.As(err, &qe) {
+		t.Fatalf("ListBooksPage err = %v", err)
+	}
+	if _, err := svc.SearchBooks(ctx, "ficc"); !errors.As(err, &qe) {
+		t.Fatalf("SearchBooks err = %v", err)
+	}
+	got, err := svc.ListBooksPage(ctx, ports.ListFilter{Q: "ficc", Author: "borges"}, ports.Page{Limit: 10})
+	if err != nil || got.Total != 1 {
+		t.Fatalf("narrowed search = %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,166 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+const maxRepriceBooks = 5000
+
+type repriceService struct {
+	books   ports.BookReader
+	prices  ports.PriceRepository
+	changes *ChangeFeed // optional
+}
+
+func NewRepriceService(books ports.BookReader, prices ports.PriceRepository, changes *ChangeFeed) ports.RepriceService {
+	return &repriceService{books: books, prices: prices, changes: changes}
+}
+
+// Reprice previews (the default) or applies a price rule to every book
+// matching the filter. Books whose price wouldn't change are left out.
+func (s *repriceService) Reprice(ctx context.Context, in ports.RepriceRequest) (*ports.RepriceResponse, error) {
+	if err := validateReprice(in); err != nil {
+		return nil, err
+	}
+	dryRun := in.DryRun == nil || *in.DryRun
+
+	books, err := selectBooks(ctx, s.books, in.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ports.RepriceResponse{DryRun: dryRun, Matched: len(books), Changes: []ports.RepriceItem{}}
+	for _, b := range books {
+		newPrice := applyRepriceRule(in.Rule, b.Price)
+		if newPrice == b.Price {
+			continue
+		}
+		resp.Changes = append(resp.Changes, ports.RepriceItem{BookID: b.ID, Title: b.Title, OldPrice: b.Price, NewPrice: newPrice})
+	}
+
+	errs := &ValidationError{}
+	if len(resp.Changes) > maxRepriceBooks {
+		errs.add("filter", fmt.Sprintf("Filter matches %d books to change; at most %d per run", len(resp.Changes), maxRepriceBooks))
+	}
+	for _, c := range resp.Changes {
+		if c.NewPrice < 0 || c.NewPrice > 1_000_000 {
+			errs.add("rule", fmt.Sprintf("Rule puts the price of book %d out of range (%.2f)", c.BookID, c.NewPrice))
+		}
+	}
+	if !errs.ok() {
+		return nil, errs
+	}
+	if dryRun || len(resp.Changes) == 0 {
+		return resp, nil
+	}
+
+	resp.BatchID = newBatchID()
+	reason := "reprice: " + describeRule(in.Rule)
+	now := clock().UTC()
+	var changedBy string
+	if a, ok := domain.ActorFrom(ctx); ok {
+		changedBy = a.ID
+	}
+	history := make([]domain.PriceChange, len(resp.Changes))
+	for i, c := range resp.Changes {
+		history[i] = domain.PriceChange{
+			BookID: c.BookID, OldPrice: c.OldPrice, NewPrice: c.NewPrice,
+			Reason: reason, BatchID: &resp.BatchID, ChangedBy: changedBy, ChangedAt: now,
+		}
+	}
+	if err := s.prices.ApplyPrices(ctx, history); err != nil {
+		return nil, err
+	}
+	for _, c := range resp.Changes {
+		s.recordChange(ctx, c.BookID)
+	}
+	return resp, nil
+}
+
+func (s *repriceService) recordChange(ctx context.Context, bookID int64) {
+	if s.changes == nil {
+		return
+	}
+	// snapshot after the change; a failed read still records the change
+	b, err := s.books.GetByID(ctx, bookID)
+	if err != nil {
+		logger.From(ctx).Error("failed to read book for change payload", "id", bookID, "error", err)
+	}
+	if err := s.changes.Record(ctx, bookID, domain.ChangeUpdated, b); err != nil {
+		logger.From(ctx).Error("failed to record book change", "id", bookID, "op", domain.ChangeUpdated, "error", err)
+	}
+}
+
+// applyRepriceRule works in cents so results don't pick up float noise.
+func applyRepriceRule(r ports.RepriceRule, price float64) float64 {
+	cents := math.Round(price * 100)
+	if r.Percent != 0 {
+		cents = math.Round(cents * (1 + r.Percent/100))
+	}
+	cents += math.Round(r.Amount * 100)
+	if r.Ending != nil {
+		// nearest price with these cents; ties round up
+		end := math.Round(*r.Ending * 100)
+		below := math.Floor((cents-end)/100)*100 + end
+		above := below + 100
+		if cents-below < above-cents && below >= 0 {
+			cents = below
+		} else {
+			cents = above
+		}
+	}
+	return cents / 100
+}
+
+func describeRule(r ports.RepriceRule) string {
+	var parts []string
+	if r.Percent != 0 {
+		parts = append(parts, fmt.Sprintf("%+g%%", r.Percent))
+	}
+	if r.Amount != 0 {
+		parts = append(parts, fmt.Sprintf("%+.2f", r.Amount))
+	}
+	if r.Ending != nil {
+		parts = append(parts, fmt.Sprintf("ending %.2f", *r.Ending))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func newBatchID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func validateReprice(in ports.RepriceRequest) error {
+	errs := &ValidationError{}
+	if emptyFilter(in.Filter) {
+		errs.add("filter", "Filter is required (use all: true to re-price every book)")
+	}
+	r := in.Rule
+	if r.Percent == 0 && r.Amount == 0 && r.Ending == nil {
+		errs.add("rule", "Rule needs percent, amount or ending")
+	}
+	if r.Percent <= -100 || r.Percent > 1000 {
+		errs.add("rule.percent", "Percent must be > -100 and ≤ 1000")
+	}
+	if !hasMax2Decimals(r.Amount) {
+		errs.add("rule.amount", "Max 2 decimal places")
+	}
+	if r.Ending != nil && (*r.Ending < 0 || *r.Ending >= 1 || !hasMax2Decimals(*r.Ending)) {
+		errs.add("rule.ending", "Ending must be cents between 0.00 and 0.99")
+	}
+	if !errs.ok() {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type memPriceRepo struct {
+	applied []domain.PriceChange
+	err     error
+}
+
+func (m *memPriceRepo) ApplyPrices(ctx context.Context, changes []domain.PriceChange) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.applied = append(m.applied, changes...)
+	return nil
+}
+func (m *memPriceRepo) RecordPrice(ctx context.Context, c domain.PriceChange) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.applied = append(m.applied, c)
+	return nil
+}
+func (m *memPriceRepo) PriceHistory(ctx context.Context, id int64) ([]domain.PriceChange, error) {
+	history := []domain.PriceChange{}
+	for _, c := range m.applied {
+		if c.BookID == id {
+			history = append(history, c)
+		}
+	}
+	return history, m.err
+}
+
+func TestApplyRepriceRule(t *testing.T) {
+	cases := []struct {
+		rule  ports.RepriceRule
+		price float64
+		want  float64
+	}{
+		{ports.RepriceRule{Percent: 10}, 30, 33},
+		{ports.RepriceRule{Percent: 10}, 19.99, 21.99},
+		{ports.RepriceRule{Percent: 10, Ending: f64ptr(0.99)}, 30, 32.99},
+		{ports.RepriceRule{Ending: f64ptr(0.99)}, 33.60, 33.99},
+		{ports.RepriceRule{Ending: f64ptr(0.99)}, 33.49, 33.99}, // tie rounds up
+		{ports.RepriceRule{Ending: f64ptr(0.99)}, 33.48, 32.99},
+		{ports.RepriceRule{Ending: f64ptr(0.99)}, 0.20, 0.99}, // never below zero
+		{ports.RepriceRule{Ending: f64ptr(0)}, 12.30, 12},
+		{ports.RepriceRule{Amount: -0.5}, 10, 9.5},
+		{ports.RepriceRule{Percent: -15}, 0.1, 0.09},
+	}
+	for _, c := range cases {
+		if got := applyRepriceRule(c.rule, c.price); got != c.want {
+			t.Fatalf("%+v on %.2f = %v; want %v", c.rule, c.price, got, c.want)
+		}
+	}
+}
+
+func newRepriceFixture() (ports.RepriceService, *memPriceRepo, *memChangeRepo) {
+	repo := newMemBookRepo(
+		domain.Book{ID: 1, Title: "Clean Code", Author: "Robert C. Martin", Price: 30, PublicationYear: 2008},
+		domain.Book{ID: 2, Title: "Clean Architecture", Author: "Robert C. Martin", Price: 32.99, PublicationYear: 2017},
+		domain.Book{ID: 3, Title: "Refactoring", Author: "Martin Fowler", Price: 45, PublicationYear: 1999},
+	)
+	prices := &memPriceRepo{}
+	changes := &memChangeRepo{}
+	return NewRepriceService(repo, prices, NewChangeFeed(changes)), prices, changes
+}
+
+func TestReprice_PreviewByDefault(t *testing.T) {
+	svc, prices, _ := newRepriceFixture()
+
+	resp, err := svc.Reprice(context.Background(), ports.RepriceRequest{
+		Filter: ports.RepriceFilter{Author: "robert c. martin"},
+		Rule:   ports.RepriceRule{Percent: 10, Ending: f64ptr(0.99)},
+	})
+	if err != nil {
+		t.Fatalf("Reprice err: %v", err)
+	}
+	// book 2 would go from 32.99 to 36.29 -> 35.99; book 1 from 30 to 32.99
+	if !resp.DryRun || resp.Matched != 2 || len(resp.Changes) != 2 || resp.BatchID != "" {
+		t.Fatalf("resp = %+v", resp)
+	}
+	if len(prices.applied) != 0 {
+		t.Fatalf("preview applied changes: %+v", prices.applied)
+	}
+}
+
+func TestReprice_Commit(t *testing.T) {
+	svc, prices, changes := newRepriceFixture()
+	commit := false
+
+	resp, err := svc.Reprice(context.Background(), ports.RepriceRequest{
+		Filter: ports.RepriceFilter{YearFrom: iptr(2000), MaxPrice: f64ptr(31)},
+		Rule:   ports.RepriceRule{Ending: f64ptr(0.99)},
+		DryRun: &commit,
+	})
+	if err != nil {
+		t.Fatalf("Reprice err: %v", err)
+	}
+	if resp.DryRun || resp.BatchID == "" || len(resp.Changes) != 1 || resp.Changes[0].NewPrice != 29.99 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	if len(prices.applied) != 1 {
+		t.Fatalf("applied = %+v", prices.applied)
+	}
+	h := prices.applied[0]
+	if h.BookID != 1 || h.OldPrice != 30 || h.Reason != "reprice: ending 0.99" || h.BatchID == nil || *h.BatchID != resp.BatchID {
+		t.Fatalf("history = %+v", h)
+	}
+	if len(changes.changes) != 1 || changes.changes[0].BookID != 1 {
+		t.Fatalf("changes = %+v", changes.changes)
+	}
+}
+
+func TestReprice_Validation(t *testing.T) {
+	svc, _, _ := newRepriceFixture()
+	cases := []struct {
+		in    ports.RepriceRequest
+		field string
+	}{
+		{ports.RepriceRequest{Rule: ports.RepriceRule{Percent: 5}}, "filter"},
+		{ports.RepriceRequest{Filter: ports.RepriceFilter{All: true}}, "rule"},
+		{ports.RepriceRequest{Filter: ports.RepriceFilter{All: true}, Rule: ports.RepriceRule{Percent: -100}}, "rule.percent"},
+		{ports.RepriceRequest{Filter: ports.RepriceFilter{All: true}, Rule: ports.RepriceRule{Ending: f64ptr(1.5)}}, "rule.ending"},
+		{ports.RepriceRequest{Filter: ports.RepriceFilter{All: true}, Rule: ports.RepriceRule{Amount: -40}}, "rule"},
+	}
+	for _, c := range cases {
+		_, err := svc.Reprice(context.Background(), c.in)
+		ve, ok := err.(*ValidationError)
+		if !ok || ve.Fields[c.field] == "" {
+			t.Fatalf("%+v: err = %v; want error on %s", c.in, err, c.field)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/httpclient"
+	"github.com/gerry-sabar/byfood/internal/logger"
+)
+
+// maxRobotsSize is how much of a robots.txt is read; RFC 9309 asks crawlers
+// to parse at least 500 KiB.
+const maxRobotsSize = 512 << 10
+
+// RobotsChecker answers whether a user agent may fetch a URL according to
+// the robots.txt of its host (RFC 9309). Each host's rules are cached for
+// ttl, so a host's robots.txt is fetched at most once per ttl per replica.
+type RobotsChecker struct {
+	client *http.Client
+	agent  string // the product token the rules are matched against
+	cache  *lru[string, *robotsRules]
+}
+
+// NewRobotsChecker fetches robots.txt files with client, as userAgent.
+func NewRobotsChecker(client *http.Client, userAgent string, ttl time.Duration) *RobotsChecker {
+	token, _, _ := strings.Cut(userAgent, "/")
+	return &RobotsChecker{client: client, agent: strings.ToLower(token), cache: newLRU[string, *robotsRules](10000, ttl)}
+}
+
+// Allowed reports whether u may be fetched. A robots.txt that is missing
+// (4xx) allows everything; one that can't be fetched (5xx, network errors)
+// disallows everything until it can, as RFC 9309 prescribes.
+func (c *RobotsChecker) Allowed(ctx context.Context, u *url.URL) bool {
+	if u.Path == "/robots.txt" {
+		return true
+	}
+	rules := c.rules(ctx, u)
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return rules.allowed(path)
+}
+
+// Sitemaps returns the sitemap URLs the robots.txt of u's host lists.
+func (c *RobotsChecker) Sitemaps(ctx context.Context, u *url.URL) []string {
+	return c.rules(ctx, u).sitemaps
+}
+
+func (c *RobotsChecker) rules(ctx context.Context, u *url.URL) *robotsRules {
+	host := strings.ToLower(u.Scheme + "://" + u.Host)
+	rules, ok := c.cache.Get(host)
+	if !ok {
+		rules = c.fetch(ctx, host)
+		c.cache.Add(host, rules)
+	}
+	return rules
+}
+
+func (c *RobotsChecker) fetch(ctx context.Context, host string) *robotsRules {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, host+"/robots.txt", nil)
+	if err != nil {
+		return &robotsRules{disallowAll: true}
+	}
+	req.Header.Set("User-Agent", c.agent)
+	resp, err := c.client.Do(req)
+	if errors.Is(err, httpclient.ErrEgressDenied) {
+		// the host, or the one robots.txt redirects to, is off the egress
+		// allowlist: a fetch of the host's URLs is refused the same way
+		return &robotsRules{}
+	}
+	if err != nil {
+		logger.From(ctx).Info("robots.txt unreachable, treating host as disallowed", "host", host, "error", err)
+		return &robotsRules{disallowAll: true}
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 500:
+		return &robotsRules{disallowAll: true}
+	case resp.StatusCode >= 400:
+		return &robotsRules{}
+	case resp.StatusCode >= 300:
+		// the client already followed the redirects it was allowed to
+		return &robotsRules{}
+	}
+	return parseRobots(io.LimitReader(resp.Body, maxRobotsSize), c.agent)
+}
+
+// robotsRules are the rules of the group that applies to one user agent,
+// and the sitemaps, which apply to everyone.
+type robotsRules struct {
+	disallowAll bool
+	rules       []robotsRule
+	sitemaps    []string
+}
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+// allowed applies the most specific matching rule, the one with the longest
+// pattern; on a tie, allow wins. No matching rule means allowed.
+func (r *robotsRules) allowed(path string) bool {
+	if r.disallowAll {
+		return false
+	}
+	best, allow := -1, true
+	for _, rule := range r.rules {
+		if !robotsMatch(rule.pattern, path) {
+			continue
+		}
+		if n := len(rule.pattern); n > best || (n == best && rule.allow) {
+			best, allow = n, rule.allow
+		}
+	}
+	return allow
+}
+
+// robotsMatch matches path against a robots.txt path pattern, where *
+// matches any run of characters and a trailing $ anchors the end.
+func robotsMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	if len(parts) == 1 {
+		return !anchored || path == parts[0]
+	}
+	pos := len(parts[0])
+	for i, p := range parts[1:] {
+		if anchored && i == len(parts)-2 {
+			return strings.HasSuffix(path[pos:], p)
+		}
+		j := strings.Index(path[pos:], p)
+		if j < 0 {
+			return false
+		}
+		pos += j + len(p)
+	}
+	return true
+}
+
+// parseRobots returns the rules of the groups naming agent, or of the "*"
+// groups when none does.
+func parseRobots(r io.Reader, agent string) *robotsRules {
+	type group struct {
+		agents []string
+		rules  []robotsRule
+	}
+	var groups []*group
+	var cur *group
+	var sitemaps []string
+	inRules := false // a rule line was seen since the last user-agent line
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64<<10), maxRobotsSize)
+	for sc.Scan() {
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		switch key {
+		case "sitemap":
+			if value != "" {
+				sitemaps = append(sitemaps, value)
+			}
+		case "user-agent":
+			if cur == nil || inRules {
+				cur = &group{}
+				groups = append(groups, cur)
+				inRules = false
+			}
+			cur.agents = append(cur.agents, strings.ToLower(value))
+		case "allow", "disallow":
+			if cur == nil {
+				continue
+			}
+			inRules = true
+			if value != "" { // an empty disallow allows everything
+				cur.rules = append(cur.rules, robotsRule{allow: key == "allow", pattern: value})
+			}
+		}
+	}
+
+	var mine, star robotsRules
+	matched := false
+	for _, g := range groups {
+		for _, a := range g.agents {
+			switch a {
+			case agent:
+				mine.rules = append(mine.rules, g.rules...)
+				matched = true
+			case "*":
+				star.rules = append(star.rules, g.rules...)
+			}
+		}
+	}
+	if matched {
+		mine.sitemaps = sitemaps
+		return &mine
+	}
+	star.sitemaps = sitemaps
+	return &star
+}
@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/urlsafe"
+)
+
+const robotsTxt = `
+# comments and unknown lines are ignored
+User-agent: Googlebot
+Disallow: /
+
+User-agent: *
+Disallow: /private
+Allow: /private/public
+Disallow: /*.pdf$
+Disallow: /search?q=
+Crawl-delay: 10
+
+Sitemap: https://example.com/sitemap.xml
+
+User-agent: byfood-resolver
+User-agent: other
+Disallow: /books/
+Allow: /books/$
+`
+
+func TestParseRobots(t *testing.T) {
+	cases := []struct {
+		agent, path string
+		want        bool
+	}{
+		{"someone", "/", true},
+		{"someone", "/private", false},
+		{"someone", "/private/public/x", true}, // the longer allow wins
+		{"someone", "/files/a.pdf", false},
+		{"someone", "/files/a.pdf?download=1", true},
+		{"someone", "/search?q=dune", false},
+		{"someone", "/books/1", true},
+		{"byfood-resolver", "/private", true}, // its own group replaces *
+		{"byfood-resolver", "/books/1", false},
+		{"byfood-resolver", "/books/", true}, // equal length: allow wins
+		{"googlebot", "/anything", false},
+	}
+	for _, c := range cases {
+		rules := parseRobots(strings.NewReader(robotsTxt), c.agent)
+		if got := rules.allowed(c.path); got != c.want {
+			t.Errorf("%s %s allowed = %v; want %v", c.agent, c.path, got, c.want)
+		}
+	}
+	if got := parseRobots(strings.NewReader(robotsTxt), "googlebot").sitemaps; len(got) != 1 || got[0] != "https://example.com/sitemap.xml" {
+		t.Errorf("sitemaps = %v", got)
+	}
+	if !parseRobots(strings.NewReader("User-agent: *\nDisallow:\n"), "x").allowed("/a") {
+		t.Errorf("empty Disallow must allow everything")
+	}
+}
+
+func TestRobotsChecker_StatusesAndCache(t *testing.T) {
+	fetches := 0
+	status := http.StatusOK
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		w.WriteHeader(status)
+		fmt.Fprint(w, "User-agent: *\nDisallow: /\n")
+	}))
+	defer ts.Close()
+	policy := urlsafe.Policy{AllowPrivate: true}
+	ctx := context.Background()
+	page, _ := url.Parse(ts.URL + "/page")
+
+	for _, c := range []struct {
+		status int
+		want   bool
+	}{
+		{http.StatusOK, false},
+		{http.StatusNotFound, true},            // no robots.txt: crawl freely
+		{http.StatusServiceUnavailable, false}, // unreachable: assume disallowed
+	} {
+		status = c.status
+		checker := NewRobotsChecker(policy.Client(time.Second), "byfood-resolver/1.0", time.Hour)
+		for i := 0; i < 2; i++ {
+			if got := checker.Allowed(ctx, page); got != c.want {
+				t.Errorf("status %d: Allowed = %v; want %v", c.status, got, c.want)
+			}
+		}
+	}
+	if fetches != 3 {
+		t.Fatalf("robots.txt fetched %d times; want once per checker", fetches)
+	}
+}
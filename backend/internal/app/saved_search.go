@@ -0,0 +1,274 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+const (
+	defaultNotificationLimit = 50
+	maxNotificationLimit     = 200
+)
+
+// KindSearchNotification dead letters hold a notification whose delivery
+// failed.
+const KindSearchNotification = "search_notification"
+
+// searchNotificationLetter is the payload of a KindSearchNotification dead
+// letter.
+type searchNotificationLetter struct {
+	Search       domain.SavedSearch        `json:"search"`
+	Notification domain.SearchNotification `json:"notification"`
+}
+
+// SavedSearches stores each actor's saved searches and, when run, follows
+// the change log to notify searches that asked for it about new books
+// matching them.
+type SavedSearches struct {
+	searches ports.SavedSearchRepository
+	feed     *ChangeFeed
+	notifier ports.Notifier
+	dlq      *DeadLetters // nil: failed deliveries are only logged
+	now      func() time.Time
+}
+
+func NewSavedSearches(searches ports.SavedSearchRepository, feed *ChangeFeed, notifier ports.Notifier) *SavedSearches {
+	return &SavedSearches{searches: searches, feed: feed, notifier: notifier, now: clock}
+}
+
+// UseDeadLetters parks failed deliveries in d, from where an operator can
+// retry them, instead of only logging them.
+func (s *SavedSearches) UseDeadLetters(d *DeadLetters) {
+	s.dlq = d
+	d.Register(KindSearchNotification, func(ctx context.Context, payload []byte) error {
+		var l searchNotificationLetter
+		if err := json.Unmarshal(payload, &l); err != nil {
+			return err
+		}
+		return s.notifier.Notify(ctx, l.Search, l.Notification)
+	})
+}
+
+func (s *SavedSearches) CreateSavedSearch(ctx context.Context, in ports.CreateSavedSearchInput) (*domain.SavedSearch, error) {
+	owner, err := searchOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	errs := &ValidationError{}
+	name, query := strings.TrimSpace(in.Name), strings.TrimSpace(in.Query)
+	switch {
+	case name == "":
+		errs.add("name", "Name is required")
+	case len(name) > 120:
+		errs.add("name", "Name must be ≤ 120 characters")
+	}
+	switch {
+	case query == "":
+		errs.add("query", "Query is required")
+	case len(query) > 500:
+		errs.add("query", "Query must be ≤ 500 characters")
+	default:
+		if _, err := ParseSearchQuery(query); err != nil {
+			errs.add("query", "Invalid query: "+err.Error())
+		}
+	}
+	if !errs.ok() {
+		return nil, errs
+	}
+
+	ss := &domain.SavedSearch{Owner: owner, Name: name, Query: query, Notify: in.Notify, CreatedAt: s.now().UTC()}
+	id, err := s.searches.Create(ctx, ss)
+	if err != nil {
+		return nil, err
+	}
+	ss.ID = id
+	return ss, nil
+}
+
+func (s *SavedSearches) ListSavedSearches(ctx context.Context) ([]domain.SavedSearch, error) {
+	owner, err := searchOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.searches.ListByOwner(ctx, owner)
+}
+
+func (s *SavedSearches) GetSavedSearch(ctx context.Context, id int64) (*domain.SavedSearch, error) {
+	owner, err := searchOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ss, err := s.searches.GetByID(ctx, owner, id)
+	if err != nil {
+		return nil, err
+	}
+	if ss == nil {
+		return nil, errors.New("saved search not found")
+	}
+	return ss, nil
+}
+
+func (s *SavedSearches) DeleteSavedSearch(ctx context.Context, id int64) error {
+	owner, err := searchOwner(ctx)
+	if err != nil {
+		return err
+	}
+	found, err := s.searches.Delete(ctx, owner, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.New("saved search not found")
+	}
+	return nil
+}
+
+// searchOwner is the actor ctx runs as, who owns the searches it saves.
+func searchOwner(ctx context.Context) (string, error) {
+	a, ok := domain.ActorFrom(ctx)
+	if !ok || a.ID == "" {
+		return "", ports.ErrNoSearchOwner
+	}
+	return a.ID, nil
+}
+
+// SearchNotifications returns the newest notifications of the caller's
+// search id. A limit of 0 means the default; larger limits are capped.
+func (s *SavedSearches) SearchNotifications(ctx context.Context, id int64, limit int) ([]domain.SearchNotification, error) {
+	if _, err := s.GetSavedSearch(ctx, id); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultNotificationLimit
+	}
+	return s.searches.Notifications(ctx, id, min(limit, maxNotificationLimit))
+}
+
+// Match notifies every search in searches that matches the book created by
+// c. Updates and deletes never notify: the feature announces new books only.
+func (s *SavedSearches) Match(ctx context.Context, searches []domain.SavedSearch, c domain.Change) error {
+	if c.Op != domain.ChangeCreated || (c.Entity != "" && c.Entity != domain.EntityBook) {
+		return nil
+	}
+	book, err := c.Book()
+	if err != nil || book == nil {
+		// entries from before payloads existed are older than any saved
+		// search the matcher could owe a notification to
+		return nil
+	}
+	for _, ss := range searches {
+		if !ss.Notify {
+			continue
+		}
+		f, err := ParseSearchQuery(ss.Query)
+		if err != nil || !f.Matches(*book) {
+			continue
+		}
+		n := domain.SearchNotification{SearchID: ss.ID, BookID: book.ID, Title: book.Title, CreatedAt: s.now().UTC()}
+		added, err := s.searches.AddNotification(ctx, &n)
+		if err != nil {
+			return err
+		}
+		if !added {
+			continue // already sent before a restart
+		}
+		// the stored notification is the record of truth; a failed delivery
+		// is parked for an operator rather than retried here
+		if err := s.notifier.Notify(ctx, ss, n); err != nil {
+			s.deliveryFailed(ctx, ss, n, err)
+		}
+	}
+	return nil
+}
+
+// MatchAll matches changes, in log order, against the current searches,
+// e.g. when they are replayed. Searches already told about a book aren't
+// told again.
+func (s *SavedSearches) MatchAll(ctx context.Context, changes []domain.Change) error {
+	searches, err := s.searches.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, c := range changes {
+		if err := s.Match(ctx, searches, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SavedSearches) deliveryFailed(ctx context.Context, ss domain.SavedSearch, n domain.SearchNotification, cause error) {
+	if s.dlq != nil {
+		err := s.dlq.Park(ctx, KindSearchNotification, searchNotificationLetter{Search: ss, Notification: n}, cause)
+		if err == nil {
+			return
+		}
+		logger.From(ctx).Error("failed to park search notification", "search_id", ss.ID, "book_id", n.BookID, "error", err)
+	}
+	logger.From(ctx).Error("failed to deliver search notification", "search_id", ss.ID, "book_id", n.BookID, "error", cause)
+}
+
+// savedSearchPollWait is how long Run long-polls the change log per round.
+const savedSearchPollWait = 30 * time.Second
+
+// Run follows the change log until ctx is done. On first start it begins at
+// the current end of the log, so existing books aren't announced.
+func (s *SavedSearches) Run(ctx context.Context) {
+	cursor, err := s.searches.Cursor(ctx)
+	if err == nil && cursor == 0 {
+		if cursor, err = s.feed.LatestID(ctx); err == nil {
+			err = s.searches.SaveCursor(ctx, cursor)
+		}
+	}
+	if err != nil {
+		logger.From(ctx).Error("saved search matcher failed to start", "error", err)
+		return
+	}
+	for ctx.Err() == nil {
+		Heartbeat(ctx)
+		next, err := s.catchUp(ctx, cursor)
+		if err != nil && ctx.Err() == nil {
+			logger.From(ctx).Error("saved search matcher failed", "cursor", next, "error", err)
+			select {
+			case <-time.After(5 * time.Second):
+			case <-ctx.Done():
+			}
+		}
+		cursor = next
+	}
+}
+
+// catchUp matches one batch of changes after cursor and returns the new cursor.
+func (s *SavedSearches) catchUp(ctx context.Context, cursor int64) (int64, error) {
+	changes, err := s.feed.Since(ctx, cursor, 100, savedSearchPollWait)
+	if err != nil || len(changes) == 0 {
+		return cursor, err
+	}
+	searches, err := s.searches.List(ctx)
+	if err != nil {
+		return cursor, err
+	}
+	for _, c := range changes {
+		if err := s.Match(ctx, searches, c); err != nil {
+			return cursor, err
+		}
+		cursor = c.ID
+	}
+	return cursor, s.searches.SaveCursor(ctx, cursor)
+}
+
+// LogNotifier delivers notifications to the application log. It stands in
+// until a real delivery channel is configured.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, s domain.SavedSearch, n domain.SearchNotification) error {
+	logger.From(ctx).Info("saved search matched a new book", "search_id", s.ID, "search", s.Name, "book_id", n.BookID, "title", n.Title)
+	return nil
+}
@@ -0,0 +1,204 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// ---- In-memory ports.SavedSearchRepository ----
+
+type memSavedSearchRepo struct {
+	mu            sync.Mutex
+	searches      []domain.SavedSearch
+	notifications []domain.SearchNotification
+	cursor        int64
+}
+
+func (m *memSavedSearchRepo) Create(ctx context.Context, s *domain.SavedSearch) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s.ID = int64(len(m.searches) + 1)
+	m.searches = append(m.searches, *s)
+	return s.ID, nil
+}
+func (m *memSavedSearchRepo) List(ctx context.Context) ([]domain.SavedSearch, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]domain.SavedSearch{}, m.searches...), nil
+}
+func (m *memSavedSearchRepo) ListByOwner(ctx context.Context, owner string) ([]domain.SavedSearch, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := []domain.SavedSearch{}
+	for _, s := range m.searches {
+		if s.Owner == owner {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+func (m *memSavedSearchRepo) GetByID(ctx context.Context, owner string, id int64) (*domain.SavedSearch, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, s := range m.searches {
+		if s.ID == id && s.Owner == owner {
+			return &s, nil
+		}
+	}
+	return nil, nil
+}
+func (m *memSavedSearchRepo) Delete(ctx context.Context, owner string, id int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, s := range m.searches {
+		if s.ID == id && s.Owner == owner {
+			m.searches = append(m.searches[:i], m.searches[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+func (m *memSavedSearchRepo) AddNotification(ctx context.Context, n *domain.SearchNotification) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, prev := range m.notifications {
+		if prev.SearchID == n.SearchID && prev.BookID == n.BookID {
+			return false, nil
+		}
+	}
+	n.ID = int64(len(m.notifications) + 1)
+	m.notifications = append(m.notifications, *n)
+	return true, nil
+}
+func (m *memSavedSearchRepo) Notifications(ctx context.Context, searchID int64, limit int) ([]domain.SearchNotification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := []domain.SearchNotification{}
+	for i := len(m.notifications) - 1; i >= 0 && len(out) < limit; i-- {
+		if m.notifications[i].SearchID == searchID {
+			out = append(out, m.notifications[i])
+		}
+	}
+	return out, nil
+}
+func (m *memSavedSearchRepo) Cursor(ctx context.Context) (int64, error) { return m.cursor, nil }
+func (m *memSavedSearchRepo) SaveCursor(ctx context.Context, cursor int64) error {
+	m.cursor = cursor
+	return nil
+}
+
+type recordingNotifier struct {
+	sent []domain.SearchNotification
+	err  error
+}
+
+func (r *recordingNotifier) Notify(ctx context.Context, s domain.SavedSearch, n domain.SearchNotification) error {
+	r.sent = append(r.sent, n)
+	return r.err
+}
+
+func TestSavedSearches_CreateValidates(t *testing.T) {
+	svc := NewSavedSearches(&memSavedSearchRepo{}, NewChangeFeed(&memChangeRepo{}), &recordingNotifier{})
+	ctx := domain.WithActor(context.Background(), domain.Actor{ID: "ann@example.com"})
+
+	_, err := svc.CreateSavedSearch(ctx, ports.CreateSavedSearchInput{Name: " ", Query: "year:x"})
+	ve, ok := err.(*ValidationError)
+	if !ok || ve.Fields["name"] == "" || ve.Fields["query"] == "" {
+		t.Fatalf("want name/query validation errors, got %v", err)
+	}
+
+	s, err := svc.CreateSavedSearch(ctx, ports.CreateSavedSearchInput{Name: " Borges ", Query: "author:borges", Notify: true})
+	if err != nil || s.ID != 1 || s.Owner != "ann@example.com" || s.Name != "Borges" || !s.Notify {
+		t.Fatalf("Create = %+v, %v", s, err)
+	}
+	if err := svc.DeleteSavedSearch(ctx, 9); err == nil || err.Error() != "saved search not found" {
+		t.Fatalf("Delete missing = %v", err)
+	}
+	if _, err := svc.SearchNotifications(ctx, 9, 0); err == nil || err.Error() != "saved search not found" {
+		t.Fatalf("Notifications of missing = %v", err)
+	}
+}
+
+func TestSavedSearches_NotifiesNewMatchesOnce(t *testing.T) {
+	repo := &memSavedSearchRepo{}
+	changes := &memChangeRepo{}
+	feed := NewChangeFeed(changes)
+	notifier := &recordingNotifier{err: errors.New("smtp down")}
+	svc := NewSavedSearches(repo, feed, notifier)
+	ctx := domain.WithActor(context.Background(), domain.Actor{ID: "ann@example.com"})
+
+	for _, in := range []ports.CreateSavedSearchInput{
+		{Name: "Borges", Query: "author:borges", Notify: true},
+		{Name: "Quiet", Query: "author:borges"},
+		{Name: "Old", Query: "year:..1900", Notify: true},
+	} {
+		if _, err := svc.CreateSavedSearch(ctx, in); err != nil {
+			t.Fatal(err)
+		}
+	}
+	borges := &domain.Book{ID: 7, Title: "Ficciones", Author: "Borges", PublicationYear: 1944}
+	_ = feed.Record(ctx, 7, domain.ChangeCreated, borges)
+	_ = feed.Record(ctx, 7, domain.ChangeUpdated, borges)
+	_ = feed.Record(ctx, 8, domain.ChangeCreated, &domain.Book{ID: 8, Title: "Emma", Author: "Austen", PublicationYear: 1815})
+
+	cursor, err := svc.catchUp(ctx, 0)
+	if err != nil || cursor != 3 || repo.cursor != 3 {
+		t.Fatalf("catchUp = %d, %v (saved %d)", cursor, err, repo.cursor)
+	}
+	// replaying the same changes, e.g. after a crash before the cursor was
+	// saved, must not announce anything twice
+	if _, err := svc.catchUp(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(notifier.sent) != 2 || notifier.sent[0].SearchID != 1 || notifier.sent[0].BookID != 7 ||
+		notifier.sent[1].SearchID != 3 || notifier.sent[1].BookID != 8 {
+		t.Fatalf("sent %+v", notifier.sent)
+	}
+	got, err := svc.SearchNotifications(ctx, 1, 0)
+	if err != nil || len(got) != 1 || got[0].Title != "Ficciones" {
+		t.Fatalf("notifications = %+v, %v", got, err)
+	}
+}
+
+func TestSavedSearches_OwnedByTheirActor(t *testing.T) {
+	svc := NewSavedSearches(&memSavedSearchRepo{}, NewChangeFeed(&memChangeRepo{}), &recordingNotifier{})
+	ann := domain.WithActor(context.Background(), domain.Actor{ID: "ann@example.com"})
+	bob := domain.WithActor(context.Background(), domain.Actor{ID: "key:bob"})
+
+	if _, err := svc.CreateSavedSearch(context.Background(), ports.CreateSavedSearchInput{Name: "Borges", Query: "author:borges"}); !errors.Is(err, ports.ErrNoSearchOwner) {
+		t.Fatalf("anonymous Create = %v", err)
+	}
+	if _, err := svc.ListSavedSearches(context.Background()); !errors.Is(err, ports.ErrNoSearchOwner) {
+		t.Fatalf("anonymous List = %v", err)
+	}
+	s, err := svc.CreateSavedSearch(ann, ports.CreateSavedSearchInput{Name: "Borges", Query: "author:borges"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if got, err := svc.ListSavedSearches(bob); err != nil || len(got) != 0 {
+		t.Fatalf("bob's List = %+v, %v", got, err)
+	}
+	if _, err := svc.GetSavedSearch(bob, s.ID); err == nil || err.Error() != "saved search not found" {
+		t.Fatalf("bob's Get = %v", err)
+	}
+	if _, err := svc.SearchNotifications(bob, s.ID, 0); err == nil || err.Error() != "saved search not found" {
+		t.Fatalf("bob's Notifications = %v", err)
+	}
+	if err := svc.DeleteSavedSearch(bob, s.ID); err == nil || err.Error() != "saved search not found" {
+		t.Fatalf("bob's Delete = %v", err)
+	}
+
+	if got, err := svc.ListSavedSearches(ann); err != nil || len(got) != 1 || got[0].ID != s.ID {
+		t.Fatalf("ann's List = %+v, %v", got, err)
+	}
+	if err := svc.DeleteSavedSearch(ann, s.ID); err != nil {
+		t.Fatalf("ann's Delete = %v", err)
+	}
+}
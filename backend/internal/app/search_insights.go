@@ -0,0 +1,168 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// MaxSearchInsightsDays is the longest period GET /admin/search/insights
+// reports on.
+const MaxSearchInsightsDays = 90
+
+// SearchInsights buffers search counts in memory and periodically adds them
+// to the repository, like CleanupStats does for URL cleanups.
+type SearchInsights struct {
+	repo ports.SearchInsightsRepository
+
+	mu      sync.Mutex
+	pending map[domain.SearchUsageKey]domain.SearchCounts
+}
+
+var _ ports.SearchInsightsService = (*SearchInsights)(nil)
+
+func NewSearchInsights(repo ports.SearchInsightsRepository) *SearchInsights {
+	return &SearchInsights{repo: repo, pending: map[domain.SearchUsageKey]domain.SearchCounts{}}
+}
+
+// Record counts one search for q that found results books. Queries are
+// counted folded, so "García" and "garcia" are one query; blank ones aren't
+// counted.
+func (s *SearchInsights) Record(q string, results int) {
+	s.add(q, func(c *domain.SearchCounts) {
+		c.Searches++
+		if results == 0 {
+			c.ZeroResults++
+		}
+	})
+}
+
+// RecordClick counts book bookID being opened from the results of a search
+// for q.
+func (s *SearchInsights) RecordClick(q string, bookID int64) error {
+	errs := &ValidationError{}
+	if domain.SearchKey(q) == "" {
+		errs.add("q", "Query is required")
+	}
+	if bookID < 1 {
+		errs.add("book_id", "Book ID must be a positive integer")
+	}
+	if !errs.ok() {
+		return errs
+	}
+	s.add(q, func(c *domain.SearchCounts) { c.Clicks++ })
+	return nil
+}
+
+func (s *SearchInsights) add(q string, count func(*domain.SearchCounts)) {
+	key := domain.SearchKey(q)
+	if key == "" {
+		return
+	}
+	if r := []rune(key); len(r) > domain.MaxSearchQueryLen {
+		key = string(r[:domain.MaxSearchQueryLen])
+	}
+	k := domain.SearchUsageKey{Day: clock().UTC().Truncate(24 * time.Hour), Query: key}
+	s.mu.Lock()
+	c := s.pending[k]
+	count(&c)
+	s.pending[k] = c
+	s.mu.Unlock()
+}
+
+// Flush writes the buffered counts. On failure they are kept for the next
+// attempt.
+func (s *SearchInsights) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = map[domain.SearchUsageKey]domain.SearchCounts{}
+	s.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+	if err := s.repo.AddSearchUsage(ctx, batch); err != nil {
+		s.mu.Lock()
+		for k, n := range batch {
+			c := s.pending[k]
+			c.Searches += n.Searches
+			c.ZeroResults += n.ZeroResults
+			c.Clicks += n.Clicks
+			s.pending[k] = c
+		}
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Run flushes every interval until ctx is done, then flushes once more.
+func (s *SearchInsights) Run(ctx context.Context, every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			Heartbeat(ctx)
+			if err := s.Flush(ctx); err != nil {
+				logger.From(ctx).Error("failed to flush search insights", "error", err)
+			}
+		case <-ctx.Done():
+			if err := s.Flush(context.Background()); err != nil {
+				logger.From(ctx).Error("failed to flush search insights", "error", err)
+			}
+			return
+		}
+	}
+}
+
+// SearchInsights reports on the searches of today and the days-1 days
+// before, as far as they have been flushed.
+func (s *SearchInsights) SearchInsights(ctx context.Context, days, top int) (*domain.SearchInsights, error) {
+	errs := &ValidationError{}
+	if days < 1 || days > MaxSearchInsightsDays {
+		errs.add("days", fmt.Sprintf("days must be between 1 and %d", MaxSearchInsightsDays))
+	}
+	if top < 1 || top > 100 {
+		errs.add("top", "top must be between 1 and 100")
+	}
+	if !errs.ok() {
+		return nil, errs
+	}
+
+	since := clock().UTC().Truncate(24*time.Hour).AddDate(0, 0, -(days - 1))
+	totals, err := s.repo.SearchTotalsSince(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	topQueries, err := s.repo.TopSearchQueriesSince(ctx, since, false, top)
+	if err != nil {
+		return nil, err
+	}
+	zeroQueries, err := s.repo.TopSearchQueriesSince(ctx, since, true, top)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.SearchInsights{
+		Since:             since,
+		SearchCounts:      totals,
+		ZeroResultRate:    rate(totals.ZeroResults, totals.Searches),
+		ClickThroughRate:  rate(totals.Clicks, totals.Searches),
+		TopQueries:        withClickThrough(topQueries),
+		ZeroResultQueries: withClickThrough(zeroQueries),
+	}, nil
+}
+
+func withClickThrough(queries []domain.SearchQueryCount) []domain.SearchQueryCount {
+	out := make([]domain.SearchQueryCount, len(queries))
+	for i, q := range queries {
+		q.ClickThroughRate = rate(q.Clicks, q.Searches)
+		out[i] = q
+	}
+	return out
+}
@@ -0,0 +1,141 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+type memSearchInsightsRepo struct {
+	counts map[domain.SearchUsageKey]domain.SearchCounts
+	err    error
+}
+
+func (m *memSearchInsightsRepo) AddSearchUsage(_ context.Context, counts map[domain.SearchUsageKey]domain.SearchCounts) error {
+	if m.err != nil {
+		return m.err
+	}
+	for k, n := range counts {
+		c := m.counts[k]
+		c.Searches += n.Searches
+		c.ZeroResults += n.ZeroResults
+		c.Clicks += n.Clicks
+		m.counts[k] = c
+	}
+	return nil
+}
+
+func (m *memSearchInsightsRepo) sums(since time.Time) map[string]domain.SearchCounts {
+	sums := map[string]domain.SearchCounts{}
+	for k, n := range m.counts {
+		if k.Day.Before(since) {
+			continue
+		}
+		c := sums[k.Query]
+		c.Searches += n.Searches
+		c.ZeroResults += n.ZeroResults
+		c.Clicks += n.Clicks
+		sums[k.Query] = c
+	}
+	return sums
+}
+
+func (m *memSearchInsightsRepo) SearchTotalsSince(_ context.Context, since time.Time) (domain.SearchCounts, error) {
+	var total domain.SearchCounts
+	for _, c := range m.sums(since) {
+		total.Searches += c.Searches
+		total.ZeroResults += c.ZeroResults
+		total.Clicks += c.Clicks
+	}
+	return total, nil
+}
+
+func (m *memSearchInsightsRepo) TopSearchQueriesSince(_ context.Context, since time.Time, zeroResults bool, limit int) ([]domain.SearchQueryCount, error) {
+	out := []domain.SearchQueryCount{}
+	for q, c := range m.sums(since) {
+		if !zeroResults || c.ZeroResults > 0 {
+			out = append(out, domain.SearchQueryCount{Query: q, SearchCounts: c})
+		}
+	}
+	by := func(c domain.SearchQueryCount) int64 {
+		if zeroResults {
+			return c.ZeroResults
+		}
+		return c.Searches
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if by(out[i]) != by(out[j]) {
+			return by(out[i]) > by(out[j])
+		}
+		return out[i].Query < out[j].Query
+	})
+	return out[:min(limit, len(out))], nil
+}
+
+func TestSearchInsights(t *testing.T) {
+	now := time.Date(2024, 1, 10, 15, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return now })
+	t.Cleanup(func() { SetClock(time.Now) })
+
+	today := now.Truncate(24 * time.Hour)
+	repo := &memSearchInsightsRepo{counts: map[domain.SearchUsageKey]domain.SearchCounts{
+		// outside a 7-day window
+		{Day: now.AddDate(0, 0, -7).Truncate(24 * time.Hour), Query: "old"}: {Searches: 50},
+	}}
+	s := NewSearchInsights(repo)
+	s.Record("García  Márquez", 3)
+	s.Record("garcia marquez", 2)
+	s.Record("dune", 1)
+	s.Record("unicorn cookbook", 0)
+	s.Record("Unicorn Cookbook", 0)
+	s.Record("   ", 0)
+	if err := s.RecordClick("GARCIA marquez", 7); err != nil {
+		t.Fatalf("RecordClick: %v", err)
+	}
+	if err := s.RecordClick(" ", 0); err == nil {
+		t.Fatalf("RecordClick accepted a blank query and book")
+	}
+	s.Record(strings.Repeat("é", 300), 1)
+
+	repo.err = errors.New("db down")
+	if err := s.Flush(context.Background()); err == nil {
+		t.Fatalf("Flush hid the repository error")
+	}
+	repo.err = nil
+	if err := s.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if c := repo.counts[domain.SearchUsageKey{Day: today, Query: "garcia marquez"}]; c != (domain.SearchCounts{Searches: 2, Clicks: 1}) {
+		t.Fatalf("garcia marquez = %+v after a failed and a good flush", c)
+	}
+	if c := repo.counts[domain.SearchUsageKey{Day: today, Query: strings.Repeat("e", domain.MaxSearchQueryLen)}]; c.Searches != 1 {
+		t.Fatalf("long query not counted by its start: %+v", repo.counts)
+	}
+
+	in, err := s.SearchInsights(context.Background(), 7, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !in.Since.Equal(time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)) || in.SearchCounts != (domain.SearchCounts{Searches: 6, ZeroResults: 2, Clicks: 1}) {
+		t.Fatalf("insights = %+v", in)
+	}
+	if in.ZeroResultRate != 2.0/6 || in.ClickThroughRate != 1.0/6 {
+		t.Fatalf("rates = %v, %v", in.ZeroResultRate, in.ClickThroughRate)
+	}
+	if len(in.TopQueries) != 2 || in.TopQueries[0].Query != "garcia marquez" || in.TopQueries[0].ClickThroughRate != 0.5 ||
+		in.TopQueries[1].Query != "unicorn cookbook" {
+		t.Fatalf("top queries = %+v", in.TopQueries)
+	}
+	if len(in.ZeroResultQueries) != 1 || in.ZeroResultQueries[0].Query != "unicorn cookbook" || in.ZeroResultQueries[0].ZeroResults != 2 {
+		t.Fatalf("zero result queries = %+v", in.ZeroResultQueries)
+	}
+
+	if _, err := s.SearchInsights(context.Background(), 0, 500); err == nil {
+		t.Fatalf("out of range days and top accepted")
+	}
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// ParseSearchQuery reads the search query language used by saved searches.
+// Bare words search title, author and aliases; the tags author:NAME,
+// tag:TAG, year:FROM..TO and price:MIN..MAX narrow the result. A range may leave
+// either end out (year:..1975) or be a single value (year:1967). Values with
+// spaces go in double quotes: author:"Ursula K. Le Guin".
+func ParseSearchQuery(s string) (ports.ListFilter, error) {
+	var f ports.ListFilter
+	terms, err := splitSearchQuery(s)
+	if err != nil {
+		return f, err
+	}
+	var words []string
+	seen := map[string]bool{}
+	for _, t := range terms {
+		key, val, ok := strings.Cut(t, ":")
+		key = strings.ToLower(key)
+		if !ok || (key != "author" && key != "tag" && key != "year" && key != "price") {
+			words = append(words, t)
+			continue
+		}
+		if seen[key] {
+			return f, fmt.Errorf("%s: given more than once", key)
+		}
+		seen[key] = true
+		if val == "" {
+			return f, fmt.Errorf("%s: missing value", key)
+		}
+		switch key {
+		case "author":
+			f.Author = val
+		case "tag":
+			f.Tag = val
+		case "year":
+			if f.YearFrom, f.YearTo, err = parseRange(val, strconv.Atoi); err != nil {
+				return f, fmt.Errorf("year: %w", err)
+			}
+		case "price":
+			parse := func(v string) (float64, error) { return strconv.ParseFloat(v, 64) }
+			if f.PriceMin, f.PriceMax, err = parseRange(val, parse); err != nil {
+				return f, fmt.Errorf("price: %w", err)
+			}
+		}
+	}
+	f.Q = strings.Join(words, " ")
+	return f, nil
+}
+
+// splitSearchQuery splits s on spaces outside double quotes and drops the
+// quotes.
+func splitSearchQuery(s string) ([]string, error) {
+	var terms []string
+	var cur strings.Builder
+	quoted, inTerm := false, false
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted, inTerm = !quoted, true
+		case !quoted && (r == ' ' || r == '\t' || r == '\n'):
+			if inTerm {
+				terms = append(terms, cur.String())
+				cur.Reset()
+				inTerm = false
+			}
+		default:
+			cur.WriteRune(r)
+			inTerm = true
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inTerm {
+		terms = append(terms, cur.String())
+	}
+	return terms, nil
+}
+
+func parseRange[T int | float64](v string, parse func(string) (T, error)) (lo, hi *T, err error) {
+	from, to, isRange := strings.Cut(v, "..")
+	if !isRange {
+		to = from
+	}
+	if from == "" && to == "" {
+		return nil, nil, fmt.Errorf("empty range")
+	}
+	for _, p := range []struct {
+		s   string
+		dst **T
+	}{{from, &lo}, {to, &hi}} {
+		if p.s == "" {
+			continue
+		}
+		n, err := parse(p.s)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%q is not a number", p.s)
+		}
+		*p.dst = &n
+	}
+	if lo != nil && hi != nil && *lo > *hi {
+		return nil, nil, fmt.Errorf("range %s is backwards", v)
+	}
+	return lo, hi, nil
+}
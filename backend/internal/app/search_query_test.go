@@ -0,0 +1,63 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	cases := []struct {
+		in   string
+		want ports.ListFilter
+	}{
+		{"dragons", ports.ListFilter{Q: "dragons"}},
+		{`author:"Ursula K. Le Guin" year:..1975 earthsea wizard`, ports.ListFilter{
+			Q: "earthsea wizard", Author: "Ursula K. Le Guin", YearTo: iptr(1975)}},
+		{"Year:1967 price:5.5..20", ports.ListFilter{
+			YearFrom: iptr(1967), YearTo: iptr(1967), PriceMin: f64ptr(5.5), PriceMax: f64ptr(20)}},
+		{"price:10..", ports.ListFilter{PriceMin: f64ptr(10)}},
+		{`tag:"cozy mystery" murder`, ports.ListFilter{Q: "murder", Tag: "cozy mystery"}},
+		{`"dune: messiah" isbn:123`, ports.ListFilter{Q: "dune: messiah isbn:123"}},
+	}
+	for _, c := range cases {
+		got, err := ParseSearchQuery(c.in)
+		if err != nil {
+			t.Fatalf("%q: %v", c.in, err)
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Fatalf("%q = %+v; want %+v", c.in, got, c.want)
+		}
+	}
+
+	for _, bad := range []string{`author:"open`, "year:", "year:..", "year:abc", "price:20..5", "author:a author:b"} {
+		if _, err := ParseSearchQuery(bad); err == nil {
+			t.Fatalf("%q: want error", bad)
+		}
+	}
+}
+
+func TestMatchesFilter(t *testing.T) {
+	b := domain.Book{Title: "A Wizard of Earthsea", Author: "Ursula K. Le Guin", PublicationYear: 1968, Price: 9.99,
+		Aliases: []string{"Earthsea 1"}}
+	for q, want := range map[string]bool{
+		"wizard":                         true,
+		"earthsea 1":                     true,
+		`author:"ursula k. le guin"`:     true,
+		"author:guin":                    false,
+		"year:1960..1970 price:..10":     true,
+		"year:1969..":                    false,
+		"price:10..":                     false,
+		`author:"Ursula K. Le Guin" hob`: false,
+	} {
+		f, err := ParseSearchQuery(q)
+		if err != nil {
+			t.Fatalf("%q: %v", q, err)
+		}
+		if got := f.Matches(b); got != want {
+			t.Fatalf("%q matched = %v; want %v", q, got, want)
+		}
+	}
+}
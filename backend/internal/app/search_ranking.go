@@ -0,0 +1,124 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// rankingTenants is how many tenants' rankings are kept in memory.
+const rankingTenants = 10000
+
+// maxTenantLen is the longest tenant the ranking table stores.
+const maxTenantLen = 191
+
+// SearchRankings stores the ranking of each tenant and keeps them in memory
+// for the searches that use them. Like ListPreferences, a tenant's entry is
+// dropped when its ranking changes here; other replicas see the change once
+// their entry expires after ttl.
+type SearchRankings struct {
+	repo  ports.SearchRankingRepository
+	cache *lru[string, *domain.RankingWeights] // nil value: the tenant has none
+	now   func() time.Time
+}
+
+func NewSearchRankings(repo ports.SearchRankingRepository, ttl time.Duration) *SearchRankings {
+	return &SearchRankings{repo: repo, cache: newLRU[string, *domain.RankingWeights](rankingTenants, ttl), now: clock}
+}
+
+func (s *SearchRankings) ListSearchRankings(ctx context.Context) (*ports.SearchRankings, error) {
+	tenants, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tenants == nil {
+		tenants = []domain.SearchRanking{}
+	}
+	return &ports.SearchRankings{Defaults: domain.DefaultRankingWeights(), Tenants: tenants}, nil
+}
+
+func (s *SearchRankings) GetSearchRanking(ctx context.Context, tenant string) (*domain.SearchRanking, error) {
+	r, err := s.repo.Get(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, errors.New("search ranking not found")
+	}
+	return r, nil
+}
+
+// PutSearchRanking replaces the ranking of tenant. Weights range from 0 to
+// domain.MaxRankingWeight.
+func (s *SearchRankings) PutSearchRanking(ctx context.Context, tenant string, in ports.PutSearchRankingInput) (*domain.SearchRanking, error) {
+	errs := &ValidationError{}
+	tenant = strings.TrimSpace(tenant)
+	switch {
+	case tenant == "":
+		errs.add("tenant", "Tenant is required")
+	case utf8.RuneCountInString(tenant) > maxTenantLen:
+		errs.add("tenant", fmt.Sprintf("Tenant must be ≤ %d characters", maxTenantLen))
+	}
+	w := domain.DefaultRankingWeights()
+	weight := func(field, name string, v *float64, into *float64) {
+		if v == nil {
+			return
+		}
+		if *v < 0 || *v > domain.MaxRankingWeight {
+			errs.add(field, fmt.Sprintf("%s must be between 0 and %d", name, domain.MaxRankingWeight))
+		}
+		*into = *v
+	}
+	weight("title_weight", "Title weight", in.TitleWeight, &w.TitleWeight)
+	weight("author_weight", "Author weight", in.AuthorWeight, &w.AuthorWeight)
+	weight("recency_boost", "Recency boost", in.RecencyBoost, &w.RecencyBoost)
+	if !errs.ok() {
+		return nil, errs
+	}
+	r := &domain.SearchRanking{Tenant: tenant, RankingWeights: w, UpdatedAt: s.now().UTC()}
+	if err := s.repo.Put(ctx, r); err != nil {
+		return nil, err
+	}
+	s.cache.Remove(tenant)
+	return r, nil
+}
+
+func (s *SearchRankings) DeleteSearchRanking(ctx context.Context, tenant string) error {
+	found, err := s.repo.Delete(ctx, tenant)
+	if err != nil {
+		return err
+	}
+	s.cache.Remove(tenant)
+	if !found {
+		return errors.New("search ranking not found")
+	}
+	return nil
+}
+
+func (s *SearchRankings) For(ctx context.Context) (domain.RankingWeights, error) {
+	a, ok := domain.ActorFrom(ctx)
+	if !ok || a.ID == "" {
+		return domain.DefaultRankingWeights(), nil
+	}
+	w, ok := s.cache.Get(a.ID)
+	if !ok {
+		r, err := s.repo.Get(ctx, a.ID)
+		if err != nil {
+			return domain.DefaultRankingWeights(), err
+		}
+		if r != nil {
+			w = &r.RankingWeights
+		}
+		s.cache.Add(a.ID, w)
+	}
+	if w == nil {
+		return domain.DefaultRankingWeights(), nil
+	}
+	return *w, nil
+}
@@ -0,0 +1,130 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// ---- In-memory ports.SearchRankingRepository ----
+
+type memSearchRankingRepo struct {
+	mu       sync.Mutex
+	rankings map[string]domain.SearchRanking
+	gets     int
+}
+
+func (m *memSearchRankingRepo) Get(ctx context.Context, tenant string) (*domain.SearchRanking, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gets++
+	if r, ok := m.rankings[tenant]; ok {
+		return &r, nil
+	}
+	return nil, nil
+}
+func (m *memSearchRankingRepo) List(ctx context.Context) ([]domain.SearchRanking, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []domain.SearchRanking
+	for _, r := range m.rankings {
+		out = append(out, r)
+	}
+	return out, nil
+}
+func (m *memSearchRankingRepo) Put(ctx context.Context, r *domain.SearchRanking) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.rankings == nil {
+		m.rankings = map[string]domain.SearchRanking{}
+	}
+	m.rankings[r.Tenant] = *r
+	return nil
+}
+func (m *memSearchRankingRepo) Delete(ctx context.Context, tenant string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.rankings[tenant]
+	delete(m.rankings, tenant)
+	return ok, nil
+}
+
+func weight(v float64) *float64 { return &v }
+
+func TestSearchRankings_Validation(t *testing.T) {
+	s := NewSearchRankings(&memSearchRankingRepo{}, time.Minute)
+	ctx := context.Background()
+	for _, tc := range []struct {
+		tenant string
+		in     ports.PutSearchRankingInput
+		field  string
+	}{
+		{" ", ports.PutSearchRankingInput{}, "tenant"},
+		{strings.Repeat("k", 192), ports.PutSearchRankingInput{}, "tenant"},
+		{"key:storefront", ports.PutSearchRankingInput{TitleWeight: weight(-1)}, "title_weight"},
+		{"key:storefront", ports.PutSearchRankingInput{AuthorWeight: weight(10.5)}, "author_weight"},
+		{"key:storefront", ports.PutSearchRankingInput{RecencyBoost: weight(100)}, "recency_boost"},
+	} {
+		_, err := s.PutSearchRanking(ctx, tc.tenant, tc.in)
+		var ve *ValidationError
+		if !errors.As(err, &ve) || ve.Fields[tc.field] == "" {
+			t.Errorf("Put(%q, %+v) = %v; want an error for %s", tc.tenant, tc.in, err, tc.field)
+		}
+	}
+
+	r, err := s.PutSearchRanking(ctx, "key:storefront", ports.PutSearchRankingInput{AuthorWeight: weight(0), RecencyBoost: weight(10)})
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	want := domain.RankingWeights{TitleWeight: 2, AuthorWeight: 0, RecencyBoost: 10}
+	if r.Tenant != "key:storefront" || r.RankingWeights != want {
+		t.Fatalf("ranking = %+v; want the default title weight and %+v", r, want)
+	}
+	list, err := s.ListSearchRankings(ctx)
+	if err != nil || list.Defaults != domain.DefaultRankingWeights() || len(list.Tenants) != 1 {
+		t.Fatalf("List = %+v, %v", list, err)
+	}
+}
+
+func TestSearchRankings_ForCachesPerTenant(t *testing.T) {
+	repo := &memSearchRankingRepo{}
+	s := NewSearchRankings(repo, time.Minute)
+	if w, err := s.For(context.Background()); w != domain.DefaultRankingWeights() || err != nil {
+		t.Fatalf("anonymous For = %+v, %v; want the defaults", w, err)
+	}
+	if repo.gets != 0 {
+		t.Fatalf("anonymous For read the repository")
+	}
+
+	ctx := domain.WithActor(context.Background(), domain.Actor{ID: "key:storefront"})
+	if w, _ := s.For(ctx); w != domain.DefaultRankingWeights() {
+		t.Fatalf("For before Put = %+v", w)
+	}
+	if _, err := s.PutSearchRanking(context.Background(), "key:storefront", ports.PutSearchRankingInput{TitleWeight: weight(5)}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	for range 3 {
+		if w, _ := s.For(ctx); w.TitleWeight != 5 {
+			t.Fatalf("For after Put = %+v; the cached miss survived the Put", w)
+		}
+	}
+	if repo.gets != 2 {
+		t.Fatalf("repo reads = %d; want 2 (the miss, then one after the Put)", repo.gets)
+	}
+
+	if err := s.DeleteSearchRanking(context.Background(), "key:storefront"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if w, _ := s.For(ctx); w != domain.DefaultRankingWeights() {
+		t.Fatalf("For after Delete = %+v", w)
+	}
+	if err := s.DeleteSearchRanking(context.Background(), "key:storefront"); err == nil || err.Error() != "search ranking not found" {
+		t.Fatalf("second Delete = %v", err)
+	}
+}
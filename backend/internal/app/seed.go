@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// seedActor is who the change log credits seeded books to.
+var seedActor = domain.Actor{ID: "seed"}
+
+// SeedBook is a sample book with its alternate titles.
+type SeedBook struct {
+	ports.CreateBookInput
+	Aliases []string `json:"aliases"`
+}
+
+// LoadSeedBooks decodes a JSON array of books in the API's book shape.
+// Fields the API assigns, like id and created_at, are ignored, so the demo
+// fixtures double as seed data.
+func LoadSeedBooks(r io.Reader) ([]SeedBook, error) {
+	var books []SeedBook
+	if err := json.NewDecoder(r).Decode(&books); err != nil {
+		return nil, fmt.Errorf("decode seed books: %w", err)
+	}
+	return books, nil
+}
+
+// Seed imports books through svc like POST /books/import, so they are
+// validated, logged as changes by the actor "seed" and skipped when their
+// ISBN is already in the catalogue: seeding twice adds nothing. The aliases
+// of the books it inserts are added through aliases, when set.
+func Seed(ctx context.Context, svc ports.BookService, aliases ports.AliasService, books []SeedBook) (*ports.ImportResult, error) {
+	ctx = domain.WithActor(ctx, seedActor)
+	rows := make([]ports.ImportRow, len(books))
+	for i, b := range books {
+		rows[i] = ports.ImportRow{Row: i + 1, Book: b.CreateBookInput}
+	}
+	res, err := svc.ImportBooks(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range res.Rows {
+		if r.Status != ports.ImportInserted || aliases == nil {
+			continue
+		}
+		for _, alias := range books[r.Row-1].Aliases {
+			if _, err := aliases.AddAlias(ctx, r.ID, ports.AddAliasInput{Alias: alias}); err != nil {
+				logger.From(ctx).Error("seed alias", "book_id", r.ID, "alias", alias, "error", err)
+			}
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSeed(t *testing.T) {
+	books, err := LoadSeedBooks(strings.NewReader(`[
+		{"id": 9, "title": "Ficciones", "author": "Jorge Luis Borges", "isbn": "9780802130303", "price": 12.5,
+		 "publication_year": 1944, "created_at": "2024-01-01T09:20:00Z", "aliases": ["Fictions"]},
+		{"title": "No ISBN", "author": "X", "publication_year": 2000}
+	]`))
+	if err != nil {
+		t.Fatalf("LoadSeedBooks err: %v", err)
+	}
+	repo, aliasRepo, changes := newMemBookRepo(), &memAliasRepo{}, &memChangeRepo{}
+	feed := NewChangeFeed(changes)
+	svc := NewBookService(repo, WithChangeFeed(feed), WithAliases(aliasRepo))
+	aliases := NewAliasService(repo, aliasRepo, feed)
+
+	res, err := Seed(context.Background(), svc, aliases, books)
+	if err != nil {
+		t.Fatalf("Seed err: %v", err)
+	}
+	if res.Inserted != 1 || res.Failed != 1 || len(repo.books) != 1 {
+		t.Fatalf("result = %+v; repo has %d books", res, len(repo.books))
+	}
+	if len(aliasRepo.aliases) != 1 || aliasRepo.aliases[0].Alias != "Fictions" || aliasRepo.aliases[0].BookID != res.Rows[0].ID {
+		t.Fatalf("aliases = %+v", aliasRepo.aliases)
+	}
+	for _, c := range changes.changes {
+		if c.Actor != seedActor.ID {
+			t.Fatalf("change %+v not credited to %q", c, seedActor.ID)
+		}
+	}
+
+	res, err = Seed(context.Background(), svc, aliases, books[:1])
+	if err != nil || res.Skipped != 1 || len(repo.books) != 1 || len(aliasRepo.aliases) != 1 {
+		t.Fatalf("second Seed = %+v, %v; want the book skipped", res, err)
+	}
+}
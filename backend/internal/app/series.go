@@ -0,0 +1,166 @@
+package app
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type seriesService struct {
+	repo    ports.SeriesRepository
+	books   ports.BookReader
+	changes *ChangeFeed // optional; detaching books counts as updating them
+}
+
+func NewSeriesService(repo ports.SeriesRepository, books ports.BookReader, changes *ChangeFeed) ports.SeriesService {
+	return &seriesService{repo: repo, books: books, changes: changes}
+}
+
+func (s *seriesService) CreateSeries(ctx context.Context, in ports.SeriesInput) (*domain.Series, error) {
+	in, err := validateSeries(in)
+	if err != nil {
+		return nil, err
+	}
+	now := clock().UTC()
+	series := &domain.Series{Name: in.Name, CreatedAt: now, UpdatedAt: now}
+	id, err := s.repo.Create(ctx, series)
+	if err != nil {
+		return nil, err
+	}
+	series.ID = id
+	return series, nil
+}
+
+func (s *seriesService) ListSeries(ctx context.Context) ([]domain.Series, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *seriesService) GetSeries(ctx context.Context, id int64) (*domain.Series, error) {
+	series, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if series == nil {
+		return nil, errors.New("series not found")
+	}
+	return series, nil
+}
+
+func (s *seriesService) UpdateSeries(ctx context.Context, id int64, in ports.SeriesInput) (*domain.Series, error) {
+	in, err := validateSeries(in)
+	if err != nil {
+		return nil, err
+	}
+	series, err := s.GetSeries(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	series.Name, series.UpdatedAt = in.Name, clock().UTC()
+	found, err := s.repo.Update(ctx, series)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New("series not found")
+	}
+	return series, nil
+}
+
+func (s *seriesService) DeleteSeries(ctx context.Context, id int64, detach bool) error {
+	found, detached, err := s.repo.Delete(ctx, id, detach, clock().UTC())
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.New("series not found")
+	}
+	for _, bookID := range detached {
+		s.recordChange(ctx, bookID)
+	}
+	return nil
+}
+
+func (s *seriesService) ListSeriesBooks(ctx context.Context, id int64) ([]domain.Book, error) {
+	if _, err := s.GetSeries(ctx, id); err != nil {
+		return nil, err
+	}
+	books, err := s.books.List(ctx, ports.ListFilter{SeriesID: id})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(books, func(a, b domain.Book) int {
+		switch {
+		case a.SeriesPosition == nil && b.SeriesPosition != nil:
+			return 1
+		case a.SeriesPosition != nil && b.SeriesPosition == nil:
+			return -1
+		}
+		return cmp.Or(
+			cmp.Compare(idOrZero(a.SeriesPosition), idOrZero(b.SeriesPosition)),
+			cmp.Compare(a.PublicationYear, b.PublicationYear),
+			cmp.Compare(a.ID, b.ID),
+		)
+	})
+	return books, nil
+}
+
+func (s *seriesService) ListEditions(ctx context.Context, id int64) (*ports.Editions, error) {
+	b, err := s.books.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, errors.New("book not found")
+	}
+	work := b.ID
+	if b.WorkID != nil {
+		work = *b.WorkID
+	}
+	books, err := s.books.List(ctx, ports.ListFilter{WorkID: work})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(books, func(a, b domain.Book) int {
+		return cmp.Or(
+			cmp.Compare(a.PublicationYear, b.PublicationYear),
+			cmp.Compare(a.Format, b.Format),
+			cmp.Compare(a.ID, b.ID),
+		)
+	})
+	return &ports.Editions{WorkID: work, Books: books}, nil
+}
+
+func (s *seriesService) recordChange(ctx context.Context, bookID int64) {
+	if s.changes == nil {
+		return
+	}
+	b, err := s.books.GetByID(ctx, bookID)
+	if err != nil {
+		logger.From(ctx).Error("failed to read book for change payload", "id", bookID, "error", err)
+	}
+	if err := s.changes.Record(ctx, bookID, domain.ChangeUpdated, b); err != nil {
+		logger.From(ctx).Error("failed to record book change", "id", bookID, "op", domain.ChangeUpdated, "error", err)
+	}
+}
+
+// validateSeries checks in and trims the name.
+func validateSeries(in ports.SeriesInput) (ports.SeriesInput, error) {
+	errs := &ValidationError{}
+	in.Name = strings.TrimSpace(in.Name)
+	switch {
+	case in.Name == "":
+		errs.add("name", "Name is required")
+	case len(in.Name) > 120:
+		errs.add("name", "Name must be ≤ 120 characters")
+	}
+	if !errs.ok() {
+		return in, errs
+	}
+	return in, nil
+}
@@ -0,0 +1,209 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// memSeriesRepo keeps series next to the books of a memBookRepo, enforcing
+// the relation like the foreign key does.
+type memSeriesRepo struct {
+	series map[int64]domain.Series
+	books  *memBookRepo
+}
+
+func (m *memSeriesRepo) Create(ctx context.Context, s *domain.Series) (int64, error) {
+	for _, other := range m.series {
+		if other.Name == s.Name {
+			return 0, ports.ErrSeriesNameTaken
+		}
+	}
+	s.ID = int64(len(m.series) + 1)
+	m.series[s.ID] = *s
+	return s.ID, nil
+}
+func (m *memSeriesRepo) List(ctx context.Context) ([]domain.Series, error) {
+	return nil, nil
+}
+func (m *memSeriesRepo) GetByID(ctx context.Context, id int64) (*domain.Series, error) {
+	s, ok := m.series[id]
+	if !ok {
+		return nil, nil
+	}
+	return &s, nil
+}
+func (m *memSeriesRepo) Update(ctx context.Context, s *domain.Series) (bool, error) {
+	_, ok := m.series[s.ID]
+	if ok {
+		m.series[s.ID] = *s
+	}
+	return ok, nil
+}
+func (m *memSeriesRepo) Delete(ctx context.Context, id int64, detach bool, at time.Time) (bool, []int64, error) {
+	if _, ok := m.series[id]; !ok {
+		return false, nil, nil
+	}
+	var books []int64
+	for _, b := range m.books.books {
+		if b.SeriesID != nil && *b.SeriesID == id {
+			books = append(books, b.ID)
+		}
+	}
+	if len(books) > 0 && !detach {
+		return true, nil, ports.ErrSeriesInUse
+	}
+	for _, bid := range books {
+		b := m.books.books[bid]
+		b.SeriesID, b.SeriesPosition, b.UpdatedAt = nil, nil, at
+		m.books.books[bid] = b
+	}
+	delete(m.series, id)
+	return true, books, nil
+}
+
+func TestSeries_ValidatesAndDetaches(t *testing.T) {
+	dune := int64(1)
+	one := 1
+	books := newMemBookRepo(domain.Book{ID: 7, Title: "Dune", SeriesID: &dune, SeriesPosition: &one}, domain.Book{ID: 8, Title: "Emma"})
+	repo := &memSeriesRepo{series: map[int64]domain.Series{1: {ID: 1, Name: "Dune"}}, books: books}
+	changes := &memChangeRepo{}
+	svc := NewSeriesService(repo, books, NewChangeFeed(changes))
+	ctx := context.Background()
+
+	s, err := svc.CreateSeries(ctx, ports.SeriesInput{Name: "  Foundation "})
+	if err != nil || s.Name != "Foundation" {
+		t.Fatalf("create = %+v, %v", s, err)
+	}
+	if _, err := svc.CreateSeries(ctx, ports.SeriesInput{Name: "Dune"}); !errors.Is(err, ports.ErrSeriesNameTaken) {
+		t.Fatalf("duplicate: %v", err)
+	}
+	var ve *ValidationError
+	if _, err := svc.CreateSeries(ctx, ports.SeriesInput{Name: " "}); !errors.As(err, &ve) || ve.Fields["name"] == "" {
+		t.Fatalf("blank name: %v", err)
+	}
+
+	if err := svc.DeleteSeries(ctx, 1, false); !errors.Is(err, ports.ErrSeriesInUse) {
+		t.Fatalf("delete in use: %v", err)
+	}
+	if err := svc.DeleteSeries(ctx, 1, true); err != nil {
+		t.Fatalf("delete detaching: %v", err)
+	}
+	if b := books.books[7]; b.SeriesID != nil || b.SeriesPosition != nil {
+		t.Fatalf("book 7 still in series: %+v", b)
+	}
+	if len(changes.changes) != 1 || changes.changes[0].BookID != 7 {
+		t.Fatalf("changes = %+v", changes.changes)
+	}
+	if _, err := svc.ListSeriesBooks(ctx, 1); err == nil || err.Error() != "series not found" {
+		t.Fatalf("books of deleted series: %v", err)
+	}
+}
+
+func TestSeries_ListsBooksInOrder(t *testing.T) {
+	sid := int64(1)
+	pos := func(n int) *int { return &n }
+	books := newMemBookRepo(
+		domain.Book{ID: 1, Title: "Children of Dune", SeriesID: &sid, SeriesPosition: pos(3), PublicationYear: 1976},
+		domain.Book{ID: 2, Title: "Dune Companion", SeriesID: &sid, PublicationYear: 1984},
+		domain.Book{ID: 3, Title: "Dune", SeriesID: &sid, SeriesPosition: pos(1), PublicationYear: 1965},
+		domain.Book{ID: 4, Title: "Dune Messiah", SeriesID: &sid, SeriesPosition: pos(2), PublicationYear: 1969},
+		domain.Book{ID: 5, Title: "Dune (ebook)", SeriesID: &sid, SeriesPosition: pos(1), PublicationYear: 2005},
+		domain.Book{ID: 6, Title: "Emma"},
+	)
+	svc := NewSeriesService(&memSeriesRepo{series: map[int64]domain.Series{1: {ID: 1, Name: "Dune"}}, books: books}, books, nil)
+
+	got, err := svc.ListSeriesBooks(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int64
+	for _, b := range got {
+		ids = append(ids, b.ID)
+	}
+	if want := []int64{3, 5, 4, 1, 2}; !slices.Equal(ids, want) {
+		t.Fatalf("order = %v; want %v", ids, want)
+	}
+}
+
+func TestSeries_ListsEditionsOfAWork(t *testing.T) {
+	root := int64(1)
+	books := newMemBookRepo(
+		domain.Book{ID: 1, Title: "Dune", Format: "hardcover", PublicationYear: 1965},
+		domain.Book{ID: 2, Title: "Dune", Format: "paperback", PublicationYear: 1990, WorkID: &root},
+		domain.Book{ID: 3, Title: "Dune", Format: "ebook", PublicationYear: 1990, WorkID: &root},
+		domain.Book{ID: 4, Title: "Emma", PublicationYear: 1815},
+	)
+	svc := NewSeriesService(&memSeriesRepo{series: map[int64]domain.Series{}, books: books}, books, nil)
+	ctx := context.Background()
+
+	res, err := svc.ListEditions(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int64
+	for _, b := range res.Books {
+		ids = append(ids, b.ID)
+	}
+	if res.WorkID != 1 || !slices.Equal(ids, []int64{1, 3, 2}) {
+		t.Fatalf("editions = %d %v", res.WorkID, ids)
+	}
+
+	res, err = svc.ListEditions(ctx, 4)
+	if err != nil || res.WorkID != 4 || len(res.Books) != 1 {
+		t.Fatalf("standalone = %+v, %v", res, err)
+	}
+	if _, err := svc.ListEditions(ctx, 9); err == nil || err.Error() != "book not found" {
+		t.Fatalf("missing book: %v", err)
+	}
+}
+
+func TestBookSeriesAndWork_Validation(t *testing.T) {
+	root := int64(1)
+	books := newMemBookRepo(
+		domain.Book{ID: 1, Title: "Dune"},
+		domain.Book{ID: 2, Title: "Dune", WorkID: &root},
+		domain.Book{ID: 3, Title: "Emma"},
+	)
+	svc := NewBookService(books)
+	ctx := context.Background()
+	in := ports.CreateBookInput{Title: "Dune", Author: "Frank Herbert", ISBN: "9780441013593", Price: 10, PublicationYear: 1965}
+
+	pos, format := 1, "vinyl"
+	in.SeriesPosition, in.Format = &pos, format
+	_, err := svc.CreateBook(ctx, in)
+	var ve *ValidationError
+	if !errors.As(err, &ve) || ve.Fields["series_position"] == "" || ve.Fields["format"] == "" {
+		t.Fatalf("create = %v", err)
+	}
+
+	// a work_id naming any edition resolves to the work's original
+	in.SeriesPosition, in.Format = nil, "ebook"
+	edition := int64(2)
+	in.WorkID = &edition
+	b, err := svc.CreateBook(ctx, in)
+	if err != nil || b.WorkID == nil || *b.WorkID != 1 || b.Format != "ebook" {
+		t.Fatalf("create edition = %+v, %v", b, err)
+	}
+	missing := int64(99)
+	in.WorkID = &missing
+	if _, err := svc.CreateBook(ctx, in); !errors.As(err, &ve) || ve.Fields["work_id"] == "" {
+		t.Fatalf("unknown work: %v", err)
+	}
+
+	// the original can't leave a work its editions still share
+	other := int64(3)
+	if _, err := svc.UpdateBook(ctx, 1, ports.UpdateBookInput{WorkID: &other}); !errors.As(err, &ve) || ve.Fields["work_id"] == "" {
+		t.Fatalf("move original: %v", err)
+	}
+	none := int64(0)
+	b, err = svc.UpdateBook(ctx, 2, ports.UpdateBookInput{WorkID: &none})
+	if err != nil || b.WorkID != nil {
+		t.Fatalf("make standalone = %+v, %v", b, err)
+	}
+}
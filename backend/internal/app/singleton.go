@@ -0,0 +1,60 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// Singleton wraps job so that only the replica holding lock name runs it.
+// The others stand by, sending heartbeats and trying to take the lock every
+// retry, so one of them takes over when the holder crashes or loses its
+// lease. job's context is cancelled when the lease is lost; it is started
+// again once the lock is regained. If job returns by itself, so does the
+// wrapper.
+func Singleton(locker ports.Locker, name string, retry time.Duration, job func(ctx context.Context)) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		for ctx.Err() == nil {
+			Heartbeat(ctx)
+			lease, ok, err := locker.TryLock(ctx, name)
+			if err != nil && ctx.Err() == nil {
+				logger.From(ctx).Error("failed to take singleton lock", "job", name, "error", err)
+			}
+			if ok && !runLeased(ctx, name, lease, job) {
+				return
+			}
+			select {
+			case <-time.After(retry):
+			case <-ctx.Done():
+			}
+		}
+	}
+}
+
+// runLeased runs job until it returns or lease is lost, and reports whether
+// it was the lease that ended it.
+func runLeased(ctx context.Context, name string, lease ports.Lease, job func(ctx context.Context)) (lost bool) {
+	logger.From(ctx).Info("took singleton lock", "job", name)
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-lease.Lost():
+			logger.Log.Error("singleton lock lost, stopping job", "job", name)
+			cancel()
+		case <-jobCtx.Done():
+		}
+	}()
+	job(jobCtx)
+	select {
+	case <-lease.Lost():
+		return true
+	default:
+	}
+	if err := lease.Release(context.WithoutCancel(ctx)); err != nil {
+		logger.From(ctx).Error("failed to release singleton lock", "job", name, "error", err)
+	}
+	return false
+}
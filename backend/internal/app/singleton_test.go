@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// memLocker is a ports.Locker shared by the "replicas" of a test.
+type memLocker struct {
+	mu     sync.Mutex
+	holder *memLease
+}
+
+type memLease struct {
+	l        *memLocker
+	lost     chan struct{}
+	released bool
+}
+
+func (ls *memLease) Lost() <-chan struct{} { return ls.lost }
+func (ls *memLease) Release(ctx context.Context) error {
+	ls.l.mu.Lock()
+	defer ls.l.mu.Unlock()
+	ls.released = true
+	if ls.l.holder == ls {
+		ls.l.holder = nil
+	}
+	return nil
+}
+
+func (l *memLocker) TryLock(ctx context.Context, name string) (ports.Lease, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.holder != nil {
+		return nil, false, nil
+	}
+	l.holder = &memLease{l: l, lost: make(chan struct{})}
+	return l.holder, true, nil
+}
+
+// crash drops the current holder's lock as if its session had died.
+func (l *memLocker) crash() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	close(l.holder.lost)
+	l.holder = nil
+}
+
+func TestSingleton_OneReplicaRunsAndOtherTakesOver(t *testing.T) {
+	locker := &memLocker{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	running := make(chan string, 4)
+	stopped := make(chan string, 4)
+	replica := func(name string) {
+		go Singleton(locker, "purge", time.Millisecond, func(ctx context.Context) {
+			running <- name
+			<-ctx.Done()
+			stopped <- name
+		})(ctx)
+	}
+	replica("a")
+	first := <-running
+	replica("b")
+	select {
+	case second := <-running:
+		t.Fatalf("%s started while %s holds the lock", second, first)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	locker.crash()
+	if got := <-stopped; got != first {
+		t.Fatalf("stopped %s; want the holder %s", got, first)
+	}
+	// either replica may win the lock back, but only one runs
+	<-running
+	select {
+	case extra := <-running:
+		t.Fatalf("%s started as well", extra)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestSingleton_ReleasesWhenJobReturns(t *testing.T) {
+	locker := &memLocker{}
+	var lease *memLease
+	Singleton(locker, "report", time.Millisecond, func(ctx context.Context) {
+		lease = locker.holder
+	})(context.Background())
+	if lease == nil || !lease.released || locker.holder != nil {
+		t.Fatalf("lease = %+v, holder = %+v; want the lock released", lease, locker.holder)
+	}
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// SplitBook turns one record that wrongly covered two editions into two. The
+// original keeps its id, history and remaining aliases; the new edition is a
+// copy with in.Fields applied. Both end up with the same work_id.
+func (s *bookService) SplitBook(ctx context.Context, id int64, in ports.SplitBookInput) (*ports.SplitBookResult, error) {
+	source, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, errors.New("book not found")
+	}
+
+	fields, err := validateAndNormalizeUpdate(in.Fields)
+	if err != nil {
+		return nil, prefixFields(err, "fields.")
+	}
+	errs := &ValidationError{}
+	if fields.ISBN == nil {
+		errs.add("fields.isbn", "ISBN of the new edition is required")
+	} else if *fields.ISBN == isbnKey(source.ISBN) {
+		errs.add("fields.isbn", "ISBN must differ from the original edition")
+	}
+	if fields.WorkID != nil {
+		errs.add("fields.work_id", "The new edition always joins the original's work")
+	}
+	if len(in.AliasIDs) > 0 && s.aliases == nil {
+		errs.add("alias_ids", "Aliases are not supported")
+	} else if len(in.AliasIDs) > 0 {
+		aliases, err := s.aliases.ListByBook(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		ids := map[int64]bool{}
+		for _, a := range aliases {
+			ids[a.ID] = true
+		}
+		for i, aid := range in.AliasIDs {
+			if !ids[aid] {
+				errs.add(fmt.Sprintf("alias_ids[%d]", i), "Alias does not belong to this book")
+			}
+		}
+	}
+	if !errs.ok() {
+		return nil, errs
+	}
+
+	now := clock().UTC()
+	workID := source.ID
+	if source.WorkID != nil {
+		workID = *source.WorkID
+	}
+
+	edition := *source
+	edition.ID = 0
+	edition.Aliases = nil
+	edition.FieldUpdatedAt = nil
+	applyUpdate(&edition, fields)
+	edition.WorkID = &workID
+	edition.CreatedAt, edition.UpdatedAt, edition.Version = now, now, 1
+	edition.FieldUpdatedAt.Touch(now, domain.BookFields...)
+
+	prev := source.UpdatedAt
+	source.WorkID = &workID
+	source.UpdatedAt = now
+
+	editionID, err := s.writer.Split(ctx, source, prev, &edition, in.AliasIDs)
+	if err != nil {
+		return nil, unknownReference(err, "fields.")
+	}
+	// re-read so both carry their aliases after the move
+	res := &ports.SplitBookResult{}
+	for _, b := range []struct {
+		id  int64
+		dst *domain.Book
+	}{{source.ID, &res.Source}, {editionID, &res.Edition}} {
+		got, err := s.repo.GetByID(ctx, b.id)
+		if err != nil {
+			return nil, err
+		}
+		if got != nil {
+			*b.dst = *got
+		}
+	}
+	s.recordChange(ctx, source.ID, domain.ChangeUpdated, &res.Source)
+	s.recordChange(ctx, editionID, domain.ChangeCreated, &res.Edition)
+	return res, nil
+}
+
+// prefixFields nests field names of a validation error under prefix.
+func prefixFields(err error, prefix string) error {
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		return err
+	}
+	out := &ValidationError{}
+	for f, msg := range ve.Fields {
+		out.add(prefix+f, msg)
+	}
+	return out
+}
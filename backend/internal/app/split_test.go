@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+func newSplitFixture() (ports.BookService, *memBookRepo, *memChangeRepo) {
+	aliases := &memAliasRepo{aliases: []domain.Alias{
+		{ID: 1, BookID: 1, Alias: "Clean Code, 1st ed."},
+		{ID: 2, BookID: 1, Alias: "Clean Code (Polish ed.)"},
+		{ID: 3, BookID: 2, Alias: "Other book"},
+	}}
+	repo := newMemBookRepo(syncBook(), domain.Book{ID: 2, ISBN: "9780201633610"})
+	repo.aliases = aliases
+	changes := &memChangeRepo{}
+	svc := NewBookService(repo, WithChangeFeed(NewChangeFeed(changes)), WithAliases(aliases))
+	return svc, repo, changes
+}
+
+func TestSplitBook(t *testing.T) {
+	svc, repo, changes := newSplitFixture()
+
+	res, err := svc.SplitBook(context.Background(), 1, ports.SplitBookInput{
+		Fields:   ports.UpdateBookInput{ISBN: strptr("0-321-12521-5"), PublicationYear: iptr(2010)},
+		AliasIDs: []int64{2},
+	})
+	if err != nil {
+		t.Fatalf("SplitBook err: %v", err)
+	}
+
+	src, ed := res.Source, res.Edition
+	if src.ID != 1 || ed.ID == 0 || ed.ID == 1 {
+		t.Fatalf("ids: source=%d edition=%d", src.ID, ed.ID)
+	}
+	if src.WorkID == nil || ed.WorkID == nil || *src.WorkID != 1 || *ed.WorkID != 1 {
+		t.Fatalf("work ids: %v %v", src.WorkID, ed.WorkID)
+	}
+	if ed.Title != src.Title || ed.ISBN != "9780321125217" || ed.ISBN10 != "0321125215" || ed.PublicationYear != 2010 || src.PublicationYear != 2008 {
+		t.Fatalf("edition = %+v", ed)
+	}
+	if len(src.Aliases) != 1 || len(ed.Aliases) != 1 || ed.Aliases[0] != "Clean Code (Polish ed.)" {
+		t.Fatalf("aliases: source=%v edition=%v", src.Aliases, ed.Aliases)
+	}
+	if !repo.books[1].CreatedAt.Equal(syncBook().CreatedAt) {
+		t.Fatalf("source history rewritten: %+v", repo.books[1])
+	}
+	if len(changes.changes) != 2 || changes.changes[0].Op != domain.ChangeUpdated || changes.changes[1].Op != domain.ChangeCreated {
+		t.Fatalf("changes = %+v", changes.changes)
+	}
+
+	// splitting the new edition again keeps the original work
+	res, err = svc.SplitBook(context.Background(), ed.ID, ports.SplitBookInput{
+		Fields: ports.UpdateBookInput{ISBN: strptr("9780135398579")},
+	})
+	if err != nil || *res.Edition.WorkID != 1 {
+		t.Fatalf("second split: %+v, %v", res, err)
+	}
+}
+
+func TestSplitBook_Validation(t *testing.T) {
+	svc, _, _ := newSplitFixture()
+	cases := []struct {
+		in    ports.SplitBookInput
+		field string
+	}{
+		{ports.SplitBookInput{}, "fields.isbn"},
+		{ports.SplitBookInput{Fields: ports.UpdateBookInput{ISBN: strptr("978-0-13-235088-4")}}, "fields.isbn"},
+		{ports.SplitBookInput{Fields: ports.UpdateBookInput{ISBN: strptr("9780135398579"), Title: strptr(" ")}}, "fields.title"},
+		{ports.SplitBookInput{Fields: ports.UpdateBookInput{ISBN: strptr("9780135398579")}, AliasIDs: []int64{3}}, "alias_ids[0]"},
+	}
+	for _, c := range cases {
+		_, err := svc.SplitBook(context.Background(), 1, c.in)
+		ve, ok := err.(*ValidationError)
+		if !ok || ve.Fields[c.field] == "" {
+			t.Fatalf("%+v: err = %v; want error on %s", c.in, err, c.field)
+		}
+	}
+
+	if _, err := svc.SplitBook(context.Background(), 99, ports.SplitBookInput{}); err == nil || err.Error() != "book not found" {
+		t.Fatalf("err = %v; want book not found", err)
+	}
+}
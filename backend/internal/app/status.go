@@ -0,0 +1,74 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// DependencyCheck checks one dependency: Check returns nil when it is up.
+type DependencyCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// Status checks every dependency at once, each within timeout, and
+// remembers the last error of each.
+type Status struct {
+	checks  []DependencyCheck
+	timeout time.Duration
+	now     func() time.Time
+
+	mu   sync.Mutex
+	last map[string]lastFailure
+}
+
+type lastFailure struct {
+	err string
+	at  time.Time
+}
+
+func NewStatus(timeout time.Duration, checks ...DependencyCheck) *Status {
+	return &Status{checks: checks, timeout: timeout, now: clock, last: map[string]lastFailure{}}
+}
+
+func (s *Status) Status(ctx context.Context) domain.SystemStatus {
+	out := domain.SystemStatus{Status: "ok", CheckedAt: s.now().UTC(), Dependencies: make([]domain.DependencyStatus, len(s.checks))}
+	var wg sync.WaitGroup
+	for i, c := range s.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out.Dependencies[i] = s.check(ctx, c)
+		}()
+	}
+	wg.Wait()
+	for _, d := range out.Dependencies {
+		if d.Status != domain.DependencyUp {
+			out.Status = "degraded"
+		}
+	}
+	return out
+}
+
+func (s *Status) check(ctx context.Context, c DependencyCheck) domain.DependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	start := time.Now()
+	err := c.Check(ctx)
+	d := domain.DependencyStatus{Name: c.Name, Status: domain.DependencyUp,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		d.Status, d.Error = domain.DependencyDown, err.Error()
+		s.last[c.Name] = lastFailure{err: err.Error(), at: s.now().UTC()}
+	}
+	if f, ok := s.last[c.Name]; ok {
+		d.LastError, d.LastErrorAt = f.err, &f.at
+	}
+	return d
+}
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+func TestStatus(t *testing.T) {
+	var brokerErr error
+	s := NewStatus(50*time.Millisecond,
+		DependencyCheck{Name: "mysql", Check: func(ctx context.Context) error { return nil }},
+		DependencyCheck{Name: "broker", Check: func(ctx context.Context) error { return brokerErr }},
+		DependencyCheck{Name: "slow", Check: func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() }},
+	)
+	ctx := context.Background()
+
+	brokerErr = errors.New("connection refused")
+	got := s.Status(ctx)
+	if got.Status != "degraded" || len(got.Dependencies) != 3 {
+		t.Fatalf("Status = %+v", got)
+	}
+	mysql, broker, slow := got.Dependencies[0], got.Dependencies[1], got.Dependencies[2]
+	if mysql.Name != "mysql" || mysql.Status != domain.DependencyUp || mysql.LastError != "" {
+		t.Errorf("mysql = %+v", mysql)
+	}
+	if broker.Status != domain.DependencyDown || broker.Error != "connection refused" || broker.LastErrorAt == nil {
+		t.Errorf("broker = %+v", broker)
+	}
+	if slow.Status != domain.DependencyDown || slow.Error != context.DeadlineExceeded.Error() {
+		t.Errorf("slow = %+v; want it to time out", slow)
+	}
+
+	brokerErr = nil
+	broker = s.Status(ctx).Dependencies[1]
+	if broker.Status != domain.DependencyUp || broker.Error != "" || broker.LastError != "connection refused" {
+		t.Errorf("recovered broker = %+v; want up with the last error kept", broker)
+	}
+}
@@ -0,0 +1,149 @@
+package app
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+const (
+	// maxSuggestionTerms caps the titles and authors kept in the index.
+	maxSuggestionTerms = 50000
+	// maxSuggestions is how many suggestions a search gets.
+	maxSuggestions = 3
+	// minSuggestionSimilarity is the share of the query's trigrams a
+	// suggestion must contain.
+	minSuggestionSimilarity = 0.5
+)
+
+// Suggester answers "did you mean" from an in-memory trigram index of the
+// book titles and author names, rebuilt when it is older than its TTL.
+type Suggester struct {
+	src ports.SearchTermSource
+	ttl time.Duration
+
+	mu    sync.Mutex
+	index *termIndex
+	built time.Time
+}
+
+var _ ports.SuggestionService = (*Suggester)(nil)
+
+// NewSuggester reads the terms from src, and again once the index is older
+// than ttl.
+func NewSuggester(src ports.SearchTermSource, ttl time.Duration) *Suggester {
+	return &Suggester{src: src, ttl: ttl}
+}
+
+// Suggest returns up to three titles or authors holding most of the
+// trigrams of q, ignoring case and accents, best first and shorter ones
+// first on a tie.
+func (s *Suggester) Suggest(ctx context.Context, q string) ([]string, error) {
+	grams := trigrams(domain.SearchKey(q))
+	if len(grams) == 0 {
+		return []string{}, nil
+	}
+	idx, err := s.terms(ctx)
+	if err != nil {
+		return nil, err
+	}
+	shared := map[int]int{}
+	for g := range grams {
+		for _, i := range idx.byGram[g] {
+			shared[i]++
+		}
+	}
+	type match struct {
+		term  int
+		score float64
+	}
+	var matches []match
+	for i, n := range shared {
+		if score := float64(n) / float64(len(grams)); score >= minSuggestionSimilarity {
+			matches = append(matches, match{i, score})
+		}
+	}
+	sort.Slice(matches, func(a, b int) bool {
+		ta, tb := idx.terms[matches[a].term], idx.terms[matches[b].term]
+		switch {
+		case matches[a].score != matches[b].score:
+			return matches[a].score > matches[b].score
+		case len(ta.key) != len(tb.key):
+			return len(ta.key) < len(tb.key)
+		}
+		return ta.key < tb.key
+	})
+	out := []string{}
+	for _, m := range matches[:min(maxSuggestions, len(matches))] {
+		out = append(out, idx.terms[m.term].name)
+	}
+	return out, nil
+}
+
+// terms returns the index, rebuilding it when it is stale. A failed rebuild
+// keeps serving the old index if there is one.
+func (s *Suggester) terms(ctx context.Context) (*termIndex, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := clock()
+	if s.index != nil && now.Sub(s.built) < s.ttl {
+		return s.index, nil
+	}
+	names, err := s.src.SearchTerms(ctx, maxSuggestionTerms)
+	if err != nil {
+		if s.index != nil {
+			return s.index, nil
+		}
+		return nil, err
+	}
+	s.index, s.built = newTermIndex(names), now
+	return s.index, nil
+}
+
+type indexedTerm struct {
+	name string // as stored, for display
+	key  string // folded by SearchKey
+}
+
+// termIndex maps each trigram to the terms holding it.
+type termIndex struct {
+	terms  []indexedTerm
+	byGram map[string][]int
+}
+
+func newTermIndex(names []string) *termIndex {
+	idx := &termIndex{byGram: map[string][]int{}}
+	seen := map[string]bool{}
+	for _, name := range names {
+		key := domain.SearchKey(name)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		i := len(idx.terms)
+		idx.terms = append(idx.terms, indexedTerm{name: strings.TrimSpace(name), key: key})
+		for g := range trigrams(key) {
+			idx.byGram[g] = append(idx.byGram[g], i)
+		}
+	}
+	return idx
+}
+
+// trigrams returns the distinct three-rune sequences of the words of s, each
+// padded with two spaces in front and one behind, as PostgreSQL's pg_trgm
+// does, so word starts weigh more than their middles.
+func trigrams(s string) map[string]struct{} {
+	grams := map[string]struct{}{}
+	for _, w := range strings.Fields(s) {
+		r := []rune("  " + w + " ")
+		for i := 0; i+3 <= len(r); i++ {
+			grams[string(r[i:i+3])] = struct{}{}
+		}
+	}
+	return grams
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+type stubTermSource struct {
+	terms []string
+	err   error
+	reads int
+}
+
+func (s *stubTermSource) SearchTerms(ctx context.Context, limit int) ([]string, error) {
+	s.reads++
+	return s.terms, s.err
+}
+
+func TestSuggester(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return now })
+	defer SetClock(time.Now)
+	src := &stubTermSource{terms: []string{
+		"Harry Potter and the Chamber of Secrets", "J. K. Rowling", "J. R. R. Tolkien", "The Hobbit",
+		"Cien años de soledad", "Gabriel García Márquez", "Potter's Field", "the hobbit",
+	}}
+	s := NewSuggester(src, time.Minute)
+	ctx := context.Background()
+
+	for q, want := range map[string][]string{
+		"harry pottr":   {"Harry Potter and the Chamber of Secrets"},
+		"tolkein":       {"J. R. R. Tolkien"},
+		"HOBIT":         {"The Hobbit"},
+		"garcia marqes": {"Gabriel García Márquez"},
+		"potter":        {"Harry Potter and the Chamber of Secrets", "Potter's Field"}, // "potter's" lacks "er "
+		"zzzz":          {},
+		"  ":            {},
+	} {
+		got, err := s.Suggest(ctx, q)
+		if err != nil || !slices.Equal(got, want) {
+			t.Errorf("Suggest(%q) = %q, %v; want %q", q, got, err, want)
+		}
+	}
+	if src.reads != 1 {
+		t.Fatalf("terms read %d times within the TTL; want once", src.reads)
+	}
+
+	// a stale index is rebuilt, and kept when the rebuild fails
+	now = now.Add(2 * time.Minute)
+	src.terms = []string{"Dune"}
+	if got, _ := s.Suggest(ctx, "dun"); !slices.Equal(got, []string{"Dune"}) {
+		t.Fatalf("Suggest after the TTL = %q; want the new terms", got)
+	}
+	now = now.Add(2 * time.Minute)
+	src.err = errors.New("db down")
+	if got, err := s.Suggest(ctx, "dun"); err != nil || !slices.Equal(got, []string{"Dune"}) {
+		t.Fatalf("Suggest with the source down = %q, %v; want the old index", got, err)
+	}
+	if _, err := NewSuggester(src, time.Minute).Suggest(ctx, "dun"); err == nil {
+		t.Fatalf("Suggest without any index hid the source error")
+	}
+}
@@ -0,0 +1,376 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// syncService implements the offline-sync protocol for the mobile app on top
+// of the change log (pulls) and the book service (pushes), so pushed edits go
+// through the same validation and change recording as regular API calls.
+type syncService struct {
+	books   ports.BookService
+	changes ports.ChangeRepository
+	limits  ports.PageLimits
+}
+
+// NewSyncService pages initial syncs at most limits.Max books at a time.
+func NewSyncService(books ports.BookService, changes ports.ChangeRepository, limits ports.PageLimits) ports.SyncService {
+	return &syncService{books: books, changes: changes, limits: limits}
+}
+
+const maxSyncPush = 500
+
+// Pull returns rows created/updated and tombstones for rows deleted after
+// checkpoint. Checkpoint 0 is an initial sync: every book is returned, a
+// page at a time.
+func (s *syncService) Pull(ctx context.Context, checkpoint int64, snapshot *ports.SyncSnapshot, limit int) (*ports.SyncPullResponse, error) {
+	if checkpoint == 0 {
+		return s.pullSnapshot(ctx, snapshot, limit)
+	}
+	resp := &ports.SyncPullResponse{
+		Upserts:    []domain.Book{},
+		Tombstones: []ports.Tombstone{},
+		Checkpoint: checkpoint,
+	}
+
+	changes, err := s.changes.ListSince(ctx, checkpoint, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) > limit {
+		changes = changes[:limit]
+		resp.HasMore = true
+	}
+
+	// only the latest change per book matters
+	last := map[int64]domain.Change{}
+	var order []int64
+	for _, c := range changes {
+		if _, seen := last[c.BookID]; !seen {
+			order = append(order, c.BookID)
+		}
+		last[c.BookID] = c
+		resp.Checkpoint = c.ID
+	}
+
+	for _, id := range order {
+		c := last[id]
+		if c.Op == domain.ChangeDeleted {
+			resp.Tombstones = append(resp.Tombstones, ports.Tombstone{ID: id, DeletedAt: c.CreatedAt})
+			continue
+		}
+		// the payload is the book as of this cursor; entries written before
+		// payloads existed fall back to reading the current row
+		b, _ := c.Book()
+		if b == nil {
+			var err error
+			if b, err = s.books.GetBook(ctx, id); err != nil {
+				return nil, err
+			}
+		}
+		if b == nil {
+			continue // deleted later; the tombstone comes with a following page
+		}
+		if b.Aliases == nil {
+			b.Aliases = []string{}
+		}
+		resp.Upserts = append(resp.Upserts, *b)
+	}
+	return resp, nil
+}
+
+// pullSnapshot returns the page of the initial sync after snap, books in
+// id order. The first page reads the change log cursor before any book:
+// anything written while paging is re-sent by the pulls after the last
+// page. Until then the checkpoint stays 0, so a client that gives up
+// starts over rather than skipping the books it never got.
+func (s *syncService) pullSnapshot(ctx context.Context, snap *ports.SyncSnapshot, limit int) (*ports.SyncPullResponse, error) {
+	if snap == nil {
+		latest, err := s.changes.LatestID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		snap = &ports.SyncSnapshot{Checkpoint: latest}
+	}
+	if s.limits.Max > 0 {
+		limit = min(limit, s.limits.Max)
+	}
+	page, err := s.books.ListBooksPage(ctx, ports.ListFilter{AfterID: snap.AfterID},
+		ports.Page{Limit: limit, Sort: ports.Sort{Field: "id"}})
+	if err != nil {
+		return nil, err
+	}
+	resp := &ports.SyncPullResponse{Upserts: page.Books, Tombstones: []ports.Tombstone{}}
+	if resp.Upserts == nil {
+		resp.Upserts = []domain.Book{}
+	}
+	for i := range resp.Upserts {
+		if resp.Upserts[i].Aliases == nil {
+			resp.Upserts[i].Aliases = []string{}
+		}
+	}
+	if n := len(page.Books); n > 0 && n < page.Total {
+		resp.HasMore = true
+		resp.Snapshot = ports.SyncSnapshot{Checkpoint: snap.Checkpoint, AfterID: page.Books[n-1].ID}.String()
+	} else {
+		resp.Checkpoint = snap.Checkpoint
+	}
+	return resp, nil
+}
+
+func (s *syncService) Push(ctx context.Context, in ports.SyncPushRequest) (*ports.SyncPushResponse, error) {
+	if err := validateSyncPush(in); err != nil {
+		return nil, err
+	}
+	if in.Strategy == "" {
+		in.Strategy = ports.SyncMerge
+	}
+
+	resp := &ports.SyncPushResponse{Results: make([]ports.SyncResult, 0, len(in.Changes))}
+	for _, c := range in.Changes {
+		var res ports.SyncResult
+		var err error
+		switch c.Op {
+		case ports.SyncOpCreate:
+			res, err = s.pushCreate(ctx, c)
+		case ports.SyncOpUpdate:
+			res, err = s.pushUpdate(ctx, in, c)
+		case ports.SyncOpDelete:
+			res, err = s.pushDelete(ctx, in, c)
+		}
+		if err != nil {
+			return nil, err
+		}
+		resp.Results = append(resp.Results, res)
+	}
+	return resp, nil
+}
+
+func (s *syncService) pushCreate(ctx context.Context, c ports.SyncChange) (ports.SyncResult, error) {
+	res := ports.SyncResult{ClientID: c.ClientID}
+	f := c.Fields
+	in := ports.CreateBookInput{}
+	if f.Title != nil {
+		in.Title = *f.Title
+	}
+	if f.Author != nil {
+		in.Author = *f.Author
+	}
+	if f.ISBN != nil {
+		in.ISBN = *f.ISBN
+	}
+	if f.Price != nil {
+		in.Price = *f.Price
+	}
+	if f.PublicationYear != nil {
+		in.PublicationYear = *f.PublicationYear
+	}
+	b, err := s.books.CreateBook(ctx, in)
+	if err != nil {
+		return rejected(res, err), nil
+	}
+	res.ID, res.Status, res.Book = b.ID, "created", b
+	return res, nil
+}
+
+// pushUpdate merges c into the book and writes the result at the version
+// it merged against. When another write lands in between, the merge is
+// redone on top of it; one that keeps losing the race is a conflict.
+func (s *syncService) pushUpdate(ctx context.Context, req ports.SyncPushRequest, c ports.SyncChange) (ports.SyncResult, error) {
+	res := ports.SyncResult{ClientID: c.ClientID, ID: c.ID}
+	for attempt := 1; ; attempt++ {
+		server, err := s.books.GetBook(ctx, c.ID)
+		if err != nil {
+			return res, err
+		}
+		if server == nil {
+			res.Status = "not_found"
+			return res, nil
+		}
+
+		m := &syncMerge{strategy: req.Strategy, fieldStrategies: req.FieldStrategies}
+		upd := ports.UpdateBookInput{
+			Title:           mergeField(m, "title", c.Fields.Title, c.Base.Title, server.Title, nil),
+			Author:          mergeField(m, "author", c.Fields.Author, c.Base.Author, server.Author, nil),
+			ISBN:            mergeField(m, "isbn", c.Fields.ISBN, c.Base.ISBN, server.ISBN, isbnKey),
+			Price:           mergeField(m, "price", c.Fields.Price, c.Base.Price, server.Price, nil),
+			PublicationYear: mergeField(m, "publication_year", c.Fields.PublicationYear, c.Base.PublicationYear, server.PublicationYear, nil),
+			Version:         &server.Version,
+		}
+		res.Conflicts = m.conflicts
+
+		if req.Strategy == ports.SyncServerWins && len(m.conflicts) > 0 {
+			res.Status, res.Book = "conflict", server
+			return res, nil
+		}
+		if !m.changed {
+			res.Status, res.Book = statusFor(m), server
+			return res, nil
+		}
+
+		b, err := s.books.UpdateBook(ctx, c.ID, upd)
+		var conflict *ConflictError
+		if errors.As(err, &conflict) {
+			if attempt < maxUpdateAttempts {
+				continue
+			}
+			res.Status, res.Book = "conflict", server
+			return res, nil
+		}
+		if err != nil {
+			if err.Error() == "book not found" {
+				res.Status = "not_found"
+				return res, nil
+			}
+			return rejected(res, err), nil
+		}
+		res.Status, res.Book = statusFor(m), b
+		return res, nil
+	}
+}
+
+func (s *syncService) pushDelete(ctx context.Context, req ports.SyncPushRequest, c ports.SyncChange) (ports.SyncResult, error) {
+	res := ports.SyncResult{ClientID: c.ClientID, ID: c.ID}
+	server, err := s.books.GetBook(ctx, c.ID)
+	if err != nil {
+		return res, err
+	}
+	if server == nil {
+		res.Status = "deleted" // already gone, nothing to do
+		return res, nil
+	}
+	// a server edit after the client's snapshot wins unless the client does
+	editedSince := c.BaseUpdatedAt == nil || server.UpdatedAt.After(*c.BaseUpdatedAt)
+	if editedSince && req.Strategy != ports.SyncClientWins {
+		res.Status, res.Book = "conflict", server
+		return res, nil
+	}
+	if err := s.books.DeleteBook(ctx, c.ID); err != nil {
+		return rejected(res, err), nil
+	}
+	res.Status = "deleted"
+	return res, nil
+}
+
+// ---- three-way merge ----
+
+type syncMerge struct {
+	strategy        string
+	fieldStrategies map[string]string
+	conflicts       []ports.FieldConflict
+	changed         bool
+}
+
+func (m *syncMerge) resolution(field string) string {
+	if m.strategy != ports.SyncMerge {
+		return m.strategy
+	}
+	if fs, ok := m.fieldStrategies[field]; ok {
+		return fs
+	}
+	return ports.SyncServerWins
+}
+
+// mergeField returns the value to write for one field, or nil to leave the
+// server value untouched. base is what the client last saw; a nil base means
+// the client never saw the field, so any server difference counts as a conflict.
+func mergeField[T comparable](m *syncMerge, field string, client, base *T, server T, norm func(T) T) *T {
+	if client == nil {
+		return nil
+	}
+	cv := *client
+	if norm != nil {
+		cv, server = norm(cv), norm(server)
+	}
+	if cv == server {
+		return nil
+	}
+	serverUnchanged := false
+	if base != nil {
+		bv := *base
+		if norm != nil {
+			bv = norm(bv)
+		}
+		if bv == cv {
+			return nil // not edited offline; keep whatever the server has
+		}
+		serverUnchanged = bv == server
+	}
+	if serverUnchanged || m.strategy == ports.SyncClientWins {
+		if !serverUnchanged {
+			m.conflicts = append(m.conflicts, ports.FieldConflict{Field: field, Server: server, Client: cv, Resolution: ports.SyncClientWins})
+		}
+		m.changed = true
+		return client
+	}
+
+	res := m.resolution(field)
+	m.conflicts = append(m.conflicts, ports.FieldConflict{Field: field, Server: server, Client: cv, Resolution: res})
+	if res == ports.SyncClientWins {
+		m.changed = true
+		return client
+	}
+	return nil
+}
+
+func statusFor(m *syncMerge) string {
+	if len(m.conflicts) > 0 {
+		return "merged"
+	}
+	return "applied"
+}
+
+func rejected(res ports.SyncResult, err error) ports.SyncResult {
+	res.Status, res.Error = "rejected", err.Error()
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		res.Fields = ve.Fields
+	}
+	return res
+}
+
+func validateSyncPush(in ports.SyncPushRequest) error {
+	errs := &ValidationError{}
+	if !isSyncStrategy(in.Strategy, true) {
+		errs.add("strategy", "Strategy must be merge, server-wins or client-wins")
+	}
+	for field, fs := range in.FieldStrategies {
+		if !isSyncStrategy(fs, false) {
+			errs.add("field_strategies."+field, "Field strategy must be server-wins or client-wins")
+		}
+	}
+	if len(in.Changes) > maxSyncPush {
+		errs.add("changes", fmt.Sprintf("At most %d changes per push", maxSyncPush))
+	}
+	for i, c := range in.Changes {
+		key := fmt.Sprintf("changes[%d]", i)
+		switch c.Op {
+		case ports.SyncOpCreate:
+		case ports.SyncOpUpdate, ports.SyncOpDelete:
+			if c.ID <= 0 {
+				errs.add(key+".id", "ID is required")
+			}
+		default:
+			errs.add(key+".op", "Op must be create, update or delete")
+		}
+	}
+	if !errs.ok() {
+		return errs
+	}
+	return nil
+}
+
+func isSyncStrategy(s string, allowMerge bool) bool {
+	switch s {
+	case ports.SyncServerWins, ports.SyncClientWins:
+		return true
+	case "", ports.SyncMerge:
+		return allowMerge
+	}
+	return false
+}
@@ -0,0 +1,395 @@
+package app
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// ---- In-memory ports.BookRepository ----
+
+type memBookRepo struct {
+	books   map[int64]domain.Book
+	nextID  int64
+	aliases *memAliasRepo // optional, attached on GetByID and moved by Split
+}
+
+func newMemBookRepo(books ...domain.Book) *memBookRepo {
+	m := &memBookRepo{books: map[int64]domain.Book{}}
+	for _, b := range books {
+		m.books[b.ID] = b
+		m.nextID = max(m.nextID, b.ID)
+	}
+	return m
+}
+
+func (m *memBookRepo) List(ctx context.Context, f ports.ListFilter) ([]domain.Book, error) {
+	out := []domain.Book{}
+	for _, b := range m.books {
+		if f.Matches(b) {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+func (m *memBookRepo) Search(ctx context.Context, q string) ([]domain.Book, error) {
+	return m.List(ctx, ports.ListFilter{Q: q})
+}
+func (m *memBookRepo) ListPage(ctx context.Context, f ports.ListFilter, page ports.Page) ([]domain.Book, int, error) {
+	all, _ := m.List(ctx, f)
+	slices.SortFunc(all, func(a, b domain.Book) int { return cmp.Compare(b.ID, a.ID) })
+	if page.Sort.Field == "id" && !page.Sort.Desc {
+		slices.Reverse(all)
+	}
+	end := len(all)
+	if page.Limit > 0 {
+		end = min(end, page.Offset+page.Limit)
+	}
+	return all[min(page.Offset, end):end], len(all), nil
+}
+func (m *memBookRepo) GetByID(ctx context.Context, id int64) (*domain.Book, error) {
+	b, ok := m.books[id]
+	if !ok {
+		return nil, nil
+	}
+	if m.aliases != nil {
+		b.Aliases = []string{}
+		as, _ := m.aliases.ListByBook(ctx, id)
+		for _, a := range as {
+			b.Aliases = append(b.Aliases, a.Alias)
+		}
+	}
+	return &b, nil
+}
+func (m *memBookRepo) Create(ctx context.Context, b *domain.Book) (int64, error) {
+	m.nextID++
+	b.ID = m.nextID
+	m.books[b.ID] = *b
+	return b.ID, nil
+}
+func (m *memBookRepo) Update(ctx context.Context, b *domain.Book, prev time.Time) error {
+	cur, ok := m.books[b.ID]
+	if !ok || !cur.UpdatedAt.Equal(prev) {
+		return ports.ErrConcurrentUpdate
+	}
+	b.Version = cur.Version + 1
+	m.books[b.ID] = *b
+	return nil
+}
+func (m *memBookRepo) Split(ctx context.Context, src *domain.Book, prev time.Time, ed *domain.Book, aliasIDs []int64) (int64, error) {
+	if err := m.Update(ctx, src, prev); err != nil {
+		return 0, err
+	}
+	id, _ := m.Create(ctx, ed)
+	if m.aliases != nil {
+		for i, a := range m.aliases.aliases {
+			if a.BookID == src.ID && slices.Contains(aliasIDs, a.ID) {
+				m.aliases.aliases[i].BookID = id
+			}
+		}
+	}
+	return id, nil
+}
+func (m *memBookRepo) Delete(ctx context.Context, id int64) error {
+	delete(m.books, id)
+	return nil
+}
+
+func newSyncFixture(books ...domain.Book) (ports.SyncService, *memBookRepo, *memChangeRepo) {
+	repo := newMemBookRepo(books...)
+	changes := &memChangeRepo{}
+	svc := NewBookService(repo, WithChangeFeed(NewChangeFeed(changes)))
+	return NewSyncService(svc, changes, ports.PageLimits{Max: 2}), repo, changes
+}
+
+func syncBook() domain.Book {
+	return domain.Book{
+		ID: 1, Title: "Clean Code", Author: "Robert C. Martin", ISBN: "9780132350884",
+		PublicationYear: 2008, Price: 30, UpdatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+// ---- Pull ----
+
+func TestSyncPull_Initial(t *testing.T) {
+	sync, _, changes := newSyncFixture(syncBook())
+	changes.changes = append(changes.changes, domain.Change{ID: 1, BookID: 1, Op: domain.ChangeCreated})
+
+	got, err := sync.Pull(context.Background(), 0, nil, 100)
+	if err != nil {
+		t.Fatalf("Pull err: %v", err)
+	}
+	if len(got.Upserts) != 1 || got.Checkpoint != 1 || got.HasMore {
+		t.Fatalf("unexpected: %+v", got)
+	}
+}
+
+func TestSyncPull_PagesSnapshot(t *testing.T) {
+	var books []domain.Book
+	for id := int64(1); id <= 5; id++ {
+		b := syncBook()
+		b.ID, b.ISBN = id, ""
+		books = append(books, b)
+	}
+	sync, repo, changes := newSyncFixture(books...)
+	changes.changes = []domain.Change{{ID: 1, BookID: 5, Op: domain.ChangeCreated}}
+
+	var got []int64
+	var snap *ports.SyncSnapshot
+	for page := 1; ; page++ {
+		resp, err := sync.Pull(context.Background(), 0, snap, 100)
+		if err != nil {
+			t.Fatalf("Pull page %d: %v", page, err)
+		}
+		if len(resp.Upserts) > 2 {
+			t.Fatalf("page %d has %d books; limit is capped at 2", page, len(resp.Upserts))
+		}
+		for _, b := range resp.Upserts {
+			got = append(got, b.ID)
+		}
+		if !resp.HasMore {
+			if resp.Checkpoint != 1 || resp.Snapshot != "" {
+				t.Fatalf("last page: %+v", resp)
+			}
+			break
+		}
+		if resp.Checkpoint != 0 {
+			t.Fatalf("page %d: checkpoint %d before the last page", page, resp.Checkpoint)
+		}
+		next, err := ports.ParseSyncSnapshot(resp.Snapshot)
+		if err != nil {
+			t.Fatalf("page %d snapshot %q: %v", page, resp.Snapshot, err)
+		}
+		snap = &next
+		if page == 1 {
+			delete(repo.books, 3) // deleted mid-snapshot: nothing after it is skipped
+		}
+	}
+	if !slices.Equal(got, []int64{1, 2, 4, 5}) {
+		t.Fatalf("snapshot = %v", got)
+	}
+}
+
+func TestSyncPull_UpsertsAndTombstones(t *testing.T) {
+	b := syncBook()
+	sync, _, changes := newSyncFixture(b)
+	deletedAt := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	changes.changes = []domain.Change{
+		{ID: 1, BookID: 1, Op: domain.ChangeCreated},
+		{ID: 2, BookID: 2, Op: domain.ChangeCreated},
+		{ID: 3, BookID: 1, Op: domain.ChangeUpdated},
+		{ID: 4, BookID: 2, Op: domain.ChangeDeleted, CreatedAt: deletedAt},
+		{ID: 5, BookID: 3, Op: domain.ChangeCreated}, // beyond the limit
+	}
+
+	got, err := sync.Pull(context.Background(), 1, nil, 3)
+	if err != nil {
+		t.Fatalf("Pull err: %v", err)
+	}
+	if !got.HasMore || got.Checkpoint != 4 {
+		t.Fatalf("checkpoint=%d has_more=%v", got.Checkpoint, got.HasMore)
+	}
+	if len(got.Upserts) != 1 || got.Upserts[0].ID != 1 {
+		t.Fatalf("upserts = %+v", got.Upserts)
+	}
+	if len(got.Tombstones) != 1 || got.Tombstones[0].ID != 2 || !got.Tombstones[0].DeletedAt.Equal(deletedAt) {
+		t.Fatalf("tombstones = %+v", got.Tombstones)
+	}
+}
+
+func TestSyncPull_UsesChangePayload(t *testing.T) {
+	sync, repo, changes := newSyncFixture(syncBook())
+	payload, _ := domain.NewChangePayload(domain.Book{ID: 1, Title: "As of cursor 2"})
+	changes.changes = []domain.Change{
+		{ID: 1, BookID: 1, Op: domain.ChangeCreated},
+		{ID: 2, BookID: 1, Op: domain.ChangeUpdated, Payload: payload},
+	}
+	delete(repo.books, 1) // the payload alone must be enough
+
+	got, err := sync.Pull(context.Background(), 1, nil, 10)
+	if err != nil {
+		t.Fatalf("Pull err: %v", err)
+	}
+	if len(got.Upserts) != 1 || got.Upserts[0].Title != "As of cursor 2" || got.Upserts[0].Aliases == nil {
+		t.Fatalf("upserts = %+v", got.Upserts)
+	}
+}
+
+// ---- Push ----
+
+func TestSyncPush_MergesDisjointEdits(t *testing.T) {
+	server := syncBook()
+	server.Title = "Clean Code (2nd)" // edited on the server meanwhile
+	sync, repo, _ := newSyncFixture(server)
+
+	res, err := sync.Push(context.Background(), ports.SyncPushRequest{
+		Changes: []ports.SyncChange{{
+			Op:     ports.SyncOpUpdate,
+			ID:     1,
+			Base:   ports.UpdateBookInput{Title: strptr("Clean Code"), Price: f64ptr(30)},
+			Fields: ports.UpdateBookInput{Title: strptr("Clean Code"), Price: f64ptr(25)},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Push err: %v", err)
+	}
+	r := res.Results[0]
+	if r.Status != "applied" || len(r.Conflicts) != 0 {
+		t.Fatalf("result = %+v", r)
+	}
+	if got := repo.books[1]; got.Title != "Clean Code (2nd)" || got.Price != 25 {
+		t.Fatalf("stored = %+v", got)
+	}
+}
+
+func TestSyncPush_RemergesAfterConcurrentWrite(t *testing.T) {
+	repo := newMemBookRepo(syncBook())
+	changes := &memChangeRepo{}
+	books := NewBookService(&racingRepo{memBookRepo: repo, race: func() {
+		// another writer reprices the book between the merge and the write
+		b := repo.books[1]
+		b.Price, b.Version, b.UpdatedAt = 40, b.Version+1, b.UpdatedAt.Add(time.Second)
+		repo.books[1] = b
+	}}, WithChangeFeed(NewChangeFeed(changes)))
+	sync := NewSyncService(books, changes, ports.PageLimits{})
+
+	res, err := sync.Push(context.Background(), ports.SyncPushRequest{
+		Changes: []ports.SyncChange{{
+			Op:     ports.SyncOpUpdate,
+			ID:     1,
+			Base:   ports.UpdateBookInput{Price: f64ptr(30)},
+			Fields: ports.UpdateBookInput{Price: f64ptr(25)},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Push err: %v", err)
+	}
+	// merged again against 40, the edits collide instead of 25 silently
+	// overwriting the reprice
+	r := res.Results[0]
+	if len(r.Conflicts) != 1 || r.Conflicts[0].Field != "price" {
+		t.Fatalf("result = %+v", r)
+	}
+}
+
+func TestSyncPush_FieldConflictStrategies(t *testing.T) {
+	cases := []struct {
+		name       string
+		req        ports.SyncPushRequest
+		wantStatus string
+		wantTitle  string
+		wantPrice  float64
+	}{
+		{"merge defaults to server for conflicts", ports.SyncPushRequest{}, "merged", "Server Title", 25},
+		{"merge with client-wins override", ports.SyncPushRequest{FieldStrategies: map[string]string{"title": ports.SyncClientWins}}, "merged", "Client Title", 25},
+		{"server-wins rejects the whole change", ports.SyncPushRequest{Strategy: ports.SyncServerWins}, "conflict", "Server Title", 30},
+		{"client-wins overwrites", ports.SyncPushRequest{Strategy: ports.SyncClientWins}, "merged", "Client Title", 25},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := syncBook()
+			server.Title = "Server Title"
+			sync, repo, _ := newSyncFixture(server)
+
+			c.req.Changes = []ports.SyncChange{{
+				Op:     ports.SyncOpUpdate,
+				ID:     1,
+				Base:   ports.UpdateBookInput{Title: strptr("Clean Code"), Price: f64ptr(30)},
+				Fields: ports.UpdateBookInput{Title: strptr("Client Title"), Price: f64ptr(25)},
+			}}
+			res, err := sync.Push(context.Background(), c.req)
+			if err != nil {
+				t.Fatalf("Push err: %v", err)
+			}
+			r := res.Results[0]
+			if r.Status != c.wantStatus {
+				t.Fatalf("status = %s; want %s (%+v)", r.Status, c.wantStatus, r)
+			}
+			if len(r.Conflicts) != 1 || r.Conflicts[0].Field != "title" {
+				t.Fatalf("conflicts = %+v", r.Conflicts)
+			}
+			if got := repo.books[1]; got.Title != c.wantTitle || got.Price != c.wantPrice {
+				t.Fatalf("stored = %+v", got)
+			}
+		})
+	}
+}
+
+func TestSyncPush_ISBNComparedNormalized(t *testing.T) {
+	sync, _, _ := newSyncFixture(syncBook())
+
+	res, err := sync.Push(context.Background(), ports.SyncPushRequest{
+		Changes: []ports.SyncChange{{
+			Op:     ports.SyncOpUpdate,
+			ID:     1,
+			Fields: ports.UpdateBookInput{ISBN: strptr("978-0-13-235088-4")},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Push err: %v", err)
+	}
+	if r := res.Results[0]; r.Status != "applied" || len(r.Conflicts) != 0 {
+		t.Fatalf("result = %+v", r)
+	}
+}
+
+func TestSyncPush_CreateAndDelete(t *testing.T) {
+	sync, repo, _ := newSyncFixture(syncBook())
+	stale := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	fresh := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	res, err := sync.Push(context.Background(), ports.SyncPushRequest{
+		Changes: []ports.SyncChange{
+			{Op: ports.SyncOpCreate, ClientID: "tmp-1", Fields: ports.UpdateBookInput{
+				Title: strptr("DDD"), Author: strptr("Eric Evans"), ISBN: strptr("9780321125217"),
+				PublicationYear: iptr(2003), Price: f64ptr(49.99),
+			}},
+			{Op: ports.SyncOpCreate, ClientID: "tmp-2", Fields: ports.UpdateBookInput{Title: strptr("")}},
+			{Op: ports.SyncOpDelete, ID: 1, BaseUpdatedAt: &stale},
+			{Op: ports.SyncOpDelete, ID: 1, BaseUpdatedAt: &fresh},
+			{Op: ports.SyncOpUpdate, ID: 1, Fields: ports.UpdateBookInput{Title: strptr("gone")}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Push err: %v", err)
+	}
+	want := []string{"created", "rejected", "conflict", "deleted", "not_found"}
+	for i, r := range res.Results {
+		if r.Status != want[i] {
+			t.Fatalf("result %d = %+v; want status %s", i, r, want[i])
+		}
+	}
+	if res.Results[0].ClientID != "tmp-1" || res.Results[0].ID == 0 {
+		t.Fatalf("create result = %+v", res.Results[0])
+	}
+	if res.Results[1].Fields["title"] == "" {
+		t.Fatalf("rejected create should carry field errors: %+v", res.Results[1])
+	}
+	if _, ok := repo.books[1]; ok {
+		t.Fatalf("book 1 should be deleted")
+	}
+}
+
+func TestSyncPush_Validation(t *testing.T) {
+	sync, _, _ := newSyncFixture()
+
+	_, err := sync.Push(context.Background(), ports.SyncPushRequest{
+		Strategy:        "whatever",
+		FieldStrategies: map[string]string{"price": ports.SyncMerge},
+		Changes:         []ports.SyncChange{{Op: "upsert"}, {Op: ports.SyncOpUpdate}},
+	})
+	ve, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("want *ValidationError, got %T (%v)", err, err)
+	}
+	for _, f := range []string{"strategy", "field_strategies.price", "changes[0].op", "changes[1].id"} {
+		if ve.Fields[f] == "" {
+			t.Fatalf("missing %s error: %+v", f, ve.Fields)
+		}
+	}
+}
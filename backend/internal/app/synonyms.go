@@ -0,0 +1,240 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// maxSynonymExpansions caps the searches one query expands into, so a
+// query made of many dictionary terms doesn't multiply into a huge WHERE.
+const maxSynonymExpansions = 16
+
+// Synonyms stores the synonym dictionary and expands searches with it. The
+// whole dictionary is kept in memory and read again once it is older than
+// its TTL; a change drops it at once on this replica, other replicas see
+// the change once theirs expires.
+type Synonyms struct {
+	repo ports.SynonymRepository
+	ttl  time.Duration
+
+	mu     sync.Mutex
+	groups [][]string // terms of each group, folded; nil until read
+	read   time.Time
+}
+
+var _ ports.SynonymService = (*Synonyms)(nil)
+
+func NewSynonyms(repo ports.SynonymRepository, ttl time.Duration) *Synonyms {
+	return &Synonyms{repo: repo, ttl: ttl}
+}
+
+func (s *Synonyms) ListSynonyms(ctx context.Context) ([]domain.SynonymGroup, error) {
+	groups, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if groups == nil {
+		groups = []domain.SynonymGroup{}
+	}
+	return groups, nil
+}
+
+func (s *Synonyms) GetSynonyms(ctx context.Context, id int64) (*domain.SynonymGroup, error) {
+	g, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if g == nil {
+		return nil, errors.New("synonym group not found")
+	}
+	return g, nil
+}
+
+func (s *Synonyms) CreateSynonyms(ctx context.Context, in ports.SynonymInput) (*domain.SynonymGroup, error) {
+	terms, err := validateSynonyms(in)
+	if err != nil {
+		return nil, err
+	}
+	now := clock().UTC()
+	g := &domain.SynonymGroup{Terms: terms, CreatedAt: now, UpdatedAt: now}
+	id, err := s.repo.Create(ctx, g)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	g.ID = id
+	return g, nil
+}
+
+// UpdateSynonyms replaces the terms of group id.
+func (s *Synonyms) UpdateSynonyms(ctx context.Context, id int64, in ports.SynonymInput) (*domain.SynonymGroup, error) {
+	terms, err := validateSynonyms(in)
+	if err != nil {
+		return nil, err
+	}
+	g, err := s.GetSynonyms(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	g.Terms, g.UpdatedAt = terms, clock().UTC()
+	found, err := s.repo.Update(ctx, g)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	if !found {
+		return nil, errors.New("synonym group not found")
+	}
+	return g, nil
+}
+
+func (s *Synonyms) DeleteSynonyms(ctx context.Context, id int64) error {
+	found, err := s.repo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	s.invalidate()
+	if !found {
+		return errors.New("synonym group not found")
+	}
+	return nil
+}
+
+// Expand returns q, folded, with every dictionary term among its words
+// swapped for the other terms of its group, in every combination up to
+// maxSynonymExpansions: with programming ↔ coding and C# ↔ csharp,
+// "C# Programming" expands to "csharp programming", "c# coding" and
+// "csharp coding".
+func (s *Synonyms) Expand(ctx context.Context, q string) ([]string, error) {
+	key := domain.SearchKey(q)
+	if key == "" {
+		return nil, nil
+	}
+	groups, err := s.dictionary(ctx)
+	if err != nil {
+		return nil, err
+	}
+	searches := []string{key}
+	for _, terms := range groups {
+		for _, t := range terms {
+			if !hasWords(key, t) {
+				continue
+			}
+			for _, v := range searches {
+				for _, other := range terms {
+					if len(searches) == maxSynonymExpansions+1 {
+						break
+					}
+					if alt := replaceWords(v, t, other); !slices.Contains(searches, alt) {
+						searches = append(searches, alt)
+					}
+				}
+			}
+		}
+	}
+	if len(searches) == 1 {
+		return nil, nil
+	}
+	return searches[1:], nil
+}
+
+// dictionary returns the folded terms of every group, reading them again
+// when they are older than the TTL. A failed read keeps serving the old
+// ones if there are any.
+func (s *Synonyms) dictionary(ctx context.Context) ([][]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := clock()
+	if s.groups != nil && now.Sub(s.read) < s.ttl {
+		return s.groups, nil
+	}
+	list, err := s.repo.List(ctx)
+	if err != nil {
+		if s.groups != nil {
+			logger.From(ctx).Warn("failed to read synonyms, using the old ones", "error", err)
+			return s.groups, nil
+		}
+		return nil, err
+	}
+	groups := make([][]string, 0, len(list))
+	for _, g := range list {
+		terms := make([]string, len(g.Terms))
+		for i, t := range g.Terms {
+			terms[i] = domain.SearchKey(t)
+		}
+		groups = append(groups, terms)
+	}
+	s.groups, s.read = groups, now
+	return groups, nil
+}
+
+// invalidate drops the dictionary so the next search reads the change.
+func (s *Synonyms) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.groups = nil
+}
+
+// hasWords reports whether the words of term appear together in key.
+func hasWords(key, term string) bool {
+	return strings.Contains(" "+key+" ", " "+term+" ")
+}
+
+// replaceWords replaces each run of words of key spelling term with with.
+func replaceWords(key, term, with string) string {
+	words, find := strings.Fields(key), strings.Fields(term)
+	var out []string
+	for i := 0; i < len(words); {
+		if i+len(find) <= len(words) && slices.Equal(words[i:i+len(find)], find) {
+			out = append(out, with)
+			i += len(find)
+			continue
+		}
+		out = append(out, words[i])
+		i++
+	}
+	return strings.Join(out, " ")
+}
+
+// validateSynonyms checks the terms of a group and returns them with their
+// spaces collapsed.
+func validateSynonyms(in ports.SynonymInput) ([]string, error) {
+	errs := &ValidationError{}
+	switch {
+	case len(in.Terms) < 2:
+		errs.add("terms", "A synonym group needs at least 2 terms")
+	case len(in.Terms) > domain.MaxSynonymTerms:
+		errs.add("terms", fmt.Sprintf("A synonym group has at most %d terms", domain.MaxSynonymTerms))
+	}
+	terms := make([]string, 0, len(in.Terms))
+	keys := map[string]bool{}
+	for _, t := range in.Terms {
+		t = strings.Join(strings.Fields(t), " ")
+		key := domain.SearchKey(t)
+		switch {
+		case !strings.ContainsFunc(t, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }):
+			errs.add("terms", "Each term needs a letter or digit")
+		case utf8.RuneCountInString(t) > domain.MaxSynonymTermLen:
+			errs.add("terms", fmt.Sprintf("Terms must be ≤ %d characters", domain.MaxSynonymTermLen))
+		case keys[key]:
+			errs.add("terms", fmt.Sprintf("%q is listed twice, ignoring case and accents", t))
+		}
+		keys[key] = true
+		terms = append(terms, t)
+	}
+	if !errs.ok() {
+		return nil, errs
+	}
+	return terms, nil
+}
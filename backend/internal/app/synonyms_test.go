@@ -0,0 +1,250 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// ---- In-memory ports.SynonymRepository ----
+
+type memSynonymRepo struct {
+	mu     sync.Mutex
+	groups []domain.SynonymGroup
+	nextID int64
+	lists  int
+	err    error
+}
+
+func (m *memSynonymRepo) List(ctx context.Context) ([]domain.SynonymGroup, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lists++
+	if m.err != nil {
+		return nil, m.err
+	}
+	return slices.Clone(m.groups), nil
+}
+func (m *memSynonymRepo) GetByID(ctx context.Context, id int64) (*domain.SynonymGroup, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, g := range m.groups {
+		if g.ID == id {
+			return &g, nil
+		}
+	}
+	return nil, nil
+}
+func (m *memSynonymRepo) Create(ctx context.Context, g *domain.SynonymGroup) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.taken(0, g.Terms) {
+		return 0, ports.ErrSynonymTaken
+	}
+	m.nextID++
+	c := *g
+	c.ID = m.nextID
+	m.groups = append(m.groups, c)
+	return c.ID, nil
+}
+func (m *memSynonymRepo) Update(ctx context.Context, g *domain.SynonymGroup) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.taken(g.ID, g.Terms) {
+		return false, ports.ErrSynonymTaken
+	}
+	for i := range m.groups {
+		if m.groups[i].ID == g.ID {
+			m.groups[i] = *g
+			return true, nil
+		}
+	}
+	return false, nil
+}
+func (m *memSynonymRepo) Delete(ctx context.Context, id int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := len(m.groups)
+	m.groups = slices.DeleteFunc(m.groups, func(g domain.SynonymGroup) bool { return g.ID == id })
+	return len(m.groups) < n, nil
+}
+
+// taken reports whether a group other than id has one of terms.
+func (m *memSynonymRepo) taken(id int64, terms []string) bool {
+	for _, g := range m.groups {
+		for _, t := range g.Terms {
+			if g.ID != id && slices.ContainsFunc(terms, func(u string) bool { return domain.SearchKey(u) == domain.SearchKey(t) }) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestSynonyms_Validation(t *testing.T) {
+	s := NewSynonyms(&memSynonymRepo{}, time.Minute)
+	for _, terms := range [][]string{
+		nil,
+		{"coding"},
+		{"coding", "  "},
+		{"coding", "--"},
+		{"coding", strings.Repeat("x", domain.MaxSynonymTermLen+1)},
+		{"Programming", "coding", "programming"},
+		slices.Repeat([]string{"x"}, domain.MaxSynonymTerms+1),
+	} {
+		_, err := s.CreateSynonyms(context.Background(), ports.SynonymInput{Terms: terms})
+		var ve *ValidationError
+		if !errors.As(err, &ve) || ve.Fields["terms"] == "" {
+			t.Errorf("Create(%q) = %v; want an error for terms", terms, err)
+		}
+	}
+}
+
+func TestSynonyms_CRUD(t *testing.T) {
+	s := NewSynonyms(&memSynonymRepo{}, time.Minute)
+	ctx := context.Background()
+	g, err := s.CreateSynonyms(ctx, ports.SynonymInput{Terms: []string{" C# ", "csharp"}})
+	if err != nil || g.ID == 0 || !slices.Equal(g.Terms, []string{"C#", "csharp"}) {
+		t.Fatalf("Create = %+v, %v", g, err)
+	}
+	if _, err := s.CreateSynonyms(ctx, ports.SynonymInput{Terms: []string{"CSharp", "c sharp"}}); !errors.Is(err, ports.ErrSynonymTaken) {
+		t.Fatalf("Create with a taken term = %v; want ErrSynonymTaken", err)
+	}
+	g, err = s.UpdateSynonyms(ctx, g.ID, ports.SynonymInput{Terms: []string{"C#", "csharp", "c sharp"}})
+	if err != nil || len(g.Terms) != 3 {
+		t.Fatalf("Update = %+v, %v", g, err)
+	}
+	if _, err := s.UpdateSynonyms(ctx, 99, ports.SynonymInput{Terms: []string{"a", "b"}}); err == nil || err.Error() != "synonym group not found" {
+		t.Fatalf("Update of a missing group = %v", err)
+	}
+	if list, err := s.ListSynonyms(ctx); err != nil || len(list) != 1 {
+		t.Fatalf("List = %+v, %v", list, err)
+	}
+	if err := s.DeleteSynonyms(ctx, g.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := s.DeleteSynonyms(ctx, g.ID); err == nil || err.Error() != "synonym group not found" {
+		t.Fatalf("second Delete = %v", err)
+	}
+}
+
+func TestSynonyms_Expand(t *testing.T) {
+	repo := &memSynonymRepo{groups: []domain.SynonymGroup{
+		{ID: 1, Terms: []string{"Programming", "coding"}},
+		{ID: 2, Terms: []string{"C#", "csharp"}},
+		{ID: 3, Terms: []string{"machine learning", "ML"}},
+	}}
+	s := NewSynonyms(repo, time.Minute)
+	for _, tc := range []struct {
+		q    string
+		want []string
+	}{
+		{"Coding", []string{"programming"}},
+		{"C# Programming", []string{"c# coding", "csharp programming", "csharp coding"}},
+		{"intro to machine learning", []string{"intro to ml"}},
+		{"programmingcoding", nil}, // terms match whole words only
+		{"machine", nil},
+		{"  ", nil},
+	} {
+		got, err := s.Expand(context.Background(), tc.q)
+		if err != nil {
+			t.Fatalf("Expand(%q): %v", tc.q, err)
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("Expand(%q) = %q; want %q", tc.q, got, tc.want)
+		}
+	}
+	if repo.lists != 1 {
+		t.Fatalf("repo reads = %d; want the dictionary read once", repo.lists)
+	}
+}
+
+func TestSynonyms_ExpandCapped(t *testing.T) {
+	var groups []domain.SynonymGroup
+	var words []string
+	for i, w := range []string{"a", "b", "c", "d", "e"} {
+		groups = append(groups, domain.SynonymGroup{ID: int64(i + 1), Terms: []string{w, w + w, w + w + w}})
+		words = append(words, w)
+	}
+	s := NewSynonyms(&memSynonymRepo{groups: groups}, time.Minute)
+	got, err := s.Expand(context.Background(), strings.Join(words, " "))
+	if err != nil || len(got) != maxSynonymExpansions {
+		t.Fatalf("Expand = %d searches, %v; want %d", len(got), err, maxSynonymExpansions)
+	}
+}
+
+func TestSynonyms_ChangeDropsDictionary(t *testing.T) {
+	defer SetClock(time.Now)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return now })
+	repo := &memSynonymRepo{}
+	s := NewSynonyms(repo, time.Minute)
+	ctx := context.Background()
+
+	if got, _ := s.Expand(ctx, "coding"); got != nil {
+		t.Fatalf("Expand before Create = %q", got)
+	}
+	if _, err := s.CreateSynonyms(ctx, ports.SynonymInput{Terms: []string{"programming", "coding"}}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if got, _ := s.Expand(ctx, "coding"); !slices.Equal(got, []string{"programming"}) {
+		t.Fatalf("Expand after Create = %q; the old dictionary survived the change", got)
+	}
+
+	// a change made elsewhere shows once the TTL is over
+	repo.groups = nil
+	if got, _ := s.Expand(ctx, "coding"); got == nil {
+		t.Fatalf("Expand before the TTL = nil; want the cached dictionary")
+	}
+	now = now.Add(time.Minute)
+	if got, _ := s.Expand(ctx, "coding"); got != nil {
+		t.Fatalf("Expand after the TTL = %q; want the new dictionary", got)
+	}
+
+	// a failed read keeps the old dictionary
+	repo.groups = []domain.SynonymGroup{{ID: 1, Terms: []string{"programming", "coding"}}}
+	now = now.Add(time.Minute)
+	_, _ = s.Expand(ctx, "coding")
+	repo.err = errors.New("database down")
+	now = now.Add(time.Minute)
+	if got, err := s.Expand(ctx, "coding"); err != nil || got == nil {
+		t.Fatalf("Expand with the database down = %q, %v; want the old dictionary", got, err)
+	}
+}
+
+func TestBookService_Synonyms(t *testing.T) {
+	repo := newMemBookRepo(
+		domain.Book{ID: 1, Title: "Coding in Go", Author: "A", PublicationYear: 2020},
+		domain.Book{ID: 2, Title: "Programming Pearls", Author: "Jon Bentley", PublicationYear: 1986},
+		domain.Book{ID: 3, Title: "Clean Code", Author: "Robert C. Martin", PublicationYear: 2008},
+	)
+	syn := NewSynonyms(&memSynonymRepo{groups: []domain.SynonymGroup{{ID: 1, Terms: []string{"programming", "coding"}}}}, time.Minute)
+	svc := NewBookService(repo, WithSynonyms(syn))
+	ctx := context.Background()
+
+	page, err := svc.ListBooksPage(ctx, ports.ListFilter{Q: "Programming"}, ports.Page{})
+	if err != nil || page.Total != 2 || page.Books[0].ID != 2 || page.Books[1].ID != 1 {
+		t.Fatalf("ListBooksPage = %+v, %v; want both books", page, err)
+	}
+	page, err = svc.ListBooksPage(ctx, ports.ListFilter{Q: "Programming", Exact: true}, ports.Page{})
+	if err != nil || page.Total != 1 {
+		t.Fatalf("exact ListBooksPage = %+v, %v; want no synonyms", page, err)
+	}
+	books, err := svc.SearchBooks(ctx, "coding")
+	if err != nil || len(books) != 2 {
+		t.Fatalf("SearchBooks = %+v, %v; want both books", books, err)
+	}
+
+	// searches go ahead without synonyms when the dictionary can't be read
+	broken := NewBookService(repo, WithSynonyms(NewSynonyms(&memSynonymRepo{err: errors.New("database down")}, time.Minute)))
+	if books, err := broken.SearchBooks(ctx, "coding"); err != nil || len(books) != 1 {
+		t.Fatalf("SearchBooks without synonyms = %+v, %v", books, err)
+	}
+}
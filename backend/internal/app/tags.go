@@ -0,0 +1,156 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// Page sizes of GET /tags.
+const (
+	defaultTagLimit = 100
+	maxTagLimit     = 1000
+)
+
+type tagService struct {
+	books   ports.BookReader
+	tags    ports.TagRepository
+	changes *ChangeFeed // optional; tagging counts as updating the book
+}
+
+func NewTagService(books ports.BookReader, tags ports.TagRepository, changes *ChangeFeed) ports.TagService {
+	return &tagService{books: books, tags: tags, changes: changes}
+}
+
+// TagBook puts tag on the book. Tagging a book with a tag it has, in any
+// spelling, changes nothing.
+func (s *tagService) TagBook(ctx context.Context, bookID int64, tag string) (*domain.Book, error) {
+	name, err := validateTag(tag)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.books.GetByID(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, errors.New("book not found")
+	}
+	key := domain.TagKey(name)
+	if hasTag(b, key) {
+		return b, nil
+	}
+	if len(b.Tags) >= domain.MaxBookTags {
+		errs := &ValidationError{}
+		errs.add("tag", fmt.Sprintf("A book can have at most %d tags", domain.MaxBookTags))
+		return nil, errs
+	}
+	found, added, err := s.tags.Tag(ctx, bookID, name, key, clock().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New("book not found")
+	}
+	if !added {
+		return b, nil // tagged concurrently
+	}
+	return s.recordChange(ctx, b), nil
+}
+
+func (s *tagService) UntagBook(ctx context.Context, bookID int64, tag string) (*domain.Book, error) {
+	key := domain.TagKey(tag)
+	if key == "" {
+		return nil, errors.New("tag not found")
+	}
+	b, err := s.books.GetByID(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, errors.New("book not found")
+	}
+	removed, err := s.tags.Untag(ctx, bookID, key)
+	if err != nil {
+		return nil, err
+	}
+	if !removed {
+		return nil, errors.New("tag not found")
+	}
+	return s.recordChange(ctx, b), nil
+}
+
+func (s *tagService) ListTags(ctx context.Context, q ports.TagQuery) ([]domain.Tag, error) {
+	errs := &ValidationError{}
+	switch {
+	case q.Limit == 0:
+		q.Limit = defaultTagLimit
+	case q.Limit < 0 || q.Limit > maxTagLimit:
+		errs.add("limit", fmt.Sprintf("Limit must be between 1 and %d", maxTagLimit))
+	}
+	if utf8.RuneCountInString(q.Prefix) > domain.MaxTagLen {
+		errs.add("prefix", fmt.Sprintf("Prefix must be ≤ %d characters", domain.MaxTagLen))
+	}
+	if !errs.ok() {
+		return nil, errs
+	}
+	tags, err := s.tags.List(ctx, domain.TagKey(q.Prefix), q.Limit)
+	if err != nil {
+		return nil, err
+	}
+	if tags == nil {
+		tags = []domain.Tag{}
+	}
+	return tags, nil
+}
+
+// recordChange records the tagged book in the change log, which also clears
+// it from the read cache, and returns it as it is now. A failed read falls
+// back to b, as it was before the change.
+func (s *tagService) recordChange(ctx context.Context, b *domain.Book) *domain.Book {
+	after, err := s.books.GetByID(ctx, b.ID)
+	if err != nil {
+		logger.From(ctx).Error("failed to read book for change payload", "id", b.ID, "error", err)
+	}
+	if s.changes != nil {
+		if err := s.changes.Record(ctx, b.ID, domain.ChangeUpdated, after); err != nil {
+			logger.From(ctx).Error("failed to record book change", "id", b.ID, "op", domain.ChangeUpdated, "error", err)
+		}
+	}
+	if after == nil {
+		return b
+	}
+	return after
+}
+
+func hasTag(b *domain.Book, key string) bool {
+	return slices.ContainsFunc(b.Tags, func(t string) bool { return domain.TagKey(t) == key })
+}
+
+// validateTag checks a tag and returns it with its spaces collapsed.
+func validateTag(tag string) (string, error) {
+	name := strings.Join(strings.Fields(tag), " ")
+	errs := &ValidationError{}
+	switch {
+	case name == "":
+		errs.add("tag", "Tag is required")
+	case utf8.RuneCountInString(name) > domain.MaxTagLen:
+		errs.add("tag", fmt.Sprintf("Tag must be ≤ %d characters", domain.MaxTagLen))
+	case strings.ContainsFunc(name, func(r rune) bool { return r == '/' || unicode.IsControl(r) }):
+		errs.add("tag", "Tag can't contain / or control characters")
+	case !strings.ContainsFunc(name, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }):
+		errs.add("tag", "Tag needs a letter or digit")
+	}
+	if !errs.ok() {
+		return "", errs
+	}
+	return name, nil
+}
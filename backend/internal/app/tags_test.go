@@ -0,0 +1,128 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// ---- In-memory ports.TagRepository over a memBookRepo ----
+
+type memTagRepo struct {
+	books *memBookRepo
+	names map[string]string // key → the spelling first used
+}
+
+func (m *memTagRepo) Tag(ctx context.Context, bookID int64, name, key string, at time.Time) (bool, bool, error) {
+	b, ok := m.books.books[bookID]
+	if !ok {
+		return false, false, nil
+	}
+	if hasTag(&b, key) {
+		return true, false, nil
+	}
+	if m.names == nil {
+		m.names = map[string]string{}
+	}
+	if _, ok := m.names[key]; !ok {
+		m.names[key] = name
+	}
+	b.Tags = append(slices.Clone(b.Tags), m.names[key])
+	slices.Sort(b.Tags)
+	m.books.books[bookID] = b
+	return true, true, nil
+}
+
+func (m *memTagRepo) Untag(ctx context.Context, bookID int64, key string) (bool, error) {
+	b, ok := m.books.books[bookID]
+	if !ok || !hasTag(&b, key) {
+		return false, nil
+	}
+	b.Tags = slices.DeleteFunc(slices.Clone(b.Tags), func(t string) bool { return domain.TagKey(t) == key })
+	m.books.books[bookID] = b
+	return true, nil
+}
+
+func (m *memTagRepo) List(ctx context.Context, prefix string, limit int) ([]domain.Tag, error) {
+	counts := map[string]int{}
+	for _, b := range m.books.books {
+		for _, t := range b.Tags {
+			if strings.HasPrefix(domain.TagKey(t), prefix) {
+				counts[t]++
+			}
+		}
+	}
+	var tags []domain.Tag
+	for name, n := range counts {
+		tags = append(tags, domain.Tag{Name: name, Books: n})
+	}
+	slices.SortFunc(tags, func(a, b domain.Tag) int {
+		if a.Books != b.Books {
+			return b.Books - a.Books
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return tags[:min(limit, len(tags))], nil
+}
+
+func TestTags_TagAndUntag(t *testing.T) {
+	books := newMemBookRepo(domain.Book{ID: 1, Title: "Dune"}, domain.Book{ID: 2, Title: "Emma"})
+	changes := &memChangeRepo{}
+	svc := NewTagService(books, &memTagRepo{books: books}, NewChangeFeed(changes))
+	ctx := context.Background()
+
+	b, err := svc.TagBook(ctx, 1, "  Space   Opera ")
+	if err != nil || !slices.Equal(b.Tags, []string{"Space Opera"}) {
+		t.Fatalf("TagBook = %+v, %v", b, err)
+	}
+	// another spelling is the same tag, and keeps the first one
+	if b, err = svc.TagBook(ctx, 1, "space opera"); err != nil || len(b.Tags) != 1 {
+		t.Fatalf("retag = %+v, %v", b, err)
+	}
+	if b, err = svc.TagBook(ctx, 2, "SPACE OPERA"); err != nil || !slices.Equal(b.Tags, []string{"Space Opera"}) {
+		t.Fatalf("TagBook(2) = %+v, %v", b, err)
+	}
+	if len(changes.changes) != 2 || changes.changes[0].Op != domain.ChangeUpdated {
+		t.Fatalf("changes = %+v; want one per book actually tagged", changes.changes)
+	}
+
+	tags, err := svc.ListTags(ctx, ports.TagQuery{Prefix: "Spa"})
+	if err != nil || len(tags) != 1 || tags[0].Books != 2 {
+		t.Fatalf("ListTags = %+v, %v", tags, err)
+	}
+
+	if b, err = svc.UntagBook(ctx, 1, "Space Opéra"); err != nil || len(b.Tags) != 0 {
+		t.Fatalf("UntagBook = %+v, %v", b, err)
+	}
+	if _, err := svc.UntagBook(ctx, 1, "space opera"); err == nil || err.Error() != "tag not found" {
+		t.Fatalf("second UntagBook = %v", err)
+	}
+	if _, err := svc.TagBook(ctx, 9, "classic"); err == nil || err.Error() != "book not found" {
+		t.Fatalf("TagBook(missing) = %v", err)
+	}
+}
+
+func TestTags_Validation(t *testing.T) {
+	full := domain.Book{ID: 1}
+	for i := range domain.MaxBookTags {
+		full.Tags = append(full.Tags, strings.Repeat("t", i+1))
+	}
+	books := newMemBookRepo(full)
+	svc := NewTagService(books, &memTagRepo{books: books}, nil)
+	for _, tag := range []string{"", "   ", "a/b", "--", strings.Repeat("x", domain.MaxTagLen+1), "one too many"} {
+		_, err := svc.TagBook(context.Background(), 1, tag)
+		var ve *ValidationError
+		if !errors.As(err, &ve) || ve.Fields["tag"] == "" {
+			t.Errorf("TagBook(%q) = %v; want a validation error", tag, err)
+		}
+	}
+	if _, err := svc.ListTags(context.Background(), ports.TagQuery{Limit: maxTagLimit + 1}); err == nil {
+		t.Fatal("ListTags accepted a limit above the maximum")
+	}
+}
@@ -0,0 +1,250 @@
+package app
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// maxTaxonomyTerms bounds the categories, and separately the authors, of
+// an imported taxonomy.
+const maxTaxonomyTerms = 10000
+
+type taxonomyService struct {
+	repo ports.TaxonomyRepository
+}
+
+func NewTaxonomyService(repo ports.TaxonomyRepository) ports.TaxonomyService {
+	return &taxonomyService{repo: repo}
+}
+
+func (s *taxonomyService) ExportTaxonomy(ctx context.Context) (*domain.Taxonomy, error) {
+	t, err := s.repo.Taxonomy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t.Version = domain.TaxonomyVersion
+	if t.Categories == nil {
+		t.Categories = []domain.Category{}
+	}
+	if t.Authors == nil {
+		t.Authors = []domain.Author{}
+	}
+	return t, nil
+}
+
+// ImportTaxonomy diffs t against the stored taxonomy and applies the
+// difference in one transaction, so a failed import changes nothing.
+func (s *taxonomyService) ImportTaxonomy(ctx context.Context, t domain.Taxonomy, mode string, dryRun bool) (*ports.TaxonomyReport, error) {
+	t, err := validateTaxonomy(t, mode)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.repo.Taxonomy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ports.TaxonomyReport{Mode: mode, DryRun: dryRun, Conflicts: []ports.TaxonomyConflict{}}
+	var changes ports.TaxonomyChanges
+	categories := diffTerms(&report.Conflicts, "category", mode, current.Categories, t.Categories,
+		func(c domain.Category) string { return c.Slug },
+		func(c domain.Category) map[string]string {
+			return map[string]string{"name": c.Name, "parent": c.Parent}
+		},
+		&report.Categories, &changes.PutCategories, &changes.DeleteCategories)
+	diffTerms(&report.Conflicts, "author", mode, current.Authors, t.Authors,
+		func(a domain.Author) string { return a.ID },
+		func(a domain.Author) map[string]string { return map[string]string{"name": a.Name} },
+		&report.Authors, &changes.PutAuthors, &changes.DeleteAuthors)
+	if err := checkCategoryTree(categories, t.Categories); err != nil {
+		return nil, err
+	}
+	for _, terms := range []*ports.TermReport{&report.Categories, &report.Authors} {
+		slices.Sort(terms.Added)
+		slices.Sort(terms.Updated)
+		slices.Sort(terms.Removed)
+	}
+	slices.SortFunc(report.Conflicts, func(a, b ports.TaxonomyConflict) int {
+		return cmp.Or(cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.ID, b.ID), cmp.Compare(a.Field, b.Field))
+	})
+
+	if !dryRun && !changes.Empty() {
+		if err := s.repo.Apply(ctx, changes, clock().UTC()); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// diffTerms compares the imported terms of one kind with the current ones,
+// filling in the report and the changes to make, and returns the terms the
+// target ends up with, by id. fields lists what a conflict can be about.
+func diffTerms[T comparable](conflicts *[]ports.TaxonomyConflict, kind, mode string, current, imported []T,
+	id func(T) string, fields func(T) map[string]string,
+	terms *ports.TermReport, put *[]T, del *[]string) map[string]T {
+	*terms = ports.TermReport{Added: []string{}, Updated: []string{}, Removed: []string{}}
+	cur := make(map[string]T, len(current))
+	result := make(map[string]T, len(current)+len(imported))
+	for _, t := range current {
+		cur[id(t)] = t
+		if mode == ports.TaxonomyMerge {
+			result[id(t)] = t
+		}
+	}
+	seen := make(map[string]bool, len(imported))
+	for _, t := range imported {
+		key := id(t)
+		seen[key] = true
+		old, ok := cur[key]
+		switch {
+		case !ok:
+			terms.Added = append(terms.Added, key)
+			*put = append(*put, t)
+			result[key] = t
+		case old == t:
+			terms.Unchanged++
+			result[key] = t
+		case mode == ports.TaxonomyMerge:
+			was, now := fields(old), fields(t)
+			names := make([]string, 0, len(now))
+			for f := range now {
+				names = append(names, f)
+			}
+			slices.Sort(names)
+			for _, f := range names {
+				if was[f] != now[f] {
+					*conflicts = append(*conflicts, ports.TaxonomyConflict{Kind: kind, ID: key, Field: f, Current: was[f], Imported: now[f]})
+				}
+			}
+		default:
+			terms.Updated = append(terms.Updated, key)
+			*put = append(*put, t)
+			result[key] = t
+		}
+	}
+	if mode == ports.TaxonomyReplace {
+		for _, t := range current {
+			if key := id(t); !seen[key] {
+				terms.Removed = append(terms.Removed, key)
+				*del = append(*del, key)
+			}
+		}
+	}
+	return result
+}
+
+// checkCategoryTree rejects an import that would leave a category inside a
+// parent the taxonomy lacks, or inside itself. Only imported categories
+// can cause either, as the stored tree is sound.
+func checkCategoryTree(result map[string]domain.Category, imported []domain.Category) error {
+	errs := &ValidationError{}
+	for i, c := range imported {
+		c = result[c.Slug] // a merge may keep the stored version
+		if c.Parent == "" {
+			continue
+		}
+		if _, ok := result[c.Parent]; !ok {
+			errs.add(fmt.Sprintf("categories[%d].parent", i), fmt.Sprintf("No category %q in the taxonomy", c.Parent))
+			continue
+		}
+		// walk up; a chain longer than the tree is a cycle
+		at := c.Parent
+		for range len(result) {
+			if at == c.Slug {
+				errs.add(fmt.Sprintf("categories[%d].parent", i), "Category would be inside itself")
+				break
+			}
+			if at = result[at].Parent; at == "" {
+				break
+			}
+		}
+	}
+	if !errs.ok() {
+		return errs
+	}
+	return nil
+}
+
+// validateTaxonomy checks an imported taxonomy and returns a trimmed copy.
+func validateTaxonomy(t domain.Taxonomy, mode string) (domain.Taxonomy, error) {
+	errs := &ValidationError{}
+	if mode != ports.TaxonomyMerge && mode != ports.TaxonomyReplace {
+		errs.add("mode", "Mode must be merge or replace")
+	}
+	if t.Version != domain.TaxonomyVersion {
+		errs.add("version", fmt.Sprintf("Version must be %d", domain.TaxonomyVersion))
+	}
+	if len(t.Categories) > maxTaxonomyTerms {
+		errs.add("categories", fmt.Sprintf("At most %d categories", maxTaxonomyTerms))
+	}
+	if len(t.Authors) > maxTaxonomyTerms {
+		errs.add("authors", fmt.Sprintf("At most %d authors", maxTaxonomyTerms))
+	}
+	if !errs.ok() {
+		return t, errs
+	}
+
+	t.Categories, t.Authors = slices.Clone(t.Categories), slices.Clone(t.Authors)
+	slugs := map[string]bool{}
+	for i := range t.Categories {
+		c := &t.Categories[i]
+		field := fmt.Sprintf("categories[%d]", i)
+		c.Slug, c.Name, c.Parent = strings.TrimSpace(c.Slug), strings.TrimSpace(c.Name), strings.TrimSpace(c.Parent)
+		switch {
+		case c.Slug == "" || c.Slug != domain.CategorySlug(c.Slug):
+			errs.add(field+".slug", "Slug must be lowercase words joined by dashes, such as science-fiction")
+		case len(c.Slug) > domain.MaxCategoryLen:
+			errs.add(field+".slug", fmt.Sprintf("Slug must be ≤ %d characters", domain.MaxCategoryLen))
+		case slugs[c.Slug]:
+			errs.add(field+".slug", "Slug is listed twice")
+		}
+		slugs[c.Slug] = true
+		switch {
+		case c.Name == "":
+			errs.add(field+".name", "Name is required")
+		case len(c.Name) > 120:
+			errs.add(field+".name", "Name must be ≤ 120 characters")
+		}
+		switch {
+		case c.Parent != "" && c.Parent != domain.CategorySlug(c.Parent):
+			errs.add(field+".parent", "Parent must be a category slug")
+		case c.Parent != "" && c.Parent == c.Slug:
+			errs.add(field+".parent", "Category would be inside itself")
+		}
+	}
+
+	ids := map[string]bool{}
+	for i := range t.Authors {
+		a := &t.Authors[i]
+		field := fmt.Sprintf("authors[%d]", i)
+		a.ID, a.Name = strings.TrimSpace(a.ID), strings.TrimSpace(a.Name)
+		switch {
+		case a.ID == "" || a.ID != domain.AuthorSlug(a.ID):
+			errs.add(field+".id", "Id must be an author slug, such as ursula-k-le-guin")
+		case len(a.ID) > 255:
+			errs.add(field+".id", "Id must be ≤ 255 characters")
+		case ids[a.ID]:
+			errs.add(field+".id", "Id is listed twice")
+		}
+		ids[a.ID] = true
+		switch {
+		case a.Name == "":
+			errs.add(field+".name", "Name is required")
+		case len(a.Name) > 255:
+			errs.add(field+".name", "Name must be ≤ 255 characters")
+		case domain.AuthorSlug(a.Name) != a.ID:
+			// another spelling would move the author to another page
+			errs.add(field+".name", fmt.Sprintf("Name must be a spelling of %q", a.ID))
+		}
+	}
+	if !errs.ok() {
+		return t, errs
+	}
+	return t, nil
+}
@@ -0,0 +1,144 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type memTaxonomyRepo struct {
+	taxonomy domain.Taxonomy
+	applied  []ports.TaxonomyChanges
+}
+
+func (m *memTaxonomyRepo) Taxonomy(ctx context.Context) (*domain.Taxonomy, error) {
+	t := domain.Taxonomy{Categories: slices.Clone(m.taxonomy.Categories), Authors: slices.Clone(m.taxonomy.Authors)}
+	return &t, nil
+}
+func (m *memTaxonomyRepo) Author(ctx context.Context, id string) (*domain.Author, error) {
+	for _, a := range m.taxonomy.Authors {
+		if a.ID == id {
+			return &a, nil
+		}
+	}
+	return nil, nil
+}
+func (m *memTaxonomyRepo) Apply(ctx context.Context, c ports.TaxonomyChanges, at time.Time) error {
+	m.applied = append(m.applied, c)
+	return nil
+}
+
+func storedTaxonomy() *memTaxonomyRepo {
+	return &memTaxonomyRepo{taxonomy: domain.Taxonomy{
+		Categories: []domain.Category{
+			{Slug: "fiction", Name: "Fiction"},
+			{Slug: "poetry", Name: "Poetry"},
+			{Slug: "science-fiction", Name: "Sci-Fi", Parent: "fiction"},
+		},
+		Authors: []domain.Author{{ID: "ursula-k-le-guin", Name: "Ursula K. Le Guin"}},
+	}}
+}
+
+func importedTaxonomy() domain.Taxonomy {
+	return domain.Taxonomy{
+		Version: domain.TaxonomyVersion,
+		Categories: []domain.Category{
+			{Slug: "science-fiction", Name: "Science Fiction", Parent: "fiction"},
+			{Slug: "fiction", Name: "Fiction"},
+			{Slug: "fantasy", Name: " Fantasy ", Parent: "fiction"},
+		},
+		Authors: []domain.Author{{ID: "gabriel-garcia-marquez", Name: "Gabriel García Márquez"}},
+	}
+}
+
+func TestTaxonomyImport_MergeReportsConflicts(t *testing.T) {
+	repo := storedTaxonomy()
+	report, err := NewTaxonomyService(repo).ImportTaxonomy(context.Background(), importedTaxonomy(), ports.TaxonomyMerge, false)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	c := report.Categories
+	if !slices.Equal(c.Added, []string{"fantasy"}) || len(c.Updated) != 0 || len(c.Removed) != 0 || c.Unchanged != 1 {
+		t.Fatalf("categories = %+v", c)
+	}
+	want := []ports.TaxonomyConflict{{Kind: "category", ID: "science-fiction", Field: "name", Current: "Sci-Fi", Imported: "Science Fiction"}}
+	if !slices.Equal(report.Conflicts, want) {
+		t.Fatalf("conflicts = %+v", report.Conflicts)
+	}
+	if len(repo.applied) != 1 {
+		t.Fatalf("applied %d times", len(repo.applied))
+	}
+	got := repo.applied[0]
+	if len(got.PutCategories) != 1 || got.PutCategories[0] != (domain.Category{Slug: "fantasy", Name: "Fantasy", Parent: "fiction"}) ||
+		len(got.DeleteCategories)+len(got.DeleteAuthors) != 0 || len(got.PutAuthors) != 1 {
+		t.Fatalf("changes = %+v", got)
+	}
+}
+
+func TestTaxonomyImport_ReplaceDryRun(t *testing.T) {
+	repo := storedTaxonomy()
+	report, err := NewTaxonomyService(repo).ImportTaxonomy(context.Background(), importedTaxonomy(), ports.TaxonomyReplace, true)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	c := report.Categories
+	if !slices.Equal(c.Updated, []string{"science-fiction"}) || !slices.Equal(c.Removed, []string{"poetry"}) || len(report.Conflicts) != 0 {
+		t.Fatalf("categories = %+v, conflicts = %+v", c, report.Conflicts)
+	}
+	if !slices.Equal(report.Authors.Removed, []string{"ursula-k-le-guin"}) || !slices.Equal(report.Authors.Added, []string{"gabriel-garcia-marquez"}) {
+		t.Fatalf("authors = %+v", report.Authors)
+	}
+	if len(repo.applied) != 0 {
+		t.Fatalf("dry run applied %+v", repo.applied)
+	}
+}
+
+func TestTaxonomyImport_RejectsBrokenTrees(t *testing.T) {
+	svc := NewTaxonomyService(storedTaxonomy())
+	for name, tc := range map[string]struct {
+		mode  string
+		cats  []domain.Category
+		field string
+	}{
+		"missing parent": {ports.TaxonomyReplace, []domain.Category{{Slug: "fantasy", Name: "Fantasy", Parent: "fiction"}}, "categories[0].parent"},
+		"cycle": {ports.TaxonomyMerge, []domain.Category{
+			{Slug: "a", Name: "A", Parent: "b"}, {Slug: "b", Name: "B", Parent: "a"},
+		}, "categories[1].parent"},
+		"not a slug": {ports.TaxonomyMerge, []domain.Category{{Slug: "Science Fiction", Name: "SF"}}, "categories[0].slug"},
+		"duplicate":  {ports.TaxonomyMerge, []domain.Category{{Slug: "a", Name: "A"}, {Slug: "a", Name: "A"}}, "categories[1].slug"},
+		"bad mode":   {"upsert", nil, "mode"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := svc.ImportTaxonomy(context.Background(), domain.Taxonomy{Version: 1, Categories: tc.cats}, tc.mode, false)
+			var ve *ValidationError
+			if !errors.As(err, &ve) || ve.Fields[tc.field] == "" {
+				t.Fatalf("err = %v; want an error for %s", err, tc.field)
+			}
+		})
+	}
+
+	_, err := svc.ImportTaxonomy(context.Background(), domain.Taxonomy{
+		Version: 1, Authors: []domain.Author{{ID: "ursula-k-le-guin", Name: "Ursula Le Guin"}},
+	}, ports.TaxonomyMerge, false)
+	var ve *ValidationError
+	if !errors.As(err, &ve) || ve.Fields["authors[0].name"] == "" {
+		t.Fatalf("misspelt author: %v", err)
+	}
+}
+
+func TestAuthorSummary_UsesCuratedName(t *testing.T) {
+	p, _, _, _ := newAuthorFixture(domain.Book{ID: 1, Title: "The Dispossessed", Author: "URSULA K. LE GUIN", PublicationYear: 1974})
+	if err := p.Rebuild(context.Background()); err != nil {
+		t.Fatalf("Rebuild err: %v", err)
+	}
+	p.UseTaxonomy(storedTaxonomy())
+	s, err := p.AuthorSummary(context.Background(), "ursula-k-le-guin")
+	if err != nil || s.Name != "Ursula K. Le Guin" {
+		t.Fatalf("summary = %+v, %v", s, err)
+	}
+}
@@ -0,0 +1,178 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/gerry-sabar/byfood/pkg/urlclean"
+)
+
+// Limits of one tenant's tracking rules.
+const (
+	maxTrackingParams    = 200 // names per list
+	maxTrackingParamLen  = 100
+	maxTrackingHosts     = 50
+	trackingRulesTenants = 10000 // tenants whose rules are kept in memory
+)
+
+// ErrTrackingRules means a cleanup failed because its tenant's tracking
+// rules couldn't be read.
+var ErrTrackingRules = errors.New("tracking rules unavailable")
+
+// TrackingRules stores each tenant's tracking parameter lists and keeps
+// them in memory for the cleanups that use them. A tenant's entry is
+// dropped when it changes its rules here; other replicas see the change
+// once their entry expires after ttl.
+type TrackingRules struct {
+	repo  ports.TrackingRulesRepository
+	cache *lru[string, *domain.TrackingRules] // nil value: the tenant has none
+	now   func() time.Time
+}
+
+func NewTrackingRules(repo ports.TrackingRulesRepository, ttl time.Duration) *TrackingRules {
+	return &TrackingRules{repo: repo, cache: newLRU[string, *domain.TrackingRules](trackingRulesTenants, ttl), now: clock}
+}
+
+func (s *TrackingRules) GetTrackingRules(ctx context.Context) (*domain.TrackingRules, error) {
+	tenant, err := trackingTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r, err := s.repo.Get(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, errors.New("cleanup rules not found")
+	}
+	return r, nil
+}
+
+// PutTrackingRules replaces the caller's rules. Names are trimmed and
+// lower-cased, hosts converted to their punycode form.
+func (s *TrackingRules) PutTrackingRules(ctx context.Context, in ports.PutTrackingRulesInput) (*domain.TrackingRules, error) {
+	tenant, err := trackingTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	errs := &ValidationError{}
+	r := &domain.TrackingRules{
+		Tenant:    tenant,
+		Strip:     trackingParams(errs, "strip", in.Strip),
+		Keep:      trackingParams(errs, "keep", in.Keep),
+		Hosts:     []domain.HostTrackingRules{},
+		UpdatedAt: s.now().UTC(),
+	}
+	if len(in.Hosts) > maxTrackingHosts {
+		errs.add("hosts", fmt.Sprintf("At most %d hosts", maxTrackingHosts))
+	}
+	for i, h := range in.Hosts {
+		key := fmt.Sprintf("hosts[%d]", i)
+		host, err := urlclean.ParseHost(strings.TrimSpace(h.Host))
+		switch {
+		case strings.TrimSpace(h.Host) == "":
+			errs.add(key+".host", "Host is required")
+		case err != nil || strings.ContainsAny(host.ASCII, "/:@?#*"):
+			errs.add(key+".host", "Invalid host name")
+		case slices.ContainsFunc(r.Hosts, func(o domain.HostTrackingRules) bool { return o.Host == strings.ToLower(host.ASCII) }):
+			errs.add(key+".host", "Host is listed twice")
+		}
+		r.Hosts = append(r.Hosts, domain.HostTrackingRules{
+			Host:  strings.ToLower(host.ASCII),
+			Strip: trackingParams(errs, key+".strip", h.Strip),
+			Keep:  trackingParams(errs, key+".keep", h.Keep),
+		})
+	}
+	if !errs.ok() {
+		return nil, errs
+	}
+	if err := s.repo.Put(ctx, r); err != nil {
+		return nil, err
+	}
+	s.cache.Remove(tenant)
+	return r, nil
+}
+
+func (s *TrackingRules) DeleteTrackingRules(ctx context.Context) error {
+	tenant, err := trackingTenant(ctx)
+	if err != nil {
+		return err
+	}
+	found, err := s.repo.Delete(ctx, tenant)
+	if err != nil {
+		return err
+	}
+	s.cache.Remove(tenant)
+	if !found {
+		return errors.New("cleanup rules not found")
+	}
+	return nil
+}
+
+// For returns the rules of the tenant ctx runs as, nil for anonymous
+// requests and tenants without rules.
+func (s *TrackingRules) For(ctx context.Context) (*domain.TrackingRules, error) {
+	a, ok := domain.ActorFrom(ctx)
+	if !ok || a.ID == "" {
+		return nil, nil
+	}
+	if r, ok := s.cache.Get(a.ID); ok {
+		return r, nil
+	}
+	r, err := s.repo.Get(ctx, a.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Add(a.ID, r)
+	return r, nil
+}
+
+func trackingTenant(ctx context.Context) (string, error) {
+	a, ok := domain.ActorFrom(ctx)
+	if !ok || a.ID == "" {
+		return "", ports.ErrNoTenant
+	}
+	return a.ID, nil
+}
+
+// trackingParams checks and normalizes the parameter names of field.
+func trackingParams(errs *ValidationError, field string, names []string) []string {
+	out := []string{}
+	if len(names) > maxTrackingParams {
+		errs.add(field, fmt.Sprintf("At most %d parameters", maxTrackingParams))
+		return out
+	}
+	for i, n := range names {
+		n = strings.ToLower(strings.TrimSpace(n))
+		key := fmt.Sprintf("%s[%d]", field, i)
+		switch {
+		case n == "" || n == "*":
+			errs.add(key, "Parameter name is required")
+		case len(n) > maxTrackingParamLen:
+			errs.add(key, fmt.Sprintf("Parameter name must be ≤ %d characters", maxTrackingParamLen))
+		case strings.ContainsAny(n, "&=# \t") || strings.Contains(strings.TrimSuffix(n, "*"), "*"):
+			errs.add(key, "Parameter names can't contain &, =, # or spaces, and * only at the end")
+		case !slices.Contains(out, n):
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// trackingParamsOf converts stored rules to the options of urlclean.
+func trackingParamsOf(r *domain.TrackingRules) *urlclean.TrackingParams {
+	if r == nil {
+		return nil
+	}
+	t := &urlclean.TrackingParams{Strip: r.Strip, Keep: r.Keep}
+	for _, h := range r.Hosts {
+		t.Hosts = append(t.Hosts, urlclean.HostTrackingParams{Host: h.Host, Strip: h.Strip, Keep: h.Keep})
+	}
+	return t
+}
@@ -0,0 +1,132 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/gerry-sabar/byfood/pkg/urlclean"
+)
+
+// ---- In-memory ports.TrackingRulesRepository ----
+
+type memTrackingRulesRepo struct {
+	mu    sync.Mutex
+	rules map[string]domain.TrackingRules
+	gets  int
+}
+
+func (m *memTrackingRulesRepo) Get(ctx context.Context, tenant string) (*domain.TrackingRules, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gets++
+	if r, ok := m.rules[tenant]; ok {
+		return &r, nil
+	}
+	return nil, nil
+}
+func (m *memTrackingRulesRepo) Put(ctx context.Context, r *domain.TrackingRules) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.rules == nil {
+		m.rules = map[string]domain.TrackingRules{}
+	}
+	m.rules[r.Tenant] = *r
+	return nil
+}
+func (m *memTrackingRulesRepo) Delete(ctx context.Context, tenant string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.rules[tenant]
+	delete(m.rules, tenant)
+	return ok, nil
+}
+
+func TestTrackingRules_Validation(t *testing.T) {
+	s := NewTrackingRules(&memTrackingRulesRepo{}, time.Minute)
+	if _, err := s.PutTrackingRules(context.Background(), ports.PutTrackingRulesInput{}); !errors.Is(err, ports.ErrNoTenant) {
+		t.Fatalf("anonymous Put = %v; want ErrNoTenant", err)
+	}
+	ctx := domain.WithActor(context.Background(), domain.Actor{ID: "key:feed"})
+	_, err := s.PutTrackingRules(ctx, ports.PutTrackingRulesInput{
+		Strip: []string{"ref", "a=b", "x*y"},
+		Keep:  []string{" "},
+		Hosts: []domain.HostTrackingRules{{Host: "Shop.Example.com"}, {Host: "shop.example.com"}, {Host: "a/b"}},
+	})
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("Put = %v; want a validation error", err)
+	}
+	for _, f := range []string{"strip[1]", "strip[2]", "keep[0]", "hosts[1].host", "hosts[2].host"} {
+		if _, ok := ve.Fields[f]; !ok {
+			t.Errorf("no error for %s in %v", f, ve.Fields)
+		}
+	}
+	if _, ok := ve.Fields["hosts[0].host"]; ok {
+		t.Errorf("hosts[0].host rejected: %v", ve.Fields)
+	}
+
+	r, err := s.PutTrackingRules(ctx, ports.PutTrackingRulesInput{
+		Strip: []string{" Ref ", "ref", "SRC_*"},
+		Hosts: []domain.HostTrackingRules{{Host: "Bücher.example", Keep: []string{"ref"}}},
+	})
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if len(r.Strip) != 2 || r.Strip[0] != "ref" || r.Strip[1] != "src_*" || r.Hosts[0].Host != "xn--bcher-kva.example" {
+		t.Fatalf("Put = %+v", r)
+	}
+}
+
+func TestURLCleaner_TrackingRules(t *testing.T) {
+	repo := &memTrackingRulesRepo{}
+	rules := NewTrackingRules(repo, time.Minute)
+	c, err := NewURLCleaner([]urlclean.Profile{{Name: "feed", Rules: []urlclean.Rule{urlclean.StripTrackingParams, urlclean.SortQuery}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.UseCache(10)
+	c.UseTrackingRules(rules)
+
+	feed := domain.WithActor(context.Background(), domain.Actor{ID: "key:feed"})
+	clean := func(ctx context.Context) string {
+		t.Helper()
+		out, err := c.Clean(ctx, "https://shop.example.com/a?ref=x&utm_source=y&id=1", "feed", CleanupOverrides{})
+		if err != nil {
+			t.Fatalf("Clean: %v", err)
+		}
+		return out
+	}
+	if got := clean(feed); got != "https://shop.example.com/a?id=1&ref=x" {
+		t.Fatalf("before rules: %s", got)
+	}
+	if _, err := rules.PutTrackingRules(feed, ports.PutTrackingRulesInput{Strip: []string{"ref"}}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if got := clean(feed); got != "https://shop.example.com/a?id=1" {
+		t.Fatalf("with rules: %s", got)
+	}
+	// other tenants and anonymous callers keep the built-in list
+	other := domain.WithActor(context.Background(), domain.Actor{ID: "alice"})
+	if got := clean(other); got != "https://shop.example.com/a?id=1&ref=x" {
+		t.Fatalf("other tenant: %s", got)
+	}
+	if got := clean(context.Background()); got != "https://shop.example.com/a?id=1&ref=x" {
+		t.Fatalf("anonymous: %s", got)
+	}
+	gets := repo.gets
+	clean(feed)
+	if repo.gets != gets {
+		t.Fatalf("rules read from the repository again: %d gets, had %d", repo.gets, gets)
+	}
+	if err := rules.DeleteTrackingRules(feed); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if got := clean(feed); got != "https://shop.example.com/a?id=1&ref=x" {
+		t.Fatalf("after delete: %s", got)
+	}
+}
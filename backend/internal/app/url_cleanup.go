@@ -0,0 +1,77 @@
+package app
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/gerry-sabar/byfood/pkg/urlclean"
+)
+
+// CleanupOperations are the operations POST /url/cleanup understands.
+// "resolve" follows the URL's redirects and needs a URL resolver.
+var CleanupOperations = []string{"redirection", "canonical", "all", "resolve"}
+
+// CleanupOverrides are a cleanup request's changes to the rules of its
+// operation or profile; empty fields change nothing.
+type CleanupOverrides struct {
+	Fragment    urlclean.Fragment
+	Duplicates  urlclean.Duplicates
+	EmptyParams urlclean.EmptyParams
+}
+
+// ValidateURLCleanup checks a cleanup request field by field and returns the
+// URL trimmed and what to do with it: the operation, or the profile when the
+// request names one, lower-cased. A request names an operation or a profile
+// of profiles, never both. Overrides must hold valid values, and don't go
+// with resolve.
+func ValidateURLCleanup(rawURL, op, profile string, o CleanupOverrides, profiles *URLCleaner) (string, string, error) {
+	errs := &ValidationError{}
+
+	rawURL = strings.TrimSpace(rawURL)
+	if _, err := urlclean.Parse(rawURL); err != nil {
+		errs.add("url", err.Error())
+	}
+
+	op = strings.ToLower(strings.TrimSpace(op))
+	profile = strings.ToLower(strings.TrimSpace(profile))
+	switch {
+	case profile != "" && op != "":
+		errs.add("profile", "Send either operation or profile, not both")
+	case profile != "":
+		if !profiles.Has(profile) {
+			errs.add("profile", "Unknown profile; GET /url/profiles lists them")
+		}
+		op = profile
+	case op == "":
+		errs.add("operation", "Operation or profile is required")
+	case !slices.Contains(CleanupOperations, op):
+		errs.add("operation", "Operation must be one of "+strings.Join(CleanupOperations, ", "))
+	}
+
+	switch {
+	case o.Fragment == "":
+	case !slices.Contains(urlclean.Fragments, o.Fragment):
+		errs.add("fragment", "Fragment must be one of drop, keep, routes")
+	case op == "resolve":
+		errs.add("fragment", "Fragment doesn't apply to resolve")
+	}
+	switch {
+	case o.Duplicates == "":
+	case !slices.Contains(urlclean.DuplicatesValues, o.Duplicates):
+		errs.add("duplicates", "Duplicates must be one of keep_all, keep_first, keep_last")
+	case op == "resolve":
+		errs.add("duplicates", "Duplicates doesn't apply to resolve")
+	}
+	switch {
+	case o.EmptyParams == "":
+	case !slices.Contains(urlclean.EmptyParamsValues, o.EmptyParams):
+		errs.add("empty_params", "Empty params must be one of keep, drop")
+	case op == "resolve":
+		errs.add("empty_params", "Empty params doesn't apply to resolve")
+	}
+
+	if !errs.ok() {
+		return rawURL, op, errs
+	}
+	return rawURL, op, nil
+}
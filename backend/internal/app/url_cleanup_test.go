@@ -0,0 +1,88 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/pkg/urlclean"
+)
+
+func TestValidateURLCleanup(t *testing.T) {
+	cases := []struct {
+		url, op   string
+		wantField map[string]string
+	}{
+		{" https://example.com/a ", " ALL ", nil},
+		{"http://example.com", "canonical", nil},
+		{"", "all", map[string]string{"url": "URL is required"}},
+		{"https://example.com/" + strings.Repeat("a", urlclean.MaxLength), "all", map[string]string{"url": "URL must be ≤ 2048 characters"}},
+		{"ftp://example.com/file", "all", map[string]string{"url": "URL scheme must be http or https"}},
+		{"example.com/path", "all", map[string]string{"url": "URL scheme must be http or https"}},
+		{"https:///path", "all", map[string]string{"url": "URL must have a host"}},
+		{"https://exa mple.com/%zz", "all", map[string]string{"url": "URL is not valid"}},
+		{"https://example.com", "", map[string]string{"operation": "Operation or profile is required"}},
+		{"", "shorten", map[string]string{"url": "URL is required", "operation": "Operation must be one of redirection, canonical, all, resolve"}},
+	}
+	builtIn, _ := NewURLCleaner(nil)
+	for _, c := range cases {
+		u, op, err := ValidateURLCleanup(c.url, c.op, "", CleanupOverrides{}, builtIn)
+		if c.wantField == nil {
+			if err != nil || u != strings.TrimSpace(c.url) || op != strings.ToLower(strings.TrimSpace(c.op)) {
+				t.Errorf("ValidateURLCleanup(%q, %q) = %q, %q, %v", c.url, c.op, u, op, err)
+			}
+			continue
+		}
+		ve, ok := err.(*ValidationError)
+		if !ok || len(ve.Fields) != len(c.wantField) {
+			t.Errorf("ValidateURLCleanup(%q, %q) err = %v; want fields %v", c.url, c.op, err, c.wantField)
+			continue
+		}
+		for f, msg := range c.wantField {
+			if ve.Fields[f] != msg {
+				t.Errorf("ValidateURLCleanup(%q, %q) %s = %q; want %q", c.url, c.op, f, ve.Fields[f], msg)
+			}
+		}
+	}
+}
+
+func TestValidateURLCleanup_Profiles(t *testing.T) {
+	c, err := NewURLCleaner([]urlclean.Profile{{Name: "SEO", Rules: []urlclean.Rule{urlclean.DropFragment}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, name, err := ValidateURLCleanup("https://example.com", "", " seo ", CleanupOverrides{}, c); err != nil || name != "seo" {
+		t.Fatalf("profile seo = %q, %v", name, err)
+	}
+	for profile, op := range map[string]string{"missing": "", "seo": "all"} {
+		_, _, err := ValidateURLCleanup("https://example.com", op, profile, CleanupOverrides{}, c)
+		if ve, ok := err.(*ValidationError); !ok || ve.Fields["profile"] == "" {
+			t.Errorf("profile %q, operation %q: err = %v; want a profile error", profile, op, err)
+		}
+	}
+}
+
+func TestValidateURLCleanup_Overrides(t *testing.T) {
+	c, _ := NewURLCleaner(nil)
+	valid := CleanupOverrides{Fragment: urlclean.FragmentRoutes, Duplicates: urlclean.DuplicatesKeepLast, EmptyParams: urlclean.EmptyParamsDrop}
+	if _, _, err := ValidateURLCleanup("https://example.com", "all", "", valid, c); err != nil {
+		t.Fatalf("valid overrides: %v", err)
+	}
+	cases := []struct {
+		op    string
+		o     CleanupOverrides
+		field string
+	}{
+		{"all", CleanupOverrides{Fragment: "hashbang"}, "fragment"},
+		{"resolve", CleanupOverrides{Fragment: urlclean.FragmentKeep}, "fragment"},
+		{"all", CleanupOverrides{Duplicates: "merge"}, "duplicates"},
+		{"resolve", CleanupOverrides{Duplicates: urlclean.DuplicatesKeepFirst}, "duplicates"},
+		{"all", CleanupOverrides{EmptyParams: "blank"}, "empty_params"},
+		{"resolve", CleanupOverrides{EmptyParams: urlclean.EmptyParamsDrop}, "empty_params"},
+	}
+	for _, tc := range cases {
+		_, _, err := ValidateURLCleanup("https://example.com", tc.op, "", tc.o, c)
+		if ve, ok := err.(*ValidationError); !ok || ve.Fields[tc.field] == "" {
+			t.Errorf("%s with %+v: err = %v; want a %s error", tc.op, tc.o, err, tc.field)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/gerry-sabar/byfood/internal/urlsafe"
+	"github.com/gerry-sabar/byfood/pkg/urlclean"
+	"golang.org/x/net/html"
+)
+
+// maxPageSize is how much of a page Extract reads. The hints live in the
+// <head>, which is rarely more than a few kilobytes.
+const maxPageSize = 1 << 20
+
+// URLExtractor reads canonical URL hints from pages for POST /url/extract.
+// Like URLResolver, every URL, redirect and connection goes through the
+// urlsafe policy.
+type URLExtractor struct {
+	policy    urlsafe.Policy
+	client    *http.Client
+	userAgent string
+	robots    *RobotsChecker
+	cleaner   *URLCleaner
+}
+
+var _ ports.URLExtractor = (*URLExtractor)(nil)
+
+// NewURLExtractor fetches pages with client, normally one from
+// policy.Client, whose timeout bounds each page, redirects included.
+// Sitemaps listed in robots.txt come from robots, and the best guess is
+// normalized by cleaner's canonical operation.
+func NewURLExtractor(policy urlsafe.Policy, client *http.Client, userAgent string, robots *RobotsChecker, cleaner *URLCleaner) *URLExtractor {
+	return &URLExtractor{policy: policy, client: client, userAgent: userAgent, robots: robots, cleaner: cleaner}
+}
+
+// Extract GETs rawURL, following redirects, and reads the canonical link,
+// og:url and sitemap links of the page, plus the sitemaps of the host's
+// robots.txt. Hints that aren't http(s) URLs are dropped. Errors are those
+// of URLResolver.Resolve; a page answering with an error status counts as
+// unreachable.
+func (e *URLExtractor) Extract(ctx context.Context, rawURL string) (*domain.PageLinks, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if _, err := urlclean.Parse(rawURL); err != nil {
+		return nil, urlError(err)
+	}
+	u, err := e.policy.Parse(rawURL)
+	if err != nil {
+		return nil, urlError(err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, urlError(err)
+	}
+	req.Header.Set("User-Agent", e.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.1")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fetchError(ctx, "url extract failed", u, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return nil, fetchError(ctx, "url extract failed", u, fmt.Errorf("status %d", resp.StatusCode))
+	}
+
+	final := resp.Request.URL
+	links := &domain.PageLinks{URL: rawURL, FinalURL: final.String(), Sitemaps: []string{}}
+	if ct, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); ct == "text/html" || ct == "application/xhtml+xml" {
+		hints := readHeadLinks(io.LimitReader(resp.Body, maxPageSize))
+		base := final
+		if b, err := final.Parse(hints.base); hints.base != "" && err == nil {
+			base = b
+		}
+		links.Canonical = absoluteHTTP(base, hints.canonical)
+		links.OGURL = absoluteHTTP(base, hints.ogURL)
+		for _, s := range hints.sitemaps {
+			links.Sitemaps = appendNew(links.Sitemaps, absoluteHTTP(base, s))
+		}
+	}
+	if e.robots != nil {
+		for _, s := range e.robots.Sitemaps(ctx, final) {
+			links.Sitemaps = appendNew(links.Sitemaps, absoluteHTTP(final, s))
+		}
+	}
+
+	for _, c := range []struct{ source, url string }{
+		{domain.LinkSourceCanonical, links.Canonical},
+		{domain.LinkSourceOGURL, links.OGURL},
+		{domain.LinkSourceFinalURL, links.FinalURL},
+	} {
+		if c.url == "" {
+			continue
+		}
+		if best, err := e.cleaner.Clean(ctx, c.url, "canonical", CleanupOverrides{}); err == nil {
+			links.BestGuess, links.Source = best, c.source
+			break
+		}
+	}
+	return links, nil
+}
+
+// headLinks are the raw href/content values found in a page's <head>.
+type headLinks struct {
+	base, canonical, ogURL string
+	sitemaps               []string
+}
+
+// readHeadLinks scans the page up to </head> or <body>. The first
+// <base>, canonical link and og:url win, as in browsers and crawlers.
+func readHeadLinks(r io.Reader) headLinks {
+	var out headLinks
+	z := html.NewTokenizer(r)
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			return out
+		case html.EndTagToken:
+			if name, _ := z.TagName(); string(name) == "head" {
+				return out
+			}
+			continue
+		case html.StartTagToken, html.SelfClosingTagToken:
+		default:
+			continue
+		}
+		name, hasAttr := z.TagName()
+		tag := string(name)
+		if tag == "body" {
+			return out
+		}
+		if !hasAttr || (tag != "link" && tag != "meta" && tag != "base") {
+			continue
+		}
+		attrs := map[string]string{}
+		for more := true; more; {
+			var k, v []byte
+			k, v, more = z.TagAttr()
+			attrs[string(k)] = strings.TrimSpace(string(v))
+		}
+		switch tag {
+		case "base":
+			if out.base == "" {
+				out.base = attrs["href"]
+			}
+		case "link":
+			rels := strings.Fields(strings.ToLower(attrs["rel"]))
+			if slices.Contains(rels, "canonical") && out.canonical == "" {
+				out.canonical = attrs["href"]
+			}
+			if slices.Contains(rels, "sitemap") && attrs["href"] != "" {
+				out.sitemaps = append(out.sitemaps, attrs["href"])
+			}
+		case "meta":
+			prop := strings.ToLower(attrs["property"])
+			if prop == "" {
+				prop = strings.ToLower(attrs["name"])
+			}
+			if prop == "og:url" && out.ogURL == "" {
+				out.ogURL = attrs["content"]
+			}
+		}
+	}
+}
+
+// absoluteHTTP resolves ref against base and returns it if it is an
+// http(s) URL with a host, or "" otherwise.
+func absoluteHTTP(base *url.URL, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	u, err := base.Parse(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+	return u.String()
+}
+
+func appendNew(list []string, s string) []string {
+	if s == "" || slices.Contains(list, s) {
+		return list
+	}
+	return append(list, s)
+}
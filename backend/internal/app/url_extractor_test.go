@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/gerry-sabar/byfood/internal/urlsafe"
+)
+
+func TestReadHeadLinks(t *testing.T) {
+	page := `<!doctype html><html><head>
+<base href="/en/">
+<meta property="og:url" content=" https://example.com/books/dune ">
+<link rel="alternate canonical" href="dune?utm_source=feed">
+<link rel="canonical" href="/ignored">
+<link rel="sitemap" type="application/xml" href="/sitemap.xml">
+</head><body><link rel="sitemap" href="/body.xml"></body></html>`
+	got := readHeadLinks(strings.NewReader(page))
+	want := headLinks{base: "/en/", canonical: "dune?utm_source=feed", ogURL: "https://example.com/books/dune", sitemaps: []string{"/sitemap.xml"}}
+	if got.base != want.base || got.canonical != want.canonical || got.ogURL != want.ogURL || !slices.Equal(got.sitemaps, want.sitemaps) {
+		t.Fatalf("readHeadLinks = %+v; want %+v", got, want)
+	}
+}
+
+func TestURLExtractor_Extract(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "Sitemap: /books-sitemap.xml\n")
+	})
+	mux.HandleFunc("/short", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/Books/Dune/?utm_source=x", http.StatusFound)
+	})
+	mux.HandleFunc("/Books/Dune/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, `<html><head><link rel="canonical" href="javascript:alert(1)"><meta property="og:url" content="/Books/Dune/?ref=og"><link rel="sitemap" href="/sitemap.xml"></head></html>`)
+	})
+	mux.HandleFunc("/data.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"canonical": "nope"}`)
+	})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) { http.Error(w, "gone", http.StatusGone) })
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	ctx := context.Background()
+	policy := urlsafe.Policy{AllowPrivate: true}
+	robots := NewRobotsChecker(policy.Client(time.Second), "byfood-resolver", time.Hour)
+	cleaner, _ := NewURLCleaner(nil)
+	e := NewURLExtractor(policy, policy.Client(time.Second), "byfood-resolver", robots, cleaner)
+
+	links, err := e.Extract(ctx, ts.URL+"/short")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the canonical link isn't http(s), so og:url is the best guess
+	if links.Canonical != "" || links.OGURL != ts.URL+"/Books/Dune/?ref=og" || links.Source != domain.LinkSourceOGURL ||
+		links.BestGuess != ts.URL+"/Books/Dune" {
+		t.Fatalf("links = %+v", links)
+	}
+	if want := []string{ts.URL + "/sitemap.xml", ts.URL + "/books-sitemap.xml"}; !slices.Equal(links.Sitemaps, want) {
+		t.Fatalf("sitemaps = %v; want %v", links.Sitemaps, want)
+	}
+
+	links, err = e.Extract(ctx, ts.URL+"/data.json")
+	if err != nil || links.Source != domain.LinkSourceFinalURL || links.BestGuess != ts.URL+"/data.json" {
+		t.Fatalf("Extract(json) = %+v, %v", links, err)
+	}
+	if _, err := e.Extract(ctx, ts.URL+"/gone"); !errors.Is(err, ports.ErrURLUnreachable) {
+		t.Fatalf("Extract(410) = %v; want ErrURLUnreachable", err)
+	}
+	strict := NewURLExtractor(urlsafe.Policy{}, urlsafe.Policy{}.Client(time.Second), "byfood-resolver", nil, cleaner)
+	for _, u := range []string{ts.URL + "/short", "ftp://example.com/"} {
+		var ve *ValidationError
+		if _, err := strict.Extract(ctx, u); !errors.As(err, &ve) || ve.Fields["url"] == "" {
+			t.Errorf("strict Extract(%q) = %v; want a url validation error", u, err)
+		}
+	}
+}
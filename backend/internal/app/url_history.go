@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+const (
+	defaultURLHistoryLimit = 20
+	// maxURLHistory entries are kept per user; older ones are deleted.
+	maxURLHistory = 100
+)
+
+// URLHistory keeps each user's recent cleanups, so the UI's recent URLs
+// follow them across devices. The user is the actor a request runs as: a
+// signed-in user, a proxy identity or an API key.
+type URLHistory struct {
+	repo ports.URLHistoryRepository
+	now  func() time.Time
+}
+
+func NewURLHistory(repo ports.URLHistoryRepository) *URLHistory {
+	return &URLHistory{repo: repo, now: clock}
+}
+
+func (s *URLHistory) Record(ctx context.Context, url, processedURL, operation string) error {
+	a, ok := domain.ActorFrom(ctx)
+	if !ok || a.ID == "" {
+		return nil
+	}
+	e := &domain.URLHistoryEntry{Owner: a.ID, URL: url, ProcessedURL: processedURL, Operation: operation, CreatedAt: s.now().UTC()}
+	return s.repo.Add(ctx, e, maxURLHistory)
+}
+
+// URLHistory returns the caller's newest entries first. A limit of 0 means
+// the default; larger limits are capped at what is kept.
+func (s *URLHistory) URLHistory(ctx context.Context, limit int) ([]domain.URLHistoryEntry, error) {
+	owner, err := historyOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultURLHistoryLimit
+	}
+	return s.repo.List(ctx, owner, min(limit, maxURLHistory))
+}
+
+func (s *URLHistory) DeleteURLHistoryEntry(ctx context.Context, id int64) error {
+	owner, err := historyOwner(ctx)
+	if err != nil {
+		return err
+	}
+	found, err := s.repo.Delete(ctx, owner, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.New("history entry not found")
+	}
+	return nil
+}
+
+func (s *URLHistory) ClearURLHistory(ctx context.Context) error {
+	owner, err := historyOwner(ctx)
+	if err != nil {
+		return err
+	}
+	return s.repo.Clear(ctx, owner)
+}
+
+func historyOwner(ctx context.Context) (string, error) {
+	a, ok := domain.ActorFrom(ctx)
+	if !ok || a.ID == "" {
+		return "", ports.ErrNoUser
+	}
+	return a.ID, nil
+}
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// ---- In-memory ports.URLHistoryRepository ----
+
+type memURLHistoryRepo struct {
+	mu      sync.Mutex
+	entries []domain.URLHistoryEntry // oldest first
+	nextID  int64
+}
+
+func (m *memURLHistoryRepo) Add(ctx context.Context, e *domain.URLHistoryEntry, keep int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = slices.DeleteFunc(m.entries, func(o domain.URLHistoryEntry) bool {
+		return o.Owner == e.Owner && o.URL == e.URL && o.Operation == e.Operation
+	})
+	m.nextID++
+	e.ID = m.nextID
+	m.entries = append(m.entries, *e)
+	n := 0
+	for i := len(m.entries) - 1; i >= 0; i-- {
+		if m.entries[i].Owner != e.Owner {
+			continue
+		}
+		if n++; n > keep {
+			m.entries = slices.Delete(m.entries, i, i+1)
+		}
+	}
+	return nil
+}
+func (m *memURLHistoryRepo) List(ctx context.Context, owner string, limit int) ([]domain.URLHistoryEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := []domain.URLHistoryEntry{}
+	for i := len(m.entries) - 1; i >= 0 && len(out) < limit; i-- {
+		if m.entries[i].Owner == owner {
+			out = append(out, m.entries[i])
+		}
+	}
+	return out, nil
+}
+func (m *memURLHistoryRepo) Delete(ctx context.Context, owner string, id int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := len(m.entries)
+	m.entries = slices.DeleteFunc(m.entries, func(e domain.URLHistoryEntry) bool { return e.Owner == owner && e.ID == id })
+	return len(m.entries) < n, nil
+}
+func (m *memURLHistoryRepo) Clear(ctx context.Context, owner string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = slices.DeleteFunc(m.entries, func(e domain.URLHistoryEntry) bool { return e.Owner == owner })
+	return nil
+}
+
+func TestURLHistory(t *testing.T) {
+	repo := &memURLHistoryRepo{}
+	s := NewURLHistory(repo)
+	alice := domain.WithActor(context.Background(), domain.Actor{ID: "alice"})
+	bob := domain.WithActor(context.Background(), domain.Actor{ID: "bob"})
+
+	if err := s.Record(context.Background(), "https://a.example/", "https://a.example", "all"); err != nil || len(repo.entries) != 0 {
+		t.Fatalf("anonymous Record = %v, %d entries", err, len(repo.entries))
+	}
+	if _, err := s.URLHistory(context.Background(), 0); !errors.Is(err, ports.ErrNoUser) {
+		t.Fatalf("anonymous URLHistory = %v; want ErrNoUser", err)
+	}
+	for _, u := range []string{"https://a.example/", "https://b.example/", "https://a.example/"} {
+		if err := s.Record(alice, u, u, "all"); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+	_ = s.Record(bob, "https://c.example/", "https://c.example", "canonical")
+
+	got, err := s.URLHistory(alice, 0)
+	if err != nil || len(got) != 2 || got[0].URL != "https://a.example/" || got[1].URL != "https://b.example/" {
+		t.Fatalf("URLHistory = %+v, %v; want a (repeated, so newest) then b", got, err)
+	}
+	if err := s.DeleteURLHistoryEntry(bob, got[0].ID); err == nil {
+		t.Fatalf("bob deleted alice's entry")
+	}
+	if err := s.DeleteURLHistoryEntry(alice, got[0].ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := s.ClearURLHistory(alice); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+	if got, _ := s.URLHistory(alice, 0); len(got) != 0 {
+		t.Fatalf("after Clear: %+v", got)
+	}
+	if got, _ := s.URLHistory(bob, 0); len(got) != 1 {
+		t.Fatalf("bob's history = %+v", got)
+	}
+
+	for i := 0; i < maxURLHistory+5; i++ {
+		_ = s.Record(alice, "https://example.com/"+string(rune('a'+i%26))+string(rune('a'+i/26)), "x", "all")
+	}
+	if got, _ := s.URLHistory(alice, 1000); len(got) != maxURLHistory {
+		t.Fatalf("kept %d entries; want %d", len(got), maxURLHistory)
+	}
+}
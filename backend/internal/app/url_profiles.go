@@ -0,0 +1,126 @@
+package app
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/pkg/urlclean"
+)
+
+// URLCleaner applies cleanup profiles to URLs with pkg/urlclean.
+type URLCleaner struct {
+	profiles *urlclean.Profiles
+	cache    *lru[cleanKey, string] // nil unless UseCache was called
+	tracking *TrackingRules         // nil unless UseTrackingRules was called
+}
+
+type cleanKey struct {
+	profile   string
+	overrides CleanupOverrides
+	url       string
+	// tenant and rulesVersion identify the tracking rules applied, if any
+	tenant       string
+	rulesVersion int64
+}
+
+// NewURLCleaner offers the built-in profiles plus extra, checking that every
+// extra profile has a new name and only known rules.
+func NewURLCleaner(extra []urlclean.Profile) (*URLCleaner, error) {
+	ps, err := urlclean.NewProfiles(extra...)
+	if err != nil {
+		return nil, err
+	}
+	return &URLCleaner{profiles: ps}, nil
+}
+
+// Has reports whether a profile is named name.
+func (c *URLCleaner) Has(name string) bool {
+	_, ok := c.profiles.Lookup(name)
+	return ok
+}
+
+// Rules returns the rules the profile name applies, none for an unknown
+// profile or the resolve operation.
+func (c *URLCleaner) Rules(name string) []string {
+	p, _ := c.profiles.Lookup(name)
+	rules := make([]string, len(p.Rules))
+	for i, r := range p.Rules {
+		rules[i] = string(r)
+	}
+	return rules
+}
+
+// Profiles lists every profile, built-ins first.
+func (c *URLCleaner) Profiles() []domain.CleanupProfile {
+	var out []domain.CleanupProfile
+	for _, p := range c.profiles.List() {
+		out = append(out, domain.CleanupProfile{
+			Name:        p.Name,
+			Description: p.Description,
+			Rules:       c.Rules(p.Name),
+			HTTPSHosts:  p.HTTPSHosts,
+			BuiltIn:     urlclean.IsBuiltIn(p.Name),
+		})
+	}
+	return out
+}
+
+// UseCache keeps the results of the last size cleanups. Profiles are fixed
+// once the cleaner is built, so the same URL and profile always clean to the
+// same result and entries never go stale; a tenant's tracking rules are part
+// of the key with the time they were saved. Call it before serving requests.
+func (c *URLCleaner) UseCache(size int) {
+	c.cache = newLRU[cleanKey, string](size, 0)
+}
+
+// CacheStats returns the counters of the cache set up by UseCache.
+func (c *URLCleaner) CacheStats() CacheStats {
+	if c.cache == nil {
+		return CacheStats{}
+	}
+	return c.cache.Stats()
+}
+
+// Publish exposes the cache's counters as the expvar "url_cleanup_cache"
+// (served on /debug/vars). It panics if called twice, like expvar.Publish.
+func (c *URLCleaner) Publish() {
+	expvar.Publish("url_cleanup_cache", expvar.Func(func() any { return c.CacheStats() }))
+}
+
+// UseTrackingRules makes strip_tracking_params use the tracking rules in r
+// of the tenant each cleanup runs as. Call it before serving requests.
+func (c *URLCleaner) UseTrackingRules(r *TrackingRules) {
+	c.tracking = r
+}
+
+// Clean applies the named profile to rawURL, with its rules changed by o;
+// ValidateURLCleanup has already checked both.
+func (c *URLCleaner) Clean(ctx context.Context, rawURL, profile string, o CleanupOverrides) (string, error) {
+	var rules *domain.TrackingRules
+	if c.tracking != nil {
+		var err error
+		if rules, err = c.tracking.For(ctx); err != nil {
+			return "", fmt.Errorf("%w: %v", ErrTrackingRules, err)
+		}
+	}
+	opts := urlclean.Options{Profile: profile, Profiles: c.profiles,
+		Fragment: o.Fragment, Duplicates: o.Duplicates, EmptyParams: o.EmptyParams, Tracking: trackingParamsOf(rules)}
+	if c.cache == nil {
+		return urlclean.Clean(ctx, rawURL, opts)
+	}
+	key := cleanKey{profile: profile, overrides: o, url: rawURL}
+	if rules != nil {
+		key.tenant, key.rulesVersion = rules.Tenant, rules.UpdatedAt.UnixNano()
+	}
+	if out, ok := c.cache.Get(key); ok {
+		return out, nil
+	}
+	out, err := urlclean.Clean(ctx, rawURL, opts)
+	if err != nil {
+		return "", err
+	}
+	c.cache.Add(key, out)
+	return out, nil
+}
@@ -0,0 +1,31 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/pkg/urlclean"
+)
+
+func TestURLCleaner(t *testing.T) {
+	c, err := NewURLCleaner([]urlclean.Profile{
+		{Name: "Cache_Key", Rules: []urlclean.Rule{urlclean.LowercaseHost, urlclean.SortQuery}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := c.Profiles()
+	if len(all) != 4 || !all[0].BuiltIn || all[3].Name != "cache_key" || all[3].BuiltIn ||
+		len(all[3].Rules) != 2 || all[3].Rules[1] != "sort_query" {
+		t.Fatalf("Profiles = %+v", all)
+	}
+	if !c.Has("cache_key") || c.Has("seo") {
+		t.Fatalf("Has is wrong")
+	}
+	if got, err := c.Clean(context.Background(), "https://Example.com/?b=2&a=1", "cache_key", CleanupOverrides{}); err != nil || got != "https://example.com/?a=1&b=2" {
+		t.Fatalf("Clean = %q, %v", got, err)
+	}
+	if _, err := NewURLCleaner([]urlclean.Profile{{Name: "all", Rules: []urlclean.Rule{urlclean.DropQuery}}}); err == nil {
+		t.Fatalf("profile shadowing a built-in accepted")
+	}
+}
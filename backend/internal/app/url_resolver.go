@@ -0,0 +1,135 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/gerry-sabar/byfood/internal/httpclient"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/gerry-sabar/byfood/internal/urlsafe"
+)
+
+// policyErrors are the urlsafe and egress allowlist rejections, reported to
+// the caller as validation errors on the url rather than as fetch failures.
+var policyErrors = []error{
+	urlsafe.ErrScheme, urlsafe.ErrUserinfo, urlsafe.ErrHost,
+	urlsafe.ErrLocalhost, urlsafe.ErrPrivate, httpclient.ErrEgressDenied,
+}
+
+// URLResolver resolves URLs for the cleanup resolve operation. Every URL,
+// redirect and connection goes through the urlsafe policy.
+type URLResolver struct {
+	policy    urlsafe.Policy
+	client    *http.Client
+	userAgent string
+	robots    *RobotsChecker
+}
+
+var _ ports.URLResolver = (*URLResolver)(nil)
+
+// NewURLResolver resolves with a copy of client, normally one from
+// policy.Client, whose timeout bounds each resolution, redirects included.
+// It identifies itself as userAgent. robots decides what may be fetched when
+// a resolution respects robots.txt.
+func NewURLResolver(policy urlsafe.Policy, client *http.Client, userAgent string, robots *RobotsChecker) *URLResolver {
+	c := *client
+	r := &URLResolver{policy: policy, client: &c, userAgent: userAgent, robots: robots}
+	checkPolicy := r.client.CheckRedirect
+	r.client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if err := checkPolicy(req, via); err != nil {
+			return err
+		}
+		return r.checkRobots(req.Context(), req.URL)
+	}
+	return r
+}
+
+// blockedError stops a resolution at a URL robots.txt disallows.
+type blockedError struct{ url *url.URL }
+
+func (e *blockedError) Error() string { return "blocked by robots.txt: " + e.url.Redacted() }
+
+type respectRobotsKey struct{}
+
+// checkRobots fails with a blockedError when the resolution respects
+// robots.txt and u's host disallows it.
+func (r *URLResolver) checkRobots(ctx context.Context, u *url.URL) error {
+	if respect, _ := ctx.Value(respectRobotsKey{}).(bool); respect && !r.robots.Allowed(ctx, u) {
+		return &blockedError{url: u}
+	}
+	return nil
+}
+
+// Resolve sends HEAD, or GET to servers that refuse HEAD, and returns the
+// URL of the final response. With opts.RespectRobots, the first URL
+// robots.txt disallows is returned unfetched, marked BlockedByRobots. URLs
+// the policy rejects, before or after a redirect, fail with a
+// ValidationError on url; anything else that goes wrong wraps
+// ports.ErrURLUnreachable.
+func (r *URLResolver) Resolve(ctx context.Context, rawURL string, opts ports.ResolveOptions) (*ports.Resolution, error) {
+	u, err := r.policy.Parse(rawURL)
+	if err != nil {
+		return nil, urlError(err)
+	}
+	ctx = context.WithValue(ctx, respectRobotsKey{}, opts.RespectRobots)
+	err = r.checkRobots(ctx, u)
+	var final string
+	if err == nil {
+		final, err = r.fetch(ctx, http.MethodHead, u.String())
+		if errors.Is(err, errMethodRefused) {
+			final, err = r.fetch(ctx, http.MethodGet, u.String())
+		}
+	}
+	var blocked *blockedError
+	if errors.As(err, &blocked) {
+		return &ports.Resolution{URL: blocked.url.String(), BlockedByRobots: true}, nil
+	}
+	if err != nil {
+		return nil, fetchError(ctx, "url resolve failed", u, err)
+	}
+	return &ports.Resolution{URL: final}, nil
+}
+
+// fetchError turns a failed fetch of u into a ValidationError on url when
+// the policy refused it, and into ports.ErrURLUnreachable otherwise.
+func fetchError(ctx context.Context, msg string, u *url.URL, err error) error {
+	for _, pe := range policyErrors {
+		if errors.Is(err, pe) {
+			return urlError(pe)
+		}
+	}
+	logger.From(ctx).Info(msg, "url", u.Redacted(), "error", err)
+	return fmt.Errorf("%w: %v", ports.ErrURLUnreachable, err)
+}
+
+var errMethodRefused = errors.New("method refused")
+
+func (r *URLResolver) fetch(ctx context.Context, method, target string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", r.userAgent)
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if method == http.MethodHead &&
+		(resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		return "", errMethodRefused
+	}
+	return resp.Request.URL.String(), nil
+}
+
+func urlError(err error) error {
+	errs := &ValidationError{}
+	errs.add("url", err.Error())
+	return errs
+}
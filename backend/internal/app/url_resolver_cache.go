@@ -0,0 +1,51 @@
+package app
+
+import (
+	"context"
+	"expvar"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// CachingURLResolver remembers where URLs resolved to for a while, since
+// resolving takes network round trips and short links rarely change their
+// target. Only successful resolutions are kept.
+type CachingURLResolver struct {
+	inner ports.URLResolver
+	cache *lru[resolveKey, ports.Resolution]
+}
+
+var _ ports.URLResolver = (*CachingURLResolver)(nil)
+
+// NewCachingURLResolver keeps up to size resolutions for ttl each.
+func NewCachingURLResolver(inner ports.URLResolver, size int, ttl time.Duration) *CachingURLResolver {
+	return &CachingURLResolver{inner: inner, cache: newLRU[resolveKey, ports.Resolution](size, ttl)}
+}
+
+type resolveKey struct {
+	url  string
+	opts ports.ResolveOptions
+}
+
+func (r *CachingURLResolver) Resolve(ctx context.Context, rawURL string, opts ports.ResolveOptions) (*ports.Resolution, error) {
+	key := resolveKey{url: rawURL, opts: opts}
+	if out, ok := r.cache.Get(key); ok {
+		return &out, nil
+	}
+	out, err := r.inner.Resolve(ctx, rawURL, opts)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.Add(key, *out)
+	return out, nil
+}
+
+// Stats returns the cache's counters.
+func (r *CachingURLResolver) Stats() CacheStats { return r.cache.Stats() }
+
+// Publish exposes the cache's counters as the expvar "url_resolve_cache"
+// (served on /debug/vars). It panics if called twice, like expvar.Publish.
+func (r *CachingURLResolver) Publish() {
+	expvar.Publish("url_resolve_cache", expvar.Func(func() any { return r.Stats() }))
+}
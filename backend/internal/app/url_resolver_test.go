@@ -0,0 +1,124 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/httpclient"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/gerry-sabar/byfood/internal/urlsafe"
+)
+
+func newTestResolver(policy urlsafe.Policy) *URLResolver {
+	robots := NewRobotsChecker(policy.Client(time.Second), "byfood-resolver", time.Hour)
+	return NewURLResolver(policy, policy.Client(time.Second), "byfood-resolver", robots)
+}
+
+func TestURLResolver_FollowsRedirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/short", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/books/1?utm_source=x", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/books/1", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/no-head", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		http.Redirect(w, r, "/books/1", http.StatusFound)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	ctx := context.Background()
+
+	r := newTestResolver(urlsafe.Policy{AllowPrivate: true})
+	if got, err := r.Resolve(ctx, ts.URL+"/short", ports.ResolveOptions{}); err != nil || got.URL != ts.URL+"/books/1?utm_source=x" {
+		t.Fatalf("Resolve(/short) = %+v, %v", got, err)
+	}
+	if got, err := r.Resolve(ctx, ts.URL+"/no-head", ports.ResolveOptions{}); err != nil || got.URL != ts.URL+"/books/1" {
+		t.Fatalf("Resolve(/no-head) = %+v, %v", got, err)
+	}
+
+	strict := newTestResolver(urlsafe.Policy{})
+	for _, u := range []string{ts.URL + "/short", "http://169.254.169.254/", "https://admin:pw@example.com/"} {
+		_, err := strict.Resolve(ctx, u, ports.ResolveOptions{})
+		var ve *ValidationError
+		if !errors.As(err, &ve) || ve.Fields["url"] == "" {
+			t.Errorf("strict Resolve(%q) = %v; want a url validation error", u, err)
+		}
+	}
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	if _, err := r.Resolve(ctx, closed.URL, ports.ResolveOptions{}); !errors.Is(err, ports.ErrURLUnreachable) {
+		t.Fatalf("Resolve(closed server) = %v; want ErrURLUnreachable", err)
+	}
+}
+
+func TestURLResolver_RespectsRobots(t *testing.T) {
+	var fetched []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fetched = append(fetched, r.URL.Path)
+		if ua := r.Header.Get("User-Agent"); ua != "byfood-resolver" {
+			t.Errorf("User-Agent = %q", ua)
+		}
+		if r.URL.Path == "/short" {
+			http.Redirect(w, r, "/private/page", http.StatusFound)
+		}
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	ctx := context.Background()
+	r := newTestResolver(urlsafe.Policy{AllowPrivate: true})
+
+	got, err := r.Resolve(ctx, ts.URL+"/short", ports.ResolveOptions{RespectRobots: true})
+	if err != nil || !got.BlockedByRobots || got.URL != ts.URL+"/private/page" {
+		t.Fatalf("Resolve(redirect into /private) = %+v, %v", got, err)
+	}
+	got, err = r.Resolve(ctx, ts.URL+"/private/x", ports.ResolveOptions{RespectRobots: true})
+	if err != nil || !got.BlockedByRobots || got.URL != ts.URL+"/private/x" {
+		t.Fatalf("Resolve(/private/x) = %+v, %v", got, err)
+	}
+	if !slices.Equal(fetched, []string{"/short"}) {
+		t.Fatalf("fetched %v; want the disallowed pages untouched", fetched)
+	}
+	if got, err := r.Resolve(ctx, ts.URL+"/short", ports.ResolveOptions{}); err != nil || got.BlockedByRobots {
+		t.Fatalf("Resolve without robots = %+v, %v", got, err)
+	}
+}
+
+func TestURLResolver_EgressAllowlist(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/away" {
+			http.Redirect(w, r, "http://localhost/elsewhere", http.StatusFound)
+		}
+	}))
+	defer ts.Close()
+	ctx := context.Background()
+
+	policy := urlsafe.Policy{AllowPrivate: true}
+	allow, _ := httpclient.ParseAllowlist([]string{"127.0.0.1"})
+	client := httpclient.New(policy.Client(time.Second), httpclient.Options{Name: "test-resolver", Egress: allow})
+	r := NewURLResolver(policy, client, "byfood-resolver", NewRobotsChecker(client, "byfood-resolver", time.Hour))
+
+	if got, err := r.Resolve(ctx, ts.URL+"/page", ports.ResolveOptions{RespectRobots: true}); err != nil || got.URL != ts.URL+"/page" {
+		t.Fatalf("Resolve(allowed host) = %+v, %v", got, err)
+	}
+	for _, opts := range []ports.ResolveOptions{{}, {RespectRobots: true}} {
+		_, err := r.Resolve(ctx, ts.URL+"/away", opts)
+		var ve *ValidationError
+		if !errors.As(err, &ve) || ve.Fields["url"] != httpclient.ErrEgressDenied.Error() {
+			t.Errorf("Resolve(redirect off the allowlist, %+v) = %v; want a url validation error", opts, err)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// Page sizes of GET /users.
+const (
+	defaultUserLimit = 100
+	maxUserLimit     = 1000
+)
+
+// userScopes are the scopes accounts may be granted.
+var userScopes = []string{domain.ScopeAdmin, domain.ScopeEditor, domain.ScopeReader}
+
+// Users manages the accounts behind user logins. Tokens carry the scopes an
+// account had when it logged in, so scope changes and deletes apply to it
+// from its next login.
+type Users struct {
+	repo       ports.UserRepository
+	iterations int
+	now        func() time.Time
+}
+
+var _ ports.UserService = (*Users)(nil)
+
+func NewUsers(repo ports.UserRepository) *Users {
+	return &Users{repo: repo, iterations: passwordIterations, now: clock}
+}
+
+func (s *Users) ListUsers(ctx context.Context, q ports.UserQuery) (*ports.UserPage, error) {
+	errs := &ValidationError{}
+	switch {
+	case q.Limit == 0:
+		q.Limit = defaultUserLimit
+	case q.Limit < 0 || q.Limit > maxUserLimit:
+		errs.add("limit", fmt.Sprintf("Limit must be between 1 and %d", maxUserLimit))
+	}
+	if q.Offset < 0 {
+		errs.add("offset", "Offset must not be negative")
+	}
+	if !errs.ok() {
+		return nil, errs
+	}
+	users, total, err := s.repo.ListPage(ctx, q.Limit, q.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return &ports.UserPage{Users: users, Total: total, Limit: q.Limit, Offset: q.Offset}, nil
+}
+
+func (s *Users) GetUser(ctx context.Context, id int64) (*domain.User, error) {
+	u, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, errors.New("user not found")
+	}
+	return u, nil
+}
+
+// CreateUser adds an account as POST /auth/register does, with a name and
+// scopes.
+func (s *Users) CreateUser(ctx context.Context, in ports.CreateUserInput) (*domain.User, error) {
+	errs := &ValidationError{}
+	email := validateEmail(errs, in.Email)
+	validatePassword(errs, "password", in.Password)
+	name := validateUserName(errs, in.Name)
+	scopes := validateUserScopes(errs, in.Scopes)
+	if !errs.ok() {
+		return nil, errs
+	}
+	hashed, err := hashPassword(in.Password, s.iterations)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	u := &domain.User{Email: email, PasswordHash: hashed, Name: name, Scopes: scopes, CreatedAt: now, UpdatedAt: now}
+	if u.ID, err = s.repo.Create(ctx, u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// UpdateUser replaces the name and scopes of user id, and its password when
+// in has one.
+func (s *Users) UpdateUser(ctx context.Context, id int64, in ports.UpdateUserInput) (*domain.User, error) {
+	errs := &ValidationError{}
+	name := validateUserName(errs, in.Name)
+	scopes := validateUserScopes(errs, in.Scopes)
+	if in.Password != "" {
+		validatePassword(errs, "password", in.Password)
+	}
+	if !errs.ok() {
+		return nil, errs
+	}
+	u, err := s.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	u.Name, u.Scopes = name, scopes
+	return s.save(ctx, u, in.Password)
+}
+
+// DeleteUser removes user id along with their loans and holds.
+func (s *Users) DeleteUser(ctx context.Context, id int64) error {
+	found, err := s.repo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+// Me looks up the caller by the email its token or identity carries. API
+// keys and proxy identities without an account get "user not found".
+func (s *Users) Me(ctx context.Context) (*domain.User, error) {
+	a, ok := domain.ActorFrom(ctx)
+	if !ok || a.ID == "" {
+		return nil, ports.ErrNotSignedIn
+	}
+	u, err := s.repo.GetByEmail(ctx, strings.ToLower(a.ID))
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, errors.New("user not found")
+	}
+	return u, nil
+}
+
+// UpdateMe replaces the caller's name, and their password when in has a new
+// one and the current one matches.
+func (s *Users) UpdateMe(ctx context.Context, in ports.UpdateProfileInput) (*domain.User, error) {
+	errs := &ValidationError{}
+	name := validateUserName(errs, in.Name)
+	if in.NewPassword != "" {
+		validatePassword(errs, "new_password", in.NewPassword)
+	}
+	if !errs.ok() {
+		return nil, errs
+	}
+	u, err := s.Me(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if in.NewPassword != "" && !checkPassword(u.PasswordHash, in.CurrentPassword) {
+		errs.add("current_password", "Current password is wrong")
+		return nil, errs
+	}
+	u.Name = name
+	return s.save(ctx, u, in.NewPassword)
+}
+
+// save stores u, with password as its new password unless it is empty.
+func (s *Users) save(ctx context.Context, u *domain.User, password string) (*domain.User, error) {
+	if password != "" {
+		hashed, err := hashPassword(password, s.iterations)
+		if err != nil {
+			return nil, err
+		}
+		u.PasswordHash = hashed
+	}
+	u.UpdatedAt = s.now().UTC()
+	found, err := s.repo.Update(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New("user not found")
+	}
+	return u, nil
+}
+
+// validateUserName returns name with its spaces collapsed.
+func validateUserName(errs *ValidationError, name string) string {
+	name = strings.Join(strings.Fields(name), " ")
+	if utf8.RuneCountInString(name) > domain.MaxUserNameLen {
+		errs.add("name", fmt.Sprintf("Name must be ≤ %d characters", domain.MaxUserNameLen))
+	}
+	return name
+}
+
+// validateUserScopes returns scopes sorted, without repeats.
+func validateUserScopes(errs *ValidationError, scopes []string) domain.ScopeList {
+	out := domain.ScopeList{}
+	for _, sc := range scopes {
+		sc = strings.TrimSpace(sc)
+		if !slices.Contains(userScopes, sc) {
+			errs.add("scopes", "Scopes must be among "+strings.Join(userScopes, ", "))
+			continue
+		}
+		out = append(out, sc)
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
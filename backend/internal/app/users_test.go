@@ -0,0 +1,124 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+func newTestUsers(repo ports.UserRepository) *Users {
+	s := NewUsers(repo)
+	s.iterations = 10
+	return s
+}
+
+func TestUsers_Validation(t *testing.T) {
+	s := newTestUsers(&memUserRepo{})
+	ctx := context.Background()
+	for _, in := range []ports.CreateUserInput{
+		{Email: "ann", Password: "correct horse"},
+		{Email: "ann@example.com", Password: "short"},
+		{Email: "ann@example.com", Password: "correct horse", Name: strings.Repeat("x", domain.MaxUserNameLen+1)},
+		{Email: "ann@example.com", Password: "correct horse", Scopes: []string{"root"}},
+	} {
+		if _, err := s.CreateUser(ctx, in); !errors.As(err, new(*ValidationError)) {
+			t.Errorf("CreateUser(%+v) = %v; want a validation error", in, err)
+		}
+	}
+	if _, err := s.ListUsers(ctx, ports.UserQuery{Limit: maxUserLimit + 1}); !errors.As(err, new(*ValidationError)) {
+		t.Errorf("ListUsers over the limit = %v; want a validation error", err)
+	}
+}
+
+func TestUsers_CRUD(t *testing.T) {
+	defer SetClock(time.Now)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return now })
+	repo := &memUserRepo{}
+	s := newTestUsers(repo)
+	ctx := context.Background()
+
+	u, err := s.CreateUser(ctx, ports.CreateUserInput{Email: " Ann@Example.com", Password: "correct horse", Name: "  Ann   Lee ", Scopes: []string{"reader", "editor", "reader"}})
+	if err != nil || u.Email != "ann@example.com" || u.Name != "Ann Lee" || strings.Join(u.Scopes, ",") != "editor,reader" {
+		t.Fatalf("CreateUser = %+v, %v", u, err)
+	}
+	if _, err := s.CreateUser(ctx, ports.CreateUserInput{Email: "ann@example.com", Password: "correct horse"}); !errors.Is(err, ports.ErrEmailTaken) {
+		t.Fatalf("CreateUser twice = %v; want ErrEmailTaken", err)
+	}
+	if _, err := s.CreateUser(ctx, ports.CreateUserInput{Email: "bob@example.com", Password: "correct horse"}); err != nil {
+		t.Fatalf("CreateUser bob: %v", err)
+	}
+
+	page, err := s.ListUsers(ctx, ports.UserQuery{Limit: 1, Offset: 1})
+	if err != nil || page.Total != 2 || len(page.Users) != 1 || page.Users[0].Email != "bob@example.com" {
+		t.Fatalf("ListUsers = %+v, %v", page, err)
+	}
+	if page, err := s.ListUsers(ctx, ports.UserQuery{}); err != nil || page.Limit != defaultUserLimit || len(page.Users) != 2 {
+		t.Fatalf("ListUsers default = %+v, %v", page, err)
+	}
+
+	now = now.Add(time.Hour)
+	old := repo.users[0].PasswordHash
+	u, err = s.UpdateUser(ctx, u.ID, ports.UpdateUserInput{Name: "Ann", Scopes: []string{"admin"}})
+	if err != nil || u.Name != "Ann" || !u.UpdatedAt.Equal(now) || repo.users[0].Scopes[0] != "admin" || repo.users[0].PasswordHash != old {
+		t.Fatalf("UpdateUser = %+v, %v", u, err)
+	}
+	if _, err := s.UpdateUser(ctx, u.ID, ports.UpdateUserInput{Password: "new password"}); err != nil || repo.users[0].PasswordHash == old {
+		t.Fatalf("UpdateUser with a password = %v; the password didn't change", err)
+	}
+	if _, err := s.UpdateUser(ctx, 99, ports.UpdateUserInput{}); err == nil || err.Error() != "user not found" {
+		t.Fatalf("UpdateUser of a missing user = %v", err)
+	}
+
+	if err := s.DeleteUser(ctx, u.ID); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+	if _, err := s.GetUser(ctx, u.ID); err == nil || err.Error() != "user not found" {
+		t.Fatalf("GetUser after DeleteUser = %v", err)
+	}
+	if err := s.DeleteUser(ctx, u.ID); err == nil || err.Error() != "user not found" {
+		t.Fatalf("second DeleteUser = %v", err)
+	}
+}
+
+func TestUsers_Me(t *testing.T) {
+	repo := &memUserRepo{}
+	s := newTestUsers(repo)
+	ctx := context.Background()
+	if _, err := s.CreateUser(ctx, ports.CreateUserInput{Email: "ann@example.com", Password: "correct horse"}); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	if _, err := s.Me(ctx); !errors.Is(err, ports.ErrNotSignedIn) {
+		t.Fatalf("anonymous Me = %v; want ErrNotSignedIn", err)
+	}
+	if _, err := s.Me(domain.WithActor(ctx, domain.Actor{ID: "key:ci"})); err == nil || err.Error() != "user not found" {
+		t.Fatalf("Me without an account = %v", err)
+	}
+	ann := domain.WithActor(ctx, domain.Actor{ID: "ann@example.com"})
+	if u, err := s.Me(ann); err != nil || u.Email != "ann@example.com" {
+		t.Fatalf("Me = %+v, %v", u, err)
+	}
+
+	u, err := s.UpdateMe(ann, ports.UpdateProfileInput{Name: "Ann Lee"})
+	if err != nil || u.Name != "Ann Lee" {
+		t.Fatalf("UpdateMe = %+v, %v", u, err)
+	}
+	old := repo.users[0].PasswordHash
+	_, err = s.UpdateMe(ann, ports.UpdateProfileInput{Name: "Ann Lee", NewPassword: "battery staple", CurrentPassword: "wrong horse"})
+	var ve *ValidationError
+	if !errors.As(err, &ve) || ve.Fields["current_password"] == "" || repo.users[0].PasswordHash != old {
+		t.Fatalf("UpdateMe with a wrong current password = %v", err)
+	}
+	if _, err := s.UpdateMe(ann, ports.UpdateProfileInput{Name: "Ann Lee", NewPassword: "battery staple", CurrentPassword: "correct horse"}); err != nil {
+		t.Fatalf("UpdateMe with a new password: %v", err)
+	}
+	if !checkPassword(repo.users[0].PasswordHash, "battery staple") {
+		t.Fatalf("the new password doesn't match")
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"math"
 	"regexp"
+	"slices"
 	"strings"
 
+	"github.com/gerry-sabar/byfood/internal/domain"
 	"github.com/gerry-sabar/byfood/internal/ports"
 )
 
@@ -42,7 +44,7 @@ func isValidFourDigitYearInt(y int) bool {
 
 func normalizeISBN(s string) string {
 	// remove spaces/hyphens, uppercase X
-	return strings.ToUpper(strings.ReplaceAll(strings.ReplaceAll(s, " ", ""), "-", ""))
+	return domain.ISBNKey(s)
 }
 
 func isValidISBN10(s string) bool {
@@ -124,7 +126,7 @@ func validateAndNormalizeCreate(in ports.CreateBookInput) (ports.CreateBookInput
 	} else if !isValidISBN(in.ISBN) {
 		errs.add("isbn", "Invalid ISBN (must be ISBN-10 or ISBN-13)")
 	} else {
-		in.ISBN = normalizeISBN(in.ISBN) // store normalized
+		in.ISBN, _, _ = CanonicalISBN(in.ISBN) // store the ISBN-13
 	}
 
 	// ---- PublicationYear (create) ----
@@ -142,6 +144,28 @@ func validateAndNormalizeCreate(in ports.CreateBookInput) (ports.CreateBookInput
 		errs.add("price", "Max 2 decimal places")
 	}
 
+	if in.PublisherID != nil && *in.PublisherID <= 0 {
+		errs.add("publisher_id", "Publisher id must be positive")
+	}
+
+	if in.SeriesID != nil && *in.SeriesID <= 0 {
+		errs.add("series_id", "Series id must be positive")
+	}
+	if in.SeriesPosition != nil {
+		if !isSeriesPosition(*in.SeriesPosition) {
+			errs.add("series_position", "Series position must be between 1 and 10000")
+		} else if in.SeriesID == nil {
+			errs.add("series_position", "Series position needs a series_id")
+		}
+	}
+	in.Format = strings.ToLower(strings.TrimSpace(in.Format))
+	if in.Format != "" && !slices.Contains(domain.BookFormats, in.Format) {
+		errs.add("format", "Format must be hardcover, paperback or ebook")
+	}
+	if in.WorkID != nil && *in.WorkID <= 0 {
+		errs.add("work_id", "Work id must be positive")
+	}
+
 	if !errs.ok() {
 		return in, errs
 	}
@@ -180,8 +204,7 @@ func validateAndNormalizeUpdate(in ports.UpdateBookInput) (ports.UpdateBookInput
 		} else if !isValidISBN(s) {
 			errs.add("isbn", "Invalid ISBN (must be ISBN-10 or ISBN-13)")
 		} else {
-			ns := normalizeISBN(s)
-			*in.ISBN = ns
+			*in.ISBN, _, _ = CanonicalISBN(s) // store the ISBN-13
 		}
 	}
 
@@ -204,12 +227,39 @@ func validateAndNormalizeUpdate(in ports.UpdateBookInput) (ports.UpdateBookInput
 		}
 	}
 
+	if in.PublisherID != nil && *in.PublisherID < 0 {
+		errs.add("publisher_id", "Publisher id must be positive, or 0 to remove the publisher")
+	}
+
+	if in.SeriesID != nil && *in.SeriesID < 0 {
+		errs.add("series_id", "Series id must be positive, or 0 to take the book out of its series")
+	}
+	if in.SeriesPosition != nil && *in.SeriesPosition != 0 && !isSeriesPosition(*in.SeriesPosition) {
+		errs.add("series_position", "Series position must be between 1 and 10000, or 0 to clear it")
+	}
+	if in.Format != nil {
+		f := strings.ToLower(strings.TrimSpace(*in.Format))
+		if f != "" && !slices.Contains(domain.BookFormats, f) {
+			errs.add("format", "Format must be hardcover, paperback or ebook")
+		} else {
+			in.Format = &f
+		}
+	}
+	if in.WorkID != nil && *in.WorkID < 0 {
+		errs.add("work_id", "Work id must be positive, or 0 to make the book standalone")
+	}
+
 	if !errs.ok() {
 		return in, errs
 	}
 	return in, nil
 }
 
+// isSeriesPosition reports whether n is a place in a series.
+func isSeriesPosition(n int) bool {
+	return n >= 1 && n <= 10000
+}
+
 /* Optional: helper to pretty print (useful in logs) */
 func (v *ValidationError) String() string {
 	var b strings.Builder
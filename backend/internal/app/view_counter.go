@@ -0,0 +1,72 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// ViewCounter buffers book views in memory and periodically adds them to the
+// view repository, so a page view never waits on a database write.
+type ViewCounter struct {
+	repo ports.ViewRepository
+
+	mu      sync.Mutex
+	pending map[int64]int64
+}
+
+func NewViewCounter(repo ports.ViewRepository) *ViewCounter {
+	return &ViewCounter{repo: repo, pending: map[int64]int64{}}
+}
+
+// Add counts one view of bookID.
+func (c *ViewCounter) Add(bookID int64) {
+	c.mu.Lock()
+	c.pending[bookID]++
+	c.mu.Unlock()
+}
+
+// Flush writes the buffered counts. On failure they are kept for the next
+// attempt.
+func (c *ViewCounter) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	batch := c.pending
+	c.pending = map[int64]int64{}
+	c.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+	if err := c.repo.AddViews(ctx, batch); err != nil {
+		c.mu.Lock()
+		for id, n := range batch {
+			c.pending[id] += n
+		}
+		c.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Run flushes every interval until ctx is done, then flushes once more.
+func (c *ViewCounter) Run(ctx context.Context, every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			Heartbeat(ctx)
+			if err := c.Flush(ctx); err != nil {
+				logger.From(ctx).Error("failed to flush book views", "error", err)
+			}
+		case <-ctx.Done():
+			if err := c.Flush(context.Background()); err != nil {
+				logger.From(ctx).Error("failed to flush book views", "error", err)
+			}
+			return
+		}
+	}
+}
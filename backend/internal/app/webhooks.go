@@ -0,0 +1,436 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/httpclient"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/gerry-sabar/byfood/internal/urlsafe"
+)
+
+const (
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 500
+
+	// webhookPollWait is how long Run long-polls the change log per round,
+	// and so how late a retry may be.
+	webhookPollWait = 5 * time.Second
+	// webhookAttempts is how many times a delivery is tried before it is
+	// dead-lettered, waiting webhookRetryBase after the first failure and
+	// twice as long after each next one, up to webhookRetryMax.
+	webhookAttempts  = 6
+	webhookRetryBase = 30 * time.Second
+	webhookRetryMax  = time.Hour
+	// webhookSecretPrefix marks generated secrets, like apiKeyPrefix.
+	webhookSecretPrefix = "whsec_"
+	webhookUserAgent    = "byfood-webhooks/1.0"
+)
+
+// KindWebhookDelivery dead letters hold a webhook delivery that failed
+// every attempt.
+const KindWebhookDelivery = "webhook_delivery"
+
+// webhookDeliveryLetter is the payload of a KindWebhookDelivery dead letter.
+type webhookDeliveryLetter struct {
+	DeliveryID int64 `json:"delivery_id"`
+}
+
+// Webhooks manages webhooks and, when run, follows the change log to queue
+// a delivery of every book event to the webhooks subscribed to it, then
+// POSTs the deliveries, retrying failed ones with backoff. Deliveries are
+// at least once: receivers tell repeats apart by X-Webhook-Id.
+type Webhooks struct {
+	repo   ports.WebhookRepository
+	feed   *ChangeFeed
+	policy urlsafe.Policy
+	egress *httpclient.Allowlist
+	client *http.Client
+	dlq    *DeadLetters // nil: deliveries that failed every attempt are only logged
+	now    func() time.Time
+}
+
+// NewWebhooks delivers with client, normally one from policy.Client going
+// through egress, whose timeout bounds each attempt. Webhooks must have URLs
+// the policy and egress allow.
+func NewWebhooks(repo ports.WebhookRepository, feed *ChangeFeed, policy urlsafe.Policy, egress *httpclient.Allowlist, client *http.Client) *Webhooks {
+	return &Webhooks{repo: repo, feed: feed, policy: policy, egress: egress, client: client, now: clock}
+}
+
+// UseDeadLetters parks deliveries that failed every attempt in d, from where
+// an operator can retry them, instead of only logging them.
+func (w *Webhooks) UseDeadLetters(d *DeadLetters) {
+	w.dlq = d
+	d.Register(KindWebhookDelivery, func(ctx context.Context, payload []byte) error {
+		var l webhookDeliveryLetter
+		if err := json.Unmarshal(payload, &l); err != nil {
+			return err
+		}
+		del, err := w.repo.GetDelivery(ctx, l.DeliveryID)
+		if err != nil {
+			return err
+		}
+		if del == nil {
+			return errors.New("webhook delivery not found")
+		}
+		hook, err := w.repo.GetByID(ctx, del.WebhookID)
+		if err != nil {
+			return err
+		}
+		if hook == nil {
+			return errors.New("webhook not found")
+		}
+		sendErr := w.send(ctx, *hook, del)
+		if err := w.repo.SaveAttempt(ctx, del); err != nil {
+			return err
+		}
+		return sendErr
+	})
+}
+
+// SignWebhook returns the signature of body sent at timestamp ts: the hex
+// HMAC-SHA256, keyed with secret, of ts, a dot and body.
+func SignWebhook(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (w *Webhooks) CreateWebhook(ctx context.Context, in ports.WebhookInput) (*ports.CreatedWebhook, error) {
+	hook := domain.Webhook{Active: true}
+	if err := w.apply(&hook, in); err != nil {
+		return nil, err
+	}
+	if hook.Secret == "" {
+		raw := make([]byte, 32)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		hook.Secret = webhookSecretPrefix + base64.RawURLEncoding.EncodeToString(raw)
+	}
+	hook.CreatedAt = w.now().UTC()
+	hook.UpdatedAt = hook.CreatedAt
+	id, err := w.repo.Create(ctx, &hook)
+	if err != nil {
+		return nil, err
+	}
+	hook.ID = id
+	return &ports.CreatedWebhook{Webhook: hook, Secret: hook.Secret}, nil
+}
+
+func (w *Webhooks) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	return w.repo.List(ctx)
+}
+
+func (w *Webhooks) GetWebhook(ctx context.Context, id int64) (*domain.Webhook, error) {
+	hook, err := w.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if hook == nil {
+		return nil, errors.New("webhook not found")
+	}
+	return hook, nil
+}
+
+func (w *Webhooks) UpdateWebhook(ctx context.Context, id int64, in ports.WebhookInput) (*domain.Webhook, error) {
+	hook, err := w.GetWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.apply(hook, in); err != nil {
+		return nil, err
+	}
+	hook.UpdatedAt = w.now().UTC()
+	found, err := w.repo.Update(ctx, hook)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New("webhook not found")
+	}
+	return hook, nil
+}
+
+func (w *Webhooks) DeleteWebhook(ctx context.Context, id int64) error {
+	found, err := w.repo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.New("webhook not found")
+	}
+	return nil
+}
+
+// WebhookDeliveries returns the newest deliveries of webhook id first. A
+// limit of 0 means the default; larger limits are capped.
+func (w *Webhooks) WebhookDeliveries(ctx context.Context, id int64, limit int) ([]domain.WebhookDelivery, error) {
+	if _, err := w.GetWebhook(ctx, id); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultDeliveryLimit
+	}
+	return w.repo.Deliveries(ctx, id, min(limit, maxDeliveryLimit))
+}
+
+// apply validates in and sets it on hook, keeping hook's secret and active
+// flag where in leaves them out.
+func (w *Webhooks) apply(hook *domain.Webhook, in ports.WebhookInput) error {
+	errs := &ValidationError{}
+	rawURL := strings.TrimSpace(in.URL)
+	switch {
+	case rawURL == "":
+		errs.add("url", "URL is required")
+	case len(rawURL) > 2048:
+		errs.add("url", "URL must be ≤ 2048 characters")
+	default:
+		if u, err := w.policy.Parse(rawURL); err != nil {
+			errs.add("url", err.Error())
+		} else if !w.egress.Allows(u.Hostname()) {
+			errs.add("url", httpclient.ErrEgressDenied.Error())
+		}
+	}
+	if in.Secret != "" && (len(in.Secret) < 16 || len(in.Secret) > 255) {
+		errs.add("secret", "Secret must be 16 to 255 characters")
+	}
+	events := domain.EventTypes{}
+	for _, e := range in.Events {
+		e = strings.TrimSpace(e)
+		if !slices.Contains(domain.WebhookEvents, e) {
+			errs.add("events", "Events must be of "+strings.Join(domain.WebhookEvents, ", "))
+			break
+		}
+		if !slices.Contains(events, e) {
+			events = append(events, e)
+		}
+	}
+	if !errs.ok() {
+		return errs
+	}
+	slices.Sort(events)
+	hook.URL, hook.Events = rawURL, events
+	if in.Secret != "" {
+		hook.Secret = in.Secret
+	}
+	if in.Active != nil {
+		hook.Active = *in.Active
+	}
+	return nil
+}
+
+// Run follows the change log until ctx is done, queueing deliveries and
+// making the attempts that are due. On first start it begins at the current
+// end of the log, so past events aren't delivered; replaying events to
+// webhooks delivers them.
+func (w *Webhooks) Run(ctx context.Context) {
+	cursor, err := w.repo.Cursor(ctx)
+	if err == nil && cursor == 0 {
+		if cursor, err = w.feed.LatestID(ctx); err == nil {
+			err = w.repo.SaveCursor(ctx, cursor)
+		}
+	}
+	if err != nil {
+		logger.From(ctx).Error("webhook dispatcher failed to start", "error", err)
+		return
+	}
+	for ctx.Err() == nil {
+		Heartbeat(ctx)
+		next, err := w.catchUp(ctx, cursor)
+		if err == nil {
+			err = w.DeliverDue(ctx)
+		}
+		if err != nil && ctx.Err() == nil {
+			logger.From(ctx).Error("webhook dispatcher failed", "cursor", next, "error", err)
+			select {
+			case <-time.After(5 * time.Second):
+			case <-ctx.Done():
+			}
+		}
+		cursor = next
+	}
+}
+
+// catchUp queues the deliveries of one batch of changes after cursor and
+// returns the new cursor.
+func (w *Webhooks) catchUp(ctx context.Context, cursor int64) (int64, error) {
+	changes, err := w.feed.Since(ctx, cursor, 100, webhookPollWait)
+	if err != nil || len(changes) == 0 {
+		return cursor, err
+	}
+	if err := w.EnqueueAll(ctx, changes); err != nil {
+		return cursor, err
+	}
+	cursor = changes[len(changes)-1].ID
+	return cursor, w.repo.SaveCursor(ctx, cursor)
+}
+
+// EnqueueAll queues a delivery of the book event of each of changes to
+// every webhook subscribed to it, e.g. when events are replayed. A webhook
+// never gets two deliveries of an event.
+func (w *Webhooks) EnqueueAll(ctx context.Context, changes []domain.Change) error {
+	hooks, err := w.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	now := w.now().UTC()
+	var deliveries []domain.WebhookDelivery
+	for _, c := range changes {
+		e := domain.EventOf(c)
+		if e.Entity != domain.EntityBook {
+			continue
+		}
+		var payload domain.ChangePayload
+		for _, hook := range hooks {
+			if !hook.Subscribes(e.Type) {
+				continue
+			}
+			if payload == nil {
+				if payload, err = domain.NewChangePayload(e); err != nil {
+					return err
+				}
+			}
+			deliveries = append(deliveries, domain.WebhookDelivery{
+				WebhookID: hook.ID, EventOffset: e.Offset, EventType: e.Type, Payload: payload,
+				Status: domain.DeliveryPending, NextAttemptAt: &now, CreatedAt: now,
+			})
+		}
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return w.repo.Enqueue(ctx, deliveries)
+}
+
+// DeliverDue makes the next attempt at the deliveries that are due, oldest
+// first.
+func (w *Webhooks) DeliverDue(ctx context.Context) error {
+	due, err := w.repo.DueDeliveries(ctx, w.now().UTC(), 50)
+	if err != nil {
+		return err
+	}
+	hooks := map[int64]*domain.Webhook{}
+	for i := range due {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		d := &due[i]
+		hook, ok := hooks[d.WebhookID]
+		if !ok {
+			if hook, err = w.repo.GetByID(ctx, d.WebhookID); err != nil {
+				return err
+			}
+			hooks[d.WebhookID] = hook
+		}
+		if hook == nil {
+			continue // deleted since, with its deliveries
+		}
+		sendErr := w.send(ctx, *hook, d)
+		if sendErr != nil && d.Attempts < webhookAttempts {
+			next := d.LastAttemptAt.Add(webhookBackoff(d.Attempts))
+			d.NextAttemptAt = &next
+		}
+		if err := w.repo.SaveAttempt(ctx, d); err != nil {
+			return err
+		}
+		if sendErr != nil && d.Status == domain.DeliveryFailed {
+			w.deliveryFailed(ctx, d, sendErr)
+		}
+	}
+	return nil
+}
+
+// send makes an attempt at d and records its outcome on d: delivered, or
+// failed when the attempt was the last, else still pending.
+func (w *Webhooks) send(ctx context.Context, hook domain.Webhook, d *domain.WebhookDelivery) error {
+	now := w.now().UTC()
+	d.Attempts++
+	d.LastAttemptAt, d.NextAttemptAt = &now, nil
+	d.ResponseStatus, d.Error = 0, ""
+	err := w.post(ctx, hook, d, now)
+	switch {
+	case err == nil:
+		d.Status = domain.DeliveryDelivered
+	case d.Attempts >= webhookAttempts:
+		d.Status = domain.DeliveryFailed
+	default:
+		d.Status = domain.DeliveryPending
+	}
+	if err != nil {
+		d.Error = err.Error()
+		if len(d.Error) > 1024 {
+			d.Error = strings.ToValidUTF8(d.Error[:1024], "")
+		}
+	}
+	return err
+}
+
+func (w *Webhooks) post(ctx context.Context, hook domain.Webhook, d *domain.WebhookDelivery, now time.Time) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return err
+	}
+	ts := strconv.FormatInt(now.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", webhookUserAgent)
+	req.Header.Set("X-Webhook-Id", strconv.FormatInt(d.ID, 10))
+	req.Header.Set("X-Webhook-Event", d.EventType)
+	req.Header.Set("X-Webhook-Timestamp", ts)
+	req.Header.Set("X-Webhook-Signature", "sha256="+SignWebhook(hook.Secret, ts, d.Payload))
+	res, err := w.client.Do(req)
+	if err != nil {
+		// the URL may carry a token, so only the cause is kept
+		var ue *url.Error
+		if errors.As(err, &ue) {
+			return ue.Err
+		}
+		return err
+	}
+	defer res.Body.Close()
+	// the connection can be reused once the body is read
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	d.ResponseStatus = res.StatusCode
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook answered %s", res.Status)
+	}
+	return nil
+}
+
+// webhookBackoff is the wait after the attempts-th failed attempt.
+func webhookBackoff(attempts int) time.Duration {
+	wait := webhookRetryBase
+	for i := 1; i < attempts && wait < webhookRetryMax; i++ {
+		wait *= 2
+	}
+	return min(wait, webhookRetryMax)
+}
+
+func (w *Webhooks) deliveryFailed(ctx context.Context, d *domain.WebhookDelivery, cause error) {
+	if w.dlq != nil {
+		err := w.dlq.Park(ctx, KindWebhookDelivery, webhookDeliveryLetter{DeliveryID: d.ID}, cause)
+		if err == nil {
+			return
+		}
+		logger.From(ctx).Error("failed to park webhook delivery", "delivery_id", d.ID, "webhook_id", d.WebhookID, "error", err)
+	}
+	logger.From(ctx).Error("webhook delivery failed every attempt", "delivery_id", d.ID, "webhook_id", d.WebhookID, "error", cause)
+}
@@ -0,0 +1,262 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/httpclient"
+	"github.com/gerry-sabar/byfood/internal/ports"
+	"github.com/gerry-sabar/byfood/internal/urlsafe"
+)
+
+// memWebhookRepo keeps webhooks and their deliveries like the tables do.
+type memWebhookRepo struct {
+	hooks      map[int64]domain.Webhook
+	deliveries []domain.WebhookDelivery
+	cursor     int64
+}
+
+func newMemWebhookRepo() *memWebhookRepo { return &memWebhookRepo{hooks: map[int64]domain.Webhook{}} }
+
+func (m *memWebhookRepo) Create(ctx context.Context, w *domain.Webhook) (int64, error) {
+	w.ID = int64(len(m.hooks) + 1)
+	m.hooks[w.ID] = *w
+	return w.ID, nil
+}
+func (m *memWebhookRepo) List(ctx context.Context) ([]domain.Webhook, error) {
+	out := []domain.Webhook{}
+	for id := int64(1); id <= int64(len(m.hooks)); id++ {
+		if w, ok := m.hooks[id]; ok {
+			out = append(out, w)
+		}
+	}
+	return out, nil
+}
+func (m *memWebhookRepo) GetByID(ctx context.Context, id int64) (*domain.Webhook, error) {
+	w, ok := m.hooks[id]
+	if !ok {
+		return nil, nil
+	}
+	return &w, nil
+}
+func (m *memWebhookRepo) Update(ctx context.Context, w *domain.Webhook) (bool, error) {
+	_, ok := m.hooks[w.ID]
+	if ok {
+		m.hooks[w.ID] = *w
+	}
+	return ok, nil
+}
+func (m *memWebhookRepo) Delete(ctx context.Context, id int64) (bool, error) {
+	_, ok := m.hooks[id]
+	delete(m.hooks, id)
+	return ok, nil
+}
+func (m *memWebhookRepo) Enqueue(ctx context.Context, deliveries []domain.WebhookDelivery) error {
+	for _, d := range deliveries {
+		if slices.ContainsFunc(m.deliveries, func(o domain.WebhookDelivery) bool {
+			return o.WebhookID == d.WebhookID && o.EventOffset == d.EventOffset
+		}) {
+			continue
+		}
+		d.ID = int64(len(m.deliveries) + 1)
+		m.deliveries = append(m.deliveries, d)
+	}
+	return nil
+}
+func (m *memWebhookRepo) DueDeliveries(ctx context.Context, now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	out := []domain.WebhookDelivery{}
+	for _, d := range m.deliveries {
+		if d.Status == domain.DeliveryPending && !d.NextAttemptAt.After(now) && m.hooks[d.WebhookID].Active && len(out) < limit {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+func (m *memWebhookRepo) GetDelivery(ctx context.Context, id int64) (*domain.WebhookDelivery, error) {
+	if id < 1 || id > int64(len(m.deliveries)) {
+		return nil, nil
+	}
+	d := m.deliveries[id-1]
+	return &d, nil
+}
+func (m *memWebhookRepo) SaveAttempt(ctx context.Context, d *domain.WebhookDelivery) error {
+	m.deliveries[d.ID-1] = *d
+	return nil
+}
+func (m *memWebhookRepo) Deliveries(ctx context.Context, webhookID int64, limit int) ([]domain.WebhookDelivery, error) {
+	return nil, nil
+}
+func (m *memWebhookRepo) Cursor(ctx context.Context) (int64, error) { return m.cursor, nil }
+func (m *memWebhookRepo) SaveCursor(ctx context.Context, cursor int64) error {
+	m.cursor = cursor
+	return nil
+}
+
+// webhookReceiver records what a webhook endpoint got, answering with the
+// statuses in order and then 204.
+type webhookReceiver struct {
+	mu       sync.Mutex
+	statuses []int
+	got      []*http.Request
+	bodies   []string
+}
+
+func (rc *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.got = append(rc.got, r)
+	rc.bodies = append(rc.bodies, string(body))
+	status := http.StatusNoContent
+	if len(rc.statuses) > 0 {
+		status, rc.statuses = rc.statuses[0], rc.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func newWebhookFixture(t *testing.T, statuses ...int) (*Webhooks, *memWebhookRepo, *webhookReceiver, string, *time.Time) {
+	t.Helper()
+	rc := &webhookReceiver{statuses: statuses}
+	srv := httptest.NewServer(rc)
+	t.Cleanup(srv.Close)
+	repo := newMemWebhookRepo()
+	w := NewWebhooks(repo, NewChangeFeed(&memChangeRepo{}), urlsafe.Policy{AllowPrivate: true}, nil, srv.Client())
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	w.now = func() time.Time { return now }
+	return w, repo, rc, srv.URL, &now
+}
+
+func bookChange(id int64, op string) domain.Change {
+	payload, _ := domain.NewChangePayload(domain.Book{ID: 7, Title: "Dune"})
+	if op == domain.ChangeDeleted {
+		payload = nil
+	}
+	return domain.Change{ID: id, BookID: 7, Op: op, Entity: domain.EntityBook, Version: id, Payload: payload}
+}
+
+func TestWebhooks_Validation(t *testing.T) {
+	allow, _ := httpclient.ParseAllowlist([]string{"*.example.com"})
+	w := NewWebhooks(newMemWebhookRepo(), nil, urlsafe.Policy{}, allow, http.DefaultClient)
+	ctx := context.Background()
+
+	var ve *ValidationError
+	for in, field := range map[*ports.WebhookInput]string{
+		{URL: "http://127.0.0.1/hook"}:                                      "url",
+		{URL: "https://hooks.other.org/x"}:                                  "url",
+		{URL: "https://hooks.example.com/x", Secret: "short"}:               "secret",
+		{URL: "https://hooks.example.com/x", Events: []string{"book.read"}}: "events",
+	} {
+		if _, err := w.CreateWebhook(ctx, *in); !errors.As(err, &ve) || ve.Fields[field] == "" {
+			t.Errorf("create %+v = %v; want an error on %s", *in, err, field)
+		}
+	}
+
+	created, err := w.CreateWebhook(ctx, ports.WebhookInput{URL: " https://hooks.example.com/x ", Events: []string{"book.updated", "book.created", "book.updated"}})
+	if err != nil || !strings.HasPrefix(created.Secret, webhookSecretPrefix) || !created.Active ||
+		!slices.Equal(created.Events, domain.EventTypes{"book.created", "book.updated"}) {
+		t.Fatalf("create = %+v, %v", created, err)
+	}
+	off := false
+	hook, err := w.UpdateWebhook(ctx, created.ID, ports.WebhookInput{URL: "https://hooks.example.com/y", Active: &off})
+	if err != nil || hook.Secret != created.Secret || hook.Active || len(hook.Events) != 0 {
+		t.Fatalf("update = %+v, %v", hook, err)
+	}
+	if err := w.DeleteWebhook(ctx, 9); err == nil || err.Error() != "webhook not found" {
+		t.Fatalf("delete missing: %v", err)
+	}
+}
+
+func TestWebhooks_DeliversSignedEvents(t *testing.T) {
+	w, repo, rc, url, _ := newWebhookFixture(t)
+	ctx := context.Background()
+	all, _ := w.CreateWebhook(ctx, ports.WebhookInput{URL: url + "/all", Secret: "0123456789abcdef"})
+	_, _ = w.CreateWebhook(ctx, ports.WebhookInput{URL: url + "/deletes", Events: []string{"book.deleted"}})
+	off := false
+	_, _ = w.CreateWebhook(ctx, ports.WebhookInput{URL: url + "/off", Active: &off})
+
+	changes := []domain.Change{bookChange(1, domain.ChangeCreated), bookChange(2, domain.ChangeDeleted)}
+	if err := w.EnqueueAll(ctx, changes); err != nil {
+		t.Fatal(err)
+	}
+	// replaying queues nothing twice
+	if err := w.EnqueueAll(ctx, changes); err != nil {
+		t.Fatal(err)
+	}
+	// the inactive webhook gets nothing
+	if len(repo.deliveries) != 3 {
+		t.Fatalf("queued %d deliveries; want 3", len(repo.deliveries))
+	}
+	if err := w.DeliverDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(rc.got) != 3 {
+		t.Fatalf("delivered %d; want 3", len(rc.got))
+	}
+	req, body := rc.got[0], rc.bodies[0]
+	ts := req.Header.Get("X-Webhook-Timestamp")
+	if req.URL.Path != "/all" || req.Header.Get("X-Webhook-Event") != "book.created" || req.Header.Get("X-Webhook-Id") != "1" ||
+		req.Header.Get("X-Webhook-Signature") != "sha256="+SignWebhook(all.Secret, ts, []byte(body)) ||
+		!strings.Contains(body, `"type":"book.created"`) || !strings.Contains(body, `"title":"Dune"`) {
+		t.Fatalf("delivery = %s %v %s", req.URL.Path, req.Header, body)
+	}
+	for _, d := range repo.deliveries {
+		if d.Status != domain.DeliveryDelivered || d.Attempts != 1 || d.ResponseStatus != http.StatusNoContent {
+			t.Errorf("delivery %d = %+v", d.ID, d)
+		}
+	}
+}
+
+func TestWebhooks_RetriesThenDeadLetters(t *testing.T) {
+	statuses := make([]int, webhookAttempts)
+	for i := range statuses {
+		statuses[i] = http.StatusServiceUnavailable
+	}
+	w, repo, rc, url, now := newWebhookFixture(t, statuses...)
+	dlq := NewDeadLetters(&memDeadLetterRepo{})
+	w.UseDeadLetters(dlq)
+	ctx := context.Background()
+	_, _ = w.CreateWebhook(ctx, ports.WebhookInput{URL: url})
+	_ = w.EnqueueAll(ctx, []domain.Change{bookChange(1, domain.ChangeUpdated)})
+
+	var waits []time.Duration
+	for range webhookAttempts {
+		if err := w.DeliverDue(ctx); err != nil {
+			t.Fatal(err)
+		}
+		d := repo.deliveries[0]
+		if d.NextAttemptAt == nil {
+			break
+		}
+		waits = append(waits, d.NextAttemptAt.Sub(*now))
+		*now = *d.NextAttemptAt
+	}
+	want := []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute}
+	if !slices.Equal(waits, want) {
+		t.Fatalf("waits = %v; want %v", waits, want)
+	}
+	d := repo.deliveries[0]
+	if d.Status != domain.DeliveryFailed || d.Attempts != webhookAttempts || d.ResponseStatus != http.StatusServiceUnavailable || d.Error == "" {
+		t.Fatalf("delivery = %+v", d)
+	}
+	letters, _ := dlq.ListDeadLetters(ctx, KindWebhookDelivery, 0)
+	if len(letters) != 1 {
+		t.Fatalf("dead letters = %+v", letters)
+	}
+
+	// the receiver is back: an operator retries the dead letter
+	if err := dlq.RetryDeadLetter(ctx, letters[0].ID); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if d := repo.deliveries[0]; d.Status != domain.DeliveryDelivered || len(rc.got) != webhookAttempts+1 {
+		t.Fatalf("after retry = %+v, %d requests", d, len(rc.got))
+	}
+}
@@ -0,0 +1,311 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/logger"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// Workers supervises the background workers of the process. Each worker
+// calls Heartbeat once per round; one that goes longer than its stall
+// timeout without a heartbeat, or returns while the process is up, is
+// reported as unhealthy. Pausing goes through the repository so every
+// replica's copy of the worker pauses, picked up by Run. Request-driven jobs
+// such as imports register with Track so that Drain waits for them too.
+type Workers struct {
+	repo ports.WorkerRepository
+	now  func() time.Time
+
+	mu       sync.Mutex
+	workers  map[string]*worker
+	draining bool
+	jobs     sync.WaitGroup
+	cutoff   chan struct{} // closed when the drain deadline passes
+}
+
+type worker struct {
+	name       string
+	stallAfter time.Duration
+	lastBeat   time.Time
+	stopped    bool
+	paused     bool
+	resumed    chan struct{} // closed on resume; set while paused
+	cancel     context.CancelCauseFunc
+	done       chan struct{}
+}
+
+func NewWorkers(repo ports.WorkerRepository) *Workers {
+	return &Workers{repo: repo, now: clock, workers: map[string]*worker{}, cutoff: make(chan struct{})}
+}
+
+type workerKey struct{}
+
+// Go runs fn in its own goroutine as the worker name. fn must call Heartbeat
+// with the context it is given at least every stallAfter, and return once
+// that context is done. Nothing starts once Drain was called.
+func (ws *Workers) Go(ctx context.Context, name string, stallAfter time.Duration, fn func(ctx context.Context)) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	w := &worker{name: name, stallAfter: stallAfter, cancel: cancel, done: make(chan struct{})}
+	ws.mu.Lock()
+	if ws.draining {
+		ws.mu.Unlock()
+		cancel(ports.ErrDraining)
+		logger.From(ctx).Warn("not starting background worker while draining", "worker", name)
+		return
+	}
+	w.lastBeat = ws.now()
+	ws.workers[name] = w
+	ws.mu.Unlock()
+	go func() {
+		defer close(w.done)
+		fn(context.WithValue(ctx, workerKey{}, workerRef{ws, w}))
+		if ctx.Err() == nil {
+			logger.Log.Error("background worker stopped", "worker", name)
+		}
+		ws.mu.Lock()
+		w.stopped = true
+		ws.mu.Unlock()
+	}()
+}
+
+// Track registers a request-driven job, such as an import. ok is false once
+// Drain was called; the job must not start then. The job runs with jobCtx,
+// which is cancelled with cause ports.ErrDraining if the job is still running
+// when the drain deadline passes, and calls done when it ends.
+func (ws *Workers) Track(ctx context.Context) (jobCtx context.Context, done func(), ok bool) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if ws.draining {
+		return ctx, func() {}, false
+	}
+	ws.jobs.Add(1)
+	jobCtx, cancel := context.WithCancelCause(ctx)
+	go func() {
+		select {
+		case <-ws.cutoff:
+			cancel(ports.ErrDraining)
+		case <-jobCtx.Done():
+		}
+	}()
+	var once sync.Once
+	return jobCtx, func() {
+		once.Do(func() {
+			cancel(nil)
+			ws.jobs.Done()
+		})
+	}, true
+}
+
+// jobGrace is how long Drain waits, after the deadline, for cut-off jobs to
+// checkpoint.
+const jobGrace = 5 * time.Second
+
+// Drain prepares the process to exit: no worker or job starts any more, the
+// workers are told to stop after their current round, and in-flight jobs may
+// run until ctx is done. Then they are cancelled with ports.ErrDraining and
+// get jobGrace to checkpoint and requeue what is left. Drain returns the
+// workers still running at the deadline; the round they were in is redone
+// from their last checkpoint on the next start.
+func (ws *Workers) Drain(ctx context.Context) (unfinished []string) {
+	ws.mu.Lock()
+	ws.draining = true
+	workers := slices.Collect(maps.Values(ws.workers))
+	ws.mu.Unlock()
+	for _, w := range workers {
+		w.cancel(ports.ErrDraining)
+	}
+
+	jobsDone := make(chan struct{})
+	go func() {
+		ws.jobs.Wait()
+		close(jobsDone)
+	}()
+	for _, w := range workers {
+		select {
+		case <-w.done:
+		case <-ctx.Done():
+		}
+	}
+	select {
+	case <-jobsDone:
+	case <-ctx.Done():
+		close(ws.cutoff)
+		select {
+		case <-jobsDone:
+		case <-time.After(jobGrace):
+			logger.Log.Error("jobs still running after the drain deadline")
+		}
+	}
+	for _, w := range workers {
+		select {
+		case <-w.done:
+		default:
+			unfinished = append(unfinished, w.name)
+		}
+	}
+	slices.Sort(unfinished)
+	return unfinished
+}
+
+type workerRef struct {
+	ws *Workers
+	w  *worker
+}
+
+// Heartbeat records that the worker running ctx is alive and, while it is
+// paused, blocks until it is resumed or ctx is done. It does nothing for a
+// context that doesn't belong to a worker, so loops can call it
+// unconditionally.
+func Heartbeat(ctx context.Context) {
+	ref, ok := ctx.Value(workerKey{}).(workerRef)
+	if !ok {
+		return
+	}
+	for {
+		ref.ws.mu.Lock()
+		ref.w.lastBeat = ref.ws.now()
+		resumed := ref.w.resumed
+		ref.ws.mu.Unlock()
+		if resumed == nil {
+			return
+		}
+		select {
+		case <-resumed:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Unhealthy returns the names of the stalled and stopped workers. Workers
+// stopping because of Drain don't count.
+func (ws *Workers) Unhealthy() []string {
+	ws.mu.Lock()
+	draining := ws.draining
+	ws.mu.Unlock()
+	if draining {
+		return nil
+	}
+	var out []string
+	for _, s := range ws.statuses() {
+		if s.State == domain.WorkerStalled || s.State == domain.WorkerStopped {
+			out = append(out, s.Name)
+		}
+	}
+	return out
+}
+
+func (ws *Workers) ListWorkers(ctx context.Context) ([]domain.WorkerStatus, error) {
+	return ws.statuses(), nil
+}
+
+func (ws *Workers) PauseWorker(ctx context.Context, name string) (*domain.WorkerStatus, error) {
+	return ws.setPaused(ctx, name, true)
+}
+
+func (ws *Workers) ResumeWorker(ctx context.Context, name string) (*domain.WorkerStatus, error) {
+	return ws.setPaused(ctx, name, false)
+}
+
+func (ws *Workers) setPaused(ctx context.Context, name string, paused bool) (*domain.WorkerStatus, error) {
+	ws.mu.Lock()
+	_, found := ws.workers[name]
+	ws.mu.Unlock()
+	if !found {
+		return nil, errors.New("worker not found")
+	}
+	if err := ws.repo.SetPaused(ctx, name, paused, ws.now().UTC()); err != nil {
+		return nil, err
+	}
+	ws.mu.Lock()
+	w := ws.workers[name]
+	ws.pause(w, paused)
+	s := ws.status(w)
+	ws.mu.Unlock()
+	logger.From(ctx).Info("background worker paused", "worker", name, "paused", paused)
+	return &s, nil
+}
+
+// pause switches w; the caller holds ws.mu.
+func (ws *Workers) pause(w *worker, paused bool) {
+	switch {
+	case paused && !w.paused:
+		w.paused, w.resumed = true, make(chan struct{})
+	case !paused && w.paused:
+		close(w.resumed)
+		w.paused, w.resumed = false, nil
+		// the stall timeout restarts from the resume
+		w.lastBeat = ws.now()
+	}
+}
+
+// Run applies pauses and resumes made on other replicas every interval
+// until ctx is done.
+func (ws *Workers) Run(ctx context.Context, every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		if err := ws.refresh(ctx); err != nil && ctx.Err() == nil {
+			logger.From(ctx).Error("failed to load paused workers", "error", err)
+		}
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (ws *Workers) refresh(ctx context.Context) error {
+	names, err := ws.repo.Paused(ctx)
+	if err != nil {
+		return err
+	}
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	for name, w := range ws.workers {
+		ws.pause(w, slices.Contains(names, name))
+	}
+	return nil
+}
+
+func (ws *Workers) statuses() []domain.WorkerStatus {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	out := []domain.WorkerStatus{}
+	for _, name := range slices.Sorted(maps.Keys(ws.workers)) {
+		out = append(out, ws.status(ws.workers[name]))
+	}
+	return out
+}
+
+// status describes w; the caller holds ws.mu.
+func (ws *Workers) status(w *worker) domain.WorkerStatus {
+	s := domain.WorkerStatus{
+		Name: w.name, State: domain.WorkerRunning,
+		LastHeartbeat: w.lastBeat.UTC(), StallAfter: int(w.stallAfter / time.Second),
+	}
+	switch {
+	case w.stopped:
+		s.State = domain.WorkerStopped
+	case w.paused:
+		s.State = domain.WorkerPaused
+	case ws.now().Sub(w.lastBeat) > w.stallAfter:
+		s.State = domain.WorkerStalled
+	}
+	return s
+}
+
+// Publish exposes the worker statuses as the expvar "workers" (served on
+// /debug/vars). It panics if called twice, like expvar.Publish.
+func (ws *Workers) Publish() {
+	expvar.Publish("workers", expvar.Func(func() any { return ws.statuses() }))
+}
@@ -0,0 +1,155 @@
+package app
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+type memWorkerRepo struct {
+	mu     sync.Mutex
+	paused map[string]bool
+}
+
+func (m *memWorkerRepo) Paused(ctx context.Context) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []string
+	for name, p := range m.paused {
+		if p {
+			out = append(out, name)
+		}
+	}
+	return out, nil
+}
+func (m *memWorkerRepo) SetPaused(ctx context.Context, name string, paused bool, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.paused[name] = paused
+	return nil
+}
+
+func TestWorkers_StallPauseAndStop(t *testing.T) {
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	var clockMu sync.Mutex
+	tick := func(d time.Duration) {
+		clockMu.Lock()
+		now = now.Add(d)
+		clockMu.Unlock()
+	}
+	repo := &memWorkerRepo{paused: map[string]bool{}}
+	ws := NewWorkers(repo)
+	ws.now = func() time.Time { clockMu.Lock(); defer clockMu.Unlock(); return now }
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rounds := make(chan struct{})
+	ws.Go(ctx, "matcher", time.Minute, func(ctx context.Context) {
+		for ctx.Err() == nil {
+			Heartbeat(ctx)
+			select {
+			case rounds <- struct{}{}:
+			case <-ctx.Done():
+			}
+		}
+	})
+	done := make(chan struct{})
+	ws.Go(ctx, "oneshot", time.Minute, func(ctx context.Context) { close(done) })
+	<-rounds
+	<-done
+
+	state := func(name string) string {
+		list, _ := ws.ListWorkers(ctx)
+		for _, s := range list {
+			if s.Name == name {
+				return s.State
+			}
+		}
+		return ""
+	}
+	waitFor := func(name, want string) {
+		for i := 0; i < 100 && state(name) != want; i++ {
+			time.Sleep(time.Millisecond)
+		}
+		if got := state(name); got != want {
+			t.Fatalf("%s state = %q; want %q", name, got, want)
+		}
+	}
+	waitFor("oneshot", domain.WorkerStopped)
+	if got := ws.Unhealthy(); !slices.Equal(got, []string{"oneshot"}) {
+		t.Fatalf("Unhealthy = %v", got)
+	}
+
+	tick(2 * time.Minute)
+	if state("matcher") != domain.WorkerStalled {
+		t.Fatalf("matcher should be stalled after 2m without a heartbeat")
+	}
+	<-rounds // the next heartbeat brings it back
+	waitFor("matcher", domain.WorkerRunning)
+
+	// paused on another replica: the refresh pauses the local copy, which
+	// blocks in Heartbeat and isn't reported as stalled
+	_ = repo.SetPaused(ctx, "matcher", true, now)
+	if err := ws.refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	<-rounds // the round already past its heartbeat finishes
+	tick(time.Hour)
+	select {
+	case <-rounds:
+		t.Fatal("paused worker ran another round")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if state("matcher") != domain.WorkerPaused {
+		t.Fatalf("matcher state = %q", state("matcher"))
+	}
+	if s, err := ws.ResumeWorker(ctx, "matcher"); err != nil || s.State != domain.WorkerRunning || repo.paused["matcher"] {
+		t.Fatalf("Resume = %+v, %v", s, err)
+	}
+	<-rounds
+	if _, err := ws.PauseWorker(ctx, "nope"); err == nil || err.Error() != "worker not found" {
+		t.Fatalf("Pause unknown = %v", err)
+	}
+}
+
+func TestWorkers_Drain(t *testing.T) {
+	ws := NewWorkers(&memWorkerRepo{paused: map[string]bool{}})
+	ctx := context.Background()
+
+	// a worker ending its round on cancellation, and one ignoring it
+	ws.Go(ctx, "flush", time.Minute, func(ctx context.Context) { <-ctx.Done() })
+	stuck := make(chan struct{})
+	defer close(stuck)
+	ws.Go(ctx, "stuck", time.Minute, func(ctx context.Context) { <-stuck })
+
+	jobCtx, done, ok := ws.Track(ctx)
+	if !ok {
+		t.Fatalf("Track refused before draining")
+	}
+	cutOff := make(chan error, 1)
+	go func() {
+		<-jobCtx.Done()
+		cutOff <- context.Cause(jobCtx)
+		done()
+	}()
+
+	deadline, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if got := ws.Drain(deadline); !slices.Equal(got, []string{"stuck"}) {
+		t.Fatalf("unfinished = %v; want [stuck]", got)
+	}
+	if err := <-cutOff; err != ports.ErrDraining {
+		t.Fatalf("job cancelled with %v; want ErrDraining", err)
+	}
+	if _, _, ok := ws.Track(ctx); ok {
+		t.Fatalf("Track accepted a job while draining")
+	}
+	if got := ws.Unhealthy(); got != nil {
+		t.Fatalf("Unhealthy while draining = %v", got)
+	}
+}
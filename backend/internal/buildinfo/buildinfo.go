@@ -0,0 +1,60 @@
+// Package buildinfo says what the running binary was built from. The
+// variables are set at build time with
+//
+//	go build -ldflags "-X github.com/gerry-sabar/byfood/internal/buildinfo.Version=1.4.0 \
+//	  -X github.com/gerry-sabar/byfood/internal/buildinfo.Commit=$(git rev-parse HEAD) \
+//	  -X github.com/gerry-sabar/byfood/internal/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Builds without them fall back to the VCS stamp Go records when built
+// inside a git checkout.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+// Info is the build of the running binary.
+// swagger:model BuildInfo
+type Info struct {
+	Version   string `json:"version" example:"1.4.0"`
+	Commit    string `json:"commit" example:"3fff8f0c1d2e4b5a6978a1b2c3d4e5f601234567"`
+	BuildTime string `json:"build_time" example:"2024-01-02T12:00:00Z"`
+	GoVersion string `json:"go_version" example:"go1.23.1"`
+}
+
+// Get returns the build info, "unknown" for what wasn't recorded.
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, BuildTime: BuildTime, GoVersion: runtime.Version()}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch {
+			case s.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = s.Value
+			case s.Key == "vcs.time" && info.BuildTime == "":
+				info.BuildTime = s.Value
+			}
+		}
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.BuildTime == "" {
+		info.BuildTime = "unknown"
+	}
+	return info
+}
+
+// String is the version with the short commit, as in 1.4.0+3fff8f0.
+func (i Info) String() string {
+	if i.Commit == "unknown" {
+		return i.Version
+	}
+	return i.Version + "+" + i.Commit[:min(7, len(i.Commit))]
+}
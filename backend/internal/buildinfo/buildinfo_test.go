@@ -0,0 +1,23 @@
+package buildinfo
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	defer func(v, c, b string) { Version, Commit, BuildTime = v, c, b }(Version, Commit, BuildTime)
+	Version, Commit, BuildTime = "1.4.0", "3fff8f0c1d2e4b5a", "2024-01-02T12:00:00Z"
+
+	got := Get()
+	want := Info{Version: "1.4.0", Commit: "3fff8f0c1d2e4b5a", BuildTime: "2024-01-02T12:00:00Z", GoVersion: runtime.Version()}
+	if got != want {
+		t.Fatalf("Get = %+v; want %+v", got, want)
+	}
+	if s := got.String(); s != "1.4.0+3fff8f0" {
+		t.Fatalf("String = %q", s)
+	}
+	if s := (Info{Version: "dev", Commit: "unknown"}).String(); s != "dev" {
+		t.Fatalf("String without a commit = %q", s)
+	}
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"context"
+	"slices"
+)
+
+// ScopeAdmin lets an actor act as another user (X-Impersonate-User) and use
+// the /admin endpoints.
+const ScopeAdmin = "admin"
+
+// Roles, granted as scopes like admin. Editors may change the catalogue;
+// readers, like anyone without a role, may only read it. Admins may do
+// everything editors may.
+const (
+	ScopeEditor = "editor"
+	ScopeReader = "reader"
+)
+
+// Actor is the identity a request runs as. When an admin impersonates a
+// user, ID is the user and ImpersonatedBy the admin, so both end up on
+// everything the request does.
+type Actor struct {
+	ID             string
+	Scopes         []string
+	ImpersonatedBy string
+}
+
+func (a Actor) HasScope(scope string) bool {
+	return slices.Contains(a.Scopes, scope)
+}
+
+// CanEditBooks reports whether a may create, change and delete books.
+func (a Actor) CanEditBooks() bool {
+	return a.HasScope(ScopeEditor) || a.HasScope(ScopeAdmin)
+}
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx carrying a.
+func WithActor(ctx context.Context, a Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, a)
+}
+
+// ActorFrom returns the actor stored in ctx; ok is false for anonymous
+// requests and background jobs.
+func ActorFrom(ctx context.Context) (a Actor, ok bool) {
+	a, ok = ctx.Value(actorKey{}).(Actor)
+	return a, ok
+}
@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// Alias is an alternate title of a book: a subtitle, the original-language
+// title, a series name and so on.
+// swagger:model Alias
+type Alias struct {
+	ID        int64     `db:"id" json:"id"`
+	BookID    int64     `db:"book_id" json:"book_id"`
+	Alias     string    `db:"alias" json:"alias"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
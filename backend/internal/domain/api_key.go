@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// APIKey lets a client call the API without going through the identity
+// proxy. Only a hash of the key is stored; the key itself is shown once,
+// when it is issued.
+// swagger:model APIKey
+type APIKey struct {
+	ID   int64  `db:"id" json:"id"`
+	Name string `db:"name" json:"name" example:"warehouse-sync"`
+	Hash string `db:"key_hash" json:"-"`
+	// Scopes are granted to requests made with the key, e.g. admin.
+	Scopes    ScopeList  `db:"scopes" json:"scopes" swaggertype:"array,string"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	RevokedAt *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+}
+
+// ScopeList is a set of scopes persisted as a comma separated column.
+type ScopeList []string
+
+func (s ScopeList) Value() (driver.Value, error) {
+	return strings.Join(s, ","), nil
+}
+
+func (s *ScopeList) Scan(src any) error {
+	var v string
+	switch src := src.(type) {
+	case nil:
+	case []byte:
+		v = string(src)
+	case string:
+		v = src
+	default:
+		return fmt.Errorf("ScopeList: unsupported type %T", src)
+	}
+	*s = ScopeList{}
+	for _, scope := range strings.Split(v, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			*s = append(*s, scope)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Ops of audit entries.
+const (
+	AuditCreated = "created"
+	AuditUpdated = "updated"
+	AuditDeleted = "deleted"
+)
+
+// AuditEntry records one mutation of a book: who made it, when, and the
+// fields it changed with their values before and after. Unlike the change
+// log it is never compacted, and it outlives the book.
+// swagger:model AuditEntry
+type AuditEntry struct {
+	ID     int64  `db:"id" json:"id"`
+	BookID int64  `db:"book_id" json:"book_id"`
+	Op     string `db:"op" json:"op" enums:"created,updated,deleted"`
+	// Actor made the change; ImpersonatedBy is the admin acting as them.
+	Actor          string    `db:"actor" json:"actor,omitempty"`
+	ImpersonatedBy string    `db:"impersonated_by" json:"impersonated_by,omitempty"`
+	Changes        AuditDiff `db:"changes" json:"changes"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
+
+// FieldChange is a field's value before and after a mutation; null where
+// the book didn't exist or the field was unset.
+type FieldChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// AuditDiff maps the changed fields of a book, by their JSON names, to how
+// they changed. It is persisted as a JSON column.
+type AuditDiff map[string]FieldChange
+
+// auditedFields returns the fields of b an audit entry compares, nil ones
+// for a nil b.
+func auditedFields(b *Book) map[string]any {
+	if b == nil {
+		return map[string]any{}
+	}
+	f := map[string]any{
+		"title":            b.Title,
+		"author":           b.Author,
+		"isbn":             b.ISBN,
+		"price":            b.Price,
+		"publication_year": b.PublicationYear,
+		"format":           b.Format,
+	}
+	for name, id := range map[string]*int64{"publisher_id": b.PublisherID, "series_id": b.SeriesID, "work_id": b.WorkID} {
+		if id != nil {
+			f[name] = *id
+		}
+	}
+	if b.SeriesPosition != nil {
+		f["series_position"] = *b.SeriesPosition
+	}
+	return f
+}
+
+// DiffBooks returns the audited fields that differ between before and
+// after. A nil before is a create and a nil after a delete, so every field
+// of the other book is in the diff.
+func DiffBooks(before, after *Book) AuditDiff {
+	old, cur := auditedFields(before), auditedFields(after)
+	diff := AuditDiff{}
+	for name, v := range cur {
+		if old[name] != v {
+			diff[name] = FieldChange{Old: old[name], New: v}
+		}
+	}
+	for name, v := range old {
+		if _, ok := cur[name]; !ok {
+			diff[name] = FieldChange{Old: v}
+		}
+	}
+	return diff
+}
+
+func (d AuditDiff) Value() (driver.Value, error) {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (d *AuditDiff) Scan(src any) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*d = AuditDiff{}
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("audit diff: unsupported type %T", src)
+	}
+	return json.Unmarshal(b, d)
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffBooks(t *testing.T) {
+	series := int64(3)
+	before := &Book{Title: "Dune", Author: "Frank Herbert", ISBN: "9780441013593", Price: 10, PublicationYear: 1965, SeriesID: &series}
+	after := *before
+	after.Price, after.SeriesID, after.Format = 12.5, nil, "ebook"
+
+	want := AuditDiff{
+		"price":     {Old: 10.0, New: 12.5},
+		"series_id": {Old: int64(3)},
+		"format":    {Old: "", New: "ebook"},
+	}
+	if got := DiffBooks(before, &after); !reflect.DeepEqual(got, want) {
+		t.Fatalf("update = %v; want %v", got, want)
+	}
+	if got := DiffBooks(before, before); len(got) != 0 {
+		t.Fatalf("no change = %v", got)
+	}
+
+	created := DiffBooks(nil, before)
+	if len(created) != 7 || created["title"] != (FieldChange{New: "Dune"}) {
+		t.Fatalf("create = %v", created)
+	}
+	deleted := DiffBooks(before, nil)
+	if len(deleted) != 7 || deleted["series_id"] != (FieldChange{Old: int64(3)}) {
+		t.Fatalf("delete = %v", deleted)
+	}
+}
+
+func TestAuditDiff_RoundTrip(t *testing.T) {
+	v, err := AuditDiff{"price": {Old: 10.0, New: 12.5}}.Value()
+	if err != nil || v != `{"price":{"old":10,"new":12.5}}` {
+		t.Fatalf("Value = %v, %v", v, err)
+	}
+	var d AuditDiff
+	if err := d.Scan([]byte(v.(string))); err != nil || d["price"] != (FieldChange{Old: 10.0, New: 12.5}) {
+		t.Fatalf("Scan = %v, %v", d, err)
+	}
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// AuthorSlug identifies an author by name: "Gabriel García Márquez" →
+// "gabriel-garcia-marquez". Spellings that differ only in case, accents or
+// punctuation share a slug.
+func AuthorSlug(name string) string {
+	return slug(name)
+}
+
+// slug lowercases s, strips accents and joins its runs of letters and
+// digits with dashes.
+func slug(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range SearchKey(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	return b.String()
+}
+
+// AuthorSummary is the precomputed read model behind an author page.
+// swagger:model AuthorSummary
+type AuthorSummary struct {
+	ID        string `json:"id" example:"gabriel-garcia-marquez"`
+	Name      string `json:"name" example:"Gabriel García Márquez"`
+	BookCount int    `json:"book_count"`
+	// FirstYear and LastYear span the known publication years; nil when no
+	// book has one.
+	FirstYear *int         `json:"first_year"`
+	LastYear  *int         `json:"last_year"`
+	MinPrice  float64      `json:"min_price"`
+	MaxPrice  float64      `json:"max_price"`
+	Books     []AuthorBook `json:"books"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// AuthorBook is a book as listed on its author's page.
+type AuthorBook struct {
+	ID              int64   `json:"id"`
+	Title           string  `json:"title"`
+	ISBN            string  `json:"isbn"`
+	PublicationYear int     `json:"publication_year"`
+	Price           float64 `json:"price"`
+}
+
+// SummarizeAuthor aggregates books (all by the author with slug id, in any
+// order) into a summary. Books are listed by publication year, then id; the
+// display name is taken from the newest book.
+func SummarizeAuthor(id string, books []Book, now time.Time) AuthorSummary {
+	s := AuthorSummary{ID: id, BookCount: len(books), Books: make([]AuthorBook, 0, len(books)), UpdatedAt: now}
+	if len(books) == 0 {
+		return s
+	}
+	s.MinPrice, s.MaxPrice = math.Inf(1), math.Inf(-1)
+	var newest int64
+	for _, b := range books {
+		if b.ID > newest {
+			newest, s.Name = b.ID, b.Author
+		}
+		s.MinPrice = min(s.MinPrice, b.Price)
+		s.MaxPrice = max(s.MaxPrice, b.Price)
+		if y := b.PublicationYear; y > 0 {
+			if s.FirstYear == nil || y < *s.FirstYear {
+				s.FirstYear = &y
+			}
+			if s.LastYear == nil || y > *s.LastYear {
+				s.LastYear = &y
+			}
+		}
+		s.Books = append(s.Books, AuthorBook{
+			ID: b.ID, Title: b.Title, ISBN: b.ISBN, PublicationYear: b.PublicationYear, Price: b.Price,
+		})
+	}
+	slices.SortFunc(s.Books, func(a, b AuthorBook) int {
+		if c := cmp.Compare(a.PublicationYear, b.PublicationYear); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return s
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAuthorSlug(t *testing.T) {
+	cases := map[string]string{
+		"Gabriel García Márquez": "gabriel-garcia-marquez",
+		"  J.R.R. Tolkien ":      "j-r-r-tolkien",
+		"Flann O'Brien":          "flann-o-brien",
+		"Ægir Øster":             "aegir-oster",
+		"---":                    "",
+	}
+	for in, want := range cases {
+		if got := AuthorSlug(in); got != want {
+			t.Errorf("AuthorSlug(%q) = %q; want %q", in, got, want)
+		}
+	}
+}
+
+func TestSummarizeAuthor(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	books := []Book{
+		{ID: 3, Title: "Love in the Time of Cholera", Author: "Gabriel García Márquez", PublicationYear: 1985, Price: 14},
+		{ID: 1, Title: "Cien años de soledad", Author: "Gabriel Garcia Marquez", PublicationYear: 1967, Price: 18.5},
+		{ID: 2, Title: "Unknown year", Author: "G. García Márquez", Price: 9.99},
+	}
+
+	s := SummarizeAuthor("gabriel-garcia-marquez", books, now)
+	if s.Name != "Gabriel García Márquez" || s.BookCount != 3 {
+		t.Fatalf("name/count = %q/%d", s.Name, s.BookCount)
+	}
+	if *s.FirstYear != 1967 || *s.LastYear != 1985 {
+		t.Fatalf("years = %d..%d", *s.FirstYear, *s.LastYear)
+	}
+	if s.MinPrice != 9.99 || s.MaxPrice != 18.5 {
+		t.Fatalf("prices = %v..%v", s.MinPrice, s.MaxPrice)
+	}
+	if s.Books[0].ID != 2 || s.Books[1].ID != 1 || s.Books[2].ID != 3 {
+		t.Fatalf("order = %+v", s.Books)
+	}
+}
+
+func TestSummarizeAuthor_Empty(t *testing.T) {
+	s := SummarizeAuthor("nobody", nil, time.Now())
+	if s.BookCount != 0 || s.Books == nil || s.FirstYear != nil || s.MinPrice != 0 {
+		t.Fatalf("summary = %+v", s)
+	}
+}
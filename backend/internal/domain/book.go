@@ -8,9 +8,38 @@ type Book struct {
 	ID              int64     `db:"id" json:"id"`
 	Title           string    `db:"title" json:"title"`
 	Author          string    `db:"author" json:"author"`
-	ISBN            string    `db:"isbn" json:"isbn"`
+	ISBN            string    `db:"isbn" json:"isbn"`               // ISBN-13, also for books created with an ISBN-10
+	ISBN10          string    `db:"isbn10" json:"isbn10,omitempty"` // empty for ISBN-13s starting with 979, which have none
 	Price           float64   `db:"price" json:"price"`
 	PublicationYear int       `db:"publication_year" json:"publication_year"`
 	CreatedAt       time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt       time.Time `db:"updated_at" json:"updated_at"`
+	// Version starts at 1 and goes up by one with every write.
+	Version int64    `db:"version" json:"version"`
+	Aliases []string `db:"-" json:"aliases"`
+	// Categories are the slugs the book is filed under, sorted.
+	Categories []string `db:"-" json:"categories"`
+	// Tags are the book's free-form tags, sorted.
+	Tags []string `db:"-" json:"tags"`
+	// WorkID groups editions of the same work; nil for a standalone book.
+	WorkID *int64 `db:"work_id" json:"work_id,omitempty"`
+	// PublisherID is the book's publisher; nil when it has none.
+	PublisherID *int64 `db:"publisher_id" json:"publisher_id,omitempty"`
+	// SeriesID is the series the book belongs to; nil when it has none.
+	SeriesID *int64 `db:"series_id" json:"series_id,omitempty"`
+	// SeriesPosition is the book's place in its series, from 1; nil when
+	// unknown.
+	SeriesPosition *int `db:"series_position" json:"series_position,omitempty"`
+	// Format tells the editions of a work apart: one of BookFormats, or
+	// empty when unknown.
+	Format string `db:"format" json:"format,omitempty" enums:"hardcover,paperback,ebook"`
+
+	FieldUpdatedAt FieldTimes `db:"field_updated_at" json:"-"`
 }
+
+// BookFields lists the editable fields, by JSON name.
+var BookFields = []string{"title", "author", "isbn", "price", "publication_year", "publisher_id",
+	"series_id", "series_position", "format", "work_id"}
+
+// BookFormats are the formats an edition can have.
+var BookFormats = []string{"hardcover", "paperback", "ebook"}
@@ -0,0 +1,14 @@
+package domain
+
+// BookMetadata is what an external catalogue knows about an ISBN, used to
+// fill in a new book.
+// swagger:model BookMetadata
+type BookMetadata struct {
+	ISBN            string `json:"isbn" example:"9780132350884"`
+	Title           string `json:"title" example:"Clean Code"`
+	Author          string `json:"author,omitempty" example:"Robert C. Martin"`
+	PublicationYear int    `json:"publication_year,omitempty" example:"2008"`
+	// Source names the catalogue the title came from: openlibrary or
+	// googlebooks.
+	Source string `json:"source" example:"openlibrary"`
+}
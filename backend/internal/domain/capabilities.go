@@ -0,0 +1,51 @@
+package domain
+
+// Capabilities describe what this deployment of the API offers, so clients
+// adapt to it instead of hardcoding environment differences.
+// swagger:model Capabilities
+type Capabilities struct {
+	Version string `json:"version" example:"1.4.0+3fff8f0"`
+	// Features lists the optional features enabled here, sorted, such as
+	// change_feed, saved_searches or url_resolve.
+	Features   []string               `json:"features" example:"change_feed,saved_searches,url_resolve"`
+	Search     SearchCapabilities     `json:"search"`
+	Auth       AuthCapabilities       `json:"auth"`
+	Pagination PaginationCapabilities `json:"pagination"`
+	Export     ExportCapabilities     `json:"export"`
+}
+
+// SearchCapabilities describe book search.
+type SearchCapabilities struct {
+	// Backend is where searches run: "mysql", or "memory" in demo mode.
+	Backend    string   `json:"backend" example:"mysql"`
+	SortFields []string `json:"sort_fields" example:"id,title,author,price,publication_year"`
+	Collations []string `json:"collations" example:"de,sv"`
+}
+
+// AuthCapabilities describe how callers identify themselves.
+type AuthCapabilities struct {
+	// Modes are the accepted identities: "proxy_headers" (X-User from an
+	// authenticating proxy), "api_key" (X-API-Key) and "bearer" (tokens
+	// from POST /auth/login).
+	Modes []string `json:"modes" example:"api_key,bearer"`
+	// APIKeyRequired is "writes" or "all" when book requests need an API
+	// key, empty when they don't.
+	APIKeyRequired string `json:"api_key_required" example:"writes"`
+	// Roles is set when only editors and admins may change books.
+	Roles bool `json:"roles"`
+}
+
+// PaginationCapabilities describe paged lists.
+type PaginationCapabilities struct {
+	// MaxPageSize is the largest limit a list accepts; 0 means no maximum.
+	MaxPageSize int `json:"max_page_size" example:"200"`
+	// DefaultPageSize is the page size when a paged request sends no
+	// limit; 0 means the whole list.
+	DefaultPageSize int `json:"default_page_size" example:"50"`
+}
+
+// ExportCapabilities list the book file formats.
+type ExportCapabilities struct {
+	Formats       []string `json:"formats" example:"csv,xlsx"`
+	ImportFormats []string `json:"import_formats" example:"csv,json"`
+}
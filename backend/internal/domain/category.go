@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// MaxCategoryLen is the longest category slug, as stored.
+const MaxCategoryLen = 64
+
+// CategorySlug files a category under a slug: "Science Fiction" →
+// "science-fiction", so spellings differing in case, accents or spacing
+// name the same category.
+func CategorySlug(name string) string {
+	return slug(name)
+}
+
+// Bulk job statuses. A job is running until it ends in one of the others;
+// an interrupted job stopped with the process and can be run again, as its
+// changes are idempotent.
+const (
+	JobRunning     = "running"
+	JobDone        = "done"
+	JobFailed      = "failed"
+	JobInterrupted = "interrupted"
+)
+
+// BulkJob is the progress of a bulk operation running in the background.
+// swagger:model BulkJob
+type BulkJob struct {
+	ID   string `db:"id" json:"id" example:"9f2c4e0a1b7d4c1e8a3f5b6d7e8f9a0b"`
+	Kind string `db:"kind" json:"kind" example:"bulk_tag"`
+	// Status is running, done, failed or interrupted.
+	Status string `db:"status" json:"status" example:"running"`
+	// Total books to change, and how many are done.
+	Total int `db:"total" json:"total" example:"1200"`
+	Done  int `db:"done" json:"done" example:"400"`
+	// Error is why a failed job stopped.
+	Error     string    `db:"error" json:"error,omitempty"`
+	Actor     string    `db:"actor" json:"actor,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
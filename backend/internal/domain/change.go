@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Change operations recorded in the books change log.
+const (
+	ChangeCreated = "created"
+	ChangeUpdated = "updated"
+	ChangeDeleted = "deleted"
+)
+
+// EntityBook is the entity of changes to a book (including its aliases).
+const EntityBook = "book"
+
+// Change is one entry of the books change log. ID is monotonic and doubles as
+// the sync cursor handed to clients.
+// swagger:model Change
+type Change struct {
+	ID     int64  `db:"id" json:"cursor"`
+	Entity string `db:"entity" json:"entity"`
+	BookID int64  `db:"book_id" json:"book_id"`
+	Op     string `db:"op" json:"op"`
+	// Version numbers the changes of one entity, starting at 1.
+	Version int64 `db:"version" json:"version"`
+	// Payload is the entity right after the change; absent for deletes.
+	Payload ChangePayload `db:"payload" json:"payload,omitempty" swaggertype:"object"`
+	// Actor made the change; ImpersonatedBy is the admin acting as them.
+	Actor          string    `db:"actor" json:"actor,omitempty"`
+	ImpersonatedBy string    `db:"impersonated_by" json:"impersonated_by,omitempty"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
+
+// Book decodes the payload of a book change. It returns nil for deletes and
+// for entries written before payloads were recorded.
+func (c Change) Book() (*Book, error) {
+	if len(c.Payload) == 0 {
+		return nil, nil
+	}
+	var b Book
+	if err := json.Unmarshal(c.Payload, &b); err != nil {
+		return nil, fmt.Errorf("change %d: %w", c.ID, err)
+	}
+	return &b, nil
+}
+
+// ChangePayload is a JSON snapshot persisted as a JSON column.
+type ChangePayload []byte
+
+// NewChangePayload encodes v; a nil v (or nil pointer) gives an empty payload.
+func NewChangePayload(v any) (ChangePayload, error) {
+	b, err := json.Marshal(v)
+	if err != nil || string(b) == "null" {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (p ChangePayload) MarshalJSON() ([]byte, error) {
+	if len(p) == 0 {
+		return []byte("null"), nil
+	}
+	return p, nil
+}
+
+func (p *ChangePayload) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*p = nil
+		return nil
+	}
+	*p = append((*p)[:0], b...)
+	return nil
+}
+
+func (p ChangePayload) Value() (driver.Value, error) {
+	if len(p) == 0 {
+		return nil, nil
+	}
+	return string(p), nil
+}
+
+func (p *ChangePayload) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*p = nil
+		return nil
+	case []byte:
+		*p = append(ChangePayload(nil), v...)
+		return nil
+	case string:
+		*p = ChangePayload(v)
+		return nil
+	}
+	return fmt.Errorf("ChangePayload: unsupported type %T", src)
+}
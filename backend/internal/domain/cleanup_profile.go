@@ -0,0 +1,12 @@
+package domain
+
+// CleanupProfile is a named canonical form: the cleanup rules applied to a
+// URL, in order. Teams configure their own (an SEO canonical, a cache key,
+// an analytics key) next to the built-in operations. See pkg/urlclean.
+type CleanupProfile struct {
+	Name        string   `json:"name" example:"cache_key"`
+	Description string   `json:"description,omitempty" example:"One key per page for the CDN cache"`
+	Rules       []string `json:"rules" example:"lowercase_host,strip_tracking_params,sort_query,drop_fragment"`
+	HTTPSHosts  []string `json:"https_hosts,omitempty" example:"blog.example.com"` // upgrade_https only upgrades these hosts; empty means all
+	BuiltIn     bool     `json:"built_in"`
+}
@@ -0,0 +1,50 @@
+package domain
+
+import "time"
+
+// Outcomes of a URL cleanup request, as counted by the cleanup stats.
+const (
+	CleanupOK          = "ok"
+	CleanupInvalid     = "invalid"     // rejected with 422
+	CleanupUnreachable = "unreachable" // resolve couldn't reach the URL
+	CleanupFailed      = "failed"      // the server failed with 500
+)
+
+// CleanupUsageKey is what cleanup requests are counted by.
+type CleanupUsageKey struct {
+	Day       time.Time // midnight UTC
+	Domain    string    // lower-cased host; empty when the URL didn't parse
+	Operation string    // the operation or profile
+	Outcome   string
+}
+
+// CleanupUsage is the number of cleanup requests with one domain, operation
+// and outcome.
+type CleanupUsage struct {
+	Domain    string `db:"domain"`
+	Operation string `db:"operation"`
+	Outcome   string `db:"outcome"`
+	Requests  int64  `db:"requests"`
+}
+
+// CleanupStats summarizes the cleanup requests made since a day.
+type CleanupStats struct {
+	Since     time.Time `json:"since"`
+	Requests  int64     `json:"requests"`
+	Errors    int64     `json:"errors"`
+	ErrorRate float64   `json:"error_rate"`
+	// TopDomains are the most cleaned domains, busiest first.
+	TopDomains []CleanupCount `json:"top_domains"`
+	Operations []CleanupCount `json:"operations"`
+	// Rules counts the requests that applied each cleanup rule.
+	Rules []CleanupCount `json:"rules"`
+}
+
+// CleanupCount is the share of cleanup requests of one domain, operation or
+// rule.
+type CleanupCount struct {
+	Name      string  `json:"name" example:"example.com"`
+	Requests  int64   `json:"requests"`
+	Errors    int64   `json:"errors"`
+	ErrorRate float64 `json:"error_rate"`
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// DeadLetter is a piece of async work (a delivery, a publish, a job run)
+// that failed and was parked so an operator can inspect, retry or discard it
+// instead of it being lost.
+// swagger:model DeadLetter
+type DeadLetter struct {
+	ID int64 `db:"id" json:"id"`
+	// Kind names the work, and with it the handler that retries it.
+	Kind string `db:"kind" json:"kind" example:"search_notification"`
+	// Payload is whatever the handler needs to run the work again.
+	Payload ChangePayload `db:"payload" json:"payload" swaggertype:"object"`
+	// Error is the reason the latest attempt failed.
+	Error         string    `db:"error" json:"error"`
+	Attempts      int       `db:"attempts" json:"attempts"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+	LastAttemptAt time.Time `db:"last_attempt_at" json:"last_attempt_at"`
+}
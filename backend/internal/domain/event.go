@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// Event is a change log entry as integrations consume it: a domain event
+// named after its entity and operation, such as book.updated. Offset is the
+// change's cursor.
+// swagger:model Event
+type Event struct {
+	Offset   int64  `json:"offset"`
+	Type     string `json:"type" example:"book.updated"`
+	Entity   string `json:"entity" example:"book"`
+	EntityID int64  `json:"entity_id"`
+	// Version numbers the events of one entity, starting at 1.
+	Version int64 `json:"version"`
+	// Data is the entity right after the event; absent for deletes.
+	Data           ChangePayload `json:"data,omitempty" swaggertype:"object"`
+	Actor          string        `json:"actor,omitempty"`
+	ImpersonatedBy string        `json:"impersonated_by,omitempty"`
+	OccurredAt     time.Time     `json:"occurred_at"`
+}
+
+// EventOf returns the event change c records.
+func EventOf(c Change) Event {
+	entity := c.Entity
+	if entity == "" {
+		entity = EntityBook // entries from before entities were recorded
+	}
+	return Event{
+		Offset:         c.ID,
+		Type:           entity + "." + c.Op,
+		Entity:         entity,
+		EntityID:       c.BookID,
+		Version:        c.Version,
+		Data:           c.Payload,
+		Actor:          c.Actor,
+		ImpersonatedBy: c.ImpersonatedBy,
+		OccurredAt:     c.CreatedAt,
+	}
+}
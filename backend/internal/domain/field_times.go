@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// FieldTimes holds the last modification time per book field (keyed by JSON
+// name). It is persisted as a JSON column.
+type FieldTimes map[string]time.Time
+
+// Touch marks fields as modified at t. Times are kept at microsecond
+// precision to match the DATETIME(6) columns they are compared with.
+func (f *FieldTimes) Touch(t time.Time, fields ...string) {
+	if *f == nil {
+		*f = FieldTimes{}
+	}
+	for _, name := range fields {
+		(*f)[name] = t.Round(time.Microsecond)
+	}
+}
+
+func (f FieldTimes) Value() (driver.Value, error) {
+	if f == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(map[string]time.Time(f))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (f *FieldTimes) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*f = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, (*map[string]time.Time)(f))
+	case string:
+		return json.Unmarshal([]byte(v), (*map[string]time.Time)(f))
+	}
+	return fmt.Errorf("FieldTimes: unsupported type %T", src)
+}
@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// Hold statuses. A hold waits in its book's queue until the book comes back
+// and is lent to the hold's user, or until it is cancelled.
+const (
+	HoldWaiting   = "waiting"
+	HoldFulfilled = "fulfilled"
+	HoldCancelled = "cancelled"
+)
+
+// Hold reserves a book that is out on loan for a user. Returned books go to
+// the oldest waiting hold first.
+// swagger:model Hold
+type Hold struct {
+	ID       int64     `db:"id" json:"id"`
+	BookID   int64     `db:"book_id" json:"book_id"`
+	UserID   int64     `db:"user_id" json:"user_id"`
+	Status   string    `db:"status" json:"status" enums:"waiting,fulfilled,cancelled"`
+	PlacedAt time.Time `db:"placed_at" json:"placed_at"`
+	// Position is the hold's place in its book's queue, 1 being next; 0
+	// once the hold is no longer waiting.
+	Position int `db:"position" json:"position"`
+	// LoanID is the loan that fulfilled the hold.
+	LoanID *int64 `db:"loan_id" json:"loan_id,omitempty"`
+	// ClosedAt is when the hold was fulfilled or cancelled.
+	ClosedAt *time.Time `db:"closed_at" json:"closed_at,omitempty"`
+}
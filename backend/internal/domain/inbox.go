@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// InboxMessage records that an upstream message was consumed and what it did:
+// the book operation applied, or the error that made it unprocessable.
+type InboxMessage struct {
+	Source      string    `db:"source" json:"source"`
+	MessageID   string    `db:"message_id" json:"message_id"`
+	Op          string    `db:"op" json:"op"` // a Change* op, or "" when nothing was applied
+	BookID      *int64    `db:"book_id" json:"book_id,omitempty"`
+	Error       string    `db:"error" json:"error,omitempty"`
+	ProcessedAt time.Time `db:"processed_at" json:"processed_at"`
+}
@@ -0,0 +1,9 @@
+package domain
+
+import "time"
+
+// Leader is the replica that won an election and when it did.
+type Leader struct {
+	Identity  string    `json:"identity" db:"identity"`
+	ElectedAt time.Time `json:"elected_at" db:"elected_at"`
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// ListPreferences are a tenant's defaults for book lists: the order and
+// page size of GET /books/ requests that don't set their own. The tenant is
+// the actor the requests run as, an API key (key:NAME) or a user, so a
+// storefront and an admin UI calling with different identities get
+// different defaults.
+// swagger:model ListPreferences
+type ListPreferences struct {
+	Tenant string `db:"tenant" json:"tenant" example:"key:storefront"`
+	// Sort is a sort field, prefixed with - for descending; empty keeps
+	// newest first.
+	Sort      string `db:"sort" json:"sort" example:"-publication_year"`
+	Collation string `db:"collation" json:"collation" example:""`
+	// PageSize is the limit of lists that send none; 0 keeps the server's.
+	PageSize  int       `db:"page_size" json:"page_size" example:"24"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
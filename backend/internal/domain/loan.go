@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// Loan lends a book to a user until it is returned. A book is out on at
+// most one loan at a time.
+// swagger:model Loan
+type Loan struct {
+	ID           int64     `db:"id" json:"id"`
+	BookID       int64     `db:"book_id" json:"book_id"`
+	UserID       int64     `db:"user_id" json:"user_id"`
+	CheckedOutAt time.Time `db:"checked_out_at" json:"checked_out_at"`
+	DueAt        time.Time `db:"due_at" json:"due_at"`
+	// ReturnedAt is nil while the book is out.
+	ReturnedAt *time.Time `db:"returned_at" json:"returned_at,omitempty"`
+}
+
+// Overdue reports whether the book is still out after its due date.
+func (l Loan) Overdue(now time.Time) bool {
+	return l.ReturnedAt == nil && now.After(l.DueAt)
+}
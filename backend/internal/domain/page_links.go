@@ -0,0 +1,22 @@
+package domain
+
+// Sources of PageLinks.BestGuess, from most to least trusted.
+const (
+	LinkSourceCanonical = "canonical"
+	LinkSourceOGURL     = "og_url"
+	LinkSourceFinalURL  = "final_url"
+)
+
+// PageLinks are the hints a web page gives about its own canonical URL,
+// and the URL they add up to.
+type PageLinks struct {
+	URL       string   `json:"url" example:"https://bit.ly/3xyz"`
+	FinalURL  string   `json:"final_url" example:"https://www.example.com/books/dune?utm_source=x"` // after redirects
+	Canonical string   `json:"canonical,omitempty" example:"https://www.example.com/books/dune"`    // <link rel="canonical">
+	OGURL     string   `json:"og_url,omitempty" example:"https://www.example.com/books/dune"`       // <meta property="og:url">
+	Sitemaps  []string `json:"sitemaps" example:"https://www.example.com/sitemap.xml"`              // <link rel="sitemap"> and robots.txt Sitemap lines
+	// BestGuess is the most trusted of canonical, og_url and final_url that
+	// is an http(s) URL, normalized by the canonical cleanup operation.
+	BestGuess string `json:"best_guess" example:"https://www.example.com/books/dune"`
+	Source    string `json:"source" enums:"canonical,og_url,final_url" example:"canonical"`
+}
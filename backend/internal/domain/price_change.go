@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// PriceChange is one entry of a book's price history. Reason is the reason
+// of the re-price that made it, or "update" for an edit of the book.
+// swagger:model PriceChange
+type PriceChange struct {
+	ID       int64   `db:"id" json:"id"`
+	BookID   int64   `db:"book_id" json:"book_id"`
+	OldPrice float64 `db:"old_price" json:"old_price"`
+	NewPrice float64 `db:"new_price" json:"new_price"`
+	Reason   string  `db:"reason" json:"reason"`
+	BatchID  *string `db:"batch_id" json:"batch_id,omitempty"`
+	// ChangedBy is the actor who changed the price; empty for anonymous
+	// requests and background jobs.
+	ChangedBy string    `db:"changed_by" json:"changed_by,omitempty"`
+	ChangedAt time.Time `db:"changed_at" json:"changed_at"`
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// Publisher is a publishing house books can be filed under.
+// swagger:model Publisher
+type Publisher struct {
+	ID   int64  `db:"id" json:"id"`
+	Name string `db:"name" json:"name" example:"Ace Books"`
+	// Country is an ISO 3166-1 alpha-2 code, empty when unknown.
+	Country string `db:"country" json:"country" example:"US"`
+	// Website is an http(s) URL, empty when unknown.
+	Website   string    `db:"website" json:"website" example:"https://www.penguinrandomhouse.com/ace"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
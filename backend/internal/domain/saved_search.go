@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// SavedSearch is a named book query kept for later, written in the search
+// query language (`author:"Le Guin" year:1960..1975 dragons`).
+// swagger:model SavedSearch
+type SavedSearch struct {
+	ID int64 `db:"id" json:"id"`
+	// Owner is the actor who saved the search, the only one who sees it.
+	Owner string `db:"owner" json:"owner" example:"ann@example.com"`
+	Name  string `db:"name" json:"name"`
+	Query string `db:"query" json:"query"`
+	// Notify asks for a notification whenever a new book matches.
+	Notify    bool      `db:"notify" json:"notify"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// SearchNotification records that a newly added book matched a saved search.
+// swagger:model SearchNotification
+type SearchNotification struct {
+	ID        int64     `db:"id" json:"id"`
+	SearchID  int64     `db:"search_id" json:"search_id"`
+	BookID    int64     `db:"book_id" json:"book_id"`
+	Title     string    `db:"title" json:"title"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
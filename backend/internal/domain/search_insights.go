@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// MaxSearchQueryLen is the longest search query key counted; longer queries
+// are counted by their start.
+const MaxSearchQueryLen = 191
+
+// SearchUsageKey is what searches are counted by.
+type SearchUsageKey struct {
+	Day   time.Time // midnight UTC
+	Query string    // the query folded by SearchKey
+}
+
+// SearchCounts are the counters kept per search query.
+type SearchCounts struct {
+	Searches    int64 `db:"searches" json:"searches"`
+	ZeroResults int64 `db:"zero_results" json:"zero_results"`
+	Clicks      int64 `db:"clicks" json:"clicks"`
+}
+
+// SearchQueryCount is the usage of one search query.
+type SearchQueryCount struct {
+	Query string `db:"query_key" json:"query" example:"garcia marquez"`
+	SearchCounts
+	ClickThroughRate float64 `db:"-" json:"click_through_rate"`
+}
+
+// SearchInsights summarizes the searches made since a day.
+type SearchInsights struct {
+	Since time.Time `json:"since"`
+	SearchCounts
+	ZeroResultRate   float64 `json:"zero_result_rate"`
+	ClickThroughRate float64 `json:"click_through_rate"`
+	// TopQueries are the most searched queries, busiest first.
+	TopQueries []SearchQueryCount `json:"top_queries"`
+	// ZeroResultQueries are the queries that most often found nothing,
+	// the books the catalogue is missing.
+	ZeroResultQueries []SearchQueryCount `json:"zero_result_queries"`
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// letters that don't decompose into base + combining mark
+var foldLetters = strings.NewReplacer(
+	"ß", "ss", "æ", "ae", "œ", "oe", "ø", "o", "ł", "l", "đ", "d", "ð", "d", "þ", "th", "ı", "i",
+)
+
+// SearchKey folds s for accent- and case-insensitive matching:
+// "García  Márquez" → "garcia marquez". Keys are stored next to the
+// searchable columns and search terms go through the same function.
+func SearchKey(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		folded = s
+	}
+	folded = foldLetters.Replace(strings.ToLower(folded))
+	return strings.Join(strings.Fields(folded), " ")
+}
+
+// ISBNKey drops the spaces and hyphens from an ISBN and upper-cases the
+// check digit X, the form ISBNs are stored in.
+func ISBNKey(s string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.ReplaceAll(s, " ", ""), "-", ""))
+}
@@ -0,0 +1,19 @@
+package domain
+
+import "testing"
+
+func TestSearchKey(t *testing.T) {
+	cases := map[string]string{
+		"García Márquez":     "garcia marquez",
+		"  Cien   años\tde ": "cien anos de",
+		"Ærø Straße":         "aero strasse",
+		"Łódź":               "lodz",
+		"ÉCOLE":              "ecole",
+		"Clean Code":         "clean code",
+	}
+	for in, want := range cases {
+		if got := SearchKey(in); got != want {
+			t.Fatalf("SearchKey(%q) = %q; want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// MaxRankingWeight bounds each field of RankingWeights.
+const MaxRankingWeight = 10
+
+// RankingWeights order the books a search matches. A book scores
+// TitleWeight when the query is in its title or an alias, AuthorWeight when
+// it is in its author, and RecencyBoost divided by one plus its age in
+// years; higher scores come first and equal scores go newest first.
+type RankingWeights struct {
+	TitleWeight  float64 `db:"title_weight" json:"title_weight" example:"2"`
+	AuthorWeight float64 `db:"author_weight" json:"author_weight" example:"1"`
+	RecencyBoost float64 `db:"recency_boost" json:"recency_boost" example:"0.5"`
+}
+
+// DefaultRankingWeights rank tenants that have no ranking of their own:
+// title matches before author matches, without favouring recent books.
+func DefaultRankingWeights() RankingWeights {
+	return RankingWeights{TitleWeight: 2, AuthorWeight: 1}
+}
+
+// SearchRanking is a tenant's ranking. Like ListPreferences, the tenant is
+// the actor searches run as, an API key (key:NAME) or a user.
+// swagger:model SearchRanking
+type SearchRanking struct {
+	Tenant string `db:"tenant" json:"tenant" example:"key:storefront"`
+	RankingWeights
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
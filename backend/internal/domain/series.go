@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// Series is a sequence of works, such as a trilogy; its books are ordered
+// by their SeriesPosition.
+// swagger:model Series
+type Series struct {
+	ID        int64     `db:"id" json:"id"`
+	Name      string    `db:"name" json:"name" example:"The Expanse"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
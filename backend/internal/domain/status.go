@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// Dependency states in a SystemStatus.
+const (
+	DependencyUp   = "up"
+	DependencyDown = "down"
+)
+
+// SystemStatus is the health of everything the API depends on, for the ops
+// dashboard. Status is "ok" when every dependency is up, else "degraded".
+// swagger:model SystemStatus
+type SystemStatus struct {
+	Status       string             `json:"status" example:"ok"`
+	CheckedAt    time.Time          `json:"checked_at"`
+	Dependencies []DependencyStatus `json:"dependencies"`
+}
+
+// DependencyStatus is the outcome of one dependency's check. LastError is
+// the most recent failure seen by this replica, kept after the dependency
+// recovers.
+// swagger:model DependencyStatus
+type DependencyStatus struct {
+	Name        string     `json:"name" example:"mysql"`
+	Status      string     `json:"status" example:"up"`
+	LatencyMS   float64    `json:"latency_ms" example:"1.8"`
+	Error       string     `json:"error,omitempty"`
+	LastError   string     `json:"last_error,omitempty" example:"dial tcp 10.0.0.5:3306: connect: connection refused"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// Limits of the synonym dictionary.
+const (
+	MaxSynonymTerms   = 20 // terms in one group
+	MaxSynonymTermLen = 64 // characters of a term
+)
+
+// SynonymGroup is a set of terms searches treat as the same: a search for
+// one also finds books matching any other. Terms match whole words of the
+// query, ignoring case and accents like searches do.
+// swagger:model SynonymGroup
+type SynonymGroup struct {
+	ID        int64     `db:"id" json:"id"`
+	Terms     []string  `db:"-" json:"terms" example:"programming,coding"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,20 @@
+package domain
+
+// Limits of free-form tags.
+const (
+	MaxTagLen   = 64 // characters of a tag
+	MaxBookTags = 50 // tags on one book
+)
+
+// TagKey folds a tag for matching: "Cozy  Mystery" and "cozy mystery" are
+// the same tag.
+func TagKey(name string) string {
+	return SearchKey(name)
+}
+
+// Tag is a free-form label and the number of books carrying it.
+// swagger:model Tag
+type Tag struct {
+	Name  string `db:"name" json:"name" example:"cozy mystery"`
+	Books int    `db:"books" json:"books" example:"12"`
+}
@@ -0,0 +1,27 @@
+package domain
+
+// TaxonomyVersion is the format version of exported taxonomies.
+const TaxonomyVersion = 1
+
+// Taxonomy is the curated vocabulary of the catalogue: the names of the
+// categories books are filed under, and of their authors. Exports list
+// both sorted by slug, so two exports diff cleanly.
+// swagger:model Taxonomy
+type Taxonomy struct {
+	Version    int        `json:"version" example:"1"`
+	Categories []Category `json:"categories"`
+	Authors    []Author   `json:"authors"`
+}
+
+// Category names a category slug, optionally inside a parent category.
+type Category struct {
+	Slug   string `db:"slug" json:"slug" example:"science-fiction"`
+	Name   string `db:"name" json:"name" example:"Science Fiction"`
+	Parent string `db:"parent" json:"parent,omitempty" example:"fiction"`
+}
+
+// Author is the curated display name of the author with slug ID.
+type Author struct {
+	ID   string `db:"id" json:"id" example:"ursula-k-le-guin"`
+	Name string `db:"name" json:"name" example:"Ursula K. Le Guin"`
+}
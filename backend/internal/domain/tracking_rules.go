@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// TrackingRules are a tenant's changes to the tracking parameters the
+// strip_tracking_params cleanup rule removes. The tenant is the actor the
+// cleanup requests run as: an API key (key:NAME) or a user.
+// swagger:model TrackingRules
+type TrackingRules struct {
+	Tenant string `json:"tenant" example:"key:partner-feed"`
+	// Strip lists parameters removed on top of the built-in ones; a
+	// trailing * matches any suffix.
+	Strip []string `json:"strip" example:"ref,src_*"`
+	// Keep lists parameters never removed, even built-in ones.
+	Keep      []string            `json:"keep" example:"utm_id"`
+	Hosts     []HostTrackingRules `json:"hosts"`
+	UpdatedAt time.Time           `json:"updated_at"`
+}
+
+// HostTrackingRules apply on top of the tenant-wide lists to one host and
+// its subdomains.
+// swagger:model HostTrackingRules
+type HostTrackingRules struct {
+	Host  string   `json:"host" example:"shop.example.com"`
+	Strip []string `json:"strip" example:"sessionid"`
+	Keep  []string `json:"keep" example:"ref"`
+}
@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// URLHistoryEntry is one of a user's recent URL cleanups, as listed by the
+// UI's "recent URLs" panel.
+// swagger:model URLHistoryEntry
+type URLHistoryEntry struct {
+	ID           int64     `db:"id" json:"id"`
+	Owner        string    `db:"owner" json:"-"`
+	URL          string    `db:"url" json:"url" example:"https://Example.com/a/?utm_source=x"`
+	ProcessedURL string    `db:"processed_url" json:"processed_url" example:"https://www.example.com/a"`
+	Operation    string    `db:"operation" json:"operation" example:"all"` // or the profile
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// MaxUserNameLen bounds the display name of a user.
+const MaxUserNameLen = 100
+
+// User is an account that logs in with email and password and acts under
+// the JWT it gets back.
+// swagger:model User
+type User struct {
+	ID           int64  `db:"id" json:"id"`
+	Email        string `db:"email" json:"email" example:"ann@example.com"`
+	PasswordHash string `db:"password_hash" json:"-"`
+	// Name is how the user is shown, empty until set.
+	Name string `db:"name" json:"name" example:"Ann Lee"`
+	// Scopes are granted by an operator, e.g. admin; new users have none.
+	Scopes    ScopeList `db:"scopes" json:"scopes" swaggertype:"array,string"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
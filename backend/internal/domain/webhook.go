@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"slices"
+	"time"
+)
+
+// WebhookEvents are the event types webhooks can subscribe to.
+var WebhookEvents = []string{"book.created", "book.deleted", "book.updated"}
+
+// Webhook is an endpoint book events are POSTed to, each delivery signed
+// with the webhook's secret. The secret is only shown when the webhook is
+// registered.
+// swagger:model Webhook
+type Webhook struct {
+	ID     int64  `db:"id" json:"id"`
+	URL    string `db:"url" json:"url" example:"https://hooks.example.com/books"`
+	Secret string `db:"secret" json:"-"`
+	// Events are the event types delivered; empty delivers them all.
+	Events EventTypes `db:"events" json:"events" swaggertype:"array,string"`
+	// Active webhooks get deliveries; inactive ones keep their log.
+	Active    bool      `db:"active" json:"active"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Subscribes reports whether w gets events of type eventType.
+func (w Webhook) Subscribes(eventType string) bool {
+	return w.Active && (len(w.Events) == 0 || slices.Contains(w.Events, eventType))
+}
+
+// EventTypes is a set of event types persisted as a comma separated column.
+type EventTypes []string
+
+func (e EventTypes) Value() (driver.Value, error) { return ScopeList(e).Value() }
+func (e *EventTypes) Scan(src any) error          { return (*ScopeList)(e).Scan(src) }
+
+// Statuses of webhook deliveries.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookDelivery is the delivery of one event to one webhook, with the
+// outcome of its latest attempt. A pending delivery is tried again at
+// NextAttemptAt; one that failed every attempt is dead-lettered.
+// swagger:model WebhookDelivery
+type WebhookDelivery struct {
+	ID          int64  `db:"id" json:"id"`
+	WebhookID   int64  `db:"webhook_id" json:"webhook_id"`
+	EventOffset int64  `db:"event_offset" json:"event_offset"`
+	EventType   string `db:"event_type" json:"event_type" example:"book.updated"`
+	// Payload is the body sent, the Event as JSON.
+	Payload  ChangePayload `db:"payload" json:"-"`
+	Status   string        `db:"status" json:"status" enums:"pending,delivered,failed"`
+	Attempts int           `db:"attempts" json:"attempts"`
+	// ResponseStatus is the HTTP status of the latest attempt; absent when
+	// it got no response.
+	ResponseStatus int        `db:"response_status" json:"response_status,omitempty"`
+	Error          string     `db:"error" json:"error,omitempty"`
+	NextAttemptAt  *time.Time `db:"next_attempt_at" json:"next_attempt_at,omitempty"`
+	LastAttemptAt  *time.Time `db:"last_attempt_at" json:"last_attempt_at,omitempty"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+}
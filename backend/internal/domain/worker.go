@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// Worker states.
+const (
+	WorkerRunning = "running"
+	WorkerPaused  = "paused"
+	// WorkerStalled: no heartbeat for longer than the worker's stall timeout.
+	WorkerStalled = "stalled"
+	// WorkerStopped: the worker returned while the process kept running.
+	WorkerStopped = "stopped"
+)
+
+// WorkerStatus describes one background worker of the serving process.
+// swagger:model WorkerStatus
+type WorkerStatus struct {
+	Name          string    `json:"name" example:"saved_searches"`
+	State         string    `json:"state" enums:"running,paused,stalled,stopped"`
+	LastHeartbeat time.Time `json:"last_heartbeat"`
+	// StallAfter is how long the worker may go without a heartbeat, in seconds.
+	StallAfter int `json:"stall_after"`
+}
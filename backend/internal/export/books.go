@@ -0,0 +1,186 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+	"github.com/gerry-sabar/byfood/internal/ports"
+)
+
+// bookColumns are the CSV columns an import needs, named as in the export.
+var bookColumns = []string{"title", "author", "isbn", "price", "publication_year"}
+
+// WriteBooksCSV writes books in the export's CSV layout.
+func WriteBooksCSV(w io.Writer, books []domain.Book, opts CSVOptions) error {
+	cw, err := NewCSVWriter(w, opts)
+	if err != nil {
+		return err
+	}
+	_ = cw.WriteRow("id", "title", "author", "isbn", "price", "publication_year", "created_at", "updated_at")
+	for _, b := range books {
+		_ = cw.WriteRow(b.ID, b.Title, b.Author, b.ISBN, b.Price, b.PublicationYear, b.CreatedAt, b.UpdatedAt)
+	}
+	return cw.Flush()
+}
+
+// WriteBooksXLSX writes books as a workbook with one typed column per field.
+func WriteBooksXLSX(w io.Writer, books []domain.Book) error {
+	xw, err := NewXLSXWriter(w, "Books", []XLSXColumn{
+		{Header: "ID", Style: StyleInteger, Width: 8},
+		{Header: "Title", Width: 40},
+		{Header: "Author", Width: 28},
+		{Header: "ISBN", Width: 16}, // text, so leading zeros survive
+		{Header: "Price", Style: StyleMoney, Width: 10},
+		{Header: "Publication Year", Style: StyleInteger, Width: 16},
+		{Header: "Created At", Style: StyleDateTime, Width: 20},
+		{Header: "Updated At", Style: StyleDateTime, Width: 20},
+	})
+	if err != nil {
+		return err
+	}
+	for _, b := range books {
+		if err := xw.WriteRow(b.ID, b.Title, b.Author, b.ISBN, b.Price, b.PublicationYear, b.CreatedAt, b.UpdatedAt); err != nil {
+			return err
+		}
+	}
+	return xw.Close()
+}
+
+// The formats books are exported in and imported from.
+var (
+	BookExportFormats = []string{"csv", "xlsx"}
+	BookImportFormats = []string{"csv", "json"}
+)
+
+// BookFormat tells a book file's format, "csv" or "json", from its
+// extension, then its content type. It is empty for anything else.
+func BookFormat(filename, contentType string) string {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".csv":
+		return "csv"
+	case ".json":
+		return "json"
+	}
+	switch {
+	case strings.Contains(contentType, "json"):
+		return "json"
+	case strings.Contains(contentType, "csv"):
+		return "csv"
+	}
+	return ""
+}
+
+// ReadBooksJSON reads a JSON array of books into import rows. A book with a
+// value of the wrong type becomes a row with that field's error.
+func ReadBooksJSON(r io.Reader) ([]ports.ImportRow, error) {
+	var raw []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, errors.New("JSON import must be an array of books")
+	}
+	rows := make([]ports.ImportRow, len(raw))
+	for i, msg := range raw {
+		rows[i].Row = i + 1
+		if err := json.Unmarshal(msg, &rows[i].Book); err != nil {
+			field := "row"
+			var te *json.UnmarshalTypeError
+			if errors.As(err, &te) && te.Field != "" {
+				field = te.Field
+			}
+			rows[i].Errors = map[string]string{field: "Invalid value"}
+		}
+	}
+	return rows, nil
+}
+
+// ReadBooksCSV reads a CSV in the export's layout. The delimiter is taken
+// from the header line; semicolon-separated files may use decimal commas,
+// as the export writes them with decimal=comma.
+func ReadBooksCSV(r io.Reader) ([]ports.ImportRow, error) {
+	br := bufio.NewReader(r)
+	first, err := br.Peek(1024)
+	if err != nil && err != io.EOF && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, err
+	}
+	if bytes.HasPrefix(first, []byte("\ufeff")) {
+		_, _ = br.Discard(3)
+		first = first[3:]
+	}
+	if i := bytes.IndexByte(first, '\n'); i >= 0 {
+		first = first[:i]
+	}
+	cr := csv.NewReader(br)
+	cr.FieldsPerRecord = -1
+	for _, d := range []rune{';', '\t', '|'} {
+		if bytes.Count(first, []byte(string(d))) > bytes.Count(first, []byte(",")) {
+			cr.Comma = d
+			break
+		}
+	}
+
+	head, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %v", err)
+	}
+	col := map[string]int{}
+	for i, name := range head {
+		col[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range bookColumns {
+		if _, ok := col[name]; !ok {
+			return nil, fmt.Errorf("CSV header must include %s", strings.Join(bookColumns, ", "))
+		}
+	}
+
+	var rows []ports.ImportRow
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %v", err)
+		}
+		get := func(name string) string {
+			if i := col[name]; i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+		row := ports.ImportRow{Row: len(rows) + 1, Book: ports.CreateBookInput{
+			Title: get("title"), Author: get("author"), ISBN: get("isbn"),
+		}}
+		if v := get("price"); v != "" {
+			if cr.Comma == ';' {
+				v = strings.Replace(v, ",", ".", 1)
+			}
+			p, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				row.Errors = map[string]string{"price": "Price must be a number"}
+			}
+			row.Book.Price = p
+		}
+		if v := get("publication_year"); v != "" {
+			y, err := strconv.Atoi(v)
+			if err != nil {
+				if row.Errors == nil {
+					row.Errors = map[string]string{}
+				}
+				row.Errors["publication_year"] = "Publication year must be a number"
+			}
+			row.Book.PublicationYear = y
+		}
+		rows = append(rows, row)
+	}
+}
@@ -0,0 +1,119 @@
+// Package export writes tabular data in spreadsheet-friendly formats, and
+// reads book files back in the layout it writes them.
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVOptions controls the dialect of a CSV export. The zero value writes
+// plain RFC 4180 CSV: comma separated, '.' as decimal separator, no BOM.
+type CSVOptions struct {
+	Delimiter    rune // defaults to ','
+	DecimalComma bool // write 12,50 instead of 12.50
+	BOM          bool // prefix a UTF-8 BOM so Excel detects the encoding
+}
+
+// ParseCSVOptions reads the delimiter, decimal and bom query options.
+// Accepted values: delimiter one of , ; | tab (or their names, since a bare
+// ';' is not a valid query separator and must otherwise be sent as %3B);
+// decimal point or comma; bom true/false.
+func ParseCSVOptions(delimiter, decimal, bom string) (CSVOptions, error) {
+	var o CSVOptions
+	switch delimiter {
+	case "", ",", "comma":
+		o.Delimiter = ','
+	case ";", "semicolon":
+		o.Delimiter = ';'
+	case "|", "pipe":
+		o.Delimiter = '|'
+	case "tab", "\t":
+		o.Delimiter = '\t'
+	default:
+		return o, fmt.Errorf("invalid delimiter %q (use comma, semicolon, pipe or tab)", delimiter)
+	}
+	switch strings.ToLower(decimal) {
+	case "", "point", "dot":
+	case "comma":
+		o.DecimalComma = true
+	default:
+		return o, fmt.Errorf("invalid decimal %q (use point or comma)", decimal)
+	}
+	if bom != "" {
+		b, err := strconv.ParseBool(bom)
+		if err != nil {
+			return o, fmt.Errorf("invalid bom %q", bom)
+		}
+		o.BOM = b
+	}
+	return o, nil
+}
+
+// CSVWriter writes rows of typed cells, formatting numbers and times
+// according to its options.
+type CSVWriter struct {
+	w    *csv.Writer
+	opts CSVOptions
+}
+
+func NewCSVWriter(w io.Writer, opts CSVOptions) (*CSVWriter, error) {
+	if opts.BOM {
+		if _, err := io.WriteString(w, "\ufeff"); err != nil {
+			return nil, err
+		}
+	}
+	cw := csv.NewWriter(w)
+	if opts.Delimiter != 0 {
+		cw.Comma = opts.Delimiter
+	}
+	cw.UseCRLF = true // what Excel expects
+	return &CSVWriter{w: cw, opts: opts}, nil
+}
+
+// WriteRow writes one record. Supported cell types are string, int, int64,
+// float64, bool and time.Time; anything else is written with fmt's %v.
+func (c *CSVWriter) WriteRow(cells ...any) error {
+	rec := make([]string, len(cells))
+	for i, v := range cells {
+		rec[i] = c.format(v)
+	}
+	return c.w.Write(rec)
+}
+
+// Flush writes any buffered data and reports the first write error.
+func (c *CSVWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *CSVWriter) format(v any) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case int:
+		return strconv.Itoa(x)
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case float64:
+		s := strconv.FormatFloat(x, 'f', -1, 64)
+		if c.opts.DecimalComma {
+			s = strings.Replace(s, ".", ",", 1)
+		}
+		return s
+	case bool:
+		return strconv.FormatBool(x)
+	case time.Time:
+		if x.IsZero() {
+			return ""
+		}
+		return x.UTC().Format(time.RFC3339)
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(v)
+}
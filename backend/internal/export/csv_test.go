@@ -0,0 +1,64 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestCSVWriter_Dialects(t *testing.T) {
+	ts := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name string
+		opts CSVOptions
+		want string
+	}{
+		{"default", CSVOptions{}, "id,title,price,at\r\n1,\"Smith, J.\",12.5,2024-03-01T12:00:00Z\r\n"},
+		{"european", CSVOptions{Delimiter: ';', DecimalComma: true, BOM: true}, "\ufeffid;title;price;at\r\n1;Smith, J.;12,5;2024-03-01T12:00:00Z\r\n"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewCSVWriter(&buf, c.opts)
+			if err != nil {
+				t.Fatalf("NewCSVWriter: %v", err)
+			}
+			_ = w.WriteRow("id", "title", "price", "at")
+			_ = w.WriteRow(int64(1), "Smith, J.", 12.5, ts)
+			if err := w.Flush(); err != nil {
+				t.Fatalf("Flush: %v", err)
+			}
+			if buf.String() != c.want {
+				t.Fatalf("got %q\nwant %q", buf.String(), c.want)
+			}
+		})
+	}
+}
+
+func TestCSVWriter_DecimalCommaQuotedWithCommaDelimiter(t *testing.T) {
+	var buf bytes.Buffer
+	w, _ := NewCSVWriter(&buf, CSVOptions{DecimalComma: true})
+	_ = w.WriteRow(9.99)
+	_ = w.Flush()
+	if buf.String() != "\"9,99\"\r\n" {
+		t.Fatalf("got %q", buf.String())
+	}
+}
+
+func TestParseCSVOptions(t *testing.T) {
+	o, err := ParseCSVOptions(";", "comma", "true")
+	if err != nil || o.Delimiter != ';' || !o.DecimalComma || !o.BOM {
+		t.Fatalf("got %+v, %v", o, err)
+	}
+	if o, _ := ParseCSVOptions("semicolon", "", ""); o.Delimiter != ';' {
+		t.Fatalf("semicolon delimiter = %q", o.Delimiter)
+	}
+	if o, _ := ParseCSVOptions("tab", "", ""); o.Delimiter != '\t' {
+		t.Fatalf("tab delimiter = %q", o.Delimiter)
+	}
+	for _, bad := range [][3]string{{"x", "", ""}, {"", "space", ""}, {"", "", "maybe"}} {
+		if _, err := ParseCSVOptions(bad[0], bad[1], bad[2]); err == nil {
+			t.Fatalf("ParseCSVOptions(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package export
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// MaxPriceFuzz is the largest fraction a Masker moves prices by.
+const MaxPriceFuzz = 0.5
+
+// Masker anonymizes exported records for copies outside production, such as
+// refreshing staging. It replaces emails and actors with pseudonyms derived
+// from a secret key, so the same person gets the same pseudonym in every
+// table and every export made with that key, and joins still line up.
+// Without the key the pseudonyms can't be reversed or recomputed from a
+// guessed email.
+type Masker struct {
+	key       []byte
+	priceFuzz float64
+}
+
+// NewMasker returns a Masker keyed by key, which must be at least 16 bytes.
+// A priceFuzz above zero moves every price by up to that fraction, e.g. 0.1
+// for ±10%; each book keeps its own factor, so its price history still
+// moves the way it did.
+func NewMasker(key string, priceFuzz float64) (*Masker, error) {
+	if len(key) < 16 {
+		return nil, errors.New("mask key must be at least 16 bytes")
+	}
+	if priceFuzz < 0 || priceFuzz > MaxPriceFuzz || math.IsNaN(priceFuzz) {
+		return nil, fmt.Errorf("price fuzz must be between 0 and %g", MaxPriceFuzz)
+	}
+	return &Masker{key: []byte(key), priceFuzz: priceFuzz}, nil
+}
+
+func (m *Masker) sum(kind, value string) []byte {
+	h := hmac.New(sha256.New, m.key)
+	h.Write([]byte(kind))
+	h.Write([]byte{0})
+	h.Write([]byte(value))
+	return h.Sum(nil)
+}
+
+// Email returns the pseudonym of an email, on a reserved domain so staging
+// can never mail it. Emails differing only in case get the same one, as
+// MySQL treats them as the same account.
+func (m *Masker) Email(email string) string {
+	if email == "" {
+		return ""
+	}
+	return "user-" + hex.EncodeToString(m.sum("email", strings.ToLower(email))[:6]) + "@example.invalid"
+}
+
+// Actor returns the pseudonym of a change log actor. Actors that are emails
+// get the same pseudonym as the user with that email; the rest, such as
+// proxy user IDs and API key names, get one of their own.
+func (m *Masker) Actor(actor string) string {
+	switch {
+	case actor == "":
+		return ""
+	case strings.Contains(actor, "@"):
+		return m.Email(actor)
+	}
+	return "actor-" + hex.EncodeToString(m.sum("actor", actor)[:6])
+}
+
+// Price returns the fuzzed price of book id, rounded to cents.
+func (m *Masker) Price(id int64, price float64) float64 {
+	if m.priceFuzz == 0 || price == 0 {
+		return price
+	}
+	u := float64(binary.BigEndian.Uint64(m.sum("price", strconv.FormatInt(id, 10)))) / math.MaxUint64
+	return math.Max(0.01, math.Round(price*(1+m.priceFuzz*(2*u-1))*100)/100)
+}
+
+// Book masks a book. Titles, authors and ISBNs are published catalogue
+// data, so only the price changes.
+func (m *Masker) Book(b domain.Book) domain.Book {
+	b.Price = m.Price(b.ID, b.Price)
+	return b
+}
+
+// User masks an account.
+func (m *Masker) User(u domain.User) domain.User {
+	u.Email = m.Email(u.Email)
+	u.PasswordHash = ""
+	return u
+}
+
+// Change masks a change log entry: its actors and the book in its payload.
+func (m *Masker) Change(c domain.Change) (domain.Change, error) {
+	c.Actor = m.Actor(c.Actor)
+	c.ImpersonatedBy = m.Actor(c.ImpersonatedBy)
+	b, err := c.Book()
+	if err != nil || b == nil {
+		return c, err
+	}
+	masked := m.Book(*b)
+	if masked.Price == b.Price {
+		return c, nil
+	}
+	// patch the price only, so fields the payload has and Book doesn't survive
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(c.Payload, &fields); err != nil {
+		return c, fmt.Errorf("change %d: %w", c.ID, err)
+	}
+	fields["price"], _ = json.Marshal(masked.Price)
+	c.Payload, err = json.Marshal(fields)
+	return c, err
+}
+
+// MaskBooks is the export stage that masks books; a nil Masker passes them
+// through.
+func (m *Masker) MaskBooks(books []domain.Book) []domain.Book {
+	if m == nil {
+		return books
+	}
+	out := make([]domain.Book, len(books))
+	for i, b := range books {
+		out[i] = m.Book(b)
+	}
+	return out
+}
+
+// MaskUsers is the export stage that masks users; a nil Masker passes them
+// through.
+func (m *Masker) MaskUsers(users []domain.User) []domain.User {
+	if m == nil {
+		return users
+	}
+	out := make([]domain.User, len(users))
+	for i, u := range users {
+		out[i] = m.User(u)
+	}
+	return out
+}
+
+// MaskChanges is the export stage that masks change log entries; a nil
+// Masker passes them through.
+func (m *Masker) MaskChanges(changes []domain.Change) ([]domain.Change, error) {
+	if m == nil {
+		return changes, nil
+	}
+	out := make([]domain.Change, len(changes))
+	for i, c := range changes {
+		var err error
+		if out[i], err = m.Change(c); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
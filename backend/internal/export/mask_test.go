@@ -0,0 +1,90 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+func TestNewMasker_Validates(t *testing.T) {
+	for _, c := range []struct {
+		key  string
+		fuzz float64
+	}{{"short", 0}, {"0123456789abcdef", -0.1}, {"0123456789abcdef", 0.6}} {
+		if _, err := NewMasker(c.key, c.fuzz); err == nil {
+			t.Errorf("NewMasker(%q, %g) accepted", c.key, c.fuzz)
+		}
+	}
+}
+
+func TestMasker_Pseudonyms(t *testing.T) {
+	m, _ := NewMasker("0123456789abcdef", 0)
+	other, _ := NewMasker("fedcba9876543210", 0)
+
+	ann := m.Email("ann@example.com")
+	if !strings.HasPrefix(ann, "user-") || !strings.HasSuffix(ann, "@example.invalid") || strings.Contains(ann, "ann") {
+		t.Fatalf("Email = %q", ann)
+	}
+	if m.Email("Ann@Example.com") != ann || m.Actor("ann@example.com") != ann {
+		t.Fatal("the same email must get the same pseudonym")
+	}
+	if m.Email("bob@example.com") == ann || other.Email("ann@example.com") == ann {
+		t.Fatal("pseudonyms must differ between emails and keys")
+	}
+	if a := m.Actor("cli"); !strings.HasPrefix(a, "actor-") || a != m.Actor("cli") {
+		t.Fatalf("Actor(cli) = %q", a)
+	}
+	if m.Email("") != "" || m.Actor("") != "" {
+		t.Fatal("empty values must stay empty")
+	}
+
+	u := m.User(domain.User{ID: 1, Email: "ann@example.com", PasswordHash: "h"})
+	if u.Email != ann || u.PasswordHash != "" {
+		t.Fatalf("User = %+v", u)
+	}
+}
+
+func TestMasker_FuzzesPrices(t *testing.T) {
+	m, _ := NewMasker("0123456789abcdef", 0.1)
+	b := domain.Book{ID: 7, Title: "Dune", Price: 20}
+	got := m.Book(b)
+	if got.Title != "Dune" || got.Price == 20 || got.Price < 18 || got.Price > 22 {
+		t.Fatalf("Book price = %v", got.Price)
+	}
+	if m.Book(b).Price != got.Price {
+		t.Fatal("fuzzing must be deterministic")
+	}
+	if plain := (*Masker)(nil).MaskBooks([]domain.Book{b}); plain[0].Price != 20 {
+		t.Fatal("a nil Masker must pass books through")
+	}
+
+	payload, _ := domain.NewChangePayload(b)
+	c, err := m.Change(domain.Change{ID: 1, BookID: 7, Actor: "ann@example.com", ImpersonatedBy: "root", Payload: payload})
+	if err != nil {
+		t.Fatalf("Change: %v", err)
+	}
+	cb, _ := c.Book()
+	if cb.Price != got.Price || cb.Title != "Dune" {
+		t.Fatalf("payload = %s; want price %v", c.Payload, got.Price)
+	}
+	if c.Actor != m.Email("ann@example.com") || c.ImpersonatedBy != m.Actor("root") {
+		t.Fatalf("actors = %q, %q", c.Actor, c.ImpersonatedBy)
+	}
+	if del, err := m.Change(domain.Change{ID: 2, Op: domain.ChangeDeleted}); err != nil || del.Payload != nil {
+		t.Fatalf("delete = %+v, %v", del, err)
+	}
+}
+
+func TestWriteUsersCSV_OmitsPasswordHashes(t *testing.T) {
+	var buf bytes.Buffer
+	users := []domain.User{{ID: 1, Email: "ann@example.com", PasswordHash: "secret-hash", Scopes: domain.ScopeList{"admin", "editor"}}}
+	if err := WriteUsersCSV(&buf, users, CSVOptions{}); err != nil {
+		t.Fatalf("WriteUsersCSV: %v", err)
+	}
+	want := "id,email,scopes,created_at\r\n1,ann@example.com,\"admin,editor\",\r\n"
+	if buf.String() != want {
+		t.Fatalf("got %q\nwant %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,80 @@
+package export
+
+import (
+	"io"
+	"strings"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// The tables the export command writes, books being the default.
+var ExportTables = []string{"books", "users", "changes"}
+
+// WriteUsersCSV writes user accounts. Password hashes are never exported.
+func WriteUsersCSV(w io.Writer, users []domain.User, opts CSVOptions) error {
+	cw, err := NewCSVWriter(w, opts)
+	if err != nil {
+		return err
+	}
+	_ = cw.WriteRow("id", "email", "scopes", "created_at")
+	for _, u := range users {
+		_ = cw.WriteRow(u.ID, u.Email, strings.Join(u.Scopes, ","), u.CreatedAt)
+	}
+	return cw.Flush()
+}
+
+// WriteUsersXLSX writes user accounts as a workbook.
+func WriteUsersXLSX(w io.Writer, users []domain.User) error {
+	xw, err := NewXLSXWriter(w, "Users", []XLSXColumn{
+		{Header: "ID", Style: StyleInteger, Width: 8},
+		{Header: "Email", Width: 36},
+		{Header: "Scopes", Width: 20},
+		{Header: "Created At", Style: StyleDateTime, Width: 20},
+	})
+	if err != nil {
+		return err
+	}
+	for _, u := range users {
+		if err := xw.WriteRow(u.ID, u.Email, strings.Join(u.Scopes, ","), u.CreatedAt); err != nil {
+			return err
+		}
+	}
+	return xw.Close()
+}
+
+// WriteChangesCSV writes change log entries, with the payload as JSON.
+func WriteChangesCSV(w io.Writer, changes []domain.Change, opts CSVOptions) error {
+	cw, err := NewCSVWriter(w, opts)
+	if err != nil {
+		return err
+	}
+	_ = cw.WriteRow("cursor", "entity", "book_id", "op", "version", "actor", "impersonated_by", "payload", "created_at")
+	for _, c := range changes {
+		_ = cw.WriteRow(c.ID, c.Entity, c.BookID, c.Op, c.Version, c.Actor, c.ImpersonatedBy, string(c.Payload), c.CreatedAt)
+	}
+	return cw.Flush()
+}
+
+// WriteChangesXLSX writes change log entries as a workbook.
+func WriteChangesXLSX(w io.Writer, changes []domain.Change) error {
+	xw, err := NewXLSXWriter(w, "Changes", []XLSXColumn{
+		{Header: "Cursor", Style: StyleInteger, Width: 10},
+		{Header: "Entity", Width: 8},
+		{Header: "Book ID", Style: StyleInteger, Width: 8},
+		{Header: "Op", Width: 10},
+		{Header: "Version", Style: StyleInteger, Width: 8},
+		{Header: "Actor", Width: 28},
+		{Header: "Impersonated By", Width: 28},
+		{Header: "Payload", Width: 60},
+		{Header: "Created At", Style: StyleDateTime, Width: 20},
+	})
+	if err != nil {
+		return err
+	}
+	for _, c := range changes {
+		if err := xw.WriteRow(c.ID, c.Entity, c.BookID, c.Op, c.Version, c.Actor, c.ImpersonatedBy, string(c.Payload), c.CreatedAt); err != nil {
+			return err
+		}
+	}
+	return xw.Close()
+}
@@ -0,0 +1,218 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Style selects the cell format of an XLSX column.
+type Style int
+
+const (
+	StyleText     Style = iota
+	StyleInteger        // 0
+	StyleMoney          // #,##0.00
+	StyleDateTime       // yyyy-mm-dd hh:mm:ss
+	styleHeader
+)
+
+// XLSXColumn describes one column of an XLSX sheet.
+type XLSXColumn struct {
+	Header string
+	Style  Style
+	Width  float64 // in characters; 0 lets Excel decide
+}
+
+// XLSXWriter streams a single-sheet workbook: rows go straight into the zip
+// entry, so memory use doesn't grow with the number of rows. The header row
+// is bold, frozen and has an autofilter.
+type XLSXWriter struct {
+	zw   *zip.Writer
+	buf  *bufio.Writer
+	cols []XLSXColumn
+	rows int
+}
+
+func NewXLSXWriter(w io.Writer, sheet string, cols []XLSXColumn) (*XLSXWriter, error) {
+	zw := zip.NewWriter(w)
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbook, xmlEscape(sheet))},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, p := range parts {
+		f, err := zw.Create(p.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, p.body); err != nil {
+			return nil, err
+		}
+	}
+
+	// the sheet must be the last entry since it stays open while streaming
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	x := &XLSXWriter{zw: zw, buf: bufio.NewWriter(f), cols: cols}
+
+	x.buf.WriteString(xml.Header)
+	x.buf.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	x.buf.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	var widths strings.Builder
+	for i, c := range cols {
+		if c.Width > 0 {
+			fmt.Fprintf(&widths, `<col min="%d" max="%d" width="%s" customWidth="1"/>`, i+1, i+1, strconv.FormatFloat(c.Width, 'f', -1, 64))
+		}
+	}
+	if widths.Len() > 0 {
+		x.buf.WriteString("<cols>" + widths.String() + "</cols>")
+	}
+	x.buf.WriteString("<sheetData>")
+
+	header := make([]any, len(cols))
+	for i, c := range cols {
+		header[i] = c.Header
+	}
+	if err := x.writeRow(header, true); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// WriteRow appends a row. Cells are formatted with their column's style;
+// supported types are string, int, int64, float64, bool and time.Time.
+func (x *XLSXWriter) WriteRow(cells ...any) error {
+	return x.writeRow(cells, false)
+}
+
+func (x *XLSXWriter) writeRow(cells []any, header bool) error {
+	x.rows++
+	fmt.Fprintf(x.buf, `<row r="%d">`, x.rows)
+	for i, v := range cells {
+		style := styleHeader
+		if !header {
+			style = StyleText
+			if i < len(x.cols) {
+				style = x.cols[i].Style
+			}
+		}
+		ref := columnName(i) + strconv.Itoa(x.rows)
+		s := ""
+		if style != StyleText {
+			s = fmt.Sprintf(` s="%d"`, style)
+		}
+		switch c := v.(type) {
+		case nil:
+			continue
+		case int:
+			fmt.Fprintf(x.buf, `<c r="%s"%s><v>%d</v></c>`, ref, s, c)
+		case int64:
+			fmt.Fprintf(x.buf, `<c r="%s"%s><v>%d</v></c>`, ref, s, c)
+		case float64:
+			fmt.Fprintf(x.buf, `<c r="%s"%s><v>%s</v></c>`, ref, s, strconv.FormatFloat(c, 'f', -1, 64))
+		case bool:
+			b := 0
+			if c {
+				b = 1
+			}
+			fmt.Fprintf(x.buf, `<c r="%s"%s t="b"><v>%d</v></c>`, ref, s, b)
+		case time.Time:
+			if c.IsZero() {
+				continue
+			}
+			fmt.Fprintf(x.buf, `<c r="%s"%s><v>%s</v></c>`, ref, s, strconv.FormatFloat(excelSerial(c), 'f', -1, 64))
+		default:
+			str, ok := v.(string)
+			if !ok {
+				str = fmt.Sprint(v)
+			}
+			fmt.Fprintf(x.buf, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, s, xmlEscape(str))
+		}
+	}
+	_, err := x.buf.WriteString("</row>")
+	return err
+}
+
+// Close finishes the sheet and the zip archive. It does not close the
+// underlying writer.
+func (x *XLSXWriter) Close() error {
+	x.buf.WriteString("</sheetData>")
+	if len(x.cols) > 0 {
+		fmt.Fprintf(x.buf, `<autoFilter ref="A1:%s%d"/>`, columnName(len(x.cols)-1), x.rows)
+	}
+	x.buf.WriteString("</worksheet>")
+	if err := x.buf.Flush(); err != nil {
+		return err
+	}
+	return x.zw.Close()
+}
+
+// columnName converts a zero-based index to A, B, ..., Z, AA, AB, ...
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// excelSerial converts t to Excel's serial day number (1900 date system), in UTC.
+func excelSerial(t time.Time) float64 {
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	return float64(t.UTC().Sub(epoch)) / float64(24*time.Hour)
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+const xlsxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const xlsxRootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbook = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>` +
+	`</workbook>`
+
+const xlsxWorkbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+// cellXfs are indexed by Style.
+const xlsxStyles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm:ss"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="3"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill>` +
+	`<fill><patternFill patternType="solid"><fgColor rgb="FFD9E1F2"/><bgColor indexed="64"/></patternFill></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="5">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="1" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="2" borderId="0" xfId="0" applyFont="1" applyFill="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
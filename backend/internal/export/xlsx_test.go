@@ -0,0 +1,81 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewXLSXWriter(&buf, "Books & more", []XLSXColumn{
+		{Header: "Title", Width: 40},
+		{Header: "Price", Style: StyleMoney},
+		{Header: "Year", Style: StyleInteger},
+		{Header: "Updated", Style: StyleDateTime},
+	})
+	if err != nil {
+		t.Fatalf("NewXLSXWriter: %v", err)
+	}
+	_ = w.WriteRow("Tom <& Jerry>", 12.5, 2008, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("not a zip: %v", err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(b)
+
+		// every part must be well-formed XML
+		d := xml.NewDecoder(bytes.NewReader(b))
+		for {
+			if _, err := d.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s: %v", f.Name, err)
+			}
+		}
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/styles.xml", "xl/worksheets/sheet1.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Fatalf("missing part %s", name)
+		}
+	}
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{
+		`<c r="A1" s="4" t="inlineStr"><is><t xml:space="preserve">Title</t></is></c>`,
+		`<t xml:space="preserve">Tom &lt;&amp; Jerry&gt;</t>`,
+		`<c r="B2" s="2"><v>12.5</v></c>`,
+		`<c r="C2" s="1"><v>2008</v></c>`,
+		`<c r="D2" s="3"><v>45292.5</v></c>`,
+		`<col min="1" max="1" width="40" customWidth="1"/>`,
+		`<autoFilter ref="A1:D2"/>`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Fatalf("sheet missing %s\n%s", want, sheet)
+		}
+	}
+	if !strings.Contains(files["xl/workbook.xml"], `name="Books &amp; more"`) {
+		t.Fatalf("sheet name not escaped: %s", files["xl/workbook.xml"])
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(i); got != want {
+			t.Fatalf("columnName(%d) = %s; want %s", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package httpclient
+
+import (
+	"sync"
+	"time"
+)
+
+// breakers keeps a circuit breaker per host. A host's breaker opens after
+// threshold consecutive failures and fails requests to it for cooldown.
+// After that one request at a time is let through: a success closes the
+// breaker, a failure opens it for another cooldown. Only hosts with
+// failures have an entry.
+type breakers struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*breaker
+}
+
+type breaker struct {
+	failures  int
+	openUntil time.Time // zero while closed
+	probing   bool      // a request is testing the host after the cooldown
+}
+
+func newBreakers(threshold int, cooldown time.Duration) *breakers {
+	return &breakers{threshold: threshold, cooldown: cooldown, hosts: map[string]*breaker{}}
+}
+
+// allow reports whether a request to host may be sent. Every allowed
+// request must be followed by record or release.
+func (bs *breakers) allow(host string, now time.Time) bool {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	b := bs.hosts[host]
+	switch {
+	case b == nil || b.openUntil.IsZero():
+		return true
+	case now.Before(b.openUntil) || b.probing:
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// record notes the outcome of a request to host.
+func (bs *breakers) record(host string, ok bool, now time.Time) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if ok {
+		delete(bs.hosts, host)
+		return
+	}
+	b := bs.hosts[host]
+	if b == nil {
+		b = &breaker{}
+		bs.hosts[host] = b
+	}
+	b.failures++
+	b.probing = false
+	if b.failures >= bs.threshold {
+		b.openUntil = now.Add(bs.cooldown)
+	}
+}
+
+// release ends a request to host that told nothing about it, e.g. because
+// its caller gave up.
+func (bs *breakers) release(host string) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if b := bs.hosts[host]; b != nil {
+		b.probing = false
+	}
+}
@@ -0,0 +1,65 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ErrEgressDenied fails requests to hosts off the client's allowlist,
+// without contacting them.
+var ErrEgressDenied = errors.New("URL host is not on the egress allowlist")
+
+// Allowlist is the set of hosts a client may reach. A pattern is a host
+// name or IP address, which matches itself, or *.domain, which matches
+// every host under domain but not domain itself. Ports don't matter. A nil
+// Allowlist allows every host.
+type Allowlist struct {
+	hosts    map[string]bool
+	suffixes []string // ".domain" of each *.domain
+}
+
+// ParseAllowlist checks patterns and returns their allowlist; nil when there
+// are none.
+func ParseAllowlist(patterns []string) (*Allowlist, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	a := &Allowlist{hosts: map[string]bool{}}
+	for _, p := range patterns {
+		host := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(p)), ".")
+		domain, wildcard := strings.CutPrefix(host, "*.")
+		if ip := net.ParseIP(strings.Trim(host, "[]")); ip != nil && !wildcard {
+			domain = ip.String()
+		} else if domain == "" || strings.ContainsAny(domain, "*/:@?#[] ") {
+			return nil, fmt.Errorf("%q is not a host or *.domain", p)
+		}
+		if wildcard {
+			a.suffixes = append(a.suffixes, "."+domain)
+		} else {
+			a.hosts[domain] = true
+		}
+	}
+	return a, nil
+}
+
+// Allows reports whether hostname, without a port, may be reached.
+func (a *Allowlist) Allows(hostname string) bool {
+	if a == nil {
+		return true
+	}
+	host := strings.TrimSuffix(strings.ToLower(hostname), ".")
+	if ip := net.ParseIP(host); ip != nil {
+		host = ip.String()
+	}
+	if a.hosts[host] {
+		return true
+	}
+	for _, s := range a.suffixes {
+		if strings.HasSuffix(host, s) {
+			return true
+		}
+	}
+	return false
+}
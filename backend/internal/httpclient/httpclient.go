@@ -0,0 +1,258 @@
+// Package httpclient is the plumbing every outbound adapter shares: it wraps
+// an *http.Client, normally one from urlsafe.Policy.Client, with per-host
+// rate limiting, retries with jittered backoff, response caching, a circuit
+// breaker per host and metrics. Adapters keep taking an *http.Client and
+// don't know the layers are there.
+//
+// A request goes through the layers in this order: hosts off the egress
+// allowlist are refused and logged; the cache answers GETs
+// it has a fresh response for; an open breaker fails the request at once;
+// otherwise each attempt waits for its host's rate limit before it is sent,
+// and failed attempts of idempotent requests are retried.
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/logger"
+)
+
+// ErrCircuitOpen fails requests to a host whose breaker is open, without
+// contacting it.
+var ErrCircuitOpen = errors.New("circuit open")
+
+// Options configure the layers; a zero field turns its layer off, except
+// where a default is given.
+type Options struct {
+	// Name identifies the client in the metrics and logs. Clients with the
+	// same name share their counters.
+	Name string
+	// Egress limits the hosts the client may reach, redirects included.
+	// Other requests fail with ErrEgressDenied and are logged at warn level.
+	// nil allows every host.
+	Egress *Allowlist
+	// RatePerHost is how many requests per second each host gets, with
+	// bursts of up to Burst (default 1). Requests beyond it wait their
+	// turn.
+	RatePerHost float64
+	Burst       int
+	// Retries is how many times a GET or HEAD is tried again after a
+	// network error, a 429 or a 5xx. Waits start at RetryBase (default
+	// 200ms) and double up to RetryMax (default 5s), each a random part of
+	// that (full jitter); a Retry-After within RetryMax is honoured instead.
+	Retries   int
+	RetryBase time.Duration
+	RetryMax  time.Duration
+	// CacheTTL is how long 200 responses to GETs are reused. CacheSize
+	// (default 1000) bounds the responses kept and CacheMaxBody (default
+	// 1 MiB) the body of each; larger ones aren't cached. Responses marked
+	// Cache-Control: no-store or private aren't cached either.
+	CacheTTL     time.Duration
+	CacheSize    int
+	CacheMaxBody int64
+	// BreakerFailures consecutive failures, after retries, open a host's
+	// breaker for BreakerCooldown (default 30s). Then a single request
+	// tries the host again and closes the breaker if it succeeds.
+	BreakerFailures int
+	BreakerCooldown time.Duration
+}
+
+func (o Options) withDefaults() Options {
+	if o.Burst < 1 {
+		o.Burst = 1
+	}
+	if o.RetryBase <= 0 {
+		o.RetryBase = 200 * time.Millisecond
+	}
+	if o.RetryMax <= 0 {
+		o.RetryMax = 5 * time.Second
+	}
+	if o.CacheSize <= 0 {
+		o.CacheSize = 1000
+	}
+	if o.CacheMaxBody <= 0 {
+		o.CacheMaxBody = 1 << 20
+	}
+	if o.BreakerCooldown <= 0 {
+		o.BreakerCooldown = 30 * time.Second
+	}
+	return o
+}
+
+// New returns a copy of base whose transport goes through the layers of o.
+// base keeps its own transport, timeout and redirect policy; the timeout
+// covers retries and rate limit waits.
+func New(base *http.Client, o Options) *http.Client {
+	c := *base
+	c.Transport = NewTransport(base.Transport, o)
+	return &c
+}
+
+// Transport is an http.RoundTripper with the layers of its Options.
+type Transport struct {
+	next    http.RoundTripper
+	opts    Options
+	limiter *limiter
+	breaker *breakers
+	cache   *cache
+	metrics *Metrics
+
+	now    func() time.Time
+	sleep  func(ctx context.Context, d time.Duration) error
+	jitter func(d time.Duration) time.Duration
+}
+
+// NewTransport wraps next, http.DefaultTransport when nil.
+func NewTransport(next http.RoundTripper, o Options) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	o = o.withDefaults()
+	t := &Transport{next: next, opts: o, metrics: metricsFor(o.Name), now: time.Now, sleep: sleep, jitter: fullJitter}
+	if o.RatePerHost > 0 {
+		t.limiter = newLimiter(o.RatePerHost, o.Burst)
+	}
+	if o.BreakerFailures > 0 {
+		t.breaker = newBreakers(o.BreakerFailures, o.BreakerCooldown)
+	}
+	if o.CacheTTL > 0 {
+		t.cache = newCache(o.CacheSize, o.CacheTTL)
+	}
+	return t
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.metrics.add(func(s *Stats) { s.Requests++ })
+	if !t.opts.Egress.Allows(req.URL.Hostname()) {
+		t.metrics.add(func(s *Stats) { s.EgressDenied++ })
+		// queries may carry keys, so only the path is logged
+		logged := url.URL{Scheme: req.URL.Scheme, Host: req.URL.Host, Path: req.URL.Path}
+		logger.From(req.Context()).Warn("outbound request blocked by egress allowlist",
+			"client", t.opts.Name, "method", req.Method, "host", req.URL.Host, "url", logged.String())
+		return nil, fmt.Errorf("%w: %s", ErrEgressDenied, req.URL.Host)
+	}
+	cacheable := t.cache != nil && req.Method == http.MethodGet && req.Header.Get("Authorization") == ""
+	key := req.URL.String()
+	if cacheable {
+		if resp, ok := t.cache.get(key, t.now()); ok {
+			t.metrics.add(func(s *Stats) { s.CacheHits++ })
+			resp.Request = req
+			return resp, nil
+		}
+	}
+	host := strings.ToLower(req.URL.Host)
+	if t.breaker != nil && !t.breaker.allow(host, t.now()) {
+		t.metrics.add(func(s *Stats) { s.CircuitOpen++ })
+		return nil, fmt.Errorf("%w for %s", ErrCircuitOpen, host)
+	}
+
+	resp, err := t.send(req, host)
+	failed := err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	if t.breaker != nil {
+		if err != nil && req.Context().Err() != nil {
+			// the caller gave up, which says nothing about the host
+			t.breaker.release(host)
+		} else {
+			t.breaker.record(host, !failed, t.now())
+		}
+	}
+	if failed {
+		t.metrics.add(func(s *Stats) { s.Failures++ })
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cacheable && resp.StatusCode == http.StatusOK && storable(resp) {
+		return t.cache.put(key, resp, t.opts.CacheMaxBody, t.now())
+	}
+	return resp, nil
+}
+
+// send makes the attempts of req.
+func (t *Transport) send(req *http.Request, host string) (*http.Response, error) {
+	retries := 0
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		retries = t.opts.Retries
+	}
+	for attempt := 0; ; attempt++ {
+		if t.limiter != nil {
+			if wait := t.limiter.reserve(host, t.now()); wait > 0 {
+				t.metrics.add(func(s *Stats) { s.RateLimited++ })
+				if err := t.sleep(req.Context(), wait); err != nil {
+					return nil, err
+				}
+			}
+		}
+		resp, err := t.next.RoundTrip(req)
+		if attempt == retries || !retryable(req, resp, err) {
+			return resp, err
+		}
+		wait := t.backoff(attempt, resp)
+		if resp != nil {
+			// the connection can be reused once the body is read
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		t.metrics.add(func(s *Stats) { s.Retries++ })
+		if err := t.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryable reports whether an attempt failed in a way another may not.
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		return req.Context().Err() == nil
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusBadGateway ||
+		resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusGatewayTimeout ||
+		resp.StatusCode == http.StatusInternalServerError
+}
+
+// backoff is the wait before attempt+1: the Retry-After of resp when it is
+// within RetryMax, else a jittered share of RetryBase doubled attempt times.
+func (t *Transport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs >= 0 {
+			if d := time.Duration(secs) * time.Second; d <= t.opts.RetryMax {
+				return d
+			}
+		}
+	}
+	d := t.opts.RetryBase << min(attempt, 30)
+	if d <= 0 || d > t.opts.RetryMax {
+		d = t.opts.RetryMax
+	}
+	return t.jitter(d)
+}
+
+func fullJitter(d time.Duration) time.Duration {
+	return time.Duration(rand.Int64N(int64(d) + 1))
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// storable reports whether the origin lets resp be reused.
+func storable(resp *http.Response) bool {
+	cc := strings.ToLower(resp.Header.Get("Cache-Control"))
+	return !strings.Contains(cc, "no-store") && !strings.Contains(cc, "private")
+}
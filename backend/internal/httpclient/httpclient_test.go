@@ -0,0 +1,314 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/logger"
+)
+
+// fakeClock stands in for the transport's time: sleeping moves it on.
+type fakeClock struct {
+	now    time.Time
+	slept  []time.Duration
+	cancel context.CancelFunc // called on the first sleep when set
+}
+
+func (c *fakeClock) install(t *Transport) {
+	t.now = func() time.Time { return c.now }
+	t.sleep = func(ctx context.Context, d time.Duration) error {
+		c.slept = append(c.slept, d)
+		c.now = c.now.Add(d)
+		if c.cancel != nil {
+			c.cancel()
+		}
+		return ctx.Err()
+	}
+	t.jitter = func(d time.Duration) time.Duration { return d }
+}
+
+func newTestClient(t *testing.T, o Options) (*http.Client, *fakeClock) {
+	t.Helper()
+	clock := &fakeClock{now: time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)}
+	tr := NewTransport(nil, o)
+	clock.install(tr)
+	return &http.Client{Transport: tr}, clock
+}
+
+// statsDelta returns a function reporting how the counters of name moved
+// since it was called; the registry outlives each test.
+func statsDelta(name string) func() Stats {
+	before := AllStats()[name]
+	return func() Stats {
+		now := AllStats()[name]
+		return Stats{
+			Requests:     now.Requests - before.Requests,
+			EgressDenied: now.EgressDenied - before.EgressDenied,
+			CacheHits:    now.CacheHits - before.CacheHits,
+			Retries:      now.Retries - before.Retries,
+			RateLimited:  now.RateLimited - before.RateLimited,
+			CircuitOpen:  now.CircuitOpen - before.CircuitOpen,
+			Failures:     now.Failures - before.Failures,
+		}
+	}
+}
+
+func get(t *testing.T, c *http.Client, url string) (int, string, error) {
+	t.Helper()
+	resp, err := c.Get(url)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(b), nil
+}
+
+func TestRetries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			_, _ = io.WriteString(w, "ok")
+		}
+	}))
+	defer srv.Close()
+
+	stats := statsDelta("test-retries")
+	c, clock := newTestClient(t, Options{Name: "test-retries", Retries: 2, RetryBase: 100 * time.Millisecond})
+	if code, body, err := get(t, c, srv.URL); err != nil || code != http.StatusOK || body != "ok" {
+		t.Fatalf("GET = %d %q, %v", code, body, err)
+	}
+	if calls.Load() != 3 || len(clock.slept) != 2 || clock.slept[0] != 100*time.Millisecond || clock.slept[1] != 2*time.Second {
+		t.Fatalf("calls = %d, waits = %v; want 3 calls after 100ms and the Retry-After of 2s", calls.Load(), clock.slept)
+	}
+	if s := stats(); s.Requests != 1 || s.Retries != 2 || s.Failures != 0 {
+		t.Fatalf("stats = %+v", s)
+	}
+
+	// out of retries, the last answer is the caller's
+	calls.Store(0)
+	c, _ = newTestClient(t, Options{Name: "test-retries", Retries: 0})
+	if code, _, err := get(t, c, srv.URL); err != nil || code != http.StatusServiceUnavailable {
+		t.Fatalf("GET without retries = %d, %v", code, err)
+	}
+
+	// POSTs aren't retried
+	calls.Store(0)
+	c, _ = newTestClient(t, Options{Name: "test-retries", Retries: 2})
+	resp, err := c.Post(srv.URL, "text/plain", strings.NewReader("x"))
+	if err != nil || resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != 1 {
+		t.Fatalf("POST = %v, %v after %d calls", resp, err, calls.Load())
+	}
+	resp.Body.Close()
+}
+
+func TestRetries_StopWhenCallerGivesUp(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	c, clock := newTestClient(t, Options{Name: "test-cancel", Retries: 5, BreakerFailures: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+	clock.cancel = cancel
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if _, err := c.Do(req); !errors.Is(err, context.Canceled) || calls.Load() != 1 {
+		t.Fatalf("Do = %v after %d calls; want context.Canceled after 1", err, calls.Load())
+	}
+	// a request its caller gave up on doesn't count against the host
+	clock.cancel = nil
+	if _, _, err := get(t, c, srv.URL); errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("GET after a cancelled request = %v; the breaker opened", err)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer other.Close()
+
+	stats := statsDelta("test-rate")
+	c, clock := newTestClient(t, Options{Name: "test-rate", RatePerHost: 2, Burst: 2})
+	for range 4 {
+		if _, _, err := get(t, c, srv.URL); err != nil {
+			t.Fatalf("GET: %v", err)
+		}
+	}
+	// the burst goes at once, then a request every half second
+	if len(clock.slept) != 2 || clock.slept[0] != 500*time.Millisecond || clock.slept[1] != 500*time.Millisecond {
+		t.Fatalf("waits = %v; want two of 500ms", clock.slept)
+	}
+	if _, _, err := get(t, c, other.URL); err != nil || len(clock.slept) != 2 {
+		t.Fatalf("GET to another host waited: %v, %v", clock.slept, err)
+	}
+	if s := stats(); s.RateLimited != 2 {
+		t.Fatalf("stats = %+v", s)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	var fail atomic.Bool
+	var calls atomic.Int32
+	fail.Store(true)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if fail.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	stats := statsDelta("test-breaker")
+	c, clock := newTestClient(t, Options{Name: "test-breaker", BreakerFailures: 2, BreakerCooldown: time.Minute})
+	for range 2 {
+		if code, _, err := get(t, c, srv.URL); err != nil || code != http.StatusInternalServerError {
+			t.Fatalf("GET = %d, %v", code, err)
+		}
+	}
+	if _, _, err := get(t, c, srv.URL); !errors.Is(err, ErrCircuitOpen) || calls.Load() != 2 {
+		t.Fatalf("GET with the breaker open = %v after %d calls", err, calls.Load())
+	}
+
+	// after the cooldown one request probes the host; its failure reopens
+	clock.now = clock.now.Add(time.Minute)
+	if code, _, _ := get(t, c, srv.URL); code != http.StatusInternalServerError {
+		t.Fatalf("probe = %d", code)
+	}
+	if _, _, err := get(t, c, srv.URL); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("GET after a failed probe = %v; want ErrCircuitOpen", err)
+	}
+	clock.now = clock.now.Add(time.Minute)
+	fail.Store(false)
+	for range 3 {
+		if code, _, err := get(t, c, srv.URL); err != nil || code != http.StatusOK {
+			t.Fatalf("GET after the host recovered = %d, %v", code, err)
+		}
+	}
+	if s := stats(); s.CircuitOpen != 2 || s.Failures != 3 {
+		t.Fatalf("stats = %+v", s)
+	}
+}
+
+func TestCache(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		switch r.URL.Path {
+		case "/private":
+			w.Header().Set("Cache-Control", "private")
+		case "/big":
+			_, _ = io.WriteString(w, strings.Repeat("x", 100))
+			return
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		}
+		w.Header().Set("X-Call", string(rune('0'+n)))
+		_, _ = io.WriteString(w, "body of "+r.URL.Path)
+	}))
+	defer srv.Close()
+
+	stats := statsDelta("test-cache")
+	c, clock := newTestClient(t, Options{Name: "test-cache", CacheTTL: time.Minute, CacheSize: 2, CacheMaxBody: 50})
+	for range 2 {
+		if code, body, err := get(t, c, srv.URL+"/a"); err != nil || code != http.StatusOK || body != "body of /a" {
+			t.Fatalf("GET /a = %d %q, %v", code, body, err)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("calls = %d; want the second GET from the cache", calls.Load())
+	}
+	for _, path := range []string{"/private", "/big", "/missing"} {
+		calls.Store(0)
+		for range 2 {
+			if _, body, err := get(t, c, srv.URL+path); err != nil || (path == "/big" && len(body) != 100) {
+				t.Fatalf("GET %s = %q, %v", path, body, err)
+			}
+		}
+		if calls.Load() != 2 {
+			t.Fatalf("GET %s twice made %d calls; want it not cached", path, calls.Load())
+		}
+	}
+
+	calls.Store(0)
+	clock.now = clock.now.Add(time.Minute)
+	if _, _, _ = get(t, c, srv.URL+"/a"); calls.Load() != 1 {
+		t.Fatalf("GET after the TTL made %d calls; want 1", calls.Load())
+	}
+	// /a is evicted once two newer responses are cached
+	_, _, _ = get(t, c, srv.URL+"/b")
+	_, _, _ = get(t, c, srv.URL+"/c")
+	calls.Store(0)
+	if _, _, _ = get(t, c, srv.URL+"/a"); calls.Load() != 1 {
+		t.Fatalf("GET of an evicted response made %d calls; want 1", calls.Load())
+	}
+	if s := stats(); s.CacheHits != 1 {
+		t.Fatalf("stats = %+v", s)
+	}
+}
+
+func TestEgress(t *testing.T) {
+	if _, err := ParseAllowlist([]string{"openlibrary.org", "https://example.com"}); err == nil {
+		t.Fatal("ParseAllowlist accepted a URL")
+	}
+	allow, err := ParseAllowlist([]string{"127.0.0.1", "*.Example.com."})
+	if err != nil {
+		t.Fatalf("ParseAllowlist: %v", err)
+	}
+	for host, want := range map[string]bool{"127.0.0.1": true, "a.example.com": true, "A.B.EXAMPLE.COM": true, "example.com": false, "badexample.com": false, "localhost": false} {
+		if got := allow.Allows(host); got != want {
+			t.Errorf("Allows(%q) = %v; want %v", host, got, want)
+		}
+	}
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Redirect(w, r, "http://localhost/next?token=secret", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	var logs bytes.Buffer
+	ctx := logger.WithLogger(context.Background(), slog.New(slog.NewJSONHandler(&logs, nil)))
+	stats := statsDelta("test-egress")
+	c, _ := newTestClient(t, Options{Name: "test-egress", Egress: allow})
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	// the first hop is allowed, the redirect to localhost isn't
+	if _, err := c.Do(req); !errors.Is(err, ErrEgressDenied) || calls.Load() != 1 {
+		t.Fatalf("Do = %v after %d calls; want ErrEgressDenied after 1", err, calls.Load())
+	}
+	if s := stats(); s.Requests != 2 || s.EgressDenied != 1 {
+		t.Fatalf("stats = %+v", s)
+	}
+	if out := logs.String(); !strings.Contains(out, `"client":"test-egress"`) || !strings.Contains(out, `"host":"localhost"`) || strings.Contains(out, "secret") {
+		t.Fatalf("log = %s; want the client and host, without the query", out)
+	}
+}
+
+func TestNew_KeepsClient(t *testing.T) {
+	base := &http.Client{Timeout: 3 * time.Second, CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	c := New(base, Options{Name: "test-new"})
+	if c == base || c.Timeout != base.Timeout || c.CheckRedirect == nil || base.Transport != nil {
+		t.Fatalf("New = %+v; want a copy of base with the layers as its transport", c)
+	}
+	if _, ok := c.Transport.(*Transport); !ok {
+		t.Fatalf("Transport = %T", c.Transport)
+	}
+}
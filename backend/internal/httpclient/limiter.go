@@ -0,0 +1,63 @@
+package httpclient
+
+import (
+	"sync"
+	"time"
+)
+
+// limiter keeps a token bucket per host. Unlike the API's own rate limit,
+// it doesn't refuse a request beyond the rate: it books the next token and
+// says how long to wait for it, so requests to a busy host queue up in
+// turn. Buckets that have refilled are dropped, so memory follows the hosts
+// recently contacted.
+type limiter struct {
+	rate  float64 // tokens added per second
+	burst float64 // bucket size
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64 // below 0 when requests wait for tokens to come
+	at     time.Time
+}
+
+func newLimiter(rate float64, burst int) *limiter {
+	return &limiter{rate: rate, burst: float64(burst), buckets: map[string]*bucket{}}
+}
+
+// reserve takes a token from host's bucket and returns how long until it is
+// there.
+func (l *limiter) reserve(host string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+	b, ok := l.buckets[host]
+	if !ok {
+		b = &bucket{tokens: l.burst, at: now}
+		l.buckets[host] = b
+	}
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.at).Seconds()*l.rate)
+	b.at = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / l.rate * float64(time.Second))
+}
+
+// sweep drops the buckets that are full again, at most once a minute. The
+// caller holds l.mu.
+func (l *limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for host, b := range l.buckets {
+		if b.tokens+now.Sub(b.at).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, host)
+		}
+	}
+}
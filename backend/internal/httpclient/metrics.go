@@ -0,0 +1,81 @@
+package httpclient
+
+import (
+	"expvar"
+	"maps"
+	"sync"
+)
+
+// Stats are the counters of the clients with one name.
+type Stats struct {
+	// Requests made through the client, cache hits included.
+	Requests int64 `json:"requests"`
+	// EgressDenied requests were refused because their host is off the
+	// egress allowlist.
+	EgressDenied int64 `json:"egress_denied"`
+	// CacheHits answered from the cache.
+	CacheHits int64 `json:"cache_hits"`
+	// Retries are the attempts beyond the first.
+	Retries int64 `json:"retries"`
+	// RateLimited attempts waited for their host's rate limit.
+	RateLimited int64 `json:"rate_limited"`
+	// CircuitOpen requests failed at once because their host's breaker
+	// was open.
+	CircuitOpen int64 `json:"circuit_open"`
+	// Failures are requests that ended in an error, a 429 or a 5xx after
+	// their retries.
+	Failures int64 `json:"failures"`
+}
+
+// Metrics counts what the clients of one name do.
+type Metrics struct {
+	mu    sync.Mutex
+	stats Stats
+}
+
+func (m *Metrics) add(f func(*Stats)) {
+	m.mu.Lock()
+	f(&m.stats)
+	m.mu.Unlock()
+}
+
+// Stats returns the counters so far.
+func (m *Metrics) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stats
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]*Metrics{}
+)
+
+func metricsFor(name string) *Metrics {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	m, ok := registry[name]
+	if !ok {
+		m = &Metrics{}
+		registry[name] = m
+	}
+	return m
+}
+
+// AllStats returns the counters of every client by name.
+func AllStats() map[string]Stats {
+	registryMu.Lock()
+	ms := maps.Clone(registry)
+	registryMu.Unlock()
+	stats := make(map[string]Stats, len(ms))
+	for name, m := range ms {
+		stats[name] = m.Stats()
+	}
+	return stats
+}
+
+// Publish exposes AllStats as the expvar "http_clients" (served on
+// /debug/vars). It panics if called twice, like expvar.Publish.
+func Publish() {
+	expvar.Publish("http_clients", expvar.Func(func() any { return AllStats() }))
+}
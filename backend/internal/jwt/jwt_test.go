@@ -0,0 +1,41 @@
+package jwt
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	secret := []byte("s3cret")
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	tok, err := Sign(Claims{Subject: "7", Email: "ann@example.com", IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Hour).Unix()}, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := Verify(tok, secret, now.Add(time.Minute))
+	if err != nil || c.Subject != "7" || c.Email != "ann@example.com" {
+		t.Fatalf("Verify = %+v, %v", c, err)
+	}
+
+	parts := strings.Split(tok, ".")
+	none := "eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0." + parts[1] + "."
+	cases := []struct {
+		name  string
+		token string
+		key   string
+		at    time.Time
+		want  error
+	}{
+		{"expired", tok, "s3cret", now.Add(time.Hour), ErrExpired},
+		{"wrong secret", tok, "other", now, ErrSignature},
+		{"tampered payload", parts[0] + "." + parts[1] + "x." + parts[2], "s3cret", now, ErrSignature},
+		{"alg none", none, "s3cret", now, ErrMalformed},
+		{"not a jwt", "abc", "s3cret", now, ErrMalformed},
+	}
+	for _, c := range cases {
+		if _, err := Verify(c.token, []byte(c.key), c.at); err != c.want {
+			t.Errorf("%s: err = %v; want %v", c.name, err, c.want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"context"
 	"log/slog"
 	"os"
 )
@@ -10,3 +11,20 @@ var Log *slog.Logger
 func init() {
 	Log = slog.New(slog.NewJSONHandler(os.Stdout, nil))
 }
+
+type loggerKey struct{}
+
+// WithLogger returns a copy of ctx carrying l, e.g. Log with the request ID
+// of the request ctx belongs to.
+func WithLogger(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// From returns the logger stored in ctx, or Log if there is none, so code
+// logs with the request's attributes whenever it runs for a request.
+func From(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return Log
+}
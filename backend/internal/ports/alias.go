@@ -0,0 +1,27 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+type AliasRepository interface {
+	ListByBook(ctx context.Context, bookID int64) ([]domain.Alias, error)
+	Add(ctx context.Context, a *domain.Alias) (int64, error)
+	// Delete removes alias id of bookID and reports whether it existed.
+	Delete(ctx context.Context, bookID, id int64) (bool, error)
+}
+
+// AliasService manages the alternate titles of a book.
+type AliasService interface {
+	ListAliases(ctx context.Context, bookID int64) ([]domain.Alias, error)
+	AddAlias(ctx context.Context, bookID int64, in AddAliasInput) (*domain.Alias, error)
+	DeleteAlias(ctx context.Context, bookID, aliasID int64) error
+}
+
+// AddAliasInput for POST /books/{id}/aliases.
+// swagger:model AddAliasInput
+type AddAliasInput struct {
+	Alias string `json:"alias" example:"Cien años de soledad"`
+}
@@ -0,0 +1,41 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, k *domain.APIKey) (int64, error)
+	List(ctx context.Context) ([]domain.APIKey, error)
+	// GetByHash returns nil if no key, revoked or not, has hash.
+	GetByHash(ctx context.Context, hash string) (*domain.APIKey, error)
+	// Revoke marks key id revoked and reports whether it existed.
+	Revoke(ctx context.Context, id int64, at time.Time) (bool, error)
+}
+
+// APIKeyService issues API keys and checks the ones clients send.
+type APIKeyService interface {
+	// Authenticate returns the key matching key, or nil if it is unknown or
+	// revoked.
+	Authenticate(ctx context.Context, key string) (*domain.APIKey, error)
+	IssueAPIKey(ctx context.Context, in IssueAPIKeyInput) (*IssuedAPIKey, error)
+	ListAPIKeys(ctx context.Context) ([]domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64) error
+}
+
+// IssueAPIKeyInput for POST /admin/api-keys.
+// swagger:model IssueAPIKeyInput
+type IssueAPIKeyInput struct {
+	Name   string   `json:"name" example:"warehouse-sync"`
+	Scopes []string `json:"scopes"`
+}
+
+// IssuedAPIKey carries a new key in the clear; it can't be shown again.
+// swagger:model IssuedAPIKey
+type IssuedAPIKey struct {
+	domain.APIKey
+	Key string `json:"key" example:"bfk_3q2J9xN0yZ..."`
+}
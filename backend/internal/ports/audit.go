@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// AuditRepository reads the audit trail that book writes append to.
+type AuditRepository interface {
+	// BookAudit returns up to limit entries of book id, newest first, only
+	// those older than entry before when it isn't 0.
+	BookAudit(ctx context.Context, id int64, before int64, limit int) ([]domain.AuditEntry, error)
+}
+
+type AuditService interface {
+	BookAudit(ctx context.Context, id int64, before int64, limit int) ([]domain.AuditEntry, error)
+}
@@ -0,0 +1,30 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// AuthorRepository stores the author read model.
+type AuthorRepository interface {
+	// Summary returns the stored summary of author id, or nil if there is none.
+	Summary(ctx context.Context, id string) (*domain.AuthorSummary, error)
+	SaveSummary(ctx context.Context, s *domain.AuthorSummary) error
+	DeleteSummary(ctx context.Context, id string) error
+	// IndexBook files bookID under authorID ("" removes it) and returns the
+	// author it was filed under before ("" if none).
+	IndexBook(ctx context.Context, bookID int64, authorID string) (string, error)
+	// Books returns the current rows of the books filed under authorID.
+	Books(ctx context.Context, authorID string) ([]domain.Book, error)
+	// Reset empties the read model before a rebuild.
+	Reset(ctx context.Context) error
+	// Cursor is the change log position the model reflects.
+	Cursor(ctx context.Context) (int64, error)
+	SaveCursor(ctx context.Context, cursor int64) error
+}
+
+// AuthorService serves author pages.
+type AuthorService interface {
+	AuthorSummary(ctx context.Context, id string) (*domain.AuthorSummary, error)
+}
@@ -0,0 +1,24 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// ErrBeyondHistory is returned for instants the change log can no longer
+// reconstruct: before its retention window, or from entries written before
+// payloads were recorded.
+var ErrBeyondHistory = errors.New("the change log doesn't go back that far")
+
+// BookHistory reconstructs books as they were at a past instant, for
+// ?as_of= reads.
+type BookHistory interface {
+	// BookAsOf returns book id as it was at t, or nil if it didn't exist
+	// then.
+	BookAsOf(ctx context.Context, id int64, t time.Time) (*domain.Book, error)
+	// BooksAsOf returns every book that existed at t, newest first.
+	BooksAsOf(ctx context.Context, t time.Time) ([]domain.Book, error)
+}
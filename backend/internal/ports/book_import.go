@@ -0,0 +1,43 @@
+package ports
+
+// Outcomes of one import row.
+const (
+	ImportInserted = "inserted"
+	ImportSkipped  = "skipped"
+	ImportFailed   = "failed"
+	// ImportRequeued rows were not reached before a shutdown; they are
+	// imported when the parked remainder of the import is retried.
+	ImportRequeued = "requeued"
+)
+
+// ImportRow is one book read from an import file. Row counts data rows from
+// 1 (the CSV header is not a row); Errors holds fields the file format could
+// not parse, such as a price that is not a number.
+type ImportRow struct {
+	Row    int
+	Book   CreateBookInput
+	Errors map[string]string
+}
+
+// ImportRowResult reports what happened to one row: inserted (ID is the new
+// book), skipped because the ISBN already exists (ID is that book) or
+// appeared earlier in the file, failed with a message and any per-field
+// validation errors, or requeued because the server shut down first.
+type ImportRowResult struct {
+	Row    int               `json:"row"`
+	Status string            `json:"status" enums:"inserted,skipped,failed,requeued"`
+	ID     int64             `json:"id,omitempty"`
+	ISBN   string            `json:"isbn,omitempty"`
+	Error  string            `json:"error,omitempty"`
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// ImportResult for POST /books/import.
+// swagger:model ImportResult
+type ImportResult struct {
+	Inserted int               `json:"inserted"`
+	Skipped  int               `json:"skipped"`
+	Failed   int               `json:"failed"`
+	Requeued int               `json:"requeued"`
+	Rows     []ImportRowResult `json:"rows"`
+}
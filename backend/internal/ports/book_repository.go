@@ -2,14 +2,169 @@ package ports
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/gerry-sabar/byfood/internal/domain"
 )
 
-type BookRepository interface {
-	List(ctx context.Context) ([]domain.Book, error)
+// BookReader reads books. Decorators that only concern reads (caching,
+// metrics, read-replica routing) wrap a BookReader, and read-only
+// deployments wire nothing else.
+type BookReader interface {
+	// List returns the books matching f, newest first.
+	List(ctx context.Context, f ListFilter) ([]domain.Book, error)
+	// Search returns books whose title or author contains q, ignoring case
+	// and accents.
+	Search(ctx context.Context, q string) ([]domain.Book, error)
+	// ListPage returns one page of the books matching f together with the
+	// total number of matches.
+	ListPage(ctx context.Context, f ListFilter, page Page) ([]domain.Book, int, error)
 	GetByID(ctx context.Context, id int64) (*domain.Book, error)
+}
+
+// BookWriter changes books.
+type BookWriter interface {
 	Create(ctx context.Context, b *domain.Book) (int64, error)
-	Update(ctx context.Context, b *domain.Book) error
+	// Update writes b only if the stored updated_at still equals prevUpdatedAt,
+	// otherwise it returns ErrConcurrentUpdate.
+	Update(ctx context.Context, b *domain.Book, prevUpdatedAt time.Time) error
+	// Delete removes book id; it fails with ErrBookOnLoan while the book
+	// is checked out.
 	Delete(ctx context.Context, id int64) error
+	// Split atomically writes source (compare-and-swap on prevUpdatedAt, like
+	// Update), inserts edition and moves aliasIDs from source to the edition.
+	// It returns the edition's id.
+	Split(ctx context.Context, source *domain.Book, prevUpdatedAt time.Time, edition *domain.Book, aliasIDs []int64) (int64, error)
+}
+
+// BookRepository reads and writes books.
+type BookRepository interface {
+	BookReader
+	BookWriter
+}
+
+// BookCountEstimator estimates how many books are stored without counting
+// them, e.g. from table statistics.
+type BookCountEstimator interface {
+	EstimateBooks(ctx context.Context) (int64, error)
+}
+
+// ListFilter narrows a book listing. Zero fields don't filter; the year and
+// price bounds are inclusive. String filters ignore case and accents (and
+// ISBN hyphens) unless Exact is set; Tag always ignores them.
+type ListFilter struct {
+	// Q is a free-text search over title, author and aliases.
+	Q string
+	// Synonyms are other searches, folded with domain.SearchKey, that Q
+	// also matches as; Exact ignores them.
+	Synonyms []string
+	Author   string
+	ISBN     string
+	Tag      string
+	Exact    bool
+	YearFrom *int
+	YearTo   *int
+	PriceMin *float64
+	PriceMax *float64
+	// SeriesID keeps the books of one series.
+	SeriesID int64
+	// WorkID keeps the editions of one work: the books with this work_id
+	// and the book with this id.
+	WorkID int64
+	// AfterID keeps the books with a larger id, for paging through every
+	// book in id order.
+	AfterID int64
+}
+
+// Matches evaluates f against one book the way the MySQL repository does:
+// Q as a folded substring of title, author or an alias, Author as the whole
+// folded name and ISBN without hyphens against the ISBN or ISBN-10, or all of
+// them verbatim when Exact, and Tag as one of the book's tags. A book
+// matching one of Synonyms matches Q.
+func (f ListFilter) Matches(b domain.Book) bool {
+	key, isbnKey := domain.SearchKey, domain.ISBNKey
+	if f.Exact {
+		key, isbnKey = strings.TrimSpace, strings.TrimSpace
+	}
+	if qs := f.queries(key); len(qs) > 0 {
+		found := false
+		for _, q := range qs {
+			found = found || strings.Contains(key(b.Title), q) || strings.Contains(key(b.Author), q)
+			for _, a := range b.Aliases {
+				found = found || strings.Contains(key(a), q)
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	switch {
+	case f.Author != "" && key(f.Author) != key(b.Author):
+		return false
+	case f.ISBN != "" && isbnKey(f.ISBN) != isbnKey(b.ISBN) && (f.Exact || isbnKey(f.ISBN) != b.ISBN10):
+		return false
+	case f.Tag != "" && !slices.ContainsFunc(b.Tags, func(t string) bool { return domain.TagKey(t) == domain.TagKey(f.Tag) }):
+		return false
+	case f.YearFrom != nil && b.PublicationYear < *f.YearFrom, f.YearTo != nil && b.PublicationYear > *f.YearTo:
+		return false
+	case f.PriceMin != nil && b.Price < *f.PriceMin, f.PriceMax != nil && b.Price > *f.PriceMax:
+		return false
+	case f.SeriesID != 0 && (b.SeriesID == nil || *b.SeriesID != f.SeriesID):
+		return false
+	case f.WorkID != 0 && b.ID != f.WorkID && (b.WorkID == nil || *b.WorkID != f.WorkID):
+		return false
+	case f.AfterID != 0 && b.ID <= f.AfterID:
+		return false
+	}
+	return true
+}
+
+// Score ranks a book matching f.Q the way the MySQL repository does:
+// r.TitleWeight when Q is in its title or an alias, plus r.AuthorWeight when
+// it is in its author, plus r.RecencyBoost divided by one plus the years from
+// its publication to r.Year. Q and its Synonyms are compared as in Matches.
+func (f ListFilter) Score(b domain.Book, r Ranking) float64 {
+	key := domain.SearchKey
+	if f.Exact {
+		key = strings.TrimSpace
+	}
+	var score float64
+	var title, author bool
+	for _, q := range f.queries(key) {
+		title = title || strings.Contains(key(b.Title), q)
+		for _, a := range b.Aliases {
+			title = title || strings.Contains(key(a), q)
+		}
+		author = author || strings.Contains(key(b.Author), q)
+	}
+	if title {
+		score += r.TitleWeight
+	}
+	if author {
+		score += r.AuthorWeight
+	}
+	return score + r.RecencyBoost/float64(1+max(r.Year-b.PublicationYear, 0))
 }
+
+// queries returns Q under key followed by its Synonyms, or nothing when Q
+// is blank.
+func (f ListFilter) queries(key func(string) string) []string {
+	q := key(f.Q)
+	if q == "" {
+		return nil
+	}
+	if f.Exact {
+		return []string{q}
+	}
+	return append([]string{q}, f.Synonyms...)
+}
+
+// ErrConcurrentUpdate is returned when a row changed between read and write.
+var ErrConcurrentUpdate = errors.New("book was modified concurrently")
+
+// ErrReadOnly is returned by writes to a book service built without a
+// BookWriter.
+var ErrReadOnly = errors.New("books are read-only in this deployment")
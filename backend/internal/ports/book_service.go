@@ -2,16 +2,96 @@ package ports
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/gerry-sabar/byfood/internal/domain"
 )
 
 type BookService interface {
 	ListBooks(ctx context.Context) ([]domain.Book, error)
+	SearchBooks(ctx context.Context, q string) ([]domain.Book, error)
+	// ListBooksPage returns one page of the books matching f.
+	ListBooksPage(ctx context.Context, f ListFilter, page Page) (*BookPage, error)
 	GetBook(ctx context.Context, id int64) (*domain.Book, error)
+	// GetBookByISBN finds a book by its ISBN-10 or ISBN-13; nil when none
+	// has it.
+	GetBookByISBN(ctx context.Context, isbn string) (*domain.Book, error)
 	CreateBook(ctx context.Context, in CreateBookInput) (*domain.Book, error)
 	UpdateBook(ctx context.Context, id int64, in UpdateBookInput) (*domain.Book, error)
 	DeleteBook(ctx context.Context, id int64) error
+	// SplitBook creates a new edition from book id, linking both under the
+	// same work.
+	SplitBook(ctx context.Context, id int64, in SplitBookInput) (*SplitBookResult, error)
+	// ImportBooks creates a book per row, reporting every row's outcome. A
+	// bad row doesn't stop the import; only an oversized import is rejected
+	// as a whole.
+	ImportBooks(ctx context.Context, rows []ImportRow) (*ImportResult, error)
+}
+
+// Page selects a window of a list ordered by Sort, newest first when Sort is
+// zero. A zero Limit means no limit. Ranking, when set, orders a search by
+// ListFilter.Score instead of Sort.
+type Page struct {
+	Limit   int
+	Offset  int
+	Sort    Sort
+	Ranking *Ranking
+}
+
+// Ranking is the weights a search is ranked with and the year book ages are
+// counted up to.
+type Ranking struct {
+	domain.RankingWeights
+	Year int
+}
+
+// ErrPageSizeExceeded means a list asked for a larger page than PageLimits
+// allow.
+var ErrPageSizeExceeded = errors.New("page size exceeded")
+
+// PageLimits bound the pages of book lists: Default is the limit of
+// requests that send none, and Max the largest one accepted. Zero fields
+// don't limit.
+type PageLimits struct {
+	Default int
+	Max     int
+}
+
+// Apply sets p's limit to the default when it has none, and fails with an
+// error wrapping ErrPageSizeExceeded when it is larger than Max.
+func (l PageLimits) Apply(p *Page) error {
+	if p.Limit == 0 {
+		p.Limit = l.Default
+	}
+	if l.Max > 0 && p.Limit > l.Max {
+		return fmt.Errorf("%w: limit must be at most %d", ErrPageSizeExceeded, l.Max)
+	}
+	return nil
+}
+
+// Sort orders a book list by Field, a JSON field name from SortFields.
+// Collation, a locale from SortCollations, sets how title and author
+// compare; empty uses the language-neutral Unicode order. Comparisons
+// ignore case and accents, and equal values go newest first.
+type Sort struct {
+	Field     string
+	Desc      bool
+	Collation string
+}
+
+// SortFields are the fields a book list can be sorted by.
+var SortFields = []string{"id", "title", "author", "price", "publication_year"}
+
+// SortCollations are the locales (BCP 47) titles and authors can be sorted
+// in, e.g. "tr" puts dotless ı before i.
+var SortCollations = []string{"cs", "da", "de", "es", "hr", "hu", "pl", "ro", "ru", "sk", "sv", "tr", "vi"}
+
+// BookPage is one page of books plus the number of books across all pages.
+type BookPage struct {
+	Books []domain.Book
+	Total int
 }
 
 // CreateBookInput for POST /books.
@@ -22,6 +102,19 @@ type CreateBookInput struct {
 	ISBN            string  `json:"isbn"`
 	Price           float64 `json:"price"`
 	PublicationYear int     `json:"publication_year"`
+	// PublisherID files the book under an existing publisher.
+	PublisherID *int64 `json:"publisher_id,omitempty"`
+	// SeriesID puts the book in an existing series, at SeriesPosition.
+	SeriesID       *int64 `json:"series_id,omitempty"`
+	SeriesPosition *int   `json:"series_position,omitempty"`
+	// Format is hardcover, paperback or ebook.
+	Format string `json:"format,omitempty" enums:"hardcover,paperback,ebook"`
+	// WorkID makes the book another edition of the work of this book id.
+	WorkID *int64 `json:"work_id,omitempty"`
+	// Autofill fills a blank title, author and publication year from the
+	// external catalogues the ISBN is found in. When they can't be reached
+	// the book is created from what was sent.
+	Autofill bool `json:"autofill,omitempty"`
 }
 
 // UpdateBookInput for PUT /books/{id}.
@@ -32,10 +125,51 @@ type UpdateBookInput struct {
 	ISBN            *string  `json:"isbn"`
 	Price           *float64 `json:"price"`
 	PublicationYear *int     `json:"publication_year"`
+	// PublisherID moves the book to another publisher; 0 removes its
+	// publisher.
+	PublisherID *int64 `json:"publisher_id,omitempty"`
+	// SeriesID moves the book to another series; 0 takes it out of its
+	// series. SeriesPosition 0 clears the position.
+	SeriesID       *int64 `json:"series_id,omitempty"`
+	SeriesPosition *int   `json:"series_position,omitempty"`
+	// Format is hardcover, paperback or ebook; "" clears it.
+	Format *string `json:"format,omitempty" enums:"hardcover,paperback,ebook"`
+	// WorkID makes the book another edition of the work of this book id;
+	// 0 makes it a standalone book.
+	WorkID *int64 `json:"work_id,omitempty"`
+	// BaseUpdatedAt is the updated_at the client last read. When set, fields
+	// changed by someone else after it are rejected with 409 instead of
+	// silently overwritten; edits to other fields are merged.
+	BaseUpdatedAt *time.Time `json:"base_updated_at,omitempty"`
+	// Version is the version the client last read (also accepted as an
+	// If-Match header). When set, the update is rejected with 409 if the
+	// book has been written since, whichever fields changed.
+	Version *int64 `json:"version,omitempty"`
+}
+
+// SplitBookInput for POST /books/{id}/split. The new edition starts as a
+// copy of the book with Fields applied on top; ISBN is required since two
+// editions never share one.
+// swagger:model SplitBookInput
+type SplitBookInput struct {
+	Fields UpdateBookInput `json:"fields"`
+	// AliasIDs are aliases that belong to the new edition and move with it.
+	AliasIDs []int64 `json:"alias_ids,omitempty"`
+}
+
+// SplitBookResult holds both editions after a split.
+type SplitBookResult struct {
+	Source  domain.Book
+	Edition domain.Book
 }
 
 // ErrorResponse matches your httpError shape.
 // swagger:model ErrorResponse
 type ErrorResponse struct {
 	Error string `json:"error" example:"not found"`
+	// Code identifies errors clients may want to handle specially, such as
+	// "page_size_exceeded".
+	Code string `json:"code,omitempty" example:"page_size_exceeded"`
+	// RequestID is the request's X-Request-ID, to quote when reporting it.
+	RequestID string `json:"request_id,omitempty" example:"5f2b0c9e8a1d4e7f9b3c6a2d1e0f8b7c"`
 }
@@ -0,0 +1,64 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// CategoryRepository files books under categories.
+type CategoryRepository interface {
+	// Assign adds and then removes categories of every book in bookIDs, in
+	// one transaction. Adding a category a book has, or removing one it
+	// hasn't, is a no-op.
+	Assign(ctx context.Context, bookIDs []int64, add, remove []string) error
+}
+
+// BulkJobRepository keeps the progress of background bulk jobs.
+type BulkJobRepository interface {
+	Create(ctx context.Context, j *domain.BulkJob) error
+	// Update saves the status, progress and error of j.
+	Update(ctx context.Context, j *domain.BulkJob) error
+	// Get returns nil if no job has id.
+	Get(ctx context.Context, id string) (*domain.BulkJob, error)
+}
+
+// BulkTagService adds and removes categories of every book a filter
+// matches.
+type BulkTagService interface {
+	// BulkTag previews the change, or starts a job applying it.
+	BulkTag(ctx context.Context, in BulkTagRequest) (*BulkTagResponse, error)
+	// Job returns nil if no job has id.
+	Job(ctx context.Context, id string) (*domain.BulkJob, error)
+}
+
+// BulkTagRequest for POST /books/bulk-tag. Categories are given by name or
+// slug. DryRun defaults to true; send false to start the job.
+// swagger:model BulkTagRequest
+type BulkTagRequest struct {
+	// Filter selects books like the re-price filter does.
+	Filter RepriceFilter `json:"filter"`
+	Add    []string      `json:"add,omitempty" example:"science-fiction"`
+	Remove []string      `json:"remove,omitempty" example:"fantasy"`
+	DryRun *bool         `json:"dry_run,omitempty"`
+}
+
+// BulkTagItem is a book the change would alter, with its categories after.
+type BulkTagItem struct {
+	BookID     int64    `json:"book_id"`
+	Title      string   `json:"title"`
+	Categories []string `json:"categories"`
+}
+
+// swagger:model BulkTagResponse
+type BulkTagResponse struct {
+	DryRun bool `json:"dry_run"`
+	// Matched books pass the filter; Changed of them would get different
+	// categories.
+	Matched int `json:"matched"`
+	Changed int `json:"changed"`
+	// Preview lists the first changed books, at most 100.
+	Preview []BulkTagItem `json:"preview"`
+	// Job is the started job, when not a dry run and some book changes.
+	Job *domain.BulkJob `json:"job,omitempty"`
+}
@@ -0,0 +1,20 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+type ChangeFeed interface {
+	// Since returns changes after cursor, blocking up to wait when there are none yet.
+	Since(ctx context.Context, cursor int64, limit int, wait time.Duration) ([]domain.Change, error)
+}
+
+// ChangesResponse for GET /books/changes.
+// swagger:model ChangesResponse
+type ChangesResponse struct {
+	Changes []domain.Change `json:"changes"`
+	Cursor  int64           `json:"cursor"`
+}
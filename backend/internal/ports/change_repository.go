@@ -0,0 +1,25 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+type ChangeRepository interface {
+	// Append stores c and assigns its version, the next one for its entity.
+	Append(ctx context.Context, c *domain.Change) (int64, error)
+	ListSince(ctx context.Context, cursor int64, limit int) ([]domain.Change, error)
+	LatestID(ctx context.Context) (int64, error)
+	// BookAt returns the last change to book bookID made at or before t, or
+	// nil if there was none.
+	BookAt(ctx context.Context, bookID int64, t time.Time) (*domain.Change, error)
+	// BooksAt returns the last change to every book made at or before t,
+	// newest book first.
+	BooksAt(ctx context.Context, t time.Time) ([]domain.Change, error)
+	// Compact deletes entries created before cutoff that a later entry of
+	// the same entity supersedes, so the latest state of every entity
+	// (including deletes) survives. It returns how many entries were removed.
+	Compact(ctx context.Context, cutoff time.Time) (int64, error)
+}
@@ -0,0 +1,27 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// CleanupStatsRepository persists daily counters of URL cleanup requests.
+type CleanupStatsRepository interface {
+	// AddCleanupUsage increments the counter of every key in counts by its
+	// value.
+	AddCleanupUsage(ctx context.Context, counts map[domain.CleanupUsageKey]int64) error
+	// CleanupUsageSince sums the counters of the days from since on per
+	// domain, operation and outcome.
+	CleanupUsageSince(ctx context.Context, since time.Time) ([]domain.CleanupUsage, error)
+}
+
+// CleanupStatsService counts URL cleanup requests and reports on them.
+type CleanupStatsService interface {
+	// Record counts one request without waiting on storage.
+	Record(host, operation, outcome string)
+	// CleanupStats reports on the last days days, listing the top busiest
+	// domains.
+	CleanupStats(ctx context.Context, days, top int) (*domain.CleanupStats, error)
+}
@@ -0,0 +1,31 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+type DeadLetterRepository interface {
+	Add(ctx context.Context, d *domain.DeadLetter) (int64, error)
+	// List returns the newest dead letters first, only those of kind when it
+	// is not empty.
+	List(ctx context.Context, kind string, limit int) ([]domain.DeadLetter, error)
+	// GetByID returns nil if there is no dead letter id.
+	GetByID(ctx context.Context, id int64) (*domain.DeadLetter, error)
+	// Failed records another failed attempt at dead letter id.
+	Failed(ctx context.Context, id int64, reason string, at time.Time) error
+	// Delete removes dead letter id and reports whether it existed.
+	Delete(ctx context.Context, id int64) (bool, error)
+}
+
+// DeadLetterService is the operator console for failed async work.
+type DeadLetterService interface {
+	ListDeadLetters(ctx context.Context, kind string, limit int) ([]domain.DeadLetter, error)
+	GetDeadLetter(ctx context.Context, id int64) (*domain.DeadLetter, error)
+	// RetryDeadLetter runs the work again and removes the dead letter once it
+	// succeeds; on failure the dead letter stays with the new error.
+	RetryDeadLetter(ctx context.Context, id int64) error
+	DiscardDeadLetter(ctx context.Context, id int64) error
+}
@@ -0,0 +1,53 @@
+package ports
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// ErrReplayRunning is returned when a replay to the same consumer is still
+// running on this instance.
+var ErrReplayRunning = errors.New("a replay to this consumer is already running")
+
+// EventService reads the change log as domain events and replays it to the
+// consumers that follow it.
+type EventService interface {
+	// ListEvents returns up to limit events after offset since, oldest first.
+	ListEvents(ctx context.Context, since int64, limit int) (*EventsResponse, error)
+	// EventConsumers names the consumers events can be replayed to.
+	EventConsumers() []string
+	// ReplayEvents hands the events after in.Since, up to the newest one at
+	// the start, to consumer in.Consumer.
+	ReplayEvents(ctx context.Context, in ReplayInput) (*ReplayResult, error)
+}
+
+// EventPublisher hands domain events to a message broker for downstream
+// systems. Publish returns once the broker took the event, or with the
+// reason it didn't.
+type EventPublisher interface {
+	Publish(ctx context.Context, e domain.Event) error
+}
+
+// EventsResponse for GET /events.
+type EventsResponse struct {
+	Events []domain.Event `json:"events"`
+	// Next is the offset to pass as the next since: the offset of the last
+	// event, or since itself when there were none.
+	Next int64 `json:"next"`
+}
+
+type ReplayInput struct {
+	Consumer string `json:"consumer" example:"authors"`
+	// Since is the offset to replay after; 0 replays the whole log.
+	Since int64 `json:"since" minimum:"0"`
+}
+
+type ReplayResult struct {
+	Consumer string `json:"consumer"`
+	Since    int64  `json:"since"`
+	// Until is the offset of the last event replayed.
+	Until  int64 `json:"until"`
+	Events int   `json:"events"`
+}
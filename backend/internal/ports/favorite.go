@@ -0,0 +1,46 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// ErrNoFavoritesAccount means a caller without a user account, anonymous
+// or an API key, tried to manage favorites.
+var ErrNoFavoritesAccount = errors.New("favorites belong to a user account; sign in")
+
+type FavoriteRepository interface {
+	// Add marks book bookID a favorite of user userID at at. found is false
+	// if the book doesn't exist; adding a favorite twice keeps the first.
+	Add(ctx context.Context, userID, bookID int64, at time.Time) (found bool, err error)
+	// Remove reports whether book bookID was a favorite of user userID.
+	Remove(ctx context.Context, userID, bookID int64) (bool, error)
+	// ListBooks returns limit of the favorite books of user userID from
+	// offset, the latest favorite first, and how many there are in all.
+	ListBooks(ctx context.Context, userID int64, limit, offset int) ([]domain.Book, int, error)
+}
+
+// FavoriteService manages the favorite books of the signed-in user.
+type FavoriteService interface {
+	Favorite(ctx context.Context, bookID int64) error
+	Unfavorite(ctx context.Context, bookID int64) error
+	ListFavorites(ctx context.Context, q FavoriteQuery) (*FavoritePage, error)
+}
+
+// FavoriteQuery pages GET /me/favorites.
+type FavoriteQuery struct {
+	Limit  int
+	Offset int
+}
+
+// FavoritePage is one page of favorite books and how many there are in
+// all. Limit and Offset are those of the page served.
+type FavoritePage struct {
+	Books  []domain.Book
+	Total  int
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,68 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+var (
+	// ErrBookAvailable means a hold was placed on a book that isn't out, so
+	// it can be checked out instead.
+	ErrBookAvailable = errors.New("book is not on loan")
+	// ErrHoldExists means the user already waits for the book.
+	ErrHoldExists = errors.New("user already holds this book")
+	// ErrBorrowerHold means the user placing a hold has the book out.
+	ErrBorrowerHold = errors.New("user has this book on loan")
+	// ErrHoldClosed means a hold was fulfilled or cancelled already.
+	ErrHoldClosed = errors.New("hold is no longer waiting")
+	// ErrNoHoldAccount means a caller without a user account, anonymous or
+	// an API key, tried to hold a book for themselves.
+	ErrNoHoldAccount = errors.New("holds belong to a user account; sign in")
+	// ErrNotHoldOwner means a caller who isn't an editor or admin touched
+	// another user's hold.
+	ErrNotHoldOwner = errors.New("hold belongs to another user")
+)
+
+// HoldRepository stores holds. Holds are placed under the same lock on the
+// book as checkouts, and LoanRepository.Return fulfils them.
+type HoldRepository interface {
+	// Place queues h and sets its ID and position. found is false if the
+	// book doesn't exist; it fails with ErrBookAvailable if the book isn't
+	// out, ErrBorrowerHold if the user has it out, ErrHoldExists if the
+	// user already waits for it and ErrUnknownBorrower if the user doesn't
+	// exist.
+	Place(ctx context.Context, h *domain.Hold) (found bool, err error)
+	// GetByID returns nil if there is no hold id.
+	GetByID(ctx context.Context, id int64) (*domain.Hold, error)
+	// Cancel closes hold id at at and reports whether it was waiting.
+	Cancel(ctx context.Context, id int64, at time.Time) (bool, error)
+	// ListWaiting returns the waiting holds of user userID with their
+	// positions, oldest first.
+	ListWaiting(ctx context.Context, userID int64) ([]domain.Hold, error)
+}
+
+// HoldService queues users for books out on loan. Users place, read and
+// cancel their own holds; editors and admins those of anyone. Other holds
+// fail with ErrNotHoldOwner.
+type HoldService interface {
+	PlaceHold(ctx context.Context, in PlaceHoldInput) (*domain.Hold, error)
+	GetHold(ctx context.Context, id int64) (*domain.Hold, error)
+	// CancelHold takes hold id out of its queue; it fails with
+	// ErrHoldClosed if it was fulfilled or cancelled already.
+	CancelHold(ctx context.Context, id int64) (*domain.Hold, error)
+	// ListMyHolds returns the caller's waiting holds, oldest first; it
+	// fails with ErrNoHoldAccount for callers without an account.
+	ListMyHolds(ctx context.Context) ([]domain.Hold, error)
+}
+
+// PlaceHoldInput for POST /holds.
+// swagger:model PlaceHoldInput
+type PlaceHoldInput struct {
+	BookID int64 `json:"book_id" example:"42"`
+	// UserID queues another user; only editors and admins may set it.
+	// Without it the hold is the caller's own.
+	UserID int64 `json:"user_id,omitempty" example:"7"`
+}
@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// InboxRepository remembers which upstream messages were consumed, keyed by
+// source and message id, so a redelivered message is applied only once.
+type InboxRepository interface {
+	// Seen reports whether the message was already recorded.
+	Seen(ctx context.Context, source, messageID string) (bool, error)
+	// Record stores the outcome of a message. Recording the same message
+	// again keeps the first outcome.
+	Record(ctx context.Context, m *domain.InboxMessage) error
+}
@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// LeaderRepository records the winner of each election, so replicas that
+// lost can still tell who leads.
+type LeaderRepository interface {
+	SetLeader(ctx context.Context, election, identity string, at time.Time) error
+	// Leader returns nil if nobody has won election yet.
+	Leader(ctx context.Context, election string) (*domain.Leader, error)
+}
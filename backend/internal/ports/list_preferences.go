@@ -0,0 +1,41 @@
+package ports
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// ErrNoPreferencesTenant means a request without an actor tried to manage
+// list preferences, which belong to an API key or a user.
+var ErrNoPreferencesTenant = errors.New("list preferences belong to an API key or a user; send X-API-Key or sign in")
+
+type ListPreferencesRepository interface {
+	// Get returns nil if tenant has no preferences.
+	Get(ctx context.Context, tenant string) (*domain.ListPreferences, error)
+	// Put replaces the preferences of p.Tenant.
+	Put(ctx context.Context, p *domain.ListPreferences) error
+	// Delete removes the preferences of tenant and reports whether there
+	// were any.
+	Delete(ctx context.Context, tenant string) (bool, error)
+}
+
+// ListPreferencesService manages the list preferences of the calling
+// tenant.
+type ListPreferencesService interface {
+	GetListPreferences(ctx context.Context) (*domain.ListPreferences, error)
+	PutListPreferences(ctx context.Context, in PutListPreferencesInput) (*domain.ListPreferences, error)
+	DeleteListPreferences(ctx context.Context) error
+	// For returns the preferences of the tenant ctx runs as, nil for
+	// anonymous requests and tenants without any.
+	For(ctx context.Context) (*domain.ListPreferences, error)
+}
+
+// PutListPreferencesInput for PUT /me/list-preferences.
+// swagger:model PutListPreferencesInput
+type PutListPreferencesInput struct {
+	Sort      string `json:"sort" example:"-publication_year"`
+	Collation string `json:"collation" example:""`
+	PageSize  int    `json:"page_size" example:"24"`
+}
@@ -0,0 +1,64 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+var (
+	// ErrBookOnLoan means a book is checked out, so it can't be lent again
+	// or deleted until it is returned.
+	ErrBookOnLoan = errors.New("book is on loan")
+	// ErrUnknownBorrower means a checkout named a user_id no user has.
+	ErrUnknownBorrower = errors.New("no user has this id")
+	// ErrLoanReturned means a loan was returned already.
+	ErrLoanReturned = errors.New("loan already returned")
+)
+
+// LoanRepository stores loans. It enforces that a book is on one open loan
+// at most.
+type LoanRepository interface {
+	// Checkout opens l and sets its ID. found is false if the book doesn't
+	// exist; it fails with ErrBookOnLoan if the book is out and with
+	// ErrUnknownBorrower if the user doesn't exist.
+	Checkout(ctx context.Context, l *domain.Loan) (found bool, err error)
+	// GetByID returns nil if there is no loan id.
+	GetByID(ctx context.Context, id int64) (*domain.Loan, error)
+	// Return closes loan id at at and reports whether it was open. If the
+	// book has waiting holds, it lends the book to the oldest one's user
+	// until nextDue, fulfils that hold and returns the new loan as next.
+	Return(ctx context.Context, id int64, at, nextDue time.Time) (open bool, next *domain.Loan, err error)
+	// Overdue returns up to limit open loans due before now, the longest
+	// overdue first.
+	Overdue(ctx context.Context, now time.Time, limit int) ([]domain.Loan, error)
+}
+
+// LoanService lends books to users.
+type LoanService interface {
+	CheckoutBook(ctx context.Context, in CheckoutInput) (*domain.Loan, error)
+	GetLoan(ctx context.Context, id int64) (*domain.Loan, error)
+	// ReturnLoan closes loan id and lends the book to the first waiting
+	// hold, if any; it fails with ErrLoanReturned if it was closed already.
+	ReturnLoan(ctx context.Context, id int64) (*ReturnedLoan, error)
+	ListOverdueLoans(ctx context.Context, limit int) ([]domain.Loan, error)
+}
+
+// ReturnedLoan is a closed loan and the loan that lent the book on to the
+// first waiting hold.
+type ReturnedLoan struct {
+	domain.Loan
+	// NextLoan is missing if no hold was waiting for the book.
+	NextLoan *domain.Loan `json:"next_loan,omitempty"`
+}
+
+// CheckoutInput for POST /loans.
+// swagger:model CheckoutInput
+type CheckoutInput struct {
+	BookID int64 `json:"book_id" example:"42"`
+	UserID int64 `json:"user_id" example:"7"`
+	// DueAt defaults to the loan period from now.
+	DueAt *time.Time `json:"due_at,omitempty"`
+}
@@ -0,0 +1,19 @@
+package ports
+
+import "context"
+
+// Locker hands out named locks that hold across all replicas, so that only
+// one of them runs a singleton job at a time.
+type Locker interface {
+	// TryLock takes lock name without waiting; ok is false if another
+	// process holds it.
+	TryLock(ctx context.Context, name string) (lease Lease, ok bool, err error)
+}
+
+// Lease is a held lock. Lost is closed once the holder can no longer be sure
+// it still holds the lock (e.g. its database session dropped); the guarded
+// work must stop then, as another replica may take the lock over.
+type Lease interface {
+	Lost() <-chan struct{}
+	Release(ctx context.Context) error
+}
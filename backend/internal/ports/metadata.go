@@ -0,0 +1,29 @@
+package ports
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// ErrMetadataUnavailable means every metadata provider failed, so whether
+// the ISBN is known is undecided.
+var ErrMetadataUnavailable = errors.New("book metadata providers are unavailable")
+
+// MetadataProvider looks books up in an external catalogue such as
+// OpenLibrary or Google Books.
+type MetadataProvider interface {
+	// Name identifies the provider in logs and BookMetadata.Source.
+	Name() string
+	// LookupISBN returns what the catalogue knows about isbn13, or nil when
+	// it doesn't know the book.
+	LookupISBN(ctx context.Context, isbn13 string) (*domain.BookMetadata, error)
+}
+
+// MetadataService finds the metadata of a book by its ISBN-10 or ISBN-13,
+// for POST /books/lookup and autofill on create. It returns nil when no
+// provider knows the book.
+type MetadataService interface {
+	LookupBook(ctx context.Context, isbn string) (*domain.BookMetadata, error)
+}
@@ -0,0 +1,60 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+var (
+	// ErrUnknownPublisher means a book was given a publisher_id no
+	// publisher has.
+	ErrUnknownPublisher = errors.New("no publisher has this id")
+	// ErrPublisherNameTaken means another publisher already has the name.
+	ErrPublisherNameTaken = errors.New("another publisher has this name")
+	// ErrPublisherInUse means a publisher still has books and wasn't asked
+	// to detach them.
+	ErrPublisherInUse = errors.New("publisher still has books")
+)
+
+// PublisherRepository stores publishers. It enforces the relation to
+// books: a publisher is only deleted once no book refers to it.
+type PublisherRepository interface {
+	// Create fails with ErrPublisherNameTaken if the name is in use.
+	Create(ctx context.Context, p *domain.Publisher) (int64, error)
+	// List returns every publisher ordered by name.
+	List(ctx context.Context) ([]domain.Publisher, error)
+	// GetByID returns nil if there is no publisher id.
+	GetByID(ctx context.Context, id int64) (*domain.Publisher, error)
+	// Update saves the name, country and website of p and reports whether
+	// it exists; it fails with ErrPublisherNameTaken if the name is in use.
+	Update(ctx context.Context, p *domain.Publisher) (bool, error)
+	// Delete removes publisher id and reports whether it existed. While
+	// books refer to it, it fails with ErrPublisherInUse unless detach is
+	// set; then it clears their publisher in the same transaction, as an
+	// update at at, and returns their ids.
+	Delete(ctx context.Context, id int64, detach bool, at time.Time) (found bool, detached []int64, err error)
+}
+
+// PublisherService manages publishers.
+type PublisherService interface {
+	CreatePublisher(ctx context.Context, in PublisherInput) (*domain.Publisher, error)
+	ListPublishers(ctx context.Context) ([]domain.Publisher, error)
+	GetPublisher(ctx context.Context, id int64) (*domain.Publisher, error)
+	UpdatePublisher(ctx context.Context, id int64, in PublisherInput) (*domain.Publisher, error)
+	// DeletePublisher removes publisher id; with detach, its books are
+	// kept without a publisher instead of blocking the delete.
+	DeletePublisher(ctx context.Context, id int64, detach bool) error
+}
+
+// PublisherInput for POST /publishers and PUT /publishers/{id}, which
+// replaces every field.
+// swagger:model PublisherInput
+type PublisherInput struct {
+	Name string `json:"name" example:"Ace Books"`
+	// Country is an ISO 3166-1 alpha-2 code, in either case.
+	Country string `json:"country,omitempty" example:"us"`
+	Website string `json:"website,omitempty" example:"https://www.penguinrandomhouse.com/ace"`
+}
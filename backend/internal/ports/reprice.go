@@ -0,0 +1,74 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+type PriceRepository interface {
+	// ApplyPrices sets every book's price to NewPrice in one transaction and
+	// appends the changes to the price history. A book whose price is no
+	// longer OldPrice aborts the whole batch with ErrConcurrentUpdate.
+	ApplyPrices(ctx context.Context, changes []domain.PriceChange) error
+	// RecordPrice appends a change made with the rest of the book to the
+	// price history.
+	RecordPrice(ctx context.Context, c domain.PriceChange) error
+	// PriceHistory returns the price changes of book id, oldest first.
+	PriceHistory(ctx context.Context, id int64) ([]domain.PriceChange, error)
+}
+
+type RepriceService interface {
+	Reprice(ctx context.Context, in RepriceRequest) (*RepriceResponse, error)
+}
+
+type PriceHistoryService interface {
+	// PriceHistory returns the price changes of book id, oldest first.
+	PriceHistory(ctx context.Context, id int64) ([]domain.PriceChange, error)
+}
+
+// RepriceFilter selects the books of a bulk operation, a re-price or a bulk
+// tag. Criteria are combined with AND; an empty filter must say so with All.
+type RepriceFilter struct {
+	All      bool     `json:"all,omitempty"`
+	IDs      []int64  `json:"ids,omitempty"`
+	Query    string   `json:"q,omitempty"` // title, author or alias, like GET /books?q=
+	Author   string   `json:"author,omitempty"`
+	MinPrice *float64 `json:"min_price,omitempty"`
+	MaxPrice *float64 `json:"max_price,omitempty"`
+	YearFrom *int     `json:"year_from,omitempty"`
+	YearTo   *int     `json:"year_to,omitempty"`
+}
+
+// RepriceRule is applied in order: percent, then amount, then rounding.
+type RepriceRule struct {
+	Percent float64 `json:"percent,omitempty" example:"10"`
+	Amount  float64 `json:"amount,omitempty" example:"-0.5"`
+	// Ending rounds to the nearest price with these cents, e.g. 0.99 or 0.95.
+	Ending *float64 `json:"ending,omitempty" example:"0.99"`
+}
+
+// RepriceRequest for POST /books/reprice. DryRun defaults to true; send
+// false to apply the changes.
+// swagger:model RepriceRequest
+type RepriceRequest struct {
+	Filter RepriceFilter `json:"filter"`
+	Rule   RepriceRule   `json:"rule"`
+	DryRun *bool         `json:"dry_run,omitempty"`
+}
+
+// RepriceItem is one proposed or applied price change.
+type RepriceItem struct {
+	BookID   int64   `json:"book_id"`
+	Title    string  `json:"title"`
+	OldPrice float64 `json:"old_price"`
+	NewPrice float64 `json:"new_price"`
+}
+
+// swagger:model RepriceResponse
+type RepriceResponse struct {
+	DryRun  bool          `json:"dry_run"`
+	BatchID string        `json:"batch_id,omitempty"`
+	Matched int           `json:"matched"`
+	Changes []RepriceItem `json:"changes"`
+}
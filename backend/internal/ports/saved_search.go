@@ -0,0 +1,57 @@
+package ports
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// ErrNoSearchOwner means a request without an actor tried to manage saved
+// searches.
+var ErrNoSearchOwner = errors.New("saved searches belong to an API key or a user; send X-API-Key or sign in")
+
+type SavedSearchRepository interface {
+	Create(ctx context.Context, s *domain.SavedSearch) (int64, error)
+	// List returns every search, whoever saved it, for the matcher.
+	List(ctx context.Context) ([]domain.SavedSearch, error)
+	// ListByOwner returns the searches owner saved.
+	ListByOwner(ctx context.Context, owner string) ([]domain.SavedSearch, error)
+	// GetByID returns nil if owner has no search id.
+	GetByID(ctx context.Context, owner string, id int64) (*domain.SavedSearch, error)
+	// Delete removes owner's search id with its notifications and reports
+	// whether it existed.
+	Delete(ctx context.Context, owner string, id int64) (bool, error)
+	// AddNotification stores n unless its search was already notified about
+	// the book, and reports whether it was stored.
+	AddNotification(ctx context.Context, n *domain.SearchNotification) (bool, error)
+	// Notifications returns the newest notifications of searchID first.
+	Notifications(ctx context.Context, searchID int64, limit int) ([]domain.SearchNotification, error)
+	// Cursor is the change log position the matcher has reached.
+	Cursor(ctx context.Context) (int64, error)
+	SaveCursor(ctx context.Context, cursor int64) error
+}
+
+// Notifier delivers search notifications to whoever saved the search.
+type Notifier interface {
+	Notify(ctx context.Context, s domain.SavedSearch, n domain.SearchNotification) error
+}
+
+// SavedSearchService manages the caller's saved searches and their
+// notifications. Callers without an actor get ErrNoSearchOwner, and other
+// actors' searches are not found.
+type SavedSearchService interface {
+	CreateSavedSearch(ctx context.Context, in CreateSavedSearchInput) (*domain.SavedSearch, error)
+	ListSavedSearches(ctx context.Context) ([]domain.SavedSearch, error)
+	GetSavedSearch(ctx context.Context, id int64) (*domain.SavedSearch, error)
+	DeleteSavedSearch(ctx context.Context, id int64) error
+	SearchNotifications(ctx context.Context, id int64, limit int) ([]domain.SearchNotification, error)
+}
+
+// CreateSavedSearchInput for POST /saved-searches.
+// swagger:model CreateSavedSearchInput
+type CreateSavedSearchInput struct {
+	Name   string `json:"name" example:"Early Le Guin"`
+	Query  string `json:"query" example:"author:\"Ursula K. Le Guin\" year:..1975"`
+	Notify bool   `json:"notify"`
+}
@@ -0,0 +1,34 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// SearchInsightsRepository persists daily counters of book searches.
+type SearchInsightsRepository interface {
+	// AddSearchUsage adds the counts of every key in counts to its
+	// counters.
+	AddSearchUsage(ctx context.Context, counts map[domain.SearchUsageKey]domain.SearchCounts) error
+	// SearchTotalsSince sums the counters of the days from since on.
+	SearchTotalsSince(ctx context.Context, since time.Time) (domain.SearchCounts, error)
+	// TopSearchQueriesSince sums the counters of the days from since on per
+	// query and returns the limit most searched queries or, with
+	// zeroResults, the limit queries that most often found nothing.
+	TopSearchQueriesSince(ctx context.Context, since time.Time, zeroResults bool, limit int) ([]domain.SearchQueryCount, error)
+}
+
+// SearchInsightsService counts book searches and the results opened from
+// them, and reports on them.
+type SearchInsightsService interface {
+	// Record counts one search for q that found results books, without
+	// waiting on storage.
+	Record(q string, results int)
+	// RecordClick counts a result of a search for q being opened.
+	RecordClick(q string, bookID int64) error
+	// SearchInsights reports on the last days days, listing the top most
+	// searched and most often empty queries.
+	SearchInsights(ctx context.Context, days, top int) (*domain.SearchInsights, error)
+}
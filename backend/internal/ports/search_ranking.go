@@ -0,0 +1,46 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+type SearchRankingRepository interface {
+	// Get returns nil if tenant has no ranking.
+	Get(ctx context.Context, tenant string) (*domain.SearchRanking, error)
+	// List returns every tenant's ranking, by tenant.
+	List(ctx context.Context) ([]domain.SearchRanking, error)
+	// Put replaces the ranking of r.Tenant.
+	Put(ctx context.Context, r *domain.SearchRanking) error
+	// Delete removes the ranking of tenant and reports whether it had one.
+	Delete(ctx context.Context, tenant string) (bool, error)
+}
+
+// SearchRankingService manages the search ranking of each tenant.
+type SearchRankingService interface {
+	ListSearchRankings(ctx context.Context) (*SearchRankings, error)
+	GetSearchRanking(ctx context.Context, tenant string) (*domain.SearchRanking, error)
+	PutSearchRanking(ctx context.Context, tenant string, in PutSearchRankingInput) (*domain.SearchRanking, error)
+	DeleteSearchRanking(ctx context.Context, tenant string) error
+	// For returns the weights searches of the tenant ctx runs as rank
+	// with, the defaults for anonymous requests and tenants without any.
+	For(ctx context.Context) (domain.RankingWeights, error)
+}
+
+// SearchRankings for GET /admin/search/ranking.
+// swagger:model SearchRankings
+type SearchRankings struct {
+	// Defaults rank the tenants that aren't listed.
+	Defaults domain.RankingWeights  `json:"defaults"`
+	Tenants  []domain.SearchRanking `json:"tenants"`
+}
+
+// PutSearchRankingInput for PUT /admin/search/ranking/{tenant}. Omitted
+// weights take their default.
+// swagger:model PutSearchRankingInput
+type PutSearchRankingInput struct {
+	TitleWeight  *float64 `json:"title_weight" example:"3"`
+	AuthorWeight *float64 `json:"author_weight" example:"1"`
+	RecencyBoost *float64 `json:"recency_boost" example:"0.5"`
+}
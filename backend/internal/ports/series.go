@@ -0,0 +1,69 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+var (
+	// ErrUnknownSeries means a book was given a series_id no series has.
+	ErrUnknownSeries = errors.New("no series has this id")
+	// ErrSeriesNameTaken means another series already has the name.
+	ErrSeriesNameTaken = errors.New("another series has this name")
+	// ErrSeriesInUse means a series still has books and wasn't asked to
+	// detach them.
+	ErrSeriesInUse = errors.New("series still has books")
+)
+
+// SeriesRepository stores series. Like PublisherRepository, it only
+// deletes a series once no book refers to it.
+type SeriesRepository interface {
+	// Create fails with ErrSeriesNameTaken if the name is in use.
+	Create(ctx context.Context, s *domain.Series) (int64, error)
+	// List returns every series ordered by name.
+	List(ctx context.Context) ([]domain.Series, error)
+	// GetByID returns nil if there is no series id.
+	GetByID(ctx context.Context, id int64) (*domain.Series, error)
+	// Update saves the name of s and reports whether it exists; it fails
+	// with ErrSeriesNameTaken if the name is in use.
+	Update(ctx context.Context, s *domain.Series) (bool, error)
+	// Delete removes series id and reports whether it existed. While books
+	// belong to it, it fails with ErrSeriesInUse unless detach is set; then
+	// it takes them out of the series in the same transaction, as an update
+	// at at, and returns their ids.
+	Delete(ctx context.Context, id int64, detach bool, at time.Time) (found bool, detached []int64, err error)
+}
+
+// SeriesService manages series and lists their books in order.
+type SeriesService interface {
+	CreateSeries(ctx context.Context, in SeriesInput) (*domain.Series, error)
+	ListSeries(ctx context.Context) ([]domain.Series, error)
+	GetSeries(ctx context.Context, id int64) (*domain.Series, error)
+	UpdateSeries(ctx context.Context, id int64, in SeriesInput) (*domain.Series, error)
+	// DeleteSeries removes series id; with detach, its books are kept
+	// outside any series instead of blocking the delete.
+	DeleteSeries(ctx context.Context, id int64, detach bool) error
+	// ListSeriesBooks returns the books of series id by position, books
+	// without one last, then by publication year.
+	ListSeriesBooks(ctx context.Context, id int64) ([]domain.Book, error)
+	// ListEditions returns every edition of the work book id belongs to,
+	// the book itself included, oldest first.
+	ListEditions(ctx context.Context, id int64) (*Editions, error)
+}
+
+// SeriesInput for POST /series and PUT /series/{id}.
+// swagger:model SeriesInput
+type SeriesInput struct {
+	Name string `json:"name" example:"The Expanse"`
+}
+
+// Editions are the books of one work.
+type Editions struct {
+	// WorkID is the id the editions share as their work_id; for a book
+	// without other editions, its own id.
+	WorkID int64
+	Books  []domain.Book
+}
@@ -0,0 +1,12 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// StatusService checks the API's dependencies.
+type StatusService interface {
+	Status(ctx context.Context) domain.SystemStatus
+}
@@ -0,0 +1,18 @@
+package ports
+
+import "context"
+
+// SearchTermSource lists what searches can be corrected to.
+type SearchTermSource interface {
+	// SearchTerms returns up to limit distinct book titles and author
+	// names.
+	SearchTerms(ctx context.Context, limit int) ([]string, error)
+}
+
+// SuggestionService proposes what a search that found nothing may have
+// meant.
+type SuggestionService interface {
+	// Suggest returns the titles and author names most like q, best first;
+	// none if nothing is close.
+	Suggest(ctx context.Context, q string) ([]string, error)
+}
@@ -0,0 +1,123 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// Sync conflict strategies.
+const (
+	SyncMerge      = "merge"       // per-field three-way merge (default)
+	SyncServerWins = "server-wins" // any conflict rejects the whole change
+	SyncClientWins = "client-wins" // client values overwrite the server
+)
+
+// Sync push operations.
+const (
+	SyncOpCreate = "create"
+	SyncOpUpdate = "update"
+	SyncOpDelete = "delete"
+)
+
+type SyncService interface {
+	// Pull returns the changes after checkpoint or, with checkpoint 0, a
+	// page of the initial sync that snapshot continues (nil starts one).
+	Pull(ctx context.Context, checkpoint int64, snapshot *SyncSnapshot, limit int) (*SyncPullResponse, error)
+	Push(ctx context.Context, in SyncPushRequest) (*SyncPushResponse, error)
+}
+
+// SyncSnapshot is where an initial sync continues: the checkpoint it ends
+// at and the id of the last book it sent. Clients get it as the opaque
+// token "<checkpoint>.<id>".
+type SyncSnapshot struct {
+	Checkpoint int64
+	AfterID    int64
+}
+
+func (s SyncSnapshot) String() string {
+	return strconv.FormatInt(s.Checkpoint, 10) + "." + strconv.FormatInt(s.AfterID, 10)
+}
+
+// ParseSyncSnapshot reads a token made by SyncSnapshot.String.
+func ParseSyncSnapshot(token string) (SyncSnapshot, error) {
+	cp, id, ok := strings.Cut(token, ".")
+	checkpoint, err1 := strconv.ParseInt(cp, 10, 64)
+	afterID, err2 := strconv.ParseInt(id, 10, 64)
+	if !ok || err1 != nil || err2 != nil || checkpoint < 0 || afterID < 1 {
+		return SyncSnapshot{}, errors.New("invalid snapshot token")
+	}
+	return SyncSnapshot{Checkpoint: checkpoint, AfterID: afterID}, nil
+}
+
+// Tombstone marks a book deleted since the client's checkpoint.
+// swagger:model Tombstone
+type Tombstone struct {
+	ID        int64     `json:"id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// SyncPullResponse for GET /sync/books.
+// swagger:model SyncPullResponse
+type SyncPullResponse struct {
+	Upserts    []domain.Book `json:"upserts"`
+	Tombstones []Tombstone   `json:"tombstones"`
+	// Checkpoint stays 0 until the last page of an initial sync.
+	Checkpoint int64 `json:"checkpoint"`
+	HasMore    bool  `json:"has_more"`
+	// Snapshot continues an initial sync with more books to send; pass it
+	// back as the snapshot parameter.
+	Snapshot string `json:"snapshot,omitempty" example:"812.340"`
+}
+
+// SyncChange is one offline edit. Base holds the values the client last
+// pulled and is what concurrent server edits are detected against.
+// swagger:model SyncChange
+type SyncChange struct {
+	Op       string          `json:"op" example:"update"` // "create" | "update" | "delete"
+	ID       int64           `json:"id,omitempty"`
+	ClientID string          `json:"client_id,omitempty"` // echoed back for creates
+	Fields   UpdateBookInput `json:"fields"`
+	Base     UpdateBookInput `json:"base"`
+	// BaseUpdatedAt guards deletes: a server edit after it is a conflict.
+	BaseUpdatedAt *time.Time `json:"base_updated_at,omitempty"`
+}
+
+// SyncPushRequest for POST /sync/books.
+// swagger:model SyncPushRequest
+type SyncPushRequest struct {
+	Strategy        string            `json:"strategy" example:"merge"`
+	FieldStrategies map[string]string `json:"field_strategies,omitempty"`
+	Changes         []SyncChange      `json:"changes"`
+}
+
+// FieldConflict reports a field edited both offline and on the server.
+// swagger:model FieldConflict
+type FieldConflict struct {
+	Field      string `json:"field"`
+	Server     any    `json:"server"`
+	Client     any    `json:"client"`
+	Resolution string `json:"resolution"`
+}
+
+// SyncResult is the outcome of one pushed change, in request order.
+// swagger:model SyncResult
+type SyncResult struct {
+	ClientID  string            `json:"client_id,omitempty"`
+	ID        int64             `json:"id,omitempty"`
+	Status    string            `json:"status"` // created | applied | merged | deleted | conflict | not_found | rejected
+	Book      *domain.Book      `json:"book,omitempty"`
+	Conflicts []FieldConflict   `json:"conflicts,omitempty"`
+	Error     string            `json:"error,omitempty"`
+	Fields    map[string]string `json:"fields,omitempty"`
+}
+
+// SyncPushResponse for POST /sync/books.
+// swagger:model SyncPushResponse
+type SyncPushResponse struct {
+	Results []SyncResult `json:"results"`
+}
@@ -0,0 +1,55 @@
+package ports
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// ErrSynonymTaken means a term of a synonym group is already in another
+// group.
+var ErrSynonymTaken = errors.New("a term is already in another synonym group")
+
+// SynonymRepository stores the synonym dictionary. A term, folded with
+// domain.SearchKey, belongs to one group at most.
+type SynonymRepository interface {
+	// List returns every group, by id, with its terms in order.
+	List(ctx context.Context) ([]domain.SynonymGroup, error)
+	// GetByID returns nil if there is no group id.
+	GetByID(ctx context.Context, id int64) (*domain.SynonymGroup, error)
+	// Create fails with ErrSynonymTaken if another group has one of the
+	// terms.
+	Create(ctx context.Context, g *domain.SynonymGroup) (int64, error)
+	// Update replaces the terms of g and reports whether it exists; it
+	// fails with ErrSynonymTaken if another group has one of them.
+	Update(ctx context.Context, g *domain.SynonymGroup) (bool, error)
+	// Delete removes group id and reports whether it existed.
+	Delete(ctx context.Context, id int64) (bool, error)
+}
+
+// SynonymExpander rewrites a search into the searches its synonyms make
+// equivalent.
+type SynonymExpander interface {
+	// Expand returns q, folded, with each dictionary term in it swapped
+	// for the other terms of its group, leaving out q itself. It returns
+	// nil when q contains no term.
+	Expand(ctx context.Context, q string) ([]string, error)
+}
+
+// SynonymService manages the synonym dictionary.
+type SynonymService interface {
+	SynonymExpander
+	ListSynonyms(ctx context.Context) ([]domain.SynonymGroup, error)
+	GetSynonyms(ctx context.Context, id int64) (*domain.SynonymGroup, error)
+	CreateSynonyms(ctx context.Context, in SynonymInput) (*domain.SynonymGroup, error)
+	UpdateSynonyms(ctx context.Context, id int64, in SynonymInput) (*domain.SynonymGroup, error)
+	DeleteSynonyms(ctx context.Context, id int64) error
+}
+
+// SynonymInput for POST /admin/search/synonyms and PUT
+// /admin/search/synonyms/{id}, which replaces the terms.
+// swagger:model SynonymInput
+type SynonymInput struct {
+	Terms []string `json:"terms" example:"C#,csharp"`
+}
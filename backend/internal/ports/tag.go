@@ -0,0 +1,36 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// TagRepository puts free-form tags on books.
+type TagRepository interface {
+	// Tag puts the tag with key on the book, creating the tag under name if
+	// it doesn't exist yet. found is false if no book has bookID, and added
+	// false if the book had the tag.
+	Tag(ctx context.Context, bookID int64, name, key string, at time.Time) (found, added bool, err error)
+	// Untag removes the tag with key from the book and reports whether the
+	// book had it.
+	Untag(ctx context.Context, bookID int64, key string) (bool, error)
+	// List returns the tags on at least one book whose key starts with
+	// prefix, most used first.
+	List(ctx context.Context, prefix string, limit int) ([]domain.Tag, error)
+}
+
+// TagService tags and untags books and counts the use of each tag.
+type TagService interface {
+	// TagBook and UntagBook return the book with its tags after the change.
+	TagBook(ctx context.Context, bookID int64, tag string) (*domain.Book, error)
+	UntagBook(ctx context.Context, bookID int64, tag string) (*domain.Book, error)
+	ListTags(ctx context.Context, q TagQuery) ([]domain.Tag, error)
+}
+
+// TagQuery narrows GET /tags. Limit 0 means the default.
+type TagQuery struct {
+	Prefix string
+	Limit  int
+}
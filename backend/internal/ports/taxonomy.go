@@ -0,0 +1,78 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// TaxonomyRepository stores the curated taxonomy.
+type TaxonomyRepository interface {
+	// Taxonomy returns every category and author, sorted by slug.
+	Taxonomy(ctx context.Context) (*domain.Taxonomy, error)
+	// Author returns the curated name of author id, nil if it has none.
+	Author(ctx context.Context, id string) (*domain.Author, error)
+	// Apply saves the changes in one transaction, at at.
+	Apply(ctx context.Context, c TaxonomyChanges, at time.Time) error
+}
+
+// TaxonomyChanges are the writes of a taxonomy import: terms to add or
+// overwrite, and slugs to delete.
+type TaxonomyChanges struct {
+	PutCategories    []domain.Category
+	DeleteCategories []string
+	PutAuthors       []domain.Author
+	DeleteAuthors    []string
+}
+
+// Empty reports whether c changes nothing.
+func (c TaxonomyChanges) Empty() bool {
+	return len(c.PutCategories)+len(c.DeleteCategories)+len(c.PutAuthors)+len(c.DeleteAuthors) == 0
+}
+
+// Taxonomy import modes. Merge adds the terms the target lacks and keeps
+// its own version of those that differ, reporting them as conflicts; it
+// deletes nothing. Replace makes the target exactly the imported taxonomy.
+const (
+	TaxonomyMerge   = "merge"
+	TaxonomyReplace = "replace"
+)
+
+// TaxonomyService exports and imports the taxonomy.
+type TaxonomyService interface {
+	ExportTaxonomy(ctx context.Context) (*domain.Taxonomy, error)
+	// ImportTaxonomy applies t in mode, or only reports what it would do
+	// with dryRun.
+	ImportTaxonomy(ctx context.Context, t domain.Taxonomy, mode string, dryRun bool) (*TaxonomyReport, error)
+}
+
+// TaxonomyReport is the outcome of an import, by kind of term.
+// swagger:model TaxonomyReport
+type TaxonomyReport struct {
+	Mode       string     `json:"mode" example:"merge"`
+	DryRun     bool       `json:"dry_run"`
+	Categories TermReport `json:"categories"`
+	Authors    TermReport `json:"authors"`
+	// Conflicts are the differences a merge kept the target's version of.
+	Conflicts []TaxonomyConflict `json:"conflicts"`
+}
+
+// TermReport lists the slugs an import added, overwrote and deleted.
+type TermReport struct {
+	Added     []string `json:"added"`
+	Updated   []string `json:"updated"`
+	Removed   []string `json:"removed"`
+	Unchanged int      `json:"unchanged"`
+}
+
+// TaxonomyConflict is a field of a term that differs between the target
+// and the import.
+type TaxonomyConflict struct {
+	// Kind is category or author.
+	Kind     string `json:"kind" example:"category"`
+	ID       string `json:"id" example:"science-fiction"`
+	Field    string `json:"field" example:"name"`
+	Current  string `json:"current" example:"Sci-Fi"`
+	Imported string `json:"imported" example:"Science Fiction"`
+}
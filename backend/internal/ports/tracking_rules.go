@@ -0,0 +1,36 @@
+package ports
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// ErrNoTenant means a request without an actor tried to manage tracking
+// rules, which belong to an API key or a user.
+var ErrNoTenant = errors.New("cleanup rules belong to an API key or a user; send X-API-Key or sign in")
+
+type TrackingRulesRepository interface {
+	// Get returns nil if tenant has no rules.
+	Get(ctx context.Context, tenant string) (*domain.TrackingRules, error)
+	// Put replaces the rules of r.Tenant.
+	Put(ctx context.Context, r *domain.TrackingRules) error
+	// Delete removes the rules of tenant and reports whether there were any.
+	Delete(ctx context.Context, tenant string) (bool, error)
+}
+
+// TrackingRulesService manages the tracking rules of the calling tenant.
+type TrackingRulesService interface {
+	GetTrackingRules(ctx context.Context) (*domain.TrackingRules, error)
+	PutTrackingRules(ctx context.Context, in PutTrackingRulesInput) (*domain.TrackingRules, error)
+	DeleteTrackingRules(ctx context.Context) error
+}
+
+// PutTrackingRulesInput for PUT /url/cleanup/rules.
+// swagger:model PutTrackingRulesInput
+type PutTrackingRulesInput struct {
+	Strip []string                   `json:"strip" example:"ref,src_*"`
+	Keep  []string                   `json:"keep" example:"utm_id"`
+	Hosts []domain.HostTrackingRules `json:"hosts"`
+}
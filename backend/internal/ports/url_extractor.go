@@ -0,0 +1,12 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// URLExtractor fetches a page and reads the canonical URL hints it carries.
+type URLExtractor interface {
+	Extract(ctx context.Context, rawURL string) (*domain.PageLinks, error)
+}
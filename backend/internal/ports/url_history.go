@@ -0,0 +1,33 @@
+package ports
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// ErrNoUser means an anonymous request asked for a URL history.
+var ErrNoUser = errors.New("URL history needs a signed-in user or an API key")
+
+type URLHistoryRepository interface {
+	// Add stores e as its owner's newest entry, replacing an entry with the
+	// same URL and operation, and deletes all but the newest keep entries.
+	Add(ctx context.Context, e *domain.URLHistoryEntry, keep int) error
+	// List returns the newest limit entries of owner, newest first.
+	List(ctx context.Context, owner string, limit int) ([]domain.URLHistoryEntry, error)
+	// Delete removes entry id of owner and reports whether it existed.
+	Delete(ctx context.Context, owner string, id int64) (bool, error)
+	// Clear removes every entry of owner.
+	Clear(ctx context.Context, owner string) error
+}
+
+// URLHistoryService keeps the recent cleanups of the calling user.
+type URLHistoryService interface {
+	// Record adds a successful cleanup to the history of the user ctx runs
+	// as; anonymous cleanups aren't kept.
+	Record(ctx context.Context, url, processedURL, operation string) error
+	URLHistory(ctx context.Context, limit int) ([]domain.URLHistoryEntry, error)
+	DeleteURLHistoryEntry(ctx context.Context, id int64) error
+	ClearURLHistory(ctx context.Context) error
+}
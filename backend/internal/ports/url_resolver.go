@@ -0,0 +1,30 @@
+package ports
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrURLUnreachable means a URL passed the safety checks but fetching it
+// failed: DNS, connection, TLS, timeout or too many redirects.
+var ErrURLUnreachable = errors.New("URL could not be reached")
+
+// ResolveOptions tune a resolution.
+type ResolveOptions struct {
+	// RespectRobots stops before fetching a URL the robots.txt of its host
+	// disallows.
+	RespectRobots bool
+}
+
+// Resolution is where a URL led.
+type Resolution struct {
+	// URL is the final URL or, when BlockedByRobots, the URL that wasn't
+	// fetched.
+	URL             string
+	BlockedByRobots bool
+}
+
+// URLResolver follows a URL's redirects to the URL it finally lands on.
+type URLResolver interface {
+	Resolve(ctx context.Context, rawURL string, opts ResolveOptions) (*Resolution, error)
+}
@@ -0,0 +1,130 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// ErrEmailTaken is returned when registering an email that has an account.
+var ErrEmailTaken = errors.New("email already registered")
+
+// ErrNotSignedIn is returned by the /me endpoints to anonymous callers.
+var ErrNotSignedIn = errors.New("sign in to manage your profile")
+
+type UserRepository interface {
+	// Create returns ErrEmailTaken if the email is in use.
+	Create(ctx context.Context, u *domain.User) (int64, error)
+	// GetByEmail returns nil if no user has email.
+	GetByEmail(ctx context.Context, email string) (*domain.User, error)
+	// GetByID returns nil if there is no user id.
+	GetByID(ctx context.Context, id int64) (*domain.User, error)
+	// List returns every user by id, for exports.
+	List(ctx context.Context) ([]domain.User, error)
+	// ListPage returns limit users by id from offset, and how many there
+	// are in all.
+	ListPage(ctx context.Context, limit, offset int) ([]domain.User, int, error)
+	// Update saves the name, scopes and password hash of u and reports
+	// whether it exists.
+	Update(ctx context.Context, u *domain.User) (bool, error)
+	// Delete removes user id, with their loans and holds, and reports
+	// whether it existed.
+	Delete(ctx context.Context, id int64) (bool, error)
+}
+
+// UserService manages user accounts: every account for admins, and their
+// own profile for signed-in users.
+type UserService interface {
+	ListUsers(ctx context.Context, q UserQuery) (*UserPage, error)
+	GetUser(ctx context.Context, id int64) (*domain.User, error)
+	CreateUser(ctx context.Context, in CreateUserInput) (*domain.User, error)
+	UpdateUser(ctx context.Context, id int64, in UpdateUserInput) (*domain.User, error)
+	DeleteUser(ctx context.Context, id int64) error
+	// Me returns the account of the user ctx runs as; ErrNotSignedIn when
+	// it runs as nobody.
+	Me(ctx context.Context) (*domain.User, error)
+	UpdateMe(ctx context.Context, in UpdateProfileInput) (*domain.User, error)
+}
+
+// UserQuery pages GET /users.
+type UserQuery struct {
+	Limit  int
+	Offset int
+}
+
+// UserPage is one page of users and how many there are in all. Limit and
+// Offset are those of the page served.
+type UserPage struct {
+	Users  []domain.User
+	Total  int
+	Limit  int
+	Offset int
+}
+
+// CreateUserInput for POST /users.
+// swagger:model CreateUserInput
+type CreateUserInput struct {
+	Email    string   `json:"email" example:"ann@example.com"`
+	Password string   `json:"password" example:"correct horse battery"`
+	Name     string   `json:"name,omitempty" example:"Ann Lee"`
+	Scopes   []string `json:"scopes,omitempty" example:"editor"`
+}
+
+// UpdateUserInput for PUT /users/{id}, which replaces the name and scopes.
+// The email can't change.
+// swagger:model UpdateUserInput
+type UpdateUserInput struct {
+	Name   string   `json:"name" example:"Ann Lee"`
+	Scopes []string `json:"scopes" example:"editor"`
+	// Password, when set, replaces the user's password.
+	Password string `json:"password,omitempty" example:"correct horse battery"`
+}
+
+// UpdateProfileInput for PUT /me, which replaces the name.
+// swagger:model UpdateProfileInput
+type UpdateProfileInput struct {
+	Name string `json:"name" example:"Ann Lee"`
+	// NewPassword, when set, replaces the password; CurrentPassword must
+	// then be the one it replaces.
+	NewPassword     string `json:"new_password,omitempty" example:"correct horse battery staple"`
+	CurrentPassword string `json:"current_password,omitempty" example:"correct horse battery"`
+}
+
+// AuthService registers users, logs them in and checks the tokens they get.
+type AuthService interface {
+	Register(ctx context.Context, in Credentials) (*domain.User, error)
+	// Login returns ErrInvalidCredentials unless email and password match.
+	Login(ctx context.Context, in Credentials) (*AuthToken, error)
+	// Verify returns the actor a token was issued to, with the scopes the
+	// user has now. It fails with ErrInvalidToken if the token is invalid or
+	// expired, or its user was deleted.
+	Verify(ctx context.Context, token string) (*domain.Actor, error)
+	// Actor returns the actor of the account with email, with its current
+	// scopes, or nil if there is no such account.
+	Actor(ctx context.Context, email string) (*domain.Actor, error)
+}
+
+// ErrInvalidCredentials is the answer to any failed login, so it doesn't
+// reveal which emails have accounts.
+var ErrInvalidCredentials = errors.New("invalid email or password")
+
+// ErrInvalidToken is wrapped by every error of a token that must be
+// refused, as opposed to failures to check it.
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// Credentials for POST /auth/register and POST /auth/login.
+// swagger:model Credentials
+type Credentials struct {
+	Email    string `json:"email" example:"ann@example.com"`
+	Password string `json:"password" example:"correct horse battery"`
+}
+
+// AuthToken is a signed JWT to send as "Authorization: Bearer <token>".
+// swagger:model AuthToken
+type AuthToken struct {
+	Token     string    `json:"token"`
+	TokenType string    `json:"token_type" example:"Bearer"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,16 @@
+package ports
+
+import "context"
+
+// ViewRepository persists per-book view counters.
+type ViewRepository interface {
+	// AddViews increments the counter of every book in counts by its value.
+	AddViews(ctx context.Context, counts map[int64]int64) error
+	// TopViewed returns the ids of the n most viewed books, hottest first.
+	TopViewed(ctx context.Context, n int) ([]int64, error)
+}
+
+// ViewCounter counts book views for later aggregation.
+type ViewCounter interface {
+	Add(bookID int64)
+}
@@ -0,0 +1,66 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+type WebhookRepository interface {
+	Create(ctx context.Context, w *domain.Webhook) (int64, error)
+	List(ctx context.Context) ([]domain.Webhook, error)
+	// GetByID returns nil if there is no webhook id.
+	GetByID(ctx context.Context, id int64) (*domain.Webhook, error)
+	Update(ctx context.Context, w *domain.Webhook) (bool, error)
+	// Delete removes webhook id with its deliveries and reports whether it
+	// existed.
+	Delete(ctx context.Context, id int64) (bool, error)
+	// Enqueue stores deliveries, skipping those of an event a webhook
+	// already has a delivery of.
+	Enqueue(ctx context.Context, deliveries []domain.WebhookDelivery) error
+	// DueDeliveries returns up to limit pending deliveries due at now,
+	// longest due first.
+	DueDeliveries(ctx context.Context, now time.Time, limit int) ([]domain.WebhookDelivery, error)
+	// GetDelivery returns nil if there is no delivery id.
+	GetDelivery(ctx context.Context, id int64) (*domain.WebhookDelivery, error)
+	// SaveAttempt stores the status and outcome of d's latest attempt.
+	SaveAttempt(ctx context.Context, d *domain.WebhookDelivery) error
+	// Deliveries returns the newest deliveries of webhookID first.
+	Deliveries(ctx context.Context, webhookID int64, limit int) ([]domain.WebhookDelivery, error)
+	// Cursor is the change log position deliveries have been queued up to.
+	Cursor(ctx context.Context) (int64, error)
+	SaveCursor(ctx context.Context, cursor int64) error
+}
+
+// WebhookService manages webhooks and reads their delivery log.
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, in WebhookInput) (*CreatedWebhook, error)
+	ListWebhooks(ctx context.Context) ([]domain.Webhook, error)
+	GetWebhook(ctx context.Context, id int64) (*domain.Webhook, error)
+	UpdateWebhook(ctx context.Context, id int64, in WebhookInput) (*domain.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int64) error
+	WebhookDeliveries(ctx context.Context, id int64, limit int) ([]domain.WebhookDelivery, error)
+}
+
+// WebhookInput for POST /webhooks and PUT /webhooks/{id}.
+// swagger:model WebhookInput
+type WebhookInput struct {
+	URL string `json:"url" example:"https://hooks.example.com/books"`
+	// Secret signs the deliveries, 16 to 255 characters. Registering
+	// without one generates it; updating without one keeps it.
+	Secret string `json:"secret,omitempty"`
+	// Events to deliver, of book.created, book.updated and book.deleted;
+	// empty delivers them all.
+	Events []string `json:"events,omitempty"`
+	// Active defaults to true on registration and is kept on update.
+	Active *bool `json:"active,omitempty"`
+}
+
+// CreatedWebhook carries the secret of a new webhook; it can't be shown
+// again.
+// swagger:model CreatedWebhook
+type CreatedWebhook struct {
+	domain.Webhook
+	Secret string `json:"secret" example:"whsec_3q2J9xN0yZ..."`
+}
@@ -0,0 +1,38 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// WorkerRepository shares which background workers are paused across
+// replicas.
+type WorkerRepository interface {
+	// Paused returns the names of the paused workers.
+	Paused(ctx context.Context) ([]string, error)
+	SetPaused(ctx context.Context, name string, paused bool, at time.Time) error
+}
+
+// WorkerService lets an admin watch and pause background workers.
+type WorkerService interface {
+	ListWorkers(ctx context.Context) ([]domain.WorkerStatus, error)
+	PauseWorker(ctx context.Context, name string) (*domain.WorkerStatus, error)
+	ResumeWorker(ctx context.Context, name string) (*domain.WorkerStatus, error)
+}
+
+// ErrDraining is the cancellation cause of work cut off because the process
+// is shutting down.
+var ErrDraining = errors.New("server is shutting down")
+
+// JobTracker lets request-driven jobs, such as imports, take part in a
+// graceful shutdown.
+type JobTracker interface {
+	// Track registers a job; ok is false once the process is draining and
+	// the job must not start. jobCtx is cancelled with cause ErrDraining if
+	// the job outlives the drain deadline. done must be called when the job
+	// ends.
+	Track(ctx context.Context) (jobCtx context.Context, done func(), ok bool)
+}
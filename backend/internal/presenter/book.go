@@ -0,0 +1,63 @@
+// Package presenter shapes domain values for API responses. Anything derived
+// at read time (rather than stored) is computed here so every transport
+// renders it the same way.
+package presenter
+
+import (
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+const (
+	// RecentYears is how many calendar years (including the current one) a
+	// book counts as recent.
+	RecentYears = 3
+	// publicDomainAfter follows the US rule for published works: they enter
+	// the public domain on Jan 1 after 95 years. Only a heuristic — it knows
+	// nothing about renewals, unpublished works or other jurisdictions.
+	publicDomainAfter = 95
+)
+
+// BookView is the response shape of a book: the stored fields plus derived ones.
+// swagger:model BookView
+type BookView struct {
+	domain.Book
+	// AuthorID addresses the author page, see GET /authors/{id}/summary.
+	AuthorID              string `json:"author_id" example:"robert-c-martin"`
+	YearsSincePublication int    `json:"years_since_publication"`
+	IsRecent              bool   `json:"is_recent"`
+	IsPublicDomain        bool   `json:"is_public_domain"`
+
+	hidden []string // fields a FieldPolicy keeps from the caller
+}
+
+// Book derives the computed fields of b as of now.
+func Book(b domain.Book, now time.Time) BookView {
+	v := BookView{Book: b, AuthorID: domain.AuthorSlug(b.Author)}
+	if v.Aliases == nil {
+		v.Aliases = []string{}
+	}
+	if v.Categories == nil {
+		v.Categories = []string{}
+	}
+	if v.Tags == nil {
+		v.Tags = []string{}
+	}
+	if b.PublicationYear <= 0 {
+		return v // unknown year: nothing sensible to derive
+	}
+	v.YearsSincePublication = max(now.Year()-b.PublicationYear, 0)
+	v.IsRecent = v.YearsSincePublication < RecentYears
+	v.IsPublicDomain = b.PublicationYear+publicDomainAfter < now.Year()
+	return v
+}
+
+// Books presents a list, always returning a non-nil slice.
+func Books(bs []domain.Book, now time.Time) []BookView {
+	out := make([]BookView, 0, len(bs))
+	for _, b := range bs {
+		out = append(out, Book(b, now))
+	}
+	return out
+}
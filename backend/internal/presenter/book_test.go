@@ -0,0 +1,51 @@
+package presenter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+func TestBook_DerivedFields(t *testing.T) {
+	now := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		year         int
+		wantYears    int
+		wantRecent   bool
+		wantPublicDm bool
+	}{
+		{2026, 0, true, false},
+		{2024, 2, true, false},
+		{2023, 3, false, false},
+		{2008, 18, false, false},
+		{1931, 95, false, false}, // enters the public domain on Jan 1, 2027
+		{1930, 96, false, true},
+		{1605, 421, false, true},
+		{2030, 0, true, false}, // announced/future year never goes negative
+		{0, 0, false, false},   // unknown year
+	}
+	for _, c := range cases {
+		got := Book(domain.Book{ID: 1, PublicationYear: c.year}, now)
+		if got.YearsSincePublication != c.wantYears || got.IsRecent != c.wantRecent || got.IsPublicDomain != c.wantPublicDm {
+			t.Fatalf("year %d: got years=%d recent=%v public_domain=%v; want %d %v %v",
+				c.year, got.YearsSincePublication, got.IsRecent, got.IsPublicDomain, c.wantYears, c.wantRecent, c.wantPublicDm)
+		}
+		if got.ID != 1 {
+			t.Fatalf("stored fields not carried over: %+v", got)
+		}
+	}
+}
+
+func TestBooks_NonNil(t *testing.T) {
+	if got := Books(nil, time.Now()); got == nil || len(got) != 0 {
+		t.Fatalf("Books(nil) = %#v; want empty slice", got)
+	}
+}
+
+func TestBook_AuthorID(t *testing.T) {
+	got := Book(domain.Book{Author: "Gabriel García Márquez"}, time.Now())
+	if got.AuthorID != "gabriel-garcia-marquez" {
+		t.Fatalf("author_id = %q", got.AuthorID)
+	}
+}
@@ -0,0 +1,137 @@
+package presenter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// FieldPolicy keeps book fields from callers without the scopes to see
+// them, e.g. price from readers. Hidden fields are left out of the response
+// rather than sent empty, so a client can't mistake them for a zero value.
+// The zero value hides nothing.
+type FieldPolicy struct {
+	// rules maps a field's JSON name to the scopes, any of which reveal it.
+	rules map[string][]string
+}
+
+// bookViewFields are the JSON names of BookView's fields.
+var bookViewFields = jsonFields(reflect.TypeOf(BookView{}))
+
+func jsonFields(t reflect.Type) []string {
+	var names []string
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if f.Anonymous {
+			names = append(names, jsonFields(f.Type)...)
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if f.IsExported() && name != "-" && name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ParseFieldPolicy reads rules like "price=editor|admin,work_id=admin": a
+// field, by its JSON name, and the scopes that may see it. Fields without a
+// rule are visible to everyone.
+func ParseFieldPolicy(s string) (FieldPolicy, error) {
+	p := FieldPolicy{rules: map[string][]string{}}
+	for _, rule := range strings.Split(s, ",") {
+		if strings.TrimSpace(rule) == "" {
+			continue
+		}
+		field, scopes, ok := strings.Cut(rule, "=")
+		field = strings.TrimSpace(field)
+		if !ok || !slices.Contains(bookViewFields, field) || field == "id" {
+			return FieldPolicy{}, fmt.Errorf("%q is not field=scope|scope with a book field other than id", strings.TrimSpace(rule))
+		}
+		for _, scope := range strings.Split(scopes, "|") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				p.rules[field] = append(p.rules[field], scope)
+			}
+		}
+		if len(p.rules[field]) == 0 {
+			return FieldPolicy{}, fmt.Errorf("%q names no scope", strings.TrimSpace(rule))
+		}
+	}
+	return p, nil
+}
+
+// Hidden returns the fields a may not see, sorted.
+func (p FieldPolicy) Hidden(a domain.Actor) []string {
+	var hidden []string
+	for field, scopes := range p.rules {
+		if !slices.ContainsFunc(scopes, a.HasScope) {
+			hidden = append(hidden, field)
+		}
+	}
+	slices.Sort(hidden)
+	return hidden
+}
+
+// Apply hides the fields of v that a may not see.
+func (p FieldPolicy) Apply(v BookView, a domain.Actor) BookView {
+	v.hidden = p.Hidden(a)
+	return v
+}
+
+// ApplyAll hides the fields of every view that a may not see.
+func (p FieldPolicy) ApplyAll(vs []BookView, a domain.Actor) []BookView {
+	hidden := p.Hidden(a)
+	for i := range vs {
+		vs[i].hidden = hidden
+	}
+	return vs
+}
+
+// MarshalJSON leaves out the fields a FieldPolicy hid, keeping the order of
+// the others.
+func (v BookView) MarshalJSON() ([]byte, error) {
+	type plain BookView
+	data, err := json.Marshal(plain(v))
+	if err != nil || len(v.hidden) == 0 {
+		return data, err
+	}
+	return omitKeys(data, v.hidden)
+}
+
+// omitKeys drops keys from the JSON object data.
+func omitKeys(data []byte, keys []string) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil { // {
+		return nil, err
+	}
+	var out bytes.Buffer
+	out.WriteByte('{')
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		key := tok.(string)
+		if slices.Contains(keys, key) {
+			continue
+		}
+		if out.Len() > 1 {
+			out.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		out.Write(k)
+		out.WriteByte(':')
+		out.Write(value)
+	}
+	out.WriteByte('}')
+	return out.Bytes(), nil
+}
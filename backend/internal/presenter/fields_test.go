@@ -0,0 +1,65 @@
+package presenter
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+func TestParseFieldPolicy(t *testing.T) {
+	p, err := ParseFieldPolicy(" price=editor|admin, work_id = admin ,")
+	if err != nil {
+		t.Fatalf("ParseFieldPolicy: %v", err)
+	}
+	for _, c := range []struct {
+		scopes []string
+		want   []string
+	}{
+		{nil, []string{"price", "work_id"}},
+		{[]string{"reader"}, []string{"price", "work_id"}},
+		{[]string{"editor"}, []string{"work_id"}},
+		{[]string{"admin"}, nil},
+	} {
+		if got := p.Hidden(domain.Actor{Scopes: c.scopes}); !slices.Equal(got, c.want) {
+			t.Errorf("hidden from %v = %v; want %v", c.scopes, got, c.want)
+		}
+	}
+
+	for _, bad := range []string{"price", "cost=admin", "id=admin", "price=", "price=|"} {
+		if _, err := ParseFieldPolicy(bad); err == nil {
+			t.Errorf("ParseFieldPolicy(%q) accepted", bad)
+		}
+	}
+	if p, err := ParseFieldPolicy(""); err != nil || len(p.Hidden(domain.Actor{})) != 0 {
+		t.Fatalf("empty policy = %v, %v", p, err)
+	}
+}
+
+func TestFieldPolicy_OmitsFields(t *testing.T) {
+	now := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	b := domain.Book{ID: 1, Title: "Ficciones", ISBN: "9780802130303", Price: 12.5, PublicationYear: 1944}
+	p, _ := ParseFieldPolicy("price=editor,is_recent=editor")
+
+	full, _ := json.Marshal(Book(b, now))
+	unchanged, _ := json.Marshal(p.Apply(Book(b, now), domain.Actor{Scopes: []string{"editor"}}))
+	if string(full) != string(unchanged) {
+		t.Fatalf("editor view = %s; want %s", unchanged, full)
+	}
+
+	got, err := json.Marshal(p.ApplyAll(Books([]domain.Book{b}, now), domain.Actor{}))
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	s := string(got)
+	if strings.Contains(s, `"price"`) || strings.Contains(s, `"is_recent"`) || !strings.Contains(s, `"title":"Ficciones"`) {
+		t.Fatalf("reader view = %s", s)
+	}
+	// the remaining fields keep their order
+	if strings.Index(s, `"id"`) > strings.Index(s, `"title"`) || strings.Index(s, `"title"`) > strings.Index(s, `"publication_year"`) {
+		t.Fatalf("fields reordered: %s", s)
+	}
+}
@@ -0,0 +1,67 @@
+package presenter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+// Shelf labels are 2x1" at 203 dpi, the default Zebra desktop printer setup.
+const (
+	labelWidth  = 406
+	labelHeight = 203
+)
+
+// LabelFields are the book fields a shelf label prints.
+var LabelFields = []string{"title", "isbn", "price"}
+
+// ZPLLabel renders a shelf label for b: title (up to two lines), an EAN-13
+// barcode of the ISBN and the price. Books whose ISBN can't be encoded get
+// the ISBN printed as text instead of a barcode.
+func ZPLLabel(b domain.Book) []byte {
+	var sb strings.Builder
+	sb.WriteString("^XA\n^CI28\n") // UTF-8 field data
+	fmt.Fprintf(&sb, "^PW%d\n^LL%d\n", labelWidth, labelHeight)
+	fmt.Fprintf(&sb, "^FO16,12^A0N,24,24^FB%d,2,0,L^FH^FD%s^FS\n", labelWidth-32, zplEscape(b.Title))
+	if ean := isbnEAN(b.ISBN); ean != "" {
+		// ^BE takes the first 12 digits and prints its own check digit
+		fmt.Fprintf(&sb, "^FO16,72^BY2^BEN,80,Y,N^FD%s^FS\n", ean[:12])
+	} else {
+		fmt.Fprintf(&sb, "^FO16,100^A0N,22,22^FH^FDISBN %s^FS\n", zplEscape(b.ISBN))
+	}
+	fmt.Fprintf(&sb, "^FO230,110^A0N,40,40^FB160,1,0,R^FD%.2f^FS\n", b.Price)
+	sb.WriteString("^XZ\n")
+	return []byte(sb.String())
+}
+
+// isbnEAN returns the 13-digit EAN for a normalized ISBN-10 or ISBN-13, or
+// "" if it isn't one.
+func isbnEAN(isbn string) string {
+	switch len(isbn) {
+	case 13:
+		if strings.Trim(isbn, "0123456789") == "" {
+			return isbn
+		}
+	case 10:
+		if strings.Trim(isbn[:9], "0123456789") == "" {
+			body := "978" + isbn[:9]
+			sum := 0
+			for i, d := range body {
+				w := 1
+				if i%2 == 1 {
+					w = 3
+				}
+				sum += int(d-'0') * w
+			}
+			return body + string(rune('0'+(10-sum%10)%10))
+		}
+	}
+	return ""
+}
+
+// zplEscape hex-encodes the characters ZPL treats as commands; fields using
+// it must be preceded by ^FH.
+func zplEscape(s string) string {
+	return strings.NewReplacer("_", "_5F", "^", "_5E", "~", "_7E").Replace(s)
+}
@@ -0,0 +1,49 @@
+package presenter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gerry-sabar/byfood/internal/domain"
+)
+
+func TestZPLLabel(t *testing.T) {
+	got := string(ZPLLabel(domain.Book{Title: "C^C++ ~ a_b", ISBN: "9780132350884", Price: 29.9}))
+	for _, want := range []string{
+		"^XA\n",
+		"^FH^FDC_5EC++ _7E a_5Fb^FS",
+		"^BEN,80,Y,N^FD978013235088^FS",
+		"^FD29.90^FS",
+		"^XZ\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("label missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestZPLLabel_ISBN10AndFallback(t *testing.T) {
+	// 0132350882 is the ISBN-10 of 9780132350884
+	if got := string(ZPLLabel(domain.Book{ISBN: "0132350882"})); !strings.Contains(got, "^FD978013235088^FS") {
+		t.Fatalf("ISBN-10 not converted:\n%s", got)
+	}
+	got := string(ZPLLabel(domain.Book{ISBN: "12345"}))
+	if strings.Contains(got, "^BE") || !strings.Contains(got, "^FDISBN 12345^FS") {
+		t.Fatalf("unexpected fallback:\n%s", got)
+	}
+}
+
+func TestISBNEAN(t *testing.T) {
+	cases := map[string]string{
+		"9780132350884": "9780132350884",
+		"0132350882":    "9780132350884",
+		"080442957X":    "9780804429573",
+		"97801323508":   "",
+		"ABCDEFGHIJ":    "",
+	}
+	for in, want := range cases {
+		if got := isbnEAN(in); got != want {
+			t.Fatalf("isbnEAN(%s) = %q; want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// Package urlsafe decides which URLs the service may fetch on a caller's
+// behalf, so a caller can't make it reach localhost, the private network or
+// a cloud metadata endpoint (SSRF). Anything that fetches a URL it was given
+// (cleanup's resolve mode, webhook deliveries, metadata lookups) checks it
+// with a Policy and fetches it with the Policy's Client, which checks every
+// address it connects to again, after DNS resolution and on each redirect.
+package urlsafe
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrScheme    = errors.New("URL scheme must be http or https")
+	ErrUserinfo  = errors.New("URL must not contain credentials")
+	ErrHost      = errors.New("URL must have a host")
+	ErrLocalhost = errors.New("URL must not point to localhost")
+	ErrPrivate   = errors.New("URL must not point to a private or reserved address")
+)
+
+// maxRedirects matches net/http's default limit.
+const maxRedirects = 10
+
+// reserved are the ranges not covered by the netip.Addr predicates that must
+// not be reachable either.
+var reserved = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"),   // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentation
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentation
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved, broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64, may embed any IPv4
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+	netip.MustParsePrefix("2002::/16"),       // 6to4, may embed any IPv4
+}
+
+// Policy is the set of URLs that may be fetched. The zero value is the
+// production policy: public http(s) hosts only, without credentials.
+type Policy struct {
+	// AllowPrivate lets URLs reach localhost and private or reserved
+	// addresses. Only for tests and local development.
+	AllowPrivate bool
+}
+
+// Parse parses raw and checks it with CheckURL.
+func (p Policy) Parse(raw string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, fmt.Errorf("URL is not valid")
+	}
+	if err := p.CheckURL(u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// CheckURL rejects URLs that aren't http(s), carry user:password@, or name
+// localhost or a private address literally. Host names are checked once
+// they resolve, by the Client's dialer.
+func (p Policy) CheckURL(u *url.URL) error {
+	if s := strings.ToLower(u.Scheme); s != "http" && s != "https" {
+		return ErrScheme
+	}
+	if u.User != nil {
+		return ErrUserinfo
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return ErrHost
+	}
+	if p.AllowPrivate {
+		return nil
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrLocalhost
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return p.CheckAddr(addr)
+	}
+	return nil
+}
+
+// CheckAddr rejects loopback, private, link-local (including the
+// 169.254.169.254 metadata endpoint), multicast, unspecified and reserved
+// addresses, IPv4-mapped IPv6 forms included.
+func (p Policy) CheckAddr(addr netip.Addr) error {
+	if p.AllowPrivate {
+		return nil
+	}
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return ErrPrivate
+	}
+	for _, r := range reserved {
+		if r.Contains(addr) {
+			return ErrPrivate
+		}
+	}
+	return nil
+}
+
+// Control is a net.Dialer Control hook that refuses connections to
+// addresses CheckAddr rejects. It runs after DNS resolution, so a host name
+// can't smuggle a private address past CheckURL, nor rebind to one later.
+func (p Policy) Control(_, address string, _ syscall.RawConn) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("urlsafe: unexpected dial address %q: %w", address, err)
+	}
+	return p.CheckAddr(ap.Addr())
+}
+
+// Client returns an HTTP client that only connects to addresses the policy
+// allows and checks every redirect with CheckURL. Proxies from the
+// environment are ignored, since the proxy would make the connection the
+// policy is meant to vet.
+func (p Policy) Client(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, Control: p.Control}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return p.CheckURL(req.URL)
+		},
+	}
+}
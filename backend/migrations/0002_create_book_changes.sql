@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS book_changes (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  book_id BIGINT UNSIGNED NOT NULL,
+  op VARCHAR(16) NOT NULL,
+  created_at DATETIME NOT NULL,
+  PRIMARY KEY (id),
+  KEY idx_book_changes_book_id (book_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
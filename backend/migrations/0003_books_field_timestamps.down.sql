@@ -0,0 +1,3 @@
+ALTER TABLE books
+  DROP COLUMN field_updated_at,
+  MODIFY updated_at DATETIME NOT NULL;
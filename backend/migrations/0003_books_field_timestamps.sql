@@ -0,0 +1,5 @@
+-- Microsecond updated_at so concurrent writes can be told apart, plus the
+-- last-modified time of each individual field for conflict-aware merges.
+ALTER TABLE books
+  MODIFY updated_at DATETIME(6) NOT NULL,
+  ADD COLUMN field_updated_at JSON NULL;
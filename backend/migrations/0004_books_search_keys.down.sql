@@ -0,0 +1,5 @@
+ALTER TABLE books
+  DROP INDEX idx_books_title_key,
+  DROP INDEX idx_books_author_key,
+  DROP COLUMN title_key,
+  DROP COLUMN author_key;
@@ -0,0 +1,8 @@
+-- Lowercased, accent-stripped copies of title/author for accent-insensitive
+-- search. The application writes them on every insert/update (see
+-- domain.SearchKey); existing rows are filled in at startup.
+ALTER TABLE books
+  ADD COLUMN title_key VARCHAR(255) NOT NULL DEFAULT '',
+  ADD COLUMN author_key VARCHAR(255) NOT NULL DEFAULT '',
+  ADD INDEX idx_books_title_key (title_key),
+  ADD INDEX idx_books_author_key (author_key);
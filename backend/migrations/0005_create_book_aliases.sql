@@ -0,0 +1,12 @@
+-- Alternate titles (subtitles, original-language titles, ...) per book.
+CREATE TABLE IF NOT EXISTS book_aliases (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  book_id BIGINT UNSIGNED NOT NULL,
+  alias VARCHAR(255) NOT NULL,
+  alias_key VARCHAR(255) NOT NULL,
+  created_at DATETIME NOT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY idx_book_aliases_book_key (book_id, alias_key),
+  KEY idx_book_aliases_key (alias_key),
+  CONSTRAINT fk_book_aliases_book FOREIGN KEY (book_id) REFERENCES books (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,3 @@
+ALTER TABLE books
+  DROP INDEX idx_books_work_id,
+  DROP COLUMN work_id;
@@ -0,0 +1,5 @@
+-- Editions of the same work share a work_id: the id of the work's original
+-- record. NULL means the book is the only known edition.
+ALTER TABLE books
+  ADD COLUMN work_id BIGINT UNSIGNED NULL,
+  ADD INDEX idx_books_work_id (work_id);
@@ -0,0 +1,13 @@
+-- Every price change with its reason; bulk re-pricing groups rows by batch_id.
+CREATE TABLE IF NOT EXISTS book_price_history (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  book_id BIGINT UNSIGNED NOT NULL,
+  old_price DECIMAL(12,2) NOT NULL,
+  new_price DECIMAL(12,2) NOT NULL,
+  reason VARCHAR(255) NOT NULL,
+  batch_id VARCHAR(32) NULL,
+  changed_at DATETIME(6) NOT NULL,
+  PRIMARY KEY (id),
+  KEY idx_price_history_book (book_id, changed_at),
+  KEY idx_price_history_batch (batch_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,9 @@
+-- Per-book view counters, flushed in batches by the API; used to pick the
+-- books to preload into the cache on startup.
+CREATE TABLE IF NOT EXISTS book_views (
+  book_id BIGINT UNSIGNED NOT NULL,
+  views BIGINT UNSIGNED NOT NULL DEFAULT 0,
+  updated_at DATETIME(6) NOT NULL,
+  PRIMARY KEY (book_id),
+  KEY idx_book_views_views (views)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,4 @@
+-- Integer cents alongside the DECIMAL price. Filled online: the API writes both
+-- columns once MIGRATION_PRICE_CENTS=dual_write, a backfill job fills older
+-- rows, then read_new switches reads over. NULL means "not written yet".
+ALTER TABLE books ADD COLUMN price_cents BIGINT NULL AFTER price;
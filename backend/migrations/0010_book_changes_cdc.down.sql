@@ -0,0 +1,7 @@
+-- The entry snapshots and versions are lost; the entries themselves stay.
+ALTER TABLE book_changes
+  DROP KEY uq_book_changes_version,
+  DROP KEY idx_book_changes_created_at,
+  DROP COLUMN payload,
+  DROP COLUMN version,
+  DROP COLUMN entity;
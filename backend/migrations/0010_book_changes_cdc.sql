@@ -0,0 +1,18 @@
+-- The change log doubles as change-data-capture: every entry carries the
+-- entity, a per-entity version and a snapshot of the row right after the
+-- change. It is written by the service layer rather than DB triggers so it
+-- covers exactly the mutations the API considers meaningful and the payload
+-- matches the API's JSON shape.
+ALTER TABLE book_changes
+  ADD COLUMN entity VARCHAR(32) NOT NULL DEFAULT 'book' AFTER id,
+  ADD COLUMN version BIGINT UNSIGNED NOT NULL DEFAULT 0 AFTER op,
+  ADD COLUMN payload JSON NULL AFTER version,
+  ADD KEY idx_book_changes_created_at (created_at);
+
+-- number the entries written before versions existed
+UPDATE book_changes c
+JOIN (SELECT id, ROW_NUMBER() OVER (PARTITION BY entity, book_id ORDER BY id) AS v FROM book_changes) n
+  ON n.id = c.id
+SET c.version = n.v;
+
+ALTER TABLE book_changes ADD UNIQUE KEY uq_book_changes_version (entity, book_id, version);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS projection_cursors;
+DROP TABLE IF EXISTS author_summaries;
+DROP TABLE IF EXISTS author_books;
@@ -0,0 +1,28 @@
+-- Read model behind GET /authors/{id}/summary, maintained by a projection
+-- that follows book_changes. It can be dropped and rebuilt at any time.
+CREATE TABLE IF NOT EXISTS author_books (
+  book_id BIGINT UNSIGNED NOT NULL,
+  author_id VARCHAR(255) NOT NULL,
+  PRIMARY KEY (book_id),
+  KEY idx_author_books_author (author_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS author_summaries (
+  id VARCHAR(255) NOT NULL,
+  name VARCHAR(255) NOT NULL,
+  book_count INT UNSIGNED NOT NULL,
+  first_year SMALLINT NULL,
+  last_year SMALLINT NULL,
+  min_price DECIMAL(12,2) NOT NULL,
+  max_price DECIMAL(12,2) NOT NULL,
+  books JSON NOT NULL,
+  updated_at DATETIME(6) NOT NULL,
+  PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- How far each projection has read the change log.
+CREATE TABLE IF NOT EXISTS projection_cursors (
+  name VARCHAR(64) NOT NULL,
+  position BIGINT UNSIGNED NOT NULL,
+  PRIMARY KEY (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS saved_searches (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  name VARCHAR(120) NOT NULL,
+  query VARCHAR(500) NOT NULL,
+  notify TINYINT(1) NOT NULL DEFAULT 0,
+  created_at DATETIME(6) NOT NULL,
+  PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- One row per (search, book): a book is announced to a search at most once,
+-- however often the matcher sees it.
+CREATE TABLE IF NOT EXISTS saved_search_notifications (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  search_id BIGINT UNSIGNED NOT NULL,
+  book_id BIGINT UNSIGNED NOT NULL,
+  title VARCHAR(255) NOT NULL,
+  created_at DATETIME(6) NOT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY uq_saved_search_book (search_id, book_id),
+  CONSTRAINT fk_saved_search_notifications_search
+    FOREIGN KEY (search_id) REFERENCES saved_searches (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
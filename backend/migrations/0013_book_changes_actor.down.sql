@@ -0,0 +1,3 @@
+ALTER TABLE book_changes
+  DROP COLUMN impersonated_by,
+  DROP COLUMN actor;
@@ -0,0 +1,5 @@
+-- Who made each change. impersonated_by is the admin when one acted as
+-- actor; both are empty for anonymous requests and background jobs.
+ALTER TABLE book_changes
+  ADD COLUMN actor VARCHAR(255) NOT NULL DEFAULT '',
+  ADD COLUMN impersonated_by VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,4 @@
+-- Optimistic concurrency: every write to a book increments version, and
+-- clients send back the version they read (body or If-Match) to update.
+ALTER TABLE books
+  ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,12 @@
+-- Upstream catalogue messages already consumed, so redeliveries are skipped.
+-- error is set for messages that could not be applied and won't be retried.
+CREATE TABLE IF NOT EXISTS catalog_inbox (
+  source VARCHAR(255) NOT NULL,
+  message_id VARCHAR(255) NOT NULL,
+  op VARCHAR(16) NOT NULL DEFAULT '',
+  book_id BIGINT UNSIGNED NULL,
+  error TEXT NULL,
+  processed_at DATETIME(6) NOT NULL,
+  PRIMARY KEY (source, message_id),
+  KEY idx_catalog_inbox_processed_at (processed_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,13 @@
+-- Async work that failed (notification deliveries, upstream events, job
+-- runs), kept until an operator retries or discards it via /admin/dlq.
+CREATE TABLE IF NOT EXISTS dead_letters (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  kind VARCHAR(64) NOT NULL,
+  payload JSON NOT NULL,
+  error TEXT NOT NULL,
+  attempts INT UNSIGNED NOT NULL DEFAULT 1,
+  created_at DATETIME(6) NOT NULL,
+  last_attempt_at DATETIME(6) NOT NULL,
+  PRIMARY KEY (id),
+  KEY idx_dead_letters_kind (kind, id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,8 @@
+-- Background workers an admin paused via /admin/workers; every replica
+-- polls this table and pauses its own copy of the worker.
+CREATE TABLE IF NOT EXISTS workers (
+  name VARCHAR(64) NOT NULL,
+  paused TINYINT(1) NOT NULL DEFAULT 0,
+  updated_at DATETIME(6) NOT NULL,
+  PRIMARY KEY (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
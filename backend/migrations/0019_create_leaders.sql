@@ -0,0 +1,8 @@
+-- The replica currently elected leader, written by the replica when it wins
+-- the election so that every replica can report who leads.
+CREATE TABLE IF NOT EXISTS leaders (
+  election VARCHAR(64) NOT NULL,
+  identity VARCHAR(255) NOT NULL,
+  elected_at DATETIME(6) NOT NULL,
+  PRIMARY KEY (election)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
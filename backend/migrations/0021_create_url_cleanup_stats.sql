@@ -0,0 +1,10 @@
+-- Daily counters of POST /url/cleanup requests per domain, operation (or
+-- profile) and outcome, behind GET /url/cleanup/stats.
+CREATE TABLE IF NOT EXISTS url_cleanup_stats (
+  day DATE NOT NULL,
+  domain VARCHAR(253) NOT NULL,
+  operation VARCHAR(64) NOT NULL,
+  outcome VARCHAR(16) NOT NULL,
+  requests BIGINT NOT NULL DEFAULT 0,
+  PRIMARY KEY (day, domain, operation, outcome)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,9 @@
+-- Each tenant's tracking parameter lists for the strip_tracking_params
+-- cleanup rule, managed under /url/cleanup/rules. rules holds the strip,
+-- keep and hosts lists as JSON.
+CREATE TABLE IF NOT EXISTS tracking_rules (
+  tenant VARCHAR(191) NOT NULL,
+  rules JSON NOT NULL,
+  updated_at DATETIME(6) NOT NULL,
+  PRIMARY KEY (tenant)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
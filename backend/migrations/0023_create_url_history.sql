@@ -0,0 +1,12 @@
+-- Each user's recent URL cleanups, behind GET /me/url-history. Only the
+-- newest entries per owner are kept.
+CREATE TABLE IF NOT EXISTS url_history (
+  id BIGINT NOT NULL AUTO_INCREMENT,
+  owner VARCHAR(191) NOT NULL,
+  url VARCHAR(2048) NOT NULL,
+  processed_url VARCHAR(2048) NOT NULL,
+  operation VARCHAR(64) NOT NULL,
+  created_at DATETIME(6) NOT NULL,
+  PRIMARY KEY (id),
+  KEY idx_url_history_owner (owner, id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
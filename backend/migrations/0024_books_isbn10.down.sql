@@ -0,0 +1,3 @@
+ALTER TABLE books
+  DROP INDEX idx_books_isbn10,
+  DROP COLUMN isbn10;
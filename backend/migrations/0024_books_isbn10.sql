@@ -0,0 +1,6 @@
+-- Books store the ISBN-13; isbn10 keeps the ISBN-10 of those that have one,
+-- so lookups by either form hit an index. The server converts existing rows
+-- on start.
+ALTER TABLE books
+  ADD COLUMN isbn10 VARCHAR(10) NOT NULL DEFAULT '' AFTER isbn,
+  ADD INDEX idx_books_isbn10 (isbn10);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS bulk_jobs;
+DROP TABLE IF EXISTS book_categories;
@@ -0,0 +1,24 @@
+-- Categories a cataloguer files books under, as slugs such as
+-- science-fiction. A book has any number of them.
+CREATE TABLE IF NOT EXISTS book_categories (
+  book_id BIGINT UNSIGNED NOT NULL,
+  category VARCHAR(64) NOT NULL,
+  PRIMARY KEY (book_id, category),
+  KEY idx_book_categories_category (category),
+  CONSTRAINT fk_book_categories_book FOREIGN KEY (book_id) REFERENCES books (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Progress of bulk operations that run in the background, such as
+-- POST /books/bulk-tag, so any replica can answer a poll for it.
+CREATE TABLE IF NOT EXISTS bulk_jobs (
+  id CHAR(32) NOT NULL,
+  kind VARCHAR(32) NOT NULL,
+  status VARCHAR(16) NOT NULL,
+  total INT NOT NULL,
+  done INT NOT NULL DEFAULT 0,
+  error TEXT NOT NULL,
+  actor VARCHAR(255) NOT NULL DEFAULT '',
+  created_at DATETIME(6) NOT NULL,
+  updated_at DATETIME(6) NOT NULL,
+  PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
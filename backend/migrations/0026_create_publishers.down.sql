@@ -0,0 +1,4 @@
+ALTER TABLE books
+  DROP FOREIGN KEY fk_books_publisher,
+  DROP COLUMN publisher_id;
+DROP TABLE IF EXISTS publishers;
@@ -0,0 +1,19 @@
+-- Publishers books can be filed under. Names are unique, ignoring case and
+-- accents like the table's collation; country is an ISO 3166-1 alpha-2
+-- code and website an http(s) URL, both optional.
+CREATE TABLE IF NOT EXISTS publishers (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  name VARCHAR(120) NOT NULL,
+  country CHAR(2) NOT NULL DEFAULT '',
+  website VARCHAR(255) NOT NULL DEFAULT '',
+  created_at DATETIME(6) NOT NULL,
+  updated_at DATETIME(6) NOT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY uq_publishers_name (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- A book has at most one publisher. A publisher with books can't be
+-- deleted until they are detached from it.
+ALTER TABLE books
+  ADD COLUMN publisher_id BIGINT UNSIGNED NULL,
+  ADD CONSTRAINT fk_books_publisher FOREIGN KEY (publisher_id) REFERENCES publishers (id) ON DELETE RESTRICT;
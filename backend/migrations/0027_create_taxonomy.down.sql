@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS taxonomy_authors;
+DROP TABLE IF EXISTS taxonomy_categories;
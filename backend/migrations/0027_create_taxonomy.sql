@@ -0,0 +1,21 @@
+-- The curated taxonomy, exported and imported as a whole through
+-- /taxonomy so it can be reviewed in Git and promoted between
+-- environments. Categories name the slugs books are filed under and may
+-- sit inside a parent category ('' for a top-level one); books may still
+-- be filed under slugs the taxonomy doesn't name.
+CREATE TABLE IF NOT EXISTS taxonomy_categories (
+  slug VARCHAR(64) NOT NULL,
+  name VARCHAR(120) NOT NULL,
+  parent VARCHAR(64) NOT NULL DEFAULT '',
+  updated_at DATETIME(6) NOT NULL,
+  PRIMARY KEY (slug)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Curated display names of authors, by author slug. Author pages show them
+-- instead of the spelling on the author's newest book.
+CREATE TABLE IF NOT EXISTS taxonomy_authors (
+  id VARCHAR(255) NOT NULL,
+  name VARCHAR(255) NOT NULL,
+  updated_at DATETIME(6) NOT NULL,
+  PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,11 @@
+-- Each tenant's defaults for GET /books/, managed under
+-- /me/list-preferences. sort is a field of the sort parameter, with - for
+-- descending; empty fields and a page_size of 0 leave the server default.
+CREATE TABLE IF NOT EXISTS list_preferences (
+  tenant VARCHAR(191) NOT NULL,
+  sort VARCHAR(32) NOT NULL DEFAULT '',
+  collation VARCHAR(8) NOT NULL DEFAULT '',
+  page_size INT NOT NULL DEFAULT 0,
+  updated_at DATETIME(6) NOT NULL,
+  PRIMARY KEY (tenant)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
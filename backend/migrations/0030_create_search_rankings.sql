@@ -0,0 +1,10 @@
+-- Each tenant's search ranking, managed under /admin/search/ranking.
+-- Tenants without a row rank with domain.DefaultRankingWeights.
+CREATE TABLE IF NOT EXISTS search_rankings (
+  tenant VARCHAR(191) NOT NULL,
+  title_weight DOUBLE NOT NULL,
+  author_weight DOUBLE NOT NULL,
+  recency_boost DOUBLE NOT NULL,
+  updated_at DATETIME(6) NOT NULL,
+  PRIMARY KEY (tenant)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,17 @@
+-- Books lent to users, managed under /loans. A loan is open until
+-- returned_at is set; a book is on at most one open loan, which checkouts
+-- and book deletes check under a lock on the book.
+CREATE TABLE IF NOT EXISTS loans (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  book_id BIGINT UNSIGNED NOT NULL,
+  user_id BIGINT UNSIGNED NOT NULL,
+  checked_out_at DATETIME(6) NOT NULL,
+  due_at DATETIME(6) NOT NULL,
+  returned_at DATETIME(6) NULL,
+  PRIMARY KEY (id),
+  KEY idx_loans_book (book_id, returned_at),
+  KEY idx_loans_due (returned_at, due_at),
+  KEY idx_loans_user (user_id),
+  CONSTRAINT fk_loans_book FOREIGN KEY (book_id) REFERENCES books (id) ON DELETE CASCADE,
+  CONSTRAINT fk_loans_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,12 @@
+-- Daily counters of book searches per folded query: how often it was
+-- searched, how often it found nothing and how often a result was opened,
+-- behind GET /admin/search/insights.
+CREATE TABLE IF NOT EXISTS search_query_stats (
+  day DATE NOT NULL,
+  query_key VARCHAR(191) NOT NULL,
+  searches BIGINT NOT NULL DEFAULT 0,
+  zero_results BIGINT NOT NULL DEFAULT 0,
+  clicks BIGINT NOT NULL DEFAULT 0,
+  PRIMARY KEY (day, query_key),
+  KEY idx_search_query_stats_query (query_key)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,18 @@
+-- Holds on books out on loan, managed under /holds. Waiting holds form a
+-- queue per book in id order; returning the book lends it to the first one
+-- and records the loan on it.
+CREATE TABLE IF NOT EXISTS holds (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  book_id BIGINT UNSIGNED NOT NULL,
+  user_id BIGINT UNSIGNED NOT NULL,
+  status VARCHAR(16) NOT NULL DEFAULT 'waiting',
+  placed_at DATETIME(6) NOT NULL,
+  loan_id BIGINT UNSIGNED NULL,
+  closed_at DATETIME(6) NULL,
+  PRIMARY KEY (id),
+  KEY idx_holds_queue (book_id, status, id),
+  KEY idx_holds_user (user_id),
+  CONSTRAINT fk_holds_book FOREIGN KEY (book_id) REFERENCES books (id) ON DELETE CASCADE,
+  CONSTRAINT fk_holds_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+  CONSTRAINT fk_holds_loan FOREIGN KEY (loan_id) REFERENCES loans (id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS synonym_terms;
+DROP TABLE IF EXISTS synonym_groups;
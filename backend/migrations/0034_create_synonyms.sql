@@ -0,0 +1,19 @@
+-- The synonym dictionary, managed under /admin/search/synonyms. A group's
+-- terms are interchangeable in searches; term_key is the folded term, so a
+-- term belongs to one group only.
+CREATE TABLE IF NOT EXISTS synonym_groups (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  created_at DATETIME(6) NOT NULL,
+  updated_at DATETIME(6) NOT NULL,
+  PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS synonym_terms (
+  group_id BIGINT UNSIGNED NOT NULL,
+  position INT NOT NULL,
+  term VARCHAR(64) NOT NULL,
+  term_key VARCHAR(191) NOT NULL,
+  PRIMARY KEY (group_id, position),
+  UNIQUE KEY uq_synonym_terms_key (term_key),
+  CONSTRAINT fk_synonym_terms_group FOREIGN KEY (group_id) REFERENCES synonym_groups (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
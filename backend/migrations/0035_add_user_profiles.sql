@@ -0,0 +1,7 @@
+-- Profile fields of users, managed under /users and /me. Existing accounts
+-- start with no name, last updated when they were created.
+ALTER TABLE users
+  ADD COLUMN name VARCHAR(100) NOT NULL DEFAULT '',
+  ADD COLUMN updated_at DATETIME(6) NULL;
+UPDATE users SET updated_at = created_at;
+ALTER TABLE users MODIFY COLUMN updated_at DATETIME(6) NOT NULL;
@@ -0,0 +1,12 @@
+-- Books users bookmarked with POST /books/{id}/favorite, listed newest first
+-- under /me/favorites.
+CREATE TABLE IF NOT EXISTS favorites (
+  user_id BIGINT UNSIGNED NOT NULL,
+  book_id BIGINT UNSIGNED NOT NULL,
+  created_at DATETIME(6) NOT NULL,
+  PRIMARY KEY (user_id, book_id),
+  KEY idx_favorites_user_created (user_id, created_at),
+  KEY idx_favorites_book (book_id),
+  CONSTRAINT fk_favorites_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+  CONSTRAINT fk_favorites_book FOREIGN KEY (book_id) REFERENCES books (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
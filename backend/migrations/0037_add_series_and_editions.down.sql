@@ -0,0 +1,7 @@
+ALTER TABLE books
+  DROP FOREIGN KEY fk_books_series,
+  DROP INDEX idx_books_series,
+  DROP COLUMN series_id,
+  DROP COLUMN series_position,
+  DROP COLUMN format;
+DROP TABLE IF EXISTS series;
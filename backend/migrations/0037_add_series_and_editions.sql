@@ -0,0 +1,21 @@
+-- Series books belong to, such as "The Expanse". Names are unique, ignoring
+-- case and accents like the table's collation.
+CREATE TABLE IF NOT EXISTS series (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  name VARCHAR(120) NOT NULL,
+  created_at DATETIME(6) NOT NULL,
+  updated_at DATETIME(6) NOT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY uq_series_name (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- A book is in at most one series, at a position counted from 1; editions
+-- of the same work share the position. A series with books can't be
+-- deleted until they are detached from it. format tells the editions of a
+-- work apart: hardcover, paperback, ebook, or '' when unknown.
+ALTER TABLE books
+  ADD COLUMN series_id BIGINT UNSIGNED NULL,
+  ADD COLUMN series_position INT UNSIGNED NULL,
+  ADD COLUMN format VARCHAR(16) NOT NULL DEFAULT '',
+  ADD INDEX idx_books_series (series_id, series_position),
+  ADD CONSTRAINT fk_books_series FOREIGN KEY (series_id) REFERENCES series (id) ON DELETE RESTRICT;
@@ -0,0 +1,2 @@
+ALTER TABLE book_price_history
+  DROP COLUMN changed_by;
@@ -0,0 +1,5 @@
+-- Who changed each price, the actor as in book_changes; empty for anonymous
+-- requests and background jobs. Book edits now record their price changes
+-- too, with the reason 'update'.
+ALTER TABLE book_price_history
+  ADD COLUMN changed_by VARCHAR(255) NOT NULL DEFAULT '' AFTER batch_id;
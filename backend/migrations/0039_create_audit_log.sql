@@ -0,0 +1,15 @@
+-- Every create, update and delete of a book, written in the transaction of
+-- the change: who made it, when, and the changed fields with their values
+-- before and after as a JSON object. There is no foreign key to books so
+-- that the entries of a deleted book stay, its deletion included.
+CREATE TABLE IF NOT EXISTS audit_log (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  book_id BIGINT UNSIGNED NOT NULL,
+  op VARCHAR(16) NOT NULL,
+  actor VARCHAR(255) NOT NULL DEFAULT '',
+  impersonated_by VARCHAR(255) NOT NULL DEFAULT '',
+  changes JSON NOT NULL,
+  created_at DATETIME(6) NOT NULL,
+  PRIMARY KEY (id),
+  KEY idx_audit_log_book (book_id, id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,36 @@
+-- Endpoints book events are POSTed to. The secret signs every delivery, so
+-- it is kept as given; events is a comma separated list of event types,
+-- empty for all of them.
+CREATE TABLE IF NOT EXISTS webhooks (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  url VARCHAR(2048) NOT NULL,
+  secret VARCHAR(255) NOT NULL,
+  events VARCHAR(255) NOT NULL DEFAULT '',
+  active BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at DATETIME(6) NOT NULL,
+  updated_at DATETIME(6) NOT NULL,
+  PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- One row per event and webhook, with the body sent and the outcome of the
+-- latest attempt; pending rows are tried again at next_attempt_at. The
+-- unique key keeps an event from being queued twice for a webhook, e.g.
+-- when events are replayed.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  webhook_id BIGINT UNSIGNED NOT NULL,
+  event_offset BIGINT UNSIGNED NOT NULL,
+  event_type VARCHAR(64) NOT NULL,
+  payload JSON NOT NULL,
+  status VARCHAR(16) NOT NULL,
+  attempts INT UNSIGNED NOT NULL DEFAULT 0,
+  response_status INT NOT NULL DEFAULT 0,
+  error VARCHAR(1024) NOT NULL DEFAULT '',
+  next_attempt_at DATETIME(6) NULL,
+  last_attempt_at DATETIME(6) NULL,
+  created_at DATETIME(6) NOT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY uq_webhook_deliveries_event (webhook_id, event_offset),
+  KEY idx_webhook_deliveries_due (status, next_attempt_at),
+  CONSTRAINT fk_webhook_deliveries_webhook FOREIGN KEY (webhook_id) REFERENCES webhooks (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
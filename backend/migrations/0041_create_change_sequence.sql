@@ -0,0 +1,13 @@
+-- The last id handed to a book_changes entry. Appends lock this row for the
+-- length of their transaction and take the next id from it, so entries
+-- commit in id order: a reader that has seen an id has seen every smaller
+-- one. AUTO_INCREMENT alone hands out ids before commit, and a smaller id
+-- could become visible after a larger one already was.
+CREATE TABLE IF NOT EXISTS change_sequence (
+  name VARCHAR(32) NOT NULL,
+  last_id BIGINT UNSIGNED NOT NULL,
+  PRIMARY KEY (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+INSERT IGNORE INTO change_sequence (name, last_id)
+SELECT 'book_changes', COALESCE(MAX(id), 0) FROM book_changes;
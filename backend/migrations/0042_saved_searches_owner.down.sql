@@ -0,0 +1,3 @@
+ALTER TABLE saved_searches
+  DROP INDEX idx_saved_searches_owner,
+  DROP COLUMN owner;
@@ -0,0 +1,6 @@
+-- The actor who saved each search, who alone may see or delete it. Searches
+-- saved before owners were recorded have none, and only the matcher sees
+-- them.
+ALTER TABLE saved_searches
+  ADD COLUMN owner VARCHAR(191) NOT NULL DEFAULT '' AFTER id,
+  ADD INDEX idx_saved_searches_owner (owner);
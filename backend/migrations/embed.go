@@ -0,0 +1,9 @@
+// Package migrations embeds the schema migrations into the binary. Each
+// NNNN_name.sql migrates up and its NNNN_name.down.sql reverts it; see
+// the mysql adapter's Migrator.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,120 @@
+package urlclean
+
+import (
+	"net"
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/idna"
+)
+
+// idnProfile converts host names without rejecting the ones browsers
+// accept anyway, such as labels with underscores.
+var idnProfile = idna.New(idna.MapForLookup(), idna.Transitional(false), idna.StrictDomainName(false))
+
+// Host is a host name in both of its spellings, with what its scripts say
+// about it.
+type Host struct {
+	// ASCII is the punycode form, what DNS and HTTP see.
+	ASCII string `json:"ascii" example:"xn--80ak6aa92e.com"`
+	// Unicode is the form to show people.
+	Unicode string `json:"unicode" example:"аррӏе.com"`
+	// Scripts are the Unicode scripts of the host's letters, sorted.
+	Scripts []string `json:"scripts" example:"Cyrillic"`
+	// Confusable is set when a label mixes scripts (other than the CJK
+	// combinations Japanese and Korean use), or is written in Cyrillic,
+	// Greek or Armenian letters that all look like Latin ones: the usual
+	// ways to spoof a well-known domain.
+	Confusable bool `json:"confusable"`
+}
+
+// ParseHost returns both forms of host and checks it for confusables. IP
+// addresses come back as they are. It fails on host names that aren't
+// valid IDNA.
+func ParseHost(host string) (Host, error) {
+	if net.ParseIP(strings.Trim(host, "[]")) != nil {
+		return Host{ASCII: host, Unicode: host, Scripts: []string{}}, nil
+	}
+	ascii, err := idnProfile.ToASCII(host)
+	if err != nil {
+		return Host{}, err
+	}
+	uni, err := idnProfile.ToUnicode(ascii)
+	if err != nil {
+		return Host{}, err
+	}
+	h := Host{ASCII: ascii, Unicode: uni, Scripts: []string{}}
+	for _, label := range strings.Split(uni, ".") {
+		scripts := labelScripts(label)
+		for _, s := range scripts {
+			if !slices.Contains(h.Scripts, s) {
+				h.Scripts = append(h.Scripts, s)
+			}
+		}
+		if mixedScripts(scripts) || latinLookalike(label, scripts) {
+			h.Confusable = true
+		}
+	}
+	slices.Sort(h.Scripts)
+	return h, nil
+}
+
+// labelScripts lists the scripts of a label's letters, ignoring digits,
+// hyphens and other characters shared by all scripts.
+func labelScripts(label string) []string {
+	var out []string
+	for _, r := range label {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		for name, table := range unicode.Scripts {
+			if name == "Common" || name == "Inherited" || !unicode.Is(table, r) {
+				continue
+			}
+			if !slices.Contains(out, name) {
+				out = append(out, name)
+			}
+			break
+		}
+	}
+	return out
+}
+
+// cjkScripts may share a label: Japanese mixes Han with the kana, Korean
+// Han with Hangul, and Chinese Han with Bopomofo.
+var cjkScripts = []string{"Han", "Hiragana", "Katakana", "Hangul", "Bopomofo"}
+
+func mixedScripts(scripts []string) bool {
+	if len(scripts) < 2 {
+		return false
+	}
+	for _, s := range scripts {
+		if !slices.Contains(cjkScripts, s) {
+			return true
+		}
+	}
+	// Hangul and kana together is neither Japanese nor Korean
+	return slices.Contains(scripts, "Hangul") &&
+		(slices.Contains(scripts, "Hiragana") || slices.Contains(scripts, "Katakana"))
+}
+
+// latinLookalikes are the lowercase Cyrillic, Greek and Armenian letters
+// that render like Latin ones in common fonts (after UTS #39).
+const latinLookalikes = "аеорсухіјѕԁӏԛԝһвкмнтьѵ" + // Cyrillic
+	"αοιρνυκτχγεηω" + // Greek
+	"օսոհզց" // Armenian
+
+// latinLookalike reports whether a single-script, non-Latin label is
+// spelled only with letters that look Latin, like "аррӏе" in Cyrillic.
+func latinLookalike(label string, scripts []string) bool {
+	if len(scripts) != 1 || !slices.Contains([]string{"Cyrillic", "Greek", "Armenian"}, scripts[0]) {
+		return false
+	}
+	for _, r := range label {
+		if unicode.IsLetter(r) && !strings.ContainsRune(latinLookalikes, r) {
+			return false
+		}
+	}
+	return true
+}
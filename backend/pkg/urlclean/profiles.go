@@ -0,0 +1,177 @@
+package urlclean
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Profile is a named canonical form: the rules applied to a URL, in order.
+type Profile struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Rules       []Rule `yaml:"rules"`
+	// HTTPSHosts limits upgrade_https to these hosts and their subdomains;
+	// empty upgrades every host.
+	HTTPSHosts []string `yaml:"https_hosts"`
+
+	tracking *TrackingParams // Options.Tracking of the Clean call
+}
+
+// upgrades reports whether upgrade_https applies to host.
+func (p *Profile) upgrades(host string) bool {
+	if len(p.HTTPSHosts) == 0 {
+		return true
+	}
+	host = strings.ToLower(host)
+	for _, h := range p.HTTPSHosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+// wwwRules are the www policies; a profile picks at most one.
+var wwwRules = []Rule{AddWWW, ForceWWW, StripWWW}
+
+var redirectionRules = []Rule{
+	LowercaseHost, AddWWW, LowercasePath, TrimTrailingSlash, TrimQueryValueSlashes, DropFragment,
+}
+
+// builtIn are the operations POST /url/cleanup has always offered.
+var builtIn = []Profile{
+	{Name: "redirection", Description: "Lower-cased host and path, www. for bare domains, no trailing slash or fragment",
+		Rules: redirectionRules},
+	{Name: "canonical", Description: "Host and path as given, without query, fragment or trailing slash",
+		Rules: []Rule{DropQuery, DropFragment, TrimTrailingSlash}},
+	{Name: "all", Description: "redirection, then canonical",
+		Rules: append(append([]Rule(nil), redirectionRules...), DropQuery)},
+}
+
+// reserved names can't be used by registered profiles.
+var reserved = []string{"resolve"}
+
+// Profiles is a set of profiles: the built-in ones and any registered with
+// NewProfiles. A nil *Profiles holds the built-in profiles only.
+type Profiles struct {
+	byName map[string]Profile
+	names  []string // built-ins first, then registered profiles in order
+}
+
+// NewProfiles returns the built-in profiles plus extra. Names are
+// lower-cased; each must be new and every rule known.
+func NewProfiles(extra ...Profile) (*Profiles, error) {
+	ps := &Profiles{byName: map[string]Profile{}}
+	for _, p := range builtIn {
+		ps.add(p)
+	}
+	for _, p := range extra {
+		p.Name = strings.ToLower(strings.TrimSpace(p.Name))
+		switch {
+		case p.Name == "":
+			return nil, fmt.Errorf("profile without a name")
+		case slices.Contains(reserved, p.Name):
+			return nil, fmt.Errorf("profile %q: name is reserved", p.Name)
+		case len(p.Rules) == 0:
+			return nil, fmt.Errorf("profile %q has no rules", p.Name)
+		}
+		if _, dup := ps.byName[p.Name]; dup {
+			return nil, fmt.Errorf("profile %q is defined twice", p.Name)
+		}
+		www, slash, dup := 0, 0, 0
+		for _, r := range p.Rules {
+			if !known(r) {
+				return nil, fmt.Errorf("profile %q: %w %q", p.Name, ErrUnknownRule, r)
+			}
+			if slices.Contains(wwwRules, r) {
+				www++
+			}
+			if slices.Contains(slashRules, r) {
+				slash++
+			}
+			if slices.Contains(duplicateRules, r) {
+				dup++
+			}
+		}
+		if www > 1 {
+			return nil, fmt.Errorf("profile %q: add_www, force_www and strip_www exclude each other", p.Name)
+		}
+		if slash > 1 {
+			return nil, fmt.Errorf("profile %q: trim_trailing_slash and add_trailing_slash exclude each other", p.Name)
+		}
+		if dup > 1 {
+			return nil, fmt.Errorf("profile %q: keep_first_param and keep_last_param exclude each other", p.Name)
+		}
+		if len(p.HTTPSHosts) > 0 && !slices.Contains(p.Rules, UpgradeHTTPS) {
+			return nil, fmt.Errorf("profile %q: https_hosts needs the upgrade_https rule", p.Name)
+		}
+		hosts := make([]string, 0, len(p.HTTPSHosts))
+		for _, h := range p.HTTPSHosts {
+			hosts = append(hosts, strings.ToLower(strings.TrimPrefix(strings.TrimSpace(h), ".")))
+		}
+		p.HTTPSHosts = hosts
+		ps.add(p)
+	}
+	return ps, nil
+}
+
+func (ps *Profiles) add(p Profile) {
+	p.Rules = append([]Rule(nil), p.Rules...)
+	ps.byName[p.Name] = p
+	ps.names = append(ps.names, p.Name)
+}
+
+var builtInProfiles, _ = NewProfiles()
+
+// Lookup returns the profile called name.
+func (ps *Profiles) Lookup(name string) (Profile, bool) {
+	if ps == nil {
+		ps = builtInProfiles
+	}
+	p, ok := ps.byName[name]
+	return p, ok
+}
+
+// List returns every profile, built-ins first.
+func (ps *Profiles) List() []Profile {
+	if ps == nil {
+		ps = builtInProfiles
+	}
+	out := make([]Profile, 0, len(ps.names))
+	for _, name := range ps.names {
+		out = append(out, ps.byName[name])
+	}
+	return out
+}
+
+// IsBuiltIn reports whether name is a built-in profile.
+func IsBuiltIn(name string) bool {
+	return slices.ContainsFunc(builtIn, func(p Profile) bool { return p.Name == name })
+}
+
+// LoadProfiles reads profiles from YAML of the form
+//
+//	profiles:
+//	  - name: cache_key
+//	    description: One key per page for the CDN cache
+//	    rules: [lowercase_host, strip_tracking_params, sort_query, drop_fragment]
+//	  - name: blog_seo
+//	    rules: [upgrade_https, strip_www, lowercase_host, trim_trailing_slash]
+//	    https_hosts: [blog.example.com]
+//
+// Unknown keys are errors, so a typo doesn't silently drop a setting.
+func LoadProfiles(r io.Reader) ([]Profile, error) {
+	var file struct {
+		Profiles []Profile `yaml:"profiles"`
+	}
+	dec := yaml.NewDecoder(r)
+	dec.SetStrict(true)
+	if err := dec.Decode(&file); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("parse profiles: %w", err)
+	}
+	return file.Profiles, nil
+}
@@ -0,0 +1,531 @@
+// Package urlclean normalizes URLs the way the byfood API's POST /url/cleanup
+// does, for Go services that would rather call a function than the API.
+//
+//	out, err := urlclean.Clean(ctx, "https://Example.com/Books/?utm_source=x#top", urlclean.Options{Profile: "all"})
+//	// out == "https://www.example.com/books"
+//
+// A profile is a named list of rules applied in order. The built-in profiles
+// are "redirection", "canonical" and "all"; more can be registered with
+// NewProfiles, for instance from YAML read by LoadProfiles. Clean never
+// fetches the URL.
+package urlclean
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// MaxLength is the longest URL accepted, the limit most browsers and CDNs
+// agree on.
+const MaxLength = 2048
+
+// The errors of Parse. Their messages are meant for end users.
+var (
+	ErrEmpty   = errors.New("URL is required")
+	ErrTooLong = errors.New("URL must be ≤ 2048 characters")
+	ErrInvalid = errors.New("URL is not valid")
+	ErrScheme  = errors.New("URL scheme must be http or https")
+	ErrNoHost  = errors.New("URL must have a host")
+	// ErrPort is returned by Clean for profiles with reject_port.
+	ErrPort = errors.New("URL must not have a port")
+
+	ErrUnknownProfile  = errors.New("unknown profile")
+	ErrUnknownRule     = errors.New("unknown rule")
+	ErrUnknownFragment = errors.New("unknown fragment handling")
+	ErrUnknownQuery    = errors.New("unknown query parameter handling")
+)
+
+// Options select how Clean normalizes a URL.
+type Options struct {
+	// Profile names the profile to apply; empty means "all".
+	Profile string
+	// Profiles looks Profile up; nil offers the built-in profiles only.
+	Profiles *Profiles
+	// Rules, when set, are applied in order instead of a profile. Their
+	// upgrade_https upgrades every host.
+	Rules []Rule
+	// Fragment, when set, replaces the fragment rules of the profile or
+	// Rules.
+	Fragment Fragment
+	// Duplicates and EmptyParams, when set, replace the profile's rules for
+	// repeated query keys and for parameters without a value.
+	Duplicates  Duplicates
+	EmptyParams EmptyParams
+	// Tracking, when set, changes which parameters strip_tracking_params
+	// removes.
+	Tracking *TrackingParams
+}
+
+// TrackingParams adds to and exempts from the tracking parameters
+// strip_tracking_params removes, for instance a tenant's own campaign IDs.
+// Names ignore case, and a trailing * matches any suffix, as in utm_*.
+type TrackingParams struct {
+	Strip []string // removed too
+	Keep  []string // never removed, even when built in or listed in Strip
+	// Hosts apply on top of Strip and Keep to their host and its
+	// subdomains.
+	Hosts []HostTrackingParams
+}
+
+// HostTrackingParams are the tracking parameters of one host.
+type HostTrackingParams struct {
+	Host  string
+	Strip []string
+	Keep  []string
+}
+
+// isTracking reports whether the parameter k of a URL on host is tracking,
+// going by the built-in list and t.
+func (t *TrackingParams) isTracking(host, k string) bool {
+	if t == nil {
+		return isTrackingParam(k)
+	}
+	strip, keep := isTrackingParam(k) || matchParam(t.Strip, k), matchParam(t.Keep, k)
+	host = asciiHost(host)
+	for _, h := range t.Hosts {
+		if hh := asciiHost(h.Host); host == hh || strings.HasSuffix(host, "."+hh) {
+			strip = strip || matchParam(h.Strip, k)
+			keep = keep || matchParam(h.Keep, k)
+		}
+	}
+	return strip && !keep
+}
+
+// asciiHost lower-cases host in its punycode form, so both spellings of an
+// internationalized name match.
+func asciiHost(host string) string {
+	if a, err := idnProfile.ToASCII(host); err == nil {
+		host = a
+	}
+	return strings.ToLower(host)
+}
+
+// matchParam reports whether k is one of names, ignoring case, where a name
+// ending in * matches every key it prefixes.
+func matchParam(names []string, k string) bool {
+	k = strings.ToLower(k)
+	for _, n := range names {
+		n = strings.ToLower(n)
+		if prefix, ok := strings.CutSuffix(n, "*"); (ok && strings.HasPrefix(k, prefix)) || n == k {
+			return true
+		}
+	}
+	return false
+}
+
+// Fragment is what happens to a URL's #fragment.
+type Fragment string
+
+const (
+	FragmentDrop   Fragment = "drop"   // drop_fragment
+	FragmentKeep   Fragment = "keep"   // no fragment rule
+	FragmentRoutes Fragment = "routes" // drop_non_route_fragment
+)
+
+// Fragments lists the valid Fragment values.
+var Fragments = []Fragment{FragmentDrop, FragmentKeep, FragmentRoutes}
+
+// Duplicates is what happens to a query key given more than once.
+type Duplicates string
+
+const (
+	DuplicatesKeepAll   Duplicates = "keep_all"   // no duplicates rule
+	DuplicatesKeepFirst Duplicates = "keep_first" // keep_first_param
+	DuplicatesKeepLast  Duplicates = "keep_last"  // keep_last_param
+)
+
+// DuplicatesValues lists the valid Duplicates values.
+var DuplicatesValues = []Duplicates{DuplicatesKeepAll, DuplicatesKeepFirst, DuplicatesKeepLast}
+
+// EmptyParams is what happens to query parameters without a value.
+type EmptyParams string
+
+const (
+	EmptyParamsKeep EmptyParams = "keep" // no empty params rule
+	EmptyParamsDrop EmptyParams = "drop" // drop_empty_params
+)
+
+// EmptyParamsValues lists the valid EmptyParams values.
+var EmptyParamsValues = []EmptyParams{EmptyParamsKeep, EmptyParamsDrop}
+
+// withQuery returns rules with their duplicate and empty parameter rules
+// replaced by the ones d and e ask for, at the end.
+func withQuery(rules []Rule, d Duplicates, e EmptyParams) ([]Rule, error) {
+	out := rules
+	if d != "" {
+		out = slices.DeleteFunc(slices.Clone(out), func(r Rule) bool { return slices.Contains(duplicateRules, r) })
+		switch d {
+		case DuplicatesKeepFirst:
+			out = append(out, KeepFirstParam)
+		case DuplicatesKeepLast:
+			out = append(out, KeepLastParam)
+		case DuplicatesKeepAll:
+		default:
+			return nil, fmt.Errorf("%w %q", ErrUnknownQuery, d)
+		}
+	}
+	if e != "" {
+		out = slices.DeleteFunc(slices.Clone(out), func(r Rule) bool { return r == DropEmptyParams })
+		switch e {
+		case EmptyParamsDrop:
+			out = append(out, DropEmptyParams)
+		case EmptyParamsKeep:
+		default:
+			return nil, fmt.Errorf("%w %q", ErrUnknownQuery, e)
+		}
+	}
+	return out, nil
+}
+
+// withFragment returns rules with their fragment rules replaced by the one
+// f asks for, at the end.
+func withFragment(rules []Rule, f Fragment) ([]Rule, error) {
+	if f == "" {
+		return rules, nil
+	}
+	out := slices.DeleteFunc(slices.Clone(rules), func(r Rule) bool {
+		return r == DropFragment || r == DropNonRouteFragment
+	})
+	switch f {
+	case FragmentDrop:
+		out = append(out, DropFragment)
+	case FragmentRoutes:
+		out = append(out, DropNonRouteFragment)
+	case FragmentKeep:
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownFragment, f)
+	}
+	return out, nil
+}
+
+// Clean parses rawURL with Parse and applies the rules opts select.
+func Clean(ctx context.Context, rawURL string, opts Options) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	profile := &Profile{Rules: opts.Rules}
+	if len(opts.Rules) == 0 {
+		name := opts.Profile
+		if name == "" {
+			name = "all"
+		}
+		p, ok := opts.Profiles.Lookup(name)
+		if !ok {
+			return "", fmt.Errorf("%w %q", ErrUnknownProfile, name)
+		}
+		profile = &p
+	}
+	rules, err := withFragment(profile.Rules, opts.Fragment)
+	if err != nil {
+		return "", err
+	}
+	if rules, err = withQuery(rules, opts.Duplicates, opts.EmptyParams); err != nil {
+		return "", err
+	}
+	profile = &Profile{Name: profile.Name, Rules: rules, HTTPSHosts: profile.HTTPSHosts, tracking: opts.Tracking}
+	for _, r := range profile.Rules {
+		if !known(r) {
+			return "", fmt.Errorf("%w %q", ErrUnknownRule, r)
+		}
+	}
+	u, err := Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range profile.Rules {
+		if check, ok := checks[r]; ok {
+			if err := check(u); err != nil {
+				return "", err
+			}
+		}
+	}
+	// the trailing slash is settled last, so no other step can leave the
+	// path ending differently than the policy says
+	for _, r := range profile.Rules {
+		if step, ok := steps[r]; ok && !slices.Contains(slashRules, r) {
+			step(u, profile)
+		}
+	}
+	for _, r := range profile.Rules {
+		if slices.Contains(slashRules, r) {
+			steps[r](u, profile)
+		}
+	}
+	return u.String(), nil
+}
+
+// Parse accepts absolute http and https URLs of at most MaxLength characters
+// after trimming surrounding space.
+func Parse(rawURL string) (*url.URL, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	switch {
+	case rawURL == "":
+		return nil, ErrEmpty
+	case len(rawURL) > MaxLength:
+		return nil, ErrTooLong
+	}
+	u, err := url.Parse(rawURL)
+	switch {
+	case err != nil:
+		return nil, ErrInvalid
+	case u.Scheme != "http" && u.Scheme != "https":
+		return nil, ErrScheme
+	case u.Host == "":
+		return nil, ErrNoHost
+	}
+	return u, nil
+}
+
+// Rule is one normalization step.
+type Rule string
+
+const (
+	LowercaseHost Rule = "lowercase_host"
+	// AddWWW prefixes bare root domains (example.com -> www.example.com) and
+	// leaves subdomains such as api.example.com alone.
+	AddWWW Rule = "add_www"
+	// RejectPort fails URLs that name a port, even a default one, with
+	// ErrPort. Like every check it looks at the URL as given, wherever it
+	// appears among the rules.
+	RejectPort Rule = "reject_port"
+	// ForceWWW prefixes every host without www., for properties known to
+	// serve only from www (shop.example.co.uk -> www.shop.example.co.uk).
+	ForceWWW Rule = "force_www"
+	// StripWWW removes a leading www. (www.example.com -> example.com).
+	// Leaving out all three www rules preserves the host as given.
+	StripWWW Rule = "strip_www"
+	// UpgradeHTTPS turns http into https, dropping an explicit :80, for the
+	// hosts of the profile's HTTPSHosts, or every host when it lists none.
+	UpgradeHTTPS Rule = "upgrade_https"
+	// DropDefaultPort removes :80 from http and :443 from https URLs, and
+	// an empty port ("example.com:"); other ports stay.
+	DropDefaultPort Rule = "drop_default_port"
+	LowercasePath   Rule = "lowercase_path"
+	// TrimTrailingSlash removes every slash ending the path, so /books/,
+	// /books// and /books all become /books, and the root becomes empty.
+	// An escaped slash (%2F) is part of a segment and stays.
+	TrimTrailingSlash Rule = "trim_trailing_slash"
+	// AddTrailingSlash ends the path in exactly one slash, for sites that
+	// serve directories, unless its last segment names a file (index.html).
+	// The two slash rules exclude each other and, wherever they appear
+	// among the rules, run after every other step.
+	AddTrailingSlash      Rule = "add_trailing_slash"
+	TrimQueryValueSlashes Rule = "trim_query_value_slashes"
+	// StripTrackingParams removes campaign and click IDs (utm_*, gclid,
+	// fbclid, ...), which change per visitor but not the page, and the
+	// parameters Options.Tracking adds.
+	StripTrackingParams Rule = "strip_tracking_params"
+	SortQuery           Rule = "sort_query"
+	// KeepFirstParam keeps the first of the parameters sharing a key and
+	// KeepLastParam the last (?a=1&a=2 -> ?a=1 or ?a=2). Without either,
+	// every value is kept.
+	KeepFirstParam Rule = "keep_first_param"
+	KeepLastParam  Rule = "keep_last_param"
+	// DropEmptyParams removes parameters without a value (?b= and ?b).
+	DropEmptyParams Rule = "drop_empty_params"
+	DropQuery       Rule = "drop_query"
+	DropFragment    Rule = "drop_fragment"
+	// DropNonRouteFragment keeps single-page app routes, #/path and #!path,
+	// and drops any other fragment.
+	DropNonRouteFragment Rule = "drop_non_route_fragment"
+)
+
+// steps holds what each rule does; a step changes u in place, reading its
+// settings, if any, from the profile being applied.
+var steps = map[Rule]func(u *url.URL, p *Profile){
+	LowercaseHost: func(u *url.URL, _ *Profile) { u.Host = strings.ToLower(u.Host) },
+	AddWWW: func(u *url.URL, _ *Profile) {
+		if host := u.Hostname(); !hasWWW(host) && strings.Count(host, ".") == 1 {
+			setHostname(u, "www."+host)
+		}
+	},
+	ForceWWW: func(u *url.URL, _ *Profile) {
+		if host := u.Hostname(); !hasWWW(host) && strings.Contains(host, ".") && net.ParseIP(host) == nil {
+			setHostname(u, "www."+host)
+		}
+	},
+	StripWWW: func(u *url.URL, _ *Profile) {
+		if host := u.Hostname(); hasWWW(host) && strings.Contains(host[4:], ".") {
+			setHostname(u, host[4:])
+		}
+	},
+	UpgradeHTTPS: func(u *url.URL, p *Profile) {
+		if u.Scheme != "http" || !p.upgrades(u.Hostname()) {
+			return
+		}
+		u.Scheme = "https"
+		if u.Port() == "80" {
+			u.Host = strings.TrimSuffix(u.Host, ":80")
+		}
+	},
+	DropDefaultPort: func(u *url.URL, _ *Profile) {
+		if port := u.Port(); port == "" || (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+			u.Host = strings.TrimSuffix(strings.TrimSuffix(u.Host, port), ":")
+		}
+	},
+	LowercasePath: func(u *url.URL, _ *Profile) {
+		u.Path, u.RawPath = strings.ToLower(u.Path), lowerKeepingEscapes(u.RawPath)
+	},
+	TrimTrailingSlash: func(u *url.URL, _ *Profile) { setEscapedPath(u, strings.TrimRight(u.EscapedPath(), "/")) },
+	AddTrailingSlash: func(u *url.URL, _ *Profile) {
+		p := strings.TrimRight(u.EscapedPath(), "/")
+		if last := p[strings.LastIndexByte(p, '/')+1:]; !strings.Contains(last, ".") {
+			p += "/"
+		}
+		setEscapedPath(u, p)
+	},
+	TrimQueryValueSlashes: func(u *url.URL, _ *Profile) {
+		q := u.Query()
+		for _, vals := range q {
+			for i, v := range vals {
+				vals[i] = strings.TrimSuffix(v, "/")
+			}
+		}
+		u.RawQuery = q.Encode()
+	},
+	StripTrackingParams: func(u *url.URL, p *Profile) {
+		q := u.Query()
+		for k := range q {
+			if p.tracking.isTracking(u.Hostname(), k) {
+				q.Del(k)
+			}
+		}
+		u.RawQuery = q.Encode()
+	},
+	SortQuery: func(u *url.URL, _ *Profile) { u.RawQuery = u.Query().Encode() },
+	KeepFirstParam: func(u *url.URL, _ *Profile) {
+		seen := map[string]bool{}
+		filterParams(u, func(key, _ string) bool {
+			keep := !seen[key]
+			seen[key] = true
+			return keep
+		})
+	},
+	KeepLastParam: func(u *url.URL, _ *Profile) {
+		left := map[string]int{}
+		filterParams(u, func(key, _ string) bool { left[key]++; return true })
+		filterParams(u, func(key, _ string) bool { left[key]--; return left[key] == 0 })
+	},
+	DropEmptyParams: func(u *url.URL, _ *Profile) {
+		filterParams(u, func(_, value string) bool { return value != "" })
+	},
+	DropQuery:    func(u *url.URL, _ *Profile) { u.RawQuery, u.ForceQuery = "", false },
+	DropFragment: func(u *url.URL, _ *Profile) { u.Fragment, u.RawFragment = "", "" },
+	DropNonRouteFragment: func(u *url.URL, _ *Profile) {
+		if !strings.HasPrefix(u.Fragment, "/") && !strings.HasPrefix(u.Fragment, "!") {
+			u.Fragment, u.RawFragment = "", ""
+		}
+	},
+}
+
+// checks holds the rules that accept or reject a URL instead of changing it.
+var checks = map[Rule]func(u *url.URL) error{
+	RejectPort: func(u *url.URL) error {
+		if strings.LastIndexByte(u.Host, ':') > strings.LastIndexByte(u.Host, ']') {
+			return ErrPort
+		}
+		return nil
+	},
+}
+
+func known(r Rule) bool {
+	_, step := steps[r]
+	_, check := checks[r]
+	return step || check
+}
+
+// duplicateRules are the duplicate key policies; a profile picks at most one.
+var duplicateRules = []Rule{KeepFirstParam, KeepLastParam}
+
+// filterParams keeps the query parameters keep accepts, given each one's
+// unescaped key and value, in order. Kept parameters stay in their order
+// and encoding, unlike the rules that go through url.Values.
+func filterParams(u *url.URL, keep func(key, value string) bool) {
+	if u.RawQuery == "" {
+		return
+	}
+	var kept []string
+	for _, param := range strings.Split(u.RawQuery, "&") {
+		if param == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(param, "=")
+		if k, err := url.QueryUnescape(key); err == nil {
+			key = k
+		}
+		if v, err := url.QueryUnescape(value); err == nil {
+			value = v
+		}
+		if keep(key, value) {
+			kept = append(kept, param)
+		}
+	}
+	u.RawQuery = strings.Join(kept, "&")
+}
+
+// slashRules are the trailing slash policies; a profile picks at most one.
+var slashRules = []Rule{TrimTrailingSlash, AddTrailingSlash}
+
+// setEscapedPath replaces u's path with escaped, an escaped path, keeping
+// escapes such as %2F as they are.
+func setEscapedPath(u *url.URL, escaped string) {
+	if escaped == u.EscapedPath() {
+		return
+	}
+	path, err := url.PathUnescape(escaped)
+	if err != nil {
+		return
+	}
+	u.Path, u.RawPath = path, escaped
+}
+
+// lowerKeepingEscapes lower-cases an escaped path but not the hex digits of
+// its escapes, so %2F stays %2F.
+func lowerKeepingEscapes(escaped string) string {
+	b := []byte(escaped)
+	for i := 0; i < len(b); i++ {
+		if b[i] == '%' {
+			i += 2
+			continue
+		}
+		if 'A' <= b[i] && b[i] <= 'Z' {
+			b[i] += 'a' - 'A'
+		}
+	}
+	return string(b)
+}
+
+func hasWWW(host string) bool { return strings.HasPrefix(strings.ToLower(host), "www.") }
+
+// setHostname replaces u's host name, keeping its port.
+func setHostname(u *url.URL, host string) {
+	if port := u.Port(); port != "" {
+		host += ":" + port
+	}
+	u.Host = host
+}
+
+var trackingParams = []string{"gclid", "dclid", "fbclid", "msclkid", "mc_cid", "mc_eid", "_ga", "yclid"}
+
+func isTrackingParam(k string) bool {
+	k = strings.ToLower(k)
+	return strings.HasPrefix(k, "utm_") || slices.Contains(trackingParams, k)
+}
+
+// Rules lists every rule, sorted by name.
+func Rules() []Rule {
+	out := make([]Rule, 0, len(steps)+len(checks))
+	for r := range steps {
+		out = append(out, r)
+	}
+	for r := range checks {
+		out = append(out, r)
+	}
+	slices.Sort(out)
+	return out
+}
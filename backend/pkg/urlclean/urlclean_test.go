@@ -0,0 +1,342 @@
+package urlclean
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestClean_BuiltInProfiles(t *testing.T) {
+	ctx := context.Background()
+	cases := []struct{ profile, in, want string }{
+		{"canonical", "https://Example.com/Path/To/?a=1#frag", "https://Example.com/Path/To"},
+		{"redirection", "https://example.com/Path/To/?y=2/&x=1#frag", "https://www.example.com/path/to?x=1&y=2"},
+		{"redirection", "http://Example.com:8080/", "http://www.example.com:8080"},
+		{"all", "https://Sub.Example.com/Path/To/?x=1#frag", "https://sub.example.com/path/to"},
+		{"", " https://Example.com/Books/ ", "https://www.example.com/books"},
+	}
+	for _, tc := range cases {
+		if got, err := Clean(ctx, tc.in, Options{Profile: tc.profile}); err != nil || got != tc.want {
+			t.Errorf("Clean(%q, %q) = %q, %v; want %q", tc.in, tc.profile, got, err, tc.want)
+		}
+	}
+}
+
+func TestClean_TrailingSlash(t *testing.T) {
+	ctx := context.Background()
+	ps, err := NewProfiles(
+		// the slash rule runs last wherever it is listed
+		Profile{Name: "dirs", Rules: []Rule{AddTrailingSlash, LowercaseHost, LowercasePath, DropQuery}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		in                                string
+		redirection, canonical, all, dirs string
+	}{
+		{"https://example.com/books/",
+			"https://www.example.com/books", "https://example.com/books", "https://www.example.com/books", "https://example.com/books/"},
+		{"https://example.com/books//",
+			"https://www.example.com/books", "https://example.com/books", "https://www.example.com/books", "https://example.com/books/"},
+		{"https://example.com/books",
+			"https://www.example.com/books", "https://example.com/books", "https://www.example.com/books", "https://example.com/books/"},
+		{"https://example.com/",
+			"https://www.example.com", "https://example.com", "https://www.example.com", "https://example.com/"},
+		{"https://example.com",
+			"https://www.example.com", "https://example.com", "https://www.example.com", "https://example.com/"},
+		{"https://example.com//",
+			"https://www.example.com", "https://example.com", "https://www.example.com", "https://example.com/"},
+		{"https://example.com/Books/?a=b/#/x/",
+			"https://www.example.com/books?a=b", "https://example.com/Books", "https://www.example.com/books", "https://example.com/books/#/x/"},
+		{"https://example.com/Books/Index.html",
+			"https://www.example.com/books/index.html", "https://example.com/Books/Index.html", "https://www.example.com/books/index.html", "https://example.com/books/index.html"},
+		// an escaped slash belongs to the segment, not the path's end
+		{"https://example.com/A%2F",
+			"https://www.example.com/a%2F", "https://example.com/A%2F", "https://www.example.com/a%2F", "https://example.com/a%2F/"},
+	}
+	for _, c := range cases {
+		for profile, want := range map[string]string{"redirection": c.redirection, "canonical": c.canonical, "all": c.all, "dirs": c.dirs} {
+			got, err := Clean(ctx, c.in, Options{Profile: profile, Profiles: ps})
+			if err != nil || got != want {
+				t.Errorf("%s: Clean(%q) = %q, %v; want %q", profile, c.in, got, err, want)
+				continue
+			}
+			if again, _ := Clean(ctx, got, Options{Profile: profile, Profiles: ps}); again != got {
+				t.Errorf("%s: Clean(%q) = %q; cleaning again gives %q", profile, c.in, got, again)
+			}
+		}
+	}
+
+	if _, err := NewProfiles(Profile{Name: "both", Rules: []Rule{TrimTrailingSlash, AddTrailingSlash}}); err == nil {
+		t.Fatal("NewProfiles accepted both slash rules")
+	}
+}
+
+func TestClean_Rules(t *testing.T) {
+	got, err := Clean(context.Background(), "https://Shop.Example.com:443/Books?utm_source=x&b=2&fbclid=y&a=1#top",
+		Options{Rules: []Rule{LowercaseHost, DropDefaultPort, StripTrackingParams, SortQuery, DropFragment}})
+	if want := "https://shop.example.com/Books?a=1&b=2"; err != nil || got != want {
+		t.Fatalf("Clean = %q, %v; want %q", got, err, want)
+	}
+	if _, err := Clean(context.Background(), "https://example.com", Options{Rules: []Rule{"shorten"}}); !errors.Is(err, ErrUnknownRule) {
+		t.Fatalf("unknown rule: %v", err)
+	}
+}
+
+func TestClean_WWWAndHTTPS(t *testing.T) {
+	ctx := context.Background()
+	ps, err := NewProfiles(
+		Profile{Name: "blog", Rules: []Rule{UpgradeHTTPS, StripWWW}, HTTPSHosts: []string{"Blog.Example.com"}},
+		Profile{Name: "shop", Rules: []Rule{UpgradeHTTPS, ForceWWW}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct{ profile, in, want string }{
+		{"blog", "http://www.blog.example.com:80/post", "https://blog.example.com/post"},
+		{"blog", "http://www.example.com:8080/", "http://example.com:8080/"}, // not a listed host: no upgrade
+		{"blog", "http://www.com/", "http://www.com/"},                       // www is the domain itself
+		{"shop", "http://shop.example.co.uk/cart", "https://www.shop.example.co.uk/cart"},
+		{"shop", "http://10.0.0.1:8080/", "https://10.0.0.1:8080/"},
+		{"shop", "https://www.example.com/", "https://www.example.com/"},
+	}
+	for _, c := range cases {
+		if got, err := Clean(ctx, c.in, Options{Profile: c.profile, Profiles: ps}); err != nil || got != c.want {
+			t.Errorf("%s: Clean(%q) = %q, %v; want %q", c.profile, c.in, got, err, c.want)
+		}
+	}
+	// the redirection operation keeps adding www to bare domains only
+	if got, _ := Clean(ctx, "https://shop.example.co.uk/", Options{Profile: "redirection"}); got != "https://shop.example.co.uk" {
+		t.Errorf("redirection = %q", got)
+	}
+}
+
+func TestClean_Fragment(t *testing.T) {
+	ctx := context.Background()
+	cases := []struct {
+		in       string
+		fragment Fragment
+		want     string
+	}{
+		{"https://example.com/app/#/books/1", "", "https://www.example.com/app"},
+		{"https://example.com/app/#/books/1", FragmentRoutes, "https://www.example.com/app#/books/1"},
+		{"https://example.com/app/#!/books/1", FragmentRoutes, "https://www.example.com/app#!/books/1"},
+		{"https://example.com/app/#top", FragmentRoutes, "https://www.example.com/app"},
+		{"https://example.com/app/#top", FragmentKeep, "https://www.example.com/app#top"},
+		{"https://example.com/app/#top", FragmentDrop, "https://www.example.com/app"},
+	}
+	for _, c := range cases {
+		if got, err := Clean(ctx, c.in, Options{Profile: "redirection", Fragment: c.fragment}); err != nil || got != c.want {
+			t.Errorf("Clean(%q, fragment %q) = %q, %v; want %q", c.in, c.fragment, got, err, c.want)
+		}
+	}
+	if _, err := Clean(ctx, "https://example.com", Options{Fragment: "hashbang"}); !errors.Is(err, ErrUnknownFragment) {
+		t.Errorf("unknown fragment: %v", err)
+	}
+}
+
+func TestClean_QueryParams(t *testing.T) {
+	ctx := context.Background()
+	ps, err := NewProfiles(
+		Profile{Name: "plain", Rules: []Rule{LowercaseHost}},
+		Profile{Name: "analytics", Rules: []Rule{LowercaseHost, KeepLastParam, DropEmptyParams}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const in = "https://example.com/p?a=1&b=&a=2&c&A=3&a%20b=x&a+b=y"
+	cases := []struct {
+		profile    string
+		duplicates Duplicates
+		empty      EmptyParams
+		want       string
+	}{
+		{"plain", "", "", "https://example.com/p?a=1&b=&a=2&c&A=3&a%20b=x&a+b=y"},
+		{"plain", DuplicatesKeepAll, EmptyParamsKeep, "https://example.com/p?a=1&b=&a=2&c&A=3&a%20b=x&a+b=y"},
+		// keys compare unescaped and case-sensitively
+		{"plain", DuplicatesKeepFirst, "", "https://example.com/p?a=1&b=&c&A=3&a%20b=x"},
+		{"plain", DuplicatesKeepLast, "", "https://example.com/p?b=&a=2&c&A=3&a+b=y"},
+		{"plain", "", EmptyParamsDrop, "https://example.com/p?a=1&a=2&A=3&a%20b=x&a+b=y"},
+		{"plain", DuplicatesKeepFirst, EmptyParamsDrop, "https://example.com/p?a=1&A=3&a%20b=x"},
+		{"analytics", "", "", "https://example.com/p?a=2&A=3&a+b=y"},
+		// overrides replace the profile's rules
+		{"analytics", DuplicatesKeepAll, EmptyParamsKeep, "https://example.com/p?a=1&b=&a=2&c&A=3&a%20b=x&a+b=y"},
+		{"analytics", DuplicatesKeepFirst, "", "https://example.com/p?a=1&A=3&a%20b=x"},
+	}
+	for _, c := range cases {
+		got, err := Clean(ctx, in, Options{Profile: c.profile, Profiles: ps, Duplicates: c.duplicates, EmptyParams: c.empty})
+		if err != nil || got != c.want {
+			t.Errorf("%s, duplicates %q, empty %q: Clean = %q, %v; want %q", c.profile, c.duplicates, c.empty, got, err, c.want)
+		}
+	}
+	// with sort_query the keys are sorted first, values keep their order
+	if got, _ := Clean(ctx, "https://example.com/?b=2&a=2&a=1", Options{Rules: []Rule{SortQuery, KeepFirstParam}}); got != "https://example.com/?a=2&b=2" {
+		t.Errorf("sorted keep_first = %q", got)
+	}
+	if _, err := Clean(ctx, in, Options{Duplicates: "merge"}); !errors.Is(err, ErrUnknownQuery) {
+		t.Errorf("unknown duplicates: %v", err)
+	}
+	if _, err := NewProfiles(Profile{Name: "both", Rules: []Rule{KeepFirstParam, KeepLastParam}}); err == nil {
+		t.Error("NewProfiles accepted both duplicate rules")
+	}
+}
+
+func TestClean_TrackingParams(t *testing.T) {
+	ctx := context.Background()
+	tracking := &TrackingParams{
+		Strip: []string{"ref", "Src_*"},
+		Keep:  []string{"utm_id"},
+		Hosts: []HostTrackingParams{
+			{Host: "Shop.Example.com", Strip: []string{"sid"}, Keep: []string{"ref"}},
+		},
+	}
+	cases := []struct{ in, want string }{
+		{"https://example.com/?utm_source=x&utm_id=7&ref=home&src_feed=a&SRC_x=b&sid=1&q=go", "https://example.com/?q=go&sid=1&utm_id=7"},
+		{"https://www.shop.example.com/?ref=home&sid=1&gclid=z&q=go", "https://www.shop.example.com/?q=go&ref=home"},
+		{"https://shop.example.com.evil.net/?ref=home&sid=1", "https://shop.example.com.evil.net/?sid=1"},
+	}
+	for _, c := range cases {
+		got, err := Clean(ctx, c.in, Options{Rules: []Rule{StripTrackingParams}, Tracking: tracking})
+		if err != nil || got != c.want {
+			t.Errorf("Clean(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+	if got, _ := Clean(ctx, cases[0].in, Options{Rules: []Rule{StripTrackingParams}}); got != "https://example.com/?SRC_x=b&q=go&ref=home&sid=1&src_feed=a" {
+		t.Errorf("built-in list only = %q", got)
+	}
+}
+
+func TestClean_Ports(t *testing.T) {
+	ctx := context.Background()
+	drop := Options{Rules: []Rule{DropDefaultPort}}
+	for in, want := range map[string]string{
+		"http://example.com:80/a":   "http://example.com/a",
+		"https://example.com:443/a": "https://example.com/a",
+		"https://example.com:80/a":  "https://example.com:80/a", // not https's default
+		"http://example.com:8080/":  "http://example.com:8080/",
+		"https://example.com:/a":    "https://example.com/a",
+		"https://[::1]:443/":        "https://[::1]/",
+		"https://[::1]/":            "https://[::1]/",
+	} {
+		if got, err := Clean(ctx, in, drop); err != nil || got != want {
+			t.Errorf("drop_default_port(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+
+	reject := Options{Rules: []Rule{DropDefaultPort, RejectPort}}
+	for _, in := range []string{"https://example.com:443/", "http://example.com:8080/", "https://[::1]:8443/"} {
+		if _, err := Clean(ctx, in, reject); !errors.Is(err, ErrPort) {
+			t.Errorf("reject_port(%q) = %v; want ErrPort", in, err)
+		}
+	}
+	if got, err := Clean(ctx, "https://[::1]/", reject); err != nil || got != "https://[::1]/" {
+		t.Errorf("reject_port(IPv6 without port) = %q, %v", got, err)
+	}
+	if !slices.Contains(Rules(), RejectPort) {
+		t.Errorf("Rules() misses reject_port")
+	}
+}
+
+func TestClean_Errors(t *testing.T) {
+	ctx := context.Background()
+	for in, want := range map[string]error{
+		"": ErrEmpty,
+		"https://example.com/" + strings.Repeat("a", MaxLength): ErrTooLong,
+		"https://exa mple.com/%zz":                              ErrInvalid,
+		"ftp://example.com/file":                                ErrScheme,
+		"https:///path":                                         ErrNoHost,
+	} {
+		if _, err := Clean(ctx, in, Options{}); !errors.Is(err, want) {
+			t.Errorf("Clean(%.30q) = %v; want %v", in, err, want)
+		}
+	}
+	if _, err := Clean(ctx, "https://example.com", Options{Profile: "seo"}); !errors.Is(err, ErrUnknownProfile) {
+		t.Errorf("unknown profile: %v", err)
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := Clean(cancelled, "https://example.com", Options{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled ctx: %v", err)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	extra, err := LoadProfiles(strings.NewReader(`
+profiles:
+  - name: cache_key
+    description: One key per page for the CDN cache
+    rules: [lowercase_host, sort_query]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps, err := NewProfiles(extra...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list := ps.List(); len(list) != 4 || list[3].Name != "cache_key" || list[3].Rules[1] != SortQuery {
+		t.Fatalf("List = %+v", list)
+	}
+	if got, _ := Clean(context.Background(), "https://A.com/?b=1&a=2", Options{Profile: "cache_key", Profiles: ps}); got != "https://a.com/?a=2&b=1" {
+		t.Fatalf("cache_key = %q", got)
+	}
+
+	for _, bad := range []Profile{
+		{Name: "all", Rules: []Rule{DropQuery}},
+		{Name: "resolve", Rules: []Rule{DropQuery}},
+		{Name: "x", Rules: []Rule{"shorten"}},
+		{Name: "x"},
+		{Rules: []Rule{DropQuery}},
+		{Name: "x", Rules: []Rule{AddWWW, StripWWW}},
+		{Name: "x", Rules: []Rule{DropQuery}, HTTPSHosts: []string{"example.com"}},
+	} {
+		if _, err := NewProfiles(bad); err == nil {
+			t.Errorf("NewProfiles(%+v) accepted", bad)
+		}
+	}
+	if _, err := LoadProfiles(strings.NewReader("profiles:\n  - name: x\n    rulez: []\n")); err == nil {
+		t.Errorf("unknown YAML key accepted")
+	}
+}
+
+func ExampleClean() {
+	ps, _ := NewProfiles(Profile{Name: "cache_key", Rules: []Rule{LowercaseHost, StripTrackingParams, SortQuery}})
+	out, _ := Clean(context.Background(), "https://Shop.Example.com/Books?utm_source=mail&b=2&a=1",
+		Options{Profile: "cache_key", Profiles: ps})
+	fmt.Println(out)
+	// Output: https://shop.example.com/Books?a=1&b=2
+}
+
+func TestParseHost(t *testing.T) {
+	cases := []struct {
+		host, ascii, unicode string
+		scripts              []string
+		confusable           bool
+	}{
+		{"Example.COM", "example.com", "example.com", []string{"Latin"}, false},
+		{"аррӏе.com", "xn--80ak6aa92e.com", "аррӏе.com", []string{"Cyrillic", "Latin"}, true}, // whole-script lookalike
+		{"xn--80ak6aa92e.com", "xn--80ak6aa92e.com", "аррӏе.com", []string{"Cyrillic", "Latin"}, true},
+		{"pаypal.com", "xn--pypal-4ve.com", "pаypal.com", []string{"Cyrillic", "Latin"}, true}, // Cyrillic а in a Latin label
+		{"яндекс.рф", "xn--d1acpjx3f.xn--p1ai", "яндекс.рф", []string{"Cyrillic"}, false},
+		{"bücher.de", "xn--bcher-kva.de", "bücher.de", []string{"Latin"}, false},
+		{"日本語ドメイン.jp", "xn--eckwd4c7c5976acvb2w6i.jp", "日本語ドメイン.jp", []string{"Han", "Katakana", "Latin"}, false},
+		{"127.0.0.1", "127.0.0.1", "127.0.0.1", []string{}, false},
+	}
+	for _, c := range cases {
+		h, err := ParseHost(c.host)
+		if err != nil {
+			t.Errorf("ParseHost(%q): %v", c.host, err)
+			continue
+		}
+		if h.ASCII != c.ascii || h.Unicode != c.unicode || !slices.Equal(h.Scripts, c.scripts) || h.Confusable != c.confusable {
+			t.Errorf("ParseHost(%q) = %+v; want %s %s %v %v", c.host, h, c.ascii, c.unicode, c.scripts, c.confusable)
+		}
+	}
+	if _, err := ParseHost("xn--a.com"); err == nil {
+		t.Errorf("invalid punycode accepted")
+	}
+}
@@ -13,8 +13,21 @@ async function http<T>(path: string, init?: RequestInit): Promise<T> {
   return res.json();
 }
 
+// The API caps the page size of book lists, so the full list is fetched a
+// page at a time.
+const LIST_PAGE_SIZE = 200;
+
+async function listAllBooks(): Promise<Book[]> {
+  const books: Book[] = [];
+  for (;;) {
+    const page = await http<Book[]>(`/books/?limit=${LIST_PAGE_SIZE}&offset=${books.length}`);
+    books.push(...page);
+    if (page.length < LIST_PAGE_SIZE) return books;
+  }
+}
+
 export const api = {
-  listBooks: () => http<Book[]>('/books/'),
+  listBooks: listAllBooks,
   getBook: (id: string | number) => http<Book>(`/books/${id}`),
   createBook: (payload: Partial<Book>) =>
     http<Book>('/books/', { method: 'POST', body: JSON.stringify(payload) }),
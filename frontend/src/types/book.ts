@@ -3,6 +3,10 @@ export type Book = {
   title: string;
   author: string;
   isbn: string;
+  isbn10?: string;
+  categories?: string[];
+  tags?: string[];
+  publisher_id?: number;
   price: number;
   publication_year: number;
   created_at?: string;